@@ -21,6 +21,109 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// 文件内容的传输编码
+type ContentEncoding int32
+
+const (
+	ContentEncoding_CONTENT_RAW    ContentEncoding = 0 // 原始字节，使用 content 字段
+	ContentEncoding_CONTENT_BASE64 ContentEncoding = 1 // base64，使用 text 字段
+	ContentEncoding_CONTENT_UTF8   ContentEncoding = 2 // UTF-8 文本，使用 text 字段，不含 BOM，换行原样保留
+)
+
+// Enum value maps for ContentEncoding.
+var (
+	ContentEncoding_name = map[int32]string{
+		0: "CONTENT_RAW",
+		1: "CONTENT_BASE64",
+		2: "CONTENT_UTF8",
+	}
+	ContentEncoding_value = map[string]int32{
+		"CONTENT_RAW":    0,
+		"CONTENT_BASE64": 1,
+		"CONTENT_UTF8":   2,
+	}
+)
+
+func (x ContentEncoding) Enum() *ContentEncoding {
+	p := new(ContentEncoding)
+	*p = x
+	return p
+}
+
+func (x ContentEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ContentEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_agent_proto_enumTypes[0].Descriptor()
+}
+
+func (ContentEncoding) Type() protoreflect.EnumType {
+	return &file_agent_proto_enumTypes[0]
+}
+
+func (x ContentEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ContentEncoding.Descriptor instead.
+func (ContentEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{0}
+}
+
+// 目标已存在时的处理方式
+type OverwritePolicy int32
+
+const (
+	OverwritePolicy_OVERWRITE_FAIL     OverwritePolicy = 0 // 返回 ALREADY_EXISTS
+	OverwritePolicy_OVERWRITE_REPLACE  OverwritePolicy = 1 // 覆盖文件，不会用文件覆盖目录
+	OverwritePolicy_OVERWRITE_SKIP     OverwritePolicy = 2
+	OverwritePolicy_OVERWRITE_IF_NEWER OverwritePolicy = 3 // 源更新时覆盖
+)
+
+// Enum value maps for OverwritePolicy.
+var (
+	OverwritePolicy_name = map[int32]string{
+		0: "OVERWRITE_FAIL",
+		1: "OVERWRITE_REPLACE",
+		2: "OVERWRITE_SKIP",
+		3: "OVERWRITE_IF_NEWER",
+	}
+	OverwritePolicy_value = map[string]int32{
+		"OVERWRITE_FAIL":     0,
+		"OVERWRITE_REPLACE":  1,
+		"OVERWRITE_SKIP":     2,
+		"OVERWRITE_IF_NEWER": 3,
+	}
+)
+
+func (x OverwritePolicy) Enum() *OverwritePolicy {
+	p := new(OverwritePolicy)
+	*p = x
+	return p
+}
+
+func (x OverwritePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OverwritePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_agent_proto_enumTypes[1].Descriptor()
+}
+
+func (OverwritePolicy) Type() protoreflect.EnumType {
+	return &file_agent_proto_enumTypes[1]
+}
+
+func (x OverwritePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OverwritePolicy.Descriptor instead.
+func (OverwritePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{1}
+}
+
 type ServiceAction int32
 
 const (
@@ -60,11 +163,11 @@ func (x ServiceAction) String() string {
 }
 
 func (ServiceAction) Descriptor() protoreflect.EnumDescriptor {
-	return file_agent_proto_enumTypes[0].Descriptor()
+	return file_agent_proto_enumTypes[2].Descriptor()
 }
 
 func (ServiceAction) Type() protoreflect.EnumType {
-	return &file_agent_proto_enumTypes[0]
+	return &file_agent_proto_enumTypes[2]
 }
 
 func (x ServiceAction) Number() protoreflect.EnumNumber {
@@ -73,7 +176,7 @@ func (x ServiceAction) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ServiceAction.Descriptor instead.
 func (ServiceAction) EnumDescriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{0}
+	return file_agent_proto_rawDescGZIP(), []int{2}
 }
 
 // 插件状态
@@ -116,11 +219,11 @@ func (x PluginState) String() string {
 }
 
 func (PluginState) Descriptor() protoreflect.EnumDescriptor {
-	return file_agent_proto_enumTypes[1].Descriptor()
+	return file_agent_proto_enumTypes[3].Descriptor()
 }
 
 func (PluginState) Type() protoreflect.EnumType {
-	return &file_agent_proto_enumTypes[1]
+	return &file_agent_proto_enumTypes[3]
 }
 
 func (x PluginState) Number() protoreflect.EnumNumber {
@@ -129,7 +232,7 @@ func (x PluginState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use PluginState.Descriptor instead.
 func (PluginState) EnumDescriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{1}
+	return file_agent_proto_rawDescGZIP(), []int{3}
 }
 
 // 插件类型
@@ -166,11 +269,11 @@ func (x PluginType) String() string {
 }
 
 func (PluginType) Descriptor() protoreflect.EnumDescriptor {
-	return file_agent_proto_enumTypes[2].Descriptor()
+	return file_agent_proto_enumTypes[4].Descriptor()
 }
 
 func (PluginType) Type() protoreflect.EnumType {
-	return &file_agent_proto_enumTypes[2]
+	return &file_agent_proto_enumTypes[4]
 }
 
 func (x PluginType) Number() protoreflect.EnumNumber {
@@ -179,7 +282,7 @@ func (x PluginType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use PluginType.Descriptor instead.
 func (PluginType) EnumDescriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{2}
+	return file_agent_proto_rawDescGZIP(), []int{4}
 }
 
 // 空消息
@@ -356,6 +459,13 @@ type SystemInfo struct {
 	Disks           []*DiskInfo            `protobuf:"bytes,12,rep,name=disks,proto3" json:"disks,omitempty"`
 	Networks        []*NetworkInfo         `protobuf:"bytes,13,rep,name=networks,proto3" json:"networks,omitempty"`
 	Gpus            []*GpuInfo             `protobuf:"bytes,20,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	Units           *UnitSummary           `protobuf:"bytes,21,opt,name=units,proto3" json:"units,omitempty"`
+	Logins          *LoginInfo             `protobuf:"bytes,22,opt,name=logins,proto3" json:"logins,omitempty"`
+	Clock           *ClockSync             `protobuf:"bytes,23,opt,name=clock,proto3" json:"clock,omitempty"`
+	Distro          *DistroInfo            `protobuf:"bytes,24,opt,name=distro,proto3" json:"distro,omitempty"`
+	Packages        *PackageInfo           `protobuf:"bytes,25,opt,name=packages,proto3" json:"packages,omitempty"`
+	Labels          map[string]string      `protobuf:"bytes,26,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 主机标签
+	PublicIp        *PublicIP              `protobuf:"bytes,27,opt,name=public_ip,json=publicIp,proto3" json:"public_ip,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -481,31 +591,82 @@ func (x *SystemInfo) GetGpus() []*GpuInfo {
 	return nil
 }
 
-type CpuInfo struct {
+func (x *SystemInfo) GetUnits() *UnitSummary {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *SystemInfo) GetLogins() *LoginInfo {
+	if x != nil {
+		return x.Logins
+	}
+	return nil
+}
+
+func (x *SystemInfo) GetClock() *ClockSync {
+	if x != nil {
+		return x.Clock
+	}
+	return nil
+}
+
+func (x *SystemInfo) GetDistro() *DistroInfo {
+	if x != nil {
+		return x.Distro
+	}
+	return nil
+}
+
+func (x *SystemInfo) GetPackages() *PackageInfo {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+func (x *SystemInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *SystemInfo) GetPublicIp() *PublicIP {
+	if x != nil {
+		return x.PublicIp
+	}
+	return nil
+}
+
+type PublicIP struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
-	Cores         int32                  `protobuf:"varint,2,opt,name=cores,proto3" json:"cores,omitempty"`
-	Threads       int32                  `protobuf:"varint,3,opt,name=threads,proto3" json:"threads,omitempty"`
-	Frequency     float64                `protobuf:"fixed64,4,opt,name=frequency,proto3" json:"frequency,omitempty"`
-	UsagePerCore  []float64              `protobuf:"fixed64,5,rep,packed,name=usage_per_core,json=usagePerCore,proto3" json:"usage_per_core,omitempty"`
+	Ipv4          string                 `protobuf:"bytes,1,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6          string                 `protobuf:"bytes,2,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	Asn           string                 `protobuf:"bytes,4,opt,name=asn,proto3" json:"asn,omitempty"` // 如 AS13335
+	Org           string                 `protobuf:"bytes,5,opt,name=org,proto3" json:"org,omitempty"`
+	Source        string                 `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	CheckedAt     int64                  `protobuf:"varint,7,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CpuInfo) Reset() {
-	*x = CpuInfo{}
+func (x *PublicIP) Reset() {
+	*x = PublicIP{}
 	mi := &file_agent_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CpuInfo) String() string {
+func (x *PublicIP) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CpuInfo) ProtoMessage() {}
+func (*PublicIP) ProtoMessage() {}
 
-func (x *CpuInfo) ProtoReflect() protoreflect.Message {
+func (x *PublicIP) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -517,72 +678,85 @@ func (x *CpuInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CpuInfo.ProtoReflect.Descriptor instead.
-func (*CpuInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use PublicIP.ProtoReflect.Descriptor instead.
+func (*PublicIP) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *CpuInfo) GetModel() string {
+func (x *PublicIP) GetIpv4() string {
 	if x != nil {
-		return x.Model
+		return x.Ipv4
 	}
 	return ""
 }
 
-func (x *CpuInfo) GetCores() int32 {
+func (x *PublicIP) GetIpv6() string {
 	if x != nil {
-		return x.Cores
+		return x.Ipv6
 	}
-	return 0
+	return ""
 }
 
-func (x *CpuInfo) GetThreads() int32 {
+func (x *PublicIP) GetCountry() string {
 	if x != nil {
-		return x.Threads
+		return x.Country
 	}
-	return 0
+	return ""
 }
 
-func (x *CpuInfo) GetFrequency() float64 {
+func (x *PublicIP) GetAsn() string {
 	if x != nil {
-		return x.Frequency
+		return x.Asn
 	}
-	return 0
+	return ""
 }
 
-func (x *CpuInfo) GetUsagePerCore() []float64 {
+func (x *PublicIP) GetOrg() string {
 	if x != nil {
-		return x.UsagePerCore
+		return x.Org
 	}
-	return nil
+	return ""
 }
 
-type MemoryInfo struct {
+func (x *PublicIP) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PublicIP) GetCheckedAt() int64 {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return 0
+}
+
+type DistroInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Total         uint64                 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
-	Available     uint64                 `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
-	Used          uint64                 `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`
-	UsedPercent   float64                `protobuf:"fixed64,4,opt,name=used_percent,json=usedPercent,proto3" json:"used_percent,omitempty"`
-	SwapTotal     uint64                 `protobuf:"varint,5,opt,name=swap_total,json=swapTotal,proto3" json:"swap_total,omitempty"`
-	SwapUsed      uint64                 `protobuf:"varint,6,opt,name=swap_used,json=swapUsed,proto3" json:"swap_used,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Codename      string                 `protobuf:"bytes,4,opt,name=codename,proto3" json:"codename,omitempty"`
+	PrettyName    string                 `protobuf:"bytes,5,opt,name=pretty_name,json=prettyName,proto3" json:"pretty_name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MemoryInfo) Reset() {
-	*x = MemoryInfo{}
+func (x *DistroInfo) Reset() {
+	*x = DistroInfo{}
 	mi := &file_agent_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MemoryInfo) String() string {
+func (x *DistroInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MemoryInfo) ProtoMessage() {}
+func (*DistroInfo) ProtoMessage() {}
 
-func (x *MemoryInfo) ProtoReflect() protoreflect.Message {
+func (x *DistroInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -594,80 +768,71 @@ func (x *MemoryInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MemoryInfo.ProtoReflect.Descriptor instead.
-func (*MemoryInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use DistroInfo.ProtoReflect.Descriptor instead.
+func (*DistroInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *MemoryInfo) GetTotal() uint64 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-func (x *MemoryInfo) GetAvailable() uint64 {
+func (x *DistroInfo) GetId() string {
 	if x != nil {
-		return x.Available
+		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *MemoryInfo) GetUsed() uint64 {
+func (x *DistroInfo) GetName() string {
 	if x != nil {
-		return x.Used
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *MemoryInfo) GetUsedPercent() float64 {
+func (x *DistroInfo) GetVersion() string {
 	if x != nil {
-		return x.UsedPercent
+		return x.Version
 	}
-	return 0
+	return ""
 }
 
-func (x *MemoryInfo) GetSwapTotal() uint64 {
+func (x *DistroInfo) GetCodename() string {
 	if x != nil {
-		return x.SwapTotal
+		return x.Codename
 	}
-	return 0
+	return ""
 }
 
-func (x *MemoryInfo) GetSwapUsed() uint64 {
+func (x *DistroInfo) GetPrettyName() string {
 	if x != nil {
-		return x.SwapUsed
+		return x.PrettyName
 	}
-	return 0
+	return ""
 }
 
-type DiskInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
-	Mountpoint    string                 `protobuf:"bytes,2,opt,name=mountpoint,proto3" json:"mountpoint,omitempty"`
-	Fstype        string                 `protobuf:"bytes,3,opt,name=fstype,proto3" json:"fstype,omitempty"`
-	Total         uint64                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
-	Used          uint64                 `protobuf:"varint,5,opt,name=used,proto3" json:"used,omitempty"`
-	Free          uint64                 `protobuf:"varint,6,opt,name=free,proto3" json:"free,omitempty"`
-	UsedPercent   float64                `protobuf:"fixed64,7,opt,name=used_percent,json=usedPercent,proto3" json:"used_percent,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type PackageInfo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Manager         string                 `protobuf:"bytes,1,opt,name=manager,proto3" json:"manager,omitempty"` // apt / dnf / yum / rpm / apk / pacman
+	Installed       int32                  `protobuf:"varint,2,opt,name=installed,proto3" json:"installed,omitempty"`
+	Updates         int32                  `protobuf:"varint,3,opt,name=updates,proto3" json:"updates,omitempty"`                                        // -1 表示无法获取
+	SecurityUpdates int32                  `protobuf:"varint,4,opt,name=security_updates,json=securityUpdates,proto3" json:"security_updates,omitempty"` // -1 表示无法获取
+	CheckedAt       int64                  `protobuf:"varint,5,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *DiskInfo) Reset() {
-	*x = DiskInfo{}
+func (x *PackageInfo) Reset() {
+	*x = PackageInfo{}
 	mi := &file_agent_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiskInfo) String() string {
+func (x *PackageInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiskInfo) ProtoMessage() {}
+func (*PackageInfo) ProtoMessage() {}
 
-func (x *DiskInfo) ProtoReflect() protoreflect.Message {
+func (x *PackageInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -679,85 +844,71 @@ func (x *DiskInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiskInfo.ProtoReflect.Descriptor instead.
-func (*DiskInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use PackageInfo.ProtoReflect.Descriptor instead.
+func (*PackageInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *DiskInfo) GetDevice() string {
-	if x != nil {
-		return x.Device
-	}
-	return ""
-}
-
-func (x *DiskInfo) GetMountpoint() string {
+func (x *PackageInfo) GetManager() string {
 	if x != nil {
-		return x.Mountpoint
-	}
-	return ""
-}
-
-func (x *DiskInfo) GetFstype() string {
-	if x != nil {
-		return x.Fstype
+		return x.Manager
 	}
 	return ""
 }
 
-func (x *DiskInfo) GetTotal() uint64 {
+func (x *PackageInfo) GetInstalled() int32 {
 	if x != nil {
-		return x.Total
+		return x.Installed
 	}
 	return 0
 }
 
-func (x *DiskInfo) GetUsed() uint64 {
+func (x *PackageInfo) GetUpdates() int32 {
 	if x != nil {
-		return x.Used
+		return x.Updates
 	}
 	return 0
 }
 
-func (x *DiskInfo) GetFree() uint64 {
+func (x *PackageInfo) GetSecurityUpdates() int32 {
 	if x != nil {
-		return x.Free
+		return x.SecurityUpdates
 	}
 	return 0
 }
 
-func (x *DiskInfo) GetUsedPercent() float64 {
+func (x *PackageInfo) GetCheckedAt() int64 {
 	if x != nil {
-		return x.UsedPercent
+		return x.CheckedAt
 	}
 	return 0
 }
 
-type NetworkInfo struct {
+type ClockSync struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Addresses     []string               `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
-	Mac           string                 `protobuf:"bytes,3,opt,name=mac,proto3" json:"mac,omitempty"`
-	BytesSent     uint64                 `protobuf:"varint,4,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
-	BytesRecv     uint64                 `protobuf:"varint,5,opt,name=bytes_recv,json=bytesRecv,proto3" json:"bytes_recv,omitempty"`
+	Synchronized  bool                   `protobuf:"varint,1,opt,name=synchronized,proto3" json:"synchronized,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"` // chrony / ntpd / timesyncd / systemd / sntp
+	Server        string                 `protobuf:"bytes,3,opt,name=server,proto3" json:"server,omitempty"`
+	Stratum       int32                  `protobuf:"varint,4,opt,name=stratum,proto3" json:"stratum,omitempty"`
+	Offset        float64                `protobuf:"fixed64,5,opt,name=offset,proto3" json:"offset,omitempty"` // 秒，正值表示本地时钟偏快
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkInfo) Reset() {
-	*x = NetworkInfo{}
+func (x *ClockSync) Reset() {
+	*x = ClockSync{}
 	mi := &file_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkInfo) String() string {
+func (x *ClockSync) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkInfo) ProtoMessage() {}
+func (*ClockSync) ProtoMessage() {}
 
-func (x *NetworkInfo) ProtoReflect() protoreflect.Message {
+func (x *ClockSync) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -769,72 +920,70 @@ func (x *NetworkInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkInfo.ProtoReflect.Descriptor instead.
-func (*NetworkInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use ClockSync.ProtoReflect.Descriptor instead.
+func (*ClockSync) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *NetworkInfo) GetName() string {
+func (x *ClockSync) GetSynchronized() bool {
 	if x != nil {
-		return x.Name
+		return x.Synchronized
 	}
-	return ""
+	return false
 }
 
-func (x *NetworkInfo) GetAddresses() []string {
+func (x *ClockSync) GetSource() string {
 	if x != nil {
-		return x.Addresses
+		return x.Source
 	}
-	return nil
+	return ""
 }
 
-func (x *NetworkInfo) GetMac() string {
+func (x *ClockSync) GetServer() string {
 	if x != nil {
-		return x.Mac
+		return x.Server
 	}
 	return ""
 }
 
-func (x *NetworkInfo) GetBytesSent() uint64 {
+func (x *ClockSync) GetStratum() int32 {
 	if x != nil {
-		return x.BytesSent
+		return x.Stratum
 	}
 	return 0
 }
 
-func (x *NetworkInfo) GetBytesRecv() uint64 {
+func (x *ClockSync) GetOffset() float64 {
 	if x != nil {
-		return x.BytesRecv
+		return x.Offset
 	}
 	return 0
 }
 
-type GpuInfo struct {
+type LoginSession struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	DriverVersion string                 `protobuf:"bytes,2,opt,name=driver_version,json=driverVersion,proto3" json:"driver_version,omitempty"`
-	MemoryTotal   uint64                 `protobuf:"varint,3,opt,name=memory_total,json=memoryTotal,proto3" json:"memory_total,omitempty"`
-	MemoryUsed    uint64                 `protobuf:"varint,4,opt,name=memory_used,json=memoryUsed,proto3" json:"memory_used,omitempty"`
-	Temperature   float64                `protobuf:"fixed64,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
-	Utilization   float64                `protobuf:"fixed64,6,opt,name=utilization,proto3" json:"utilization,omitempty"`
+	User          string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Terminal      string                 `protobuf:"bytes,2,opt,name=terminal,proto3" json:"terminal,omitempty"`
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Started       int64                  `protobuf:"varint,4,opt,name=started,proto3" json:"started,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GpuInfo) Reset() {
-	*x = GpuInfo{}
+func (x *LoginSession) Reset() {
+	*x = LoginSession{}
 	mi := &file_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GpuInfo) String() string {
+func (x *LoginSession) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GpuInfo) ProtoMessage() {}
+func (*LoginSession) ProtoMessage() {}
 
-func (x *GpuInfo) ProtoReflect() protoreflect.Message {
+func (x *LoginSession) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -846,76 +995,64 @@ func (x *GpuInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GpuInfo.ProtoReflect.Descriptor instead.
-func (*GpuInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoginSession.ProtoReflect.Descriptor instead.
+func (*LoginSession) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GpuInfo) GetName() string {
+func (x *LoginSession) GetUser() string {
 	if x != nil {
-		return x.Name
+		return x.User
 	}
 	return ""
 }
 
-func (x *GpuInfo) GetDriverVersion() string {
+func (x *LoginSession) GetTerminal() string {
 	if x != nil {
-		return x.DriverVersion
+		return x.Terminal
 	}
 	return ""
 }
 
-func (x *GpuInfo) GetMemoryTotal() uint64 {
-	if x != nil {
-		return x.MemoryTotal
-	}
-	return 0
-}
-
-func (x *GpuInfo) GetMemoryUsed() uint64 {
-	if x != nil {
-		return x.MemoryUsed
-	}
-	return 0
-}
-
-func (x *GpuInfo) GetTemperature() float64 {
+func (x *LoginSession) GetHost() string {
 	if x != nil {
-		return x.Temperature
+		return x.Host
 	}
-	return 0
+	return ""
 }
 
-func (x *GpuInfo) GetUtilization() float64 {
+func (x *LoginSession) GetStarted() int64 {
 	if x != nil {
-		return x.Utilization
+		return x.Started
 	}
 	return 0
 }
 
-// 监控指标
-type MetricsRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	IntervalSeconds int32                  `protobuf:"varint,1,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
-	Metrics         []string               `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type LoginRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Terminal      string                 `protobuf:"bytes,2,opt,name=terminal,proto3" json:"terminal,omitempty"`
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Time          int64                  `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MetricsRequest) Reset() {
-	*x = MetricsRequest{}
+func (x *LoginRecord) Reset() {
+	*x = LoginRecord{}
 	mi := &file_agent_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MetricsRequest) String() string {
+func (x *LoginRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MetricsRequest) ProtoMessage() {}
+func (*LoginRecord) ProtoMessage() {}
 
-func (x *MetricsRequest) ProtoReflect() protoreflect.Message {
+func (x *LoginRecord) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -927,53 +1064,70 @@ func (x *MetricsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MetricsRequest.ProtoReflect.Descriptor instead.
-func (*MetricsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoginRecord.ProtoReflect.Descriptor instead.
+func (*LoginRecord) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *MetricsRequest) GetIntervalSeconds() int32 {
+func (x *LoginRecord) GetUser() string {
 	if x != nil {
-		return x.IntervalSeconds
+		return x.User
+	}
+	return ""
+}
+
+func (x *LoginRecord) GetTerminal() string {
+	if x != nil {
+		return x.Terminal
+	}
+	return ""
+}
+
+func (x *LoginRecord) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *LoginRecord) GetTime() int64 {
+	if x != nil {
+		return x.Time
 	}
 	return 0
 }
 
-func (x *MetricsRequest) GetMetrics() []string {
+func (x *LoginRecord) GetSuccess() bool {
 	if x != nil {
-		return x.Metrics
+		return x.Success
 	}
-	return nil
+	return false
 }
 
-type Metrics struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp      int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	CpuUsage       float64                `protobuf:"fixed64,2,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
-	MemoryUsage    float64                `protobuf:"fixed64,3,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
-	DiskMetrics    []*DiskMetric          `protobuf:"bytes,4,rep,name=disk_metrics,json=diskMetrics,proto3" json:"disk_metrics,omitempty"`
-	NetworkMetrics []*NetworkMetric       `protobuf:"bytes,5,rep,name=network_metrics,json=networkMetrics,proto3" json:"network_metrics,omitempty"`
-	Load_1         float64                `protobuf:"fixed64,6,opt,name=load_1,json=load1,proto3" json:"load_1,omitempty"`
-	Load_5         float64                `protobuf:"fixed64,7,opt,name=load_5,json=load5,proto3" json:"load_5,omitempty"`
-	Load_15        float64                `protobuf:"fixed64,8,opt,name=load_15,json=load15,proto3" json:"load_15,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// 当前会话与最近登录记录（登录历史仅 Linux 支持）
+type LoginInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*LoginSession        `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	Recent        []*LoginRecord         `protobuf:"bytes,2,rep,name=recent,proto3" json:"recent,omitempty"`
+	Failed        []*LoginRecord         `protobuf:"bytes,3,rep,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Metrics) Reset() {
-	*x = Metrics{}
+func (x *LoginInfo) Reset() {
+	*x = LoginInfo{}
 	mi := &file_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Metrics) String() string {
+func (x *LoginInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Metrics) ProtoMessage() {}
+func (*LoginInfo) ProtoMessage() {}
 
-func (x *Metrics) ProtoReflect() protoreflect.Message {
+func (x *LoginInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -985,92 +1139,59 @@ func (x *Metrics) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Metrics.ProtoReflect.Descriptor instead.
-func (*Metrics) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoginInfo.ProtoReflect.Descriptor instead.
+func (*LoginInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Metrics) GetTimestamp() int64 {
-	if x != nil {
-		return x.Timestamp
-	}
-	return 0
-}
-
-func (x *Metrics) GetCpuUsage() float64 {
-	if x != nil {
-		return x.CpuUsage
-	}
-	return 0
-}
-
-func (x *Metrics) GetMemoryUsage() float64 {
+func (x *LoginInfo) GetSessions() []*LoginSession {
 	if x != nil {
-		return x.MemoryUsage
-	}
-	return 0
-}
-
-func (x *Metrics) GetDiskMetrics() []*DiskMetric {
-	if x != nil {
-		return x.DiskMetrics
+		return x.Sessions
 	}
 	return nil
 }
 
-func (x *Metrics) GetNetworkMetrics() []*NetworkMetric {
+func (x *LoginInfo) GetRecent() []*LoginRecord {
 	if x != nil {
-		return x.NetworkMetrics
+		return x.Recent
 	}
 	return nil
 }
 
-func (x *Metrics) GetLoad_1() float64 {
-	if x != nil {
-		return x.Load_1
-	}
-	return 0
-}
-
-func (x *Metrics) GetLoad_5() float64 {
-	if x != nil {
-		return x.Load_5
-	}
-	return 0
-}
-
-func (x *Metrics) GetLoad_15() float64 {
+func (x *LoginInfo) GetFailed() []*LoginRecord {
 	if x != nil {
-		return x.Load_15
+		return x.Failed
 	}
-	return 0
+	return nil
 }
 
-type DiskMetric struct {
+// systemd 单元状态汇总（非 systemd 系统不返回）
+type UnitSummary struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
-	ReadBytes     uint64                 `protobuf:"varint,2,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`
-	WriteBytes    uint64                 `protobuf:"varint,3,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"`
-	ReadCount     uint64                 `protobuf:"varint,4,opt,name=read_count,json=readCount,proto3" json:"read_count,omitempty"`
-	WriteCount    uint64                 `protobuf:"varint,5,opt,name=write_count,json=writeCount,proto3" json:"write_count,omitempty"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Active        int32                  `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	Inactive      int32                  `protobuf:"varint,3,opt,name=inactive,proto3" json:"inactive,omitempty"`
+	Activating    int32                  `protobuf:"varint,4,opt,name=activating,proto3" json:"activating,omitempty"`
+	Failed        int32                  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	FailedUnits   []string               `protobuf:"bytes,6,rep,name=failed_units,json=failedUnits,proto3" json:"failed_units,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiskMetric) Reset() {
-	*x = DiskMetric{}
+func (x *UnitSummary) Reset() {
+	*x = UnitSummary{}
 	mi := &file_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiskMetric) String() string {
+func (x *UnitSummary) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiskMetric) ProtoMessage() {}
+func (*UnitSummary) ProtoMessage() {}
 
-func (x *DiskMetric) ProtoReflect() protoreflect.Message {
+func (x *UnitSummary) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1082,71 +1203,78 @@ func (x *DiskMetric) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiskMetric.ProtoReflect.Descriptor instead.
-func (*DiskMetric) Descriptor() ([]byte, []int) {
+// Deprecated: Use UnitSummary.ProtoReflect.Descriptor instead.
+func (*UnitSummary) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *DiskMetric) GetDevice() string {
+func (x *UnitSummary) GetTotal() int32 {
 	if x != nil {
-		return x.Device
+		return x.Total
 	}
-	return ""
+	return 0
 }
 
-func (x *DiskMetric) GetReadBytes() uint64 {
+func (x *UnitSummary) GetActive() int32 {
 	if x != nil {
-		return x.ReadBytes
+		return x.Active
 	}
 	return 0
 }
 
-func (x *DiskMetric) GetWriteBytes() uint64 {
+func (x *UnitSummary) GetInactive() int32 {
 	if x != nil {
-		return x.WriteBytes
+		return x.Inactive
 	}
 	return 0
 }
 
-func (x *DiskMetric) GetReadCount() uint64 {
+func (x *UnitSummary) GetActivating() int32 {
 	if x != nil {
-		return x.ReadCount
+		return x.Activating
 	}
 	return 0
 }
 
-func (x *DiskMetric) GetWriteCount() uint64 {
+func (x *UnitSummary) GetFailed() int32 {
 	if x != nil {
-		return x.WriteCount
+		return x.Failed
 	}
 	return 0
 }
 
-type NetworkMetric struct {
+func (x *UnitSummary) GetFailedUnits() []string {
+	if x != nil {
+		return x.FailedUnits
+	}
+	return nil
+}
+
+type CpuInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Interface     string                 `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
-	BytesSent     uint64                 `protobuf:"varint,2,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
-	BytesRecv     uint64                 `protobuf:"varint,3,opt,name=bytes_recv,json=bytesRecv,proto3" json:"bytes_recv,omitempty"`
-	PacketsSent   uint64                 `protobuf:"varint,4,opt,name=packets_sent,json=packetsSent,proto3" json:"packets_sent,omitempty"`
-	PacketsRecv   uint64                 `protobuf:"varint,5,opt,name=packets_recv,json=packetsRecv,proto3" json:"packets_recv,omitempty"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	Cores         int32                  `protobuf:"varint,2,opt,name=cores,proto3" json:"cores,omitempty"`
+	Threads       int32                  `protobuf:"varint,3,opt,name=threads,proto3" json:"threads,omitempty"`
+	Frequency     float64                `protobuf:"fixed64,4,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	UsagePerCore  []float64              `protobuf:"fixed64,5,rep,packed,name=usage_per_core,json=usagePerCore,proto3" json:"usage_per_core,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NetworkMetric) Reset() {
-	*x = NetworkMetric{}
+func (x *CpuInfo) Reset() {
+	*x = CpuInfo{}
 	mi := &file_agent_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NetworkMetric) String() string {
+func (x *CpuInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NetworkMetric) ProtoMessage() {}
+func (*CpuInfo) ProtoMessage() {}
 
-func (x *NetworkMetric) ProtoReflect() protoreflect.Message {
+func (x *CpuInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1158,73 +1286,72 @@ func (x *NetworkMetric) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NetworkMetric.ProtoReflect.Descriptor instead.
-func (*NetworkMetric) Descriptor() ([]byte, []int) {
+// Deprecated: Use CpuInfo.ProtoReflect.Descriptor instead.
+func (*CpuInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *NetworkMetric) GetInterface() string {
+func (x *CpuInfo) GetModel() string {
 	if x != nil {
-		return x.Interface
+		return x.Model
 	}
 	return ""
 }
 
-func (x *NetworkMetric) GetBytesSent() uint64 {
+func (x *CpuInfo) GetCores() int32 {
 	if x != nil {
-		return x.BytesSent
+		return x.Cores
 	}
 	return 0
 }
 
-func (x *NetworkMetric) GetBytesRecv() uint64 {
+func (x *CpuInfo) GetThreads() int32 {
 	if x != nil {
-		return x.BytesRecv
+		return x.Threads
 	}
 	return 0
 }
 
-func (x *NetworkMetric) GetPacketsSent() uint64 {
+func (x *CpuInfo) GetFrequency() float64 {
 	if x != nil {
-		return x.PacketsSent
+		return x.Frequency
 	}
 	return 0
 }
 
-func (x *NetworkMetric) GetPacketsRecv() uint64 {
+func (x *CpuInfo) GetUsagePerCore() []float64 {
 	if x != nil {
-		return x.PacketsRecv
+		return x.UsagePerCore
 	}
-	return 0
+	return nil
 }
 
-// 命令执行
-type CommandRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Command        string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
-	Args           []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
-	WorkingDir     string                 `protobuf:"bytes,3,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
-	Env            map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	TimeoutSeconds int32                  `protobuf:"varint,5,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
-	Sudo           bool                   `protobuf:"varint,6,opt,name=sudo,proto3" json:"sudo,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type MemoryInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         uint64                 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Available     uint64                 `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
+	Used          uint64                 `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`
+	UsedPercent   float64                `protobuf:"fixed64,4,opt,name=used_percent,json=usedPercent,proto3" json:"used_percent,omitempty"`
+	SwapTotal     uint64                 `protobuf:"varint,5,opt,name=swap_total,json=swapTotal,proto3" json:"swap_total,omitempty"`
+	SwapUsed      uint64                 `protobuf:"varint,6,opt,name=swap_used,json=swapUsed,proto3" json:"swap_used,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CommandRequest) Reset() {
-	*x = CommandRequest{}
+func (x *MemoryInfo) Reset() {
+	*x = MemoryInfo{}
 	mi := &file_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CommandRequest) String() string {
+func (x *MemoryInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandRequest) ProtoMessage() {}
+func (*MemoryInfo) ProtoMessage() {}
 
-func (x *CommandRequest) ProtoReflect() protoreflect.Message {
+func (x *MemoryInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1236,77 +1363,84 @@ func (x *CommandRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
-func (*CommandRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use MemoryInfo.ProtoReflect.Descriptor instead.
+func (*MemoryInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *CommandRequest) GetCommand() string {
+func (x *MemoryInfo) GetTotal() uint64 {
 	if x != nil {
-		return x.Command
+		return x.Total
 	}
-	return ""
+	return 0
 }
 
-func (x *CommandRequest) GetArgs() []string {
+func (x *MemoryInfo) GetAvailable() uint64 {
 	if x != nil {
-		return x.Args
+		return x.Available
 	}
-	return nil
+	return 0
 }
 
-func (x *CommandRequest) GetWorkingDir() string {
+func (x *MemoryInfo) GetUsed() uint64 {
 	if x != nil {
-		return x.WorkingDir
+		return x.Used
 	}
-	return ""
+	return 0
 }
 
-func (x *CommandRequest) GetEnv() map[string]string {
+func (x *MemoryInfo) GetUsedPercent() float64 {
 	if x != nil {
-		return x.Env
+		return x.UsedPercent
 	}
-	return nil
+	return 0
 }
 
-func (x *CommandRequest) GetTimeoutSeconds() int32 {
+func (x *MemoryInfo) GetSwapTotal() uint64 {
 	if x != nil {
-		return x.TimeoutSeconds
+		return x.SwapTotal
 	}
 	return 0
 }
 
-func (x *CommandRequest) GetSudo() bool {
+func (x *MemoryInfo) GetSwapUsed() uint64 {
 	if x != nil {
-		return x.Sudo
+		return x.SwapUsed
 	}
-	return false
+	return 0
 }
 
-type CommandResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
-	Stdout        string                 `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
-	Stderr        string                 `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
-	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type DiskInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Device            string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	Mountpoint        string                 `protobuf:"bytes,2,opt,name=mountpoint,proto3" json:"mountpoint,omitempty"`
+	Fstype            string                 `protobuf:"bytes,3,opt,name=fstype,proto3" json:"fstype,omitempty"`
+	Total             uint64                 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	Used              uint64                 `protobuf:"varint,5,opt,name=used,proto3" json:"used,omitempty"`
+	Free              uint64                 `protobuf:"varint,6,opt,name=free,proto3" json:"free,omitempty"`
+	UsedPercent       float64                `protobuf:"fixed64,7,opt,name=used_percent,json=usedPercent,proto3" json:"used_percent,omitempty"`
+	InodesTotal       uint64                 `protobuf:"varint,8,opt,name=inodes_total,json=inodesTotal,proto3" json:"inodes_total,omitempty"`
+	InodesUsed        uint64                 `protobuf:"varint,9,opt,name=inodes_used,json=inodesUsed,proto3" json:"inodes_used,omitempty"`
+	InodesFree        uint64                 `protobuf:"varint,10,opt,name=inodes_free,json=inodesFree,proto3" json:"inodes_free,omitempty"`
+	InodesUsedPercent float64                `protobuf:"fixed64,11,opt,name=inodes_used_percent,json=inodesUsedPercent,proto3" json:"inodes_used_percent,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
-func (x *CommandResponse) Reset() {
-	*x = CommandResponse{}
+func (x *DiskInfo) Reset() {
+	*x = DiskInfo{}
 	mi := &file_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CommandResponse) String() string {
+func (x *DiskInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandResponse) ProtoMessage() {}
+func (*DiskInfo) ProtoMessage() {}
 
-func (x *CommandResponse) ProtoReflect() protoreflect.Message {
+func (x *DiskInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1318,65 +1452,113 @@ func (x *CommandResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandResponse.ProtoReflect.Descriptor instead.
-func (*CommandResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DiskInfo.ProtoReflect.Descriptor instead.
+func (*DiskInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *CommandResponse) GetExitCode() int32 {
+func (x *DiskInfo) GetDevice() string {
 	if x != nil {
-		return x.ExitCode
+		return x.Device
 	}
-	return 0
+	return ""
 }
 
-func (x *CommandResponse) GetStdout() string {
+func (x *DiskInfo) GetMountpoint() string {
 	if x != nil {
-		return x.Stdout
+		return x.Mountpoint
 	}
 	return ""
 }
 
-func (x *CommandResponse) GetStderr() string {
+func (x *DiskInfo) GetFstype() string {
 	if x != nil {
-		return x.Stderr
+		return x.Fstype
 	}
 	return ""
 }
 
-func (x *CommandResponse) GetDurationMs() int64 {
+func (x *DiskInfo) GetTotal() uint64 {
 	if x != nil {
-		return x.DurationMs
+		return x.Total
 	}
 	return 0
 }
 
-type ShellInput struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Input:
-	//
-	//	*ShellInput_Start
-	//	*ShellInput_Data
-	//	*ShellInput_Resize
-	Input         isShellInput_Input `protobuf_oneof:"input"`
+func (x *DiskInfo) GetUsed() uint64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *DiskInfo) GetFree() uint64 {
+	if x != nil {
+		return x.Free
+	}
+	return 0
+}
+
+func (x *DiskInfo) GetUsedPercent() float64 {
+	if x != nil {
+		return x.UsedPercent
+	}
+	return 0
+}
+
+func (x *DiskInfo) GetInodesTotal() uint64 {
+	if x != nil {
+		return x.InodesTotal
+	}
+	return 0
+}
+
+func (x *DiskInfo) GetInodesUsed() uint64 {
+	if x != nil {
+		return x.InodesUsed
+	}
+	return 0
+}
+
+func (x *DiskInfo) GetInodesFree() uint64 {
+	if x != nil {
+		return x.InodesFree
+	}
+	return 0
+}
+
+func (x *DiskInfo) GetInodesUsedPercent() float64 {
+	if x != nil {
+		return x.InodesUsedPercent
+	}
+	return 0
+}
+
+type NetworkInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Addresses     []string               `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Mac           string                 `protobuf:"bytes,3,opt,name=mac,proto3" json:"mac,omitempty"`
+	BytesSent     uint64                 `protobuf:"varint,4,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesRecv     uint64                 `protobuf:"varint,5,opt,name=bytes_recv,json=bytesRecv,proto3" json:"bytes_recv,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShellInput) Reset() {
-	*x = ShellInput{}
+func (x *NetworkInfo) Reset() {
+	*x = NetworkInfo{}
 	mi := &file_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShellInput) String() string {
+func (x *NetworkInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShellInput) ProtoMessage() {}
+func (*NetworkInfo) ProtoMessage() {}
 
-func (x *ShellInput) ProtoReflect() protoreflect.Message {
+func (x *NetworkInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1388,91 +1570,72 @@ func (x *ShellInput) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShellInput.ProtoReflect.Descriptor instead.
-func (*ShellInput) Descriptor() ([]byte, []int) {
+// Deprecated: Use NetworkInfo.ProtoReflect.Descriptor instead.
+func (*NetworkInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ShellInput) GetInput() isShellInput_Input {
+func (x *NetworkInfo) GetName() string {
 	if x != nil {
-		return x.Input
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-func (x *ShellInput) GetStart() *ShellStart {
+func (x *NetworkInfo) GetAddresses() []string {
 	if x != nil {
-		if x, ok := x.Input.(*ShellInput_Start); ok {
-			return x.Start
-		}
+		return x.Addresses
 	}
 	return nil
 }
 
-func (x *ShellInput) GetData() []byte {
+func (x *NetworkInfo) GetMac() string {
 	if x != nil {
-		if x, ok := x.Input.(*ShellInput_Data); ok {
-			return x.Data
-		}
+		return x.Mac
 	}
-	return nil
+	return ""
 }
 
-func (x *ShellInput) GetResize() *ShellResize {
+func (x *NetworkInfo) GetBytesSent() uint64 {
 	if x != nil {
-		if x, ok := x.Input.(*ShellInput_Resize); ok {
-			return x.Resize
-		}
+		return x.BytesSent
 	}
-	return nil
-}
-
-type isShellInput_Input interface {
-	isShellInput_Input()
-}
-
-type ShellInput_Start struct {
-	Start *ShellStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
-}
-
-type ShellInput_Data struct {
-	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+	return 0
 }
 
-type ShellInput_Resize struct {
-	Resize *ShellResize `protobuf:"bytes,3,opt,name=resize,proto3,oneof"`
+func (x *NetworkInfo) GetBytesRecv() uint64 {
+	if x != nil {
+		return x.BytesRecv
+	}
+	return 0
 }
 
-func (*ShellInput_Start) isShellInput_Input() {}
-
-func (*ShellInput_Data) isShellInput_Input() {}
-
-func (*ShellInput_Resize) isShellInput_Input() {}
-
-type ShellStart struct {
+type GpuInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Shell         string                 `protobuf:"bytes,1,opt,name=shell,proto3" json:"shell,omitempty"`
-	Rows          int32                  `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
-	Cols          int32                  `protobuf:"varint,3,opt,name=cols,proto3" json:"cols,omitempty"`
-	Env           map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DriverVersion string                 `protobuf:"bytes,2,opt,name=driver_version,json=driverVersion,proto3" json:"driver_version,omitempty"`
+	MemoryTotal   uint64                 `protobuf:"varint,3,opt,name=memory_total,json=memoryTotal,proto3" json:"memory_total,omitempty"`
+	MemoryUsed    uint64                 `protobuf:"varint,4,opt,name=memory_used,json=memoryUsed,proto3" json:"memory_used,omitempty"`
+	Temperature   float64                `protobuf:"fixed64,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Utilization   float64                `protobuf:"fixed64,6,opt,name=utilization,proto3" json:"utilization,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShellStart) Reset() {
-	*x = ShellStart{}
+func (x *GpuInfo) Reset() {
+	*x = GpuInfo{}
 	mi := &file_agent_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShellStart) String() string {
+func (x *GpuInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShellStart) ProtoMessage() {}
+func (*GpuInfo) ProtoMessage() {}
 
-func (x *ShellStart) ProtoReflect() protoreflect.Message {
+func (x *GpuInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_agent_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1484,62 +1647,5742 @@ func (x *ShellStart) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShellStart.ProtoReflect.Descriptor instead.
-func (*ShellStart) Descriptor() ([]byte, []int) {
+// Deprecated: Use GpuInfo.ProtoReflect.Descriptor instead.
+func (*GpuInfo) Descriptor() ([]byte, []int) {
 	return file_agent_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *ShellStart) GetShell() string {
+func (x *GpuInfo) GetName() string {
 	if x != nil {
-		return x.Shell
+		return x.Name
 	}
 	return ""
 }
 
-func (x *ShellStart) GetRows() int32 {
+func (x *GpuInfo) GetDriverVersion() string {
 	if x != nil {
-		return x.Rows
+		return x.DriverVersion
+	}
+	return ""
+}
+
+func (x *GpuInfo) GetMemoryTotal() uint64 {
+	if x != nil {
+		return x.MemoryTotal
 	}
 	return 0
 }
 
-func (x *ShellStart) GetCols() int32 {
+func (x *GpuInfo) GetMemoryUsed() uint64 {
 	if x != nil {
-		return x.Cols
+		return x.MemoryUsed
 	}
 	return 0
 }
 
-func (x *ShellStart) GetEnv() map[string]string {
+func (x *GpuInfo) GetTemperature() float64 {
 	if x != nil {
-		return x.Env
+		return x.Temperature
 	}
-	return nil
+	return 0
 }
 
-type ShellResize struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+func (x *GpuInfo) GetUtilization() float64 {
+	if x != nil {
+		return x.Utilization
+	}
+	return 0
+}
+
+// 监控指标
+type MetricsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IntervalSeconds int32                  `protobuf:"varint,1,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	Metrics         []string               `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *MetricsRequest) Reset() {
+	*x = MetricsRequest{}
+	mi := &file_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsRequest) ProtoMessage() {}
+
+func (x *MetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsRequest.ProtoReflect.Descriptor instead.
+func (*MetricsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MetricsRequest) GetIntervalSeconds() int32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *MetricsRequest) GetMetrics() []string {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type Metrics struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp      int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CpuUsage       float64                `protobuf:"fixed64,2,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
+	MemoryUsage    float64                `protobuf:"fixed64,3,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
+	DiskMetrics    []*DiskMetric          `protobuf:"bytes,4,rep,name=disk_metrics,json=diskMetrics,proto3" json:"disk_metrics,omitempty"`
+	NetworkMetrics []*NetworkMetric       `protobuf:"bytes,5,rep,name=network_metrics,json=networkMetrics,proto3" json:"network_metrics,omitempty"`
+	Load_1         float64                `protobuf:"fixed64,6,opt,name=load_1,json=load1,proto3" json:"load_1,omitempty"`
+	Load_5         float64                `protobuf:"fixed64,7,opt,name=load_5,json=load5,proto3" json:"load_5,omitempty"`
+	Load_15        float64                `protobuf:"fixed64,8,opt,name=load_15,json=load15,proto3" json:"load_15,omitempty"`
+	Containers     []*ContainerMetric     `protobuf:"bytes,9,rep,name=containers,proto3" json:"containers,omitempty"`
+	Units          *UnitSummary           `protobuf:"bytes,10,opt,name=units,proto3" json:"units,omitempty"`
+	ProcsRunning   int32                  `protobuf:"varint,11,opt,name=procs_running,json=procsRunning,proto3" json:"procs_running,omitempty"`
+	ProcsBlocked   int32                  `protobuf:"varint,12,opt,name=procs_blocked,json=procsBlocked,proto3" json:"procs_blocked,omitempty"`
+	Uptime         int64                  `protobuf:"varint,13,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	BootTime       int64                  `protobuf:"varint,14,opt,name=boot_time,json=bootTime,proto3" json:"boot_time,omitempty"`
+	SwapTotal      uint64                 `protobuf:"varint,15,opt,name=swap_total,json=swapTotal,proto3" json:"swap_total,omitempty"`
+	SwapUsed       uint64                 `protobuf:"varint,16,opt,name=swap_used,json=swapUsed,proto3" json:"swap_used,omitempty"`
+	SwapFree       uint64                 `protobuf:"varint,17,opt,name=swap_free,json=swapFree,proto3" json:"swap_free,omitempty"`
+	SwapUsage      float64                `protobuf:"fixed64,18,opt,name=swap_usage,json=swapUsage,proto3" json:"swap_usage,omitempty"`
+	SwapInRate     uint64                 `protobuf:"varint,19,opt,name=swap_in_rate,json=swapInRate,proto3" json:"swap_in_rate,omitempty"`    // 字节/秒
+	SwapOutRate    uint64                 `protobuf:"varint,20,opt,name=swap_out_rate,json=swapOutRate,proto3" json:"swap_out_rate,omitempty"` // 字节/秒
+	Top            *TopProcesses          `protobuf:"bytes,21,opt,name=top,proto3" json:"top,omitempty"`
+	Zombies        int32                  `protobuf:"varint,22,opt,name=zombies,proto3" json:"zombies,omitempty"`
+	StuckProcesses []*StuckProcess        `protobuf:"bytes,23,rep,name=stuck_processes,json=stuckProcesses,proto3" json:"stuck_processes,omitempty"` // 长时间处于 D 状态的进程
+	ConntrackCount int64                  `protobuf:"varint,24,opt,name=conntrack_count,json=conntrackCount,proto3" json:"conntrack_count,omitempty"`
+	ConntrackMax   int64                  `protobuf:"varint,25,opt,name=conntrack_max,json=conntrackMax,proto3" json:"conntrack_max,omitempty"` // 未加载 nf_conntrack 时为 0
+	FdAllocated    uint64                 `protobuf:"varint,26,opt,name=fd_allocated,json=fdAllocated,proto3" json:"fd_allocated,omitempty"`
+	FdMax          uint64                 `protobuf:"varint,27,opt,name=fd_max,json=fdMax,proto3" json:"fd_max,omitempty"`
+	FdNearLimit    []*FdUsage             `protobuf:"bytes,28,rep,name=fd_near_limit,json=fdNearLimit,proto3" json:"fd_near_limit,omitempty"`                                            // 文件描述符接近软限制的进程
+	Custom         []*CustomSample        `protobuf:"bytes,29,rep,name=custom,proto3" json:"custom,omitempty"`                                                                           // 自定义数据源输出的样本
+	Labels         map[string]string      `protobuf:"bytes,30,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 主机标签
+	Power          *PowerInfo             `protobuf:"bytes,31,opt,name=power,proto3" json:"power,omitempty"`                                                                             // 没有电源信息的机器不返回
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Metrics) Reset() {
+	*x = Metrics{}
+	mi := &file_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metrics) ProtoMessage() {}
+
+func (x *Metrics) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metrics.ProtoReflect.Descriptor instead.
+func (*Metrics) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Metrics) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Metrics) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *Metrics) GetMemoryUsage() float64 {
+	if x != nil {
+		return x.MemoryUsage
+	}
+	return 0
+}
+
+func (x *Metrics) GetDiskMetrics() []*DiskMetric {
+	if x != nil {
+		return x.DiskMetrics
+	}
+	return nil
+}
+
+func (x *Metrics) GetNetworkMetrics() []*NetworkMetric {
+	if x != nil {
+		return x.NetworkMetrics
+	}
+	return nil
+}
+
+func (x *Metrics) GetLoad_1() float64 {
+	if x != nil {
+		return x.Load_1
+	}
+	return 0
+}
+
+func (x *Metrics) GetLoad_5() float64 {
+	if x != nil {
+		return x.Load_5
+	}
+	return 0
+}
+
+func (x *Metrics) GetLoad_15() float64 {
+	if x != nil {
+		return x.Load_15
+	}
+	return 0
+}
+
+func (x *Metrics) GetContainers() []*ContainerMetric {
+	if x != nil {
+		return x.Containers
+	}
+	return nil
+}
+
+func (x *Metrics) GetUnits() *UnitSummary {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *Metrics) GetProcsRunning() int32 {
+	if x != nil {
+		return x.ProcsRunning
+	}
+	return 0
+}
+
+func (x *Metrics) GetProcsBlocked() int32 {
+	if x != nil {
+		return x.ProcsBlocked
+	}
+	return 0
+}
+
+func (x *Metrics) GetUptime() int64 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+func (x *Metrics) GetBootTime() int64 {
+	if x != nil {
+		return x.BootTime
+	}
+	return 0
+}
+
+func (x *Metrics) GetSwapTotal() uint64 {
+	if x != nil {
+		return x.SwapTotal
+	}
+	return 0
+}
+
+func (x *Metrics) GetSwapUsed() uint64 {
+	if x != nil {
+		return x.SwapUsed
+	}
+	return 0
+}
+
+func (x *Metrics) GetSwapFree() uint64 {
+	if x != nil {
+		return x.SwapFree
+	}
+	return 0
+}
+
+func (x *Metrics) GetSwapUsage() float64 {
+	if x != nil {
+		return x.SwapUsage
+	}
+	return 0
+}
+
+func (x *Metrics) GetSwapInRate() uint64 {
+	if x != nil {
+		return x.SwapInRate
+	}
+	return 0
+}
+
+func (x *Metrics) GetSwapOutRate() uint64 {
+	if x != nil {
+		return x.SwapOutRate
+	}
+	return 0
+}
+
+func (x *Metrics) GetTop() *TopProcesses {
+	if x != nil {
+		return x.Top
+	}
+	return nil
+}
+
+func (x *Metrics) GetZombies() int32 {
+	if x != nil {
+		return x.Zombies
+	}
+	return 0
+}
+
+func (x *Metrics) GetStuckProcesses() []*StuckProcess {
+	if x != nil {
+		return x.StuckProcesses
+	}
+	return nil
+}
+
+func (x *Metrics) GetConntrackCount() int64 {
+	if x != nil {
+		return x.ConntrackCount
+	}
+	return 0
+}
+
+func (x *Metrics) GetConntrackMax() int64 {
+	if x != nil {
+		return x.ConntrackMax
+	}
+	return 0
+}
+
+func (x *Metrics) GetFdAllocated() uint64 {
+	if x != nil {
+		return x.FdAllocated
+	}
+	return 0
+}
+
+func (x *Metrics) GetFdMax() uint64 {
+	if x != nil {
+		return x.FdMax
+	}
+	return 0
+}
+
+func (x *Metrics) GetFdNearLimit() []*FdUsage {
+	if x != nil {
+		return x.FdNearLimit
+	}
+	return nil
+}
+
+func (x *Metrics) GetCustom() []*CustomSample {
+	if x != nil {
+		return x.Custom
+	}
+	return nil
+}
+
+func (x *Metrics) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Metrics) GetPower() *PowerInfo {
+	if x != nil {
+		return x.Power
+	}
+	return nil
+}
+
+type PowerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AcOnline      bool                   `protobuf:"varint,1,opt,name=ac_online,json=acOnline,proto3" json:"ac_online,omitempty"`
+	OnBattery     bool                   `protobuf:"varint,2,opt,name=on_battery,json=onBattery,proto3" json:"on_battery,omitempty"`
+	Batteries     []*Battery             `protobuf:"bytes,3,rep,name=batteries,proto3" json:"batteries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PowerInfo) Reset() {
+	*x = PowerInfo{}
+	mi := &file_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PowerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PowerInfo) ProtoMessage() {}
+
+func (x *PowerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PowerInfo.ProtoReflect.Descriptor instead.
+func (*PowerInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PowerInfo) GetAcOnline() bool {
+	if x != nil {
+		return x.AcOnline
+	}
+	return false
+}
+
+func (x *PowerInfo) GetOnBattery() bool {
+	if x != nil {
+		return x.OnBattery
+	}
+	return false
+}
+
+func (x *PowerInfo) GetBatteries() []*Battery {
+	if x != nil {
+		return x.Batteries
+	}
+	return nil
+}
+
+type Battery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Percent       float64                `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`        // charging / discharging / full / not_charging / unknown
+	Remaining     int64                  `protobuf:"varint,4,opt,name=remaining,proto3" json:"remaining,omitempty"` // 预计剩余放电时间（秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Battery) Reset() {
+	*x = Battery{}
+	mi := &file_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Battery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Battery) ProtoMessage() {}
+
+func (x *Battery) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Battery.ProtoReflect.Descriptor instead.
+func (*Battery) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Battery) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Battery) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Battery) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Battery) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+type CustomSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Help          string                 `protobuf:"bytes,3,opt,name=help,proto3" json:"help,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Value         float64                `protobuf:"fixed64,5,opt,name=value,proto3" json:"value,omitempty"`
+	Type          string                 `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"` // gauge 或 counter
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomSample) Reset() {
+	*x = CustomSample{}
+	mi := &file_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomSample) ProtoMessage() {}
+
+func (x *CustomSample) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomSample.ProtoReflect.Descriptor instead.
+func (*CustomSample) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CustomSample) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CustomSample) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CustomSample) GetHelp() string {
+	if x != nil {
+		return x.Help
+	}
+	return ""
+}
+
+func (x *CustomSample) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CustomSample) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *CustomSample) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type FdUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Open          int32                  `protobuf:"varint,3,opt,name=open,proto3" json:"open,omitempty"`
+	Limit         uint64                 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Percent       float64                `protobuf:"fixed64,5,opt,name=percent,proto3" json:"percent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FdUsage) Reset() {
+	*x = FdUsage{}
+	mi := &file_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FdUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FdUsage) ProtoMessage() {}
+
+func (x *FdUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FdUsage.ProtoReflect.Descriptor instead.
+func (*FdUsage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FdUsage) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *FdUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FdUsage) GetOpen() int32 {
+	if x != nil {
+		return x.Open
+	}
+	return 0
+}
+
+func (x *FdUsage) GetLimit() uint64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FdUsage) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+type StuckProcess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	User          string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Cmdline       string                 `protobuf:"bytes,4,opt,name=cmdline,proto3" json:"cmdline,omitempty"`
+	Since         int64                  `protobuf:"varint,5,opt,name=since,proto3" json:"since,omitempty"`        // 进入 D 状态的时间（Unix 秒）
+	Duration      float64                `protobuf:"fixed64,6,opt,name=duration,proto3" json:"duration,omitempty"` // 已持续的秒数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StuckProcess) Reset() {
+	*x = StuckProcess{}
+	mi := &file_agent_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StuckProcess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StuckProcess) ProtoMessage() {}
+
+func (x *StuckProcess) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StuckProcess.ProtoReflect.Descriptor instead.
+func (*StuckProcess) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StuckProcess) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *StuckProcess) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StuckProcess) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *StuckProcess) GetCmdline() string {
+	if x != nil {
+		return x.Cmdline
+	}
+	return ""
+}
+
+func (x *StuckProcess) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *StuckProcess) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type TopProcess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	User          string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Value         float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"` // by_cpu 中为 CPU%，by_memory 中为 RSS 字节数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopProcess) Reset() {
+	*x = TopProcess{}
+	mi := &file_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopProcess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProcess) ProtoMessage() {}
+
+func (x *TopProcess) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProcess.ProtoReflect.Descriptor instead.
+func (*TopProcess) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TopProcess) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *TopProcess) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TopProcess) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *TopProcess) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type TopProcesses struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ByCpu         []*TopProcess          `protobuf:"bytes,1,rep,name=by_cpu,json=byCpu,proto3" json:"by_cpu,omitempty"`
+	ByMemory      []*TopProcess          `protobuf:"bytes,2,rep,name=by_memory,json=byMemory,proto3" json:"by_memory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopProcesses) Reset() {
+	*x = TopProcesses{}
+	mi := &file_agent_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopProcesses) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProcesses) ProtoMessage() {}
+
+func (x *TopProcesses) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProcesses.ProtoReflect.Descriptor instead.
+func (*TopProcesses) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TopProcesses) GetByCpu() []*TopProcess {
+	if x != nil {
+		return x.ByCpu
+	}
+	return nil
+}
+
+func (x *TopProcesses) GetByMemory() []*TopProcess {
+	if x != nil {
+		return x.ByMemory
+	}
+	return nil
+}
+
+// 容器资源占用（检测到 Docker 时才有数据，可能滞后数秒）
+type ContainerMetric struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Image         string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	State         string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	CpuPercent    float64                `protobuf:"fixed64,5,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	MemoryUsage   uint64                 `protobuf:"varint,6,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
+	MemoryLimit   uint64                 `protobuf:"varint,7,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
+	MemoryPercent float64                `protobuf:"fixed64,8,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
+	NetworkRx     uint64                 `protobuf:"varint,9,opt,name=network_rx,json=networkRx,proto3" json:"network_rx,omitempty"`  // 字节/秒
+	NetworkTx     uint64                 `protobuf:"varint,10,opt,name=network_tx,json=networkTx,proto3" json:"network_tx,omitempty"` // 字节/秒
+	RestartCount  int32                  `protobuf:"varint,11,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerMetric) Reset() {
+	*x = ContainerMetric{}
+	mi := &file_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerMetric) ProtoMessage() {}
+
+func (x *ContainerMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerMetric.ProtoReflect.Descriptor instead.
+func (*ContainerMetric) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ContainerMetric) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ContainerMetric) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ContainerMetric) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ContainerMetric) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ContainerMetric) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ContainerMetric) GetMemoryUsage() uint64 {
+	if x != nil {
+		return x.MemoryUsage
+	}
+	return 0
+}
+
+func (x *ContainerMetric) GetMemoryLimit() uint64 {
+	if x != nil {
+		return x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *ContainerMetric) GetMemoryPercent() float64 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
+}
+
+func (x *ContainerMetric) GetNetworkRx() uint64 {
+	if x != nil {
+		return x.NetworkRx
+	}
+	return 0
+}
+
+func (x *ContainerMetric) GetNetworkTx() uint64 {
+	if x != nil {
+		return x.NetworkTx
+	}
+	return 0
+}
+
+func (x *ContainerMetric) GetRestartCount() int32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+type DiskMetric struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	ReadBytes     uint64                 `protobuf:"varint,2,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`
+	WriteBytes    uint64                 `protobuf:"varint,3,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"`
+	ReadCount     uint64                 `protobuf:"varint,4,opt,name=read_count,json=readCount,proto3" json:"read_count,omitempty"`
+	WriteCount    uint64                 `protobuf:"varint,5,opt,name=write_count,json=writeCount,proto3" json:"write_count,omitempty"`
+	Await         float64                `protobuf:"fixed64,6,opt,name=await,proto3" json:"await,omitempty"` // 平均每次 I/O 耗时（毫秒）
+	Util          float64                `protobuf:"fixed64,7,opt,name=util,proto3" json:"util,omitempty"`   // 设备忙碌时间占比（%）
+	InFlight      uint64                 `protobuf:"varint,8,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskMetric) Reset() {
+	*x = DiskMetric{}
+	mi := &file_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskMetric) ProtoMessage() {}
+
+func (x *DiskMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskMetric.ProtoReflect.Descriptor instead.
+func (*DiskMetric) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DiskMetric) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *DiskMetric) GetReadBytes() uint64 {
+	if x != nil {
+		return x.ReadBytes
+	}
+	return 0
+}
+
+func (x *DiskMetric) GetWriteBytes() uint64 {
+	if x != nil {
+		return x.WriteBytes
+	}
+	return 0
+}
+
+func (x *DiskMetric) GetReadCount() uint64 {
+	if x != nil {
+		return x.ReadCount
+	}
+	return 0
+}
+
+func (x *DiskMetric) GetWriteCount() uint64 {
+	if x != nil {
+		return x.WriteCount
+	}
+	return 0
+}
+
+func (x *DiskMetric) GetAwait() float64 {
+	if x != nil {
+		return x.Await
+	}
+	return 0
+}
+
+func (x *DiskMetric) GetUtil() float64 {
+	if x != nil {
+		return x.Util
+	}
+	return 0
+}
+
+func (x *DiskMetric) GetInFlight() uint64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+type NetworkMetric struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Interface     string                 `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
+	BytesSent     uint64                 `protobuf:"varint,2,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesRecv     uint64                 `protobuf:"varint,3,opt,name=bytes_recv,json=bytesRecv,proto3" json:"bytes_recv,omitempty"`
+	PacketsSent   uint64                 `protobuf:"varint,4,opt,name=packets_sent,json=packetsSent,proto3" json:"packets_sent,omitempty"`
+	PacketsRecv   uint64                 `protobuf:"varint,5,opt,name=packets_recv,json=packetsRecv,proto3" json:"packets_recv,omitempty"`
+	ErrIn         uint64                 `protobuf:"varint,6,opt,name=err_in,json=errIn,proto3" json:"err_in,omitempty"`
+	ErrOut        uint64                 `protobuf:"varint,7,opt,name=err_out,json=errOut,proto3" json:"err_out,omitempty"`
+	DropIn        uint64                 `protobuf:"varint,8,opt,name=drop_in,json=dropIn,proto3" json:"drop_in,omitempty"`
+	DropOut       uint64                 `protobuf:"varint,9,opt,name=drop_out,json=dropOut,proto3" json:"drop_out,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkMetric) Reset() {
+	*x = NetworkMetric{}
+	mi := &file_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkMetric) ProtoMessage() {}
+
+func (x *NetworkMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkMetric.ProtoReflect.Descriptor instead.
+func (*NetworkMetric) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *NetworkMetric) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *NetworkMetric) GetBytesSent() uint64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetBytesRecv() uint64 {
+	if x != nil {
+		return x.BytesRecv
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetPacketsSent() uint64 {
+	if x != nil {
+		return x.PacketsSent
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetPacketsRecv() uint64 {
+	if x != nil {
+		return x.PacketsRecv
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetErrIn() uint64 {
+	if x != nil {
+		return x.ErrIn
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetErrOut() uint64 {
+	if x != nil {
+		return x.ErrOut
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetDropIn() uint64 {
+	if x != nil {
+		return x.DropIn
+	}
+	return 0
+}
+
+func (x *NetworkMetric) GetDropOut() uint64 {
+	if x != nil {
+		return x.DropOut
+	}
+	return 0
+}
+
+// 命令执行
+type CommandRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Command        string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Args           []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	WorkingDir     string                 `protobuf:"bytes,3,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	Env            map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TimeoutSeconds int32                  `protobuf:"varint,5,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	Sudo           bool                   `protobuf:"varint,6,opt,name=sudo,proto3" json:"sudo,omitempty"`
+	Stdin          []byte                 `protobuf:"bytes,7,opt,name=stdin,proto3" json:"stdin,omitempty"`      // 写入命令标准输入的内容（最大 10MB）
+	Sandbox        bool                   `protobuf:"varint,8,opt,name=sandbox,proto3" json:"sandbox,omitempty"` // 在沙箱中执行（仅 Linux），不能与 sudo 同时使用
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CommandRequest) Reset() {
+	*x = CommandRequest{}
+	mi := &file_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRequest) ProtoMessage() {}
+
+func (x *CommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
+func (*CommandRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CommandRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *CommandRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *CommandRequest) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *CommandRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *CommandRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *CommandRequest) GetSudo() bool {
+	if x != nil {
+		return x.Sudo
+	}
+	return false
+}
+
+func (x *CommandRequest) GetStdin() []byte {
+	if x != nil {
+		return x.Stdin
+	}
+	return nil
+}
+
+func (x *CommandRequest) GetSandbox() bool {
+	if x != nil {
+		return x.Sandbox
+	}
+	return false
+}
+
+type ScriptRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Script         string                 `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	Interpreter    string                 `protobuf:"bytes,2,opt,name=interpreter,proto3" json:"interpreter,omitempty"` // bash / sh / python / powershell
+	Args           []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`               // 传给脚本的参数
+	WorkingDir     string                 `protobuf:"bytes,4,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	Env            map[string]string      `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TimeoutSeconds int32                  `protobuf:"varint,6,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	Stdin          []byte                 `protobuf:"bytes,7,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	Sandbox        bool                   `protobuf:"varint,8,opt,name=sandbox,proto3" json:"sandbox,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ScriptRequest) Reset() {
+	*x = ScriptRequest{}
+	mi := &file_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptRequest) ProtoMessage() {}
+
+func (x *ScriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptRequest.ProtoReflect.Descriptor instead.
+func (*ScriptRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ScriptRequest) GetScript() string {
+	if x != nil {
+		return x.Script
+	}
+	return ""
+}
+
+func (x *ScriptRequest) GetInterpreter() string {
+	if x != nil {
+		return x.Interpreter
+	}
+	return ""
+}
+
+func (x *ScriptRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ScriptRequest) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *ScriptRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ScriptRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *ScriptRequest) GetStdin() []byte {
+	if x != nil {
+		return x.Stdin
+	}
+	return nil
+}
+
+func (x *ScriptRequest) GetSandbox() bool {
+	if x != nil {
+		return x.Sandbox
+	}
+	return false
+}
+
+type CommandResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stdout        string                 `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        string                 `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandResponse) Reset() {
+	*x = CommandResponse{}
+	mi := &file_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandResponse) ProtoMessage() {}
+
+func (x *CommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandResponse.ProtoReflect.Descriptor instead.
+func (*CommandResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CommandResponse) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *CommandResponse) GetStdout() string {
+	if x != nil {
+		return x.Stdout
+	}
+	return ""
+}
+
+func (x *CommandResponse) GetStderr() string {
+	if x != nil {
+		return x.Stderr
+	}
+	return ""
+}
+
+func (x *CommandResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type BatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*CommandRequest      `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`                  // 最多 100 条，timeout_seconds 为单条命令的超时（默认 60）
+	Concurrency   int32                  `protobuf:"varint,2,opt,name=concurrency,proto3" json:"concurrency,omitempty"`           // 同时执行的命令数，默认 4，最大 32
+	FailFast      bool                   `protobuf:"varint,3,opt,name=fail_fast,json=failFast,proto3" json:"fail_fast,omitempty"` // 任一命令未成功时终止其余命令；否则出错后继续执行
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	mi := &file_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRequest) ProtoMessage() {}
+
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BatchRequest) GetCommands() []*CommandRequest {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+func (x *BatchRequest) GetConcurrency() int32 {
+	if x != nil {
+		return x.Concurrency
+	}
+	return 0
+}
+
+func (x *BatchRequest) GetFailFast() bool {
+	if x != nil {
+		return x.FailFast
+	}
+	return false
+}
+
+type BatchCommandResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // succeeded / failed / error / cancelled / skipped
+	ExitCode      int32                  `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stdout        string                 `protobuf:"bytes,4,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        string                 `protobuf:"bytes,5,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"` // status 为 error 时的错误信息
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCommandResult) Reset() {
+	*x = BatchCommandResult{}
+	mi := &file_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCommandResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCommandResult) ProtoMessage() {}
+
+func (x *BatchCommandResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCommandResult.ProtoReflect.Descriptor instead.
+func (*BatchCommandResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *BatchCommandResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchCommandResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BatchCommandResult) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *BatchCommandResult) GetStdout() string {
+	if x != nil {
+		return x.Stdout
+	}
+	return ""
+}
+
+func (x *BatchCommandResult) GetStderr() string {
+	if x != nil {
+		return x.Stderr
+	}
+	return ""
+}
+
+func (x *BatchCommandResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *BatchCommandResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchCommandResult  `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"` // 全部命令都成功
+	DurationMs    int64                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResponse) Reset() {
+	*x = BatchResponse{}
+	mi := &file_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResponse) ProtoMessage() {}
+
+func (x *BatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResponse.ProtoReflect.Descriptor instead.
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *BatchResponse) GetResults() []*BatchCommandResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BatchResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type CommandOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Output:
+	//
+	//	*CommandOutput_Stdout
+	//	*CommandOutput_Stderr
+	//	*CommandOutput_Exit
+	Output        isCommandOutput_Output `protobuf_oneof:"output"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandOutput) Reset() {
+	*x = CommandOutput{}
+	mi := &file_agent_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandOutput) ProtoMessage() {}
+
+func (x *CommandOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandOutput.ProtoReflect.Descriptor instead.
+func (*CommandOutput) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CommandOutput) GetOutput() isCommandOutput_Output {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *CommandOutput) GetStdout() []byte {
+	if x != nil {
+		if x, ok := x.Output.(*CommandOutput_Stdout); ok {
+			return x.Stdout
+		}
+	}
+	return nil
+}
+
+func (x *CommandOutput) GetStderr() []byte {
+	if x != nil {
+		if x, ok := x.Output.(*CommandOutput_Stderr); ok {
+			return x.Stderr
+		}
+	}
+	return nil
+}
+
+func (x *CommandOutput) GetExit() *CommandExit {
+	if x != nil {
+		if x, ok := x.Output.(*CommandOutput_Exit); ok {
+			return x.Exit
+		}
+	}
+	return nil
+}
+
+type isCommandOutput_Output interface {
+	isCommandOutput_Output()
+}
+
+type CommandOutput_Stdout struct {
+	Stdout []byte `protobuf:"bytes,1,opt,name=stdout,proto3,oneof"`
+}
+
+type CommandOutput_Stderr struct {
+	Stderr []byte `protobuf:"bytes,2,opt,name=stderr,proto3,oneof"`
+}
+
+type CommandOutput_Exit struct {
+	Exit *CommandExit `protobuf:"bytes,3,opt,name=exit,proto3,oneof"`
+}
+
+func (*CommandOutput_Stdout) isCommandOutput_Output() {}
+
+func (*CommandOutput_Stderr) isCommandOutput_Output() {}
+
+func (*CommandOutput_Exit) isCommandOutput_Output() {}
+
+type CommandExit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // 安全检查失败、超时等说明
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandExit) Reset() {
+	*x = CommandExit{}
+	mi := &file_agent_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandExit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandExit) ProtoMessage() {}
+
+func (x *CommandExit) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandExit.ProtoReflect.Descriptor instead.
+func (*CommandExit) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CommandExit) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *CommandExit) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *CommandExit) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ExecHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         int64                  `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"` // Unix 时间戳，0 表示不限制
+	Until         int64                  `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	TokenId       string                 `protobuf:"bytes,3,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	Command       string                 `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"` // 匹配命令或其文件名
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`    // 默认 100，最大 1000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecHistoryRequest) Reset() {
+	*x = ExecHistoryRequest{}
+	mi := &file_agent_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecHistoryRequest) ProtoMessage() {}
+
+func (x *ExecHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ExecHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ExecHistoryRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *ExecHistoryRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *ExecHistoryRequest) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *ExecHistoryRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *ExecHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ExecRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix 毫秒
+	ClientIp      string                 `protobuf:"bytes,2,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	TokenId       string                 `protobuf:"bytes,3,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"` // execute_command / execute_stream / execute_script / execute_batch
+	Command       string                 `protobuf:"bytes,5,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,6,rep,name=args,proto3" json:"args,omitempty"`
+	WorkingDir    string                 `protobuf:"bytes,7,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,8,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,9,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	OutputSha256  string                 `protobuf:"bytes,10,opt,name=output_sha256,json=outputSha256,proto3" json:"output_sha256,omitempty"` // stdout 与 stderr 拼接后的 SHA-256，流式执行时按输出到达的顺序
+	OutputSize    int64                  `protobuf:"varint,11,opt,name=output_size,json=outputSize,proto3" json:"output_size,omitempty"`
+	ScriptSha256  string                 `protobuf:"bytes,12,opt,name=script_sha256,json=scriptSha256,proto3" json:"script_sha256,omitempty"`
+	Sandbox       bool                   `protobuf:"varint,13,opt,name=sandbox,proto3" json:"sandbox,omitempty"`
+	Error         string                 `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRecord) Reset() {
+	*x = ExecRecord{}
+	mi := &file_agent_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRecord) ProtoMessage() {}
+
+func (x *ExecRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecRecord.ProtoReflect.Descriptor instead.
+func (*ExecRecord) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ExecRecord) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ExecRecord) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ExecRecord) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecRecord) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ExecRecord) GetOutputSha256() string {
+	if x != nil {
+		return x.OutputSha256
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetOutputSize() int64 {
+	if x != nil {
+		return x.OutputSize
+	}
+	return 0
+}
+
+func (x *ExecRecord) GetScriptSha256() string {
+	if x != nil {
+		return x.ScriptSha256
+	}
+	return ""
+}
+
+func (x *ExecRecord) GetSandbox() bool {
+	if x != nil {
+		return x.Sandbox
+	}
+	return false
+}
+
+func (x *ExecRecord) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ExecHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*ExecRecord          `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	Truncated     bool                   `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"` // 还有更早的匹配记录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecHistory) Reset() {
+	*x = ExecHistory{}
+	mi := &file_agent_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecHistory) ProtoMessage() {}
+
+func (x *ExecHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecHistory.ProtoReflect.Descriptor instead.
+func (*ExecHistory) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ExecHistory) GetRecords() []*ExecRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *ExecHistory) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+// 第一条消息必须是 start
+type ShellInput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Input:
+	//
+	//	*ShellInput_Start
+	//	*ShellInput_Data
+	//	*ShellInput_Resize
+	//	*ShellInput_Signal
+	Input         isShellInput_Input `protobuf_oneof:"input"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShellInput) Reset() {
+	*x = ShellInput{}
+	mi := &file_agent_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShellInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellInput) ProtoMessage() {}
+
+func (x *ShellInput) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellInput.ProtoReflect.Descriptor instead.
+func (*ShellInput) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ShellInput) GetInput() isShellInput_Input {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *ShellInput) GetStart() *ShellStart {
+	if x != nil {
+		if x, ok := x.Input.(*ShellInput_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *ShellInput) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Input.(*ShellInput_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+func (x *ShellInput) GetResize() *ShellResize {
+	if x != nil {
+		if x, ok := x.Input.(*ShellInput_Resize); ok {
+			return x.Resize
+		}
+	}
+	return nil
+}
+
+func (x *ShellInput) GetSignal() string {
+	if x != nil {
+		if x, ok := x.Input.(*ShellInput_Signal); ok {
+			return x.Signal
+		}
+	}
+	return ""
+}
+
+type isShellInput_Input interface {
+	isShellInput_Input()
+}
+
+type ShellInput_Start struct {
+	Start *ShellStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type ShellInput_Data struct {
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+type ShellInput_Resize struct {
+	Resize *ShellResize `protobuf:"bytes,3,opt,name=resize,proto3,oneof"`
+}
+
+type ShellInput_Signal struct {
+	Signal string `protobuf:"bytes,4,opt,name=signal,proto3,oneof"` // 发送给前台进程组的信号，如 SIGINT
+}
+
+func (*ShellInput_Start) isShellInput_Input() {}
+
+func (*ShellInput_Data) isShellInput_Input() {}
+
+func (*ShellInput_Resize) isShellInput_Input() {}
+
+func (*ShellInput_Signal) isShellInput_Input() {}
+
+type ShellStart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shell         string                 `protobuf:"bytes,1,opt,name=shell,proto3" json:"shell,omitempty"` // 为空时使用允许列表中第一个存在的 shell
+	Rows          int32                  `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          int32                  `protobuf:"varint,3,opt,name=cols,proto3" json:"cols,omitempty"`
+	Env           map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	WorkingDir    string                 `protobuf:"bytes,5,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShellStart) Reset() {
+	*x = ShellStart{}
+	mi := &file_agent_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShellStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellStart) ProtoMessage() {}
+
+func (x *ShellStart) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellStart.ProtoReflect.Descriptor instead.
+func (*ShellStart) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ShellStart) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *ShellStart) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *ShellStart) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *ShellStart) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ShellStart) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+type ShellResize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	Rows          int32                  `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
 	Cols          int32                  `protobuf:"varint,2,opt,name=cols,proto3" json:"cols,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShellResize) Reset() {
-	*x = ShellResize{}
-	mi := &file_agent_proto_msgTypes[17]
+func (x *ShellResize) Reset() {
+	*x = ShellResize{}
+	mi := &file_agent_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShellResize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellResize) ProtoMessage() {}
+
+func (x *ShellResize) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellResize.ProtoReflect.Descriptor instead.
+func (*ShellResize) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ShellResize) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *ShellResize) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+// 第一条消息只携带 session_id，最后一条消息携带 exit
+type ShellOutput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Exit          *ShellExit             `protobuf:"bytes,3,opt,name=exit,proto3" json:"exit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShellOutput) Reset() {
+	*x = ShellOutput{}
+	mi := &file_agent_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShellOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellOutput) ProtoMessage() {}
+
+func (x *ShellOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellOutput.ProtoReflect.Descriptor instead.
+func (*ShellOutput) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ShellOutput) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ShellOutput) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ShellOutput) GetExit() *ShellExit {
+	if x != nil {
+		return x.Exit
+	}
+	return nil
+}
+
+type ShellExit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // exited / idle_timeout / client_closed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShellExit) Reset() {
+	*x = ShellExit{}
+	mi := &file_agent_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShellExit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellExit) ProtoMessage() {}
+
+func (x *ShellExit) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellExit.ProtoReflect.Descriptor instead.
+func (*ShellExit) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ShellExit) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ShellExit) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// 文件操作
+type FileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`                                 // DownloadFile：从该偏移继续下载；ReadFile：起始偏移，负数从末尾倒数
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`                                 // ReadFile：读取长度，0 表示读到末尾
+	Encoding      ContentEncoding        `protobuf:"varint,4,opt,name=encoding,proto3,enum=runixo.ContentEncoding" json:"encoding,omitempty"` // ReadFile：返回内容的编码
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileRequest) Reset() {
+	*x = FileRequest{}
+	mi := &file_agent_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRequest) ProtoMessage() {}
+
+func (x *FileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRequest.ProtoReflect.Descriptor instead.
+func (*FileRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *FileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *FileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FileRequest) GetEncoding() ContentEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return ContentEncoding_CONTENT_RAW
+}
+
+type FileContent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Content   []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Info      *FileInfo              `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`            // info.size 为文件总大小
+	Offset    int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`       // 实际起始偏移，下一页从 offset + length 开始
+	Truncated bool                   `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"` // 请求的范围超过读取上限被截断
+	Length    int64                  `protobuf:"varint,5,opt,name=length,proto3" json:"length,omitempty"`       // 本次返回内容对应的文件字节数
+	Text      string                 `protobuf:"bytes,6,opt,name=text,proto3" json:"text,omitempty"`            // CONTENT_BASE64 / CONTENT_UTF8 时的内容
+	Bom       bool                   `protobuf:"varint,7,opt,name=bom,proto3" json:"bom,omitempty"`             // 文件以 UTF-8 BOM 开头
+	Newline   string                 `protobuf:"bytes,8,opt,name=newline,proto3" json:"newline,omitempty"`      // lf / crlf / mixed，没有换行时为空
+	// 不是合法的 UTF-8 或包含 NUL；请求 CONTENT_UTF8 时改为在 content 中返回原始字节
+	Binary        bool `protobuf:"varint,9,opt,name=binary,proto3" json:"binary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileContent) Reset() {
+	*x = FileContent{}
+	mi := &file_agent_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileContent) ProtoMessage() {}
+
+func (x *FileContent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
+func (*FileContent) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *FileContent) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *FileContent) GetInfo() *FileInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *FileContent) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *FileContent) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *FileContent) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FileContent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *FileContent) GetBom() bool {
+	if x != nil {
+		return x.Bom
+	}
+	return false
+}
+
+func (x *FileContent) GetNewline() string {
+	if x != nil {
+		return x.Newline
+	}
+	return ""
+}
+
+func (x *FileContent) GetBinary() bool {
+	if x != nil {
+		return x.Binary
+	}
+	return false
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Mode          int64                  `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	ModTime       int64                  `protobuf:"varint,5,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"`
+	IsDir         bool                   `protobuf:"varint,6,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Owner         string                 `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+	Group         string                 `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_agent_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *FileInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetMode() int64 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *FileInfo) GetModTime() int64 {
+	if x != nil {
+		return x.ModTime
+	}
+	return 0
+}
+
+func (x *FileInfo) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+func (x *FileInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *FileInfo) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type WriteFileRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Path       string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Content    []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Mode       int64                  `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	CreateDirs bool                   `protobuf:"varint,4,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"`
+	// 原子写入：写入临时文件并 fsync 后重命名替换，保留原文件的权限和属主
+	Atomic     bool  `protobuf:"varint,5,opt,name=atomic,proto3" json:"atomic,omitempty"`
+	Backup     bool  `protobuf:"varint,6,opt,name=backup,proto3" json:"backup,omitempty"`                           // 保留原内容备份 <path>.<时间戳>.bak，隐含 atomic
+	MaxBackups int32 `protobuf:"varint,7,opt,name=max_backups,json=maxBackups,proto3" json:"max_backups,omitempty"` // 每个文件最多保留的备份数，默认 5
+	// 校验命令及参数，隐含 atomic，如 ["nginx", "-t"] 或 ["visudo", "-cf", "{file}"]
+	// 参数含 {file} 时在替换前校验临时文件，否则替换后校验并在失败时恢复原内容
+	Validate []string        `protobuf:"bytes,8,rep,name=validate,proto3" json:"validate,omitempty"`
+	Encoding ContentEncoding `protobuf:"varint,9,opt,name=encoding,proto3,enum=runixo.ContentEncoding" json:"encoding,omitempty"` // CONTENT_BASE64 / CONTENT_UTF8 时从 text 读取内容
+	Text     string          `protobuf:"bytes,10,opt,name=text,proto3" json:"text,omitempty"`
+	// CONTENT_UTF8：lf / crlf 转换换行；为空时沿用现有文件的风格（现有文件为 CRLF 时转换）
+	Newline       string `protobuf:"bytes,11,opt,name=newline,proto3" json:"newline,omitempty"`
+	Bom           bool   `protobuf:"varint,12,opt,name=bom,proto3" json:"bom,omitempty"` // CONTENT_UTF8：写入 BOM，现有文件带 BOM 时同样保留
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileRequest) Reset() {
+	*x = WriteFileRequest{}
+	mi := &file_agent_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileRequest) ProtoMessage() {}
+
+func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
+func (*WriteFileRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *WriteFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *WriteFileRequest) GetMode() int64 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *WriteFileRequest) GetCreateDirs() bool {
+	if x != nil {
+		return x.CreateDirs
+	}
+	return false
+}
+
+func (x *WriteFileRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+func (x *WriteFileRequest) GetBackup() bool {
+	if x != nil {
+		return x.Backup
+	}
+	return false
+}
+
+func (x *WriteFileRequest) GetMaxBackups() int32 {
+	if x != nil {
+		return x.MaxBackups
+	}
+	return 0
+}
+
+func (x *WriteFileRequest) GetValidate() []string {
+	if x != nil {
+		return x.Validate
+	}
+	return nil
+}
+
+func (x *WriteFileRequest) GetEncoding() ContentEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return ContentEncoding_CONTENT_RAW
+}
+
+func (x *WriteFileRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetNewline() string {
+	if x != nil {
+		return x.Newline
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetBom() bool {
+	if x != nil {
+		return x.Bom
+	}
+	return false
+}
+
+// 流式文件传输
+type FileChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*FileChunk_Start
+	//	*FileChunk_Chunk
+	//	*FileChunk_End
+	Data          isFileChunk_Data `protobuf_oneof:"data"`
+	Offset        int64            `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"` // chunk 在文件中的偏移，可用于计算进度；上传时非 0 则必须与当前位置一致
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	mi := &file_agent_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunk) ProtoMessage() {}
+
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *FileChunk) GetData() isFileChunk_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *FileChunk) GetStart() *FileUploadStart {
+	if x != nil {
+		if x, ok := x.Data.(*FileChunk_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *FileChunk) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*FileChunk_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *FileChunk) GetEnd() *FileUploadEnd {
+	if x != nil {
+		if x, ok := x.Data.(*FileChunk_End); ok {
+			return x.End
+		}
+	}
+	return nil
+}
+
+func (x *FileChunk) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type isFileChunk_Data interface {
+	isFileChunk_Data()
+}
+
+type FileChunk_Start struct {
+	Start *FileUploadStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"` // 开始上传，包含元信息
+}
+
+type FileChunk_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"` // 文件数据块
+}
+
+type FileChunk_End struct {
+	End *FileUploadEnd `protobuf:"bytes,3,opt,name=end,proto3,oneof"` // 结束上传
+}
+
+func (*FileChunk_Start) isFileChunk_Data() {}
+
+func (*FileChunk_Chunk) isFileChunk_Data() {}
+
+func (*FileChunk_End) isFileChunk_Data() {}
+
+type FileUploadStart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`                                // 目标路径
+	TotalSize     int64                  `protobuf:"varint,2,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`    // 文件总大小
+	Mode          int64                  `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"`                               // 文件权限
+	CreateDirs    bool                   `protobuf:"varint,4,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"` // 是否创建父目录
+	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"`                        // 可选：文件校验和 (sha256)
+	IsTarGz       bool                   `protobuf:"varint,6,opt,name=is_tar_gz,json=isTarGz,proto3" json:"is_tar_gz,omitempty"`        // 是否是 tar.gz 压缩包（需要解压）
+	ExtractTo     string                 `protobuf:"bytes,7,opt,name=extract_to,json=extractTo,proto3" json:"extract_to,omitempty"`     // 如果是压缩包，解压到此目录
+	Offset        int64                  `protobuf:"varint,8,opt,name=offset,proto3" json:"offset,omitempty"`                           // 续传：从该偏移继续写入（见 GetUploadOffset）；下载时为起始偏移
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileUploadStart) Reset() {
+	*x = FileUploadStart{}
+	mi := &file_agent_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileUploadStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileUploadStart) ProtoMessage() {}
+
+func (x *FileUploadStart) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileUploadStart.ProtoReflect.Descriptor instead.
+func (*FileUploadStart) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *FileUploadStart) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileUploadStart) GetTotalSize() int64 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+func (x *FileUploadStart) GetMode() int64 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *FileUploadStart) GetCreateDirs() bool {
+	if x != nil {
+		return x.CreateDirs
+	}
+	return false
+}
+
+func (x *FileUploadStart) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *FileUploadStart) GetIsTarGz() bool {
+	if x != nil {
+		return x.IsTarGz
+	}
+	return false
+}
+
+func (x *FileUploadStart) GetExtractTo() string {
+	if x != nil {
+		return x.ExtractTo
+	}
+	return ""
+}
+
+func (x *FileUploadStart) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type FileUploadEnd struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checksum      string                 `protobuf:"bytes,1,opt,name=checksum,proto3" json:"checksum,omitempty"` // 可选：最终校验和
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileUploadEnd) Reset() {
+	*x = FileUploadEnd{}
+	mi := &file_agent_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileUploadEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileUploadEnd) ProtoMessage() {}
+
+func (x *FileUploadEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileUploadEnd.ProtoReflect.Descriptor instead.
+func (*FileUploadEnd) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *FileUploadEnd) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type UploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	BytesWritten  int64                  `protobuf:"varint,4,opt,name=bytes_written,json=bytesWritten,proto3" json:"bytes_written,omitempty"` // 实际写入的字节数
+	Path          string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`                                      // 最终文件路径
+	Checksum      string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`                              // 整个文件的 sha256
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadResponse) Reset() {
+	*x = UploadResponse{}
+	mi := &file_agent_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadResponse) ProtoMessage() {}
+
+func (x *UploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadResponse.ProtoReflect.Descriptor instead.
+func (*UploadResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *UploadResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UploadResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UploadResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *UploadResponse) GetBytesWritten() int64 {
+	if x != nil {
+		return x.BytesWritten
+	}
+	return 0
+}
+
+func (x *UploadResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UploadResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type UploadOffset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"` // 未完成上传已写入的字节数，0 表示需要从头上传
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadOffset) Reset() {
+	*x = UploadOffset{}
+	mi := &file_agent_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadOffset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadOffset) ProtoMessage() {}
+
+func (x *UploadOffset) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadOffset.ProtoReflect.Descriptor instead.
+func (*UploadOffset) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *UploadOffset) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type HashFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Algorithm     string                 `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"` // md5 / sha1 / sha256（默认）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HashFileRequest) Reset() {
+	*x = HashFileRequest{}
+	mi := &file_agent_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HashFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashFileRequest) ProtoMessage() {}
+
+func (x *HashFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashFileRequest.ProtoReflect.Descriptor instead.
+func (*HashFileRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *HashFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HashFileRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+type FileHash struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Algorithm     string                 `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	Hash          string                 `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"` // 小写十六进制
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileHash) Reset() {
+	*x = FileHash{}
+	mi := &file_agent_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileHash) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileHash) ProtoMessage() {}
+
+func (x *FileHash) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileHash.ProtoReflect.Descriptor instead.
+func (*FileHash) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *FileHash) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileHash) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *FileHash) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *FileHash) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type CompareFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PathA         string                 `protobuf:"bytes,1,opt,name=path_a,json=pathA,proto3" json:"path_a,omitempty"`
+	PathB         string                 `protobuf:"bytes,2,opt,name=path_b,json=pathB,proto3" json:"path_b,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareFilesRequest) Reset() {
+	*x = CompareFilesRequest{}
+	mi := &file_agent_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareFilesRequest) ProtoMessage() {}
+
+func (x *CompareFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareFilesRequest.ProtoReflect.Descriptor instead.
+func (*CompareFilesRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *CompareFilesRequest) GetPathA() string {
+	if x != nil {
+		return x.PathA
+	}
+	return ""
+}
+
+func (x *CompareFilesRequest) GetPathB() string {
+	if x != nil {
+		return x.PathB
+	}
+	return ""
+}
+
+type FileComparison struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Equal           bool                   `protobuf:"varint,1,opt,name=equal,proto3" json:"equal,omitempty"`
+	SizeA           int64                  `protobuf:"varint,2,opt,name=size_a,json=sizeA,proto3" json:"size_a,omitempty"`
+	SizeB           int64                  `protobuf:"varint,3,opt,name=size_b,json=sizeB,proto3" json:"size_b,omitempty"`
+	FirstDifference int64                  `protobuf:"varint,4,opt,name=first_difference,json=firstDifference,proto3" json:"first_difference,omitempty"` // 第一个不同字节的偏移，相同时为 -1
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *FileComparison) Reset() {
+	*x = FileComparison{}
+	mi := &file_agent_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileComparison) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileComparison) ProtoMessage() {}
+
+func (x *FileComparison) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileComparison.ProtoReflect.Descriptor instead.
+func (*FileComparison) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *FileComparison) GetEqual() bool {
+	if x != nil {
+		return x.Equal
+	}
+	return false
+}
+
+func (x *FileComparison) GetSizeA() int64 {
+	if x != nil {
+		return x.SizeA
+	}
+	return 0
+}
+
+func (x *FileComparison) GetSizeB() int64 {
+	if x != nil {
+		return x.SizeB
+	}
+	return 0
+}
+
+func (x *FileComparison) GetFirstDifference() int64 {
+	if x != nil {
+		return x.FirstDifference
+	}
+	return 0
+}
+
+type SearchFilesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Root           string                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Pattern        string                 `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"` // 文件名通配符，如 *.conf
+	MinSize        int64                  `protobuf:"varint,3,opt,name=min_size,json=minSize,proto3" json:"min_size,omitempty"`
+	MaxSize        int64                  `protobuf:"varint,4,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`
+	ModifiedAfter  int64                  `protobuf:"varint,5,opt,name=modified_after,json=modifiedAfter,proto3" json:"modified_after,omitempty"` // Unix 时间戳
+	ModifiedBefore int64                  `protobuf:"varint,6,opt,name=modified_before,json=modifiedBefore,proto3" json:"modified_before,omitempty"`
+	Content        string                 `protobuf:"bytes,7,opt,name=content,proto3" json:"content,omitempty"`                          // 内容正则（RE2），设置后跳过二进制文件
+	MaxMatches     int32                  `protobuf:"varint,8,opt,name=max_matches,json=maxMatches,proto3" json:"max_matches,omitempty"` // 每个文件最多返回的匹配行，默认 10
+	MaxResults     int32                  `protobuf:"varint,9,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"` // 最多返回的文件数，默认 1000，上限 10000
+	MaxDepth       int32                  `protobuf:"varint,10,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	ShowHidden     bool                   `protobuf:"varint,11,opt,name=show_hidden,json=showHidden,proto3" json:"show_hidden,omitempty"`
+	TimeoutSeconds int32                  `protobuf:"varint,12,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // 默认 60
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SearchFilesRequest) Reset() {
+	*x = SearchFilesRequest{}
+	mi := &file_agent_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilesRequest) ProtoMessage() {}
+
+func (x *SearchFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilesRequest.ProtoReflect.Descriptor instead.
+func (*SearchFilesRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *SearchFilesRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *SearchFilesRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *SearchFilesRequest) GetMinSize() int64 {
+	if x != nil {
+		return x.MinSize
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetMaxSize() int64 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetModifiedAfter() int64 {
+	if x != nil {
+		return x.ModifiedAfter
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetModifiedBefore() int64 {
+	if x != nil {
+		return x.ModifiedBefore
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SearchFilesRequest) GetMaxMatches() int32 {
+	if x != nil {
+		return x.MaxMatches
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetShowHidden() bool {
+	if x != nil {
+		return x.ShowHidden
+	}
+	return false
+}
+
+func (x *SearchFilesRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type SearchMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          int32                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchMatch) Reset() {
+	*x = SearchMatch{}
+	mi := &file_agent_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMatch) ProtoMessage() {}
+
+func (x *SearchMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMatch.ProtoReflect.Descriptor instead.
+func (*SearchMatch) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *SearchMatch) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *SearchMatch) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Matches       []*SearchMatch         `protobuf:"bytes,2,rep,name=matches,proto3" json:"matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_agent_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *SearchResult) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *SearchResult) GetMatches() []*SearchMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+type SearchFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Truncated     bool                   `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"` // 结果达到上限或超时，未搜索完
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFilesResponse) Reset() {
+	*x = SearchFilesResponse{}
+	mi := &file_agent_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilesResponse) ProtoMessage() {}
+
+func (x *SearchFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilesResponse.ProtoReflect.Descriptor instead.
+func (*SearchFilesResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *SearchFilesResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchFilesResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type CreateArchiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paths         []string               `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`   // 要打包的文件或目录，以基本名作为包内顶层条目
+	Dest          string                 `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`     // 生成的压缩包路径
+	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"` // tar.gz 或 zip，为空时按 dest 扩展名判断
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateArchiveRequest) Reset() {
+	*x = CreateArchiveRequest{}
+	mi := &file_agent_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateArchiveRequest) ProtoMessage() {}
+
+func (x *CreateArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateArchiveRequest.ProtoReflect.Descriptor instead.
+func (*CreateArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *CreateArchiveRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *CreateArchiveRequest) GetDest() string {
+	if x != nil {
+		return x.Dest
+	}
+	return ""
+}
+
+func (x *CreateArchiveRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type ExtractArchiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Archive       string                 `protobuf:"bytes,1,opt,name=archive,proto3" json:"archive,omitempty"` // tar.gz、tar 或 zip，按文件内容识别
+	Dest          string                 `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`       // 解压目录，不存在时创建
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractArchiveRequest) Reset() {
+	*x = ExtractArchiveRequest{}
+	mi := &file_agent_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractArchiveRequest) ProtoMessage() {}
+
+func (x *ExtractArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractArchiveRequest.ProtoReflect.Descriptor instead.
+func (*ExtractArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *ExtractArchiveRequest) GetArchive() string {
+	if x != nil {
+		return x.Archive
+	}
+	return ""
+}
+
+func (x *ExtractArchiveRequest) GetDest() string {
+	if x != nil {
+		return x.Dest
+	}
+	return ""
+}
+
+type ArchiveProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         int64                  `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`
+	Bytes         int64                  `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`                             // 已处理的未压缩字节数
+	TotalBytes    int64                  `protobuf:"varint,3,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"` // 未知时为 0
+	Current       string                 `protobuf:"bytes,4,opt,name=current,proto3" json:"current,omitempty"`                          // 当前条目
+	Done          bool                   `protobuf:"varint,5,opt,name=done,proto3" json:"done,omitempty"`
+	Path          string                 `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"` // done 时为压缩包或解压目录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveProgress) Reset() {
+	*x = ArchiveProgress{}
+	mi := &file_agent_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveProgress) ProtoMessage() {}
+
+func (x *ArchiveProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveProgress.ProtoReflect.Descriptor instead.
+func (*ArchiveProgress) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ArchiveProgress) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *ArchiveProgress) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *ArchiveProgress) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *ArchiveProgress) GetCurrent() string {
+	if x != nil {
+		return x.Current
+	}
+	return ""
+}
+
+func (x *ArchiveProgress) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *ArchiveProgress) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ChmodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"` // 八进制（755）或符号模式（u+x,go-w,a=rX）
+	Recursive     bool                   `protobuf:"varint,3,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChmodRequest) Reset() {
+	*x = ChmodRequest{}
+	mi := &file_agent_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChmodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChmodRequest) ProtoMessage() {}
+
+func (x *ChmodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChmodRequest.ProtoReflect.Descriptor instead.
+func (*ChmodRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ChmodRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ChmodRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ChmodRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *ChmodRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ChownRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"` // user、user:group 或 :group，支持名称和数字 ID
+	Recursive     bool                   `protobuf:"varint,3,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChownRequest) Reset() {
+	*x = ChownRequest{}
+	mi := &file_agent_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChownRequest) ProtoMessage() {}
+
+func (x *ChownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChownRequest.ProtoReflect.Descriptor instead.
+func (*ChownRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *ChownRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ChownRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ChownRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *ChownRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SetACLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Entries       []string               `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"` // setfacl 条目，如 u:www-data:rwx、d:g:dev:rX
+	Remove        bool                   `protobuf:"varint,3,opt,name=remove,proto3" json:"remove,omitempty"`  // 删除条目（setfacl -x）
+	Recursive     bool                   `protobuf:"varint,4,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetACLRequest) Reset() {
+	*x = SetACLRequest{}
+	mi := &file_agent_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetACLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetACLRequest) ProtoMessage() {}
+
+func (x *SetACLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetACLRequest.ProtoReflect.Descriptor instead.
+func (*SetACLRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *SetACLRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetACLRequest) GetEntries() []string {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *SetACLRequest) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+func (x *SetACLRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *SetACLRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type PermissionChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Old           string                 `protobuf:"bytes,2,opt,name=old,proto3" json:"old,omitempty"` // 八进制模式、uid:gid 或当前 ACL
+	New           string                 `protobuf:"bytes,3,opt,name=new,proto3" json:"new,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionChange) Reset() {
+	*x = PermissionChange{}
+	mi := &file_agent_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionChange) ProtoMessage() {}
+
+func (x *PermissionChange) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionChange.ProtoReflect.Descriptor instead.
+func (*PermissionChange) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *PermissionChange) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PermissionChange) GetOld() string {
+	if x != nil {
+		return x.Old
+	}
+	return ""
+}
+
+func (x *PermissionChange) GetNew() string {
+	if x != nil {
+		return x.New
+	}
+	return ""
+}
+
+type PermissionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*PermissionChange    `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"` // 最多 1000 条
+	Changed       int32                  `protobuf:"varint,2,opt,name=changed,proto3" json:"changed,omitempty"`
+	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"` // 递归时单个路径的失败
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionResult) Reset() {
+	*x = PermissionResult{}
+	mi := &file_agent_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionResult) ProtoMessage() {}
+
+func (x *PermissionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionResult.ProtoReflect.Descriptor instead.
+func (*PermissionResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *PermissionResult) GetChanges() []*PermissionChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *PermissionResult) GetChanged() int32 {
+	if x != nil {
+		return x.Changed
+	}
+	return 0
+}
+
+func (x *PermissionResult) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *PermissionResult) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CopyPathRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Src           string                 `protobuf:"bytes,1,opt,name=src,proto3" json:"src,omitempty"`
+	Dst           string                 `protobuf:"bytes,2,opt,name=dst,proto3" json:"dst,omitempty"`              // 复制后的完整路径，不是所在目录
+	Recursive     bool                   `protobuf:"varint,3,opt,name=recursive,proto3" json:"recursive,omitempty"` // 复制目录时必须设置，目标目录已存在时合并
+	Overwrite     OverwritePolicy        `protobuf:"varint,4,opt,name=overwrite,proto3,enum=runixo.OverwritePolicy" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyPathRequest) Reset() {
+	*x = CopyPathRequest{}
+	mi := &file_agent_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyPathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyPathRequest) ProtoMessage() {}
+
+func (x *CopyPathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyPathRequest.ProtoReflect.Descriptor instead.
+func (*CopyPathRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *CopyPathRequest) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *CopyPathRequest) GetDst() string {
+	if x != nil {
+		return x.Dst
+	}
+	return ""
+}
+
+func (x *CopyPathRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *CopyPathRequest) GetOverwrite() OverwritePolicy {
+	if x != nil {
+		return x.Overwrite
+	}
+	return OverwritePolicy_OVERWRITE_FAIL
+}
+
+type MovePathRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Src           string                 `protobuf:"bytes,1,opt,name=src,proto3" json:"src,omitempty"`
+	Dst           string                 `protobuf:"bytes,2,opt,name=dst,proto3" json:"dst,omitempty"` // 跨设备时自动复制后删除
+	Overwrite     OverwritePolicy        `protobuf:"varint,3,opt,name=overwrite,proto3,enum=runixo.OverwritePolicy" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MovePathRequest) Reset() {
+	*x = MovePathRequest{}
+	mi := &file_agent_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MovePathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MovePathRequest) ProtoMessage() {}
+
+func (x *MovePathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MovePathRequest.ProtoReflect.Descriptor instead.
+func (*MovePathRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *MovePathRequest) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *MovePathRequest) GetDst() string {
+	if x != nil {
+		return x.Dst
+	}
+	return ""
+}
+
+func (x *MovePathRequest) GetOverwrite() OverwritePolicy {
+	if x != nil {
+		return x.Overwrite
+	}
+	return OverwritePolicy_OVERWRITE_FAIL
+}
+
+type DeletePathRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"` // 删除非空目录时必须设置
+	Trash         bool                   `protobuf:"varint,3,opt,name=trash,proto3" json:"trash,omitempty"`         // 移入回收站，可通过 RestoreTrash 恢复
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePathRequest) Reset() {
+	*x = DeletePathRequest{}
+	mi := &file_agent_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePathRequest) ProtoMessage() {}
+
+func (x *DeletePathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePathRequest.ProtoReflect.Descriptor instead.
+func (*DeletePathRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *DeletePathRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DeletePathRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *DeletePathRequest) GetTrash() bool {
+	if x != nil {
+		return x.Trash
+	}
+	return false
+}
+
+type FileOpResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Files         int64                  `protobuf:"varint,2,opt,name=files,proto3" json:"files,omitempty"`
+	Bytes         int64                  `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Skipped       int64                  `protobuf:"varint,4,opt,name=skipped,proto3" json:"skipped,omitempty"` // 因覆盖策略跳过，移动时有跳过则保留源路径
+	TrashId       string                 `protobuf:"bytes,5,opt,name=trash_id,json=trashId,proto3" json:"trash_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileOpResult) Reset() {
+	*x = FileOpResult{}
+	mi := &file_agent_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileOpResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileOpResult) ProtoMessage() {}
+
+func (x *FileOpResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileOpResult.ProtoReflect.Descriptor instead.
+func (*FileOpResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *FileOpResult) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileOpResult) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *FileOpResult) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *FileOpResult) GetSkipped() int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *FileOpResult) GetTrashId() string {
+	if x != nil {
+		return x.TrashId
+	}
+	return ""
+}
+
+type TrashEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OriginalPath  string                 `protobuf:"bytes,2,opt,name=original_path,json=originalPath,proto3" json:"original_path,omitempty"`
+	DeletedAt     int64                  `protobuf:"varint,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	IsDir         bool                   `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrashEntry) Reset() {
+	*x = TrashEntry{}
+	mi := &file_agent_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrashEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrashEntry) ProtoMessage() {}
+
+func (x *TrashEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrashEntry.ProtoReflect.Descriptor instead.
+func (*TrashEntry) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *TrashEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TrashEntry) GetOriginalPath() string {
+	if x != nil {
+		return x.OriginalPath
+	}
+	return ""
+}
+
+func (x *TrashEntry) GetDeletedAt() int64 {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return 0
+}
+
+func (x *TrashEntry) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+type TrashList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*TrashEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrashList) Reset() {
+	*x = TrashList{}
+	mi := &file_agent_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrashList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrashList) ProtoMessage() {}
+
+func (x *TrashList) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrashList.ProtoReflect.Descriptor instead.
+func (*TrashList) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *TrashList) GetEntries() []*TrashEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type RestoreTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreTrashRequest) Reset() {
+	*x = RestoreTrashRequest{}
+	mi := &file_agent_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreTrashRequest) ProtoMessage() {}
+
+func (x *RestoreTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreTrashRequest.ProtoReflect.Descriptor instead.
+func (*RestoreTrashRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *RestoreTrashRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DirectorySizeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Path           string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Depth          int32                  `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`                                         // 明细层数，默认 1，上限 5
+	Top            int32                  `protobuf:"varint,3,opt,name=top,proto3" json:"top,omitempty"`                                             // 每层最多返回的子目录数，默认 20
+	MaxEntries     int64                  `protobuf:"varint,4,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`             // 最多遍历的条目数，默认 1000000
+	CrossMounts    bool                   `protobuf:"varint,5,opt,name=cross_mounts,json=crossMounts,proto3" json:"cross_mounts,omitempty"`          // 进入其他文件系统的挂载点
+	TimeoutSeconds int32                  `protobuf:"varint,6,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // 默认 60
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DirectorySizeRequest) Reset() {
+	*x = DirectorySizeRequest{}
+	mi := &file_agent_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectorySizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectorySizeRequest) ProtoMessage() {}
+
+func (x *DirectorySizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectorySizeRequest.ProtoReflect.Descriptor instead.
+func (*DirectorySizeRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *DirectorySizeRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirectorySizeRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *DirectorySizeRequest) GetTop() int32 {
+	if x != nil {
+		return x.Top
+	}
+	return 0
+}
+
+func (x *DirectorySizeRequest) GetMaxEntries() int64 {
+	if x != nil {
+		return x.MaxEntries
+	}
+	return 0
+}
+
+func (x *DirectorySizeRequest) GetCrossMounts() bool {
+	if x != nil {
+		return x.CrossMounts
+	}
+	return false
+}
+
+func (x *DirectorySizeRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type DirectorySize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"` // 文件大小之和
+	Disk          int64                  `protobuf:"varint,3,opt,name=disk,proto3" json:"disk,omitempty"` // 实际占用的磁盘空间
+	Files         int64                  `protobuf:"varint,4,opt,name=files,proto3" json:"files,omitempty"`
+	Dirs          int64                  `protobuf:"varint,5,opt,name=dirs,proto3" json:"dirs,omitempty"`
+	Children      []*DirectorySize       `protobuf:"bytes,6,rep,name=children,proto3" json:"children,omitempty"` // 按 disk 降序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectorySize) Reset() {
+	*x = DirectorySize{}
+	mi := &file_agent_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectorySize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectorySize) ProtoMessage() {}
+
+func (x *DirectorySize) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectorySize.ProtoReflect.Descriptor instead.
+func (*DirectorySize) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *DirectorySize) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirectorySize) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *DirectorySize) GetDisk() int64 {
+	if x != nil {
+		return x.Disk
+	}
+	return 0
+}
+
+func (x *DirectorySize) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *DirectorySize) GetDirs() int64 {
+	if x != nil {
+		return x.Dirs
+	}
+	return 0
+}
+
+func (x *DirectorySize) GetChildren() []*DirectorySize {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type DirectorySizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          *DirectorySize         `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Truncated     bool                   `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"` // 超时或达到 max_entries，结果不完整
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectorySizeResponse) Reset() {
+	*x = DirectorySizeResponse{}
+	mi := &file_agent_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectorySizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectorySizeResponse) ProtoMessage() {}
+
+func (x *DirectorySizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectorySizeResponse.ProtoReflect.Descriptor instead.
+func (*DirectorySizeResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *DirectorySizeResponse) GetRoot() *DirectorySize {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *DirectorySizeResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type DirRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	ShowHidden    bool                   `protobuf:"varint,3,opt,name=show_hidden,json=showHidden,proto3" json:"show_hidden,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirRequest) Reset() {
+	*x = DirRequest{}
+	mi := &file_agent_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirRequest) ProtoMessage() {}
+
+func (x *DirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirRequest.ProtoReflect.Descriptor instead.
+func (*DirRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *DirRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *DirRequest) GetShowHidden() bool {
+	if x != nil {
+		return x.ShowHidden
+	}
+	return false
+}
+
+type DirContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Files         []*FileInfo            `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirContent) Reset() {
+	*x = DirContent{}
+	mi := &file_agent_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirContent) ProtoMessage() {}
+
+func (x *DirContent) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirContent.ProtoReflect.Descriptor instead.
+func (*DirContent) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *DirContent) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirContent) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// 日志
+type LogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Lines int32                  `protobuf:"varint,2,opt,name=lines,proto3" json:"lines,omitempty"`
+	// 持续输出新增内容，文件轮转或截断后自动重新打开
+	Follow bool `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	// 只返回匹配该正则的行（RE2 语法）
+	Filter        string `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_agent_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *LogRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *LogRequest) GetLines() int32 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
+}
+
+func (x *LogRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *LogRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+type LogLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_agent_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLine) ProtoMessage() {}
+
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *LogLine) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *LogLine) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// 服务管理
+type ServiceFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NameFilter    string                 `protobuf:"bytes,1,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
+	StatusFilter  string                 `protobuf:"bytes,2,opt,name=status_filter,json=statusFilter,proto3" json:"status_filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceFilter) Reset() {
+	*x = ServiceFilter{}
+	mi := &file_agent_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceFilter) ProtoMessage() {}
+
+func (x *ServiceFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceFilter.ProtoReflect.Descriptor instead.
+func (*ServiceFilter) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *ServiceFilter) GetNameFilter() string {
+	if x != nil {
+		return x.NameFilter
+	}
+	return ""
+}
+
+func (x *ServiceFilter) GetStatusFilter() string {
+	if x != nil {
+		return x.StatusFilter
+	}
+	return ""
+}
+
+type ServiceList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []*ServiceInfo         `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceList) Reset() {
+	*x = ServiceList{}
+	mi := &file_agent_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceList) ProtoMessage() {}
+
+func (x *ServiceList) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceList.ProtoReflect.Descriptor instead.
+func (*ServiceList) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *ServiceList) GetServices() []*ServiceInfo {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+type ServiceInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Pid           int32                  `protobuf:"varint,5,opt,name=pid,proto3" json:"pid,omitempty"`
+	Uptime        int64                  `protobuf:"varint,6,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceInfo) Reset() {
+	*x = ServiceInfo{}
+	mi := &file_agent_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceInfo) ProtoMessage() {}
+
+func (x *ServiceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceInfo.ProtoReflect.Descriptor instead.
+func (*ServiceInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *ServiceInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServiceInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ServiceInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ServiceInfo) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ServiceInfo) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ServiceInfo) GetUptime() int64 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+type ServiceActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Action        ServiceAction          `protobuf:"varint,2,opt,name=action,proto3,enum=runixo.ServiceAction" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceActionRequest) Reset() {
+	*x = ServiceActionRequest{}
+	mi := &file_agent_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceActionRequest) ProtoMessage() {}
+
+func (x *ServiceActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceActionRequest.ProtoReflect.Descriptor instead.
+func (*ServiceActionRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *ServiceActionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServiceActionRequest) GetAction() ServiceAction {
+	if x != nil {
+		return x.Action
+	}
+	return ServiceAction_SERVICE_START
+}
+
+// 进程管理
+type ProcessFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NameFilter    string                 `protobuf:"bytes,1,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
+	UserFilter    string                 `protobuf:"bytes,2,opt,name=user_filter,json=userFilter,proto3" json:"user_filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessFilter) Reset() {
+	*x = ProcessFilter{}
+	mi := &file_agent_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessFilter) ProtoMessage() {}
+
+func (x *ProcessFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessFilter.ProtoReflect.Descriptor instead.
+func (*ProcessFilter) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *ProcessFilter) GetNameFilter() string {
+	if x != nil {
+		return x.NameFilter
+	}
+	return ""
+}
+
+func (x *ProcessFilter) GetUserFilter() string {
+	if x != nil {
+		return x.UserFilter
+	}
+	return ""
+}
+
+type ProcessList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Processes     []*ProcessInfo         `protobuf:"bytes,1,rep,name=processes,proto3" json:"processes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessList) Reset() {
+	*x = ProcessList{}
+	mi := &file_agent_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessList) ProtoMessage() {}
+
+func (x *ProcessList) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessList.ProtoReflect.Descriptor instead.
+func (*ProcessList) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *ProcessList) GetProcesses() []*ProcessInfo {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+type ProcessInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Ppid          int32                  `protobuf:"varint,2,opt,name=ppid,proto3" json:"ppid,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	User          string                 `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	CpuPercent    float64                `protobuf:"fixed64,6,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	MemoryPercent float64                `protobuf:"fixed64,7,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
+	MemoryRss     uint64                 `protobuf:"varint,8,opt,name=memory_rss,json=memoryRss,proto3" json:"memory_rss,omitempty"`
+	CreateTime    int64                  `protobuf:"varint,9,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	Cmdline       string                 `protobuf:"bytes,10,opt,name=cmdline,proto3" json:"cmdline,omitempty"`
+	NumThreads    int32                  `protobuf:"varint,11,opt,name=num_threads,json=numThreads,proto3" json:"num_threads,omitempty"`
+	NumFds        int32                  `protobuf:"varint,12,opt,name=num_fds,json=numFds,proto3" json:"num_fds,omitempty"`
+	IoReadBytes   uint64                 `protobuf:"varint,13,opt,name=io_read_bytes,json=ioReadBytes,proto3" json:"io_read_bytes,omitempty"`
+	IoWriteBytes  uint64                 `protobuf:"varint,14,opt,name=io_write_bytes,json=ioWriteBytes,proto3" json:"io_write_bytes,omitempty"`
+	IoReadRate    float64                `protobuf:"fixed64,15,opt,name=io_read_rate,json=ioReadRate,proto3" json:"io_read_rate,omitempty"`    // 字节/秒
+	IoWriteRate   float64                `protobuf:"fixed64,16,opt,name=io_write_rate,json=ioWriteRate,proto3" json:"io_write_rate,omitempty"` // 字节/秒
+	FdLimit       uint64                 `protobuf:"varint,17,opt,name=fd_limit,json=fdLimit,proto3" json:"fd_limit,omitempty"`                // RLIMIT_NOFILE 软限制，未知或无限制时为 0
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessInfo) Reset() {
+	*x = ProcessInfo{}
+	mi := &file_agent_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessInfo) ProtoMessage() {}
+
+func (x *ProcessInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessInfo.ProtoReflect.Descriptor instead.
+func (*ProcessInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *ProcessInfo) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetPpid() int32 {
+	if x != nil {
+		return x.Ppid
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProcessInfo) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ProcessInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProcessInfo) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetMemoryPercent() float64 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetMemoryRss() uint64 {
+	if x != nil {
+		return x.MemoryRss
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetCmdline() string {
+	if x != nil {
+		return x.Cmdline
+	}
+	return ""
+}
+
+func (x *ProcessInfo) GetNumThreads() int32 {
+	if x != nil {
+		return x.NumThreads
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetNumFds() int32 {
+	if x != nil {
+		return x.NumFds
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetIoReadBytes() uint64 {
+	if x != nil {
+		return x.IoReadBytes
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetIoWriteBytes() uint64 {
+	if x != nil {
+		return x.IoWriteBytes
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetIoReadRate() float64 {
+	if x != nil {
+		return x.IoReadRate
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetIoWriteRate() float64 {
+	if x != nil {
+		return x.IoWriteRate
+	}
+	return 0
+}
+
+func (x *ProcessInfo) GetFdLimit() uint64 {
+	if x != nil {
+		return x.FdLimit
+	}
+	return 0
+}
+
+type ProcessTreeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Aggregate     bool                   `protobuf:"varint,1,opt,name=aggregate,proto3" json:"aggregate,omitempty"` // 累加子孙进程的 CPU / 内存
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessTreeRequest) Reset() {
+	*x = ProcessTreeRequest{}
+	mi := &file_agent_proto_msgTypes[91]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShellResize) String() string {
+func (x *ProcessTreeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShellResize) ProtoMessage() {}
+func (*ProcessTreeRequest) ProtoMessage() {}
 
-func (x *ShellResize) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[17]
+func (x *ProcessTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[91]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1550,47 +7393,125 @@ func (x *ShellResize) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShellResize.ProtoReflect.Descriptor instead.
-func (*ShellResize) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use ProcessTreeRequest.ProtoReflect.Descriptor instead.
+func (*ProcessTreeRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{91}
 }
 
-func (x *ShellResize) GetRows() int32 {
+func (x *ProcessTreeRequest) GetAggregate() bool {
 	if x != nil {
-		return x.Rows
+		return x.Aggregate
+	}
+	return false
+}
+
+type ProcessNode struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Process  *ProcessInfo           `protobuf:"bytes,1,opt,name=process,proto3" json:"process,omitempty"`
+	Children []*ProcessNode         `protobuf:"bytes,2,rep,name=children,proto3" json:"children,omitempty"`
+	// 以下字段仅在 aggregate 时填充，包含自身及所有子孙进程
+	TotalCpuPercent    float64 `protobuf:"fixed64,3,opt,name=total_cpu_percent,json=totalCpuPercent,proto3" json:"total_cpu_percent,omitempty"`
+	TotalMemoryRss     uint64  `protobuf:"varint,4,opt,name=total_memory_rss,json=totalMemoryRss,proto3" json:"total_memory_rss,omitempty"`
+	TotalMemoryPercent float64 `protobuf:"fixed64,5,opt,name=total_memory_percent,json=totalMemoryPercent,proto3" json:"total_memory_percent,omitempty"`
+	Descendants        int32   `protobuf:"varint,6,opt,name=descendants,proto3" json:"descendants,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ProcessNode) Reset() {
+	*x = ProcessNode{}
+	mi := &file_agent_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessNode) ProtoMessage() {}
+
+func (x *ProcessNode) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessNode.ProtoReflect.Descriptor instead.
+func (*ProcessNode) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *ProcessNode) GetProcess() *ProcessInfo {
+	if x != nil {
+		return x.Process
+	}
+	return nil
+}
+
+func (x *ProcessNode) GetChildren() []*ProcessNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *ProcessNode) GetTotalCpuPercent() float64 {
+	if x != nil {
+		return x.TotalCpuPercent
 	}
 	return 0
 }
 
-func (x *ShellResize) GetCols() int32 {
+func (x *ProcessNode) GetTotalMemoryRss() uint64 {
 	if x != nil {
-		return x.Cols
+		return x.TotalMemoryRss
 	}
 	return 0
 }
 
-type ShellOutput struct {
+func (x *ProcessNode) GetTotalMemoryPercent() float64 {
+	if x != nil {
+		return x.TotalMemoryPercent
+	}
+	return 0
+}
+
+func (x *ProcessNode) GetDescendants() int32 {
+	if x != nil {
+		return x.Descendants
+	}
+	return 0
+}
+
+type ProcessTree struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Roots         []*ProcessNode         `protobuf:"bytes,1,rep,name=roots,proto3" json:"roots,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShellOutput) Reset() {
-	*x = ShellOutput{}
-	mi := &file_agent_proto_msgTypes[18]
+func (x *ProcessTree) Reset() {
+	*x = ProcessTree{}
+	mi := &file_agent_proto_msgTypes[93]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShellOutput) String() string {
+func (x *ProcessTree) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShellOutput) ProtoMessage() {}
+func (*ProcessTree) ProtoMessage() {}
 
-func (x *ShellOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[18]
+func (x *ProcessTree) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[93]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1601,41 +7522,45 @@ func (x *ShellOutput) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShellOutput.ProtoReflect.Descriptor instead.
-func (*ShellOutput) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use ProcessTree.ProtoReflect.Descriptor instead.
+func (*ProcessTree) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{93}
 }
 
-func (x *ShellOutput) GetData() []byte {
+func (x *ProcessTree) GetRoots() []*ProcessNode {
 	if x != nil {
-		return x.Data
+		return x.Roots
 	}
 	return nil
 }
 
-// 文件操作
-type FileRequest struct {
+type ListeningPort struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Protocol      string                 `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"` // tcp / tcp6 / udp / udp6
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Port          uint32                 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Pid           int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
+	Process       string                 `protobuf:"bytes,5,opt,name=process,proto3" json:"process,omitempty"`
+	Established   int32                  `protobuf:"varint,6,opt,name=established,proto3" json:"established,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileRequest) Reset() {
-	*x = FileRequest{}
-	mi := &file_agent_proto_msgTypes[19]
+func (x *ListeningPort) Reset() {
+	*x = ListeningPort{}
+	mi := &file_agent_proto_msgTypes[94]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileRequest) String() string {
+func (x *ListeningPort) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileRequest) ProtoMessage() {}
+func (*ListeningPort) ProtoMessage() {}
 
-func (x *FileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[19]
+func (x *ListeningPort) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[94]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1646,41 +7571,77 @@ func (x *FileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileRequest.ProtoReflect.Descriptor instead.
-func (*FileRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ListeningPort.ProtoReflect.Descriptor instead.
+func (*ListeningPort) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{94}
 }
 
-func (x *FileRequest) GetPath() string {
+func (x *ListeningPort) GetProtocol() string {
 	if x != nil {
-		return x.Path
+		return x.Protocol
 	}
 	return ""
 }
 
-type FileContent struct {
+func (x *ListeningPort) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ListeningPort) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ListeningPort) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ListeningPort) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+func (x *ListeningPort) GetEstablished() int32 {
+	if x != nil {
+		return x.Established
+	}
+	return 0
+}
+
+type NetworkConnections struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	Info          *FileInfo              `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	Listening     []*ListeningPort       `protobuf:"bytes,1,rep,name=listening,proto3" json:"listening,omitempty"`
+	StateCounts   map[string]int32       `protobuf:"bytes,2,rep,name=state_counts,json=stateCounts,proto3" json:"state_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileContent) Reset() {
-	*x = FileContent{}
-	mi := &file_agent_proto_msgTypes[20]
+func (x *NetworkConnections) Reset() {
+	*x = NetworkConnections{}
+	mi := &file_agent_proto_msgTypes[95]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileContent) String() string {
+func (x *NetworkConnections) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileContent) ProtoMessage() {}
+func (*NetworkConnections) ProtoMessage() {}
 
-func (x *FileContent) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[20]
+func (x *NetworkConnections) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[95]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1691,54 +7652,111 @@ func (x *FileContent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
-func (*FileContent) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use NetworkConnections.ProtoReflect.Descriptor instead.
+func (*NetworkConnections) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{95}
 }
 
-func (x *FileContent) GetContent() []byte {
+func (x *NetworkConnections) GetListening() []*ListeningPort {
+	if x != nil {
+		return x.Listening
+	}
+	return nil
+}
+
+func (x *NetworkConnections) GetStateCounts() map[string]int32 {
+	if x != nil {
+		return x.StateCounts
+	}
+	return nil
+}
+
+func (x *NetworkConnections) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type KillProcessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Signal        int32                  `protobuf:"varint,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KillProcessRequest) Reset() {
+	*x = KillProcessRequest{}
+	mi := &file_agent_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KillProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KillProcessRequest) ProtoMessage() {}
+
+func (x *KillProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KillProcessRequest.ProtoReflect.Descriptor instead.
+func (*KillProcessRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *KillProcessRequest) GetPid() int32 {
 	if x != nil {
-		return x.Content
+		return x.Pid
 	}
-	return nil
+	return 0
 }
 
-func (x *FileContent) GetInfo() *FileInfo {
+func (x *KillProcessRequest) GetSignal() int32 {
 	if x != nil {
-		return x.Info
+		return x.Signal
 	}
-	return nil
+	return 0
 }
 
-type FileInfo struct {
+// 通用响应
+type ActionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
-	Mode          int64                  `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
-	ModTime       int64                  `protobuf:"varint,5,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"`
-	IsDir         bool                   `protobuf:"varint,6,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
-	Owner         string                 `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
-	Group         string                 `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Code          string                 `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`             // 稳定错误码，失败时设置，如 PATH_NOT_ALLOWED
+	Violations    []*FieldViolation      `protobuf:"bytes,5,rep,name=violations,proto3" json:"violations,omitempty"` // 逐字段的校验错误，如插件清单校验失败的字段
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileInfo) Reset() {
-	*x = FileInfo{}
-	mi := &file_agent_proto_msgTypes[21]
+func (x *ActionResponse) Reset() {
+	*x = ActionResponse{}
+	mi := &file_agent_proto_msgTypes[97]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileInfo) String() string {
+func (x *ActionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileInfo) ProtoMessage() {}
+func (*ActionResponse) ProtoMessage() {}
 
-func (x *FileInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[21]
+func (x *ActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[97]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1749,92 +7767,70 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
-func (*FileInfo) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use ActionResponse.ProtoReflect.Descriptor instead.
+func (*ActionResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{97}
 }
 
-func (x *FileInfo) GetName() string {
+func (x *ActionResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Name
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *FileInfo) GetPath() string {
+func (x *ActionResponse) GetMessage() string {
 	if x != nil {
-		return x.Path
+		return x.Message
 	}
 	return ""
 }
 
-func (x *FileInfo) GetSize() int64 {
-	if x != nil {
-		return x.Size
-	}
-	return 0
-}
-
-func (x *FileInfo) GetMode() int64 {
-	if x != nil {
-		return x.Mode
-	}
-	return 0
-}
-
-func (x *FileInfo) GetModTime() int64 {
-	if x != nil {
-		return x.ModTime
-	}
-	return 0
-}
-
-func (x *FileInfo) GetIsDir() bool {
+func (x *ActionResponse) GetError() string {
 	if x != nil {
-		return x.IsDir
+		return x.Error
 	}
-	return false
+	return ""
 }
 
-func (x *FileInfo) GetOwner() string {
+func (x *ActionResponse) GetCode() string {
 	if x != nil {
-		return x.Owner
+		return x.Code
 	}
 	return ""
 }
 
-func (x *FileInfo) GetGroup() string {
+func (x *ActionResponse) GetViolations() []*FieldViolation {
 	if x != nil {
-		return x.Group
+		return x.Violations
 	}
-	return ""
+	return nil
 }
 
-type WriteFileRequest struct {
+// 字段校验错误
+type FieldViolation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
-	Mode          int64                  `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"`
-	CreateDirs    bool                   `protobuf:"varint,4,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"` // 字段路径，如 resources.memory、permissions[1]
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WriteFileRequest) Reset() {
-	*x = WriteFileRequest{}
-	mi := &file_agent_proto_msgTypes[22]
+func (x *FieldViolation) Reset() {
+	*x = FieldViolation{}
+	mi := &file_agent_proto_msgTypes[98]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WriteFileRequest) String() string {
+func (x *FieldViolation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteFileRequest) ProtoMessage() {}
+func (*FieldViolation) ProtoMessage() {}
 
-func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[22]
+func (x *FieldViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[98]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1845,67 +7841,50 @@ func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
-func (*WriteFileRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use FieldViolation.ProtoReflect.Descriptor instead.
+func (*FieldViolation) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{98}
 }
 
-func (x *WriteFileRequest) GetPath() string {
+func (x *FieldViolation) GetField() string {
 	if x != nil {
-		return x.Path
+		return x.Field
 	}
 	return ""
 }
 
-func (x *WriteFileRequest) GetContent() []byte {
-	if x != nil {
-		return x.Content
-	}
-	return nil
-}
-
-func (x *WriteFileRequest) GetMode() int64 {
-	if x != nil {
-		return x.Mode
-	}
-	return 0
-}
-
-func (x *WriteFileRequest) GetCreateDirs() bool {
+func (x *FieldViolation) GetDescription() string {
 	if x != nil {
-		return x.CreateDirs
+		return x.Description
 	}
-	return false
+	return ""
 }
 
-// 流式文件传输
-type FileChunk struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Data:
-	//
-	//	*FileChunk_Start
-	//	*FileChunk_Chunk
-	//	*FileChunk_End
-	Data          isFileChunk_Data `protobuf_oneof:"data"`
+// Docker Hub 搜索
+type DockerSearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileChunk) Reset() {
-	*x = FileChunk{}
-	mi := &file_agent_proto_msgTypes[23]
+func (x *DockerSearchRequest) Reset() {
+	*x = DockerSearchRequest{}
+	mi := &file_agent_proto_msgTypes[99]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileChunk) String() string {
+func (x *DockerSearchRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileChunk) ProtoMessage() {}
+func (*DockerSearchRequest) ProtoMessage() {}
 
-func (x *FileChunk) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[23]
+func (x *DockerSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[99]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1916,95 +7895,57 @@ func (x *FileChunk) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
-func (*FileChunk) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{23}
-}
-
-func (x *FileChunk) GetData() isFileChunk_Data {
-	if x != nil {
-		return x.Data
-	}
-	return nil
+// Deprecated: Use DockerSearchRequest.ProtoReflect.Descriptor instead.
+func (*DockerSearchRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{99}
 }
 
-func (x *FileChunk) GetStart() *FileUploadStart {
+func (x *DockerSearchRequest) GetQuery() string {
 	if x != nil {
-		if x, ok := x.Data.(*FileChunk_Start); ok {
-			return x.Start
-		}
+		return x.Query
 	}
-	return nil
+	return ""
 }
 
-func (x *FileChunk) GetChunk() []byte {
+func (x *DockerSearchRequest) GetPageSize() int32 {
 	if x != nil {
-		if x, ok := x.Data.(*FileChunk_Chunk); ok {
-			return x.Chunk
-		}
+		return x.PageSize
 	}
-	return nil
+	return 0
 }
 
-func (x *FileChunk) GetEnd() *FileUploadEnd {
+func (x *DockerSearchRequest) GetPage() int32 {
 	if x != nil {
-		if x, ok := x.Data.(*FileChunk_End); ok {
-			return x.End
-		}
+		return x.Page
 	}
-	return nil
-}
-
-type isFileChunk_Data interface {
-	isFileChunk_Data()
-}
-
-type FileChunk_Start struct {
-	Start *FileUploadStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"` // 开始上传，包含元信息
-}
-
-type FileChunk_Chunk struct {
-	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"` // 文件数据块
-}
-
-type FileChunk_End struct {
-	End *FileUploadEnd `protobuf:"bytes,3,opt,name=end,proto3,oneof"` // 结束上传
+	return 0
 }
 
-func (*FileChunk_Start) isFileChunk_Data() {}
-
-func (*FileChunk_Chunk) isFileChunk_Data() {}
-
-func (*FileChunk_End) isFileChunk_Data() {}
-
-type FileUploadStart struct {
+type DockerSearchResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`                                // 目标路径
-	TotalSize     int64                  `protobuf:"varint,2,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`    // 文件总大小
-	Mode          int64                  `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"`                               // 文件权限
-	CreateDirs    bool                   `protobuf:"varint,4,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"` // 是否创建父目录
-	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"`                        // 可选：文件校验和 (sha256)
-	IsTarGz       bool                   `protobuf:"varint,6,opt,name=is_tar_gz,json=isTarGz,proto3" json:"is_tar_gz,omitempty"`        // 是否是 tar.gz 压缩包（需要解压）
-	ExtractTo     string                 `protobuf:"bytes,7,opt,name=extract_to,json=extractTo,proto3" json:"extract_to,omitempty"`     // 如果是压缩包，解压到此目录
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Results       []*DockerImage         `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,4,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileUploadStart) Reset() {
-	*x = FileUploadStart{}
-	mi := &file_agent_proto_msgTypes[24]
+func (x *DockerSearchResponse) Reset() {
+	*x = DockerSearchResponse{}
+	mi := &file_agent_proto_msgTypes[100]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileUploadStart) String() string {
+func (x *DockerSearchResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileUploadStart) ProtoMessage() {}
+func (*DockerSearchResponse) ProtoMessage() {}
 
-func (x *FileUploadStart) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[24]
+func (x *DockerSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[100]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2015,82 +7956,66 @@ func (x *FileUploadStart) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileUploadStart.ProtoReflect.Descriptor instead.
-func (*FileUploadStart) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{24}
-}
-
-func (x *FileUploadStart) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *FileUploadStart) GetTotalSize() int64 {
-	if x != nil {
-		return x.TotalSize
-	}
-	return 0
-}
-
-func (x *FileUploadStart) GetMode() int64 {
-	if x != nil {
-		return x.Mode
-	}
-	return 0
+// Deprecated: Use DockerSearchResponse.ProtoReflect.Descriptor instead.
+func (*DockerSearchResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{100}
 }
 
-func (x *FileUploadStart) GetCreateDirs() bool {
+func (x *DockerSearchResponse) GetSuccess() bool {
 	if x != nil {
-		return x.CreateDirs
+		return x.Success
 	}
 	return false
 }
 
-func (x *FileUploadStart) GetChecksum() string {
+func (x *DockerSearchResponse) GetError() string {
 	if x != nil {
-		return x.Checksum
+		return x.Error
 	}
 	return ""
 }
 
-func (x *FileUploadStart) GetIsTarGz() bool {
+func (x *DockerSearchResponse) GetResults() []*DockerImage {
 	if x != nil {
-		return x.IsTarGz
+		return x.Results
 	}
-	return false
+	return nil
 }
 
-func (x *FileUploadStart) GetExtractTo() string {
+func (x *DockerSearchResponse) GetTotalCount() int32 {
 	if x != nil {
-		return x.ExtractTo
+		return x.TotalCount
 	}
-	return ""
+	return 0
 }
 
-type FileUploadEnd struct {
+type DockerImage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Checksum      string                 `protobuf:"bytes,1,opt,name=checksum,proto3" json:"checksum,omitempty"` // 可选：最终校验和
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	StarCount     int64                  `protobuf:"varint,3,opt,name=star_count,json=starCount,proto3" json:"star_count,omitempty"`
+	IsOfficial    bool                   `protobuf:"varint,4,opt,name=is_official,json=isOfficial,proto3" json:"is_official,omitempty"`
+	IsAutomated   bool                   `protobuf:"varint,5,opt,name=is_automated,json=isAutomated,proto3" json:"is_automated,omitempty"`
+	PullCount     int64                  `protobuf:"varint,6,opt,name=pull_count,json=pullCount,proto3" json:"pull_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileUploadEnd) Reset() {
-	*x = FileUploadEnd{}
-	mi := &file_agent_proto_msgTypes[25]
+func (x *DockerImage) Reset() {
+	*x = DockerImage{}
+	mi := &file_agent_proto_msgTypes[101]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileUploadEnd) String() string {
+func (x *DockerImage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileUploadEnd) ProtoMessage() {}
+func (*DockerImage) ProtoMessage() {}
 
-func (x *FileUploadEnd) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[25]
+func (x *DockerImage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[101]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2101,44 +8026,80 @@ func (x *FileUploadEnd) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileUploadEnd.ProtoReflect.Descriptor instead.
-func (*FileUploadEnd) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use DockerImage.ProtoReflect.Descriptor instead.
+func (*DockerImage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{101}
 }
 
-func (x *FileUploadEnd) GetChecksum() string {
+func (x *DockerImage) GetName() string {
 	if x != nil {
-		return x.Checksum
+		return x.Name
 	}
 	return ""
 }
 
-type UploadResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
-	BytesWritten  int64                  `protobuf:"varint,4,opt,name=bytes_written,json=bytesWritten,proto3" json:"bytes_written,omitempty"` // 实际写入的字节数
-	Path          string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`                                      // 最终文件路径
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *DockerImage) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
 }
 
-func (x *UploadResponse) Reset() {
-	*x = UploadResponse{}
-	mi := &file_agent_proto_msgTypes[26]
+func (x *DockerImage) GetStarCount() int64 {
+	if x != nil {
+		return x.StarCount
+	}
+	return 0
+}
+
+func (x *DockerImage) GetIsOfficial() bool {
+	if x != nil {
+		return x.IsOfficial
+	}
+	return false
+}
+
+func (x *DockerImage) GetIsAutomated() bool {
+	if x != nil {
+		return x.IsAutomated
+	}
+	return false
+}
+
+func (x *DockerImage) GetPullCount() int64 {
+	if x != nil {
+		return x.PullCount
+	}
+	return 0
+}
+
+// HTTP 代理请求
+type HttpProxyRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Url            string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Method         string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Headers        map[string]string      `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Body           []byte                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	TimeoutSeconds int32                  `protobuf:"varint,5,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *HttpProxyRequest) Reset() {
+	*x = HttpProxyRequest{}
+	mi := &file_agent_proto_msgTypes[102]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadResponse) String() string {
+func (x *HttpProxyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadResponse) ProtoMessage() {}
+func (*HttpProxyRequest) ProtoMessage() {}
 
-func (x *UploadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[26]
+func (x *HttpProxyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[102]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2149,70 +8110,73 @@ func (x *UploadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadResponse.ProtoReflect.Descriptor instead.
-func (*UploadResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use HttpProxyRequest.ProtoReflect.Descriptor instead.
+func (*HttpProxyRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{102}
 }
 
-func (x *UploadResponse) GetSuccess() bool {
+func (x *HttpProxyRequest) GetUrl() string {
 	if x != nil {
-		return x.Success
+		return x.Url
 	}
-	return false
+	return ""
 }
 
-func (x *UploadResponse) GetMessage() string {
+func (x *HttpProxyRequest) GetMethod() string {
 	if x != nil {
-		return x.Message
+		return x.Method
 	}
 	return ""
 }
 
-func (x *UploadResponse) GetError() string {
+func (x *HttpProxyRequest) GetHeaders() map[string]string {
 	if x != nil {
-		return x.Error
+		return x.Headers
 	}
-	return ""
+	return nil
 }
 
-func (x *UploadResponse) GetBytesWritten() int64 {
+func (x *HttpProxyRequest) GetBody() []byte {
 	if x != nil {
-		return x.BytesWritten
+		return x.Body
 	}
-	return 0
+	return nil
 }
 
-func (x *UploadResponse) GetPath() string {
+func (x *HttpProxyRequest) GetTimeoutSeconds() int32 {
 	if x != nil {
-		return x.Path
+		return x.TimeoutSeconds
 	}
-	return ""
+	return 0
 }
 
-type DirRequest struct {
+type HttpProxyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
-	ShowHidden    bool                   `protobuf:"varint,3,opt,name=show_hidden,json=showHidden,proto3" json:"show_hidden,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	StatusCode    int32                  `protobuf:"varint,2,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	StatusText    string                 `protobuf:"bytes,3,opt,name=status_text,json=statusText,proto3" json:"status_text,omitempty"`
+	Headers       map[string]string      `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Body          []byte                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DirRequest) Reset() {
-	*x = DirRequest{}
-	mi := &file_agent_proto_msgTypes[27]
+func (x *HttpProxyResponse) Reset() {
+	*x = HttpProxyResponse{}
+	mi := &file_agent_proto_msgTypes[103]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DirRequest) String() string {
+func (x *HttpProxyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DirRequest) ProtoMessage() {}
+func (*HttpProxyResponse) ProtoMessage() {}
 
-func (x *DirRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[27]
+func (x *HttpProxyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[103]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2223,55 +8187,76 @@ func (x *DirRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DirRequest.ProtoReflect.Descriptor instead.
-func (*DirRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use HttpProxyResponse.ProtoReflect.Descriptor instead.
+func (*HttpProxyResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{103}
 }
 
-func (x *DirRequest) GetPath() string {
+func (x *HttpProxyResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Path
+		return x.Success
+	}
+	return false
+}
+
+func (x *HttpProxyResponse) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *HttpProxyResponse) GetStatusText() string {
+	if x != nil {
+		return x.StatusText
 	}
 	return ""
 }
 
-func (x *DirRequest) GetRecursive() bool {
+func (x *HttpProxyResponse) GetHeaders() map[string]string {
 	if x != nil {
-		return x.Recursive
+		return x.Headers
 	}
-	return false
+	return nil
 }
 
-func (x *DirRequest) GetShowHidden() bool {
+func (x *HttpProxyResponse) GetBody() []byte {
 	if x != nil {
-		return x.ShowHidden
+		return x.Body
 	}
-	return false
+	return nil
 }
 
-type DirContent struct {
+func (x *HttpProxyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 插件请求
+type PluginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Files         []*FileInfo            `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DirContent) Reset() {
-	*x = DirContent{}
-	mi := &file_agent_proto_msgTypes[28]
+func (x *PluginRequest) Reset() {
+	*x = PluginRequest{}
+	mi := &file_agent_proto_msgTypes[104]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DirContent) String() string {
+func (x *PluginRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DirContent) ProtoMessage() {}
+func (*PluginRequest) ProtoMessage() {}
 
-func (x *DirContent) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[28]
+func (x *PluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[104]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2282,50 +8267,46 @@ func (x *DirContent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DirContent.ProtoReflect.Descriptor instead.
-func (*DirContent) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use PluginRequest.ProtoReflect.Descriptor instead.
+func (*PluginRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{104}
 }
 
-func (x *DirContent) GetPath() string {
+func (x *PluginRequest) GetPluginId() string {
 	if x != nil {
-		return x.Path
+		return x.PluginId
 	}
 	return ""
 }
 
-func (x *DirContent) GetFiles() []*FileInfo {
-	if x != nil {
-		return x.Files
-	}
-	return nil
-}
-
-// 日志
-type LogRequest struct {
+// 安装插件请求
+type InstallPluginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Lines         int32                  `protobuf:"varint,2,opt,name=lines,proto3" json:"lines,omitempty"`
-	Follow        bool                   `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`       // 来源: official, url, local
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`             // 如果 source 是 url，则为下载地址
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`           // 如果 source 是 local，则为插件数据
+	Signature     string                 `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"` // 插件包的 Ed25519 签名（base64），source 为 local 时必须随请求提交，为 url 时默认下载 <url>.sig
+	Sha256        string                 `protobuf:"bytes,6,opt,name=sha256,proto3" json:"sha256,omitempty"`       // 插件包的 SHA-256（十六进制），不为空时必须一致
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogRequest) Reset() {
-	*x = LogRequest{}
-	mi := &file_agent_proto_msgTypes[29]
+func (x *InstallPluginRequest) Reset() {
+	*x = InstallPluginRequest{}
+	mi := &file_agent_proto_msgTypes[105]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogRequest) String() string {
+func (x *InstallPluginRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogRequest) ProtoMessage() {}
+func (*InstallPluginRequest) ProtoMessage() {}
 
-func (x *LogRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[29]
+func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[105]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2336,55 +8317,80 @@ func (x *LogRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
-func (*LogRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use InstallPluginRequest.ProtoReflect.Descriptor instead.
+func (*InstallPluginRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{105}
 }
 
-func (x *LogRequest) GetPath() string {
+func (x *InstallPluginRequest) GetPluginId() string {
 	if x != nil {
-		return x.Path
+		return x.PluginId
 	}
 	return ""
 }
 
-func (x *LogRequest) GetLines() int32 {
+func (x *InstallPluginRequest) GetSource() string {
 	if x != nil {
-		return x.Lines
+		return x.Source
 	}
-	return 0
+	return ""
 }
 
-func (x *LogRequest) GetFollow() bool {
+func (x *InstallPluginRequest) GetUrl() string {
 	if x != nil {
-		return x.Follow
+		return x.Url
 	}
-	return false
+	return ""
 }
 
-type LogLine struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+func (x *InstallPluginRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *InstallPluginRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// 分块上传插件包：第一个消息为 start，之后为 chunk，发送完毕后关闭流
+type PluginUpload struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*PluginUpload_Start
+	//	*PluginUpload_Chunk
+	Data          isPluginUpload_Data `protobuf_oneof:"data"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogLine) Reset() {
-	*x = LogLine{}
-	mi := &file_agent_proto_msgTypes[30]
+func (x *PluginUpload) Reset() {
+	*x = PluginUpload{}
+	mi := &file_agent_proto_msgTypes[106]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogLine) String() string {
+func (x *PluginUpload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogLine) ProtoMessage() {}
+func (*PluginUpload) ProtoMessage() {}
 
-func (x *LogLine) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[30]
+func (x *PluginUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[106]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2395,49 +8401,76 @@ func (x *LogLine) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
-func (*LogLine) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{30}
+// Deprecated: Use PluginUpload.ProtoReflect.Descriptor instead.
+func (*PluginUpload) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{106}
 }
 
-func (x *LogLine) GetContent() string {
+func (x *PluginUpload) GetData() isPluginUpload_Data {
 	if x != nil {
-		return x.Content
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *LogLine) GetTimestamp() int64 {
+func (x *PluginUpload) GetStart() *PluginUploadStart {
 	if x != nil {
-		return x.Timestamp
+		if x, ok := x.Data.(*PluginUpload_Start); ok {
+			return x.Start
+		}
 	}
-	return 0
+	return nil
 }
 
-// 服务管理
-type ServiceFilter struct {
+func (x *PluginUpload) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*PluginUpload_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isPluginUpload_Data interface {
+	isPluginUpload_Data()
+}
+
+type PluginUpload_Start struct {
+	Start *PluginUploadStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type PluginUpload_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*PluginUpload_Start) isPluginUpload_Data() {}
+
+func (*PluginUpload_Chunk) isPluginUpload_Data() {}
+
+type PluginUploadStart struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NameFilter    string                 `protobuf:"bytes,1,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
-	StatusFilter  string                 `protobuf:"bytes,2,opt,name=status_filter,json=statusFilter,proto3" json:"status_filter,omitempty"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	Signature     string                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"` // 插件包的 Ed25519 签名（base64）
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`       // 插件包的 SHA-256（十六进制），不为空时必须一致
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceFilter) Reset() {
-	*x = ServiceFilter{}
-	mi := &file_agent_proto_msgTypes[31]
+func (x *PluginUploadStart) Reset() {
+	*x = PluginUploadStart{}
+	mi := &file_agent_proto_msgTypes[107]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceFilter) String() string {
+func (x *PluginUploadStart) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceFilter) ProtoMessage() {}
+func (*PluginUploadStart) ProtoMessage() {}
 
-func (x *ServiceFilter) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[31]
+func (x *PluginUploadStart) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[107]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2448,47 +8481,55 @@ func (x *ServiceFilter) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceFilter.ProtoReflect.Descriptor instead.
-func (*ServiceFilter) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{31}
+// Deprecated: Use PluginUploadStart.ProtoReflect.Descriptor instead.
+func (*PluginUploadStart) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{107}
 }
 
-func (x *ServiceFilter) GetNameFilter() string {
+func (x *PluginUploadStart) GetPluginId() string {
 	if x != nil {
-		return x.NameFilter
+		return x.PluginId
 	}
 	return ""
 }
 
-func (x *ServiceFilter) GetStatusFilter() string {
+func (x *PluginUploadStart) GetSignature() string {
 	if x != nil {
-		return x.StatusFilter
+		return x.Signature
 	}
 	return ""
 }
 
-type ServiceList struct {
+func (x *PluginUploadStart) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// 插件列表
+type PluginList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Services      []*ServiceInfo         `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	Plugins       []*PluginInfo          `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceList) Reset() {
-	*x = ServiceList{}
-	mi := &file_agent_proto_msgTypes[32]
+func (x *PluginList) Reset() {
+	*x = PluginList{}
+	mi := &file_agent_proto_msgTypes[108]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceList) String() string {
+func (x *PluginList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceList) ProtoMessage() {}
+func (*PluginList) ProtoMessage() {}
 
-func (x *ServiceList) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[32]
+func (x *PluginList) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[108]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2499,45 +8540,51 @@ func (x *ServiceList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceList.ProtoReflect.Descriptor instead.
-func (*ServiceList) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use PluginList.ProtoReflect.Descriptor instead.
+func (*PluginList) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{108}
 }
 
-func (x *ServiceList) GetServices() []*ServiceInfo {
+func (x *PluginList) GetPlugins() []*PluginInfo {
 	if x != nil {
-		return x.Services
+		return x.Plugins
 	}
 	return nil
 }
 
-type ServiceInfo struct {
+// 插件信息
+type PluginInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	Pid           int32                  `protobuf:"varint,5,opt,name=pid,proto3" json:"pid,omitempty"`
-	Uptime        int64                  `protobuf:"varint,6,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Author        string                 `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
+	Icon          string                 `protobuf:"bytes,6,opt,name=icon,proto3" json:"icon,omitempty"`
+	State         PluginState            `protobuf:"varint,7,opt,name=state,proto3,enum=runixo.PluginState" json:"state,omitempty"`
+	Type          PluginType             `protobuf:"varint,8,opt,name=type,proto3,enum=runixo.PluginType" json:"type,omitempty"`
+	Permissions   []string               `protobuf:"bytes,9,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	InstalledAt   int64                  `protobuf:"varint,10,opt,name=installed_at,json=installedAt,proto3" json:"installed_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceInfo) Reset() {
-	*x = ServiceInfo{}
-	mi := &file_agent_proto_msgTypes[33]
+func (x *PluginInfo) Reset() {
+	*x = PluginInfo{}
+	mi := &file_agent_proto_msgTypes[109]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceInfo) String() string {
+func (x *PluginInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceInfo) ProtoMessage() {}
+func (*PluginInfo) ProtoMessage() {}
 
-func (x *ServiceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[33]
+func (x *PluginInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[109]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2548,129 +8595,113 @@ func (x *ServiceInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceInfo.ProtoReflect.Descriptor instead.
-func (*ServiceInfo) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{33}
+// Deprecated: Use PluginInfo.ProtoReflect.Descriptor instead.
+func (*PluginInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{109}
 }
 
-func (x *ServiceInfo) GetName() string {
+func (x *PluginInfo) GetId() string {
 	if x != nil {
-		return x.Name
+		return x.Id
 	}
 	return ""
 }
 
-func (x *ServiceInfo) GetStatus() string {
+func (x *PluginInfo) GetName() string {
 	if x != nil {
-		return x.Status
+		return x.Name
 	}
 	return ""
 }
 
-func (x *ServiceInfo) GetDescription() string {
+func (x *PluginInfo) GetVersion() string {
 	if x != nil {
-		return x.Description
+		return x.Version
 	}
 	return ""
 }
 
-func (x *ServiceInfo) GetEnabled() bool {
+func (x *PluginInfo) GetDescription() string {
 	if x != nil {
-		return x.Enabled
+		return x.Description
 	}
-	return false
+	return ""
 }
 
-func (x *ServiceInfo) GetPid() int32 {
+func (x *PluginInfo) GetAuthor() string {
 	if x != nil {
-		return x.Pid
+		return x.Author
 	}
-	return 0
+	return ""
 }
 
-func (x *ServiceInfo) GetUptime() int64 {
+func (x *PluginInfo) GetIcon() string {
 	if x != nil {
-		return x.Uptime
+		return x.Icon
 	}
-	return 0
-}
-
-type ServiceActionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Action        ServiceAction          `protobuf:"varint,2,opt,name=action,proto3,enum=runixo.ServiceAction" json:"action,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ServiceActionRequest) Reset() {
-	*x = ServiceActionRequest{}
-	mi := &file_agent_proto_msgTypes[34]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+	return ""
 }
 
-func (x *ServiceActionRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *PluginInfo) GetState() PluginState {
+	if x != nil {
+		return x.State
+	}
+	return PluginState_PLUGIN_INSTALLED
 }
 
-func (*ServiceActionRequest) ProtoMessage() {}
-
-func (x *ServiceActionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[34]
+func (x *PluginInfo) GetType() PluginType {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Type
 	}
-	return mi.MessageOf(x)
+	return PluginType_PLUGIN_CLIENT
 }
 
-// Deprecated: Use ServiceActionRequest.ProtoReflect.Descriptor instead.
-func (*ServiceActionRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{34}
+func (x *PluginInfo) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
 }
 
-func (x *ServiceActionRequest) GetName() string {
+func (x *PluginInfo) GetInstalledAt() int64 {
 	if x != nil {
-		return x.Name
+		return x.InstalledAt
 	}
-	return ""
+	return 0
 }
 
-func (x *ServiceActionRequest) GetAction() ServiceAction {
+func (x *PluginInfo) GetUpdatedAt() int64 {
 	if x != nil {
-		return x.Action
+		return x.UpdatedAt
 	}
-	return ServiceAction_SERVICE_START
+	return 0
 }
 
-// 进程管理
-type ProcessFilter struct {
+// 插件配置
+type PluginConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NameFilter    string                 `protobuf:"bytes,1,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
-	UserFilter    string                 `protobuf:"bytes,2,opt,name=user_filter,json=userFilter,proto3" json:"user_filter,omitempty"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	ConfigJson    string                 `protobuf:"bytes,2,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"` // JSON 格式的配置，敏感配置项为 "********"
+	SchemaJson    string                 `protobuf:"bytes,3,opt,name=schema_json,json=schemaJson,proto3" json:"schema_json,omitempty"` // 插件清单声明的配置项 schema（JSON 数组），未声明时为空
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessFilter) Reset() {
-	*x = ProcessFilter{}
-	mi := &file_agent_proto_msgTypes[35]
+func (x *PluginConfig) Reset() {
+	*x = PluginConfig{}
+	mi := &file_agent_proto_msgTypes[110]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessFilter) String() string {
+func (x *PluginConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessFilter) ProtoMessage() {}
+func (*PluginConfig) ProtoMessage() {}
 
-func (x *ProcessFilter) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[35]
+func (x *PluginConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[110]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2681,47 +8712,56 @@ func (x *ProcessFilter) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessFilter.ProtoReflect.Descriptor instead.
-func (*ProcessFilter) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{35}
+// Deprecated: Use PluginConfig.ProtoReflect.Descriptor instead.
+func (*PluginConfig) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{110}
 }
 
-func (x *ProcessFilter) GetNameFilter() string {
+func (x *PluginConfig) GetPluginId() string {
 	if x != nil {
-		return x.NameFilter
+		return x.PluginId
 	}
 	return ""
 }
 
-func (x *ProcessFilter) GetUserFilter() string {
+func (x *PluginConfig) GetConfigJson() string {
 	if x != nil {
-		return x.UserFilter
+		return x.ConfigJson
 	}
 	return ""
 }
 
-type ProcessList struct {
+func (x *PluginConfig) GetSchemaJson() string {
+	if x != nil {
+		return x.SchemaJson
+	}
+	return ""
+}
+
+// 设置插件配置请求
+type SetPluginConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Processes     []*ProcessInfo         `protobuf:"bytes,1,rep,name=processes,proto3" json:"processes,omitempty"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	ConfigJson    string                 `protobuf:"bytes,2,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"` // 完整配置，敏感配置项为 "********" 时保留原值
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessList) Reset() {
-	*x = ProcessList{}
-	mi := &file_agent_proto_msgTypes[36]
+func (x *SetPluginConfigRequest) Reset() {
+	*x = SetPluginConfigRequest{}
+	mi := &file_agent_proto_msgTypes[111]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessList) String() string {
+func (x *SetPluginConfigRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessList) ProtoMessage() {}
+func (*SetPluginConfigRequest) ProtoMessage() {}
 
-func (x *ProcessList) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[36]
+func (x *SetPluginConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[111]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2732,49 +8772,58 @@ func (x *ProcessList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessList.ProtoReflect.Descriptor instead.
-func (*ProcessList) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{36}
+// Deprecated: Use SetPluginConfigRequest.ProtoReflect.Descriptor instead.
+func (*SetPluginConfigRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{111}
 }
 
-func (x *ProcessList) GetProcesses() []*ProcessInfo {
+func (x *SetPluginConfigRequest) GetPluginId() string {
 	if x != nil {
-		return x.Processes
+		return x.PluginId
 	}
-	return nil
+	return ""
 }
 
-type ProcessInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
-	Ppid          int32                  `protobuf:"varint,2,opt,name=ppid,proto3" json:"ppid,omitempty"`
-	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	User          string                 `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
-	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
-	CpuPercent    float64                `protobuf:"fixed64,6,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
-	MemoryPercent float64                `protobuf:"fixed64,7,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
-	MemoryRss     uint64                 `protobuf:"varint,8,opt,name=memory_rss,json=memoryRss,proto3" json:"memory_rss,omitempty"`
-	CreateTime    int64                  `protobuf:"varint,9,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
-	Cmdline       string                 `protobuf:"bytes,10,opt,name=cmdline,proto3" json:"cmdline,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *SetPluginConfigRequest) GetConfigJson() string {
+	if x != nil {
+		return x.ConfigJson
+	}
+	return ""
 }
 
-func (x *ProcessInfo) Reset() {
-	*x = ProcessInfo{}
-	mi := &file_agent_proto_msgTypes[37]
+// 插件状态详情
+type PluginStatus struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	PluginId          string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	State             PluginState            `protobuf:"varint,2,opt,name=state,proto3,enum=runixo.PluginState" json:"state,omitempty"`
+	Running           bool                   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	Error             string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Uptime            int64                  `protobuf:"varint,5,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Stats             map[string]string      `protobuf:"bytes,6,rep,name=stats,proto3" json:"stats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Usage             *PluginResourceUsage   `protobuf:"bytes,7,opt,name=usage,proto3" json:"usage,omitempty"`                                                    // 最近一次资源统计，插件未运行或尚未统计时为空
+	Restarts          int32                  `protobuf:"varint,8,opt,name=restarts,proto3" json:"restarts,omitempty"`                                             // 自动重启次数
+	NextRestart       int64                  `protobuf:"varint,9,opt,name=next_restart,json=nextRestart,proto3" json:"next_restart,omitempty"`                    // 下次自动重启的 Unix 时间，没有待执行的重启时为 0
+	LastHealthCheck   int64                  `protobuf:"varint,10,opt,name=last_health_check,json=lastHealthCheck,proto3" json:"last_health_check,omitempty"`     // 最近一次健康检查的 Unix 时间
+	PermissionDenials int32                  `protobuf:"varint,11,opt,name=permission_denials,json=permissionDenials,proto3" json:"permission_denials,omitempty"` // 本次运行中因未声明权限被拒绝的访问次数
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PluginStatus) Reset() {
+	*x = PluginStatus{}
+	mi := &file_agent_proto_msgTypes[112]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessInfo) String() string {
+func (x *PluginStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessInfo) ProtoMessage() {}
+func (*PluginStatus) ProtoMessage() {}
 
-func (x *ProcessInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[37]
+func (x *PluginStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[112]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2785,104 +8834,114 @@ func (x *ProcessInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessInfo.ProtoReflect.Descriptor instead.
-func (*ProcessInfo) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{37}
+// Deprecated: Use PluginStatus.ProtoReflect.Descriptor instead.
+func (*PluginStatus) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{112}
 }
 
-func (x *ProcessInfo) GetPid() int32 {
+func (x *PluginStatus) GetPluginId() string {
 	if x != nil {
-		return x.Pid
+		return x.PluginId
 	}
-	return 0
+	return ""
 }
 
-func (x *ProcessInfo) GetPpid() int32 {
+func (x *PluginStatus) GetState() PluginState {
 	if x != nil {
-		return x.Ppid
+		return x.State
 	}
-	return 0
+	return PluginState_PLUGIN_INSTALLED
 }
 
-func (x *ProcessInfo) GetName() string {
+func (x *PluginStatus) GetRunning() bool {
 	if x != nil {
-		return x.Name
+		return x.Running
 	}
-	return ""
+	return false
 }
 
-func (x *ProcessInfo) GetUser() string {
+func (x *PluginStatus) GetError() string {
 	if x != nil {
-		return x.User
+		return x.Error
 	}
 	return ""
 }
 
-func (x *ProcessInfo) GetStatus() string {
+func (x *PluginStatus) GetUptime() int64 {
 	if x != nil {
-		return x.Status
+		return x.Uptime
 	}
-	return ""
+	return 0
 }
 
-func (x *ProcessInfo) GetCpuPercent() float64 {
+func (x *PluginStatus) GetStats() map[string]string {
 	if x != nil {
-		return x.CpuPercent
+		return x.Stats
 	}
-	return 0
+	return nil
 }
 
-func (x *ProcessInfo) GetMemoryPercent() float64 {
+func (x *PluginStatus) GetUsage() *PluginResourceUsage {
 	if x != nil {
-		return x.MemoryPercent
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *PluginStatus) GetRestarts() int32 {
+	if x != nil {
+		return x.Restarts
 	}
 	return 0
 }
 
-func (x *ProcessInfo) GetMemoryRss() uint64 {
+func (x *PluginStatus) GetNextRestart() int64 {
 	if x != nil {
-		return x.MemoryRss
+		return x.NextRestart
 	}
 	return 0
 }
 
-func (x *ProcessInfo) GetCreateTime() int64 {
+func (x *PluginStatus) GetLastHealthCheck() int64 {
 	if x != nil {
-		return x.CreateTime
+		return x.LastHealthCheck
 	}
 	return 0
 }
 
-func (x *ProcessInfo) GetCmdline() string {
+func (x *PluginStatus) GetPermissionDenials() int32 {
 	if x != nil {
-		return x.Cmdline
+		return x.PermissionDenials
 	}
-	return ""
+	return 0
 }
 
-type KillProcessRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
-	Signal        int32                  `protobuf:"varint,2,opt,name=signal,proto3" json:"signal,omitempty"`
+// 插件试运行结果
+type PluginVerifyResult struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	PluginId      string                    `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	Ok            bool                      `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"` // 没有失败的步骤
+	Steps         []*PluginVerifyStep       `protobuf:"bytes,3,rep,name=steps,proto3" json:"steps,omitempty"`
+	Denials       []*PluginPermissionDenial `protobuf:"bytes,4,rep,name=denials,proto3" json:"denials,omitempty"` // 试运行期间因未声明权限被拒绝的访问
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *KillProcessRequest) Reset() {
-	*x = KillProcessRequest{}
-	mi := &file_agent_proto_msgTypes[38]
+func (x *PluginVerifyResult) Reset() {
+	*x = PluginVerifyResult{}
+	mi := &file_agent_proto_msgTypes[113]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *KillProcessRequest) String() string {
+func (x *PluginVerifyResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*KillProcessRequest) ProtoMessage() {}
+func (*PluginVerifyResult) ProtoMessage() {}
 
-func (x *KillProcessRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[38]
+func (x *PluginVerifyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[113]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2893,50 +8952,65 @@ func (x *KillProcessRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use KillProcessRequest.ProtoReflect.Descriptor instead.
-func (*KillProcessRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{38}
+// Deprecated: Use PluginVerifyResult.ProtoReflect.Descriptor instead.
+func (*PluginVerifyResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{113}
 }
 
-func (x *KillProcessRequest) GetPid() int32 {
+func (x *PluginVerifyResult) GetPluginId() string {
 	if x != nil {
-		return x.Pid
+		return x.PluginId
 	}
-	return 0
+	return ""
 }
 
-func (x *KillProcessRequest) GetSignal() int32 {
+func (x *PluginVerifyResult) GetOk() bool {
 	if x != nil {
-		return x.Signal
+		return x.Ok
 	}
-	return 0
+	return false
 }
 
-// 通用响应
-type ActionResponse struct {
+func (x *PluginVerifyResult) GetSteps() []*PluginVerifyStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *PluginVerifyResult) GetDenials() []*PluginPermissionDenial {
+	if x != nil {
+		return x.Denials
+	}
+	return nil
+}
+
+// 试运行步骤：config、dependencies、start、health_check、stop
+type PluginVerifyStep struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // passed、failed 或 skipped
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ActionResponse) Reset() {
-	*x = ActionResponse{}
-	mi := &file_agent_proto_msgTypes[39]
+func (x *PluginVerifyStep) Reset() {
+	*x = PluginVerifyStep{}
+	mi := &file_agent_proto_msgTypes[114]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ActionResponse) String() string {
+func (x *PluginVerifyStep) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActionResponse) ProtoMessage() {}
+func (*PluginVerifyStep) ProtoMessage() {}
 
-func (x *ActionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[39]
+func (x *PluginVerifyStep) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[114]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2947,57 +9021,63 @@ func (x *ActionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActionResponse.ProtoReflect.Descriptor instead.
-func (*ActionResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{39}
+// Deprecated: Use PluginVerifyStep.ProtoReflect.Descriptor instead.
+func (*PluginVerifyStep) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{114}
 }
 
-func (x *ActionResponse) GetSuccess() bool {
+func (x *PluginVerifyStep) GetName() string {
 	if x != nil {
-		return x.Success
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *ActionResponse) GetMessage() string {
+func (x *PluginVerifyStep) GetStatus() string {
 	if x != nil {
-		return x.Message
+		return x.Status
 	}
 	return ""
 }
 
-func (x *ActionResponse) GetError() string {
+func (x *PluginVerifyStep) GetMessage() string {
 	if x != nil {
-		return x.Error
+		return x.Message
 	}
 	return ""
 }
 
-// Docker Hub 搜索
-type DockerSearchRequest struct {
+func (x *PluginVerifyStep) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type PluginPermissionDenial struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	Permission    string                 `protobuf:"bytes,1,opt,name=permission,proto3" json:"permission,omitempty"`
+	Resource      string                 `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	Time          int64                  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"` // Unix 时间
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DockerSearchRequest) Reset() {
-	*x = DockerSearchRequest{}
-	mi := &file_agent_proto_msgTypes[40]
+func (x *PluginPermissionDenial) Reset() {
+	*x = PluginPermissionDenial{}
+	mi := &file_agent_proto_msgTypes[115]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DockerSearchRequest) String() string {
+func (x *PluginPermissionDenial) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DockerSearchRequest) ProtoMessage() {}
+func (*PluginPermissionDenial) ProtoMessage() {}
 
-func (x *DockerSearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[40]
+func (x *PluginPermissionDenial) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[115]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3008,57 +9088,59 @@ func (x *DockerSearchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DockerSearchRequest.ProtoReflect.Descriptor instead.
-func (*DockerSearchRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{40}
+// Deprecated: Use PluginPermissionDenial.ProtoReflect.Descriptor instead.
+func (*PluginPermissionDenial) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{115}
 }
 
-func (x *DockerSearchRequest) GetQuery() string {
+func (x *PluginPermissionDenial) GetPermission() string {
 	if x != nil {
-		return x.Query
+		return x.Permission
 	}
 	return ""
 }
 
-func (x *DockerSearchRequest) GetPageSize() int32 {
+func (x *PluginPermissionDenial) GetResource() string {
 	if x != nil {
-		return x.PageSize
+		return x.Resource
 	}
-	return 0
+	return ""
 }
 
-func (x *DockerSearchRequest) GetPage() int32 {
+func (x *PluginPermissionDenial) GetTime() int64 {
 	if x != nil {
-		return x.Page
+		return x.Time
 	}
 	return 0
 }
 
-type DockerSearchResponse struct {
+// 插件诊断信息
+type PluginDiagnostics struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Results       []*DockerImage         `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
-	TotalCount    int32                  `protobuf:"varint,4,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	CapturedAt    int64                  `protobuf:"varint,2,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"` // 采集的 Unix 时间
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                            // 插件进入错误状态的原因
+	Panic         string                 `protobuf:"bytes,4,opt,name=panic,proto3" json:"panic,omitempty"`                              // 插件进程输出的 panic 或 fatal error 及堆栈，没有时为空
+	BundleJson    string                 `protobuf:"bytes,5,opt,name=bundle_json,json=bundleJson,proto3" json:"bundle_json,omitempty"`  // 完整的诊断信息（JSON）：最近的输出、隐藏敏感项后的配置、goroutine 堆栈、最近的事件等
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DockerSearchResponse) Reset() {
-	*x = DockerSearchResponse{}
-	mi := &file_agent_proto_msgTypes[41]
+func (x *PluginDiagnostics) Reset() {
+	*x = PluginDiagnostics{}
+	mi := &file_agent_proto_msgTypes[116]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DockerSearchResponse) String() string {
+func (x *PluginDiagnostics) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DockerSearchResponse) ProtoMessage() {}
+func (*PluginDiagnostics) ProtoMessage() {}
 
-func (x *DockerSearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[41]
+func (x *PluginDiagnostics) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[116]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3069,66 +9151,69 @@ func (x *DockerSearchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DockerSearchResponse.ProtoReflect.Descriptor instead.
-func (*DockerSearchResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{41}
+// Deprecated: Use PluginDiagnostics.ProtoReflect.Descriptor instead.
+func (*PluginDiagnostics) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{116}
 }
 
-func (x *DockerSearchResponse) GetSuccess() bool {
+func (x *PluginDiagnostics) GetPluginId() string {
 	if x != nil {
-		return x.Success
+		return x.PluginId
 	}
-	return false
+	return ""
 }
 
-func (x *DockerSearchResponse) GetError() string {
+func (x *PluginDiagnostics) GetCapturedAt() int64 {
 	if x != nil {
-		return x.Error
+		return x.CapturedAt
+	}
+	return 0
+}
+
+func (x *PluginDiagnostics) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *DockerSearchResponse) GetResults() []*DockerImage {
+func (x *PluginDiagnostics) GetPanic() string {
 	if x != nil {
-		return x.Results
+		return x.Panic
 	}
-	return nil
+	return ""
 }
 
-func (x *DockerSearchResponse) GetTotalCount() int32 {
+func (x *PluginDiagnostics) GetBundleJson() string {
 	if x != nil {
-		return x.TotalCount
+		return x.BundleJson
 	}
-	return 0
+	return ""
 }
 
-type DockerImage struct {
+// 导出插件配置请求
+type ExportPluginConfigsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	StarCount     int64                  `protobuf:"varint,3,opt,name=star_count,json=starCount,proto3" json:"star_count,omitempty"`
-	IsOfficial    bool                   `protobuf:"varint,4,opt,name=is_official,json=isOfficial,proto3" json:"is_official,omitempty"`
-	IsAutomated   bool                   `protobuf:"varint,5,opt,name=is_automated,json=isAutomated,proto3" json:"is_automated,omitempty"`
-	PullCount     int64                  `protobuf:"varint,6,opt,name=pull_count,json=pullCount,proto3" json:"pull_count,omitempty"`
+	Passphrase    string                 `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"` // 不为空时加密导出包并包含敏感配置项，否则敏感配置项为 "********"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DockerImage) Reset() {
-	*x = DockerImage{}
-	mi := &file_agent_proto_msgTypes[42]
+func (x *ExportPluginConfigsRequest) Reset() {
+	*x = ExportPluginConfigsRequest{}
+	mi := &file_agent_proto_msgTypes[117]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DockerImage) String() string {
+func (x *ExportPluginConfigsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DockerImage) ProtoMessage() {}
+func (*ExportPluginConfigsRequest) ProtoMessage() {}
 
-func (x *DockerImage) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[42]
+func (x *ExportPluginConfigsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[117]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3139,80 +9224,97 @@ func (x *DockerImage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DockerImage.ProtoReflect.Descriptor instead.
-func (*DockerImage) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{42}
+// Deprecated: Use ExportPluginConfigsRequest.ProtoReflect.Descriptor instead.
+func (*ExportPluginConfigsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{117}
 }
 
-func (x *DockerImage) GetName() string {
+func (x *ExportPluginConfigsRequest) GetPassphrase() string {
 	if x != nil {
-		return x.Name
+		return x.Passphrase
 	}
 	return ""
 }
 
-func (x *DockerImage) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
+// 插件配置导出包
+type PluginConfigBundle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"` // 导出包（JSON）
+	Encrypted     bool                   `protobuf:"varint,2,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DockerImage) GetStarCount() int64 {
-	if x != nil {
-		return x.StarCount
-	}
-	return 0
+func (x *PluginConfigBundle) Reset() {
+	*x = PluginConfigBundle{}
+	mi := &file_agent_proto_msgTypes[118]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *DockerImage) GetIsOfficial() bool {
+func (x *PluginConfigBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginConfigBundle) ProtoMessage() {}
+
+func (x *PluginConfigBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[118]
 	if x != nil {
-		return x.IsOfficial
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *DockerImage) GetIsAutomated() bool {
+// Deprecated: Use PluginConfigBundle.ProtoReflect.Descriptor instead.
+func (*PluginConfigBundle) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{118}
+}
+
+func (x *PluginConfigBundle) GetData() []byte {
 	if x != nil {
-		return x.IsAutomated
+		return x.Data
 	}
-	return false
+	return nil
 }
 
-func (x *DockerImage) GetPullCount() int64 {
+func (x *PluginConfigBundle) GetEncrypted() bool {
 	if x != nil {
-		return x.PullCount
+		return x.Encrypted
 	}
-	return 0
+	return false
 }
 
-// HTTP 代理请求
-type HttpProxyRequest struct {
+// 导入插件配置请求
+type ImportPluginConfigsRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
-	Url            string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Method         string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
-	Headers        map[string]string      `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Body           []byte                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
-	TimeoutSeconds int32                  `protobuf:"varint,5,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	Data           []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                                            // ExportPluginConfigs 生成的导出包
+	Passphrase     string                 `protobuf:"bytes,2,opt,name=passphrase,proto3" json:"passphrase,omitempty"`                                // 加密导出包的密码
+	InstallMissing bool                   `protobuf:"varint,3,opt,name=install_missing,json=installMissing,proto3" json:"install_missing,omitempty"` // 从官方仓库安装本机缺少的插件，否则跳过
+	PluginIds      []string               `protobuf:"bytes,4,rep,name=plugin_ids,json=pluginIds,proto3" json:"plugin_ids,omitempty"`                 // 只导入这些插件，为空时导入全部
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *HttpProxyRequest) Reset() {
-	*x = HttpProxyRequest{}
-	mi := &file_agent_proto_msgTypes[43]
+func (x *ImportPluginConfigsRequest) Reset() {
+	*x = ImportPluginConfigsRequest{}
+	mi := &file_agent_proto_msgTypes[119]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HttpProxyRequest) String() string {
+func (x *ImportPluginConfigsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HttpProxyRequest) ProtoMessage() {}
+func (*ImportPluginConfigsRequest) ProtoMessage() {}
 
-func (x *HttpProxyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[43]
+func (x *ImportPluginConfigsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[119]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3223,73 +9325,62 @@ func (x *HttpProxyRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HttpProxyRequest.ProtoReflect.Descriptor instead.
-func (*HttpProxyRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{43}
+// Deprecated: Use ImportPluginConfigsRequest.ProtoReflect.Descriptor instead.
+func (*ImportPluginConfigsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{119}
 }
 
-func (x *HttpProxyRequest) GetUrl() string {
+func (x *ImportPluginConfigsRequest) GetData() []byte {
 	if x != nil {
-		return x.Url
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *HttpProxyRequest) GetMethod() string {
+func (x *ImportPluginConfigsRequest) GetPassphrase() string {
 	if x != nil {
-		return x.Method
+		return x.Passphrase
 	}
 	return ""
 }
 
-func (x *HttpProxyRequest) GetHeaders() map[string]string {
+func (x *ImportPluginConfigsRequest) GetInstallMissing() bool {
 	if x != nil {
-		return x.Headers
+		return x.InstallMissing
 	}
-	return nil
+	return false
 }
 
-func (x *HttpProxyRequest) GetBody() []byte {
+func (x *ImportPluginConfigsRequest) GetPluginIds() []string {
 	if x != nil {
-		return x.Body
+		return x.PluginIds
 	}
 	return nil
 }
 
-func (x *HttpProxyRequest) GetTimeoutSeconds() int32 {
-	if x != nil {
-		return x.TimeoutSeconds
-	}
-	return 0
-}
-
-type HttpProxyResponse struct {
+// 导入插件配置的结果
+type ImportPluginConfigsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	StatusCode    int32                  `protobuf:"varint,2,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
-	StatusText    string                 `protobuf:"bytes,3,opt,name=status_text,json=statusText,proto3" json:"status_text,omitempty"`
-	Headers       map[string]string      `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Body          []byte                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
-	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Results       []*PluginImportResult  `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HttpProxyResponse) Reset() {
-	*x = HttpProxyResponse{}
-	mi := &file_agent_proto_msgTypes[44]
+func (x *ImportPluginConfigsResponse) Reset() {
+	*x = ImportPluginConfigsResponse{}
+	mi := &file_agent_proto_msgTypes[120]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HttpProxyResponse) String() string {
+func (x *ImportPluginConfigsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HttpProxyResponse) ProtoMessage() {}
+func (*ImportPluginConfigsResponse) ProtoMessage() {}
 
-func (x *HttpProxyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[44]
+func (x *ImportPluginConfigsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[120]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3300,76 +9391,42 @@ func (x *HttpProxyResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HttpProxyResponse.ProtoReflect.Descriptor instead.
-func (*HttpProxyResponse) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{44}
-}
-
-func (x *HttpProxyResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
-}
-
-func (x *HttpProxyResponse) GetStatusCode() int32 {
-	if x != nil {
-		return x.StatusCode
-	}
-	return 0
-}
-
-func (x *HttpProxyResponse) GetStatusText() string {
-	if x != nil {
-		return x.StatusText
-	}
-	return ""
-}
-
-func (x *HttpProxyResponse) GetHeaders() map[string]string {
-	if x != nil {
-		return x.Headers
-	}
-	return nil
+// Deprecated: Use ImportPluginConfigsResponse.ProtoReflect.Descriptor instead.
+func (*ImportPluginConfigsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{120}
 }
 
-func (x *HttpProxyResponse) GetBody() []byte {
+func (x *ImportPluginConfigsResponse) GetResults() []*PluginImportResult {
 	if x != nil {
-		return x.Body
+		return x.Results
 	}
 	return nil
 }
 
-func (x *HttpProxyResponse) GetError() string {
-	if x != nil {
-		return x.Error
-	}
-	return ""
-}
-
-// 插件请求
-type PluginRequest struct {
+type PluginImportResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`   // applied, installed, skipped, failed
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"` // 失败原因，或版本不同等提示
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginRequest) Reset() {
-	*x = PluginRequest{}
-	mi := &file_agent_proto_msgTypes[45]
+func (x *PluginImportResult) Reset() {
+	*x = PluginImportResult{}
+	mi := &file_agent_proto_msgTypes[121]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginRequest) String() string {
+func (x *PluginImportResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginRequest) ProtoMessage() {}
+func (*PluginImportResult) ProtoMessage() {}
 
-func (x *PluginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[45]
+func (x *PluginImportResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[121]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3380,44 +9437,63 @@ func (x *PluginRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginRequest.ProtoReflect.Descriptor instead.
-func (*PluginRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{45}
+// Deprecated: Use PluginImportResult.ProtoReflect.Descriptor instead.
+func (*PluginImportResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{121}
 }
 
-func (x *PluginRequest) GetPluginId() string {
+func (x *PluginImportResult) GetPluginId() string {
 	if x != nil {
 		return x.PluginId
 	}
 	return ""
 }
 
-// 安装插件请求
-type InstallPluginRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
-	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"` // 来源: official, url, local
-	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`       // 如果 source 是 url，则为下载地址
-	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`     // 如果 source 是 local，则为插件数据
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *PluginImportResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
 }
 
-func (x *InstallPluginRequest) Reset() {
-	*x = InstallPluginRequest{}
-	mi := &file_agent_proto_msgTypes[46]
+func (x *PluginImportResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 插件资源使用，限制为 0 表示不限制
+type PluginResourceUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Accounting     string                 `protobuf:"bytes,1,opt,name=accounting,proto3" json:"accounting,omitempty"`                     // 统计方式: cgroup, process（外部进程，无 cgroup）, goroutine（进程内插件）
+	CpuPercent     float64                `protobuf:"fixed64,2,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // 占单个 CPU 的百分比
+	Memory         uint64                 `protobuf:"varint,3,opt,name=memory,proto3" json:"memory,omitempty"`                            // 字节，进程内插件为 0
+	Goroutines     int32                  `protobuf:"varint,4,opt,name=goroutines,proto3" json:"goroutines,omitempty"`                    // 仅进程内插件
+	CpuLimit       float64                `protobuf:"fixed64,5,opt,name=cpu_limit,json=cpuLimit,proto3" json:"cpu_limit,omitempty"`
+	MemoryLimit    uint64                 `protobuf:"varint,6,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
+	GoroutineLimit int32                  `protobuf:"varint,7,opt,name=goroutine_limit,json=goroutineLimit,proto3" json:"goroutine_limit,omitempty"`
+	Violations     int32                  `protobuf:"varint,8,opt,name=violations,proto3" json:"violations,omitempty"` // 连续超限的统计周期数，达到上限后插件被自动禁用
+	SampledAt      int64                  `protobuf:"varint,9,opt,name=sampled_at,json=sampledAt,proto3" json:"sampled_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PluginResourceUsage) Reset() {
+	*x = PluginResourceUsage{}
+	mi := &file_agent_proto_msgTypes[122]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InstallPluginRequest) String() string {
+func (x *PluginResourceUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InstallPluginRequest) ProtoMessage() {}
+func (*PluginResourceUsage) ProtoMessage() {}
 
-func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[46]
+func (x *PluginResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[122]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3428,62 +9504,97 @@ func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InstallPluginRequest.ProtoReflect.Descriptor instead.
-func (*InstallPluginRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{46}
+// Deprecated: Use PluginResourceUsage.ProtoReflect.Descriptor instead.
+func (*PluginResourceUsage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{122}
 }
 
-func (x *InstallPluginRequest) GetPluginId() string {
+func (x *PluginResourceUsage) GetAccounting() string {
 	if x != nil {
-		return x.PluginId
+		return x.Accounting
 	}
 	return ""
 }
 
-func (x *InstallPluginRequest) GetSource() string {
+func (x *PluginResourceUsage) GetCpuPercent() float64 {
 	if x != nil {
-		return x.Source
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *PluginResourceUsage) GetMemory() uint64 {
+	if x != nil {
+		return x.Memory
+	}
+	return 0
+}
+
+func (x *PluginResourceUsage) GetGoroutines() int32 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+func (x *PluginResourceUsage) GetCpuLimit() float64 {
+	if x != nil {
+		return x.CpuLimit
+	}
+	return 0
+}
+
+func (x *PluginResourceUsage) GetMemoryLimit() uint64 {
+	if x != nil {
+		return x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *PluginResourceUsage) GetGoroutineLimit() int32 {
+	if x != nil {
+		return x.GoroutineLimit
 	}
-	return ""
+	return 0
 }
 
-func (x *InstallPluginRequest) GetUrl() string {
+func (x *PluginResourceUsage) GetViolations() int32 {
 	if x != nil {
-		return x.Url
+		return x.Violations
 	}
-	return ""
+	return 0
 }
 
-func (x *InstallPluginRequest) GetData() []byte {
+func (x *PluginResourceUsage) GetSampledAt() int64 {
 	if x != nil {
-		return x.Data
+		return x.SampledAt
 	}
-	return nil
+	return 0
 }
 
-// 插件列表
-type PluginList struct {
+// 可用插件列表
+type AvailablePluginList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plugins       []*PluginInfo          `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	Plugins       []*AvailablePlugin     `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginList) Reset() {
-	*x = PluginList{}
-	mi := &file_agent_proto_msgTypes[47]
+func (x *AvailablePluginList) Reset() {
+	*x = AvailablePluginList{}
+	mi := &file_agent_proto_msgTypes[123]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginList) String() string {
+func (x *AvailablePluginList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginList) ProtoMessage() {}
+func (*AvailablePluginList) ProtoMessage() {}
 
-func (x *PluginList) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[47]
+func (x *AvailablePluginList) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[123]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3494,20 +9605,20 @@ func (x *PluginList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginList.ProtoReflect.Descriptor instead.
-func (*PluginList) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{47}
+// Deprecated: Use AvailablePluginList.ProtoReflect.Descriptor instead.
+func (*AvailablePluginList) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{123}
 }
 
-func (x *PluginList) GetPlugins() []*PluginInfo {
+func (x *AvailablePluginList) GetPlugins() []*AvailablePlugin {
 	if x != nil {
 		return x.Plugins
 	}
 	return nil
 }
 
-// 插件信息
-type PluginInfo struct {
+// 可用插件信息
+type AvailablePlugin struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
@@ -3515,30 +9626,34 @@ type PluginInfo struct {
 	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
 	Author        string                 `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
 	Icon          string                 `protobuf:"bytes,6,opt,name=icon,proto3" json:"icon,omitempty"`
-	State         PluginState            `protobuf:"varint,7,opt,name=state,proto3,enum=runixo.PluginState" json:"state,omitempty"`
-	Type          PluginType             `protobuf:"varint,8,opt,name=type,proto3,enum=runixo.PluginType" json:"type,omitempty"`
-	Permissions   []string               `protobuf:"bytes,9,rep,name=permissions,proto3" json:"permissions,omitempty"`
-	InstalledAt   int64                  `protobuf:"varint,10,opt,name=installed_at,json=installedAt,proto3" json:"installed_at,omitempty"`
-	UpdatedAt     int64                  `protobuf:"varint,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Type          PluginType             `protobuf:"varint,7,opt,name=type,proto3,enum=runixo.PluginType" json:"type,omitempty"`
+	Downloads     int64                  `protobuf:"varint,8,opt,name=downloads,proto3" json:"downloads,omitempty"`
+	Rating        float64                `protobuf:"fixed64,9,opt,name=rating,proto3" json:"rating,omitempty"`
+	RatingCount   int32                  `protobuf:"varint,10,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	Tags          []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	Category      string                 `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`
+	Official      bool                   `protobuf:"varint,13,opt,name=official,proto3" json:"official,omitempty"`
+	DownloadUrl   string                 `protobuf:"bytes,14,opt,name=download_url,json=downloadUrl,proto3" json:"download_url,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginInfo) Reset() {
-	*x = PluginInfo{}
-	mi := &file_agent_proto_msgTypes[48]
+func (x *AvailablePlugin) Reset() {
+	*x = AvailablePlugin{}
+	mi := &file_agent_proto_msgTypes[124]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginInfo) String() string {
+func (x *AvailablePlugin) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginInfo) ProtoMessage() {}
+func (*AvailablePlugin) ProtoMessage() {}
 
-func (x *PluginInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[48]
+func (x *AvailablePlugin) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[124]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3549,112 +9664,142 @@ func (x *PluginInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginInfo.ProtoReflect.Descriptor instead.
-func (*PluginInfo) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{48}
+// Deprecated: Use AvailablePlugin.ProtoReflect.Descriptor instead.
+func (*AvailablePlugin) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{124}
 }
 
-func (x *PluginInfo) GetId() string {
+func (x *AvailablePlugin) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *PluginInfo) GetName() string {
+func (x *AvailablePlugin) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *PluginInfo) GetVersion() string {
+func (x *AvailablePlugin) GetVersion() string {
 	if x != nil {
 		return x.Version
 	}
 	return ""
 }
 
-func (x *PluginInfo) GetDescription() string {
+func (x *AvailablePlugin) GetDescription() string {
 	if x != nil {
 		return x.Description
 	}
 	return ""
 }
 
-func (x *PluginInfo) GetAuthor() string {
+func (x *AvailablePlugin) GetAuthor() string {
 	if x != nil {
 		return x.Author
 	}
 	return ""
 }
 
-func (x *PluginInfo) GetIcon() string {
+func (x *AvailablePlugin) GetIcon() string {
 	if x != nil {
 		return x.Icon
 	}
 	return ""
 }
 
-func (x *PluginInfo) GetState() PluginState {
+func (x *AvailablePlugin) GetType() PluginType {
 	if x != nil {
-		return x.State
+		return x.Type
 	}
-	return PluginState_PLUGIN_INSTALLED
+	return PluginType_PLUGIN_CLIENT
 }
 
-func (x *PluginInfo) GetType() PluginType {
+func (x *AvailablePlugin) GetDownloads() int64 {
 	if x != nil {
-		return x.Type
+		return x.Downloads
 	}
-	return PluginType_PLUGIN_CLIENT
+	return 0
 }
 
-func (x *PluginInfo) GetPermissions() []string {
+func (x *AvailablePlugin) GetRating() float64 {
 	if x != nil {
-		return x.Permissions
+		return x.Rating
 	}
-	return nil
+	return 0
 }
 
-func (x *PluginInfo) GetInstalledAt() int64 {
+func (x *AvailablePlugin) GetRatingCount() int32 {
 	if x != nil {
-		return x.InstalledAt
+		return x.RatingCount
 	}
 	return 0
 }
 
-func (x *PluginInfo) GetUpdatedAt() int64 {
+func (x *AvailablePlugin) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *AvailablePlugin) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *AvailablePlugin) GetOfficial() bool {
+	if x != nil {
+		return x.Official
+	}
+	return false
+}
+
+func (x *AvailablePlugin) GetDownloadUrl() string {
+	if x != nil {
+		return x.DownloadUrl
+	}
+	return ""
+}
+
+func (x *AvailablePlugin) GetUpdatedAt() string {
 	if x != nil {
 		return x.UpdatedAt
 	}
-	return 0
+	return ""
 }
 
-// 插件配置
-type PluginConfig struct {
+type SearchPluginsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
-	ConfigJson    string                 `protobuf:"bytes,2,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"` // JSON 格式的配置
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`                        // 匹配 ID、名称、描述、作者和标签，不区分大小写
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`                  // 为空表示全部分类
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`                          // 须包含全部标签
+	Page          int32                  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`                         // 从 1 开始
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 默认 20，最大 100
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginConfig) Reset() {
-	*x = PluginConfig{}
-	mi := &file_agent_proto_msgTypes[49]
+func (x *SearchPluginsRequest) Reset() {
+	*x = SearchPluginsRequest{}
+	mi := &file_agent_proto_msgTypes[125]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginConfig) String() string {
+func (x *SearchPluginsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginConfig) ProtoMessage() {}
+func (*SearchPluginsRequest) ProtoMessage() {}
 
-func (x *PluginConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[49]
+func (x *SearchPluginsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[125]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3665,49 +9810,72 @@ func (x *PluginConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginConfig.ProtoReflect.Descriptor instead.
-func (*PluginConfig) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{49}
+// Deprecated: Use SearchPluginsRequest.ProtoReflect.Descriptor instead.
+func (*SearchPluginsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{125}
 }
 
-func (x *PluginConfig) GetPluginId() string {
+func (x *SearchPluginsRequest) GetQuery() string {
 	if x != nil {
-		return x.PluginId
+		return x.Query
 	}
 	return ""
 }
 
-func (x *PluginConfig) GetConfigJson() string {
+func (x *SearchPluginsRequest) GetCategory() string {
 	if x != nil {
-		return x.ConfigJson
+		return x.Category
 	}
 	return ""
 }
 
-// 设置插件配置请求
-type SetPluginConfigRequest struct {
+func (x *SearchPluginsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SearchPluginsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchPluginsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type SearchPluginsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
-	ConfigJson    string                 `protobuf:"bytes,2,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`
+	Plugins       []*AvailablePlugin     `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Categories    []*PluginCategory      `protobuf:"bytes,5,rep,name=categories,proto3" json:"categories,omitempty"` // 满足关键词和标签条件的插件按分类计数，不受 category 影响
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SetPluginConfigRequest) Reset() {
-	*x = SetPluginConfigRequest{}
-	mi := &file_agent_proto_msgTypes[50]
+func (x *SearchPluginsResponse) Reset() {
+	*x = SearchPluginsResponse{}
+	mi := &file_agent_proto_msgTypes[126]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SetPluginConfigRequest) String() string {
+func (x *SearchPluginsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetPluginConfigRequest) ProtoMessage() {}
+func (*SearchPluginsResponse) ProtoMessage() {}
 
-func (x *SetPluginConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[50]
+func (x *SearchPluginsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[126]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3718,53 +9886,125 @@ func (x *SetPluginConfigRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SetPluginConfigRequest.ProtoReflect.Descriptor instead.
-func (*SetPluginConfigRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{50}
+// Deprecated: Use SearchPluginsResponse.ProtoReflect.Descriptor instead.
+func (*SearchPluginsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{126}
 }
 
-func (x *SetPluginConfigRequest) GetPluginId() string {
+func (x *SearchPluginsResponse) GetPlugins() []*AvailablePlugin {
 	if x != nil {
-		return x.PluginId
+		return x.Plugins
 	}
-	return ""
+	return nil
 }
 
-func (x *SetPluginConfigRequest) GetConfigJson() string {
+func (x *SearchPluginsResponse) GetTotal() int32 {
 	if x != nil {
-		return x.ConfigJson
+		return x.Total
 	}
-	return ""
+	return 0
 }
 
-// 插件状态详情
-type PluginStatus struct {
+func (x *SearchPluginsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchPluginsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchPluginsResponse) GetCategories() []*PluginCategory {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+type PluginCategory struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
-	State         PluginState            `protobuf:"varint,2,opt,name=state,proto3,enum=runixo.PluginState" json:"state,omitempty"`
-	Running       bool                   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	Uptime        int64                  `protobuf:"varint,5,opt,name=uptime,proto3" json:"uptime,omitempty"`
-	Stats         map[string]string      `protobuf:"bytes,6,rep,name=stats,proto3" json:"stats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginStatus) Reset() {
-	*x = PluginStatus{}
-	mi := &file_agent_proto_msgTypes[51]
+func (x *PluginCategory) Reset() {
+	*x = PluginCategory{}
+	mi := &file_agent_proto_msgTypes[127]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginStatus) String() string {
+func (x *PluginCategory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginCategory) ProtoMessage() {}
+
+func (x *PluginCategory) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[127]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginCategory.ProtoReflect.Descriptor instead.
+func (*PluginCategory) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{127}
+}
+
+func (x *PluginCategory) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PluginCategory) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type PluginDetails struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Plugin           *AvailablePlugin       `protobuf:"bytes,1,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	Readme           string                 `protobuf:"bytes,2,opt,name=readme,proto3" json:"readme,omitempty"`           // Markdown
+	Screenshots      []string               `protobuf:"bytes,3,rep,name=screenshots,proto3" json:"screenshots,omitempty"` // https 地址
+	Installed        bool                   `protobuf:"varint,4,opt,name=installed,proto3" json:"installed,omitempty"`
+	InstalledVersion string                 `protobuf:"bytes,5,opt,name=installed_version,json=installedVersion,proto3" json:"installed_version,omitempty"`
+	UpdateAvailable  bool                   `protobuf:"varint,6,opt,name=update_available,json=updateAvailable,proto3" json:"update_available,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PluginDetails) Reset() {
+	*x = PluginDetails{}
+	mi := &file_agent_proto_msgTypes[128]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginDetails) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginStatus) ProtoMessage() {}
+func (*PluginDetails) ProtoMessage() {}
 
-func (x *PluginStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[51]
+func (x *PluginDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[128]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3775,76 +10015,119 @@ func (x *PluginStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginStatus.ProtoReflect.Descriptor instead.
-func (*PluginStatus) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{51}
+// Deprecated: Use PluginDetails.ProtoReflect.Descriptor instead.
+func (*PluginDetails) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{128}
 }
 
-func (x *PluginStatus) GetPluginId() string {
+func (x *PluginDetails) GetPlugin() *AvailablePlugin {
 	if x != nil {
-		return x.PluginId
+		return x.Plugin
+	}
+	return nil
+}
+
+func (x *PluginDetails) GetReadme() string {
+	if x != nil {
+		return x.Readme
 	}
 	return ""
 }
 
-func (x *PluginStatus) GetState() PluginState {
+func (x *PluginDetails) GetScreenshots() []string {
 	if x != nil {
-		return x.State
+		return x.Screenshots
 	}
-	return PluginState_PLUGIN_INSTALLED
+	return nil
 }
 
-func (x *PluginStatus) GetRunning() bool {
+func (x *PluginDetails) GetInstalled() bool {
 	if x != nil {
-		return x.Running
+		return x.Installed
 	}
 	return false
 }
 
-func (x *PluginStatus) GetError() string {
+func (x *PluginDetails) GetInstalledVersion() string {
 	if x != nil {
-		return x.Error
+		return x.InstalledVersion
 	}
 	return ""
 }
 
-func (x *PluginStatus) GetUptime() int64 {
+func (x *PluginDetails) GetUpdateAvailable() bool {
 	if x != nil {
-		return x.Uptime
+		return x.UpdateAvailable
 	}
-	return 0
+	return false
 }
 
-func (x *PluginStatus) GetStats() map[string]string {
+type ListScheduledTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"` // 为空时列出全部任务
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScheduledTasksRequest) Reset() {
+	*x = ListScheduledTasksRequest{}
+	mi := &file_agent_proto_msgTypes[129]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduledTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduledTasksRequest) ProtoMessage() {}
+
+func (x *ListScheduledTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[129]
 	if x != nil {
-		return x.Stats
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-// 可用插件列表
-type AvailablePluginList struct {
+// Deprecated: Use ListScheduledTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListScheduledTasksRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{129}
+}
+
+func (x *ListScheduledTasksRequest) GetPluginId() string {
+	if x != nil {
+		return x.PluginId
+	}
+	return ""
+}
+
+type ScheduledTaskList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plugins       []*AvailablePlugin     `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	Tasks         []*ScheduledTask       `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AvailablePluginList) Reset() {
-	*x = AvailablePluginList{}
-	mi := &file_agent_proto_msgTypes[52]
+func (x *ScheduledTaskList) Reset() {
+	*x = ScheduledTaskList{}
+	mi := &file_agent_proto_msgTypes[130]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AvailablePluginList) String() string {
+func (x *ScheduledTaskList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AvailablePluginList) ProtoMessage() {}
+func (*ScheduledTaskList) ProtoMessage() {}
 
-func (x *AvailablePluginList) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[52]
+func (x *ScheduledTaskList) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[130]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3855,55 +10138,54 @@ func (x *AvailablePluginList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AvailablePluginList.ProtoReflect.Descriptor instead.
-func (*AvailablePluginList) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{52}
+// Deprecated: Use ScheduledTaskList.ProtoReflect.Descriptor instead.
+func (*ScheduledTaskList) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{130}
 }
 
-func (x *AvailablePluginList) GetPlugins() []*AvailablePlugin {
+func (x *ScheduledTaskList) GetTasks() []*ScheduledTask {
 	if x != nil {
-		return x.Plugins
+		return x.Tasks
 	}
 	return nil
 }
 
-// 可用插件信息
-type AvailablePlugin struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	Author        string                 `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
-	Icon          string                 `protobuf:"bytes,6,opt,name=icon,proto3" json:"icon,omitempty"`
-	Type          PluginType             `protobuf:"varint,7,opt,name=type,proto3,enum=runixo.PluginType" json:"type,omitempty"`
-	Downloads     int64                  `protobuf:"varint,8,opt,name=downloads,proto3" json:"downloads,omitempty"`
-	Rating        float64                `protobuf:"fixed64,9,opt,name=rating,proto3" json:"rating,omitempty"`
-	RatingCount   int32                  `protobuf:"varint,10,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
-	Tags          []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
-	Category      string                 `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`
-	Official      bool                   `protobuf:"varint,13,opt,name=official,proto3" json:"official,omitempty"`
-	DownloadUrl   string                 `protobuf:"bytes,14,opt,name=download_url,json=downloadUrl,proto3" json:"download_url,omitempty"`
-	UpdatedAt     string                 `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type ScheduledTask struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`       // 插件任务为 <插件 ID>/<任务名>
+	Owner          string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"` // 插件 ID，核心模块的任务为空
+	Cron           string                 `protobuf:"bytes,3,opt,name=cron,proto3" json:"cron,omitempty"`
+	Timezone       string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Jitter         int64                  `protobuf:"varint,5,opt,name=jitter,proto3" json:"jitter,omitempty"`                       // 秒
+	MissedRun      string                 `protobuf:"bytes,6,opt,name=missed_run,json=missedRun,proto3" json:"missed_run,omitempty"` // skip 或 run_once
+	Running        bool                   `protobuf:"varint,7,opt,name=running,proto3" json:"running,omitempty"`
+	NextRun        int64                  `protobuf:"varint,8,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"` // Unix 时间，不会再触发时为 0
+	LastRun        int64                  `protobuf:"varint,9,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"` // Unix 时间，未运行过时为 0
+	LastDurationMs int64                  `protobuf:"varint,10,opt,name=last_duration_ms,json=lastDurationMs,proto3" json:"last_duration_ms,omitempty"`
+	LastError      string                 `protobuf:"bytes,11,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Runs           int32                  `protobuf:"varint,12,opt,name=runs,proto3" json:"runs,omitempty"`
+	Failures       int32                  `protobuf:"varint,13,opt,name=failures,proto3" json:"failures,omitempty"`
+	Overlaps       int32                  `protobuf:"varint,14,opt,name=overlaps,proto3" json:"overlaps,omitempty"` // 上次运行未结束而跳过的次数
+	Missed         int32                  `protobuf:"varint,15,opt,name=missed,proto3" json:"missed,omitempty"`     // 错过的触发次数
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *AvailablePlugin) Reset() {
-	*x = AvailablePlugin{}
-	mi := &file_agent_proto_msgTypes[53]
+func (x *ScheduledTask) Reset() {
+	*x = ScheduledTask{}
+	mi := &file_agent_proto_msgTypes[131]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AvailablePlugin) String() string {
+func (x *ScheduledTask) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AvailablePlugin) ProtoMessage() {}
+func (*ScheduledTask) ProtoMessage() {}
 
-func (x *AvailablePlugin) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[53]
+func (x *ScheduledTask) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[131]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3914,114 +10196,114 @@ func (x *AvailablePlugin) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AvailablePlugin.ProtoReflect.Descriptor instead.
-func (*AvailablePlugin) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{53}
+// Deprecated: Use ScheduledTask.ProtoReflect.Descriptor instead.
+func (*ScheduledTask) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{131}
 }
 
-func (x *AvailablePlugin) GetId() string {
+func (x *ScheduledTask) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *AvailablePlugin) GetName() string {
+func (x *ScheduledTask) GetOwner() string {
 	if x != nil {
-		return x.Name
+		return x.Owner
 	}
 	return ""
 }
 
-func (x *AvailablePlugin) GetVersion() string {
+func (x *ScheduledTask) GetCron() string {
 	if x != nil {
-		return x.Version
+		return x.Cron
 	}
 	return ""
 }
 
-func (x *AvailablePlugin) GetDescription() string {
+func (x *ScheduledTask) GetTimezone() string {
 	if x != nil {
-		return x.Description
+		return x.Timezone
 	}
 	return ""
 }
 
-func (x *AvailablePlugin) GetAuthor() string {
+func (x *ScheduledTask) GetJitter() int64 {
 	if x != nil {
-		return x.Author
+		return x.Jitter
 	}
-	return ""
+	return 0
 }
 
-func (x *AvailablePlugin) GetIcon() string {
+func (x *ScheduledTask) GetMissedRun() string {
 	if x != nil {
-		return x.Icon
+		return x.MissedRun
 	}
 	return ""
 }
 
-func (x *AvailablePlugin) GetType() PluginType {
+func (x *ScheduledTask) GetRunning() bool {
 	if x != nil {
-		return x.Type
+		return x.Running
 	}
-	return PluginType_PLUGIN_CLIENT
+	return false
 }
 
-func (x *AvailablePlugin) GetDownloads() int64 {
+func (x *ScheduledTask) GetNextRun() int64 {
 	if x != nil {
-		return x.Downloads
+		return x.NextRun
 	}
 	return 0
 }
 
-func (x *AvailablePlugin) GetRating() float64 {
+func (x *ScheduledTask) GetLastRun() int64 {
 	if x != nil {
-		return x.Rating
+		return x.LastRun
 	}
 	return 0
 }
 
-func (x *AvailablePlugin) GetRatingCount() int32 {
+func (x *ScheduledTask) GetLastDurationMs() int64 {
 	if x != nil {
-		return x.RatingCount
+		return x.LastDurationMs
 	}
 	return 0
 }
 
-func (x *AvailablePlugin) GetTags() []string {
+func (x *ScheduledTask) GetLastError() string {
 	if x != nil {
-		return x.Tags
+		return x.LastError
 	}
-	return nil
+	return ""
 }
 
-func (x *AvailablePlugin) GetCategory() string {
+func (x *ScheduledTask) GetRuns() int32 {
 	if x != nil {
-		return x.Category
+		return x.Runs
 	}
-	return ""
+	return 0
 }
 
-func (x *AvailablePlugin) GetOfficial() bool {
+func (x *ScheduledTask) GetFailures() int32 {
 	if x != nil {
-		return x.Official
+		return x.Failures
 	}
-	return false
+	return 0
 }
 
-func (x *AvailablePlugin) GetDownloadUrl() string {
+func (x *ScheduledTask) GetOverlaps() int32 {
 	if x != nil {
-		return x.DownloadUrl
+		return x.Overlaps
 	}
-	return ""
+	return 0
 }
 
-func (x *AvailablePlugin) GetUpdatedAt() string {
+func (x *ScheduledTask) GetMissed() int32 {
 	if x != nil {
-		return x.UpdatedAt
+		return x.Missed
 	}
-	return ""
+	return 0
 }
 
 // 更新信息
@@ -4042,7 +10324,7 @@ type UpdateInfo struct {
 
 func (x *UpdateInfo) Reset() {
 	*x = UpdateInfo{}
-	mi := &file_agent_proto_msgTypes[54]
+	mi := &file_agent_proto_msgTypes[132]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4054,7 +10336,7 @@ func (x *UpdateInfo) String() string {
 func (*UpdateInfo) ProtoMessage() {}
 
 func (x *UpdateInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[54]
+	mi := &file_agent_proto_msgTypes[132]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4067,7 +10349,7 @@ func (x *UpdateInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateInfo.ProtoReflect.Descriptor instead.
 func (*UpdateInfo) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{54}
+	return file_agent_proto_rawDescGZIP(), []int{132}
 }
 
 func (x *UpdateInfo) GetAvailable() bool {
@@ -4143,7 +10425,7 @@ type UpdateRequest struct {
 
 func (x *UpdateRequest) Reset() {
 	*x = UpdateRequest{}
-	mi := &file_agent_proto_msgTypes[55]
+	mi := &file_agent_proto_msgTypes[133]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4155,7 +10437,7 @@ func (x *UpdateRequest) String() string {
 func (*UpdateRequest) ProtoMessage() {}
 
 func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[55]
+	mi := &file_agent_proto_msgTypes[133]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4168,7 +10450,7 @@ func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
 func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{55}
+	return file_agent_proto_rawDescGZIP(), []int{133}
 }
 
 func (x *UpdateRequest) GetVersion() string {
@@ -4191,7 +10473,7 @@ type DownloadProgress struct {
 
 func (x *DownloadProgress) Reset() {
 	*x = DownloadProgress{}
-	mi := &file_agent_proto_msgTypes[56]
+	mi := &file_agent_proto_msgTypes[134]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4203,7 +10485,7 @@ func (x *DownloadProgress) String() string {
 func (*DownloadProgress) ProtoMessage() {}
 
 func (x *DownloadProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[56]
+	mi := &file_agent_proto_msgTypes[134]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4216,7 +10498,7 @@ func (x *DownloadProgress) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DownloadProgress.ProtoReflect.Descriptor instead.
 func (*DownloadProgress) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{56}
+	return file_agent_proto_rawDescGZIP(), []int{134}
 }
 
 func (x *DownloadProgress) GetDownloaded() int64 {
@@ -4261,7 +10543,7 @@ type UpdateConfig struct {
 
 func (x *UpdateConfig) Reset() {
 	*x = UpdateConfig{}
-	mi := &file_agent_proto_msgTypes[57]
+	mi := &file_agent_proto_msgTypes[135]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4273,7 +10555,7 @@ func (x *UpdateConfig) String() string {
 func (*UpdateConfig) ProtoMessage() {}
 
 func (x *UpdateConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[57]
+	mi := &file_agent_proto_msgTypes[135]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4286,7 +10568,7 @@ func (x *UpdateConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateConfig.ProtoReflect.Descriptor instead.
 func (*UpdateConfig) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{57}
+	return file_agent_proto_rawDescGZIP(), []int{135}
 }
 
 func (x *UpdateConfig) GetAutoUpdate() bool {
@@ -4334,7 +10616,7 @@ type UpdateHistory struct {
 
 func (x *UpdateHistory) Reset() {
 	*x = UpdateHistory{}
-	mi := &file_agent_proto_msgTypes[58]
+	mi := &file_agent_proto_msgTypes[136]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4346,7 +10628,7 @@ func (x *UpdateHistory) String() string {
 func (*UpdateHistory) ProtoMessage() {}
 
 func (x *UpdateHistory) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[58]
+	mi := &file_agent_proto_msgTypes[136]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4359,7 +10641,7 @@ func (x *UpdateHistory) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateHistory.ProtoReflect.Descriptor instead.
 func (*UpdateHistory) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{58}
+	return file_agent_proto_rawDescGZIP(), []int{136}
 }
 
 func (x *UpdateHistory) GetRecords() []*UpdateRecord {
@@ -4383,7 +10665,7 @@ type UpdateRecord struct {
 
 func (x *UpdateRecord) Reset() {
 	*x = UpdateRecord{}
-	mi := &file_agent_proto_msgTypes[59]
+	mi := &file_agent_proto_msgTypes[137]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4395,7 +10677,7 @@ func (x *UpdateRecord) String() string {
 func (*UpdateRecord) ProtoMessage() {}
 
 func (x *UpdateRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_proto_msgTypes[59]
+	mi := &file_agent_proto_msgTypes[137]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4408,7 +10690,7 @@ func (x *UpdateRecord) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateRecord.ProtoReflect.Descriptor instead.
 func (*UpdateRecord) Descriptor() ([]byte, []int) {
-	return file_agent_proto_rawDescGZIP(), []int{59}
+	return file_agent_proto_rawDescGZIP(), []int{137}
 }
 
 func (x *UpdateRecord) GetVersion() string {
@@ -4446,6 +10728,59 @@ func (x *UpdateRecord) GetError() string {
 	return ""
 }
 
+// 证书响应
+type CertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificate   string                 `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"` // PEM 格式的证书内容
+	Fingerprint   string                 `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"` // 证书指纹（SHA256）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateResponse) Reset() {
+	*x = CertificateResponse{}
+	mi := &file_agent_proto_msgTypes[138]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateResponse) ProtoMessage() {}
+
+func (x *CertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[138]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateResponse.ProtoReflect.Descriptor instead.
+func (*CertificateResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{138}
+}
+
+func (x *CertificateResponse) GetCertificate() string {
+	if x != nil {
+		return x.Certificate
+	}
+	return ""
+}
+
+func (x *CertificateResponse) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
 var File_agent_proto protoreflect.FileDescriptor
 
 const file_agent_proto_rawDesc = "" +
@@ -4460,7 +10795,7 @@ const file_agent_proto_rawDesc = "" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12#\n" +
 	"\ragent_version\x18\x03 \x01(\tR\fagentVersion\x12\x1d\n" +
 	"\n" +
-	"expires_at\x18\x04 \x01(\x03R\texpiresAt\"\xbc\x03\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\"\xba\x06\n" +
 	"\n" +
 	"SystemInfo\x12\x1a\n" +
 	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
@@ -4476,7 +10811,71 @@ const file_agent_proto_rawDesc = "" +
 	"\x06memory\x18\v \x01(\v2\x12.runixo.MemoryInfoR\x06memory\x12&\n" +
 	"\x05disks\x18\f \x03(\v2\x10.runixo.DiskInfoR\x05disks\x12/\n" +
 	"\bnetworks\x18\r \x03(\v2\x13.runixo.NetworkInfoR\bnetworks\x12#\n" +
-	"\x04gpus\x18\x14 \x03(\v2\x0f.runixo.GpuInfoR\x04gpus\"\x93\x01\n" +
+	"\x04gpus\x18\x14 \x03(\v2\x0f.runixo.GpuInfoR\x04gpus\x12)\n" +
+	"\x05units\x18\x15 \x01(\v2\x13.runixo.UnitSummaryR\x05units\x12)\n" +
+	"\x06logins\x18\x16 \x01(\v2\x11.runixo.LoginInfoR\x06logins\x12'\n" +
+	"\x05clock\x18\x17 \x01(\v2\x11.runixo.ClockSyncR\x05clock\x12*\n" +
+	"\x06distro\x18\x18 \x01(\v2\x12.runixo.DistroInfoR\x06distro\x12/\n" +
+	"\bpackages\x18\x19 \x01(\v2\x13.runixo.PackageInfoR\bpackages\x126\n" +
+	"\x06labels\x18\x1a \x03(\v2\x1e.runixo.SystemInfo.LabelsEntryR\x06labels\x12-\n" +
+	"\tpublic_ip\x18\x1b \x01(\v2\x10.runixo.PublicIPR\bpublicIp\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa7\x01\n" +
+	"\bPublicIP\x12\x12\n" +
+	"\x04ipv4\x18\x01 \x01(\tR\x04ipv4\x12\x12\n" +
+	"\x04ipv6\x18\x02 \x01(\tR\x04ipv6\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12\x10\n" +
+	"\x03asn\x18\x04 \x01(\tR\x03asn\x12\x10\n" +
+	"\x03org\x18\x05 \x01(\tR\x03org\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"checked_at\x18\a \x01(\x03R\tcheckedAt\"\x87\x01\n" +
+	"\n" +
+	"DistroInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1a\n" +
+	"\bcodename\x18\x04 \x01(\tR\bcodename\x12\x1f\n" +
+	"\vpretty_name\x18\x05 \x01(\tR\n" +
+	"prettyName\"\xa9\x01\n" +
+	"\vPackageInfo\x12\x18\n" +
+	"\amanager\x18\x01 \x01(\tR\amanager\x12\x1c\n" +
+	"\tinstalled\x18\x02 \x01(\x05R\tinstalled\x12\x18\n" +
+	"\aupdates\x18\x03 \x01(\x05R\aupdates\x12)\n" +
+	"\x10security_updates\x18\x04 \x01(\x05R\x0fsecurityUpdates\x12\x1d\n" +
+	"\n" +
+	"checked_at\x18\x05 \x01(\x03R\tcheckedAt\"\x91\x01\n" +
+	"\tClockSync\x12\"\n" +
+	"\fsynchronized\x18\x01 \x01(\bR\fsynchronized\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06server\x18\x03 \x01(\tR\x06server\x12\x18\n" +
+	"\astratum\x18\x04 \x01(\x05R\astratum\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x01R\x06offset\"l\n" +
+	"\fLoginSession\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x1a\n" +
+	"\bterminal\x18\x02 \x01(\tR\bterminal\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x18\n" +
+	"\astarted\x18\x04 \x01(\x03R\astarted\"\x7f\n" +
+	"\vLoginRecord\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x1a\n" +
+	"\bterminal\x18\x02 \x01(\tR\bterminal\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\x03R\x04time\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\"\x97\x01\n" +
+	"\tLoginInfo\x120\n" +
+	"\bsessions\x18\x01 \x03(\v2\x14.runixo.LoginSessionR\bsessions\x12+\n" +
+	"\x06recent\x18\x02 \x03(\v2\x13.runixo.LoginRecordR\x06recent\x12+\n" +
+	"\x06failed\x18\x03 \x03(\v2\x13.runixo.LoginRecordR\x06failed\"\xb2\x01\n" +
+	"\vUnitSummary\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x16\n" +
+	"\x06active\x18\x02 \x01(\x05R\x06active\x12\x1a\n" +
+	"\binactive\x18\x03 \x01(\x05R\binactive\x12\x1e\n" +
+	"\n" +
+	"activating\x18\x04 \x01(\x05R\n" +
+	"activating\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x05R\x06failed\x12!\n" +
+	"\ffailed_units\x18\x06 \x03(\tR\vfailedUnits\"\x93\x01\n" +
 	"\aCpuInfo\x12\x14\n" +
 	"\x05model\x18\x01 \x01(\tR\x05model\x12\x14\n" +
 	"\x05cores\x18\x02 \x01(\x05R\x05cores\x12\x18\n" +
@@ -4491,7 +10890,7 @@ const file_agent_proto_rawDesc = "" +
 	"\fused_percent\x18\x04 \x01(\x01R\vusedPercent\x12\x1d\n" +
 	"\n" +
 	"swap_total\x18\x05 \x01(\x04R\tswapTotal\x12\x1b\n" +
-	"\tswap_used\x18\x06 \x01(\x04R\bswapUsed\"\xbb\x01\n" +
+	"\tswap_used\x18\x06 \x01(\x04R\bswapUsed\"\xd0\x02\n" +
 	"\bDiskInfo\x12\x16\n" +
 	"\x06device\x18\x01 \x01(\tR\x06device\x12\x1e\n" +
 	"\n" +
@@ -4501,7 +10900,14 @@ const file_agent_proto_rawDesc = "" +
 	"\x05total\x18\x04 \x01(\x04R\x05total\x12\x12\n" +
 	"\x04used\x18\x05 \x01(\x04R\x04used\x12\x12\n" +
 	"\x04free\x18\x06 \x01(\x04R\x04free\x12!\n" +
-	"\fused_percent\x18\a \x01(\x01R\vusedPercent\"\x8f\x01\n" +
+	"\fused_percent\x18\a \x01(\x01R\vusedPercent\x12!\n" +
+	"\finodes_total\x18\b \x01(\x04R\vinodesTotal\x12\x1f\n" +
+	"\vinodes_used\x18\t \x01(\x04R\n" +
+	"inodesUsed\x12\x1f\n" +
+	"\vinodes_free\x18\n" +
+	" \x01(\x04R\n" +
+	"inodesFree\x12.\n" +
+	"\x13inodes_used_percent\x18\v \x01(\x01R\x11inodesUsedPercent\"\x8f\x01\n" +
 	"\vNetworkInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
 	"\taddresses\x18\x02 \x03(\tR\taddresses\x12\x10\n" +
@@ -4520,7 +10926,7 @@ const file_agent_proto_rawDesc = "" +
 	"\vutilization\x18\x06 \x01(\x01R\vutilization\"U\n" +
 	"\x0eMetricsRequest\x12)\n" +
 	"\x10interval_seconds\x18\x01 \x01(\x05R\x0fintervalSeconds\x12\x18\n" +
-	"\ametrics\x18\x02 \x03(\tR\ametrics\"\xa5\x02\n" +
+	"\ametrics\x18\x02 \x03(\tR\ametrics\"\xcb\t\n" +
 	"\aMetrics\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1b\n" +
 	"\tcpu_usage\x18\x02 \x01(\x01R\bcpuUsage\x12!\n" +
@@ -4529,7 +10935,97 @@ const file_agent_proto_rawDesc = "" +
 	"\x0fnetwork_metrics\x18\x05 \x03(\v2\x15.runixo.NetworkMetricR\x0enetworkMetrics\x12\x15\n" +
 	"\x06load_1\x18\x06 \x01(\x01R\x05load1\x12\x15\n" +
 	"\x06load_5\x18\a \x01(\x01R\x05load5\x12\x17\n" +
-	"\aload_15\x18\b \x01(\x01R\x06load15\"\xa4\x01\n" +
+	"\aload_15\x18\b \x01(\x01R\x06load15\x127\n" +
+	"\n" +
+	"containers\x18\t \x03(\v2\x17.runixo.ContainerMetricR\n" +
+	"containers\x12)\n" +
+	"\x05units\x18\n" +
+	" \x01(\v2\x13.runixo.UnitSummaryR\x05units\x12#\n" +
+	"\rprocs_running\x18\v \x01(\x05R\fprocsRunning\x12#\n" +
+	"\rprocs_blocked\x18\f \x01(\x05R\fprocsBlocked\x12\x16\n" +
+	"\x06uptime\x18\r \x01(\x03R\x06uptime\x12\x1b\n" +
+	"\tboot_time\x18\x0e \x01(\x03R\bbootTime\x12\x1d\n" +
+	"\n" +
+	"swap_total\x18\x0f \x01(\x04R\tswapTotal\x12\x1b\n" +
+	"\tswap_used\x18\x10 \x01(\x04R\bswapUsed\x12\x1b\n" +
+	"\tswap_free\x18\x11 \x01(\x04R\bswapFree\x12\x1d\n" +
+	"\n" +
+	"swap_usage\x18\x12 \x01(\x01R\tswapUsage\x12 \n" +
+	"\fswap_in_rate\x18\x13 \x01(\x04R\n" +
+	"swapInRate\x12\"\n" +
+	"\rswap_out_rate\x18\x14 \x01(\x04R\vswapOutRate\x12&\n" +
+	"\x03top\x18\x15 \x01(\v2\x14.runixo.TopProcessesR\x03top\x12\x18\n" +
+	"\azombies\x18\x16 \x01(\x05R\azombies\x12=\n" +
+	"\x0fstuck_processes\x18\x17 \x03(\v2\x14.runixo.StuckProcessR\x0estuckProcesses\x12'\n" +
+	"\x0fconntrack_count\x18\x18 \x01(\x03R\x0econntrackCount\x12#\n" +
+	"\rconntrack_max\x18\x19 \x01(\x03R\fconntrackMax\x12!\n" +
+	"\ffd_allocated\x18\x1a \x01(\x04R\vfdAllocated\x12\x15\n" +
+	"\x06fd_max\x18\x1b \x01(\x04R\x05fdMax\x123\n" +
+	"\rfd_near_limit\x18\x1c \x03(\v2\x0f.runixo.FdUsageR\vfdNearLimit\x12,\n" +
+	"\x06custom\x18\x1d \x03(\v2\x14.runixo.CustomSampleR\x06custom\x123\n" +
+	"\x06labels\x18\x1e \x03(\v2\x1b.runixo.Metrics.LabelsEntryR\x06labels\x12'\n" +
+	"\x05power\x18\x1f \x01(\v2\x11.runixo.PowerInfoR\x05power\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"v\n" +
+	"\tPowerInfo\x12\x1b\n" +
+	"\tac_online\x18\x01 \x01(\bR\bacOnline\x12\x1d\n" +
+	"\n" +
+	"on_battery\x18\x02 \x01(\bR\tonBattery\x12-\n" +
+	"\tbatteries\x18\x03 \x03(\v2\x0f.runixo.BatteryR\tbatteries\"m\n" +
+	"\aBattery\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x01R\apercent\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1c\n" +
+	"\tremaining\x18\x04 \x01(\x03R\tremaining\"\xed\x01\n" +
+	"\fCustomSample\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04help\x18\x03 \x01(\tR\x04help\x128\n" +
+	"\x06labels\x18\x04 \x03(\v2 .runixo.CustomSample.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04type\x18\x06 \x01(\tR\x04type\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"s\n" +
+	"\aFdUsage\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04open\x18\x03 \x01(\x05R\x04open\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x04R\x05limit\x12\x18\n" +
+	"\apercent\x18\x05 \x01(\x01R\apercent\"\x94\x01\n" +
+	"\fStuckProcess\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04user\x18\x03 \x01(\tR\x04user\x12\x18\n" +
+	"\acmdline\x18\x04 \x01(\tR\acmdline\x12\x14\n" +
+	"\x05since\x18\x05 \x01(\x03R\x05since\x12\x1a\n" +
+	"\bduration\x18\x06 \x01(\x01R\bduration\"\\\n" +
+	"\n" +
+	"TopProcess\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04user\x18\x03 \x01(\tR\x04user\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\"j\n" +
+	"\fTopProcesses\x12)\n" +
+	"\x06by_cpu\x18\x01 \x03(\v2\x12.runixo.TopProcessR\x05byCpu\x12/\n" +
+	"\tby_memory\x18\x02 \x03(\v2\x12.runixo.TopProcessR\bbyMemory\"\xd2\x02\n" +
+	"\x0fContainerMetric\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\tR\x05image\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x1f\n" +
+	"\vcpu_percent\x18\x05 \x01(\x01R\n" +
+	"cpuPercent\x12!\n" +
+	"\fmemory_usage\x18\x06 \x01(\x04R\vmemoryUsage\x12!\n" +
+	"\fmemory_limit\x18\a \x01(\x04R\vmemoryLimit\x12%\n" +
+	"\x0ememory_percent\x18\b \x01(\x01R\rmemoryPercent\x12\x1d\n" +
+	"\n" +
+	"network_rx\x18\t \x01(\x04R\tnetworkRx\x12\x1d\n" +
+	"\n" +
+	"network_tx\x18\n" +
+	" \x01(\x04R\tnetworkTx\x12#\n" +
+	"\rrestart_count\x18\v \x01(\x05R\frestartCount\"\xeb\x01\n" +
 	"\n" +
 	"DiskMetric\x12\x16\n" +
 	"\x06device\x18\x01 \x01(\tR\x06device\x12\x1d\n" +
@@ -4540,7 +11036,10 @@ const file_agent_proto_rawDesc = "" +
 	"\n" +
 	"read_count\x18\x04 \x01(\x04R\treadCount\x12\x1f\n" +
 	"\vwrite_count\x18\x05 \x01(\x04R\n" +
-	"writeCount\"\xb1\x01\n" +
+	"writeCount\x12\x14\n" +
+	"\x05await\x18\x06 \x01(\x01R\x05await\x12\x12\n" +
+	"\x04util\x18\a \x01(\x01R\x04util\x12\x1b\n" +
+	"\tin_flight\x18\b \x01(\x04R\binFlight\"\x95\x02\n" +
 	"\rNetworkMetric\x12\x1c\n" +
 	"\tinterface\x18\x01 \x01(\tR\tinterface\x12\x1d\n" +
 	"\n" +
@@ -4548,7 +11047,11 @@ const file_agent_proto_rawDesc = "" +
 	"\n" +
 	"bytes_recv\x18\x03 \x01(\x04R\tbytesRecv\x12!\n" +
 	"\fpackets_sent\x18\x04 \x01(\x04R\vpacketsSent\x12!\n" +
-	"\fpackets_recv\x18\x05 \x01(\x04R\vpacketsRecv\"\x87\x02\n" +
+	"\fpackets_recv\x18\x05 \x01(\x04R\vpacketsRecv\x12\x15\n" +
+	"\x06err_in\x18\x06 \x01(\x04R\x05errIn\x12\x17\n" +
+	"\aerr_out\x18\a \x01(\x04R\x06errOut\x12\x17\n" +
+	"\adrop_in\x18\b \x01(\x04R\x06dropIn\x12\x19\n" +
+	"\bdrop_out\x18\t \x01(\x04R\adropOut\"\xb7\x02\n" +
 	"\x0eCommandRequest\x12\x18\n" +
 	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
 	"\x04args\x18\x02 \x03(\tR\x04args\x12\x1f\n" +
@@ -4556,7 +11059,22 @@ const file_agent_proto_rawDesc = "" +
 	"workingDir\x121\n" +
 	"\x03env\x18\x04 \x03(\v2\x1f.runixo.CommandRequest.EnvEntryR\x03env\x12'\n" +
 	"\x0ftimeout_seconds\x18\x05 \x01(\x05R\x0etimeoutSeconds\x12\x12\n" +
-	"\x04sudo\x18\x06 \x01(\bR\x04sudo\x1a6\n" +
+	"\x04sudo\x18\x06 \x01(\bR\x04sudo\x12\x14\n" +
+	"\x05stdin\x18\a \x01(\fR\x05stdin\x12\x18\n" +
+	"\asandbox\x18\b \x01(\bR\asandbox\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc1\x02\n" +
+	"\rScriptRequest\x12\x16\n" +
+	"\x06script\x18\x01 \x01(\tR\x06script\x12 \n" +
+	"\vinterpreter\x18\x02 \x01(\tR\vinterpreter\x12\x12\n" +
+	"\x04args\x18\x03 \x03(\tR\x04args\x12\x1f\n" +
+	"\vworking_dir\x18\x04 \x01(\tR\n" +
+	"workingDir\x120\n" +
+	"\x03env\x18\x05 \x03(\v2\x1e.runixo.ScriptRequest.EnvEntryR\x03env\x12'\n" +
+	"\x0ftimeout_seconds\x18\x06 \x01(\x05R\x0etimeoutSeconds\x12\x14\n" +
+	"\x05stdin\x18\a \x01(\fR\x05stdin\x12\x18\n" +
+	"\asandbox\x18\b \x01(\bR\asandbox\x1a6\n" +
 	"\bEnvEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x7f\n" +
@@ -4565,32 +11083,108 @@ const file_agent_proto_rawDesc = "" +
 	"\x06stdout\x18\x02 \x01(\tR\x06stdout\x12\x16\n" +
 	"\x06stderr\x18\x03 \x01(\tR\x06stderr\x12\x1f\n" +
 	"\vduration_ms\x18\x04 \x01(\x03R\n" +
-	"durationMs\"\x86\x01\n" +
+	"durationMs\"\x81\x01\n" +
+	"\fBatchRequest\x122\n" +
+	"\bcommands\x18\x01 \x03(\v2\x16.runixo.CommandRequestR\bcommands\x12 \n" +
+	"\vconcurrency\x18\x02 \x01(\x05R\vconcurrency\x12\x1b\n" +
+	"\tfail_fast\x18\x03 \x01(\bR\bfailFast\"\xc6\x01\n" +
+	"\x12BatchCommandResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1b\n" +
+	"\texit_code\x18\x03 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stdout\x18\x04 \x01(\tR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x05 \x01(\tR\x06stderr\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\x80\x01\n" +
+	"\rBatchResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.runixo.BatchCommandResultR\aresults\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x03R\n" +
+	"durationMs\"x\n" +
+	"\rCommandOutput\x12\x18\n" +
+	"\x06stdout\x18\x01 \x01(\fH\x00R\x06stdout\x12\x18\n" +
+	"\x06stderr\x18\x02 \x01(\fH\x00R\x06stderr\x12)\n" +
+	"\x04exit\x18\x03 \x01(\v2\x13.runixo.CommandExitH\x00R\x04exitB\b\n" +
+	"\x06output\"a\n" +
+	"\vCommandExit\x12\x1b\n" +
+	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x1f\n" +
+	"\vduration_ms\x18\x02 \x01(\x03R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x8b\x01\n" +
+	"\x12ExecHistoryRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x02 \x01(\x03R\x05until\x12\x19\n" +
+	"\btoken_id\x18\x03 \x01(\tR\atokenId\x12\x18\n" +
+	"\acommand\x18\x04 \x01(\tR\acommand\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"\xa2\x03\n" +
+	"\n" +
+	"ExecRecord\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1b\n" +
+	"\tclient_ip\x18\x02 \x01(\tR\bclientIp\x12\x19\n" +
+	"\btoken_id\x18\x03 \x01(\tR\atokenId\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x18\n" +
+	"\acommand\x18\x05 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x06 \x03(\tR\x04args\x12\x1f\n" +
+	"\vworking_dir\x18\a \x01(\tR\n" +
+	"workingDir\x12\x1b\n" +
+	"\texit_code\x18\b \x01(\x05R\bexitCode\x12\x1f\n" +
+	"\vduration_ms\x18\t \x01(\x03R\n" +
+	"durationMs\x12#\n" +
+	"\routput_sha256\x18\n" +
+	" \x01(\tR\foutputSha256\x12\x1f\n" +
+	"\voutput_size\x18\v \x01(\x03R\n" +
+	"outputSize\x12#\n" +
+	"\rscript_sha256\x18\f \x01(\tR\fscriptSha256\x12\x18\n" +
+	"\asandbox\x18\r \x01(\bR\asandbox\x12\x14\n" +
+	"\x05error\x18\x0e \x01(\tR\x05error\"Y\n" +
+	"\vExecHistory\x12,\n" +
+	"\arecords\x18\x01 \x03(\v2\x12.runixo.ExecRecordR\arecords\x12\x1c\n" +
+	"\ttruncated\x18\x02 \x01(\bR\ttruncated\"\xa0\x01\n" +
 	"\n" +
 	"ShellInput\x12*\n" +
 	"\x05start\x18\x01 \x01(\v2\x12.runixo.ShellStartH\x00R\x05start\x12\x14\n" +
 	"\x04data\x18\x02 \x01(\fH\x00R\x04data\x12-\n" +
-	"\x06resize\x18\x03 \x01(\v2\x13.runixo.ShellResizeH\x00R\x06resizeB\a\n" +
-	"\x05input\"\xb1\x01\n" +
+	"\x06resize\x18\x03 \x01(\v2\x13.runixo.ShellResizeH\x00R\x06resize\x12\x18\n" +
+	"\x06signal\x18\x04 \x01(\tH\x00R\x06signalB\a\n" +
+	"\x05input\"\xd2\x01\n" +
 	"\n" +
 	"ShellStart\x12\x14\n" +
 	"\x05shell\x18\x01 \x01(\tR\x05shell\x12\x12\n" +
 	"\x04rows\x18\x02 \x01(\x05R\x04rows\x12\x12\n" +
 	"\x04cols\x18\x03 \x01(\x05R\x04cols\x12-\n" +
-	"\x03env\x18\x04 \x03(\v2\x1b.runixo.ShellStart.EnvEntryR\x03env\x1a6\n" +
+	"\x03env\x18\x04 \x03(\v2\x1b.runixo.ShellStart.EnvEntryR\x03env\x12\x1f\n" +
+	"\vworking_dir\x18\x05 \x01(\tR\n" +
+	"workingDir\x1a6\n" +
 	"\bEnvEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"5\n" +
 	"\vShellResize\x12\x12\n" +
 	"\x04rows\x18\x01 \x01(\x05R\x04rows\x12\x12\n" +
-	"\x04cols\x18\x02 \x01(\x05R\x04cols\"!\n" +
+	"\x04cols\x18\x02 \x01(\x05R\x04cols\"g\n" +
 	"\vShellOutput\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\fR\x04data\"!\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12%\n" +
+	"\x04exit\x18\x03 \x01(\v2\x11.runixo.ShellExitR\x04exit\"@\n" +
+	"\tShellExit\x12\x1b\n" +
+	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x86\x01\n" +
 	"\vFileRequest\x12\x12\n" +
-	"\x04path\x18\x01 \x01(\tR\x04path\"M\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\x123\n" +
+	"\bencoding\x18\x04 \x01(\x0e2\x17.runixo.ContentEncodingR\bencoding\"\xf3\x01\n" +
 	"\vFileContent\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\fR\acontent\x12$\n" +
-	"\x04info\x18\x02 \x01(\v2\x10.runixo.FileInfoR\x04info\"\xb8\x01\n" +
+	"\x04info\x18\x02 \x01(\v2\x10.runixo.FileInfoR\x04info\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x1c\n" +
+	"\ttruncated\x18\x04 \x01(\bR\ttruncated\x12\x16\n" +
+	"\x06length\x18\x05 \x01(\x03R\x06length\x12\x12\n" +
+	"\x04text\x18\x06 \x01(\tR\x04text\x12\x10\n" +
+	"\x03bom\x18\a \x01(\bR\x03bom\x12\x18\n" +
+	"\anewline\x18\b \x01(\tR\anewline\x12\x16\n" +
+	"\x06binary\x18\t \x01(\bR\x06binary\"\xb8\x01\n" +
 	"\bFileInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
@@ -4599,18 +11193,29 @@ const file_agent_proto_rawDesc = "" +
 	"\bmod_time\x18\x05 \x01(\x03R\amodTime\x12\x15\n" +
 	"\x06is_dir\x18\x06 \x01(\bR\x05isDir\x12\x14\n" +
 	"\x05owner\x18\a \x01(\tR\x05owner\x12\x14\n" +
-	"\x05group\x18\b \x01(\tR\x05group\"u\n" +
+	"\x05group\x18\b \x01(\tR\x05group\"\xd7\x02\n" +
 	"\x10WriteFileRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\fR\acontent\x12\x12\n" +
 	"\x04mode\x18\x03 \x01(\x03R\x04mode\x12\x1f\n" +
 	"\vcreate_dirs\x18\x04 \x01(\bR\n" +
-	"createDirs\"\x87\x01\n" +
+	"createDirs\x12\x16\n" +
+	"\x06atomic\x18\x05 \x01(\bR\x06atomic\x12\x16\n" +
+	"\x06backup\x18\x06 \x01(\bR\x06backup\x12\x1f\n" +
+	"\vmax_backups\x18\a \x01(\x05R\n" +
+	"maxBackups\x12\x1a\n" +
+	"\bvalidate\x18\b \x03(\tR\bvalidate\x123\n" +
+	"\bencoding\x18\t \x01(\x0e2\x17.runixo.ContentEncodingR\bencoding\x12\x12\n" +
+	"\x04text\x18\n" +
+	" \x01(\tR\x04text\x12\x18\n" +
+	"\anewline\x18\v \x01(\tR\anewline\x12\x10\n" +
+	"\x03bom\x18\f \x01(\bR\x03bom\"\x9f\x01\n" +
 	"\tFileChunk\x12/\n" +
 	"\x05start\x18\x01 \x01(\v2\x17.runixo.FileUploadStartH\x00R\x05start\x12\x16\n" +
 	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunk\x12)\n" +
-	"\x03end\x18\x03 \x01(\v2\x15.runixo.FileUploadEndH\x00R\x03endB\x06\n" +
-	"\x04data\"\xd0\x01\n" +
+	"\x03end\x18\x03 \x01(\v2\x15.runixo.FileUploadEndH\x00R\x03end\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x03R\x06offsetB\x06\n" +
+	"\x04data\"\xe8\x01\n" +
 	"\x0fFileUploadStart\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
 	"\n" +
@@ -4621,15 +11226,149 @@ const file_agent_proto_rawDesc = "" +
 	"\bchecksum\x18\x05 \x01(\tR\bchecksum\x12\x1a\n" +
 	"\tis_tar_gz\x18\x06 \x01(\bR\aisTarGz\x12\x1d\n" +
 	"\n" +
-	"extract_to\x18\a \x01(\tR\textractTo\"+\n" +
+	"extract_to\x18\a \x01(\tR\textractTo\x12\x16\n" +
+	"\x06offset\x18\b \x01(\x03R\x06offset\"+\n" +
 	"\rFileUploadEnd\x12\x1a\n" +
-	"\bchecksum\x18\x01 \x01(\tR\bchecksum\"\x93\x01\n" +
+	"\bchecksum\x18\x01 \x01(\tR\bchecksum\"\xaf\x01\n" +
 	"\x0eUploadResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
 	"\x05error\x18\x03 \x01(\tR\x05error\x12#\n" +
 	"\rbytes_written\x18\x04 \x01(\x03R\fbytesWritten\x12\x12\n" +
-	"\x04path\x18\x05 \x01(\tR\x04path\"_\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12\x1a\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\"&\n" +
+	"\fUploadOffset\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\"C\n" +
+	"\x0fHashFileRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
+	"\talgorithm\x18\x02 \x01(\tR\talgorithm\"d\n" +
+	"\bFileHash\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
+	"\talgorithm\x18\x02 \x01(\tR\talgorithm\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"C\n" +
+	"\x13CompareFilesRequest\x12\x15\n" +
+	"\x06path_a\x18\x01 \x01(\tR\x05pathA\x12\x15\n" +
+	"\x06path_b\x18\x02 \x01(\tR\x05pathB\"\x7f\n" +
+	"\x0eFileComparison\x12\x14\n" +
+	"\x05equal\x18\x01 \x01(\bR\x05equal\x12\x15\n" +
+	"\x06size_a\x18\x02 \x01(\x03R\x05sizeA\x12\x15\n" +
+	"\x06size_b\x18\x03 \x01(\x03R\x05sizeB\x12)\n" +
+	"\x10first_difference\x18\x04 \x01(\x03R\x0ffirstDifference\"\x8b\x03\n" +
+	"\x12SearchFilesRequest\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12\x18\n" +
+	"\apattern\x18\x02 \x01(\tR\apattern\x12\x19\n" +
+	"\bmin_size\x18\x03 \x01(\x03R\aminSize\x12\x19\n" +
+	"\bmax_size\x18\x04 \x01(\x03R\amaxSize\x12%\n" +
+	"\x0emodified_after\x18\x05 \x01(\x03R\rmodifiedAfter\x12'\n" +
+	"\x0fmodified_before\x18\x06 \x01(\x03R\x0emodifiedBefore\x12\x18\n" +
+	"\acontent\x18\a \x01(\tR\acontent\x12\x1f\n" +
+	"\vmax_matches\x18\b \x01(\x05R\n" +
+	"maxMatches\x12\x1f\n" +
+	"\vmax_results\x18\t \x01(\x05R\n" +
+	"maxResults\x12\x1b\n" +
+	"\tmax_depth\x18\n" +
+	" \x01(\x05R\bmaxDepth\x12\x1f\n" +
+	"\vshow_hidden\x18\v \x01(\bR\n" +
+	"showHidden\x12'\n" +
+	"\x0ftimeout_seconds\x18\f \x01(\x05R\x0etimeoutSeconds\"5\n" +
+	"\vSearchMatch\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"c\n" +
+	"\fSearchResult\x12$\n" +
+	"\x04file\x18\x01 \x01(\v2\x10.runixo.FileInfoR\x04file\x12-\n" +
+	"\amatches\x18\x02 \x03(\v2\x13.runixo.SearchMatchR\amatches\"c\n" +
+	"\x13SearchFilesResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.runixo.SearchResultR\aresults\x12\x1c\n" +
+	"\ttruncated\x18\x02 \x01(\bR\ttruncated\"X\n" +
+	"\x14CreateArchiveRequest\x12\x14\n" +
+	"\x05paths\x18\x01 \x03(\tR\x05paths\x12\x12\n" +
+	"\x04dest\x18\x02 \x01(\tR\x04dest\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\"E\n" +
+	"\x15ExtractArchiveRequest\x12\x18\n" +
+	"\aarchive\x18\x01 \x01(\tR\aarchive\x12\x12\n" +
+	"\x04dest\x18\x02 \x01(\tR\x04dest\"\xa0\x01\n" +
+	"\x0fArchiveProgress\x12\x14\n" +
+	"\x05files\x18\x01 \x01(\x03R\x05files\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\x03 \x01(\x03R\n" +
+	"totalBytes\x12\x18\n" +
+	"\acurrent\x18\x04 \x01(\tR\acurrent\x12\x12\n" +
+	"\x04done\x18\x05 \x01(\bR\x04done\x12\x12\n" +
+	"\x04path\x18\x06 \x01(\tR\x04path\"m\n" +
+	"\fChmodRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x1c\n" +
+	"\trecursive\x18\x03 \x01(\bR\trecursive\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"o\n" +
+	"\fChownRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x1c\n" +
+	"\trecursive\x18\x03 \x01(\bR\trecursive\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\x8c\x01\n" +
+	"\rSetACLRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aentries\x18\x02 \x03(\tR\aentries\x12\x16\n" +
+	"\x06remove\x18\x03 \x01(\bR\x06remove\x12\x1c\n" +
+	"\trecursive\x18\x04 \x01(\bR\trecursive\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\"J\n" +
+	"\x10PermissionChange\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x10\n" +
+	"\x03old\x18\x02 \x01(\tR\x03old\x12\x10\n" +
+	"\x03new\x18\x03 \x01(\tR\x03new\"\x91\x01\n" +
+	"\x10PermissionResult\x122\n" +
+	"\achanges\x18\x01 \x03(\v2\x18.runixo.PermissionChangeR\achanges\x12\x18\n" +
+	"\achanged\x18\x02 \x01(\x05R\achanged\x12\x16\n" +
+	"\x06errors\x18\x03 \x03(\tR\x06errors\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\x8a\x01\n" +
+	"\x0fCopyPathRequest\x12\x10\n" +
+	"\x03src\x18\x01 \x01(\tR\x03src\x12\x10\n" +
+	"\x03dst\x18\x02 \x01(\tR\x03dst\x12\x1c\n" +
+	"\trecursive\x18\x03 \x01(\bR\trecursive\x125\n" +
+	"\toverwrite\x18\x04 \x01(\x0e2\x17.runixo.OverwritePolicyR\toverwrite\"l\n" +
+	"\x0fMovePathRequest\x12\x10\n" +
+	"\x03src\x18\x01 \x01(\tR\x03src\x12\x10\n" +
+	"\x03dst\x18\x02 \x01(\tR\x03dst\x125\n" +
+	"\toverwrite\x18\x03 \x01(\x0e2\x17.runixo.OverwritePolicyR\toverwrite\"[\n" +
+	"\x11DeletePathRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\x12\x14\n" +
+	"\x05trash\x18\x03 \x01(\bR\x05trash\"\x83\x01\n" +
+	"\fFileOpResult\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05files\x18\x02 \x01(\x03R\x05files\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x12\x18\n" +
+	"\askipped\x18\x04 \x01(\x03R\askipped\x12\x19\n" +
+	"\btrash_id\x18\x05 \x01(\tR\atrashId\"w\n" +
+	"\n" +
+	"TrashEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\roriginal_path\x18\x02 \x01(\tR\foriginalPath\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x03 \x01(\x03R\tdeletedAt\x12\x15\n" +
+	"\x06is_dir\x18\x04 \x01(\bR\x05isDir\"9\n" +
+	"\tTrashList\x12,\n" +
+	"\aentries\x18\x01 \x03(\v2\x12.runixo.TrashEntryR\aentries\"%\n" +
+	"\x13RestoreTrashRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xbf\x01\n" +
+	"\x14DirectorySizeRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\x12\x10\n" +
+	"\x03top\x18\x03 \x01(\x05R\x03top\x12\x1f\n" +
+	"\vmax_entries\x18\x04 \x01(\x03R\n" +
+	"maxEntries\x12!\n" +
+	"\fcross_mounts\x18\x05 \x01(\bR\vcrossMounts\x12'\n" +
+	"\x0ftimeout_seconds\x18\x06 \x01(\x05R\x0etimeoutSeconds\"\xa8\x01\n" +
+	"\rDirectorySize\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04disk\x18\x03 \x01(\x03R\x04disk\x12\x14\n" +
+	"\x05files\x18\x04 \x01(\x03R\x05files\x12\x12\n" +
+	"\x04dirs\x18\x05 \x01(\x03R\x04dirs\x121\n" +
+	"\bchildren\x18\x06 \x03(\v2\x15.runixo.DirectorySizeR\bchildren\"`\n" +
+	"\x15DirectorySizeResponse\x12)\n" +
+	"\x04root\x18\x01 \x01(\v2\x15.runixo.DirectorySizeR\x04root\x12\x1c\n" +
+	"\ttruncated\x18\x02 \x01(\bR\ttruncated\"_\n" +
 	"\n" +
 	"DirRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
@@ -4639,12 +11378,13 @@ const file_agent_proto_rawDesc = "" +
 	"\n" +
 	"DirContent\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12&\n" +
-	"\x05files\x18\x02 \x03(\v2\x10.runixo.FileInfoR\x05files\"N\n" +
+	"\x05files\x18\x02 \x03(\v2\x10.runixo.FileInfoR\x05files\"f\n" +
 	"\n" +
 	"LogRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
 	"\x05lines\x18\x02 \x01(\x05R\x05lines\x12\x16\n" +
-	"\x06follow\x18\x03 \x01(\bR\x06follow\"A\n" +
+	"\x06follow\x18\x03 \x01(\bR\x06follow\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\"A\n" +
 	"\aLogLine\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\tR\acontent\x12\x1c\n" +
 	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"U\n" +
@@ -4670,7 +11410,7 @@ const file_agent_proto_rawDesc = "" +
 	"\vuser_filter\x18\x02 \x01(\tR\n" +
 	"userFilter\"@\n" +
 	"\vProcessList\x121\n" +
-	"\tprocesses\x18\x01 \x03(\v2\x13.runixo.ProcessInfoR\tprocesses\"\x95\x02\n" +
+	"\tprocesses\x18\x01 \x03(\v2\x13.runixo.ProcessInfoR\tprocesses\"\xfa\x03\n" +
 	"\vProcessInfo\x12\x10\n" +
 	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
 	"\x04ppid\x18\x02 \x01(\x05R\x04ppid\x12\x12\n" +
@@ -4685,14 +11425,55 @@ const file_agent_proto_rawDesc = "" +
 	"\vcreate_time\x18\t \x01(\x03R\n" +
 	"createTime\x12\x18\n" +
 	"\acmdline\x18\n" +
-	" \x01(\tR\acmdline\">\n" +
+	" \x01(\tR\acmdline\x12\x1f\n" +
+	"\vnum_threads\x18\v \x01(\x05R\n" +
+	"numThreads\x12\x17\n" +
+	"\anum_fds\x18\f \x01(\x05R\x06numFds\x12\"\n" +
+	"\rio_read_bytes\x18\r \x01(\x04R\vioReadBytes\x12$\n" +
+	"\x0eio_write_bytes\x18\x0e \x01(\x04R\fioWriteBytes\x12 \n" +
+	"\fio_read_rate\x18\x0f \x01(\x01R\n" +
+	"ioReadRate\x12\"\n" +
+	"\rio_write_rate\x18\x10 \x01(\x01R\vioWriteRate\x12\x19\n" +
+	"\bfd_limit\x18\x11 \x01(\x04R\afdLimit\"2\n" +
+	"\x12ProcessTreeRequest\x12\x1c\n" +
+	"\taggregate\x18\x01 \x01(\bR\taggregate\"\x97\x02\n" +
+	"\vProcessNode\x12-\n" +
+	"\aprocess\x18\x01 \x01(\v2\x13.runixo.ProcessInfoR\aprocess\x12/\n" +
+	"\bchildren\x18\x02 \x03(\v2\x13.runixo.ProcessNodeR\bchildren\x12*\n" +
+	"\x11total_cpu_percent\x18\x03 \x01(\x01R\x0ftotalCpuPercent\x12(\n" +
+	"\x10total_memory_rss\x18\x04 \x01(\x04R\x0etotalMemoryRss\x120\n" +
+	"\x14total_memory_percent\x18\x05 \x01(\x01R\x12totalMemoryPercent\x12 \n" +
+	"\vdescendants\x18\x06 \x01(\x05R\vdescendants\"8\n" +
+	"\vProcessTree\x12)\n" +
+	"\x05roots\x18\x01 \x03(\v2\x13.runixo.ProcessNodeR\x05roots\"\xa7\x01\n" +
+	"\rListeningPort\x12\x1a\n" +
+	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\rR\x04port\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\x12\x18\n" +
+	"\aprocess\x18\x05 \x01(\tR\aprocess\x12 \n" +
+	"\vestablished\x18\x06 \x01(\x05R\vestablished\"\xef\x01\n" +
+	"\x12NetworkConnections\x123\n" +
+	"\tlistening\x18\x01 \x03(\v2\x15.runixo.ListeningPortR\tlistening\x12N\n" +
+	"\fstate_counts\x18\x02 \x03(\v2+.runixo.NetworkConnections.StateCountsEntryR\vstateCounts\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x1a>\n" +
+	"\x10StateCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\">\n" +
 	"\x12KillProcessRequest\x12\x10\n" +
 	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x16\n" +
-	"\x06signal\x18\x02 \x01(\x05R\x06signal\"Z\n" +
+	"\x06signal\x18\x02 \x01(\x05R\x06signal\"\xa6\x01\n" +
 	"\x0eActionResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"\\\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\tR\x04code\x126\n" +
+	"\n" +
+	"violations\x18\x05 \x03(\v2\x16.runixo.FieldViolationR\n" +
+	"violations\"H\n" +
+	"\x0eFieldViolation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"\\\n" +
 	"\x13DockerSearchRequest\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
 	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x12\n" +
@@ -4735,12 +11516,22 @@ const file_agent_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\",\n" +
 	"\rPluginRequest\x12\x1b\n" +
-	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\"q\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\"\xa7\x01\n" +
 	"\x14InstallPluginRequest\x12\x1b\n" +
 	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x16\n" +
 	"\x06source\x18\x02 \x01(\tR\x06source\x12\x10\n" +
 	"\x03url\x18\x03 \x01(\tR\x03url\x12\x12\n" +
-	"\x04data\x18\x04 \x01(\fR\x04data\":\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\x12\x1c\n" +
+	"\tsignature\x18\x05 \x01(\tR\tsignature\x12\x16\n" +
+	"\x06sha256\x18\x06 \x01(\tR\x06sha256\"a\n" +
+	"\fPluginUpload\x121\n" +
+	"\x05start\x18\x01 \x01(\v2\x19.runixo.PluginUploadStartH\x00R\x05start\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"f\n" +
+	"\x11PluginUploadStart\x12\x1b\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\tR\tsignature\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\":\n" +
 	"\n" +
 	"PluginList\x12,\n" +
 	"\aplugins\x18\x01 \x03(\v2\x12.runixo.PluginInfoR\aplugins\"\xcf\x02\n" +
@@ -4758,26 +11549,98 @@ const file_agent_proto_rawDesc = "" +
 	"\finstalled_at\x18\n" +
 	" \x01(\x03R\vinstalledAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\v \x01(\x03R\tupdatedAt\"L\n" +
+	"updated_at\x18\v \x01(\x03R\tupdatedAt\"m\n" +
 	"\fPluginConfig\x12\x1b\n" +
 	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x1f\n" +
 	"\vconfig_json\x18\x02 \x01(\tR\n" +
-	"configJson\"V\n" +
+	"configJson\x12\x1f\n" +
+	"\vschema_json\x18\x03 \x01(\tR\n" +
+	"schemaJson\"V\n" +
 	"\x16SetPluginConfigRequest\x12\x1b\n" +
 	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x1f\n" +
 	"\vconfig_json\x18\x02 \x01(\tR\n" +
-	"configJson\"\x8f\x02\n" +
+	"configJson\"\xdc\x03\n" +
 	"\fPluginStatus\x12\x1b\n" +
 	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12)\n" +
 	"\x05state\x18\x02 \x01(\x0e2\x13.runixo.PluginStateR\x05state\x12\x18\n" +
 	"\arunning\x18\x03 \x01(\bR\arunning\x12\x14\n" +
 	"\x05error\x18\x04 \x01(\tR\x05error\x12\x16\n" +
 	"\x06uptime\x18\x05 \x01(\x03R\x06uptime\x125\n" +
-	"\x05stats\x18\x06 \x03(\v2\x1f.runixo.PluginStatus.StatsEntryR\x05stats\x1a8\n" +
+	"\x05stats\x18\x06 \x03(\v2\x1f.runixo.PluginStatus.StatsEntryR\x05stats\x121\n" +
+	"\x05usage\x18\a \x01(\v2\x1b.runixo.PluginResourceUsageR\x05usage\x12\x1a\n" +
+	"\brestarts\x18\b \x01(\x05R\brestarts\x12!\n" +
+	"\fnext_restart\x18\t \x01(\x03R\vnextRestart\x12*\n" +
+	"\x11last_health_check\x18\n" +
+	" \x01(\x03R\x0flastHealthCheck\x12-\n" +
+	"\x12permission_denials\x18\v \x01(\x05R\x11permissionDenials\x1a8\n" +
 	"\n" +
 	"StatsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"H\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xab\x01\n" +
+	"\x12PluginVerifyResult\x12\x1b\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x0e\n" +
+	"\x02ok\x18\x02 \x01(\bR\x02ok\x12.\n" +
+	"\x05steps\x18\x03 \x03(\v2\x18.runixo.PluginVerifyStepR\x05steps\x128\n" +
+	"\adenials\x18\x04 \x03(\v2\x1e.runixo.PluginPermissionDenialR\adenials\"y\n" +
+	"\x10PluginVerifyStep\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x03R\n" +
+	"durationMs\"h\n" +
+	"\x16PluginPermissionDenial\x12\x1e\n" +
+	"\n" +
+	"permission\x18\x01 \x01(\tR\n" +
+	"permission\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\x03R\x04time\"\xa0\x01\n" +
+	"\x11PluginDiagnostics\x12\x1b\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x1f\n" +
+	"\vcaptured_at\x18\x02 \x01(\x03R\n" +
+	"capturedAt\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05panic\x18\x04 \x01(\tR\x05panic\x12\x1f\n" +
+	"\vbundle_json\x18\x05 \x01(\tR\n" +
+	"bundleJson\"<\n" +
+	"\x1aExportPluginConfigsRequest\x12\x1e\n" +
+	"\n" +
+	"passphrase\x18\x01 \x01(\tR\n" +
+	"passphrase\"F\n" +
+	"\x12PluginConfigBundle\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1c\n" +
+	"\tencrypted\x18\x02 \x01(\bR\tencrypted\"\x98\x01\n" +
+	"\x1aImportPluginConfigsRequest\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1e\n" +
+	"\n" +
+	"passphrase\x18\x02 \x01(\tR\n" +
+	"passphrase\x12'\n" +
+	"\x0finstall_missing\x18\x03 \x01(\bR\x0einstallMissing\x12\x1d\n" +
+	"\n" +
+	"plugin_ids\x18\x04 \x03(\tR\tpluginIds\"S\n" +
+	"\x1bImportPluginConfigsResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.runixo.PluginImportResultR\aresults\"c\n" +
+	"\x12PluginImportResult\x12\x1b\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xb6\x02\n" +
+	"\x13PluginResourceUsage\x12\x1e\n" +
+	"\n" +
+	"accounting\x18\x01 \x01(\tR\n" +
+	"accounting\x12\x1f\n" +
+	"\vcpu_percent\x18\x02 \x01(\x01R\n" +
+	"cpuPercent\x12\x16\n" +
+	"\x06memory\x18\x03 \x01(\x04R\x06memory\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\x04 \x01(\x05R\n" +
+	"goroutines\x12\x1b\n" +
+	"\tcpu_limit\x18\x05 \x01(\x01R\bcpuLimit\x12!\n" +
+	"\fmemory_limit\x18\x06 \x01(\x04R\vmemoryLimit\x12'\n" +
+	"\x0fgoroutine_limit\x18\a \x01(\x05R\x0egoroutineLimit\x12\x1e\n" +
+	"\n" +
+	"violations\x18\b \x01(\x05R\n" +
+	"violations\x12\x1d\n" +
+	"\n" +
+	"sampled_at\x18\t \x01(\x03R\tsampledAt\"H\n" +
 	"\x13AvailablePluginList\x121\n" +
 	"\aplugins\x18\x01 \x03(\v2\x17.runixo.AvailablePluginR\aplugins\"\xac\x03\n" +
 	"\x0fAvailablePlugin\x12\x0e\n" +
@@ -4797,7 +11660,54 @@ const file_agent_proto_rawDesc = "" +
 	"\bofficial\x18\r \x01(\bR\bofficial\x12!\n" +
 	"\fdownload_url\x18\x0e \x01(\tR\vdownloadUrl\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\x0f \x01(\tR\tupdatedAt\"\xb6\x02\n" +
+	"updated_at\x18\x0f \x01(\tR\tupdatedAt\"\x8d\x01\n" +
+	"\x14SearchPluginsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\"\xc9\x01\n" +
+	"\x15SearchPluginsResponse\x121\n" +
+	"\aplugins\x18\x01 \x03(\v2\x17.runixo.AvailablePluginR\aplugins\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x126\n" +
+	"\n" +
+	"categories\x18\x05 \x03(\v2\x16.runixo.PluginCategoryR\n" +
+	"categories\":\n" +
+	"\x0ePluginCategory\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\xf0\x01\n" +
+	"\rPluginDetails\x12/\n" +
+	"\x06plugin\x18\x01 \x01(\v2\x17.runixo.AvailablePluginR\x06plugin\x12\x16\n" +
+	"\x06readme\x18\x02 \x01(\tR\x06readme\x12 \n" +
+	"\vscreenshots\x18\x03 \x03(\tR\vscreenshots\x12\x1c\n" +
+	"\tinstalled\x18\x04 \x01(\bR\tinstalled\x12+\n" +
+	"\x11installed_version\x18\x05 \x01(\tR\x10installedVersion\x12)\n" +
+	"\x10update_available\x18\x06 \x01(\bR\x0fupdateAvailable\"8\n" +
+	"\x19ListScheduledTasksRequest\x12\x1b\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\"@\n" +
+	"\x11ScheduledTaskList\x12+\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x15.runixo.ScheduledTaskR\x05tasks\"\x99\x03\n" +
+	"\rScheduledTask\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04cron\x18\x03 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12\x16\n" +
+	"\x06jitter\x18\x05 \x01(\x03R\x06jitter\x12\x1d\n" +
+	"\n" +
+	"missed_run\x18\x06 \x01(\tR\tmissedRun\x12\x18\n" +
+	"\arunning\x18\a \x01(\bR\arunning\x12\x19\n" +
+	"\bnext_run\x18\b \x01(\x03R\anextRun\x12\x19\n" +
+	"\blast_run\x18\t \x01(\x03R\alastRun\x12(\n" +
+	"\x10last_duration_ms\x18\n" +
+	" \x01(\x03R\x0elastDurationMs\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\v \x01(\tR\tlastError\x12\x12\n" +
+	"\x04runs\x18\f \x01(\x05R\x04runs\x12\x1a\n" +
+	"\bfailures\x18\r \x01(\x05R\bfailures\x12\x1a\n" +
+	"\boverlaps\x18\x0e \x01(\x05R\boverlaps\x12\x16\n" +
+	"\x06missed\x18\x0f \x01(\x05R\x06missed\"\xb6\x02\n" +
 	"\n" +
 	"UpdateInfo\x12\x1c\n" +
 	"\tavailable\x18\x01 \x01(\bR\tavailable\x12'\n" +
@@ -4835,7 +11745,19 @@ const file_agent_proto_rawDesc = "" +
 	"\ffrom_version\x18\x02 \x01(\tR\vfromVersion\x12\x1c\n" +
 	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12\x18\n" +
 	"\asuccess\x18\x04 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error*r\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"Y\n" +
+	"\x13CertificateResponse\x12 \n" +
+	"\vcertificate\x18\x01 \x01(\tR\vcertificate\x12 \n" +
+	"\vfingerprint\x18\x02 \x01(\tR\vfingerprint*H\n" +
+	"\x0fContentEncoding\x12\x0f\n" +
+	"\vCONTENT_RAW\x10\x00\x12\x12\n" +
+	"\x0eCONTENT_BASE64\x10\x01\x12\x10\n" +
+	"\fCONTENT_UTF8\x10\x02*h\n" +
+	"\x0fOverwritePolicy\x12\x12\n" +
+	"\x0eOVERWRITE_FAIL\x10\x00\x12\x15\n" +
+	"\x11OVERWRITE_REPLACE\x10\x01\x12\x12\n" +
+	"\x0eOVERWRITE_SKIP\x10\x02\x12\x16\n" +
+	"\x12OVERWRITE_IF_NEWER\x10\x03*r\n" +
 	"\rServiceAction\x12\x11\n" +
 	"\rSERVICE_START\x10\x00\x12\x10\n" +
 	"\fSERVICE_STOP\x10\x01\x12\x13\n" +
@@ -4852,14 +11774,18 @@ const file_agent_proto_rawDesc = "" +
 	"PluginType\x12\x11\n" +
 	"\rPLUGIN_CLIENT\x10\x00\x12\x10\n" +
 	"\fPLUGIN_AGENT\x10\x01\x12\x11\n" +
-	"\rPLUGIN_HYBRID\x10\x022\xe0\b\n" +
+	"\rPLUGIN_HYBRID\x10\x022\xea\x13\n" +
 	"\fAgentService\x129\n" +
 	"\fAuthenticate\x12\x13.runixo.AuthRequest\x1a\x14.runixo.AuthResponse\x122\n" +
 	"\rGetSystemInfo\x12\r.runixo.Empty\x1a\x12.runixo.SystemInfo\x127\n" +
 	"\n" +
 	"GetMetrics\x12\x16.runixo.MetricsRequest\x1a\x0f.runixo.Metrics0\x01\x12A\n" +
-	"\x0eExecuteCommand\x12\x16.runixo.CommandRequest\x1a\x17.runixo.CommandResponse\x12;\n" +
-	"\fExecuteShell\x12\x12.runixo.ShellInput\x1a\x13.runixo.ShellOutput(\x010\x01\x124\n" +
+	"\x0eExecuteCommand\x12\x16.runixo.CommandRequest\x1a\x17.runixo.CommandResponse\x12@\n" +
+	"\rExecuteStream\x12\x16.runixo.CommandRequest\x1a\x15.runixo.CommandOutput0\x01\x12?\n" +
+	"\rExecuteScript\x12\x15.runixo.ScriptRequest\x1a\x17.runixo.CommandResponse\x12;\n" +
+	"\fExecuteBatch\x12\x14.runixo.BatchRequest\x1a\x15.runixo.BatchResponse\x12;\n" +
+	"\fExecuteShell\x12\x12.runixo.ShellInput\x1a\x13.runixo.ShellOutput(\x010\x01\x12A\n" +
+	"\x0eGetExecHistory\x12\x1a.runixo.ExecHistoryRequest\x1a\x13.runixo.ExecHistory\x124\n" +
 	"\bReadFile\x12\x13.runixo.FileRequest\x1a\x13.runixo.FileContent\x12=\n" +
 	"\tWriteFile\x12\x18.runixo.WriteFileRequest\x1a\x16.runixo.ActionResponse\x127\n" +
 	"\rListDirectory\x12\x12.runixo.DirRequest\x1a\x12.runixo.DirContent\x129\n" +
@@ -4867,24 +11793,51 @@ const file_agent_proto_rawDesc = "" +
 	"DeleteFile\x12\x13.runixo.FileRequest\x1a\x16.runixo.ActionResponse\x129\n" +
 	"\n" +
 	"UploadFile\x12\x11.runixo.FileChunk\x1a\x16.runixo.UploadResponse(\x01\x128\n" +
-	"\fDownloadFile\x12\x13.runixo.FileRequest\x1a\x11.runixo.FileChunk0\x01\x120\n" +
+	"\fDownloadFile\x12\x13.runixo.FileRequest\x1a\x11.runixo.FileChunk0\x01\x12<\n" +
+	"\x0fGetUploadOffset\x12\x13.runixo.FileRequest\x1a\x14.runixo.UploadOffset\x125\n" +
+	"\bHashFile\x12\x17.runixo.HashFileRequest\x1a\x10.runixo.FileHash\x12C\n" +
+	"\fCompareFiles\x12\x1b.runixo.CompareFilesRequest\x1a\x16.runixo.FileComparison\x12F\n" +
+	"\vSearchFiles\x12\x1a.runixo.SearchFilesRequest\x1a\x1b.runixo.SearchFilesResponse\x12H\n" +
+	"\rCreateArchive\x12\x1c.runixo.CreateArchiveRequest\x1a\x17.runixo.ArchiveProgress0\x01\x12J\n" +
+	"\x0eExtractArchive\x12\x1d.runixo.ExtractArchiveRequest\x1a\x17.runixo.ArchiveProgress0\x01\x127\n" +
+	"\x05Chmod\x12\x14.runixo.ChmodRequest\x1a\x18.runixo.PermissionResult\x127\n" +
+	"\x05Chown\x12\x14.runixo.ChownRequest\x1a\x18.runixo.PermissionResult\x129\n" +
+	"\x06SetACL\x12\x15.runixo.SetACLRequest\x1a\x18.runixo.PermissionResult\x129\n" +
+	"\bCopyPath\x12\x17.runixo.CopyPathRequest\x1a\x14.runixo.FileOpResult\x129\n" +
+	"\bMovePath\x12\x17.runixo.MovePathRequest\x1a\x14.runixo.FileOpResult\x12=\n" +
+	"\n" +
+	"DeletePath\x12\x19.runixo.DeletePathRequest\x1a\x14.runixo.FileOpResult\x12-\n" +
+	"\tListTrash\x12\r.runixo.Empty\x1a\x11.runixo.TrashList\x12A\n" +
+	"\fRestoreTrash\x12\x1b.runixo.RestoreTrashRequest\x1a\x14.runixo.FileOpResult\x12O\n" +
+	"\x10GetDirectorySize\x12\x1c.runixo.DirectorySizeRequest\x1a\x1d.runixo.DirectorySizeResponse\x120\n" +
 	"\aTailLog\x12\x12.runixo.LogRequest\x1a\x0f.runixo.LogLine0\x01\x12:\n" +
 	"\fListServices\x12\x15.runixo.ServiceFilter\x1a\x13.runixo.ServiceList\x12E\n" +
 	"\rServiceAction\x12\x1c.runixo.ServiceActionRequest\x1a\x16.runixo.ActionResponse\x12;\n" +
 	"\rListProcesses\x12\x15.runixo.ProcessFilter\x1a\x13.runixo.ProcessList\x12A\n" +
-	"\vKillProcess\x12\x1a.runixo.KillProcessRequest\x1a\x16.runixo.ActionResponse\x12L\n" +
+	"\x0eGetProcessTree\x12\x1a.runixo.ProcessTreeRequest\x1a\x13.runixo.ProcessTree\x12A\n" +
+	"\vKillProcess\x12\x1a.runixo.KillProcessRequest\x1a\x16.runixo.ActionResponse\x12B\n" +
+	"\x15GetNetworkConnections\x12\r.runixo.Empty\x1a\x1a.runixo.NetworkConnections\x12L\n" +
 	"\x0fSearchDockerHub\x12\x1b.runixo.DockerSearchRequest\x1a\x1c.runixo.DockerSearchResponse\x12G\n" +
-	"\x10ProxyHttpRequest\x12\x18.runixo.HttpProxyRequest\x1a\x19.runixo.HttpProxyResponse2\xd7\x04\n" +
+	"\x10ProxyHttpRequest\x12\x18.runixo.HttpProxyRequest\x1a\x19.runixo.HttpProxyResponse\x12A\n" +
+	"\x13DownloadCertificate\x12\r.runixo.Empty\x1a\x1b.runixo.CertificateResponse2\xbf\t\n" +
 	"\rPluginService\x120\n" +
 	"\vListPlugins\x12\r.runixo.Empty\x1a\x12.runixo.PluginList\x12E\n" +
-	"\rInstallPlugin\x12\x1c.runixo.InstallPluginRequest\x1a\x16.runixo.ActionResponse\x12@\n" +
+	"\rInstallPlugin\x12\x1c.runixo.InstallPluginRequest\x1a\x16.runixo.ActionResponse\x12>\n" +
+	"\fUploadPlugin\x12\x14.runixo.PluginUpload\x1a\x16.runixo.ActionResponse(\x01\x12@\n" +
 	"\x0fUninstallPlugin\x12\x15.runixo.PluginRequest\x1a\x16.runixo.ActionResponse\x12=\n" +
-	"\fEnablePlugin\x12\x15.runixo.PluginRequest\x1a\x16.runixo.ActionResponse\x12>\n" +
+	"\fEnablePlugin\x12\x15.runixo.PluginRequest\x1a\x16.runixo.ActionResponse\x12A\n" +
+	"\fVerifyPlugin\x12\x15.runixo.PluginRequest\x1a\x1a.runixo.PluginVerifyResult\x12>\n" +
 	"\rDisablePlugin\x12\x15.runixo.PluginRequest\x1a\x16.runixo.ActionResponse\x12>\n" +
 	"\x0fGetPluginConfig\x12\x15.runixo.PluginRequest\x1a\x14.runixo.PluginConfig\x12I\n" +
 	"\x0fSetPluginConfig\x12\x1e.runixo.SetPluginConfigRequest\x1a\x16.runixo.ActionResponse\x12>\n" +
-	"\x0fGetPluginStatus\x12\x15.runixo.PluginRequest\x1a\x14.runixo.PluginStatus\x12A\n" +
-	"\x13GetAvailablePlugins\x12\r.runixo.Empty\x1a\x1b.runixo.AvailablePluginList2\xf7\x02\n" +
+	"\x0fGetPluginStatus\x12\x15.runixo.PluginRequest\x1a\x14.runixo.PluginStatus\x12H\n" +
+	"\x14GetPluginDiagnostics\x12\x15.runixo.PluginRequest\x1a\x19.runixo.PluginDiagnostics\x12A\n" +
+	"\x13GetAvailablePlugins\x12\r.runixo.Empty\x1a\x1b.runixo.AvailablePluginList\x12L\n" +
+	"\rSearchPlugins\x12\x1c.runixo.SearchPluginsRequest\x1a\x1d.runixo.SearchPluginsResponse\x12@\n" +
+	"\x10GetPluginDetails\x12\x15.runixo.PluginRequest\x1a\x15.runixo.PluginDetails\x12R\n" +
+	"\x12ListScheduledTasks\x12!.runixo.ListScheduledTasksRequest\x1a\x19.runixo.ScheduledTaskList\x12U\n" +
+	"\x13ExportPluginConfigs\x12\".runixo.ExportPluginConfigsRequest\x1a\x1a.runixo.PluginConfigBundle\x12^\n" +
+	"\x13ImportPluginConfigs\x12\".runixo.ImportPluginConfigsRequest\x1a#.runixo.ImportPluginConfigsResponse2\xf7\x02\n" +
 	"\rUpdateService\x120\n" +
 	"\vCheckUpdate\x12\r.runixo.Empty\x1a\x12.runixo.UpdateInfo\x12C\n" +
 	"\x0eDownloadUpdate\x12\x15.runixo.UpdateRequest\x1a\x18.runixo.DownloadProgress0\x01\x12<\n" +
@@ -4905,179 +11858,379 @@ func file_agent_proto_rawDescGZIP() []byte {
 	return file_agent_proto_rawDescData
 }
 
-var file_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 65)
+var file_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 149)
 var file_agent_proto_goTypes = []any{
-	(ServiceAction)(0),             // 0: runixo.ServiceAction
-	(PluginState)(0),               // 1: runixo.PluginState
-	(PluginType)(0),                // 2: runixo.PluginType
-	(*Empty)(nil),                  // 3: runixo.Empty
-	(*AuthRequest)(nil),            // 4: runixo.AuthRequest
-	(*AuthResponse)(nil),           // 5: runixo.AuthResponse
-	(*SystemInfo)(nil),             // 6: runixo.SystemInfo
-	(*CpuInfo)(nil),                // 7: runixo.CpuInfo
-	(*MemoryInfo)(nil),             // 8: runixo.MemoryInfo
-	(*DiskInfo)(nil),               // 9: runixo.DiskInfo
-	(*NetworkInfo)(nil),            // 10: runixo.NetworkInfo
-	(*GpuInfo)(nil),                // 11: runixo.GpuInfo
-	(*MetricsRequest)(nil),         // 12: runixo.MetricsRequest
-	(*Metrics)(nil),                // 13: runixo.Metrics
-	(*DiskMetric)(nil),             // 14: runixo.DiskMetric
-	(*NetworkMetric)(nil),          // 15: runixo.NetworkMetric
-	(*CommandRequest)(nil),         // 16: runixo.CommandRequest
-	(*CommandResponse)(nil),        // 17: runixo.CommandResponse
-	(*ShellInput)(nil),             // 18: runixo.ShellInput
-	(*ShellStart)(nil),             // 19: runixo.ShellStart
-	(*ShellResize)(nil),            // 20: runixo.ShellResize
-	(*ShellOutput)(nil),            // 21: runixo.ShellOutput
-	(*FileRequest)(nil),            // 22: runixo.FileRequest
-	(*FileContent)(nil),            // 23: runixo.FileContent
-	(*FileInfo)(nil),               // 24: runixo.FileInfo
-	(*WriteFileRequest)(nil),       // 25: runixo.WriteFileRequest
-	(*FileChunk)(nil),              // 26: runixo.FileChunk
-	(*FileUploadStart)(nil),        // 27: runixo.FileUploadStart
-	(*FileUploadEnd)(nil),          // 28: runixo.FileUploadEnd
-	(*UploadResponse)(nil),         // 29: runixo.UploadResponse
-	(*DirRequest)(nil),             // 30: runixo.DirRequest
-	(*DirContent)(nil),             // 31: runixo.DirContent
-	(*LogRequest)(nil),             // 32: runixo.LogRequest
-	(*LogLine)(nil),                // 33: runixo.LogLine
-	(*ServiceFilter)(nil),          // 34: runixo.ServiceFilter
-	(*ServiceList)(nil),            // 35: runixo.ServiceList
-	(*ServiceInfo)(nil),            // 36: runixo.ServiceInfo
-	(*ServiceActionRequest)(nil),   // 37: runixo.ServiceActionRequest
-	(*ProcessFilter)(nil),          // 38: runixo.ProcessFilter
-	(*ProcessList)(nil),            // 39: runixo.ProcessList
-	(*ProcessInfo)(nil),            // 40: runixo.ProcessInfo
-	(*KillProcessRequest)(nil),     // 41: runixo.KillProcessRequest
-	(*ActionResponse)(nil),         // 42: runixo.ActionResponse
-	(*DockerSearchRequest)(nil),    // 43: runixo.DockerSearchRequest
-	(*DockerSearchResponse)(nil),   // 44: runixo.DockerSearchResponse
-	(*DockerImage)(nil),            // 45: runixo.DockerImage
-	(*HttpProxyRequest)(nil),       // 46: runixo.HttpProxyRequest
-	(*HttpProxyResponse)(nil),      // 47: runixo.HttpProxyResponse
-	(*PluginRequest)(nil),          // 48: runixo.PluginRequest
-	(*InstallPluginRequest)(nil),   // 49: runixo.InstallPluginRequest
-	(*PluginList)(nil),             // 50: runixo.PluginList
-	(*PluginInfo)(nil),             // 51: runixo.PluginInfo
-	(*PluginConfig)(nil),           // 52: runixo.PluginConfig
-	(*SetPluginConfigRequest)(nil), // 53: runixo.SetPluginConfigRequest
-	(*PluginStatus)(nil),           // 54: runixo.PluginStatus
-	(*AvailablePluginList)(nil),    // 55: runixo.AvailablePluginList
-	(*AvailablePlugin)(nil),        // 56: runixo.AvailablePlugin
-	(*UpdateInfo)(nil),             // 57: runixo.UpdateInfo
-	(*UpdateRequest)(nil),          // 58: runixo.UpdateRequest
-	(*DownloadProgress)(nil),       // 59: runixo.DownloadProgress
-	(*UpdateConfig)(nil),           // 60: runixo.UpdateConfig
-	(*UpdateHistory)(nil),          // 61: runixo.UpdateHistory
-	(*UpdateRecord)(nil),           // 62: runixo.UpdateRecord
-	nil,                            // 63: runixo.CommandRequest.EnvEntry
-	nil,                            // 64: runixo.ShellStart.EnvEntry
-	nil,                            // 65: runixo.HttpProxyRequest.HeadersEntry
-	nil,                            // 66: runixo.HttpProxyResponse.HeadersEntry
-	nil,                            // 67: runixo.PluginStatus.StatsEntry
+	(ContentEncoding)(0),                // 0: runixo.ContentEncoding
+	(OverwritePolicy)(0),                // 1: runixo.OverwritePolicy
+	(ServiceAction)(0),                  // 2: runixo.ServiceAction
+	(PluginState)(0),                    // 3: runixo.PluginState
+	(PluginType)(0),                     // 4: runixo.PluginType
+	(*Empty)(nil),                       // 5: runixo.Empty
+	(*AuthRequest)(nil),                 // 6: runixo.AuthRequest
+	(*AuthResponse)(nil),                // 7: runixo.AuthResponse
+	(*SystemInfo)(nil),                  // 8: runixo.SystemInfo
+	(*PublicIP)(nil),                    // 9: runixo.PublicIP
+	(*DistroInfo)(nil),                  // 10: runixo.DistroInfo
+	(*PackageInfo)(nil),                 // 11: runixo.PackageInfo
+	(*ClockSync)(nil),                   // 12: runixo.ClockSync
+	(*LoginSession)(nil),                // 13: runixo.LoginSession
+	(*LoginRecord)(nil),                 // 14: runixo.LoginRecord
+	(*LoginInfo)(nil),                   // 15: runixo.LoginInfo
+	(*UnitSummary)(nil),                 // 16: runixo.UnitSummary
+	(*CpuInfo)(nil),                     // 17: runixo.CpuInfo
+	(*MemoryInfo)(nil),                  // 18: runixo.MemoryInfo
+	(*DiskInfo)(nil),                    // 19: runixo.DiskInfo
+	(*NetworkInfo)(nil),                 // 20: runixo.NetworkInfo
+	(*GpuInfo)(nil),                     // 21: runixo.GpuInfo
+	(*MetricsRequest)(nil),              // 22: runixo.MetricsRequest
+	(*Metrics)(nil),                     // 23: runixo.Metrics
+	(*PowerInfo)(nil),                   // 24: runixo.PowerInfo
+	(*Battery)(nil),                     // 25: runixo.Battery
+	(*CustomSample)(nil),                // 26: runixo.CustomSample
+	(*FdUsage)(nil),                     // 27: runixo.FdUsage
+	(*StuckProcess)(nil),                // 28: runixo.StuckProcess
+	(*TopProcess)(nil),                  // 29: runixo.TopProcess
+	(*TopProcesses)(nil),                // 30: runixo.TopProcesses
+	(*ContainerMetric)(nil),             // 31: runixo.ContainerMetric
+	(*DiskMetric)(nil),                  // 32: runixo.DiskMetric
+	(*NetworkMetric)(nil),               // 33: runixo.NetworkMetric
+	(*CommandRequest)(nil),              // 34: runixo.CommandRequest
+	(*ScriptRequest)(nil),               // 35: runixo.ScriptRequest
+	(*CommandResponse)(nil),             // 36: runixo.CommandResponse
+	(*BatchRequest)(nil),                // 37: runixo.BatchRequest
+	(*BatchCommandResult)(nil),          // 38: runixo.BatchCommandResult
+	(*BatchResponse)(nil),               // 39: runixo.BatchResponse
+	(*CommandOutput)(nil),               // 40: runixo.CommandOutput
+	(*CommandExit)(nil),                 // 41: runixo.CommandExit
+	(*ExecHistoryRequest)(nil),          // 42: runixo.ExecHistoryRequest
+	(*ExecRecord)(nil),                  // 43: runixo.ExecRecord
+	(*ExecHistory)(nil),                 // 44: runixo.ExecHistory
+	(*ShellInput)(nil),                  // 45: runixo.ShellInput
+	(*ShellStart)(nil),                  // 46: runixo.ShellStart
+	(*ShellResize)(nil),                 // 47: runixo.ShellResize
+	(*ShellOutput)(nil),                 // 48: runixo.ShellOutput
+	(*ShellExit)(nil),                   // 49: runixo.ShellExit
+	(*FileRequest)(nil),                 // 50: runixo.FileRequest
+	(*FileContent)(nil),                 // 51: runixo.FileContent
+	(*FileInfo)(nil),                    // 52: runixo.FileInfo
+	(*WriteFileRequest)(nil),            // 53: runixo.WriteFileRequest
+	(*FileChunk)(nil),                   // 54: runixo.FileChunk
+	(*FileUploadStart)(nil),             // 55: runixo.FileUploadStart
+	(*FileUploadEnd)(nil),               // 56: runixo.FileUploadEnd
+	(*UploadResponse)(nil),              // 57: runixo.UploadResponse
+	(*UploadOffset)(nil),                // 58: runixo.UploadOffset
+	(*HashFileRequest)(nil),             // 59: runixo.HashFileRequest
+	(*FileHash)(nil),                    // 60: runixo.FileHash
+	(*CompareFilesRequest)(nil),         // 61: runixo.CompareFilesRequest
+	(*FileComparison)(nil),              // 62: runixo.FileComparison
+	(*SearchFilesRequest)(nil),          // 63: runixo.SearchFilesRequest
+	(*SearchMatch)(nil),                 // 64: runixo.SearchMatch
+	(*SearchResult)(nil),                // 65: runixo.SearchResult
+	(*SearchFilesResponse)(nil),         // 66: runixo.SearchFilesResponse
+	(*CreateArchiveRequest)(nil),        // 67: runixo.CreateArchiveRequest
+	(*ExtractArchiveRequest)(nil),       // 68: runixo.ExtractArchiveRequest
+	(*ArchiveProgress)(nil),             // 69: runixo.ArchiveProgress
+	(*ChmodRequest)(nil),                // 70: runixo.ChmodRequest
+	(*ChownRequest)(nil),                // 71: runixo.ChownRequest
+	(*SetACLRequest)(nil),               // 72: runixo.SetACLRequest
+	(*PermissionChange)(nil),            // 73: runixo.PermissionChange
+	(*PermissionResult)(nil),            // 74: runixo.PermissionResult
+	(*CopyPathRequest)(nil),             // 75: runixo.CopyPathRequest
+	(*MovePathRequest)(nil),             // 76: runixo.MovePathRequest
+	(*DeletePathRequest)(nil),           // 77: runixo.DeletePathRequest
+	(*FileOpResult)(nil),                // 78: runixo.FileOpResult
+	(*TrashEntry)(nil),                  // 79: runixo.TrashEntry
+	(*TrashList)(nil),                   // 80: runixo.TrashList
+	(*RestoreTrashRequest)(nil),         // 81: runixo.RestoreTrashRequest
+	(*DirectorySizeRequest)(nil),        // 82: runixo.DirectorySizeRequest
+	(*DirectorySize)(nil),               // 83: runixo.DirectorySize
+	(*DirectorySizeResponse)(nil),       // 84: runixo.DirectorySizeResponse
+	(*DirRequest)(nil),                  // 85: runixo.DirRequest
+	(*DirContent)(nil),                  // 86: runixo.DirContent
+	(*LogRequest)(nil),                  // 87: runixo.LogRequest
+	(*LogLine)(nil),                     // 88: runixo.LogLine
+	(*ServiceFilter)(nil),               // 89: runixo.ServiceFilter
+	(*ServiceList)(nil),                 // 90: runixo.ServiceList
+	(*ServiceInfo)(nil),                 // 91: runixo.ServiceInfo
+	(*ServiceActionRequest)(nil),        // 92: runixo.ServiceActionRequest
+	(*ProcessFilter)(nil),               // 93: runixo.ProcessFilter
+	(*ProcessList)(nil),                 // 94: runixo.ProcessList
+	(*ProcessInfo)(nil),                 // 95: runixo.ProcessInfo
+	(*ProcessTreeRequest)(nil),          // 96: runixo.ProcessTreeRequest
+	(*ProcessNode)(nil),                 // 97: runixo.ProcessNode
+	(*ProcessTree)(nil),                 // 98: runixo.ProcessTree
+	(*ListeningPort)(nil),               // 99: runixo.ListeningPort
+	(*NetworkConnections)(nil),          // 100: runixo.NetworkConnections
+	(*KillProcessRequest)(nil),          // 101: runixo.KillProcessRequest
+	(*ActionResponse)(nil),              // 102: runixo.ActionResponse
+	(*FieldViolation)(nil),              // 103: runixo.FieldViolation
+	(*DockerSearchRequest)(nil),         // 104: runixo.DockerSearchRequest
+	(*DockerSearchResponse)(nil),        // 105: runixo.DockerSearchResponse
+	(*DockerImage)(nil),                 // 106: runixo.DockerImage
+	(*HttpProxyRequest)(nil),            // 107: runixo.HttpProxyRequest
+	(*HttpProxyResponse)(nil),           // 108: runixo.HttpProxyResponse
+	(*PluginRequest)(nil),               // 109: runixo.PluginRequest
+	(*InstallPluginRequest)(nil),        // 110: runixo.InstallPluginRequest
+	(*PluginUpload)(nil),                // 111: runixo.PluginUpload
+	(*PluginUploadStart)(nil),           // 112: runixo.PluginUploadStart
+	(*PluginList)(nil),                  // 113: runixo.PluginList
+	(*PluginInfo)(nil),                  // 114: runixo.PluginInfo
+	(*PluginConfig)(nil),                // 115: runixo.PluginConfig
+	(*SetPluginConfigRequest)(nil),      // 116: runixo.SetPluginConfigRequest
+	(*PluginStatus)(nil),                // 117: runixo.PluginStatus
+	(*PluginVerifyResult)(nil),          // 118: runixo.PluginVerifyResult
+	(*PluginVerifyStep)(nil),            // 119: runixo.PluginVerifyStep
+	(*PluginPermissionDenial)(nil),      // 120: runixo.PluginPermissionDenial
+	(*PluginDiagnostics)(nil),           // 121: runixo.PluginDiagnostics
+	(*ExportPluginConfigsRequest)(nil),  // 122: runixo.ExportPluginConfigsRequest
+	(*PluginConfigBundle)(nil),          // 123: runixo.PluginConfigBundle
+	(*ImportPluginConfigsRequest)(nil),  // 124: runixo.ImportPluginConfigsRequest
+	(*ImportPluginConfigsResponse)(nil), // 125: runixo.ImportPluginConfigsResponse
+	(*PluginImportResult)(nil),          // 126: runixo.PluginImportResult
+	(*PluginResourceUsage)(nil),         // 127: runixo.PluginResourceUsage
+	(*AvailablePluginList)(nil),         // 128: runixo.AvailablePluginList
+	(*AvailablePlugin)(nil),             // 129: runixo.AvailablePlugin
+	(*SearchPluginsRequest)(nil),        // 130: runixo.SearchPluginsRequest
+	(*SearchPluginsResponse)(nil),       // 131: runixo.SearchPluginsResponse
+	(*PluginCategory)(nil),              // 132: runixo.PluginCategory
+	(*PluginDetails)(nil),               // 133: runixo.PluginDetails
+	(*ListScheduledTasksRequest)(nil),   // 134: runixo.ListScheduledTasksRequest
+	(*ScheduledTaskList)(nil),           // 135: runixo.ScheduledTaskList
+	(*ScheduledTask)(nil),               // 136: runixo.ScheduledTask
+	(*UpdateInfo)(nil),                  // 137: runixo.UpdateInfo
+	(*UpdateRequest)(nil),               // 138: runixo.UpdateRequest
+	(*DownloadProgress)(nil),            // 139: runixo.DownloadProgress
+	(*UpdateConfig)(nil),                // 140: runixo.UpdateConfig
+	(*UpdateHistory)(nil),               // 141: runixo.UpdateHistory
+	(*UpdateRecord)(nil),                // 142: runixo.UpdateRecord
+	(*CertificateResponse)(nil),         // 143: runixo.CertificateResponse
+	nil,                                 // 144: runixo.SystemInfo.LabelsEntry
+	nil,                                 // 145: runixo.Metrics.LabelsEntry
+	nil,                                 // 146: runixo.CustomSample.LabelsEntry
+	nil,                                 // 147: runixo.CommandRequest.EnvEntry
+	nil,                                 // 148: runixo.ScriptRequest.EnvEntry
+	nil,                                 // 149: runixo.ShellStart.EnvEntry
+	nil,                                 // 150: runixo.NetworkConnections.StateCountsEntry
+	nil,                                 // 151: runixo.HttpProxyRequest.HeadersEntry
+	nil,                                 // 152: runixo.HttpProxyResponse.HeadersEntry
+	nil,                                 // 153: runixo.PluginStatus.StatsEntry
 }
 var file_agent_proto_depIdxs = []int32{
-	7,  // 0: runixo.SystemInfo.cpu:type_name -> runixo.CpuInfo
-	8,  // 1: runixo.SystemInfo.memory:type_name -> runixo.MemoryInfo
-	9,  // 2: runixo.SystemInfo.disks:type_name -> runixo.DiskInfo
-	10, // 3: runixo.SystemInfo.networks:type_name -> runixo.NetworkInfo
-	11, // 4: runixo.SystemInfo.gpus:type_name -> runixo.GpuInfo
-	14, // 5: runixo.Metrics.disk_metrics:type_name -> runixo.DiskMetric
-	15, // 6: runixo.Metrics.network_metrics:type_name -> runixo.NetworkMetric
-	63, // 7: runixo.CommandRequest.env:type_name -> runixo.CommandRequest.EnvEntry
-	19, // 8: runixo.ShellInput.start:type_name -> runixo.ShellStart
-	20, // 9: runixo.ShellInput.resize:type_name -> runixo.ShellResize
-	64, // 10: runixo.ShellStart.env:type_name -> runixo.ShellStart.EnvEntry
-	24, // 11: runixo.FileContent.info:type_name -> runixo.FileInfo
-	27, // 12: runixo.FileChunk.start:type_name -> runixo.FileUploadStart
-	28, // 13: runixo.FileChunk.end:type_name -> runixo.FileUploadEnd
-	24, // 14: runixo.DirContent.files:type_name -> runixo.FileInfo
-	36, // 15: runixo.ServiceList.services:type_name -> runixo.ServiceInfo
-	0,  // 16: runixo.ServiceActionRequest.action:type_name -> runixo.ServiceAction
-	40, // 17: runixo.ProcessList.processes:type_name -> runixo.ProcessInfo
-	45, // 18: runixo.DockerSearchResponse.results:type_name -> runixo.DockerImage
-	65, // 19: runixo.HttpProxyRequest.headers:type_name -> runixo.HttpProxyRequest.HeadersEntry
-	66, // 20: runixo.HttpProxyResponse.headers:type_name -> runixo.HttpProxyResponse.HeadersEntry
-	51, // 21: runixo.PluginList.plugins:type_name -> runixo.PluginInfo
-	1,  // 22: runixo.PluginInfo.state:type_name -> runixo.PluginState
-	2,  // 23: runixo.PluginInfo.type:type_name -> runixo.PluginType
-	1,  // 24: runixo.PluginStatus.state:type_name -> runixo.PluginState
-	67, // 25: runixo.PluginStatus.stats:type_name -> runixo.PluginStatus.StatsEntry
-	56, // 26: runixo.AvailablePluginList.plugins:type_name -> runixo.AvailablePlugin
-	2,  // 27: runixo.AvailablePlugin.type:type_name -> runixo.PluginType
-	62, // 28: runixo.UpdateHistory.records:type_name -> runixo.UpdateRecord
-	4,  // 29: runixo.AgentService.Authenticate:input_type -> runixo.AuthRequest
-	3,  // 30: runixo.AgentService.GetSystemInfo:input_type -> runixo.Empty
-	12, // 31: runixo.AgentService.GetMetrics:input_type -> runixo.MetricsRequest
-	16, // 32: runixo.AgentService.ExecuteCommand:input_type -> runixo.CommandRequest
-	18, // 33: runixo.AgentService.ExecuteShell:input_type -> runixo.ShellInput
-	22, // 34: runixo.AgentService.ReadFile:input_type -> runixo.FileRequest
-	25, // 35: runixo.AgentService.WriteFile:input_type -> runixo.WriteFileRequest
-	30, // 36: runixo.AgentService.ListDirectory:input_type -> runixo.DirRequest
-	22, // 37: runixo.AgentService.DeleteFile:input_type -> runixo.FileRequest
-	26, // 38: runixo.AgentService.UploadFile:input_type -> runixo.FileChunk
-	22, // 39: runixo.AgentService.DownloadFile:input_type -> runixo.FileRequest
-	32, // 40: runixo.AgentService.TailLog:input_type -> runixo.LogRequest
-	34, // 41: runixo.AgentService.ListServices:input_type -> runixo.ServiceFilter
-	37, // 42: runixo.AgentService.ServiceAction:input_type -> runixo.ServiceActionRequest
-	38, // 43: runixo.AgentService.ListProcesses:input_type -> runixo.ProcessFilter
-	41, // 44: runixo.AgentService.KillProcess:input_type -> runixo.KillProcessRequest
-	43, // 45: runixo.AgentService.SearchDockerHub:input_type -> runixo.DockerSearchRequest
-	46, // 46: runixo.AgentService.ProxyHttpRequest:input_type -> runixo.HttpProxyRequest
-	3,  // 47: runixo.PluginService.ListPlugins:input_type -> runixo.Empty
-	49, // 48: runixo.PluginService.InstallPlugin:input_type -> runixo.InstallPluginRequest
-	48, // 49: runixo.PluginService.UninstallPlugin:input_type -> runixo.PluginRequest
-	48, // 50: runixo.PluginService.EnablePlugin:input_type -> runixo.PluginRequest
-	48, // 51: runixo.PluginService.DisablePlugin:input_type -> runixo.PluginRequest
-	48, // 52: runixo.PluginService.GetPluginConfig:input_type -> runixo.PluginRequest
-	53, // 53: runixo.PluginService.SetPluginConfig:input_type -> runixo.SetPluginConfigRequest
-	48, // 54: runixo.PluginService.GetPluginStatus:input_type -> runixo.PluginRequest
-	3,  // 55: runixo.PluginService.GetAvailablePlugins:input_type -> runixo.Empty
-	3,  // 56: runixo.UpdateService.CheckUpdate:input_type -> runixo.Empty
-	58, // 57: runixo.UpdateService.DownloadUpdate:input_type -> runixo.UpdateRequest
-	58, // 58: runixo.UpdateService.ApplyUpdate:input_type -> runixo.UpdateRequest
-	3,  // 59: runixo.UpdateService.GetUpdateConfig:input_type -> runixo.Empty
-	60, // 60: runixo.UpdateService.SetUpdateConfig:input_type -> runixo.UpdateConfig
-	3,  // 61: runixo.UpdateService.GetUpdateHistory:input_type -> runixo.Empty
-	5,  // 62: runixo.AgentService.Authenticate:output_type -> runixo.AuthResponse
-	6,  // 63: runixo.AgentService.GetSystemInfo:output_type -> runixo.SystemInfo
-	13, // 64: runixo.AgentService.GetMetrics:output_type -> runixo.Metrics
-	17, // 65: runixo.AgentService.ExecuteCommand:output_type -> runixo.CommandResponse
-	21, // 66: runixo.AgentService.ExecuteShell:output_type -> runixo.ShellOutput
-	23, // 67: runixo.AgentService.ReadFile:output_type -> runixo.FileContent
-	42, // 68: runixo.AgentService.WriteFile:output_type -> runixo.ActionResponse
-	31, // 69: runixo.AgentService.ListDirectory:output_type -> runixo.DirContent
-	42, // 70: runixo.AgentService.DeleteFile:output_type -> runixo.ActionResponse
-	29, // 71: runixo.AgentService.UploadFile:output_type -> runixo.UploadResponse
-	26, // 72: runixo.AgentService.DownloadFile:output_type -> runixo.FileChunk
-	33, // 73: runixo.AgentService.TailLog:output_type -> runixo.LogLine
-	35, // 74: runixo.AgentService.ListServices:output_type -> runixo.ServiceList
-	42, // 75: runixo.AgentService.ServiceAction:output_type -> runixo.ActionResponse
-	39, // 76: runixo.AgentService.ListProcesses:output_type -> runixo.ProcessList
-	42, // 77: runixo.AgentService.KillProcess:output_type -> runixo.ActionResponse
-	44, // 78: runixo.AgentService.SearchDockerHub:output_type -> runixo.DockerSearchResponse
-	47, // 79: runixo.AgentService.ProxyHttpRequest:output_type -> runixo.HttpProxyResponse
-	50, // 80: runixo.PluginService.ListPlugins:output_type -> runixo.PluginList
-	42, // 81: runixo.PluginService.InstallPlugin:output_type -> runixo.ActionResponse
-	42, // 82: runixo.PluginService.UninstallPlugin:output_type -> runixo.ActionResponse
-	42, // 83: runixo.PluginService.EnablePlugin:output_type -> runixo.ActionResponse
-	42, // 84: runixo.PluginService.DisablePlugin:output_type -> runixo.ActionResponse
-	52, // 85: runixo.PluginService.GetPluginConfig:output_type -> runixo.PluginConfig
-	42, // 86: runixo.PluginService.SetPluginConfig:output_type -> runixo.ActionResponse
-	54, // 87: runixo.PluginService.GetPluginStatus:output_type -> runixo.PluginStatus
-	55, // 88: runixo.PluginService.GetAvailablePlugins:output_type -> runixo.AvailablePluginList
-	57, // 89: runixo.UpdateService.CheckUpdate:output_type -> runixo.UpdateInfo
-	59, // 90: runixo.UpdateService.DownloadUpdate:output_type -> runixo.DownloadProgress
-	42, // 91: runixo.UpdateService.ApplyUpdate:output_type -> runixo.ActionResponse
-	60, // 92: runixo.UpdateService.GetUpdateConfig:output_type -> runixo.UpdateConfig
-	42, // 93: runixo.UpdateService.SetUpdateConfig:output_type -> runixo.ActionResponse
-	61, // 94: runixo.UpdateService.GetUpdateHistory:output_type -> runixo.UpdateHistory
-	62, // [62:95] is the sub-list for method output_type
-	29, // [29:62] is the sub-list for method input_type
-	29, // [29:29] is the sub-list for extension type_name
-	29, // [29:29] is the sub-list for extension extendee
-	0,  // [0:29] is the sub-list for field type_name
+	17,  // 0: runixo.SystemInfo.cpu:type_name -> runixo.CpuInfo
+	18,  // 1: runixo.SystemInfo.memory:type_name -> runixo.MemoryInfo
+	19,  // 2: runixo.SystemInfo.disks:type_name -> runixo.DiskInfo
+	20,  // 3: runixo.SystemInfo.networks:type_name -> runixo.NetworkInfo
+	21,  // 4: runixo.SystemInfo.gpus:type_name -> runixo.GpuInfo
+	16,  // 5: runixo.SystemInfo.units:type_name -> runixo.UnitSummary
+	15,  // 6: runixo.SystemInfo.logins:type_name -> runixo.LoginInfo
+	12,  // 7: runixo.SystemInfo.clock:type_name -> runixo.ClockSync
+	10,  // 8: runixo.SystemInfo.distro:type_name -> runixo.DistroInfo
+	11,  // 9: runixo.SystemInfo.packages:type_name -> runixo.PackageInfo
+	144, // 10: runixo.SystemInfo.labels:type_name -> runixo.SystemInfo.LabelsEntry
+	9,   // 11: runixo.SystemInfo.public_ip:type_name -> runixo.PublicIP
+	13,  // 12: runixo.LoginInfo.sessions:type_name -> runixo.LoginSession
+	14,  // 13: runixo.LoginInfo.recent:type_name -> runixo.LoginRecord
+	14,  // 14: runixo.LoginInfo.failed:type_name -> runixo.LoginRecord
+	32,  // 15: runixo.Metrics.disk_metrics:type_name -> runixo.DiskMetric
+	33,  // 16: runixo.Metrics.network_metrics:type_name -> runixo.NetworkMetric
+	31,  // 17: runixo.Metrics.containers:type_name -> runixo.ContainerMetric
+	16,  // 18: runixo.Metrics.units:type_name -> runixo.UnitSummary
+	30,  // 19: runixo.Metrics.top:type_name -> runixo.TopProcesses
+	28,  // 20: runixo.Metrics.stuck_processes:type_name -> runixo.StuckProcess
+	27,  // 21: runixo.Metrics.fd_near_limit:type_name -> runixo.FdUsage
+	26,  // 22: runixo.Metrics.custom:type_name -> runixo.CustomSample
+	145, // 23: runixo.Metrics.labels:type_name -> runixo.Metrics.LabelsEntry
+	24,  // 24: runixo.Metrics.power:type_name -> runixo.PowerInfo
+	25,  // 25: runixo.PowerInfo.batteries:type_name -> runixo.Battery
+	146, // 26: runixo.CustomSample.labels:type_name -> runixo.CustomSample.LabelsEntry
+	29,  // 27: runixo.TopProcesses.by_cpu:type_name -> runixo.TopProcess
+	29,  // 28: runixo.TopProcesses.by_memory:type_name -> runixo.TopProcess
+	147, // 29: runixo.CommandRequest.env:type_name -> runixo.CommandRequest.EnvEntry
+	148, // 30: runixo.ScriptRequest.env:type_name -> runixo.ScriptRequest.EnvEntry
+	34,  // 31: runixo.BatchRequest.commands:type_name -> runixo.CommandRequest
+	38,  // 32: runixo.BatchResponse.results:type_name -> runixo.BatchCommandResult
+	41,  // 33: runixo.CommandOutput.exit:type_name -> runixo.CommandExit
+	43,  // 34: runixo.ExecHistory.records:type_name -> runixo.ExecRecord
+	46,  // 35: runixo.ShellInput.start:type_name -> runixo.ShellStart
+	47,  // 36: runixo.ShellInput.resize:type_name -> runixo.ShellResize
+	149, // 37: runixo.ShellStart.env:type_name -> runixo.ShellStart.EnvEntry
+	49,  // 38: runixo.ShellOutput.exit:type_name -> runixo.ShellExit
+	0,   // 39: runixo.FileRequest.encoding:type_name -> runixo.ContentEncoding
+	52,  // 40: runixo.FileContent.info:type_name -> runixo.FileInfo
+	0,   // 41: runixo.WriteFileRequest.encoding:type_name -> runixo.ContentEncoding
+	55,  // 42: runixo.FileChunk.start:type_name -> runixo.FileUploadStart
+	56,  // 43: runixo.FileChunk.end:type_name -> runixo.FileUploadEnd
+	52,  // 44: runixo.SearchResult.file:type_name -> runixo.FileInfo
+	64,  // 45: runixo.SearchResult.matches:type_name -> runixo.SearchMatch
+	65,  // 46: runixo.SearchFilesResponse.results:type_name -> runixo.SearchResult
+	73,  // 47: runixo.PermissionResult.changes:type_name -> runixo.PermissionChange
+	1,   // 48: runixo.CopyPathRequest.overwrite:type_name -> runixo.OverwritePolicy
+	1,   // 49: runixo.MovePathRequest.overwrite:type_name -> runixo.OverwritePolicy
+	79,  // 50: runixo.TrashList.entries:type_name -> runixo.TrashEntry
+	83,  // 51: runixo.DirectorySize.children:type_name -> runixo.DirectorySize
+	83,  // 52: runixo.DirectorySizeResponse.root:type_name -> runixo.DirectorySize
+	52,  // 53: runixo.DirContent.files:type_name -> runixo.FileInfo
+	91,  // 54: runixo.ServiceList.services:type_name -> runixo.ServiceInfo
+	2,   // 55: runixo.ServiceActionRequest.action:type_name -> runixo.ServiceAction
+	95,  // 56: runixo.ProcessList.processes:type_name -> runixo.ProcessInfo
+	95,  // 57: runixo.ProcessNode.process:type_name -> runixo.ProcessInfo
+	97,  // 58: runixo.ProcessNode.children:type_name -> runixo.ProcessNode
+	97,  // 59: runixo.ProcessTree.roots:type_name -> runixo.ProcessNode
+	99,  // 60: runixo.NetworkConnections.listening:type_name -> runixo.ListeningPort
+	150, // 61: runixo.NetworkConnections.state_counts:type_name -> runixo.NetworkConnections.StateCountsEntry
+	103, // 62: runixo.ActionResponse.violations:type_name -> runixo.FieldViolation
+	106, // 63: runixo.DockerSearchResponse.results:type_name -> runixo.DockerImage
+	151, // 64: runixo.HttpProxyRequest.headers:type_name -> runixo.HttpProxyRequest.HeadersEntry
+	152, // 65: runixo.HttpProxyResponse.headers:type_name -> runixo.HttpProxyResponse.HeadersEntry
+	112, // 66: runixo.PluginUpload.start:type_name -> runixo.PluginUploadStart
+	114, // 67: runixo.PluginList.plugins:type_name -> runixo.PluginInfo
+	3,   // 68: runixo.PluginInfo.state:type_name -> runixo.PluginState
+	4,   // 69: runixo.PluginInfo.type:type_name -> runixo.PluginType
+	3,   // 70: runixo.PluginStatus.state:type_name -> runixo.PluginState
+	153, // 71: runixo.PluginStatus.stats:type_name -> runixo.PluginStatus.StatsEntry
+	127, // 72: runixo.PluginStatus.usage:type_name -> runixo.PluginResourceUsage
+	119, // 73: runixo.PluginVerifyResult.steps:type_name -> runixo.PluginVerifyStep
+	120, // 74: runixo.PluginVerifyResult.denials:type_name -> runixo.PluginPermissionDenial
+	126, // 75: runixo.ImportPluginConfigsResponse.results:type_name -> runixo.PluginImportResult
+	129, // 76: runixo.AvailablePluginList.plugins:type_name -> runixo.AvailablePlugin
+	4,   // 77: runixo.AvailablePlugin.type:type_name -> runixo.PluginType
+	129, // 78: runixo.SearchPluginsResponse.plugins:type_name -> runixo.AvailablePlugin
+	132, // 79: runixo.SearchPluginsResponse.categories:type_name -> runixo.PluginCategory
+	129, // 80: runixo.PluginDetails.plugin:type_name -> runixo.AvailablePlugin
+	136, // 81: runixo.ScheduledTaskList.tasks:type_name -> runixo.ScheduledTask
+	142, // 82: runixo.UpdateHistory.records:type_name -> runixo.UpdateRecord
+	6,   // 83: runixo.AgentService.Authenticate:input_type -> runixo.AuthRequest
+	5,   // 84: runixo.AgentService.GetSystemInfo:input_type -> runixo.Empty
+	22,  // 85: runixo.AgentService.GetMetrics:input_type -> runixo.MetricsRequest
+	34,  // 86: runixo.AgentService.ExecuteCommand:input_type -> runixo.CommandRequest
+	34,  // 87: runixo.AgentService.ExecuteStream:input_type -> runixo.CommandRequest
+	35,  // 88: runixo.AgentService.ExecuteScript:input_type -> runixo.ScriptRequest
+	37,  // 89: runixo.AgentService.ExecuteBatch:input_type -> runixo.BatchRequest
+	45,  // 90: runixo.AgentService.ExecuteShell:input_type -> runixo.ShellInput
+	42,  // 91: runixo.AgentService.GetExecHistory:input_type -> runixo.ExecHistoryRequest
+	50,  // 92: runixo.AgentService.ReadFile:input_type -> runixo.FileRequest
+	53,  // 93: runixo.AgentService.WriteFile:input_type -> runixo.WriteFileRequest
+	85,  // 94: runixo.AgentService.ListDirectory:input_type -> runixo.DirRequest
+	50,  // 95: runixo.AgentService.DeleteFile:input_type -> runixo.FileRequest
+	54,  // 96: runixo.AgentService.UploadFile:input_type -> runixo.FileChunk
+	50,  // 97: runixo.AgentService.DownloadFile:input_type -> runixo.FileRequest
+	50,  // 98: runixo.AgentService.GetUploadOffset:input_type -> runixo.FileRequest
+	59,  // 99: runixo.AgentService.HashFile:input_type -> runixo.HashFileRequest
+	61,  // 100: runixo.AgentService.CompareFiles:input_type -> runixo.CompareFilesRequest
+	63,  // 101: runixo.AgentService.SearchFiles:input_type -> runixo.SearchFilesRequest
+	67,  // 102: runixo.AgentService.CreateArchive:input_type -> runixo.CreateArchiveRequest
+	68,  // 103: runixo.AgentService.ExtractArchive:input_type -> runixo.ExtractArchiveRequest
+	70,  // 104: runixo.AgentService.Chmod:input_type -> runixo.ChmodRequest
+	71,  // 105: runixo.AgentService.Chown:input_type -> runixo.ChownRequest
+	72,  // 106: runixo.AgentService.SetACL:input_type -> runixo.SetACLRequest
+	75,  // 107: runixo.AgentService.CopyPath:input_type -> runixo.CopyPathRequest
+	76,  // 108: runixo.AgentService.MovePath:input_type -> runixo.MovePathRequest
+	77,  // 109: runixo.AgentService.DeletePath:input_type -> runixo.DeletePathRequest
+	5,   // 110: runixo.AgentService.ListTrash:input_type -> runixo.Empty
+	81,  // 111: runixo.AgentService.RestoreTrash:input_type -> runixo.RestoreTrashRequest
+	82,  // 112: runixo.AgentService.GetDirectorySize:input_type -> runixo.DirectorySizeRequest
+	87,  // 113: runixo.AgentService.TailLog:input_type -> runixo.LogRequest
+	89,  // 114: runixo.AgentService.ListServices:input_type -> runixo.ServiceFilter
+	92,  // 115: runixo.AgentService.ServiceAction:input_type -> runixo.ServiceActionRequest
+	93,  // 116: runixo.AgentService.ListProcesses:input_type -> runixo.ProcessFilter
+	96,  // 117: runixo.AgentService.GetProcessTree:input_type -> runixo.ProcessTreeRequest
+	101, // 118: runixo.AgentService.KillProcess:input_type -> runixo.KillProcessRequest
+	5,   // 119: runixo.AgentService.GetNetworkConnections:input_type -> runixo.Empty
+	104, // 120: runixo.AgentService.SearchDockerHub:input_type -> runixo.DockerSearchRequest
+	107, // 121: runixo.AgentService.ProxyHttpRequest:input_type -> runixo.HttpProxyRequest
+	5,   // 122: runixo.AgentService.DownloadCertificate:input_type -> runixo.Empty
+	5,   // 123: runixo.PluginService.ListPlugins:input_type -> runixo.Empty
+	110, // 124: runixo.PluginService.InstallPlugin:input_type -> runixo.InstallPluginRequest
+	111, // 125: runixo.PluginService.UploadPlugin:input_type -> runixo.PluginUpload
+	109, // 126: runixo.PluginService.UninstallPlugin:input_type -> runixo.PluginRequest
+	109, // 127: runixo.PluginService.EnablePlugin:input_type -> runixo.PluginRequest
+	109, // 128: runixo.PluginService.VerifyPlugin:input_type -> runixo.PluginRequest
+	109, // 129: runixo.PluginService.DisablePlugin:input_type -> runixo.PluginRequest
+	109, // 130: runixo.PluginService.GetPluginConfig:input_type -> runixo.PluginRequest
+	116, // 131: runixo.PluginService.SetPluginConfig:input_type -> runixo.SetPluginConfigRequest
+	109, // 132: runixo.PluginService.GetPluginStatus:input_type -> runixo.PluginRequest
+	109, // 133: runixo.PluginService.GetPluginDiagnostics:input_type -> runixo.PluginRequest
+	5,   // 134: runixo.PluginService.GetAvailablePlugins:input_type -> runixo.Empty
+	130, // 135: runixo.PluginService.SearchPlugins:input_type -> runixo.SearchPluginsRequest
+	109, // 136: runixo.PluginService.GetPluginDetails:input_type -> runixo.PluginRequest
+	134, // 137: runixo.PluginService.ListScheduledTasks:input_type -> runixo.ListScheduledTasksRequest
+	122, // 138: runixo.PluginService.ExportPluginConfigs:input_type -> runixo.ExportPluginConfigsRequest
+	124, // 139: runixo.PluginService.ImportPluginConfigs:input_type -> runixo.ImportPluginConfigsRequest
+	5,   // 140: runixo.UpdateService.CheckUpdate:input_type -> runixo.Empty
+	138, // 141: runixo.UpdateService.DownloadUpdate:input_type -> runixo.UpdateRequest
+	138, // 142: runixo.UpdateService.ApplyUpdate:input_type -> runixo.UpdateRequest
+	5,   // 143: runixo.UpdateService.GetUpdateConfig:input_type -> runixo.Empty
+	140, // 144: runixo.UpdateService.SetUpdateConfig:input_type -> runixo.UpdateConfig
+	5,   // 145: runixo.UpdateService.GetUpdateHistory:input_type -> runixo.Empty
+	7,   // 146: runixo.AgentService.Authenticate:output_type -> runixo.AuthResponse
+	8,   // 147: runixo.AgentService.GetSystemInfo:output_type -> runixo.SystemInfo
+	23,  // 148: runixo.AgentService.GetMetrics:output_type -> runixo.Metrics
+	36,  // 149: runixo.AgentService.ExecuteCommand:output_type -> runixo.CommandResponse
+	40,  // 150: runixo.AgentService.ExecuteStream:output_type -> runixo.CommandOutput
+	36,  // 151: runixo.AgentService.ExecuteScript:output_type -> runixo.CommandResponse
+	39,  // 152: runixo.AgentService.ExecuteBatch:output_type -> runixo.BatchResponse
+	48,  // 153: runixo.AgentService.ExecuteShell:output_type -> runixo.ShellOutput
+	44,  // 154: runixo.AgentService.GetExecHistory:output_type -> runixo.ExecHistory
+	51,  // 155: runixo.AgentService.ReadFile:output_type -> runixo.FileContent
+	102, // 156: runixo.AgentService.WriteFile:output_type -> runixo.ActionResponse
+	86,  // 157: runixo.AgentService.ListDirectory:output_type -> runixo.DirContent
+	102, // 158: runixo.AgentService.DeleteFile:output_type -> runixo.ActionResponse
+	57,  // 159: runixo.AgentService.UploadFile:output_type -> runixo.UploadResponse
+	54,  // 160: runixo.AgentService.DownloadFile:output_type -> runixo.FileChunk
+	58,  // 161: runixo.AgentService.GetUploadOffset:output_type -> runixo.UploadOffset
+	60,  // 162: runixo.AgentService.HashFile:output_type -> runixo.FileHash
+	62,  // 163: runixo.AgentService.CompareFiles:output_type -> runixo.FileComparison
+	66,  // 164: runixo.AgentService.SearchFiles:output_type -> runixo.SearchFilesResponse
+	69,  // 165: runixo.AgentService.CreateArchive:output_type -> runixo.ArchiveProgress
+	69,  // 166: runixo.AgentService.ExtractArchive:output_type -> runixo.ArchiveProgress
+	74,  // 167: runixo.AgentService.Chmod:output_type -> runixo.PermissionResult
+	74,  // 168: runixo.AgentService.Chown:output_type -> runixo.PermissionResult
+	74,  // 169: runixo.AgentService.SetACL:output_type -> runixo.PermissionResult
+	78,  // 170: runixo.AgentService.CopyPath:output_type -> runixo.FileOpResult
+	78,  // 171: runixo.AgentService.MovePath:output_type -> runixo.FileOpResult
+	78,  // 172: runixo.AgentService.DeletePath:output_type -> runixo.FileOpResult
+	80,  // 173: runixo.AgentService.ListTrash:output_type -> runixo.TrashList
+	78,  // 174: runixo.AgentService.RestoreTrash:output_type -> runixo.FileOpResult
+	84,  // 175: runixo.AgentService.GetDirectorySize:output_type -> runixo.DirectorySizeResponse
+	88,  // 176: runixo.AgentService.TailLog:output_type -> runixo.LogLine
+	90,  // 177: runixo.AgentService.ListServices:output_type -> runixo.ServiceList
+	102, // 178: runixo.AgentService.ServiceAction:output_type -> runixo.ActionResponse
+	94,  // 179: runixo.AgentService.ListProcesses:output_type -> runixo.ProcessList
+	98,  // 180: runixo.AgentService.GetProcessTree:output_type -> runixo.ProcessTree
+	102, // 181: runixo.AgentService.KillProcess:output_type -> runixo.ActionResponse
+	100, // 182: runixo.AgentService.GetNetworkConnections:output_type -> runixo.NetworkConnections
+	105, // 183: runixo.AgentService.SearchDockerHub:output_type -> runixo.DockerSearchResponse
+	108, // 184: runixo.AgentService.ProxyHttpRequest:output_type -> runixo.HttpProxyResponse
+	143, // 185: runixo.AgentService.DownloadCertificate:output_type -> runixo.CertificateResponse
+	113, // 186: runixo.PluginService.ListPlugins:output_type -> runixo.PluginList
+	102, // 187: runixo.PluginService.InstallPlugin:output_type -> runixo.ActionResponse
+	102, // 188: runixo.PluginService.UploadPlugin:output_type -> runixo.ActionResponse
+	102, // 189: runixo.PluginService.UninstallPlugin:output_type -> runixo.ActionResponse
+	102, // 190: runixo.PluginService.EnablePlugin:output_type -> runixo.ActionResponse
+	118, // 191: runixo.PluginService.VerifyPlugin:output_type -> runixo.PluginVerifyResult
+	102, // 192: runixo.PluginService.DisablePlugin:output_type -> runixo.ActionResponse
+	115, // 193: runixo.PluginService.GetPluginConfig:output_type -> runixo.PluginConfig
+	102, // 194: runixo.PluginService.SetPluginConfig:output_type -> runixo.ActionResponse
+	117, // 195: runixo.PluginService.GetPluginStatus:output_type -> runixo.PluginStatus
+	121, // 196: runixo.PluginService.GetPluginDiagnostics:output_type -> runixo.PluginDiagnostics
+	128, // 197: runixo.PluginService.GetAvailablePlugins:output_type -> runixo.AvailablePluginList
+	131, // 198: runixo.PluginService.SearchPlugins:output_type -> runixo.SearchPluginsResponse
+	133, // 199: runixo.PluginService.GetPluginDetails:output_type -> runixo.PluginDetails
+	135, // 200: runixo.PluginService.ListScheduledTasks:output_type -> runixo.ScheduledTaskList
+	123, // 201: runixo.PluginService.ExportPluginConfigs:output_type -> runixo.PluginConfigBundle
+	125, // 202: runixo.PluginService.ImportPluginConfigs:output_type -> runixo.ImportPluginConfigsResponse
+	137, // 203: runixo.UpdateService.CheckUpdate:output_type -> runixo.UpdateInfo
+	139, // 204: runixo.UpdateService.DownloadUpdate:output_type -> runixo.DownloadProgress
+	102, // 205: runixo.UpdateService.ApplyUpdate:output_type -> runixo.ActionResponse
+	140, // 206: runixo.UpdateService.GetUpdateConfig:output_type -> runixo.UpdateConfig
+	102, // 207: runixo.UpdateService.SetUpdateConfig:output_type -> runixo.ActionResponse
+	141, // 208: runixo.UpdateService.GetUpdateHistory:output_type -> runixo.UpdateHistory
+	146, // [146:209] is the sub-list for method output_type
+	83,  // [83:146] is the sub-list for method input_type
+	83,  // [83:83] is the sub-list for extension type_name
+	83,  // [83:83] is the sub-list for extension extendee
+	0,   // [0:83] is the sub-list for field type_name
 }
 
 func init() { file_agent_proto_init() }
@@ -5085,23 +12238,33 @@ func file_agent_proto_init() {
 	if File_agent_proto != nil {
 		return
 	}
-	file_agent_proto_msgTypes[15].OneofWrappers = []any{
+	file_agent_proto_msgTypes[35].OneofWrappers = []any{
+		(*CommandOutput_Stdout)(nil),
+		(*CommandOutput_Stderr)(nil),
+		(*CommandOutput_Exit)(nil),
+	}
+	file_agent_proto_msgTypes[40].OneofWrappers = []any{
 		(*ShellInput_Start)(nil),
 		(*ShellInput_Data)(nil),
 		(*ShellInput_Resize)(nil),
+		(*ShellInput_Signal)(nil),
 	}
-	file_agent_proto_msgTypes[23].OneofWrappers = []any{
+	file_agent_proto_msgTypes[49].OneofWrappers = []any{
 		(*FileChunk_Start)(nil),
 		(*FileChunk_Chunk)(nil),
 		(*FileChunk_End)(nil),
 	}
+	file_agent_proto_msgTypes[106].OneofWrappers = []any{
+		(*PluginUpload_Start)(nil),
+		(*PluginUpload_Chunk)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   65,
+			NumEnums:      5,
+			NumMessages:   149,
 			NumExtensions: 0,
 			NumServices:   3,
 		},
@@ -19,24 +19,46 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AgentService_Authenticate_FullMethodName     = "/runixo.AgentService/Authenticate"
-	AgentService_GetSystemInfo_FullMethodName    = "/runixo.AgentService/GetSystemInfo"
-	AgentService_GetMetrics_FullMethodName       = "/runixo.AgentService/GetMetrics"
-	AgentService_ExecuteCommand_FullMethodName   = "/runixo.AgentService/ExecuteCommand"
-	AgentService_ExecuteShell_FullMethodName     = "/runixo.AgentService/ExecuteShell"
-	AgentService_ReadFile_FullMethodName         = "/runixo.AgentService/ReadFile"
-	AgentService_WriteFile_FullMethodName        = "/runixo.AgentService/WriteFile"
-	AgentService_ListDirectory_FullMethodName    = "/runixo.AgentService/ListDirectory"
-	AgentService_DeleteFile_FullMethodName       = "/runixo.AgentService/DeleteFile"
-	AgentService_UploadFile_FullMethodName       = "/runixo.AgentService/UploadFile"
-	AgentService_DownloadFile_FullMethodName     = "/runixo.AgentService/DownloadFile"
-	AgentService_TailLog_FullMethodName          = "/runixo.AgentService/TailLog"
-	AgentService_ListServices_FullMethodName     = "/runixo.AgentService/ListServices"
-	AgentService_ServiceAction_FullMethodName    = "/runixo.AgentService/ServiceAction"
-	AgentService_ListProcesses_FullMethodName    = "/runixo.AgentService/ListProcesses"
-	AgentService_KillProcess_FullMethodName      = "/runixo.AgentService/KillProcess"
-	AgentService_SearchDockerHub_FullMethodName  = "/runixo.AgentService/SearchDockerHub"
-	AgentService_ProxyHttpRequest_FullMethodName = "/runixo.AgentService/ProxyHttpRequest"
+	AgentService_Authenticate_FullMethodName          = "/runixo.AgentService/Authenticate"
+	AgentService_GetSystemInfo_FullMethodName         = "/runixo.AgentService/GetSystemInfo"
+	AgentService_GetMetrics_FullMethodName            = "/runixo.AgentService/GetMetrics"
+	AgentService_ExecuteCommand_FullMethodName        = "/runixo.AgentService/ExecuteCommand"
+	AgentService_ExecuteStream_FullMethodName         = "/runixo.AgentService/ExecuteStream"
+	AgentService_ExecuteScript_FullMethodName         = "/runixo.AgentService/ExecuteScript"
+	AgentService_ExecuteBatch_FullMethodName          = "/runixo.AgentService/ExecuteBatch"
+	AgentService_ExecuteShell_FullMethodName          = "/runixo.AgentService/ExecuteShell"
+	AgentService_GetExecHistory_FullMethodName        = "/runixo.AgentService/GetExecHistory"
+	AgentService_ReadFile_FullMethodName              = "/runixo.AgentService/ReadFile"
+	AgentService_WriteFile_FullMethodName             = "/runixo.AgentService/WriteFile"
+	AgentService_ListDirectory_FullMethodName         = "/runixo.AgentService/ListDirectory"
+	AgentService_DeleteFile_FullMethodName            = "/runixo.AgentService/DeleteFile"
+	AgentService_UploadFile_FullMethodName            = "/runixo.AgentService/UploadFile"
+	AgentService_DownloadFile_FullMethodName          = "/runixo.AgentService/DownloadFile"
+	AgentService_GetUploadOffset_FullMethodName       = "/runixo.AgentService/GetUploadOffset"
+	AgentService_HashFile_FullMethodName              = "/runixo.AgentService/HashFile"
+	AgentService_CompareFiles_FullMethodName          = "/runixo.AgentService/CompareFiles"
+	AgentService_SearchFiles_FullMethodName           = "/runixo.AgentService/SearchFiles"
+	AgentService_CreateArchive_FullMethodName         = "/runixo.AgentService/CreateArchive"
+	AgentService_ExtractArchive_FullMethodName        = "/runixo.AgentService/ExtractArchive"
+	AgentService_Chmod_FullMethodName                 = "/runixo.AgentService/Chmod"
+	AgentService_Chown_FullMethodName                 = "/runixo.AgentService/Chown"
+	AgentService_SetACL_FullMethodName                = "/runixo.AgentService/SetACL"
+	AgentService_CopyPath_FullMethodName              = "/runixo.AgentService/CopyPath"
+	AgentService_MovePath_FullMethodName              = "/runixo.AgentService/MovePath"
+	AgentService_DeletePath_FullMethodName            = "/runixo.AgentService/DeletePath"
+	AgentService_ListTrash_FullMethodName             = "/runixo.AgentService/ListTrash"
+	AgentService_RestoreTrash_FullMethodName          = "/runixo.AgentService/RestoreTrash"
+	AgentService_GetDirectorySize_FullMethodName      = "/runixo.AgentService/GetDirectorySize"
+	AgentService_TailLog_FullMethodName               = "/runixo.AgentService/TailLog"
+	AgentService_ListServices_FullMethodName          = "/runixo.AgentService/ListServices"
+	AgentService_ServiceAction_FullMethodName         = "/runixo.AgentService/ServiceAction"
+	AgentService_ListProcesses_FullMethodName         = "/runixo.AgentService/ListProcesses"
+	AgentService_GetProcessTree_FullMethodName        = "/runixo.AgentService/GetProcessTree"
+	AgentService_KillProcess_FullMethodName           = "/runixo.AgentService/KillProcess"
+	AgentService_GetNetworkConnections_FullMethodName = "/runixo.AgentService/GetNetworkConnections"
+	AgentService_SearchDockerHub_FullMethodName       = "/runixo.AgentService/SearchDockerHub"
+	AgentService_ProxyHttpRequest_FullMethodName      = "/runixo.AgentService/ProxyHttpRequest"
+	AgentService_DownloadCertificate_FullMethodName   = "/runixo.AgentService/DownloadCertificate"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -50,16 +72,47 @@ type AgentServiceClient interface {
 	GetMetrics(ctx context.Context, in *MetricsRequest, opts ...grpc.CallOption) (AgentService_GetMetricsClient, error)
 	// 命令执行
 	ExecuteCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResponse, error)
+	// 流式执行：输出产生时即发送，最后一条消息为退出状态；timeout_seconds 为 0 时不限时
+	ExecuteStream(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (AgentService_ExecuteStreamClient, error)
+	// 执行脚本：写入临时文件后由指定解释器执行（需在配置中启用）
+	ExecuteScript(ctx context.Context, in *ScriptRequest, opts ...grpc.CallOption) (*CommandResponse, error)
+	// 批量执行：按并发上限执行一组命令，全部结束后按请求顺序返回每条命令的结果
+	ExecuteBatch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// 交互式终端（PTY / ConPTY），需要在配置中启用 shell.enabled
 	ExecuteShell(ctx context.Context, opts ...grpc.CallOption) (AgentService_ExecuteShellClient, error)
+	// 审计日志中的命令执行记录，按时间倒序
+	GetExecHistory(ctx context.Context, in *ExecHistoryRequest, opts ...grpc.CallOption) (*ExecHistory, error)
 	// 文件操作
 	ReadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*FileContent, error)
 	WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*ActionResponse, error)
 	ListDirectory(ctx context.Context, in *DirRequest, opts ...grpc.CallOption) (*DirContent, error)
 	DeleteFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*ActionResponse, error)
-	// 流式文件上传 - 支持大文件
+	// 流式文件上传 - 支持大文件、断点续传和 sha256 校验
 	UploadFile(ctx context.Context, opts ...grpc.CallOption) (AgentService_UploadFileClient, error)
-	// 流式文件下载 - 支持大文件
+	// 流式文件下载 - 支持大文件和断点续传，结束消息携带 sha256
 	DownloadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (AgentService_DownloadFileClient, error)
+	// 查询未完成上传的续传偏移
+	GetUploadOffset(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*UploadOffset, error)
+	// 文件校验和与比较，无需下载文件
+	HashFile(ctx context.Context, in *HashFileRequest, opts ...grpc.CallOption) (*FileHash, error)
+	CompareFiles(ctx context.Context, in *CompareFilesRequest, opts ...grpc.CallOption) (*FileComparison, error)
+	// 递归搜索文件（文件名、大小、修改时间，可选内容正则）
+	SearchFiles(ctx context.Context, in *SearchFilesRequest, opts ...grpc.CallOption) (*SearchFilesResponse, error)
+	// 打包与解压（tar.gz / zip），流式返回进度，最后一条消息 done=true
+	CreateArchive(ctx context.Context, in *CreateArchiveRequest, opts ...grpc.CallOption) (AgentService_CreateArchiveClient, error)
+	ExtractArchive(ctx context.Context, in *ExtractArchiveRequest, opts ...grpc.CallOption) (AgentService_ExtractArchiveClient, error)
+	// 权限与属主管理，支持递归和 dry_run 预览
+	Chmod(ctx context.Context, in *ChmodRequest, opts ...grpc.CallOption) (*PermissionResult, error)
+	Chown(ctx context.Context, in *ChownRequest, opts ...grpc.CallOption) (*PermissionResult, error)
+	SetACL(ctx context.Context, in *SetACLRequest, opts ...grpc.CallOption) (*PermissionResult, error)
+	// 复制、移动和删除，删除可移入回收站以便撤销
+	CopyPath(ctx context.Context, in *CopyPathRequest, opts ...grpc.CallOption) (*FileOpResult, error)
+	MovePath(ctx context.Context, in *MovePathRequest, opts ...grpc.CallOption) (*FileOpResult, error)
+	DeletePath(ctx context.Context, in *DeletePathRequest, opts ...grpc.CallOption) (*FileOpResult, error)
+	ListTrash(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TrashList, error)
+	RestoreTrash(ctx context.Context, in *RestoreTrashRequest, opts ...grpc.CallOption) (*FileOpResult, error)
+	// 目录占用统计（du），返回最大子目录的分层明细
+	GetDirectorySize(ctx context.Context, in *DirectorySizeRequest, opts ...grpc.CallOption) (*DirectorySizeResponse, error)
 	// 日志流
 	TailLog(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (AgentService_TailLogClient, error)
 	// 服务管理
@@ -67,11 +120,16 @@ type AgentServiceClient interface {
 	ServiceAction(ctx context.Context, in *ServiceActionRequest, opts ...grpc.CallOption) (*ActionResponse, error)
 	// 进程管理
 	ListProcesses(ctx context.Context, in *ProcessFilter, opts ...grpc.CallOption) (*ProcessList, error)
+	GetProcessTree(ctx context.Context, in *ProcessTreeRequest, opts ...grpc.CallOption) (*ProcessTree, error)
 	KillProcess(ctx context.Context, in *KillProcessRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	// 网络连接（监听端口及所属进程）
+	GetNetworkConnections(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NetworkConnections, error)
 	// Docker Hub 搜索（通过服务端代理）
 	SearchDockerHub(ctx context.Context, in *DockerSearchRequest, opts ...grpc.CallOption) (*DockerSearchResponse, error)
 	// HTTP 代理请求（通用）
 	ProxyHttpRequest(ctx context.Context, in *HttpProxyRequest, opts ...grpc.CallOption) (*HttpProxyResponse, error)
+	// TLS 证书管理
+	DownloadCertificate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CertificateResponse, error)
 }
 
 type agentServiceClient struct {
@@ -141,8 +199,58 @@ func (c *agentServiceClient) ExecuteCommand(ctx context.Context, in *CommandRequ
 	return out, nil
 }
 
+func (c *agentServiceClient) ExecuteStream(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (AgentService_ExecuteStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_ExecuteStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentServiceExecuteStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AgentService_ExecuteStreamClient interface {
+	Recv() (*CommandOutput, error)
+	grpc.ClientStream
+}
+
+type agentServiceExecuteStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentServiceExecuteStreamClient) Recv() (*CommandOutput, error) {
+	m := new(CommandOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentServiceClient) ExecuteScript(ctx context.Context, in *ScriptRequest, opts ...grpc.CallOption) (*CommandResponse, error) {
+	out := new(CommandResponse)
+	err := c.cc.Invoke(ctx, AgentService_ExecuteScript_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ExecuteBatch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, AgentService_ExecuteBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) ExecuteShell(ctx context.Context, opts ...grpc.CallOption) (AgentService_ExecuteShellClient, error) {
-	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_ExecuteShell_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[2], AgentService_ExecuteShell_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -172,6 +280,15 @@ func (x *agentServiceExecuteShellClient) Recv() (*ShellOutput, error) {
 	return m, nil
 }
 
+func (c *agentServiceClient) GetExecHistory(ctx context.Context, in *ExecHistoryRequest, opts ...grpc.CallOption) (*ExecHistory, error) {
+	out := new(ExecHistory)
+	err := c.cc.Invoke(ctx, AgentService_GetExecHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) ReadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*FileContent, error) {
 	out := new(FileContent)
 	err := c.cc.Invoke(ctx, AgentService_ReadFile_FullMethodName, in, out, opts...)
@@ -209,7 +326,7 @@ func (c *agentServiceClient) DeleteFile(ctx context.Context, in *FileRequest, op
 }
 
 func (c *agentServiceClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (AgentService_UploadFileClient, error) {
-	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[2], AgentService_UploadFile_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[3], AgentService_UploadFile_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +360,7 @@ func (x *agentServiceUploadFileClient) CloseAndRecv() (*UploadResponse, error) {
 }
 
 func (c *agentServiceClient) DownloadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (AgentService_DownloadFileClient, error) {
-	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[3], AgentService_DownloadFile_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[4], AgentService_DownloadFile_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -274,8 +391,189 @@ func (x *agentServiceDownloadFileClient) Recv() (*FileChunk, error) {
 	return m, nil
 }
 
+func (c *agentServiceClient) GetUploadOffset(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*UploadOffset, error) {
+	out := new(UploadOffset)
+	err := c.cc.Invoke(ctx, AgentService_GetUploadOffset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) HashFile(ctx context.Context, in *HashFileRequest, opts ...grpc.CallOption) (*FileHash, error) {
+	out := new(FileHash)
+	err := c.cc.Invoke(ctx, AgentService_HashFile_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CompareFiles(ctx context.Context, in *CompareFilesRequest, opts ...grpc.CallOption) (*FileComparison, error) {
+	out := new(FileComparison)
+	err := c.cc.Invoke(ctx, AgentService_CompareFiles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) SearchFiles(ctx context.Context, in *SearchFilesRequest, opts ...grpc.CallOption) (*SearchFilesResponse, error) {
+	out := new(SearchFilesResponse)
+	err := c.cc.Invoke(ctx, AgentService_SearchFiles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CreateArchive(ctx context.Context, in *CreateArchiveRequest, opts ...grpc.CallOption) (AgentService_CreateArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[5], AgentService_CreateArchive_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentServiceCreateArchiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AgentService_CreateArchiveClient interface {
+	Recv() (*ArchiveProgress, error)
+	grpc.ClientStream
+}
+
+type agentServiceCreateArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentServiceCreateArchiveClient) Recv() (*ArchiveProgress, error) {
+	m := new(ArchiveProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentServiceClient) ExtractArchive(ctx context.Context, in *ExtractArchiveRequest, opts ...grpc.CallOption) (AgentService_ExtractArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[6], AgentService_ExtractArchive_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentServiceExtractArchiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AgentService_ExtractArchiveClient interface {
+	Recv() (*ArchiveProgress, error)
+	grpc.ClientStream
+}
+
+type agentServiceExtractArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentServiceExtractArchiveClient) Recv() (*ArchiveProgress, error) {
+	m := new(ArchiveProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *agentServiceClient) Chmod(ctx context.Context, in *ChmodRequest, opts ...grpc.CallOption) (*PermissionResult, error) {
+	out := new(PermissionResult)
+	err := c.cc.Invoke(ctx, AgentService_Chmod_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) Chown(ctx context.Context, in *ChownRequest, opts ...grpc.CallOption) (*PermissionResult, error) {
+	out := new(PermissionResult)
+	err := c.cc.Invoke(ctx, AgentService_Chown_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) SetACL(ctx context.Context, in *SetACLRequest, opts ...grpc.CallOption) (*PermissionResult, error) {
+	out := new(PermissionResult)
+	err := c.cc.Invoke(ctx, AgentService_SetACL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CopyPath(ctx context.Context, in *CopyPathRequest, opts ...grpc.CallOption) (*FileOpResult, error) {
+	out := new(FileOpResult)
+	err := c.cc.Invoke(ctx, AgentService_CopyPath_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) MovePath(ctx context.Context, in *MovePathRequest, opts ...grpc.CallOption) (*FileOpResult, error) {
+	out := new(FileOpResult)
+	err := c.cc.Invoke(ctx, AgentService_MovePath_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) DeletePath(ctx context.Context, in *DeletePathRequest, opts ...grpc.CallOption) (*FileOpResult, error) {
+	out := new(FileOpResult)
+	err := c.cc.Invoke(ctx, AgentService_DeletePath_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListTrash(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TrashList, error) {
+	out := new(TrashList)
+	err := c.cc.Invoke(ctx, AgentService_ListTrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) RestoreTrash(ctx context.Context, in *RestoreTrashRequest, opts ...grpc.CallOption) (*FileOpResult, error) {
+	out := new(FileOpResult)
+	err := c.cc.Invoke(ctx, AgentService_RestoreTrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetDirectorySize(ctx context.Context, in *DirectorySizeRequest, opts ...grpc.CallOption) (*DirectorySizeResponse, error) {
+	out := new(DirectorySizeResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetDirectorySize_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) TailLog(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (AgentService_TailLogClient, error) {
-	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[4], AgentService_TailLog_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[7], AgentService_TailLog_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -333,6 +631,15 @@ func (c *agentServiceClient) ListProcesses(ctx context.Context, in *ProcessFilte
 	return out, nil
 }
 
+func (c *agentServiceClient) GetProcessTree(ctx context.Context, in *ProcessTreeRequest, opts ...grpc.CallOption) (*ProcessTree, error) {
+	out := new(ProcessTree)
+	err := c.cc.Invoke(ctx, AgentService_GetProcessTree_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) KillProcess(ctx context.Context, in *KillProcessRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
 	out := new(ActionResponse)
 	err := c.cc.Invoke(ctx, AgentService_KillProcess_FullMethodName, in, out, opts...)
@@ -342,6 +649,15 @@ func (c *agentServiceClient) KillProcess(ctx context.Context, in *KillProcessReq
 	return out, nil
 }
 
+func (c *agentServiceClient) GetNetworkConnections(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NetworkConnections, error) {
+	out := new(NetworkConnections)
+	err := c.cc.Invoke(ctx, AgentService_GetNetworkConnections_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) SearchDockerHub(ctx context.Context, in *DockerSearchRequest, opts ...grpc.CallOption) (*DockerSearchResponse, error) {
 	out := new(DockerSearchResponse)
 	err := c.cc.Invoke(ctx, AgentService_SearchDockerHub_FullMethodName, in, out, opts...)
@@ -360,6 +676,15 @@ func (c *agentServiceClient) ProxyHttpRequest(ctx context.Context, in *HttpProxy
 	return out, nil
 }
 
+func (c *agentServiceClient) DownloadCertificate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CertificateResponse, error) {
+	out := new(CertificateResponse)
+	err := c.cc.Invoke(ctx, AgentService_DownloadCertificate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility
@@ -371,16 +696,47 @@ type AgentServiceServer interface {
 	GetMetrics(*MetricsRequest, AgentService_GetMetricsServer) error
 	// 命令执行
 	ExecuteCommand(context.Context, *CommandRequest) (*CommandResponse, error)
+	// 流式执行：输出产生时即发送，最后一条消息为退出状态；timeout_seconds 为 0 时不限时
+	ExecuteStream(*CommandRequest, AgentService_ExecuteStreamServer) error
+	// 执行脚本：写入临时文件后由指定解释器执行（需在配置中启用）
+	ExecuteScript(context.Context, *ScriptRequest) (*CommandResponse, error)
+	// 批量执行：按并发上限执行一组命令，全部结束后按请求顺序返回每条命令的结果
+	ExecuteBatch(context.Context, *BatchRequest) (*BatchResponse, error)
+	// 交互式终端（PTY / ConPTY），需要在配置中启用 shell.enabled
 	ExecuteShell(AgentService_ExecuteShellServer) error
+	// 审计日志中的命令执行记录，按时间倒序
+	GetExecHistory(context.Context, *ExecHistoryRequest) (*ExecHistory, error)
 	// 文件操作
 	ReadFile(context.Context, *FileRequest) (*FileContent, error)
 	WriteFile(context.Context, *WriteFileRequest) (*ActionResponse, error)
 	ListDirectory(context.Context, *DirRequest) (*DirContent, error)
 	DeleteFile(context.Context, *FileRequest) (*ActionResponse, error)
-	// 流式文件上传 - 支持大文件
+	// 流式文件上传 - 支持大文件、断点续传和 sha256 校验
 	UploadFile(AgentService_UploadFileServer) error
-	// 流式文件下载 - 支持大文件
+	// 流式文件下载 - 支持大文件和断点续传，结束消息携带 sha256
 	DownloadFile(*FileRequest, AgentService_DownloadFileServer) error
+	// 查询未完成上传的续传偏移
+	GetUploadOffset(context.Context, *FileRequest) (*UploadOffset, error)
+	// 文件校验和与比较，无需下载文件
+	HashFile(context.Context, *HashFileRequest) (*FileHash, error)
+	CompareFiles(context.Context, *CompareFilesRequest) (*FileComparison, error)
+	// 递归搜索文件（文件名、大小、修改时间，可选内容正则）
+	SearchFiles(context.Context, *SearchFilesRequest) (*SearchFilesResponse, error)
+	// 打包与解压（tar.gz / zip），流式返回进度，最后一条消息 done=true
+	CreateArchive(*CreateArchiveRequest, AgentService_CreateArchiveServer) error
+	ExtractArchive(*ExtractArchiveRequest, AgentService_ExtractArchiveServer) error
+	// 权限与属主管理，支持递归和 dry_run 预览
+	Chmod(context.Context, *ChmodRequest) (*PermissionResult, error)
+	Chown(context.Context, *ChownRequest) (*PermissionResult, error)
+	SetACL(context.Context, *SetACLRequest) (*PermissionResult, error)
+	// 复制、移动和删除，删除可移入回收站以便撤销
+	CopyPath(context.Context, *CopyPathRequest) (*FileOpResult, error)
+	MovePath(context.Context, *MovePathRequest) (*FileOpResult, error)
+	DeletePath(context.Context, *DeletePathRequest) (*FileOpResult, error)
+	ListTrash(context.Context, *Empty) (*TrashList, error)
+	RestoreTrash(context.Context, *RestoreTrashRequest) (*FileOpResult, error)
+	// 目录占用统计（du），返回最大子目录的分层明细
+	GetDirectorySize(context.Context, *DirectorySizeRequest) (*DirectorySizeResponse, error)
 	// 日志流
 	TailLog(*LogRequest, AgentService_TailLogServer) error
 	// 服务管理
@@ -388,11 +744,16 @@ type AgentServiceServer interface {
 	ServiceAction(context.Context, *ServiceActionRequest) (*ActionResponse, error)
 	// 进程管理
 	ListProcesses(context.Context, *ProcessFilter) (*ProcessList, error)
+	GetProcessTree(context.Context, *ProcessTreeRequest) (*ProcessTree, error)
 	KillProcess(context.Context, *KillProcessRequest) (*ActionResponse, error)
+	// 网络连接（监听端口及所属进程）
+	GetNetworkConnections(context.Context, *Empty) (*NetworkConnections, error)
 	// Docker Hub 搜索（通过服务端代理）
 	SearchDockerHub(context.Context, *DockerSearchRequest) (*DockerSearchResponse, error)
 	// HTTP 代理请求（通用）
 	ProxyHttpRequest(context.Context, *HttpProxyRequest) (*HttpProxyResponse, error)
+	// TLS 证书管理
+	DownloadCertificate(context.Context, *Empty) (*CertificateResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -412,9 +773,21 @@ func (UnimplementedAgentServiceServer) GetMetrics(*MetricsRequest, AgentService_
 func (UnimplementedAgentServiceServer) ExecuteCommand(context.Context, *CommandRequest) (*CommandResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ExecuteCommand not implemented")
 }
+func (UnimplementedAgentServiceServer) ExecuteStream(*CommandRequest, AgentService_ExecuteStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteStream not implemented")
+}
+func (UnimplementedAgentServiceServer) ExecuteScript(context.Context, *ScriptRequest) (*CommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteScript not implemented")
+}
+func (UnimplementedAgentServiceServer) ExecuteBatch(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteBatch not implemented")
+}
 func (UnimplementedAgentServiceServer) ExecuteShell(AgentService_ExecuteShellServer) error {
 	return status.Errorf(codes.Unimplemented, "method ExecuteShell not implemented")
 }
+func (UnimplementedAgentServiceServer) GetExecHistory(context.Context, *ExecHistoryRequest) (*ExecHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExecHistory not implemented")
+}
 func (UnimplementedAgentServiceServer) ReadFile(context.Context, *FileRequest) (*FileContent, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReadFile not implemented")
 }
@@ -433,6 +806,51 @@ func (UnimplementedAgentServiceServer) UploadFile(AgentService_UploadFileServer)
 func (UnimplementedAgentServiceServer) DownloadFile(*FileRequest, AgentService_DownloadFileServer) error {
 	return status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
 }
+func (UnimplementedAgentServiceServer) GetUploadOffset(context.Context, *FileRequest) (*UploadOffset, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadOffset not implemented")
+}
+func (UnimplementedAgentServiceServer) HashFile(context.Context, *HashFileRequest) (*FileHash, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HashFile not implemented")
+}
+func (UnimplementedAgentServiceServer) CompareFiles(context.Context, *CompareFilesRequest) (*FileComparison, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareFiles not implemented")
+}
+func (UnimplementedAgentServiceServer) SearchFiles(context.Context, *SearchFilesRequest) (*SearchFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchFiles not implemented")
+}
+func (UnimplementedAgentServiceServer) CreateArchive(*CreateArchiveRequest, AgentService_CreateArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateArchive not implemented")
+}
+func (UnimplementedAgentServiceServer) ExtractArchive(*ExtractArchiveRequest, AgentService_ExtractArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExtractArchive not implemented")
+}
+func (UnimplementedAgentServiceServer) Chmod(context.Context, *ChmodRequest) (*PermissionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chmod not implemented")
+}
+func (UnimplementedAgentServiceServer) Chown(context.Context, *ChownRequest) (*PermissionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chown not implemented")
+}
+func (UnimplementedAgentServiceServer) SetACL(context.Context, *SetACLRequest) (*PermissionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetACL not implemented")
+}
+func (UnimplementedAgentServiceServer) CopyPath(context.Context, *CopyPathRequest) (*FileOpResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CopyPath not implemented")
+}
+func (UnimplementedAgentServiceServer) MovePath(context.Context, *MovePathRequest) (*FileOpResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MovePath not implemented")
+}
+func (UnimplementedAgentServiceServer) DeletePath(context.Context, *DeletePathRequest) (*FileOpResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePath not implemented")
+}
+func (UnimplementedAgentServiceServer) ListTrash(context.Context, *Empty) (*TrashList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedAgentServiceServer) RestoreTrash(context.Context, *RestoreTrashRequest) (*FileOpResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreTrash not implemented")
+}
+func (UnimplementedAgentServiceServer) GetDirectorySize(context.Context, *DirectorySizeRequest) (*DirectorySizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDirectorySize not implemented")
+}
 func (UnimplementedAgentServiceServer) TailLog(*LogRequest, AgentService_TailLogServer) error {
 	return status.Errorf(codes.Unimplemented, "method TailLog not implemented")
 }
@@ -445,15 +863,24 @@ func (UnimplementedAgentServiceServer) ServiceAction(context.Context, *ServiceAc
 func (UnimplementedAgentServiceServer) ListProcesses(context.Context, *ProcessFilter) (*ProcessList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListProcesses not implemented")
 }
+func (UnimplementedAgentServiceServer) GetProcessTree(context.Context, *ProcessTreeRequest) (*ProcessTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProcessTree not implemented")
+}
 func (UnimplementedAgentServiceServer) KillProcess(context.Context, *KillProcessRequest) (*ActionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method KillProcess not implemented")
 }
+func (UnimplementedAgentServiceServer) GetNetworkConnections(context.Context, *Empty) (*NetworkConnections, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNetworkConnections not implemented")
+}
 func (UnimplementedAgentServiceServer) SearchDockerHub(context.Context, *DockerSearchRequest) (*DockerSearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchDockerHub not implemented")
 }
 func (UnimplementedAgentServiceServer) ProxyHttpRequest(context.Context, *HttpProxyRequest) (*HttpProxyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProxyHttpRequest not implemented")
 }
+func (UnimplementedAgentServiceServer) DownloadCertificate(context.Context, *Empty) (*CertificateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownloadCertificate not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 
 // UnsafeAgentServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -542,6 +969,63 @@ func _AgentService_ExecuteCommand_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_ExecuteStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CommandRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).ExecuteStream(m, &agentServiceExecuteStreamServer{stream})
+}
+
+type AgentService_ExecuteStreamServer interface {
+	Send(*CommandOutput) error
+	grpc.ServerStream
+}
+
+type agentServiceExecuteStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentServiceExecuteStreamServer) Send(m *CommandOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AgentService_ExecuteScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ExecuteScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ExecuteScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ExecuteScript(ctx, req.(*ScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ExecuteBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ExecuteBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ExecuteBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ExecuteBatch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_ExecuteShell_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(AgentServiceServer).ExecuteShell(&agentServiceExecuteShellServer{stream})
 }
@@ -568,6 +1052,24 @@ func (x *agentServiceExecuteShellServer) Recv() (*ShellInput, error) {
 	return m, nil
 }
 
+func _AgentService_GetExecHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetExecHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetExecHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetExecHistory(ctx, req.(*ExecHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_ReadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FileRequest)
 	if err := dec(in); err != nil {
@@ -687,6 +1189,282 @@ func (x *agentServiceDownloadFileServer) Send(m *FileChunk) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _AgentService_GetUploadOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetUploadOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetUploadOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetUploadOffset(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_HashFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).HashFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_HashFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).HashFile(ctx, req.(*HashFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CompareFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CompareFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CompareFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CompareFiles(ctx, req.(*CompareFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_SearchFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).SearchFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_SearchFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).SearchFiles(ctx, req.(*SearchFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CreateArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CreateArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).CreateArchive(m, &agentServiceCreateArchiveServer{stream})
+}
+
+type AgentService_CreateArchiveServer interface {
+	Send(*ArchiveProgress) error
+	grpc.ServerStream
+}
+
+type agentServiceCreateArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentServiceCreateArchiveServer) Send(m *ArchiveProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AgentService_ExtractArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExtractArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).ExtractArchive(m, &agentServiceExtractArchiveServer{stream})
+}
+
+type AgentService_ExtractArchiveServer interface {
+	Send(*ArchiveProgress) error
+	grpc.ServerStream
+}
+
+type agentServiceExtractArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentServiceExtractArchiveServer) Send(m *ArchiveProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AgentService_Chmod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChmodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Chmod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Chmod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Chmod(ctx, req.(*ChmodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_Chown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Chown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Chown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Chown(ctx, req.(*ChownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_SetACL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetACLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).SetACL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_SetACL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).SetACL(ctx, req.(*SetACLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CopyPath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyPathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CopyPath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CopyPath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CopyPath(ctx, req.(*CopyPathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_MovePath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MovePathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).MovePath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_MovePath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).MovePath(ctx, req.(*MovePathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_DeletePath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DeletePath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_DeletePath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).DeletePath(ctx, req.(*DeletePathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListTrash(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_RestoreTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).RestoreTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_RestoreTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).RestoreTrash(ctx, req.(*RestoreTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetDirectorySize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DirectorySizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetDirectorySize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetDirectorySize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetDirectorySize(ctx, req.(*DirectorySizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_TailLog_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(LogRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -762,6 +1540,24 @@ func _AgentService_ListProcesses_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_GetProcessTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetProcessTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetProcessTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetProcessTree(ctx, req.(*ProcessTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_KillProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(KillProcessRequest)
 	if err := dec(in); err != nil {
@@ -780,6 +1576,24 @@ func _AgentService_KillProcess_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_GetNetworkConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetNetworkConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetNetworkConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetNetworkConnections(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_SearchDockerHub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DockerSearchRequest)
 	if err := dec(in); err != nil {
@@ -804,14 +1618,32 @@ func _AgentService_ProxyHttpRequest_Handler(srv interface{}, ctx context.Context
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AgentServiceServer).ProxyHttpRequest(ctx, in)
+		return srv.(AgentServiceServer).ProxyHttpRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ProxyHttpRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ProxyHttpRequest(ctx, req.(*HttpProxyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_DownloadCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DownloadCertificate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AgentService_ProxyHttpRequest_FullMethodName,
+		FullMethod: AgentService_DownloadCertificate_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AgentServiceServer).ProxyHttpRequest(ctx, req.(*HttpProxyRequest))
+		return srv.(AgentServiceServer).DownloadCertificate(ctx, req.(*Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -835,6 +1667,18 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ExecuteCommand",
 			Handler:    _AgentService_ExecuteCommand_Handler,
 		},
+		{
+			MethodName: "ExecuteScript",
+			Handler:    _AgentService_ExecuteScript_Handler,
+		},
+		{
+			MethodName: "ExecuteBatch",
+			Handler:    _AgentService_ExecuteBatch_Handler,
+		},
+		{
+			MethodName: "GetExecHistory",
+			Handler:    _AgentService_GetExecHistory_Handler,
+		},
 		{
 			MethodName: "ReadFile",
 			Handler:    _AgentService_ReadFile_Handler,
@@ -851,6 +1695,58 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteFile",
 			Handler:    _AgentService_DeleteFile_Handler,
 		},
+		{
+			MethodName: "GetUploadOffset",
+			Handler:    _AgentService_GetUploadOffset_Handler,
+		},
+		{
+			MethodName: "HashFile",
+			Handler:    _AgentService_HashFile_Handler,
+		},
+		{
+			MethodName: "CompareFiles",
+			Handler:    _AgentService_CompareFiles_Handler,
+		},
+		{
+			MethodName: "SearchFiles",
+			Handler:    _AgentService_SearchFiles_Handler,
+		},
+		{
+			MethodName: "Chmod",
+			Handler:    _AgentService_Chmod_Handler,
+		},
+		{
+			MethodName: "Chown",
+			Handler:    _AgentService_Chown_Handler,
+		},
+		{
+			MethodName: "SetACL",
+			Handler:    _AgentService_SetACL_Handler,
+		},
+		{
+			MethodName: "CopyPath",
+			Handler:    _AgentService_CopyPath_Handler,
+		},
+		{
+			MethodName: "MovePath",
+			Handler:    _AgentService_MovePath_Handler,
+		},
+		{
+			MethodName: "DeletePath",
+			Handler:    _AgentService_DeletePath_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _AgentService_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreTrash",
+			Handler:    _AgentService_RestoreTrash_Handler,
+		},
+		{
+			MethodName: "GetDirectorySize",
+			Handler:    _AgentService_GetDirectorySize_Handler,
+		},
 		{
 			MethodName: "ListServices",
 			Handler:    _AgentService_ListServices_Handler,
@@ -863,10 +1759,18 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListProcesses",
 			Handler:    _AgentService_ListProcesses_Handler,
 		},
+		{
+			MethodName: "GetProcessTree",
+			Handler:    _AgentService_GetProcessTree_Handler,
+		},
 		{
 			MethodName: "KillProcess",
 			Handler:    _AgentService_KillProcess_Handler,
 		},
+		{
+			MethodName: "GetNetworkConnections",
+			Handler:    _AgentService_GetNetworkConnections_Handler,
+		},
 		{
 			MethodName: "SearchDockerHub",
 			Handler:    _AgentService_SearchDockerHub_Handler,
@@ -875,6 +1779,10 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProxyHttpRequest",
 			Handler:    _AgentService_ProxyHttpRequest_Handler,
 		},
+		{
+			MethodName: "DownloadCertificate",
+			Handler:    _AgentService_DownloadCertificate_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -882,6 +1790,11 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _AgentService_GetMetrics_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExecuteStream",
+			Handler:       _AgentService_ExecuteStream_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "ExecuteShell",
 			Handler:       _AgentService_ExecuteShell_Handler,
@@ -898,6 +1811,16 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _AgentService_DownloadFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "CreateArchive",
+			Handler:       _AgentService_CreateArchive_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExtractArchive",
+			Handler:       _AgentService_ExtractArchive_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "TailLog",
 			Handler:       _AgentService_TailLog_Handler,
@@ -908,15 +1831,23 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	PluginService_ListPlugins_FullMethodName         = "/runixo.PluginService/ListPlugins"
-	PluginService_InstallPlugin_FullMethodName       = "/runixo.PluginService/InstallPlugin"
-	PluginService_UninstallPlugin_FullMethodName     = "/runixo.PluginService/UninstallPlugin"
-	PluginService_EnablePlugin_FullMethodName        = "/runixo.PluginService/EnablePlugin"
-	PluginService_DisablePlugin_FullMethodName       = "/runixo.PluginService/DisablePlugin"
-	PluginService_GetPluginConfig_FullMethodName     = "/runixo.PluginService/GetPluginConfig"
-	PluginService_SetPluginConfig_FullMethodName     = "/runixo.PluginService/SetPluginConfig"
-	PluginService_GetPluginStatus_FullMethodName     = "/runixo.PluginService/GetPluginStatus"
-	PluginService_GetAvailablePlugins_FullMethodName = "/runixo.PluginService/GetAvailablePlugins"
+	PluginService_ListPlugins_FullMethodName          = "/runixo.PluginService/ListPlugins"
+	PluginService_InstallPlugin_FullMethodName        = "/runixo.PluginService/InstallPlugin"
+	PluginService_UploadPlugin_FullMethodName         = "/runixo.PluginService/UploadPlugin"
+	PluginService_UninstallPlugin_FullMethodName      = "/runixo.PluginService/UninstallPlugin"
+	PluginService_EnablePlugin_FullMethodName         = "/runixo.PluginService/EnablePlugin"
+	PluginService_VerifyPlugin_FullMethodName         = "/runixo.PluginService/VerifyPlugin"
+	PluginService_DisablePlugin_FullMethodName        = "/runixo.PluginService/DisablePlugin"
+	PluginService_GetPluginConfig_FullMethodName      = "/runixo.PluginService/GetPluginConfig"
+	PluginService_SetPluginConfig_FullMethodName      = "/runixo.PluginService/SetPluginConfig"
+	PluginService_GetPluginStatus_FullMethodName      = "/runixo.PluginService/GetPluginStatus"
+	PluginService_GetPluginDiagnostics_FullMethodName = "/runixo.PluginService/GetPluginDiagnostics"
+	PluginService_GetAvailablePlugins_FullMethodName  = "/runixo.PluginService/GetAvailablePlugins"
+	PluginService_SearchPlugins_FullMethodName        = "/runixo.PluginService/SearchPlugins"
+	PluginService_GetPluginDetails_FullMethodName     = "/runixo.PluginService/GetPluginDetails"
+	PluginService_ListScheduledTasks_FullMethodName   = "/runixo.PluginService/ListScheduledTasks"
+	PluginService_ExportPluginConfigs_FullMethodName  = "/runixo.PluginService/ExportPluginConfigs"
+	PluginService_ImportPluginConfigs_FullMethodName  = "/runixo.PluginService/ImportPluginConfigs"
 )
 
 // PluginServiceClient is the client API for PluginService service.
@@ -927,10 +1858,14 @@ type PluginServiceClient interface {
 	ListPlugins(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PluginList, error)
 	// 安装插件
 	InstallPlugin(ctx context.Context, in *InstallPluginRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	// 分块上传并安装插件包，用于无法访问插件仓库的环境
+	UploadPlugin(ctx context.Context, opts ...grpc.CallOption) (PluginService_UploadPluginClient, error)
 	// 卸载插件
 	UninstallPlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*ActionResponse, error)
 	// 启用插件
 	EnablePlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	// 试运行插件而不启用：校验配置、检查依赖，启动后做一次健康检查并停止，插件状态不变
+	VerifyPlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginVerifyResult, error)
 	// 禁用插件
 	DisablePlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*ActionResponse, error)
 	// 获取插件配置
@@ -939,8 +1874,20 @@ type PluginServiceClient interface {
 	SetPluginConfig(ctx context.Context, in *SetPluginConfigRequest, opts ...grpc.CallOption) (*ActionResponse, error)
 	// 获取插件状态
 	GetPluginStatus(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginStatus, error)
+	// 获取插件最近一次进入错误状态时采集的诊断信息
+	GetPluginDiagnostics(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginDiagnostics, error)
 	// 获取可用插件列表（从远程仓库）
 	GetAvailablePlugins(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AvailablePluginList, error)
+	// 搜索插件市场（关键词、分类、标签，分页）
+	SearchPlugins(ctx context.Context, in *SearchPluginsRequest, opts ...grpc.CallOption) (*SearchPluginsResponse, error)
+	// 获取插件市场中插件的详情（说明、截图）及本机安装情况
+	GetPluginDetails(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginDetails, error)
+	// 列出定时任务调度器中的任务（插件和核心模块注册的）
+	ListScheduledTasks(ctx context.Context, in *ListScheduledTasksRequest, opts ...grpc.CallOption) (*ScheduledTaskList, error)
+	// 导出所有已安装插件的启用状态和配置，用于迁移 Agent 或批量配置主机
+	ExportPluginConfigs(ctx context.Context, in *ExportPluginConfigsRequest, opts ...grpc.CallOption) (*PluginConfigBundle, error)
+	// 导入 ExportPluginConfigs 生成的导出包
+	ImportPluginConfigs(ctx context.Context, in *ImportPluginConfigsRequest, opts ...grpc.CallOption) (*ImportPluginConfigsResponse, error)
 }
 
 type pluginServiceClient struct {
@@ -969,6 +1916,40 @@ func (c *pluginServiceClient) InstallPlugin(ctx context.Context, in *InstallPlug
 	return out, nil
 }
 
+func (c *pluginServiceClient) UploadPlugin(ctx context.Context, opts ...grpc.CallOption) (PluginService_UploadPluginClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PluginService_ServiceDesc.Streams[0], PluginService_UploadPlugin_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pluginServiceUploadPluginClient{stream}
+	return x, nil
+}
+
+type PluginService_UploadPluginClient interface {
+	Send(*PluginUpload) error
+	CloseAndRecv() (*ActionResponse, error)
+	grpc.ClientStream
+}
+
+type pluginServiceUploadPluginClient struct {
+	grpc.ClientStream
+}
+
+func (x *pluginServiceUploadPluginClient) Send(m *PluginUpload) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *pluginServiceUploadPluginClient) CloseAndRecv() (*ActionResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ActionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *pluginServiceClient) UninstallPlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
 	out := new(ActionResponse)
 	err := c.cc.Invoke(ctx, PluginService_UninstallPlugin_FullMethodName, in, out, opts...)
@@ -987,6 +1968,15 @@ func (c *pluginServiceClient) EnablePlugin(ctx context.Context, in *PluginReques
 	return out, nil
 }
 
+func (c *pluginServiceClient) VerifyPlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginVerifyResult, error) {
+	out := new(PluginVerifyResult)
+	err := c.cc.Invoke(ctx, PluginService_VerifyPlugin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *pluginServiceClient) DisablePlugin(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
 	out := new(ActionResponse)
 	err := c.cc.Invoke(ctx, PluginService_DisablePlugin_FullMethodName, in, out, opts...)
@@ -1023,6 +2013,15 @@ func (c *pluginServiceClient) GetPluginStatus(ctx context.Context, in *PluginReq
 	return out, nil
 }
 
+func (c *pluginServiceClient) GetPluginDiagnostics(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginDiagnostics, error) {
+	out := new(PluginDiagnostics)
+	err := c.cc.Invoke(ctx, PluginService_GetPluginDiagnostics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *pluginServiceClient) GetAvailablePlugins(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AvailablePluginList, error) {
 	out := new(AvailablePluginList)
 	err := c.cc.Invoke(ctx, PluginService_GetAvailablePlugins_FullMethodName, in, out, opts...)
@@ -1032,6 +2031,51 @@ func (c *pluginServiceClient) GetAvailablePlugins(ctx context.Context, in *Empty
 	return out, nil
 }
 
+func (c *pluginServiceClient) SearchPlugins(ctx context.Context, in *SearchPluginsRequest, opts ...grpc.CallOption) (*SearchPluginsResponse, error) {
+	out := new(SearchPluginsResponse)
+	err := c.cc.Invoke(ctx, PluginService_SearchPlugins_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) GetPluginDetails(ctx context.Context, in *PluginRequest, opts ...grpc.CallOption) (*PluginDetails, error) {
+	out := new(PluginDetails)
+	err := c.cc.Invoke(ctx, PluginService_GetPluginDetails_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) ListScheduledTasks(ctx context.Context, in *ListScheduledTasksRequest, opts ...grpc.CallOption) (*ScheduledTaskList, error) {
+	out := new(ScheduledTaskList)
+	err := c.cc.Invoke(ctx, PluginService_ListScheduledTasks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) ExportPluginConfigs(ctx context.Context, in *ExportPluginConfigsRequest, opts ...grpc.CallOption) (*PluginConfigBundle, error) {
+	out := new(PluginConfigBundle)
+	err := c.cc.Invoke(ctx, PluginService_ExportPluginConfigs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) ImportPluginConfigs(ctx context.Context, in *ImportPluginConfigsRequest, opts ...grpc.CallOption) (*ImportPluginConfigsResponse, error) {
+	out := new(ImportPluginConfigsResponse)
+	err := c.cc.Invoke(ctx, PluginService_ImportPluginConfigs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PluginServiceServer is the server API for PluginService service.
 // All implementations must embed UnimplementedPluginServiceServer
 // for forward compatibility
@@ -1040,10 +2084,14 @@ type PluginServiceServer interface {
 	ListPlugins(context.Context, *Empty) (*PluginList, error)
 	// 安装插件
 	InstallPlugin(context.Context, *InstallPluginRequest) (*ActionResponse, error)
+	// 分块上传并安装插件包，用于无法访问插件仓库的环境
+	UploadPlugin(PluginService_UploadPluginServer) error
 	// 卸载插件
 	UninstallPlugin(context.Context, *PluginRequest) (*ActionResponse, error)
 	// 启用插件
 	EnablePlugin(context.Context, *PluginRequest) (*ActionResponse, error)
+	// 试运行插件而不启用：校验配置、检查依赖，启动后做一次健康检查并停止，插件状态不变
+	VerifyPlugin(context.Context, *PluginRequest) (*PluginVerifyResult, error)
 	// 禁用插件
 	DisablePlugin(context.Context, *PluginRequest) (*ActionResponse, error)
 	// 获取插件配置
@@ -1052,8 +2100,20 @@ type PluginServiceServer interface {
 	SetPluginConfig(context.Context, *SetPluginConfigRequest) (*ActionResponse, error)
 	// 获取插件状态
 	GetPluginStatus(context.Context, *PluginRequest) (*PluginStatus, error)
+	// 获取插件最近一次进入错误状态时采集的诊断信息
+	GetPluginDiagnostics(context.Context, *PluginRequest) (*PluginDiagnostics, error)
 	// 获取可用插件列表（从远程仓库）
 	GetAvailablePlugins(context.Context, *Empty) (*AvailablePluginList, error)
+	// 搜索插件市场（关键词、分类、标签，分页）
+	SearchPlugins(context.Context, *SearchPluginsRequest) (*SearchPluginsResponse, error)
+	// 获取插件市场中插件的详情（说明、截图）及本机安装情况
+	GetPluginDetails(context.Context, *PluginRequest) (*PluginDetails, error)
+	// 列出定时任务调度器中的任务（插件和核心模块注册的）
+	ListScheduledTasks(context.Context, *ListScheduledTasksRequest) (*ScheduledTaskList, error)
+	// 导出所有已安装插件的启用状态和配置，用于迁移 Agent 或批量配置主机
+	ExportPluginConfigs(context.Context, *ExportPluginConfigsRequest) (*PluginConfigBundle, error)
+	// 导入 ExportPluginConfigs 生成的导出包
+	ImportPluginConfigs(context.Context, *ImportPluginConfigsRequest) (*ImportPluginConfigsResponse, error)
 	mustEmbedUnimplementedPluginServiceServer()
 }
 
@@ -1067,12 +2127,18 @@ func (UnimplementedPluginServiceServer) ListPlugins(context.Context, *Empty) (*P
 func (UnimplementedPluginServiceServer) InstallPlugin(context.Context, *InstallPluginRequest) (*ActionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InstallPlugin not implemented")
 }
+func (UnimplementedPluginServiceServer) UploadPlugin(PluginService_UploadPluginServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadPlugin not implemented")
+}
 func (UnimplementedPluginServiceServer) UninstallPlugin(context.Context, *PluginRequest) (*ActionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UninstallPlugin not implemented")
 }
 func (UnimplementedPluginServiceServer) EnablePlugin(context.Context, *PluginRequest) (*ActionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method EnablePlugin not implemented")
 }
+func (UnimplementedPluginServiceServer) VerifyPlugin(context.Context, *PluginRequest) (*PluginVerifyResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyPlugin not implemented")
+}
 func (UnimplementedPluginServiceServer) DisablePlugin(context.Context, *PluginRequest) (*ActionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DisablePlugin not implemented")
 }
@@ -1085,9 +2151,27 @@ func (UnimplementedPluginServiceServer) SetPluginConfig(context.Context, *SetPlu
 func (UnimplementedPluginServiceServer) GetPluginStatus(context.Context, *PluginRequest) (*PluginStatus, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPluginStatus not implemented")
 }
+func (UnimplementedPluginServiceServer) GetPluginDiagnostics(context.Context, *PluginRequest) (*PluginDiagnostics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPluginDiagnostics not implemented")
+}
 func (UnimplementedPluginServiceServer) GetAvailablePlugins(context.Context, *Empty) (*AvailablePluginList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAvailablePlugins not implemented")
 }
+func (UnimplementedPluginServiceServer) SearchPlugins(context.Context, *SearchPluginsRequest) (*SearchPluginsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchPlugins not implemented")
+}
+func (UnimplementedPluginServiceServer) GetPluginDetails(context.Context, *PluginRequest) (*PluginDetails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPluginDetails not implemented")
+}
+func (UnimplementedPluginServiceServer) ListScheduledTasks(context.Context, *ListScheduledTasksRequest) (*ScheduledTaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduledTasks not implemented")
+}
+func (UnimplementedPluginServiceServer) ExportPluginConfigs(context.Context, *ExportPluginConfigsRequest) (*PluginConfigBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportPluginConfigs not implemented")
+}
+func (UnimplementedPluginServiceServer) ImportPluginConfigs(context.Context, *ImportPluginConfigsRequest) (*ImportPluginConfigsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportPluginConfigs not implemented")
+}
 func (UnimplementedPluginServiceServer) mustEmbedUnimplementedPluginServiceServer() {}
 
 // UnsafePluginServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -1137,6 +2221,32 @@ func _PluginService_InstallPlugin_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PluginService_UploadPlugin_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PluginServiceServer).UploadPlugin(&pluginServiceUploadPluginServer{stream})
+}
+
+type PluginService_UploadPluginServer interface {
+	SendAndClose(*ActionResponse) error
+	Recv() (*PluginUpload, error)
+	grpc.ServerStream
+}
+
+type pluginServiceUploadPluginServer struct {
+	grpc.ServerStream
+}
+
+func (x *pluginServiceUploadPluginServer) SendAndClose(m *ActionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *pluginServiceUploadPluginServer) Recv() (*PluginUpload, error) {
+	m := new(PluginUpload)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _PluginService_UninstallPlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PluginRequest)
 	if err := dec(in); err != nil {
@@ -1173,6 +2283,24 @@ func _PluginService_EnablePlugin_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PluginService_VerifyPlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).VerifyPlugin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_VerifyPlugin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).VerifyPlugin(ctx, req.(*PluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PluginService_DisablePlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PluginRequest)
 	if err := dec(in); err != nil {
@@ -1245,6 +2373,24 @@ func _PluginService_GetPluginStatus_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PluginService_GetPluginDiagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).GetPluginDiagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_GetPluginDiagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).GetPluginDiagnostics(ctx, req.(*PluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PluginService_GetAvailablePlugins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -1263,6 +2409,96 @@ func _PluginService_GetAvailablePlugins_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PluginService_SearchPlugins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchPluginsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).SearchPlugins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_SearchPlugins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).SearchPlugins(ctx, req.(*SearchPluginsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_GetPluginDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).GetPluginDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_GetPluginDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).GetPluginDetails(ctx, req.(*PluginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_ListScheduledTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduledTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).ListScheduledTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_ListScheduledTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).ListScheduledTasks(ctx, req.(*ListScheduledTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_ExportPluginConfigs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportPluginConfigsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).ExportPluginConfigs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_ExportPluginConfigs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).ExportPluginConfigs(ctx, req.(*ExportPluginConfigsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_ImportPluginConfigs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportPluginConfigsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).ImportPluginConfigs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_ImportPluginConfigs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).ImportPluginConfigs(ctx, req.(*ImportPluginConfigsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PluginService_ServiceDesc is the grpc.ServiceDesc for PluginService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1286,6 +2522,10 @@ var PluginService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "EnablePlugin",
 			Handler:    _PluginService_EnablePlugin_Handler,
 		},
+		{
+			MethodName: "VerifyPlugin",
+			Handler:    _PluginService_VerifyPlugin_Handler,
+		},
 		{
 			MethodName: "DisablePlugin",
 			Handler:    _PluginService_DisablePlugin_Handler,
@@ -1302,12 +2542,42 @@ var PluginService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPluginStatus",
 			Handler:    _PluginService_GetPluginStatus_Handler,
 		},
+		{
+			MethodName: "GetPluginDiagnostics",
+			Handler:    _PluginService_GetPluginDiagnostics_Handler,
+		},
 		{
 			MethodName: "GetAvailablePlugins",
 			Handler:    _PluginService_GetAvailablePlugins_Handler,
 		},
+		{
+			MethodName: "SearchPlugins",
+			Handler:    _PluginService_SearchPlugins_Handler,
+		},
+		{
+			MethodName: "GetPluginDetails",
+			Handler:    _PluginService_GetPluginDetails_Handler,
+		},
+		{
+			MethodName: "ListScheduledTasks",
+			Handler:    _PluginService_ListScheduledTasks_Handler,
+		},
+		{
+			MethodName: "ExportPluginConfigs",
+			Handler:    _PluginService_ExportPluginConfigs_Handler,
+		},
+		{
+			MethodName: "ImportPluginConfigs",
+			Handler:    _PluginService_ImportPluginConfigs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadPlugin",
+			Handler:       _PluginService_UploadPlugin_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "agent.proto",
 }
 
@@ -0,0 +1,1016 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: plugin.proto
+
+package pluginpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Plugin_Start_FullMethodName          = "/runixo.plugin.Plugin/Start"
+	Plugin_Stop_FullMethodName           = "/runixo.plugin.Plugin/Stop"
+	Plugin_GetStatus_FullMethodName      = "/runixo.plugin.Plugin/GetStatus"
+	Plugin_HealthCheck_FullMethodName    = "/runixo.plugin.Plugin/HealthCheck"
+	Plugin_OnEvent_FullMethodName        = "/runixo.plugin.Plugin/OnEvent"
+	Plugin_OnSchedule_FullMethodName     = "/runixo.plugin.Plugin/OnSchedule"
+	Plugin_OnMetrics_FullMethodName      = "/runixo.plugin.Plugin/OnMetrics"
+	Plugin_OnConfigChange_FullMethodName = "/runixo.plugin.Plugin/OnConfigChange"
+	Plugin_OnHTTP_FullMethodName         = "/runixo.plugin.Plugin/OnHTTP"
+)
+
+// PluginClient is the client API for Plugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PluginClient interface {
+	// 启动插件，config 为插件配置的 JSON 编码
+	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*Empty, error)
+	// 停止插件，返回后 Agent 结束插件进程
+	Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// 获取插件状态
+	GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+	// 健康检查，返回错误表示插件不健康，Agent 按重启策略重启插件
+	HealthCheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// 投递插件订阅的事件（插件清单 subscribe 中声明的主题）
+	OnEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Empty, error)
+	// 触发插件通过 Host.Schedule 注册的定时任务，返回前不会再次触发同一任务
+	OnSchedule(ctx context.Context, in *ScheduleTick, opts ...grpc.CallOption) (*Empty, error)
+	// 投递 Agent 的指标采样，插件声明了 system.read 权限时每次采样后调用
+	OnMetrics(ctx context.Context, in *MetricsSample, opts ...grpc.CallOption) (*Empty, error)
+	// 通知插件配置已更新，返回错误（含 UNIMPLEMENTED）时 Agent 重启插件以应用新配置
+	OnConfigChange(ctx context.Context, in *ConfigChange, opts ...grpc.CallOption) (*Empty, error)
+	// 转发 Agent REST API 中 /api/plugins/<插件 ID>/http/ 下的请求，插件未实现时返回 UNIMPLEMENTED
+	OnHTTP(ctx context.Context, in *HTTPRequest, opts ...grpc.CallOption) (*HTTPResponse, error)
+}
+
+type pluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPluginClient(cc grpc.ClientConnInterface) PluginClient {
+	return &pluginClient{cc}
+}
+
+func (c *pluginClient) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_Start_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_Stop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Plugin_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) HealthCheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_HealthCheck_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) OnEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_OnEvent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) OnSchedule(ctx context.Context, in *ScheduleTick, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_OnSchedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) OnMetrics(ctx context.Context, in *MetricsSample, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_OnMetrics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) OnConfigChange(ctx context.Context, in *ConfigChange, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Plugin_OnConfigChange_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) OnHTTP(ctx context.Context, in *HTTPRequest, opts ...grpc.CallOption) (*HTTPResponse, error) {
+	out := new(HTTPResponse)
+	err := c.cc.Invoke(ctx, Plugin_OnHTTP_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PluginServer is the server API for Plugin service.
+// All implementations must embed UnimplementedPluginServer
+// for forward compatibility
+type PluginServer interface {
+	// 启动插件，config 为插件配置的 JSON 编码
+	Start(context.Context, *StartRequest) (*Empty, error)
+	// 停止插件，返回后 Agent 结束插件进程
+	Stop(context.Context, *Empty) (*Empty, error)
+	// 获取插件状态
+	GetStatus(context.Context, *Empty) (*StatusResponse, error)
+	// 健康检查，返回错误表示插件不健康，Agent 按重启策略重启插件
+	HealthCheck(context.Context, *Empty) (*Empty, error)
+	// 投递插件订阅的事件（插件清单 subscribe 中声明的主题）
+	OnEvent(context.Context, *Event) (*Empty, error)
+	// 触发插件通过 Host.Schedule 注册的定时任务，返回前不会再次触发同一任务
+	OnSchedule(context.Context, *ScheduleTick) (*Empty, error)
+	// 投递 Agent 的指标采样，插件声明了 system.read 权限时每次采样后调用
+	OnMetrics(context.Context, *MetricsSample) (*Empty, error)
+	// 通知插件配置已更新，返回错误（含 UNIMPLEMENTED）时 Agent 重启插件以应用新配置
+	OnConfigChange(context.Context, *ConfigChange) (*Empty, error)
+	// 转发 Agent REST API 中 /api/plugins/<插件 ID>/http/ 下的请求，插件未实现时返回 UNIMPLEMENTED
+	OnHTTP(context.Context, *HTTPRequest) (*HTTPResponse, error)
+	mustEmbedUnimplementedPluginServer()
+}
+
+// UnimplementedPluginServer must be embedded to have forward compatible implementations.
+type UnimplementedPluginServer struct {
+}
+
+func (UnimplementedPluginServer) Start(context.Context, *StartRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedPluginServer) Stop(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedPluginServer) GetStatus(context.Context, *Empty) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedPluginServer) HealthCheck(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
+}
+func (UnimplementedPluginServer) OnEvent(context.Context, *Event) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnEvent not implemented")
+}
+func (UnimplementedPluginServer) OnSchedule(context.Context, *ScheduleTick) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnSchedule not implemented")
+}
+func (UnimplementedPluginServer) OnMetrics(context.Context, *MetricsSample) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnMetrics not implemented")
+}
+func (UnimplementedPluginServer) OnConfigChange(context.Context, *ConfigChange) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnConfigChange not implemented")
+}
+func (UnimplementedPluginServer) OnHTTP(context.Context, *HTTPRequest) (*HTTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnHTTP not implemented")
+}
+func (UnimplementedPluginServer) mustEmbedUnimplementedPluginServer() {}
+
+// UnsafePluginServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PluginServer will
+// result in compilation errors.
+type UnsafePluginServer interface {
+	mustEmbedUnimplementedPluginServer()
+}
+
+func RegisterPluginServer(s grpc.ServiceRegistrar, srv PluginServer) {
+	s.RegisterService(&Plugin_ServiceDesc, srv)
+}
+
+func _Plugin_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Start(ctx, req.(*StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Stop(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).GetStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).HealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_HealthCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).HealthCheck(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_OnEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Event)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).OnEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_OnEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).OnEvent(ctx, req.(*Event))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_OnSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleTick)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).OnSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_OnSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).OnSchedule(ctx, req.(*ScheduleTick))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_OnMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricsSample)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).OnMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_OnMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).OnMetrics(ctx, req.(*MetricsSample))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_OnConfigChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).OnConfigChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_OnConfigChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).OnConfigChange(ctx, req.(*ConfigChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_OnHTTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HTTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).OnHTTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_OnHTTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).OnHTTP(ctx, req.(*HTTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Plugin_ServiceDesc is the grpc.ServiceDesc for Plugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Plugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "runixo.plugin.Plugin",
+	HandlerType: (*PluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Start",
+			Handler:    _Plugin_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _Plugin_Stop_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Plugin_GetStatus_Handler,
+		},
+		{
+			MethodName: "HealthCheck",
+			Handler:    _Plugin_HealthCheck_Handler,
+		},
+		{
+			MethodName: "OnEvent",
+			Handler:    _Plugin_OnEvent_Handler,
+		},
+		{
+			MethodName: "OnSchedule",
+			Handler:    _Plugin_OnSchedule_Handler,
+		},
+		{
+			MethodName: "OnMetrics",
+			Handler:    _Plugin_OnMetrics_Handler,
+		},
+		{
+			MethodName: "OnConfigChange",
+			Handler:    _Plugin_OnConfigChange_Handler,
+		},
+		{
+			MethodName: "OnHTTP",
+			Handler:    _Plugin_OnHTTP_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "plugin.proto",
+}
+
+const (
+	Host_Publish_FullMethodName        = "/runixo.plugin.Host/Publish"
+	Host_ReadFile_FullMethodName       = "/runixo.plugin.Host/ReadFile"
+	Host_WriteFile_FullMethodName      = "/runixo.plugin.Host/WriteFile"
+	Host_Exec_FullMethodName           = "/runixo.plugin.Host/Exec"
+	Host_Fetch_FullMethodName          = "/runixo.plugin.Host/Fetch"
+	Host_GetSystemInfo_FullMethodName  = "/runixo.plugin.Host/GetSystemInfo"
+	Host_StorageGet_FullMethodName     = "/runixo.plugin.Host/StorageGet"
+	Host_StorageSet_FullMethodName     = "/runixo.plugin.Host/StorageSet"
+	Host_StorageDelete_FullMethodName  = "/runixo.plugin.Host/StorageDelete"
+	Host_StorageList_FullMethodName    = "/runixo.plugin.Host/StorageList"
+	Host_RegisterMetric_FullMethodName = "/runixo.plugin.Host/RegisterMetric"
+	Host_RecordMetrics_FullMethodName  = "/runixo.plugin.Host/RecordMetrics"
+	Host_Schedule_FullMethodName       = "/runixo.plugin.Host/Schedule"
+	Host_Unschedule_FullMethodName     = "/runixo.plugin.Host/Unschedule"
+)
+
+// HostClient is the client API for Host service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HostClient interface {
+	// 向 Agent 事件总线发布事件，主题被加上 "plugin.<插件 ID>." 前缀（events.publish）
+	Publish(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Empty, error)
+	// 读取文件（file.read）
+	ReadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	// 原子写入文件（file.write）
+	WriteFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*Empty, error)
+	// 执行系统命令（exec）
+	Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (*ExecResponse, error)
+	// 发起 HTTP 请求（network）
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
+	// 读取系统信息（system.read）
+	GetSystemInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SystemInfo, error)
+	// 插件私有的 KV 存储，无需声明权限，总大小受 Agent 配置的配额限制
+	StorageGet(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*StorageValue, error)
+	StorageSet(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*Empty, error)
+	StorageDelete(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*Empty, error)
+	StorageList(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*StorageKeys, error)
+	// 注册插件指标，指标名被加上 "plugin_<插件 ID>_" 前缀后进入 GetMetrics、指标历史和 Prometheus 接口，无需声明权限
+	RegisterMetric(ctx context.Context, in *MetricDesc, opts ...grpc.CallOption) (*Empty, error)
+	// 上报已注册指标的样本：gauge 设置为 value，counter 累加 value
+	RecordMetrics(ctx context.Context, in *MetricBatch, opts ...grpc.CallOption) (*Empty, error)
+	// 注册定时任务，同名任务已存在时替换；任务到期时 Agent 调用插件的 OnSchedule，插件停止时任务被移除
+	Schedule(ctx context.Context, in *ScheduleSpec, opts ...grpc.CallOption) (*Empty, error)
+	// 移除定时任务，只使用 name
+	Unschedule(ctx context.Context, in *ScheduleSpec, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type hostClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHostClient(cc grpc.ClientConnInterface) HostClient {
+	return &hostClient{cc}
+}
+
+func (c *hostClient) Publish(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_Publish_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) ReadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, Host_ReadFile_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) WriteFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_WriteFile_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (*ExecResponse, error) {
+	out := new(ExecResponse)
+	err := c.cc.Invoke(ctx, Host_Exec_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
+	out := new(FetchResponse)
+	err := c.cc.Invoke(ctx, Host_Fetch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) GetSystemInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SystemInfo, error) {
+	out := new(SystemInfo)
+	err := c.cc.Invoke(ctx, Host_GetSystemInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) StorageGet(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*StorageValue, error) {
+	out := new(StorageValue)
+	err := c.cc.Invoke(ctx, Host_StorageGet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) StorageSet(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_StorageSet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) StorageDelete(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_StorageDelete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) StorageList(ctx context.Context, in *StorageRequest, opts ...grpc.CallOption) (*StorageKeys, error) {
+	out := new(StorageKeys)
+	err := c.cc.Invoke(ctx, Host_StorageList_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) RegisterMetric(ctx context.Context, in *MetricDesc, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_RegisterMetric_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) RecordMetrics(ctx context.Context, in *MetricBatch, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_RecordMetrics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) Schedule(ctx context.Context, in *ScheduleSpec, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_Schedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostClient) Unschedule(ctx context.Context, in *ScheduleSpec, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Host_Unschedule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HostServer is the server API for Host service.
+// All implementations must embed UnimplementedHostServer
+// for forward compatibility
+type HostServer interface {
+	// 向 Agent 事件总线发布事件，主题被加上 "plugin.<插件 ID>." 前缀（events.publish）
+	Publish(context.Context, *Event) (*Empty, error)
+	// 读取文件（file.read）
+	ReadFile(context.Context, *FileRequest) (*FileResponse, error)
+	// 原子写入文件（file.write）
+	WriteFile(context.Context, *FileRequest) (*Empty, error)
+	// 执行系统命令（exec）
+	Exec(context.Context, *ExecRequest) (*ExecResponse, error)
+	// 发起 HTTP 请求（network）
+	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
+	// 读取系统信息（system.read）
+	GetSystemInfo(context.Context, *Empty) (*SystemInfo, error)
+	// 插件私有的 KV 存储，无需声明权限，总大小受 Agent 配置的配额限制
+	StorageGet(context.Context, *StorageRequest) (*StorageValue, error)
+	StorageSet(context.Context, *StorageRequest) (*Empty, error)
+	StorageDelete(context.Context, *StorageRequest) (*Empty, error)
+	StorageList(context.Context, *StorageRequest) (*StorageKeys, error)
+	// 注册插件指标，指标名被加上 "plugin_<插件 ID>_" 前缀后进入 GetMetrics、指标历史和 Prometheus 接口，无需声明权限
+	RegisterMetric(context.Context, *MetricDesc) (*Empty, error)
+	// 上报已注册指标的样本：gauge 设置为 value，counter 累加 value
+	RecordMetrics(context.Context, *MetricBatch) (*Empty, error)
+	// 注册定时任务，同名任务已存在时替换；任务到期时 Agent 调用插件的 OnSchedule，插件停止时任务被移除
+	Schedule(context.Context, *ScheduleSpec) (*Empty, error)
+	// 移除定时任务，只使用 name
+	Unschedule(context.Context, *ScheduleSpec) (*Empty, error)
+	mustEmbedUnimplementedHostServer()
+}
+
+// UnimplementedHostServer must be embedded to have forward compatible implementations.
+type UnimplementedHostServer struct {
+}
+
+func (UnimplementedHostServer) Publish(context.Context, *Event) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Publish not implemented")
+}
+func (UnimplementedHostServer) ReadFile(context.Context, *FileRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadFile not implemented")
+}
+func (UnimplementedHostServer) WriteFile(context.Context, *FileRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteFile not implemented")
+}
+func (UnimplementedHostServer) Exec(context.Context, *ExecRequest) (*ExecResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exec not implemented")
+}
+func (UnimplementedHostServer) Fetch(context.Context, *FetchRequest) (*FetchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fetch not implemented")
+}
+func (UnimplementedHostServer) GetSystemInfo(context.Context, *Empty) (*SystemInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSystemInfo not implemented")
+}
+func (UnimplementedHostServer) StorageGet(context.Context, *StorageRequest) (*StorageValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageGet not implemented")
+}
+func (UnimplementedHostServer) StorageSet(context.Context, *StorageRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageSet not implemented")
+}
+func (UnimplementedHostServer) StorageDelete(context.Context, *StorageRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageDelete not implemented")
+}
+func (UnimplementedHostServer) StorageList(context.Context, *StorageRequest) (*StorageKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageList not implemented")
+}
+func (UnimplementedHostServer) RegisterMetric(context.Context, *MetricDesc) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterMetric not implemented")
+}
+func (UnimplementedHostServer) RecordMetrics(context.Context, *MetricBatch) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordMetrics not implemented")
+}
+func (UnimplementedHostServer) Schedule(context.Context, *ScheduleSpec) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Schedule not implemented")
+}
+func (UnimplementedHostServer) Unschedule(context.Context, *ScheduleSpec) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unschedule not implemented")
+}
+func (UnimplementedHostServer) mustEmbedUnimplementedHostServer() {}
+
+// UnsafeHostServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HostServer will
+// result in compilation errors.
+type UnsafeHostServer interface {
+	mustEmbedUnimplementedHostServer()
+}
+
+func RegisterHostServer(s grpc.ServiceRegistrar, srv HostServer) {
+	s.RegisterService(&Host_ServiceDesc, srv)
+}
+
+func _Host_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Event)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).Publish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_Publish_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).Publish(ctx, req.(*Event))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_ReadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).ReadFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_ReadFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).ReadFile(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_WriteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).WriteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_WriteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).WriteFile(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_Exec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).Exec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_Exec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).Exec(ctx, req.(*ExecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).Fetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_Fetch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).Fetch(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_GetSystemInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).GetSystemInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_GetSystemInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).GetSystemInfo(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_StorageGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).StorageGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_StorageGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).StorageGet(ctx, req.(*StorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_StorageSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).StorageSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_StorageSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).StorageSet(ctx, req.(*StorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_StorageDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).StorageDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_StorageDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).StorageDelete(ctx, req.(*StorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_StorageList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).StorageList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_StorageList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).StorageList(ctx, req.(*StorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_RegisterMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricDesc)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).RegisterMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_RegisterMetric_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).RegisterMetric(ctx, req.(*MetricDesc))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_RecordMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).RecordMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_RecordMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).RecordMetrics(ctx, req.(*MetricBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_Schedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleSpec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).Schedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_Schedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).Schedule(ctx, req.(*ScheduleSpec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Host_Unschedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleSpec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServer).Unschedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Host_Unschedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServer).Unschedule(ctx, req.(*ScheduleSpec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Host_ServiceDesc is the grpc.ServiceDesc for Host service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Host_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "runixo.plugin.Host",
+	HandlerType: (*HostServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Publish",
+			Handler:    _Host_Publish_Handler,
+		},
+		{
+			MethodName: "ReadFile",
+			Handler:    _Host_ReadFile_Handler,
+		},
+		{
+			MethodName: "WriteFile",
+			Handler:    _Host_WriteFile_Handler,
+		},
+		{
+			MethodName: "Exec",
+			Handler:    _Host_Exec_Handler,
+		},
+		{
+			MethodName: "Fetch",
+			Handler:    _Host_Fetch_Handler,
+		},
+		{
+			MethodName: "GetSystemInfo",
+			Handler:    _Host_GetSystemInfo_Handler,
+		},
+		{
+			MethodName: "StorageGet",
+			Handler:    _Host_StorageGet_Handler,
+		},
+		{
+			MethodName: "StorageSet",
+			Handler:    _Host_StorageSet_Handler,
+		},
+		{
+			MethodName: "StorageDelete",
+			Handler:    _Host_StorageDelete_Handler,
+		},
+		{
+			MethodName: "StorageList",
+			Handler:    _Host_StorageList_Handler,
+		},
+		{
+			MethodName: "RegisterMetric",
+			Handler:    _Host_RegisterMetric_Handler,
+		},
+		{
+			MethodName: "RecordMetrics",
+			Handler:    _Host_RecordMetrics_Handler,
+		},
+		{
+			MethodName: "Schedule",
+			Handler:    _Host_Schedule_Handler,
+		},
+		{
+			MethodName: "Unschedule",
+			Handler:    _Host_Unschedule_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "plugin.proto",
+}
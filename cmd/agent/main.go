@@ -5,7 +5,6 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
@@ -17,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"syscall"
 	"time"
 
@@ -26,13 +26,21 @@ import (
 	"github.com/runixo/agent/internal/api"
 	"github.com/runixo/agent/internal/audit"
 	"github.com/runixo/agent/internal/auth"
+	"github.com/runixo/agent/internal/collector"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/executor"
 	"github.com/runixo/agent/internal/plugin"
 	"github.com/runixo/agent/internal/ratelimit"
+	"github.com/runixo/agent/internal/scheduler"
+	"github.com/runixo/agent/internal/security"
 	"github.com/runixo/agent/internal/server"
 	"github.com/runixo/agent/internal/updater"
+	"github.com/runixo/agent/internal/webhook"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 )
 
 var (
@@ -41,6 +49,10 @@ var (
 )
 
 func main() {
+	// 作为沙箱或插件隔离辅助进程启动时不会返回
+	executor.RunSandboxHelper()
+	plugin.RunIsolationHelper()
+
 	// 命令行参数
 	configFile := flag.String("config", "/etc/runixo/agent.yaml", "配置文件路径")
 	showVersion := flag.Bool("version", false, "显示版本信息")
@@ -59,6 +71,7 @@ func main() {
 			os.Exit(1)
 		}
 		fmt.Printf("新令牌: %s\n", token)
+		fmt.Printf("令牌 ID（用于执行策略的 tokens）: %s\n", auth.TokenID(token))
 		os.Exit(0)
 	}
 
@@ -93,15 +106,75 @@ func loadConfig(configFile string) error {
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.port", 9527)
 	viper.SetDefault("server.api_port", 9528)
+	viper.SetDefault("server.dashboard", true)
+	viper.SetDefault("server.reflection", false)
 	viper.SetDefault("server.tls.enabled", true)
+	viper.SetDefault("server.tls.client_auth", security.ClientAuthNone)
 	viper.SetDefault("auth.token", "")
 	viper.SetDefault("metrics.interval", 2)
+	defaultCache := collector.DefaultCacheConfig()
+	viper.SetDefault("metrics.intervals.metrics", defaultCache.Metrics)
+	viper.SetDefault("metrics.intervals.processes", defaultCache.Processes)
+	viper.SetDefault("metrics.intervals.disks", defaultCache.Disks)
+	viper.SetDefault("metrics.intervals.connections", defaultCache.Connections)
+	viper.SetDefault("metrics.history.enabled", true)
+	viper.SetDefault("metrics.history.retention", "7d")
+	viper.SetDefault("metrics.history.resolution", "1m")
+	viper.SetDefault("metrics.history.sample_interval", "10s")
+	viper.SetDefault("metrics.ntp_server", "")
+	viper.SetDefault("public_ip.enabled", false)
+	viper.SetDefault("public_ip.services", []string{"https://ifconfig.co/json"})
+	viper.SetDefault("public_ip.stun_server", "")
+	viper.SetDefault("public_ip.interval", time.Hour)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("data.dir", "/var/lib/runixo")
 	viper.SetDefault("plugins.dir", "/var/lib/runixo/plugins")
+	viper.SetDefault("plugins.registry.url", "https://plugins.runixo.dev")
+	viper.SetDefault("plugins.registry.public_keys", []string{})
+	viper.SetDefault("plugins.registry.refresh", time.Hour)
+	viper.SetDefault("plugins.signing.trusted_keys", []string{})
+	viper.SetDefault("plugins.signing.allow_unsigned", false)
+	viper.SetDefault("plugins.limits.cpu_percent", 100)
+	viper.SetDefault("plugins.limits.memory_mb", 512)
+	viper.SetDefault("plugins.limits.goroutines", 1000)
+	viper.SetDefault("plugins.limits.interval", 10*time.Second)
+	viper.SetDefault("plugins.limits.max_violations", 3)
+	viper.SetDefault("plugins.limits.storage_mb", 8)
+	viper.SetDefault("plugins.health.interval", 30*time.Second)
+	viper.SetDefault("plugins.health.timeout", 10*time.Second)
+	viper.SetDefault("plugins.health.failure_threshold", 3)
+	viper.SetDefault("plugins.health.restart_policy", "on-failure")
+	viper.SetDefault("plugins.health.max_restarts", 5)
+	viper.SetDefault("plugins.health.initial_backoff", time.Second)
+	viper.SetDefault("plugins.health.max_backoff", 5*time.Minute)
+	viper.SetDefault("plugins.isolation.protected_paths", []string{})
+	viper.SetDefault("plugins.isolation.mount_namespace", false)
+	viper.SetDefault("plugins.isolation.uid_base", 0)
+	viper.SetDefault("plugins.isolation.uid_count", 1000)
 	viper.SetDefault("update.auto", false)
 	viper.SetDefault("update.channel", "stable")
 	viper.SetDefault("update.interval", 3600)
+	viper.SetDefault("services.manageable", api.DefaultManageableServices)
+	viper.SetDefault("webhooks.allow_private", false)
+	viper.SetDefault("shell.enabled", false)
+	viper.SetDefault("shell.idle_timeout", 15*time.Minute)
+	viper.SetDefault("shell.record", true)
+	viper.SetDefault("audit.max_size_mb", 50)
+	viper.SetDefault("audit.max_backups", 5)
+	viper.SetDefault("audit.retention", 90*24*time.Hour)
+	viper.SetDefault("executor.limits.cpu_time", 10*time.Minute)
+	viper.SetDefault("executor.limits.memory_mb", 2048)
+	viper.SetDefault("executor.limits.max_procs", 512)
+	viper.SetDefault("executor.limits.max_output_mb", 0)
+	viper.SetDefault("executor.scripts.enabled", false)
+	viper.SetDefault("executor.max_read_mb", 50)
+	viper.SetDefault("executor.kill_grace", 5*time.Second)
+	viper.SetDefault("executor.sandbox.enforce", false)
+	viper.SetDefault("executor.sandbox.network", false)
+	viper.SetDefault("executor.sandbox.root", "")
+	viper.SetDefault("executor.trash.enabled", true)
+	viper.SetDefault("executor.trash.dir", "")
+	viper.SetDefault("executor.trash.retention", 7*24*time.Hour)
 
 	// 环境变量覆盖
 	viper.AutomaticEnv()
@@ -134,17 +207,126 @@ func run() error {
 	dataDir := viper.GetString("data.dir")
 	pluginsDir := viper.GetString("plugins.dir")
 
+	// 主机标签（名称会被 viper 转为小写）
+	labels := viper.GetStringMapString("labels")
+	if err := collector.ValidateLabels(labels); err != nil {
+		return fmt.Errorf("标签配置无效: %w", err)
+	}
+
 	// 创建数据目录
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return fmt.Errorf("创建数据目录失败: %w", err)
 	}
 
+	// 事件总线：核心模块和插件发布的事件经总线分发，对外可订阅的事件再转发给 webhook
+	bus := eventbus.New()
+
+	// 初始化 webhook 投递器（失败时不影响其他功能）
+	webhooks, err := webhook.NewDispatcher(dataDir, viper.GetBool("webhooks.allow_private"))
+	if err != nil {
+		log.Warn().Err(err).Msg("初始化 webhook 失败，事件推送不可用")
+	} else {
+		webhooks.SetLabels(labels)
+		defer webhooks.Close()
+		stopForward := bus.Forward("webhook", webhooks, webhook.KnownEvents...)
+		defer stopForward()
+	}
+
+	// 指标历史（失败时不影响其他功能）
+	var history *collector.HistoryStore
+	if viper.GetBool("metrics.history.enabled") {
+		history, err = newHistoryStore(filepath.Join(dataDir, "history"))
+		if err != nil {
+			log.Warn().Err(err).Msg("初始化指标历史失败")
+		} else {
+			history.SetEventPublisher(bus)
+			history.Start()
+			defer history.Stop()
+		}
+	}
+
+	// 登录监视：新的交互式登录写入日志并推送 login 事件
+	loginWatcher := collector.NewLoginWatcher(bus)
+	loginWatcher.Start()
+	defer loginWatcher.Stop()
+
+	// 邻居表监视：出现新的或 MAC 发生变化的 ARP / NDP 条目时推送 neighbor 事件
+	neighborWatcher := collector.NewNeighborWatcher(bus)
+	neighborWatcher.Start()
+	defer neighborWatcher.Stop()
+
+	// 定时任务调度器：插件注册的任务共用，上次运行时间保存在数据目录，用于补跑 Agent 停止期间错过的任务
+	taskScheduler := scheduler.New(filepath.Join(dataDir, "scheduler.json"))
+	defer taskScheduler.Stop()
+
 	// 初始化插件管理器
 	pluginManager, err := plugin.NewManager(pluginsDir)
 	if err != nil {
 		return fmt.Errorf("初始化插件管理器失败: %w", err)
 	}
 	defer pluginManager.Close()
+	pluginManager.SetAgentVersion(version)
+	pluginManager.SetLimits(plugin.LimitsConfig{
+		Default: plugin.ResourceLimits{
+			CPUPercent: max(viper.GetFloat64("plugins.limits.cpu_percent"), 0),
+			Memory:     uint64(max(viper.GetInt64("plugins.limits.memory_mb"), 0)) << 20,
+			Goroutines: max(viper.GetInt("plugins.limits.goroutines"), 0),
+		},
+		Interval:      viper.GetDuration("plugins.limits.interval"),
+		MaxViolations: viper.GetInt("plugins.limits.max_violations"),
+		Storage:       max(viper.GetInt("plugins.limits.storage_mb"), 0) << 20,
+	})
+	pluginManager.SetHealth(plugin.HealthConfig{
+		Interval:         viper.GetDuration("plugins.health.interval"),
+		Timeout:          viper.GetDuration("plugins.health.timeout"),
+		FailureThreshold: viper.GetInt("plugins.health.failure_threshold"),
+		Restart: plugin.RestartConfig{
+			Policy:      plugin.RestartPolicy(viper.GetString("plugins.health.restart_policy")),
+			MaxRestarts: viper.GetInt("plugins.health.max_restarts"),
+		},
+		InitialBackoff: viper.GetDuration("plugins.health.initial_backoff"),
+		MaxBackoff:     viper.GetDuration("plugins.health.max_backoff"),
+	})
+	pluginManager.SetEventBus(bus)
+	pluginManager.SetScheduler(taskScheduler)
+	// Agent 的配置文件和数据目录对插件不可见（插件各自的目录除外）
+	protected := append([]string{dataDir}, viper.GetStringSlice("plugins.isolation.protected_paths")...)
+	if cfg := viper.ConfigFileUsed(); cfg != "" {
+		if abs, err := filepath.Abs(cfg); err == nil {
+			protected = append(protected, abs)
+		}
+	}
+	if err := pluginManager.SetIsolation(plugin.IsolationConfig{
+		ProtectedPaths: protected,
+		MountNamespace: viper.GetBool("plugins.isolation.mount_namespace"),
+		UIDBase:        viper.GetInt("plugins.isolation.uid_base"),
+		UIDCount:       viper.GetInt("plugins.isolation.uid_count"),
+	}); err != nil {
+		return fmt.Errorf("插件隔离配置无效: %w", err)
+	}
+	if err := pluginManager.SetSigning(plugin.SigningConfig{
+		TrustedKeys:   viper.GetStringSlice("plugins.signing.trusted_keys"),
+		AllowUnsigned: viper.GetBool("plugins.signing.allow_unsigned"),
+	}); err != nil {
+		return fmt.Errorf("插件签名配置无效: %w", err)
+	}
+	if viper.GetBool("plugins.signing.allow_unsigned") {
+		log.Warn().Msg("已允许安装未签名的插件包（plugins.signing.allow_unsigned）")
+	}
+	if keys := viper.GetStringSlice("plugins.registry.public_keys"); len(keys) > 0 {
+		registry, err := plugin.NewRegistry(plugin.RegistryConfig{
+			URL:        viper.GetString("plugins.registry.url"),
+			PublicKeys: keys,
+			CacheDir:   filepath.Join(pluginsDir, ".registry"),
+			Refresh:    viper.GetDuration("plugins.registry.refresh"),
+		})
+		if err != nil {
+			return fmt.Errorf("插件仓库配置无效: %w", err)
+		}
+		pluginManager.SetRegistry(registry)
+	} else {
+		log.Info().Msg("未配置插件仓库公钥（plugins.registry.public_keys），插件市场使用内置列表")
+	}
 
 	// 启动已启用的插件
 	pluginManager.StartEnabledPlugins()
@@ -155,6 +337,7 @@ func run() error {
 		return fmt.Errorf("初始化更新器失败: %w", err)
 	}
 	defer agentUpdater.Stop()
+	agentUpdater.SetEventPublisher(bus)
 
 	// 配置更新器
 	if viper.GetBool("update.auto") {
@@ -167,46 +350,63 @@ func run() error {
 		agentUpdater.Start()
 	}
 
-	// 创建 gRPC 监听器
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return fmt.Errorf("监听端口失败: %w", err)
+	// 创建 gRPC 监听器（未配置 server.listeners 时监听 server.host:server.port）
+	var listenerConfigs []server.ListenerConfig
+	if err := viper.UnmarshalKey("server.listeners", &listenerConfigs); err != nil {
+		return fmt.Errorf("解析监听配置失败: %w", err)
+	}
+	if len(listenerConfigs) == 0 {
+		listenerConfigs = []server.ListenerConfig{{Network: "tcp", Address: addr}}
+	}
+	var listeners []net.Listener
+	var grpcAddrs []string
+	for _, config := range listenerConfigs {
+		listener, err := server.Listen(config)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("监听 %s 失败: %w", config.Address, err)
+		}
+		listeners = append(listeners, listener)
+		grpcAddrs = append(grpcAddrs, listener.Addr().Network()+"://"+listener.Addr().String())
 	}
 
 	// gRPC 服务器选项
 	var opts []grpc.ServerOption
 
-	// TLS 证书路径（gRPC 和 REST API 共用）
-	var certFile, keyFile string
+	// TLS 证书（gRPC 和 REST API 共用，收到 SIGHUP 时重新加载）
+	var certs *security.CertReloader
 
 	// TLS 配置
-	if viper.GetBool("server.tls.enabled") {
-		certFile = viper.GetString("server.tls.cert")
-		keyFile = viper.GetString("server.tls.key")
-
+	var tlsConfig security.TLSConfig
+	if err := viper.UnmarshalKey("server.tls", &tlsConfig); err != nil {
+		return fmt.Errorf("解析 TLS 配置失败: %w", err)
+	}
+	if tlsConfig.Enabled {
 		// 如果证书文件不存在，自动生成自签名证书
-		if certFile == "" || keyFile == "" {
-			certFile = filepath.Join(dataDir, "tls", "cert.pem")
-			keyFile = filepath.Join(dataDir, "tls", "key.pem")
+		if tlsConfig.Cert == "" || tlsConfig.Key == "" {
+			tlsConfig.Cert = filepath.Join(dataDir, "tls", "cert.pem")
+			tlsConfig.Key = filepath.Join(dataDir, "tls", "key.pem")
 		}
 
-		if _, err := os.Stat(certFile); os.IsNotExist(err) {
+		if _, err := os.Stat(tlsConfig.Cert); os.IsNotExist(err) {
 			log.Info().Msg("TLS 证书不存在，自动生成自签名证书...")
-			if err := generateSelfSignedCert(certFile, keyFile); err != nil {
+			if err := generateSelfSignedCert(tlsConfig.Cert, tlsConfig.Key); err != nil {
 				return fmt.Errorf("生成自签名证书失败: %w", err)
 			}
-			log.Info().Str("cert", certFile).Str("key", keyFile).Msg("自签名证书已生成")
+			log.Info().Str("cert", tlsConfig.Cert).Str("key", tlsConfig.Key).Msg("自签名证书已生成")
 		}
 
 		// 设置环境变量供 DownloadCertificate 使用
-		os.Setenv("TLS_CERT_FILE", certFile)
+		os.Setenv("TLS_CERT_FILE", tlsConfig.Cert)
 
-		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		certs, err = security.NewCertReloader(tlsConfig)
 		if err != nil {
 			return fmt.Errorf("加载TLS证书失败: %w", err)
 		}
-		opts = append(opts, grpc.Creds(creds))
-		log.Info().Msg("TLS 已启用")
+		opts = append(opts, grpc.Creds(credentials.NewTLS(certs.ServerConfig(true, "h2"))))
+		log.Info().Bool("verify_clients", certs.VerifiesClients()).Msg("TLS 已启用")
 	} else {
 		log.Warn().Msg("⚠️  TLS 已禁用，gRPC 通信未加密，强烈建议启用 TLS")
 	}
@@ -214,26 +414,148 @@ func run() error {
 	// 添加认证和速率限制拦截器
 	if token == "" {
 		log.Warn().Msg("未设置认证令牌，建议使用 --gen-token 生成")
+	} else {
+		log.Info().Str("token_id", auth.TokenID(token)).Msg("认证令牌已配置")
 	}
 	authInterceptor := auth.NewAuthInterceptor(token)
 	rateLimiter := ratelimit.NewLimiter(nil) // 使用默认配置
 
 	// 审计日志
-	auditLogger, _ := audit.NewLogger(&audit.Config{
-		Enabled: true,
-		LogPath: filepath.Join(dataDir, "audit", "audit.log"),
-	})
+	auditConfig := audit.DefaultConfig()
+	auditConfig.LogPath = filepath.Join(dataDir, "audit", "audit.log")
+	auditConfig.MaxSizeMB = viper.GetInt("audit.max_size_mb")
+	auditConfig.MaxBackups = viper.GetInt("audit.max_backups")
+	auditConfig.Retention = viper.GetDuration("audit.retention")
+	auditLogger, _ := audit.NewLogger(auditConfig)
+	pluginManager.SetAuditLogger(auditLogger)
 
 	opts = append(opts,
-		grpc.ChainUnaryInterceptor(rateLimiter.UnaryInterceptor(), authInterceptor.Unary(), auditLogger.UnaryInterceptor()),
-		grpc.ChainStreamInterceptor(rateLimiter.StreamInterceptor(), authInterceptor.Stream()),
+		grpc.ChainUnaryInterceptor(errcode.UnaryServerInterceptor(), rateLimiter.UnaryInterceptor(), authInterceptor.Unary(), auditLogger.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(errcode.StreamServerInterceptor(), rateLimiter.StreamInterceptor(), authInterceptor.Stream(), auditLogger.StreamInterceptor()),
 	)
 
 	// 创建 gRPC 服务器
 	grpcServer := grpc.NewServer(opts...)
 
+	// gRPC 与 REST 共用一个采集器，多个客户端同时轮询时共享缓存的快照
+	sharedCollector := collector.New()
+	sharedCollector.SetCacheConfig(collector.CacheConfig{
+		Metrics:     viper.GetDuration("metrics.intervals.metrics"),
+		Processes:   viper.GetDuration("metrics.intervals.processes"),
+		Disks:       viper.GetDuration("metrics.intervals.disks"),
+		Connections: viper.GetDuration("metrics.intervals.connections"),
+	})
+	sharedCollector.SetNTPServer(viper.GetString("metrics.ntp_server"))
+	sharedCollector.SetLabels(labels)
+	if viper.GetBool("public_ip.enabled") {
+		sharedCollector.SetPublicIPConfig(collector.PublicIPConfig{
+			Services:   viper.GetStringSlice("public_ip.services"),
+			STUNServer: viper.GetString("public_ip.stun_server"),
+			Interval:   viper.GetDuration("public_ip.interval"),
+		})
+	}
+
+	// 进程健康监视：卡死（D 状态）进程或僵尸进程过多时推送 alert 事件
+	processWatcher := collector.NewProcessWatcher(sharedCollector, bus)
+	processWatcher.Start()
+	defer processWatcher.Stop()
+
 	// 注册服务
 	agentServer := server.NewAgentServer(version, token)
+	agentServer.SetCollector(sharedCollector)
+	agentServer.SetAuditLogger(auditLogger)
+	agentServer.SetExecLimits(executor.Limits{
+		CPUTime:   viper.GetDuration("executor.limits.cpu_time"),
+		Memory:    uint64(max(viper.GetInt64("executor.limits.memory_mb"), 0)) << 20,
+		MaxOutput: max(viper.GetInt64("executor.limits.max_output_mb"), 0) << 20,
+		MaxProcs:  max(viper.GetInt("executor.limits.max_procs"), 0),
+	})
+	agentServer.SetMaxReadSize(max(viper.GetInt64("executor.max_read_mb"), 0) << 20)
+	var policyConfig security.PolicyConfig
+	if err := viper.UnmarshalKey("executor.policy", &policyConfig); err != nil {
+		return fmt.Errorf("解析执行策略失败: %w", err)
+	}
+	if len(policyConfig.Rules) > 0 || policyConfig.Default != "" {
+		policy, err := security.NewPolicy(policyConfig)
+		if err != nil {
+			return fmt.Errorf("执行策略无效: %w", err)
+		}
+		agentServer.SetExecPolicy(policy)
+		log.Info().Int("rules", len(policyConfig.Rules)).Str("default", policyConfig.Default).Msg("已启用命令执行策略")
+	}
+	var elevationConfig security.ElevationConfig
+	if err := viper.UnmarshalKey("executor.elevation", &elevationConfig); err != nil {
+		return fmt.Errorf("解析提权配置失败: %w", err)
+	}
+	elevation, err := security.NewElevation(elevationConfig)
+	if err != nil {
+		return fmt.Errorf("提权配置无效: %w", err)
+	}
+	executor.SetElevation(elevation)
+	if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+		if elevation == nil {
+			log.Warn().Msg("Agent 以普通用户运行且未配置提权（executor.elevation.mode），服务管理等操作可能失败")
+		} else {
+			log.Info().Str("mode", elevationConfig.Mode).Msg("已启用提权策略，特权操作通过 sudo 执行")
+		}
+	}
+	executor.SetKillGrace(viper.GetDuration("executor.kill_grace"))
+	agentServer.SetScriptConfig(server.ScriptConfig{
+		Enabled:      viper.GetBool("executor.scripts.enabled"),
+		Interpreters: viper.GetStringSlice("executor.scripts.interpreters"),
+	})
+	if viper.GetBool("executor.scripts.enabled") {
+		log.Warn().Msg("脚本执行已启用，脚本内容不受命令白名单限制")
+	}
+	// 沙箱中同时隐藏 Agent 的配置（含令牌）、数据目录、TLS 私钥和 unix 套接字
+	sandboxHidden := append(viper.GetStringSlice("executor.sandbox.hidden"), dataDir)
+	if cfg := viper.ConfigFileUsed(); cfg != "" {
+		sandboxHidden = append(sandboxHidden, filepath.Dir(cfg))
+	}
+	if tlsConfig.Key != "" {
+		sandboxHidden = append(sandboxHidden, tlsConfig.Key)
+	}
+	for _, l := range listenerConfigs {
+		if l.Network == "unix" {
+			sandboxHidden = append(sandboxHidden, l.Address)
+		}
+	}
+	agentServer.SetSandboxConfig(server.SandboxConfig{
+		Sandbox: executor.Sandbox{
+			Network:  viper.GetBool("executor.sandbox.network"),
+			Root:     viper.GetString("executor.sandbox.root"),
+			Writable: viper.GetStringSlice("executor.sandbox.writable"),
+			Hidden:   sandboxHidden,
+		},
+		Enforce: viper.GetBool("executor.sandbox.enforce"),
+	})
+	if viper.GetBool("executor.sandbox.enforce") {
+		log.Info().Msg("已启用强制沙箱，所有命令和脚本都在沙箱中执行")
+	}
+	if viper.GetBool("executor.trash.enabled") {
+		trashDir := viper.GetString("executor.trash.dir")
+		if trashDir == "" {
+			trashDir = filepath.Join(dataDir, "trash")
+		}
+		if trash, err := executor.NewTrash(trashDir, viper.GetDuration("executor.trash.retention")); err != nil {
+			log.Warn().Err(err).Msg("回收站不可用")
+		} else {
+			agentServer.SetTrash(trash)
+		}
+	}
+	shellConfig := server.ShellConfig{
+		Enabled:     viper.GetBool("shell.enabled"),
+		Shells:      viper.GetStringSlice("shell.shells"),
+		IdleTimeout: viper.GetDuration("shell.idle_timeout"),
+	}
+	if viper.GetBool("shell.record") {
+		shellConfig.RecordDir = filepath.Join(dataDir, "audit", "sessions")
+	}
+	agentServer.SetShellConfig(shellConfig)
+	if shellConfig.Enabled {
+		log.Warn().Msg("交互式终端已启用，会话不受命令白名单限制")
+	}
+	agentServer.SetEventPublisher(bus)
 	pb.RegisterAgentServiceServer(grpcServer, agentServer)
 
 	// 注册插件服务
@@ -244,8 +566,30 @@ func run() error {
 	updateServer := server.NewUpdateServer(agentUpdater)
 	pb.RegisterUpdateServiceServer(grpcServer, updateServer)
 
+	// gRPC 反射（便于在现场使用 grpcurl 调试），与其他调用一样需要通过认证
+	if viper.GetBool("server.reflection") {
+		reflection.Register(grpcServer)
+		if token == "" {
+			log.Warn().Msg("⚠️  gRPC 反射已启用但未设置认证令牌，任何人都可以列出服务接口")
+		} else {
+			log.Info().Msg("gRPC 反射已启用")
+		}
+	}
+
 	// 创建 REST API 服务器
 	apiServer := api.NewServer(token, version)
+	apiServer.SetCollector(sharedCollector)
+	apiServer.SetServiceAllowlist(viper.GetStringSlice("services.manageable"))
+	apiServer.SetDashboardEnabled(viper.GetBool("server.dashboard"))
+	apiServer.SetPluginManager(pluginManager)
+	apiServer.SetUpdater(agentUpdater)
+	apiServer.SetAuditLogger(auditLogger)
+	if webhooks != nil {
+		apiServer.SetWebhooks(webhooks)
+	}
+	if history != nil {
+		apiServer.SetHistory(history)
+	}
 	mux := http.NewServeMux()
 	apiServer.RegisterRoutes(mux)
 	httpServer := &http.Server{
@@ -271,9 +615,24 @@ func run() error {
 		cancel()
 	}()
 
+	// 收到 SIGHUP 时重新加载 TLS 证书，加载失败时继续使用原来的证书
+	if certs != nil {
+		go func() {
+			hupCh := make(chan os.Signal, 1)
+			signal.Notify(hupCh, syscall.SIGHUP)
+			for range hupCh {
+				if err := certs.Reload(); err != nil {
+					log.Error().Err(err).Msg("重新加载 TLS 证书失败，继续使用原来的证书")
+					continue
+				}
+				log.Info().Str("cert", tlsConfig.Cert).Msg("已重新加载 TLS 证书")
+			}
+		}()
+	}
+
 	log.Info().
 		Str("version", version).
-		Str("grpc", addr).
+		Strs("grpc", grpcAddrs).
 		Str("api", apiAddr).
 		Bool("auto_update", viper.GetBool("update.auto")).
 		Msg("Runixo Agent 已启动")
@@ -281,11 +640,11 @@ func run() error {
 	// 启动 REST API 服务器（如果 TLS 启用则使用 HTTPS）
 	go func() {
 		var err error
-		if certFile != "" && keyFile != "" {
-			// REST API 也使用 TLS
-			httpServer.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if certs != nil {
+			// REST API 也使用 TLS（不校验客户端证书，REST API 使用令牌认证）
+			httpServer.TLSConfig = certs.ServerConfig(false, "h2", "http/1.1")
 			log.Info().Str("addr", apiAddr).Msg("REST API 使用 HTTPS")
-			err = httpServer.ListenAndServeTLS(certFile, keyFile)
+			err = httpServer.ListenAndServeTLS("", "")
 		} else {
 			log.Warn().Msg("⚠️  REST API 使用 HTTP（未加密），建议启用 TLS")
 			err = httpServer.ListenAndServe()
@@ -295,9 +654,17 @@ func run() error {
 		}
 	}()
 
-	// 启动 gRPC 服务
-	if err := grpcServer.Serve(listener); err != nil {
-		return fmt.Errorf("gRPC服务错误: %w", err)
+	// 启动 gRPC 服务（每个监听器一个协程，GracefulStop 后全部返回）
+	serveErrs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(l net.Listener) {
+			serveErrs <- grpcServer.Serve(l)
+		}(listener)
+	}
+	for range listeners {
+		if err := <-serveErrs; err != nil {
+			return fmt.Errorf("gRPC服务错误: %w", err)
+		}
 	}
 
 	<-ctx.Done()
@@ -305,6 +672,25 @@ func run() error {
 	return nil
 }
 
+// newHistoryStore 按配置创建指标历史存储（使用独立的采集器，不影响实时接口的速率基准）
+func newHistoryStore(dir string) (*collector.HistoryStore, error) {
+	config := collector.HistoryConfig{Dir: dir}
+	var err error
+	if config.Retention, err = collector.ParseRetention(viper.GetString("metrics.history.retention")); err != nil {
+		return nil, err
+	}
+	if config.Resolution, err = collector.ParseRetention(viper.GetString("metrics.history.resolution")); err != nil {
+		return nil, err
+	}
+	if config.SampleInterval, err = collector.ParseRetention(viper.GetString("metrics.history.sample_interval")); err != nil {
+		return nil, err
+	}
+	c := collector.New()
+	c.SetContainerMetrics(false)
+	c.SetProcessMetrics(false)
+	return collector.NewHistoryStore(c, config)
+}
+
 // generateSelfSignedCert 生成自签名 TLS 证书
 func generateSelfSignedCert(certFile, keyFile string) error {
 	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
@@ -3,22 +3,39 @@ package api
 import (
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/runixo/agent/internal/audit"
 	"github.com/runixo/agent/internal/collector"
+	"github.com/runixo/agent/internal/docker"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/plugin"
+	"github.com/runixo/agent/internal/updater"
+	"github.com/runixo/agent/internal/webhook"
 )
 
 // Server REST API 服务器
 type Server struct {
 	collector      *collector.Collector
+	docker         *docker.Client
+	plugins        *plugin.Manager
+	updater        *updater.Updater
+	audit          *audit.Logger
+	webhooks       *webhook.Dispatcher
+	history        *collector.HistoryStore
 	token          string
 	version        string
+	dashboard      bool // 是否在 / 提供内置面板
 	failedAttempts map[string]*apiAttemptInfo
-	mu             sync.RWMutex
+	// 允许通过 API 操作的 systemd 服务（通配符模式）
+	serviceAllowlist []string
+	mu               sync.RWMutex
 }
 
 type apiAttemptInfo struct {
@@ -30,15 +47,23 @@ type apiAttemptInfo struct {
 // NewServer 创建 API 服务器
 func NewServer(token, version string) *Server {
 	s := &Server{
-		collector:      collector.New(),
-		token:          token,
-		version:        version,
-		failedAttempts: make(map[string]*apiAttemptInfo),
+		collector:        collector.New(),
+		docker:           docker.NewClient(""),
+		token:            token,
+		version:          version,
+		failedAttempts:   make(map[string]*apiAttemptInfo),
+		serviceAllowlist: DefaultManageableServices,
+		dashboard:        true,
 	}
 	go s.cleanupLoop()
 	return s
 }
 
+// SetCollector 替换采集器（需在 RegisterRoutes 之前调用），便于与 gRPC 服务共享缓存的快照
+func (s *Server) SetCollector(c *collector.Collector) {
+	s.collector = c
+}
+
 // cleanupLoop 定期清理过期的失败记录
 func (s *Server) cleanupLoop() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -60,6 +85,9 @@ type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"` // 稳定错误码，见 internal/errcode
+	// Details 逐字段的校验错误，如插件清单校验失败的字段
+	Details []errcode.FieldViolation `json:"details,omitempty"`
 }
 
 func (s *Server) recordAPIFailedAttempt(ip string) {
@@ -87,14 +115,14 @@ func (s *Server) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		s.mu.RUnlock()
 		if exists && time.Now().Before(info.lockedUntil) {
 			w.Header().Set("Retry-After", "900")
-			s.jsonError(w, "Too many failed attempts", http.StatusTooManyRequests)
+			s.jsonErrorCode(w, errcode.AuthLocked, "Too many failed attempts")
 			return
 		}
 
 		auth := r.Header.Get("Authorization")
 		if auth == "" {
 			s.recordAPIFailedAttempt(ip)
-			s.jsonError(w, "Missing authorization header", http.StatusUnauthorized)
+			s.jsonErrorCode(w, errcode.AuthRequired, "Missing authorization header")
 			return
 		}
 
@@ -102,7 +130,7 @@ func (s *Server) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		// 常量时间比较防止时序攻击
 		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
 			s.recordAPIFailedAttempt(ip)
-			s.jsonError(w, "Invalid token", http.StatusUnauthorized)
+			s.jsonErrorCode(w, errcode.AuthInvalid, "Invalid token")
 			return
 		}
 
@@ -130,11 +158,31 @@ func (s *Server) jsonResponse(w http.ResponseWriter, data interface{}) {
 	json.NewEncoder(w).Encode(Response{Success: true, Data: data})
 }
 
-// jsonError 发送错误响应
-func (s *Server) jsonError(w http.ResponseWriter, message string, code int) {
+// jsonError 发送错误响应，错误码由 HTTP 状态推导
+func (s *Server) jsonError(w http.ResponseWriter, message string, httpStatus int) {
+	s.writeError(w, errcode.FromHTTPStatus(httpStatus), message, httpStatus, nil)
+}
+
+// jsonErrorCode 发送指定错误码的错误响应
+func (s *Server) jsonErrorCode(w http.ResponseWriter, code errcode.Code, message string) {
+	s.writeError(w, code, message, errcode.HTTPStatus(code), nil)
+}
+
+// jsonErrorFrom 发送内部错误对应的错误响应
+// err 携带错误码时使用其错误码和对应状态，否则使用 fallback 状态
+func (s *Server) jsonErrorFrom(w http.ResponseWriter, message string, err error, fallback int) {
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		s.writeError(w, e.Code, message, errcode.HTTPStatus(e.Code), errcode.FieldsOf(err))
+		return
+	}
+	s.jsonError(w, message, fallback)
+}
+
+func (s *Server) writeError(w http.ResponseWriter, code errcode.Code, message string, httpStatus int, details []errcode.FieldViolation) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(Response{Success: false, Error: message})
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(Response{Success: false, Error: message, Code: string(code), Details: details})
 }
 
 // RegisterRoutes 注册路由
@@ -146,7 +194,63 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	// 需要认证的端点
 	mux.HandleFunc("/api/system", s.securityHeaders(s.authMiddleware(s.handleSystemInfo)))
 	mux.HandleFunc("/api/metrics", s.securityHeaders(s.authMiddleware(s.handleMetrics)))
+	mux.HandleFunc("GET /api/metrics/history", s.securityHeaders(s.authMiddleware(s.handleMetricsHistory)))
+	mux.HandleFunc("GET /metrics", s.securityHeaders(s.authMiddleware(s.handlePrometheus)))
 	mux.HandleFunc("/api/processes", s.securityHeaders(s.authMiddleware(s.handleProcesses)))
+	mux.HandleFunc("GET /api/processes/tree", s.securityHeaders(s.authMiddleware(s.handleProcessTree)))
+	mux.HandleFunc("GET /api/processes/{pid}", s.securityHeaders(s.authMiddleware(s.handleProcessDetail)))
+	mux.HandleFunc("POST /api/processes/{pid}/signal", s.securityHeaders(s.authMiddleware(s.handleProcessSignal)))
+	mux.HandleFunc("GET /api/exec/history", s.securityHeaders(s.authMiddleware(s.handleExecHistory)))
+	mux.HandleFunc("POST /api/batch", s.securityHeaders(s.authMiddleware(s.handleBatch)))
+	mux.HandleFunc("GET /api/files/size", s.securityHeaders(s.authMiddleware(s.handleDirectorySize)))
+	mux.HandleFunc("POST /api/files/{action}", s.securityHeaders(s.authMiddleware(s.handleFilePermissions)))
+	mux.HandleFunc("/api/network", s.securityHeaders(s.authMiddleware(s.handleNetwork)))
+	mux.HandleFunc("GET /api/network/connections", s.securityHeaders(s.authMiddleware(s.handleNetworkConnections)))
+	mux.HandleFunc("GET /api/network/neighbors", s.securityHeaders(s.authMiddleware(s.handleNetworkNeighbors)))
+	mux.HandleFunc("GET /api/logins", s.securityHeaders(s.authMiddleware(s.handleLogins)))
+	mux.HandleFunc("GET /api/docker/containers", s.securityHeaders(s.authMiddleware(s.handleDockerContainers)))
+	mux.HandleFunc("POST /api/docker/containers/{id}/{action}", s.securityHeaders(s.authMiddleware(s.handleDockerAction)))
+	mux.HandleFunc("GET /api/services", s.securityHeaders(s.authMiddleware(s.handleServices)))
+	mux.HandleFunc("POST /api/services/{name}/{action}", s.securityHeaders(s.authMiddleware(s.handleServiceAction)))
+
+	// 插件管理（与 gRPC PluginService 对应）
+	mux.HandleFunc("GET /api/plugins", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleListPlugins))))
+	mux.HandleFunc("GET /api/plugins/available", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleAvailablePlugins))))
+	mux.HandleFunc("GET /api/plugins/search", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleSearchPlugins))))
+	mux.HandleFunc("GET /api/plugins/scheduled-tasks", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleScheduledTasks))))
+	mux.HandleFunc("POST /api/plugins/install", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleInstallPlugin))))
+	mux.HandleFunc("POST /api/plugins/upload", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleUploadPlugin))))
+	mux.HandleFunc("POST /api/plugins/export", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleExportPluginConfigs))))
+	mux.HandleFunc("POST /api/plugins/import", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleImportPluginConfigs))))
+	mux.HandleFunc("GET /api/plugins/{id}", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleGetPlugin))))
+	mux.HandleFunc("GET /api/plugins/{id}/details", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handlePluginDetails))))
+	mux.HandleFunc("GET /api/plugins/{id}/status", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handlePluginStatus))))
+	mux.HandleFunc("GET /api/plugins/{id}/diagnostics", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handlePluginDiagnostics))))
+	mux.HandleFunc("GET /api/plugins/{id}/config", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleGetPluginConfig))))
+	mux.HandleFunc("PUT /api/plugins/{id}/config", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleSetPluginConfig))))
+	mux.HandleFunc("POST /api/plugins/{id}/verify", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handleVerifyPlugin))))
+	mux.HandleFunc("POST /api/plugins/{id}/{action}", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handlePluginAction))))
+	mux.HandleFunc("/api/plugins/{id}/http/{path...}", s.securityHeaders(s.authMiddleware(s.requirePlugins(s.handlePluginHTTP))))
+
+	// 更新管理（与 gRPC UpdateService 对应）
+	mux.HandleFunc("GET /api/update/check", s.securityHeaders(s.authMiddleware(s.requireUpdater(s.handleUpdateCheck))))
+	mux.HandleFunc("GET /api/update/download", s.securityHeaders(s.authMiddleware(s.requireUpdater(s.handleUpdateProgress))))
+	mux.HandleFunc("POST /api/update/apply", s.securityHeaders(s.authMiddleware(s.requireUpdater(s.handleUpdateApply))))
+	mux.HandleFunc("GET /api/update/history", s.securityHeaders(s.authMiddleware(s.requireUpdater(s.handleUpdateHistory))))
+	mux.HandleFunc("GET /api/update/config", s.securityHeaders(s.authMiddleware(s.requireUpdater(s.handleGetUpdateConfig))))
+	mux.HandleFunc("PUT /api/update/config", s.securityHeaders(s.authMiddleware(s.requireUpdater(s.handleSetUpdateConfig))))
+
+	// webhook 订阅
+	mux.HandleFunc("GET /api/webhooks", s.securityHeaders(s.authMiddleware(s.requireWebhooks(s.handleListWebhooks))))
+	mux.HandleFunc("POST /api/webhooks", s.securityHeaders(s.authMiddleware(s.requireWebhooks(s.handleCreateWebhook))))
+	mux.HandleFunc("GET /api/webhooks/{id}", s.securityHeaders(s.authMiddleware(s.requireWebhooks(s.handleGetWebhook))))
+	mux.HandleFunc("PATCH /api/webhooks/{id}", s.securityHeaders(s.authMiddleware(s.requireWebhooks(s.handleUpdateWebhook))))
+	mux.HandleFunc("DELETE /api/webhooks/{id}", s.securityHeaders(s.authMiddleware(s.requireWebhooks(s.handleDeleteWebhook))))
+	mux.HandleFunc("POST /api/webhooks/{id}/test", s.securityHeaders(s.authMiddleware(s.requireWebhooks(s.handleTestWebhook))))
+
+	if s.dashboard {
+		s.registerDashboard(mux)
+	}
 }
 
 // handleHealth 健康检查
@@ -159,27 +263,27 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleVersion 版本信息
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
-	s.jsonResponse(w, map[string]string{
+	s.jsonResponseETag(w, r, map[string]string{
 		"version": s.version,
 		"name":    "Runixo Agent",
-	})
+	}, "")
 }
 
 // handleSystemInfo 系统信息
 func (s *Server) handleSystemInfo(w http.ResponseWriter, r *http.Request) {
 	info, err := s.collector.GetSystemInfo()
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to get system info: %v", err), http.StatusInternalServerError)
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get system info: %v", err), err, http.StatusInternalServerError)
 		return
 	}
-	s.jsonResponse(w, info)
+	s.jsonResponseETag(w, r, info, systemInfoETag(info))
 }
 
 // handleMetrics 监控指标
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	metrics, err := s.collector.GetMetrics()
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to get metrics: %v", err), http.StatusInternalServerError)
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get metrics: %v", err), err, http.StatusInternalServerError)
 		return
 	}
 	s.jsonResponse(w, metrics)
@@ -189,8 +293,128 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleProcesses(w http.ResponseWriter, r *http.Request) {
 	processes, err := s.collector.ListProcesses()
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to list processes: %v", err), http.StatusInternalServerError)
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to list processes: %v", err), err, http.StatusInternalServerError)
 		return
 	}
 	s.jsonResponse(w, processes)
 }
+
+// handleProcessTree 进程树（?aggregate=true 时累加子孙进程的 CPU / 内存）
+func (s *Server) handleProcessTree(w http.ResponseWriter, r *http.Request) {
+	aggregate, _ := strconv.ParseBool(r.URL.Query().Get("aggregate"))
+	tree, err := s.collector.GetProcessTree(aggregate)
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to build process tree: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, tree)
+}
+
+// handleProcessDetail 单个进程详情（?env=full 返回完整环境变量，?env=none 不返回）
+func (s *Server) handleProcessDetail(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.ParseInt(r.PathValue("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.jsonError(w, "Invalid pid", http.StatusBadRequest)
+		return
+	}
+
+	envMode := collector.EnvRedacted
+	switch r.URL.Query().Get("env") {
+	case "full":
+		envMode = collector.EnvFull
+	case "none":
+		envMode = collector.EnvNone
+	}
+
+	detail, err := s.collector.GetProcessDetail(int32(pid), envMode)
+	if errors.Is(err, collector.ErrProcessNotFound) {
+		s.jsonErrorCode(w, errcode.ProcessNotFound, "Process not found")
+		return
+	}
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get process: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, detail)
+}
+
+// handleNetwork 网卡列表（地址、链路状态、速率、收发计数）
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	interfaces, err := s.collector.GetNetworkInterfaces()
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get network interfaces: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, interfaces)
+}
+
+// handleNetworkConnections 监听端口（含所属进程）和 TCP 连接状态统计
+func (s *Server) handleNetworkConnections(w http.ResponseWriter, r *http.Request) {
+	conns, err := s.collector.GetNetworkConnections()
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get network connections: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, conns)
+}
+
+// handleLogins 当前会话与登录历史（?limit= 每类记录数，默认 50，最多 500）
+func (s *Server) handleLogins(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			s.jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	logins, err := s.collector.GetLogins(limit)
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get logins: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, logins)
+}
+
+// handleNetworkNeighbors ARP / NDP 邻居表
+func (s *Server) handleNetworkNeighbors(w http.ResponseWriter, r *http.Request) {
+	neighbors, err := s.collector.GetNeighbors()
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get neighbors: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, neighbors)
+}
+
+// handleDockerContainers 容器列表（?all=true 包含已停止容器，?stats=false 跳过资源采集）
+func (s *Server) handleDockerContainers(w http.ResponseWriter, r *http.Request) {
+	all := r.URL.Query().Get("all") == "true"
+	withStats := r.URL.Query().Get("stats") != "false"
+
+	containers, err := s.docker.ListContainers(r.Context(), all, withStats)
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to list containers: %v", err), err, http.StatusBadGateway)
+		return
+	}
+	s.jsonResponse(w, containers)
+}
+
+// handleDockerAction 容器操作（start / stop / restart）
+func (s *Server) handleDockerAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	action := r.PathValue("action")
+
+	switch action {
+	case "start", "stop", "restart":
+	default:
+		s.jsonError(w, fmt.Sprintf("Unsupported action: %s", action), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.docker.ContainerAction(r.Context(), id, action); err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to %s container: %v", action, err), err, http.StatusBadGateway)
+		return
+	}
+	s.jsonResponse(w, map[string]string{"id": id, "action": action})
+}
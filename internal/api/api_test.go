@@ -0,0 +1,214 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/runixo/agent/internal/collector"
+)
+
+func TestVersionETag(t *testing.T) {
+	s := NewServer("test-token", "v1.0.0")
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", rec.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/version", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", rec.Code)
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	cases := []struct {
+		header, etag string
+		want         bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`W/"abc"`, `"abc"`, true},
+		{`"x", "abc"`, `W/"abc"`, true},
+		{`*`, `"abc"`, true},
+		{`"x"`, `"abc"`, false},
+		{``, `"abc"`, false},
+	}
+	for _, c := range cases {
+		if got := etagMatches(c.header, c.etag); got != c.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", c.header, c.etag, got, c.want)
+		}
+	}
+}
+
+func TestSystemInfoETag(t *testing.T) {
+	info := &collector.SystemInfo{
+		Hostname: "web-1",
+		Uptime:   100,
+		Units:    &collector.UnitSummary{Total: 10, Active: 10},
+		Labels:   map[string]string{"env": "prod"},
+	}
+	etag := systemInfoETag(info)
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("systemInfoETag() = %q, want weak ETag", etag)
+	}
+
+	info.Uptime = 200
+	if got := systemInfoETag(info); got != etag {
+		t.Errorf("uptime change altered ETag: %q != %q", got, etag)
+	}
+	if info.Uptime != 200 {
+		t.Error("systemInfoETag() modified the system info")
+	}
+
+	info.Units = &collector.UnitSummary{Total: 10, Active: 9, Failed: 1, FailedUnits: []string{"nginx.service"}}
+	unitsETag := systemInfoETag(info)
+	if unitsETag == etag {
+		t.Error("failed unit change did not alter ETag")
+	}
+	info.Labels["role"] = "db"
+	if got := systemInfoETag(info); got == unitsETag {
+		t.Error("label change did not alter ETag")
+	}
+}
+
+func TestDashboardRoutes(t *testing.T) {
+	s := NewServer("test-token", "v1.0.0")
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/", http.StatusOK},
+		{"/dashboard/app.js", http.StatusOK},
+		{"/dashboard/missing.js", http.StatusNotFound},
+		{"/unknown", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if rec.Code != c.want {
+			t.Errorf("GET %s = %d, want %d", c.path, rec.Code, c.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Header().Get("Content-Security-Policy") == "" {
+		t.Error("dashboard should set Content-Security-Policy")
+	}
+
+	disabled := NewServer("test-token", "v1.0.0")
+	disabled.SetDashboardEnabled(false)
+	mux = http.NewServeMux()
+	disabled.RegisterRoutes(mux)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET / with dashboard disabled = %d, want 404", rec.Code)
+	}
+}
+
+func TestPrometheusFormat(t *testing.T) {
+	p := newPromWriter()
+	writeSystemMetrics(p, &collector.Metrics{
+		CpuUsage:       12.5,
+		ConntrackCount: 100,
+		ConntrackMax:   262144,
+		NetworkMetrics: []*collector.NetworkMetric{
+			{Interface: "eth1", BytesRecv: 20},
+			{Interface: "eth0", BytesRecv: 10, DropIn: 3},
+		},
+		Power: &collector.PowerInfo{OnBattery: true, Batteries: []*collector.Battery{{Name: "BAT0", Percent: 42, Status: "discharging"}}},
+	})
+	out := p.buf.String()
+
+	for _, want := range []string{
+		"# TYPE runixo_cpu_usage_percent gauge\nrunixo_cpu_usage_percent 12.5\n",
+		"runixo_network_receive_bytes_per_second{interface=\"eth0\"} 10\nrunixo_network_receive_bytes_per_second{interface=\"eth1\"} 20\n",
+		"runixo_network_receive_drops_per_second{interface=\"eth0\"} 3\n",
+		"runixo_conntrack_entries 100\n",
+		"runixo_conntrack_max 262144\n",
+		"runixo_power_on_battery 1\n",
+		"runixo_battery_capacity_percent{battery=\"BAT0\"} 42\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "# TYPE runixo_network_receive_bytes_per_second "); n != 1 {
+		t.Errorf("expected one TYPE line per metric, got %d", n)
+	}
+
+	p = newPromWriter()
+	writeCustomMetrics(p, []*collector.Sample{
+		{Source: "b", Name: "queue_depth", Value: 2},
+		{Source: "a", Name: "jobs", Help: "Jobs\nrunning.", Value: 1},
+		{Source: "a", Name: "queue_depth", Labels: map[string]string{"queue": "mail"}, Value: 3},
+		{Source: "plugin_demo", Name: "plugin_demo_runs_total", Type: collector.SampleCounter, Value: 7},
+	})
+	if want := "# TYPE plugin_demo_runs_total counter\nplugin_demo_runs_total{source=\"plugin_demo\"} 7\n"; !strings.Contains(p.buf.String(), want) {
+		t.Errorf("counter output missing %q:\n%s", want, p.buf.String())
+	}
+	if want := "# HELP jobs Jobs\\nrunning.\n# TYPE jobs gauge\njobs{source=\"a\"} 1\n"; !strings.Contains(p.buf.String(), want) {
+		t.Errorf("custom output missing %q:\n%s", want, p.buf.String())
+	}
+	if want := "queue_depth{source=\"b\"} 2\nqueue_depth{source=\"a\",queue=\"mail\"} 3\n"; !strings.Contains(p.buf.String(), want) {
+		t.Errorf("samples of one metric should be contiguous:\n%s", p.buf.String())
+	}
+
+	p = newPromWriter()
+	p.constLabels = sortedLabelPairs(map[string]string{"role": "db", "env": "prod", "interface": "ignored"})
+	p.gauge("runixo_load1", "help", 1)
+	p.gauge("runixo_network_receive_bytes_per_second", "help", 2, "interface", "eth0")
+	for _, want := range []string{
+		`runixo_load1{env="prod",interface="ignored",role="db"} 1`,
+		`runixo_network_receive_bytes_per_second{env="prod",role="db",interface="eth0"} 2`,
+	} {
+		if !strings.Contains(p.buf.String(), want) {
+			t.Errorf("host labels not applied, missing %q:\n%s", want, p.buf.String())
+		}
+	}
+
+	p = newPromWriter()
+	p.gauge("x", "help", 1, "v", "a\"b\\c\nd")
+	if !strings.Contains(p.buf.String(), `x{v="a\"b\\c\nd"} 1`) {
+		t.Errorf("label not escaped: %s", p.buf.String())
+	}
+}
+
+func TestPrometheusRequiresAuth(t *testing.T) {
+	s := NewServer("test-token", "v1.0.0")
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /metrics without token = %d, want 401", rec.Code)
+	}
+}
+
+func TestFilesystemMetrics(t *testing.T) {
+	p := newPromWriter()
+	writeFilesystemMetrics(p, []*collector.DiskInfo{
+		{Device: "/dev/sdb1", Mountpoint: "/var", Fstype: "ext4", InodesTotal: 100, InodesUsed: 99},
+		{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Total: 1000},
+	})
+	out := p.buf.String()
+	want := `runixo_filesystem_inodes_used{mountpoint="/",device="/dev/sda1",fstype="ext4"} 0` + "\n" +
+		`runixo_filesystem_inodes_used{mountpoint="/var",device="/dev/sdb1",fstype="ext4"} 99` + "\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("output missing %q:\n%s", want, out)
+	}
+}
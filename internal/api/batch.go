@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/runixo/agent/internal/collector"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// maxBatchQueries 单次批量请求最多包含的查询数
+const maxBatchQueries = 20
+
+// batchQuery 批量请求中的单个查询
+type batchQuery struct {
+	ID       string `json:"id"`       // 结果键，默认与 resource 相同
+	Resource string `json:"resource"` // system / metrics / disks / network / connections / processes / top_processes
+	Limit    int    `json:"limit"`    // top_processes: 返回数量，默认 10
+	SortBy   string `json:"sort_by"`  // top_processes: cpu（默认）/ memory
+}
+
+// batchRequest 批量请求
+type batchRequest struct {
+	Queries []batchQuery `json:"queries"`
+}
+
+// handleBatch 在一次请求中返回多个资源，结果以 id 为键，单个查询失败不影响其他查询
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Queries) == 0 {
+		s.jsonError(w, "No queries", http.StatusBadRequest)
+		return
+	}
+	if len(req.Queries) > maxBatchQueries {
+		s.jsonError(w, fmt.Sprintf("Too many queries (max %d)", maxBatchQueries), http.StatusBadRequest)
+		return
+	}
+
+	results := make(map[string]Response, len(req.Queries))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, q := range req.Queries {
+		id := q.ID
+		if id == "" {
+			id = q.Resource
+		}
+		wg.Add(1)
+		go func(id string, q batchQuery) {
+			defer wg.Done()
+			data, err := s.runBatchQuery(q)
+			resp := Response{Success: err == nil, Data: data}
+			if err != nil {
+				resp.Error = err.Error()
+				resp.Code = string(errcode.Of(err))
+			}
+			mu.Lock()
+			results[id] = resp
+			mu.Unlock()
+		}(id, q)
+	}
+	wg.Wait()
+
+	s.jsonResponse(w, results)
+}
+
+// runBatchQuery 执行单个查询
+func (s *Server) runBatchQuery(q batchQuery) (interface{}, error) {
+	switch q.Resource {
+	case "system":
+		return s.collector.GetSystemInfo()
+	case "metrics":
+		return s.collector.GetMetrics()
+	case "disks":
+		return s.collector.GetDisks()
+	case "network":
+		return s.collector.GetNetworkInterfaces()
+	case "connections":
+		return s.collector.GetNetworkConnections()
+	case "processes":
+		return s.collector.ListProcesses()
+	case "top_processes":
+		processes, err := s.collector.ListProcesses()
+		if err != nil {
+			return nil, err
+		}
+		if !collector.SortProcesses(processes, q.SortBy) {
+			return nil, errcode.New(errcode.InvalidArgument, "unsupported sort_by: %s", q.SortBy)
+		}
+		limit := q.Limit
+		if limit <= 0 {
+			limit = 10
+		}
+		if limit < len(processes) {
+			processes = processes[:limit]
+		}
+		return processes, nil
+	default:
+		return nil, errcode.New(errcode.InvalidArgument, "unknown resource: %s", q.Resource)
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// dashboardFS 内置面板静态资源，面板本身只调用现有的 REST API
+//
+//go:embed dashboard
+var dashboardFS embed.FS
+
+// SetDashboardEnabled 设置是否在 / 提供内置面板（需在 RegisterRoutes 之前调用）
+func (s *Server) SetDashboardEnabled(enabled bool) {
+	s.dashboard = enabled
+}
+
+// registerDashboard 注册面板路由，静态资源无需认证，数据接口仍需令牌
+func (s *Server) registerDashboard(mux *http.ServeMux) {
+	assets, err := fs.Sub(dashboardFS, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/dashboard/", http.FileServerFS(assets))
+
+	mux.HandleFunc("GET /{$}", s.dashboardHeaders(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, assets, "index.html")
+	}))
+	mux.HandleFunc("GET /dashboard/", s.dashboardHeaders(files.ServeHTTP))
+}
+
+// dashboardHeaders 面板页面的安全响应头，禁止加载外部脚本和被嵌入
+func (s *Server) dashboardHeaders(next http.HandlerFunc) http.HandlerFunc {
+	return s.securityHeaders(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		next(w, r)
+	})
+}
@@ -0,0 +1,255 @@
+// Runixo Agent 内置面板：仅使用现有 REST API，令牌保存在 sessionStorage
+(function () {
+  'use strict';
+
+  var TOKEN_KEY = 'runixo-token';
+  var timers = [];
+
+  function $(id) { return document.getElementById(id); }
+
+  function token() { return sessionStorage.getItem(TOKEN_KEY) || ''; }
+
+  function api(path) {
+    return fetch(path, { headers: { Authorization: 'Bearer ' + token() } }).then(function (resp) {
+      return resp.json().catch(function () { return {}; }).then(function (body) {
+        if (resp.status === 401 || body.code === 'AUTH_INVALID' || body.code === 'AUTH_REQUIRED') {
+          logout('令牌无效');
+          throw new Error('unauthorized');
+        }
+        if (!body.success) {
+          throw new Error(body.error || resp.statusText);
+        }
+        return body.data;
+      });
+    });
+  }
+
+  function formatBytes(n) {
+    var units = ['B', 'KB', 'MB', 'GB', 'TB'];
+    var i = 0;
+    while (n >= 1024 && i < units.length - 1) { n /= 1024; i++; }
+    return n.toFixed(i === 0 ? 0 : 1) + ' ' + units[i];
+  }
+
+  function formatUptime(sec) {
+    var d = Math.floor(sec / 86400), h = Math.floor(sec % 86400 / 3600), m = Math.floor(sec % 3600 / 60);
+    return '运行 ' + (d ? d + ' 天 ' : '') + h + ' 小时 ' + m + ' 分';
+  }
+
+  function setBar(id, percent) {
+    var bar = $(id);
+    bar.style.width = Math.min(100, percent).toFixed(1) + '%';
+    bar.className = percent >= 90 ? 'crit' : percent >= 70 ? 'warn' : '';
+  }
+
+  function row(cells, className) {
+    var tr = document.createElement('tr');
+    cells.forEach(function (text, i) {
+      var td = document.createElement('td');
+      td.textContent = text;
+      if (className && i === cells.length - 1) td.className = className;
+      tr.appendChild(td);
+    });
+    return tr;
+  }
+
+  function fill(tbodyId, rows) {
+    var tbody = $(tbodyId);
+    tbody.textContent = '';
+    rows.forEach(function (tr) { tbody.appendChild(tr); });
+  }
+
+  function status(text, isError) {
+    var el = $('status');
+    el.textContent = text;
+    el.className = isError ? 'error' : 'muted';
+  }
+
+  function loadSystem() {
+    return api('/api/system').then(function (info) {
+      var os = info.Distro && info.Distro.PrettyName ? info.Distro.PrettyName : info.Platform + ' ' + info.PlatformVersion;
+      var pkgs = info.Packages;
+      var pub = info.PublicIP;
+      $('host').textContent = info.Hostname + ' · ' + os + ' (' + info.Arch + ') · 内核 ' + info.KernelVersion +
+        (pkgs && pkgs.Updates > 0 ? ' · ' + pkgs.Updates + ' 个待更新' + (pkgs.SecurityUpdates > 0 ? '（安全更新 ' + pkgs.SecurityUpdates + '）' : '') : '') +
+        (pub ? ' · 公网 ' + [pub.IPv4, pub.IPv6].filter(Boolean).join(' / ') + (pub.ASN ? '（' + pub.ASN + (pub.Org ? ' ' + pub.Org : '') + '）' : '') : '') +
+        Object.keys(info.Labels || {}).sort().map(function (k) { return ' · ' + k + '=' + info.Labels[k]; }).join('');
+      $('uptime').textContent = formatUptime(info.Uptime);
+      var clock = info.Clock;
+      $('clock').textContent = clock
+        ? (clock.Synchronized ? '时钟已同步' : '时钟未同步') + '，偏差 ' + (clock.Offset * 1000).toFixed(1) + ' ms'
+        : '';
+      $('clock').className = clock && (!clock.Synchronized || Math.abs(clock.Offset) >= 1) ? 'crit' : 'muted';
+      fill('disks', (info.Disks || []).map(function (d) {
+        var tr = row([d.Mountpoint, d.Device, d.Fstype, formatBytes(d.Total),
+          d.UsedPercent.toFixed(1), d.InodesTotal ? d.InodesUsedPercent.toFixed(1) : '-']);
+        [[4, d.UsedPercent], [5, d.InodesUsedPercent]].forEach(function (c) {
+          tr.children[c[0]].className = c[1] >= 90 ? 'crit' : c[1] >= 80 ? 'warn' : '';
+        });
+        return tr;
+      }));
+    });
+  }
+
+  function loadMetrics() {
+    return api('/api/metrics').then(function (m) {
+      $('cpu').textContent = m.CpuUsage.toFixed(1) + '%';
+      $('mem').textContent = m.MemoryUsage.toFixed(1) + '%';
+      setBar('cpu-bar', m.CpuUsage);
+      setBar('mem-bar', m.MemoryUsage);
+      $('swap').textContent = m.SwapTotal
+        ? '交换 ' + formatBytes(m.SwapUsed) + ' / ' + formatBytes(m.SwapTotal) +
+          (m.SwapInRate || m.SwapOutRate ? '（换入 ' + formatBytes(m.SwapInRate) + '/s，换出 ' + formatBytes(m.SwapOutRate) + '/s）' : '')
+        : '';
+      $('load').textContent = [m.Load1, m.Load5, m.Load15].map(function (v) { return v.toFixed(2); }).join(' / ');
+      var sent = 0, recv = 0;
+      (m.NetworkMetrics || []).forEach(function (n) { sent += n.BytesSent; recv += n.BytesRecv; });
+      $('net').textContent = '↑ ' + formatBytes(sent) + '/s  ↓ ' + formatBytes(recv) + '/s';
+      var conntrack = m.ConntrackMax ? m.ConntrackCount / m.ConntrackMax * 100 : 0;
+      $('conntrack').textContent = m.ConntrackMax
+        ? '连接跟踪 ' + m.ConntrackCount + ' / ' + m.ConntrackMax + '（' + conntrack.toFixed(1) + '%）'
+        : '';
+      $('conntrack').className = conntrack >= 90 ? 'crit' : 'muted';
+      var power = m.Power;
+      var battery = power && power.Batteries && power.Batteries[0];
+      $('power').textContent = power
+        ? (power.OnBattery ? '电池供电' : '外部电源') +
+          (battery ? '，电量 ' + battery.Percent.toFixed(0) + '%' + (battery.Status === 'charging' ? '（充电中）' : '') : '')
+        : '';
+      $('power').className = power && power.OnBattery && battery && battery.Percent <= 20 ? 'crit' : 'muted';
+      var units = m.Units;
+      $('units-card').hidden = !units;
+      if (units) {
+        $('units').textContent = units.Active + ' 运行 / ' + units.Failed + ' 失败';
+        $('units').className = 'value small' + (units.Failed ? ' error' : '');
+        $('failed-units').textContent = (units.FailedUnits || []).join(', ');
+      }
+      status('更新于 ' + new Date().toLocaleTimeString());
+    }).catch(function (err) { status('获取指标失败: ' + err.message, true); });
+  }
+
+  function loadProcesses() {
+    return api('/api/processes').then(function (list) {
+      list.sort(function (a, b) { return b.CpuPercent - a.CpuPercent; });
+      fill('processes', list.slice(0, 15).map(function (p) {
+        return row([p.Pid, p.Name, p.User, p.CpuPercent.toFixed(1), p.MemoryPercent.toFixed(1), formatBytes(p.MemoryRss)]);
+      }));
+    }).catch(function () {});
+  }
+
+  // drawHistory 在 canvas 上绘制 CPU / 内存折线（0-100%）
+  function drawHistory(points) {
+    var canvas = $('history');
+    var ratio = window.devicePixelRatio || 1;
+    var width = canvas.clientWidth, height = canvas.clientHeight;
+    canvas.width = width * ratio;
+    canvas.height = height * ratio;
+    var ctx = canvas.getContext('2d');
+    ctx.scale(ratio, ratio);
+    ctx.clearRect(0, 0, width, height);
+
+    ctx.strokeStyle = '#eaeef2';
+    ctx.lineWidth = 1;
+    [0.25, 0.5, 0.75].forEach(function (f) {
+      ctx.beginPath();
+      ctx.moveTo(0, height * f);
+      ctx.lineTo(width, height * f);
+      ctx.stroke();
+    });
+
+    if (points.length < 2) return;
+    var t0 = points[0].Timestamp, span = points[points.length - 1].Timestamp - t0 || 1;
+    [['CpuUsage', '#0969da'], ['MemoryUsage', '#8250df']].forEach(function (series) {
+      ctx.strokeStyle = series[1];
+      ctx.lineWidth = 1.5;
+      ctx.beginPath();
+      points.forEach(function (p, i) {
+        var x = (p.Timestamp - t0) / span * width;
+        var y = height - Math.min(100, p[series[0]]) / 100 * (height - 4) - 2;
+        if (i === 0) ctx.moveTo(x, y); else ctx.lineTo(x, y);
+      });
+      ctx.stroke();
+    });
+  }
+
+  function loadHistory() {
+    return api('/api/metrics/history?range=1h').then(function (res) {
+      var points = res.points || [];
+      $('history-empty').hidden = points.length >= 2;
+      drawHistory(points);
+    }).catch(function () {
+      $('history-empty').hidden = false;
+      drawHistory([]);
+    });
+  }
+
+  function loadPlugins() {
+    return api('/api/plugins').then(function (list) {
+      if (!list.length) {
+        fill('plugins', [row(['未安装插件', '', ''])]);
+        return;
+      }
+      fill('plugins', list.map(function (p) {
+        return row([p.manifest.name || p.manifest.id, p.manifest.version, p.state], 'state-' + p.state);
+      }));
+    }).catch(function (err) { fill('plugins', [row([err.message, '', ''])]); });
+  }
+
+  function loadUpdate() {
+    return api('/api/update/check').then(function (info) {
+      $('update').textContent = info.available
+        ? '有可用更新：' + info.current_version + ' → ' + info.latest_version
+        : '已是最新版本（' + info.current_version + '）';
+    }).catch(function (err) { $('update').textContent = '检查更新失败: ' + err.message; });
+  }
+
+  function start() {
+    $('login').hidden = true;
+    $('app').hidden = false;
+    $('logout').hidden = false;
+
+    loadSystem().then(function () {
+      loadMetrics();
+      loadProcesses();
+      loadHistory();
+      loadPlugins();
+      loadUpdate();
+      timers.push(setInterval(loadMetrics, 2000));
+      timers.push(setInterval(loadProcesses, 5000));
+      timers.push(setInterval(loadHistory, 60000));
+      timers.push(setInterval(loadSystem, 60000));
+      timers.push(setInterval(loadPlugins, 30000));
+      timers.push(setInterval(loadUpdate, 600000));
+    }).catch(function (err) {
+      if (err.message !== 'unauthorized') status('连接失败: ' + err.message, true);
+    });
+  }
+
+  function logout(message) {
+    timers.forEach(clearInterval);
+    timers = [];
+    sessionStorage.removeItem(TOKEN_KEY);
+    $('app').hidden = true;
+    $('logout').hidden = true;
+    $('login').hidden = false;
+    $('login-error').textContent = message || '';
+  }
+
+  $('login-form').addEventListener('submit', function (e) {
+    e.preventDefault();
+    sessionStorage.setItem(TOKEN_KEY, $('token').value.trim());
+    $('token').value = '';
+    start();
+  });
+  $('logout').addEventListener('click', function () { logout(); });
+
+  fetch('/api/version').then(function (r) { return r.json(); }).then(function (body) {
+    if (body.success) $('version').textContent = body.data.version;
+  }).catch(function () {});
+
+  if (token()) {
+    start();
+  } else {
+    logout();
+  }
+})();
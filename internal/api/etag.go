@@ -0,0 +1,67 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/runixo/agent/internal/collector"
+)
+
+// computeETag 对任意值的 JSON 表示计算 ETag
+func computeETag(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches 按弱比较规则检查 If-None-Match 是否命中
+func etagMatches(header, etag string) bool {
+	if header == "" || etag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonResponseETag 发送带 ETag 的 JSON 响应，客户端缓存仍有效时返回 304
+// etag 为空时按响应内容计算
+func (s *Server) jsonResponseETag(w http.ResponseWriter, r *http.Request, data interface{}, etag string) {
+	if etag == "" {
+		etag = computeETag(data)
+	}
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	s.jsonResponse(w, data)
+}
+
+// systemInfoETag 系统信息的弱 ETag
+// 覆盖除 uptime 外的全部字段，服务状态、登录会话、时钟同步、待更新软件包、标签等变化时 ETag 随之变化
+func systemInfoETag(info *collector.SystemInfo) string {
+	fp := *info
+	fp.Uptime = 0
+	etag := computeETag(&fp)
+	if etag == "" {
+		return ""
+	}
+	return "W/" + etag
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/runixo/agent/internal/executor"
+)
+
+// permissionRequest 权限变更请求，mode / owner / entries 按操作取用
+type permissionRequest struct {
+	Path      string   `json:"path"`
+	Mode      string   `json:"mode"`    // chmod：755 或 u+x,go-w
+	Owner     string   `json:"owner"`   // chown：user、user:group 或 :group
+	Entries   []string `json:"entries"` // acl：setfacl 条目，如 u:www-data:rwx
+	Remove    bool     `json:"remove"`  // acl：删除条目
+	Recursive bool     `json:"recursive"`
+	DryRun    bool     `json:"dry_run"`
+}
+
+// handleFilePermissions 修改文件模式、属主或 ACL（POST /api/files/{action}）
+func (s *Server) handleFilePermissions(w http.ResponseWriter, r *http.Request) {
+	var req permissionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	opts := executor.PermissionOptions{Recursive: req.Recursive, DryRun: req.DryRun}
+	var result *executor.PermissionResult
+	var err error
+	action := r.PathValue("action")
+	switch action {
+	case "chmod":
+		result, err = executor.Chmod(r.Context(), req.Path, req.Mode, opts)
+	case "chown":
+		result, err = executor.Chown(r.Context(), req.Path, req.Owner, opts)
+	case "acl":
+		result, err = executor.SetACL(r.Context(), req.Path, req.Entries, req.Remove, opts)
+	default:
+		s.jsonError(w, fmt.Sprintf("Unsupported action: %s", action), http.StatusNotFound)
+		return
+	}
+
+	if s.audit != nil && !req.DryRun {
+		s.audit.LogFileOp(r.RemoteAddr, action, req.Path, err == nil && len(result.Errors) == 0)
+	}
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to %s %s: %v", action, req.Path, err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, result)
+}
+
+// handleDirectorySize 目录占用统计（GET /api/files/size?path=&depth=&top=&timeout=）
+func (s *Server) handleDirectorySize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := executor.DirSizeOptions{}
+	opts.Depth, _ = strconv.Atoi(q.Get("depth"))
+	opts.Top, _ = strconv.Atoi(q.Get("top"))
+	opts.MaxEntries, _ = strconv.ParseInt(q.Get("max_entries"), 10, 64)
+	opts.CrossMounts, _ = strconv.ParseBool(q.Get("cross_mounts"))
+
+	timeout := 60 * time.Second
+	if sec, err := strconv.Atoi(q.Get("timeout")); err == nil && sec > 0 {
+		timeout = time.Duration(sec) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	path := q.Get("path")
+	root, truncated, err := executor.GetDirectorySize(ctx, path, opts)
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to get size of %s: %v", path, err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, map[string]interface{}{
+		"root":      root,
+		"truncated": truncated,
+	})
+}
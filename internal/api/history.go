@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/collector"
+)
+
+// maxHistoryPoints 单次查询最多返回的点数，未指定 step 时据此自动降采样
+const maxHistoryPoints = 1000
+
+// SetHistory 设置指标历史存储（未设置时历史接口返回 503）
+func (s *Server) SetHistory(h *collector.HistoryStore) {
+	s.history = h
+}
+
+// historyResponse 历史查询结果
+type historyResponse struct {
+	From   int64                    `json:"from"`
+	To     int64                    `json:"to"`
+	Step   int64                    `json:"step"` // 秒
+	Points []collector.HistoryPoint `json:"points"`
+}
+
+// handleMetricsHistory 指标历史
+// ?range=1h（默认，支持 d 后缀）或 ?from=&to=（Unix 秒），?step=5m 指定降采样粒度
+func (s *Server) handleMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	if s.history == nil {
+		s.jsonError(w, "Metrics history not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	to := time.Now()
+	if v := q.Get("to"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			s.jsonError(w, "Invalid to", http.StatusBadRequest)
+			return
+		}
+		to = time.Unix(sec, 0)
+	}
+
+	from := to.Add(-time.Hour)
+	if v := q.Get("from"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			s.jsonError(w, "Invalid from", http.StatusBadRequest)
+			return
+		}
+		from = time.Unix(sec, 0)
+	} else if v := q.Get("range"); v != "" {
+		d, err := collector.ParseRetention(v)
+		if err != nil {
+			s.jsonError(w, "Invalid range", http.StatusBadRequest)
+			return
+		}
+		from = to.Add(-d)
+	}
+	if !from.Before(to) {
+		s.jsonError(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	resolution := s.history.Config().Resolution
+	step := resolution
+	if v := q.Get("step"); v != "" {
+		d, err := collector.ParseRetention(v)
+		if err != nil {
+			s.jsonError(w, "Invalid step", http.StatusBadRequest)
+			return
+		}
+		step = d
+	}
+	// 防止一次返回过多数据
+	if minStep := to.Sub(from) / maxHistoryPoints; step < minStep {
+		step = minStep.Truncate(resolution) + resolution
+	}
+
+	s.jsonResponse(w, historyResponse{
+		From:   from.Unix(),
+		To:     to.Unix(),
+		Step:   int64(step / time.Second),
+		Points: s.history.Query(from, to, step),
+	})
+}
+
+// handleExecHistory 审计日志中的命令执行记录，按时间倒序
+// ?since=&until=（Unix 秒）、?token=、?command=、?limit=（默认 100，最大 1000）
+func (s *Server) handleExecHistory(w http.ResponseWriter, r *http.Request) {
+	if s.audit == nil {
+		s.jsonError(w, "Audit log not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	query := audit.ExecQuery{TokenID: q.Get("token"), Command: q.Get("command")}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &query.Since}, {"until", &query.Until}} {
+		if v := q.Get(p.name); v != "" {
+			sec, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				s.jsonError(w, "Invalid "+p.name, http.StatusBadRequest)
+				return
+			}
+			*p.dst = time.Unix(sec, 0)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			s.jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = n
+	}
+
+	records, truncated, err := s.audit.ExecHistory(query)
+	if err != nil {
+		s.jsonError(w, "Failed to read audit log", http.StatusInternalServerError)
+		return
+	}
+	if records == nil {
+		records = []audit.ExecRecord{}
+	}
+	s.jsonResponse(w, map[string]interface{}{
+		"records":   records,
+		"truncated": truncated,
+	})
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/plugin"
+	"github.com/runixo/agent/internal/scheduler"
+	"github.com/runixo/agent/internal/security"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+// maxPluginUploadSize 通过 REST 上传插件包的大小上限
+const maxPluginUploadSize = 100 * 1024 * 1024
+
+var validPluginID = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// SetPluginManager 设置插件管理器（未设置时插件接口返回 503）
+func (s *Server) SetPluginManager(m *plugin.Manager) {
+	s.plugins = m
+}
+
+// installPluginRequest 安装插件请求
+type installPluginRequest struct {
+	PluginID  string `json:"plugin_id"`
+	Source    string `json:"source"` // official / url / local
+	URL       string `json:"url"`
+	Data      []byte `json:"data"`      // base64 编码的 tar.gz（source=local）
+	Signature string `json:"signature"` // base64 编码的 Ed25519 签名（source=local 必填，source=url 时默认下载 <url>.sig）
+	SHA256    string `json:"sha256"`    // 插件包的 SHA-256（十六进制），不为空时必须一致
+}
+
+// requirePlugins 检查插件管理器是否可用，并校验路径中的插件 ID
+func (s *Server) requirePlugins(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.plugins == nil {
+			s.jsonError(w, "Plugin manager not available", http.StatusServiceUnavailable)
+			return
+		}
+		if id := r.PathValue("id"); id != "" && !validPluginID.MatchString(id) {
+			s.jsonError(w, "Invalid plugin ID", http.StatusBadRequest)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleListPlugins 已安装插件列表
+func (s *Server) handleListPlugins(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponseETag(w, r, s.plugins.ListPlugins(), "")
+}
+
+// handleAvailablePlugins 可安装插件列表
+func (s *Server) handleAvailablePlugins(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponseETag(w, r, s.plugins.AvailablePlugins(r.Context()), "")
+}
+
+// handleSearchPlugins 搜索插件市场（?q=&category=&tag=&tag=&page=&page_size=）
+func (s *Server) handleSearchPlugins(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := plugin.SearchQuery{
+		Query:    query.Get("q"),
+		Category: query.Get("category"),
+		Tags:     query["tag"],
+	}
+	for name, dst := range map[string]*int{"page": &q.Page, "page_size": &q.PageSize} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				s.jsonError(w, "Invalid "+name, http.StatusBadRequest)
+				return
+			}
+			*dst = n
+		}
+	}
+	s.jsonResponse(w, s.plugins.SearchPlugins(r.Context(), q))
+}
+
+// handleScheduledTasks 定时任务列表，可按 plugin_id 过滤
+func (s *Server) handleScheduledTasks(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("plugin_id")
+	if id != "" && !validPluginID.MatchString(id) {
+		s.jsonError(w, "Invalid plugin ID", http.StatusBadRequest)
+		return
+	}
+	tasks := s.plugins.ScheduledTasks(id)
+	if tasks == nil {
+		tasks = []scheduler.TaskInfo{}
+	}
+	s.jsonResponse(w, tasks)
+}
+
+// handlePluginDetails 插件市场中的插件详情
+func (s *Server) handlePluginDetails(w http.ResponseWriter, r *http.Request) {
+	details, err := s.plugins.PluginDetails(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponseETag(w, r, details, "")
+}
+
+// handleGetPlugin 插件详情
+func (s *Server) handleGetPlugin(w http.ResponseWriter, r *http.Request) {
+	p := s.plugins.GetPlugin(r.PathValue("id"))
+	if p == nil {
+		s.jsonErrorCode(w, errcode.PluginNotFound, "Plugin not found")
+		return
+	}
+	s.jsonResponse(w, p)
+}
+
+// handlePluginStatus 插件运行状态
+func (s *Server) handlePluginStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.plugins.GetPluginStatus(r.PathValue("id"))
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, status)
+}
+
+// handleVerifyPlugin 试运行插件而不启用，返回各步骤的结果
+func (s *Server) handleVerifyPlugin(w http.ResponseWriter, r *http.Request) {
+	result, err := s.plugins.VerifyPlugin(r.PathValue("id"))
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, result)
+}
+
+// handlePluginDiagnostics 插件最近一次进入错误状态时采集的诊断信息
+func (s *Server) handlePluginDiagnostics(w http.ResponseWriter, r *http.Request) {
+	diag, err := s.plugins.GetPluginDiagnostics(r.PathValue("id"))
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, diag)
+}
+
+// handlePluginHTTP 把 /api/plugins/{id}/http/ 下的请求转发给插件，Agent 的认证信息不转发
+// 插件设置的响应头不能覆盖 Agent 的安全响应头
+func (s *Server) handlePluginHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, pluginsdk.MaxHTTPRequestBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.jsonError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	headers := make(map[string]string, len(r.Header)+1)
+	for k, v := range r.Header {
+		if k != "Authorization" && k != "Cookie" {
+			headers[k] = strings.Join(v, ", ")
+		}
+	}
+	headers["Host"] = r.Host
+
+	resp, err := s.plugins.ServePluginHTTP(r.Context(), r.PathValue("id"), pluginsdk.HTTPRequest{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.RawQuery,
+		Headers:    headers,
+		Body:       body,
+		RemoteAddr: r.RemoteAddr,
+	})
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadGateway)
+		return
+	}
+	if resp.StatusCode < 100 || resp.StatusCode > 599 {
+		s.jsonError(w, fmt.Sprintf("Invalid plugin response status %d", resp.StatusCode), http.StatusBadGateway)
+		return
+	}
+
+	for k, v := range resp.Headers {
+		k = http.CanonicalHeaderKey(k)
+		if k == "Content-Length" || k == "Connection" || k == "Transfer-Encoding" || w.Header().Get(k) != "" {
+			continue
+		}
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(resp.Body)
+}
+
+// handleInstallPlugin 安装插件
+func (s *Server) handleInstallPlugin(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPluginUploadSize)
+
+	var req installPluginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if !validPluginID.MatchString(req.PluginID) {
+		s.jsonError(w, "Invalid plugin ID", http.StatusBadRequest)
+		return
+	}
+
+	// SSRF 防护：与 gRPC 接口保持一致
+	if req.URL != "" {
+		if err := security.CheckURL(req.URL); err != nil {
+			s.jsonErrorFrom(w, fmt.Sprintf("Plugin URL rejected: %v", err), err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := s.plugins.InstallPlugin(req.PluginID, plugin.InstallRequest{
+		Source:    req.Source,
+		URL:       req.URL,
+		Data:      req.Data,
+		Signature: []byte(req.Signature),
+		SHA256:    req.SHA256,
+	})
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, s.plugins.GetPlugin(req.PluginID))
+}
+
+// maxPluginBundleSize 插件配置导出包的大小上限
+const maxPluginBundleSize = 4 * 1024 * 1024
+
+// handleExportPluginConfigs 导出所有已安装插件的启用状态和配置，响应体即导出包
+// 请求体为 {"passphrase": "..."}，可以为空；设置密码时加密导出包并包含敏感配置项
+func (s *Server) handleExportPluginConfigs(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.plugins.ExportConfigs(req.Passphrase)
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="plugin-configs.json"`)
+	w.Write(data)
+}
+
+// importPluginConfigsRequest 导入插件配置请求，bundle 为导出包
+type importPluginConfigsRequest struct {
+	Bundle         json.RawMessage `json:"bundle"`
+	Passphrase     string          `json:"passphrase"`
+	InstallMissing bool            `json:"install_missing"`
+	Plugins        []string        `json:"plugins"`
+}
+
+// handleImportPluginConfigs 导入插件配置导出包，返回每个插件的导入结果
+func (s *Server) handleImportPluginConfigs(w http.ResponseWriter, r *http.Request) {
+	var req importPluginConfigsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPluginBundleSize)).Decode(&req); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Bundle) == 0 {
+		s.jsonError(w, "Missing bundle", http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.plugins.ImportConfigs(req.Bundle, plugin.ImportOptions{
+		Passphrase:     req.Passphrase,
+		InstallMissing: req.InstallMissing,
+		Plugins:        req.Plugins,
+	})
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, results)
+}
+
+// handleUploadPlugin 以原始请求体上传并安装插件包（tar.gz），避免 base64 编码大文件
+// 插件 ID 和 SHA-256 在查询参数 plugin_id、sha256 中，签名在 X-Plugin-Signature 头中
+func (s *Server) handleUploadPlugin(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("plugin_id")
+	if !validPluginID.MatchString(id) {
+		s.jsonError(w, "Invalid plugin ID", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPluginUploadSize)
+	err := s.plugins.InstallPlugin(id, plugin.InstallRequest{
+		Source:    plugin.SourceLocal,
+		Package:   r.Body,
+		Signature: []byte(r.Header.Get("X-Plugin-Signature")),
+		SHA256:    r.URL.Query().Get("sha256"),
+	})
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, s.plugins.GetPlugin(id))
+}
+
+// handlePluginAction 插件操作（uninstall / enable / disable）
+func (s *Server) handlePluginAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	action := r.PathValue("action")
+
+	var err error
+	switch action {
+	case "uninstall":
+		err = s.plugins.UninstallPlugin(id)
+	case "enable":
+		err = s.plugins.EnablePlugin(id)
+	case "disable":
+		err = s.plugins.DisablePlugin(id)
+	default:
+		s.jsonError(w, fmt.Sprintf("Unsupported action: %s", action), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, map[string]string{"plugin_id": id, "action": action})
+}
+
+// handleGetPluginConfig 获取插件配置（values）和清单声明的配置项 schema，敏感配置项为 "********"
+func (s *Server) handleGetPluginConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := s.plugins.GetPluginConfig(r.PathValue("id"))
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, config)
+}
+
+// handleSetPluginConfig 更新插件配置（请求体为完整配置对象），返回保存后的配置
+// 敏感配置项传回 "********" 时保留原值，校验失败时在 details 中返回逐项错误
+func (s *Server) handleSetPluginConfig(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1024*1024)
+
+	var config map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid config: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := s.plugins.SetPluginConfig(id, config); err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	saved, err := s.plugins.GetPluginConfig(id)
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, saved)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/executor"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// SetAuditLogger 设置审计日志记录器
+func (s *Server) SetAuditLogger(l *audit.Logger) {
+	s.audit = l
+}
+
+// signalRequest 发送信号请求
+type signalRequest struct {
+	Signal string `json:"signal"` // 例如 SIGTERM / TERM / 15，默认 SIGTERM
+	Force  bool   `json:"force"`  // 允许操作 PID 1 和 Agent 自身
+}
+
+// handleProcessSignal 向进程发送信号
+func (s *Server) handleProcessSignal(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.Atoi(r.PathValue("pid"))
+	if err != nil || pid <= 0 {
+		s.jsonError(w, "Invalid pid", http.StatusBadRequest)
+		return
+	}
+
+	var req signalRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+			s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	sig, err := executor.ParseSignal(req.Signal)
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+
+	// 记录进程名，便于事后审计
+	var name string
+	if p, err := process.NewProcess(int32(pid)); err == nil {
+		name, _ = p.Name()
+	}
+
+	err = executor.SignalProcess(pid, sig, req.Force)
+	if s.audit != nil {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		s.audit.LogProcessSignal(r.RemoteAddr, pid, name, sig.String(), req.Force, err == nil, msg)
+	}
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"pid":    pid,
+		"name":   name,
+		"signal": sig.String(),
+	})
+}
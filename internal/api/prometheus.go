@@ -0,0 +1,312 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/runixo/agent/internal/collector"
+)
+
+// promWriter 按 Prometheus 文本格式（0.0.4）输出指标，同名指标只写一次 HELP/TYPE
+type promWriter struct {
+	buf  bytes.Buffer
+	seen map[string]bool
+	// constLabels 附加到每个样本的主机标签（key=value 成对），与样本自身标签同名时以样本为准
+	constLabels []string
+}
+
+func newPromWriter() *promWriter {
+	return &promWriter{seen: make(map[string]bool)}
+}
+
+// gauge 写入一个 gauge 样本，labels 按 key=value 成对传入
+func (p *promWriter) gauge(name, help string, value float64, labels ...string) {
+	p.sample(name, help, collector.SampleGauge, value, labels...)
+}
+
+// sample 写入一个指定类型（gauge、counter）的样本，同名指标的类型以第一次写入为准
+func (p *promWriter) sample(name, help, typ string, value float64, labels ...string) {
+	if !p.seen[name] {
+		p.seen[name] = true
+		fmt.Fprintf(&p.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	p.buf.WriteString(name)
+	if len(p.constLabels) > 0 {
+		all := make([]string, 0, len(p.constLabels)+len(labels))
+		for i := 0; i+1 < len(p.constLabels); i += 2 {
+			if !hasLabel(labels, p.constLabels[i]) {
+				all = append(all, p.constLabels[i], p.constLabels[i+1])
+			}
+		}
+		labels = append(all, labels...)
+	}
+	if len(labels) > 0 {
+		p.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				p.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&p.buf, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		p.buf.WriteByte('}')
+	}
+	p.buf.WriteByte(' ')
+	p.buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	p.buf.WriteByte('\n')
+}
+
+func hasLabel(labels []string, name string) bool {
+	for i := 0; i < len(labels); i += 2 {
+		if labels[i] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedLabelPairs 将标签 map 按名称排序展开为 key, value 对
+func sortedLabelPairs(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys)*2)
+	for _, k := range keys {
+		pairs = append(pairs, k, labels[k])
+	}
+	return pairs
+}
+
+// labelEscaper 标签值转义规则（反斜杠、双引号、换行）
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// handlePrometheus 以 Prometheus 文本格式输出当前指标，抓取时使用 bearer_token 认证
+func (s *Server) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	metrics, err := s.collector.GetMetrics()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get metrics: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	p := newPromWriter()
+	p.constLabels = sortedLabelPairs(s.collector.Labels())
+	p.gauge("runixo_agent_info", "Agent version.", 1, "version", s.version)
+	writeSystemMetrics(p, metrics)
+	if disks, err := s.collector.GetDisks(); err == nil {
+		writeFilesystemMetrics(p, disks)
+	}
+	writePackageMetrics(p, s.collector.GetPackageInfo())
+	writeCustomMetrics(p, metrics.Custom)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(p.buf.Bytes())
+}
+
+// promSeries 一组按设备展开的指标定义
+type promSeries[T any] struct {
+	name, help string
+	value      func(T) float64
+}
+
+// networkSeries 每块网卡输出的指标
+var networkSeries = []promSeries[*collector.NetworkMetric]{
+	{"runixo_network_receive_bytes_per_second", "Received bytes per second.", func(n *collector.NetworkMetric) float64 { return float64(n.BytesRecv) }},
+	{"runixo_network_transmit_bytes_per_second", "Transmitted bytes per second.", func(n *collector.NetworkMetric) float64 { return float64(n.BytesSent) }},
+	{"runixo_network_receive_packets_per_second", "Received packets per second.", func(n *collector.NetworkMetric) float64 { return float64(n.PacketsRecv) }},
+	{"runixo_network_transmit_packets_per_second", "Transmitted packets per second.", func(n *collector.NetworkMetric) float64 { return float64(n.PacketsSent) }},
+	{"runixo_network_receive_errors_per_second", "Receive errors per second.", func(n *collector.NetworkMetric) float64 { return float64(n.ErrIn) }},
+	{"runixo_network_transmit_errors_per_second", "Transmit errors per second.", func(n *collector.NetworkMetric) float64 { return float64(n.ErrOut) }},
+	{"runixo_network_receive_drops_per_second", "Dropped inbound packets per second.", func(n *collector.NetworkMetric) float64 { return float64(n.DropIn) }},
+	{"runixo_network_transmit_drops_per_second", "Dropped outbound packets per second.", func(n *collector.NetworkMetric) float64 { return float64(n.DropOut) }},
+}
+
+// diskSeries 每个磁盘设备输出的指标
+var diskSeries = []promSeries[*collector.DiskMetric]{
+	{"runixo_disk_read_bytes_per_second", "Bytes read per second.", func(d *collector.DiskMetric) float64 { return float64(d.ReadBytes) }},
+	{"runixo_disk_write_bytes_per_second", "Bytes written per second.", func(d *collector.DiskMetric) float64 { return float64(d.WriteBytes) }},
+	{"runixo_disk_reads_per_second", "Read operations per second.", func(d *collector.DiskMetric) float64 { return float64(d.ReadCount) }},
+	{"runixo_disk_writes_per_second", "Write operations per second.", func(d *collector.DiskMetric) float64 { return float64(d.WriteCount) }},
+	{"runixo_disk_await_milliseconds", "Average I/O latency in milliseconds.", func(d *collector.DiskMetric) float64 { return d.Await }},
+	{"runixo_disk_utilization_percent", "Percentage of time the device was busy.", func(d *collector.DiskMetric) float64 { return d.Util }},
+	{"runixo_disk_io_in_progress", "I/O operations currently in flight.", func(d *collector.DiskMetric) float64 { return float64(d.InFlight) }},
+}
+
+// containerSeries 每个容器输出的指标
+var containerSeries = []promSeries[*collector.ContainerMetric]{
+	{"runixo_container_cpu_usage_percent", "Container CPU usage in percent.", func(c *collector.ContainerMetric) float64 { return c.CpuPercent }},
+	{"runixo_container_memory_usage_bytes", "Container memory usage excluding page cache.", func(c *collector.ContainerMetric) float64 { return float64(c.MemoryUsage) }},
+	{"runixo_container_memory_limit_bytes", "Container memory limit.", func(c *collector.ContainerMetric) float64 { return float64(c.MemoryLimit) }},
+	{"runixo_container_network_receive_bytes_per_second", "Container received bytes per second.", func(c *collector.ContainerMetric) float64 { return float64(c.NetworkRx) }},
+	{"runixo_container_network_transmit_bytes_per_second", "Container transmitted bytes per second.", func(c *collector.ContainerMetric) float64 { return float64(c.NetworkTx) }},
+	{"runixo_container_restarts", "Number of times the container was restarted by its restart policy.", func(c *collector.ContainerMetric) float64 { return float64(c.RestartCount) }},
+	{"runixo_container_running", "Whether the container is running (1) or not (0).", func(c *collector.ContainerMetric) float64 {
+		if c.State == "running" {
+			return 1
+		}
+		return 0
+	}},
+}
+
+// writeSystemMetrics 输出 GetMetrics 的内容
+// 文本格式要求同一指标的样本连续出现，因此按指标分组、组内按设备名排序
+func writeSystemMetrics(p *promWriter, m *collector.Metrics) {
+	p.gauge("runixo_cpu_usage_percent", "CPU usage in percent.", m.CpuUsage)
+	p.gauge("runixo_memory_usage_percent", "Memory usage in percent.", m.MemoryUsage)
+	p.gauge("runixo_swap_total_bytes", "Total swap space.", float64(m.SwapTotal))
+	p.gauge("runixo_swap_used_bytes", "Used swap space.", float64(m.SwapUsed))
+	p.gauge("runixo_swap_free_bytes", "Free swap space.", float64(m.SwapFree))
+	p.gauge("runixo_swap_in_bytes_per_second", "Bytes swapped in per second.", float64(m.SwapInRate))
+	p.gauge("runixo_swap_out_bytes_per_second", "Bytes swapped out per second.", float64(m.SwapOutRate))
+	p.gauge("runixo_load1", "1-minute load average.", m.Load1)
+	p.gauge("runixo_load5", "5-minute load average.", m.Load5)
+	p.gauge("runixo_load15", "15-minute load average.", m.Load15)
+	p.gauge("runixo_procs_running", "Processes in the run queue.", float64(m.ProcsRunning))
+	p.gauge("runixo_procs_blocked", "Processes blocked waiting for I/O.", float64(m.ProcsBlocked))
+	p.gauge("runixo_procs_zombie", "Defunct processes not yet reaped by their parent.", float64(m.Zombies))
+	p.gauge("runixo_procs_stuck", "Processes in uninterruptible sleep for longer than two minutes.", float64(len(m.StuckProcesses)))
+	if m.ConntrackMax > 0 {
+		p.gauge("runixo_conntrack_entries", "Entries in the netfilter connection tracking table.", float64(m.ConntrackCount))
+		p.gauge("runixo_conntrack_max", "Size limit of the netfilter connection tracking table.", float64(m.ConntrackMax))
+	}
+	if m.FdMax > 0 {
+		p.gauge("runixo_file_descriptors_allocated", "Allocated file handles system-wide.", float64(m.FdAllocated))
+		p.gauge("runixo_file_descriptors_max", "System-wide file handle limit.", float64(m.FdMax))
+	}
+	p.gauge("runixo_procs_fd_near_limit", "Processes using at least 80% of their open file limit.", float64(len(m.FdNearLimit)))
+	writePowerMetrics(p, m.Power)
+	p.gauge("runixo_boot_time_seconds", "System boot time as a Unix timestamp.", float64(m.BootTime))
+	p.gauge("runixo_uptime_seconds", "System uptime in seconds.", float64(m.Uptime))
+
+	nics := append([]*collector.NetworkMetric(nil), m.NetworkMetrics...)
+	sort.Slice(nics, func(i, j int) bool { return nics[i].Interface < nics[j].Interface })
+	for _, series := range networkSeries {
+		for _, n := range nics {
+			p.gauge(series.name, series.help, series.value(n), "interface", n.Interface)
+		}
+	}
+
+	disks := append([]*collector.DiskMetric(nil), m.DiskMetrics...)
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Device < disks[j].Device })
+	for _, series := range diskSeries {
+		for _, d := range disks {
+			p.gauge(series.name, series.help, series.value(d), "device", d.Device)
+		}
+	}
+
+	if u := m.Units; u != nil {
+		for _, s := range []struct {
+			state string
+			n     int
+		}{{"active", u.Active}, {"inactive", u.Inactive}, {"activating", u.Activating}, {"failed", u.Failed}} {
+			p.gauge("runixo_systemd_units", "Number of systemd units by active state.", float64(s.n), "state", s.state)
+		}
+		for _, name := range u.FailedUnits {
+			p.gauge("runixo_systemd_unit_failed", "Failed systemd unit (always 1).", 1, "unit", name)
+		}
+	}
+
+	containers := append([]*collector.ContainerMetric(nil), m.Containers...)
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+	for _, series := range containerSeries {
+		for _, c := range containers {
+			p.gauge(series.name, series.help, series.value(c), "name", c.Name, "image", c.Image)
+		}
+	}
+}
+
+// filesystemSeries 每个挂载点输出的指标
+var filesystemSeries = []promSeries[*collector.DiskInfo]{
+	{"runixo_filesystem_size_bytes", "Filesystem size.", func(d *collector.DiskInfo) float64 { return float64(d.Total) }},
+	{"runixo_filesystem_used_bytes", "Filesystem space used.", func(d *collector.DiskInfo) float64 { return float64(d.Used) }},
+	{"runixo_filesystem_free_bytes", "Filesystem space available.", func(d *collector.DiskInfo) float64 { return float64(d.Free) }},
+	{"runixo_filesystem_inodes", "Total inodes (0 if the filesystem has no fixed inode table).", func(d *collector.DiskInfo) float64 { return float64(d.InodesTotal) }},
+	{"runixo_filesystem_inodes_used", "Used inodes.", func(d *collector.DiskInfo) float64 { return float64(d.InodesUsed) }},
+	{"runixo_filesystem_inodes_free", "Free inodes.", func(d *collector.DiskInfo) float64 { return float64(d.InodesFree) }},
+}
+
+// writeFilesystemMetrics 输出各挂载点的空间和 inode 用量
+func writeFilesystemMetrics(p *promWriter, disks []*collector.DiskInfo) {
+	sorted := append([]*collector.DiskInfo(nil), disks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Mountpoint < sorted[j].Mountpoint })
+	for _, series := range filesystemSeries {
+		for _, d := range sorted {
+			p.gauge(series.name, series.help, series.value(d), "mountpoint", d.Mountpoint, "device", d.Device, "fstype", d.Fstype)
+		}
+	}
+}
+
+// writePackageMetrics 输出软件包统计，无法获取的项不输出
+func writePackageMetrics(p *promWriter, info *collector.PackageInfo) {
+	if info == nil {
+		return
+	}
+	p.gauge("runixo_packages_installed", "Installed packages.", float64(info.Installed), "manager", info.Manager)
+	if info.Updates >= 0 {
+		p.gauge("runixo_packages_updates_pending", "Packages with an available update.", float64(info.Updates), "manager", info.Manager)
+	}
+	if info.SecurityUpdates >= 0 {
+		p.gauge("runixo_packages_security_updates_pending", "Pending updates from security repositories.", float64(info.SecurityUpdates), "manager", info.Manager)
+	}
+}
+
+// batterySeries 每块电池输出的指标
+var batterySeries = []promSeries[*collector.Battery]{
+	{"runixo_battery_capacity_percent", "Battery charge level in percent.", func(b *collector.Battery) float64 { return b.Percent }},
+	{"runixo_battery_charging", "Whether the battery is charging (1) or not (0).", func(b *collector.Battery) float64 { return boolValue(b.Status == "charging") }},
+	{"runixo_battery_time_remaining_seconds", "Estimated time until the battery is empty (0 if not discharging).", func(b *collector.Battery) float64 { return float64(b.Remaining) }},
+}
+
+// writePowerMetrics 输出电源状态，没有电源信息时不输出
+func writePowerMetrics(p *promWriter, info *collector.PowerInfo) {
+	if info == nil {
+		return
+	}
+	p.gauge("runixo_power_ac_online", "Whether external power is connected (1) or not (0).", boolValue(info.ACOnline))
+	p.gauge("runixo_power_on_battery", "Whether the host is running on battery power (1) or not (0).", boolValue(info.OnBattery))
+	for _, series := range batterySeries {
+		for _, b := range info.Batteries {
+			p.gauge(series.name, series.help, series.value(b), "battery", b.Name)
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// writeCustomMetrics 输出自定义数据源的样本，附加 source 标签
+// 不同数据源可能输出同名指标，按指标名分组以保证同一指标的样本连续
+func writeCustomMetrics(p *promWriter, samples []*collector.Sample) {
+	sorted := append([]*collector.Sample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	help := make(map[string]string)
+	for _, s := range sorted {
+		if help[s.Name] == "" && s.Help != "" {
+			help[s.Name] = helpEscaper.Replace(s.Help)
+		}
+	}
+	for _, s := range sorted {
+		labels := append([]string{"source", s.Source}, sortedLabelPairs(s.Labels)...)
+		h := help[s.Name]
+		if h == "" {
+			h = "Custom metric from source " + s.Source + "."
+		}
+		typ := s.Type
+		if typ == "" {
+			typ = collector.SampleGauge
+		}
+		p.sample(s.Name, h, typ, s.Value, labels...)
+	}
+}
+
+// helpEscaper HELP 文本转义规则（反斜杠、换行）
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+)
+
+// DefaultManageableServices 默认允许通过 REST API 操作的服务（支持通配符）
+var DefaultManageableServices = []string{
+	"nginx", "apache2", "httpd", "caddy",
+	"mysql", "mysqld", "mariadb", "postgresql", "postgresql@*",
+	"redis", "redis-server", "mongod", "memcached",
+	"docker", "containerd",
+	"php*-fpm", "php-fpm",
+	"supervisor", "supervisord", "cron", "crond",
+}
+
+// SetServiceAllowlist 设置允许操作的服务列表
+func (s *Server) SetServiceAllowlist(patterns []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serviceAllowlist = patterns
+}
+
+// isServiceManageable 检查服务是否在允许列表中
+func (s *Server) isServiceManageable(name string) bool {
+	name = strings.TrimSuffix(name, ".service")
+
+	s.mu.RLock()
+	patterns := s.serviceAllowlist
+	s.mu.RUnlock()
+
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(strings.TrimSuffix(pattern, ".service"), name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceView 服务列表项（附带是否可操作）
+type serviceView struct {
+	*executor.ServiceInfo
+	Manageable bool
+}
+
+// handleServices 服务列表（激活状态 + 开机自启状态）
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	services, err := executor.ListServices(ctx)
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to list services: %v", err), err, http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]serviceView, 0, len(services))
+	for _, svc := range services {
+		result = append(result, serviceView{ServiceInfo: svc, Manageable: s.isServiceManageable(svc.Name)})
+	}
+	s.jsonResponse(w, result)
+}
+
+// handleServiceAction 服务操作（start / stop / restart / enable / disable）
+func (s *Server) handleServiceAction(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	action := r.PathValue("action")
+
+	switch action {
+	case "start", "stop", "restart", "enable", "disable":
+	default:
+		s.jsonError(w, fmt.Sprintf("Unsupported action: %s", action), http.StatusBadRequest)
+		return
+	}
+
+	if !s.isServiceManageable(name) {
+		s.jsonErrorCode(w, errcode.ServiceNotAllowed, fmt.Sprintf("Service %s is not in the allowlist", name))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := executor.ServiceAction(ctx, name, action); err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to %s service: %v", action, err), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, map[string]string{"name": name, "action": action})
+}
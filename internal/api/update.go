@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/runixo/agent/internal/updater"
+)
+
+// SetUpdater 设置更新器（未设置时更新接口返回 503）
+func (s *Server) SetUpdater(u *updater.Updater) {
+	s.updater = u
+}
+
+// requireUpdater 检查更新器是否可用
+func (s *Server) requireUpdater(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.updater == nil {
+			s.jsonError(w, "Updater not available", http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// updateRequest 下载/应用更新请求
+type updateRequest struct {
+	Version string `json:"version"`
+}
+
+// handleUpdateCheck 检查更新
+func (s *Server) handleUpdateCheck(w http.ResponseWriter, r *http.Request) {
+	info, err := s.updater.CheckUpdate()
+	if err != nil {
+		s.jsonErrorFrom(w, fmt.Sprintf("Failed to check update: %v", err), err, http.StatusBadGateway)
+		return
+	}
+	s.jsonResponse(w, info)
+}
+
+// handleUpdateApply 下载、校验并应用更新（成功后服务会重启）
+func (s *Server) handleUpdateApply(w http.ResponseWriter, r *http.Request) {
+	var req updateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil || req.Version == "" {
+		s.jsonError(w, "Version is required", http.StatusBadRequest)
+		return
+	}
+
+	// 下载可能超过服务器默认写超时
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(15 * time.Minute))
+
+	if err := s.updater.ApplyUpdate(req.Version); err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, map[string]string{"version": req.Version, "message": "更新已应用，服务即将重启"})
+}
+
+// handleUpdateProgress 以 Server-Sent Events 推送下载进度（GET /api/update/download?version=vX.Y.Z）
+func (s *Server) handleUpdateProgress(w http.ResponseWriter, r *http.Request) {
+	version := r.URL.Query().Get("version")
+	if version == "" {
+		s.jsonError(w, "Version is required", http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(15 * time.Minute))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	progressChan := make(chan *updater.DownloadProgress, 10)
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := s.updater.DownloadUpdate(version, progressChan)
+		errChan <- err
+		close(progressChan)
+	}()
+
+	writeEvent := func(event string, data interface{}) error {
+		payload, _ := json.Marshal(data)
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			// 客户端断开：继续消费进度，避免下载协程阻塞
+			go func() {
+				for range progressChan {
+				}
+			}()
+			return
+		case progress, ok := <-progressChan:
+			if !ok {
+				if err := <-errChan; err != nil {
+					writeEvent("error", Response{Success: false, Error: err.Error()})
+					return
+				}
+				writeEvent("done", Response{Success: true})
+				return
+			}
+			if err := writeEvent("progress", progress); err != nil {
+				go func() {
+					for range progressChan {
+					}
+				}()
+				return
+			}
+		}
+	}
+}
+
+// handleUpdateHistory 更新历史
+func (s *Server) handleUpdateHistory(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, s.updater.GetHistory())
+}
+
+// handleGetUpdateConfig 获取更新配置
+func (s *Server) handleGetUpdateConfig(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, s.updater.GetConfig())
+}
+
+// handleSetUpdateConfig 设置更新配置
+func (s *Server) handleSetUpdateConfig(w http.ResponseWriter, r *http.Request) {
+	var config updater.Config
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&config); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid config: %v", err), http.StatusBadRequest)
+		return
+	}
+	if config.AutoUpdate && config.CheckInterval < 60 {
+		s.jsonError(w, "check_interval must be at least 60 seconds", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.updater.SetConfig(&config); err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, s.updater.GetConfig())
+}
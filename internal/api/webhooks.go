@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/runixo/agent/internal/webhook"
+)
+
+// SetWebhooks 设置 webhook 投递器（未设置时 webhook 接口返回 503）
+func (s *Server) SetWebhooks(d *webhook.Dispatcher) {
+	s.webhooks = d
+}
+
+// requireWebhooks 检查 webhook 投递器是否可用
+func (s *Server) requireWebhooks(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.webhooks == nil {
+			s.jsonError(w, "Webhooks not available", http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// webhookRequest 创建/修改订阅请求，修改时未提供的字段保持不变
+type webhookRequest struct {
+	URL     *string  `json:"url"`
+	Secret  string   `json:"secret"` // Telegram 为 Bot Token
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+	Format  *string  `json:"format"` // json（默认）、slack 或 telegram
+	ChatID  *string  `json:"chat_id"`
+}
+
+// channel 请求中的推送渠道，未提供的字段取自 current
+func (r *webhookRequest) channel(current webhook.Channel) webhook.Channel {
+	if r.Format != nil {
+		current.Format = *r.Format
+	}
+	if r.ChatID != nil {
+		current.ChatID = *r.ChatID
+	}
+	return current
+}
+
+// webhookView 订阅的对外表示，密钥只在创建时返回一次
+type webhookView struct {
+	*webhook.Subscription
+	Secret string `json:"secret,omitempty"`
+}
+
+func maskSubscription(sub *webhook.Subscription) webhookView {
+	return webhookView{Subscription: sub}
+}
+
+// handleListWebhooks 订阅列表
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs := s.webhooks.List()
+	result := make([]webhookView, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, maskSubscription(sub))
+	}
+	s.jsonResponse(w, result)
+}
+
+// handleCreateWebhook 创建订阅（未提供 secret 时自动生成，Telegram 未提供 url 时使用官方 API 地址）
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	channel := req.channel(webhook.Channel{})
+	var rawURL string
+	if req.URL != nil {
+		rawURL = *req.URL
+	}
+	if rawURL == "" && channel.Format != webhook.FormatTelegram {
+		s.jsonError(w, "url is required", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := s.webhooks.AddChannel(rawURL, req.Secret, req.Events, channel)
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, webhookView{Subscription: sub, Secret: sub.Secret})
+}
+
+// handleGetWebhook 订阅详情
+func (s *Server) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	sub := s.webhooks.Get(r.PathValue("id"))
+	if sub == nil {
+		s.jsonError(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, maskSubscription(sub))
+}
+
+// handleUpdateWebhook 修改订阅的 URL、事件过滤器、启用状态或推送渠道
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		s.jsonError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+	if req.Format != nil || req.ChatID != nil {
+		current := s.webhooks.Get(id)
+		if current == nil {
+			s.jsonError(w, "Webhook not found", http.StatusNotFound)
+			return
+		}
+		if _, err := s.webhooks.UpdateChannel(id, req.channel(current.Channel)); err != nil {
+			s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub, err := s.webhooks.Update(id, req.URL, req.Events, req.Enabled)
+	if err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, maskSubscription(sub))
+}
+
+// handleDeleteWebhook 删除订阅
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.webhooks.Remove(id); err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, map[string]string{"id": id})
+}
+
+// handleTestWebhook 发送 ping 事件，结果可通过订阅详情的 last_status 查看
+func (s *Server) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.webhooks.Test(id); err != nil {
+		s.jsonErrorFrom(w, err.Error(), err, http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, map[string]string{"id": id, "message": "ping 事件已加入投递队列"})
+}
@@ -24,6 +24,7 @@ const (
 	EventTypeFile       EventType = "file"        // 文件操作
 	EventTypeSecurity   EventType = "security"    // 安全事件
 	EventTypeSystem     EventType = "system"      // 系统事件
+	EventTypeProcess    EventType = "process"     // 进程操作
 )
 
 // EventLevel 事件级别
@@ -59,6 +60,8 @@ type Config struct {
 	MaxSizeMB int `json:"max_size_mb"`
 	// 保留的日志文件数量
 	MaxBackups int `json:"max_backups"`
+	// 备份文件的保留时长，超过时在轮转时删除，0 表示只按数量保留
+	Retention time.Duration `json:"retention"`
 	// 记录的最低级别
 	MinLevel EventLevel `json:"min_level"`
 	// 是否记录成功的认证
@@ -76,6 +79,7 @@ func DefaultConfig() *Config {
 		LogPath:        "/var/log/runixo/audit.log",
 		MaxSizeMB:      50,
 		MaxBackups:     5,
+		Retention:      90 * 24 * time.Hour,
 		MinLevel:       LevelInfo,
 		LogSuccessAuth: false, // 默认不记录成功认证，减少日志量
 		LogCommands:    true,  // 记录命令执行
@@ -97,6 +101,10 @@ func NewLogger(config *Config) (*Logger, error) {
 	if config == nil {
 		config = DefaultConfig()
 	}
+	// 未设置大小时每次写入都会触发轮转
+	if config.MaxSizeMB <= 0 {
+		config.MaxSizeMB = DefaultConfig().MaxSizeMB
+	}
 
 	l := &Logger{
 		config:    config,
@@ -109,6 +117,7 @@ func NewLogger(config *Config) (*Logger, error) {
 			// 如果无法打开日志文件，禁用审计但不报错
 			config.Enabled = false
 		}
+		l.pruneBackups()
 	}
 
 	// 启动异步写入协程
@@ -269,6 +278,8 @@ func (l *Logger) rotateFiles() {
 
 	// 重命名当前日志
 	os.Rename(l.config.LogPath, l.config.LogPath+".1")
+
+	l.pruneBackups()
 }
 
 // Close 关闭日志记录器
@@ -331,6 +342,88 @@ func (l *Logger) LogFileOp(clientIP, action, path string, success bool) {
 	})
 }
 
+// LogProcessSignal 记录向进程发送信号
+func (l *Logger) LogProcessSignal(clientIP string, pid int, name, signal string, force, success bool, message string) {
+	level := LevelInfo
+	if force {
+		level = LevelWarning
+	}
+
+	l.Log(&Event{
+		Type:     EventTypeProcess,
+		Level:    level,
+		Action:   "signal_process",
+		ClientIP: clientIP,
+		Success:  success,
+		Message:  message,
+		Details: map[string]interface{}{
+			"pid":    pid,
+			"name":   name,
+			"signal": signal,
+			"force":  force,
+		},
+	})
+}
+
+// LogShellSession 记录交互式终端会话（action 为 shell_start / shell_end）
+func (l *Logger) LogShellSession(clientIP, action, sessionID string, success bool, details map[string]interface{}) {
+	level := LevelInfo
+	if action == "shell_start" {
+		// 交互式会话可以执行任意命令，开始事件按警告级别记录
+		level = LevelWarning
+	}
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["session_id"] = sessionID
+
+	l.Log(&Event{
+		Type:     EventTypeCommand,
+		Level:    level,
+		Action:   action,
+		ClientIP: clientIP,
+		Success:  success,
+		Details:  details,
+	})
+}
+
+// LogPolicyDecision 记录执行策略的决定，拒绝按警告级别记录
+func (l *Logger) LogPolicyDecision(clientIP, tokenID, command string, args []string, allowed bool, rule string) {
+	level := LevelInfo
+	if !allowed {
+		level = LevelWarning
+	}
+
+	l.Log(&Event{
+		Type:     EventTypeSecurity,
+		Level:    level,
+		Action:   "policy_decision",
+		ClientIP: clientIP,
+		Success:  allowed,
+		Details: map[string]interface{}{
+			"token_id": tokenID,
+			"command":  command,
+			"args":     args,
+			"rule":     rule,
+		},
+	})
+}
+
+// LogPluginPermission 记录插件未声明权限的访问被拒绝
+func (l *Logger) LogPluginPermission(pluginID, permission, resource string) {
+	l.Log(&Event{
+		Type:    EventTypeSecurity,
+		Level:   LevelWarning,
+		Action:  "plugin_permission_denied",
+		Success: false,
+		Details: map[string]interface{}{
+			"plugin_id":  pluginID,
+			"permission": permission,
+			"resource":   resource,
+		},
+	})
+}
+
 // LogSecurity 记录安全事件
 func (l *Logger) LogSecurity(clientIP, action, message string, level EventLevel) {
 	l.Log(&Event{
@@ -379,6 +472,34 @@ func (l *Logger) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamInterceptor 流式调用拦截器，流结束时记录命令执行方法
+func (l *Logger) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if !l.config.Enabled || !l.config.LogCommands || !isCommandMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+		l.Log(&Event{
+			Type:     EventTypeCommand,
+			Level:    LevelInfo,
+			Action:   info.FullMethod,
+			ClientIP: getClientIP(ss.Context()),
+			Success:  err == nil,
+			Details: map[string]interface{}{
+				"duration_ms": time.Since(start).Milliseconds(),
+			},
+		})
+		return err
+	}
+}
+
 // getClientIP 获取客户端IP
 func getClientIP(ctx context.Context) string {
 	if p, ok := peer.FromContext(ctx); ok {
@@ -391,6 +512,9 @@ func getClientIP(ctx context.Context) string {
 func isCommandMethod(method string) bool {
 	commandMethods := []string{
 		"ExecuteCommand",
+		"ExecuteStream",
+		"ExecuteScript",
+		"ExecuteBatch",
 		"ExecuteShell",
 		"ServiceAction",
 		"KillProcess",
@@ -0,0 +1,212 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 执行记录的 Action
+const (
+	ActionExecCommand = "execute_command"
+	ActionExecStream  = "execute_stream"
+	ActionExecScript  = "execute_script"
+	ActionExecBatch   = "execute_batch"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// ExecRecord 一次命令执行的审计记录
+// 输出只记录 SHA-256 和长度（Execute 的输出本身可能已按上限截断），不保存内容
+type ExecRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	ClientIP     string    `json:"client_ip"`
+	TokenID      string    `json:"token_id,omitempty"`
+	Action       string    `json:"action"`
+	Command      string    `json:"command"`
+	Args         []string  `json:"args,omitempty"`
+	WorkingDir   string    `json:"working_dir,omitempty"`
+	ExitCode     int       `json:"exit_code"`
+	DurationMs   int64     `json:"duration_ms"`
+	OutputSHA256 string    `json:"output_sha256,omitempty"`
+	OutputSize   int64     `json:"output_size"`
+	// ScriptSHA256 / ScriptSize 仅脚本执行时设置，脚本内容可能包含敏感信息
+	ScriptSHA256 string `json:"script_sha256,omitempty"`
+	ScriptSize   int    `json:"script_size,omitempty"`
+	Sandbox      bool   `json:"sandbox,omitempty"`
+	// Error 命令未能启动或执行出错时的错误信息
+	Error string `json:"error,omitempty"`
+}
+
+// LogExec 记录命令执行，Action 为空时按 execute_command 记录
+func (l *Logger) LogExec(r ExecRecord) {
+	if r.Action == "" {
+		r.Action = ActionExecCommand
+	}
+	details := map[string]interface{}{
+		"token_id":    r.TokenID,
+		"command":     r.Command,
+		"args":        r.Args,
+		"working_dir": r.WorkingDir,
+		"exit_code":   r.ExitCode,
+		"duration_ms": r.DurationMs,
+		"output_size": r.OutputSize,
+	}
+	if r.OutputSHA256 != "" {
+		details["output_sha256"] = r.OutputSHA256
+	}
+	if r.ScriptSHA256 != "" {
+		details["script_sha256"] = r.ScriptSHA256
+		details["script_size"] = r.ScriptSize
+	}
+	if r.Sandbox {
+		details["sandbox"] = true
+	}
+
+	l.Log(&Event{
+		Timestamp: r.Timestamp,
+		Type:      EventTypeCommand,
+		Level:     LevelInfo,
+		Action:    r.Action,
+		ClientIP:  r.ClientIP,
+		Success:   r.Error == "" && r.ExitCode == 0,
+		Message:   r.Error,
+		Details:   details,
+	})
+}
+
+// ExecQuery 查询执行记录的条件，零值表示不限制
+type ExecQuery struct {
+	Since   time.Time
+	Until   time.Time
+	TokenID string
+	// Command 匹配命令或其文件名
+	Command string
+	// Limit 最多返回的条数，默认 100，最大 1000
+	Limit int
+}
+
+// ExecHistory 按时间倒序返回审计日志（含轮转的备份）中的执行记录
+// truncated 为 true 表示还有更早的匹配记录没有返回
+func (l *Logger) ExecHistory(q ExecQuery) ([]ExecRecord, bool, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	cfg := l.GetConfig()
+
+	// 从当前日志开始依次读取 .1、.2 …，每个文件内部是时间正序
+	var records []ExecRecord
+	for i := 0; i <= cfg.MaxBackups; i++ {
+		path := cfg.LogPath
+		if i > 0 {
+			path = fmt.Sprintf("%s.%d", cfg.LogPath, i)
+		}
+		matched, err := readExecRecords(path, q)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, false, err
+		}
+		for j := len(matched) - 1; j >= 0; j-- {
+			if len(records) == limit {
+				return records, true, nil
+			}
+			records = append(records, matched[j])
+		}
+		// 整个文件都早于 Since 时更早的备份无需再读
+		if !q.Since.IsZero() && olderThan(path, q.Since) {
+			break
+		}
+	}
+	return records, false, nil
+}
+
+// execEvent 审计日志中执行事件的结构
+type execEvent struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Type      EventType  `json:"type"`
+	Action    string     `json:"action"`
+	ClientIP  string     `json:"client_ip"`
+	Message   string     `json:"message"`
+	Details   ExecRecord `json:"details"`
+}
+
+// readExecRecords 读取单个日志文件中符合条件的执行记录，无法解析的行被跳过
+func readExecRecords(path string, q ExecQuery) ([]ExecRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []ExecRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e execEvent
+		if json.Unmarshal(scanner.Bytes(), &e) != nil || e.Type != EventTypeCommand || !isExecAction(e.Action) {
+			continue
+		}
+		r := e.Details
+		r.Timestamp, r.ClientIP, r.Action, r.Error = e.Timestamp, e.ClientIP, e.Action, e.Message
+		if matchExec(r, q) {
+			records = append(records, r)
+		}
+	}
+	return records, scanner.Err()
+}
+
+func isExecAction(action string) bool {
+	switch action {
+	case ActionExecCommand, ActionExecStream, ActionExecScript, ActionExecBatch:
+		return true
+	}
+	return false
+}
+
+func matchExec(r ExecRecord, q ExecQuery) bool {
+	if !q.Since.IsZero() && r.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.Timestamp.After(q.Until) {
+		return false
+	}
+	if q.TokenID != "" && r.TokenID != q.TokenID {
+		return false
+	}
+	if q.Command != "" && r.Command != q.Command && filepath.Base(r.Command) != q.Command {
+		return false
+	}
+	return true
+}
+
+// olderThan 文件最后修改时间早于 t
+func olderThan(path string, t time.Time) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.ModTime().Before(t)
+}
+
+// pruneBackups 删除最后修改时间超过保留时长的备份文件
+func (l *Logger) pruneBackups() {
+	if l.config.Retention <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-l.config.Retention)
+	for i := 1; i <= l.config.MaxBackups; i++ {
+		path := fmt.Sprintf("%s.%d", l.config.LogPath, i)
+		if olderThan(path, cutoff) {
+			os.Remove(path)
+		}
+	}
+}
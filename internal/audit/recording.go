@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxRecordingSize 单个会话录像的最大字节数，超出后停止录像（会话不受影响）
+const maxRecordingSize = 100 * 1024 * 1024
+
+// SessionRecorder 以 asciicast v2 格式记录终端会话的输出，可用 asciinema play 回放
+// 只记录输出：终端不回显的输入（如密码）不会出现在录像中
+type SessionRecorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	w       *bufio.Writer
+	start   time.Time
+	written int64
+	full    bool
+}
+
+// NewSessionRecorder 在 dir 下创建 <id>.cast
+func NewSessionRecorder(dir, id string, cols, rows int, shell string) (*SessionRecorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, id+".cast"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	r := &SessionRecorder{file: f, w: bufio.NewWriter(f), start: time.Now()}
+	header, _ := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": r.start.Unix(),
+		"env":       map[string]string{"SHELL": shell, "TERM": "xterm-256color"},
+	})
+	r.writeLine(header)
+	return r, nil
+}
+
+// Output 记录一段终端输出
+func (r *SessionRecorder) Output(data []byte) {
+	r.event("o", string(data))
+}
+
+// Resize 记录窗口大小变化
+func (r *SessionRecorder) Resize(cols, rows int) {
+	r.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+func (r *SessionRecorder) event(kind, data string) {
+	line, err := json.Marshal([]any{time.Since(r.start).Seconds(), kind, data})
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeLine(line)
+}
+
+func (r *SessionRecorder) writeLine(line []byte) {
+	if r.full {
+		return
+	}
+	if r.written+int64(len(line)) > maxRecordingSize {
+		r.full = true
+		return
+	}
+	r.w.Write(line)
+	r.w.WriteByte('\n')
+	r.written += int64(len(line)) + 1
+}
+
+// Close 写入缓冲并关闭文件
+func (r *SessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
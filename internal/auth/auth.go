@@ -15,11 +15,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/runixo/agent/internal/errcode"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
-	"google.golang.org/grpc/status"
 )
 
 // 安全配置
@@ -207,19 +206,19 @@ func (a *AuthInterceptor) authorize(ctx context.Context) error {
 
 	// 检查是否被锁定
 	if a.isLocked(clientIP) {
-		return status.Error(codes.ResourceExhausted, "认证失败次数过多，请稍后重试")
+		return errcode.Status(errcode.AuthLocked, "认证失败次数过多，请稍后重试")
 	}
 
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		a.recordFailedAttempt(clientIP)
-		return status.Error(codes.Unauthenticated, "缺少元数据")
+		return errcode.Status(errcode.AuthRequired, "缺少元数据")
 	}
 
 	values := md.Get("authorization")
 	if len(values) == 0 {
 		a.recordFailedAttempt(clientIP)
-		return status.Error(codes.Unauthenticated, "缺少认证令牌")
+		return errcode.Status(errcode.AuthRequired, "缺少认证令牌")
 	}
 
 	token := values[0]
@@ -232,9 +231,9 @@ func (a *AuthInterceptor) authorize(ctx context.Context) error {
 	if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
 		locked := a.recordFailedAttempt(clientIP)
 		if locked {
-			return status.Error(codes.ResourceExhausted, "认证失败次数过多，账户已锁定")
+			return errcode.Status(errcode.AuthLocked, "认证失败次数过多，账户已锁定")
 		}
-		return status.Error(codes.Unauthenticated, "认证令牌无效")
+		return errcode.Status(errcode.AuthInvalid, "认证令牌无效")
 	}
 
 	// 认证成功，重置失败计数
@@ -251,6 +250,25 @@ func GenerateToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// TokenID 返回令牌的标识（SHA-256 的前 12 位十六进制），用于在策略和日志中引用令牌而不暴露令牌本身
+func TokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// TokenIDFromContext 从请求元数据中取出令牌并返回其 ID，没有令牌时返回空
+func TokenIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return ""
+	}
+	return TokenID(strings.TrimPrefix(values[0], "Bearer "))
+}
+
 // tokenClaims 签名令牌的载荷
 type tokenClaims struct {
 	Token     string `json:"tok"`
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/runixo/agent/internal/plugin/httpapi"
+)
+
+// Handler 备份插件的 HTTP 接口，挂载在 prefix 下，响应格式与 Agent REST API 一致：
+//
+//	GET    /jobs                    任务及最近一次运行的结果
+//	POST   /jobs/{job}/run          立即运行任务，返回后台操作
+//	GET    /targets                 存储目标（凭据已隐藏）
+//	GET    /backups?job=            备份记录，从新到旧
+//	GET    /backups/{id}            备份记录
+//	DELETE /backups/{id}            删除备份
+//	POST   /backups/{id}/verify     校验备份，返回后台操作
+//	POST   /backups/{id}/restore    恢复备份（请求体为 RestoreOptions），返回后台操作
+//	GET    /operations              最近的操作
+//	GET    /operations/{id}         操作状态
+func (m *Manager) Handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/jobs", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, m.Jobs(r.Context()))
+	})
+	mux.HandleFunc("POST "+prefix+"/jobs/{job}/run", func(w http.ResponseWriter, r *http.Request) {
+		op, err := m.StartBackup(r.PathValue("job"))
+		httpapi.WriteResult(w, http.StatusAccepted, op, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/targets", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, m.Targets())
+	})
+	mux.HandleFunc("GET "+prefix+"/backups", func(w http.ResponseWriter, r *http.Request) {
+		backups, err := m.Backups(r.Context(), r.URL.Query().Get("job"))
+		httpapi.WriteResult(w, http.StatusOK, backups, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/backups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		b, err := m.GetBackup(r.Context(), r.PathValue("id"))
+		httpapi.WriteResult(w, http.StatusOK, b, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/backups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		err := m.Delete(r.Context(), r.PathValue("id"))
+		httpapi.WriteResult(w, http.StatusOK, map[string]string{"message": "备份已删除"}, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/backups/{id}/verify", func(w http.ResponseWriter, r *http.Request) {
+		op, err := m.StartVerify(r.PathValue("id"))
+		httpapi.WriteResult(w, http.StatusAccepted, op, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/backups/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		var opts RestoreOptions
+		if err := httpapi.DecodeJSON(r, &opts); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		op, err := m.StartRestore(r.PathValue("id"), opts)
+		httpapi.WriteResult(w, http.StatusAccepted, op, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/operations", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, m.Operations())
+	})
+	mux.HandleFunc("GET "+prefix+"/operations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		op, err := m.GetOperation(r.PathValue("id"))
+		httpapi.WriteResult(w, http.StatusOK, op, err)
+	})
+	return mux
+}
@@ -0,0 +1,337 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 归档中的目录和文件：files/ 下为按原绝对路径存放的文件，databases/ 下为数据库导出，最后一项为 backup.json
+const (
+	archiveFilesDir     = "files/"
+	archiveDatabasesDir = "databases/"
+	archiveManifestName = "backup.json"
+)
+
+// maxArchiveManifest backup.json 的大小上限
+const maxArchiveManifest = 64 << 20
+
+// archiveManifest 归档内的清单，记录每个文件的 SHA-256，用于校验和恢复
+type archiveManifest struct {
+	ID        string                  `json:"id"`
+	Job       string                  `json:"job"`
+	Hostname  string                  `json:"hostname"`
+	CreatedAt time.Time               `json:"created_at"`
+	Files     map[string]archiveEntry `json:"files"`     // 归档中的名称 -> 内容摘要，只含普通文件
+	Databases []archiveDatabase       `json:"databases"` // 数据库导出
+}
+
+type archiveEntry struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type archiveDatabase struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Database string `json:"database,omitempty"`
+}
+
+// archiveWriter 写入 tar.gz 归档，同时计算整个归档的 SHA-256
+type archiveWriter struct {
+	file     *os.File
+	hash     hash.Hash
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	manifest archiveManifest
+	skipped  int
+}
+
+func newArchiveWriter(path string, manifest archiveManifest) (*archiveWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(f, h))
+	manifest.Files = make(map[string]archiveEntry)
+	return &archiveWriter{file: f, hash: h, gz: gz, tw: tar.NewWriter(gz), manifest: manifest}, nil
+}
+
+// addTree 递归添加 root 下的文件，不跟随符号链接；check 拒绝的路径和匹配 exclude 的路径被跳过
+func (a *archiveWriter) addTree(ctx context.Context, root string, exclude []string, check func(string) error, skip string) error {
+	resolved, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("备份路径 %s 无效: %w", root, err)
+	}
+	if err := check(resolved); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(resolved, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// 无法读取的子目录跳过，不中止整个备份
+			log.Warn().Err(err).Str("path", p).Msg("跳过无法读取的路径")
+			a.skipped++
+			return nil
+		}
+		if p == skip || excluded(p, exclude) || (p != resolved && check(p) != nil) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return a.addFile(p, d)
+	})
+}
+
+// addFile 添加一个文件、目录或符号链接，其他类型的文件（设备、套接字等）被跳过
+func (a *archiveWriter) addFile(p string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		a.skipped++
+		return nil
+	}
+	var link string
+	switch {
+	case info.Mode().IsRegular(), info.IsDir():
+	case info.Mode()&fs.ModeSymlink != 0:
+		if link, err = os.Readlink(p); err != nil {
+			a.skipped++
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = archiveFilesDir + strings.TrimPrefix(filepath.ToSlash(p), "/")
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	if !info.Mode().IsRegular() {
+		return a.tw.WriteHeader(hdr)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		log.Warn().Err(err).Str("path", p).Msg("跳过无法读取的文件")
+		a.skipped++
+		return nil
+	}
+	defer f.Close()
+	return a.addReader(hdr, f)
+}
+
+// addDatabase 添加数据库导出文件
+func (a *archiveWriter) addDatabase(s *Source, dump string) error {
+	f, err := os.Open(dump)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     archiveDatabasesDir + s.dumpName() + ".sql",
+		Mode:     0600,
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}
+	if err := a.addReader(hdr, f); err != nil {
+		return err
+	}
+	a.manifest.Databases = append(a.manifest.Databases, archiveDatabase{Name: s.dumpName(), Type: s.Type, Database: s.Database})
+	return nil
+}
+
+// addReader 写入普通文件并记录摘要；备份期间文件变短时以 0 补齐，变长的部分被截断
+func (a *archiveWriter) addReader(hdr *tar.Header, r io.Reader) error {
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	h := sha256.New()
+	n, err := io.CopyN(io.MultiWriter(a.tw, h), r, hdr.Size)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("读取 %s 失败: %w", hdr.Name, err)
+	}
+	if n < hdr.Size {
+		log.Warn().Str("file", hdr.Name).Msg("文件在备份期间被截断")
+		if _, err := io.CopyN(io.MultiWriter(a.tw, h), zeroReader{}, hdr.Size-n); err != nil {
+			return err
+		}
+	}
+	a.manifest.Files[hdr.Name] = archiveEntry{Size: hdr.Size, SHA256: hex.EncodeToString(h.Sum(nil))}
+	return nil
+}
+
+// close 写入 backup.json 并关闭归档，返回归档的大小和 SHA-256
+func (a *archiveWriter) close() (int64, string, error) {
+	defer a.file.Close()
+	data, err := json.MarshalIndent(&a.manifest, "", "  ")
+	if err != nil {
+		return 0, "", err
+	}
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: archiveManifestName, Mode: 0600, Size: int64(len(data)), ModTime: a.manifest.CreatedAt}
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return 0, "", err
+	}
+	if _, err := a.tw.Write(data); err != nil {
+		return 0, "", err
+	}
+	if err := a.tw.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := a.gz.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := a.file.Sync(); err != nil {
+		return 0, "", err
+	}
+	info, err := a.file.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+	return info.Size(), hex.EncodeToString(a.hash.Sum(nil)), nil
+}
+
+// abort 放弃未完成的归档
+func (a *archiveWriter) abort() {
+	a.file.Close()
+	os.Remove(a.file.Name())
+}
+
+// verifyArchive 校验归档的整体摘要和每个文件的摘要，返回归档内的清单
+func verifyArchive(ctx context.Context, path, wantSHA256 string) (*archiveManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sum, err := hashSection(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if wantSHA256 != "" && sum != wantSHA256 {
+		return nil, errcode.New(errcode.ValidationFailed, "归档摘要不匹配：应为 %s，实际为 %s", wantSHA256, sum)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]archiveEntry)
+	var manifest *archiveManifest
+	err = readArchive(ctx, f, func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Name == archiveManifestName {
+			data, err := io.ReadAll(io.LimitReader(r, maxArchiveManifest))
+			if err != nil {
+				return err
+			}
+			manifest = new(archiveManifest)
+			return json.Unmarshal(data, manifest)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+		h := sha256.New()
+		n, err := io.Copy(h, r)
+		if err != nil {
+			return err
+		}
+		seen[hdr.Name] = archiveEntry{Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}
+		return nil
+	})
+	if err != nil {
+		return nil, errcode.Wrap(errcode.ValidationFailed, err, "归档已损坏")
+	}
+	if manifest == nil {
+		return nil, errcode.New(errcode.ValidationFailed, "归档缺少 %s", archiveManifestName)
+	}
+
+	for name, want := range manifest.Files {
+		got, ok := seen[name]
+		switch {
+		case !ok:
+			return nil, errcode.New(errcode.ValidationFailed, "归档缺少文件 %s", name)
+		case got != want:
+			return nil, errcode.New(errcode.ValidationFailed, "文件 %s 的摘要不匹配", name)
+		}
+	}
+	if len(seen) != len(manifest.Files) {
+		return nil, errcode.New(errcode.ValidationFailed, "归档包含清单之外的文件")
+	}
+	return manifest, nil
+}
+
+// readArchive 依次读取 tar.gz 归档的每一项，拒绝包含 ".." 或绝对路径的名称
+func readArchive(ctx context.Context, r io.Reader, fn func(hdr *tar.Header, r io.Reader) error) error {
+	gz, err := gzip.NewReader(&ctxReader{ctx: ctx, r: r})
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if path.IsAbs(name) || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("归档包含无效的路径 %q", hdr.Name)
+		}
+		if err := fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// excluded 文件名或完整路径匹配任一模式
+func excluded(p string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, filepath.Base(p)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// zeroReader 无限输出 0
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,261 @@
+// Package backup 自动备份：定时把文件和数据库（mysqldump/pg_dump）打包备份到本地目录、SFTP 或 S3 兼容存储，
+// 按保留策略清理旧备份，并支持校验和恢复
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 数据源类型
+const (
+	SourceFiles    = "files"
+	SourceMySQL    = "mysql"
+	SourcePostgres = "postgres"
+)
+
+// 存储目标类型
+const (
+	TargetLocal = "local"
+	TargetSFTP  = "sftp"
+	TargetS3    = "s3"
+)
+
+// defaultJobTimeout 单次备份的默认超时
+const defaultJobTimeout = 6 * time.Hour
+
+// namePattern 任务、存储目标和数据库备份的命名规则，与插件定时任务名一致
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// Config 备份插件配置，任务和存储目标以名称为键
+type Config struct {
+	Targets map[string]*Target `json:"targets"`
+	Jobs    map[string]*Job    `json:"jobs"`
+}
+
+// Target 备份存储目标
+type Target struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"` // local 为本地目录，sftp 为远端目录（相对路径相对于登录用户的主目录）
+
+	// SFTP，通过系统的 sftp 命令连接，只支持密钥认证
+	Host         string `json:"host,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	User         string `json:"user,omitempty"`
+	IdentityFile string `json:"identity_file,omitempty"` // 私钥文件，为空时使用 Agent 运行用户的默认密钥
+	KnownHosts   string `json:"known_hosts,omitempty"`   // known_hosts 文件，为空时首次连接把主机密钥记录到插件数据目录
+
+	// S3 兼容存储（AWS S3、MinIO、Cloudflare R2 等）
+	Endpoint  string `json:"endpoint,omitempty"` // 如 https://s3.us-east-1.amazonaws.com
+	Region    string `json:"region,omitempty"`   // 为空时为 us-east-1
+	Bucket    string `json:"bucket,omitempty"`
+	Prefix    string `json:"prefix,omitempty"` // 对象名前缀
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+	PathStyle bool   `json:"path_style,omitempty"` // 使用 <endpoint>/<bucket>/<key> 形式的地址，MinIO 等需要
+}
+
+// Job 备份任务，每次运行把全部数据源打包为一个 tar.gz 上传到存储目标
+type Job struct {
+	Schedule  string    `json:"schedule,omitempty"` // cron 表达式或 @daily 等，为空时只能手动运行
+	Timezone  string    `json:"timezone,omitempty"`
+	Target    string    `json:"target"`
+	Sources   []Source  `json:"sources"`
+	Retention Retention `json:"retention,omitempty"`
+	Timeout   int       `json:"timeout,omitempty"` // 秒，单次备份的超时，0 表示 6 小时
+}
+
+// Source 备份数据源
+type Source struct {
+	Type string `json:"type"`
+
+	// 文件
+	Paths   []string `json:"paths,omitempty"`   // 绝对路径，目录递归备份，不跟随符号链接
+	Exclude []string `json:"exclude,omitempty"` // 按 filepath.Match 匹配文件名或完整路径
+
+	// 数据库，密码通过环境变量传给 mysqldump/pg_dump，不出现在命令行中
+	Name     string `json:"name,omitempty"` // 备份在归档中的名称，为空时使用 database，再为空时使用 type
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	Database string `json:"database,omitempty"` // 为空时备份全部数据库
+}
+
+// Retention 保留策略：保留最近 KeepLast 个备份以及 KeepDays 天内的备份，其余删除；都为 0 时不清理
+type Retention struct {
+	KeepLast int `json:"keep_last,omitempty"`
+	KeepDays int `json:"keep_days,omitempty"`
+}
+
+// ParseConfig 解析并校验插件配置
+func ParseConfig(values map[string]any) (*Config, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "备份配置格式无效")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// validate 逐项校验配置，错误以字段路径返回
+func (c *Config) validate() error {
+	var fields []errcode.FieldViolation
+	add := func(field, format string, args ...any) {
+		fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range sortedKeys(c.Targets) {
+		t, field := c.Targets[name], "targets."+name
+		if !namePattern.MatchString(name) {
+			add(field, "无效的存储目标名")
+		}
+		if t == nil {
+			add(field, "不能为空")
+			continue
+		}
+		switch t.Type {
+		case TargetLocal:
+			if !filepath.IsAbs(t.Path) {
+				add(field+".path", "本地目录必须是绝对路径")
+			}
+		case TargetSFTP:
+			if t.Host == "" {
+				add(field+".host", "不能为空")
+			}
+			if t.User == "" {
+				add(field+".user", "不能为空")
+			}
+			if t.Port < 0 || t.Port > 65535 {
+				add(field+".port", "无效的端口")
+			}
+		case TargetS3:
+			if u, err := url.Parse(t.Endpoint); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				add(field+".endpoint", "必须是 http 或 https 地址")
+			}
+			if t.Bucket == "" {
+				add(field+".bucket", "不能为空")
+			}
+			if t.AccessKey == "" || t.SecretKey == "" {
+				add(field+".access_key", "需要 access_key 和 secret_key")
+			}
+		default:
+			add(field+".type", "应为 %s、%s 或 %s", TargetLocal, TargetSFTP, TargetS3)
+		}
+	}
+
+	for _, name := range sortedKeys(c.Jobs) {
+		j, field := c.Jobs[name], "jobs."+name
+		if !namePattern.MatchString(name) {
+			add(field, "无效的任务名")
+		}
+		if j == nil {
+			add(field, "不能为空")
+			continue
+		}
+		if _, ok := c.Targets[j.Target]; !ok {
+			add(field+".target", "存储目标 %q 不存在", j.Target)
+		}
+		if len(j.Sources) == 0 {
+			add(field+".sources", "至少需要一个数据源")
+		}
+		if j.Retention.KeepLast < 0 || j.Retention.KeepDays < 0 {
+			add(field+".retention", "不能为负数")
+		}
+		if j.Timeout < 0 {
+			add(field+".timeout", "不能为负数")
+		}
+		dumps := make(map[string]bool)
+		for i, s := range j.Sources {
+			sf := fmt.Sprintf("%s.sources[%d]", field, i)
+			switch s.Type {
+			case SourceFiles:
+				if len(s.Paths) == 0 {
+					add(sf+".paths", "至少需要一个路径")
+				}
+				for k, p := range s.Paths {
+					if !filepath.IsAbs(p) {
+						add(fmt.Sprintf("%s.paths[%d]", sf, k), "必须是绝对路径")
+					}
+				}
+				for k, p := range s.Exclude {
+					if _, err := filepath.Match(p, ""); err != nil {
+						add(fmt.Sprintf("%s.exclude[%d]", sf, k), "无效的匹配模式")
+					}
+				}
+			case SourceMySQL, SourcePostgres:
+				name := s.dumpName()
+				if !namePattern.MatchString(name) {
+					add(sf+".name", "无效的备份名 %q", name)
+				} else if dumps[name] {
+					add(sf+".name", "备份名 %q 重复", name)
+				}
+				dumps[name] = true
+				if s.Port < 0 || s.Port > 65535 {
+					add(sf+".port", "无效的端口")
+				}
+			default:
+				add(sf+".type", "应为 %s、%s 或 %s", SourceFiles, SourceMySQL, SourcePostgres)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "备份配置无效", fields)
+	}
+	return nil
+}
+
+// timeout 单次备份的超时
+func (j *Job) timeout() time.Duration {
+	if j.Timeout > 0 {
+		return time.Duration(j.Timeout) * time.Second
+	}
+	return defaultJobTimeout
+}
+
+// dumpName 数据库备份在归档中的名称
+func (s *Source) dumpName() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Database != "":
+		return s.Database
+	default:
+		return s.Type
+	}
+}
+
+// redacted 去掉凭据的副本，用于对外返回
+func (t *Target) redacted() *Target {
+	out := *t
+	if out.SecretKey != "" {
+		out.SecretKey = redactedValue
+	}
+	return &out
+}
+
+// redactedValue 对外返回时替换凭据的占位值
+const redactedValue = "********"
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// maxCommandStderr 保留的命令错误输出上限
+const maxCommandStderr = 4 << 10
+
+// dumpDatabase 用 mysqldump、pg_dump 或 pg_dumpall 把数据库导出为 SQL 文件
+func (m *Manager) dumpDatabase(ctx context.Context, s *Source, dest string) error {
+	var name string
+	var args []string
+	switch s.Type {
+	case SourceMySQL:
+		name = "mysqldump"
+		args = append(mysqlArgs(s), "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob")
+		if s.Database != "" {
+			args = append(args, "--databases", s.Database)
+		} else {
+			args = append(args, "--all-databases")
+		}
+	case SourcePostgres:
+		name = "pg_dumpall"
+		args = postgresArgs(s)
+		if s.Database != "" {
+			name = "pg_dump"
+			args = append(args, "--clean", "--if-exists", "--dbname", s.Database)
+		} else {
+			args = append(args, "--clean", "--if-exists")
+		}
+	default:
+		return fmt.Errorf("不支持的数据源类型: %s", s.Type)
+	}
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := m.runCommand(ctx, s, name, args, nil, out); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// restoreDatabase 用 mysql 或 psql 导入 SQL 文件
+func (m *Manager) restoreDatabase(ctx context.Context, s *Source, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	switch s.Type {
+	case SourceMySQL:
+		return m.runCommand(ctx, s, "mysql", mysqlArgs(s), in, nil)
+	case SourcePostgres:
+		// pg_dump 的导出不含建库语句，导入到原数据库；pg_dumpall 的导出连接 postgres 库后自行建库
+		database := s.Database
+		if database == "" {
+			database = "postgres"
+		}
+		args := append(postgresArgs(s), "--dbname", database, "--set", "ON_ERROR_STOP=1", "--quiet")
+		return m.runCommand(ctx, s, "psql", args, in, nil)
+	default:
+		return fmt.Errorf("不支持的数据源类型: %s", s.Type)
+	}
+}
+
+// runCommand 执行数据库客户端命令，密码通过环境变量传入
+func (m *Manager) runCommand(ctx context.Context, s *Source, name string, args []string, stdin *os.File, stdout *os.File) error {
+	bin, err := m.env.LookPath(name)
+	if err != nil {
+		return err
+	}
+
+	stderr := &limitedWriter{max: maxCommandStderr}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = m.env.DataDir
+	cmd.Env = os.Environ()
+	if s.Password != "" {
+		switch s.Type {
+		case SourceMySQL:
+			cmd.Env = append(cmd.Env, "MYSQL_PWD="+s.Password)
+		case SourcePostgres:
+			cmd.Env = append(cmd.Env, "PGPASSWORD="+s.Password)
+		}
+	}
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s 失败: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func mysqlArgs(s *Source) []string {
+	var args []string
+	if s.Host != "" {
+		args = append(args, "--host", s.Host)
+	}
+	if s.Port != 0 {
+		args = append(args, "--port", strconv.Itoa(s.Port))
+	}
+	if s.User != "" {
+		args = append(args, "--user", s.User)
+	}
+	return args
+}
+
+func postgresArgs(s *Source) []string {
+	args := []string{"--no-password"}
+	if s.Host != "" {
+		args = append(args, "--host", s.Host)
+	}
+	if s.Port != 0 {
+		args = append(args, "--port", strconv.Itoa(s.Port))
+	}
+	if s.User != "" {
+		args = append(args, "--username", s.User)
+	}
+	return args
+}
+
+// limitedWriter 只保留前 max 字节
+type limitedWriter struct {
+	bytes.Buffer
+	max int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.max - w.Len(); room > 0 {
+		w.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
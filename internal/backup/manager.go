@@ -0,0 +1,492 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// catalogPrefix 存储中备份记录的键前缀，键为 backups/<备份 ID>
+	catalogPrefix = "backups/"
+	// jobStatePrefix 存储中任务最近一次运行结果的键前缀
+	jobStatePrefix = "jobs/"
+	// maxOperations 保留的最近操作数
+	maxOperations = 50
+	// tmpDirName 数据目录中存放临时归档的子目录
+	tmpDirName = "tmp"
+)
+
+// Store 备份记录的持久化存储，插件的 KV 存储实现该接口
+type Store interface {
+	StorageGet(ctx context.Context, key string) ([]byte, bool, error)
+	StorageSet(ctx context.Context, key string, value []byte) error
+	StorageDelete(ctx context.Context, key string) error
+	StorageList(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Env 备份访问文件、命令和网络的入口，由插件按清单声明的权限提供
+type Env struct {
+	DataDir      string       // 插件数据目录，存放临时归档和 SFTP 的 known_hosts
+	HTTPClient   *http.Client // 访问 S3 兼容存储
+	Store        Store
+	LookPath     func(name string) (string, error) // 解析命令路径并检查执行权限
+	CheckRead    func(path string) error
+	CheckWrite   func(path string) error
+	CheckNetwork func(hostport string) error
+	// OnResult 每次备份、校验和恢复结束后调用，err 为 nil 表示成功
+	OnResult func(op *Operation, err error)
+}
+
+// Backup 一次备份的记录
+type Backup struct {
+	ID          string        `json:"id"`
+	Job         string        `json:"job"`
+	Target      string        `json:"target"`
+	Object      string        `json:"object"` // 存储目标中的对象名
+	Size        int64         `json:"size"`
+	SHA256      string        `json:"sha256"`
+	Files       int           `json:"files"`
+	Skipped     int           `json:"skipped,omitempty"` // 无法读取而跳过的路径数
+	Databases   []string      `json:"databases,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Duration    time.Duration `json:"duration"`
+	VerifiedAt  *time.Time    `json:"verified_at,omitempty"`
+	VerifyError string        `json:"verify_error,omitempty"`
+}
+
+// JobStatus 任务配置及最近一次运行的结果
+type JobStatus struct {
+	Name       string     `json:"name"`
+	Job        *Job       `json:"job"` // 数据库密码已隐藏
+	Running    bool       `json:"running"`
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	LastBackup string     `json:"last_backup,omitempty"` // 最近一次成功备份的 ID
+}
+
+// jobState 持久化的任务运行结果
+type jobState struct {
+	LastRun    time.Time `json:"last_run"`
+	LastError  string    `json:"last_error,omitempty"`
+	LastBackup string    `json:"last_backup,omitempty"`
+}
+
+// Manager 执行备份、校验、恢复和保留策略；同一任务或同一备份同时只能有一个操作
+type Manager struct {
+	env Env
+
+	mu     sync.Mutex
+	config *Config
+	busy   map[string]string // 任务名或备份 ID -> 进行中的操作 ID
+	ops    []*Operation
+	seq    int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建备份管理器
+func NewManager(env Env, config *Config) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		env:    env,
+		config: config,
+		busy:   make(map[string]string),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// SetConfig 更新配置，进行中的操作继续使用旧配置
+func (m *Manager) SetConfig(config *Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+}
+
+// Config 当前配置，调用方不能修改
+func (m *Manager) Config() *Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.config
+}
+
+// Close 取消进行中的操作并等待其结束
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Jobs 全部任务的状态，按名称排序
+func (m *Manager) Jobs(ctx context.Context) []*JobStatus {
+	m.mu.Lock()
+	config := m.config
+	busy := make(map[string]bool, len(m.busy))
+	for k := range m.busy {
+		busy[k] = true
+	}
+	m.mu.Unlock()
+
+	jobs := make([]*JobStatus, 0, len(config.Jobs))
+	for _, name := range sortedKeys(config.Jobs) {
+		status := &JobStatus{Name: name, Job: config.Jobs[name].redacted(), Running: busy[jobKey(name)]}
+		if state, err := m.loadJobState(ctx, name); err == nil && state != nil {
+			status.LastRun = &state.LastRun
+			status.LastError = state.LastError
+			status.LastBackup = state.LastBackup
+		}
+		jobs = append(jobs, status)
+	}
+	return jobs
+}
+
+// Targets 全部存储目标，凭据已隐藏
+func (m *Manager) Targets() map[string]*Target {
+	config := m.Config()
+	targets := make(map[string]*Target, len(config.Targets))
+	for name, t := range config.Targets {
+		targets[name] = t.redacted()
+	}
+	return targets
+}
+
+// Run 运行备份任务并应用保留策略，供定时任务调用；Close 时取消
+func (m *Manager) Run(ctx context.Context, name string) (*Backup, error) {
+	if m.ctx.Err() != nil {
+		return nil, errcode.New(errcode.Unavailable, "备份管理器已关闭")
+	}
+	op, err := m.begin(OpBackup, jobKey(name), name, "")
+	if err != nil {
+		return nil, err
+	}
+	m.wg.Add(1)
+	defer m.wg.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(m.ctx, cancel)
+	defer stop()
+
+	b, err := m.runJob(ctx, name)
+	m.finish(op, b, err)
+	return b, err
+}
+
+// runJob 打包全部数据源、上传归档、保存记录，最后清理超出保留策略的旧备份
+func (m *Manager) runJob(ctx context.Context, name string) (*Backup, error) {
+	config := m.Config()
+	job, ok := config.Jobs[name]
+	if !ok {
+		return nil, errcode.New(errcode.NotFound, "备份任务 %s 不存在", name)
+	}
+	target := config.Targets[job.Target]
+	storage, err := newStorage(target, &m.env)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, job.timeout())
+	defer cancel()
+
+	start := time.Now()
+	id := name + "-" + start.UTC().Format("20060102-150405")
+	if _, found, _ := m.env.Store.StorageGet(ctx, catalogPrefix+id); found {
+		return nil, errcode.New(errcode.AlreadyExists, "备份 %s 已存在，请稍后重试", id)
+	}
+	tmpDir, err := m.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	archivePath := filepath.Join(tmpDir, id+".tar.gz")
+	archive, err := newArchiveWriter(archivePath, archiveManifest{ID: id, Job: name, Hostname: hostname, CreatedAt: start.UTC()})
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archivePath)
+
+	for i := range job.Sources {
+		if err := m.addSource(ctx, archive, &job.Sources[i], tmpDir); err != nil {
+			archive.abort()
+			return nil, err
+		}
+	}
+	size, sum, err := archive.close()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backup{
+		ID:        id,
+		Job:       name,
+		Target:    job.Target,
+		Object:    name + "/" + id + ".tar.gz",
+		Size:      size,
+		SHA256:    sum,
+		Files:     len(archive.manifest.Files) - len(archive.manifest.Databases),
+		Skipped:   archive.skipped,
+		CreatedAt: start.UTC(),
+	}
+	for _, db := range archive.manifest.Databases {
+		b.Databases = append(b.Databases, db.Name)
+	}
+	if err := storage.Upload(ctx, archivePath, b.Object); err != nil {
+		return nil, fmt.Errorf("上传备份失败: %w", err)
+	}
+	b.Duration = time.Since(start)
+	if err := m.saveBackup(ctx, b); err != nil {
+		return nil, err
+	}
+
+	if err := m.applyRetention(ctx, name, job.Retention); err != nil {
+		log.Warn().Err(err).Str("job", name).Msg("清理旧备份失败")
+	}
+	return b, nil
+}
+
+// addSource 把一个数据源写入归档，数据库先导出到临时文件
+func (m *Manager) addSource(ctx context.Context, archive *archiveWriter, s *Source, tmpDir string) error {
+	if s.Type == SourceFiles {
+		for _, p := range s.Paths {
+			if err := archive.addTree(ctx, p, s.Exclude, m.env.CheckRead, m.env.DataDir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	dump := filepath.Join(tmpDir, s.dumpName()+".sql")
+	defer os.Remove(dump)
+	if err := m.dumpDatabase(ctx, s, dump); err != nil {
+		return err
+	}
+	return archive.addDatabase(s, dump)
+}
+
+// Backups 备份记录，job 不为空时只返回该任务的备份，按创建时间从新到旧排序
+func (m *Manager) Backups(ctx context.Context, job string) ([]*Backup, error) {
+	keys, err := m.env.Store.StorageList(ctx, catalogPrefix)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]*Backup, 0, len(keys))
+	for _, key := range keys {
+		b, err := m.GetBackup(ctx, strings.TrimPrefix(key, catalogPrefix))
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("读取备份记录失败")
+			continue
+		}
+		if job == "" || b.Job == job {
+			backups = append(backups, b)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// GetBackup 读取备份记录
+func (m *Manager) GetBackup(ctx context.Context, id string) (*Backup, error) {
+	data, found, err := m.env.Store.StorageGet(ctx, catalogPrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errcode.New(errcode.NotFound, "备份 %s 不存在", id)
+	}
+	var b Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Delete 删除备份及其记录
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	op, err := m.begin(OpDelete, backupKey(id), "", id)
+	if err != nil {
+		return err
+	}
+	err = m.deleteBackup(ctx, id)
+	m.finish(op, nil, err)
+	return err
+}
+
+func (m *Manager) deleteBackup(ctx context.Context, id string) error {
+	b, err := m.GetBackup(ctx, id)
+	if err != nil {
+		return err
+	}
+	target, ok := m.Config().Targets[b.Target]
+	if !ok {
+		return errcode.New(errcode.NotFound, "存储目标 %s 已从配置中删除", b.Target)
+	}
+	storage, err := newStorage(target, &m.env)
+	if err != nil {
+		return err
+	}
+	if err := storage.Delete(ctx, b.Object); err != nil {
+		return err
+	}
+	return m.env.Store.StorageDelete(ctx, catalogPrefix+id)
+}
+
+// applyRetention 删除超出保留策略的备份，进行中的操作涉及的备份被保留
+func (m *Manager) applyRetention(ctx context.Context, job string, r Retention) error {
+	if r.KeepLast == 0 && r.KeepDays == 0 {
+		return nil
+	}
+	backups, err := m.Backups(ctx, job)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().AddDate(0, 0, -r.KeepDays)
+	for i, b := range backups {
+		if i < r.KeepLast || (r.KeepDays > 0 && b.CreatedAt.After(cutoff)) {
+			continue
+		}
+		if err := m.Delete(ctx, b.ID); err != nil {
+			log.Warn().Err(err).Str("backup", b.ID).Msg("删除过期备份失败")
+			continue
+		}
+		log.Info().Str("backup", b.ID).Msg("已按保留策略删除备份")
+	}
+	return nil
+}
+
+// Verify 下载备份并校验归档摘要和其中每个文件的摘要，结果记录到备份记录中
+func (m *Manager) Verify(ctx context.Context, id string) (*Backup, error) {
+	op, err := m.begin(OpVerify, backupKey(id), "", id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := m.verify(ctx, id)
+	m.finish(op, b, err)
+	return b, err
+}
+
+func (m *Manager) verify(ctx context.Context, id string) (*Backup, error) {
+	b, path, err := m.download(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	_, verifyErr := verifyArchive(ctx, path, b.SHA256)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	now := time.Now().UTC()
+	b.VerifiedAt = &now
+	b.VerifyError = ""
+	if verifyErr != nil {
+		b.VerifyError = verifyErr.Error()
+	}
+	if err := m.saveBackup(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, verifyErr
+}
+
+// download 把备份下载到临时文件
+func (m *Manager) download(ctx context.Context, id string) (*Backup, string, error) {
+	b, err := m.GetBackup(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	target, ok := m.Config().Targets[b.Target]
+	if !ok {
+		return nil, "", errcode.New(errcode.NotFound, "存储目标 %s 已从配置中删除", b.Target)
+	}
+	storage, err := newStorage(target, &m.env)
+	if err != nil {
+		return nil, "", err
+	}
+	tmpDir, err := m.tmpDir()
+	if err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(tmpDir, id+".download.tar.gz")
+	if err := storage.Download(ctx, b.Object, path); err != nil {
+		os.Remove(path)
+		return nil, "", fmt.Errorf("下载备份失败: %w", err)
+	}
+	return b, path, nil
+}
+
+func (m *Manager) saveBackup(ctx context.Context, b *Backup) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return m.env.Store.StorageSet(ctx, catalogPrefix+b.ID, data)
+}
+
+func (m *Manager) loadJobState(ctx context.Context, name string) (*jobState, error) {
+	data, found, err := m.env.Store.StorageGet(ctx, jobStatePrefix+name)
+	if err != nil || !found {
+		return nil, err
+	}
+	var state jobState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// saveJobState 记录任务最近一次运行的结果，失败时保留上次成功的备份 ID
+func (m *Manager) saveJobState(name string, b *Backup, runErr error) {
+	ctx := context.Background()
+	state, _ := m.loadJobState(ctx, name)
+	if state == nil {
+		state = &jobState{}
+	}
+	state.LastRun = time.Now().UTC()
+	state.LastError = ""
+	if runErr != nil {
+		state.LastError = runErr.Error()
+	} else if b != nil {
+		state.LastBackup = b.ID
+	}
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = m.env.Store.StorageSet(ctx, jobStatePrefix+name, data)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("job", name).Msg("保存备份任务状态失败")
+	}
+}
+
+// tmpDir 数据目录中存放临时归档的目录
+func (m *Manager) tmpDir() (string, error) {
+	dir := filepath.Join(m.env.DataDir, tmpDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// redacted 隐藏数据库密码的副本，用于对外返回
+func (j *Job) redacted() *Job {
+	out := *j
+	out.Sources = make([]Source, len(j.Sources))
+	for i, s := range j.Sources {
+		if s.Password != "" {
+			s.Password = redactedValue
+		}
+		out.Sources[i] = s
+	}
+	return &out
+}
+
+func jobKey(name string) string  { return "job:" + name }
+func backupKey(id string) string { return "backup:" + id }
@@ -0,0 +1,196 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 操作类型
+const (
+	OpBackup  = "backup"
+	OpVerify  = "verify"
+	OpRestore = "restore"
+	OpDelete  = "delete"
+)
+
+// 操作状态
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Operation 一次备份、校验、恢复或删除操作
+type Operation struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	Job        string     `json:"job,omitempty"`
+	Backup     string     `json:"backup,omitempty"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Result     any        `json:"result,omitempty"` // 备份和校验为 *Backup，恢复为 *RestoreResult
+
+	key string
+}
+
+// begin 登记操作，key 对应的任务或备份已有进行中的操作时返回错误
+func (m *Manager) begin(typ, key, job, backup string) (*Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if opID, ok := m.busy[key]; ok {
+		return nil, errcode.New(errcode.AlreadyExists, "%s 正在执行操作 %s", key, opID)
+	}
+	m.seq++
+	op := &Operation{
+		ID:        fmt.Sprintf("%s-%d", typ, m.seq),
+		Type:      typ,
+		Job:       job,
+		Backup:    backup,
+		Status:    StatusRunning,
+		StartedAt: time.Now().UTC(),
+		key:       key,
+	}
+	m.busy[key] = op.ID
+	m.ops = append(m.ops, op)
+	if len(m.ops) > maxOperations {
+		m.ops = slices.Clone(m.ops[len(m.ops)-maxOperations:])
+	}
+	return op, nil
+}
+
+// finish 记录操作结果并通知插件
+func (m *Manager) finish(op *Operation, result any, err error) {
+	m.mu.Lock()
+	now := time.Now().UTC()
+	op.FinishedAt = &now
+	if err != nil {
+		op.Status = StatusFailed
+		op.Error = err.Error()
+	} else {
+		op.Status = StatusSucceeded
+		op.Result = result
+	}
+	delete(m.busy, op.key)
+	m.mu.Unlock()
+
+	if op.Type == OpBackup {
+		b, _ := result.(*Backup)
+		m.saveJobState(op.Job, b, err)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("op", op.ID).Msg("备份操作失败")
+	} else {
+		log.Info().Str("op", op.ID).Msg("备份操作完成")
+	}
+	if m.env.OnResult != nil {
+		m.env.OnResult(op.snapshot(), err)
+	}
+}
+
+// Operations 最近的操作，从新到旧
+func (m *Manager) Operations() []*Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ops := make([]*Operation, 0, len(m.ops))
+	for i := len(m.ops) - 1; i >= 0; i-- {
+		ops = append(ops, m.ops[i].snapshot())
+	}
+	return ops
+}
+
+// GetOperation 按 ID 查找最近的操作
+func (m *Manager) GetOperation(id string) (*Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range m.ops {
+		if op.ID == id {
+			return op.snapshot(), nil
+		}
+	}
+	return nil, errcode.New(errcode.NotFound, "操作 %s 不存在", id)
+}
+
+// snapshot 操作的副本，需要持有 Manager 的锁或操作已结束
+func (op *Operation) snapshot() *Operation {
+	out := *op
+	return &out
+}
+
+// StartBackup 在后台运行备份任务，立即返回操作
+func (m *Manager) StartBackup(name string) (*Operation, error) {
+	config := m.Config()
+	job, ok := config.Jobs[name]
+	if !ok {
+		return nil, errcode.New(errcode.NotFound, "备份任务 %s 不存在", name)
+	}
+	op, err := m.begin(OpBackup, jobKey(name), name, "")
+	if err != nil {
+		return nil, err
+	}
+	m.background(job.timeout(), func(ctx context.Context) {
+		b, err := m.runJob(ctx, name)
+		m.finish(op, b, err)
+	})
+	return op.snapshotLocked(m), nil
+}
+
+// StartVerify 在后台校验备份
+func (m *Manager) StartVerify(id string) (*Operation, error) {
+	if _, err := m.GetBackup(context.Background(), id); err != nil {
+		return nil, err
+	}
+	op, err := m.begin(OpVerify, backupKey(id), "", id)
+	if err != nil {
+		return nil, err
+	}
+	m.background(defaultJobTimeout, func(ctx context.Context) {
+		b, err := m.verify(ctx, id)
+		m.finish(op, b, err)
+	})
+	return op.snapshotLocked(m), nil
+}
+
+// StartRestore 在后台恢复备份
+func (m *Manager) StartRestore(id string, opts RestoreOptions) (*Operation, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	b, err := m.GetBackup(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	op, err := m.begin(OpRestore, backupKey(id), b.Job, id)
+	if err != nil {
+		return nil, err
+	}
+	m.background(defaultJobTimeout, func(ctx context.Context) {
+		result, err := m.restore(ctx, id, opts)
+		m.finish(op, result, err)
+	})
+	return op.snapshotLocked(m), nil
+}
+
+// background 在 Manager 的生命周期内运行操作，Close 时取消
+func (m *Manager) background(timeout time.Duration, fn func(ctx context.Context)) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ctx, cancel := context.WithTimeout(m.ctx, timeout)
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// snapshotLocked 加锁复制操作，操作可能正在后台更新
+func (op *Operation) snapshotLocked(m *Manager) *Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return op.snapshot()
+}
@@ -0,0 +1,206 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// RestoreOptions 恢复选项，Files 和 Databases 至少选择一项
+type RestoreOptions struct {
+	Files     bool     `json:"files"`
+	Databases bool     `json:"databases"`            // 使用任务当前配置中同名数据源的连接信息导入
+	TargetDir string   `json:"target_dir,omitempty"` // 文件恢复到该目录下并保留原绝对路径作为子路径，为空时覆盖原路径
+	Paths     []string `json:"paths,omitempty"`      // 只恢复这些原路径及其下的文件，为空时恢复全部文件
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Files     int      `json:"files"` // 恢复的文件、目录和符号链接数
+	Databases []string `json:"databases,omitempty"`
+	Skipped   int      `json:"skipped,omitempty"` // 无写入权限或类型不支持而跳过的文件数
+}
+
+func (o *RestoreOptions) validate() error {
+	if !o.Files && !o.Databases {
+		return errcode.New(errcode.InvalidArgument, "至少选择恢复文件或数据库")
+	}
+	if o.TargetDir != "" && !filepath.IsAbs(o.TargetDir) {
+		return errcode.New(errcode.InvalidArgument, "恢复目录必须是绝对路径")
+	}
+	for _, p := range o.Paths {
+		if !filepath.IsAbs(p) {
+			return errcode.New(errcode.InvalidArgument, "恢复路径 %s 必须是绝对路径", p)
+		}
+	}
+	return nil
+}
+
+// wants 原路径是否在要恢复的范围内
+func (o *RestoreOptions) wants(orig string) bool {
+	if len(o.Paths) == 0 {
+		return true
+	}
+	for _, p := range o.Paths {
+		p = filepath.Clean(p)
+		if orig == p || strings.HasPrefix(orig, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// restore 下载并校验备份，校验通过后恢复文件和数据库
+func (m *Manager) restore(ctx context.Context, id string, opts RestoreOptions) (*RestoreResult, error) {
+	b, path, err := m.download(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	manifest, err := verifyArchive(ctx, path, b.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("备份校验失败，已取消恢复: %w", err)
+	}
+
+	sources := make(map[string]*Source)
+	if opts.Databases {
+		job, ok := m.Config().Jobs[b.Job]
+		for _, db := range manifest.Databases {
+			if !ok {
+				return nil, errcode.New(errcode.NotFound, "备份任务 %s 已从配置中删除，无法获取数据库连接信息", b.Job)
+			}
+			for i := range job.Sources {
+				if s := &job.Sources[i]; s.Type == db.Type && s.dumpName() == db.Name {
+					sources[db.Name] = s
+				}
+			}
+			if sources[db.Name] == nil {
+				return nil, errcode.New(errcode.NotFound, "任务 %s 中没有数据库 %s 的连接配置", b.Job, db.Name)
+			}
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tmpDir, err := m.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{}
+	err = readArchive(ctx, f, func(hdr *tar.Header, r io.Reader) error {
+		switch {
+		case opts.Files && strings.HasPrefix(hdr.Name, archiveFilesDir):
+			return m.restoreFile(hdr, r, &opts, result)
+		case opts.Databases && strings.HasPrefix(hdr.Name, archiveDatabasesDir):
+			name := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, archiveDatabasesDir), ".sql")
+			s := sources[name]
+			if s == nil {
+				return nil
+			}
+			dump := filepath.Join(tmpDir, id+"-"+name+".sql")
+			defer os.Remove(dump)
+			if err := writeFile(dump, r, 0600); err != nil {
+				return err
+			}
+			if err := m.restoreDatabase(ctx, s, dump); err != nil {
+				return fmt.Errorf("恢复数据库 %s 失败: %w", name, err)
+			}
+			result.Databases = append(result.Databases, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// restoreFile 恢复一个文件、目录或符号链接，文件先写入同目录的临时文件再替换
+func (m *Manager) restoreFile(hdr *tar.Header, r io.Reader, opts *RestoreOptions, result *RestoreResult) error {
+	orig := "/" + strings.TrimSuffix(strings.TrimPrefix(hdr.Name, archiveFilesDir), "/")
+	if !opts.wants(filepath.FromSlash(orig)) {
+		return nil
+	}
+	dest := filepath.FromSlash(orig)
+	if opts.TargetDir != "" {
+		dest = filepath.Join(opts.TargetDir, dest)
+	}
+	if err := m.env.CheckWrite(dest); err != nil {
+		log.Warn().Err(err).Str("path", dest).Msg("跳过无写入权限的文件")
+		result.Skipped++
+		return nil
+	}
+
+	mode := os.FileMode(hdr.Mode).Perm()
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(dest, mode|0700); err != nil {
+			return err
+		}
+	case tar.TypeSymlink:
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if info, err := os.Lstat(dest); err == nil && !info.IsDir() {
+			os.Remove(dest)
+		}
+		if err := os.Symlink(hdr.Linkname, dest); err != nil {
+			return err
+		}
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		tmp := filepath.Join(filepath.Dir(dest), ".runixo-restore-"+filepath.Base(dest))
+		if err := writeFile(tmp, r, mode); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		os.Chtimes(tmp, hdr.ModTime, hdr.ModTime)
+		if err := os.Rename(tmp, dest); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	default:
+		result.Skipped++
+		return nil
+	}
+	// 以 root 运行时恢复文件属主，失败时保留 Agent 运行用户
+	if os.Geteuid() == 0 {
+		os.Lchown(dest, hdr.Uid, hdr.Gid)
+	}
+	result.Files++
+	return nil
+}
+
+// writeFile 把 r 的内容写入新文件并同步到磁盘
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// 不受 umask 影响
+	return os.Chmod(path, mode)
+}
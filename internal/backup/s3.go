@@ -0,0 +1,318 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// s3PartSize 分段上传的分段大小，小于该大小的文件一次上传
+	s3PartSize = 64 << 20
+	// s3MaxParts S3 分段上传的分段数上限
+	s3MaxParts = 10000
+	// s3DefaultRegion 未配置区域时使用的签名区域
+	s3DefaultRegion = "us-east-1"
+)
+
+// s3Storage S3 兼容的对象存储，请求使用 AWS Signature V4 签名
+type s3Storage struct {
+	target   *Target
+	client   *http.Client
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+func newS3Storage(t *Target, env *Env) (*s3Storage, error) {
+	endpoint, err := url.Parse(t.Endpoint)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "无效的 S3 地址")
+	}
+	return &s3Storage{target: t, client: env.HTTPClient, endpoint: endpoint, now: time.Now}, nil
+}
+
+func (s *s3Storage) Upload(ctx context.Context, local, name string) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() <= s3PartSize {
+		hash, err := hashSection(ctx, io.NewSectionReader(f, 0, info.Size()))
+		if err != nil {
+			return err
+		}
+		_, err = s.do(ctx, http.MethodPut, name, nil, io.NewSectionReader(f, 0, info.Size()), info.Size(), hash)
+		return err
+	}
+	if info.Size() > s3PartSize*s3MaxParts {
+		return errcode.New(errcode.InvalidArgument, "备份超过 S3 分段上传的大小上限")
+	}
+	return s.uploadMultipart(ctx, f, info.Size(), name)
+}
+
+// uploadMultipart 分段上传，失败时中止上传以释放已上传的分段
+func (s *s3Storage) uploadMultipart(ctx context.Context, f *os.File, size int64, name string) error {
+	body, err := s.do(ctx, http.MethodPost, name, url.Values{"uploads": {""}}, nil, 0, emptySHA256)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(body, &initiated); err != nil || initiated.UploadID == "" {
+		return errcode.New(errcode.UpstreamUnavailable, "S3 未返回 UploadId")
+	}
+
+	type part struct {
+		Number int    `xml:"PartNumber"`
+		ETag   string `xml:"ETag"`
+	}
+	var parts []part
+	err = func() error {
+		for offset, number := int64(0), 1; offset < size; offset, number = offset+s3PartSize, number+1 {
+			n := min(s3PartSize, size-offset)
+			hash, err := hashSection(ctx, io.NewSectionReader(f, offset, n))
+			if err != nil {
+				return err
+			}
+			query := url.Values{"partNumber": {fmt.Sprint(number)}, "uploadId": {initiated.UploadID}}
+			resp, err := s.request(ctx, http.MethodPut, name, query, io.NewSectionReader(f, offset, n), n, hash)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			parts = append(parts, part{Number: number, ETag: resp.Header.Get("ETag")})
+		}
+
+		complete, err := xml.Marshal(struct {
+			XMLName xml.Name `xml:"CompleteMultipartUpload"`
+			Parts   []part   `xml:"Part"`
+		}{Parts: parts})
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(complete)
+		body, err := s.do(ctx, http.MethodPost, name, url.Values{"uploadId": {initiated.UploadID}},
+			bytes.NewReader(complete), int64(len(complete)), hex.EncodeToString(hash[:]))
+		if err != nil {
+			return err
+		}
+		// CompleteMultipartUpload 可能在 200 响应中返回错误
+		if s3Err := parseS3Error(body); s3Err != "" {
+			return errcode.New(errcode.UpstreamUnavailable, "S3 合并分段失败: %s", s3Err)
+		}
+		return nil
+	}()
+	if err != nil {
+		abortCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		s.do(abortCtx, http.MethodDelete, name, url.Values{"uploadId": {initiated.UploadID}}, nil, 0, emptySHA256)
+	}
+	return err
+}
+
+func (s *s3Storage) Download(ctx context.Context, name, local string) error {
+	resp, err := s.request(ctx, http.MethodGet, name, nil, nil, 0, emptySHA256)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out, err := os.OpenFile(local, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return errcode.Wrap(errcode.UpstreamUnavailable, err, "下载备份失败")
+	}
+	return out.Close()
+}
+
+func (s *s3Storage) Delete(ctx context.Context, name string) error {
+	_, err := s.do(ctx, http.MethodDelete, name, nil, nil, 0, emptySHA256)
+	if errcode.Of(err) == errcode.NotFound {
+		return nil
+	}
+	return err
+}
+
+// do 发送请求并读取整个响应体
+func (s *s3Storage) do(ctx context.Context, method, name string, query url.Values, body io.Reader, size int64, payloadHash string) ([]byte, error) {
+	resp, err := s.request(ctx, method, name, query, body, size, payloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// request 发送签名的请求，非 2xx 响应转为错误
+func (s *s3Storage) request(ctx context.Context, method, name string, query url.Values, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	u := *s.endpoint
+	key := strings.TrimPrefix(strings.TrimSuffix(s.target.Prefix, "/")+"/"+name, "/")
+	if s.target.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.target.Bucket + "/" + key
+	} else {
+		u.Host = s.target.Bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	u.RawPath = s3Escape(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	s.sign(req, payloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "S3 请求失败")
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		msg := parseS3Error(data)
+		if msg == "" {
+			msg = resp.Status
+		}
+		code := errcode.UpstreamUnavailable
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			code = errcode.NotFound
+		case http.StatusForbidden:
+			code = errcode.PermissionDenied
+		}
+		return nil, errcode.New(code, "S3 %s %s: %s", method, key, msg)
+	}
+	return resp, nil
+}
+
+// sign 按 AWS Signature V4 签名请求，签名 Host 和请求中已设置的全部头
+func (s *s3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	region := s.target.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := sortedKeys(headers)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.target.SecretKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.target.AccessKey, scope, signedHeaders, signature))
+}
+
+// emptySHA256 空请求体的 SHA-256
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// hashSection 计算请求体的 SHA-256，签名需要在发送前得到
+func hashSection(ctx context.Context, r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, &ctxReader{ctx: ctx, r: r}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalQuery 按键排序并按 S3 规则编码的查询字符串，同时用作请求的查询字符串
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape 除 A-Za-z0-9-_.~ 外的字节都按 %XX 编码，encodeSlash 为 false 时保留 "/"
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// parseS3Error 提取 S3 错误响应中的错误码和消息，不是错误响应时返回空字符串
+func parseS3Error(data []byte) string {
+	var e struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}
+	if xml.Unmarshal(data, &e) != nil || e.Code == "" {
+		return ""
+	}
+	return strings.TrimSpace(e.Code + " " + e.Message)
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// sftpStorage 通过系统的 sftp 命令（OpenSSH）以批处理模式访问远端目录
+type sftpStorage struct {
+	target *Target
+	env    *Env
+}
+
+func newSFTPStorage(t *Target, env *Env) (*sftpStorage, error) {
+	return &sftpStorage{target: t, env: env}, nil
+}
+
+func (s *sftpStorage) Upload(ctx context.Context, local, name string) error {
+	remote := s.remotePath(name)
+	// 逐级创建目录，已存在时忽略错误（命令前的 "-"）
+	var batch []string
+	for dir := path.Dir(remote); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		batch = append([]string{"-mkdir " + sftpQuote(dir)}, batch...)
+	}
+	batch = append(batch,
+		"put "+sftpQuote(local)+" "+sftpQuote(remote+".part"),
+		"rename "+sftpQuote(remote+".part")+" "+sftpQuote(remote),
+	)
+	return s.run(ctx, batch)
+}
+
+func (s *sftpStorage) Download(ctx context.Context, name, local string) error {
+	return s.run(ctx, []string{"get " + sftpQuote(s.remotePath(name)) + " " + sftpQuote(local)})
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, name string) error {
+	return s.run(ctx, []string{"-rm " + sftpQuote(s.remotePath(name))})
+}
+
+func (s *sftpStorage) remotePath(name string) string {
+	if s.target.Path == "" {
+		return name
+	}
+	return path.Join(s.target.Path, name)
+}
+
+// run 执行 sftp 批处理命令，任一命令（不以 "-" 开头的）失败时 sftp 以非 0 状态退出
+func (s *sftpStorage) run(ctx context.Context, batch []string) error {
+	t := s.target
+	port := t.Port
+	if port == 0 {
+		port = 22
+	}
+	if err := s.env.CheckNetwork(net.JoinHostPort(t.Host, strconv.Itoa(port))); err != nil {
+		return err
+	}
+	bin, err := s.env.LookPath("sftp")
+	if err != nil {
+		return err
+	}
+
+	knownHosts := t.KnownHosts
+	if knownHosts == "" {
+		knownHosts = filepath.Join(s.env.DataDir, "known_hosts")
+	}
+	args := []string{
+		"-b", "-",
+		"-P", strconv.Itoa(port),
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=30",
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-o", "UserKnownHostsFile=" + knownHosts,
+	}
+	if t.IdentityFile != "" {
+		if err := s.env.CheckRead(t.IdentityFile); err != nil {
+			return err
+		}
+		args = append(args, "-i", t.IdentityFile)
+	}
+	args = append(args, t.User+"@"+t.Host)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = s.env.DataDir
+	cmd.Stdin = strings.NewReader(strings.Join(batch, "\n") + "\n")
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "not found") || strings.Contains(msg, "No such file") {
+			return errcode.New(errcode.NotFound, "SFTP: %s", msg)
+		}
+		return errcode.Wrap(errcode.UpstreamUnavailable, err, fmt.Sprintf("SFTP 操作失败: %s", msg))
+	}
+	return nil
+}
+
+// sftpQuote 为批处理命令的参数加双引号
+func sftpQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
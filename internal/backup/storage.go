@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// Storage 备份存储目标，name 为 <任务>/<备份 ID>.tar.gz 形式的对象名
+type Storage interface {
+	// Upload 上传本地文件，完成前不会出现同名的不完整对象
+	Upload(ctx context.Context, local, name string) error
+	// Download 下载对象到本地文件
+	Download(ctx context.Context, name, local string) error
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, name string) error
+}
+
+// newStorage 按存储目标类型创建 Storage
+func newStorage(t *Target, env *Env) (Storage, error) {
+	switch t.Type {
+	case TargetLocal:
+		return &localStorage{dir: t.Path, env: env}, nil
+	case TargetSFTP:
+		return newSFTPStorage(t, env)
+	case TargetS3:
+		return newS3Storage(t, env)
+	default:
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的存储目标类型: %s", t.Type)
+	}
+}
+
+// localStorage 本地目录（也可以是挂载的 NFS 等网络文件系统）
+type localStorage struct {
+	dir string
+	env *Env
+}
+
+func (s *localStorage) Upload(ctx context.Context, local, name string) error {
+	dest := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := s.env.CheckWrite(dest); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	tmp := dest + ".part"
+	if err := copyFile(ctx, local, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func (s *localStorage) Download(ctx context.Context, name, local string) error {
+	src := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := s.env.CheckRead(src); err != nil {
+		return err
+	}
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return errcode.New(errcode.NotFound, "备份文件 %s 不存在", src)
+	}
+	return copyFile(ctx, src, local)
+}
+
+func (s *localStorage) Delete(_ context.Context, name string) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := s.env.CheckWrite(path); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// copyFile 复制文件并同步到磁盘，ctx 取消时中止
+func copyFile(ctx context.Context, src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, &ctxReader{ctx: ctx, r: in}); err != nil {
+		out.Close()
+		return fmt.Errorf("复制 %s 失败: %w", src, err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ctxReader ctx 取消后读取返回 ctx 的错误，用于中止大文件的复制和上传
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
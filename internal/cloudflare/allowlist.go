@@ -0,0 +1,212 @@
+// Package cloudflare 白名单：永不被自动封禁的 IP 和网段
+package cloudflare
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// AllowEntry 白名单条目
+type AllowEntry struct {
+	Value   string    `json:"value"` // IP 或 CIDR 网段
+	Note    string    `json:"note,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+	network *net.IPNet
+}
+
+// Allowlist 白名单，检测器不记录其中 IP 的活动，封禁器不封禁其中的 IP。
+// 保存在数据目录的 allowlist.json
+type Allowlist struct {
+	path    string
+	entries map[string]*AllowEntry
+	mu      sync.RWMutex
+}
+
+// NewAllowlist 创建白名单并加载 dataPath 中保存的条目
+func NewAllowlist(dataPath string) *Allowlist {
+	a := &Allowlist{
+		path:    filepath.Join(dataPath, "allowlist.json"),
+		entries: make(map[string]*AllowEntry),
+	}
+	a.load()
+	return a
+}
+
+// parseAllowValue 把 IP 或 CIDR 转为规范形式和对应的网段，单个 IP 视为 /32 或 /128
+func parseAllowValue(value string) (string, *net.IPNet, error) {
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network.String(), network, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return "", nil, errcode.New(errcode.InvalidArgument, "无效的 IP 或网段: %s", value)
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	return ip.String(), &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// Contains 检查 IP 是否在白名单中
+func (a *Allowlist) Contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, entry := range a.entries {
+		if entry.network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps IP 或网段是否在白名单中或与白名单中的网段重叠
+func (a *Allowlist) Overlaps(value string) bool {
+	_, network, err := parseAllowValue(value)
+	if err != nil {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, entry := range a.entries {
+		if networksOverlap(entry.network, network) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 添加 IP 或网段，已存在时更新备注
+func (a *Allowlist) Add(value, note string) (*AllowEntry, error) {
+	key, network, err := parseAllowValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, exists := a.entries[key]
+	if !exists {
+		entry = &AllowEntry{Value: key, AddedAt: time.Now(), network: network}
+		a.entries[key] = entry
+	}
+	entry.Note = note
+	if err := a.saveLocked(); err != nil {
+		if !exists {
+			delete(a.entries, key)
+		}
+		return nil, err
+	}
+
+	log.Info().Str("value", key).Msg("已添加到白名单")
+	return entry, nil
+}
+
+// Remove 从白名单移除 IP 或网段
+func (a *Allowlist) Remove(value string) error {
+	key, _, err := parseAllowValue(value)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, exists := a.entries[key]
+	if !exists {
+		return errcode.New(errcode.NotFound, "%s 不在白名单中", key)
+	}
+	delete(a.entries, key)
+	if err := a.saveLocked(); err != nil {
+		a.entries[key] = entry
+		return err
+	}
+
+	log.Info().Str("value", key).Msg("已从白名单移除")
+	return nil
+}
+
+// Entries 返回所有条目，按添加时间排序
+func (a *Allowlist) Entries() []*AllowEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make([]*AllowEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AddedAt.Before(result[j].AddedAt) })
+	return result
+}
+
+// load 从文件加载白名单，无效的条目被跳过
+func (a *Allowlist) load() {
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Error().Err(err).Msg("加载白名单失败")
+		}
+		return
+	}
+
+	var entries []*AllowEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Error().Err(err).Msg("解析白名单失败")
+		return
+	}
+
+	for _, entry := range entries {
+		key, network, err := parseAllowValue(entry.Value)
+		if err != nil {
+			log.Warn().Err(err).Msg("跳过白名单条目")
+			continue
+		}
+		entry.Value, entry.network = key, network
+		a.entries[key] = entry
+	}
+	log.Info().Int("count", len(a.entries)).Msg("已加载白名单")
+}
+
+// saveLocked 保存白名单到文件（需要持有锁），写入临时文件后替换
+func (a *Allowlist) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return errcode.Wrap(errcode.Internal, err, "创建数据目录失败")
+	}
+
+	entries := make([]*AllowEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AddedAt.Before(entries[j].AddedAt) })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return errcode.Wrap(errcode.Internal, err, "序列化白名单失败")
+	}
+
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errcode.Wrap(errcode.Internal, err, "保存白名单失败")
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		return errcode.Wrap(errcode.Internal, err, "保存白名单失败")
+	}
+	return nil
+}
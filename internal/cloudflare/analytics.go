@@ -0,0 +1,406 @@
+// Package cloudflare 边缘事件：从 Cloudflare GraphQL Analytics API 拉取防火墙事件，
+// 使在边缘被拦截、不会出现在源站日志中的攻击也计入检测器的评分
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 边缘事件拉取的默认值
+const (
+	defaultAnalyticsInterval   = 60
+	defaultAnalyticsEventScore = 10
+	defaultAnalyticsLimit      = 1000
+	maxAnalyticsLimit          = 10000
+)
+
+// edgeActions 计入评分的事件动作，放行、跳过和通过质询的事件不计入
+var edgeActions = []string{"block", "challenge", "jschallenge", "managedChallenge", "log", "connectionClose"}
+
+// defaultIgnoredSources 默认不计入评分的事件来源：IP、网段、ASN 和国家/地区访问规则，
+// 多数由 Runixo 自己创建，计入会使已封禁的 IP 反复累加分数
+var defaultIgnoredSources = map[string]bool{"ip": true, "ipRange": true, "asn": true, "country": true}
+
+// edgeThreatTypes 事件来源对应的威胁类型，其余来源为 ThreatTypeEdge
+var edgeThreatTypes = map[string]ThreatType{
+	"l7ddos":        ThreatTypeDDoS,
+	"rateLimit":     ThreatTypeDDoS,
+	"botFight":      ThreatTypeBotAbuse,
+	"botManagement": ThreatTypeBotAbuse,
+	"uaBlock":       ThreatTypeBotAbuse,
+	"bic":           ThreatTypeBotAbuse,
+}
+
+// firewallEventsQuery 查询域名在 since 之后的防火墙事件
+const firewallEventsQuery = `query FirewallEvents($zoneTag: string, $since: Time, $limit: uint64, $actions: [string], $ignored: [string]) {
+  viewer {
+    zones(filter: {zoneTag: $zoneTag}) {
+      firewallEventsAdaptive(filter: {datetime_gt: $since, action_in: $actions, source_notin: $ignored}, limit: $limit, orderBy: [datetime_ASC]) {
+        action
+        clientIP
+        clientRequestHTTPMethodName
+        clientRequestHTTPHost
+        clientRequestPath
+        datetime
+        source
+        ruleId
+        rayName
+      }
+    }
+  }
+}`
+
+// AnalyticsConfig 边缘事件配置
+type AnalyticsConfig struct {
+	Enabled bool `json:"enabled"`
+	// 拉取间隔（秒），默认 60
+	Interval int `json:"interval"`
+	// 每个事件计入的分数，默认 10
+	EventScore int `json:"event_score"`
+	// 按事件来源（如 firewallManaged、firewallCustom、l7ddos、rateLimit）覆盖分数，0 表示不计入该来源
+	SourceScores map[string]int `json:"source_scores,omitempty"`
+	// 每个域名每次最多拉取的事件数，默认 1000，最大 10000
+	Limit int `json:"limit"`
+}
+
+// Validate 校验配置
+func (c *AnalyticsConfig) Validate() error {
+	var fields []errcode.FieldViolation
+	if c.Interval < 0 {
+		fields = append(fields, errcode.FieldViolation{Field: "analytics.interval", Description: "不能为负数"})
+	}
+	if c.EventScore < 0 {
+		fields = append(fields, errcode.FieldViolation{Field: "analytics.event_score", Description: "不能为负数"})
+	}
+	if c.Limit < 0 || c.Limit > maxAnalyticsLimit {
+		fields = append(fields, errcode.FieldViolation{Field: "analytics.limit", Description: fmt.Sprintf("必须在 0 到 %d 之间", maxAnalyticsLimit)})
+	}
+	for source, score := range c.SourceScores {
+		if score < 0 {
+			fields = append(fields, errcode.FieldViolation{Field: "analytics.source_scores." + source, Description: "不能为负数"})
+		}
+	}
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "边缘事件配置无效", fields)
+	}
+	return nil
+}
+
+// interval 拉取间隔
+func (c *AnalyticsConfig) interval() time.Duration {
+	if c.Interval > 0 {
+		return time.Duration(c.Interval) * time.Second
+	}
+	return defaultAnalyticsInterval * time.Second
+}
+
+// limit 每次拉取的事件数
+func (c *AnalyticsConfig) limit() int {
+	if c.Limit > 0 {
+		return c.Limit
+	}
+	return defaultAnalyticsLimit
+}
+
+// score 来源为 source 的事件计入的分数
+func (c *AnalyticsConfig) score(source string) int {
+	if score, ok := c.SourceScores[source]; ok {
+		return score
+	}
+	if defaultIgnoredSources[source] {
+		return 0
+	}
+	if c.EventScore > 0 {
+		return c.EventScore
+	}
+	return defaultAnalyticsEventScore
+}
+
+// ignoredSources 不计入评分的来源，在查询中排除
+func (c *AnalyticsConfig) ignoredSources() []string {
+	var sources []string
+	for source := range defaultIgnoredSources {
+		if _, overridden := c.SourceScores[source]; !overridden {
+			sources = append(sources, source)
+		}
+	}
+	for source, score := range c.SourceScores {
+		if score == 0 {
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// FirewallEvent Cloudflare 边缘的防火墙事件
+type FirewallEvent struct {
+	Action   string    `json:"action"`
+	ClientIP string    `json:"clientIP"`
+	Method   string    `json:"clientRequestHTTPMethodName"`
+	Host     string    `json:"clientRequestHTTPHost"`
+	Path     string    `json:"clientRequestPath"`
+	Datetime time.Time `json:"datetime"`
+	Source   string    `json:"source"`
+	RuleID   string    `json:"ruleId"`
+	RayName  string    `json:"rayName"`
+}
+
+// line 事件的文本形式，作为威胁的日志行
+func (e *FirewallEvent) line() string {
+	return fmt.Sprintf("%s cloudflare %s %s %s \"%s %s%s\" ray=%s rule=%s",
+		e.Datetime.Format(time.RFC3339), e.Source, e.Action, e.ClientIP, e.Method, e.Host, e.Path, e.RayName, e.RuleID)
+}
+
+// graphqlError GraphQL API 返回的错误
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphql 调用 Cloudflare GraphQL Analytics API，把 data 解析到 out
+func (c *Client) graphql(query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", CloudflareAPIBase+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return &APIError{Message: result.Errors[0].Message, StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{Message: resp.Status, StatusCode: resp.StatusCode}
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// ListFirewallEvents 列出域名在 since 之后的防火墙事件，按时间升序
+func (c *Client) ListFirewallEvents(zoneID string, since time.Time, limit int, actions, ignoredSources []string) ([]FirewallEvent, error) {
+	var data struct {
+		Viewer struct {
+			Zones []struct {
+				Events []FirewallEvent `json:"firewallEventsAdaptive"`
+			} `json:"zones"`
+		} `json:"viewer"`
+	}
+	variables := map[string]interface{}{
+		"zoneTag": zoneID,
+		"since":   since.UTC().Format(time.RFC3339),
+		"limit":   limit,
+		"actions": actions,
+		"ignored": ignoredSources,
+	}
+	if err := c.graphql(firewallEventsQuery, variables, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Viewer.Zones) == 0 {
+		return nil, nil
+	}
+	return data.Viewer.Zones[0].Events, nil
+}
+
+// AnalyticsStatus 一个域名的边缘事件拉取状态
+type AnalyticsStatus struct {
+	ZoneID    string     `json:"zone_id"`
+	ZoneName  string     `json:"zone_name"`
+	LastFetch *time.Time `json:"last_fetch,omitempty"`
+	// 最近一个已处理事件的时间，下次从这里继续
+	LastEvent *time.Time `json:"last_event,omitempty"`
+	// 累计计入评分的事件数
+	Events int    `json:"events"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AnalyticsIngester 定期拉取启用自动封禁的域名的防火墙事件，计入检测器评分，达到阈值的 IP 交给封禁器
+type AnalyticsIngester struct {
+	client   *Client
+	zones    *ZoneRegistry
+	detector *ThreatDetector
+	blocker  *IPBlocker
+	config   *AnalyticsConfig
+	status   map[string]*AnalyticsStatus
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewAnalyticsIngester 创建边缘事件拉取器
+func NewAnalyticsIngester(client *Client, zones *ZoneRegistry, detector *ThreatDetector, blocker *IPBlocker, config *AnalyticsConfig) *AnalyticsIngester {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AnalyticsIngester{
+		client:   client,
+		zones:    zones,
+		detector: detector,
+		blocker:  blocker,
+		config:   config,
+		status:   make(map[string]*AnalyticsStatus),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start 启动定期拉取
+func (a *AnalyticsIngester) Start() {
+	go a.loop()
+}
+
+// Stop 停止拉取
+func (a *AnalyticsIngester) Stop() {
+	a.cancel()
+}
+
+// loop 每个间隔拉取一次
+func (a *AnalyticsIngester) loop() {
+	ticker := time.NewTicker(a.config.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.Poll(); err != nil {
+				log.Warn().Err(err).Msg("拉取 Cloudflare 边缘事件失败")
+			}
+		}
+	}
+}
+
+// Poll 立即拉取所有启用自动封禁的域名的新事件
+func (a *AnalyticsIngester) Poll() error {
+	zones, err := a.zones.Enabled()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range zones {
+		if err := a.pollZone(&zones[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", zones[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pollZone 拉取一个域名的新事件，首次拉取从一个间隔之前开始
+func (a *AnalyticsIngester) pollZone(zone *Zone) error {
+	a.mu.Lock()
+	status, exists := a.status[zone.ID]
+	if !exists {
+		status = &AnalyticsStatus{ZoneID: zone.ID, ZoneName: zone.Name}
+		a.status[zone.ID] = status
+	}
+	since := time.Now().Add(-a.config.interval())
+	if status.LastEvent != nil {
+		since = *status.LastEvent
+	}
+	a.mu.Unlock()
+
+	limit := a.config.limit()
+	events, err := a.client.ListFirewallEvents(zone.ID, since, limit, edgeActions, a.config.ignoredSources())
+
+	now := time.Now()
+	a.mu.Lock()
+	status.LastFetch = &now
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(events) >= limit {
+		log.Warn().Str("zone", zone.Name).Int("limit", limit).Msg("边缘事件数达到单次拉取上限，部分事件将在下次拉取")
+	}
+
+	counted := 0
+	var last *time.Time
+	for i := range events {
+		event := &events[i]
+		t := event.Datetime
+		last = &t
+		if a.ingest(zone, event) {
+			counted++
+		}
+	}
+
+	a.mu.Lock()
+	if last != nil {
+		status.LastEvent = last
+	}
+	status.Events += counted
+	a.mu.Unlock()
+
+	if counted > 0 {
+		log.Debug().Str("zone", zone.Name).Int("events", counted).Msg("已计入 Cloudflare 边缘事件")
+	}
+	return nil
+}
+
+// ingest 把一个事件计入检测器评分，分数达到阈值时交给封禁器，返回是否计入
+func (a *AnalyticsIngester) ingest(zone *Zone, event *FirewallEvent) bool {
+	score := a.config.score(event.Source)
+	if score == 0 || event.ClientIP == "" || !slices.Contains(edgeActions, event.Action) {
+		return false
+	}
+
+	threatType, ok := edgeThreatTypes[event.Source]
+	if !ok {
+		threatType = ThreatTypeEdge
+	}
+	description := fmt.Sprintf("Cloudflare 边缘拦截: %s %s", event.Source, event.Action)
+
+	// 来源为域名名称，使封禁器在该域名上封禁
+	threat := a.detector.RecordEvent(event.ClientIP, threatType, score, description, event.line(), zone.Name)
+	if threat != nil && a.blocker != nil && threat.Score >= a.detector.config.BlockThreshold {
+		log.Warn().Str("ip", threat.IP).Str("zone", zone.Name).Str("source", event.Source).Int("score", threat.Score).Msg("边缘事件达到封禁阈值")
+		a.blocker.BlockThreat(threat)
+	}
+	return threat != nil
+}
+
+// Status 各域名的拉取状态
+func (a *AnalyticsIngester) Status() []*AnalyticsStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make([]*AnalyticsStatus, 0, len(a.status))
+	for _, status := range a.status {
+		copied := *status
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ZoneName < result[j].ZoneName })
+	return result
+}
@@ -0,0 +1,267 @@
+// Package cloudflare 安全插件的 HTTP 接口
+package cloudflare
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/plugin/httpapi"
+)
+
+// blockRequest 手动封禁请求
+type blockRequest struct {
+	IP string `json:"ip"` // IP 或 CIDR 网段（IPv4 /16、/24，IPv6 /32、/48、/64）
+	// zone ID、名称、其下的主机名、account 或 local（本机防火墙），为空时按配置在账户级别或所有启用的域名上封禁
+	Zone     string `json:"zone"`
+	Reason   string `json:"reason"`
+	Duration int    `json:"duration"` // 秒，0 表示永久
+}
+
+// allowRequest 白名单请求
+type allowRequest struct {
+	Value string `json:"value"` // IP 或 CIDR 网段
+	Note  string `json:"note"`
+}
+
+// requestIP 发起请求的地址，即连接 Agent REST API 的客户端地址，本机和内网地址无法代表管理员的公网 IP
+func requestIP(r *http.Request) (string, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", errcode.New(errcode.InvalidArgument, "无法确定请求地址")
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() {
+		return "", errcode.New(errcode.InvalidArgument, "请求来自本机或内网地址 %s，请直接填写要加入白名单的 IP", ip)
+	}
+	return ip.String(), nil
+}
+
+// Handler 安全插件的 HTTP 接口，挂载在 prefix 下，响应格式与 Agent REST API 一致：
+//
+//	GET    /zones                                API Token 可访问的域名及是否启用自动封禁
+//	POST   /zones/refresh                        立即重新列出域名
+//	POST   /zones/{zone}/enable                  启用域名的自动封禁
+//	POST   /zones/{zone}/disable                 停用域名的自动封禁
+//	GET    /zones/{zone}/waf/rules               列出域名的 WAF 自定义规则
+//	POST   /zones/{zone}/waf/rules               创建 WAF 自定义规则
+//	POST   /zones/{zone}/waf/rules/{id}/enable   启用 WAF 自定义规则
+//	POST   /zones/{zone}/waf/rules/{id}/disable  停用 WAF 自定义规则
+//	DELETE /zones/{zone}/waf/rules/{id}          删除由 Runixo 创建的 WAF 自定义规则
+//	GET    /zones/{zone}/ratelimits              列出域名的速率限制规则
+//	POST   /zones/{zone}/ratelimits              创建速率限制规则
+//	POST   /zones/{zone}/ratelimits/{id}/enable  启用速率限制规则
+//	POST   /zones/{zone}/ratelimits/{id}/disable 停用速率限制规则
+//	DELETE /zones/{zone}/ratelimits/{id}         删除由 Runixo 创建的速率限制规则
+//	GET    /countries                            已应用的国家/地区规则
+//	POST   /countries/sync                       立即同步国家/地区规则
+//	GET    /allowlist                            白名单
+//	POST   /allowlist                            把 IP 或 CIDR 网段加入白名单
+//	POST   /allowlist/me                         把发起请求的地址加入白名单
+//	DELETE /allowlist/{value...}                 从白名单移除
+//	GET    /intel                                威胁情报源的状态
+//	POST   /intel/refresh                        立即重新下载威胁情报列表
+//	GET    /intel/{ip}                           查询 IP 在威胁情报源中的信誉
+//	GET    /analytics                            各域名的边缘事件拉取状态
+//	POST   /analytics/poll                       立即拉取边缘事件
+//	GET    /sync                                 共享封禁列表的同步状态
+//	POST   /sync                                 立即发布并同步共享封禁列表
+//	GET    /sync/list                            本节点签名后的封禁列表，供控制面转发
+//	POST   /sync/list                            应用控制面转发的其他节点的封禁列表
+//	GET    /blocks                               已封禁的 IP 和网段
+//	POST   /blocks                               手动封禁 IP 或网段
+//	DELETE /blocks/{ip...}?zone=                 解封 IP 或网段，不指定 zone 时解除其所有封禁
+//	POST   /blocks/reconcile                     与 Cloudflare 核对封禁记录，移除规则已被删除的记录
+//	GET    /dry-run                              演练模式下将被处理的 IP
+//	DELETE /dry-run                              清空演练报告
+func (sm *SecurityManager) Handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/zones", func(w http.ResponseWriter, r *http.Request) {
+		zones, err := sm.GetZones()
+		httpapi.WriteResult(w, http.StatusOK, zones, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/refresh", func(w http.ResponseWriter, r *http.Request) {
+		zones, err := sm.RefreshZones()
+		httpapi.WriteResult(w, http.StatusOK, zones, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/enable", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.SetZoneEnabled(r.PathValue("zone"), true))
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/disable", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.SetZoneEnabled(r.PathValue("zone"), false))
+	})
+	mux.HandleFunc("GET "+prefix+"/zones/{zone}/waf/rules", func(w http.ResponseWriter, r *http.Request) {
+		rules, err := sm.ListWAFRules(r.PathValue("zone"))
+		httpapi.WriteResult(w, http.StatusOK, rules, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/waf/rules", func(w http.ResponseWriter, r *http.Request) {
+		var req WAFRuleRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		rule, err := sm.CreateWAFRule(r.PathValue("zone"), &req)
+		httpapi.WriteResult(w, http.StatusCreated, rule, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/waf/rules/{id}/enable", func(w http.ResponseWriter, r *http.Request) {
+		rule, err := sm.SetWAFRuleEnabled(r.PathValue("zone"), r.PathValue("id"), true)
+		httpapi.WriteResult(w, http.StatusOK, rule, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/waf/rules/{id}/disable", func(w http.ResponseWriter, r *http.Request) {
+		rule, err := sm.SetWAFRuleEnabled(r.PathValue("zone"), r.PathValue("id"), false)
+		httpapi.WriteResult(w, http.StatusOK, rule, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/zones/{zone}/waf/rules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.DeleteWAFRule(r.PathValue("zone"), r.PathValue("id")))
+	})
+	mux.HandleFunc("GET "+prefix+"/zones/{zone}/ratelimits", func(w http.ResponseWriter, r *http.Request) {
+		rules, err := sm.ListRateLimitRules(r.PathValue("zone"))
+		httpapi.WriteResult(w, http.StatusOK, rules, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/ratelimits", func(w http.ResponseWriter, r *http.Request) {
+		var req RateLimitRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		rule, err := sm.CreateRateLimitRule(r.PathValue("zone"), &req)
+		httpapi.WriteResult(w, http.StatusCreated, rule, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/ratelimits/{id}/enable", func(w http.ResponseWriter, r *http.Request) {
+		rule, err := sm.SetRateLimitRuleEnabled(r.PathValue("zone"), r.PathValue("id"), true)
+		httpapi.WriteResult(w, http.StatusOK, rule, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/zones/{zone}/ratelimits/{id}/disable", func(w http.ResponseWriter, r *http.Request) {
+		rule, err := sm.SetRateLimitRuleEnabled(r.PathValue("zone"), r.PathValue("id"), false)
+		httpapi.WriteResult(w, http.StatusOK, rule, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/zones/{zone}/ratelimits/{id}", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.DeleteRateLimitRule(r.PathValue("zone"), r.PathValue("id")))
+	})
+	mux.HandleFunc("GET "+prefix+"/countries", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, sm.GetCountryRules())
+	})
+	mux.HandleFunc("POST "+prefix+"/countries/sync", func(w http.ResponseWriter, r *http.Request) {
+		rules, err := sm.SyncCountryRules()
+		httpapi.WriteResult(w, http.StatusOK, rules, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/allowlist", func(w http.ResponseWriter, r *http.Request) {
+		entries, err := sm.GetAllowlist()
+		httpapi.WriteResult(w, http.StatusOK, entries, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/allowlist", func(w http.ResponseWriter, r *http.Request) {
+		var req allowRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		entry, err := sm.AllowIP(req.Value, req.Note)
+		httpapi.WriteResult(w, http.StatusCreated, entry, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/allowlist/me", func(w http.ResponseWriter, r *http.Request) {
+		var req allowRequest
+		if r.ContentLength != 0 {
+			if err := httpapi.DecodeJSON(r, &req); err != nil {
+				httpapi.WriteError(w, err)
+				return
+			}
+		}
+		ip, err := requestIP(r)
+		if err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		if req.Note == "" {
+			req.Note = "管理员地址"
+		}
+		entry, err := sm.AllowIP(ip, req.Note)
+		httpapi.WriteResult(w, http.StatusCreated, entry, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/allowlist/{value...}", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.DisallowIP(r.PathValue("value")))
+	})
+	mux.HandleFunc("GET "+prefix+"/intel", func(w http.ResponseWriter, r *http.Request) {
+		feeds, err := sm.GetIntelStatus()
+		httpapi.WriteResult(w, http.StatusOK, feeds, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/intel/refresh", func(w http.ResponseWriter, r *http.Request) {
+		feeds, err := sm.RefreshIntel()
+		httpapi.WriteResult(w, http.StatusOK, feeds, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/intel/{ip}", func(w http.ResponseWriter, r *http.Request) {
+		ip := r.PathValue("ip")
+		if net.ParseIP(ip) == nil {
+			httpapi.WriteError(w, errcode.New(errcode.InvalidArgument, "无效的 IP 地址: %s", ip))
+			return
+		}
+		rep, err := sm.CheckIntel(ip)
+		httpapi.WriteResult(w, http.StatusOK, map[string]any{"ip": ip, "listed": rep != nil, "reputation": rep}, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/analytics", func(w http.ResponseWriter, r *http.Request) {
+		status, err := sm.GetAnalyticsStatus()
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/analytics/poll", func(w http.ResponseWriter, r *http.Request) {
+		status, err := sm.PollAnalytics()
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/sync", func(w http.ResponseWriter, r *http.Request) {
+		status, err := sm.GetSyncStatus()
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/sync", func(w http.ResponseWriter, r *http.Request) {
+		status, err := sm.SyncBlocks()
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/sync/list", func(w http.ResponseWriter, r *http.Request) {
+		list, err := sm.ExportBlockList()
+		httpapi.WriteResult(w, http.StatusOK, list, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/sync/list", func(w http.ResponseWriter, r *http.Request) {
+		var signed SignedBlockList
+		if err := httpapi.DecodeJSON(r, &signed); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		status, err := sm.ImportBlockList(&signed)
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/blocks", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, sm.GetBlockedIPs())
+	})
+	mux.HandleFunc("POST "+prefix+"/blocks", func(w http.ResponseWriter, r *http.Request) {
+		var req blockRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		if _, _, err := parseBlockValue(req.IP); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		if req.Duration < 0 {
+			httpapi.WriteError(w, errcode.New(errcode.InvalidArgument, "duration 不能为负数"))
+			return
+		}
+		blocked, err := sm.BlockIP(req.IP, req.Zone, req.Reason, req.Duration)
+		httpapi.WriteResult(w, http.StatusCreated, blocked, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/blocks/reconcile", func(w http.ResponseWriter, r *http.Request) {
+		removed, err := sm.ReconcileBlocks()
+		httpapi.WriteResult(w, http.StatusOK, map[string]int{"removed": removed}, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/blocks/{ip...}", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.UnblockIP(r.PathValue("ip"), r.URL.Query().Get("zone")))
+	})
+	mux.HandleFunc("GET "+prefix+"/dry-run", func(w http.ResponseWriter, r *http.Request) {
+		report, err := sm.GetDryRunReport()
+		httpapi.WriteResult(w, http.StatusOK, report, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/dry-run", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteResult(w, http.StatusOK, nil, sm.ResetDryRunReport())
+	})
+	return mux
+}
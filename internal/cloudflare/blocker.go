@@ -4,26 +4,43 @@ package cloudflare
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
 )
 
-// BlockedIP 已封禁的 IP 信息
+// 封禁规则的作用范围
+const (
+	BlockScopeZone    = "zone"       // 单个域名
+	BlockScopeAccount = AccountScope // 账户下所有域名
+	BlockScopeLocal   = "local"      // 本机防火墙
+)
+
+// BlockedIP 已封禁的 IP 或网段信息
 type BlockedIP struct {
-	IP          string     `json:"ip"`
-	RuleID      string     `json:"rule_id"`
-	ZoneID      string     `json:"zone_id"`
-	ZoneName    string     `json:"zone_name"`
+	IP          string     `json:"ip"` // IP 或 CIDR 网段
+	RuleID      string     `json:"rule_id"`                   // 本机封禁时为防火墙名称
+	Scope       string     `json:"scope,omitempty"` // zone、account 或 local，旧记录为空时视为 zone
+	ZoneID      string     `json:"zone_id,omitempty"`
+	ZoneName    string     `json:"zone_name,omitempty"`
+	AccountID   string     `json:"account_id,omitempty"`
 	Reason      string     `json:"reason"`
 	ThreatType  ThreatType `json:"threat_type"`
 	Score       int        `json:"score"`
+	Mode        string     `json:"mode,omitempty"` // 访问规则的动作，旧记录为空
 	BlockedAt   time.Time  `json:"blocked_at"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	AutoBlocked bool       `json:"auto_blocked"`
+	// 来自其他节点共享的封禁列表时为该节点的名称，这些封禁不再发布，在该节点解封后解除
+	SharedFrom string `json:"shared_from,omitempty"`
 }
 
 // BlockerConfig 封禁器配置
@@ -32,30 +49,110 @@ type BlockerConfig struct {
 	AutoBlockEnabled bool `json:"auto_block_enabled"`
 	// 默认封禁时长（秒），0 表示永久
 	DefaultBlockDuration int `json:"default_block_duration"`
-	// 封禁模式：block, challenge, js_challenge
+	// 封禁模式：block, challenge, js_challenge, managed_challenge
 	BlockMode string `json:"block_mode"`
-	// 要保护的域名 Zone ID 列表（空表示所有域名）
+	// 按威胁评分选择自动封禁的动作，如评分 100 起 managed_challenge、200 起 block，
+	// 减少共享 IP 后的正常用户被误封；评分低于所有分段或未配置时使用 BlockMode
+	ActionBands []ActionBand `json:"action_bands,omitempty"`
+	// 要保护的域名 Zone ID 列表（空表示所有域名），其余域名不启用自动封禁
 	ProtectedZones []string `json:"protected_zones"`
 	// 白名单 IP
 	WhitelistIPs []string `json:"whitelist_ips"`
+	// 暴力破解的处理方式：ban（默认）或 rate_limit，见 BruteForceRateLimit
+	BruteForceAction string `json:"brute_force_action"`
+	// 以 rate_limit 方式处理暴力破解时创建的速率限制规则的参数，为空时使用 DefaultRateLimitSettings
+	BruteForceRateLimit *RateLimitSettings `json:"brute_force_rate_limit,omitempty"`
+	// 同一域名上自动封禁的 IP 在同一 /24（IPv6 为 /64）网段中达到该数量时合并为一条网段规则，小于 2 时不合并
+	CollapseThreshold int `json:"collapse_threshold"`
+	// 演练模式：照常检测和决定封禁动作，但不创建规则，只记录在演练报告中（手动封禁不受影响）
+	DryRun bool `json:"dry_run"`
+	// Cloudflare API 不可用时改为在本机防火墙（nftables/iptables 或 Windows 防火墙）封禁
+	LocalFallback bool `json:"local_fallback"`
+	// 不经过 Cloudflare 代理的站点日志，来自这些日志的威胁直接在本机封禁
+	LocalSources []string `json:"local_sources,omitempty"`
 	// 数据存储路径
 	DataPath string `json:"data_path"`
 }
 
+// ActionBand 威胁评分分段：评分不低于 MinScore 时使用 Mode
+type ActionBand struct {
+	MinScore int    `json:"min_score"`
+	Mode     string `json:"mode"`
+}
+
+// blockModes 封禁规则支持的动作
+var blockModes = map[string]bool{
+	"block":             true,
+	"challenge":         true,
+	"js_challenge":      true,
+	"managed_challenge": true,
+}
+
+// ValidateActionBands 校验威胁评分分段
+func ValidateActionBands(bands []ActionBand) error {
+	var fields []errcode.FieldViolation
+	seen := make(map[int]bool)
+	for i, band := range bands {
+		prefix := fmt.Sprintf("action_bands[%d].", i)
+		switch {
+		case band.MinScore < 0:
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "min_score", Description: "不能小于 0"})
+		case seen[band.MinScore]:
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "min_score", Description: fmt.Sprintf("与其他分段重复：%d", band.MinScore)})
+		}
+		seen[band.MinScore] = true
+		if !blockModes[band.Mode] {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "mode", Description: fmt.Sprintf("不支持的动作 %q，可选 block、challenge、js_challenge、managed_challenge", band.Mode)})
+		}
+	}
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "威胁评分分段无效", fields)
+	}
+	return nil
+}
+
+// modeForScore 威胁评分对应的封禁动作：MinScore 不超过评分的最高分段，没有时为 BlockMode
+func (c *BlockerConfig) modeForScore(score int) string {
+	mode, best := c.BlockMode, -1
+	for _, band := range c.ActionBands {
+		if band.MinScore <= score && band.MinScore > best {
+			mode, best = band.Mode, band.MinScore
+		}
+	}
+	if mode == "" {
+		mode = "block"
+	}
+	return mode
+}
+
 // IPBlocker IP 封禁执行器
 type IPBlocker struct {
 	client     *Client
+	zones      *ZoneRegistry
+	allowlist  *Allowlist
 	config     *BlockerConfig
 	blockedIPs map[string]*BlockedIP
 	mu         sync.RWMutex
 	ctx        context.Context
 	cancel     context.CancelFunc
 	eventChan  chan *BlockEvent
+	// 为缓解暴力破解创建或找到的速率限制规则，键为 zone ID 和规则表达式
+	rateLimited map[string]string
+	rateLimitMu sync.Mutex
+	// 本机防火墙，首次本机封禁时检测
+	firewall   localFirewall
+	lookPath   LookPathFunc
+	firewallMu sync.Mutex
+	// 演练模式下将被处理的 IP
+	dryRun        map[string]*DryRunEntry
+	dryRunSince   time.Time
+	dryRunDropped int
+	dryRunMu      sync.Mutex
 }
 
 // BlockEvent 封禁事件
 type BlockEvent struct {
-	Type      string     `json:"type"` // blocked, unblocked, expired
+	Type      string     `json:"type"` // blocked, escalated, collapsed, unblocked, expired, rate_limited, would_block
 	IP        string     `json:"ip"`
 	ZoneID    string     `json:"zone_id"`
 	Reason    string     `json:"reason"`
@@ -64,6 +161,47 @@ type BlockEvent struct {
 	BlockedIP *BlockedIP `json:"blocked_ip,omitempty"`
 }
 
+// blockEventActions 通知消息中各类封禁事件的说明
+var blockEventActions = map[string]string{
+	"blocked":      "已封禁",
+	"escalated":    "已由质询改为封禁",
+	"collapsed":    "已合并为网段封禁",
+	"unblocked":    "已解封",
+	"expired":      "封禁已过期",
+	"rate_limited": "触发暴力破解限速",
+	"would_block":  "将被处理（演练模式）",
+}
+
+// Summary 通知消息的正文：IP、事件、范围、原因和评分
+func (e *BlockEvent) Summary() string {
+	action, ok := blockEventActions[e.Type]
+	if !ok {
+		action = e.Type
+	}
+	lines := []string{fmt.Sprintf("IP %s %s", e.IP, action)}
+
+	scope, score, mode := e.ZoneID, 0, ""
+	if e.BlockedIP != nil {
+		scope, score, mode = e.BlockedIP.scopeName(), e.BlockedIP.Score, e.BlockedIP.Mode
+	}
+	if e.Threat != nil {
+		score = e.Threat.Score
+	}
+	if scope != "" {
+		lines = append(lines, "范围: "+scope)
+	}
+	if mode != "" {
+		lines = append(lines, "动作: "+mode)
+	}
+	if e.Reason != "" {
+		lines = append(lines, "原因: "+e.Reason)
+	}
+	if score > 0 {
+		lines = append(lines, fmt.Sprintf("评分: %d", score))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // DefaultBlockerConfig 默认封禁器配置
 func DefaultBlockerConfig() *BlockerConfig {
 	return &BlockerConfig{
@@ -72,25 +210,34 @@ func DefaultBlockerConfig() *BlockerConfig {
 		BlockMode:            "block",
 		ProtectedZones:       []string{},
 		WhitelistIPs:         []string{},
+		BruteForceAction:     BruteForceBan,
+		CollapseThreshold:    10,
 		DataPath:             "/var/lib/runixo/cloudflare",
 	}
 }
 
-// NewIPBlocker 创建 IP 封禁器
-func NewIPBlocker(client *Client, config *BlockerConfig) *IPBlocker {
+// NewIPBlocker 创建 IP 封禁器，zones 为空时在要保护的域名上封禁
+func NewIPBlocker(client *Client, zones *ZoneRegistry, config *BlockerConfig) *IPBlocker {
 	if config == nil {
 		config = DefaultBlockerConfig()
 	}
+	if zones == nil {
+		zones = NewZoneRegistry(client, client.accountID, nil, config.ProtectedZones)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	blocker := &IPBlocker{
-		client:     client,
-		config:     config,
-		blockedIPs: make(map[string]*BlockedIP),
-		ctx:        ctx,
-		cancel:     cancel,
-		eventChan:  make(chan *BlockEvent, 100),
+		client:      client,
+		zones:       zones,
+		config:      config,
+		blockedIPs:  make(map[string]*BlockedIP),
+		ctx:         ctx,
+		cancel:      cancel,
+		eventChan:   make(chan *BlockEvent, 100),
+		rateLimited: make(map[string]string),
+		dryRun:      make(map[string]*DryRunEntry),
+		dryRunSince: time.Now(),
 	}
 
 	// 加载已保存的封禁记录
@@ -104,7 +251,7 @@ func NewIPBlocker(client *Client, config *BlockerConfig) *IPBlocker {
 
 // BlockThreat 封禁威胁 IP
 func (b *IPBlocker) BlockThreat(threat *Threat) error {
-	if !b.config.AutoBlockEnabled {
+	if !b.config.AutoBlockEnabled && !b.config.DryRun {
 		return nil
 	}
 
@@ -114,9 +261,22 @@ func (b *IPBlocker) BlockThreat(threat *Threat) error {
 		return nil
 	}
 
-	// 检查是否已封禁
+	// 演练模式只记录将执行的动作
+	if b.config.DryRun {
+		b.recordDryRun(threat)
+		return nil
+	}
+
+	// Web 暴力破解按配置改为对路径限速
+	if b.rateLimitThreat(threat) {
+		return nil
+	}
+
+	mode := b.config.modeForScore(threat.Score)
+
+	// 检查是否已封禁，已质询的 IP 评分升到 block 分段时改为封禁
 	if b.IsBlocked(threat.IP) {
-		log.Debug().Str("ip", threat.IP).Msg("IP 已被封禁")
+		b.escalate(threat, mode)
 		return nil
 	}
 
@@ -125,124 +285,192 @@ func (b *IPBlocker) BlockThreat(threat *Threat) error {
 		reason = "Auto-blocked by Runixo: " + string(threat.Type)
 	}
 
-	// 获取要保护的域名
-	zones, err := b.getProtectedZones()
+	// 确定封禁范围，Cloudflare 不可用时按配置改为本机封禁
+	targets, err := b.threatTargets(threat.Source)
 	if err != nil {
-		log.Error().Err(err).Msg("获取域名列表失败")
-		return err
+		if !b.fallbackLocal(err) {
+			log.Error().Err(err).Msg("确定封禁范围失败")
+			return err
+		}
+		log.Warn().Err(err).Str("ip", threat.IP).Msg("Cloudflare 不可用，改为本机封禁")
+		targets = []blockTarget{{local: true}}
 	}
 
-	// 在所有保护的域名上封禁该 IP
-	for _, zone := range zones {
-		if err := b.blockIPOnZone(threat.IP, zone.ID, zone.Name, reason, threat); err != nil {
-			log.Error().Err(err).Str("ip", threat.IP).Str("zone", zone.Name).Msg("封禁 IP 失败")
+	fallback := false
+	for _, target := range targets {
+		if _, err := b.block(target, threat.IP, mode, reason, threat, b.config.DefaultBlockDuration); err != nil {
+			log.Error().Err(err).Str("ip", threat.IP).Str("zone", target.name()).Msg("封禁 IP 失败")
+			fallback = fallback || (!target.local && b.fallbackLocal(err))
 			continue
 		}
+		b.collapse(target, threat.IP)
+	}
+
+	if fallback {
+		log.Warn().Str("ip", threat.IP).Msg("Cloudflare 不可用，改为本机封禁")
+		local := blockTarget{local: true}
+		if _, err := b.block(local, threat.IP, mode, reason, threat, b.config.DefaultBlockDuration); err != nil {
+			log.Error().Err(err).Str("ip", threat.IP).Msg("本机封禁 IP 失败")
+			return err
+		}
+		b.collapse(local, threat.IP)
 	}
 
 	return nil
 }
 
-// blockIPOnZone 在指定域名上封禁 IP
-func (b *IPBlocker) blockIPOnZone(ip, zoneID, zoneName, reason string, threat *Threat) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// blockTarget 封禁规则的作用范围：一个域名，zone 为空时为 accountID 指定的整个账户，local 时为本机防火墙
+type blockTarget struct {
+	zone      *Zone
+	accountID string
+	local     bool
+}
 
-	// 调用 Cloudflare API 封禁
-	rule, err := b.client.CreateAccessRule(zoneID, b.config.BlockMode, ip, reason)
-	if err != nil {
-		return err
+// key 封禁记录的键
+func (t blockTarget) key(ip string) string {
+	if t.local {
+		return ip + ":" + BlockScopeLocal
 	}
-
-	// 计算过期时间
-	var expiresAt *time.Time
-	if b.config.DefaultBlockDuration > 0 {
-		t := time.Now().Add(time.Duration(b.config.DefaultBlockDuration) * time.Second)
-		expiresAt = &t
+	if t.zone == nil {
+		return ip + ":" + AccountScope
 	}
+	return ip + ":" + t.zone.ID
+}
 
-	// 记录封禁信息
-	blocked := &BlockedIP{
-		IP:          ip,
-		RuleID:      rule.ID,
-		ZoneID:      zoneID,
-		ZoneName:    zoneName,
-		Reason:      reason,
-		ThreatType:  threat.Type,
-		Score:       threat.Score,
-		BlockedAt:   time.Now(),
-		ExpiresAt:   expiresAt,
-		AutoBlocked: true,
+// name 日志中显示的范围
+func (t blockTarget) name() string {
+	if t.local {
+		return BlockScopeLocal
+	}
+	if t.zone == nil {
+		return AccountScope
 	}
+	return t.zone.Name
+}
 
-	key := ip + ":" + zoneID
-	b.blockedIPs[key] = blocked
+// accessRulesPath 范围的访问规则接口
+func (t blockTarget) accessRulesPath() string {
+	if t.zone == nil {
+		return accountAccessRulesPath(t.accountID)
+	}
+	return zoneAccessRulesPath(t.zone.ID)
+}
 
-	// 保存到文件
-	b.saveBlockedIPs()
+// threatTargets 确定自动封禁的范围：不经过 Cloudflare 代理的日志在本机封禁，其余为威胁来源日志对应的域名，
+// 该域名停用了自动封禁时不封禁；无法确定域名时见 defaultTargets
+func (b *IPBlocker) threatTargets(source string) ([]blockTarget, error) {
+	if b.isLocalSource(source) {
+		return []blockTarget{{local: true}}, nil
+	}
+	if zone := b.zones.ForSource(source); zone != nil {
+		if !b.zones.IsEnabled(zone) {
+			log.Debug().Str("source", source).Str("zone", zone.Name).Msg("域名已停用自动封禁")
+			return nil, nil
+		}
+		return []blockTarget{{zone: zone}}, nil
+	}
+	return b.defaultTargets()
+}
 
-	// 发送事件
-	b.sendEvent(&BlockEvent{
-		Type:      "blocked",
-		IP:        ip,
-		ZoneID:    zoneID,
-		Reason:    reason,
-		Timestamp: time.Now(),
-		Threat:    threat,
-		BlockedIP: blocked,
-	})
+// defaultTargets 未指定域名时的封禁范围：配置了账户级封禁时为整个账户，否则为所有启用的域名
+func (b *IPBlocker) defaultTargets() ([]blockTarget, error) {
+	if b.zones.AccountWide() {
+		return b.accountTarget()
+	}
 
-	log.Info().
-		Str("ip", ip).
-		Str("zone", zoneName).
-		Str("rule_id", rule.ID).
-		Str("threat_type", string(threat.Type)).
-		Int("score", threat.Score).
-		Msg("IP 已封禁")
+	zones, err := b.zones.Enabled()
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]blockTarget, 0, len(zones))
+	for i := range zones {
+		targets = append(targets, blockTarget{zone: &zones[i]})
+	}
+	return targets, nil
+}
 
-	return nil
+// accountTarget 账户级别的封禁范围
+func (b *IPBlocker) accountTarget() ([]blockTarget, error) {
+	accountID := b.zones.AccountID()
+	if accountID == "" {
+		return nil, &ConfigError{Message: "账户级封禁需要配置账户 ID（域名分属多个账户时无法自动确定）"}
+	}
+	return []blockTarget{{accountID: accountID}}, nil
 }
 
-// ManualBlock 手动封禁 IP
-func (b *IPBlocker) ManualBlock(ip, zoneID, reason string, durationSeconds int) (*BlockedIP, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// createRule 在 target 上创建动作为 mode 的封禁规则，返回规则 ID 和实际的动作：本机防火墙只能丢弃连接
+func (b *IPBlocker) createRule(target blockTarget, ip, mode, reason string) (string, string, error) {
+	if target.local {
+		firewall, err := b.hostFirewall()
+		if err != nil {
+			return "", "", err
+		}
+		if err := firewall.Block(ip); err != nil {
+			return "", "", err
+		}
+		return firewall.Name(), "block", nil
+	}
 
-	// 获取域名信息
-	zone, err := b.client.GetZone(zoneID)
+	var rule *AccessRule
+	var err error
+	if target.zone != nil {
+		rule, err = b.client.CreateAccessRule(target.zone.ID, mode, ip, reason)
+	} else {
+		rule, err = b.client.CreateAccountAccessRule(target.accountID, mode, ip, reason)
+	}
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
+	return rule.ID, mode, nil
+}
+
+// newBlockedIP target 上的封禁记录
+func newBlockedIP(target blockTarget, ip, ruleID, mode, reason string) *BlockedIP {
+	blocked := &BlockedIP{
+		IP:         ip,
+		RuleID:     ruleID,
+		Scope:      BlockScopeAccount,
+		AccountID:  target.accountID,
+		Reason:     reason,
+		Mode:       mode,
+		ThreatType: ThreatTypeUnknown,
+		BlockedAt:  time.Now(),
+	}
+	switch {
+	case target.local:
+		blocked.Scope = BlockScopeLocal
+	case target.zone != nil:
+		blocked.Scope = BlockScopeZone
+		blocked.ZoneID = target.zone.ID
+		blocked.ZoneName = target.zone.Name
+	}
+	return blocked
+}
 
-	// 调用 Cloudflare API 封禁
-	rule, err := b.client.CreateAccessRule(zoneID, b.config.BlockMode, ip, reason)
+// block 在 target 上创建动作为 mode 的封禁规则并记录，threat 为空时为手动封禁
+func (b *IPBlocker) block(target blockTarget, ip, mode, reason string, threat *Threat, durationSeconds int) (*BlockedIP, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ruleID, mode, err := b.createRule(target, ip, mode, reason)
 	if err != nil {
 		return nil, err
 	}
 
-	// 计算过期时间
-	var expiresAt *time.Time
+	// 记录封禁信息
+	blocked := newBlockedIP(target, ip, ruleID, mode, reason)
 	if durationSeconds > 0 {
 		t := time.Now().Add(time.Duration(durationSeconds) * time.Second)
-		expiresAt = &t
+		blocked.ExpiresAt = &t
 	}
-
-	// 记录封禁信息
-	blocked := &BlockedIP{
-		IP:          ip,
-		RuleID:      rule.ID,
-		ZoneID:      zoneID,
-		ZoneName:    zone.Name,
-		Reason:      reason,
-		ThreatType:  ThreatTypeUnknown,
-		Score:       0,
-		BlockedAt:   time.Now(),
-		ExpiresAt:   expiresAt,
-		AutoBlocked: false,
+	if threat != nil {
+		blocked.ThreatType = threat.Type
+		blocked.Score = threat.Score
+		blocked.AutoBlocked = true
+		blocked.SharedFrom = threat.Node
 	}
 
-	key := ip + ":" + zoneID
-	b.blockedIPs[key] = blocked
+	b.blockedIPs[target.key(ip)] = blocked
 
 	// 保存到文件
 	b.saveBlockedIPs()
@@ -251,68 +479,246 @@ func (b *IPBlocker) ManualBlock(ip, zoneID, reason string, durationSeconds int)
 	b.sendEvent(&BlockEvent{
 		Type:      "blocked",
 		IP:        ip,
-		ZoneID:    zoneID,
+		ZoneID:    blocked.ZoneID,
 		Reason:    reason,
 		Timestamp: time.Now(),
+		Threat:    threat,
 		BlockedIP: blocked,
 	})
 
-	log.Info().
-		Str("ip", ip).
-		Str("zone", zone.Name).
-		Str("rule_id", rule.ID).
-		Msg("IP 已手动封禁")
+	if threat != nil {
+		log.Info().
+			Str("ip", ip).
+			Str("zone", target.name()).
+			Str("rule_id", ruleID).
+			Str("mode", mode).
+			Str("threat_type", string(threat.Type)).
+			Int("score", threat.Score).
+			Msg("IP 已封禁")
+	} else {
+		log.Info().
+			Str("ip", ip).
+			Str("zone", target.name()).
+			Str("rule_id", ruleID).
+			Msg("IP 已手动封禁")
+	}
 
 	return blocked, nil
 }
 
-// Unblock 解封 IP
-func (b *IPBlocker) Unblock(ip, zoneID string) error {
+// escalate 把 IP 的质询规则改为 mode，只在 mode 为 block 时升级，不在质询动作之间切换
+func (b *IPBlocker) escalate(threat *Threat, mode string) {
+	if mode != "block" {
+		return
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	key := ip + ":" + zoneID
-	blocked, exists := b.blockedIPs[key]
-	if !exists {
-		return nil
+	changed := false
+	for key, blocked := range b.blockedIPs {
+		if !strings.HasPrefix(key, threat.IP+":") || !isChallengeAction(blocked.Mode) {
+			continue
+		}
+
+		if err := b.client.updateAccessRuleMode(blocked.accessRulesPath(), blocked.RuleID, mode, blocked.Reason); err != nil {
+			log.Error().Err(err).Str("ip", threat.IP).Str("zone", blocked.scopeName()).Msg("升级封禁动作失败")
+			continue
+		}
+
+		log.Info().
+			Str("ip", threat.IP).
+			Str("zone", blocked.scopeName()).
+			Str("rule_id", blocked.RuleID).
+			Str("from", blocked.Mode).
+			Int("score", threat.Score).
+			Msg("IP 评分升高，已由质询改为封禁")
+
+		blocked.Mode = mode
+		blocked.Score = threat.Score
+		blocked.ThreatType = threat.Type
+		if blocked.ExpiresAt != nil && b.config.DefaultBlockDuration > 0 {
+			t := time.Now().Add(time.Duration(b.config.DefaultBlockDuration) * time.Second)
+			blocked.ExpiresAt = &t
+		}
+		changed = true
+
+		b.sendEvent(&BlockEvent{
+			Type:      "escalated",
+			IP:        threat.IP,
+			ZoneID:    blocked.ZoneID,
+			Reason:    blocked.Reason,
+			Timestamp: time.Now(),
+			Threat:    threat,
+			BlockedIP: blocked,
+		})
 	}
 
-	// 调用 Cloudflare API 删除规则
-	if err := b.client.DeleteAccessRule(zoneID, blocked.RuleID); err != nil {
-		return err
+	if changed {
+		b.saveBlockedIPs()
 	}
+}
 
-	// 删除记录
-	delete(b.blockedIPs, key)
+// ManualBlock 手动封禁 IP。zone 为 zone ID、名称或其下的主机名时只在该域名上封禁（不受停用影响），
+// 为 AccountScope 时在账户级别封禁，为 BlockScopeLocal 时在本机防火墙封禁，为空时与无法确定域名的自动封禁相同
+func (b *IPBlocker) ManualBlock(ip, zone, reason string, durationSeconds int) ([]*BlockedIP, error) {
+	ip, _, err := parseBlockValue(ip)
+	if err != nil {
+		return nil, err
+	}
+	if b.isWhitelisted(ip) {
+		return nil, errcode.New(errcode.InvalidArgument, "%s 在白名单中或与白名单重叠，请先从白名单移除", ip)
+	}
+	if reason == "" {
+		reason = "Manually blocked by Runixo"
+	}
+
+	var targets []blockTarget
+	switch zone {
+	case "":
+		targets, err = b.defaultTargets()
+	case AccountScope:
+		targets, err = b.accountTarget()
+	case BlockScopeLocal:
+		targets = []blockTarget{{local: true}}
+	default:
+		var z *Zone
+		if z, err = b.zones.Resolve(zone); err == nil {
+			targets = []blockTarget{{zone: z}}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*BlockedIP, 0, len(targets))
+	for _, target := range targets {
+		blocked, err := b.block(target, ip, b.config.BlockMode, reason, nil, durationSeconds)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, blocked)
+	}
+	return result, nil
+}
+
+// Unblock 解封 IP。zone 为 zone ID、名称或其下的主机名时只解除该域名上的封禁，
+// 为 AccountScope 时解除账户级别的封禁，为空时解除该 IP 的所有封禁
+func (b *IPBlocker) Unblock(ip, zone string) error {
+	if key, _, err := parseAllowValue(ip); err == nil {
+		ip = key
+	}
+	scope := zone
+	if zone != "" && zone != AccountScope && zone != BlockScopeLocal {
+		b.mu.RLock()
+		_, exists := b.blockedIPs[ip+":"+zone]
+		b.mu.RUnlock()
+		if !exists {
+			z, err := b.zones.Resolve(zone)
+			if err != nil {
+				return err
+			}
+			scope = z.ID
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var firstErr error
+	removed := 0
+	for key, blocked := range b.blockedIPs {
+		if blocked.IP != ip || (scope != "" && key != ip+":"+scope) {
+			continue
+		}
+
+		// 调用 Cloudflare API 删除规则
+		if err := b.deleteRule(blocked); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		// 删除记录
+		delete(b.blockedIPs, key)
+		removed++
+
+		// 发送事件
+		b.sendEvent(&BlockEvent{
+			Type:      "unblocked",
+			IP:        ip,
+			ZoneID:    blocked.ZoneID,
+			Reason:    "Manual unblock",
+			Timestamp: time.Now(),
+			BlockedIP: blocked,
+		})
+
+		log.Info().
+			Str("ip", ip).
+			Str("zone", blocked.scopeName()).
+			Msg("IP 已解封")
+	}
 
 	// 保存到文件
-	b.saveBlockedIPs()
+	if removed > 0 {
+		b.saveBlockedIPs()
+	}
 
-	// 发送事件
-	b.sendEvent(&BlockEvent{
-		Type:      "unblocked",
-		IP:        ip,
-		ZoneID:    zoneID,
-		Reason:    "Manual unblock",
-		Timestamp: time.Now(),
-		BlockedIP: blocked,
-	})
+	return firstErr
+}
 
-	log.Info().
-		Str("ip", ip).
-		Str("zone", blocked.ZoneName).
-		Msg("IP 已解封")
+// deleteRule 删除封禁记录对应的 Cloudflare 规则，规则已不存在时视为成功
+func (b *IPBlocker) deleteRule(blocked *BlockedIP) error {
+	if blocked.Scope == BlockScopeLocal {
+		firewall, err := b.hostFirewall()
+		if err != nil {
+			return err
+		}
+		return firewall.Unblock(blocked.IP)
+	}
 
-	return nil
+	var err error
+	if blocked.Scope == BlockScopeAccount {
+		err = b.client.DeleteAccountAccessRule(blocked.AccountID, blocked.RuleID)
+	} else {
+		err = b.client.DeleteAccessRule(blocked.ZoneID, blocked.RuleID)
+	}
+	if IsNotFound(err) {
+		log.Debug().Str("ip", blocked.IP).Str("rule_id", blocked.RuleID).Msg("封禁规则已不存在")
+		return nil
+	}
+	return err
+}
+
+// scopeName 日志和统计中显示的封禁范围
+func (b *BlockedIP) scopeName() string {
+	switch b.Scope {
+	case BlockScopeAccount:
+		return AccountScope
+	case BlockScopeLocal:
+		return BlockScopeLocal
+	}
+	return b.ZoneName
+}
+
+// accessRulesPath 封禁规则所在的访问规则接口
+func (b *BlockedIP) accessRulesPath() string {
+	if b.Scope == BlockScopeAccount {
+		return accountAccessRulesPath(b.AccountID)
+	}
+	return zoneAccessRulesPath(b.ZoneID)
 }
 
 // IsBlocked 检查 IP 是否已被封禁
 func (b *IPBlocker) IsBlocked(ip string) bool {
+	parsed := net.ParseIP(ip)
+
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	for key := range b.blockedIPs {
-		if len(key) > len(ip) && key[:len(ip)] == ip && key[len(ip)] == ':' {
+	for _, blocked := range b.blockedIPs {
+		if blocked.IP == ip || (parsed != nil && blocked.covers(parsed)) {
 			return true
 		}
 	}
@@ -350,110 +756,192 @@ func (b *IPBlocker) Events() <-chan *BlockEvent {
 	return b.eventChan
 }
 
-// Stop 停止封禁器
+// Stop 停止封禁器，进行中的过期检查和核对不再发送事件
 func (b *IPBlocker) Stop() {
 	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	close(b.eventChan)
 }
 
-// getProtectedZones 获取要保护的域名列表
-func (b *IPBlocker) getProtectedZones() ([]Zone, error) {
-	allZones, err := b.client.ListZones()
-	if err != nil {
-		return nil, err
-	}
-
-	// 如果没有指定保护的域名，返回所有域名
-	if len(b.config.ProtectedZones) == 0 {
-		return allZones, nil
-	}
-
-	// 过滤出指定的域名
-	var zones []Zone
-	for _, zone := range allZones {
-		for _, protectedID := range b.config.ProtectedZones {
-			if zone.ID == protectedID {
-				zones = append(zones, zone)
-				break
-			}
-		}
-	}
-
-	return zones, nil
+// SetAllowlist 设置白名单，其中的 IP 不被封禁
+func (b *IPBlocker) SetAllowlist(allowlist *Allowlist) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.allowlist = allowlist
 }
 
-// isWhitelisted 检查 IP 是否在白名单中
+// isWhitelisted 检查 IP 是否在白名单或配置的白名单 IP 中
 func (b *IPBlocker) isWhitelisted(ip string) bool {
+	b.mu.RLock()
+	allowlist := b.allowlist
+	b.mu.RUnlock()
+	if allowlist != nil && allowlist.Overlaps(ip) {
+		return true
+	}
+	_, network, err := parseAllowValue(ip)
 	for _, whiteIP := range b.config.WhitelistIPs {
 		if ip == whiteIP {
 			return true
 		}
+		if _, white, werr := parseAllowValue(whiteIP); err == nil && werr == nil && networksOverlap(network, white) {
+			return true
+		}
 	}
 	return false
 }
 
-// expirationLoop 过期检查循环
+// expirationLoop 过期检查循环，启动时立即检查一次，清除 Agent 停止期间过期的封禁
 func (b *IPBlocker) expirationLoop() {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
 	for {
+		b.checkExpirations()
+
 		select {
 		case <-b.ctx.Done():
 			return
 		case <-ticker.C:
-			b.checkExpirations()
 		}
 	}
 }
 
-// checkExpirations 检查过期的封禁
+// checkExpirations 删除过期封禁的 Cloudflare 规则，删除失败的封禁在下次检查时重试
 func (b *IPBlocker) checkExpirations() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
 	now := time.Now()
-	var toRemove []string
 
+	b.mu.RLock()
+	expired := make(map[string]*BlockedIP)
 	for key, blocked := range b.blockedIPs {
 		if blocked.ExpiresAt != nil && blocked.ExpiresAt.Before(now) {
-			// 调用 Cloudflare API 删除规则
-			if err := b.client.DeleteAccessRule(blocked.ZoneID, blocked.RuleID); err != nil {
-				log.Error().Err(err).Str("ip", blocked.IP).Msg("删除过期封禁规则失败")
-				continue
-			}
+			expired[key] = blocked
+		}
+	}
+	b.mu.RUnlock()
 
-			toRemove = append(toRemove, key)
+	if len(expired) == 0 {
+		return
+	}
 
-			// 发送事件
-			b.sendEvent(&BlockEvent{
-				Type:      "expired",
-				IP:        blocked.IP,
-				ZoneID:    blocked.ZoneID,
-				Reason:    "Block expired",
-				Timestamp: now,
-				BlockedIP: blocked,
-			})
+	// 调用 Cloudflare API 删除规则，不持有锁
+	for key, blocked := range expired {
+		if err := b.deleteRule(blocked); err != nil {
+			log.Error().Err(err).Str("ip", blocked.IP).Msg("删除过期封禁规则失败")
+			delete(expired, key)
+		}
+	}
+
+	if len(expired) == 0 {
+		return
+	}
 
-			log.Info().
-				Str("ip", blocked.IP).
-				Str("zone", blocked.ZoneName).
-				Msg("封禁已过期，自动解封")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, blocked := range expired {
+		// 期间被解封或重新封禁的记录不再处理
+		if b.blockedIPs[key] != blocked {
+			continue
 		}
+		delete(b.blockedIPs, key)
+
+		// 发送事件
+		b.sendEvent(&BlockEvent{
+			Type:      "expired",
+			IP:        blocked.IP,
+			ZoneID:    blocked.ZoneID,
+			Reason:    "Block expired",
+			Timestamp: now,
+			BlockedIP: blocked,
+		})
+
+		log.Info().
+			Str("ip", blocked.IP).
+			Str("zone", blocked.scopeName()).
+			Msg("封禁已过期，自动解封")
 	}
 
-	// 删除过期记录
-	for _, key := range toRemove {
+	b.saveBlockedIPs()
+}
+
+// Reconcile 与 Cloudflare 核对封禁记录：规则已在 Cloudflare 上被删除的记录从本地移除，
+// 返回移除的记录数。无法列出规则的域名或账户保留原有记录
+func (b *IPBlocker) Reconcile() (int, error) {
+	b.mu.RLock()
+	scopes := make(map[string]blockTarget)
+	for _, blocked := range b.blockedIPs {
+		switch blocked.Scope {
+		case BlockScopeLocal:
+			// 本机封禁不在 Cloudflare 上
+		case BlockScopeAccount:
+			scopes[AccountScope+":"+blocked.AccountID] = blockTarget{accountID: blocked.AccountID}
+		default:
+			scopes[blocked.ZoneID] = blockTarget{zone: &Zone{ID: blocked.ZoneID, Name: blocked.ZoneName}}
+		}
+	}
+	b.mu.RUnlock()
+
+	// 列出各范围现有的规则，不持有锁
+	existing := make(map[string]map[string]bool)
+	var errs []error
+	for key, target := range scopes {
+		rules, err := b.client.listAccessRules(target.accessRulesPath(), "")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.name(), err))
+			continue
+		}
+		ids := make(map[string]bool, len(rules))
+		for _, rule := range rules {
+			ids[rule.ID] = true
+		}
+		existing[key] = ids
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	removed := 0
+	for key, blocked := range b.blockedIPs {
+		scope := blocked.ZoneID
+		if blocked.Scope == BlockScopeAccount {
+			scope = AccountScope + ":" + blocked.AccountID
+		}
+		ids, listed := existing[scope]
+		if !listed || ids[blocked.RuleID] {
+			continue
+		}
 		delete(b.blockedIPs, key)
+		removed++
+
+		b.sendEvent(&BlockEvent{
+			Type:      "unblocked",
+			IP:        blocked.IP,
+			ZoneID:    blocked.ZoneID,
+			Reason:    "Rule removed on Cloudflare",
+			Timestamp: time.Now(),
+			BlockedIP: blocked,
+		})
+
+		log.Info().
+			Str("ip", blocked.IP).
+			Str("zone", blocked.scopeName()).
+			Msg("封禁规则已在 Cloudflare 上删除，移除本地记录")
 	}
 
-	if len(toRemove) > 0 {
+	if removed > 0 {
 		b.saveBlockedIPs()
 	}
+
+	return removed, errors.Join(errs...)
 }
 
-// sendEvent 发送事件
+// sendEvent 发送事件（需要持有锁），封禁器停止后丢弃
 func (b *IPBlocker) sendEvent(event *BlockEvent) {
+	if b.ctx.Err() != nil {
+		return
+	}
 	select {
 	case b.eventChan <- event:
 	default:
@@ -555,11 +1043,14 @@ func (b *IPBlocker) GetStats() map[string]interface{} {
 		"manual_blocked":     0,
 		"by_threat_type":     make(map[string]int),
 		"by_zone":            make(map[string]int),
+		"by_mode":            make(map[string]int),
 		"auto_block_enabled": b.config.AutoBlockEnabled,
+		"dry_run":            b.config.DryRun,
 	}
 
 	byType := stats["by_threat_type"].(map[string]int)
 	byZone := stats["by_zone"].(map[string]int)
+	byMode := stats["by_mode"].(map[string]int)
 
 	for _, blocked := range b.blockedIPs {
 		if blocked.AutoBlocked {
@@ -568,7 +1059,10 @@ func (b *IPBlocker) GetStats() map[string]interface{} {
 			stats["manual_blocked"] = stats["manual_blocked"].(int) + 1
 		}
 		byType[string(blocked.ThreatType)]++
-		byZone[blocked.ZoneName]++
+		byZone[blocked.scopeName()]++
+		if blocked.Mode != "" {
+			byMode[blocked.Mode]++
+		}
 	}
 
 	return stats
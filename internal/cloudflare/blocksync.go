@@ -0,0 +1,607 @@
+// Package cloudflare 共享封禁列表：多台服务器上的 Agent 互相发布和应用自动封禁的 IP，
+// 攻击者在一台服务器上被封禁后，其他服务器在它到达之前就已封禁
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// defaultSyncInterval 默认同步间隔（秒）
+	defaultSyncInterval = 60
+	// minSyncSecretLength 签名密钥的最短长度
+	minSyncSecretLength = 16
+	// maxSharedBlocks 一个共享列表最多包含的封禁数
+	maxSharedBlocks = 10000
+	// syncTimeout 没有指定 HTTP 客户端时请求共享列表的超时
+	syncTimeout = 30 * time.Second
+	// syncSourceAPI 通过 HTTP 接口导入的列表的来源，用于控制面转发
+	syncSourceAPI = "api"
+)
+
+// SyncConfig 共享封禁列表配置。每个节点把自己自动封禁的 IP 发布到 PublishURL（或由控制面通过接口拉取），
+// 并定期读取其他节点发布的列表，签名校验通过后按本机的封禁流程封禁
+type SyncConfig struct {
+	Enabled bool `json:"enabled"`
+	// 本节点的名称，默认主机名，各节点不能相同
+	Node string `json:"node,omitempty"`
+	// HMAC-SHA256 签名密钥，所有节点相同，至少 16 个字符
+	Secret string `json:"secret"`
+	// 发布本节点列表的地址（HTTP PUT，如 S3 预签名地址），为空时只能由控制面通过接口拉取
+	PublishURL string `json:"publish_url,omitempty"`
+	// 其他节点发布的列表地址（HTTP GET）
+	PeerURLs []string `json:"peer_urls,omitempty"`
+	// 请求发布和列表地址时附加的请求头，如 Authorization
+	Headers map[string]string `json:"headers,omitempty"`
+	// 同步间隔（秒），默认 60
+	Interval int `json:"interval"`
+	// 只应用评分不低于该值的封禁，0 表示全部应用
+	MinScore int `json:"min_score"`
+}
+
+// Validate 校验配置
+func (c *SyncConfig) Validate() error {
+	var fields []errcode.FieldViolation
+	if c.Enabled && len(c.Secret) < minSyncSecretLength {
+		fields = append(fields, errcode.FieldViolation{Field: "sync.secret", Description: fmt.Sprintf("至少 %d 个字符", minSyncSecretLength)})
+	}
+	if c.PublishURL != "" && !isHTTPURL(c.PublishURL) {
+		fields = append(fields, errcode.FieldViolation{Field: "sync.publish_url", Description: "必须是 http 或 https 地址"})
+	}
+	for i, peer := range c.PeerURLs {
+		if !isHTTPURL(peer) {
+			fields = append(fields, errcode.FieldViolation{Field: fmt.Sprintf("sync.peer_urls[%d]", i), Description: "必须是 http 或 https 地址"})
+		}
+	}
+	if c.Interval < 0 {
+		fields = append(fields, errcode.FieldViolation{Field: "sync.interval", Description: "不能为负数"})
+	}
+	if c.MinScore < 0 {
+		fields = append(fields, errcode.FieldViolation{Field: "sync.min_score", Description: "不能为负数"})
+	}
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "共享封禁列表配置无效", fields)
+	}
+	return nil
+}
+
+// isHTTPURL 是否为 http 或 https 地址
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// interval 同步间隔
+func (c *SyncConfig) interval() time.Duration {
+	if c.Interval > 0 {
+		return time.Duration(c.Interval) * time.Second
+	}
+	return defaultSyncInterval * time.Second
+}
+
+// node 本节点的名称
+func (c *SyncConfig) node() string {
+	if c.Node != "" {
+		return c.Node
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// SharedBlock 共享列表中的一个封禁
+type SharedBlock struct {
+	IP         string     `json:"ip"` // IP 或 CIDR 网段
+	Reason     string     `json:"reason"`
+	ThreatType ThreatType `json:"threat_type"`
+	Score      int        `json:"score"`
+	BlockedAt  time.Time  `json:"blocked_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// SharedBlockList 一个节点发布的封禁列表
+type SharedBlockList struct {
+	Node        string         `json:"node"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Blocks      []*SharedBlock `json:"blocks"`
+}
+
+// SignedBlockList 带签名的封禁列表，Signature 为 "sha256=" 加 HMAC-SHA256(secret, payload) 的十六进制，
+// 签名放在内容中而不是请求头中，存放在 S3 等不保留自定义请求头的位置时也能校验
+type SignedBlockList struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+}
+
+// signBlockList 序列化并签名封禁列表
+func signBlockList(list *SharedBlockList, secret string) (*SignedBlockList, error) {
+	payload, err := json.Marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("序列化封禁列表失败: %w", err)
+	}
+	return &SignedBlockList{Payload: payload, Signature: blockListSignature(secret, payload)}, nil
+}
+
+// blockListSignature 计算封禁列表的签名
+func blockListSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验签名并解析封禁列表
+func (s *SignedBlockList) verify(secret string) (*SharedBlockList, error) {
+	if !hmac.Equal([]byte(s.Signature), []byte(blockListSignature(secret, s.Payload))) {
+		return nil, errcode.New(errcode.PermissionDenied, "封禁列表签名无效")
+	}
+
+	var list SharedBlockList
+	if err := json.Unmarshal(s.Payload, &list); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "解析封禁列表失败")
+	}
+	if list.Node == "" {
+		return nil, errcode.New(errcode.InvalidArgument, "封禁列表缺少节点名称")
+	}
+	if len(list.Blocks) > maxSharedBlocks {
+		return nil, errcode.New(errcode.InvalidArgument, "封禁列表超过 %d 条", maxSharedBlocks)
+	}
+	return &list, nil
+}
+
+// SyncPeerStatus 一个节点的列表的同步状态
+type SyncPeerStatus struct {
+	Node   string `json:"node,omitempty"`
+	Source string `json:"source"` // 列表地址，控制面转发时为 api
+	// 最近一次应用的列表的生成时间，更早的列表被拒绝，防止重放
+	GeneratedAt *time.Time `json:"generated_at,omitempty"`
+	LastSync    *time.Time `json:"last_sync,omitempty"`
+	Blocks      int        `json:"blocks"`  // 列表中的封禁数
+	Applied     int        `json:"applied"` // 累计在本机新增的封禁数
+	Revoked     int        `json:"revoked"` // 累计因对方解封而解除的封禁数
+	Error       string     `json:"error,omitempty"`
+}
+
+// SyncStatus 共享封禁列表的同步状态
+type SyncStatus struct {
+	Node         string            `json:"node"`
+	PublishURL   string            `json:"publish_url,omitempty"`
+	LastPublish  *time.Time        `json:"last_publish,omitempty"`
+	Published    int               `json:"published"` // 最近一次发布的封禁数
+	PublishError string            `json:"publish_error,omitempty"`
+	Peers        []*SyncPeerStatus `json:"peers"`
+}
+
+// BlockSync 定期发布本节点自动封禁的 IP 并应用其他节点的封禁，来自其他节点的封禁不再发布，避免循环
+type BlockSync struct {
+	client  *http.Client
+	blocker *IPBlocker
+	config  *SyncConfig
+	node    string
+	status  SyncStatus
+	peers   map[string]*SyncPeerStatus // 键为列表地址或 api
+	nodes   map[string]time.Time       // 各节点最近一次应用的列表的生成时间
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewBlockSync 创建共享封禁列表同步，client 为空时使用默认客户端
+func NewBlockSync(config *SyncConfig, client *http.Client, blocker *IPBlocker) *BlockSync {
+	if client == nil {
+		client = &http.Client{Timeout: syncTimeout}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	node := config.node()
+	return &BlockSync{
+		client:  client,
+		blocker: blocker,
+		config:  config,
+		node:    node,
+		status:  SyncStatus{Node: node, PublishURL: config.PublishURL},
+		peers:   make(map[string]*SyncPeerStatus),
+		nodes:   make(map[string]time.Time),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start 立即同步一次并开始定期同步
+func (s *BlockSync) Start() {
+	go s.loop()
+}
+
+// Stop 停止同步
+func (s *BlockSync) Stop() {
+	s.cancel()
+}
+
+// loop 每个间隔同步一次
+func (s *BlockSync) loop() {
+	ticker := time.NewTicker(s.config.interval())
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(); err != nil {
+			log.Warn().Err(err).Msg("同步共享封禁列表失败")
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 立即发布本节点的列表并应用所有节点的列表
+func (s *BlockSync) Sync() error {
+	var errs []error
+	if s.config.PublishURL != "" {
+		if err := s.publish(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, peer := range s.config.PeerURLs {
+		if err := s.pull(peer); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", peer, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Export 本节点签名后的封禁列表
+func (s *BlockSync) Export() (*SignedBlockList, error) {
+	return signBlockList(s.list(), s.config.Secret)
+}
+
+// list 本节点的封禁列表
+func (s *BlockSync) list() *SharedBlockList {
+	return &SharedBlockList{
+		Node:        s.node,
+		GeneratedAt: time.Now().UTC(),
+		Blocks:      s.blocker.sharedBlocks(),
+	}
+}
+
+// Import 应用控制面转发的其他节点的列表
+func (s *BlockSync) Import(signed *SignedBlockList) (*SyncPeerStatus, error) {
+	err := s.apply(syncSourceAPI, signed)
+	return s.peerStatus(syncSourceAPI), err
+}
+
+// publish 把本节点的列表上传到发布地址
+func (s *BlockSync) publish() error {
+	list := s.list()
+	signed, err := signBlockList(list, s.config.Secret)
+	if err == nil {
+		err = s.put(signed)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.status.LastPublish = &now
+	s.status.PublishError = ""
+	if err != nil {
+		s.status.PublishError = err.Error()
+	} else {
+		s.status.Published = len(list.Blocks)
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return errcode.Wrap(errcode.UpstreamUnavailable, err, "发布封禁列表失败")
+	}
+	return nil
+}
+
+// put 上传签名后的列表
+func (s *BlockSync) put(signed *SignedBlockList) error {
+	body, err := json.Marshal(signed)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPut, s.config.PublishURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.setHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// pull 下载并应用一个节点的列表
+func (s *BlockSync) pull(source string) error {
+	signed, err := s.fetch(source)
+	if err != nil {
+		s.recordError(source, err)
+		return err
+	}
+	return s.apply(source, signed)
+}
+
+// fetch 下载签名后的列表
+func (s *BlockSync) fetch(source string) (*SignedBlockList, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	s.setHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "请求封禁列表失败")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errcode.New(errcode.UpstreamUnavailable, "封禁列表地址返回 HTTP %d", resp.StatusCode)
+	}
+
+	var signed SignedBlockList
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxFeedSize)).Decode(&signed); err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "解析封禁列表失败")
+	}
+	return &signed, nil
+}
+
+// setHeaders 添加配置的请求头
+func (s *BlockSync) setHeaders(req *http.Request) {
+	for name, value := range s.config.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// apply 校验列表并应用：封禁列表中的 IP，解除该节点已不再封禁的 IP
+func (s *BlockSync) apply(source string, signed *SignedBlockList) error {
+	list, err := signed.verify(s.config.Secret)
+	if err == nil && list.Node == s.node {
+		err = errcode.New(errcode.InvalidArgument, "封禁列表来自本节点 %s", s.node)
+	}
+	if err == nil {
+		s.mu.Lock()
+		if last, ok := s.nodes[list.Node]; ok && !list.GeneratedAt.After(last) {
+			if list.GeneratedAt.Equal(last) {
+				// 列表未更新
+				s.mu.Unlock()
+				return nil
+			}
+			err = errcode.New(errcode.InvalidArgument, "节点 %s 的封禁列表早于已应用的版本", list.Node)
+		}
+		s.mu.Unlock()
+	}
+	if err != nil {
+		s.recordError(source, err)
+		return err
+	}
+
+	now := time.Now()
+	active := make(map[string]bool, len(list.Blocks))
+	applied := 0
+	for _, shared := range list.Blocks {
+		if shared == nil || (shared.ExpiresAt != nil && shared.ExpiresAt.Before(now)) {
+			continue
+		}
+		ip, _, err := parseBlockValue(shared.IP)
+		if err != nil {
+			log.Debug().Err(err).Str("node", list.Node).Str("ip", shared.IP).Msg("跳过共享封禁")
+			continue
+		}
+		active[ip] = true
+		if shared.Score < s.config.MinScore {
+			continue
+		}
+		if s.blocker.applySharedBlock(list.Node, ip, shared) {
+			applied++
+		}
+	}
+	revoked := s.blocker.revokeSharedBlocks(list.Node, active)
+
+	s.mu.Lock()
+	s.nodes[list.Node] = list.GeneratedAt
+	status := s.peerStatusLocked(source)
+	status.Node = list.Node
+	generatedAt := list.GeneratedAt
+	status.GeneratedAt = &generatedAt
+	status.LastSync = &now
+	status.Blocks = len(list.Blocks)
+	status.Applied += applied
+	status.Revoked += revoked
+	status.Error = ""
+	s.mu.Unlock()
+
+	if applied > 0 || revoked > 0 {
+		log.Info().Str("node", list.Node).Int("applied", applied).Int("revoked", revoked).Msg("已同步共享封禁列表")
+	}
+	return nil
+}
+
+// recordError 记录同步失败
+func (s *BlockSync) recordError(source string, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.peerStatusLocked(source)
+	status.LastSync = &now
+	status.Error = err.Error()
+}
+
+// peerStatusLocked 来源的状态，不存在时创建（需要持有锁）
+func (s *BlockSync) peerStatusLocked(source string) *SyncPeerStatus {
+	status, ok := s.peers[source]
+	if !ok {
+		status = &SyncPeerStatus{Source: source}
+		s.peers[source] = status
+	}
+	return status
+}
+
+// peerStatus 来源的状态副本
+func (s *BlockSync) peerStatus(source string) *SyncPeerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *s.peerStatusLocked(source)
+	return &copied
+}
+
+// Status 同步状态
+func (s *BlockSync) Status() *SyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status
+	status.Peers = make([]*SyncPeerStatus, 0, len(s.peers))
+	for _, peer := range s.peers {
+		copied := *peer
+		status.Peers = append(status.Peers, &copied)
+	}
+	sort.Slice(status.Peers, func(i, j int) bool { return status.Peers[i].Source < status.Peers[j].Source })
+	return &status
+}
+
+// sharedBlocks 本节点自动封禁、未过期且不是来自其他节点的 IP，同一 IP 在多个范围的封禁合并为一条
+func (b *IPBlocker) sharedBlocks() []*SharedBlock {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	now := time.Now()
+	byIP := make(map[string]*SharedBlock)
+	for _, blocked := range b.blockedIPs {
+		if !blocked.AutoBlocked || blocked.SharedFrom != "" || (blocked.ExpiresAt != nil && blocked.ExpiresAt.Before(now)) {
+			continue
+		}
+		shared, ok := byIP[blocked.IP]
+		if !ok {
+			shared = &SharedBlock{
+				IP:         blocked.IP,
+				Reason:     blocked.Reason,
+				ThreatType: blocked.ThreatType,
+				Score:      blocked.Score,
+				BlockedAt:  blocked.BlockedAt,
+				ExpiresAt:  blocked.ExpiresAt,
+			}
+			byIP[blocked.IP] = shared
+			continue
+		}
+		shared.Score = max(shared.Score, blocked.Score)
+		if shared.ExpiresAt != nil && (blocked.ExpiresAt == nil || blocked.ExpiresAt.After(*shared.ExpiresAt)) {
+			shared.ExpiresAt = blocked.ExpiresAt
+		}
+	}
+
+	result := make([]*SharedBlock, 0, len(byIP))
+	for _, shared := range byIP {
+		result = append(result, shared)
+		if len(result) == maxSharedBlocks {
+			break
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IP < result[j].IP })
+	return result
+}
+
+// applySharedBlock 按本机的自动封禁流程封禁 node 共享的 IP（白名单、演练模式和评分分段同样生效），返回是否新增了封禁
+func (b *IPBlocker) applySharedBlock(node, ip string, shared *SharedBlock) bool {
+	if b.IsBlocked(ip) {
+		return false
+	}
+
+	threatType := shared.ThreatType
+	if threatType == "" {
+		threatType = ThreatTypeUnknown
+	}
+	reason := strings.TrimSpace(fmt.Sprintf("Shared by %s: %s", node, shared.Reason))
+	b.BlockThreat(&Threat{
+		ID:          generateThreatID(),
+		IP:          ip,
+		Type:        threatType,
+		Score:       shared.Score,
+		Description: reason,
+		Node:        node,
+		Timestamp:   time.Now(),
+		Count:       1,
+	})
+	return b.IsBlocked(ip)
+}
+
+// revokeSharedBlocks 解除来自 node、但已不在其列表 active 中的封禁，即对方已解封或已过期的 IP，返回解除的数量
+func (b *IPBlocker) revokeSharedBlocks(node string, active map[string]bool) int {
+	b.mu.RLock()
+	revoked := make(map[string]*BlockedIP)
+	for key, blocked := range b.blockedIPs {
+		if blocked.SharedFrom == node && !active[blocked.IP] {
+			revoked[key] = blocked
+		}
+	}
+	b.mu.RUnlock()
+
+	if len(revoked) == 0 {
+		return 0
+	}
+
+	// 调用 Cloudflare API 删除规则，不持有锁
+	for key, blocked := range revoked {
+		if err := b.deleteRule(blocked); err != nil {
+			log.Error().Err(err).Str("ip", blocked.IP).Str("node", node).Msg("解除共享封禁失败")
+			delete(revoked, key)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	count := 0
+	for key, blocked := range revoked {
+		// 期间被解封或重新封禁的记录不再处理
+		if b.blockedIPs[key] != blocked {
+			continue
+		}
+		delete(b.blockedIPs, key)
+		count++
+
+		b.sendEvent(&BlockEvent{
+			Type:      "unblocked",
+			IP:        blocked.IP,
+			ZoneID:    blocked.ZoneID,
+			Reason:    "Unblocked by " + node,
+			Timestamp: time.Now(),
+			BlockedIP: blocked,
+		})
+
+		log.Info().
+			Str("ip", blocked.IP).
+			Str("zone", blocked.scopeName()).
+			Str("node", node).
+			Msg("共享封禁已被来源节点解除")
+	}
+
+	if count > 0 {
+		b.saveBlockedIPs()
+	}
+	return count
+}
@@ -0,0 +1,120 @@
+package cloudflare
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const testSyncSecret = "0123456789abcdef"
+
+func signedList(t *testing.T, node string, at time.Time, blocks ...*SharedBlock) *SignedBlockList {
+	t.Helper()
+	signed, err := signBlockList(&SharedBlockList{Node: node, GeneratedAt: at, Blocks: blocks}, testSyncSecret)
+	if err != nil {
+		t.Fatalf("signBlockList() error: %v", err)
+	}
+	return signed
+}
+
+func TestSignedBlockListVerify(t *testing.T) {
+	signed := signedList(t, "peer", time.Now(), &SharedBlock{IP: "203.0.113.7", Score: 100})
+	list, err := signed.verify(testSyncSecret)
+	if err != nil || list.Node != "peer" || len(list.Blocks) != 1 {
+		t.Fatalf("verify() = %+v, %v", list, err)
+	}
+
+	tampered := *signed
+	tampered.Payload = []byte(string(signed.Payload[:len(signed.Payload)-1]) + " }")
+	rejected := map[string]struct {
+		signed *SignedBlockList
+		secret string
+		code   errcode.Code
+	}{
+		"wrong secret":     {signed, "fedcba9876543210", errcode.PermissionDenied},
+		"tampered payload": {&tampered, testSyncSecret, errcode.PermissionDenied},
+		"unsigned":         {&SignedBlockList{Payload: signed.Payload}, testSyncSecret, errcode.PermissionDenied},
+		"missing node":     {signedList(t, "", time.Now()), testSyncSecret, errcode.InvalidArgument},
+		"too many blocks":  {signedList(t, "peer", time.Now(), make([]*SharedBlock, maxSharedBlocks+1)...), testSyncSecret, errcode.InvalidArgument},
+	}
+	for name, c := range rejected {
+		if _, err := c.signed.verify(c.secret); errcode.Of(err) != c.code {
+			t.Errorf("verify(%s) = %v, want %s", name, err, c.code)
+		}
+	}
+}
+
+func TestBlockSyncApply(t *testing.T) {
+	config := DefaultBlockerConfig()
+	config.CollapseThreshold = 0
+	b, api := newTestBlocker(t, config)
+	s := NewBlockSync(&SyncConfig{Enabled: true, Node: "local", Secret: testSyncSecret, MinScore: 50}, nil, b)
+
+	// 本节点自动封禁的 IP 不受其他节点的列表影响
+	blockThreat(t, b, "192.0.2.9")
+
+	t0 := time.Now().UTC()
+	status, err := s.Import(signedList(t, "peer", t0,
+		&SharedBlock{IP: "198.51.100.1", Score: 100},
+		&SharedBlock{IP: "198.51.100.2", Score: 100},
+		&SharedBlock{IP: "198.51.100.3", Score: 10},                  // 低于 MinScore
+		&SharedBlock{IP: "10.0.0.0/8", Score: 100},                   // 不支持的网段
+		&SharedBlock{IP: "198.51.100.4", Score: 100, ExpiresAt: &t0}, // 已过期
+	))
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if status.Node != "peer" || status.Applied != 2 || status.Blocks != 5 {
+		t.Errorf("Import() status = %+v, want 2 of 5 applied", status)
+	}
+	want := []string{"192.0.2.9", "198.51.100.1", "198.51.100.2"}
+	if got := api.values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("access rules = %v, want %v", got, want)
+	}
+
+	// 来自其他节点的封禁不再发布
+	exported, err := s.Export()
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if list, err := exported.verify(testSyncSecret); err != nil || len(list.Blocks) != 1 || list.Blocks[0].IP != "192.0.2.9" {
+		t.Errorf("Export() = %+v, %v, want only 192.0.2.9", list, err)
+	}
+
+	// 重复的列表不做处理，更早的列表和本节点的列表被拒绝
+	if _, err := s.Import(signedList(t, "peer", t0)); err != nil {
+		t.Errorf("Import(same list) error: %v", err)
+	}
+	if _, err := s.Import(signedList(t, "peer", t0.Add(-time.Minute))); errcode.Of(err) != errcode.InvalidArgument {
+		t.Errorf("Import(older list) = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := s.Import(signedList(t, "local", t0.Add(time.Minute))); errcode.Of(err) != errcode.InvalidArgument {
+		t.Errorf("Import(own list) = %v, want INVALID_ARGUMENT", err)
+	}
+	forged := signedList(t, "peer", t0.Add(time.Minute))
+	forged.Signature = blockListSignature("fedcba9876543210", forged.Payload)
+	if _, err := s.Import(forged); errcode.Of(err) != errcode.PermissionDenied {
+		t.Errorf("Import(forged list) = %v, want PERMISSION_DENIED", err)
+	}
+	if got := api.values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("access rules after rejected lists = %v, want %v", got, want)
+	}
+
+	// 对方解封的 IP 在本机解除
+	status, err = s.Import(signedList(t, "peer", t0.Add(time.Minute), &SharedBlock{IP: "198.51.100.1", Score: 100}))
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if status.Revoked != 1 {
+		t.Errorf("Import() status = %+v, want 1 revoked", status)
+	}
+	want = []string{"192.0.2.9", "198.51.100.1"}
+	if got := api.values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("access rules after revoke = %v, want %v", got, want)
+	}
+	if b.IsBlocked("198.51.100.2") {
+		t.Error("revoked shared block still recorded")
+	}
+}
@@ -4,9 +4,12 @@ package cloudflare
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -33,8 +36,15 @@ type Zone struct {
 	ID          string   `json:"id"`
 	Name        string   `json:"name"`
 	Status      string   `json:"status"`
-	NameServers []string `json:"name_servers"`
-	Plan        *Plan    `json:"plan,omitempty"`
+	NameServers []string     `json:"name_servers"`
+	Plan        *Plan        `json:"plan,omitempty"`
+	Account     *ZoneAccount `json:"account,omitempty"`
+}
+
+// ZoneAccount 域名所属的账户
+type ZoneAccount struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 // Plan 套餐信息
@@ -101,12 +111,34 @@ type APIResponse struct {
 	Errors   []APIError      `json:"errors"`
 	Messages []string        `json:"messages"`
 	Result   json.RawMessage `json:"result"`
+	// 列表接口的分页信息
+	ResultInfo *ResultInfo `json:"result_info,omitempty"`
+}
+
+// ResultInfo 分页信息
+type ResultInfo struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+	Count      int `json:"count"`
+	TotalCount int `json:"total_count"`
 }
 
 // APIError API 错误
 type APIError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"-"` // 响应的 HTTP 状态码
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// IsNotFound 检查错误是否为 API 返回的资源不存在
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
 // NewClient 创建新的 Cloudflare 客户端
@@ -152,7 +184,9 @@ func (c *Client) request(method, endpoint string, body interface{}) (*APIRespons
 
 	if !apiResp.Success {
 		if len(apiResp.Errors) > 0 {
-			return nil, fmt.Errorf("API 错误: %s", apiResp.Errors[0].Message)
+			apiErr := apiResp.Errors[0]
+			apiErr.StatusCode = resp.StatusCode
+			return nil, fmt.Errorf("API 错误: %w", &apiErr)
 		}
 		return nil, fmt.Errorf("API 请求失败")
 	}
@@ -160,19 +194,28 @@ func (c *Client) request(method, endpoint string, body interface{}) (*APIRespons
 	return &apiResp, nil
 }
 
-// ListZones 列出所有域名
-func (c *Client) ListZones() ([]Zone, error) {
-	resp, err := c.request("GET", "/zones", nil)
-	if err != nil {
-		return nil, err
-	}
+// zonesPerPage 列出域名时每页的数量（API 允许的最大值）
+const zonesPerPage = 50
 
+// ListZones 列出 API Token 可访问的所有域名，逐页读取
+func (c *Client) ListZones() ([]Zone, error) {
 	var zones []Zone
-	if err := json.Unmarshal(resp.Result, &zones); err != nil {
-		return nil, fmt.Errorf("解析域名列表失败: %w", err)
-	}
+	for page := 1; ; page++ {
+		resp, err := c.request("GET", fmt.Sprintf("/zones?per_page=%d&page=%d", zonesPerPage, page), nil)
+		if err != nil {
+			return nil, err
+		}
 
-	return zones, nil
+		var pageZones []Zone
+		if err := json.Unmarshal(resp.Result, &pageZones); err != nil {
+			return nil, fmt.Errorf("解析域名列表失败: %w", err)
+		}
+		zones = append(zones, pageZones...)
+
+		if resp.ResultInfo == nil || page >= resp.ResultInfo.TotalPages || len(pageZones) == 0 {
+			return zones, nil
+		}
+	}
 }
 
 // GetZone 获取域名信息
@@ -280,18 +323,88 @@ func (c *Client) ListAccessRules(zoneID string) ([]AccessRule, error) {
 	return rules, nil
 }
 
-// CreateAccessRule 创建 IP 访问规则（封禁/允许 IP）
+// CreateAccessRule 创建 IP 访问规则（封禁/允许 IP），ip 可以是 IPv4、IPv6 地址或 Cloudflare 支持的网段
 func (c *Client) CreateAccessRule(zoneID string, mode string, ip string, notes string) (*AccessRule, error) {
+	target, value, err := accessRuleTarget(ip)
+	if err != nil {
+		return nil, err
+	}
+	return c.createAccessRule(zoneAccessRulesPath(zoneID), mode, target, value, notes)
+}
+
+// DeleteAccessRule 删除 IP 访问规则
+func (c *Client) DeleteAccessRule(zoneID, ruleID string) error {
+	_, err := c.request("DELETE", zoneAccessRulesPath(zoneID)+"/"+ruleID, nil)
+	return err
+}
+
+// CreateAccountAccessRule 创建账户级别的 IP 访问规则，对账户下所有域名生效，ip 的格式同 CreateAccessRule
+func (c *Client) CreateAccountAccessRule(accountID string, mode string, ip string, notes string) (*AccessRule, error) {
+	target, value, err := accessRuleTarget(ip)
+	if err != nil {
+		return nil, err
+	}
+	return c.createAccessRule(accountAccessRulesPath(accountID), mode, target, value, notes)
+}
+
+// DeleteAccountAccessRule 删除账户级别的 IP 访问规则
+func (c *Client) DeleteAccountAccessRule(accountID, ruleID string) error {
+	_, err := c.request("DELETE", accountAccessRulesPath(accountID)+"/"+ruleID, nil)
+	return err
+}
+
+// accessRulesPerPage 列出访问规则时每页的数量（API 允许的最大值）
+const accessRulesPerPage = 1000
+
+// zoneAccessRulesPath 域名的访问规则接口
+func zoneAccessRulesPath(zoneID string) string {
+	return fmt.Sprintf("/zones/%s/firewall/access_rules/rules", zoneID)
+}
+
+// accountAccessRulesPath 账户的访问规则接口
+func accountAccessRulesPath(accountID string) string {
+	return fmt.Sprintf("/accounts/%s/firewall/access_rules/rules", accountID)
+}
+
+// listAccessRules 列出 path 下目标类型为 target（ip、country 等，为空时不限）的所有访问规则，逐页读取
+func (c *Client) listAccessRules(path, target string) ([]AccessRule, error) {
+	var rules []AccessRule
+	for page := 1; ; page++ {
+		query := url.Values{}
+		if target != "" {
+			query.Set("configuration.target", target)
+		}
+		query.Set("per_page", strconv.Itoa(accessRulesPerPage))
+		query.Set("page", strconv.Itoa(page))
+		resp, err := c.request("GET", path+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var pageRules []AccessRule
+		if err := json.Unmarshal(resp.Result, &pageRules); err != nil {
+			return nil, fmt.Errorf("解析访问规则失败: %w", err)
+		}
+		rules = append(rules, pageRules...)
+
+		if resp.ResultInfo == nil || page >= resp.ResultInfo.TotalPages || len(pageRules) == 0 {
+			return rules, nil
+		}
+	}
+}
+
+// createAccessRule 在 path 下创建目标类型为 target 的访问规则
+func (c *Client) createAccessRule(path, mode, target, value, notes string) (*AccessRule, error) {
 	body := map[string]interface{}{
 		"mode": mode,
 		"configuration": map[string]string{
-			"target": "ip",
-			"value":  ip,
+			"target": target,
+			"value":  value,
 		},
 		"notes": notes,
 	}
 
-	resp, err := c.request("POST", fmt.Sprintf("/zones/%s/firewall/access_rules/rules", zoneID), body)
+	resp, err := c.request("POST", path, body)
 	if err != nil {
 		return nil, err
 	}
@@ -304,13 +417,14 @@ func (c *Client) CreateAccessRule(zoneID string, mode string, ip string, notes s
 	return &rule, nil
 }
 
-// DeleteAccessRule 删除 IP 访问规则
-func (c *Client) DeleteAccessRule(zoneID, ruleID string) error {
-	_, err := c.request("DELETE", fmt.Sprintf("/zones/%s/firewall/access_rules/rules/%s", zoneID, ruleID), nil)
+// updateAccessRuleMode 修改 path 下访问规则的动作
+func (c *Client) updateAccessRuleMode(path, ruleID, mode, notes string) error {
+	body := map[string]string{"mode": mode, "notes": notes}
+	_, err := c.request("PATCH", path+"/"+ruleID, body)
 	return err
 }
 
-// BlockIP 封禁 IP
+// BlockIP 封禁 IP 或网段
 func (c *Client) BlockIP(zoneID, ip, reason string) (*AccessRule, error) {
 	if reason == "" {
 		reason = fmt.Sprintf("Blocked by Runixo at %s", time.Now().Format(time.RFC3339))
@@ -318,7 +432,7 @@ func (c *Client) BlockIP(zoneID, ip, reason string) (*AccessRule, error) {
 	return c.CreateAccessRule(zoneID, "block", ip, reason)
 }
 
-// UnblockIP 解封 IP（通过删除规则）
+// UnblockIP 解封 IP 或网段（通过删除规则）
 func (c *Client) UnblockIP(zoneID, ruleID string) error {
 	return c.DeleteAccessRule(zoneID, ruleID)
 }
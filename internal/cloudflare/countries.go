@@ -0,0 +1,218 @@
+// Package cloudflare 按国家/地区封禁：在 Cloudflare 边缘按访问者所在国家/地区封禁或质询
+package cloudflare
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// AllZones 国家/地区规则中表示所有启用自动封禁的域名的键
+	AllZones = "*"
+	// countryRuleNotes 由 Runixo 管理的国家/地区访问规则的备注，同步时只修改和删除带此备注的规则
+	countryRuleNotes = "Managed by Runixo country rules"
+)
+
+// CountryRules 一个范围内的国家/地区规则，填 ISO 3166-1 两位字母代码，T1 表示 Tor 网络
+type CountryRules struct {
+	// 封禁的国家/地区
+	Block []string `json:"block"`
+	// 需要通过质询的国家/地区
+	Challenge []string `json:"challenge"`
+}
+
+// CountryRule 已应用的一条国家/地区规则
+type CountryRule struct {
+	Scope   string `json:"scope"` // 域名名称或 account
+	ZoneID  string `json:"zone_id,omitempty"`
+	Country string `json:"country"`
+	Mode    string `json:"mode"` // block 或 challenge
+	RuleID  string `json:"rule_id"`
+}
+
+// ValidateCountryRules 校验国家/地区规则，键为 zone 名称、ID、其下的主机名、AllZones 或 AccountScope
+func ValidateCountryRules(rules map[string]*CountryRules) error {
+	var fields []errcode.FieldViolation
+	for scope, r := range rules {
+		if r == nil {
+			continue
+		}
+		modes := make(map[string]string)
+		check := func(list []string, mode string) {
+			for i, code := range list {
+				field := fmt.Sprintf("country_rules.%s.%s[%d]", scope, mode, i)
+				code = strings.ToUpper(strings.TrimSpace(code))
+				switch {
+				case !isCountryCode(code):
+					fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf("%q 不是两位国家/地区代码", list[i])})
+				case modes[code] != "" && modes[code] != mode:
+					fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf("%s 同时出现在 block 和 challenge 中", code)})
+				default:
+					modes[code] = mode
+				}
+			}
+		}
+		check(r.Block, "block")
+		check(r.Challenge, "challenge")
+	}
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return errcode.Invalid(errcode.ValidationFailed, "国家/地区规则无效", fields)
+	}
+	return nil
+}
+
+// isCountryCode 是否为两位大写字母或数字组成的代码（如 CN、T1）
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// desired 规则中国家/地区代码到动作的映射
+func (r *CountryRules) desired() map[string]string {
+	modes := make(map[string]string)
+	if r == nil {
+		return modes
+	}
+	for _, code := range r.Block {
+		modes[strings.ToUpper(strings.TrimSpace(code))] = "block"
+	}
+	for _, code := range r.Challenge {
+		modes[strings.ToUpper(strings.TrimSpace(code))] = "challenge"
+	}
+	return modes
+}
+
+// syncCountryRules 使 Cloudflare 上由 Runixo 管理的国家/地区访问规则与配置一致，返回应用后的规则。
+//
+// 每个域名使用以其名称、ID 或主机名为键的规则，没有时使用 AllZones 的规则（只对启用自动封禁的域名生效）；
+// AccountScope 的规则在账户级别创建。配置中不再出现的国家/地区删除对应规则，
+// 不带 Runixo 备注的规则不修改，与其冲突的配置被跳过
+func syncCountryRules(client *Client, zones *ZoneRegistry, rules map[string]*CountryRules) ([]*CountryRule, error) {
+	statuses, err := zones.Zones()
+	if err != nil {
+		return nil, err
+	}
+
+	// 按域名归并规则，明确指定域名的规则优先于 AllZones
+	perZone := make(map[string]*CountryRules)
+	for key, r := range rules {
+		if key == AllZones || key == AccountScope {
+			continue
+		}
+		zone, err := zones.Resolve(key)
+		if err != nil {
+			log.Warn().Err(err).Str("zone", key).Msg("跳过国家/地区规则")
+			continue
+		}
+		perZone[zone.ID] = r
+	}
+
+	var applied []*CountryRule
+	var errs []error
+	for _, status := range statuses {
+		r, ok := perZone[status.ID]
+		if !ok && status.Enabled {
+			r = rules[AllZones]
+		}
+		zone := status.Zone
+		result, err := syncScopeCountryRules(client, blockTarget{zone: &zone}, r.desired())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", zone.Name, err))
+		}
+		applied = append(applied, result...)
+	}
+
+	if r, ok := rules[AccountScope]; ok {
+		if accountID := zones.AccountID(); accountID == "" {
+			errs = append(errs, &ConfigError{Message: "账户级国家/地区规则需要配置账户 ID"})
+		} else {
+			result, err := syncScopeCountryRules(client, blockTarget{accountID: accountID}, r.desired())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", AccountScope, err))
+			}
+			applied = append(applied, result...)
+		}
+	}
+
+	return applied, errors.Join(errs...)
+}
+
+// syncScopeCountryRules 同步一个域名或账户上的国家/地区规则
+func syncScopeCountryRules(client *Client, target blockTarget, desired map[string]string) ([]*CountryRule, error) {
+	path := target.accessRulesPath()
+	existing, err := client.listAccessRules(path, "country")
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []*CountryRule
+	var errs []error
+	record := func(country, mode, ruleID string) {
+		rule := &CountryRule{Scope: target.name(), Country: country, Mode: mode, RuleID: ruleID}
+		if target.zone != nil {
+			rule.ZoneID = target.zone.ID
+		}
+		applied = append(applied, rule)
+	}
+
+	for _, rule := range existing {
+		country := rule.Configuration.Value
+		mode, wanted := desired[country]
+		managed := rule.Notes == countryRuleNotes
+		switch {
+		case !managed && wanted:
+			log.Warn().Str("zone", target.name()).Str("country", country).Str("mode", rule.Mode).
+				Msg("已有非 Runixo 管理的国家/地区规则，跳过")
+			delete(desired, country)
+		case !managed:
+		case !wanted:
+			if _, err := client.request("DELETE", path+"/"+rule.ID, nil); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			log.Info().Str("zone", target.name()).Str("country", country).Msg("已删除国家/地区规则")
+		case rule.Mode != mode:
+			if err := client.updateAccessRuleMode(path, rule.ID, mode, countryRuleNotes); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			record(country, mode, rule.ID)
+			delete(desired, country)
+			log.Info().Str("zone", target.name()).Str("country", country).Str("mode", mode).Msg("已更新国家/地区规则")
+		default:
+			record(country, mode, rule.ID)
+			delete(desired, country)
+		}
+	}
+
+	countries := make([]string, 0, len(desired))
+	for country := range desired {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	for _, country := range countries {
+		mode := desired[country]
+		rule, err := client.createAccessRule(path, mode, "country", country, countryRuleNotes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", country, err))
+			continue
+		}
+		record(country, mode, rule.ID)
+		log.Info().Str("zone", target.name()).Str("country", country).Str("mode", mode).Msg("已创建国家/地区规则")
+	}
+
+	return applied, errors.Join(errs...)
+}
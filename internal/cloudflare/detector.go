@@ -2,6 +2,8 @@
 package cloudflare
 
 import (
+	"fmt"
+	"net"
 	"regexp"
 	"strings"
 	"sync"
@@ -19,6 +21,8 @@ const (
 	ThreatTypePathTraversal ThreatType = "path_traversal"  // 路径遍历
 	ThreatTypeBotAbuse      ThreatType = "bot_abuse"       // 恶意爬虫
 	ThreatTypeDDoS          ThreatType = "ddos"            // DDoS 攻击
+	ThreatTypeReputation    ThreatType = "reputation"      // 威胁情报命中
+	ThreatTypeEdge          ThreatType = "edge"            // Cloudflare 边缘拦截
 	ThreatTypeUnknown       ThreatType = "unknown"         // 未知威胁
 )
 
@@ -33,6 +37,27 @@ type Threat struct {
 	Line        string     `json:"line"`
 	Timestamp   time.Time  `json:"timestamp"`
 	Count       int        `json:"count"`
+	// 来自其他节点共享的封禁列表时为该节点的名称
+	Node string `json:"node,omitempty"`
+}
+
+// Summary 通知消息的正文：IP、威胁类型、评分、描述和来源
+func (t *Threat) Summary() string {
+	lines := []string{
+		fmt.Sprintf("检测到高风险 IP %s", t.IP),
+		fmt.Sprintf("类型: %s", t.Type),
+		fmt.Sprintf("评分: %d", t.Score),
+	}
+	if t.Description != "" {
+		lines = append(lines, "描述: "+t.Description)
+	}
+	switch {
+	case t.Node != "":
+		lines = append(lines, "来源: 节点 "+t.Node)
+	case t.Source != "":
+		lines = append(lines, "来源: "+t.Source)
+	}
+	return strings.Join(lines, "\n")
 }
 
 // ThreatDetector 威胁检测器
@@ -40,6 +65,9 @@ type ThreatDetector struct {
 	config     *DetectorConfig
 	patterns   []DetectionPattern
 	ipTracker  map[string]*IPActivity
+	sources    map[string]*sourceConfig // 设置了格式的日志来源，其余来源使用所有内置模式
+	allowlist  *Allowlist
+	intel      *ThreatIntel
 	mu         sync.RWMutex
 	threatChan chan *Threat
 }
@@ -65,6 +93,8 @@ type DetectionPattern struct {
 	Score       int
 	Description string
 	IPExtractor func(string) string
+	// 适用的日志格式，为空时适用于所有格式（custom 除外）
+	Formats []string
 }
 
 // IPActivity IP 活动记录
@@ -75,6 +105,8 @@ type IPActivity struct {
 	TotalScore   int
 	ThreatCounts map[ThreatType]int
 	Lines        []string
+	Reputation   *Reputation // 威胁情报命中信息，命中后分数只增加一次
+	source       string      // 最近一次出现的日志来源
 }
 
 // DefaultDetectorConfig 默认检测器配置
@@ -98,6 +130,7 @@ func NewThreatDetector(config *DetectorConfig) *ThreatDetector {
 	td := &ThreatDetector{
 		config:     config,
 		ipTracker:  make(map[string]*IPActivity),
+		sources:    make(map[string]*sourceConfig),
 		threatChan: make(chan *Threat, 100),
 	}
 
@@ -118,6 +151,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       20,
 			Description: "SSH 登录失败",
 			IPExtractor: extractIPFromMatch,
+			Formats:     []string{FormatSSHD},
 		},
 		{
 			Name:        "SSH Invalid User",
@@ -126,6 +160,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       25,
 			Description: "SSH 无效用户尝试",
 			IPExtractor: extractIPFromMatch,
+			Formats:     []string{FormatSSHD},
 		},
 		{
 			Name:        "SSH Too Many Auth Failures",
@@ -134,6 +169,18 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       50,
 			Description: "SSH 认证失败次数过多",
 			IPExtractor: extractIPFromMatch,
+			Formats:     []string{FormatSSHD},
+		},
+
+		// Web 登录暴力破解，登录失败时这些页面返回 200（重新显示表单）、401 或 403
+		{
+			Name:        "Web Login Failure",
+			Type:        ThreatTypeBruteForce,
+			Pattern:     regexp.MustCompile(`(\d+\.\d+\.\d+\.\d+).*"POST [^" ]*(wp-login\.php|xmlrpc\.php|/login|/signin|/user/login|/admin/login)[^" ]* [^"]*" (200|401|403) `),
+			Score:       10,
+			Description: "Web 登录失败",
+			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 
 		// Web 扫描
@@ -144,6 +191,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       15,
 			Description: "扫描敏感路径",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 		{
 			Name:        "Nginx 403 Scanner",
@@ -152,6 +200,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       10,
 			Description: "访问禁止路径",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 
 		// SQL 注入
@@ -162,6 +211,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       40,
 			Description: "SQL 注入尝试",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 
 		// XSS 攻击
@@ -172,6 +222,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       35,
 			Description: "XSS 攻击尝试",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 
 		// 路径遍历
@@ -182,6 +233,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       30,
 			Description: "路径遍历攻击",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 
 		// 恶意爬虫
@@ -192,6 +244,7 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       50,
 			Description: "恶意扫描工具",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 
 		// 高频请求（潜在 DDoS）
@@ -202,8 +255,12 @@ func (d *ThreatDetector) initPatterns() {
 			Score:       1, // 低分，需要累积
 			Description: "高频请求",
 			IPExtractor: extractIPFromMatch,
+			Formats:     webAccessFormats,
 		},
 	}
+
+	// 错误日志、sshd 和邮件服务按日志格式使用的模式
+	d.patterns = append(d.patterns, formatPatterns()...)
 }
 
 // extractIPFromMatch 从正则匹配中提取 IP
@@ -222,8 +279,9 @@ func (d *ThreatDetector) Analyze(line, source string) *Threat {
 	defer d.mu.Unlock()
 
 	var detectedThreat *Threat
+	detectedScore := 0
 
-	for _, pattern := range d.patterns {
+	for _, pattern := range d.patternsFor(source) {
 		// 检查是否启用该类型检测
 		if !d.isDetectionEnabled(pattern.Type) {
 			continue
@@ -235,51 +293,25 @@ func (d *ThreatDetector) Analyze(line, source string) *Threat {
 				continue
 			}
 
-			// 跳过私有 IP
-			if isPrivateIP(ip) {
+			// 跳过私有 IP 和白名单中的 IP
+			if isPrivateIP(ip) || (d.allowlist != nil && d.allowlist.Contains(ip)) {
 				continue
 			}
 
-			// 更新 IP 活动记录
-			activity := d.getOrCreateActivity(ip)
-			activity.LastSeen = time.Now()
-			activity.TotalScore += pattern.Score
-			activity.ThreatCounts[pattern.Type]++
-			activity.Lines = append(activity.Lines, line)
-
-			// 限制保存的日志行数
-			if len(activity.Lines) > 100 {
-				activity.Lines = activity.Lines[len(activity.Lines)-100:]
-			}
-
-			// 创建威胁记录
-			threat := &Threat{
-				ID:          generateThreatID(),
-				IP:          ip,
-				Type:        pattern.Type,
-				Score:       activity.TotalScore,
-				Description: pattern.Description,
-				Source:      source,
-				Line:        line,
-				Timestamp:   time.Now(),
-				Count:       activity.ThreatCounts[pattern.Type],
-			}
-
-			// 如果分数超过阈值，发送到通道
-			if activity.TotalScore >= d.config.BlockThreshold {
-				select {
-				case d.threatChan <- threat:
-				default:
-				}
-			}
+			threat := d.recordLocked(ip, pattern.Type, pattern.Score, pattern.Description, line, source)
 
-			// 返回最高分的威胁
-			if detectedThreat == nil || threat.Score > detectedThreat.Score {
-				detectedThreat = threat
+			// 返回单条匹配分数最高的威胁（累计分数随匹配的模式递增，不能用于比较），
+			// 使同时匹配高频请求的 Web 登录失败仍按暴力破解处理
+			if detectedThreat == nil || pattern.Score > detectedScore {
+				detectedThreat, detectedScore = threat, pattern.Score
 			}
 		}
 	}
 
+	if detectedThreat != nil {
+		detectedThreat.Score = d.ipTracker[detectedThreat.IP].TotalScore
+	}
+
 	return detectedThreat
 }
 
@@ -354,6 +386,7 @@ func (d *ThreatDetector) GetIPActivity(ip string) *IPActivity {
 			TotalScore:   activity.TotalScore,
 			ThreatCounts: activity.ThreatCounts,
 			Lines:        activity.Lines,
+			Reputation:   activity.Reputation,
 		}
 	}
 	return nil
@@ -372,6 +405,7 @@ func (d *ThreatDetector) GetAllActivities() []*IPActivity {
 			LastSeen:     activity.LastSeen,
 			TotalScore:   activity.TotalScore,
 			ThreatCounts: activity.ThreatCounts,
+			Reputation:   activity.Reputation,
 		})
 	}
 	return activities
@@ -391,6 +425,7 @@ func (d *ThreatDetector) GetHighRiskIPs(minScore int) []*IPActivity {
 				LastSeen:     activity.LastSeen,
 				TotalScore:   activity.TotalScore,
 				ThreatCounts: activity.ThreatCounts,
+				Reputation:   activity.Reputation,
 			})
 		}
 	}
@@ -402,6 +437,116 @@ func (d *ThreatDetector) Threats() <-chan *Threat {
 	return d.threatChan
 }
 
+// SetAllowlist 设置白名单，其中 IP 的活动不被记录
+func (d *ThreatDetector) SetAllowlist(allowlist *Allowlist) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.allowlist = allowlist
+}
+
+// SetIntel 设置威胁情报，检测到的 IP 首次出现时查询信誉
+func (d *ThreatDetector) SetIntel(intel *ThreatIntel) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.intel = intel
+}
+
+// RecordEvent 把日志以外观察到的威胁（如 Cloudflare 边缘事件）计入 IP 的活动记录，返回威胁，
+// 分数达到封禁阈值时同时发送到威胁通道。私有 IP 和白名单中的 IP 返回 nil
+func (d *ThreatDetector) RecordEvent(ip string, threatType ThreatType, score int, description, line, source string) *Threat {
+	if isPrivateIP(ip) || (d.allowlist != nil && d.allowlist.Contains(ip)) {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.recordLocked(ip, threatType, score, description, line, source)
+}
+
+// recordLocked 更新 IP 的活动记录并创建威胁，分数达到封禁阈值时发送到通道（需要持有锁）
+func (d *ThreatDetector) recordLocked(ip string, threatType ThreatType, score int, description, line, source string) *Threat {
+	// 更新 IP 活动记录
+	activity := d.getOrCreateActivity(ip)
+	activity.LastSeen = time.Now()
+	activity.TotalScore += score
+	activity.ThreatCounts[threatType]++
+	activity.Lines = append(activity.Lines, line)
+	activity.source = source
+	if activity.Reputation == nil && d.intel != nil {
+		if rep := d.intel.Lookup(ip); rep != nil {
+			activity.Reputation = rep
+			activity.TotalScore += rep.Score
+		}
+	}
+
+	// 限制保存的日志行数
+	if len(activity.Lines) > 100 {
+		activity.Lines = activity.Lines[len(activity.Lines)-100:]
+	}
+
+	// 创建威胁记录
+	threat := &Threat{
+		ID:          generateThreatID(),
+		IP:          ip,
+		Type:        threatType,
+		Score:       activity.TotalScore,
+		Description: description,
+		Source:      source,
+		Line:        line,
+		Timestamp:   time.Now(),
+		Count:       activity.ThreatCounts[threatType],
+	}
+
+	// 如果分数超过阈值，发送到通道
+	if activity.TotalScore >= d.config.BlockThreshold {
+		select {
+		case d.threatChan <- threat:
+		default:
+		}
+	}
+	return threat
+}
+
+// ApplyReputation 把异步查询到的信誉加到 IP 的活动记录，分数达到封禁阈值时返回威胁。
+// IP 的活动记录已过期或已经加过信誉分数时返回 nil
+func (d *ThreatDetector) ApplyReputation(ip string, rep *Reputation) *Threat {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	activity, exists := d.ipTracker[ip]
+	if !exists || activity.Reputation != nil {
+		return nil
+	}
+	activity.Reputation = rep
+	activity.TotalScore += rep.Score
+	activity.ThreatCounts[ThreatTypeReputation]++
+
+	if activity.TotalScore < d.config.BlockThreshold {
+		return nil
+	}
+
+	threat := &Threat{
+		ID:          generateThreatID(),
+		IP:          ip,
+		Type:        ThreatTypeReputation,
+		Score:       activity.TotalScore,
+		Description: "威胁情报命中: " + rep.Feed + " " + rep.Detail,
+		Source:      activity.source,
+		Timestamp:   time.Now(),
+		Count:       1,
+	}
+	if len(activity.Lines) > 0 {
+		threat.Line = activity.Lines[len(activity.Lines)-1]
+	}
+
+	select {
+	case d.threatChan <- threat:
+	default:
+	}
+	return threat
+}
+
 // ResetIP 重置 IP 的活动记录
 func (d *ThreatDetector) ResetIP(ip string) {
 	d.mu.Lock()
@@ -411,6 +556,10 @@ func (d *ThreatDetector) ResetIP(ip string) {
 
 // isPrivateIP 检查是否为私有 IP
 func isPrivateIP(ip string) bool {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsLinkLocalUnicast() || parsed.IsUnspecified()
+	}
+
 	privateRanges := []string{
 		"10.",
 		"172.16.", "172.17.", "172.18.", "172.19.",
@@ -0,0 +1,158 @@
+// Package cloudflare 演练模式：检测和封禁流程照常运行，但不在 Cloudflare 或本机防火墙上执行，只记录将执行的动作
+package cloudflare
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxDryRunEntries 演练报告最多记录的 IP 数量，超出后只计数
+const maxDryRunEntries = 10000
+
+// DryRunEntry 演练模式下将被处理的 IP
+type DryRunEntry struct {
+	IP string `json:"ip"`
+	// 将执行的动作：block、challenge、js_challenge、managed_challenge 或 rate_limit
+	Action string `json:"action"`
+	// rate_limit 时为将被限速的请求，如 POST /wp-login.php
+	Detail string `json:"detail,omitempty"`
+	// 将封禁的范围：域名、account 或 local
+	Targets []string `json:"targets"`
+	// 无法确定范围时的错误
+	Error      string     `json:"error,omitempty"`
+	ThreatType ThreatType `json:"threat_type"`
+	Score      int        `json:"score"`
+	Reason     string     `json:"reason"`
+	Source     string     `json:"source,omitempty"`
+	// 达到封禁阈值的次数
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// DryRunReport 演练报告
+type DryRunReport struct {
+	Enabled bool      `json:"enabled"`
+	Since   time.Time `json:"since"`
+	// 将被处理的 IP 数量
+	Total        int            `json:"total"`
+	ByAction     map[string]int `json:"by_action"`
+	ByThreatType map[string]int `json:"by_threat_type"`
+	// 超出记录上限未记录的 IP 数量
+	Dropped int `json:"dropped,omitempty"`
+	// 按评分从高到低排列
+	Entries []*DryRunEntry `json:"entries"`
+}
+
+// recordDryRun 记录演练模式下对威胁将执行的动作，IP 首次出现或动作变化时发送 would_block 事件
+func (b *IPBlocker) recordDryRun(threat *Threat) {
+	entry := &DryRunEntry{
+		IP:         threat.IP,
+		ThreatType: threat.Type,
+		Score:      threat.Score,
+		Reason:     threat.Description,
+		Source:     threat.Source,
+	}
+	if method, path, ok := b.rateLimitRequest(threat); ok {
+		entry.Action, entry.Detail = BruteForceRateLimit, method+" "+path
+	} else {
+		entry.Action = b.config.modeForScore(threat.Score)
+	}
+
+	// 确定范围只读取域名列表，不修改 Cloudflare 上的配置
+	targets, err := b.threatTargets(threat.Source)
+	if err != nil && b.fallbackLocal(err) {
+		targets, err = []blockTarget{{local: true}}, nil
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	entry.Targets = make([]string, 0, len(targets))
+	for _, target := range targets {
+		entry.Targets = append(entry.Targets, target.name())
+	}
+
+	now := time.Now()
+
+	b.dryRunMu.Lock()
+	existing, exists := b.dryRun[threat.IP]
+	switch {
+	case exists:
+		existing.Count++
+		existing.LastSeen = now
+		changed := existing.Action != entry.Action
+		if entry.Score > existing.Score || changed {
+			existing.Score, existing.ThreatType, existing.Reason = entry.Score, entry.ThreatType, entry.Reason
+			existing.Action, existing.Detail, existing.Targets, existing.Error = entry.Action, entry.Detail, entry.Targets, entry.Error
+		}
+		b.dryRunMu.Unlock()
+		if !changed {
+			return
+		}
+	case len(b.dryRun) >= maxDryRunEntries:
+		b.dryRunDropped++
+		b.dryRunMu.Unlock()
+		return
+	default:
+		entry.Count, entry.FirstSeen, entry.LastSeen = 1, now, now
+		b.dryRun[threat.IP] = entry
+		b.dryRunMu.Unlock()
+	}
+
+	b.sendEvent(&BlockEvent{
+		Type:      "would_block",
+		IP:        threat.IP,
+		Reason:    entry.Action,
+		Timestamp: now,
+		Threat:    threat,
+	})
+
+	log.Info().
+		Str("ip", threat.IP).
+		Str("action", entry.Action).
+		Strs("targets", entry.Targets).
+		Str("threat_type", string(threat.Type)).
+		Int("score", threat.Score).
+		Msg("演练模式：将处理 IP")
+}
+
+// DryRunReport 演练报告
+func (b *IPBlocker) DryRunReport() *DryRunReport {
+	b.dryRunMu.Lock()
+	defer b.dryRunMu.Unlock()
+
+	report := &DryRunReport{
+		Enabled:      b.config.DryRun,
+		Since:        b.dryRunSince,
+		Total:        len(b.dryRun),
+		ByAction:     make(map[string]int),
+		ByThreatType: make(map[string]int),
+		Dropped:      b.dryRunDropped,
+		Entries:      make([]*DryRunEntry, 0, len(b.dryRun)),
+	}
+	for _, entry := range b.dryRun {
+		copied := *entry
+		report.Entries = append(report.Entries, &copied)
+		report.ByAction[entry.Action]++
+		report.ByThreatType[string(entry.ThreatType)]++
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Score != report.Entries[j].Score {
+			return report.Entries[i].Score > report.Entries[j].Score
+		}
+		return report.Entries[i].IP < report.Entries[j].IP
+	})
+	return report
+}
+
+// ResetDryRunReport 清空演练报告，重新开始记录
+func (b *IPBlocker) ResetDryRunReport() {
+	b.dryRunMu.Lock()
+	defer b.dryRunMu.Unlock()
+
+	b.dryRun = make(map[string]*DryRunEntry)
+	b.dryRunSince = time.Now()
+	b.dryRunDropped = 0
+}
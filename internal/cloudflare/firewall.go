@@ -0,0 +1,140 @@
+// Package cloudflare 本机防火墙：Cloudflare API 不可用或站点未经 Cloudflare 代理时在主机上封禁
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// localFirewall 主机防火墙
+type localFirewall interface {
+	// Name 防火墙名称，如 nftables、iptables、windows_firewall
+	Name() string
+	// Block 丢弃来自 ip 的连接，已封禁时不报错
+	Block(ip string) error
+	// Unblock 解除封禁，未封禁时不报错
+	Unblock(ip string) error
+}
+
+// LookPathFunc 查找防火墙命令，插件传入检查 exec 权限的实现
+type LookPathFunc func(name string) (string, error)
+
+// firewallCommandTimeout 单条防火墙命令的超时时间
+const firewallCommandTimeout = 10 * time.Second
+
+// runFirewallCommand 执行防火墙命令，stdin 不为空时作为命令输入，失败时错误中包含命令输出
+func runFirewallCommand(stdin, path string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), firewallCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s: %w: %s", filepath.Base(path), strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// isUnavailable 错误是否表示 Cloudflare API 不可用：请求失败、响应无法解析、限流或服务端错误
+func isUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return false
+	}
+	var codeErr *errcode.Error
+	if errors.As(err, &codeErr) {
+		return codeErr.Code == errcode.UpstreamUnavailable
+	}
+	return true
+}
+
+// hostFirewall 本机防火墙，首次使用时检测
+func (b *IPBlocker) hostFirewall() (localFirewall, error) {
+	b.firewallMu.Lock()
+	defer b.firewallMu.Unlock()
+
+	if b.firewall == nil {
+		lookPath := b.lookPath
+		if lookPath == nil {
+			lookPath = exec.LookPath
+		}
+		firewall, err := newLocalFirewall(lookPath)
+		if err != nil {
+			return nil, errcode.Wrap(errcode.Unavailable, err, "本机防火墙不可用")
+		}
+		b.firewall = firewall
+	}
+	return b.firewall, nil
+}
+
+// SetLookPath 设置查找防火墙命令的函数，在首次本机封禁前调用
+func (b *IPBlocker) SetLookPath(lookPath LookPathFunc) {
+	b.firewallMu.Lock()
+	defer b.firewallMu.Unlock()
+	b.lookPath = lookPath
+}
+
+// fallbackLocal Cloudflare 封禁失败时是否改为在本机封禁
+func (b *IPBlocker) fallbackLocal(err error) bool {
+	return b.config.LocalFallback && isUnavailable(err)
+}
+
+// isLocalSource 日志是否来自不经过 Cloudflare 代理的站点
+func (b *IPBlocker) isLocalSource(source string) bool {
+	if source == "" {
+		return false
+	}
+	for _, local := range b.config.LocalSources {
+		if local == source || filepath.Clean(local) == filepath.Clean(source) {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreLocalBlocks 重新应用保存的本机封禁，防火墙规则在重启后不保留
+func (b *IPBlocker) restoreLocalBlocks() {
+	b.mu.RLock()
+	var ips []string
+	for _, blocked := range b.blockedIPs {
+		if blocked.Scope == BlockScopeLocal {
+			ips = append(ips, blocked.IP)
+		}
+	}
+	b.mu.RUnlock()
+
+	if len(ips) == 0 {
+		return
+	}
+
+	firewall, err := b.hostFirewall()
+	if err != nil {
+		log.Error().Err(err).Int("count", len(ips)).Msg("恢复本机封禁失败")
+		return
+	}
+	for _, ip := range ips {
+		if err := firewall.Block(ip); err != nil {
+			log.Error().Err(err).Str("ip", ip).Msg("恢复本机封禁失败")
+		}
+	}
+	log.Info().Int("count", len(ips)).Str("firewall", firewall.Name()).Msg("已恢复本机封禁")
+}
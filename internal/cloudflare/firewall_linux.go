@@ -0,0 +1,149 @@
+//go:build linux
+
+package cloudflare
+
+import (
+	"errors"
+	"strings"
+)
+
+// 本机封禁使用的 nftables 表和 iptables 链
+const (
+	nftTable      = "runixo"
+	iptablesChain = "RUNIXO-BLOCK"
+)
+
+// nftRuleset 封禁用的 nftables 表：两个地址集合和一条在 input 钩子上丢弃集合中地址的链
+const nftRuleset = `table inet runixo {
+	set blocked4 { type ipv4_addr; flags interval; }
+	set blocked6 { type ipv6_addr; flags interval; }
+	chain input {
+		type filter hook input priority -10; policy accept;
+		ip saddr @blocked4 drop
+		ip6 saddr @blocked6 drop
+	}
+}
+`
+
+// newLocalFirewall 优先使用 nftables，没有 nft 命令时使用 iptables
+func newLocalFirewall(lookPath LookPathFunc) (localFirewall, error) {
+	if nft, err := lookPath("nft"); err == nil {
+		firewall := &nftFirewall{nft: nft}
+		if err := firewall.setup(); err != nil {
+			return nil, err
+		}
+		return firewall, nil
+	}
+	if iptables, err := lookPath("iptables"); err == nil {
+		firewall := &iptablesFirewall{iptables: iptables}
+		if ip6tables, err := lookPath("ip6tables"); err == nil {
+			firewall.ip6tables = ip6tables
+		}
+		if err := firewall.setup(); err != nil {
+			return nil, err
+		}
+		return firewall, nil
+	}
+	return nil, errors.New("未找到 nft 或 iptables 命令")
+}
+
+// nftFirewall 通过 nftables 集合封禁
+type nftFirewall struct {
+	nft string
+}
+
+func (f *nftFirewall) Name() string { return "nftables" }
+
+// setup 表不存在时创建
+func (f *nftFirewall) setup() error {
+	if runFirewallCommand("", f.nft, "list", "table", "inet", nftTable) == nil {
+		return nil
+	}
+	return runFirewallCommand(nftRuleset, f.nft, "-f", "-")
+}
+
+// set IP 所在的集合
+func (f *nftFirewall) set(ip string) string {
+	if isIPv6(ip) {
+		return "blocked6"
+	}
+	return "blocked4"
+}
+
+func (f *nftFirewall) Block(ip string) error {
+	return runFirewallCommand("", f.nft, "add", "element", "inet", nftTable, f.set(ip), "{ "+ip+" }")
+}
+
+func (f *nftFirewall) Unblock(ip string) error {
+	// 先以 add 保证元素存在，使 delete 在未封禁时不报错
+	if err := f.Block(ip); err != nil {
+		return err
+	}
+	return runFirewallCommand("", f.nft, "delete", "element", "inet", nftTable, f.set(ip), "{ "+ip+" }")
+}
+
+// iptablesFirewall 通过 iptables/ip6tables 的独立链封禁
+type iptablesFirewall struct {
+	iptables  string
+	ip6tables string // 没有 ip6tables 时为空，不能封禁 IPv6 地址
+}
+
+func (f *iptablesFirewall) Name() string { return "iptables" }
+
+// setup 创建封禁链并从 INPUT 跳转
+func (f *iptablesFirewall) setup() error {
+	for _, command := range []string{f.iptables, f.ip6tables} {
+		if command == "" {
+			continue
+		}
+		if runFirewallCommand("", command, "-w", "-n", "-L", iptablesChain) != nil {
+			if err := runFirewallCommand("", command, "-w", "-N", iptablesChain); err != nil {
+				return err
+			}
+		}
+		if runFirewallCommand("", command, "-w", "-C", "INPUT", "-j", iptablesChain) != nil {
+			if err := runFirewallCommand("", command, "-w", "-I", "INPUT", "-j", iptablesChain); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// command IP 对应的命令
+func (f *iptablesFirewall) command(ip string) (string, error) {
+	if !isIPv6(ip) {
+		return f.iptables, nil
+	}
+	if f.ip6tables == "" {
+		return "", errors.New("未找到 ip6tables 命令，无法封禁 IPv6 地址")
+	}
+	return f.ip6tables, nil
+}
+
+func (f *iptablesFirewall) Block(ip string) error {
+	command, err := f.command(ip)
+	if err != nil {
+		return err
+	}
+	if runFirewallCommand("", command, "-w", "-C", iptablesChain, "-s", ip, "-j", "DROP") == nil {
+		return nil
+	}
+	return runFirewallCommand("", command, "-w", "-A", iptablesChain, "-s", ip, "-j", "DROP")
+}
+
+func (f *iptablesFirewall) Unblock(ip string) error {
+	command, err := f.command(ip)
+	if err != nil {
+		return err
+	}
+	if runFirewallCommand("", command, "-w", "-C", iptablesChain, "-s", ip, "-j", "DROP") != nil {
+		return nil
+	}
+	return runFirewallCommand("", command, "-w", "-D", iptablesChain, "-s", ip, "-j", "DROP")
+}
+
+// isIPv6 地址或网段是否为 IPv6，值已是规范形式
+func isIPv6(ip string) bool {
+	return strings.Contains(ip, ":")
+}
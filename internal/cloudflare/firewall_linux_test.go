@@ -0,0 +1,149 @@
+//go:build linux
+
+package cloudflare
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeCommandScript 记录参数的防火墙命令；存在 exists 文件时查询类命令（list、-L、-C）成功，否则失败
+const fakeCommandScript = `#!/bin/sh
+echo "${0##*/} $*" >> "%[1]s/log"
+if [ "$1" = "-f" ]; then cat > "%[1]s/stdin"; fi
+case "$*" in
+list\ *|*\ -L\ *|*\ -C\ *) [ -e "%[1]s/exists" ] ;;
+esac
+`
+
+type fakeCommands struct {
+	t   *testing.T
+	dir string
+}
+
+func newFakeCommands(t *testing.T, names ...string) *fakeCommands {
+	t.Helper()
+	f := &fakeCommands{t: t, dir: t.TempDir()}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(f.dir, name), []byte(fmt.Sprintf(fakeCommandScript, f.dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func (f *fakeCommands) lookPath(name string) (string, error) {
+	path := filepath.Join(f.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", exec.ErrNotFound
+	}
+	return path, nil
+}
+
+// setExists 设置查询类命令的结果，即表、链或规则是否已存在
+func (f *fakeCommands) setExists(exists bool) {
+	path := filepath.Join(f.dir, "exists")
+	if !exists {
+		os.Remove(path)
+	} else if err := os.WriteFile(path, nil, 0644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// calls 返回并清空已执行的命令
+func (f *fakeCommands) calls() []string {
+	data, _ := os.ReadFile(filepath.Join(f.dir, "log"))
+	os.Remove(filepath.Join(f.dir, "log"))
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func (f *fakeCommands) expect(action string, want ...string) {
+	f.t.Helper()
+	if got := f.calls(); !reflect.DeepEqual(got, want) {
+		f.t.Errorf("%s ran:\n%s\nwant:\n%s", action, strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNftFirewall(t *testing.T) {
+	f := newFakeCommands(t, "nft", "iptables")
+	firewall, err := newLocalFirewall(f.lookPath)
+	if err != nil {
+		t.Fatalf("newLocalFirewall() error: %v", err)
+	}
+	if firewall.Name() != "nftables" {
+		t.Errorf("Name() = %q, want nftables", firewall.Name())
+	}
+	f.expect("setup", "nft list table inet runixo", "nft -f -")
+	if stdin, _ := os.ReadFile(filepath.Join(f.dir, "stdin")); string(stdin) != nftRuleset {
+		t.Errorf("nft -f - input = %q, want the runixo ruleset", stdin)
+	}
+
+	f.setExists(true)
+	if _, err := newLocalFirewall(f.lookPath); err != nil {
+		t.Fatalf("newLocalFirewall() error: %v", err)
+	}
+	f.expect("setup with existing table", "nft list table inet runixo")
+
+	firewall.Block("203.0.113.7")
+	f.expect("Block(ipv4)", "nft add element inet runixo blocked4 { 203.0.113.7 }")
+	firewall.Block("2001:db8::/64")
+	f.expect("Block(ipv6 range)", "nft add element inet runixo blocked6 { 2001:db8::/64 }")
+	firewall.Unblock("203.0.113.0/24")
+	f.expect("Unblock", "nft add element inet runixo blocked4 { 203.0.113.0/24 }", "nft delete element inet runixo blocked4 { 203.0.113.0/24 }")
+}
+
+func TestIptablesFirewall(t *testing.T) {
+	f := newFakeCommands(t, "iptables", "ip6tables")
+	firewall, err := newLocalFirewall(f.lookPath)
+	if err != nil {
+		t.Fatalf("newLocalFirewall() error: %v", err)
+	}
+	if firewall.Name() != "iptables" {
+		t.Errorf("Name() = %q, want iptables", firewall.Name())
+	}
+	var setup []string
+	for _, command := range []string{"iptables", "ip6tables"} {
+		setup = append(setup,
+			command+" -w -n -L RUNIXO-BLOCK",
+			command+" -w -N RUNIXO-BLOCK",
+			command+" -w -C INPUT -j RUNIXO-BLOCK",
+			command+" -w -I INPUT -j RUNIXO-BLOCK",
+		)
+	}
+	f.expect("setup", setup...)
+
+	firewall.Block("203.0.113.7")
+	f.expect("Block(ipv4)", "iptables -w -C RUNIXO-BLOCK -s 203.0.113.7 -j DROP", "iptables -w -A RUNIXO-BLOCK -s 203.0.113.7 -j DROP")
+	firewall.Unblock("2001:db8::1")
+	f.expect("Unblock(not blocked)", "ip6tables -w -C RUNIXO-BLOCK -s 2001:db8::1 -j DROP")
+
+	f.setExists(true)
+	firewall.Block("2001:db8::1")
+	f.expect("Block(already blocked)", "ip6tables -w -C RUNIXO-BLOCK -s 2001:db8::1 -j DROP")
+	firewall.Unblock("2001:db8::1")
+	f.expect("Unblock", "ip6tables -w -C RUNIXO-BLOCK -s 2001:db8::1 -j DROP", "ip6tables -w -D RUNIXO-BLOCK -s 2001:db8::1 -j DROP")
+}
+
+func TestIptablesFirewallWithoutIPv6(t *testing.T) {
+	f := newFakeCommands(t, "iptables")
+	f.setExists(true)
+	firewall, err := newLocalFirewall(f.lookPath)
+	if err != nil {
+		t.Fatalf("newLocalFirewall() error: %v", err)
+	}
+	f.expect("setup", "iptables -w -n -L RUNIXO-BLOCK", "iptables -w -C INPUT -j RUNIXO-BLOCK")
+
+	if err := firewall.Block("2001:db8::1"); err == nil {
+		t.Error("Block(ipv6) without ip6tables succeeded")
+	}
+	f.expect("Block(ipv6)", "")
+
+	if _, err := newLocalFirewall(newFakeCommands(t).lookPath); err == nil {
+		t.Error("newLocalFirewall() without nft or iptables succeeded")
+	}
+}
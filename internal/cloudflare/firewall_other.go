@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package cloudflare
+
+import "errors"
+
+// newLocalFirewall 该平台不支持本机封禁
+func newLocalFirewall(lookPath LookPathFunc) (localFirewall, error) {
+	return nil, errors.New("当前平台不支持本机防火墙封禁")
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package cloudflare
+
+import "fmt"
+
+// newLocalFirewall 使用 Windows 防火墙
+func newLocalFirewall(lookPath LookPathFunc) (localFirewall, error) {
+	netsh, err := lookPath("netsh")
+	if err != nil {
+		return nil, err
+	}
+	if err := runFirewallCommand("", netsh, "advfirewall", "show", "currentprofile"); err != nil {
+		return nil, fmt.Errorf("无法访问 Windows 防火墙: %w", err)
+	}
+	return &windowsFirewall{netsh: netsh}, nil
+}
+
+// windowsFirewall 每个 IP 一条 Windows 防火墙入站阻止规则
+type windowsFirewall struct {
+	netsh string
+}
+
+func (f *windowsFirewall) Name() string { return "windows_firewall" }
+
+// ruleName IP 对应的规则名称参数
+func (f *windowsFirewall) ruleName(ip string) string {
+	return "name=Runixo Block " + ip
+}
+
+func (f *windowsFirewall) Block(ip string) error {
+	if runFirewallCommand("", f.netsh, "advfirewall", "firewall", "show", "rule", f.ruleName(ip)) == nil {
+		return nil
+	}
+	return runFirewallCommand("", f.netsh, "advfirewall", "firewall", "add", "rule", f.ruleName(ip),
+		"dir=in", "action=block", "remoteip="+ip)
+}
+
+func (f *windowsFirewall) Unblock(ip string) error {
+	if runFirewallCommand("", f.netsh, "advfirewall", "firewall", "show", "rule", f.ruleName(ip)) != nil {
+		return nil
+	}
+	return runFirewallCommand("", f.netsh, "advfirewall", "firewall", "delete", "rule", f.ruleName(ip))
+}
@@ -0,0 +1,521 @@
+// Package cloudflare 威胁情报：用外部信誉源补充本地检测，命中的 IP 增加威胁分数，达到阈值后按同样的流程封禁
+package cloudflare
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 威胁情报源类型
+const (
+	FeedAbuseIPDB    = "abuseipdb"     // AbuseIPDB 按 IP 查询信誉
+	FeedSpamhausDROP = "spamhaus_drop" // Spamhaus DROP 列表
+	FeedURL          = "url"           // 每行一个 IP 或 CIDR 网段的列表，# 或 ; 之后为注释
+)
+
+const (
+	// defaultSpamhausDROPURL Spamhaus DROP 列表地址
+	defaultSpamhausDROPURL = "https://www.spamhaus.org/drop/drop.txt"
+	// defaultAbuseIPDBURL AbuseIPDB 查询接口
+	defaultAbuseIPDBURL = "https://api.abuseipdb.com/api/v2/check"
+	// maxFeedSize 下载列表的大小上限
+	maxFeedSize = 16 << 20
+	// intelTimeout 没有指定 HTTP 客户端时请求情报源的超时
+	intelTimeout = 30 * time.Second
+)
+
+// FeedConfig 威胁情报源配置
+type FeedConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // abuseipdb、spamhaus_drop 或 url
+	// 列表地址，url 类型必填，其他类型可以覆盖默认地址
+	URL string `json:"url,omitempty"`
+	// AbuseIPDB API Key
+	APIKey string `json:"api_key,omitempty"`
+	// AbuseIPDB 置信度（0-100）达到该值时视为命中，默认 75
+	MinConfidence int `json:"min_confidence,omitempty"`
+	// 命中时增加的威胁分数，默认为封禁阈值，即命中即封禁
+	Score int `json:"score,omitempty"`
+	// 列表的刷新间隔或 AbuseIPDB 查询结果的缓存时长（分钟），默认列表 60、AbuseIPDB 1440
+	RefreshMinutes int `json:"refresh_minutes,omitempty"`
+	// AbuseIPDB 每天最多查询次数，默认 1000（免费套餐的额度）
+	DailyLimit int `json:"daily_limit,omitempty"`
+}
+
+// IntelConfig 威胁情报配置
+type IntelConfig struct {
+	Feeds []*FeedConfig `json:"feeds"`
+}
+
+// ValidateFeeds 校验威胁情报源配置
+func ValidateFeeds(feeds []*FeedConfig) error {
+	var fields []errcode.FieldViolation
+	names := make(map[string]bool)
+	for i, f := range feeds {
+		prefix := fmt.Sprintf("intel_feeds[%d].", i)
+		if f == nil {
+			fields = append(fields, errcode.FieldViolation{Field: fmt.Sprintf("intel_feeds[%d]", i), Description: "不能为空"})
+			continue
+		}
+		switch {
+		case f.Name == "":
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "name", Description: "不能为空"})
+		case names[f.Name]:
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "name", Description: fmt.Sprintf("名称 %q 重复", f.Name)})
+		}
+		names[f.Name] = true
+		switch f.Type {
+		case FeedAbuseIPDB:
+			if f.APIKey == "" {
+				fields = append(fields, errcode.FieldViolation{Field: prefix + "api_key", Description: "abuseipdb 需要 API Key"})
+			}
+		case FeedURL:
+			if f.URL == "" {
+				fields = append(fields, errcode.FieldViolation{Field: prefix + "url", Description: "url 类型需要列表地址"})
+			}
+		case FeedSpamhausDROP:
+		default:
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "type", Description: fmt.Sprintf("不支持的类型 %q，可选 abuseipdb、spamhaus_drop、url", f.Type)})
+		}
+		if f.URL != "" {
+			if u, err := url.Parse(f.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				fields = append(fields, errcode.FieldViolation{Field: prefix + "url", Description: "必须是 http 或 https 地址"})
+			}
+		}
+		if f.MinConfidence < 0 || f.MinConfidence > 100 {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "min_confidence", Description: "必须在 0 到 100 之间"})
+		}
+		if f.Score < 0 {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "score", Description: "不能为负数"})
+		}
+		if f.RefreshMinutes < 0 {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "refresh_minutes", Description: "不能为负数"})
+		}
+		if f.DailyLimit < 0 {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "daily_limit", Description: "不能为负数"})
+		}
+	}
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "威胁情报源配置无效", fields)
+	}
+	return nil
+}
+
+// url 情报源的地址
+func (f *FeedConfig) url() string {
+	switch {
+	case f.URL != "":
+		return f.URL
+	case f.Type == FeedAbuseIPDB:
+		return defaultAbuseIPDBURL
+	default:
+		return defaultSpamhausDROPURL
+	}
+}
+
+// refreshInterval 列表刷新间隔或查询结果缓存时长
+func (f *FeedConfig) refreshInterval() time.Duration {
+	switch {
+	case f.RefreshMinutes > 0:
+		return time.Duration(f.RefreshMinutes) * time.Minute
+	case f.Type == FeedAbuseIPDB:
+		return 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
+
+// Reputation IP 在威胁情报源中的命中信息
+type Reputation struct {
+	Feed   string `json:"feed"`
+	Score  int    `json:"score"`  // 增加的威胁分数
+	Detail string `json:"detail"` // 命中的网段或 AbuseIPDB 置信度
+}
+
+// FeedStatus 情报源状态
+type FeedStatus struct {
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	Entries      int        `json:"entries"` // 列表中的网段数或缓存的查询结果数
+	RefreshedAt  *time.Time `json:"refreshed_at,omitempty"`
+	LookupsToday int        `json:"lookups_today,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// feed 一个情报源的数据
+type feed struct {
+	config      *FeedConfig
+	score       int
+	networks    []*net.IPNet // 列表类型
+	refreshedAt time.Time
+	attemptedAt time.Time
+	lastErr     error
+	cache       map[string]*cachedReputation // AbuseIPDB 类型
+	pending     map[string]bool
+	lookupDay   string
+	lookups     int
+}
+
+// cachedReputation 缓存的 AbuseIPDB 查询结果，rep 为空表示未命中
+type cachedReputation struct {
+	rep     *Reputation
+	expires time.Time
+}
+
+// ThreatIntel 威胁情报，列表类型定期下载后在本地匹配，AbuseIPDB 在 IP 首次出现时异步查询
+type ThreatIntel struct {
+	client   *http.Client
+	feeds    []*feed
+	onResult func(ip string, rep *Reputation)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.RWMutex
+}
+
+// NewThreatIntel 创建威胁情报，defaultScore 为未指定分数的情报源命中时增加的分数，client 为空时使用默认客户端
+func NewThreatIntel(config *IntelConfig, client *http.Client, defaultScore int) *ThreatIntel {
+	if client == nil {
+		client = &http.Client{Timeout: intelTimeout}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ti := &ThreatIntel{client: client, ctx: ctx, cancel: cancel}
+	if config != nil {
+		for _, c := range config.Feeds {
+			f := &feed{
+				config:  c,
+				score:   c.Score,
+				cache:   make(map[string]*cachedReputation),
+				pending: make(map[string]bool),
+			}
+			if f.score == 0 {
+				f.score = defaultScore
+			}
+			ti.feeds = append(ti.feeds, f)
+		}
+	}
+	return ti
+}
+
+// SetCallback 设置异步查询命中时的回调
+func (ti *ThreatIntel) SetCallback(fn func(ip string, rep *Reputation)) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.onResult = fn
+}
+
+// Start 下载列表并开始定期刷新
+func (ti *ThreatIntel) Start() {
+	go ti.refreshLoop()
+}
+
+// Stop 停止刷新和查询
+func (ti *ThreatIntel) Stop() {
+	ti.cancel()
+}
+
+// refreshLoop 立即刷新一次列表，之后每分钟检查是否到了刷新时间
+func (ti *ThreatIntel) refreshLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		ti.refresh(false)
+		select {
+		case <-ti.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 立即重新下载所有列表
+func (ti *ThreatIntel) Refresh() error {
+	return ti.refresh(true)
+}
+
+// refresh 下载到了刷新时间的列表，force 时下载所有列表，返回最后一个错误
+func (ti *ThreatIntel) refresh(force bool) error {
+	var lastErr error
+	for _, f := range ti.feeds {
+		if f.config.Type == FeedAbuseIPDB {
+			continue
+		}
+		ti.mu.RLock()
+		due := force || time.Since(f.attemptedAt) >= f.config.refreshInterval()
+		ti.mu.RUnlock()
+		if !due {
+			continue
+		}
+
+		networks, err := ti.fetchList(f.config)
+
+		ti.mu.Lock()
+		f.attemptedAt = time.Now()
+		f.lastErr = err
+		if err == nil {
+			f.networks = networks
+			f.refreshedAt = f.attemptedAt
+		}
+		ti.mu.Unlock()
+
+		if err != nil {
+			log.Warn().Err(err).Str("feed", f.config.Name).Msg("下载威胁情报列表失败")
+			lastErr = err
+			continue
+		}
+		log.Info().Str("feed", f.config.Name).Int("entries", len(networks)).Msg("已刷新威胁情报列表")
+	}
+	return lastErr
+}
+
+// fetchList 下载并解析列表，无法解析的行被跳过
+func (ti *ThreatIntel) fetchList(config *FeedConfig) ([]*net.IPNet, error) {
+	req, err := http.NewRequestWithContext(ti.ctx, "GET", config.url(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ti.client.Do(req)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "请求威胁情报源失败")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errcode.New(errcode.UpstreamUnavailable, "威胁情报源返回 HTTP %d", resp.StatusCode)
+	}
+
+	var networks []*net.IPNet
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxFeedSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		for i, c := range line {
+			if c == '#' || c == ';' {
+				line = line[:i]
+				break
+			}
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, network, err := parseAllowValue(fields[0]); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "读取威胁情报列表失败")
+	}
+	return networks, nil
+}
+
+// Lookup 查询 IP 的信誉，返回分数最高的命中。AbuseIPDB 没有缓存结果时在后台查询，命中后调用回调
+func (ti *ThreatIntel) Lookup(ip string) *Reputation {
+	return ti.lookup(ip, false)
+}
+
+// Check 查询 IP 的信誉，AbuseIPDB 没有缓存结果时等待查询完成，不调用回调
+func (ti *ThreatIntel) Check(ip string) *Reputation {
+	return ti.lookup(ip, true)
+}
+
+// lookup 查询 IP 的信誉，wait 时同步查询 AbuseIPDB
+func (ti *ThreatIntel) lookup(ip string, wait bool) *Reputation {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil
+	}
+
+	var best *Reputation
+	consider := func(rep *Reputation) {
+		if rep != nil && (best == nil || rep.Score > best.Score) {
+			best = rep
+		}
+	}
+
+	// 先匹配本地列表，命中时不再消耗 AbuseIPDB 的查询额度
+	for _, f := range ti.feeds {
+		if f.config.Type != FeedAbuseIPDB {
+			consider(ti.matchList(f, parsed))
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	for _, f := range ti.feeds {
+		if f.config.Type != FeedAbuseIPDB {
+			continue
+		}
+		rep, cached, query := ti.cachedAbuseIPDB(f, ip)
+		switch {
+		case cached:
+			consider(rep)
+		case !query:
+		case wait:
+			consider(ti.queryAbuseIPDB(f, ip))
+		default:
+			go func(f *feed) {
+				if rep := ti.queryAbuseIPDB(f, ip); rep != nil {
+					ti.mu.RLock()
+					onResult := ti.onResult
+					ti.mu.RUnlock()
+					if onResult != nil {
+						onResult(ip, rep)
+					}
+				}
+			}(f)
+		}
+	}
+	return best
+}
+
+// matchList 在列表中查找包含 IP 的网段
+func (ti *ThreatIntel) matchList(f *feed, ip net.IP) *Reputation {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	for _, network := range f.networks {
+		if network.Contains(ip) {
+			return &Reputation{Feed: f.config.Name, Score: f.score, Detail: network.String()}
+		}
+	}
+	return nil
+}
+
+// cachedAbuseIPDB 返回缓存的查询结果；没有缓存时返回是否应该查询（未在查询中且未超过当天额度），并占用一次额度
+func (ti *ThreatIntel) cachedAbuseIPDB(f *feed, ip string) (*Reputation, bool, bool) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	if c, ok := f.cache[ip]; ok && time.Now().Before(c.expires) {
+		return c.rep, true, false
+	}
+	if f.pending[ip] {
+		return nil, false, false
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if f.lookupDay != today {
+		f.lookupDay, f.lookups = today, 0
+	}
+	limit := f.config.DailyLimit
+	if limit == 0 {
+		limit = 1000
+	}
+	if f.lookups >= limit {
+		return nil, false, false
+	}
+	f.lookups++
+	f.pending[ip] = true
+	return nil, false, true
+}
+
+// queryAbuseIPDB 查询 AbuseIPDB 并缓存结果，失败时不缓存
+func (ti *ThreatIntel) queryAbuseIPDB(f *feed, ip string) *Reputation {
+	rep, err := ti.fetchAbuseIPDB(f, ip)
+
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	delete(f.pending, ip)
+	f.lastErr = err
+	if err != nil {
+		log.Warn().Err(err).Str("feed", f.config.Name).Str("ip", ip).Msg("查询 AbuseIPDB 失败")
+		return nil
+	}
+
+	now := time.Now()
+	f.refreshedAt = now
+	f.cache[ip] = &cachedReputation{rep: rep, expires: now.Add(f.config.refreshInterval())}
+	for key, c := range f.cache {
+		if now.After(c.expires) {
+			delete(f.cache, key)
+		}
+	}
+	return rep
+}
+
+// fetchAbuseIPDB 请求 AbuseIPDB，置信度低于阈值时返回 nil
+func (ti *ThreatIntel) fetchAbuseIPDB(f *feed, ip string) (*Reputation, error) {
+	req, err := http.NewRequestWithContext(ti.ctx, "GET", f.config.url()+"?"+url.Values{
+		"ipAddress":    {ip},
+		"maxAgeInDays": {"90"},
+	}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Key", f.config.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ti.client.Do(req)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "请求 AbuseIPDB 失败")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, errcode.New(errcode.RateLimited, "AbuseIPDB 查询额度已用完")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errcode.New(errcode.UpstreamUnavailable, "AbuseIPDB 返回 HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			AbuseConfidenceScore int `json:"abuseConfidenceScore"`
+			TotalReports         int `json:"totalReports"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxFeedSize)).Decode(&result); err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "解析 AbuseIPDB 响应失败")
+	}
+
+	minConfidence := f.config.MinConfidence
+	if minConfidence == 0 {
+		minConfidence = 75
+	}
+	if result.Data.AbuseConfidenceScore < minConfidence {
+		return nil, nil
+	}
+	return &Reputation{
+		Feed:   f.config.Name,
+		Score:  f.score,
+		Detail: fmt.Sprintf("AbuseIPDB 置信度 %d（%d 次举报）", result.Data.AbuseConfidenceScore, result.Data.TotalReports),
+	}, nil
+}
+
+// Status 所有情报源的状态
+func (ti *ThreatIntel) Status() []*FeedStatus {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	result := make([]*FeedStatus, 0, len(ti.feeds))
+	for _, f := range ti.feeds {
+		status := &FeedStatus{Name: f.config.Name, Type: f.config.Type, Entries: len(f.networks)}
+		if f.config.Type == FeedAbuseIPDB {
+			status.Entries = len(f.cache)
+			if f.lookupDay == time.Now().Format("2006-01-02") {
+				status.LookupsToday = f.lookups
+			}
+		}
+		if !f.refreshedAt.IsZero() {
+			t := f.refreshedAt
+			status.RefreshedAt = &t
+		}
+		if f.lastErr != nil {
+			status.Error = f.lastErr.Error()
+		}
+		result = append(result, status)
+	}
+	return result
+}
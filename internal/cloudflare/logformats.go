@@ -0,0 +1,285 @@
+// Package cloudflare 日志格式：按日志来源选择检测模式，支持自定义正则格式
+package cloudflare
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 日志格式
+const (
+	FormatAuto         = ""              // 使用所有内置检测模式
+	FormatNginxAccess  = "nginx_access"  // nginx 访问日志（combined 格式）
+	FormatNginxError   = "nginx_error"   // nginx 错误日志
+	FormatApacheAccess = "apache_access" // Apache 访问日志（combined 格式）
+	FormatApacheError  = "apache_error"  // Apache 错误日志
+	FormatSSHD         = "sshd"          // sshd 认证日志（auth.log、secure）
+	FormatPostfix      = "postfix"       // Postfix 邮件日志
+	FormatDovecot      = "dovecot"       // Dovecot 邮件日志
+	FormatMail         = "mail"          // Postfix 和 Dovecot 共用的邮件日志（mail.log、maillog）
+	FormatCustom       = "custom"        // 只使用自定义检测模式
+)
+
+// knownFormats 支持的日志格式
+var knownFormats = map[string]bool{
+	FormatAuto: true, FormatNginxAccess: true, FormatNginxError: true, FormatApacheAccess: true,
+	FormatApacheError: true, FormatSSHD: true, FormatPostfix: true, FormatDovecot: true,
+	FormatMail: true, FormatCustom: true,
+}
+
+// formatAliases 包含其他格式的日志格式
+var formatAliases = map[string][]string{
+	FormatMail: {FormatPostfix, FormatDovecot},
+}
+
+// webAccessFormats Web 访问日志格式
+var webAccessFormats = []string{FormatNginxAccess, FormatApacheAccess}
+
+// LogSource 监控的日志及其格式
+type LogSource struct {
+	Path string `json:"path"`
+	// 日志格式，为空时使用所有内置检测模式
+	Format string `json:"format,omitempty"`
+	// 自定义检测模式，在内置模式之外使用；Format 为 custom 时只使用这些模式
+	Patterns []CustomPattern `json:"patterns,omitempty"`
+}
+
+// CustomPattern 自定义检测模式
+type CustomPattern struct {
+	Name string `json:"name"`
+	// 匹配日志行的正则表达式，必须包含名为 ip 的分组，如 `login failed from (?P<ip>\S+)`
+	Regex string `json:"regex"`
+	// 威胁类型，默认 brute_force
+	Type ThreatType `json:"type,omitempty"`
+	// 每次匹配的分数，默认 20
+	Score       int    `json:"score,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// threatTypes 自定义检测模式可以使用的威胁类型
+var threatTypes = map[ThreatType]bool{
+	ThreatTypeBruteForce: true, ThreatTypeScanning: true, ThreatTypeSQLInjection: true, ThreatTypeXSS: true,
+	ThreatTypePathTraversal: true, ThreatTypeBotAbuse: true, ThreatTypeDDoS: true, ThreatTypeReputation: true, ThreatTypeEdge: true, ThreatTypeUnknown: true,
+}
+
+// ValidateLogSources 校验日志来源的格式和自定义检测模式
+func ValidateLogSources(sources []LogSource) error {
+	var fields []errcode.FieldViolation
+	for i, source := range sources {
+		prefix := fmt.Sprintf("sources[%d].", i)
+		if source.Path == "" {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "path", Description: "不能为空"})
+		}
+		if !knownFormats[source.Format] {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "format", Description: fmt.Sprintf("不支持的日志格式 %q", source.Format)})
+		}
+		if source.Format == FormatCustom && len(source.Patterns) == 0 {
+			fields = append(fields, errcode.FieldViolation{Field: prefix + "patterns", Description: "custom 格式至少需要一个检测模式"})
+		}
+		for j, p := range source.Patterns {
+			field := fmt.Sprintf("%spatterns[%d].", prefix, j)
+			if re, err := regexp.Compile(p.Regex); err != nil {
+				fields = append(fields, errcode.FieldViolation{Field: field + "regex", Description: err.Error()})
+			} else if re.SubexpIndex("ip") < 0 {
+				fields = append(fields, errcode.FieldViolation{Field: field + "regex", Description: "必须包含名为 ip 的分组 (?P<ip>...)"})
+			}
+			if p.Type != "" && !threatTypes[p.Type] {
+				fields = append(fields, errcode.FieldViolation{Field: field + "type", Description: fmt.Sprintf("不支持的威胁类型 %q", p.Type)})
+			}
+			if p.Score < 0 {
+				fields = append(fields, errcode.FieldViolation{Field: field + "score", Description: "不能为负数"})
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "日志来源配置无效", fields)
+	}
+	return nil
+}
+
+// compilePatterns 编译自定义检测模式，调用前需要通过 ValidateLogSources 校验
+func (s *LogSource) compilePatterns() []DetectionPattern {
+	patterns := make([]DetectionPattern, 0, len(s.Patterns))
+	for _, p := range s.Patterns {
+		re := regexp.MustCompile(p.Regex)
+		pattern := DetectionPattern{
+			Name:        p.Name,
+			Type:        p.Type,
+			Pattern:     re,
+			Score:       p.Score,
+			Description: p.Description,
+			IPExtractor: extractIPGroup(re),
+		}
+		if pattern.Name == "" {
+			pattern.Name = "Custom"
+		}
+		if pattern.Type == "" {
+			pattern.Type = ThreatTypeBruteForce
+		}
+		if pattern.Score == 0 {
+			pattern.Score = 20
+		}
+		if pattern.Description == "" {
+			pattern.Description = pattern.Name
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// extractIPGroup 从 re 名为 ip 的分组中提取 IP，支持 IPv4 和 IPv6
+func extractIPGroup(re *regexp.Regexp) func(string) string {
+	index := re.SubexpIndex("ip")
+	return func(line string) string {
+		matches := re.FindStringSubmatch(line)
+		if index < 0 || index >= len(matches) {
+			return ""
+		}
+		ip := net.ParseIP(matches[index])
+		if ip == nil {
+			return ""
+		}
+		return ip.String()
+	}
+}
+
+// pattern 创建从 ip 分组提取 IP 的检测模式
+func pattern(name string, threatType ThreatType, expr string, score int, description string, formats ...string) DetectionPattern {
+	re := regexp.MustCompile(expr)
+	return DetectionPattern{
+		Name:        name,
+		Type:        threatType,
+		Pattern:     re,
+		Score:       score,
+		Description: description,
+		IPExtractor: extractIPGroup(re),
+		Formats:     formats,
+	}
+}
+
+const (
+	// ipGroup 匹配 IPv4 或 IPv6 地址的分组
+	ipGroup = `(?P<ip>[0-9a-fA-F:.]+)`
+	// ipv6Group 只匹配 IPv6 地址的分组，用于补充只识别 IPv4 的内置模式
+	ipv6Group = `(?P<ip>[0-9a-fA-F]*:[0-9a-fA-F:.]+)`
+)
+
+// formatPatterns Web 错误日志、sshd 和邮件服务的检测模式
+func formatPatterns() []DetectionPattern {
+	return []DetectionPattern{
+		// nginx 错误日志：HTTP 基本认证失败和被规则拒绝的访问
+		pattern("Nginx Auth User Not Found", ThreatTypeBruteForce,
+			`user ".*" was not found in ".*", client: `+ipGroup, 20, "nginx 基本认证用户不存在", FormatNginxError),
+		pattern("Nginx Auth Password Mismatch", ThreatTypeBruteForce,
+			`user ".*": password mismatch, client: `+ipGroup, 20, "nginx 基本认证密码错误", FormatNginxError),
+		pattern("Nginx Access Forbidden", ThreatTypeScanning,
+			`access forbidden by rule, client: `+ipGroup, 10, "访问被 nginx 规则拒绝", FormatNginxError),
+
+		// Apache 错误日志
+		pattern("Apache Auth User Not Found", ThreatTypeBruteForce,
+			`\[client `+ipGroup+`:\d+\] AH01618: user .* not found`, 20, "Apache 基本认证用户不存在", FormatApacheError),
+		pattern("Apache Auth Password Mismatch", ThreatTypeBruteForce,
+			`\[client `+ipGroup+`:\d+\] AH01617: user .*: authentication failure`, 20, "Apache 基本认证密码错误", FormatApacheError),
+		pattern("Apache Client Denied", ThreatTypeScanning,
+			`\[client `+ipGroup+`:\d+\] AH01630: client denied by server configuration`, 10, "访问被 Apache 配置拒绝", FormatApacheError),
+
+		// sshd：IPv6 来源和预认证阶段的失败
+		pattern("SSH Failed Password IPv6", ThreatTypeBruteForce,
+			`Failed password for .* from `+ipv6Group+` port \d+`, 20, "SSH 登录失败", FormatSSHD),
+		pattern("SSH Invalid User IPv6", ThreatTypeBruteForce,
+			`Invalid user .* from `+ipv6Group+` port \d+`, 25, "SSH 无效用户尝试", FormatSSHD),
+		pattern("SSH Max Auth Attempts", ThreatTypeBruteForce,
+			`maximum authentication attempts exceeded for .* from `+ipGroup, 50, "SSH 认证尝试次数超过上限", FormatSSHD),
+		pattern("SSH Preauth Disconnect", ThreatTypeBruteForce,
+			`Connection closed by (?:authenticating|invalid) user .* `+ipGroup+` port \d+ \[preauth\]`, 10, "SSH 认证前断开", FormatSSHD),
+		pattern("SSH No Identification", ThreatTypeScanning,
+			`Did not receive identification string from `+ipGroup, 10, "SSH 端口扫描", FormatSSHD),
+
+		// Postfix
+		pattern("Postfix SASL Failure", ThreatTypeBruteForce,
+			`warning: [\w.-]+\[`+ipGroup+`\]: SASL \S+ authentication failed`, 20, "SMTP 认证失败", FormatPostfix),
+		pattern("Postfix Auth Disconnect", ThreatTypeBruteForce,
+			`lost connection after AUTH from [\w.-]+\[`+ipGroup+`\]`, 10, "SMTP 认证中断开", FormatPostfix),
+		pattern("Postfix Relay Denied", ThreatTypeBotAbuse,
+			`NOQUEUE: reject: RCPT from [\w.-]+\[`+ipGroup+`\]: 554 .*Relay access denied`, 15, "尝试利用邮件中继", FormatPostfix),
+
+		// Dovecot
+		pattern("Dovecot Login Failed", ThreatTypeBruteForce,
+			`(?:imap|pop3|managesieve|submission)-login: .*\(auth failed, \d+ attempts.*rip=`+ipGroup, 20, "IMAP/POP3 登录失败", FormatDovecot),
+		pattern("Dovecot Auth Failure", ThreatTypeBruteForce,
+			`auth(?:-worker)?(?:\(\d+\))?: [\w-]+\([^,]*,`+ipGroup+`[,)].*(?:Password mismatch|unknown user|pam_authenticate\(\) failed)`, 15, "Dovecot 认证失败", FormatDovecot),
+	}
+}
+
+// appliesTo 模式是否适用于 format 格式的日志，FormatAuto 时所有内置模式都适用
+func (p *DetectionPattern) appliesTo(format string) bool {
+	if format == FormatAuto || len(p.Formats) == 0 {
+		return format != FormatCustom
+	}
+	formats := append([]string{format}, formatAliases[format]...)
+	for _, f := range p.Formats {
+		for _, want := range formats {
+			if f == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sourceConfig 一个日志来源的格式和自定义检测模式
+type sourceConfig struct {
+	format   string
+	patterns []DetectionPattern
+}
+
+// SetSource 设置日志来源的格式和自定义检测模式，调用前需要通过 ValidateLogSources 校验
+func (d *ThreatDetector) SetSource(source LogSource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sources[filepath.Clean(source.Path)] = &sourceConfig{
+		format:   source.Format,
+		patterns: source.compilePatterns(),
+	}
+}
+
+// RemoveSource 移除日志来源的格式设置，之后该来源使用所有内置检测模式
+func (d *ThreatDetector) RemoveSource(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.sources, filepath.Clean(path))
+}
+
+// Sources 已设置格式的日志来源及其格式
+func (d *ThreatDetector) Sources() map[string]string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result := make(map[string]string, len(d.sources))
+	for path, source := range d.sources {
+		result[path] = source.format
+	}
+	return result
+}
+
+// patternsFor 日志来源适用的检测模式（需要持有锁）
+func (d *ThreatDetector) patternsFor(source string) []DetectionPattern {
+	config, ok := d.sources[filepath.Clean(source)]
+	if !ok {
+		return d.patterns
+	}
+
+	patterns := make([]DetectionPattern, 0, len(d.patterns)+len(config.patterns))
+	for _, p := range d.patterns {
+		if p.appliesTo(config.format) {
+			patterns = append(patterns, p)
+		}
+	}
+	return append(patterns, config.patterns...)
+}
@@ -4,12 +4,15 @@ package cloudflare
 import (
 	"context"
 	"encoding/json"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
 )
 
 // SecurityManager 安全管理器
@@ -18,7 +21,15 @@ type SecurityManager struct {
 	watcher     *LogWatcher
 	detector    *ThreatDetector
 	blocker     *IPBlocker
+	zones       *ZoneRegistry
+	allowlist   *Allowlist
+	intel       *ThreatIntel
+	analytics   *AnalyticsIngester
+	sync        *BlockSync
+	httpClient  *http.Client // 请求威胁情报源和共享封禁列表的客户端
+	lookPath    LookPathFunc // 查找本机防火墙命令
 	ruleManager *RuleManager
+	countries   []*CountryRule // 最近一次同步后的国家/地区规则
 	config      *SecurityConfig
 	mu          sync.RWMutex
 	running     bool
@@ -37,6 +48,16 @@ type SecurityConfig struct {
 	Detector *DetectorConfig `json:"detector"`
 	// 封禁器配置
 	Blocker *BlockerConfig `json:"blocker"`
+	// 多域名配置
+	Zones *ZoneConfig `json:"zones"`
+	// 国家/地区规则，键为 zone 名称、ID、其下的主机名、AllZones 或 AccountScope
+	Countries map[string]*CountryRules `json:"countries"`
+	// 威胁情报配置，包含 API Key，不保存到文件
+	Intel *IntelConfig `json:"intel,omitempty"`
+	// 边缘事件配置，API Token 需要有 Analytics 读取权限
+	Analytics *AnalyticsConfig `json:"analytics,omitempty"`
+	// 共享封禁列表配置，包含签名密钥，不保存到文件
+	Sync *SyncConfig `json:"sync,omitempty"`
 	// 数据存储路径
 	DataPath string `json:"data_path"`
 }
@@ -69,6 +90,8 @@ func DefaultSecurityConfig() *SecurityConfig {
 		Watcher:    DefaultWatcherConfig(),
 		Detector:   DefaultDetectorConfig(),
 		Blocker:    DefaultBlockerConfig(),
+		Zones:      DefaultZoneConfig(),
+		Countries:  map[string]*CountryRules{},
 		DataPath:   "/var/lib/runixo/cloudflare",
 	}
 }
@@ -106,6 +129,7 @@ func (sm *SecurityManager) Configure(apiToken, accountID string) error {
 
 	// 创建客户端
 	sm.client = NewClient(sm.config.Cloudflare)
+	sm.zones = sm.newZoneRegistry()
 
 	// 验证 Token
 	valid, err := sm.client.VerifyToken()
@@ -132,16 +156,64 @@ func (sm *SecurityManager) Start() error {
 		return nil
 	}
 
+	var sources []LogSource
+	if sm.config.Watcher != nil {
+		sources = sm.config.Watcher.Sources
+	}
+	if err := ValidateLogSources(sources); err != nil {
+		return err
+	}
+	if sm.config.Intel != nil {
+		if err := ValidateFeeds(sm.config.Intel.Feeds); err != nil {
+			return err
+		}
+	}
+	if sm.config.Blocker != nil {
+		if err := ValidateActionBands(sm.config.Blocker.ActionBands); err != nil {
+			return err
+		}
+	}
+	if sm.config.Analytics != nil {
+		if err := sm.config.Analytics.Validate(); err != nil {
+			return err
+		}
+	}
+	if sm.config.Sync != nil {
+		if err := sm.config.Sync.Validate(); err != nil {
+			return err
+		}
+	}
+
 	if sm.client == nil {
 		if sm.config.Cloudflare == nil || sm.config.Cloudflare.APIToken == "" {
 			return &ConfigError{Message: "Cloudflare 未配置"}
 		}
 		sm.client = NewClient(sm.config.Cloudflare)
+		sm.zones = sm.newZoneRegistry()
+	}
+
+	// 发现 API Token 可访问的域名，失败时在首次封禁时重试
+	if zones, err := sm.zones.Zones(); err != nil {
+		log.Warn().Err(err).Msg("获取 Cloudflare 域名列表失败")
+	} else {
+		log.Info().Int("zones", len(zones)).Msg("已发现 Cloudflare 域名")
 	}
 
 	// 初始化各模块
+	sm.allowlist = NewAllowlist(sm.config.DataPath)
 	sm.detector = NewThreatDetector(sm.config.Detector)
-	sm.blocker = NewIPBlocker(sm.client, sm.config.Blocker)
+	sm.detector.SetAllowlist(sm.allowlist)
+	for _, source := range sources {
+		sm.detector.SetSource(source)
+	}
+	sm.blocker = NewIPBlocker(sm.client, sm.zones, sm.config.Blocker)
+	sm.blocker.SetAllowlist(sm.allowlist)
+	if sm.lookPath != nil {
+		sm.blocker.SetLookPath(sm.lookPath)
+	}
+	sm.startIntelLocked()
+	sm.startAnalyticsLocked()
+	sm.startSyncLocked()
 	sm.ruleManager = NewRuleManager(sm.config.DataPath)
 
 	// 创建日志监控器
@@ -156,6 +228,17 @@ func (sm *SecurityManager) Start() error {
 		return err
 	}
 
+	// 应用国家/地区规则，失败时不影响其他防护
+	sm.syncCountryRulesLocked()
+
+	// 与 Cloudflare 核对封禁记录并恢复本机封禁，过期的封禁由封禁器在启动时和之后每分钟清除
+	go func(blocker *IPBlocker) {
+		if _, err := blocker.Reconcile(); err != nil {
+			log.Warn().Err(err).Msg("核对封禁记录失败")
+		}
+		blocker.restoreLocalBlocks()
+	}(sm.blocker)
+
 	// 启动事件处理
 	go sm.processEvents()
 
@@ -182,12 +265,199 @@ func (sm *SecurityManager) Stop() {
 		sm.blocker.Stop()
 	}
 
+	if sm.intel != nil {
+		sm.intel.Stop()
+		sm.intel = nil
+	}
+
+	if sm.analytics != nil {
+		sm.analytics.Stop()
+		sm.analytics = nil
+	}
+
+	if sm.sync != nil {
+		sm.sync.Stop()
+		sm.sync = nil
+	}
+
 	sm.cancel()
 	sm.running = false
 
 	log.Info().Msg("安全管理器已停止")
 }
 
+// SetHTTPClient 设置请求威胁情报源和共享封禁列表的 HTTP 客户端，插件传入检查网络权限的客户端，下次启动时生效
+func (sm *SecurityManager) SetHTTPClient(client *http.Client) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.httpClient = client
+}
+
+// SetLookPath 设置查找本机防火墙命令的函数，插件传入检查 exec 权限的实现，下次启动时生效
+func (sm *SecurityManager) SetLookPath(lookPath LookPathFunc) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.lookPath = lookPath
+}
+
+// startIntelLocked 配置了威胁情报源时启动威胁情报（需要持有锁），异步查询命中且分数达到阈值的 IP 交给封禁器
+func (sm *SecurityManager) startIntelLocked() {
+	if sm.config.Intel == nil || len(sm.config.Intel.Feeds) == 0 {
+		return
+	}
+
+	detector, blocker := sm.detector, sm.blocker
+	sm.intel = NewThreatIntel(sm.config.Intel, sm.httpClient, detector.config.BlockThreshold)
+	sm.intel.SetCallback(func(ip string, rep *Reputation) {
+		if threat := detector.ApplyReputation(ip, rep); threat != nil {
+			log.Warn().Str("ip", ip).Str("feed", rep.Feed).Int("score", threat.Score).Msg("威胁情报命中")
+			blocker.BlockThreat(threat)
+		}
+	})
+	sm.detector.SetIntel(sm.intel)
+	sm.intel.Start()
+
+	log.Info().Int("feeds", len(sm.config.Intel.Feeds)).Msg("已启用威胁情报")
+}
+
+// startAnalyticsLocked 启用了边缘事件时开始定期拉取（需要持有锁）
+func (sm *SecurityManager) startAnalyticsLocked() {
+	if sm.config.Analytics == nil || !sm.config.Analytics.Enabled {
+		return
+	}
+
+	sm.analytics = NewAnalyticsIngester(sm.client, sm.zones, sm.detector, sm.blocker, sm.config.Analytics)
+	sm.analytics.Start()
+
+	log.Info().Dur("interval", sm.config.Analytics.interval()).Msg("已启用 Cloudflare 边缘事件")
+}
+
+// GetAnalyticsStatus 获取各域名的边缘事件拉取状态
+func (sm *SecurityManager) GetAnalyticsStatus() ([]*AnalyticsStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.analytics == nil {
+		return nil, &ConfigError{Message: "未启用边缘事件"}
+	}
+
+	return sm.analytics.Status(), nil
+}
+
+// PollAnalytics 立即拉取边缘事件
+func (sm *SecurityManager) PollAnalytics() ([]*AnalyticsStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.analytics == nil {
+		return nil, &ConfigError{Message: "未启用边缘事件"}
+	}
+
+	err := sm.analytics.Poll()
+	if err != nil {
+		err = errcode.Wrap(errcode.UpstreamUnavailable, err, "拉取边缘事件失败")
+	}
+	return sm.analytics.Status(), err
+}
+
+// startSyncLocked 启用了共享封禁列表时开始定期同步（需要持有锁）
+func (sm *SecurityManager) startSyncLocked() {
+	if sm.config.Sync == nil || !sm.config.Sync.Enabled {
+		return
+	}
+
+	sm.sync = NewBlockSync(sm.config.Sync, sm.httpClient, sm.blocker)
+	sm.sync.Start()
+
+	log.Info().Str("node", sm.sync.node).Int("peers", len(sm.config.Sync.PeerURLs)).Msg("已启用共享封禁列表")
+}
+
+// GetSyncStatus 获取共享封禁列表的同步状态
+func (sm *SecurityManager) GetSyncStatus() (*SyncStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.sync == nil {
+		return nil, &ConfigError{Message: "未启用共享封禁列表"}
+	}
+
+	return sm.sync.Status(), nil
+}
+
+// SyncBlocks 立即发布本节点的封禁列表并应用其他节点的列表
+func (sm *SecurityManager) SyncBlocks() (*SyncStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.sync == nil {
+		return nil, &ConfigError{Message: "未启用共享封禁列表"}
+	}
+
+	err := sm.sync.Sync()
+	if err != nil {
+		err = errcode.Wrap(errcode.UpstreamUnavailable, err, "同步共享封禁列表失败")
+	}
+	return sm.sync.Status(), err
+}
+
+// ExportBlockList 本节点签名后的封禁列表，供控制面转发给其他节点
+func (sm *SecurityManager) ExportBlockList() (*SignedBlockList, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.sync == nil {
+		return nil, &ConfigError{Message: "未启用共享封禁列表"}
+	}
+
+	return sm.sync.Export()
+}
+
+// ImportBlockList 应用控制面转发的其他节点的封禁列表
+func (sm *SecurityManager) ImportBlockList(signed *SignedBlockList) (*SyncPeerStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.sync == nil {
+		return nil, &ConfigError{Message: "未启用共享封禁列表"}
+	}
+
+	return sm.sync.Import(signed)
+}
+
+// GetIntelStatus 获取威胁情报源的状态
+func (sm *SecurityManager) GetIntelStatus() ([]*FeedStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.intel == nil {
+		return nil, &ConfigError{Message: "未配置威胁情报源"}
+	}
+	return sm.intel.Status(), nil
+}
+
+// RefreshIntel 立即重新下载威胁情报列表
+func (sm *SecurityManager) RefreshIntel() ([]*FeedStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.intel == nil {
+		return nil, &ConfigError{Message: "未配置威胁情报源"}
+	}
+	err := sm.intel.Refresh()
+	return sm.intel.Status(), err
+}
+
+// CheckIntel 查询 IP 在威胁情报源中的信誉，未命中时返回 nil
+func (sm *SecurityManager) CheckIntel(ip string) (*Reputation, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.intel == nil {
+		return nil, &ConfigError{Message: "未配置威胁情报源"}
+	}
+	return sm.intel.Check(ip), nil
+}
+
 // GetStatus 获取安全状态
 func (sm *SecurityManager) GetStatus() *SecurityStatus {
 	sm.mu.RLock()
@@ -238,8 +508,8 @@ func (sm *SecurityManager) GetBlockedIPs() []*BlockedIP {
 	return sm.blocker.GetBlockedIPs()
 }
 
-// BlockIP 手动封禁 IP
-func (sm *SecurityManager) BlockIP(ip, zoneID, reason string, duration int) (*BlockedIP, error) {
+// BlockIP 手动封禁 IP，zone 为 zone ID、名称、其下的主机名、AccountScope 或 BlockScopeLocal，为空时按配置在账户级别或所有启用的域名上封禁
+func (sm *SecurityManager) BlockIP(ip, zone, reason string, duration int) ([]*BlockedIP, error) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -247,11 +517,11 @@ func (sm *SecurityManager) BlockIP(ip, zoneID, reason string, duration int) (*Bl
 		return nil, &ConfigError{Message: "封禁器未初始化"}
 	}
 
-	return sm.blocker.ManualBlock(ip, zoneID, reason, duration)
+	return sm.blocker.ManualBlock(ip, zone, reason, duration)
 }
 
-// UnblockIP 解封 IP
-func (sm *SecurityManager) UnblockIP(ip, zoneID string) error {
+// UnblockIP 解封 IP，zone 为空时解除该 IP 的所有封禁
+func (sm *SecurityManager) UnblockIP(ip, zone string) error {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -259,7 +529,104 @@ func (sm *SecurityManager) UnblockIP(ip, zoneID string) error {
 		return &ConfigError{Message: "封禁器未初始化"}
 	}
 
-	return sm.blocker.Unblock(ip, zoneID)
+	return sm.blocker.Unblock(ip, zone)
+}
+
+// GetAllowlist 获取白名单
+func (sm *SecurityManager) GetAllowlist() ([]*AllowEntry, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.allowlist == nil {
+		return nil, &ConfigError{Message: "白名单未初始化"}
+	}
+
+	return sm.allowlist.Entries(), nil
+}
+
+// AllowIP 把 IP 或 CIDR 网段加入白名单，并解除其中已封禁的 IP、清除其活动记录
+func (sm *SecurityManager) AllowIP(value, note string) (*AllowEntry, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.allowlist == nil {
+		return nil, &ConfigError{Message: "白名单未初始化"}
+	}
+
+	entry, err := sm.allowlist.Add(value, note)
+	if err != nil {
+		return nil, err
+	}
+
+	unblocked := make(map[string]bool)
+	for _, blocked := range sm.blocker.GetBlockedIPs() {
+		if unblocked[blocked.IP] {
+			continue
+		}
+		if _, network, err := parseAllowValue(blocked.IP); err != nil || !networksOverlap(entry.network, network) {
+			continue
+		}
+		unblocked[blocked.IP] = true
+		if err := sm.blocker.Unblock(blocked.IP, ""); err != nil {
+			log.Error().Err(err).Str("ip", blocked.IP).Msg("解封白名单中的 IP 失败")
+		}
+	}
+	for _, activity := range sm.detector.GetAllActivities() {
+		if entry.network.Contains(net.ParseIP(activity.IP)) {
+			sm.detector.ResetIP(activity.IP)
+		}
+	}
+
+	return entry, nil
+}
+
+// DisallowIP 从白名单移除 IP 或 CIDR 网段
+func (sm *SecurityManager) DisallowIP(value string) error {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.allowlist == nil {
+		return &ConfigError{Message: "白名单未初始化"}
+	}
+
+	return sm.allowlist.Remove(value)
+}
+
+// ReconcileBlocks 与 Cloudflare 核对封禁记录，返回移除的记录数
+func (sm *SecurityManager) ReconcileBlocks() (int, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.blocker == nil {
+		return 0, &ConfigError{Message: "封禁器未初始化"}
+	}
+
+	return sm.blocker.Reconcile()
+}
+
+// GetDryRunReport 演练模式下将被处理的 IP
+func (sm *SecurityManager) GetDryRunReport() (*DryRunReport, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.blocker == nil {
+		return nil, &ConfigError{Message: "封禁器未初始化"}
+	}
+
+	return sm.blocker.DryRunReport(), nil
+}
+
+// ResetDryRunReport 清空演练报告
+func (sm *SecurityManager) ResetDryRunReport() error {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.blocker == nil {
+		return &ConfigError{Message: "封禁器未初始化"}
+	}
+
+	sm.blocker.ResetDryRunReport()
+	return nil
 }
 
 // GetThreats 获取威胁列表
@@ -358,16 +725,96 @@ func (sm *SecurityManager) DeleteRule(id string) error {
 	return sm.ruleManager.DeleteRule(id)
 }
 
-// GetZones 获取域名列表
-func (sm *SecurityManager) GetZones() ([]Zone, error) {
+// GetZones 获取 API Token 可访问的域名及其是否启用自动封禁
+func (sm *SecurityManager) GetZones() ([]*ZoneStatus, error) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	if sm.client == nil {
+	if sm.zones == nil {
+		return nil, &ConfigError{Message: "Cloudflare 未配置"}
+	}
+
+	return sm.zones.Zones()
+}
+
+// RefreshZones 立即重新列出域名，用于在 Cloudflare 上新增域名后
+func (sm *SecurityManager) RefreshZones() ([]*ZoneStatus, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.zones == nil {
 		return nil, &ConfigError{Message: "Cloudflare 未配置"}
 	}
 
-	return sm.client.ListZones()
+	if err := sm.zones.Refresh(); err != nil {
+		return nil, err
+	}
+	return sm.zones.Zones()
+}
+
+// SetZoneEnabled 启用或停用域名的自动封禁并保存配置，zone 为 zone ID、名称或其下的主机名
+func (sm *SecurityManager) SetZoneEnabled(zone string, enabled bool) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.zones == nil {
+		return &ConfigError{Message: "Cloudflare 未配置"}
+	}
+
+	z, err := sm.zones.SetEnabled(zone, enabled)
+	if err != nil {
+		return err
+	}
+
+	sm.saveConfig()
+
+	log.Info().Str("zone", z.Name).Bool("enabled", enabled).Msg("已更新域名自动封禁")
+	return nil
+}
+
+// SyncCountryRules 立即同步国家/地区规则，用于在 Cloudflare 上新增域名或手动修改规则后
+func (sm *SecurityManager) SyncCountryRules() ([]*CountryRule, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !sm.running {
+		return nil, &ConfigError{Message: "安全管理器未运行"}
+	}
+
+	err := sm.syncCountryRulesLocked()
+	return sm.countries, err
+}
+
+// GetCountryRules 获取最近一次同步后已应用的国家/地区规则
+func (sm *SecurityManager) GetCountryRules() []*CountryRule {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.countries
+}
+
+// syncCountryRulesLocked 同步国家/地区规则（需要持有锁），部分范围失败时仍记录其余已应用的规则
+func (sm *SecurityManager) syncCountryRulesLocked() error {
+	applied, err := syncCountryRules(sm.client, sm.zones, sm.config.Countries)
+	if err != nil {
+		log.Error().Err(err).Msg("同步国家/地区规则失败")
+	}
+	if applied != nil || err == nil {
+		sm.countries = applied
+	}
+	log.Info().Int("rules", len(applied)).Msg("已同步国家/地区规则")
+	return err
+}
+
+// newZoneRegistry 为当前客户端创建域名注册表（需要持有锁）
+func (sm *SecurityManager) newZoneRegistry() *ZoneRegistry {
+	if sm.config.Zones == nil {
+		sm.config.Zones = DefaultZoneConfig()
+	}
+	var protected []string
+	if sm.config.Blocker != nil {
+		protected = sm.config.Blocker.ProtectedZones
+	}
+	return NewZoneRegistry(sm.client, sm.config.Cloudflare.AccountID, sm.config.Zones, protected)
 }
 
 // EnableUnderAttackMode 启用 Under Attack 模式
@@ -406,6 +853,23 @@ func (sm *SecurityManager) AddMonitorPath(path string) error {
 	return sm.watcher.AddPath(path)
 }
 
+// AddMonitorSource 添加监控路径并设置其日志格式和自定义检测模式
+func (sm *SecurityManager) AddMonitorSource(source LogSource) error {
+	if err := ValidateLogSources([]LogSource{source}); err != nil {
+		return err
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.watcher == nil {
+		return &ConfigError{Message: "监控器未初始化"}
+	}
+
+	sm.detector.SetSource(source)
+	return sm.watcher.AddPath(source.Path)
+}
+
 // RemoveMonitorPath 移除监控路径
 func (sm *SecurityManager) RemoveMonitorPath(path string) {
 	sm.mu.RLock()
@@ -414,6 +878,9 @@ func (sm *SecurityManager) RemoveMonitorPath(path string) {
 	if sm.watcher != nil {
 		sm.watcher.RemovePath(path)
 	}
+	if sm.detector != nil {
+		sm.detector.RemoveSource(path)
+	}
 }
 
 // Events 返回事件通道
@@ -489,6 +956,16 @@ func (sm *SecurityManager) loadConfig() {
 	if config.Blocker != nil {
 		sm.config.Blocker = config.Blocker
 	}
+	// 调用方提供的多域名配置优先，运行中启用或停用域名后保存的配置在调用方未提供时使用
+	if config.Zones != nil && sm.config.Zones == nil {
+		sm.config.Zones = config.Zones
+	}
+	if config.Countries != nil && sm.config.Countries == nil {
+		sm.config.Countries = config.Countries
+	}
+	if config.Analytics != nil && sm.config.Analytics == nil {
+		sm.config.Analytics = config.Analytics
+	}
 
 	log.Info().Msg("已加载安全配置")
 }
@@ -511,7 +988,10 @@ func (sm *SecurityManager) saveConfig() {
 		Watcher:  sm.config.Watcher,
 		Detector: sm.config.Detector,
 		Blocker:  sm.config.Blocker,
-		DataPath: sm.config.DataPath,
+		Zones:     sm.config.Zones,
+		Countries: sm.config.Countries,
+		Analytics: sm.config.Analytics,
+		DataPath:  sm.config.DataPath,
 	}
 
 	data, err := json.MarshalIndent(configToSave, "", "  ")
@@ -530,6 +1010,35 @@ func (sm *SecurityManager) UpdateConfig(config *SecurityConfig) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	if err := ValidateCountryRules(config.Countries); err != nil {
+		return err
+	}
+	if config.Intel != nil {
+		if err := ValidateFeeds(config.Intel.Feeds); err != nil {
+			return err
+		}
+	}
+	if config.Watcher != nil {
+		if err := ValidateLogSources(config.Watcher.Sources); err != nil {
+			return err
+		}
+	}
+	if config.Blocker != nil {
+		if err := ValidateActionBands(config.Blocker.ActionBands); err != nil {
+			return err
+		}
+	}
+	if config.Analytics != nil {
+		if err := config.Analytics.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Sync != nil {
+		if err := config.Sync.Validate(); err != nil {
+			return err
+		}
+	}
+
 	if config.Watcher != nil {
 		sm.config.Watcher = config.Watcher
 	}
@@ -539,6 +1048,28 @@ func (sm *SecurityManager) UpdateConfig(config *SecurityConfig) error {
 	if config.Blocker != nil {
 		sm.config.Blocker = config.Blocker
 	}
+	if config.Zones != nil {
+		sm.config.Zones = config.Zones
+		if sm.zones != nil {
+			sm.zones.SetConfig(config.Zones)
+		}
+	}
+	if config.Countries != nil {
+		sm.config.Countries = config.Countries
+		if sm.running {
+			sm.syncCountryRulesLocked()
+		}
+	}
+	// 威胁情报源、边缘事件和共享封禁列表在下次启动时生效
+	if config.Intel != nil {
+		sm.config.Intel = config.Intel
+	}
+	if config.Analytics != nil {
+		sm.config.Analytics = config.Analytics
+	}
+	if config.Sync != nil {
+		sm.config.Sync = config.Sync
+	}
 
 	sm.saveConfig()
 	return nil
@@ -557,7 +1088,10 @@ func (sm *SecurityManager) GetConfig() *SecurityConfig {
 		Watcher:  sm.config.Watcher,
 		Detector: sm.config.Detector,
 		Blocker:  sm.config.Blocker,
-		DataPath: sm.config.DataPath,
+		Zones:     sm.config.Zones,
+		Countries: sm.config.Countries,
+		Analytics: sm.config.Analytics,
+		DataPath:  sm.config.DataPath,
 	}
 }
 
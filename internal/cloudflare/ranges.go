@@ -0,0 +1,181 @@
+// Package cloudflare 网段封禁：Cloudflare 访问规则支持的网段，以及把同一网段的多个单 IP 封禁合并为一条网段规则
+package cloudflare
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 合并单 IP 封禁时使用的网段长度
+const (
+	collapseBitsIPv4 = 24
+	collapseBitsIPv6 = 64
+)
+
+// rangePrefixes Cloudflare 访问规则支持的网段长度，键为地址位数
+var rangePrefixes = map[int]map[int]bool{
+	32:  {16: true, 24: true},
+	128: {32: true, 48: true, 64: true},
+}
+
+// parseBlockValue 把 IP 或 CIDR 转为规范形式和对应的网段，单个 IP 视为 /32 或 /128；
+// 网段只能是 Cloudflare 访问规则支持的 IPv4 /16、/24 和 IPv6 /32、/48、/64
+func parseBlockValue(value string) (string, *net.IPNet, error) {
+	key, network, err := parseAllowValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	ones, bits := network.Mask.Size()
+	if ones != bits && !rangePrefixes[bits][ones] {
+		return "", nil, errcode.New(errcode.InvalidArgument, "不支持的网段 %s，只能封禁 IPv4 /16、/24 和 IPv6 /32、/48、/64 网段", value)
+	}
+	return key, network, nil
+}
+
+// accessRuleTarget 访问规则的目标类型（ip、ip6 或 ip_range）和规范形式的值
+func accessRuleTarget(value string) (string, string, error) {
+	key, network, err := parseBlockValue(value)
+	if err != nil {
+		return "", "", err
+	}
+	ones, bits := network.Mask.Size()
+	switch {
+	case ones != bits:
+		return "ip_range", key, nil
+	case bits == 128:
+		return "ip6", key, nil
+	default:
+		return "ip", key, nil
+	}
+}
+
+// networksOverlap 两个网段是否有重叠
+func networksOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// collapsePrefix 单个 IP 所在的待合并网段，IPv4 为 /24，IPv6 为 /64，不是单个 IP 时为空
+func collapsePrefix(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if ip4 := parsed.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(collapseBitsIPv4, 32)), Mask: net.CIDRMask(collapseBitsIPv4, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(collapseBitsIPv6, 128)), Mask: net.CIDRMask(collapseBitsIPv6, 128)}).String()
+}
+
+// covers 封禁记录是否覆盖 ip：记录为该 IP 或包含它的网段
+func (b *BlockedIP) covers(ip net.IP) bool {
+	_, network, err := parseAllowValue(b.IP)
+	return err == nil && network.Contains(ip)
+}
+
+// collapse target 上同一网段中自动封禁的单个 IP 达到 CollapseThreshold 时改为封禁整个网段：
+// 创建网段规则后删除这些 IP 的规则，避免超出 Cloudflare 访问规则的数量限制。手动封禁不参与合并
+func (b *IPBlocker) collapse(target blockTarget, ip string) {
+	threshold := b.config.CollapseThreshold
+	if threshold < 2 {
+		return
+	}
+	prefix := collapsePrefix(ip)
+	if prefix == "" || b.isWhitelisted(prefix) {
+		return
+	}
+	_, network, _ := net.ParseCIDR(prefix)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.blockedIPs[target.key(prefix)]; exists {
+		return
+	}
+
+	var members []string
+	for key, blocked := range b.blockedIPs {
+		if key != target.key(blocked.IP) || !blocked.AutoBlocked {
+			continue
+		}
+		if parsed := net.ParseIP(blocked.IP); parsed != nil && network.Contains(parsed) {
+			members = append(members, key)
+		}
+	}
+	if len(members) < threshold {
+		return
+	}
+
+	// 网段规则的动作：有 IP 被封禁时封禁，否则沿用质询；过期时间取最晚的一个，有永久封禁时为永久
+	var mode string
+	var threatType ThreatType
+	var score int
+	var expiresAt *time.Time
+	permanent := false
+	for _, key := range members {
+		blocked := b.blockedIPs[key]
+		if mode != "block" && blocked.Mode != "" {
+			mode = blocked.Mode
+		}
+		if blocked.Score >= score {
+			score, threatType = blocked.Score, blocked.ThreatType
+		}
+		switch {
+		case blocked.ExpiresAt == nil:
+			permanent = true
+		case expiresAt == nil || blocked.ExpiresAt.After(*expiresAt):
+			expiresAt = blocked.ExpiresAt
+		}
+	}
+	if mode == "" {
+		mode = b.config.modeForScore(score)
+	}
+	if permanent {
+		expiresAt = nil
+	}
+
+	reason := fmt.Sprintf("Auto-blocked by Runixo: %d blocked IPs in %s", len(members), prefix)
+	ruleID, mode, err := b.createRule(target, prefix, mode, reason)
+	if err != nil {
+		log.Error().Err(err).Str("range", prefix).Str("zone", target.name()).Msg("合并封禁网段失败")
+		return
+	}
+
+	record := newBlockedIP(target, prefix, ruleID, mode, reason)
+	record.ThreatType = threatType
+	record.Score = score
+	record.ExpiresAt = expiresAt
+	record.AutoBlocked = true
+	b.blockedIPs[target.key(prefix)] = record
+
+	// 删除被网段覆盖的单 IP 规则，失败的保留记录，过期时再删除
+	for _, key := range members {
+		blocked := b.blockedIPs[key]
+		if err := b.deleteRule(blocked); err != nil {
+			log.Warn().Err(err).Str("ip", blocked.IP).Str("zone", target.name()).Msg("删除已合并的封禁规则失败")
+			continue
+		}
+		delete(b.blockedIPs, key)
+	}
+
+	b.saveBlockedIPs()
+
+	b.sendEvent(&BlockEvent{
+		Type:      "collapsed",
+		IP:        prefix,
+		ZoneID:    record.ZoneID,
+		Reason:    reason,
+		Timestamp: time.Now(),
+		BlockedIP: record,
+	})
+
+	log.Info().
+		Str("range", prefix).
+		Str("zone", target.name()).
+		Str("rule_id", ruleID).
+		Int("ips", len(members)).
+		Msg("已将同一网段的封禁合并为网段规则")
+}
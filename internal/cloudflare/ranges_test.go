@@ -0,0 +1,202 @@
+package cloudflare
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// fakeAPI 模拟 Cloudflare 访问规则接口，记录现有的规则
+type fakeAPI struct {
+	mu     sync.Mutex
+	nextID int
+	rules  map[string]string // 规则 ID 到封禁的值
+}
+
+func (f *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result any
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/access_rules/rules"):
+		var body struct {
+			Mode          string           `json:"mode"`
+			Configuration AccessRuleConfig `json:"configuration"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		f.nextID++
+		id := fmt.Sprintf("rule-%d", f.nextID)
+		f.rules[id] = body.Configuration.Value
+		result = AccessRule{ID: id, Mode: body.Mode, Configuration: body.Configuration}
+	case req.Method == http.MethodDelete:
+		id := path.Base(req.URL.Path)
+		delete(f.rules, id)
+		result = map[string]string{"id": id}
+	default:
+		return jsonResponse(http.StatusNotFound, map[string]any{"success": false, "errors": []APIError{{Code: 7003, Message: "not found"}}}), nil
+	}
+	return jsonResponse(http.StatusOK, map[string]any{"success": true, "result": result}), nil
+}
+
+// values 现有规则封禁的值，已排序
+func (f *fakeAPI) values() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := make([]string, 0, len(f.rules))
+	for _, value := range f.rules {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+func jsonResponse(status int, body any) *http.Response {
+	data, _ := json.Marshal(body)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}
+}
+
+// newTestBlocker 在账户级别封禁的封禁器，访问规则请求由 fakeAPI 处理
+func newTestBlocker(t *testing.T, config *BlockerConfig) (*IPBlocker, *fakeAPI) {
+	t.Helper()
+	api := &fakeAPI{rules: make(map[string]string)}
+	client := &Client{apiToken: "test", accountID: "acct", httpClient: &http.Client{Transport: api}}
+	config.DataPath = t.TempDir()
+	zones := NewZoneRegistry(client, "acct", &ZoneConfig{AccountWide: true}, nil)
+	blocker := NewIPBlocker(client, zones, config)
+	t.Cleanup(blocker.Stop)
+	return blocker, api
+}
+
+func blockThreat(t *testing.T, b *IPBlocker, ip string) {
+	t.Helper()
+	err := b.BlockThreat(&Threat{ID: generateThreatID(), IP: ip, Type: ThreatTypeScanning, Score: 100, Timestamp: time.Now(), Count: 1})
+	if err != nil {
+		t.Fatalf("BlockThreat(%s) error: %v", ip, err)
+	}
+}
+
+func TestParseBlockValue(t *testing.T) {
+	tests := []struct {
+		value  string
+		key    string
+		target string
+	}{
+		{"203.0.113.7", "203.0.113.7", "ip"},
+		{"203.0.113.7/32", "203.0.113.7/32", "ip"},
+		{"203.0.113.9/24", "203.0.113.0/24", "ip_range"},
+		{"10.1.0.0/16", "10.1.0.0/16", "ip_range"},
+		{"2001:db8::1", "2001:db8::1", "ip6"},
+		{"2001:db8::/32", "2001:db8::/32", "ip_range"},
+		{"2001:db8:1::/48", "2001:db8:1::/48", "ip_range"},
+		{"2001:db8:1:2::5/64", "2001:db8:1:2::/64", "ip_range"},
+	}
+	for _, tt := range tests {
+		target, key, err := accessRuleTarget(tt.value)
+		if err != nil || key != tt.key || target != tt.target {
+			t.Errorf("accessRuleTarget(%q) = %q, %q, %v, want %q, %q", tt.value, target, key, err, tt.target, tt.key)
+		}
+	}
+
+	for _, value := range []string{"10.0.0.0/8", "203.0.113.0/25", "203.0.113.0/23", "2001:db8::/56", "2001:db8::/16", "not-an-ip", ""} {
+		if _, _, err := parseBlockValue(value); errcode.Of(err) != errcode.InvalidArgument {
+			t.Errorf("parseBlockValue(%q) = %v, want INVALID_ARGUMENT", value, err)
+		}
+	}
+}
+
+func TestCollapsePrefix(t *testing.T) {
+	tests := map[string]string{
+		"203.0.113.7":          "203.0.113.0/24",
+		"::ffff:203.0.113.7":   "203.0.113.0/24",
+		"2001:db8:1:2:3:4:5:6": "2001:db8:1:2::/64",
+		"203.0.113.0/24":       "",
+		"not-an-ip":            "",
+	}
+	for ip, want := range tests {
+		if got := collapsePrefix(ip); got != want {
+			t.Errorf("collapsePrefix(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	tests := []struct {
+		name      string
+		whitelist []string
+		manual    []string // 先手动封禁，不参与合并
+		auto      []string
+		want      []string
+	}{
+		{
+			name: "ipv4 /24",
+			auto: []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"},
+			want: []string{"203.0.113.0/24"},
+		},
+		{
+			name: "ipv6 /64",
+			auto: []string{"2001:db8::1", "2001:db8::2", "2001:db8::3"},
+			want: []string{"2001:db8::/64"},
+		},
+		{
+			name: "different ranges",
+			auto: []string{"203.0.113.1", "203.0.113.2", "198.51.100.1"},
+			want: []string{"198.51.100.1", "203.0.113.1", "203.0.113.2"},
+		},
+		{
+			name:      "allowlisted ip in range",
+			whitelist: []string{"203.0.113.50"},
+			auto:      []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"},
+			want:      []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"},
+		},
+		{
+			name:   "manual blocks",
+			manual: []string{"203.0.113.1"},
+			auto:   []string{"203.0.113.2", "203.0.113.3"},
+			want:   []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultBlockerConfig()
+			config.CollapseThreshold = 3
+			config.WhitelistIPs = tt.whitelist
+			b, api := newTestBlocker(t, config)
+
+			for _, ip := range tt.manual {
+				if _, err := b.ManualBlock(ip, AccountScope, "", 0); err != nil {
+					t.Fatalf("ManualBlock(%s) error: %v", ip, err)
+				}
+			}
+			for _, ip := range tt.auto {
+				blockThreat(t, b, ip)
+			}
+
+			if got := api.values(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("access rules = %v, want %v", got, tt.want)
+			}
+			for _, ip := range tt.whitelist {
+				if b.IsBlocked(ip) {
+					t.Errorf("allowlisted %s is blocked", ip)
+				}
+			}
+		})
+	}
+}
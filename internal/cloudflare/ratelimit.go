@@ -0,0 +1,404 @@
+// Package cloudflare 速率限制规则：限制单个 IP 对某个路径的请求频率，用于缓解暴力破解而不直接封禁 IP
+package cloudflare
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 暴力破解的处理方式
+const (
+	BruteForceBan       = "ban"        // 封禁 IP
+	BruteForceRateLimit = "rate_limit" // 对被暴力破解的路径创建速率限制规则
+)
+
+// rateLimitPeriods Cloudflare 支持的计数周期（秒）
+var rateLimitPeriods = map[int]bool{10: true, 60: true, 120: true, 300: true, 600: true, 3600: true}
+
+// mitigationTimeouts Cloudflare 支持的动作时长（秒）
+var mitigationTimeouts = map[int]bool{10: true, 60: true, 120: true, 300: true, 600: true, 3600: true, 86400: true}
+
+// rateLimitCharacteristics 按数据中心和来源 IP 计数
+var rateLimitCharacteristics = []string{"cf.colo.id", "ip.src"}
+
+// requestLinePattern 访问日志中的请求方法和路径
+var requestLinePattern = regexp.MustCompile(`"(GET|POST|PUT|PATCH|DELETE|HEAD) (/[^ "?]*)`)
+
+// RateLimitSettings 速率限制参数
+type RateLimitSettings struct {
+	// 计数周期内允许的请求数
+	Threshold int `json:"threshold"`
+	// 计数周期（秒）：10、60、120、300、600 或 3600，免费套餐只支持 10
+	Period int `json:"period"`
+	// block、challenge、js_challenge、managed_challenge 或 log，默认 block
+	Action string `json:"action"`
+	// 触发后执行动作的时长（秒），默认与周期相同；质询类动作忽略
+	Timeout int `json:"timeout"`
+}
+
+// DefaultRateLimitSettings 自动缓解暴力破解时使用的默认参数：每分钟 10 次，超出后封禁 10 分钟
+func DefaultRateLimitSettings() *RateLimitSettings {
+	return &RateLimitSettings{
+		Threshold: 10,
+		Period:    60,
+		Action:    "block",
+		Timeout:   600,
+	}
+}
+
+// Validate 校验参数
+func (s *RateLimitSettings) Validate() error {
+	if fields := s.violations(""); len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "速率限制参数无效", fields)
+	}
+	return nil
+}
+
+// violations 校验参数，字段名加上 prefix 前缀
+func (s *RateLimitSettings) violations(prefix string) []errcode.FieldViolation {
+	var fields []errcode.FieldViolation
+	if s.Threshold < 1 {
+		fields = append(fields, errcode.FieldViolation{Field: prefix + "threshold", Description: "必须大于 0"})
+	}
+	if !rateLimitPeriods[s.Period] {
+		fields = append(fields, errcode.FieldViolation{Field: prefix + "period", Description: "必须为 10、60、120、300、600 或 3600"})
+	}
+	if s.Action != "" && !wafActions[s.Action] {
+		fields = append(fields, errcode.FieldViolation{Field: prefix + "action", Description: fmt.Sprintf("不支持的动作 %q，可选 block、challenge、js_challenge、managed_challenge、log", s.Action)})
+	}
+	if s.Timeout != 0 && !isChallengeAction(s.Action) && !mitigationTimeouts[s.Timeout] {
+		fields = append(fields, errcode.FieldViolation{Field: prefix + "timeout", Description: "必须为 10、60、120、300、600、3600 或 86400"})
+	}
+	return fields
+}
+
+// isChallengeAction 是否为质询类动作，这些动作在访问者通过质询前一直生效，不使用动作时长
+func isChallengeAction(action string) bool {
+	return action == "challenge" || action == "js_challenge" || action == "managed_challenge"
+}
+
+// ruleset 规则的动作和速率限制参数
+func (s *RateLimitSettings) ruleset() (string, *RatelimitParams) {
+	action := s.Action
+	if action == "" {
+		action = "block"
+	}
+	timeout := s.Timeout
+	switch {
+	case isChallengeAction(action):
+		timeout = 0
+	case timeout == 0:
+		timeout = s.Period
+	}
+	return action, &RatelimitParams{
+		Characteristics:   rateLimitCharacteristics,
+		Period:            s.Period,
+		RequestsPerPeriod: s.Threshold,
+		MitigationTimeout: timeout,
+	}
+}
+
+// RateLimitRequest 创建速率限制规则的请求
+type RateLimitRequest struct {
+	Description string `json:"description"`
+	// 限制的路径，如 /wp-login.php
+	Path string `json:"path"`
+	// 只限制该方法的请求，为空时限制所有方法
+	Method string `json:"method"`
+	// Cloudflare 规则表达式，代替 path 和 method 匹配请求
+	Expression string `json:"expression"`
+	RateLimitSettings
+	// 是否启用，默认启用
+	Enabled *bool `json:"enabled"`
+}
+
+// Validate 校验请求
+func (r *RateLimitRequest) Validate() error {
+	var fields []errcode.FieldViolation
+	switch {
+	case r.Expression != "" && (r.Path != "" || r.Method != ""):
+		fields = append(fields, errcode.FieldViolation{Field: "expression", Description: "不能与 path 和 method 同时填写"})
+	case r.Expression != "":
+		if len(r.Expression) > maxWAFExpressionLength {
+			fields = append(fields, errcode.FieldViolation{Field: "expression", Description: fmt.Sprintf("不能超过 %d 个字符", maxWAFExpressionLength)})
+		}
+	case r.Path == "":
+		fields = append(fields, errcode.FieldViolation{Field: "path", Description: "path 和 expression 必须填写一个"})
+	case !strings.HasPrefix(r.Path, "/") || strings.ContainsAny(r.Path, "\"\\ \t\r\n"):
+		fields = append(fields, errcode.FieldViolation{Field: "path", Description: "必须以 / 开头且不含引号、反斜杠和空白"})
+	}
+	if r.Method != "" && !requestMethods[strings.ToUpper(r.Method)] {
+		fields = append(fields, errcode.FieldViolation{Field: "method", Description: fmt.Sprintf("不支持的方法 %q", r.Method)})
+	}
+	fields = append(fields, r.RateLimitSettings.violations("")...)
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "速率限制规则无效", fields)
+	}
+	return nil
+}
+
+// requestMethods 速率限制规则可以匹配的请求方法
+var requestMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true}
+
+// pathExpression 匹配路径和方法的规则表达式
+func pathExpression(path, method string) string {
+	if method == "" {
+		return fmt.Sprintf(`(http.request.uri.path eq "%s")`, path)
+	}
+	return fmt.Sprintf(`(http.request.method eq "%s" and http.request.uri.path eq "%s")`, strings.ToUpper(method), path)
+}
+
+// rule 转为规则集中的规则
+func (r *RateLimitRequest) rule() *RulesetRule {
+	expression := strings.TrimSpace(r.Expression)
+	if expression == "" {
+		expression = pathExpression(r.Path, r.Method)
+	}
+	description := r.Description
+	if description == "" {
+		description = "Created by Runixo"
+	}
+	action, params := r.RateLimitSettings.ruleset()
+	return &RulesetRule{
+		Ref:         newManagedRef(),
+		Action:      action,
+		Expression:  expression,
+		Description: description,
+		Enabled:     r.Enabled == nil || *r.Enabled,
+		Ratelimit:   params,
+	}
+}
+
+// RateLimitRule 域名上的一条速率限制规则
+type RateLimitRule struct {
+	ID          string `json:"id"`
+	ZoneID      string `json:"zone_id"`
+	ZoneName    string `json:"zone_name"`
+	Description string `json:"description"`
+	Expression  string `json:"expression"`
+	Action      string `json:"action"`
+	Threshold   int    `json:"threshold"`
+	Period      int    `json:"period"`
+	Timeout     int    `json:"timeout"`
+	Enabled     bool   `json:"enabled"`
+	// 是否由 Runixo 创建，只有这些规则可以通过 Runixo 删除
+	Managed     bool   `json:"managed"`
+	LastUpdated string `json:"last_updated,omitempty"`
+}
+
+// newRateLimitRule 把规则集中的规则转为 RateLimitRule
+func newRateLimitRule(zone *Zone, rule *RulesetRule) *RateLimitRule {
+	r := &RateLimitRule{
+		ID:          rule.ID,
+		ZoneID:      zone.ID,
+		ZoneName:    zone.Name,
+		Description: rule.Description,
+		Expression:  rule.Expression,
+		Action:      rule.Action,
+		Enabled:     rule.Enabled,
+		Managed:     rule.managed(),
+		LastUpdated: rule.LastUpdated,
+	}
+	if rule.Ratelimit != nil {
+		r.Threshold = rule.Ratelimit.RequestsPerPeriod
+		r.Period = rule.Ratelimit.Period
+		r.Timeout = rule.Ratelimit.MitigationTimeout
+	}
+	return r
+}
+
+// ListRateLimitRules 列出域名上的速率限制规则，zone 为 zone ID、名称或其下的主机名
+func (sm *SecurityManager) ListRateLimitRules(zone string) ([]*RateLimitRule, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleset, err := sm.client.phaseRuleset(z.ID, PhaseRateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*RateLimitRule, 0)
+	if ruleset != nil {
+		for i := range ruleset.Rules {
+			rules = append(rules, newRateLimitRule(z, &ruleset.Rules[i]))
+		}
+	}
+	return rules, nil
+}
+
+// CreateRateLimitRule 在域名上创建速率限制规则，添加在已有规则之后
+func (sm *SecurityManager) CreateRateLimitRule(zone string, req *RateLimitRequest) (*RateLimitRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := sm.client.addPhaseRule(z.ID, PhaseRateLimit, req.rule())
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "创建速率限制规则失败")
+	}
+
+	log.Info().Str("zone", z.Name).Str("rule", created.ID).Str("expression", created.Expression).Msg("已创建速率限制规则")
+	return newRateLimitRule(z, created), nil
+}
+
+// SetRateLimitRuleEnabled 启用或停用域名上的速率限制规则
+func (sm *SecurityManager) SetRateLimitRuleEnabled(zone, id string, enabled bool) (*RateLimitRule, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := sm.client.setPhaseRuleEnabled(z.ID, PhaseRateLimit, id, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("zone", z.Name).Str("rule", id).Bool("enabled", enabled).Msg("已更新速率限制规则")
+	return newRateLimitRule(z, rule), nil
+}
+
+// DeleteRateLimitRule 删除域名上由 Runixo 创建的速率限制规则，其他规则只能停用
+func (sm *SecurityManager) DeleteRateLimitRule(zone, id string) error {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return err
+	}
+
+	if err := sm.client.deleteManagedPhaseRule(z.ID, PhaseRateLimit, id); err != nil {
+		return err
+	}
+
+	log.Info().Str("zone", z.Name).Str("rule", id).Msg("已删除速率限制规则")
+	return nil
+}
+
+// rateLimitThreat 按配置用速率限制规则缓解 Web 暴力破解，返回是否已处理；
+// 不是 Web 请求的暴力破解（如 SSH）无法在 Cloudflare 上限速，仍然封禁 IP
+func (b *IPBlocker) rateLimitThreat(threat *Threat) bool {
+	method, path, ok := b.rateLimitRequest(threat)
+	if !ok {
+		return false
+	}
+
+	targets, err := b.threatTargets(threat.Source)
+	if err != nil {
+		log.Error().Err(err).Msg("确定速率限制范围失败")
+		return true
+	}
+	// 速率限制规则只能创建在域名上，账户级范围改为所有启用的域名
+	var zones []Zone
+	for _, target := range targets {
+		if target.zone != nil {
+			zones = append(zones, *target.zone)
+			continue
+		}
+		enabled, err := b.zones.Enabled()
+		if err != nil {
+			log.Error().Err(err).Msg("确定速率限制范围失败")
+			return true
+		}
+		zones = append(zones, enabled...)
+	}
+
+	for i := range zones {
+		if err := b.ensureRateLimit(&zones[i], method, path, threat); err != nil {
+			log.Error().Err(err).Str("zone", zones[i].Name).Str("path", path).Msg("创建速率限制规则失败")
+		}
+	}
+	return true
+}
+
+// rateLimitRequest 按配置应该对其限速的请求方法和路径，不以速率限制处理时 ok 为 false
+func (b *IPBlocker) rateLimitRequest(threat *Threat) (method, path string, ok bool) {
+	if threat.Type != ThreatTypeBruteForce || b.config.BruteForceAction != BruteForceRateLimit {
+		return "", "", false
+	}
+	matches := requestLinePattern.FindStringSubmatch(threat.Line)
+	if matches == nil || strings.ContainsRune(matches[2], '\\') {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
+
+// ensureRateLimit 确保域名上有限制 method 和 path 的速率限制规则，已有相同表达式的规则（包括手动创建的）时不重复创建
+func (b *IPBlocker) ensureRateLimit(zone *Zone, method, path string, threat *Threat) error {
+	expression := pathExpression(path, method)
+	key := zone.ID + " " + expression
+
+	b.rateLimitMu.Lock()
+	defer b.rateLimitMu.Unlock()
+
+	if _, exists := b.rateLimited[key]; exists {
+		return nil
+	}
+
+	ruleset, err := b.client.phaseRuleset(zone.ID, PhaseRateLimit)
+	if err != nil {
+		return err
+	}
+	if ruleset != nil {
+		for _, rule := range ruleset.Rules {
+			if rule.Expression == expression {
+				b.rateLimited[key] = rule.ID
+				return nil
+			}
+		}
+	}
+
+	settings := b.config.BruteForceRateLimit
+	if settings == nil {
+		settings = DefaultRateLimitSettings()
+	}
+	req := &RateLimitRequest{
+		Description:       "Runixo brute force mitigation: " + method + " " + path,
+		Path:              path,
+		Method:            method,
+		RateLimitSettings: *settings,
+	}
+	rule, err := b.client.addPhaseRule(zone.ID, PhaseRateLimit, req.rule())
+	if err != nil {
+		return err
+	}
+	b.rateLimited[key] = rule.ID
+
+	b.sendEvent(&BlockEvent{
+		Type:      "rate_limited",
+		IP:        threat.IP,
+		ZoneID:    zone.ID,
+		Reason:    req.Description,
+		Timestamp: time.Now(),
+		Threat:    threat,
+	})
+
+	log.Info().
+		Str("zone", zone.Name).
+		Str("rule_id", rule.ID).
+		Str("path", path).
+		Str("ip", threat.IP).
+		Msg("已创建速率限制规则缓解暴力破解")
+	return nil
+}
@@ -0,0 +1,201 @@
+// Package cloudflare 规则集 API：WAF 自定义规则和速率限制规则都是域名入口规则集中的规则
+package cloudflare
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 规则集阶段
+const (
+	// PhaseCustomFirewall WAF 自定义规则所在的阶段
+	PhaseCustomFirewall = "http_request_firewall_custom"
+	// PhaseRateLimit 速率限制规则所在的阶段
+	PhaseRateLimit = "http_ratelimit"
+)
+
+// managedRefPrefix Runixo 创建的规则的 ref 前缀，只有这些规则可以通过 Runixo 删除
+const managedRefPrefix = "runixo_"
+
+// Ruleset 规则集
+type Ruleset struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Kind        string        `json:"kind"`
+	Phase       string        `json:"phase"`
+	Rules       []RulesetRule `json:"rules"`
+	LastUpdated string        `json:"last_updated,omitempty"`
+}
+
+// RulesetRule 规则集中的规则
+type RulesetRule struct {
+	ID          string `json:"id,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	Action      string `json:"action"`
+	Expression  string `json:"expression"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	// 速率限制参数，只用于 PhaseRateLimit 阶段的规则
+	Ratelimit   *RatelimitParams `json:"ratelimit,omitempty"`
+	LastUpdated string           `json:"last_updated,omitempty"`
+}
+
+// RatelimitParams 速率限制规则的参数
+type RatelimitParams struct {
+	// 计数的维度，必须包含 cf.colo.id
+	Characteristics []string `json:"characteristics"`
+	// 计数周期（秒）
+	Period int `json:"period"`
+	// 周期内允许的请求数
+	RequestsPerPeriod int `json:"requests_per_period"`
+	// 触发后执行动作的时长（秒），质询类动作为 0
+	MitigationTimeout int `json:"mitigation_timeout"`
+}
+
+// managed 规则是否由 Runixo 创建
+func (r *RulesetRule) managed() bool {
+	return strings.HasPrefix(r.Ref, managedRefPrefix)
+}
+
+// newManagedRef 生成 Runixo 创建的规则的 ref
+func newManagedRef() string {
+	return managedRefPrefix + randomString(12)
+}
+
+// rule 按 ID 查找规则
+func (r *Ruleset) rule(id string) *RulesetRule {
+	for i := range r.Rules {
+		if r.Rules[i].ID == id {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
+
+// rulesetResult 解析返回规则集的响应
+func rulesetResult(resp *APIResponse, err error) (*Ruleset, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var ruleset Ruleset
+	if err := json.Unmarshal(resp.Result, &ruleset); err != nil {
+		return nil, fmt.Errorf("解析规则集失败: %w", err)
+	}
+
+	return &ruleset, nil
+}
+
+// GetPhaseEntrypoint 获取域名在 phase 阶段的入口规则集，域名尚未创建时返回的错误满足 IsNotFound
+func (c *Client) GetPhaseEntrypoint(zoneID, phase string) (*Ruleset, error) {
+	return rulesetResult(c.request("GET", fmt.Sprintf("/zones/%s/rulesets/phases/%s/entrypoint", zoneID, phase), nil))
+}
+
+// CreatePhaseEntrypoint 创建或替换域名在 phase 阶段的入口规则集
+func (c *Client) CreatePhaseEntrypoint(zoneID, phase string, rules []RulesetRule) (*Ruleset, error) {
+	body := map[string]interface{}{"rules": rules}
+	return rulesetResult(c.request("PUT", fmt.Sprintf("/zones/%s/rulesets/phases/%s/entrypoint", zoneID, phase), body))
+}
+
+// AddRulesetRule 在规则集末尾添加规则，返回更新后的规则集
+func (c *Client) AddRulesetRule(zoneID, rulesetID string, rule *RulesetRule) (*Ruleset, error) {
+	return rulesetResult(c.request("POST", fmt.Sprintf("/zones/%s/rulesets/%s/rules", zoneID, rulesetID), rule))
+}
+
+// UpdateRulesetRule 修改规则，返回更新后的规则集
+func (c *Client) UpdateRulesetRule(zoneID, rulesetID, ruleID string, rule *RulesetRule) (*Ruleset, error) {
+	return rulesetResult(c.request("PATCH", fmt.Sprintf("/zones/%s/rulesets/%s/rules/%s", zoneID, rulesetID, ruleID), rule))
+}
+
+// DeleteRulesetRule 删除规则，返回更新后的规则集
+func (c *Client) DeleteRulesetRule(zoneID, rulesetID, ruleID string) (*Ruleset, error) {
+	return rulesetResult(c.request("DELETE", fmt.Sprintf("/zones/%s/rulesets/%s/rules/%s", zoneID, rulesetID, ruleID), nil))
+}
+
+// addPhaseRule 把规则添加到域名 phase 阶段的入口规则集，入口规则集不存在时创建，返回新规则
+func (c *Client) addPhaseRule(zoneID, phase string, rule *RulesetRule) (*RulesetRule, error) {
+	ruleset, err := c.GetPhaseEntrypoint(zoneID, phase)
+	switch {
+	case IsNotFound(err):
+		ruleset, err = c.CreatePhaseEntrypoint(zoneID, phase, []RulesetRule{*rule})
+	case err == nil:
+		ruleset, err = c.AddRulesetRule(zoneID, ruleset.ID, rule)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ruleset.Rules {
+		if ruleset.Rules[i].Ref == rule.Ref {
+			return &ruleset.Rules[i], nil
+		}
+	}
+	return nil, fmt.Errorf("规则集中没有新建的规则 %s", rule.Ref)
+}
+
+// phaseRuleset 获取域名在 phase 阶段的入口规则集，尚未创建时返回 nil
+func (c *Client) phaseRuleset(zoneID, phase string) (*Ruleset, error) {
+	ruleset, err := c.GetPhaseEntrypoint(zoneID, phase)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "获取规则集失败")
+	}
+	return ruleset, nil
+}
+
+// findPhaseRule 在域名 phase 阶段的入口规则集中查找规则
+func (c *Client) findPhaseRule(zoneID, phase, id string) (*Ruleset, *RulesetRule, error) {
+	ruleset, err := c.phaseRuleset(zoneID, phase)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ruleset != nil {
+		if rule := ruleset.rule(id); rule != nil {
+			return ruleset, rule, nil
+		}
+	}
+	return nil, nil, errcode.New(errcode.NotFound, "规则 %s 不存在", id)
+}
+
+// setPhaseRuleEnabled 启用或停用域名 phase 阶段的规则，返回更新后的规则
+func (c *Client) setPhaseRuleEnabled(zoneID, phase, id string, enabled bool) (*RulesetRule, error) {
+	ruleset, rule, err := c.findPhaseRule(zoneID, phase, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Enabled == enabled {
+		return rule, nil
+	}
+
+	update := *rule
+	update.Enabled = enabled
+	ruleset, err = c.UpdateRulesetRule(zoneID, ruleset.ID, id, &update)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "更新规则失败")
+	}
+	if rule = ruleset.rule(id); rule == nil {
+		return nil, errcode.New(errcode.NotFound, "规则 %s 不存在", id)
+	}
+	return rule, nil
+}
+
+// deleteManagedPhaseRule 删除域名 phase 阶段由 Runixo 创建的规则，其他规则只能停用
+func (c *Client) deleteManagedPhaseRule(zoneID, phase, id string) error {
+	ruleset, rule, err := c.findPhaseRule(zoneID, phase, id)
+	if err != nil {
+		return err
+	}
+	if !rule.managed() {
+		return errcode.New(errcode.PermissionDenied, "规则 %s 不是由 Runixo 创建的，只能停用", id)
+	}
+
+	if _, err := c.DeleteRulesetRule(zoneID, ruleset.ID, id); err != nil && !IsNotFound(err) {
+		return errcode.Wrap(errcode.UpstreamUnavailable, err, "删除规则失败")
+	}
+	return nil
+}
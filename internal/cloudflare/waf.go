@@ -0,0 +1,186 @@
+// Package cloudflare WAF 自定义规则：按表达式在 Cloudflare 边缘拦截请求，用于单个 IP 封禁无法覆盖的攻击
+package cloudflare
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// maxWAFExpressionLength Cloudflare 规则表达式的最大长度
+const maxWAFExpressionLength = 4096
+
+// wafActions WAF 自定义规则支持的动作
+var wafActions = map[string]bool{
+	"block":             true,
+	"challenge":         true,
+	"js_challenge":      true,
+	"managed_challenge": true,
+	"log":               true,
+}
+
+// WAFRule 域名上的一条 WAF 自定义规则
+type WAFRule struct {
+	ID          string `json:"id"`
+	ZoneID      string `json:"zone_id"`
+	ZoneName    string `json:"zone_name"`
+	Description string `json:"description"`
+	Expression  string `json:"expression"`
+	Action      string `json:"action"`
+	Enabled     bool   `json:"enabled"`
+	// 是否由 Runixo 创建，只有这些规则可以通过 Runixo 删除
+	Managed     bool   `json:"managed"`
+	LastUpdated string `json:"last_updated,omitempty"`
+}
+
+// WAFRuleRequest 创建 WAF 自定义规则的请求
+type WAFRuleRequest struct {
+	Description string `json:"description"`
+	// Cloudflare 规则表达式，如 (http.request.uri.path contains "/wp-login.php")
+	Expression string `json:"expression"`
+	// block、challenge、js_challenge、managed_challenge 或 log
+	Action string `json:"action"`
+	// 是否启用，默认启用
+	Enabled *bool `json:"enabled"`
+}
+
+// Validate 校验请求
+func (r *WAFRuleRequest) Validate() error {
+	var fields []errcode.FieldViolation
+	expression := strings.TrimSpace(r.Expression)
+	switch {
+	case expression == "":
+		fields = append(fields, errcode.FieldViolation{Field: "expression", Description: "不能为空"})
+	case len(expression) > maxWAFExpressionLength:
+		fields = append(fields, errcode.FieldViolation{Field: "expression", Description: fmt.Sprintf("不能超过 %d 个字符", maxWAFExpressionLength)})
+	}
+	if !wafActions[r.Action] {
+		fields = append(fields, errcode.FieldViolation{Field: "action", Description: fmt.Sprintf("不支持的动作 %q，可选 block、challenge、js_challenge、managed_challenge、log", r.Action)})
+	}
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "WAF 规则无效", fields)
+	}
+	return nil
+}
+
+// newWAFRule 把规则集中的规则转为 WAFRule
+func newWAFRule(zone *Zone, rule *RulesetRule) *WAFRule {
+	return &WAFRule{
+		ID:          rule.ID,
+		ZoneID:      zone.ID,
+		ZoneName:    zone.Name,
+		Description: rule.Description,
+		Expression:  rule.Expression,
+		Action:      rule.Action,
+		Enabled:     rule.Enabled,
+		Managed:     rule.managed(),
+		LastUpdated: rule.LastUpdated,
+	}
+}
+
+// rulesetZone 解析规则集规则所在的域名（需要持有读锁）
+func (sm *SecurityManager) rulesetZone(zone string) (*Zone, error) {
+	if sm.client == nil || sm.zones == nil {
+		return nil, &ConfigError{Message: "Cloudflare 未配置"}
+	}
+	return sm.zones.Resolve(zone)
+}
+
+// ListWAFRules 列出域名上的 WAF 自定义规则，zone 为 zone ID、名称或其下的主机名
+func (sm *SecurityManager) ListWAFRules(zone string) ([]*WAFRule, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleset, err := sm.client.phaseRuleset(z.ID, PhaseCustomFirewall)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*WAFRule, 0)
+	if ruleset != nil {
+		for i := range ruleset.Rules {
+			rules = append(rules, newWAFRule(z, &ruleset.Rules[i]))
+		}
+	}
+	return rules, nil
+}
+
+// CreateWAFRule 在域名上创建 WAF 自定义规则，添加在已有规则之后
+func (sm *SecurityManager) CreateWAFRule(zone string, req *WAFRuleRequest) (*WAFRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "Created by Runixo"
+	}
+	rule := &RulesetRule{
+		Ref:         newManagedRef(),
+		Action:      req.Action,
+		Expression:  strings.TrimSpace(req.Expression),
+		Description: description,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+
+	created, err := sm.client.addPhaseRule(z.ID, PhaseCustomFirewall, rule)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "创建 WAF 规则失败")
+	}
+
+	log.Info().Str("zone", z.Name).Str("rule", created.ID).Str("action", created.Action).
+		Str("expression", created.Expression).Msg("已创建 WAF 规则")
+	return newWAFRule(z, created), nil
+}
+
+// SetWAFRuleEnabled 启用或停用域名上的 WAF 自定义规则
+func (sm *SecurityManager) SetWAFRuleEnabled(zone, id string, enabled bool) (*WAFRule, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := sm.client.setPhaseRuleEnabled(z.ID, PhaseCustomFirewall, id, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("zone", z.Name).Str("rule", id).Bool("enabled", enabled).Msg("已更新 WAF 规则")
+	return newWAFRule(z, rule), nil
+}
+
+// DeleteWAFRule 删除域名上由 Runixo 创建的 WAF 自定义规则，其他规则只能停用
+func (sm *SecurityManager) DeleteWAFRule(zone, id string) error {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	z, err := sm.rulesetZone(zone)
+	if err != nil {
+		return err
+	}
+
+	if err := sm.client.deleteManagedPhaseRule(z.ID, PhaseCustomFirewall, id); err != nil {
+		return err
+	}
+
+	log.Info().Str("zone", z.Name).Str("rule", id).Msg("已删除 WAF 规则")
+	return nil
+}
@@ -44,8 +44,10 @@ type LogEvent struct {
 
 // WatcherConfig 监控器配置
 type WatcherConfig struct {
-	// 要监控的日志文件路径
+	// 要监控的日志文件路径，使用所有内置检测模式
 	LogPaths []string `json:"log_paths"`
+	// 指定了日志格式或自定义检测模式的日志文件，同样会被监控
+	Sources []LogSource `json:"sources,omitempty"`
 	// 是否启用自动封禁
 	AutoBlock bool `json:"auto_block"`
 	// 封禁阈值（触发次数）
@@ -87,8 +89,13 @@ func NewLogWatcher(config *WatcherConfig, detector *ThreatDetector, blocker *IPB
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	paths := append([]string{}, config.LogPaths...)
+	for _, source := range config.Sources {
+		paths = append(paths, source.Path)
+	}
+
 	lw := &LogWatcher{
-		paths:      config.LogPaths,
+		paths:      paths,
 		detector:   detector,
 		blocker:    blocker,
 		watcher:    watcher,
@@ -0,0 +1,342 @@
+// Package cloudflare 多域名支持：自动发现域名、按域名启用封禁、日志到域名的映射
+package cloudflare
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// AccountScope 手动封禁和解封时表示账户级别的范围
+	AccountScope = "account"
+	// defaultZoneRefreshInterval 未配置时域名列表的刷新间隔
+	defaultZoneRefreshInterval = 10 * time.Minute
+)
+
+// ZoneConfig 多域名配置
+type ZoneConfig struct {
+	// 停用自动封禁的域名，填 zone 名称或 ID
+	Disabled []string `json:"disabled"`
+	// 日志文件到域名的映射，值为 zone 名称、ID 或其下的主机名。
+	// 未映射的日志按文件名中的域名匹配，如 shop.example.com.access.log 对应 example.com
+	Sources map[string]string `json:"sources"`
+	// 无法确定域名时在账户级别封禁（对账户下所有域名生效），否则在所有启用的域名上分别封禁
+	AccountWide bool `json:"account_wide"`
+	// 域名列表的刷新间隔（秒）
+	RefreshInterval int `json:"refresh_interval"`
+}
+
+// ZoneStatus 发现的域名及其状态
+type ZoneStatus struct {
+	Zone
+	// 是否启用自动封禁
+	Enabled bool `json:"enabled"`
+	// 映射到该域名的日志文件
+	Sources []string `json:"sources,omitempty"`
+}
+
+// DefaultZoneConfig 默认多域名配置
+func DefaultZoneConfig() *ZoneConfig {
+	return &ZoneConfig{
+		Disabled:        []string{},
+		Sources:         map[string]string{},
+		RefreshInterval: int(defaultZoneRefreshInterval.Seconds()),
+	}
+}
+
+// ZoneRegistry API Token 可访问的域名，超过刷新间隔后在下次使用时重新列出
+type ZoneRegistry struct {
+	client      *Client
+	config      *ZoneConfig
+	accountID   string   // 配置的账户 ID，为空时使用域名所属的账户
+	protected   []string // 封禁器配置中要保护的 Zone ID，空表示所有域名
+	zones       []Zone
+	refreshedAt time.Time
+	mu          sync.RWMutex
+}
+
+// NewZoneRegistry 创建域名注册表
+func NewZoneRegistry(client *Client, accountID string, config *ZoneConfig, protected []string) *ZoneRegistry {
+	if config == nil {
+		config = DefaultZoneConfig()
+	}
+	if config.Sources == nil {
+		config.Sources = map[string]string{}
+	}
+
+	return &ZoneRegistry{
+		client:    client,
+		config:    config,
+		accountID: accountID,
+		protected: protected,
+	}
+}
+
+// Refresh 重新列出 API Token 可访问的域名
+func (r *ZoneRegistry) Refresh() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.refreshLocked()
+}
+
+// refreshLocked 重新列出域名（需要持有锁）
+func (r *ZoneRegistry) refreshLocked() error {
+	zones, err := r.client.ListZones()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	r.zones = zones
+	r.refreshedAt = time.Now()
+
+	log.Debug().Int("count", len(zones)).Msg("已刷新 Cloudflare 域名列表")
+	return nil
+}
+
+// list 返回域名列表，超过刷新间隔时先刷新；刷新失败但有缓存时使用缓存
+func (r *ZoneRegistry) list() ([]Zone, error) {
+	r.mu.RLock()
+	interval := time.Duration(r.config.RefreshInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultZoneRefreshInterval
+	}
+	fresh := !r.refreshedAt.IsZero() && time.Since(r.refreshedAt) < interval
+	zones := r.zones
+	r.mu.RUnlock()
+	if fresh {
+		return zones, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.refreshLocked(); err != nil {
+		if r.refreshedAt.IsZero() {
+			return nil, err
+		}
+		log.Warn().Err(err).Msg("刷新 Cloudflare 域名列表失败，使用缓存")
+	}
+	return r.zones, nil
+}
+
+// Zones 返回发现的域名及其状态
+func (r *ZoneRegistry) Zones() ([]*ZoneStatus, error) {
+	zones, err := r.list()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*ZoneStatus, 0, len(zones))
+	for _, zone := range zones {
+		status := &ZoneStatus{Zone: zone, Enabled: r.enabledLocked(&zone)}
+		for source, target := range r.config.Sources {
+			if z := matchZone(zones, target); z != nil && z.ID == zone.ID {
+				status.Sources = append(status.Sources, source)
+			}
+		}
+		sort.Strings(status.Sources)
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Enabled 返回启用自动封禁的域名
+func (r *ZoneRegistry) Enabled() ([]Zone, error) {
+	zones, err := r.list()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var enabled []Zone
+	for i := range zones {
+		if r.enabledLocked(&zones[i]) {
+			enabled = append(enabled, zones[i])
+		}
+	}
+	return enabled, nil
+}
+
+// IsEnabled 检查域名是否启用自动封禁
+func (r *ZoneRegistry) IsEnabled(zone *Zone) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.enabledLocked(zone)
+}
+
+// enabledLocked 域名未被停用，且在要保护的域名中（需要持有锁）
+func (r *ZoneRegistry) enabledLocked(zone *Zone) bool {
+	for _, d := range r.config.Disabled {
+		if d == zone.ID || strings.EqualFold(d, zone.Name) {
+			return false
+		}
+	}
+	return r.protectedLocked(zone)
+}
+
+// protectedLocked 域名在要保护的域名中（需要持有锁）
+func (r *ZoneRegistry) protectedLocked(zone *Zone) bool {
+	if len(r.protected) == 0 {
+		return true
+	}
+	for _, id := range r.protected {
+		if id == zone.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve 按 zone ID、名称或其下的主机名查找域名
+func (r *ZoneRegistry) Resolve(name string) (*Zone, error) {
+	zones, err := r.list()
+	if err != nil {
+		return nil, err
+	}
+	if zone := matchZone(zones, name); zone != nil {
+		return zone, nil
+	}
+	return nil, errcode.New(errcode.NotFound, "API Token 无法访问域名 %s", name)
+}
+
+// ForSource 返回日志文件对应的域名：先查配置的映射，再按文件名中出现的域名匹配，
+// 多个域名都出现时取最长的。无法确定时返回 nil
+func (r *ZoneRegistry) ForSource(source string) *Zone {
+	if source == "" {
+		return nil
+	}
+	zones, err := r.list()
+	if err != nil {
+		log.Warn().Err(err).Msg("获取域名列表失败")
+		return nil
+	}
+
+	r.mu.RLock()
+	target, mapped := r.config.Sources[source]
+	if !mapped {
+		target, mapped = r.config.Sources[filepath.Clean(source)]
+	}
+	r.mu.RUnlock()
+	if mapped {
+		zone := matchZone(zones, target)
+		if zone == nil {
+			log.Warn().Str("source", source).Str("zone", target).Msg("日志映射的域名不存在")
+		}
+		return zone
+	}
+
+	base := strings.ToLower(filepath.Base(source))
+	var found *Zone
+	for i := range zones {
+		if containsDomain(base, strings.ToLower(zones[i].Name)) && (found == nil || len(zones[i].Name) > len(found.Name)) {
+			found = &zones[i]
+		}
+	}
+	return found
+}
+
+// AccountID 返回账户级封禁使用的账户：配置的账户 ID，未配置时为所有域名共同所属的账户
+func (r *ZoneRegistry) AccountID() string {
+	if r.accountID != "" {
+		return r.accountID
+	}
+	zones, err := r.list()
+	if err != nil {
+		return ""
+	}
+
+	var accountID string
+	for _, zone := range zones {
+		if zone.Account == nil || (accountID != "" && zone.Account.ID != accountID) {
+			return ""
+		}
+		accountID = zone.Account.ID
+	}
+	return accountID
+}
+
+// AccountWide 无法确定域名时是否在账户级别封禁
+func (r *ZoneRegistry) AccountWide() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config.AccountWide
+}
+
+// SetEnabled 启用或停用域名的自动封禁，返回域名
+func (r *ZoneRegistry) SetEnabled(name string, enabled bool) (*Zone, error) {
+	zone, err := r.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if enabled && !r.protectedLocked(zone) {
+		return nil, errcode.New(errcode.InvalidArgument, "域名 %s 不在要保护的域名中", zone.Name)
+	}
+	disabled := make([]string, 0, len(r.config.Disabled)+1)
+	for _, d := range r.config.Disabled {
+		if d != zone.ID && !strings.EqualFold(d, zone.Name) {
+			disabled = append(disabled, d)
+		}
+	}
+	if !enabled {
+		disabled = append(disabled, zone.Name)
+	}
+	r.config.Disabled = disabled
+	return zone, nil
+}
+
+// SetConfig 更新配置
+func (r *ZoneRegistry) SetConfig(config *ZoneConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if config.Sources == nil {
+		config.Sources = map[string]string{}
+	}
+	r.config = config
+}
+
+// matchZone 按 zone ID、名称或主机名（取后缀匹配最长的域名）查找域名
+func matchZone(zones []Zone, name string) *Zone {
+	host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	var found *Zone
+	for i := range zones {
+		zoneName := strings.ToLower(zones[i].Name)
+		switch {
+		case zones[i].ID == name || zoneName == host:
+			return &zones[i]
+		case strings.HasSuffix(host, "."+zoneName) && (found == nil || len(zoneName) > len(found.Name)):
+			found = &zones[i]
+		}
+	}
+	return found
+}
+
+// containsDomain 文件名中是否出现完整的域名，前后为文件名边界或 . - _ 分隔符
+func containsDomain(name, domain string) bool {
+	boundary := func(c byte) bool { return c == '.' || c == '-' || c == '_' }
+	for offset := 0; ; {
+		i := strings.Index(name[offset:], domain)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(domain)
+		if (start == 0 || boundary(name[start-1])) && (end == len(name) || boundary(name[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
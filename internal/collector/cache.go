@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// CacheConfig 各类数据的缓存时长
+// 时长内的重复请求直接返回上次采集的快照，多个客户端同时轮询时只触发一次采集
+type CacheConfig struct {
+	Metrics     time.Duration // CPU / 内存 / 速率类指标
+	Processes   time.Duration // 进程列表（需要遍历 /proc，开销最大）
+	Disks       time.Duration // 挂载点容量与 inode
+	Connections time.Duration // 网络连接（需要遍历所有进程的 fd）
+}
+
+// DefaultCacheConfig 默认缓存时长
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Metrics:     800 * time.Millisecond,
+		Processes:   3 * time.Second,
+		Disks:       30 * time.Second,
+		Connections: 5 * time.Second,
+	}
+}
+
+// snapshot 带过期时间的单值缓存
+// 采集期间持有锁，并发请求会等待同一次采集完成而不是各自重新采集
+type snapshot[T any] struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	value T
+	at    time.Time
+}
+
+func (s *snapshot[T]) get(fetch func() (T, error)) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.at.IsZero() && time.Since(s.at) < s.ttl {
+		return s.value, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	s.value, s.at = v, time.Now()
+	return v, nil
+}
+
+func (s *snapshot[T]) setTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+}
+
+// SetCacheConfig 设置各类数据的缓存时长，小于等于 0 的项保持不变
+func (c *Collector) SetCacheConfig(config CacheConfig) {
+	if config.Metrics > 0 {
+		c.mu.Lock()
+		c.cacheValidFor = config.Metrics
+		c.mu.Unlock()
+	}
+	if config.Processes > 0 {
+		c.processCache.setTTL(config.Processes)
+	}
+	if config.Disks > 0 {
+		c.diskCache.setTTL(config.Disks)
+	}
+	if config.Connections > 0 {
+		c.connCache.setTTL(config.Connections)
+	}
+}
@@ -0,0 +1,261 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// clockCacheTTL 时钟同步状态的缓存时间，偏差变化很慢，不需要频繁查询
+	clockCacheTTL = time.Minute
+	// maxClockOffset 直接探测时偏差在该范围内视为已同步
+	maxClockOffset = 500 * time.Millisecond
+)
+
+// ClockSync 系统时钟同步状态
+// 时钟偏差会导致证书校验失败、日志时间无法对齐，且通常不会有明显报错
+type ClockSync struct {
+	Synchronized bool
+	Source       string  // chrony / ntpd / timesyncd / systemd / sntp
+	Server       string  // 当前参考的时间服务器
+	Stratum      int     // 0 表示未知
+	Offset       float64 // 本地时钟相对参考时钟的偏差（秒），正值表示本地偏快
+}
+
+// clockCollector 缓存时钟同步状态
+type clockCollector struct {
+	mu     sync.Mutex
+	server string // 本地没有可查询的同步服务时直接探测的 NTP 服务器，为空则不探测
+	status *ClockSync
+	at     time.Time
+}
+
+// SetNTPServer 设置备用的 NTP 服务器（host 或 host:port）
+// 仅在本机没有 chrony / ntpd / systemd-timesyncd 可查询时使用，为空表示不主动探测
+func (c *Collector) SetNTPServer(server string) {
+	c.clock.mu.Lock()
+	defer c.clock.mu.Unlock()
+	c.clock.server = server
+	c.clock.at = time.Time{}
+}
+
+// GetClockSync 获取时钟同步状态，无法确定时返回 nil
+func (c *Collector) GetClockSync() *ClockSync {
+	return c.clock.get()
+}
+
+func (k *clockCollector) get() *ClockSync {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.at.IsZero() && time.Since(k.at) < clockCacheTTL {
+		return k.status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	k.status = queryClockSync(ctx, k.server)
+	k.at = time.Now()
+	return k.status
+}
+
+// queryClockSync 依次尝试 chrony、ntpd、systemd-timesyncd，最后直接探测 NTP 服务器
+func queryClockSync(ctx context.Context, server string) *ClockSync {
+	if out, err := exec.CommandContext(ctx, "chronyc", "-n", "tracking").Output(); err == nil {
+		if status := parseChronyTracking(string(out)); status != nil {
+			return status
+		}
+	}
+	if out, err := exec.CommandContext(ctx, "ntpq", "-pn").Output(); err == nil {
+		if status := parseNtpqPeers(string(out)); status != nil {
+			return status
+		}
+	}
+	if out, err := exec.CommandContext(ctx, "timedatectl", "show", "-p", "NTPSynchronized", "--value").Output(); err == nil {
+		status := &ClockSync{Source: "systemd", Synchronized: strings.TrimSpace(string(out)) == "yes"}
+		// timesync-status 仅在使用 systemd-timesyncd 时可用
+		if out, err := exec.CommandContext(ctx, "timedatectl", "timesync-status").Output(); err == nil {
+			parseTimesyncStatus(string(out), status)
+		}
+		return status
+	}
+	if server != "" {
+		if status, err := probeNTP(ctx, server); err == nil {
+			return status
+		}
+	}
+	return nil
+}
+
+// parseChronyTracking 解析 chronyc tracking 的输出
+//
+//	Reference ID    : A9FEA97B (169.254.169.123)
+//	Stratum         : 4
+//	System time     : 0.000012345 seconds fast of NTP time
+//	Leap status     : Normal
+func parseChronyTracking(output string) *ClockSync {
+	status := &ClockSync{Source: "chrony"}
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "Reference ID":
+			if i := strings.IndexByte(value, '('); i >= 0 {
+				status.Server = strings.TrimSuffix(value[i+1:], ")")
+			}
+		case "Stratum":
+			status.Stratum, _ = strconv.Atoi(value)
+		case "System time":
+			fields := strings.Fields(value)
+			if len(fields) >= 3 {
+				offset, _ := strconv.ParseFloat(fields[0], 64)
+				if fields[2] == "slow" {
+					offset = -offset
+				}
+				status.Offset = offset
+			}
+		case "Leap status":
+			found = true
+			status.Synchronized = value != "Not synchronised"
+		}
+	}
+	if !found {
+		return nil
+	}
+	return status
+}
+
+// parseNtpqPeers 解析 ntpq -pn 的输出，以 * 开头的行为当前同步源（offset 单位为毫秒）
+//
+//	     remote           refid      st t when poll reach   delay   offset  jitter
+//	==============================================================================
+//	*192.0.2.1       .GPS.            1 u   33   64  377    0.321   -0.012   0.005
+func parseNtpqPeers(output string) *ClockSync {
+	status := &ClockSync{Source: "ntpd"}
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "==") {
+			found = true
+			continue
+		}
+		if !strings.HasPrefix(line, "*") {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) < 9 {
+			continue
+		}
+		status.Synchronized = true
+		status.Server = fields[0]
+		status.Stratum, _ = strconv.Atoi(fields[2])
+		if offset, err := strconv.ParseFloat(fields[8], 64); err == nil {
+			// ntpq 的 offset 为参考时钟减本地时钟
+			status.Offset = -offset / 1000
+		}
+	}
+	if !found {
+		return nil
+	}
+	return status
+}
+
+// parseTimesyncStatus 从 timedatectl timesync-status 的输出中补充服务器、层级和偏差
+//
+//	Server: 192.0.2.1 (ntp.ubuntu.com)
+//	Stratum: 2
+//	Offset: -1.234ms
+func parseTimesyncStatus(output string, status *ClockSync) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Server":
+			status.Server = value
+			status.Source = "timesyncd"
+		case "Stratum":
+			status.Stratum, _ = strconv.Atoi(value)
+		case "Offset":
+			// timesyncd 的 Offset 为参考时钟减本地时钟
+			if d, err := time.ParseDuration(value); err == nil {
+				status.Offset = -d.Seconds()
+			}
+		}
+	}
+}
+
+// ntpEpochOffset NTP 纪元（1900）与 Unix 纪元（1970）相差的秒数
+const ntpEpochOffset = 2208988800
+
+// probeNTP 向 NTP 服务器发送一次 SNTP 请求估算本地时钟偏差
+func probeNTP(ctx context.Context, server string) (*ClockSync, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, 48)
+	req[0] = 0x23 // LI=0, VN=4, Mode=3（客户端）
+	sent := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return nil, err
+	}
+	received := time.Now()
+	if n < 48 || resp[0]&0x07 != 4 {
+		return nil, errors.New("无效的 NTP 响应")
+	}
+	stratum := int(resp[1])
+	if stratum == 0 {
+		return nil, errors.New("NTP 服务器拒绝请求（Kiss-o'-Death）")
+	}
+
+	serverRecv := ntpTime(resp[32:40])
+	serverSent := ntpTime(resp[40:48])
+	// 参考时钟减本地时钟：((t2 - t1) + (t3 - t4)) / 2
+	offset := (serverRecv.Sub(sent) + serverSent.Sub(received)) / 2
+	return &ClockSync{
+		Synchronized: math.Abs(float64(offset)) <= float64(maxClockOffset),
+		Source:       "sntp",
+		Server:       conn.RemoteAddr().String(),
+		Stratum:      stratum,
+		Offset:       -offset.Seconds(),
+	}, nil
+}
+
+// ntpTime 解析 64 位 NTP 时间戳（32 位秒 + 32 位小数）
+func ntpTime(b []byte) time.Time {
+	secs := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+	frac := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(secs, frac*1e9>>32)
+}
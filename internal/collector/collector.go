@@ -2,6 +2,8 @@ package collector
 
 import (
 	"bufio"
+	"context"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
@@ -9,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/runixo/agent/internal/docker"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/host"
@@ -24,9 +27,16 @@ type Collector struct {
 	// 上次采集的网络数据，用于计算速率
 	lastNetworkStats map[string]*NetworkStat
 	lastNetworkTime  time.Time
+	// 网卡详情接口使用的独立基准（见 GetNetworkInterfaces）
+	lastIfaceStats map[string]*ifaceCounters
+	lastIfaceTime  time.Time
 	// 上次采集的磁盘数据
 	lastDiskStats map[string]*DiskStat
 	lastDiskTime  time.Time
+	// 上次采集的累计换入 / 换出字节数
+	lastSwapIn   uint64
+	lastSwapOut  uint64
+	lastSwapTime time.Time
 	// 上次采集的 CPU 数据（用于计算使用率）
 	lastCpuStats *CpuStat
 	lastCpuTime  time.Time
@@ -34,6 +44,29 @@ type Collector struct {
 	cachedMetrics    *Metrics
 	cachedMetricsAt  time.Time
 	cacheValidFor    time.Duration
+	// 进程采样基准（按 PID），用于计算 CPU 与 I/O 增量
+	procMu    sync.Mutex
+	lastProcs map[int32]*procSample
+	// 容器指标（检测到 Docker socket 时启用），为 nil 表示不采集
+	containers *containerSampler
+	// systemd 单元状态（带缓存）
+	units unitCollector
+	// 时钟同步状态（带缓存）
+	clock clockCollector
+	// 软件包清单（后台刷新）
+	packages packageInventory
+	// 公网地址探测（后台刷新，默认不启用）
+	publicIP publicIPDetector
+	// 主机标签，只整体替换不原地修改
+	labels map[string]string
+	// GetMetrics 中 Top 排行的条数，0 表示不附带
+	topN int
+	// GetMetrics 是否附带进程相关指标
+	processMetrics bool
+	// 开销较大的采集结果缓存（见 CacheConfig）
+	processCache snapshot[[]*ProcessInfo]
+	diskCache    snapshot[[]*DiskInfo]
+	connCache    snapshot[*NetworkConnections]
 }
 
 // 对象池：复用 Metrics 和切片，减少 GC 压力
@@ -67,6 +100,10 @@ type NetworkStat struct {
 	BytesRecv   uint64
 	PacketsSent uint64
 	PacketsRecv uint64
+	ErrIn       uint64
+	ErrOut      uint64
+	DropIn      uint64
+	DropOut     uint64
 }
 
 // DiskStat 磁盘统计
@@ -75,6 +112,9 @@ type DiskStat struct {
 	WriteBytes uint64
 	ReadCount  uint64
 	WriteCount uint64
+	ReadTime   uint64 // 累计读耗时（毫秒）
+	WriteTime  uint64 // 累计写耗时（毫秒）
+	IoTime     uint64 // 设备忙碌累计时间（毫秒）
 }
 
 // New 创建采集器
@@ -82,8 +122,17 @@ func New() *Collector {
 	c := &Collector{
 		lastNetworkStats: make(map[string]*NetworkStat),
 		lastDiskStats:    make(map[string]*DiskStat),
-		cacheValidFor:    800 * time.Millisecond, // 800ms 内的重复请求直接返回缓存
+		lastProcs:        make(map[int32]*procSample),
+		containers:       newContainerSampler(docker.DefaultSocket),
+		topN:             5,
+		processMetrics:   true,
 	}
+	defaults := DefaultCacheConfig()
+	c.cacheValidFor = defaults.Metrics
+	c.processCache.ttl = defaults.Processes
+	c.diskCache.ttl = defaults.Disks
+	c.connCache.ttl = defaults.Connections
+
 	// 预热 CPU 采集
 	cpu.Percent(time.Millisecond*100, false)
 	// 初始化 CPU 基准数据
@@ -170,26 +219,21 @@ func (c *Collector) initBaselineStats() {
 	netIO, err := net.IOCounters(true)
 	if err == nil {
 		for _, io := range netIO {
-			c.lastNetworkStats[io.Name] = &NetworkStat{
-				BytesSent:   io.BytesSent,
-				BytesRecv:   io.BytesRecv,
-				PacketsSent: io.PacketsSent,
-				PacketsRecv: io.PacketsRecv,
-			}
+			c.lastNetworkStats[io.Name] = newNetworkStat(io)
 		}
 		c.lastNetworkTime = time.Now()
 	}
 
+	// 交换分区基准
+	if swap, err := mem.SwapMemory(); err == nil {
+		c.lastSwapIn, c.lastSwapOut, c.lastSwapTime = swap.Sin, swap.Sout, time.Now()
+	}
+
 	// 磁盘基准
 	diskIO, err := disk.IOCounters()
 	if err == nil {
 		for name, io := range diskIO {
-			c.lastDiskStats[name] = &DiskStat{
-				ReadBytes:  io.ReadBytes,
-				WriteBytes: io.WriteBytes,
-				ReadCount:  io.ReadCount,
-				WriteCount: io.WriteCount,
-			}
+			c.lastDiskStats[name] = newDiskStat(io)
 		}
 		c.lastDiskTime = time.Now()
 	}
@@ -210,6 +254,13 @@ type SystemInfo struct {
 	Disks           []*DiskInfo
 	Networks        []*NetworkInfo
 	Gpus            []*GpuInfo
+	Units           *UnitSummary // 非 systemd 系统为 nil
+	Logins          *LoginInfo   // 当前会话及最近 10 条登录 / 失败记录
+	Clock           *ClockSync   // 无法确定同步状态时为 nil
+	Distro          *DistroInfo  // 非 Linux 系统为 nil
+	Packages        *PackageInfo // 首次查询完成前或不支持的系统为 nil
+	Labels          map[string]string
+	PublicIP        *PublicIP // 未启用探测或尚未完成首次探测时为 nil
 }
 
 // CpuInfo CPU信息
@@ -241,6 +292,12 @@ type DiskInfo struct {
 	Used        uint64
 	Free        uint64
 	UsedPercent float64
+	// inode 用量；空间充足但 inode 耗尽时同样无法创建文件
+	// 不使用 inode 的文件系统（如 btrfs、部分网络文件系统）总数为 0
+	InodesTotal       uint64
+	InodesUsed        uint64
+	InodesFree        uint64
+	InodesUsedPercent float64
 }
 
 // NetworkInfo 网络信息
@@ -271,15 +328,41 @@ type Metrics struct {
 	Load1          float64
 	Load5          float64
 	Load15         float64
+	SwapTotal      uint64
+	SwapUsed       uint64
+	SwapFree       uint64
+	SwapUsage      float64 // 交换分区使用率（%），未配置交换分区时为 0
+	SwapInRate     uint64  // 换入速率 bytes/s
+	SwapOutRate    uint64  // 换出速率 bytes/s
+	ProcsRunning   int     // 可运行（运行队列中）的进程数
+	ProcsBlocked   int     // 等待 I/O 而阻塞的进程数
+	Uptime         int64
+	BootTime       int64
+	Containers     []*ContainerMetric // 最近一次采集的容器指标，可能滞后数秒
+	Units          *UnitSummary       // systemd 单元状态，非 systemd 系统为 nil
+	Top            *TopProcesses      // CPU / 内存占用最高的进程（来自缓存的进程列表）
+	Zombies        int                // 僵尸进程数
+	StuckProcesses []*StuckProcess    // 长时间处于 D 状态的进程
+	ConntrackCount int64              // 连接跟踪表当前条目数，未加载 nf_conntrack 时为 0
+	ConntrackMax   int64              // 连接跟踪表上限，写满后新连接会被内核丢弃
+	FdAllocated    uint64             // 系统已分配的文件句柄数
+	FdMax          uint64             // 系统文件句柄上限（fs.file-max）
+	FdNearLimit    []*FdUsage         // 文件描述符接近软限制的进程
+	Power          *PowerInfo         // 电池与外部电源状态，没有电源信息的机器为 nil
+	Custom         []*Sample          // 已注册的自定义数据源输出的样本
+	Labels         map[string]string  // 主机标签（只读）
 }
 
 // DiskMetric 磁盘指标（速率，字节/秒）
 type DiskMetric struct {
 	Device     string
-	ReadBytes  uint64 // 读取速率 bytes/s
-	WriteBytes uint64 // 写入速率 bytes/s
-	ReadCount  uint64
-	WriteCount uint64
+	ReadBytes  uint64  // 读取速率 bytes/s
+	WriteBytes uint64  // 写入速率 bytes/s
+	ReadCount  uint64  // 每秒读次数
+	WriteCount uint64  // 每秒写次数
+	Await      float64 // 平均每次 I/O 耗时（毫秒），区间内无 I/O 时为 0
+	Util       float64 // 设备忙碌时间占比（%），接近 100 说明已饱和
+	InFlight   uint64  // 当前正在处理的 I/O 数
 }
 
 // NetworkMetric 网络指标（速率，字节/秒）
@@ -287,8 +370,12 @@ type NetworkMetric struct {
 	Interface   string
 	BytesSent   uint64 // 发送速率 bytes/s
 	BytesRecv   uint64 // 接收速率 bytes/s
-	PacketsSent uint64
-	PacketsRecv uint64
+	PacketsSent uint64 // 每秒发送包数
+	PacketsRecv uint64 // 每秒接收包数
+	ErrIn       uint64 // 每秒接收错误数
+	ErrOut      uint64 // 每秒发送错误数
+	DropIn      uint64 // 每秒接收丢包数
+	DropOut     uint64 // 每秒发送丢包数
 }
 
 // ProcessInfo 进程信息
@@ -303,6 +390,13 @@ type ProcessInfo struct {
 	MemoryRss     uint64
 	CreateTime    int64
 	Cmdline       string
+	NumThreads    int32
+	NumFds        int32   // 打开的文件描述符数量（无权限时为 0）
+	FdLimit       uint64  // 文件描述符软限制（RLIMIT_NOFILE），未知或无限制时为 0
+	IoReadBytes   uint64  // 累计读取字节
+	IoWriteBytes  uint64  // 累计写入字节
+	IoReadRate    float64 // 与上次采样之间的读取速率（字节/秒）
+	IoWriteRate   float64 // 与上次采样之间的写入速率（字节/秒）
 }
 
 // GetSystemInfo 获取系统信息
@@ -323,6 +417,16 @@ func (c *Collector) GetSystemInfo() (*SystemInfo, error) {
 		BootTime:        int64(hostInfo.BootTime),
 	}
 
+	info.Units = c.units.get()
+	info.Clock = c.clock.get()
+	info.Labels = c.Labels()
+	info.PublicIP = c.publicIP.get()
+	info.Distro = readDistroInfo()
+	info.Packages = c.packages.get()
+	if logins, err := c.GetLogins(10); err == nil {
+		info.Logins = logins
+	}
+
 	// CPU 信息
 	cpuInfo, err := c.getCpuInfo()
 	if err == nil {
@@ -336,7 +440,7 @@ func (c *Collector) GetSystemInfo() (*SystemInfo, error) {
 	}
 
 	// 磁盘信息
-	diskInfo, err := c.getDiskInfo()
+	diskInfo, err := c.GetDisks()
 	if err == nil {
 		info.Disks = diskInfo
 	}
@@ -406,6 +510,11 @@ func (c *Collector) getMemoryInfo() (*MemoryInfo, error) {
 	return info, nil
 }
 
+// GetDisks 获取各分区的容量使用情况（缓存时长内返回同一快照，调用方不应修改）
+func (c *Collector) GetDisks() ([]*DiskInfo, error) {
+	return c.diskCache.get(c.getDiskInfo)
+}
+
 func (c *Collector) getDiskInfo() ([]*DiskInfo, error) {
 	partitions, err := disk.Partitions(false)
 	if err != nil {
@@ -427,6 +536,11 @@ func (c *Collector) getDiskInfo() ([]*DiskInfo, error) {
 			Used:        usage.Used,
 			Free:        usage.Free,
 			UsedPercent: usage.UsedPercent,
+
+			InodesTotal:       usage.InodesTotal,
+			InodesUsed:        usage.InodesUsed,
+			InodesFree:        usage.InodesFree,
+			InodesUsedPercent: usage.InodesUsedPercent,
 		})
 	}
 
@@ -469,6 +583,33 @@ func (c *Collector) getNetworkInfo() ([]*NetworkInfo, error) {
 	return networks, nil
 }
 
+// SetTopN 设置 GetMetrics 附带的进程排行条数，0 表示不附带
+func (c *Collector) SetTopN(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topN = n
+}
+
+// SetProcessMetrics 设置 GetMetrics 是否附带进程相关指标（Top 排行、僵尸 / D 状态进程）
+// 这些指标需要遍历进程列表，关闭后 GetMetrics 不再触发进程采集
+func (c *Collector) SetProcessMetrics(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.processMetrics = enabled
+}
+
+// SetContainerMetrics 设置 GetMetrics 是否附带容器指标
+// 只需要主机指标的使用方（如历史采样）可以关闭，避免额外请求 Docker
+func (c *Collector) SetContainerMetrics(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !enabled {
+		c.containers = nil
+	} else if c.containers == nil {
+		c.containers = newContainerSampler(docker.DefaultSocket)
+	}
+}
+
 // GetMetrics 获取监控指标（返回速率而非累计值）
 func (c *Collector) GetMetrics() (*Metrics, error) {
 	c.mu.Lock()
@@ -493,6 +634,20 @@ func (c *Collector) GetMetrics() (*Metrics, error) {
 		metrics.MemoryUsage = vmem.UsedPercent
 	}
 
+	// 交换分区：持续的换入换出比使用率更能说明内存压力
+	if swap, err := mem.SwapMemory(); err == nil {
+		metrics.SwapTotal = swap.Total
+		metrics.SwapUsed = swap.Used
+		metrics.SwapFree = swap.Free
+		metrics.SwapUsage = swap.UsedPercent
+		if !c.lastSwapTime.IsZero() {
+			elapsed := now.Sub(c.lastSwapTime).Seconds()
+			metrics.SwapInRate = counterRate(c.lastSwapIn, swap.Sin, elapsed)
+			metrics.SwapOutRate = counterRate(c.lastSwapOut, swap.Sout, elapsed)
+		}
+		c.lastSwapIn, c.lastSwapOut, c.lastSwapTime = swap.Sin, swap.Sout, now
+	}
+
 	// 负载
 	loadAvg, err := load.Avg()
 	if err == nil {
@@ -500,6 +655,24 @@ func (c *Collector) GetMetrics() (*Metrics, error) {
 		metrics.Load5 = loadAvg.Load5
 		metrics.Load15 = loadAvg.Load15
 	}
+	// 连接跟踪表：写满时新连接被静默丢弃，表现为服务不可达但 CPU 空闲
+	metrics.ConntrackCount, metrics.ConntrackMax = 0, 0
+	if filters, err := net.FilterCounters(); err == nil && len(filters) > 0 {
+		metrics.ConntrackCount = filters[0].ConnTrackCount
+		metrics.ConntrackMax = filters[0].ConnTrackMax
+	}
+
+	metrics.FdAllocated, metrics.FdMax = readFileNr()
+	metrics.Power = readPowerInfo()
+
+	if misc, err := load.Misc(); err == nil {
+		metrics.ProcsRunning = misc.ProcsRunning
+		metrics.ProcsBlocked = misc.ProcsBlocked
+	}
+	if bootTime, err := host.BootTime(); err == nil {
+		metrics.BootTime = int64(bootTime)
+		metrics.Uptime = now.Unix() - int64(bootTime)
+	}
 
 	// 磁盘 IO（计算速率）
 	diskIO, err := disk.IOCounters()
@@ -507,18 +680,16 @@ func (c *Collector) GetMetrics() (*Metrics, error) {
 		elapsed := now.Sub(c.lastDiskTime).Seconds()
 		if elapsed > 0 {
 			for name, io := range diskIO {
-				dm := &DiskMetric{Device: name}
+				if isVirtualDisk(name) {
+					continue
+				}
+				cur := newDiskStat(io)
+				dm := &DiskMetric{Device: name, InFlight: io.IopsInProgress}
 				if last, ok := c.lastDiskStats[name]; ok {
-					dm.ReadBytes = uint64(float64(io.ReadBytes-last.ReadBytes) / elapsed)
-					dm.WriteBytes = uint64(float64(io.WriteBytes-last.WriteBytes) / elapsed)
-					dm.ReadCount = uint64(float64(io.ReadCount-last.ReadCount) / elapsed)
-					dm.WriteCount = uint64(float64(io.WriteCount-last.WriteCount) / elapsed)
+					fillDiskRates(dm, cur, last, elapsed)
 				}
 				metrics.DiskMetrics = append(metrics.DiskMetrics, dm)
-				c.lastDiskStats[name] = &DiskStat{
-					ReadBytes: io.ReadBytes, WriteBytes: io.WriteBytes,
-					ReadCount: io.ReadCount, WriteCount: io.WriteCount,
-				}
+				c.lastDiskStats[name] = cur
 			}
 		}
 		c.lastDiskTime = now
@@ -530,23 +701,41 @@ func (c *Collector) GetMetrics() (*Metrics, error) {
 		elapsed := now.Sub(c.lastNetworkTime).Seconds()
 		if elapsed > 0 {
 			for _, io := range netIO {
+				cur := newNetworkStat(io)
 				nm := &NetworkMetric{Interface: io.Name}
 				if last, ok := c.lastNetworkStats[io.Name]; ok {
-					nm.BytesSent = uint64(float64(io.BytesSent-last.BytesSent) / elapsed)
-					nm.BytesRecv = uint64(float64(io.BytesRecv-last.BytesRecv) / elapsed)
-					nm.PacketsSent = uint64(float64(io.PacketsSent-last.PacketsSent) / elapsed)
-					nm.PacketsRecv = uint64(float64(io.PacketsRecv-last.PacketsRecv) / elapsed)
+					fillNetworkRates(nm, cur, last, elapsed)
 				}
 				metrics.NetworkMetrics = append(metrics.NetworkMetrics, nm)
-				c.lastNetworkStats[io.Name] = &NetworkStat{
-					BytesSent: io.BytesSent, BytesRecv: io.BytesRecv,
-					PacketsSent: io.PacketsSent, PacketsRecv: io.PacketsRecv,
-				}
+				c.lastNetworkStats[io.Name] = cur
 			}
 		}
 		c.lastNetworkTime = now
 	}
 
+	metrics.Units = c.units.get()
+	metrics.Top = nil
+	metrics.Zombies = 0
+	metrics.StuckProcesses = nil
+	metrics.FdNearLimit = nil
+	if c.processMetrics {
+		if processes, err := c.ListProcesses(); err == nil {
+			if c.topN > 0 {
+				metrics.Top = topProcesses(processes, c.topN)
+			}
+			health := c.processHealth(processes, now)
+			metrics.Zombies = health.Zombies
+			metrics.StuckProcesses = health.Stuck
+			metrics.FdNearLimit = health.FdNearLimit
+		}
+	}
+	metrics.Custom = collectSources(context.Background())
+	metrics.Labels = c.labels
+	metrics.Containers = nil
+	if c.containers != nil {
+		metrics.Containers = c.containers.snapshot()
+	}
+
 	// 更新缓存
 	c.cachedMetrics = metrics
 	c.cachedMetricsAt = now
@@ -554,46 +743,89 @@ func (c *Collector) GetMetrics() (*Metrics, error) {
 	return metrics, nil
 }
 
-// ListProcesses 列出进程
+// ListProcesses 列出进程（缓存时长内返回同一快照）
+// 返回切片的副本，调用方可以自行排序和截取，但不应修改其中的元素
 func (c *Collector) ListProcesses() ([]*ProcessInfo, error) {
+	processes, err := c.processCache.get(c.listProcesses)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*ProcessInfo(nil), processes...), nil
+}
+
+func (c *Collector) listProcesses() ([]*ProcessInfo, error) {
 	procs, err := process.Processes()
 	if err != nil {
 		return nil, err
 	}
 
-	var processes []*ProcessInfo
+	now := time.Now()
+	processes := make([]*ProcessInfo, 0, len(procs))
+	seen := make(map[int32]bool, len(procs))
 	for _, p := range procs {
-		name, _ := p.Name()
-		user, _ := p.Username()
-		status, _ := p.Status()
-		cpuPercent, _ := p.CPUPercent()
-		memPercent, _ := p.MemoryPercent()
-		memInfo, _ := p.MemoryInfo()
-		createTime, _ := p.CreateTime()
-		cmdline, _ := p.Cmdline()
-		ppid, _ := p.Ppid()
-
-		procInfo := &ProcessInfo{
-			Pid:           p.Pid,
-			Ppid:          ppid,
-			Name:          name,
-			User:          user,
-			CpuPercent:    cpuPercent,
-			MemoryPercent: float64(memPercent),
-			CreateTime:    createTime,
-			Cmdline:       cmdline,
-		}
+		processes = append(processes, c.buildProcessInfo(p, now))
+		seen[p.Pid] = true
+	}
+	c.pruneProcSamples(seen)
 
-		if len(status) > 0 {
-			procInfo.Status = status[0]
-		}
+	return processes, nil
+}
 
-		if memInfo != nil {
-			procInfo.MemoryRss = memInfo.RSS
-		}
+func newDiskStat(io disk.IOCountersStat) *DiskStat {
+	return &DiskStat{
+		ReadBytes:  io.ReadBytes,
+		WriteBytes: io.WriteBytes,
+		ReadCount:  io.ReadCount,
+		WriteCount: io.WriteCount,
+		ReadTime:   io.ReadTime,
+		WriteTime:  io.WriteTime,
+		IoTime:     io.IoTime,
+	}
+}
+
+// fillDiskRates 由两次采样计算速率、await 与 util（同 iostat -x 的算法）
+func fillDiskRates(dm *DiskMetric, cur, last *DiskStat, elapsed float64) {
+	dm.ReadBytes = counterRate(last.ReadBytes, cur.ReadBytes, elapsed)
+	dm.WriteBytes = counterRate(last.WriteBytes, cur.WriteBytes, elapsed)
+	dm.ReadCount = counterRate(last.ReadCount, cur.ReadCount, elapsed)
+	dm.WriteCount = counterRate(last.WriteCount, cur.WriteCount, elapsed)
 
-		processes = append(processes, procInfo)
+	if cur.ReadCount < last.ReadCount || cur.WriteCount < last.WriteCount ||
+		cur.ReadTime < last.ReadTime || cur.WriteTime < last.WriteTime || cur.IoTime < last.IoTime {
+		return // 计数器重置（设备重新挂载等）
 	}
+	if ops := (cur.ReadCount - last.ReadCount) + (cur.WriteCount - last.WriteCount); ops > 0 {
+		dm.Await = float64((cur.ReadTime-last.ReadTime)+(cur.WriteTime-last.WriteTime)) / float64(ops)
+	}
+	dm.Util = math.Min(100, float64(cur.IoTime-last.IoTime)/(elapsed*1000)*100)
+}
 
-	return processes, nil
+// isVirtualDisk 过滤 loop、ram 等没有实际磁盘意义的设备
+func isVirtualDisk(name string) bool {
+	return strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram")
+}
+
+func newNetworkStat(io net.IOCountersStat) *NetworkStat {
+	return &NetworkStat{
+		BytesSent:   io.BytesSent,
+		BytesRecv:   io.BytesRecv,
+		PacketsSent: io.PacketsSent,
+		PacketsRecv: io.PacketsRecv,
+		ErrIn:       io.Errin,
+		ErrOut:      io.Errout,
+		DropIn:      io.Dropin,
+		DropOut:     io.Dropout,
+	}
+}
+
+// fillNetworkRates 由两次采样计算网卡各计数器的每秒速率
+func fillNetworkRates(nm *NetworkMetric, cur, last *NetworkStat, elapsed float64) {
+	nm.BytesSent = counterRate(last.BytesSent, cur.BytesSent, elapsed)
+	nm.BytesRecv = counterRate(last.BytesRecv, cur.BytesRecv, elapsed)
+	nm.PacketsSent = counterRate(last.PacketsSent, cur.PacketsSent, elapsed)
+	nm.PacketsRecv = counterRate(last.PacketsRecv, cur.PacketsRecv, elapsed)
+	nm.ErrIn = counterRate(last.ErrIn, cur.ErrIn, elapsed)
+	nm.ErrOut = counterRate(last.ErrOut, cur.ErrOut, elapsed)
+	nm.DropIn = counterRate(last.DropIn, cur.DropIn, elapsed)
+	nm.DropOut = counterRate(last.DropOut, cur.DropOut, elapsed)
 }
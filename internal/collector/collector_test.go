@@ -1,7 +1,21 @@
 package collector
 
 import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
+
+	gnet "github.com/shirou/gopsutil/v3/net"
 )
 
 func TestNew(t *testing.T) {
@@ -74,6 +88,14 @@ func TestGetMetrics(t *testing.T) {
 	if metrics.MemoryUsage < 0 || metrics.MemoryUsage > 100 {
 		t.Errorf("MemoryUsage out of range: %f", metrics.MemoryUsage)
 	}
+
+	if metrics.SwapUsed > metrics.SwapTotal {
+		t.Errorf("SwapUsed %d exceeds SwapTotal %d", metrics.SwapUsed, metrics.SwapTotal)
+	}
+
+	if metrics.BootTime <= 0 || metrics.Uptime <= 0 {
+		t.Errorf("expected boot time and uptime, got %d / %d", metrics.BootTime, metrics.Uptime)
+	}
 }
 
 func TestListProcesses(t *testing.T) {
@@ -114,3 +136,806 @@ func TestListProcesses(t *testing.T) {
 
 	t.Logf("Found %d total processes, %d with valid names", len(processes), validProcessCount)
 }
+
+func TestGetNetworkInterfaces(t *testing.T) {
+	c := New()
+	ifaces, err := c.GetNetworkInterfaces()
+	if err != nil {
+		t.Fatalf("GetNetworkInterfaces() error: %v", err)
+	}
+
+	for _, iface := range ifaces {
+		if iface.Name == "" {
+			t.Error("Interface name is empty")
+		}
+	}
+
+	// 第二次采集应能计算出速率且不出错
+	if _, err := c.GetNetworkInterfaces(); err != nil {
+		t.Fatalf("second GetNetworkInterfaces() error: %v", err)
+	}
+}
+
+func TestGetProcessDetail(t *testing.T) {
+	c := New()
+	detail, err := c.GetProcessDetail(int32(os.Getpid()), EnvRedacted)
+	if err != nil {
+		t.Fatalf("GetProcessDetail() error: %v", err)
+	}
+	if detail.Pid != int32(os.Getpid()) {
+		t.Errorf("Pid = %d, want %d", detail.Pid, os.Getpid())
+	}
+	if detail.NumThreads <= 0 {
+		t.Error("NumThreads should be > 0")
+	}
+
+	if _, err := c.GetProcessDetail(1<<30, EnvNone); err != ErrProcessNotFound {
+		t.Errorf("expected ErrProcessNotFound, got %v", err)
+	}
+}
+
+func TestRedactEnviron(t *testing.T) {
+	env := redactEnviron([]string{"PATH=/usr/bin", "API_TOKEN=abc", "DB_PASSWORD=x=y"})
+	if env[0] != "PATH=/usr/bin" {
+		t.Errorf("PATH should not be redacted: %s", env[0])
+	}
+	for _, kv := range env[1:] {
+		if !strings.HasSuffix(kv, "=******") {
+			t.Errorf("expected redacted value: %s", kv)
+		}
+	}
+}
+
+func TestHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	config := HistoryConfig{Dir: dir, SampleInterval: 10 * time.Second, Resolution: time.Minute, Retention: time.Hour}
+	h, err := NewHistoryStore(nil, config)
+	if err != nil {
+		t.Fatalf("NewHistoryStore() error: %v", err)
+	}
+
+	base := time.Now().Truncate(2 * time.Minute).Add(-30 * time.Minute)
+	for i := 0; i < 12; i++ {
+		// 每分钟 6 个采样，CPU 依次为 0..50
+		cpu := float64(i%6) * 10
+		p := HistoryPoint{CpuUsage: cpu, CpuMax: cpu, MemoryUsage: 40}
+		// 自定义序列只出现在第二分钟的偶数次采样中：6、8、10
+		if i >= 6 && i%2 == 0 {
+			p.Custom = map[string]float64{"app:queue_depth": float64(i)}
+		}
+		h.add(base.Add(time.Duration(i)*10*time.Second), p)
+	}
+	h.Stop()
+
+	points := h.Latest(10)
+	if len(points) != 2 {
+		t.Fatalf("expected 2 aggregated points, got %d", len(points))
+	}
+	if points[0].CpuUsage != 25 || points[0].CpuMax != 50 || points[0].Timestamp != base.Unix() {
+		t.Errorf("unexpected aggregate: %+v", points[0])
+	}
+	if points[0].Custom != nil || points[1].Custom["app:queue_depth"] != 8 {
+		t.Errorf("custom series should be averaged over its own samples: %+v / %+v", points[0].Custom, points[1].Custom)
+	}
+
+	merged := h.Query(base, base.Add(time.Hour), 2*time.Minute)
+	if len(merged) != 1 || merged[0].MemoryUsage != 40 {
+		t.Errorf("expected 1 downsampled point, got %+v", merged)
+	}
+
+	// 重新打开时应从磁盘分段恢复
+	reopened, err := NewHistoryStore(nil, config)
+	if err != nil {
+		t.Fatalf("reopen error: %v", err)
+	}
+	if got := reopened.Latest(10); len(got) != 2 || !reflect.DeepEqual(got[1], points[1]) {
+		t.Errorf("expected segments to be reloaded, got %+v", got)
+	}
+
+	if _, err := NewHistoryStore(nil, HistoryConfig{SampleInterval: time.Minute, Resolution: time.Second}); err == nil {
+		t.Error("expected error when resolution < sample interval")
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	cases := map[string]time.Duration{"7d": 7 * 24 * time.Hour, "36h": 36 * time.Hour, " 1d ": 24 * time.Hour}
+	for in, want := range cases {
+		if got, err := ParseRetention(in); err != nil || got != want {
+			t.Errorf("ParseRetention(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0d", "xd", "-1h"} {
+		if _, err := ParseRetention(in); err == nil {
+			t.Errorf("ParseRetention(%q) should fail", in)
+		}
+	}
+}
+
+func TestProcessDeltas(t *testing.T) {
+	c := New()
+	if _, err := c.ListProcesses(); err != nil {
+		t.Fatalf("ListProcesses() error: %v", err)
+	}
+	// 第二次采样应基于上一次的基准计算增量
+	time.Sleep(50 * time.Millisecond)
+	processes, err := c.ListProcesses()
+	if err != nil {
+		t.Fatalf("ListProcesses() error: %v", err)
+	}
+
+	var self *ProcessInfo
+	for _, p := range processes {
+		if p.Pid == int32(os.Getpid()) {
+			self = p
+		}
+		if p.CpuPercent < 0 || p.IoReadRate < 0 || p.IoWriteRate < 0 {
+			t.Errorf("negative rate for pid %d: %+v", p.Pid, p)
+		}
+	}
+	if self == nil {
+		t.Fatal("current process not found")
+	}
+	if self.NumThreads <= 0 {
+		t.Error("NumThreads should be > 0")
+	}
+	if _, ok := c.lastProcs[self.Pid]; !ok {
+		t.Error("expected sample baseline for current process")
+	}
+}
+
+func TestFillDiskRates(t *testing.T) {
+	last := &DiskStat{ReadCount: 100, WriteCount: 100, ReadTime: 1000, WriteTime: 1000, IoTime: 5000}
+	cur := &DiskStat{ReadBytes: 4096, ReadCount: 110, WriteCount: 110, ReadTime: 1050, WriteTime: 1150, IoTime: 5500}
+
+	dm := &DiskMetric{}
+	fillDiskRates(dm, cur, last, 1)
+	if dm.Await != 10 {
+		t.Errorf("Await = %v, want 10", dm.Await)
+	}
+	if dm.Util != 50 {
+		t.Errorf("Util = %v, want 50", dm.Util)
+	}
+	if dm.ReadBytes != 4096 || dm.ReadCount != 10 {
+		t.Errorf("unexpected rates: %+v", dm)
+	}
+
+	// 计数器回退时不应产生巨大的速率
+	dm = &DiskMetric{}
+	fillDiskRates(dm, last, cur, 1)
+	if dm.ReadCount != 0 || dm.Await != 0 || dm.Util != 0 {
+		t.Errorf("expected zero after counter reset, got %+v", dm)
+	}
+}
+
+func TestFillNetworkRates(t *testing.T) {
+	last := &NetworkStat{BytesRecv: 1000, ErrIn: 5, DropOut: 10}
+	cur := &NetworkStat{BytesRecv: 3000, ErrIn: 9, DropOut: 4}
+
+	nm := &NetworkMetric{}
+	fillNetworkRates(nm, cur, last, 2)
+	if nm.BytesRecv != 1000 || nm.ErrIn != 2 {
+		t.Errorf("unexpected rates: %+v", nm)
+	}
+	if nm.DropOut != 0 {
+		t.Errorf("counter reset should yield 0, got %d", nm.DropOut)
+	}
+}
+
+func TestContainerSampler(t *testing.T) {
+	dir, err := os.MkdirTemp("", "dk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix socket unavailable: %v", err)
+	}
+
+	var rx atomic.Uint64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.30/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"Id":"0123456789abcdef","Names":["/web"],"Image":"nginx","State":"running"},
+			{"Id":"fedcba9876543210","Names":["/job"],"Image":"busybox","State":"exited"}]`)
+	})
+	mux.HandleFunc("/v1.30/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RestartCount":3}`)
+	})
+	mux.HandleFunc("/v1.30/containers/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"memory_stats":{"usage":2048,"limit":4096},"networks":{"eth0":{"rx_bytes":%d}}}`, rx.Add(10000))
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	s := newContainerSampler(socket)
+	if got := s.snapshot(); got != nil {
+		t.Fatalf("first snapshot should be empty, got %+v", got)
+	}
+	waitRefresh := func() {
+		for i := 0; i < 100; i++ {
+			s.mu.Lock()
+			done := !s.refreshing
+			s.mu.Unlock()
+			if done {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("refresh did not finish")
+	}
+	waitRefresh()
+
+	// 第二次刷新后才有网络速率
+	s.mu.Lock()
+	s.refreshedAt = s.refreshedAt.Add(-containerRefreshInterval)
+	s.mu.Unlock()
+	s.snapshot()
+	waitRefresh()
+
+	metrics := s.snapshot()
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(metrics))
+	}
+	web := metrics[0]
+	if web.Name != "web" || web.Image != "nginx" || web.RestartCount != 3 || web.MemoryUsage != 2048 || web.MemoryPercent != 50 {
+		t.Errorf("unexpected container metric: %+v", web)
+	}
+	if web.NetworkRx == 0 {
+		t.Error("expected non-zero network rate after second refresh")
+	}
+	if metrics[1].State != "exited" || metrics[1].RestartCount != 3 {
+		t.Errorf("stopped container should still report restarts: %+v", metrics[1])
+	}
+
+	if newContainerSampler(filepath.Join(dir, "missing.sock")).snapshot() != nil {
+		t.Error("expected nil without docker socket")
+	}
+}
+
+func TestParseUnitList(t *testing.T) {
+	output := `nginx.service loaded active running A high performance web server
+● backup.service loaded failed failed Nightly backup
+proc-sys-fs-binfmt_misc.mount loaded inactive dead Arbitrary Executable File Formats
+apt-daily.timer loaded activating waiting Daily apt download activities
+mnt-data.mount loaded failed failed /mnt/data
+`
+	s := parseUnitList(output)
+	if s.Total != 5 || s.Active != 1 || s.Inactive != 1 || s.Activating != 1 || s.Failed != 2 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if strings.Join(s.FailedUnits, ",") != "backup.service,mnt-data.mount" {
+		t.Errorf("unexpected failed units: %v", s.FailedUnits)
+	}
+}
+
+func TestSummarizeConnections(t *testing.T) {
+	tcp := func(lport, rport uint32, status string, pid int32) gnet.ConnectionStat {
+		c := gnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Status: status, Pid: pid}
+		c.Laddr.IP, c.Laddr.Port = "0.0.0.0", lport
+		if rport > 0 {
+			c.Raddr.IP, c.Raddr.Port = "10.0.0.2", rport
+		}
+		return c
+	}
+	udp := gnet.ConnectionStat{Family: syscall.AF_INET6, Type: syscall.SOCK_DGRAM, Pid: 7}
+	udp.Laddr.IP, udp.Laddr.Port = "::", 53
+
+	conns := []gnet.ConnectionStat{
+		tcp(443, 0, "LISTEN", 42),
+		tcp(443, 0, "LISTEN", 42), // SO_REUSEPORT
+		tcp(443, 50000, "ESTABLISHED", 42),
+		tcp(443, 50001, "ESTABLISHED", 42),
+		tcp(443, 50002, "TIME_WAIT", 0),
+		tcp(22, 0, "LISTEN", 0),
+		udp,
+	}
+	lookups := 0
+	result := summarizeConnections(conns, func(pid int32) string {
+		lookups++
+		return map[int32]string{42: "nginx", 7: "dnsmasq"}[pid]
+	})
+
+	if len(result.Listening) != 3 {
+		t.Fatalf("expected 3 listening sockets, got %d", len(result.Listening))
+	}
+	ssh, dns, https := result.Listening[0], result.Listening[1], result.Listening[2]
+	if ssh.Port != 22 || ssh.Process != "" {
+		t.Errorf("unexpected ssh entry: %+v", ssh)
+	}
+	if dns.Protocol != "udp6" || dns.Process != "dnsmasq" {
+		t.Errorf("unexpected udp entry: %+v", dns)
+	}
+	if https.Process != "nginx" || https.Established != 2 {
+		t.Errorf("unexpected https entry: %+v", https)
+	}
+	if result.StateCounts["ESTABLISHED"] != 2 || result.StateCounts["TIME_WAIT"] != 1 || result.Total != 7 {
+		t.Errorf("unexpected counts: %+v total=%d", result.StateCounts, result.Total)
+	}
+	if lookups != 2 {
+		t.Errorf("expected one name lookup per pid, got %d", lookups)
+	}
+}
+
+type recordingPublisher struct {
+	events []string
+}
+
+func (p *recordingPublisher) Publish(eventType string, data any) {
+	p.events = append(p.events, eventType)
+}
+
+func TestLoginWatcher(t *testing.T) {
+	pub := &recordingPublisher{}
+	w := NewLoginWatcher(pub)
+	existing := &LoginSession{User: "alice", Terminal: "pts/0", Started: 100}
+	w.known = map[LoginSession]bool{*existing: true}
+
+	w.check([]*LoginSession{existing})
+	if len(pub.events) != 0 {
+		t.Fatalf("existing session should not publish, got %v", pub.events)
+	}
+
+	w.check([]*LoginSession{existing, {User: "bob", Terminal: "pts/1", Host: "10.0.0.5", Started: 200}})
+	if len(pub.events) != 1 || pub.events[0] != "login" {
+		t.Fatalf("expected one login event, got %v", pub.events)
+	}
+
+	// 会话结束后重新登录（不同的开始时间）应再次通知
+	w.check([]*LoginSession{existing})
+	w.check([]*LoginSession{existing, {User: "bob", Terminal: "pts/1", Host: "10.0.0.5", Started: 300}})
+	if len(pub.events) != 2 {
+		t.Errorf("expected second login event, got %v", pub.events)
+	}
+}
+
+func TestSnapshotCache(t *testing.T) {
+	var fetches atomic.Int32
+	s := &snapshot[int]{ttl: time.Hour}
+	fetch := func() (int, error) {
+		time.Sleep(20 * time.Millisecond)
+		return int(fetches.Add(1)), nil
+	}
+
+	// 并发请求只触发一次采集
+	done := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			v, _ := s.get(fetch)
+			done <- v
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		if v := <-done; v != 1 {
+			t.Errorf("expected cached value 1, got %d", v)
+		}
+	}
+
+	s.setTTL(0)
+	if v, _ := s.get(fetch); v != 2 {
+		t.Errorf("expected refresh after ttl, got %d", v)
+	}
+
+	// 失败结果不缓存
+	if _, err := s.get(func() (int, error) { return 0, fmt.Errorf("boom") }); err == nil {
+		t.Error("expected error")
+	}
+	if s.value != 2 {
+		t.Errorf("error should not replace cached value, got %d", s.value)
+	}
+}
+
+func TestTopProcesses(t *testing.T) {
+	processes := []*ProcessInfo{
+		{Pid: 1, Name: "init", CpuPercent: 0.1, MemoryRss: 10},
+		{Pid: 2, Name: "java", CpuPercent: 5, MemoryRss: 9000},
+		{Pid: 3, Name: "cron", CpuPercent: 90, MemoryRss: 20},
+	}
+	top := topProcesses(processes, 2)
+	if len(top.ByCpu) != 2 || top.ByCpu[0].Name != "cron" || top.ByCpu[0].Value != 90 || top.ByCpu[1].Name != "java" {
+		t.Errorf("unexpected ByCpu: %+v %+v", top.ByCpu[0], top.ByCpu[1])
+	}
+	if len(top.ByMemory) != 2 || top.ByMemory[0].Name != "java" || top.ByMemory[0].Value != 9000 {
+		t.Errorf("unexpected ByMemory: %+v", top.ByMemory[0])
+	}
+	if processes[0].Pid != 1 {
+		t.Error("topProcesses should not reorder the input")
+	}
+	if SortProcesses(processes, "pid") {
+		t.Error("unsupported sort key should be rejected")
+	}
+}
+
+func TestBuildProcessTree(t *testing.T) {
+	processes := []*ProcessInfo{
+		{Pid: 1, Ppid: 0, Name: "systemd", CpuPercent: 1, MemoryRss: 100},
+		{Pid: 20, Ppid: 1, Name: "cron", CpuPercent: 1, MemoryRss: 10},
+		{Pid: 21, Ppid: 20, Name: "sh", CpuPercent: 30, MemoryRss: 5},
+		{Pid: 22, Ppid: 21, Name: "sh", CpuPercent: 30, MemoryRss: 5},
+		{Pid: 10, Ppid: 1, Name: "nginx", CpuPercent: 2, MemoryRss: 50},
+		{Pid: 99, Ppid: 98, Name: "orphan"},
+	}
+
+	roots := buildProcessTree(processes, false)
+	if len(roots) != 2 || roots[0].Pid != 1 || roots[1].Pid != 99 {
+		t.Fatalf("unexpected roots: %+v", roots)
+	}
+	if roots[0].TotalCpuPercent != 0 {
+		t.Error("totals should be empty without aggregation")
+	}
+
+	roots = buildProcessTree(processes, true)
+	root := roots[0]
+	if len(root.Children) != 2 || root.Children[0].Name != "nginx" || root.Children[1].Name != "cron" {
+		t.Fatalf("children should be sorted by pid: %+v", root.Children)
+	}
+	cron := root.Children[1]
+	if cron.TotalCpuPercent != 61 || cron.TotalMemoryRss != 20 || cron.Descendants != 2 {
+		t.Errorf("unexpected cron totals: cpu=%v rss=%v descendants=%d", cron.TotalCpuPercent, cron.TotalMemoryRss, cron.Descendants)
+	}
+	if root.Descendants != 4 || root.TotalCpuPercent != 64 {
+		t.Errorf("unexpected root totals: cpu=%v descendants=%d", root.TotalCpuPercent, root.Descendants)
+	}
+}
+
+func TestProcessHealth(t *testing.T) {
+	c := New()
+	now := time.Now()
+	c.lastProcs[10] = &procSample{blockedSince: now.Add(-5 * time.Minute)}
+	c.lastProcs[11] = &procSample{blockedSince: now.Add(-10 * time.Second)}
+	processes := []*ProcessInfo{
+		{Pid: 10, Name: "rsync", Status: "blocked"},
+		{Pid: 11, Name: "cp", Status: "blocked"},
+		{Pid: 12, Name: "sh", Status: "zombie"},
+		{Pid: 13, Name: "sh", Status: "zombie"},
+		{Pid: 14, Name: "nginx", Status: "sleep"},
+	}
+
+	health := c.processHealth(processes, now)
+	if health.Zombies != 2 {
+		t.Errorf("expected 2 zombies, got %d", health.Zombies)
+	}
+	if len(health.Stuck) != 1 || health.Stuck[0].Pid != 10 || health.Stuck[0].Duration < 299 {
+		t.Fatalf("only pid 10 should be stuck, got %+v", health.Stuck)
+	}
+
+	pub := &recordingPublisher{}
+	w := NewProcessWatcher(c, pub)
+	w.check(health)
+	w.check(health)
+	if len(pub.events) != 1 || pub.events[0] != "alert" {
+		t.Fatalf("stuck process should alert once, got %v", pub.events)
+	}
+
+	health.Zombies = zombieAlertThreshold
+	w.check(health)
+	w.check(health)
+	if len(pub.events) != 2 {
+		t.Fatalf("zombie threshold should alert once, got %v", pub.events)
+	}
+}
+
+func TestParseClockSync(t *testing.T) {
+	chrony := parseChronyTracking(`Reference ID    : A9FEA97B (169.254.169.123)
+Stratum         : 4
+System time     : 0.002500000 seconds slow of NTP time
+Leap status     : Normal
+`)
+	if chrony == nil || !chrony.Synchronized || chrony.Server != "169.254.169.123" || chrony.Stratum != 4 || chrony.Offset != -0.0025 {
+		t.Errorf("unexpected chrony status: %+v", chrony)
+	}
+	if s := parseChronyTracking("Leap status     : Not synchronised\n"); s == nil || s.Synchronized {
+		t.Errorf("unsynchronised chrony should be reported: %+v", s)
+	}
+
+	ntpq := parseNtpqPeers(`     remote           refid      st t when poll reach   delay   offset  jitter
+==============================================================================
++192.0.2.2       .GPS.            1 u   40   64  377    0.400    3.000   0.010
+*192.0.2.1       .GPS.            1 u   33   64  377    0.321  -12.000   0.005
+`)
+	if ntpq == nil || !ntpq.Synchronized || ntpq.Server != "192.0.2.1" || ntpq.Offset != 0.012 {
+		t.Errorf("unexpected ntpq status: %+v", ntpq)
+	}
+
+	status := &ClockSync{Source: "systemd", Synchronized: true}
+	parseTimesyncStatus("       Server: 192.0.2.1 (ntp.ubuntu.com)\n      Stratum: 2\n       Offset: +1.5ms\n", status)
+	if status.Source != "timesyncd" || status.Stratum != 2 || status.Offset != -0.0015 {
+		t.Errorf("unexpected timesyncd status: %+v", status)
+	}
+}
+
+func TestProbeNTP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("udp not available:", err)
+	}
+	defer conn.Close()
+
+	// 服务器时钟比本地快 3 秒
+	go func() {
+		buf := make([]byte, 48)
+		_, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		resp := make([]byte, 48)
+		resp[0] = 0x24 // VN=4, Mode=4（服务器）
+		resp[1] = 2
+		now := time.Now().Add(3 * time.Second)
+		secs := uint32(now.Unix() + ntpEpochOffset)
+		frac := uint32(uint64(now.Nanosecond()) << 32 / 1e9)
+		for _, off := range []int{32, 40} {
+			binary.BigEndian.PutUint32(resp[off:], secs)
+			binary.BigEndian.PutUint32(resp[off+4:], frac)
+		}
+		conn.WriteTo(resp, addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	status, err := probeNTP(ctx, conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("probeNTP failed: %v", err)
+	}
+	if status.Synchronized || status.Offset > -2.9 || status.Offset < -3.1 || status.Stratum != 2 {
+		t.Errorf("unexpected probe result: %+v", status)
+	}
+}
+
+func TestFdUsage(t *testing.T) {
+	if allocated, limit := parseFileNr("9344\t0\t9223372036854775807\n"); allocated != 9344 || limit != 9223372036854775807 {
+		t.Errorf("unexpected file-nr: %d %d", allocated, limit)
+	}
+	if allocated, _ := parseFileNr("3391 969 52427\n"); allocated != 2422 {
+		t.Errorf("unused handles should be subtracted, got %d", allocated)
+	}
+
+	near := fdNearLimit([]*ProcessInfo{
+		{Pid: 1, Name: "nginx", NumFds: 900, FdLimit: 1024},
+		{Pid: 2, Name: "java", NumFds: 1000, FdLimit: 1024},
+		{Pid: 3, Name: "sshd", NumFds: 10, FdLimit: 1024},
+		{Pid: 4, Name: "unknown", NumFds: 5000},
+	})
+	if len(near) != 2 || near[0].Name != "java" || near[1].Name != "nginx" {
+		t.Fatalf("unexpected near-limit processes: %+v", near)
+	}
+}
+
+func TestPackageParsers(t *testing.T) {
+	distro := parseOsRelease("PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nID=debian\nVERSION_ID=\"12\"\nVERSION_CODENAME=bookworm\n")
+	if distro.ID != "debian" || distro.Version != "12" || distro.Codename != "bookworm" || distro.PrettyName != "Debian GNU/Linux 12 (bookworm)" {
+		t.Errorf("unexpected distro: %+v", distro)
+	}
+
+	updates, security := parseAptSimulate(`Reading package lists...
+Inst libssl3 [3.0.2-0ubuntu1.9] (3.0.2-0ubuntu1.10 Ubuntu:22.04/jammy-security [amd64])
+Inst curl [7.81.0-1ubuntu1.13] (7.81.0-1ubuntu1.14 Ubuntu:22.04/jammy-updates [amd64])
+Conf libssl3 (3.0.2-0ubuntu1.10 Ubuntu:22.04/jammy-security [amd64])
+`)
+	if updates != 2 || security != 1 {
+		t.Errorf("apt: updates=%d security=%d", updates, security)
+	}
+
+	if n := parseCheckUpdate(`
+openssl-libs.x86_64          1:3.0.7-25.el9_3          baseos
+kernel.x86_64                5.14.0-362.18.1.el9_3     baseos
+Obsoleting Packages
+grub2-tools.x86_64           1:2.06-70.el9_3           baseos
+`); n != 2 {
+		t.Errorf("check-update: expected 2, got %d", n)
+	}
+	if n := parseUpdateinfo(`RHSA-2024:1234 Important/Sec. openssl-libs-1:3.0.7-25.el9_3.x86_64
+RHSA-2024:1250 Moderate/Sec.  openssl-libs-1:3.0.7-25.el9_3.x86_64
+RHSA-2024:1300 Important/Sec. kernel-5.14.0-362.18.1.el9_3.x86_64
+`); n != 2 {
+		t.Errorf("updateinfo: expected 2, got %d", n)
+	}
+}
+
+type fakeSource struct {
+	name    string
+	samples []Sample
+	panics  bool
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Collect(ctx context.Context) ([]Sample, error) {
+	if s.panics {
+		panic("boom")
+	}
+	return s.samples, nil
+}
+
+func TestCustomSources(t *testing.T) {
+	good := &fakeSource{name: "app", samples: []Sample{
+		{Name: "queue_depth", Labels: map[string]string{"queue": "mail"}, Value: 3},
+		{Name: "runixo_cpu_usage_percent", Value: 1}, // 保留前缀
+		{Name: "bad-name", Value: 1},
+		{Name: "requests", Labels: map[string]string{"source": "x"}, Value: 1}, // source 标签由采集器填充
+	}}
+	if err := RegisterSource(good); err != nil {
+		t.Fatalf("RegisterSource() error: %v", err)
+	}
+	defer UnregisterSource("app")
+	if err := RegisterSource(&fakeSource{name: "app"}); err == nil {
+		t.Error("duplicate source name should be rejected")
+	}
+	if err := RegisterSource(&fakeSource{name: "bad name"}); err == nil {
+		t.Error("invalid source name should be rejected")
+	}
+	if err := RegisterSource(&fakeSource{name: "broken", panics: true}); err != nil {
+		t.Fatalf("RegisterSource() error: %v", err)
+	}
+	defer UnregisterSource("broken")
+
+	samples := collectSources(context.Background())
+	if len(samples) != 1 || samples[0].Source != "app" || samples[0].Key() != `queue_depth{queue="mail"}` {
+		t.Fatalf("unexpected samples: %+v", samples)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if err := ValidateLabels(map[string]string{"env": "prod", "role": "db"}); err != nil {
+		t.Errorf("valid labels rejected: %v", err)
+	}
+	for _, bad := range []map[string]string{{"bad-name": "x"}, {"__name__": "x"}, {"env": strings.Repeat("x", 300)}} {
+		if ValidateLabels(bad) == nil {
+			t.Errorf("expected %v to be rejected", bad)
+		}
+	}
+
+	c := New()
+	input := map[string]string{"env": "prod"}
+	c.SetLabels(input)
+	input["env"] = "dev"
+	labels := c.Labels()
+	labels["role"] = "db"
+	if got := c.Labels(); len(got) != 1 || got["env"] != "prod" {
+		t.Errorf("labels should be copied on set and get: %v", got)
+	}
+}
+
+func TestParseEchoResponse(t *testing.T) {
+	tests := []struct {
+		body                  string
+		ip, country, asn, org string
+	}{
+		{"203.0.113.7\n", "203.0.113.7", "", "", ""},
+		{`{"ip":"2001:db8::1","country":"Japan","country_iso":"JP","asn":"AS2516","asn_org":"KDDI"}`, "2001:db8::1", "JP", "AS2516", "KDDI"},
+		{`{"ip":"203.0.113.7","country_code":"DE","asn":3320,"org":"Deutsche Telekom"}`, "203.0.113.7", "DE", "AS3320", "Deutsche Telekom"},
+		{`{"ip":"203.0.113.7","country":"US","org":"AS13335 Cloudflare, Inc."}`, "203.0.113.7", "US", "AS13335", "Cloudflare, Inc."},
+	}
+	for _, tt := range tests {
+		resp, err := parseEchoResponse([]byte(tt.body))
+		if err != nil {
+			t.Errorf("parseEchoResponse(%q) error: %v", tt.body, err)
+			continue
+		}
+		if resp.IP != tt.ip || resp.country() != tt.country || resp.asn() != tt.asn || resp.org() != tt.org {
+			t.Errorf("parseEchoResponse(%q) = %s %s %s %s", tt.body, resp.IP, resp.country(), resp.asn(), resp.org())
+		}
+	}
+	for _, bad := range []string{"<html>", `{"ip":"not-an-ip"}`} {
+		if _, err := parseEchoResponse([]byte(bad)); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestStunMappedAddress(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("udp not available:", err)
+	}
+	defer conn.Close()
+
+	// 返回 XOR-MAPPED-ADDRESS 198.51.100.9:4242
+	go func() {
+		req := make([]byte, 1500)
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil {
+			return
+		}
+		resp := make([]byte, 32)
+		binary.BigEndian.PutUint16(resp[0:], stunBindingResponse)
+		binary.BigEndian.PutUint16(resp[2:], 12)
+		copy(resp[4:20], req[4:20])
+		binary.BigEndian.PutUint16(resp[20:], stunXorMappedAddr)
+		binary.BigEndian.PutUint16(resp[22:], 8)
+		resp[25] = 0x01
+		binary.BigEndian.PutUint16(resp[26:], 4242^uint16(stunMagicCookie>>16))
+		for i, b := range net.ParseIP("198.51.100.9").To4() {
+			resp[28+i] = b ^ req[4+i]
+		}
+		conn.WriteTo(resp, addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ip, err := stunMappedAddress(ctx, "udp4", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("stunMappedAddress failed: %v", err)
+	}
+	if ip.String() != "198.51.100.9" {
+		t.Errorf("unexpected mapped address: %s", ip)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	neighbors := parseIPNeigh(`192.168.1.1 dev eth0 lladdr 00:11:22:AA:BB:CC REACHABLE
+192.168.1.9 dev eth0 FAILED
+fe80::1 dev eth0 lladdr 00:11:22:aa:bb:cc router STALE
+10.0.0.2 dev wg0 lladdr 00:00:00:00:00:00 PERMANENT
+`)
+	if len(neighbors) != 2 || neighbors[0].MAC != "00:11:22:aa:bb:cc" || neighbors[0].State != "REACHABLE" ||
+		neighbors[1].IP != "fe80::1" || neighbors[1].State != "STALE" {
+		t.Fatalf("unexpected ip neigh result: %+v", neighbors)
+	}
+
+	arp := parseProcNetARP(`IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         00:11:22:aa:bb:cc     *        eth0
+192.168.1.9      0x1         0x0         00:00:00:00:00:00     *        eth0
+`)
+	if len(arp) != 1 || arp[0].IP != "192.168.1.1" || arp[0].Interface != "eth0" {
+		t.Fatalf("unexpected /proc/net/arp result: %+v", arp)
+	}
+
+	w := NewNeighborWatcher(nil)
+	now := time.Now()
+	if changes := w.check(nil, now); len(changes) != 0 {
+		t.Fatalf("initial check should not report: %v", changes)
+	}
+	changes := w.check([]*Neighbor{{IP: "192.168.1.1", MAC: "00:11:22:aa:bb:cc", Interface: "eth0"}}, now)
+	if len(changes) != 1 || changes[0]["kind"] != "new" {
+		t.Fatalf("expected new neighbor: %v", changes)
+	}
+	changes = w.check([]*Neighbor{{IP: "192.168.1.1", MAC: "00:11:22:dd:ee:ff", Interface: "eth0"}}, now)
+	if len(changes) != 1 || changes[0]["kind"] != "changed" || changes[0]["previous_mac"] != "00:11:22:aa:bb:cc" {
+		t.Fatalf("expected changed neighbor: %v", changes)
+	}
+	// 条目过期后短时间内重新出现不再报告
+	if changes := w.check(nil, now.Add(time.Hour)); len(changes) != 0 {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+	if changes := w.check([]*Neighbor{{IP: "192.168.1.1", MAC: "00:11:22:dd:ee:ff", Interface: "eth0"}}, now.Add(2*time.Hour)); len(changes) != 0 {
+		t.Fatalf("returning neighbor should not be reported: %v", changes)
+	}
+}
+
+func TestReadPowerSupplies(t *testing.T) {
+	root := t.TempDir()
+	write := func(dev string, attrs map[string]string) {
+		dir := filepath.Join(root, dev)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, v := range attrs {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(v+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if readPowerSupplies(root) != nil {
+		t.Fatal("expected nil without power supplies")
+	}
+
+	write("AC", map[string]string{"type": "Mains", "online": "0"})
+	write("BAT0", map[string]string{"type": "Battery", "status": "Discharging", "energy_now": "30000000", "energy_full": "40000000", "power_now": "10000000"})
+	write("hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device", "capacity": "50"})
+	info := readPowerSupplies(root)
+	if info == nil || info.ACOnline || !info.OnBattery || len(info.Batteries) != 1 {
+		t.Fatalf("unexpected power info: %+v", info)
+	}
+	if b := info.Batteries[0]; b.Name != "BAT0" || b.Percent != 75 || b.Status != "discharging" || b.Remaining != 3*3600 {
+		t.Errorf("unexpected battery: %+v", b)
+	}
+
+	write("AC", map[string]string{"online": "1"})
+	write("BAT0", map[string]string{"status": "Not charging", "capacity": "98"})
+	info = readPowerSupplies(root)
+	if !info.ACOnline || info.OnBattery || info.Batteries[0].Status != "not_charging" || info.Batteries[0].Percent != 98 || info.Batteries[0].Remaining != 0 {
+		t.Errorf("unexpected power info on AC: %+v %+v", info, info.Batteries[0])
+	}
+}
@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ListeningPort 监听中的端口（UDP 为已绑定且未 connect 的 socket）
+type ListeningPort struct {
+	Protocol    string // tcp / tcp6 / udp / udp6
+	Address     string // 监听地址，0.0.0.0 / :: 表示所有地址
+	Port        uint32
+	Pid         int32  // 非 root 运行时其他用户的进程可能为 0
+	Process     string // 进程名
+	Established int    // 该端口上已建立的入站连接数（仅 TCP）
+}
+
+// NetworkConnections 监听端口和连接状态汇总
+type NetworkConnections struct {
+	Listening   []*ListeningPort
+	StateCounts map[string]int // TCP 连接按状态计数（ESTABLISHED / TIME_WAIT ...）
+	Total       int            // TCP + UDP socket 总数
+}
+
+// GetNetworkConnections 采集监听端口及其所属进程、已建立连接数
+// Linux 上由 gopsutil 读取 /proc/net/{tcp,tcp6,udp,udp6} 并通过 /proc/<pid>/fd 关联进程
+func (c *Collector) GetNetworkConnections() (*NetworkConnections, error) {
+	return c.connCache.get(func() (*NetworkConnections, error) {
+		conns, err := net.Connections("inet")
+		if err != nil {
+			return nil, err
+		}
+		return summarizeConnections(conns, processName), nil
+	})
+}
+
+// summarizeConnections 汇总连接列表，nameOf 用于查询进程名（同一 PID 只查询一次）
+func summarizeConnections(conns []net.ConnectionStat, nameOf func(int32) string) *NetworkConnections {
+	result := &NetworkConnections{StateCounts: make(map[string]int), Total: len(conns)}
+
+	type key struct {
+		proto string
+		addr  string
+		port  uint32
+	}
+	listening := make(map[key]*ListeningPort)
+	established := make(map[uint32]int) // 本地端口 -> 已建立连接数
+	names := make(map[int32]string)
+
+	for _, conn := range conns {
+		proto := connectionType(conn.Family, conn.Type)
+		isTCP := proto == "tcp" || proto == "tcp6"
+		if isTCP {
+			result.StateCounts[conn.Status]++
+		}
+
+		switch {
+		case isTCP && conn.Status == "LISTEN":
+		case !isTCP && conn.Raddr.IP == "":
+		default:
+			if isTCP && conn.Status == "ESTABLISHED" {
+				established[conn.Laddr.Port]++
+			}
+			continue
+		}
+
+		k := key{proto, conn.Laddr.IP, conn.Laddr.Port}
+		if _, ok := listening[k]; ok {
+			continue // SO_REUSEPORT 时多个 socket 监听同一地址
+		}
+		lp := &ListeningPort{Protocol: proto, Address: conn.Laddr.IP, Port: conn.Laddr.Port, Pid: conn.Pid}
+		if conn.Pid > 0 {
+			name, ok := names[conn.Pid]
+			if !ok {
+				name = nameOf(conn.Pid)
+				names[conn.Pid] = name
+			}
+			lp.Process = name
+		}
+		listening[k] = lp
+	}
+
+	for _, lp := range listening {
+		if lp.Protocol == "tcp" || lp.Protocol == "tcp6" {
+			lp.Established = established[lp.Port]
+		}
+		result.Listening = append(result.Listening, lp)
+	}
+	sort.Slice(result.Listening, func(i, j int) bool {
+		a, b := result.Listening[i], result.Listening[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.Address < b.Address
+	})
+	return result
+}
+
+// processName 查询进程名，失败时返回空字符串
+func processName(pid int32) string {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ""
+	}
+	name, _ := p.Name()
+	return name
+}
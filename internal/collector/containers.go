@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/docker"
+)
+
+// containerRefreshInterval 容器指标的刷新间隔
+// Docker stats 接口每次需要约 1 秒采样，不能跟随 GetMetrics 同步调用
+const containerRefreshInterval = 10 * time.Second
+
+// containerRefreshTimeout 单次刷新的超时时间
+const containerRefreshTimeout = 8 * time.Second
+
+// ContainerMetric 单个容器的资源占用（速率为字节/秒）
+type ContainerMetric struct {
+	ID            string
+	Name          string
+	Image         string
+	State         string
+	CpuPercent    float64
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+	NetworkRx     uint64 // 接收速率
+	NetworkTx     uint64 // 发送速率
+	RestartCount  int
+}
+
+// containerSampler 在后台刷新容器指标，GetMetrics 只读取最近一次快照
+type containerSampler struct {
+	socket string
+	client *docker.Client
+
+	mu          sync.Mutex
+	metrics     []*ContainerMetric
+	refreshedAt time.Time
+	refreshing  bool
+	lastNet     map[string][2]uint64 // 容器 ID -> 累计 rx/tx
+}
+
+func newContainerSampler(socket string) *containerSampler {
+	return &containerSampler{
+		socket:  socket,
+		client:  docker.NewClient(socket),
+		lastNet: make(map[string][2]uint64),
+	}
+}
+
+// snapshot 返回最近一次采集的结果，过期时在后台触发刷新
+// 未检测到 Docker socket 时返回 nil
+func (s *containerSampler) snapshot() []*ContainerMetric {
+	if _, err := os.Stat(s.socket); err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.refreshing && time.Since(s.refreshedAt) >= containerRefreshInterval {
+		s.refreshing = true
+		go s.refresh()
+	}
+	return s.metrics
+}
+
+func (s *containerSampler) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), containerRefreshTimeout)
+	defer cancel()
+
+	containers, err := s.client.ListContainers(ctx, true, true)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	if err != nil {
+		// 失败后同样等待一个间隔再重试，避免 Docker 异常时频繁请求
+		s.refreshedAt = now
+		s.metrics = nil
+		s.lastNet = make(map[string][2]uint64)
+		log.Debug().Err(err).Msg("采集容器指标失败")
+		return
+	}
+
+	elapsed := now.Sub(s.refreshedAt).Seconds()
+	nextNet := make(map[string][2]uint64, len(containers))
+	metrics := make([]*ContainerMetric, 0, len(containers))
+	for _, ct := range containers {
+		m := &ContainerMetric{
+			ID:           ct.ID,
+			Name:         ct.Name,
+			Image:        ct.Image,
+			State:        ct.State,
+			RestartCount: ct.RestartCount,
+		}
+		if st := ct.Stats; st != nil {
+			m.CpuPercent = st.CpuPercent
+			m.MemoryUsage = st.MemoryUsage
+			m.MemoryLimit = st.MemoryLimit
+			m.MemoryPercent = st.MemoryPercent
+			if last, ok := s.lastNet[ct.ID]; ok {
+				m.NetworkRx = counterRate(last[0], st.NetworkRx, elapsed)
+				m.NetworkTx = counterRate(last[1], st.NetworkTx, elapsed)
+			}
+			nextNet[ct.ID] = [2]uint64{st.NetworkRx, st.NetworkTx}
+		}
+		metrics = append(metrics, m)
+	}
+
+	s.metrics = metrics
+	s.lastNet = nextNet
+	s.refreshedAt = now
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// fdWarnPercent 打开的文件描述符达到软限制的该比例时标记为接近上限
+const fdWarnPercent = 80
+
+// unlimitedFds gopsutil 对 unlimited 的表示
+const unlimitedFds = math.MaxUint64
+
+// FdUsage 文件描述符接近上限的进程
+// 耗尽后 accept / open 返回 EMFILE，服务通常只在日志中留下 "too many open files"
+type FdUsage struct {
+	Pid     int32
+	Name    string
+	Open    int32
+	Limit   uint64
+	Percent float64
+}
+
+// readFdLimit 读取进程 RLIMIT_NOFILE 软限制，失败时返回 0
+func readFdLimit(p *process.Process) uint64 {
+	limits, err := p.Rlimit()
+	if err != nil {
+		return 0
+	}
+	for _, l := range limits {
+		if l.Resource == process.RLIMIT_NOFILE {
+			return l.Soft
+		}
+	}
+	return 0
+}
+
+// readFileNr 读取系统级文件句柄使用情况（/proc/sys/fs/file-nr：已分配 未使用 上限）
+// 非 Linux 平台返回 0, 0
+func readFileNr() (allocated, limit uint64) {
+	data, err := os.ReadFile("/proc/sys/fs/file-nr")
+	if err != nil {
+		return 0, 0
+	}
+	return parseFileNr(string(data))
+}
+
+func parseFileNr(data string) (allocated, limit uint64) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return 0, 0
+	}
+	allocated, _ = strconv.ParseUint(fields[0], 10, 64)
+	unused, _ := strconv.ParseUint(fields[1], 10, 64)
+	limit, _ = strconv.ParseUint(fields[2], 10, 64)
+	// 2.6 之后的内核 unused 恒为 0，旧内核中已分配但未使用的句柄可以复用
+	if unused <= allocated {
+		allocated -= unused
+	}
+	return allocated, limit
+}
+
+// fdNearLimit 找出打开文件描述符接近软限制的进程，按占用比例降序
+func fdNearLimit(processes []*ProcessInfo) []*FdUsage {
+	var result []*FdUsage
+	for _, p := range processes {
+		if p.FdLimit == 0 || p.NumFds <= 0 {
+			continue
+		}
+		percent := float64(p.NumFds) / float64(p.FdLimit) * 100
+		if percent < fdWarnPercent {
+			continue
+		}
+		result = append(result, &FdUsage{Pid: p.Pid, Name: p.Name, Open: p.NumFds, Limit: p.FdLimit, Percent: percent})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Percent > result[j].Percent })
+	return result
+}
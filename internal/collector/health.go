@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/webhook"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	// stuckThreshold 连续处于 D 状态超过该时长视为卡死（与内核 hung_task_timeout_secs 默认值一致）
+	stuckThreshold = 2 * time.Minute
+	// zombieAlertThreshold 僵尸进程数达到该值时告警，少量僵尸进程通常只是父进程回收不及时
+	zombieAlertThreshold = 20
+	// healthPollInterval 进程健康检查间隔
+	healthPollInterval = 30 * time.Second
+)
+
+// StuckProcess 长时间处于不可中断睡眠（D 状态）的进程
+// 通常意味着 NFS 挂起、磁盘故障或驱动问题，进程无法被信号终止
+type StuckProcess struct {
+	Pid      int32
+	Name     string
+	User     string
+	Cmdline  string
+	Since    int64   // 进入 D 状态的时间（Unix 秒，按采样估算）
+	Duration float64 // 已持续的秒数
+}
+
+// ProcessHealth 异常进程统计
+type ProcessHealth struct {
+	Zombies int             // 已退出但未被父进程回收的进程数
+	Stuck   []*StuckProcess // 持续处于 D 状态超过 stuckThreshold 的进程，按持续时间降序
+	// 打开的文件描述符达到软限制 fdWarnPercent% 的进程
+	FdNearLimit []*FdUsage
+}
+
+// GetProcessHealth 统计僵尸进程、长时间处于 D 状态的进程和文件描述符接近上限的进程
+// D 状态的持续时间依赖连续采样，采集器启动后至少需要 stuckThreshold 才能发现卡死进程
+func (c *Collector) GetProcessHealth() (*ProcessHealth, error) {
+	processes, err := c.ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return c.processHealth(processes, time.Now()), nil
+}
+
+func (c *Collector) processHealth(processes []*ProcessInfo, now time.Time) *ProcessHealth {
+	health := &ProcessHealth{FdNearLimit: fdNearLimit(processes)}
+
+	c.procMu.Lock()
+	defer c.procMu.Unlock()
+	for _, p := range processes {
+		switch p.Status {
+		case process.Zombie:
+			health.Zombies++
+		case process.Blocked:
+			sample := c.lastProcs[p.Pid]
+			if sample == nil || sample.blockedSince.IsZero() {
+				continue
+			}
+			if d := now.Sub(sample.blockedSince); d >= stuckThreshold {
+				health.Stuck = append(health.Stuck, &StuckProcess{
+					Pid:      p.Pid,
+					Name:     p.Name,
+					User:     p.User,
+					Cmdline:  p.Cmdline,
+					Since:    sample.blockedSince.Unix(),
+					Duration: d.Seconds(),
+				})
+			}
+		}
+	}
+	sort.Slice(health.Stuck, func(i, j int) bool {
+		return health.Stuck[i].Duration > health.Stuck[j].Duration
+	})
+	return health
+}
+
+// ProcessWatcher 定期检查进程健康状况，发现卡死进程或僵尸进程过多时发布 alert 事件
+type ProcessWatcher struct {
+	collector *Collector
+	events    webhook.Publisher
+
+	stuck         map[int32]int64 // 已告警的卡死进程 -> 进入 D 状态的时间
+	zombieAlerted bool
+	stopChan      chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewProcessWatcher 创建进程健康监视器，events 为 nil 时只写日志
+func NewProcessWatcher(c *Collector, events webhook.Publisher) *ProcessWatcher {
+	return &ProcessWatcher{
+		collector: c,
+		events:    events,
+		stuck:     make(map[int32]int64),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start 开始监视
+func (w *ProcessWatcher) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(healthPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stopChan:
+				return
+			case <-ticker.C:
+				if health, err := w.collector.GetProcessHealth(); err == nil {
+					w.check(health)
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止监视
+func (w *ProcessWatcher) Stop() {
+	close(w.stopChan)
+	w.wg.Wait()
+}
+
+// check 每个卡死进程只告警一次（恢复后再次卡死会重新告警）；
+// 僵尸进程数在越过阈值时告警一次，回落到阈值以下后重置
+func (w *ProcessWatcher) check(health *ProcessHealth) {
+	current := make(map[int32]int64, len(health.Stuck))
+	for _, p := range health.Stuck {
+		current[p.Pid] = p.Since
+		if since, ok := w.stuck[p.Pid]; ok && since == p.Since {
+			continue
+		}
+		log.Warn().Int32("pid", p.Pid).Str("name", p.Name).Float64("seconds", p.Duration).Msg("进程长时间处于不可中断睡眠（D）状态")
+		w.publish(map[string]any{
+			"source":  "process",
+			"kind":    "stuck",
+			"process": p,
+		})
+	}
+	w.stuck = current
+
+	if health.Zombies < zombieAlertThreshold {
+		w.zombieAlerted = false
+		return
+	}
+	if !w.zombieAlerted {
+		w.zombieAlerted = true
+		log.Warn().Int("zombies", health.Zombies).Msg("僵尸进程过多")
+		w.publish(map[string]any{
+			"source":  "process",
+			"kind":    "zombies",
+			"zombies": health.Zombies,
+		})
+	}
+}
+
+func (w *ProcessWatcher) publish(data map[string]any) {
+	if w.events != nil {
+		w.events.Publish(webhook.EventAlert, data)
+	}
+}
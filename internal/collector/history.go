@@ -0,0 +1,593 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/webhook"
+)
+
+// HistoryPoint 一个聚合周期内的指标（速率为周期内平均值，字节/秒）
+type HistoryPoint struct {
+	Timestamp   int64 // 周期起始时间（Unix 秒）
+	CpuUsage    float64
+	CpuMax      float64 // 周期内 CPU 峰值
+	MemoryUsage float64
+	Load1       float64
+	NetSent     float64
+	NetRecv     float64
+	DiskRead    float64
+	DiskWrite   float64
+	Custom      map[string]float64 // 自定义数据源的序列（键见 Sample.Key），周期内平均值
+}
+
+// historyRecord 磁盘上的定长记录（自定义序列另存于 .custom 文件）
+// 字段顺序即磁盘格式，与已有分段文件兼容，不能调整
+type historyRecord struct {
+	Timestamp                             int64
+	CpuUsage, CpuMax, MemoryUsage, Load1  float64
+	NetSent, NetRecv, DiskRead, DiskWrite float64
+}
+
+func (p *HistoryPoint) record() historyRecord {
+	return historyRecord{p.Timestamp, p.CpuUsage, p.CpuMax, p.MemoryUsage, p.Load1, p.NetSent, p.NetRecv, p.DiskRead, p.DiskWrite}
+}
+
+func (r *historyRecord) point() HistoryPoint {
+	return HistoryPoint{
+		Timestamp:   r.Timestamp,
+		CpuUsage:    r.CpuUsage,
+		CpuMax:      r.CpuMax,
+		MemoryUsage: r.MemoryUsage,
+		Load1:       r.Load1,
+		NetSent:     r.NetSent,
+		NetRecv:     r.NetRecv,
+		DiskRead:    r.DiskRead,
+		DiskWrite:   r.DiskWrite,
+	}
+}
+
+// customRecord .custom 文件中的一行
+type customRecord struct {
+	T int64              `json:"t"`
+	V map[string]float64 `json:"v"`
+}
+
+// HistoryConfig 历史数据配置
+type HistoryConfig struct {
+	Dir            string        // 磁盘分段目录，为空时只保存在内存
+	SampleInterval time.Duration // 采样间隔
+	Resolution     time.Duration // 聚合粒度
+	Retention      time.Duration // 保留时长
+}
+
+// DefaultHistoryConfig 默认保留 7 天、1 分钟粒度
+func DefaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{
+		SampleInterval: 10 * time.Second,
+		Resolution:     time.Minute,
+		Retention:      7 * 24 * time.Hour,
+	}
+}
+
+// historyRecordSize 磁盘记录大小：1 个 int64 + 8 个 float64
+const historyRecordSize = 9 * 8
+
+// maxHistoryPoints 内存中最多保留的点数，防止配置过细导致内存占用失控
+const maxHistoryPoints = 200000
+
+// HistoryStore 指标历史存储：内存环形缓冲 + 按天分段的定长二进制文件
+type HistoryStore struct {
+	collector *Collector
+	config    HistoryConfig
+
+	mu   sync.RWMutex
+	ring []HistoryPoint
+	head int // 下一个写入位置
+	size int
+
+	// 当前聚合周期
+	bucketStart time.Time
+	bucketSum   HistoryPoint
+	bucketCount int
+	// 自定义序列可能只在部分采样中出现，按序列分别计数
+	customCount map[string]int
+
+	segment           *os.File
+	segmentName       string
+	customSegment     *os.File
+	customSegmentName string
+
+	events webhook.Publisher
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewHistoryStore 创建历史存储并加载保留期内的磁盘数据
+func NewHistoryStore(c *Collector, config HistoryConfig) (*HistoryStore, error) {
+	defaults := DefaultHistoryConfig()
+	if config.SampleInterval <= 0 {
+		config.SampleInterval = defaults.SampleInterval
+	}
+	if config.Resolution <= 0 {
+		config.Resolution = defaults.Resolution
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	if config.Resolution < config.SampleInterval {
+		return nil, fmt.Errorf("聚合粒度 (%s) 不能小于采样间隔 (%s)", config.Resolution, config.SampleInterval)
+	}
+
+	capacity := int(config.Retention / config.Resolution)
+	if capacity > maxHistoryPoints {
+		return nil, fmt.Errorf("保留时长与粒度组合过大 (%d 点，最多 %d)", capacity, maxHistoryPoints)
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	h := &HistoryStore{
+		collector: c,
+		config:    config,
+		ring:      make([]HistoryPoint, capacity),
+		stopChan:  make(chan struct{}),
+	}
+
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0700); err != nil {
+			return nil, fmt.Errorf("创建历史数据目录失败: %w", err)
+		}
+		h.load()
+	}
+	return h, nil
+}
+
+// Start 开始后台采样
+func (h *HistoryStore) Start() {
+	h.wg.Add(1)
+	go h.sampleLoop()
+}
+
+// Stop 停止采样并写出当前周期
+func (h *HistoryStore) Stop() {
+	close(h.stopChan)
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flushBucketLocked()
+	if h.segment != nil {
+		h.segment.Close()
+		h.segment = nil
+	}
+	if h.customSegment != nil {
+		h.customSegment.Close()
+		h.customSegment = nil
+	}
+}
+
+// SetEventPublisher 设置事件发布，每次采样后发布 metrics.sampled 事件
+func (h *HistoryStore) SetEventPublisher(p webhook.Publisher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = p
+}
+
+// Config 返回生效的配置
+func (h *HistoryStore) Config() HistoryConfig {
+	return h.config
+}
+
+func (h *HistoryStore) sampleLoop() {
+	defer h.wg.Done()
+	ticker := time.NewTicker(h.config.SampleInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-h.stopChan:
+			return
+		case now := <-ticker.C:
+			h.sample(now)
+		case <-cleanup.C:
+			h.removeExpiredSegments()
+		}
+	}
+}
+
+// sample 采集一次指标并累加到当前聚合周期
+func (h *HistoryStore) sample(now time.Time) {
+	m, err := h.collector.GetMetrics()
+	if err != nil {
+		log.Debug().Err(err).Msg("采集历史指标失败")
+		return
+	}
+	// GetMetrics 返回的对象可能被复用，这里立即复制需要的字段
+	p := HistoryPoint{
+		CpuUsage:    m.CpuUsage,
+		CpuMax:      m.CpuUsage,
+		MemoryUsage: m.MemoryUsage,
+		Load1:       m.Load1,
+	}
+	for _, n := range m.NetworkMetrics {
+		p.NetSent += float64(n.BytesSent)
+		p.NetRecv += float64(n.BytesRecv)
+	}
+	for _, d := range m.DiskMetrics {
+		p.DiskRead += float64(d.ReadBytes)
+		p.DiskWrite += float64(d.WriteBytes)
+	}
+	if len(m.Custom) > 0 {
+		p.Custom = make(map[string]float64, len(m.Custom))
+		for _, s := range m.Custom {
+			p.Custom[s.Source+":"+s.Key()] = s.Value
+		}
+	}
+	h.add(now, p)
+
+	h.mu.RLock()
+	events := h.events
+	h.mu.RUnlock()
+	if events != nil {
+		p.Timestamp = now.Unix()
+		events.Publish(eventbus.TopicMetricsSampled, p)
+	}
+}
+
+// add 累加一个采样点，进入新周期时写出上一周期
+func (h *HistoryStore) add(now time.Time, p HistoryPoint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bucket := now.Truncate(h.config.Resolution)
+	if h.bucketCount > 0 && !bucket.Equal(h.bucketStart) {
+		h.flushBucketLocked()
+	}
+	if h.bucketCount == 0 {
+		h.bucketStart = bucket
+		h.bucketSum = HistoryPoint{}
+		h.customCount = nil
+	}
+
+	s := &h.bucketSum
+	s.CpuUsage += p.CpuUsage
+	s.MemoryUsage += p.MemoryUsage
+	s.Load1 += p.Load1
+	s.NetSent += p.NetSent
+	s.NetRecv += p.NetRecv
+	s.DiskRead += p.DiskRead
+	s.DiskWrite += p.DiskWrite
+	if p.CpuMax > s.CpuMax {
+		s.CpuMax = p.CpuMax
+	}
+	for k, v := range p.Custom {
+		if s.Custom == nil {
+			s.Custom = make(map[string]float64)
+			h.customCount = make(map[string]int)
+		}
+		s.Custom[k] += v
+		h.customCount[k]++
+	}
+	h.bucketCount++
+}
+
+// flushBucketLocked 计算当前周期平均值并写入内存和磁盘（需要持有锁）
+func (h *HistoryStore) flushBucketLocked() {
+	if h.bucketCount == 0 {
+		return
+	}
+	n := float64(h.bucketCount)
+	s := h.bucketSum
+	p := HistoryPoint{
+		Timestamp:   h.bucketStart.Unix(),
+		CpuUsage:    s.CpuUsage / n,
+		CpuMax:      s.CpuMax,
+		MemoryUsage: s.MemoryUsage / n,
+		Load1:       s.Load1 / n,
+		NetSent:     s.NetSent / n,
+		NetRecv:     s.NetRecv / n,
+		DiskRead:    s.DiskRead / n,
+		DiskWrite:   s.DiskWrite / n,
+		Custom:      averageCustom(s.Custom, h.customCount),
+	}
+	h.bucketCount = 0
+
+	h.pushLocked(p)
+	if h.config.Dir != "" {
+		if err := h.appendSegmentLocked(p); err != nil {
+			log.Warn().Err(err).Msg("写入历史数据失败")
+		}
+	}
+}
+
+// averageCustom 按各序列的样本数求平均
+func averageCustom(sum map[string]float64, count map[string]int) map[string]float64 {
+	if len(sum) == 0 {
+		return nil
+	}
+	avg := make(map[string]float64, len(sum))
+	for k, v := range sum {
+		avg[k] = v / float64(count[k])
+	}
+	return avg
+}
+
+func (h *HistoryStore) pushLocked(p HistoryPoint) {
+	h.ring[h.head] = p
+	h.head = (h.head + 1) % len(h.ring)
+	if h.size < len(h.ring) {
+		h.size++
+	}
+}
+
+// Query 返回 [from, to] 内的历史点，step 大于聚合粒度时进一步降采样
+func (h *HistoryStore) Query(from, to time.Time, step time.Duration) []HistoryPoint {
+	h.mu.RLock()
+	points := make([]HistoryPoint, 0, h.size)
+	start := (h.head - h.size + len(h.ring)) % len(h.ring)
+	for i := 0; i < h.size; i++ {
+		p := h.ring[(start+i)%len(h.ring)]
+		if p.Timestamp >= from.Unix() && p.Timestamp <= to.Unix() {
+			points = append(points, p)
+		}
+	}
+	h.mu.RUnlock()
+
+	if step <= h.config.Resolution {
+		return points
+	}
+	return downsample(points, int64(step/time.Second))
+}
+
+// Latest 返回最近 n 个历史点
+func (h *HistoryStore) Latest(n int) []HistoryPoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if n > h.size {
+		n = h.size
+	}
+	points := make([]HistoryPoint, n)
+	for i := 0; i < n; i++ {
+		points[i] = h.ring[(h.head-n+i+len(h.ring))%len(h.ring)]
+	}
+	return points
+}
+
+// downsample 按 step 秒对齐合并，平均值取均值，峰值取最大
+func downsample(points []HistoryPoint, step int64) []HistoryPoint {
+	if step <= 0 || len(points) == 0 {
+		return points
+	}
+	result := make([]HistoryPoint, 0, len(points))
+	var sum HistoryPoint
+	var customCount map[string]int
+	count := 0
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		n := float64(count)
+		result = append(result, HistoryPoint{
+			Timestamp:   sum.Timestamp,
+			CpuUsage:    sum.CpuUsage / n,
+			CpuMax:      sum.CpuMax,
+			MemoryUsage: sum.MemoryUsage / n,
+			Load1:       sum.Load1 / n,
+			NetSent:     sum.NetSent / n,
+			NetRecv:     sum.NetRecv / n,
+			DiskRead:    sum.DiskRead / n,
+			DiskWrite:   sum.DiskWrite / n,
+			Custom:      averageCustom(sum.Custom, customCount),
+		})
+		count = 0
+	}
+
+	for _, p := range points {
+		bucket := p.Timestamp - p.Timestamp%step
+		if count > 0 && bucket != sum.Timestamp {
+			flush()
+		}
+		if count == 0 {
+			sum = HistoryPoint{Timestamp: bucket}
+			customCount = nil
+		}
+		sum.CpuUsage += p.CpuUsage
+		sum.MemoryUsage += p.MemoryUsage
+		sum.Load1 += p.Load1
+		sum.NetSent += p.NetSent
+		sum.NetRecv += p.NetRecv
+		sum.DiskRead += p.DiskRead
+		sum.DiskWrite += p.DiskWrite
+		if p.CpuMax > sum.CpuMax {
+			sum.CpuMax = p.CpuMax
+		}
+		for k, v := range p.Custom {
+			if sum.Custom == nil {
+				sum.Custom = make(map[string]float64)
+				customCount = make(map[string]int)
+			}
+			sum.Custom[k] += v
+			customCount[k]++
+		}
+		count++
+	}
+	flush()
+	return result
+}
+
+// segmentDate 分段文件按 UTC 日期命名，例如 20261016.seg / 20261016.custom
+func segmentDate(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format("20060102")
+}
+
+func segmentName(ts int64) string {
+	return segmentDate(ts) + ".seg"
+}
+
+// appendSegmentLocked 追加一条定长记录，跨天时切换分段文件（需要持有锁）
+func (h *HistoryStore) appendSegmentLocked(p HistoryPoint) error {
+	name := segmentName(p.Timestamp)
+	if h.segment == nil || h.segmentName != name {
+		if h.segment != nil {
+			h.segment.Close()
+		}
+		f, err := os.OpenFile(filepath.Join(h.config.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			h.segment = nil
+			return err
+		}
+		h.segment = f
+		h.segmentName = name
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(historyRecordSize)
+	binary.Write(&buf, binary.LittleEndian, p.record())
+	if _, err := h.segment.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if len(p.Custom) > 0 {
+		return h.appendCustomLocked(p)
+	}
+	return nil
+}
+
+// appendCustomLocked 自定义序列的键不固定，以 JSON 行追加到同一天的 .custom 文件
+func (h *HistoryStore) appendCustomLocked(p HistoryPoint) error {
+	name := segmentDate(p.Timestamp) + ".custom"
+	if h.customSegment == nil || h.customSegmentName != name {
+		if h.customSegment != nil {
+			h.customSegment.Close()
+		}
+		f, err := os.OpenFile(filepath.Join(h.config.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			h.customSegment = nil
+			return err
+		}
+		h.customSegment = f
+		h.customSegmentName = name
+	}
+	line, err := json.Marshal(customRecord{T: p.Timestamp, V: p.Custom})
+	if err != nil {
+		return err
+	}
+	_, err = h.customSegment.Write(append(line, '\n'))
+	return err
+}
+
+// load 读取保留期内的分段文件填充内存缓冲
+func (h *HistoryStore) load() {
+	cutoff := time.Now().Add(-h.config.Retention).Unix()
+	custom := h.loadCustom(cutoff)
+	for _, name := range h.segmentFiles(".seg") {
+		data, err := os.ReadFile(filepath.Join(h.config.Dir, name))
+		if err != nil {
+			continue
+		}
+		// 进程异常退出可能留下不完整的尾部记录，直接忽略
+		r := bytes.NewReader(data[:len(data)-len(data)%historyRecordSize])
+		for {
+			var rec historyRecord
+			if err := binary.Read(r, binary.LittleEndian, &rec); err != nil {
+				if err != io.EOF {
+					log.Warn().Err(err).Str("segment", name).Msg("读取历史数据失败")
+				}
+				break
+			}
+			if rec.Timestamp >= cutoff {
+				p := rec.point()
+				p.Custom = custom[p.Timestamp]
+				h.pushLocked(p)
+			}
+		}
+	}
+	h.removeExpiredSegments()
+}
+
+// loadCustom 读取保留期内的自定义序列，按时间戳索引
+func (h *HistoryStore) loadCustom(cutoff int64) map[int64]map[string]float64 {
+	result := make(map[int64]map[string]float64)
+	for _, name := range h.segmentFiles(".custom") {
+		f, err := os.Open(filepath.Join(h.config.Dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			var rec customRecord
+			// 异常退出留下的不完整行直接跳过
+			if json.Unmarshal(scanner.Bytes(), &rec) == nil && rec.T >= cutoff {
+				result[rec.T] = rec.V
+			}
+		}
+		f.Close()
+	}
+	return result
+}
+
+// segmentFiles 按日期升序返回指定后缀的分段文件名
+func (h *HistoryStore) segmentFiles(suffix string) []string {
+	entries, err := os.ReadDir(h.config.Dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), suffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// removeExpiredSegments 删除整天都超出保留期的分段
+func (h *HistoryStore) removeExpiredSegments() {
+	if h.config.Dir == "" {
+		return
+	}
+	// 分段以当天 0 点命名，保留期边界所在的那天仍需保留
+	cutoff := segmentDate(time.Now().Add(-h.config.Retention).Unix())
+	for _, name := range append(h.segmentFiles(".seg"), h.segmentFiles(".custom")...) {
+		if name[:strings.IndexByte(name, '.')] < cutoff {
+			if err := os.Remove(filepath.Join(h.config.Dir, name)); err == nil {
+				log.Debug().Str("segment", name).Msg("已删除过期历史数据")
+			}
+		}
+	}
+}
+
+// ParseRetention 解析时长，在 time.ParseDuration 基础上支持天（d），例如 7d、36h
+func ParseRetention(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("无效的时长: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的时长: %s", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,274 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// packageRefreshInterval 软件包清单的刷新间隔，包管理器查询较慢且结果变化不频繁
+	packageRefreshInterval = time.Hour
+	// packageQueryTimeout 单次包管理器查询的超时时间
+	packageQueryTimeout = 2 * time.Minute
+)
+
+// DistroInfo 发行版信息（来自 /etc/os-release）
+type DistroInfo struct {
+	ID         string // debian / ubuntu / centos / rocky ...
+	Name       string
+	Version    string // VERSION_ID，如 12 / 22.04 / 9.3
+	Codename   string // VERSION_CODENAME，如 bookworm / jammy
+	PrettyName string
+}
+
+// PackageInfo 已安装软件包和待更新情况
+// 只使用本地已有的软件源元数据，不会主动刷新索引
+type PackageInfo struct {
+	Manager         string // apt / dnf / yum / apk / pacman
+	Installed       int
+	Updates         int   // 可更新的软件包数，-1 表示无法获取
+	SecurityUpdates int   // 其中的安全更新数，-1 表示无法获取
+	CheckedAt       int64 // 查询完成时间（Unix 秒）
+}
+
+// readDistroInfo 读取 /etc/os-release，不存在时返回 nil
+func readDistroInfo() *DistroInfo {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		data, err = os.ReadFile("/usr/lib/os-release")
+		if err != nil {
+			return nil
+		}
+	}
+	return parseOsRelease(string(data))
+}
+
+func parseOsRelease(data string) *DistroInfo {
+	info := &DistroInfo{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			info.ID = value
+		case "NAME":
+			info.Name = value
+		case "VERSION_ID":
+			info.Version = value
+		case "VERSION_CODENAME":
+			info.Codename = value
+		case "PRETTY_NAME":
+			info.PrettyName = value
+		}
+	}
+	return info
+}
+
+// packageInventory 在后台定期查询包管理器，调用方只读取最近一次的结果
+type packageInventory struct {
+	mu      sync.Mutex
+	info    *PackageInfo
+	at      time.Time
+	running bool
+}
+
+// GetPackageInfo 返回最近一次的软件包统计
+// 首次调用时在后台开始查询并返回 nil；不支持的系统始终返回 nil
+func (c *Collector) GetPackageInfo() *PackageInfo {
+	return c.packages.get()
+}
+
+func (p *packageInventory) get() *PackageInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running && (p.at.IsZero() || time.Since(p.at) >= packageRefreshInterval) {
+		p.running = true
+		go p.refresh()
+	}
+	return p.info
+}
+
+func (p *packageInventory) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), packageQueryTimeout)
+	defer cancel()
+	info, err := queryPackages(ctx)
+	if err != nil {
+		log.Debug().Err(err).Msg("查询软件包信息失败")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running = false
+	p.at = time.Now()
+	if info != nil {
+		p.info = info
+	}
+}
+
+// errNoPackageManager 未找到支持的包管理器
+var errNoPackageManager = errors.New("未找到支持的包管理器")
+
+// queryPackages 根据可用的包管理器统计已安装和待更新的软件包
+func queryPackages(ctx context.Context) (*PackageInfo, error) {
+	switch {
+	case commandExists("dpkg-query"):
+		return queryApt(ctx)
+	case commandExists("rpm"):
+		return queryRpm(ctx)
+	case commandExists("apk"):
+		return queryApk(ctx)
+	case commandExists("pacman"):
+		out, err := exec.CommandContext(ctx, "pacman", "-Q").Output()
+		if err != nil {
+			return nil, err
+		}
+		return &PackageInfo{Manager: "pacman", Installed: countLines(out), Updates: -1, SecurityUpdates: -1, CheckedAt: time.Now().Unix()}, nil
+	}
+	return nil, errNoPackageManager
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func queryApt(ctx context.Context) (*PackageInfo, error) {
+	out, err := exec.CommandContext(ctx, "dpkg-query", "-W", "-f=${db:Status-Abbrev}\n").Output()
+	if err != nil {
+		return nil, err
+	}
+	info := &PackageInfo{Manager: "apt", Updates: -1, SecurityUpdates: -1}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "ii") {
+			info.Installed++
+		}
+	}
+
+	// 模拟升级，不需要 root 也不会修改系统
+	sim, err := exec.CommandContext(ctx, "apt-get", "-s", "-o", "Debug::NoLocking=true", "dist-upgrade").Output()
+	if err == nil {
+		info.Updates, info.SecurityUpdates = parseAptSimulate(string(sim))
+	}
+	info.CheckedAt = time.Now().Unix()
+	return info, nil
+}
+
+// parseAptSimulate 解析 apt-get -s 输出中的 Inst 行，来源包含 -security 的视为安全更新
+//
+//	Inst libssl3 [3.0.2-0ubuntu1.9] (3.0.2-0ubuntu1.10 Ubuntu:22.04/jammy-security [amd64])
+func parseAptSimulate(output string) (updates, security int) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Inst ") {
+			continue
+		}
+		updates++
+		if strings.Contains(line, "-security") {
+			security++
+		}
+	}
+	return updates, security
+}
+
+func queryRpm(ctx context.Context) (*PackageInfo, error) {
+	out, err := exec.CommandContext(ctx, "rpm", "-qa").Output()
+	if err != nil {
+		return nil, err
+	}
+	info := &PackageInfo{Manager: "rpm", Installed: countLines(out), Updates: -1, SecurityUpdates: -1}
+
+	manager := ""
+	if commandExists("dnf") {
+		manager = "dnf"
+	} else if commandExists("yum") {
+		manager = "yum"
+	}
+	if manager != "" {
+		info.Manager = manager
+		// -C 只使用本地缓存，避免在采集时访问软件源；check-update 有可用更新时退出码为 100
+		out, err := exec.CommandContext(ctx, manager, "-C", "-q", "check-update").Output()
+		var exitErr *exec.ExitError
+		if err == nil || (errors.As(err, &exitErr) && exitErr.ExitCode() == 100) {
+			info.Updates = parseCheckUpdate(string(out))
+		}
+		if out, err := exec.CommandContext(ctx, manager, "-C", "-q", "updateinfo", "list", "--security").Output(); err == nil {
+			info.SecurityUpdates = parseUpdateinfo(string(out))
+		}
+	}
+	info.CheckedAt = time.Now().Unix()
+	return info, nil
+}
+
+// parseCheckUpdate 统计 dnf/yum check-update 列出的软件包（name.arch version repo）
+func parseCheckUpdate(output string) int {
+	count := 0
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// 之后是被替代的软件包列表，不计入
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 3 && strings.Contains(fields[0], ".") && !strings.HasPrefix(line, " ") {
+			count++
+		}
+	}
+	return count
+}
+
+// parseUpdateinfo 统计 updateinfo list --security 中涉及的软件包（同一软件包可能对应多个公告）
+//
+//	RHSA-2024:1234 Important/Sec. openssl-libs-1:3.0.7-25.el9_3.x86_64
+func parseUpdateinfo(output string) int {
+	packages := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 {
+			packages[fields[len(fields)-1]] = true
+		}
+	}
+	return len(packages)
+}
+
+func queryApk(ctx context.Context) (*PackageInfo, error) {
+	out, err := exec.CommandContext(ctx, "apk", "info").Output()
+	if err != nil {
+		return nil, err
+	}
+	info := &PackageInfo{Manager: "apk", Installed: countLines(out), Updates: -1, SecurityUpdates: -1}
+	// 输出首行为 "Installed: Available:" 表头
+	if out, err := exec.CommandContext(ctx, "apk", "version", "-l", "<").Output(); err == nil {
+		if n := countLines(out); n > 0 {
+			info.Updates = n - 1
+		}
+	}
+	info.CheckedAt = time.Now().Unix()
+	return info, nil
+}
+
+// countLines 统计非空行数
+func countLines(data []byte) int {
+	count := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLabelValueLength 标签值的最大长度
+const maxLabelValueLength = 256
+
+// ValidateLabels 校验主机标签：名称规则同 Prometheus 标签名，__ 前缀为保留名称
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if !metricNamePattern.MatchString(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("无效的标签名: %q", k)
+		}
+		if len(v) > maxLabelValueLength {
+			return fmt.Errorf("标签 %s 的值过长（最多 %d 字节）", k, maxLabelValueLength)
+		}
+	}
+	return nil
+}
+
+// SetLabels 设置主机标签（如 env=prod、role=db），随系统信息和指标一起输出
+func (c *Collector) SetLabels(labels map[string]string) {
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 整体替换而不是原地修改，已返回给调用方的 map 不会被改动
+	c.labels = copied
+}
+
+// Labels 返回主机标签的副本
+func (c *Collector) Labels() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	copied := make(map[string]string, len(c.labels))
+	for k, v := range c.labels {
+		copied[k] = v
+	}
+	return copied
+}
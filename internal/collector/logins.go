@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/webhook"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// LoginSession 当前登录会话（utmp）
+type LoginSession struct {
+	User     string
+	Terminal string
+	Host     string // 远程地址，本地登录为空
+	Started  int64
+}
+
+// LoginRecord 一条登录记录（wtmp 为成功登录，btmp 为失败尝试）
+type LoginRecord struct {
+	User     string
+	Terminal string
+	Host     string
+	Time     int64
+	Success  bool
+}
+
+// LoginInfo 当前会话与最近的登录记录
+type LoginInfo struct {
+	Sessions []*LoginSession
+	Recent   []*LoginRecord // 最近的成功登录，新的在前
+	Failed   []*LoginRecord // 最近的失败登录（读取 btmp 通常需要 root），新的在前
+}
+
+// GetLogins 获取当前会话和最近 limit 条登录 / 失败登录记录
+// 登录历史目前只支持 Linux，其他平台只返回当前会话
+func (c *Collector) GetLogins(limit int) (*LoginInfo, error) {
+	sessions, err := getSessions()
+	if err != nil {
+		return nil, err
+	}
+	info := &LoginInfo{Sessions: sessions}
+	info.Recent, _ = readLoginHistory(wtmpPath, limit, true)
+	info.Failed, _ = readLoginHistory(btmpPath, limit, false)
+	return info, nil
+}
+
+// getSessions 读取当前登录会话
+func getSessions() ([]*LoginSession, error) {
+	users, err := host.Users()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*LoginSession, 0, len(users))
+	for _, u := range users {
+		sessions = append(sessions, &LoginSession{User: u.User, Terminal: u.Terminal, Host: u.Host, Started: int64(u.Started)})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Started < sessions[j].Started })
+	return sessions, nil
+}
+
+// loginPollInterval 检查新会话的间隔
+const loginPollInterval = 10 * time.Second
+
+// LoginWatcher 定期检查登录会话，出现新的交互式登录时发布 login 事件
+type LoginWatcher struct {
+	events webhook.Publisher
+
+	known    map[LoginSession]bool
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewLoginWatcher 创建登录监视器，启动时已存在的会话不会产生事件
+func NewLoginWatcher(events webhook.Publisher) *LoginWatcher {
+	return &LoginWatcher{events: events, stopChan: make(chan struct{})}
+}
+
+// Start 开始监视
+func (w *LoginWatcher) Start() {
+	sessions, err := getSessions()
+	if err != nil {
+		log.Debug().Err(err).Msg("无法读取登录会话，登录监视未启动")
+		return
+	}
+	w.known = make(map[LoginSession]bool, len(sessions))
+	for _, s := range sessions {
+		w.known[*s] = true
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(loginPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stopChan:
+				return
+			case <-ticker.C:
+				if sessions, err := getSessions(); err == nil {
+					w.check(sessions)
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止监视
+func (w *LoginWatcher) Stop() {
+	close(w.stopChan)
+	w.wg.Wait()
+}
+
+// check 对比会话列表，为新会话发布事件；已结束的会话从记录中移除
+func (w *LoginWatcher) check(sessions []*LoginSession) {
+	current := make(map[LoginSession]bool, len(sessions))
+	for _, s := range sessions {
+		current[*s] = true
+		if w.known[*s] {
+			continue
+		}
+		log.Info().Str("user", s.User).Str("terminal", s.Terminal).Str("host", s.Host).Msg("检测到新的登录会话")
+		if w.events != nil {
+			w.events.Publish(webhook.EventLogin, map[string]any{
+				"user":     s.User,
+				"terminal": s.Terminal,
+				"host":     s.Host,
+				"started":  s.Started,
+			})
+		}
+	}
+	w.known = current
+}
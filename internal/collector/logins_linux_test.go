@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func utmpRecord(typ int16, user, line, host string, ts int32) []byte {
+	rec := make([]byte, utmpRecordSize)
+	binary.LittleEndian.PutUint16(rec[utmpTypeOff:], uint16(typ))
+	copy(rec[utmpLineOff:], line)
+	copy(rec[utmpUserOff:], user)
+	copy(rec[utmpHostOff:], host)
+	binary.LittleEndian.PutUint32(rec[utmpTimeOff:], uint32(ts))
+	return rec
+}
+
+func TestParseUtmp(t *testing.T) {
+	var data []byte
+	data = append(data, utmpRecord(2, "reboot", "~", "", 100)...)
+	data = append(data, utmpRecord(utmpUserProcess, "alice", "pts/0", "10.0.0.5", 200)...)
+	data = append(data, utmpRecord(8, "", "pts/0", "", 250)...) // 注销
+	data = append(data, utmpRecord(utmpUserProcess, "bob", "tty1", "", 300)...)
+
+	records := parseUtmp(data, 10, true)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 logins, got %d", len(records))
+	}
+	if records[0].User != "bob" || records[1].User != "alice" || records[1].Host != "10.0.0.5" || records[1].Time != 200 {
+		t.Errorf("unexpected records: %+v %+v", records[0], records[1])
+	}
+	if got := parseUtmp(data, 1, true); len(got) != 1 || got[0].User != "bob" {
+		t.Errorf("limit not applied: %+v", got)
+	}
+
+	failed := parseUtmp(utmpRecord(utmpLoginProcess, "root", "ssh:notty", "203.0.113.9", 400), 10, false)
+	if len(failed) != 1 || failed[0].Success || failed[0].Host != "203.0.113.9" {
+		t.Errorf("unexpected failed records: %+v", failed)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package collector
+
+const (
+	wtmpPath = ""
+	btmpPath = ""
+)
+
+// readLoginHistory 非 Linux 平台暂不支持读取登录历史
+func readLoginHistory(path string, limit int, success bool) ([]*LoginRecord, error) {
+	return nil, nil
+}
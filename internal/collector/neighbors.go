@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"bufio"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/webhook"
+)
+
+const (
+	// neighborPollInterval 检查邻居表的间隔
+	neighborPollInterval = 30 * time.Second
+	// neighborForgetAfter 记录从邻居表消失超过该时长后才遗忘，避免条目过期后重新出现时重复报告
+	neighborForgetAfter = 24 * time.Hour
+	// maxNeighborEvents 单次检查最多发布的事件数，其余只写日志
+	maxNeighborEvents = 32
+)
+
+// Neighbor ARP（IPv4）/ NDP（IPv6）邻居表中的一条记录
+type Neighbor struct {
+	IP        string
+	MAC       string
+	Interface string
+	State     string // REACHABLE、STALE、PERMANENT 等，取决于平台
+}
+
+// GetNeighbors 读取邻居表，未解析出 MAC 的条目（INCOMPLETE / FAILED）不返回
+func (c *Collector) GetNeighbors() ([]*Neighbor, error) {
+	neighbors, err := readNeighbors()
+	if err != nil {
+		return nil, err
+	}
+	sortNeighbors(neighbors)
+	return neighbors, nil
+}
+
+func sortNeighbors(neighbors []*Neighbor) {
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Interface != neighbors[j].Interface {
+			return neighbors[i].Interface < neighbors[j].Interface
+		}
+		return neighbors[i].IP < neighbors[j].IP
+	})
+}
+
+// parseIPNeigh 解析 `ip neigh show` 的输出，例如：
+//
+//	192.168.1.1 dev eth0 lladdr 00:11:22:33:44:55 REACHABLE
+//	fe80::1 dev eth0 lladdr 00:11:22:33:44:55 router STALE
+func parseIPNeigh(output string) []*Neighbor {
+	var neighbors []*Neighbor
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+			continue
+		}
+		n := &Neighbor{IP: fields[0]}
+		for i := 1; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "dev":
+				n.Interface = fields[i+1]
+			case "lladdr":
+				n.MAC = normalizeMAC(fields[i+1])
+			}
+		}
+		// 状态总是最后一个字段
+		if last := fields[len(fields)-1]; strings.Trim(last, "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") == "" {
+			n.State = last
+		}
+		if n.MAC != "" {
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors
+}
+
+// parseProcNetARP 解析 /proc/net/arp（只有 IPv4），在没有 ip 命令的精简系统上使用
+func parseProcNetARP(content string) []*Neighbor {
+	var neighbors []*Neighbor
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Scan() // 表头
+	for scanner.Scan() {
+		// IP address  HW type  Flags  HW address  Mask  Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || net.ParseIP(fields[0]) == nil {
+			continue
+		}
+		mac := normalizeMAC(fields[3])
+		if mac == "" || fields[2] == "0x0" {
+			continue
+		}
+		state := ""
+		if fields[2] == "0x6" {
+			state = "PERMANENT"
+		}
+		neighbors = append(neighbors, &Neighbor{IP: fields[0], MAC: mac, Interface: fields[5], State: state})
+	}
+	return neighbors
+}
+
+// normalizeMAC 统一为小写冒号格式，全零地址视为未解析
+func normalizeMAC(s string) string {
+	hw, err := net.ParseMAC(s)
+	if err != nil {
+		return ""
+	}
+	for _, b := range hw {
+		if b != 0 {
+			return hw.String()
+		}
+	}
+	return ""
+}
+
+// neighborKey 同一接口上的 IP 地址唯一确定一条邻居记录
+type neighborKey struct {
+	iface string
+	ip    string
+}
+
+type knownNeighbor struct {
+	mac      string
+	lastSeen time.Time
+}
+
+// NeighborWatcher 定期检查邻居表，出现新的 MAC-IP 对应关系或已知 IP 的 MAC 发生变化时发布 neighbor 事件
+// MAC 变化可能意味着 IP 冲突、设备更换或 ARP 欺骗
+type NeighborWatcher struct {
+	events webhook.Publisher
+
+	known    map[neighborKey]*knownNeighbor
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewNeighborWatcher 创建邻居表监视器，启动时已存在的条目不会产生事件
+func NewNeighborWatcher(events webhook.Publisher) *NeighborWatcher {
+	return &NeighborWatcher{events: events, stopChan: make(chan struct{})}
+}
+
+// Start 开始监视
+func (w *NeighborWatcher) Start() {
+	neighbors, err := readNeighbors()
+	if err != nil {
+		log.Debug().Err(err).Msg("无法读取邻居表，邻居表监视未启动")
+		return
+	}
+	w.check(neighbors, time.Now())
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(neighborPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stopChan:
+				return
+			case <-ticker.C:
+				if neighbors, err := readNeighbors(); err == nil {
+					w.check(neighbors, time.Now())
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止监视
+func (w *NeighborWatcher) Stop() {
+	close(w.stopChan)
+	w.wg.Wait()
+}
+
+// check 对比邻居表并返回发生的变化；首次检查只记录不报告
+func (w *NeighborWatcher) check(neighbors []*Neighbor, now time.Time) []map[string]any {
+	initial := w.known == nil
+	if initial {
+		w.known = make(map[neighborKey]*knownNeighbor, len(neighbors))
+	}
+	var changes []map[string]any
+	for _, n := range neighbors {
+		key := neighborKey{n.Interface, n.IP}
+		prev, ok := w.known[key]
+		w.known[key] = &knownNeighbor{mac: n.MAC, lastSeen: now}
+		if initial || (ok && prev.mac == n.MAC) {
+			continue
+		}
+		change := map[string]any{
+			"kind":      "new",
+			"ip":        n.IP,
+			"mac":       n.MAC,
+			"interface": n.Interface,
+			"state":     n.State,
+		}
+		if ok {
+			change["kind"] = "changed"
+			change["previous_mac"] = prev.mac
+			log.Warn().Str("ip", n.IP).Str("interface", n.Interface).Str("mac", n.MAC).Str("previous_mac", prev.mac).Msg("邻居 MAC 地址发生变化")
+		} else {
+			log.Info().Str("ip", n.IP).Str("interface", n.Interface).Str("mac", n.MAC).Msg("发现新的邻居")
+		}
+		changes = append(changes, change)
+	}
+	for key, n := range w.known {
+		if now.Sub(n.lastSeen) > neighborForgetAfter {
+			delete(w.known, key)
+		}
+	}
+
+	if w.events != nil {
+		for i, change := range changes {
+			if i >= maxNeighborEvents {
+				log.Debug().Int("dropped", len(changes)-i).Msg("邻居表变化过多，部分事件未发布")
+				break
+			}
+			w.events.Publish(webhook.EventNeighbor, change)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,22 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// readNeighbors 优先使用 ip 命令（同时包含 IPv6 NDP 条目），不可用时回退到 /proc/net/arp
+func readNeighbors() ([]*Neighbor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "ip", "neigh", "show").Output(); err == nil {
+		return parseIPNeigh(string(out)), nil
+	}
+	data, err := os.ReadFile("/proc/net/arp")
+	if err != nil {
+		return nil, err
+	}
+	return parseProcNetARP(string(data)), nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package collector
+
+import "errors"
+
+// readNeighbors 非 Linux 平台暂不支持读取邻居表
+func readNeighbors() ([]*Neighbor, error) {
+	return nil, errors.New("当前平台不支持读取邻居表")
+}
@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// InterfaceStat 网卡详细统计（累计值 + 速率）
+type InterfaceStat struct {
+	Name      string
+	Addresses []string
+	Mac       string
+	Mtu       int
+	Up        bool  // 链路状态（operstate == up 或带 up 标志）
+	Speed     int64 // 链路速率 Mbps，未知为 -1
+	Flags     []string
+
+	// 累计计数器
+	BytesSent   uint64
+	BytesRecv   uint64
+	PacketsSent uint64
+	PacketsRecv uint64
+	ErrIn       uint64
+	ErrOut      uint64
+	DropIn      uint64
+	DropOut     uint64
+
+	// 速率（每秒）
+	BytesSentRate   uint64
+	BytesRecvRate   uint64
+	PacketsSentRate uint64
+	PacketsRecvRate uint64
+	ErrInRate       uint64
+	ErrOutRate      uint64
+}
+
+// ifaceCounters 网卡计数器快照，用于计算速率
+type ifaceCounters struct {
+	bytesSent, bytesRecv     uint64
+	packetsSent, packetsRecv uint64
+	errIn, errOut            uint64
+}
+
+// GetNetworkInterfaces 获取各网卡的地址、链路状态、速率和收发计数
+// 使用独立的基准数据，不影响 GetMetrics 的速率计算
+func (c *Collector) GetNetworkInterfaces() ([]*InterfaceStat, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	counters, _ := net.IOCounters(true)
+	counterMap := make(map[string]net.IOCountersStat, len(counters))
+	for _, counter := range counters {
+		counterMap[counter.Name] = counter
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastIfaceTime).Seconds()
+	if c.lastIfaceStats == nil {
+		c.lastIfaceStats = make(map[string]*ifaceCounters)
+	}
+
+	result := make([]*InterfaceStat, 0, len(interfaces))
+	for _, iface := range interfaces {
+		stat := &InterfaceStat{
+			Name:  iface.Name,
+			Mac:   iface.HardwareAddr,
+			Mtu:   iface.MTU,
+			Flags: iface.Flags,
+			Speed: readLinkSpeed(iface.Name),
+			Up:    linkUp(iface.Name, iface.Flags),
+		}
+		for _, addr := range iface.Addrs {
+			stat.Addresses = append(stat.Addresses, addr.Addr)
+		}
+
+		if io, ok := counterMap[iface.Name]; ok {
+			stat.BytesSent = io.BytesSent
+			stat.BytesRecv = io.BytesRecv
+			stat.PacketsSent = io.PacketsSent
+			stat.PacketsRecv = io.PacketsRecv
+			stat.ErrIn = io.Errin
+			stat.ErrOut = io.Errout
+			stat.DropIn = io.Dropin
+			stat.DropOut = io.Dropout
+
+			current := &ifaceCounters{
+				bytesSent: io.BytesSent, bytesRecv: io.BytesRecv,
+				packetsSent: io.PacketsSent, packetsRecv: io.PacketsRecv,
+				errIn: io.Errin, errOut: io.Errout,
+			}
+			if last, ok := c.lastIfaceStats[iface.Name]; ok && elapsed > 0 {
+				stat.BytesSentRate = counterRate(last.bytesSent, current.bytesSent, elapsed)
+				stat.BytesRecvRate = counterRate(last.bytesRecv, current.bytesRecv, elapsed)
+				stat.PacketsSentRate = counterRate(last.packetsSent, current.packetsSent, elapsed)
+				stat.PacketsRecvRate = counterRate(last.packetsRecv, current.packetsRecv, elapsed)
+				stat.ErrInRate = counterRate(last.errIn, current.errIn, elapsed)
+				stat.ErrOutRate = counterRate(last.errOut, current.errOut, elapsed)
+			}
+			c.lastIfaceStats[iface.Name] = current
+		}
+
+		result = append(result, stat)
+	}
+	c.lastIfaceTime = now
+
+	return result, nil
+}
+
+// counterRate 计算计数器速率，计数器回绕或重置时返回 0
+func counterRate(prev, curr uint64, elapsed float64) uint64 {
+	if curr < prev || elapsed <= 0 {
+		return 0
+	}
+	return uint64(float64(curr-prev) / elapsed)
+}
+
+// readLinkSpeed 读取 /sys/class/net/<iface>/speed（虚拟网卡通常不可读）
+func readLinkSpeed(name string) int64 {
+	data, err := os.ReadFile("/sys/class/net/" + name + "/speed")
+	if err != nil {
+		return -1
+	}
+	speed, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || speed < 0 {
+		return -1
+	}
+	return speed
+}
+
+// linkUp 判断链路是否处于 up 状态，优先使用 operstate
+func linkUp(name string, flags []string) bool {
+	if data, err := os.ReadFile("/sys/class/net/" + name + "/operstate"); err == nil {
+		state := strings.TrimSpace(string(data))
+		if state != "unknown" {
+			return state == "up"
+		}
+	}
+	for _, f := range flags {
+		if f == "up" {
+			return true
+		}
+	}
+	return false
+}
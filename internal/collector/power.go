@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// powerSupplyRoot Linux 电源设备目录
+const powerSupplyRoot = "/sys/class/power_supply"
+
+// PowerInfo 电源状态，没有电池和外部电源信息的机器（多数服务器）为 nil
+type PowerInfo struct {
+	ACOnline  bool // 外部电源（市电 / USB / 适配器）在线
+	OnBattery bool // 外部电源离线，由电池供电
+	Batteries []*Battery
+}
+
+// Battery 单块电池的状态
+type Battery struct {
+	Name    string
+	Percent float64
+	// Status charging、discharging、full、not_charging 或 unknown
+	Status string
+	// Remaining 预计剩余放电时间（秒），未放电或无法估算时为 0
+	Remaining int64
+}
+
+// readPowerSupplies 读取 sysfs 电源设备（type 为 Battery / Mains / USB 等）
+func readPowerSupplies(root string) *PowerInfo {
+	entries, err := os.ReadDir(root)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	info := &PowerInfo{}
+	hasMains := false
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		attr := func(name string) string {
+			data, _ := os.ReadFile(filepath.Join(dir, name))
+			return strings.TrimSpace(string(data))
+		}
+		switch attr("type") {
+		case "Battery":
+			// 外设（鼠标、手柄等）的电池与主机供电无关
+			if attr("scope") == "Device" || attr("present") == "0" {
+				continue
+			}
+			info.Batteries = append(info.Batteries, sysfsBattery(e.Name(), attr))
+		case "Mains", "USB", "USB_C", "USB_PD":
+			hasMains = true
+			if attr("online") == "1" {
+				info.ACOnline = true
+			}
+		}
+	}
+	if !hasMains && len(info.Batteries) == 0 {
+		return nil
+	}
+	sort.Slice(info.Batteries, func(i, j int) bool { return info.Batteries[i].Name < info.Batteries[j].Name })
+
+	discharging := false
+	for _, b := range info.Batteries {
+		if b.Status == "discharging" {
+			discharging = true
+		}
+	}
+	// 部分设备没有 Mains 节点，只能根据电池状态判断
+	info.OnBattery = discharging || (hasMains && !info.ACOnline && len(info.Batteries) > 0)
+	if !hasMains {
+		info.ACOnline = !discharging
+	}
+	return info
+}
+
+func sysfsBattery(name string, attr func(string) string) *Battery {
+	b := &Battery{Name: name, Status: strings.ToLower(strings.ReplaceAll(attr("status"), " ", "_"))}
+	if b.Status == "" {
+		b.Status = "unknown"
+	}
+	num := func(name string) float64 {
+		v, _ := strconv.ParseFloat(attr(name), 64)
+		return v
+	}
+
+	// 驱动按能量（µWh / µW）或电荷（µAh / µA）报告，二者取其一
+	now, full, rate := num("energy_now"), num("energy_full"), num("power_now")
+	if full == 0 {
+		now, full, rate = num("charge_now"), num("charge_full"), num("current_now")
+	}
+	if attr("capacity") != "" {
+		b.Percent = num("capacity")
+	} else if full > 0 {
+		b.Percent = now / full * 100
+	}
+	if b.Percent > 100 {
+		b.Percent = 100
+	}
+	if b.Status == "discharging" && rate > 0 && now > 0 {
+		b.Remaining = int64(now / rate * 3600)
+	}
+	return b
+}
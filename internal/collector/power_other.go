@@ -0,0 +1,8 @@
+//go:build !windows
+
+package collector
+
+// readPowerInfo 从 sysfs 读取电源状态，非 Linux 平台目录不存在，返回 nil
+func readPowerInfo() *PowerInfo {
+	return readPowerSupplies(powerSupplyRoot)
+}
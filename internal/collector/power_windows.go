@@ -0,0 +1,65 @@
+//go:build windows
+
+package collector
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetSystemPowerStatus = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus 对应 Win32 SYSTEM_POWER_STATUS
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+const (
+	batteryFlagCharging  = 8
+	batteryFlagNoBattery = 128
+	batteryFlagUnknown   = 255
+	batteryUnknownValue  = 255
+	batteryUnknownTime   = 0xFFFFFFFF
+)
+
+// readPowerInfo 通过 GetSystemPowerStatus 读取电源状态（Windows 只报告汇总后的一块电池）
+func readPowerInfo() *PowerInfo {
+	var status systemPowerStatus
+	if r, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); r == 0 {
+		return nil
+	}
+	hasBattery := status.BatteryFlag&batteryFlagNoBattery == 0 && status.BatteryFlag != batteryFlagUnknown
+	if status.ACLineStatus == batteryUnknownValue && !hasBattery {
+		return nil
+	}
+
+	info := &PowerInfo{ACOnline: status.ACLineStatus == 1}
+	if !hasBattery {
+		return info
+	}
+	b := &Battery{Name: "battery", Status: "unknown"}
+	if status.BatteryLifePercent != batteryUnknownValue {
+		b.Percent = float64(status.BatteryLifePercent)
+	}
+	switch {
+	case status.BatteryFlag&batteryFlagCharging != 0:
+		b.Status = "charging"
+	case status.ACLineStatus == 0:
+		b.Status = "discharging"
+		if status.BatteryLifeTime != batteryUnknownTime {
+			b.Remaining = int64(status.BatteryLifeTime)
+		}
+	case status.ACLineStatus == 1 && b.Percent >= 100:
+		b.Status = "full"
+	case status.ACLineStatus == 1:
+		b.Status = "not_charging"
+	}
+	info.Batteries = []*Battery{b}
+	info.OnBattery = status.ACLineStatus == 0
+	return info
+}
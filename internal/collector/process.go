@@ -0,0 +1,380 @@
+package collector
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// maxDetailConnections 进程详情中最多返回的连接数
+const maxDetailConnections = 500
+
+// ProcessDetail 单个进程的扩展信息
+type ProcessDetail struct {
+	ProcessInfo
+	Exe         string
+	Cwd         string
+	Nice        int32
+	MemoryVms   uint64
+	MemorySwap  uint64
+	Environ     []string // 按请求可能被脱敏或省略
+	Connections []*ProcessConnection
+	Cgroup      string
+}
+
+// ProcessConnection 进程持有的网络连接
+type ProcessConnection struct {
+	Type       string // tcp / udp / unix
+	LocalAddr  string
+	RemoteAddr string
+	Status     string
+}
+
+// EnvMode 环境变量返回方式
+type EnvMode int
+
+const (
+	EnvRedacted EnvMode = iota // 敏感变量的值替换为 ******
+	EnvFull                    // 原样返回
+	EnvNone                    // 不返回
+)
+
+// sensitiveEnvKeys 变量名包含这些片段时视为敏感
+var sensitiveEnvKeys = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "PASS", "KEY", "AUTH", "CREDENTIAL", "COOKIE", "SESSION"}
+
+// ErrProcessNotFound 进程不存在
+var ErrProcessNotFound = errcode.New(errcode.ProcessNotFound, "进程不存在")
+
+// GetProcessDetail 获取单个进程的扩展信息
+func (c *Collector) GetProcessDetail(pid int32, envMode EnvMode) (*ProcessDetail, error) {
+	exists, err := process.PidExists(pid)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProcessNotFound
+	}
+
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, ErrProcessNotFound
+	}
+
+	detail := &ProcessDetail{ProcessInfo: *c.buildProcessInfo(p, time.Now())}
+	detail.Exe, _ = p.Exe()
+	detail.Cwd, _ = p.Cwd()
+	detail.Nice, _ = p.Nice()
+
+	if memInfo, err := p.MemoryInfo(); err == nil && memInfo != nil {
+		detail.MemoryVms = memInfo.VMS
+		detail.MemorySwap = memInfo.Swap
+	}
+
+	if envMode != EnvNone {
+		if environ, err := p.Environ(); err == nil {
+			if envMode == EnvRedacted {
+				environ = redactEnviron(environ)
+			}
+			detail.Environ = environ
+		}
+	}
+
+	if conns, err := p.ConnectionsMax(maxDetailConnections); err == nil {
+		for _, conn := range conns {
+			pc := &ProcessConnection{
+				Type:      connectionType(conn.Family, conn.Type),
+				LocalAddr: fmt.Sprintf("%s:%d", conn.Laddr.IP, conn.Laddr.Port),
+				Status:    conn.Status,
+			}
+			if conn.Raddr.IP != "" {
+				pc.RemoteAddr = fmt.Sprintf("%s:%d", conn.Raddr.IP, conn.Raddr.Port)
+			}
+			detail.Connections = append(detail.Connections, pc)
+		}
+	}
+
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid)); err == nil {
+		detail.Cgroup = strings.TrimSpace(string(data))
+	}
+
+	return detail, nil
+}
+
+// TopProcess 资源占用排行中的一项
+type TopProcess struct {
+	Pid   int32
+	Name  string
+	User  string
+	Value float64 // ByCpu 中为 CPU%，ByMemory 中为 RSS 字节数
+}
+
+// TopProcesses 按 CPU 和内存排序的前 N 个进程
+type TopProcesses struct {
+	ByCpu    []*TopProcess
+	ByMemory []*TopProcess
+}
+
+// SortProcesses 按 cpu 或 memory（RSS）降序排序，by 为空时按 CPU
+// by 不支持时返回 false 且不修改切片
+func SortProcesses(processes []*ProcessInfo, by string) bool {
+	switch by {
+	case "", "cpu":
+		sort.SliceStable(processes, func(i, j int) bool { return processes[i].CpuPercent > processes[j].CpuPercent })
+	case "memory":
+		sort.SliceStable(processes, func(i, j int) bool { return processes[i].MemoryRss > processes[j].MemoryRss })
+	default:
+		return false
+	}
+	return true
+}
+
+// topProcesses 从进程列表中取 CPU 和内存占用最高的 n 个
+func topProcesses(processes []*ProcessInfo, n int) *TopProcesses {
+	pick := func(by string, value func(*ProcessInfo) float64) []*TopProcess {
+		sorted := append([]*ProcessInfo(nil), processes...)
+		SortProcesses(sorted, by)
+		if len(sorted) > n {
+			sorted = sorted[:n]
+		}
+		result := make([]*TopProcess, 0, len(sorted))
+		for _, p := range sorted {
+			result = append(result, &TopProcess{Pid: p.Pid, Name: p.Name, User: p.User, Value: value(p)})
+		}
+		return result
+	}
+	return &TopProcesses{
+		ByCpu:    pick("cpu", func(p *ProcessInfo) float64 { return p.CpuPercent }),
+		ByMemory: pick("memory", func(p *ProcessInfo) float64 { return float64(p.MemoryRss) }),
+	}
+}
+
+// ProcessNode 进程树节点
+type ProcessNode struct {
+	*ProcessInfo
+	Children []*ProcessNode
+
+	// 以下字段仅在聚合时填充：包含自身及所有子孙进程
+	TotalCpuPercent    float64
+	TotalMemoryRss     uint64
+	TotalMemoryPercent float64
+	Descendants        int
+}
+
+// GetProcessTree 按父子关系组织进程，返回根节点（父进程不存在的进程也作为根）
+// aggregate 为 true 时把子孙进程的 CPU / 内存累加到每个节点的 Total* 字段
+func (c *Collector) GetProcessTree(aggregate bool) ([]*ProcessNode, error) {
+	processes, err := c.ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return buildProcessTree(processes, aggregate), nil
+}
+
+func buildProcessTree(processes []*ProcessInfo, aggregate bool) []*ProcessNode {
+	nodes := make(map[int32]*ProcessNode, len(processes))
+	for _, p := range processes {
+		nodes[p.Pid] = &ProcessNode{ProcessInfo: p}
+	}
+
+	var roots []*ProcessNode
+	for _, p := range processes {
+		node := nodes[p.Pid]
+		parent, ok := nodes[p.Ppid]
+		if !ok || p.Ppid == p.Pid {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	var sortNodes func([]*ProcessNode)
+	sortNodes = func(list []*ProcessNode) {
+		sort.Slice(list, func(i, j int) bool { return list[i].Pid < list[j].Pid })
+		for _, n := range list {
+			sortNodes(n.Children)
+		}
+	}
+	sortNodes(roots)
+
+	if aggregate {
+		for _, root := range roots {
+			aggregateNode(root)
+		}
+	}
+	return roots
+}
+
+// aggregateNode 自底向上累加子树的资源占用
+func aggregateNode(n *ProcessNode) {
+	n.TotalCpuPercent = n.CpuPercent
+	n.TotalMemoryRss = n.MemoryRss
+	n.TotalMemoryPercent = n.MemoryPercent
+	n.Descendants = 0
+	for _, child := range n.Children {
+		aggregateNode(child)
+		n.TotalCpuPercent += child.TotalCpuPercent
+		n.TotalMemoryRss += child.TotalMemoryRss
+		n.TotalMemoryPercent += child.TotalMemoryPercent
+		n.Descendants += child.Descendants + 1
+	}
+}
+
+// procSample 单个进程上一次采样的累计值
+type procSample struct {
+	createTime int64 // 用于识别 PID 复用
+	cpuTotal   float64
+	ioRead     uint64
+	ioWrite    uint64
+	at         time.Time
+	// 连续处于 D 状态的起始时间，不在 D 状态时为零值
+	blockedSince time.Time
+	// RLIMIT_NOFILE 软限制，0 表示未读取
+	fdLimit uint64
+}
+
+// buildProcessInfo 采集进程基本信息（与 ListProcesses 字段一致）
+//
+// CPU 与 I/O 速率按与上次采样的差值计算；首次见到的进程没有基准，
+// CPU 退化为进程生命周期内的平均值，I/O 速率为 0。
+func (c *Collector) buildProcessInfo(p *process.Process, now time.Time) *ProcessInfo {
+	name, _ := p.Name()
+	user, _ := p.Username()
+	status, _ := p.Status()
+	memPercent, _ := p.MemoryPercent()
+	memInfo, _ := p.MemoryInfo()
+	createTime, _ := p.CreateTime()
+	cmdline, _ := p.Cmdline()
+	ppid, _ := p.Ppid()
+	numThreads, _ := p.NumThreads()
+	numFds, _ := p.NumFDs()
+
+	info := &ProcessInfo{
+		Pid:           p.Pid,
+		Ppid:          ppid,
+		Name:          name,
+		User:          user,
+		MemoryPercent: float64(memPercent),
+		CreateTime:    createTime,
+		Cmdline:       cmdline,
+		NumThreads:    numThreads,
+		NumFds:        numFds,
+	}
+	if len(status) > 0 {
+		info.Status = status[0]
+	}
+	if memInfo != nil {
+		info.MemoryRss = memInfo.RSS
+	}
+
+	cur := &procSample{createTime: createTime, at: now}
+	times, timesErr := p.Times()
+	if timesErr == nil {
+		cur.cpuTotal = times.User + times.System
+	}
+	if io, err := p.IOCounters(); err == nil {
+		cur.ioRead = io.ReadBytes
+		cur.ioWrite = io.WriteBytes
+		info.IoReadBytes = io.ReadBytes
+		info.IoWriteBytes = io.WriteBytes
+	}
+
+	// 样本写入 lastProcs 后不再修改，可以在锁外读取
+	c.procMu.Lock()
+	last := c.lastProcs[p.Pid]
+	c.procMu.Unlock()
+	if last != nil && last.createTime != createTime {
+		last = nil // PID 被复用
+	}
+	if info.Status == process.Blocked {
+		cur.blockedSince = now
+		if last != nil && !last.blockedSince.IsZero() {
+			cur.blockedSince = last.blockedSince
+		}
+	}
+	// 文件描述符上限很少变化，沿用上次读取的值；接近上限时重新读取，以便发现 prlimit 调整
+	if numFds > 0 {
+		if last != nil && last.fdLimit > 0 && uint64(numFds) < last.fdLimit/5*4 {
+			cur.fdLimit = last.fdLimit
+		} else {
+			cur.fdLimit = readFdLimit(p)
+		}
+	}
+	if cur.fdLimit != unlimitedFds {
+		info.FdLimit = cur.fdLimit
+	}
+
+	c.procMu.Lock()
+	c.lastProcs[p.Pid] = cur
+	c.procMu.Unlock()
+
+	if last != nil && timesErr == nil {
+		if elapsed := now.Sub(last.at).Seconds(); elapsed > 0 {
+			info.CpuPercent = math.Max(0, (cur.cpuTotal-last.cpuTotal)/elapsed*100)
+			info.IoReadRate = float64(counterRate(last.ioRead, cur.ioRead, elapsed))
+			info.IoWriteRate = float64(counterRate(last.ioWrite, cur.ioWrite, elapsed))
+		}
+	} else {
+		info.CpuPercent, _ = p.CPUPercent()
+	}
+	return info
+}
+
+// pruneProcSamples 丢弃已退出进程的采样基准
+func (c *Collector) pruneProcSamples(alive map[int32]bool) {
+	c.procMu.Lock()
+	defer c.procMu.Unlock()
+	for pid := range c.lastProcs {
+		if !alive[pid] {
+			delete(c.lastProcs, pid)
+		}
+	}
+}
+
+// redactEnviron 隐藏敏感环境变量的值
+func redactEnviron(environ []string) []string {
+	result := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		key, _, found := strings.Cut(kv, "=")
+		if found && isSensitiveEnvKey(key) {
+			kv = key + "=******"
+		}
+		result = append(result, kv)
+	}
+	return result
+}
+
+// isSensitiveEnvKey 判断环境变量名是否可能包含凭据
+func isSensitiveEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, s := range sensitiveEnvKeys {
+		if strings.Contains(upper, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionType 将地址族和 socket 类型转为可读名称
+func connectionType(family, sockType uint32) string {
+	switch {
+	case family == syscall.AF_UNIX:
+		return "unix"
+	case sockType == syscall.SOCK_STREAM && family == syscall.AF_INET6:
+		return "tcp6"
+	case sockType == syscall.SOCK_STREAM:
+		return "tcp"
+	case sockType == syscall.SOCK_DGRAM && family == syscall.AF_INET6:
+		return "udp6"
+	case sockType == syscall.SOCK_DGRAM:
+		return "udp"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,351 @@
+package collector
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// publicIPTimeout 单次探测（HTTP 或 STUN）的超时时间
+	publicIPTimeout = 10 * time.Second
+	// maxEchoResponse 回显服务响应的最大长度
+	maxEchoResponse = 64 * 1024
+)
+
+// PublicIP 主机的公网地址（NAT 之后看到的出口地址）
+type PublicIP struct {
+	IPv4      string
+	IPv6      string
+	Country   string // 仅回显服务返回 JSON 且包含地理信息时填充
+	ASN       string // 如 AS13335
+	Org       string
+	Source    string // 最近一次成功探测使用的服务
+	CheckedAt int64
+}
+
+// PublicIPConfig 公网地址探测配置，Services 和 STUNServer 都为空时不探测
+type PublicIPConfig struct {
+	// Services 回显服务 URL，按顺序尝试；响应为纯文本 IP 或包含 ip 字段的 JSON
+	Services []string
+	// STUNServer 回显服务都失败时使用的 STUN 服务器（host:port）
+	STUNServer string
+	// Interval 刷新间隔
+	Interval time.Duration
+}
+
+// publicIPDetector 在后台定期探测公网地址
+type publicIPDetector struct {
+	mu      sync.Mutex
+	config  PublicIPConfig
+	info    *PublicIP
+	at      time.Time
+	running bool
+}
+
+// SetPublicIPConfig 设置公网地址探测，探测在后台进行，结果通过 GetSystemInfo 返回
+func (c *Collector) SetPublicIPConfig(config PublicIPConfig) {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	c.publicIP.mu.Lock()
+	defer c.publicIP.mu.Unlock()
+	c.publicIP.config = config
+	c.publicIP.at = time.Time{}
+}
+
+// GetPublicIP 返回最近一次探测到的公网地址，未启用或尚未完成首次探测时返回 nil
+func (c *Collector) GetPublicIP() *PublicIP {
+	return c.publicIP.get()
+}
+
+func (d *publicIPDetector) get() *PublicIP {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.config.Services) == 0 && d.config.STUNServer == "" {
+		return nil
+	}
+	if !d.running && (d.at.IsZero() || time.Since(d.at) >= d.config.Interval) {
+		d.running = true
+		go d.refresh(d.config)
+	}
+	return d.info
+}
+
+func (d *publicIPDetector) refresh(config PublicIPConfig) {
+	info := detectPublicIP(context.Background(), config)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.running = false
+	d.at = time.Now()
+	if info != nil {
+		d.info = info
+	}
+}
+
+// detectPublicIP 分别通过 IPv4 和 IPv6 探测，任一成功即返回
+func detectPublicIP(ctx context.Context, config PublicIPConfig) *PublicIP {
+	result := &PublicIP{}
+	for _, family := range []string{"4", "6"} {
+		echo, source, err := queryEchoServices(ctx, config.Services, family)
+		if err != nil && config.STUNServer != "" {
+			var ip net.IP
+			if ip, err = stunMappedAddress(ctx, "udp"+family, config.STUNServer); err == nil {
+				echo, source = &echoResponse{IP: ip.String()}, "stun:"+config.STUNServer
+			}
+		}
+		if err != nil {
+			log.Debug().Err(err).Str("family", "ipv"+family).Msg("探测公网地址失败")
+			continue
+		}
+		if family == "4" {
+			result.IPv4 = echo.IP
+		} else {
+			result.IPv6 = echo.IP
+		}
+		result.Source = source
+		if result.Country == "" && result.ASN == "" {
+			result.Country, result.ASN, result.Org = echo.country(), echo.asn(), echo.org()
+		}
+	}
+	if result.IPv4 == "" && result.IPv6 == "" {
+		return nil
+	}
+	result.CheckedAt = time.Now().Unix()
+	return result
+}
+
+// echoResponse 常见回显服务的 JSON 字段（ifconfig.co、ipinfo.io、ipapi.co 等）
+type echoResponse struct {
+	IP          string          `json:"ip"`
+	Country     string          `json:"country"`
+	CountryISO  string          `json:"country_iso"`
+	CountryCode string          `json:"country_code"`
+	ASN         json.RawMessage `json:"asn"` // 字符串 "AS13335" 或数字 13335
+	ASNOrg      string          `json:"asn_org"`
+	Org         string          `json:"org"` // ipinfo.io 格式："AS13335 Cloudflare, Inc."
+}
+
+func (e *echoResponse) country() string {
+	for _, v := range []string{e.CountryISO, e.CountryCode, e.Country} {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (e *echoResponse) asn() string {
+	if len(e.ASN) > 0 {
+		var s string
+		if json.Unmarshal(e.ASN, &s) == nil && s != "" {
+			if !strings.HasPrefix(strings.ToUpper(s), "AS") {
+				s = "AS" + s
+			}
+			return strings.ToUpper(s)
+		}
+		var n int64
+		if json.Unmarshal(e.ASN, &n) == nil && n > 0 {
+			return "AS" + strconv.FormatInt(n, 10)
+		}
+	}
+	if asn, _, ok := strings.Cut(e.Org, " "); ok && strings.HasPrefix(asn, "AS") {
+		return asn
+	}
+	return ""
+}
+
+func (e *echoResponse) org() string {
+	if e.ASNOrg != "" {
+		return e.ASNOrg
+	}
+	if asn, org, ok := strings.Cut(e.Org, " "); ok && strings.HasPrefix(asn, "AS") {
+		return org
+	}
+	return e.Org
+}
+
+// parseEchoResponse 解析回显服务的响应，支持纯文本 IP 和 JSON
+func parseEchoResponse(body []byte) (*echoResponse, error) {
+	text := strings.TrimSpace(string(body))
+	if ip := net.ParseIP(text); ip != nil {
+		return &echoResponse{IP: ip.String()}, nil
+	}
+	var resp echoResponse
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return nil, errors.New("无法解析回显服务响应")
+	}
+	ip := net.ParseIP(resp.IP)
+	if ip == nil {
+		return nil, fmt.Errorf("回显服务返回了无效的地址: %q", resp.IP)
+	}
+	resp.IP = ip.String()
+	return &resp, nil
+}
+
+// queryEchoServices 强制使用指定地址族依次请求回显服务
+func queryEchoServices(ctx context.Context, services []string, family string) (*echoResponse, string, error) {
+	dialer := &net.Dialer{Timeout: publicIPTimeout}
+	client := &http.Client{
+		Timeout: publicIPTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "tcp"+family, addr)
+			},
+			TLSHandshakeTimeout: publicIPTimeout,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	err := errors.New("未配置回显服务")
+	for _, service := range services {
+		var resp *echoResponse
+		if resp, err = fetchEcho(ctx, client, service); err == nil {
+			return resp, service, nil
+		}
+	}
+	return nil, "", err
+}
+
+func fetchEcho(ctx context.Context, client *http.Client, service string) (*echoResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, service, nil)
+	if err != nil {
+		return nil, err
+	}
+	// 部分服务（如 ifconfig.co）根据 User-Agent / Accept 决定返回格式
+	req.Header.Set("Accept", "application/json, text/plain")
+	req.Header.Set("User-Agent", "runixo-agent")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s 返回 HTTP %d", service, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEchoResponse))
+	if err != nil {
+		return nil, err
+	}
+	return parseEchoResponse(body)
+}
+
+// STUN（RFC 5389）常量
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112A442
+	stunMappedAddr      = 0x0001
+	stunXorMappedAddr   = 0x0020
+)
+
+// stunMappedAddress 发送 STUN Binding 请求，返回服务器看到的源地址
+func stunMappedAddress(ctx context.Context, network, server string) (net.IP, error) {
+	ctx, cancel := context.WithTimeout(ctx, publicIPTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	req := make([]byte, 20)
+	binary.BigEndian.PutUint16(req[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:], stunMagicCookie)
+	txID := req[8:20]
+	if _, err := rand.Read(txID); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1500)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return parseStunResponse(buf[:n], txID)
+}
+
+// parseStunResponse 从 Binding 响应中取出 XOR-MAPPED-ADDRESS（或旧版的 MAPPED-ADDRESS）
+func parseStunResponse(msg, txID []byte) (net.IP, error) {
+	if len(msg) < 20 || binary.BigEndian.Uint16(msg[0:]) != stunBindingResponse ||
+		binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || string(msg[8:20]) != string(txID) {
+		return nil, errors.New("无效的 STUN 响应")
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if 20+length > len(msg) {
+		return nil, errors.New("STUN 响应不完整")
+	}
+
+	var mapped net.IP
+	attrs := msg[20 : 20+length]
+	for len(attrs) >= 4 {
+		typ := binary.BigEndian.Uint16(attrs[0:])
+		size := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+size > len(attrs) {
+			break
+		}
+		value := attrs[4 : 4+size]
+		switch typ {
+		case stunXorMappedAddr:
+			if ip := stunAddress(value, msg[4:20]); ip != nil {
+				return ip, nil
+			}
+		case stunMappedAddr:
+			mapped = stunAddress(value, nil)
+		}
+		// 属性按 4 字节对齐，最后一个属性可能省略填充
+		next := 4 + (size+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	if mapped != nil {
+		return mapped, nil
+	}
+	return nil, errors.New("STUN 响应中没有映射地址")
+}
+
+// stunAddress 解析地址属性，xor 不为空时按 XOR-MAPPED-ADDRESS 规则还原（magic cookie + 事务 ID）
+func stunAddress(value, xor []byte) net.IP {
+	if len(value) < 8 {
+		return nil
+	}
+	var ip net.IP
+	switch value[1] {
+	case 0x01:
+		ip = net.IP(append([]byte(nil), value[4:8]...))
+	case 0x02:
+		if len(value) < 20 {
+			return nil
+		}
+		ip = net.IP(append([]byte(nil), value[4:20]...))
+	default:
+		return nil
+	}
+	if xor != nil {
+		for i := range ip {
+			ip[i] ^= xor[i]
+		}
+	}
+	return ip
+}
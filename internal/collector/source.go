@@ -0,0 +1,211 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// sourceTimeout 单个自定义数据源一次采集的最长时间，超时的数据源本轮不输出样本
+	sourceTimeout = 2 * time.Second
+	// maxSourceSamples 单个数据源每次最多输出的样本数
+	maxSourceSamples = 1000
+)
+
+// Sample 自定义数据源输出的一个样本
+type Sample struct {
+	Source string // 由采集器填充为数据源名称
+	Name   string // 指标名，规则同 Prometheus（字母、数字、下划线，不能以数字开头）
+	Help   string
+	Labels map[string]string
+	Value  float64
+	Type   string // SampleGauge 或 SampleCounter，为空时按 gauge 处理
+}
+
+// 样本类型，对应 Prometheus 的 TYPE
+const (
+	SampleGauge   = "gauge"
+	SampleCounter = "counter"
+)
+
+// Key 返回样本的序列标识，形如 name{a="1",b="2"}，用于历史数据中区分不同序列
+func (s *Sample) Key() string {
+	if len(s.Labels) == 0 {
+		return s.Name
+	}
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(s.Name)
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", k, s.Labels[k])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Source 自定义指标数据源
+// 插件或站点定制代码实现该接口并通过 RegisterSource 注册后，样本会随 GetMetrics 一起输出，
+// 进入指标历史和 Prometheus 接口
+type Source interface {
+	Name() string
+	Collect(ctx context.Context) ([]Sample, error)
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]Source)
+
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// RegisterSource 注册自定义数据源，名称不能重复
+// 注册对所有 Collector 生效（包括指标历史使用的独立采集器）
+func RegisterSource(s Source) error {
+	name := s.Name()
+	if !metricNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的数据源名称: %q", name)
+	}
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, exists := sources[name]; exists {
+		return fmt.Errorf("数据源已注册: %s", name)
+	}
+	sources[name] = s
+	return nil
+}
+
+// UnregisterSource 注销数据源，不存在时忽略
+func UnregisterSource(name string) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	delete(sources, name)
+}
+
+// RegisteredSources 返回已注册的数据源名称
+func RegisteredSources() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// collectSources 并发采集所有数据源，返回按数据源、指标名排序的样本
+// 单个数据源出错、超时或 panic 不影响其他数据源（panic 作为采集失败处理）
+func collectSources(ctx context.Context) []*Sample {
+	sourcesMu.RLock()
+	list := make([]Source, 0, len(sources))
+	for _, s := range sources {
+		list = append(list, s)
+	}
+	sourcesMu.RUnlock()
+	if len(list) == 0 {
+		return nil
+	}
+
+	results := make([][]*Sample, len(list))
+	var wg sync.WaitGroup
+	for i, src := range list {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			results[i] = collectSource(ctx, src)
+		}(i, src)
+	}
+	wg.Wait()
+
+	var all []*Sample
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Source != all[j].Source {
+			return all[i].Source < all[j].Source
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+func collectSource(ctx context.Context, src Source) []*Sample {
+	name := src.Name()
+	ctx, cancel := context.WithTimeout(ctx, sourceTimeout)
+	defer cancel()
+
+	// 数据源可能不检查 ctx，超时后丢弃其结果而不是阻塞 GetMetrics
+	type result struct {
+		samples []Sample
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		s, err := src.Collect(ctx)
+		done <- result{s, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		log.Debug().Str("source", name).Msg("自定义数据源采集超时")
+		return nil
+	}
+	if res.err != nil {
+		log.Debug().Err(res.err).Str("source", name).Msg("自定义数据源采集失败")
+		return nil
+	}
+
+	var samples []*Sample
+	for i := range res.samples {
+		if len(samples) >= maxSourceSamples {
+			log.Debug().Str("source", name).Int("limit", maxSourceSamples).Msg("自定义数据源样本过多，已截断")
+			break
+		}
+		s := res.samples[i]
+		if !validSample(&s) {
+			continue
+		}
+		s.Source = name
+		samples = append(samples, &s)
+	}
+	return samples
+}
+
+// validSample 指标名和标签名必须符合 Prometheus 规则，runixo_ 前缀保留给内置指标
+func validSample(s *Sample) bool {
+	if !metricNamePattern.MatchString(s.Name) || strings.HasPrefix(s.Name, "runixo_") {
+		return false
+	}
+	if s.Type != "" && s.Type != SampleGauge && s.Type != SampleCounter {
+		return false
+	}
+	for k := range s.Labels {
+		if !metricNamePattern.MatchString(k) || k == "source" {
+			return false
+		}
+	}
+	return true
+}
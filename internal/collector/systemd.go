@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unitCacheTTL systemd 单元状态的缓存时间，避免每次指标请求都调用 systemctl
+const unitCacheTTL = 15 * time.Second
+
+// UnitSummary systemd 单元状态汇总
+type UnitSummary struct {
+	Total       int
+	Active      int
+	Inactive    int
+	Activating  int // 包括 activating / deactivating / reloading
+	Failed      int
+	FailedUnits []string // 失败单元名称（含类型后缀，如 nginx.service）
+}
+
+// unitCollector 缓存 systemctl 的查询结果
+type unitCollector struct {
+	mu      sync.Mutex
+	summary *UnitSummary
+	at      time.Time
+}
+
+// get 返回单元状态汇总，非 systemd 系统或查询失败时返回 nil
+func (u *unitCollector) get() *UnitSummary {
+	// 与 sd_booted() 相同的判断方式
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !u.at.IsZero() && time.Since(u.at) < unitCacheTTL {
+		return u.summary
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "systemctl", "list-units", "--all", "--no-legend", "--no-pager", "--plain").Output()
+	u.at = time.Now()
+	if err != nil {
+		u.summary = nil
+		return nil
+	}
+	u.summary = parseUnitList(string(output))
+	return u.summary
+}
+
+// parseUnitList 解析 systemctl list-units --plain --no-legend 的输出
+// 每行格式：UNIT LOAD ACTIVE SUB DESCRIPTION...
+func parseUnitList(output string) *UnitSummary {
+	summary := &UnitSummary{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		// 部分版本即使指定 --plain 仍会在失败单元前输出 ●
+		if fields[0] == "●" || fields[0] == "*" {
+			fields = fields[1:]
+			if len(fields) < 4 {
+				continue
+			}
+		}
+
+		summary.Total++
+		switch fields[2] {
+		case "active":
+			summary.Active++
+		case "inactive":
+			summary.Inactive++
+		case "failed":
+			summary.Failed++
+			summary.FailedUnits = append(summary.FailedUnits, fields[0])
+		default:
+			summary.Activating++
+		}
+	}
+	sort.Strings(summary.FailedUnits)
+	return summary
+}
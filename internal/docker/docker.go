@@ -0,0 +1,342 @@
+// Package docker 通过 Docker Engine API（unix socket）管理容器
+// 不依赖 docker CLI，避免命令白名单和输出解析问题
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// DefaultSocket Docker 守护进程默认 socket 路径
+const DefaultSocket = "/var/run/docker.sock"
+
+// apiVersion 使用的 Engine API 版本（Docker 17.06+ 均支持）
+const apiVersion = "v1.30"
+
+// validContainerID 容器 ID 或名称
+var validContainerID = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,127}$`)
+
+// Container 容器信息
+type Container struct {
+	ID      string
+	Name    string
+	Image   string
+	State   string // running / exited / paused ...
+	Status  string // 例如 "Up 3 hours"
+	Created int64
+	Ports   []string
+	Stats   *ContainerStats // 仅 running 状态的容器有值
+
+	RestartCount int // 仅 withStats 时采集
+}
+
+// ContainerStats 容器资源占用
+type ContainerStats struct {
+	CpuPercent    float64
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+	NetworkRx     uint64
+	NetworkTx     uint64
+	BlockRead     uint64
+	BlockWrite    uint64
+	Pids          uint64
+}
+
+// Client Docker Engine API 客户端
+type Client struct {
+	socket     string
+	httpClient *http.Client
+}
+
+// NewClient 创建客户端，socket 为空时使用默认路径
+func NewClient(socket string) *Client {
+	if socket == "" {
+		socket = DefaultSocket
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{
+		socket: socket,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+	}
+}
+
+// request 发送 API 请求，非 2xx 状态码转为错误
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, result interface{}) error {
+	u := "http://docker/" + apiVersion + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errcode.Wrap(errcode.DockerUnavailable, err, "连接 Docker 失败")
+	}
+	defer resp.Body.Close()
+
+	// 304 表示容器已处于目标状态（例如对已启动的容器再次 start）
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		code := errcode.DockerUnavailable
+		if resp.StatusCode == http.StatusNotFound {
+			code = errcode.NotFound
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return errcode.New(code, "Docker API 错误 (%d): %s", resp.StatusCode, apiErr.Message)
+		}
+		return errcode.New(code, "Docker API 错误 (%d)", resp.StatusCode)
+	}
+
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
+
+// Ping 检查 Docker 守护进程是否可用
+func (c *Client) Ping(ctx context.Context) error {
+	u := "http://docker/_ping"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errcode.Wrap(errcode.DockerUnavailable, err, "Docker 不可用")
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errcode.New(errcode.DockerUnavailable, "Docker 不可用 (%d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// apiContainer /containers/json 返回结构
+type apiContainer struct {
+	ID      string   `json:"Id"`
+	Names   []string `json:"Names"`
+	Image   string   `json:"Image"`
+	State   string   `json:"State"`
+	Status  string   `json:"Status"`
+	Created int64    `json:"Created"`
+	Ports   []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+}
+
+// ListContainers 列出容器；withStats 为 true 时并发采集运行中容器的资源占用
+func (c *Client) ListContainers(ctx context.Context, all, withStats bool) ([]*Container, error) {
+	query := url.Values{}
+	if all {
+		query.Set("all", "1")
+	}
+
+	var raw []apiContainer
+	if err := c.request(ctx, http.MethodGet, "/containers/json", query, &raw); err != nil {
+		return nil, err
+	}
+
+	containers := make([]*Container, 0, len(raw))
+	for _, rc := range raw {
+		ct := &Container{
+			ID:      shortID(rc.ID),
+			Image:   rc.Image,
+			State:   rc.State,
+			Status:  rc.Status,
+			Created: rc.Created,
+		}
+		if len(rc.Names) > 0 {
+			ct.Name = strings.TrimPrefix(rc.Names[0], "/")
+		}
+		for _, p := range rc.Ports {
+			if p.PublicPort > 0 {
+				ct.Ports = append(ct.Ports, fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+			} else {
+				ct.Ports = append(ct.Ports, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+			}
+		}
+		containers = append(containers, ct)
+	}
+
+	if withStats {
+		var wg sync.WaitGroup
+		for _, ct := range containers {
+			wg.Add(1)
+			go func(ct *Container) {
+				defer wg.Done()
+				// 重启次数对反复崩溃、当前已退出的容器同样有意义
+				if n, err := c.restartCount(ctx, ct.ID); err == nil {
+					ct.RestartCount = n
+				}
+				if ct.State != "running" {
+					return
+				}
+				if stats, err := c.GetStats(ctx, ct.ID); err == nil {
+					ct.Stats = stats
+				}
+			}(ct)
+		}
+		wg.Wait()
+	}
+
+	return containers, nil
+}
+
+// apiStats /containers/{id}/stats 返回结构（只取需要的字段）
+type apiStats struct {
+	CPUStats    apiCPUStats `json:"cpu_stats"`
+	PreCPUStats apiCPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IoServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+	PidsStats struct {
+		Current uint64 `json:"current"`
+	} `json:"pids_stats"`
+}
+
+type apiCPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+// GetStats 获取单个容器的资源占用快照
+func (c *Client) GetStats(ctx context.Context, id string) (*ContainerStats, error) {
+	if !validContainerID.MatchString(id) {
+		return nil, errcode.New(errcode.InvalidArgument, "无效的容器 ID: %s", id)
+	}
+
+	var raw apiStats
+	query := url.Values{"stream": {"false"}}
+	if err := c.request(ctx, http.MethodGet, "/containers/"+id+"/stats", query, &raw); err != nil {
+		return nil, err
+	}
+
+	stats := &ContainerStats{
+		MemoryLimit: raw.MemoryStats.Limit,
+		Pids:        raw.PidsStats.Current,
+	}
+
+	// 与 docker stats 一致：内存使用量扣除页缓存
+	usage := raw.MemoryStats.Usage
+	if cache, ok := raw.MemoryStats.Stats["inactive_file"]; ok && cache < usage {
+		usage -= cache
+	} else if cache, ok := raw.MemoryStats.Stats["cache"]; ok && cache < usage {
+		usage -= cache
+	}
+	stats.MemoryUsage = usage
+	if stats.MemoryLimit > 0 {
+		stats.MemoryPercent = float64(usage) / float64(stats.MemoryLimit) * 100
+	}
+
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	sysDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(raw.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && sysDelta > 0 {
+		stats.CpuPercent = cpuDelta / sysDelta * onlineCPUs * 100
+	}
+
+	for _, n := range raw.Networks {
+		stats.NetworkRx += n.RxBytes
+		stats.NetworkTx += n.TxBytes
+	}
+	for _, b := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(b.Op) {
+		case "read":
+			stats.BlockRead += b.Value
+		case "write":
+			stats.BlockWrite += b.Value
+		}
+	}
+
+	return stats, nil
+}
+
+// restartCount 通过 inspect 获取容器被重启策略拉起的次数
+func (c *Client) restartCount(ctx context.Context, id string) (int, error) {
+	var raw struct {
+		RestartCount int `json:"RestartCount"`
+	}
+	if err := c.request(ctx, http.MethodGet, "/containers/"+id+"/json", nil, &raw); err != nil {
+		return 0, err
+	}
+	return raw.RestartCount, nil
+}
+
+// ContainerAction 对容器执行 start / stop / restart
+func (c *Client) ContainerAction(ctx context.Context, id, action string) error {
+	if !validContainerID.MatchString(id) {
+		return errcode.New(errcode.InvalidArgument, "无效的容器 ID: %s", id)
+	}
+
+	query := url.Values{}
+	switch action {
+	case "start":
+	case "stop", "restart":
+		query.Set("t", "10") // 给容器 10 秒优雅退出时间
+	default:
+		return errcode.New(errcode.InvalidArgument, "不支持的容器操作: %s", action)
+	}
+
+	return c.request(ctx, http.MethodPost, "/containers/"+id+"/"+action, query, nil)
+}
+
+// shortID 返回 12 位短 ID
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
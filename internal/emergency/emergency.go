@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/webhook"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/process"
@@ -51,6 +53,7 @@ type Manager struct {
 	cancel          context.CancelFunc
 	consecutiveHigh int // 连续超阈值的采样次数
 	killHistory     []KillRecord
+	events          webhook.Publisher
 }
 
 // New 创建管理器
@@ -63,6 +66,13 @@ func New() *Manager {
 	}
 }
 
+// SetEventPublisher 设置事件推送，负载开始超过阈值时发送 threshold.breached 事件，执行紧急终止时发送 alert 事件
+func (m *Manager) SetEventPublisher(p webhook.Publisher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = p
+}
+
 // Enable 启用紧急避险
 func (m *Manager) Enable() {
 	m.mu.Lock()
@@ -177,8 +187,20 @@ func (m *Manager) checkAndAct() {
 		m.consecutiveHigh = 0
 	}
 	shouldKill := m.consecutiveHigh >= SamplesRequired
+	breached := m.consecutiveHigh == 1
+	events := m.events
 	m.mu.Unlock()
 
+	if breached && events != nil {
+		events.Publish(eventbus.TopicThresholdBreached, map[string]any{
+			"source":        "emergency",
+			"cpu_usage":     cpuUsage,
+			"mem_usage":     memUsage,
+			"cpu_threshold": cpuThresh,
+			"mem_threshold": memThresh,
+		})
+	}
+
 	if shouldKill {
 		m.killTopProcess(cpuUsage, memUsage)
 		m.mu.Lock()
@@ -279,7 +301,17 @@ func (m *Manager) killTopProcess(cpuUsage, memUsage float64) {
 
 	m.mu.Lock()
 	m.killHistory = append(m.killHistory, record)
+	events := m.events
 	m.mu.Unlock()
+
+	if events != nil {
+		events.Publish(webhook.EventAlert, map[string]any{
+			"source":      "emergency",
+			"cpu_usage":   cpuUsage,
+			"mem_usage":   memUsage,
+			"kill_record": record,
+		})
+	}
 }
 
 // killDockerContainer 停止 Docker 容器并禁用重启策略
@@ -0,0 +1,289 @@
+// Package errcode 定义 REST 与 gRPC 共用的稳定错误码
+// 客户端应根据错误码分支处理，错误消息仅供展示，不保证稳定
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain gRPC ErrorInfo 中使用的错误域
+const Domain = "runixo.agent"
+
+// Code 稳定错误码
+type Code string
+
+const (
+	Internal            Code = "INTERNAL"
+	InvalidArgument     Code = "INVALID_ARGUMENT"
+	NotFound            Code = "NOT_FOUND"
+	AlreadyExists       Code = "ALREADY_EXISTS"
+	Unavailable         Code = "UNAVAILABLE"
+	Unimplemented       Code = "UNIMPLEMENTED"
+	AuthRequired        Code = "AUTH_REQUIRED"
+	AuthInvalid         Code = "AUTH_INVALID"
+	AuthLocked          Code = "AUTH_LOCKED"
+	RateLimited         Code = "RATE_LIMITED"
+	PermissionDenied    Code = "PERMISSION_DENIED"
+	PathNotAllowed      Code = "PATH_NOT_ALLOWED"
+	CommandNotAllowed   Code = "COMMAND_NOT_ALLOWED"
+	ServiceNotAllowed   Code = "SERVICE_NOT_ALLOWED"
+	ProcessNotFound     Code = "PROCESS_NOT_FOUND"
+	ProcessProtected    Code = "PROCESS_PROTECTED"
+	PluginNotFound      Code = "PLUGIN_NOT_FOUND"
+	PluginExists        Code = "PLUGIN_ALREADY_INSTALLED"
+	PluginVerifyFailed  Code = "PLUGIN_VERIFY_FAILED"
+	PluginDepsUnmet     Code = "PLUGIN_DEPENDENCY_UNMET"
+	PluginBadManifest   Code = "PLUGIN_MANIFEST_INVALID"
+	UpdateCooldown      Code = "UPDATE_COOLDOWN"
+	UpdateNotAvailable  Code = "UPDATE_NOT_AVAILABLE"
+	UpdateVerifyFailed  Code = "UPDATE_VERIFY_FAILED"
+	DockerUnavailable   Code = "DOCKER_UNAVAILABLE"
+	UpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+	ValidationFailed    Code = "VALIDATION_FAILED"
+)
+
+// Error 携带错误码的错误
+type Error struct {
+	Code Code
+	Msg  string
+	Err  error
+	// Fields 逐字段的校验错误，REST 响应中作为 details 返回，gRPC 状态中附带 BadRequest
+	Fields []FieldViolation
+}
+
+// FieldViolation 单个字段的校验错误
+type FieldViolation struct {
+	Field       string `json:"field"` // 字段路径，如 "resources.memory"、"permissions[1]"
+	Description string `json:"description"`
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Msg
+	}
+	if e.Msg == "" {
+		return e.Err.Error()
+	}
+	return e.Msg + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New 创建带错误码的错误
+func New(code Code, format string, args ...any) error {
+	return &Error{Code: code, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Wrap 为已有错误附加错误码，err 为 nil 时返回 nil
+func Wrap(code Code, err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Msg: msg, Err: err}
+}
+
+// Invalid 创建附带逐字段校验错误的错误
+func Invalid(code Code, msg string, fields []FieldViolation) error {
+	return &Error{Code: code, Msg: msg, Fields: fields}
+}
+
+// FieldsOf 提取错误链中最外层带字段校验错误的 Fields
+func FieldsOf(err error) []FieldViolation {
+	for err != nil {
+		if e, ok := err.(*Error); ok && len(e.Fields) > 0 {
+			return e.Fields
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+// Of 提取错误链中最外层的错误码
+// 没有错误码的错误视为 INTERNAL；gRPC status 错误按状态码映射
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return FromStatus(st)
+	}
+	return Internal
+}
+
+// HTTPStatus 返回错误码对应的 HTTP 状态码
+func HTTPStatus(code Code) int {
+	switch code {
+	case InvalidArgument:
+		return http.StatusBadRequest
+	case AuthRequired, AuthInvalid:
+		return http.StatusUnauthorized
+	case PermissionDenied, PathNotAllowed, CommandNotAllowed, ServiceNotAllowed, ProcessProtected:
+		return http.StatusForbidden
+	case NotFound, ProcessNotFound, PluginNotFound, UpdateNotAvailable:
+		return http.StatusNotFound
+	case AlreadyExists, PluginExists:
+		return http.StatusConflict
+	case AuthLocked, RateLimited, UpdateCooldown:
+		return http.StatusTooManyRequests
+	case UpdateVerifyFailed, PluginVerifyFailed, PluginDepsUnmet, PluginBadManifest, ValidationFailed:
+		return http.StatusUnprocessableEntity
+	case DockerUnavailable, UpstreamUnavailable:
+		return http.StatusBadGateway
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	case Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FromHTTPStatus 为未显式指定错误码的 REST 错误推导通用错误码
+func FromHTTPStatus(httpStatus int) Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return AuthInvalid
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return AlreadyExists
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return UpstreamUnavailable
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusNotImplemented:
+		return Unimplemented
+	default:
+		return Internal
+	}
+}
+
+// GRPCCode 返回错误码对应的 gRPC 状态码
+func GRPCCode(code Code) codes.Code {
+	switch code {
+	case InvalidArgument:
+		return codes.InvalidArgument
+	case AuthRequired, AuthInvalid:
+		return codes.Unauthenticated
+	case PermissionDenied, PathNotAllowed, CommandNotAllowed, ServiceNotAllowed, ProcessProtected:
+		return codes.PermissionDenied
+	case NotFound, ProcessNotFound, PluginNotFound, UpdateNotAvailable:
+		return codes.NotFound
+	case AlreadyExists, PluginExists:
+		return codes.AlreadyExists
+	case AuthLocked, RateLimited:
+		return codes.ResourceExhausted
+	case UpdateCooldown, UpdateVerifyFailed, PluginVerifyFailed, PluginDepsUnmet, PluginBadManifest, ValidationFailed:
+		return codes.FailedPrecondition
+	case DockerUnavailable, UpstreamUnavailable, Unavailable:
+		return codes.Unavailable
+	case Unimplemented:
+		return codes.Unimplemented
+	default:
+		return codes.Internal
+	}
+}
+
+// fromGRPCCode 为未附带 ErrorInfo 的 gRPC 状态推导通用错误码
+func fromGRPCCode(c codes.Code) Code {
+	switch c {
+	case codes.OK:
+		return ""
+	case codes.InvalidArgument, codes.OutOfRange:
+		return InvalidArgument
+	case codes.Unauthenticated:
+		return AuthInvalid
+	case codes.PermissionDenied:
+		return PermissionDenied
+	case codes.NotFound:
+		return NotFound
+	case codes.AlreadyExists:
+		return AlreadyExists
+	case codes.ResourceExhausted:
+		return RateLimited
+	case codes.Unavailable:
+		return Unavailable
+	case codes.Unimplemented:
+		return Unimplemented
+	default:
+		return Internal
+	}
+}
+
+// FromStatus 读取 gRPC 状态中的错误码，优先使用 ErrorInfo.Reason
+func FromStatus(st *status.Status) Code {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == Domain {
+			return Code(info.Reason)
+		}
+	}
+	return fromGRPCCode(st.Code())
+}
+
+// Status 创建附带 ErrorInfo 的 gRPC 状态错误
+func Status(code Code, format string, args ...any) error {
+	return withInfo(status.New(GRPCCode(code), fmt.Sprintf(format, args...)), code)
+}
+
+// ToStatus 将任意错误转为附带错误码的 gRPC 状态错误
+// 已带 ErrorInfo 的状态原样返回，其余按 Of 推导错误码
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		if hasInfo(st) {
+			return err
+		}
+		if st.Code() != codes.Unknown {
+			return withInfo(st, fromGRPCCode(st.Code()))
+		}
+	}
+	code := Of(err)
+	st := status.New(GRPCCode(code), err.Error())
+	if fields := FieldsOf(err); len(fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Description})
+		}
+		if detailed, err := st.WithDetails(br); err == nil {
+			st = detailed
+		}
+	}
+	return withInfo(st, code)
+}
+
+func hasInfo(st *status.Status) bool {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == Domain {
+			return true
+		}
+	}
+	return false
+}
+
+func withInfo(st *status.Status, code Code) error {
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(code), Domain: Domain})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,76 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOf(t *testing.T) {
+	wrapped := fmt.Errorf("删除失败: %w", New(PathNotAllowed, "禁止访问路径: %s", "/etc"))
+	if got := Of(wrapped); got != PathNotAllowed {
+		t.Errorf("Of(wrapped) = %s, want %s", got, PathNotAllowed)
+	}
+	if got := Of(errors.New("plain")); got != Internal {
+		t.Errorf("Of(plain) = %s, want %s", got, Internal)
+	}
+	if got := Of(nil); got != "" {
+		t.Errorf("Of(nil) = %q, want empty", got)
+	}
+	if got := HTTPStatus(Of(wrapped)); got != http.StatusForbidden {
+		t.Errorf("HTTPStatus = %d, want %d", got, http.StatusForbidden)
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	err := ToStatus(New(UpdateCooldown, "更新冷却中"))
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("ToStatus() did not return a status error: %v", err)
+	}
+	if st.Code() != codes.FailedPrecondition {
+		t.Errorf("grpc code = %v, want FailedPrecondition", st.Code())
+	}
+	if got := Of(err); got != UpdateCooldown {
+		t.Errorf("Of(status) = %s, want %s", got, UpdateCooldown)
+	}
+
+	// 未附带 ErrorInfo 的状态按 gRPC 状态码推导
+	plain := ToStatus(status.Error(codes.NotFound, "读取文件失败"))
+	if got := Of(plain); got != NotFound {
+		t.Errorf("Of(plain status) = %s, want %s", got, NotFound)
+	}
+
+	// 已附带错误码的状态不被覆盖
+	locked := Status(AuthLocked, "认证失败次数过多")
+	if got := Of(ToStatus(locked)); got != AuthLocked {
+		t.Errorf("Of(ToStatus(locked)) = %s, want %s", got, AuthLocked)
+	}
+}
+
+func TestToStatusFieldViolations(t *testing.T) {
+	fields := []FieldViolation{{Field: "version", Description: "格式无效"}}
+	err := fmt.Errorf("读取插件清单失败: %w", Invalid(PluginBadManifest, "插件清单校验失败", fields))
+	if got := FieldsOf(err); len(got) != 1 || got[0] != fields[0] {
+		t.Errorf("FieldsOf() = %v, want %v", got, fields)
+	}
+
+	st, _ := status.FromError(ToStatus(err))
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			violations = br.FieldViolations
+		}
+	}
+	if len(violations) != 1 || violations[0].Field != "version" {
+		t.Errorf("BadRequest violations = %v, want field version", violations)
+	}
+	if got := Of(st.Err()); got != PluginBadManifest {
+		t.Errorf("Of(status) = %s, want %s", got, PluginBadManifest)
+	}
+}
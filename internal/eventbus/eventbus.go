@@ -0,0 +1,156 @@
+// Package eventbus Agent 内部按主题发布 / 订阅的事件总线
+//
+// 核心模块（指标采集、紧急避险、文件操作、更新器等）和插件都向总线发布事件，
+// 订阅方按主题接收；webhook 作为订阅方之一转发对外可订阅的事件。
+// 主题以 "." 分段，订阅时 "*" 匹配全部，"ip.*" 匹配以 "ip." 开头的主题。
+package eventbus
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 核心模块发布的主题，与 webhook 事件类型同名的主题（alert、ip.blocked 等）另见 webhook 包
+const (
+	TopicMetricsSampled    = "metrics.sampled"    // 指标历史完成一次采样
+	TopicThresholdBreached = "threshold.breached" // 系统负载超过紧急避险阈值
+	TopicFileChanged       = "file.changed"       // 通过 Agent 写入、移动或删除了文件
+	TopicSecurityThreat    = "security.threat"    // Cloudflare 插件检测到威胁
+	TopicPermissionDenied  = "permission.denied"  // 插件访问了清单未声明的权限
+)
+
+// SourceAgent Agent 核心模块发布的事件来源
+const SourceAgent = "agent"
+
+// defaultBuffer 订阅的默认缓冲大小，订阅方处理不及时时新事件被丢弃
+const defaultBuffer = 64
+
+// Event 总线上的事件
+type Event struct {
+	Topic  string    `json:"topic"`
+	Source string    `json:"source"` // agent 或 plugin:<插件 ID>
+	Time   time.Time `json:"time"`
+	Data   any       `json:"data,omitempty"`
+}
+
+// Publisher 事件发布接口，与 webhook.Publisher 相同，便于总线与 webhook 互换
+type Publisher interface {
+	Publish(topic string, data any)
+}
+
+// Bus 事件总线，发布不会阻塞：订阅方缓冲已满时丢弃事件
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// New 创建事件总线
+func New() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription 一个订阅，事件从 C 读取，不再需要时调用 Close
+type Subscription struct {
+	C <-chan Event
+
+	bus     *Bus
+	name    string
+	topics  []string
+	ch      chan Event
+	dropped atomic.Uint64
+}
+
+// Subscribe 订阅主题，name 用于日志；buffer 不大于 0 时使用默认缓冲
+func (b *Bus) Subscribe(name string, buffer int, topics ...string) *Subscription {
+	if buffer <= 0 {
+		buffer = defaultBuffer
+	}
+	ch := make(chan Event, buffer)
+	s := &Subscription{C: ch, bus: b, name: name, topics: topics, ch: ch}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Close 取消订阅并关闭 C，可重复调用
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.ch)
+	}
+}
+
+// Dropped 因缓冲已满被丢弃的事件数
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscription) matches(topic string) bool {
+	for _, pattern := range s.topics {
+		if Match(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match 判断主题是否匹配订阅模式
+func Match(pattern, topic string) bool {
+	if pattern == "*" || pattern == topic {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	return ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(topic, prefix)
+}
+
+// Publish 以 Agent 为来源发布事件
+func (b *Bus) Publish(topic string, data any) {
+	b.PublishEvent(Event{Topic: topic, Source: SourceAgent, Data: data})
+}
+
+// PublishEvent 发布事件，未设置时间时使用当前时间
+func (b *Bus) PublishEvent(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if !s.matches(e.Topic) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			// 只在第 1、101、201… 次丢弃时记录，避免慢订阅方刷屏
+			if n := s.dropped.Add(1); n%100 == 1 {
+				log.Warn().Str("subscriber", s.name).Str("topic", e.Topic).Uint64("dropped", n).Msg("事件订阅缓冲已满，丢弃事件")
+			}
+		}
+	}
+}
+
+// Forward 把匹配的事件转发给 p（例如 webhook 投递器），返回停止转发的函数
+func (b *Bus) Forward(name string, p Publisher, topics ...string) (stop func()) {
+	sub := b.Subscribe(name, 0, topics...)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range sub.C {
+			p.Publish(e.Topic, e.Data)
+		}
+	}()
+	return func() {
+		sub.Close()
+		<-done
+	}
+}
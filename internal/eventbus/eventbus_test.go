@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"*", "metrics.sampled", true},
+		{"metrics.sampled", "metrics.sampled", true},
+		{"ip.*", "ip.blocked", true},
+		{"ip.*", "ip", false},
+		{"ip.*", "ipv6.blocked", false},
+		{"plugin.backup.*", "plugin.backup.done", true},
+		{"ip*", "ipv6.blocked", false},
+		{"alert", "alerts", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribeReceivesMatchingEvents(t *testing.T) {
+	bus := New()
+	sub := bus.Subscribe("test", 4, "ip.*")
+	defer sub.Close()
+
+	bus.Publish("metrics.sampled", nil)
+	bus.Publish("ip.blocked", "203.0.113.7")
+
+	select {
+	case e := <-sub.C:
+		if e.Topic != "ip.blocked" || e.Source != SourceAgent || e.Data != "203.0.113.7" || e.Time.IsZero() {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+	select {
+	case e := <-sub.C:
+		t.Errorf("unsubscribed topic delivered: %+v", e)
+	default:
+	}
+}
+
+func TestPublishDropsWhenBufferFull(t *testing.T) {
+	bus := New()
+	sub := bus.Subscribe("slow", 1, "*")
+
+	bus.Publish("a", nil)
+	bus.Publish("b", nil)
+	if sub.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", sub.Dropped())
+	}
+
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; !ok {
+		t.Error("buffered event lost on Close")
+	}
+	if _, ok := <-sub.C; ok {
+		t.Error("C not closed after Close")
+	}
+	// 取消订阅后发布不应 panic
+	bus.Publish("c", nil)
+}
@@ -0,0 +1,605 @@
+package executor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 支持的压缩包格式
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+	formatTar   = "tar" // 只用于解压
+)
+
+const (
+	defaultArchiveMaxBytes   = 10 * 1024 * 1024 * 1024 // 10GB
+	defaultArchiveMaxEntries = 100000
+)
+
+// ArchiveOptions 打包和解压选项
+type ArchiveOptions struct {
+	// Format 打包格式（tar.gz / zip），为空时按目标文件扩展名判断；解压时按文件内容识别
+	Format string
+	// MaxBytes 未压缩内容总大小上限，默认 10GB
+	MaxBytes int64
+	// MaxEntries 条目数上限，默认 100000
+	MaxEntries int64
+	// Progress 每处理完一个条目回调一次
+	Progress func(ArchiveProgress)
+}
+
+// ArchiveProgress 打包或解压进度
+type ArchiveProgress struct {
+	Files      int64
+	Bytes      int64  // 已处理的未压缩字节数
+	TotalBytes int64  // 未压缩总字节数，tar 解压时未知为 0
+	Current    string // 当前条目在包内的名称
+}
+
+// ArchiveResult 打包或解压结果
+type ArchiveResult struct {
+	Path  string // 生成的压缩包或解压目录
+	Files int64
+	Bytes int64
+}
+
+// archiveCounter 统计进度并执行大小、条目数限制
+type archiveCounter struct {
+	opts     ArchiveOptions
+	progress ArchiveProgress
+}
+
+func newArchiveCounter(opts ArchiveOptions) *archiveCounter {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultArchiveMaxBytes
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultArchiveMaxEntries
+	}
+	return &archiveCounter{opts: opts}
+}
+
+func (c *archiveCounter) entry(name string) error {
+	if c.progress.Files++; c.progress.Files > c.opts.MaxEntries {
+		return errcode.New(errcode.InvalidArgument, "条目数超过上限 %d", c.opts.MaxEntries)
+	}
+	c.progress.Current = name
+	return nil
+}
+
+// copy 复制条目内容，累计超过 MaxBytes 时返回错误（防止压缩炸弹）
+func (c *archiveCounter) copy(ctx context.Context, dst io.Writer, src io.Reader) error {
+	remaining := c.opts.MaxBytes - c.progress.Bytes
+	bufp := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufp)
+	n, err := io.CopyBuffer(dst, io.LimitReader(&ctxReader{ctx: ctx, r: src}, remaining+1), *bufp)
+	c.progress.Bytes += n
+	if err != nil {
+		return err
+	}
+	if n > remaining {
+		return errcode.New(errcode.InvalidArgument, "未压缩内容超过上限 %d 字节", c.opts.MaxBytes)
+	}
+	return nil
+}
+
+func (c *archiveCounter) report() {
+	if c.opts.Progress != nil {
+		c.opts.Progress(c.progress)
+	}
+}
+
+func (c *archiveCounter) result(path string) *ArchiveResult {
+	return &ArchiveResult{Path: path, Files: c.progress.Files, Bytes: c.progress.Bytes}
+}
+
+// isWithin 判断 path 是否位于 root 内（含 root 本身）
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// archiveEntry 待打包的条目
+type archiveEntry struct {
+	path string // 磁盘路径
+	name string // 包内名称，以 / 分隔
+	info fs.FileInfo
+}
+
+// CreateArchive 把 paths 打包到 dest，每个路径以其基本名作为包内顶层条目
+// 不跟随符号链接（tar.gz 保存链接本身，zip 跳过），跳过禁止访问的路径
+// 先写入同目录的临时文件，完成后再重命名，失败时不会留下不完整的压缩包
+func CreateArchive(ctx context.Context, paths []string, dest string, opts ArchiveOptions) (*ArchiveResult, error) {
+	if len(paths) == 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "未指定要打包的路径")
+	}
+	format := opts.Format
+	if format == "" {
+		format = archiveFormatFromName(dest)
+	}
+	if format != FormatTarGz && format != FormatZip {
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的打包格式: %s", format)
+	}
+	destPath, err := resolveWritePath(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newArchiveCounter(opts)
+	entries, err := collectArchiveEntries(ctx, paths, destPath, c)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), ".runixo-archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	done := false
+	defer func() {
+		if !done {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if format == FormatZip {
+		err = writeZip(ctx, tmp, entries, c)
+	} else {
+		err = writeTarGz(ctx, tmp, entries, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		return nil, fmt.Errorf("移动压缩包失败: %w", err)
+	}
+	done = true
+	return c.result(destPath), nil
+}
+
+func archiveFormatFromName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz
+	}
+	return ""
+}
+
+// collectArchiveEntries 遍历待打包路径并统计总大小，超出限制时在写入前失败
+func collectArchiveEntries(ctx context.Context, paths []string, destPath string, c *archiveCounter) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	for _, p := range paths {
+		root, err := resolveReadPath(p)
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Dir(root) == root {
+			return nil, errcode.New(errcode.InvalidArgument, "不能打包根目录")
+		}
+		base := filepath.Base(root)
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				if path == root {
+					return err
+				}
+				return nil // 跳过无法访问的文件
+			}
+			if path == destPath {
+				return nil
+			}
+			if path != root && pathValidator.ValidatePath(path) != nil {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() && !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 {
+				return nil // 设备文件、套接字等不打包
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			rel, _ := filepath.Rel(root, path)
+			entries = append(entries, archiveEntry{
+				path: path,
+				name: filepath.ToSlash(filepath.Join(base, rel)),
+				info: info,
+			})
+			if int64(len(entries)) > c.opts.MaxEntries {
+				return errcode.New(errcode.InvalidArgument, "条目数超过上限 %d", c.opts.MaxEntries)
+			}
+			if info.Mode().IsRegular() {
+				if c.progress.TotalBytes += info.Size(); c.progress.TotalBytes > c.opts.MaxBytes {
+					return errcode.New(errcode.InvalidArgument, "待打包内容超过上限 %d 字节", c.opts.MaxBytes)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func writeTarGz(ctx context.Context, w io.Writer, entries []archiveEntry, c *archiveCounter) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		if err := c.entry(e.name); err != nil {
+			return err
+		}
+		if err := writeTarEntry(ctx, tw, e, c); err != nil {
+			return err
+		}
+		c.report()
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func writeTarEntry(ctx context.Context, tw *tar.Writer, e archiveEntry, c *archiveCounter) error {
+	if !e.info.Mode().IsRegular() {
+		link := ""
+		if e.info.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			if link, err = os.Readlink(e.path); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(e.info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = e.name
+		if e.info.IsDir() {
+			hdr.Name += "/"
+		}
+		return tw.WriteHeader(hdr)
+	}
+
+	// 以打开后的文件信息写头部，避免遍历后文件大小变化导致 tar 写入失败
+	f, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = e.name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	before := c.progress.Bytes
+	if err := c.copy(ctx, tw, io.LimitReader(f, info.Size())); err != nil {
+		return err
+	}
+	if c.progress.Bytes-before != info.Size() {
+		return fmt.Errorf("文件在打包过程中被截断: %s", e.path)
+	}
+	return nil
+}
+
+func writeZip(ctx context.Context, w io.Writer, entries []archiveEntry, c *archiveCounter) error {
+	zw := zip.NewWriter(w)
+	for _, e := range entries {
+		if e.info.Mode()&fs.ModeSymlink != 0 {
+			continue
+		}
+		if err := c.entry(e.name); err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(e.info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = e.name
+		if e.info.IsDir() {
+			hdr.Name += "/"
+		} else {
+			hdr.Method = zip.Deflate
+		}
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if !e.info.IsDir() {
+			f, err := os.Open(e.path)
+			if err != nil {
+				return err
+			}
+			err = c.copy(ctx, fw, f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+		c.report()
+	}
+	return zw.Close()
+}
+
+// ExtractArchive 把 tar.gz、tar 或 zip 压缩包解压到 dest，格式按文件内容识别
+// 拒绝绝对路径、.. 以及经由符号链接逃逸出 dest 的条目；不恢复属主和 setuid/setgid 位
+func ExtractArchive(ctx context.Context, archive, dest string, opts ArchiveOptions) (*ArchiveResult, error) {
+	f, info, _, err := openRegularFile(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	destPath, err := resolveWritePath(dest)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(destPath, 0755); err != nil {
+		return nil, fmt.Errorf("创建解压目录失败: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(destPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := pathValidator.ValidatePathForWrite(root); err != nil {
+		return nil, fmt.Errorf("解压目录被拒绝: %w", err)
+	}
+
+	format, err := detectArchiveFormat(f)
+	if err != nil {
+		return nil, err
+	}
+	x := &extractor{root: root, counter: newArchiveCounter(opts)}
+	switch format {
+	case FormatZip:
+		var zr *zip.Reader
+		if zr, err = zip.NewReader(f, info.Size()); err != nil {
+			return nil, errcode.New(errcode.InvalidArgument, "zip 解析失败: %v", err)
+		}
+		err = x.extractZip(ctx, zr)
+	case FormatTarGz:
+		var gr *gzip.Reader
+		if gr, err = gzip.NewReader(&ctxReader{ctx: ctx, r: f}); err != nil {
+			return nil, errcode.New(errcode.InvalidArgument, "gzip 解析失败: %v", err)
+		}
+		defer gr.Close()
+		err = x.extractTar(ctx, tar.NewReader(gr))
+	default:
+		err = x.extractTar(ctx, tar.NewReader(&ctxReader{ctx: ctx, r: f}))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return x.counter.result(root), nil
+}
+
+// detectArchiveFormat 按文件头识别压缩包格式
+func detectArchiveFormat(f *os.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return FormatTarGz, nil
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return FormatZip, nil
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return formatTar, nil
+	}
+	return "", errcode.New(errcode.InvalidArgument, "无法识别的压缩包格式，支持 tar.gz、tar 和 zip")
+}
+
+type extractor struct {
+	root    string // 解压目录的真实路径
+	counter *archiveCounter
+}
+
+// target 返回条目的解压路径，并确认父目录解析符号链接后仍在解压目录内
+func (x *extractor) target(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(clean) {
+		return "", errcode.New(errcode.PathNotAllowed, "检测到路径遍历: %s", name)
+	}
+	if clean == "." {
+		return x.root, nil
+	}
+	parent := filepath.Dir(filepath.Join(x.root, clean))
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", err
+	}
+	realParent, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+	if !isWithin(x.root, realParent) {
+		return "", errcode.New(errcode.PathNotAllowed, "检测到符号链接路径遍历: %s", name)
+	}
+	target := filepath.Join(realParent, filepath.Base(clean))
+	if err := pathValidator.ValidatePathForWrite(target); err != nil {
+		return "", err
+	}
+	// 已存在的符号链接先删除，避免写入链接指向的文件
+	if fi, err := os.Lstat(target); err == nil && fi.Mode()&fs.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+func (x *extractor) extractTar(ctx context.Context, tr *tar.Reader) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return errcode.New(errcode.InvalidArgument, "读取 tar 失败: %v", err)
+		}
+		if err := x.counter.entry(hdr.Name); err != nil {
+			return err
+		}
+		target, err := x.target(hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(target, mode)
+		case tar.TypeReg:
+			err = x.writeFile(ctx, target, tr, mode)
+			if err == nil {
+				os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+			}
+		case tar.TypeSymlink:
+			err = x.symlink(hdr.Name, target, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.link(target, hdr.Linkname)
+		default:
+			// 设备文件、FIFO 等不解压
+		}
+		if err != nil {
+			return err
+		}
+		x.counter.report()
+	}
+}
+
+func (x *extractor) extractZip(ctx context.Context, zr *zip.Reader) error {
+	for _, zf := range zr.File {
+		x.counter.progress.TotalBytes += int64(zf.UncompressedSize64)
+	}
+	for _, zf := range zr.File {
+		if err := x.counter.entry(zf.Name); err != nil {
+			return err
+		}
+		target, err := x.target(zf.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.mkdir(target, mode)
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			if rc, err = zf.Open(); err != nil {
+				return errcode.New(errcode.InvalidArgument, "读取 zip 条目失败: %v", err)
+			}
+			err = x.writeFile(ctx, target, rc, mode)
+			rc.Close()
+			if err == nil {
+				os.Chtimes(target, zf.Modified, zf.Modified)
+			}
+		default:
+			// zip 中的符号链接等特殊条目不解压
+		}
+		if err != nil {
+			return err
+		}
+		x.counter.report()
+	}
+	return nil
+}
+
+func (x *extractor) mkdir(target string, mode fs.FileMode) error {
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	// 保证属主可写，后续条目才能写入该目录
+	return os.Chmod(target, mode.Perm()|0700)
+}
+
+func (x *extractor) writeFile(ctx context.Context, target string, r io.Reader, mode fs.FileMode) error {
+	// 先删除已存在的文件，避免截断与解压目录外共享 inode 的硬链接
+	os.Remove(target)
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := x.counter.copy(ctx, f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(target, mode.Perm())
+}
+
+// symlink 只允许指向解压目录内的相对链接
+func (x *extractor) symlink(name, target, linkname string) error {
+	rel := filepath.Join(filepath.Dir(filepath.FromSlash(name)), filepath.FromSlash(linkname))
+	if filepath.IsAbs(linkname) || !filepath.IsLocal(rel) {
+		return errcode.New(errcode.PathNotAllowed, "检测到符号链接路径遍历: %s -> %s", name, linkname)
+	}
+	os.Remove(target)
+	if err := os.Symlink(linkname, target); err != nil {
+		return err
+	}
+	// 经由其他链接时字面检查不够，按实际解析结果再确认一次
+	if real, err := filepath.EvalSymlinks(target); err == nil && !isWithin(x.root, real) {
+		os.Remove(target)
+		return errcode.New(errcode.PathNotAllowed, "检测到符号链接路径遍历: %s -> %s", name, linkname)
+	}
+	return nil
+}
+
+// link 创建硬链接，源必须是解压目录内已解压的文件
+func (x *extractor) link(target, linkname string) error {
+	clean := filepath.Clean(filepath.FromSlash(linkname))
+	if !filepath.IsLocal(clean) {
+		return errcode.New(errcode.PathNotAllowed, "检测到硬链接路径遍历: %s", linkname)
+	}
+	src, err := filepath.EvalSymlinks(filepath.Join(x.root, clean))
+	if err != nil {
+		return err
+	}
+	if !isWithin(x.root, src) {
+		return errcode.New(errcode.PathNotAllowed, "检测到硬链接路径遍历: %s", linkname)
+	}
+	os.Remove(target)
+	return os.Link(src, target)
+}
@@ -0,0 +1,261 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	defaultMaxBackups      = 5
+	defaultValidateTimeout = 30 * time.Second
+	// validatePlaceholder 校验参数中的占位符，替换为尚未替换原文件的临时文件路径
+	validatePlaceholder = "{file}"
+	backupTimeLayout    = "20060102-150405"
+)
+
+var backupSuffix = regexp.MustCompile(`^\.[0-9]{8}-[0-9]{6}(-[0-9]+)?\.bak$`)
+
+// AtomicWriteOptions 原子写入选项
+type AtomicWriteOptions struct {
+	// Mode 文件权限，为 0 时沿用原文件的权限，新文件为 0644
+	Mode       int64
+	CreateDirs bool
+	// Backup 保留原内容的备份 <path>.<时间戳>.bak
+	Backup bool
+	// MaxBackups 每个文件最多保留的备份数，默认 5
+	MaxBackups int
+	// Validate 校验命令及参数，如 ["nginx", "-t"]，同样受命令白名单限制
+	// 参数包含 {file} 时先校验临时文件再替换原文件；否则替换后校验，失败时恢复原内容
+	Validate        []string
+	ValidateTimeout time.Duration
+	// Sandbox 不为 nil 时校验命令在沙箱中执行
+	Sandbox *Sandbox
+}
+
+// AtomicWriteResult 原子写入结果
+type AtomicWriteResult struct {
+	Path           string // 实际写入的路径，符号链接时为链接目标
+	BackupPath     string
+	ValidateOutput string
+}
+
+// WriteFileAtomic 原子写入文件：写入同目录的临时文件并 fsync，再重命名替换原文件
+// 读取方只会看到完整的旧内容或新内容；原文件的权限和属主被保留
+func WriteFileAtomic(ctx context.Context, path string, content []byte, opts AtomicWriteOptions) (*AtomicWriteResult, error) {
+	cleanPath, err := resolveWritePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxWriteSize {
+		return nil, errcode.New(errcode.InvalidArgument, "写入内容过大，超过 50MB 限制")
+	}
+	// 替换符号链接本身会破坏 sites-enabled 之类的链接，改为写入链接目标
+	if realPath, err := filepath.EvalSymlinks(cleanPath); err == nil && realPath != cleanPath {
+		if err := pathValidator.ValidatePathForWrite(realPath); err != nil {
+			return nil, fmt.Errorf("符号链接目标路径被拒绝: %w", err)
+		}
+		cleanPath = realPath
+	}
+	dir := filepath.Dir(cleanPath)
+	if opts.CreateDirs {
+		if err := pathValidator.ValidatePathForWrite(dir); err != nil {
+			return nil, fmt.Errorf("目录路径被拒绝: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("创建目录失败: %w", err)
+		}
+	}
+
+	existing, err := os.Stat(cleanPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if existing != nil && !existing.Mode().IsRegular() {
+		return nil, errcode.New(errcode.InvalidArgument, "%s 不是普通文件", path)
+	}
+	mode := fs.FileMode(opts.Mode).Perm()
+	if mode == 0 {
+		mode = 0644
+		if existing != nil {
+			mode = existing.Mode().Perm()
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(cleanPath)+".runixo-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if existing != nil {
+		copyOwner(existing, tmp.Name())
+	}
+
+	result := &AtomicWriteResult{Path: cleanPath}
+	inPlace := len(opts.Validate) > 0 && !strings.Contains(strings.Join(opts.Validate[1:], "\x00"), validatePlaceholder)
+	if len(opts.Validate) > 0 && !inPlace {
+		if result.ValidateOutput, err = runValidation(ctx, opts, tmp.Name()); err != nil {
+			return result, err
+		}
+	}
+
+	// 替换后校验需要用备份回滚，即使调用方没有要求保留备份
+	var backup string
+	if existing != nil && (opts.Backup || inPlace) {
+		if backup, err = backupFile(ctx, cleanPath, existing); err != nil {
+			return nil, fmt.Errorf("备份原文件失败: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), cleanPath); err != nil {
+		if backup != "" && !opts.Backup {
+			os.Remove(backup)
+		}
+		return nil, fmt.Errorf("替换文件失败: %w", err)
+	}
+	syncDir(dir)
+
+	if inPlace {
+		if result.ValidateOutput, err = runValidation(ctx, opts, cleanPath); err != nil {
+			if backup != "" {
+				os.Rename(backup, cleanPath)
+			} else {
+				os.Remove(cleanPath)
+			}
+			syncDir(dir)
+			return result, err
+		}
+	}
+
+	if backup != "" {
+		if !opts.Backup {
+			os.Remove(backup)
+		} else {
+			result.BackupPath = backup
+			pruneBackups(cleanPath, opts.MaxBackups)
+		}
+	}
+	return result, nil
+}
+
+// runValidation 执行校验命令，退出码非 0 时返回 ValidationFailed
+func runValidation(ctx context.Context, opts AtomicWriteOptions, file string) (string, error) {
+	args := make([]string, 0, len(opts.Validate)-1)
+	for _, a := range opts.Validate[1:] {
+		args = append(args, strings.ReplaceAll(a, validatePlaceholder, file))
+	}
+	timeout := opts.ValidateTimeout
+	if timeout <= 0 {
+		timeout = defaultValidateTimeout
+	}
+	res, err := Execute(ctx, opts.Validate[0], args, Options{Timeout: timeout, Sandbox: opts.Sandbox})
+	if err != nil {
+		return "", fmt.Errorf("执行校验命令失败: %w", err)
+	}
+	output := strings.TrimSpace(res.Stdout + res.Stderr)
+	if res.ExitCode != 0 {
+		return output, errcode.New(errcode.ValidationFailed, "校验命令失败（退出码 %d）: %s", res.ExitCode, output)
+	}
+	return output, nil
+}
+
+// backupFile 为原文件创建备份，优先使用硬链接：重命名替换后原 inode 只由备份引用，无需复制
+func backupFile(ctx context.Context, path string, info fs.FileInfo) (string, error) {
+	base := path + "." + time.Now().Format(backupTimeLayout)
+	backup := base + ".bak"
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s-%d.bak", base, i)
+	}
+	if err := os.Link(path, backup); err == nil {
+		return backup, nil
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(backup, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, &ctxReader{ctx: ctx, r: in})
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(backup)
+		return "", err
+	}
+	copyOwner(info, backup)
+	return backup, nil
+}
+
+// pruneBackups 只保留最新的 max 个备份
+func pruneBackups(path string, max int) {
+	if max <= 0 {
+		max = defaultMaxBackups
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return
+	}
+	prefix := filepath.Base(path)
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, prefix) && backupSuffix.MatchString(name[len(prefix):]) {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= max {
+		return
+	}
+	// 时间戳格式保证按名称排序即按时间排序
+	sort.Strings(backups)
+	for _, name := range backups[:len(backups)-max] {
+		os.Remove(filepath.Join(filepath.Dir(path), name))
+	}
+}
+
+// copyOwner 尽力把 info 的属主应用到 path，没有权限时保持当前属主
+func copyOwner(info fs.FileInfo, path string) {
+	if uid, gid, ok := fileOwner(info); ok {
+		os.Lchown(path, uid, gid)
+	}
+}
+
+// syncDir fsync 目录，使重命名在掉电后同样生效
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 32
+	// MaxBatchCommands 单个批次最多包含的命令数
+	MaxBatchCommands = 100
+)
+
+// 批量执行中单条命令的状态
+const (
+	BatchSucceeded = "succeeded" // 退出码为 0
+	BatchFailed    = "failed"    // 退出码非 0、未通过安全检查或超时
+	BatchError     = "error"     // 命令无法启动等执行错误
+	BatchCancelled = "cancelled" // 因 FailFast 或调用方取消被终止
+	BatchSkipped   = "skipped"   // 因 FailFast 或调用方取消未执行
+)
+
+// BatchCommand 批量执行中的一条命令，Options.Timeout 为该命令单独的超时
+type BatchCommand struct {
+	Command string
+	Args    []string
+	Options Options
+}
+
+// BatchOptions 批量执行选项
+type BatchOptions struct {
+	// Concurrency 同时执行的命令数，默认 4，最大 32
+	Concurrency int
+	// FailFast 任一命令未成功时终止正在执行的命令，尚未开始的命令不再执行
+	FailFast bool
+}
+
+// BatchResult 单条命令的执行结果，与 BatchCommand 按下标一一对应
+type BatchResult struct {
+	Status string
+	// Result 跳过的命令为 nil
+	Result *Result
+	Err    error
+}
+
+// ExecuteBatch 按并发上限执行一组命令（每条都经过与 Execute 相同的安全检查），
+// 等待全部结束后按输入顺序返回结果
+func ExecuteBatch(ctx context.Context, cmds []BatchCommand, opts BatchOptions) ([]BatchResult, error) {
+	if len(cmds) == 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "批量执行的命令为空")
+	}
+	if len(cmds) > MaxBatchCommands {
+		return nil, errcode.New(errcode.InvalidArgument, "批量执行最多 %d 条命令", MaxBatchCommands)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]BatchResult, len(cmds))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, c := range cmds {
+		// 按顺序占用并发名额，取消后剩余命令全部跳过
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Status: BatchSkipped}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c BatchCommand) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r := runBatchCommand(ctx, c)
+			if opts.FailFast && r.Status != BatchSucceeded && r.Status != BatchCancelled {
+				cancel()
+			}
+			results[i] = r
+		}(i, c)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// runBatchCommand 执行单条命令并归类状态
+func runBatchCommand(ctx context.Context, c BatchCommand) BatchResult {
+	result, err := Execute(ctx, c.Command, c.Args, c.Options)
+	switch {
+	case ctx.Err() != nil && (err != nil || result.ExitCode != 0):
+		// 批次已取消，命令被终止，退出码没有意义
+		return BatchResult{Status: BatchCancelled, Result: result, Err: err}
+	case err != nil:
+		return BatchResult{Status: BatchError, Err: err}
+	case result.ExitCode != 0:
+		return BatchResult{Status: BatchFailed, Result: result}
+	}
+	return BatchResult{Status: BatchSucceeded, Result: result}
+}
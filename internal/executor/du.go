@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	defaultDirSizeDepth   = 1
+	maxDirSizeDepth       = 5
+	defaultDirSizeTop     = 20
+	defaultDirSizeEntries = 1000000
+)
+
+// DirSizeOptions 目录占用统计选项
+type DirSizeOptions struct {
+	// Depth 返回子目录明细的层数，默认 1，上限 5；更深的目录只计入上层的合计
+	Depth int
+	// Top 每层只保留占用最大的 N 个子目录，默认 20
+	Top int
+	// MaxEntries 最多遍历的条目数，默认 100 万，达到后停止并标记 truncated
+	MaxEntries int64
+	// CrossMounts 进入挂载在其下的其他文件系统，默认与 du -x 一样跳过
+	CrossMounts bool
+}
+
+// DirSize 目录占用
+type DirSize struct {
+	Path     string
+	Size     int64 // 文件大小之和
+	Disk     int64 // 实际占用的磁盘空间，稀疏文件会小于 Size
+	Files    int64
+	Dirs     int64
+	Children []*DirSize // 按 Disk 降序
+}
+
+// GetDirectorySize 统计目录占用并给出最大子目录的明细
+// 不跟随符号链接，硬链接的文件只计一次，跳过禁止访问的路径
+// ctx 超时或达到 MaxEntries 时返回已统计的部分结果，truncated 为 true
+func GetDirectorySize(ctx context.Context, path string, opts DirSizeOptions) (*DirSize, bool, error) {
+	cleanPath, err := resolveReadPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	info, err := os.Lstat(cleanPath)
+	if os.IsNotExist(err) {
+		return nil, false, errcode.New(errcode.NotFound, "路径不存在: %s", path)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !info.IsDir() {
+		return nil, false, errcode.New(errcode.InvalidArgument, "%s 不是目录", path)
+	}
+
+	if opts.Depth <= 0 {
+		opts.Depth = defaultDirSizeDepth
+	}
+	if opts.Depth > maxDirSizeDepth {
+		opts.Depth = maxDirSizeDepth
+	}
+	if opts.Top <= 0 {
+		opts.Top = defaultDirSizeTop
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultDirSizeEntries
+	}
+
+	w := &duWalker{ctx: ctx, opts: opts, seen: make(map[[2]uint64]struct{})}
+	w.rootDev, _, _, _, _ = fileUsage(info)
+	root := w.walk(cleanPath, info, 0)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, false, ctx.Err()
+	}
+	return root, w.truncated, nil
+}
+
+type duWalker struct {
+	ctx       context.Context
+	opts      DirSizeOptions
+	rootDev   uint64
+	seen      map[[2]uint64]struct{} // 已计入的硬链接 (dev, ino)
+	entries   int64
+	truncated bool
+}
+
+func (w *duWalker) stop() bool {
+	if w.truncated {
+		return true
+	}
+	if w.entries >= w.opts.MaxEntries || w.ctx.Err() != nil {
+		w.truncated = true
+	}
+	return w.truncated
+}
+
+func (w *duWalker) walk(path string, info os.FileInfo, depth int) *DirSize {
+	node := &DirSize{Path: path, Dirs: 1}
+	_, _, _, node.Disk, _ = fileUsage(info)
+
+	// 无权读取的目录只计入目录本身
+	entries, _ := os.ReadDir(path)
+	for _, e := range entries {
+		if w.stop() {
+			break
+		}
+		w.entries++
+		child := filepath.Join(path, e.Name())
+		if pathValidator.ValidatePath(child) != nil {
+			continue
+		}
+		childInfo, err := e.Info()
+		if err != nil {
+			continue
+		}
+		dev, ino, nlink, disk, ok := fileUsage(childInfo)
+		if childInfo.IsDir() {
+			if ok && !w.opts.CrossMounts && dev != w.rootDev {
+				continue
+			}
+			sub := w.walk(child, childInfo, depth+1)
+			node.Size += sub.Size
+			node.Disk += sub.Disk
+			node.Files += sub.Files
+			node.Dirs += sub.Dirs
+			if depth+1 <= w.opts.Depth {
+				node.Children = append(node.Children, sub)
+			}
+			continue
+		}
+		if ok && nlink > 1 {
+			key := [2]uint64{dev, ino}
+			if _, dup := w.seen[key]; dup {
+				continue
+			}
+			w.seen[key] = struct{}{}
+		}
+		node.Files++
+		node.Size += childInfo.Size()
+		node.Disk += disk
+	}
+
+	sort.Slice(node.Children, func(i, j int) bool {
+		return node.Children[i].Disk > node.Children[j].Disk
+	})
+	if len(node.Children) > w.opts.Top {
+		node.Children = node.Children[:w.opts.Top]
+	}
+	return node
+}
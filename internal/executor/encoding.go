@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 换行风格
+const (
+	NewlineLF    = "lf"
+	NewlineCRLF  = "crlf"
+	NewlineMixed = "mixed"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// textSniffSize 检测现有文件换行风格时读取的长度
+const textSniffSize = 64 * 1024
+
+// TextInfo 文本内容的检测结果
+type TextInfo struct {
+	BOM     bool
+	Newline string // lf / crlf / mixed，没有换行时为空
+	Binary  bool   // 不是合法的 UTF-8 或包含 NUL
+}
+
+// DetectText 检测 BOM、换行风格和是否为二进制内容
+func DetectText(content []byte) TextInfo {
+	info := TextInfo{BOM: bytes.HasPrefix(content, utf8BOM)}
+	body := content
+	if info.BOM {
+		body = content[len(utf8BOM):]
+	}
+	info.Binary = bytes.IndexByte(body, 0) >= 0 || !utf8.Valid(body)
+
+	crlf := bytes.Count(body, []byte("\r\n"))
+	lf := bytes.Count(body, []byte("\n")) - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		info.Newline = NewlineMixed
+	case crlf > 0:
+		info.Newline = NewlineCRLF
+	case lf > 0:
+		info.Newline = NewlineLF
+	}
+	return info
+}
+
+// DecodeText 去掉 BOM 后按 UTF-8 返回文本，换行原样保留；二进制内容返回空字符串
+func DecodeText(content []byte) (string, TextInfo) {
+	info := DetectText(content)
+	if info.Binary {
+		return "", info
+	}
+	if info.BOM {
+		content = content[len(utf8BOM):]
+	}
+	return string(content), info
+}
+
+// TrimPartialRunes 去掉范围读取时被切断的首尾多字节字符，返回剩余内容和开头跳过的字节数
+// 内容本身不是 UTF-8 时原样返回
+func TrimPartialRunes(content []byte) ([]byte, int) {
+	skip := 0
+	for skip < len(content) && skip < utf8.UTFMax-1 && !utf8.RuneStart(content[skip]) {
+		skip++
+	}
+	end := len(content)
+	for i := 1; i < utf8.UTFMax && end-i >= skip; i++ {
+		if utf8.RuneStart(content[end-i]) {
+			if !utf8.FullRune(content[end-i : end]) {
+				end -= i
+			}
+			break
+		}
+	}
+	trimmed := content[skip:end]
+	if !utf8.Valid(trimmed) {
+		return content, 0
+	}
+	return trimmed, skip
+}
+
+// TextWriteOptions 以文本方式写入的选项
+type TextWriteOptions struct {
+	// Newline 为 lf / crlf 时转换换行；为空时沿用现有文件的风格（现有文件为 CRLF 时转换），新文件原样写入
+	Newline string
+	// BOM 写入 UTF-8 BOM；现有文件带 BOM 时同样保留
+	BOM bool
+}
+
+// EncodeText 把文本按选项编码为要写入 path 的内容
+// 浏览器编辑器通常把换行规范为 \n 并丢掉 BOM，沿用现有文件的风格可以避免改写整个文件
+func EncodeText(path, text string, opts TextWriteOptions) ([]byte, error) {
+	if !utf8.ValidString(text) {
+		return nil, errcode.New(errcode.InvalidArgument, "文本不是合法的 UTF-8")
+	}
+	switch opts.Newline {
+	case "", NewlineLF, NewlineCRLF:
+	default:
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的换行风格: %s", opts.Newline)
+	}
+
+	newline, bom := opts.Newline, opts.BOM
+	if existing := sniffExisting(path); existing != nil {
+		if newline == "" && existing.Newline == NewlineCRLF {
+			newline = NewlineCRLF
+		}
+		bom = bom || existing.BOM
+	}
+
+	body := []byte(text)
+	if bytes.HasPrefix(body, utf8BOM) {
+		body, bom = body[len(utf8BOM):], true
+	}
+	switch newline {
+	case NewlineLF:
+		body = bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+	case NewlineCRLF:
+		body = bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+		body = bytes.ReplaceAll(body, []byte("\n"), []byte("\r\n"))
+	}
+	if bom {
+		body = append(append([]byte{}, utf8BOM...), body...)
+	}
+	return body, nil
+}
+
+// sniffExisting 检测现有文件开头部分的文本风格，文件不存在或不可读时返回 nil
+func sniffExisting(path string) *TextInfo {
+	f, _, _, err := openRegularFile(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	head, err := io.ReadAll(io.LimitReader(f, textSniffSize))
+	if err != nil && err != io.EOF {
+		return nil
+	}
+	head, _ = TrimPartialRunes(head)
+	info := DetectText(head)
+	if info.Binary {
+		return nil
+	}
+	return &info
+}
@@ -2,17 +2,20 @@ package executor
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
+	"github.com/runixo/agent/internal/errcode"
 	"github.com/runixo/agent/internal/security"
 )
 
@@ -20,6 +23,8 @@ import (
 var (
 	cmdValidator  *security.CommandValidator
 	pathValidator *security.PathValidator
+	// elevation 为 nil 时 Sudo 请求按内置的 sudo 白名单校验
+	elevation *security.Elevation
 )
 
 // 缓冲区池：复用命令输出读取缓冲区
@@ -36,12 +41,32 @@ func init() {
 	pathValidator = security.NewPathValidator(config)
 }
 
+// SetElevation 设置提权策略，启动时调用
+// 设置后 Options.Sudo 按提权规则校验，服务管理等内置操作在规则允许时同样通过 sudo 执行
+func SetElevation(e *security.Elevation) {
+	elevation = e
+}
+
+const (
+	maxStdinSize  = 10 * 1024 * 1024 // 标准输入的最大长度
+	maxOutputSize = 10 * 1024 * 1024 // Execute 缓存的输出上限
+)
+
 // Options 执行选项
 type Options struct {
 	WorkingDir string
-	Env        map[string]string
-	Timeout    time.Duration
-	Sudo       bool
+	// Env 追加到（过滤后的）Agent 环境变量之后，同名时覆盖
+	Env     map[string]string
+	Timeout time.Duration
+	Sudo    bool
+	// Stdin 写入命令标准输入的内容，写完后关闭标准输入
+	Stdin  []byte
+	Limits Limits
+	// Sandbox 不为 nil 时在沙箱中执行，不能与 Sudo 同时使用
+	Sandbox *Sandbox
+
+	// scriptDir ExecuteScript 在沙箱中执行时脚本所在的临时目录
+	scriptDir string
 }
 
 // Result 执行结果
@@ -67,7 +92,8 @@ type FileInfo struct {
 // ServiceInfo 服务信息
 type ServiceInfo struct {
 	Name        string
-	Status      string
+	Status      string // 子状态，如 running / exited / dead
+	Active      string // 激活状态，如 active / inactive / failed
 	Description string
 	Enabled     bool
 	Pid         int32
@@ -76,128 +102,246 @@ type ServiceInfo struct {
 
 // Execute 执行命令（带安全检查）
 func Execute(ctx context.Context, command string, args []string, opts Options) (*Result, error) {
-	// 安全检查：验证命令
-	if err := cmdValidator.ValidateCommand(command, args, opts.Sudo); err != nil {
+	if denied := checkCommand(command, args, opts); denied != nil {
+		return denied, nil
+	}
+	return run(ctx, command, args, opts)
+}
+
+// run 执行已通过安全检查的命令并缓存输出
+func run(ctx context.Context, command string, args []string, opts Options) (*Result, error) {
+	start := time.Now()
+
+	// 输出超过限制时通过 cancel 终止命令
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd := buildCommand(ctx, command, args, opts)
+
+	// 输出缓存在内存中，未设置限制时也不超过 maxOutputSize
+	maxOutput := opts.Limits.MaxOutput
+	if maxOutput <= 0 || maxOutput > maxOutputSize {
+		maxOutput = maxOutputSize
+	}
+	budget := newOutputBudget(maxOutput, cancel)
+	stdout := &cappedWriter{budget: budget}
+	stderr := &cappedWriter{budget: budget}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	lim, err := startCommand(cmd, opts.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("启动命令失败: %w", err)
+	}
+	err = cmd.Wait()
+	oom := lim.release()
+
+	result := &Result{
+		Stdout:     stdout.buf.String(),
+		Stderr:     stderr.buf.String(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	if msg := limitMessage(opts.Limits, cmd.ProcessState, oom, budget); msg != "" {
+		result.Stderr += "\n[已终止] " + msg
+	}
+	if err := exitStatus(ctx, err, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkCommand 安全检查，不通过时返回 ExitCode 为 -1 的结果
+func checkCommand(command string, args []string, opts Options) *Result {
+	// 安全检查：验证命令；配置了提权策略时 sudo 由提权规则决定
+	if err := cmdValidator.ValidateCommand(command, args, opts.Sudo && elevation == nil); err != nil {
 		return &Result{
 			ExitCode: -1,
 			Stderr:   fmt.Sprintf("安全检查失败: %s", err.Error()),
-		}, nil
+		}
+	}
+	if opts.Sudo && elevation != nil {
+		if _, err := elevation.Check(command, args); err != nil {
+			return &Result{
+				ExitCode: -1,
+				Stderr:   fmt.Sprintf("安全检查失败: %s", err.Error()),
+			}
+		}
 	}
+	return checkOptions(opts)
+}
 
+// checkOptions 检查工作目录、环境变量和标准输入
+func checkOptions(opts Options) *Result {
 	// 安全检查：验证工作目录
 	if opts.WorkingDir != "" {
 		if err := pathValidator.ValidatePath(opts.WorkingDir); err != nil {
 			return &Result{
 				ExitCode: -1,
 				Stderr:   fmt.Sprintf("工作目录安全检查失败: %s", err.Error()),
-			}, nil
+			}
 		}
 	}
 
-	start := time.Now()
+	// 调用方传入的环境变量同样不能包含可注入代码的变量
+	for k := range opts.Env {
+		if !IsValidEnvVar(k) || isDangerousEnvVar(k) {
+			return &Result{
+				ExitCode: -1,
+				Stderr:   fmt.Sprintf("不允许设置环境变量: %s", k),
+			}
+		}
+	}
+	if opts.Sandbox != nil {
+		if !sandboxSupported {
+			return &Result{ExitCode: -1, Stderr: "当前平台不支持沙箱执行"}
+		}
+		if opts.Sudo {
+			return &Result{ExitCode: -1, Stderr: "沙箱中不支持 sudo"}
+		}
+	}
+	if len(opts.Stdin) > maxStdinSize {
+		return &Result{
+			ExitCode: -1,
+			Stderr:   "标准输入超过 10MB 限制",
+		}
+	}
+	return nil
+}
 
-	// 创建带超时的上下文
-	if opts.Timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
-		defer cancel()
+// exitStatus 根据 cmd.Wait 的错误填充退出码，非退出码类错误原样返回
+func exitStatus(ctx context.Context, err error, result *Result) error {
+	// 超时后命令可能处理 SIGTERM 并正常退出，同样按超时返回
+	if ctx.Err() == context.DeadlineExceeded {
+		result.ExitCode = -1
+		if result.Stderr != "" {
+			result.Stderr += "\n"
+		}
+		result.Stderr += "命令执行超时"
+		return nil
+	}
+	// 命令已正常退出，只是后台进程仍持有输出管道
+	if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		return nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ExitCode()
+		return nil
 	}
+	return err
+}
 
-	// 构建命令
+// buildCommand 构建命令（sudo、工作目录、过滤后的环境变量、独立的进程组）
+func buildCommand(ctx context.Context, command string, args []string, opts Options) *exec.Cmd {
 	var cmd *exec.Cmd
-	if opts.Sudo {
+	if opts.Sandbox != nil {
+		cmd = sandboxCommand(ctx, command, args, opts)
+	} else if opts.Sudo && elevation != nil {
+		name, allArgs := elevation.Command(command, args)
+		cmd = exec.CommandContext(ctx, name, allArgs...)
+	} else if opts.Sudo {
 		allArgs := append([]string{command}, args...)
 		cmd = exec.CommandContext(ctx, "sudo", allArgs...)
 	} else {
 		cmd = exec.CommandContext(ctx, command, args...)
 	}
 
-	// 设置工作目录
-	if opts.WorkingDir != "" {
+	// 设置工作目录（沙箱设置了 Root 时由辅助进程在 chroot 后切换）
+	if opts.WorkingDir != "" && (opts.Sandbox == nil || opts.Sandbox.Root == "") {
 		cmd.Dir = opts.WorkingDir
 	}
 
-	// 设置环境变量（过滤危险变量）
+	// 设置环境变量（过滤危险变量，opts.Env 已在 checkCommand 中校验）
 	cmd.Env = FilterEnvVars(os.Environ())
 	for k, v := range opts.Env {
-		// 验证环境变量名
-		if IsValidEnvVar(k) {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
-		}
-	}
-
-	// 捕获输出
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("创建 stdout 管道失败: %w", err)
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("创建 stderr 管道失败: %w", err)
-	}
-
-	// 启动命令
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("启动命令失败: %w", err)
+	if opts.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(opts.Stdin)
 	}
+	setProcessGroup(cmd)
+	return cmd
+}
 
-	// 读取输出（限制大小，处理错误）
-	const maxOutputSize = 10 * 1024 * 1024 // 10MB
-	stdoutBytes, stdoutErr := io.ReadAll(io.LimitReader(stdout, maxOutputSize))
-	stderrBytes, stderrErr := io.ReadAll(io.LimitReader(stderr, maxOutputSize))
-
-	// 等待完成
-	err = cmd.Wait()
+// dangerousEnvVars 可以向子进程注入代码的环境变量
+var dangerousEnvVars = map[string]bool{
+	"BASH_ENV":       true,
+	"ENV":            true,
+	"PROMPT_COMMAND": true,
+	"SHELLOPTS":      true, // 可开启 xtrace，配合 PS4 中的命令替换执行代码
+	"BASHOPTS":       true,
+	"PS4":            true,
+	"GCONV_PATH":     true, // glibc 从该目录加载字符集转换模块
+	// 语言运行时路径注入
+	"PYTHONPATH": true,
+	"PERL5LIB":   true,
+	"PERLLIB":    true,
+	"NODE_PATH":  true,
+	"RUBYLIB":    true,
+	"CLASSPATH":  true,
+	"LUA_PATH":   true,
+	"LUA_CPATH":  true,
+	// 语言运行时启动选项和启动脚本
+	"PYTHONHOME":        true,
+	"PYTHONSTARTUP":     true,
+	"PYTHONWARNINGS":    true, // 警告类别会被导入，可加载任意模块
+	"PERL5OPT":          true,
+	"PERL5DB":           true,
+	"NODE_OPTIONS":      true,
+	"RUBYOPT":           true,
+	"LUA_INIT":          true,
+	"JAVA_TOOL_OPTIONS": true,
+	"_JAVA_OPTIONS":     true,
+	"JDK_JAVA_OPTIONS":  true,
+	// 被调用的程序会执行其中的命令
+	"GIT_SSH":           true,
+	"GIT_SSH_COMMAND":   true,
+	"GIT_EXEC_PATH":     true,
+	"GIT_ASKPASS":       true,
+	"GIT_PROXY_COMMAND": true,
+	"GIT_EXTERNAL_DIFF": true,
+	"SSH_ASKPASS":       true,
+	"SUDO_ASKPASS":      true,
+	"PAGER":             true,
+	"SYSTEMD_PAGER":     true,
+	"LESSOPEN":          true,
+	"LESSCLOSE":         true,
+	"EDITOR":            true,
+	"VISUAL":            true,
+}
 
-	result := &Result{
-		Stdout:     string(stdoutBytes),
-		Stderr:     string(stderrBytes),
-		DurationMs: time.Since(start).Milliseconds(),
-	}
+// dangerousEnvPrefixes 整组禁止的环境变量前缀
+var dangerousEnvPrefixes = []string{
+	"LD_",         // 动态链接器：LD_PRELOAD、LD_AUDIT、LD_LIBRARY_PATH 等
+	"DYLD_",       // macOS 动态链接器
+	"BASH_FUNC_",  // bash 导出的函数，可覆盖命令
+	"GIT_CONFIG_", // GIT_CONFIG_KEY_n / GIT_CONFIG_VALUE_n 可设置 core.sshCommand 等
+}
 
-	if stdoutErr != nil || stderrErr != nil {
-		result.Stderr += "\n[警告] 读取输出时发生错误"
+// isDangerousEnvVar 环境变量是否可以向子进程注入代码
+func isDangerousEnvVar(name string) bool {
+	if dangerousEnvVars[name] {
+		return true
 	}
-
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitErr.ExitCode()
-		} else if ctx.Err() == context.DeadlineExceeded {
-			result.ExitCode = -1
-			result.Stderr = "命令执行超时"
-		} else {
-			return nil, err
+	for _, prefix := range dangerousEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
 		}
 	}
-
-	return result, nil
+	return false
 }
 
 // FilterEnvVars 过滤危险的环境变量
 func FilterEnvVars(envs []string) []string {
-	dangerousVars := map[string]bool{
-		"LD_PRELOAD":            true,
-		"LD_LIBRARY_PATH":      true,
-		"DYLD_INSERT_LIBRARIES": true,
-		"BASH_ENV":              true,
-		"ENV":                   true,
-		"PROMPT_COMMAND":        true,
-		// 语言运行时路径注入
-		"PYTHONPATH":  true,
-		"PERL5LIB":   true,
-		"PERLLIB":     true,
-		"NODE_PATH":   true,
-		"RUBYLIB":     true,
-		"CLASSPATH":   true,
-		"LUA_PATH":    true,
-		"LUA_CPATH":   true,
-	}
-
 	var filtered []string
 	for _, env := range envs {
 		parts := strings.SplitN(env, "=", 2)
-		if len(parts) == 2 && !dangerousVars[parts[0]] {
+		if len(parts) == 2 && !isDangerousEnvVar(parts[0]) {
 			filtered = append(filtered, env)
 		}
 	}
@@ -221,16 +365,16 @@ func IsValidEnvVar(name string) bool {
 	return true
 }
 
-// ReadFile 读取文件（带安全检查）
-func ReadFile(path string) ([]byte, *FileInfo, error) {
+// resolveReadPath 清理并校验读取路径，符号链接解析为目标路径后再次校验
+func resolveReadPath(path string) (string, error) {
 	// 安全检查
 	cleanPath, err := security.SanitizePath(path)
 	if err != nil {
-		return nil, nil, fmt.Errorf("路径安全检查失败: %w", err)
+		return "", fmt.Errorf("路径安全检查失败: %w", err)
 	}
 
 	if err := pathValidator.ValidatePath(cleanPath); err != nil {
-		return nil, nil, fmt.Errorf("路径访问被拒绝: %w", err)
+		return "", fmt.Errorf("路径访问被拒绝: %w", err)
 	}
 
 	// 检查是否为符号链接（防止符号链接攻击）
@@ -238,10 +382,33 @@ func ReadFile(path string) ([]byte, *FileInfo, error) {
 	if err == nil && realPath != cleanPath {
 		// 重新验证真实路径
 		if err := pathValidator.ValidatePath(realPath); err != nil {
-			return nil, nil, fmt.Errorf("符号链接目标路径被拒绝: %w", err)
+			return "", fmt.Errorf("符号链接目标路径被拒绝: %w", err)
 		}
 		cleanPath = realPath
 	}
+	return cleanPath, nil
+}
+
+const maxWriteSize = 50 * 1024 * 1024 // WriteFile / WriteFileAtomic 单次写入上限 50MB
+
+// resolveWritePath 清理并校验写入路径
+func resolveWritePath(path string) (string, error) {
+	cleanPath, err := security.SanitizePath(path)
+	if err != nil {
+		return "", fmt.Errorf("路径安全检查失败: %w", err)
+	}
+	if err := pathValidator.ValidatePathForWrite(cleanPath); err != nil {
+		return "", fmt.Errorf("写入路径被拒绝: %w", err)
+	}
+	return cleanPath, nil
+}
+
+// ReadFile 读取文件（带安全检查）
+func ReadFile(path string) ([]byte, *FileInfo, error) {
+	cleanPath, err := resolveReadPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	info, err := os.Stat(cleanPath)
 	if err != nil {
@@ -249,13 +416,12 @@ func ReadFile(path string) ([]byte, *FileInfo, error) {
 	}
 
 	if info.IsDir() {
-		return nil, nil, fmt.Errorf("路径是目录而非文件")
+		return nil, nil, errcode.New(errcode.InvalidArgument, "路径是目录而非文件")
 	}
 
 	// 限制文件大小（防止读取超大文件导致内存耗尽）
-	const maxFileSize = 50 * 1024 * 1024 // 50MB
-	if info.Size() > maxFileSize {
-		return nil, nil, fmt.Errorf("文件过大，超过 50MB 限制")
+	if info.Size() > defaultMaxReadSize {
+		return nil, nil, errcode.New(errcode.InvalidArgument, "文件过大，超过 50MB 限制")
 	}
 
 	content, err := os.ReadFile(cleanPath)
@@ -275,22 +441,84 @@ func ReadFile(path string) ([]byte, *FileInfo, error) {
 	return content, fileInfo, nil
 }
 
-// WriteFile 写入文件（带安全检查）
-func WriteFile(path string, content []byte, mode int64, createDirs bool) error {
-	// 安全检查
-	cleanPath, err := security.SanitizePath(path)
+const defaultMaxReadSize = 50 * 1024 * 1024 // 单次读取默认上限 50MB
+
+// ReadOptions 范围读取选项
+type ReadOptions struct {
+	// Offset 起始偏移，负数表示从文件末尾倒数
+	Offset int64
+	// Length 读取长度，0 表示读到文件末尾
+	Length int64
+	// MaxSize 单次最多读取的字节数，默认 50MB，超出部分截断
+	MaxSize int64
+}
+
+// ReadRange 范围读取的结果
+type ReadRange struct {
+	Info      *FileInfo
+	Offset    int64 // 实际起始偏移，下一页从 Offset+len(content) 开始
+	Truncated bool  // 请求的范围超过 MaxSize 被截断
+}
+
+// ReadFileRange 读取文件的一段，只分配实际读取的内容，适合分页读取大文件
+func ReadFileRange(path string, opts ReadOptions) ([]byte, *ReadRange, error) {
+	f, info, cleanPath, err := openRegularFile(path)
 	if err != nil {
-		return fmt.Errorf("路径安全检查失败: %w", err)
+		return nil, nil, err
 	}
+	defer f.Close()
 
-	if err := pathValidator.ValidatePathForWrite(cleanPath); err != nil {
-		return fmt.Errorf("写入路径被拒绝: %w", err)
+	if opts.Length < 0 {
+		return nil, nil, errcode.New(errcode.InvalidArgument, "读取长度不能为负数")
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxReadSize
+	}
+	size := info.Size()
+	offset := opts.Offset
+	if offset < 0 {
+		offset = max(size+offset, 0)
+	}
+	offset = min(offset, size)
+
+	length := size - offset
+	if opts.Length > 0 {
+		length = min(opts.Length, length)
+	}
+	result := &ReadRange{
+		Info: &FileInfo{
+			Name:    info.Name(),
+			Path:    cleanPath,
+			Size:    size,
+			Mode:    int64(info.Mode()),
+			ModTime: info.ModTime().Unix(),
+		},
+		Offset: offset,
+	}
+	if length > maxSize {
+		length, result.Truncated = maxSize, true
+	}
+
+	content := make([]byte, length)
+	// 读取期间文件可能被截断，按实际读到的长度返回
+	n, err := f.ReadAt(content, offset)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	return content[:n], result, nil
+}
+
+// WriteFile 写入文件（带安全检查）
+func WriteFile(path string, content []byte, mode int64, createDirs bool) error {
+	cleanPath, err := resolveWritePath(path)
+	if err != nil {
+		return err
 	}
 
 	// 限制写入内容大小
-	const maxWriteSize = 50 * 1024 * 1024 // 50MB
 	if len(content) > maxWriteSize {
-		return fmt.Errorf("写入内容过大，超过 50MB 限制")
+		return errcode.New(errcode.InvalidArgument, "写入内容过大，超过 50MB 限制")
 	}
 
 	if createDirs {
@@ -388,75 +616,6 @@ func ListDirectory(path string, recursive bool, showHidden bool) ([]*FileInfo, e
 	return files, nil
 }
 
-// TailFile 实时读取文件（带安全检查）
-func TailFile(ctx context.Context, path string, lines int, follow bool) (<-chan string, error) {
-	// 安全检查
-	cleanPath, err := security.SanitizePath(path)
-	if err != nil {
-		return nil, fmt.Errorf("路径安全检查失败: %w", err)
-	}
-
-	if err := pathValidator.ValidatePath(cleanPath); err != nil {
-		return nil, fmt.Errorf("路径访问被拒绝: %w", err)
-	}
-
-	file, err := os.Open(cleanPath)
-	if err != nil {
-		return nil, err
-	}
-
-	lineChan := make(chan string, 100)
-
-	go func() {
-		defer file.Close()
-		defer close(lineChan)
-
-		// 读取最后 N 行
-		scanner := bufio.NewScanner(file)
-		var buffer []string
-		for scanner.Scan() {
-			buffer = append(buffer, scanner.Text())
-			if len(buffer) > lines {
-				buffer = buffer[1:]
-			}
-		}
-
-		for _, line := range buffer {
-			select {
-			case <-ctx.Done():
-				return
-			case lineChan <- line:
-			}
-		}
-
-		if !follow {
-			return
-		}
-
-		// 持续监听新内容（使用递增退避，避免 busy-wait）
-		backoff := 200 * time.Millisecond
-		maxBackoff := 2 * time.Second
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				if scanner.Scan() {
-					lineChan <- scanner.Text()
-					backoff = 200 * time.Millisecond // 有新内容时重置退避
-				} else {
-					time.Sleep(backoff)
-					if backoff < maxBackoff {
-						backoff = backoff * 3 / 2 // 1.5x 递增
-					}
-				}
-			}
-		}
-	}()
-
-	return lineChan, nil
-}
-
 // ListServices 列出系统服务
 func ListServices(ctx context.Context) ([]*ServiceInfo, error) {
 	// 使用 systemctl 列出服务
@@ -484,17 +643,46 @@ func ListServices(ctx context.Context) ([]*ServiceInfo, error) {
 		}
 
 		name := strings.TrimSuffix(fields[0], ".service")
-		status := fields[3]
 
 		services = append(services, &ServiceInfo{
-			Name:   name,
-			Status: status,
+			Name:        name,
+			Active:      fields[2],
+			Status:      fields[3],
+			Description: strings.Join(fields[4:], " "),
 		})
 	}
 
+	// 补充开机自启状态（失败不影响列表本身）
+	if enabled, err := listServiceUnitFiles(ctx); err == nil {
+		for _, svc := range services {
+			svc.Enabled = enabled[svc.Name]
+		}
+	}
+
 	return services, nil
 }
 
+// listServiceUnitFiles 读取各服务的 unit file 状态，返回是否开机自启
+func listServiceUnitFiles(ctx context.Context) (map[string]bool, error) {
+	cmd := exec.CommandContext(ctx, "systemctl", "list-unit-files", "--type=service", "--no-pager", "--plain", "--no-legend")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimSuffix(fields[0], ".service")
+		result[name] = fields[1] == "enabled" || fields[1] == "enabled-runtime"
+	}
+	return result, nil
+}
+
 // ServiceAction 服务操作
 func ServiceAction(ctx context.Context, name string, action string) error {
 	// 验证操作类型
@@ -509,46 +697,43 @@ func ServiceAction(ctx context.Context, name string, action string) error {
 	}
 
 	if !allowedActions[action] {
-		return fmt.Errorf("不允许的服务操作: %s", action)
+		return errcode.New(errcode.InvalidArgument, "不允许的服务操作: %s", action)
 	}
 
 	// 验证服务名（防止命令注入）
 	if strings.ContainsAny(name, ";|&$`(){}[]<>\\\"' \t\n\r") {
-		return fmt.Errorf("服务名包含非法字符")
+		return errcode.New(errcode.InvalidArgument, "服务名包含非法字符")
 	}
 
-	cmd := exec.CommandContext(ctx, "systemctl", action, name)
-	return cmd.Run()
+	cmd := privilegedCommand(ctx, "systemctl", action, name)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
 }
 
-// KillProcess 终止进程
-func KillProcess(pid int, signal int) error {
-	// 验证 PID
-	if pid <= 1 {
-		return fmt.Errorf("不允许终止 PID <= 1 的进程")
+// privilegedCommand 构建需要 root 权限的内置操作，提权规则允许时通过 sudo 执行，
+// 否则直接执行（Agent 以 root 运行时的旧行为）
+func privilegedCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	if elevation != nil {
+		if _, err := elevation.Check(command, args); err == nil {
+			name, allArgs := elevation.Command(command, args)
+			return exec.CommandContext(ctx, name, allArgs...)
+		}
 	}
+	return exec.CommandContext(ctx, command, args...)
+}
 
-	process, err := os.FindProcess(pid)
+// KillProcess 终止进程，signal 为 0 时发送 SIGTERM。
+// 与 SignalProcess 使用同一份信号白名单，且不允许向 PID 1 和 Agent 自身发送信号
+func KillProcess(pid int, signal int) error {
+	name := ""
+	if signal != 0 {
+		name = strconv.Itoa(signal)
+	}
+	sig, err := ParseSignal(name)
 	if err != nil {
 		return err
 	}
-
-	sig := syscall.Signal(signal)
-	if signal == 0 {
-		sig = syscall.SIGTERM
-	}
-
-	// 只允许特定信号
-	allowedSignals := map[syscall.Signal]bool{
-		syscall.SIGTERM: true,
-		syscall.SIGKILL: true,
-		syscall.SIGINT:  true,
-		syscall.SIGHUP:  true,
-	}
-
-	if !allowedSignals[sig] {
-		return fmt.Errorf("不允许的信号: %d", signal)
-	}
-
-	return process.Signal(sig)
+	return SignalProcess(pid, sig, false)
 }
@@ -1,13 +1,29 @@
 package executor
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
+
+	"github.com/runixo/agent/internal/errcode"
 )
 
+// TestMain 测试二进制同样需要能作为沙箱辅助进程启动
+func TestMain(m *testing.M) {
+	RunSandboxHelper()
+	os.Exit(m.Run())
+}
+
 func TestExecute(t *testing.T) {
 	ctx := context.Background()
 
@@ -63,6 +79,165 @@ func TestExecuteWithWorkingDir(t *testing.T) {
 	}
 }
 
+func TestExecuteStream(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0644)
+
+	var stdout, stderr string
+	result, err := ExecuteStream(context.Background(), "ls", []string{dir, filepath.Join(dir, "missing")}, Options{},
+		func(isStderr bool, data []byte) error {
+			if isStderr {
+				stderr += string(data)
+			} else {
+				stdout += string(data)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error: %v", err)
+	}
+	if result.ExitCode == 0 || !strings.Contains(stdout, "a.txt") || !strings.Contains(stderr, "missing") {
+		t.Errorf("unexpected result: exit=%d stdout=%q stderr=%q", result.ExitCode, stdout, stderr)
+	}
+
+	// 安全检查失败时不回调
+	result, err = ExecuteStream(context.Background(), "rm", []string{"-rf", "/"}, Options{}, func(bool, []byte) error {
+		t.Error("emit should not be called")
+		return nil
+	})
+	if err != nil || result.ExitCode != -1 || result.Stderr == "" {
+		t.Errorf("expected rejected command, got %+v, %v", result, err)
+	}
+}
+
+func TestExecuteStdinAndEnv(t *testing.T) {
+	result, err := Execute(context.Background(), "cat", nil, Options{Stdin: []byte("hello\n")})
+	if err != nil || result.Stdout != "hello\n" {
+		t.Errorf("stdin not passed: %+v, %v", result, err)
+	}
+
+	result, err = Execute(context.Background(), "printenv", []string{"RUNIXO_TEST"}, Options{
+		Env: map[string]string{"RUNIXO_TEST": "42"},
+	})
+	if err != nil || strings.TrimSpace(result.Stdout) != "42" {
+		t.Errorf("env not passed: %+v, %v", result, err)
+	}
+
+	for _, name := range []string{
+		"LD_PRELOAD", "LD_AUDIT", "GCONV_PATH", "PERL5OPT", "NODE_OPTIONS", "RUBYOPT",
+		"PYTHONHOME", "PYTHONSTARTUP", "JAVA_TOOL_OPTIONS", "_JAVA_OPTIONS", "SHELLOPTS", "PS4",
+		"GIT_SSH_COMMAND", "GIT_EXEC_PATH", "GIT_CONFIG_KEY_0", "BASH_FUNC_ls", "1BAD", "A=B",
+	} {
+		result, err = Execute(context.Background(), "printenv", nil, Options{Env: map[string]string{name: "x"}})
+		if err != nil || result.ExitCode != -1 {
+			t.Errorf("env %q should be rejected, got %+v, %v", name, result, err)
+		}
+	}
+
+	// Agent 自身的环境中继承来的同样被过滤，bash 导出的函数名包含 %%
+	filtered := FilterEnvVars([]string{"PATH=/usr/bin", "BASH_FUNC_ls%%=() { id; }", "LD_AUDIT=/tmp/x.so", "NODE_OPTIONS=--require=/tmp/x.js"})
+	if len(filtered) != 1 || filtered[0] != "PATH=/usr/bin" {
+		t.Errorf("FilterEnvVars() = %v, want only PATH", filtered)
+	}
+}
+
+func TestExecuteOutputLimit(t *testing.T) {
+	input := bytes.Repeat([]byte("x"), 1<<20)
+	opts := Options{Stdin: input, Limits: Limits{MaxOutput: 1000}}
+
+	result, err := Execute(context.Background(), "cat", nil, opts)
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if len(result.Stdout) != 1000 || !strings.Contains(result.Stderr, "1000 字节") {
+		t.Errorf("output not capped: len=%d stderr=%q", len(result.Stdout), result.Stderr)
+	}
+
+	var streamed int
+	result, err = ExecuteStream(context.Background(), "cat", nil, opts, func(_ bool, data []byte) error {
+		streamed += len(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error: %v", err)
+	}
+	if streamed != 1000 || result.Stderr == "" {
+		t.Errorf("stream not capped: streamed=%d result=%+v", streamed, result)
+	}
+}
+
+func TestExecuteScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh")
+	}
+	script := "#!/bin/sh\r\necho \"$1 $FOO\"\r\ncat\r\nexit 3\r\n"
+	result, err := ExecuteScript(context.Background(), "sh", script, []string{"hi"}, Options{
+		Env:   map[string]string{"FOO": "bar"},
+		Stdin: []byte("in"),
+	})
+	if err != nil {
+		t.Fatalf("ExecuteScript() error: %v", err)
+	}
+	if result.ExitCode != 3 || result.Stdout != "hi bar\nin" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err := ExecuteScript(context.Background(), "perl", "print 1", nil, Options{}); err == nil {
+		t.Error("expected error for unknown interpreter")
+	}
+	if _, err := ExecuteScript(context.Background(), "sh", "true", nil, Options{Sudo: true}); err == nil {
+		t.Error("expected error for sudo")
+	}
+}
+
+func TestStartTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ConPTY 需要交互式桌面会话")
+	}
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("/bin/sh not available")
+	}
+	if _, err := StartTerminal(TerminalOptions{Shell: "/bin/sh"}, []string{"/bin/bash-not-here"}); err == nil {
+		t.Fatal("shell outside the allowlist should be rejected")
+	}
+
+	term, err := StartTerminal(TerminalOptions{Shell: "/bin/sh", Rows: 30, Cols: 100}, []string{"/bin/sh"})
+	if err != nil {
+		t.Fatalf("StartTerminal() error: %v", err)
+	}
+	defer term.Close()
+	if err := term.Resize(40, 120); err != nil {
+		t.Errorf("Resize() error: %v", err)
+	}
+	term.Write([]byte("stty size; exit 3\n"))
+
+	output := make(chan string, 1)
+	go func() {
+		var out []byte
+		buf := make([]byte, 1024)
+		for {
+			n, err := term.Read(buf)
+			out = append(out, buf[:n]...)
+			if err != nil {
+				output <- string(out)
+				return
+			}
+		}
+	}()
+	code, err := term.Wait()
+	if err != nil || code != 3 {
+		t.Errorf("Wait() = %d, %v; want 3", code, err)
+	}
+	select {
+	case out := <-output:
+		if !strings.Contains(out, "40 120") {
+			t.Errorf("terminal size not applied, output: %q", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("terminal output not closed after shell exit")
+	}
+}
+
 func TestReadFile(t *testing.T) {
 	// 创建临时文件
 	tempDir := t.TempDir()
@@ -97,6 +272,81 @@ func TestReadFile(t *testing.T) {
 	}
 }
 
+func TestHashAndCompareFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	a := filepath.Join(tmpDir, "a.conf")
+	b := filepath.Join(tmpDir, "b.conf")
+	c := filepath.Join(tmpDir, "c.conf")
+	os.WriteFile(a, []byte("hello world"), 0644)
+	os.WriteFile(b, []byte("hello world"), 0644)
+	os.WriteFile(c, []byte("hello there!"), 0644)
+
+	h, err := HashFile(context.Background(), a, "")
+	if err != nil {
+		t.Fatalf("HashFile() error: %v", err)
+	}
+	if h.Algorithm != "sha256" || h.Hash != "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9" || h.Size != 11 {
+		t.Errorf("unexpected hash: %+v", h)
+	}
+	if h, _ := HashFile(context.Background(), a, "md5"); h == nil || h.Hash != "5eb63bbbe01eeed093cb22bb8f5acdc3" {
+		t.Errorf("unexpected md5: %+v", h)
+	}
+	if _, err := HashFile(context.Background(), a, "crc32"); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+
+	if cmp, err := CompareFiles(context.Background(), a, b); err != nil || !cmp.Equal || cmp.FirstDifference != -1 {
+		t.Errorf("expected equal files: %+v, %v", cmp, err)
+	}
+	if cmp, err := CompareFiles(context.Background(), a, c); err != nil || cmp.Equal || cmp.FirstDifference != 6 {
+		t.Errorf("expected difference at 6: %+v, %v", cmp, err)
+	}
+}
+
+func TestSearchFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	os.MkdirAll(filepath.Join(tmpDir, "sites", "deep"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "sites", "a.conf"), []byte("server_name example.com;\nlisten 80;\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "sites", "b.conf"), []byte("server_name other.org;\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "sites", "deep", "c.conf"), []byte("# example.com\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "sites", "bin.conf"), []byte("example.com\x00\x01"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "sites", ".hidden.conf"), []byte("example.com"), 0644)
+
+	results, truncated, err := SearchFiles(context.Background(), SearchOptions{
+		Root:    tmpDir,
+		Pattern: "*.conf",
+		Content: `example\.com`,
+	})
+	if err != nil || truncated {
+		t.Fatalf("SearchFiles() = %v, truncated=%v", err, truncated)
+	}
+	found := map[string]int{}
+	for _, r := range results {
+		found[r.File.Name] = r.Matches[0].Line
+	}
+	if len(found) != 2 || found["a.conf"] != 1 || found["c.conf"] != 1 {
+		t.Errorf("unexpected results: %v", found)
+	}
+
+	results, _, _ = SearchFiles(context.Background(), SearchOptions{Root: tmpDir, Pattern: "*.conf", MaxDepth: 1})
+	if len(results) != 0 {
+		t.Errorf("max depth 1 should only search the root: %d results", len(results))
+	}
+	if _, truncated, _ = SearchFiles(context.Background(), SearchOptions{Root: tmpDir, MaxResults: 1}); !truncated {
+		t.Error("expected truncated results")
+	}
+}
+
 func TestReadFileNotFound(t *testing.T) {
 	_, _, err := ReadFile("/nonexistent/file/path")
 	if err == nil {
@@ -184,4 +434,735 @@ func TestKillProcess(t *testing.T) {
 	if err == nil {
 		t.Log("KillProcess to nonexistent PID didn't return error (may be OS-specific)")
 	}
+
+	// Agent 自身和 PID 1 受保护
+	for _, pid := range []int{1, os.Getpid()} {
+		if err := KillProcess(pid, 0); errcode.Of(err) != errcode.ProcessProtected {
+			t.Errorf("KillProcess(%d) = %v, want PROCESS_PROTECTED", pid, err)
+		}
+	}
+	if err := KillProcess(999999, int(syscall.SIGSEGV)); errcode.Of(err) != errcode.InvalidArgument {
+		t.Errorf("KillProcess(SIGSEGV) = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"", "TERM", "SIGTERM", "sigterm", "15"} {
+		sig, err := ParseSignal(name)
+		if err != nil {
+			t.Errorf("ParseSignal(%q) error: %v", name, err)
+			continue
+		}
+		if sig != syscall.SIGTERM {
+			t.Errorf("ParseSignal(%q) = %v, want SIGTERM", name, sig)
+		}
+	}
+
+	if _, err := ParseSignal("SIGSEGV"); err == nil {
+		t.Error("ParseSignal(SIGSEGV) should be rejected")
+	}
+}
+
+func TestSignalProcessProtected(t *testing.T) {
+	if err := SignalProcess(1, syscall.SIGTERM, false); err == nil {
+		t.Error("signalling PID 1 without force should fail")
+	}
+	if err := SignalProcess(os.Getpid(), syscall.SIGTERM, false); err == nil {
+		t.Error("signalling the agent itself without force should fail")
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logPath := filepath.Join(tmpDir, "app.log")
+	os.WriteFile(logPath, []byte("one\nERROR two\nthree\nERROR four\n"), 0644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	next := func(ch <-chan string) string {
+		select {
+		case line := <-ch:
+			return line
+		case <-ctx.Done():
+			t.Fatal("等待日志行超时")
+			return ""
+		}
+	}
+
+	// 末尾行 + 过滤
+	ch, err := TailFile(ctx, logPath, TailOptions{Lines: 3, Filter: "^ERROR"})
+	if err != nil {
+		t.Fatalf("TailFile() error = %v", err)
+	}
+	var got []string
+	for line := range ch {
+		got = append(got, line)
+	}
+	if strings.Join(got, ",") != "ERROR two,ERROR four" {
+		t.Errorf("TailFile() lines = %v", got)
+	}
+
+	if _, err := TailFile(ctx, logPath, TailOptions{Filter: "("}); err == nil {
+		t.Error("TailFile() should reject invalid filter")
+	}
+
+	// 跟踪：追加、截断、轮转
+	ch, err = TailFile(ctx, logPath, TailOptions{Lines: 1, Follow: true})
+	if err != nil {
+		t.Fatalf("TailFile() error = %v", err)
+	}
+	if line := next(ch); line != "ERROR four" {
+		t.Errorf("first line = %q", line)
+	}
+
+	f, _ := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("appended\n")
+	f.Close()
+	if line := next(ch); line != "appended" {
+		t.Errorf("after append = %q", line)
+	}
+
+	os.WriteFile(logPath, []byte("new\n"), 0644)
+	if line := next(ch); line != "new" {
+		t.Errorf("after truncate = %q", line)
+	}
+
+	os.Rename(logPath, logPath+".1")
+	os.WriteFile(logPath, []byte("rotated\n"), 0644)
+	if line := next(ch); line != "rotated" {
+		t.Errorf("after rotate = %q", line)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "site")
+	os.MkdirAll(filepath.Join(src, "conf"), 0755)
+	os.WriteFile(filepath.Join(src, "index.html"), []byte("<h1>hi</h1>"), 0644)
+	os.WriteFile(filepath.Join(src, "conf", "app.conf"), []byte("listen 80;\n"), 0600)
+
+	for _, format := range []string{FormatTarGz, FormatZip} {
+		archive := filepath.Join(tmpDir, "site."+format)
+		var reports int
+		result, err := CreateArchive(context.Background(), []string{src}, archive, ArchiveOptions{
+			Progress: func(ArchiveProgress) { reports++ },
+		})
+		if err != nil {
+			t.Fatalf("CreateArchive(%s) error = %v", format, err)
+		}
+		if result.Files != 4 || reports != 4 {
+			t.Errorf("CreateArchive(%s) files = %d, reports = %d", format, result.Files, reports)
+		}
+
+		dest := filepath.Join(tmpDir, "out-"+format)
+		if _, err := ExtractArchive(context.Background(), archive, dest, ArchiveOptions{}); err != nil {
+			t.Fatalf("ExtractArchive(%s) error = %v", format, err)
+		}
+		data, err := os.ReadFile(filepath.Join(dest, "site", "conf", "app.conf"))
+		if err != nil || string(data) != "listen 80;\n" {
+			t.Errorf("extracted content = %q, %v", data, err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "site", "conf", "app.conf")); err == nil && info.Mode().Perm() != 0600 {
+			t.Errorf("extracted mode = %v", info.Mode().Perm())
+		}
+
+		if _, err := ExtractArchive(context.Background(), archive, filepath.Join(tmpDir, "small"), ArchiveOptions{MaxBytes: 5}); err == nil {
+			t.Errorf("ExtractArchive(%s) should enforce MaxBytes", format)
+		}
+	}
+
+	// 路径遍历和指向目录外的符号链接都应被拒绝
+	evil := func(name string, hdr *tar.Header) string {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		tw.WriteHeader(hdr)
+		if hdr.Size > 0 {
+			tw.Write(make([]byte, hdr.Size))
+		}
+		tw.Close()
+		gw.Close()
+		p := filepath.Join(tmpDir, name)
+		os.WriteFile(p, buf.Bytes(), 0644)
+		return p
+	}
+	for name, hdr := range map[string]*tar.Header{
+		"dotdot.tar.gz":  {Name: "../escape.txt", Typeflag: tar.TypeReg, Size: 1, Mode: 0644},
+		"symlink.tar.gz": {Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc", Mode: 0777},
+	} {
+		if _, err := ExtractArchive(context.Background(), evil(name, hdr), filepath.Join(tmpDir, "evil"), ArchiveOptions{}); err == nil {
+			t.Errorf("ExtractArchive(%s) should fail", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "escape.txt")); err == nil {
+		t.Error("path traversal entry was extracted outside dest")
+	}
+}
+
+func TestParseModeSpec(t *testing.T) {
+	tests := []struct {
+		spec  string
+		old   uint32
+		isDir bool
+		want  uint32
+	}{
+		{"755", 0644, false, 0755},
+		{"u+x", 0644, false, 0744},
+		{"go-w", 0666, false, 0644},
+		{"a=rX", 0700, true, 0555},
+		{"a=rX", 0600, false, 0444},
+		{"u=rw,g+s,o=", 0777, true, 02670},
+		{"+t", 0777, true, 01777},
+	}
+	for _, tt := range tests {
+		apply, err := parseModeSpec(tt.spec)
+		if err != nil {
+			t.Fatalf("parseModeSpec(%q) error = %v", tt.spec, err)
+		}
+		if got := apply(tt.old, tt.isDir); got != tt.want {
+			t.Errorf("parseModeSpec(%q)(%04o) = %04o, want %04o", tt.spec, tt.old, got, tt.want)
+		}
+	}
+	for _, spec := range []string{"", "800", "u+y", "z=r"} {
+		if _, err := parseModeSpec(spec); err == nil {
+			t.Errorf("parseModeSpec(%q) should fail", spec)
+		}
+	}
+}
+
+func TestChmodRecursiveDryRun(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	os.Chmod(tmpDir, 0755)
+	os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("a"), 0600)
+	os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("b"), 0644)
+
+	result, err := Chmod(context.Background(), tmpDir, "go+r", PermissionOptions{Recursive: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+	if result.Changed != 1 || result.Changes[0].Old != "0600" || result.Changes[0].New != "0644" {
+		t.Errorf("Chmod() dry run = %+v", result)
+	}
+	if info, _ := os.Stat(filepath.Join(tmpDir, "a.txt")); info.Mode().Perm() != 0600 {
+		t.Error("dry run should not modify files")
+	}
+
+	if _, err := Chmod(context.Background(), tmpDir, "go+r", PermissionOptions{Recursive: true}); err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+	if info, _ := os.Stat(filepath.Join(tmpDir, "a.txt")); info.Mode().Perm() != 0644 {
+		t.Errorf("Chmod() mode = %v", info.Mode().Perm())
+	}
+}
+
+func TestCopyMoveDelete(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+
+	src := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("aaa"), 0640)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("bb"), 0644)
+	os.Symlink("a.txt", filepath.Join(src, "link"))
+
+	if _, err := CopyPath(ctx, src, filepath.Join(tmpDir, "copy"), CopyOptions{}); err == nil {
+		t.Error("copying a directory without recursive should fail")
+	}
+	if _, err := CopyPath(ctx, src, filepath.Join(src, "sub", "loop"), CopyOptions{Recursive: true}); err == nil {
+		t.Error("copying into itself should fail")
+	}
+	result, err := CopyPath(ctx, src, filepath.Join(tmpDir, "copy"), CopyOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("CopyPath() error = %v", err)
+	}
+	if result.Files != 5 || result.Bytes != 5 {
+		t.Errorf("CopyPath() = %+v", result)
+	}
+	if info, err := os.Stat(filepath.Join(tmpDir, "copy", "a.txt")); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("copied file mode = %v, %v", info, err)
+	}
+	if target, _ := os.Readlink(filepath.Join(tmpDir, "copy", "link")); target != "a.txt" {
+		t.Errorf("copied symlink target = %q", target)
+	}
+
+	// 覆盖策略
+	dst := filepath.Join(tmpDir, "copy", "a.txt")
+	if _, err := CopyPath(ctx, filepath.Join(src, "sub", "b.txt"), dst, CopyOptions{}); errcode.Of(err) != errcode.AlreadyExists {
+		t.Errorf("CopyPath() without overwrite error = %v", err)
+	}
+	if result, _ := CopyPath(ctx, filepath.Join(src, "sub", "b.txt"), dst, CopyOptions{Overwrite: OverwriteSkip}); result.Skipped != 1 {
+		t.Errorf("CopyPath() skip = %+v", result)
+	}
+	if _, err := CopyPath(ctx, filepath.Join(src, "sub", "b.txt"), dst, CopyOptions{Overwrite: OverwriteReplace}); err != nil {
+		t.Fatalf("CopyPath() replace error = %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "bb" {
+		t.Errorf("replaced content = %q", data)
+	}
+
+	if _, err := MovePath(ctx, filepath.Join(tmpDir, "copy"), filepath.Join(tmpDir, "moved"), CopyOptions{}); err != nil {
+		t.Fatalf("MovePath() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "moved", "sub", "b.txt")); err != nil {
+		t.Errorf("moved file missing: %v", err)
+	}
+
+	// 删除到回收站并恢复
+	trash, err := NewTrash(filepath.Join(tmpDir, "trash"), time.Hour)
+	if err != nil {
+		t.Fatalf("NewTrash() error = %v", err)
+	}
+	moved := filepath.Join(tmpDir, "moved")
+	if _, err := DeletePath(ctx, moved, DeleteOptions{}); err == nil {
+		t.Error("deleting a non-empty directory without recursive should fail")
+	}
+	result, err = DeletePath(ctx, moved, DeleteOptions{Recursive: true, Trash: trash})
+	if err != nil || result.TrashID == "" {
+		t.Fatalf("DeletePath() = %+v, %v", result, err)
+	}
+	if _, err := os.Stat(moved); !os.IsNotExist(err) {
+		t.Error("deleted path still exists")
+	}
+	if entries, _ := trash.List(); len(entries) != 1 || entries[0].OriginalPath != moved {
+		t.Errorf("trash.List() = %v", entries)
+	}
+	if _, err := trash.Restore(ctx, result.TrashID); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(moved, "a.txt")); string(data) != "bb" {
+		t.Errorf("restored content = %q", data)
+	}
+	if _, err := trash.Restore(ctx, "../../etc"); err == nil {
+		t.Error("Restore() should reject invalid ids")
+	}
+
+	if _, err := DeletePath(ctx, "/etc", DeleteOptions{Recursive: true}); err == nil {
+		t.Error("deleting /etc should be rejected")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+
+	path := filepath.Join(tmpDir, "site.conf")
+	os.WriteFile(path, []byte("listen 80;\n"), 0640)
+
+	result, err := WriteFileAtomic(ctx, path, []byte("listen 443;\n"), AtomicWriteOptions{Backup: true})
+	if err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if data, _ := os.ReadFile(result.BackupPath); string(data) != "listen 80;\n" {
+		t.Errorf("backup content = %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("mode not preserved: %v", info.Mode().Perm())
+	}
+
+	// 替换前校验临时文件：失败时原文件不变
+	_, err = WriteFileAtomic(ctx, path, []byte("broken\n"), AtomicWriteOptions{Validate: []string{"grep", "-q", "listen", "{file}"}})
+	if errcode.Of(err) != errcode.ValidationFailed {
+		t.Fatalf("WriteFileAtomic() error = %v, want ValidationFailed", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "listen 443;\n" {
+		t.Errorf("content after failed validation = %q", data)
+	}
+
+	// 替换后校验：失败时恢复原内容，且不留下备份
+	_, err = WriteFileAtomic(ctx, path, []byte("broken\n"), AtomicWriteOptions{Validate: []string{"grep", "-q", "listen", path}})
+	if errcode.Of(err) != errcode.ValidationFailed {
+		t.Fatalf("WriteFileAtomic() in-place error = %v, want ValidationFailed", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "listen 443;\n" {
+		t.Errorf("content after rollback = %q", data)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 2 {
+		t.Errorf("unexpected files left behind: %d entries", len(entries))
+	}
+}
+
+func TestGetDirectorySize(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("/tmp", "runixo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+
+	os.MkdirAll(filepath.Join(tmpDir, "big", "deep"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "small"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "big", "deep", "a"), make([]byte, 8192), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "small", "b"), []byte("b"), 0644)
+	// 硬链接只计一次
+	os.Link(filepath.Join(tmpDir, "big", "deep", "a"), filepath.Join(tmpDir, "small", "a-link"))
+
+	root, truncated, err := GetDirectorySize(ctx, tmpDir, DirSizeOptions{})
+	if err != nil || truncated {
+		t.Fatalf("GetDirectorySize() error = %v, truncated = %v", err, truncated)
+	}
+	if root.Size != 8193 || root.Files != 2 || root.Dirs != 4 {
+		t.Errorf("GetDirectorySize() = %+v", root)
+	}
+	if len(root.Children) != 2 || root.Children[0].Path != filepath.Join(tmpDir, "big") {
+		t.Fatalf("children = %+v", root.Children)
+	}
+	if len(root.Children[0].Children) != 0 {
+		t.Error("depth 1 should not include grandchildren")
+	}
+
+	root, _, _ = GetDirectorySize(ctx, tmpDir, DirSizeOptions{Depth: 2, Top: 1})
+	if len(root.Children) != 1 || len(root.Children[0].Children) != 1 {
+		t.Errorf("depth 2, top 1 children = %+v", root.Children)
+	}
+
+	if _, truncated, _ = GetDirectorySize(ctx, tmpDir, DirSizeOptions{MaxEntries: 2}); !truncated {
+		t.Error("MaxEntries should truncate the walk")
+	}
+	if _, _, err := GetDirectorySize(ctx, filepath.Join(tmpDir, "small", "b"), DirSizeOptions{}); err == nil {
+		t.Error("a regular file should be rejected")
+	}
+}
+
+func TestReadFileRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("0123456789"), 0644)
+
+	cases := []struct {
+		opts      ReadOptions
+		want      string
+		offset    int64
+		truncated bool
+	}{
+		{ReadOptions{}, "0123456789", 0, false},
+		{ReadOptions{Offset: 3, Length: 4}, "3456", 3, false},
+		{ReadOptions{Offset: -3}, "789", 7, false},
+		{ReadOptions{Offset: 2, MaxSize: 5}, "23456", 2, true},
+		{ReadOptions{Offset: 8, Length: 100}, "89", 8, false},
+		{ReadOptions{Offset: 20}, "", 10, false},
+	}
+	for _, c := range cases {
+		content, r, err := ReadFileRange(path, c.opts)
+		if err != nil {
+			t.Fatalf("ReadFileRange(%+v) error = %v", c.opts, err)
+		}
+		if string(content) != c.want || r.Offset != c.offset || r.Truncated != c.truncated || r.Info.Size != 10 {
+			t.Errorf("ReadFileRange(%+v) = %q, %+v", c.opts, content, r)
+		}
+	}
+	if _, _, err := ReadFileRange(filepath.Dir(path), ReadOptions{}); err == nil {
+		t.Error("reading a directory should fail")
+	}
+}
+
+// sandboxTestDir 创建沙箱用户可以访问的临时目录。t.TempDir 的上级目录只有当前用户可以访问，
+// 而 Agent 以 root 运行时沙箱进程在主机上是 nobody
+func sandboxTestDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "runixo-sandbox-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	uid, gid := sandboxOwner()
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(dir, uid, gid); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestSandbox(t *testing.T) {
+	if !sandboxSupported {
+		t.Skip("当前平台不支持沙箱")
+	}
+	dir := sandboxTestDir(t)
+	ctx := context.Background()
+
+	result, err := Execute(ctx, "ls", []string{"/proc"}, Options{Sandbox: &Sandbox{}})
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if result.ExitCode == sandboxExitCode {
+		t.Skipf("当前环境无法创建命名空间: %s", result.Stderr)
+	}
+	// 新的 PID 命名空间中只有命令自身
+	for _, f := range strings.Fields(result.Stdout) {
+		if f != "1" && strings.Trim(f, "0123456789") == "" {
+			t.Errorf("host process %s visible in sandbox", f)
+		}
+	}
+
+	// 以 root 运行时同样不保留任何 capability
+	result, _ = Execute(ctx, "cat", []string{"/proc/self/status"}, Options{Sandbox: &Sandbox{}})
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		if field, value, ok := strings.Cut(line, ":"); ok && strings.HasPrefix(field, "Cap") {
+			if strings.Trim(strings.TrimSpace(value), "0") != "" {
+				t.Errorf("%s = %s in sandbox, want none", field, strings.TrimSpace(value))
+			}
+		}
+	}
+
+	result, _ = Execute(ctx, "touch", []string{filepath.Join(dir, "denied")}, Options{Sandbox: &Sandbox{}})
+	if result.ExitCode == 0 {
+		t.Error("filesystem should be read-only in sandbox")
+	}
+	result, _ = Execute(ctx, "touch", []string{filepath.Join(dir, "allowed")}, Options{Sandbox: &Sandbox{Writable: []string{dir}}})
+	if result.ExitCode != 0 {
+		t.Errorf("writable path not writable: %+v", result)
+	}
+
+	result, _ = Execute(ctx, "ls", nil, Options{Sandbox: &Sandbox{}, Sudo: true})
+	if result.ExitCode != -1 {
+		t.Error("sudo should be rejected in sandbox")
+	}
+
+	// /run 中的服务套接字和 Hidden 中的路径不可见
+	hidden := filepath.Join(dir, "hidden")
+	os.Mkdir(hidden, 0755)
+	os.WriteFile(filepath.Join(hidden, "token"), []byte("secret"), 0644)
+	socket := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	os.Chmod(socket, 0777)
+	hiddenSandbox := &Sandbox{Hidden: []string{hidden, socket}}
+	for _, script := range []string{
+		// 只有挂载的脚本目录
+		"test -z \"$(ls -A /run/ /var/run/ | grep -v -e runixo-script -e ':$' -e '^$')\"",
+		"test ! -e " + filepath.Join(hidden, "token"),
+		"test ! -S " + socket,
+	} {
+		result, err = ExecuteScript(ctx, "sh", script, nil, Options{Sandbox: hiddenSandbox})
+		if err != nil || result.ExitCode != 0 {
+			t.Errorf("%s in sandbox: %+v", script, result)
+		}
+	}
+
+	// Agent 以 root 运行时沙箱进程在主机上不是 root，不能读取只有 root 可以访问的文件
+	if os.Getuid() == 0 {
+		secret := filepath.Join(dir, "root-only")
+		os.WriteFile(secret, []byte("secret"), 0600)
+		os.Chown(secret, 0, 0)
+		result, _ = Execute(ctx, "cat", []string{secret}, Options{Sandbox: &Sandbox{}})
+		if result.ExitCode == 0 || strings.Contains(result.Stdout, "secret") {
+			t.Errorf("root-only file readable in sandbox: %+v", result)
+		}
+	}
+}
+
+// sandboxRoot 复制 sh 及其依赖的库，构建只包含 sh 的 chroot 根目录
+func sandboxRoot(t *testing.T) (string, string) {
+	t.Helper()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("找不到 sh")
+	}
+	out, err := exec.Command("ldd", sh).Output()
+	if err != nil {
+		t.Skipf("无法获取 %s 依赖的库: %v", sh, err)
+	}
+	root := sandboxTestDir(t)
+	files := []string{sh}
+	for _, field := range strings.Fields(string(out)) {
+		if filepath.IsAbs(field) {
+			files = append(files, field)
+		}
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, data, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "run"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return root, sh
+}
+
+func TestSandboxScript(t *testing.T) {
+	if !sandboxSupported {
+		t.Skip("当前平台不支持沙箱")
+	}
+	root, _ := sandboxRoot(t)
+	ctx := context.Background()
+
+	// 设置 Root 后主机的临时目录在沙箱中不存在，脚本挂载到沙箱中执行
+	for _, sandbox := range []*Sandbox{{}, {Root: root}} {
+		result, err := ExecuteScript(ctx, "sh", "read x\necho \"$1 $x\"\n", []string{"hi"}, Options{
+			Sandbox: sandbox,
+			Stdin:   []byte("in\n"),
+		})
+		if err != nil {
+			t.Fatalf("ExecuteScript() error: %v", err)
+		}
+		if result.ExitCode == sandboxExitCode && strings.Contains(result.Stderr, "设置挂载传播失败") {
+			t.Skipf("当前环境无法创建命名空间: %s", result.Stderr)
+		}
+		if result.ExitCode != 0 || result.Stdout != "hi in\n" {
+			t.Errorf("ExecuteScript(root=%q) = %+v", sandbox.Root, result)
+		}
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	crlf := append([]byte{0xEF, 0xBB, 0xBF}, "a\r\nb\r\n"...)
+	text, info := DecodeText(crlf)
+	if text != "a\r\nb\r\n" || !info.BOM || info.Newline != NewlineCRLF || info.Binary {
+		t.Errorf("DecodeText() = %q, %+v", text, info)
+	}
+	if info := DetectText([]byte("a\nb\r\n")); info.Newline != NewlineMixed {
+		t.Errorf("mixed newlines detected as %q", info.Newline)
+	}
+	if _, info := DecodeText([]byte{0x7f, 'E', 'L', 'F', 0, 1}); !info.Binary {
+		t.Error("NUL bytes should be detected as binary")
+	}
+
+	// "中" 为 3 字节，范围读取切断的首尾字符被去掉
+	trimmed, skip := TrimPartialRunes([]byte("中文字")[1:8])
+	if string(trimmed) != "文" || skip != 2 {
+		t.Errorf("TrimPartialRunes() = %q, %d", trimmed, skip)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "win.ini")
+	os.WriteFile(path, crlf, 0644)
+	// 编辑器提交的 \n 文本沿用现有文件的 CRLF 和 BOM
+	content, err := EncodeText(path, "a\nc\n", TextWriteOptions{})
+	if err != nil || !bytes.Equal(content, append([]byte{0xEF, 0xBB, 0xBF}, "a\r\nc\r\n"...)) {
+		t.Errorf("EncodeText() = %q, %v", content, err)
+	}
+	content, _ = EncodeText(path, "a\r\nc\r\n", TextWriteOptions{Newline: NewlineLF})
+	if string(content) != "\uFEFFa\nc\n" {
+		t.Errorf("EncodeText(lf) = %q", content)
+	}
+	content, _ = EncodeText(filepath.Join(dir, "new.txt"), "a\r\nb\n", TextWriteOptions{})
+	if string(content) != "a\r\nb\n" {
+		t.Errorf("new file should be written as given, got %q", content)
+	}
+	if _, err := EncodeText(path, "a", TextWriteOptions{Newline: "cr"}); err == nil {
+		t.Error("unknown newline style should be rejected")
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sleep / false")
+	}
+	ctx := context.Background()
+	cmds := []BatchCommand{
+		{Command: "echo", Args: []string{"one"}},
+		{Command: "false"},
+		{Command: "sleep", Args: []string{"5"}, Options: Options{Timeout: 200 * time.Millisecond}},
+		{Command: "rm", Args: []string{"-rf", "/"}},
+	}
+
+	// 出错后继续执行
+	results, err := ExecuteBatch(ctx, cmds, BatchOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error: %v", err)
+	}
+	want := []string{BatchSucceeded, BatchFailed, BatchFailed, BatchFailed}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("results[%d].Status = %s, want %s (%+v)", i, r.Status, want[i], r.Result)
+		}
+	}
+	if !strings.Contains(results[0].Result.Stdout, "one") {
+		t.Errorf("results[0].Stdout = %q", results[0].Result.Stdout)
+	}
+
+	// 快速失败：false 失败后终止 sleep，之后的命令被跳过
+	start := time.Now()
+	results, err = ExecuteBatch(ctx, []BatchCommand{
+		{Command: "sleep", Args: []string{"5"}},
+		{Command: "false"},
+		{Command: "echo", Args: []string{"two"}},
+	}, BatchOptions{Concurrency: 2, FailFast: true})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error: %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Error("fail-fast did not cancel the running command")
+	}
+	want = []string{BatchCancelled, BatchFailed, BatchSkipped}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("fail-fast results[%d].Status = %s, want %s", i, r.Status, want[i])
+		}
+	}
+
+	if _, err := ExecuteBatch(ctx, nil, BatchOptions{}); err == nil {
+		t.Error("expected error for empty batch")
+	}
+}
+
+func TestTimeoutKillsProcessGroup(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("通过 /proc 检查进程状态")
+	}
+	defer SetKillGrace(defaultKillGrace)
+	SetKillGrace(200 * time.Millisecond)
+
+	// 后台的 sleep 是孙进程；sh 忽略 SIGTERM，需要宽限期后的 SIGKILL
+	start := time.Now()
+	result, err := ExecuteScript(context.Background(), "sh", "trap '' TERM\nsleep 30 &\necho $!\nwait\n", nil, Options{
+		Timeout: 300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteScript() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command was not killed in time: %v", elapsed)
+	}
+	if result.ExitCode != -1 || !strings.Contains(result.Stderr, "超时") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	pid := strings.TrimSpace(result.Stdout)
+	alive := func() bool {
+		data, err := os.ReadFile("/proc/" + pid + "/stat")
+		// 第三列为进程状态，僵尸进程等待回收，已经结束
+		return err == nil && !strings.Contains(string(data), ") Z ")
+	}
+	for i := 0; i < 50 && alive(); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if pid == "" || alive() {
+		t.Errorf("background process %q survived the timeout", pid)
+	}
 }
@@ -0,0 +1,347 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// OverwritePolicy 目标已存在时的处理方式
+type OverwritePolicy int
+
+const (
+	OverwriteFail    OverwritePolicy = iota // 返回 AlreadyExists 错误（默认）
+	OverwriteReplace                        // 覆盖，但不会用文件覆盖目录
+	OverwriteSkip                           // 跳过已存在的条目
+	OverwriteIfNewer                        // 源比目标新时覆盖，否则跳过
+)
+
+// CopyOptions 复制和移动选项
+type CopyOptions struct {
+	// Recursive 复制目录时必须设置；目标目录已存在时合并内容
+	Recursive bool
+	Overwrite OverwritePolicy
+}
+
+// FileOpResult 复制、移动或删除的结果
+type FileOpResult struct {
+	Path    string // 目标路径
+	Files   int64  // 复制的条目数（文件、目录和链接），直接重命名时为 1
+	Bytes   int64
+	Skipped int64  // 因覆盖策略跳过的条目，移动时有跳过则保留源路径
+	TrashID string // 删除到回收站时的条目 ID
+}
+
+// deleteProtected 禁止删除或移走的系统关键目录
+var deleteProtected = []string{"/", "/bin", "/sbin", "/usr", "/etc", "/var", "/boot", "/root", "/home"}
+
+// resolveRemovablePath 校验将被删除或移走的路径，符号链接的目标同样需要允许写入
+func resolveRemovablePath(path string) (string, error) {
+	cleanPath, err := resolveWritePath(path)
+	if err != nil {
+		return "", err
+	}
+	if realPath, err := filepath.EvalSymlinks(cleanPath); err == nil && realPath != cleanPath {
+		if err := pathValidator.ValidatePathForWrite(realPath); err != nil {
+			return "", errcode.Wrap(errcode.PathNotAllowed, err, "符号链接目标路径被拒绝")
+		}
+	}
+	for _, protected := range deleteProtected {
+		if cleanPath == protected {
+			return "", errcode.New(errcode.PathNotAllowed, "禁止删除或移动系统关键目录")
+		}
+	}
+	if filepath.Dir(cleanPath) == cleanPath {
+		return "", errcode.New(errcode.PathNotAllowed, "禁止删除或移动根目录")
+	}
+	return cleanPath, nil
+}
+
+func lstatExisting(path string) (fs.FileInfo, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.NotFound, "路径不存在: %s", path)
+	}
+	return info, err
+}
+
+// CopyPath 把 src 复制为 dst（dst 是复制后的完整路径，而不是其所在目录）
+// 保留权限位和修改时间，符号链接按链接本身复制，跳过禁止访问的路径
+func CopyPath(ctx context.Context, src, dst string, opts CopyOptions) (*FileOpResult, error) {
+	srcPath, err := resolveReadPath(src)
+	if err != nil {
+		return nil, err
+	}
+	dstPath, err := resolveWritePath(dst)
+	if err != nil {
+		return nil, err
+	}
+	info, err := lstatExisting(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() && !opts.Recursive {
+		return nil, errcode.New(errcode.InvalidArgument, "%s 是目录，需要设置 recursive", src)
+	}
+	if isWithin(srcPath, dstPath) {
+		return nil, errcode.New(errcode.InvalidArgument, "不能复制到自身或其子目录")
+	}
+
+	c := &copier{opts: opts, result: &FileOpResult{Path: dstPath}}
+	if err := c.copy(ctx, srcPath, dstPath, info); err != nil {
+		return nil, err
+	}
+	return c.result, nil
+}
+
+// MovePath 把 src 移动为 dst，同一文件系统内直接重命名，跨设备时复制后删除源路径
+// 源和目标都是目录时合并内容，合并按覆盖策略逐个处理条目
+func MovePath(ctx context.Context, src, dst string, opts CopyOptions) (*FileOpResult, error) {
+	srcPath, err := resolveRemovablePath(src)
+	if err != nil {
+		return nil, err
+	}
+	dstPath, err := resolveWritePath(dst)
+	if err != nil {
+		return nil, err
+	}
+	info, err := lstatExisting(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	if isWithin(srcPath, dstPath) {
+		return nil, errcode.New(errcode.InvalidArgument, "不能移动到自身或其子目录")
+	}
+
+	c := &copier{opts: CopyOptions{Recursive: true, Overwrite: opts.Overwrite}, result: &FileOpResult{Path: dstPath}}
+	merge := false
+	if existing, err := os.Lstat(dstPath); err == nil {
+		if merge = info.IsDir() && existing.IsDir(); !merge {
+			if ok, err := c.proceed(dstPath, info, existing); !ok {
+				return c.result, err
+			}
+		}
+	}
+	if !merge {
+		err := os.Rename(srcPath, dstPath)
+		if err == nil {
+			c.result.Files = 1
+			return c.result, nil
+		}
+		if !isCrossDevice(err) {
+			return nil, err
+		}
+	}
+
+	if err := c.copy(ctx, srcPath, dstPath, info); err != nil {
+		return nil, err
+	}
+	if c.result.Skipped == 0 {
+		if err := os.RemoveAll(srcPath); err != nil {
+			return nil, err
+		}
+	}
+	return c.result, nil
+}
+
+// DeleteOptions 删除选项
+type DeleteOptions struct {
+	// Recursive 删除非空目录时必须设置
+	Recursive bool
+	// Trash 不为 nil 时移入回收站而不是直接删除
+	Trash *Trash
+}
+
+// DeletePath 删除文件或目录
+func DeletePath(ctx context.Context, path string, opts DeleteOptions) (*FileOpResult, error) {
+	cleanPath, err := resolveRemovablePath(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := lstatExisting(cleanPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() && !opts.Recursive {
+		entries, err := os.ReadDir(cleanPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) > 0 {
+			return nil, errcode.New(errcode.InvalidArgument, "目录非空，需要设置 recursive")
+		}
+	}
+
+	result := &FileOpResult{Path: cleanPath, Files: 1}
+	if opts.Trash != nil {
+		if result.TrashID, err = opts.Trash.put(ctx, cleanPath, info); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if err := os.RemoveAll(cleanPath); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// moveAcrossDevices 重命名，跨设备时复制后删除
+func moveAcrossDevices(ctx context.Context, src, dst string, info fs.FileInfo) error {
+	err := os.Rename(src, dst)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	c := &copier{opts: CopyOptions{Recursive: true, Overwrite: OverwriteReplace}, result: &FileOpResult{}}
+	if err := c.copy(ctx, src, dst, info); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+type copier struct {
+	opts   CopyOptions
+	result *FileOpResult
+}
+
+func (c *copier) copy(ctx context.Context, src, dst string, info fs.FileInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		return c.copyDir(ctx, src, dst, info)
+	case info.Mode()&fs.ModeSymlink != 0:
+		return c.copySymlink(src, dst, info)
+	case info.Mode().IsRegular():
+		return c.copyFile(ctx, src, dst, info)
+	}
+	// 设备文件、套接字等不复制
+	c.result.Skipped++
+	return nil
+}
+
+// proceed 按覆盖策略处理已存在的目标，返回 true 表示继续写入
+func (c *copier) proceed(dst string, src, existing fs.FileInfo) (bool, error) {
+	switch c.opts.Overwrite {
+	case OverwriteReplace:
+	case OverwriteSkip:
+		c.result.Skipped++
+		return false, nil
+	case OverwriteIfNewer:
+		if !src.ModTime().After(existing.ModTime()) {
+			c.result.Skipped++
+			return false, nil
+		}
+	default:
+		return false, errcode.New(errcode.AlreadyExists, "目标已存在: %s", dst)
+	}
+	if existing.IsDir() {
+		return false, errcode.New(errcode.AlreadyExists, "目标是目录，不能被覆盖: %s", dst)
+	}
+	return true, nil
+}
+
+func (c *copier) copyDir(ctx context.Context, src, dst string, info fs.FileInfo) error {
+	if existing, err := os.Lstat(dst); err == nil && !existing.IsDir() {
+		if ok, err := c.proceed(dst, info, existing); !ok {
+			return err
+		}
+		// proceed 不允许用文件覆盖目录，反过来用目录替换文件是可以的
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	// 复制过程中保证目录可写，完成后再恢复原权限
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		from, to := filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())
+		if pathValidator.ValidatePath(from) != nil || pathValidator.ValidatePathForWrite(to) != nil {
+			continue
+		}
+		child, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if err := c.copy(ctx, from, to, child); err != nil {
+			return err
+		}
+	}
+	c.result.Files++
+	if err := os.Chmod(dst, fileMode(info.Mode(), unixMode(info.Mode()))); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+func (c *copier) copySymlink(src, dst string, info fs.FileInfo) error {
+	if existing, err := os.Lstat(dst); err == nil {
+		if ok, err := c.proceed(dst, info, existing); !ok {
+			return err
+		}
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(target, dst); err != nil {
+		return err
+	}
+	c.result.Files++
+	return nil
+}
+
+// copyFile 先写入同目录的临时文件再重命名，覆盖时目标不会出现写了一半的内容
+func (c *copier) copyFile(ctx context.Context, src, dst string, info fs.FileInfo) error {
+	if existing, err := os.Lstat(dst); err == nil {
+		if ok, err := c.proceed(dst, info, existing); !ok {
+			return err
+		}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".runixo-copy-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	bufp := bufPool.Get().(*[]byte)
+	n, err := io.CopyBuffer(tmp, &ctxReader{ctx: ctx, r: in}, *bufp)
+	bufPool.Put(bufp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return err
+	}
+	c.result.Files++
+	c.result.Bytes += n
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package executor
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+)
+
+// isCrossDevice 判断重命名失败是否因为源和目标不在同一文件系统
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// fileUsage 返回文件所在设备、inode、硬链接数和实际占用的磁盘空间
+func fileUsage(info fs.FileInfo) (dev, ino, nlink uint64, disk int64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, info.Size(), false
+	}
+	return uint64(st.Dev), uint64(st.Ino), uint64(st.Nlink), int64(st.Blocks) * 512, true
+}
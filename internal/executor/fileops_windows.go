@@ -0,0 +1,20 @@
+//go:build windows
+
+package executor
+
+import (
+	"errors"
+	"io/fs"
+
+	"golang.org/x/sys/windows"
+)
+
+// isCrossDevice 判断重命名失败是否因为源和目标不在同一卷
+func isCrossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
+
+// fileUsage Windows 下无法从 FileInfo 取得设备和 inode，占用空间按文件大小计算
+func fileUsage(info fs.FileInfo) (dev, ino, nlink uint64, disk int64, ok bool) {
+	return 0, 0, 0, info.Size(), false
+}
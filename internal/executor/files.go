@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// ctxReader 每次读取前检查 ctx，使长时间的文件读取可以被取消
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// FileHash 文件校验和
+type FileHash struct {
+	Path      string
+	Algorithm string
+	Hash      string // 小写十六进制
+	Size      int64
+}
+
+// newHash 按名称创建哈希，为空时使用 sha256
+func newHash(algo string) (hash.Hash, string, error) {
+	switch strings.ToLower(algo) {
+	case "", "sha256":
+		return sha256.New(), "sha256", nil
+	case "sha1":
+		return sha1.New(), "sha1", nil
+	case "md5":
+		return md5.New(), "md5", nil
+	}
+	return nil, "", errcode.New(errcode.InvalidArgument, "不支持的哈希算法: %s", algo)
+}
+
+// openRegularFile 校验路径后打开普通文件
+func openRegularFile(path string) (*os.File, os.FileInfo, string, error) {
+	cleanPath, err := resolveReadPath(path)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	f, err := os.Open(cleanPath)
+	if os.IsNotExist(err) {
+		return nil, nil, "", errcode.New(errcode.NotFound, "文件不存在: %s", path)
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, "", err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, "", errcode.New(errcode.InvalidArgument, "%s 不是普通文件", path)
+	}
+	return f, info, cleanPath, nil
+}
+
+// HashFile 计算文件的校验和（md5 / sha1 / sha256），不受读取大小限制
+func HashFile(ctx context.Context, path, algo string) (*FileHash, error) {
+	h, name, err := newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	f, info, cleanPath, err := openRegularFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	bufp := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufp)
+	if _, err := io.CopyBuffer(h, &ctxReader{ctx: ctx, r: f}, *bufp); err != nil {
+		return nil, err
+	}
+	return &FileHash{
+		Path:      cleanPath,
+		Algorithm: name,
+		Hash:      hex.EncodeToString(h.Sum(nil)),
+		Size:      info.Size(),
+	}, nil
+}
+
+// FileComparison 两个文件的比较结果
+type FileComparison struct {
+	Equal bool
+	SizeA int64
+	SizeB int64
+	// FirstDifference 第一个不同字节的偏移，相同时为 -1
+	// 一个文件是另一个的前缀时为较短文件的长度
+	FirstDifference int64
+}
+
+// CompareFiles 逐字节比较两个文件，找到第一个差异即停止读取
+func CompareFiles(ctx context.Context, pathA, pathB string) (*FileComparison, error) {
+	fa, ia, _, err := openRegularFile(pathA)
+	if err != nil {
+		return nil, err
+	}
+	defer fa.Close()
+	fb, ib, _, err := openRegularFile(pathB)
+	if err != nil {
+		return nil, err
+	}
+	defer fb.Close()
+
+	result := &FileComparison{SizeA: ia.Size(), SizeB: ib.Size(), FirstDifference: -1}
+	if os.SameFile(ia, ib) {
+		result.Equal = true
+		return result, nil
+	}
+
+	bufA := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufA)
+	bufB := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufB)
+	ra := &ctxReader{ctx: ctx, r: fa}
+	rb := &ctxReader{ctx: ctx, r: fb}
+
+	var offset int64
+	for {
+		na, errA := io.ReadFull(ra, *bufA)
+		nb, errB := io.ReadFull(rb, (*bufB)[:na])
+		if n := min(na, nb); !bytes.Equal((*bufA)[:n], (*bufB)[:n]) {
+			for i := 0; i < n; i++ {
+				if (*bufA)[i] != (*bufB)[i] {
+					result.FirstDifference = offset + int64(i)
+					return result, nil
+				}
+			}
+		}
+		offset += int64(min(na, nb))
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF || errB == io.EOF || errB == io.ErrUnexpectedEOF {
+			break
+		}
+		if errA != nil {
+			return nil, errA
+		}
+		if errB != nil {
+			return nil, errB
+		}
+	}
+	if ia.Size() == ib.Size() {
+		result.Equal = true
+	} else {
+		result.FirstDifference = offset
+	}
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Limits 命令资源限制，零值字段表示不限制
+//
+// Linux 上内存和进程数优先通过 cgroup v2 限制（需要 root），不可用时退回到
+// rlimit；CPU 时间使用 RLIMIT_CPU。Windows 上使用作业对象，其他平台只支持 MaxOutput。
+type Limits struct {
+	CPUTime   time.Duration // CPU 时间
+	Memory    uint64        // 内存（字节）
+	MaxOutput int64         // stdout 与 stderr 合计的字节数，超过后终止命令
+	MaxProcs  int           // 同时存在的进程数（含命令自身）
+}
+
+// outputBudget stdout 与 stderr 共享的输出额度，用完时调用一次 onExceed
+type outputBudget struct {
+	mu        sync.Mutex
+	limit     int64
+	remaining int64
+	exceeded  bool
+	onExceed  func()
+}
+
+// newOutputBudget limit <= 0 时返回 nil（不限制）
+func newOutputBudget(limit int64, onExceed func()) *outputBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &outputBudget{limit: limit, remaining: limit, onExceed: onExceed}
+}
+
+// take 申请 n 字节，返回实际可用的字节数
+func (b *outputBudget) take(n int) int {
+	if b == nil {
+		return n
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		if !b.exceeded {
+			b.exceeded = true
+			b.onExceed()
+		}
+	}
+	b.remaining -= int64(n)
+	return n
+}
+
+func (b *outputBudget) isExceeded() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exceeded
+}
+
+// cappedWriter 按额度写入缓冲区，超出部分丢弃
+// 始终报告写入成功，让 os/exec 继续读取管道，直到命令被终止
+type cappedWriter struct {
+	buf    bytes.Buffer
+	budget *outputBudget
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	n := w.budget.take(len(p))
+	w.buf.Write(p[:n])
+	return len(p), nil
+}
+
+// limitMessage 根据命令结束状态判断是否因超出限制被终止，返回说明，未超出时返回空
+func limitMessage(l Limits, state *os.ProcessState, oom bool, output *outputBudget) string {
+	switch {
+	case output.isExceeded():
+		return fmt.Sprintf("输出超过 %d 字节限制，命令已被终止", output.limit)
+	case oom:
+		return "命令超出内存限制，已被终止"
+	// RLIMIT_CPU 以秒为单位计数，留出 1 秒误差
+	case l.CPUTime > 0 && state != nil && !state.Success() &&
+		state.UserTime()+state.SystemTime() >= l.CPUTime-time.Second:
+		return "命令超出 CPU 时间限制，已被终止"
+	}
+	return ""
+}
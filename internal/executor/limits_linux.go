@@ -0,0 +1,164 @@
+//go:build linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+var (
+	cgroupOnce   sync.Once
+	cgroupParent string // 为空表示 cgroup v2 不可用
+	cgroupSeq    atomic.Uint64
+)
+
+// limiter 一次命令执行的资源限制状态
+type limiter struct {
+	cgroup string
+}
+
+// initCgroup 创建 runixo-exec 父 cgroup 并为子 cgroup 启用 memory / pids 控制器
+// 需要 root 和 cgroup v2 统一层级，不满足时内存和进程数退回到 rlimit
+func initCgroup() {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return
+	}
+	parent := filepath.Join(cgroupRoot, "runixo-exec")
+	if err := os.Mkdir(parent, 0755); err != nil && !os.IsExist(err) {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +pids"), 0644); err != nil {
+		return
+	}
+	// 清理上次运行遗留的空 cgroup（仍有进程的删除会失败）
+	if entries, err := os.ReadDir(parent); err == nil {
+		for _, e := range entries {
+			if e.IsDir() && strings.HasPrefix(e.Name(), "cmd-") {
+				os.Remove(filepath.Join(parent, e.Name()))
+			}
+		}
+	}
+	cgroupParent = parent
+}
+
+// startCommand 按限制启动命令
+// cgroup 在 clone 时原子加入；rlimit 只能在启动后通过 prlimit 设置，
+// 命令在设置前的极短时间内创建的子进程不受 rlimit 约束
+func startCommand(cmd *exec.Cmd, l Limits) (*limiter, error) {
+	lim := &limiter{}
+	fd := -1
+	if l.Memory > 0 || l.MaxProcs > 0 {
+		cgroupOnce.Do(initCgroup)
+		if cgroupParent != "" {
+			// 创建失败时退回到 rlimit
+			if dir, err := createCgroup(l); err == nil {
+				if fd, err = unix.Open(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0); err == nil {
+					lim.cgroup = dir
+				} else {
+					os.Remove(dir)
+				}
+			}
+		}
+	}
+	if lim.cgroup != "" {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = fd
+	}
+
+	err := cmd.Start()
+	if fd >= 0 {
+		unix.Close(fd)
+	}
+	if err != nil {
+		lim.release()
+		return nil, err
+	}
+	lim.setRlimits(cmd.Process.Pid, l)
+	return lim, nil
+}
+
+// createCgroup 为一次执行创建子 cgroup 并写入限制
+func createCgroup(l Limits) (string, error) {
+	dir := filepath.Join(cgroupParent, fmt.Sprintf("cmd-%d-%d", os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return "", err
+	}
+	write := func(name, value string) error {
+		return os.WriteFile(filepath.Join(dir, name), []byte(value), 0644)
+	}
+	var err error
+	if l.Memory > 0 {
+		err = write("memory.max", strconv.FormatUint(l.Memory, 10))
+		// 禁止换出，否则内存限制形同虚设；未启用 swap 记账的内核没有该文件
+		write("memory.swap.max", "0")
+	}
+	if err == nil && l.MaxProcs > 0 {
+		err = write("pids.max", strconv.Itoa(l.MaxProcs))
+	}
+	if err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// setRlimits 设置 CPU 时间，以及 cgroup 不可用时的内存和进程数 rlimit
+// 通过 sudo 提权的命令可能拒绝修改，错误忽略
+func (lim *limiter) setRlimits(pid int, l Limits) {
+	if l.CPUTime > 0 {
+		secs := uint64((l.CPUTime + time.Second - 1) / time.Second)
+		// 软限制触发 SIGXCPU，硬限制多 1 秒后 SIGKILL
+		unix.Prlimit(pid, unix.RLIMIT_CPU, &unix.Rlimit{Cur: secs, Max: secs + 1}, nil)
+	}
+	if lim.cgroup != "" {
+		return
+	}
+	if l.Memory > 0 {
+		// RLIMIT_DATA 只统计可写的私有映射，不会像 RLIMIT_AS 那样误伤预留大量虚拟地址的程序
+		unix.Prlimit(pid, unix.RLIMIT_DATA, &unix.Rlimit{Cur: l.Memory, Max: l.Memory}, nil)
+	}
+	if l.MaxProcs > 0 {
+		// RLIMIT_NPROC 按用户计数，且对 root 无效
+		n := uint64(l.MaxProcs)
+		unix.Prlimit(pid, unix.RLIMIT_NPROC, &unix.Rlimit{Cur: n, Max: n}, nil)
+	}
+}
+
+// release 命令结束后调用：结束遗留的子进程并删除 cgroup，返回是否发生过 OOM
+func (lim *limiter) release() (oom bool) {
+	if lim == nil || lim.cgroup == "" {
+		return false
+	}
+	if data, err := os.ReadFile(filepath.Join(lim.cgroup, "memory.events")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if f := strings.Fields(line); len(f) == 2 && f[0] == "oom_kill" && f[1] != "0" {
+				oom = true
+			}
+		}
+	}
+	// cgroup.kill 需要 5.14+，旧内核上遗留进程会让删除失败，留待下次启动清理
+	os.WriteFile(filepath.Join(lim.cgroup, "cgroup.kill"), []byte("1"), 0644)
+	for i := 0; i < 20; i++ {
+		if err := os.Remove(lim.cgroup); err == nil || os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return oom
+}
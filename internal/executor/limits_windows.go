@@ -0,0 +1,134 @@
+//go:build windows
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// limiter 命令所在的作业对象，关闭时结束作业中剩余的进程
+type limiter struct {
+	job    windows.Handle
+	memory uint64
+}
+
+// startCommand 挂起启动命令，加入作业对象后再恢复，命令无法在加入前创建子进程
+// 未设置限制时同样使用作业对象，超时或取消时结束整个作业而不只是命令本身
+func startCommand(cmd *exec.Cmd, l Limits) (*limiter, error) {
+	limited := l.CPUTime > 0 || l.Memory > 0 || l.MaxProcs > 0
+
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		if !limited {
+			return nil, cmd.Start()
+		}
+		return nil, fmt.Errorf("创建作业对象失败: %w", err)
+	}
+	lim := &limiter{job: job, memory: l.Memory}
+
+	var info windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+	basic := &info.BasicLimitInformation
+	basic.LimitFlags = windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE
+	if l.CPUTime > 0 {
+		basic.LimitFlags |= windows.JOB_OBJECT_LIMIT_JOB_TIME
+		basic.PerJobUserTimeLimit = int64(l.CPUTime / 100) // 单位 100ns
+	}
+	if l.Memory > 0 {
+		basic.LimitFlags |= windows.JOB_OBJECT_LIMIT_JOB_MEMORY
+		info.JobMemoryLimit = uintptr(l.Memory)
+	}
+	if l.MaxProcs > 0 {
+		basic.LimitFlags |= windows.JOB_OBJECT_LIMIT_ACTIVE_PROCESS
+		basic.ActiveProcessLimit = uint32(l.MaxProcs)
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(job)
+		return nil, fmt.Errorf("设置作业对象限制失败: %w", err)
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+	cmd.Cancel = func() error {
+		return windows.TerminateJobObject(job, 1)
+	}
+	if err := cmd.Start(); err != nil {
+		windows.CloseHandle(job)
+		return nil, err
+	}
+	if err := lim.assign(uint32(cmd.Process.Pid)); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		windows.CloseHandle(job)
+		return nil, fmt.Errorf("加入作业对象失败: %w", err)
+	}
+	return lim, nil
+}
+
+func (lim *limiter) assign(pid uint32) error {
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, pid)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+	if err := windows.AssignProcessToJobObject(lim.job, h); err != nil {
+		return err
+	}
+	return resumeProcess(pid)
+}
+
+// resumeProcess 恢复进程的所有线程（os/exec 不保留主线程句柄）
+func resumeProcess(pid uint32) error {
+	snap, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(snap)
+
+	resumed := false
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	for err = windows.Thread32First(snap, &entry); err == nil; err = windows.Thread32Next(snap, &entry) {
+		if entry.OwnerProcessID != pid {
+			continue
+		}
+		th, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return err
+		}
+		_, err = windows.ResumeThread(th)
+		windows.CloseHandle(th)
+		if err != nil {
+			return err
+		}
+		resumed = true
+	}
+	if !resumed {
+		return errors.New("找不到进程的线程")
+	}
+	return nil
+}
+
+// release 关闭作业对象（结束剩余进程），返回内存峰值是否接近限制
+// 作业对象超出内存时让分配失败而不是结束进程，峰值通常略低于限制
+func (lim *limiter) release() (oom bool) {
+	if lim == nil {
+		return false
+	}
+	if lim.memory > 0 {
+		var info windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+		if err := windows.QueryInformationJobObject(lim.job, windows.JobObjectExtendedLimitInformation,
+			uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info)), nil); err == nil {
+			oom = uint64(info.PeakJobMemoryUsed) >= lim.memory-lim.memory/20
+		}
+	}
+	windows.CloseHandle(lim.job)
+	return oom
+}
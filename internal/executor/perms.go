@@ -0,0 +1,410 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	maxReportedChanges = 1000 // 结果中最多列出的变更
+	maxReportedErrors  = 100
+	setfaclBatchSize   = 100
+)
+
+// PermissionOptions 权限变更选项
+type PermissionOptions struct {
+	// Recursive 递归处理目录，不跟随符号链接，跳过禁止写入的路径
+	Recursive bool
+	// DryRun 只返回将要发生的变更，不修改文件
+	DryRun bool
+}
+
+// PermissionChange 单个路径的变更
+// Old/New 依操作不同为八进制模式、uid:gid 或 ACL 条目
+type PermissionChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// PermissionResult 权限变更结果
+type PermissionResult struct {
+	Changes []PermissionChange // 最多列出 1000 条
+	Changed int                // 发生（或将要发生）变更的路径数
+	Errors  []string           // 递归时单个路径的失败，最多 100 条
+	DryRun  bool
+}
+
+func (r *PermissionResult) add(c *PermissionChange) {
+	r.Changed++
+	if len(r.Changes) < maxReportedChanges {
+		r.Changes = append(r.Changes, *c)
+	}
+}
+
+func (r *PermissionResult) addError(path string, err error) {
+	if len(r.Errors) < maxReportedErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", path, err))
+	}
+}
+
+// walkPermissions 对 path（递归时包括其下所有条目）调用 fn
+// fn 返回 nil 变更表示无需修改；非递归时 fn 的错误直接返回
+func walkPermissions(ctx context.Context, path string, opts PermissionOptions, fn func(p string, info fs.FileInfo) (*PermissionChange, error)) (*PermissionResult, error) {
+	root, err := resolveWritePath(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(root)
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.NotFound, "路径不存在: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PermissionResult{DryRun: opts.DryRun}
+	if !opts.Recursive || !info.IsDir() {
+		change, err := fn(root, info)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			result.add(change)
+		}
+		return result, nil
+	}
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			result.addError(p, err)
+			return nil
+		}
+		if p != root && pathValidator.ValidatePathForWrite(p) != nil {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			result.addError(p, err)
+			return nil
+		}
+		change, err := fn(p, info)
+		if err != nil {
+			result.addError(p, err)
+		} else if change != nil {
+			result.add(change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Chmod 修改文件模式，mode 支持八进制（755、0644）和符号模式（u+x,go-w,a=rX）
+// 符号链接被跳过，因为 chmod 会作用到链接目标
+func Chmod(ctx context.Context, path, mode string, opts PermissionOptions) (*PermissionResult, error) {
+	apply, err := parseModeSpec(mode)
+	if err != nil {
+		return nil, err
+	}
+	return walkPermissions(ctx, path, opts, func(p string, info fs.FileInfo) (*PermissionChange, error) {
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return nil, nil
+		}
+		old := unixMode(info.Mode())
+		next := apply(old, info.IsDir())
+		if old == next {
+			return nil, nil
+		}
+		change := &PermissionChange{Path: p, Old: fmt.Sprintf("%04o", old), New: fmt.Sprintf("%04o", next)}
+		if opts.DryRun {
+			return change, nil
+		}
+		return change, os.Chmod(p, fileMode(info.Mode(), next))
+	})
+}
+
+// unixMode 把 fs.FileMode 的权限位转换为 Unix 的 12 位模式
+func unixMode(m fs.FileMode) uint32 {
+	mode := uint32(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		mode |= 04000
+	}
+	if m&fs.ModeSetgid != 0 {
+		mode |= 02000
+	}
+	if m&fs.ModeSticky != 0 {
+		mode |= 01000
+	}
+	return mode
+}
+
+// fileMode 用 Unix 模式替换 m 的权限位，保留文件类型位
+func fileMode(m fs.FileMode, mode uint32) fs.FileMode {
+	result := m.Type() | fs.FileMode(mode&0777)
+	if mode&04000 != 0 {
+		result |= fs.ModeSetuid
+	}
+	if mode&02000 != 0 {
+		result |= fs.ModeSetgid
+	}
+	if mode&01000 != 0 {
+		result |= fs.ModeSticky
+	}
+	return result
+}
+
+var symbolicClause = regexp.MustCompile(`^([ugoa]*)((?:[-+=][rwxXst]*)+)$`)
+
+// parseModeSpec 解析模式字符串，返回根据旧模式计算新模式的函数
+func parseModeSpec(spec string) (func(old uint32, isDir bool) uint32, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errcode.New(errcode.InvalidArgument, "模式不能为空")
+	}
+	if spec[0] >= '0' && spec[0] <= '7' {
+		mode, err := strconv.ParseUint(spec, 8, 32)
+		if err != nil || mode > 07777 {
+			return nil, errcode.New(errcode.InvalidArgument, "无效的八进制模式: %s", spec)
+		}
+		return func(uint32, bool) uint32 { return uint32(mode) }, nil
+	}
+
+	type op struct {
+		who   uint32 // 作用范围内的全部位（含特殊位）
+		kind  byte
+		perms string
+	}
+	var ops []op
+	for _, clause := range strings.Split(spec, ",") {
+		m := symbolicClause.FindStringSubmatch(clause)
+		if m == nil {
+			return nil, errcode.New(errcode.InvalidArgument, "无效的符号模式: %s", clause)
+		}
+		var who uint32
+		for _, c := range m[1] {
+			switch c {
+			case 'u':
+				who |= 04700
+			case 'g':
+				who |= 02070
+			case 'o':
+				who |= 01007
+			case 'a':
+				who |= 07777
+			}
+		}
+		if who == 0 {
+			who = 07777
+		}
+		actions := m[2]
+		for len(actions) > 0 {
+			end := strings.IndexAny(actions[1:], "+-=") + 1
+			if end == 0 {
+				end = len(actions)
+			}
+			ops = append(ops, op{who: who, kind: actions[0], perms: actions[1:end]})
+			actions = actions[end:]
+		}
+	}
+
+	return func(mode uint32, isDir bool) uint32 {
+		for _, o := range ops {
+			var bits uint32
+			for _, c := range o.perms {
+				switch c {
+				case 'r':
+					bits |= 0444
+				case 'w':
+					bits |= 0222
+				case 'x':
+					bits |= 0111
+				case 'X':
+					if isDir || mode&0111 != 0 {
+						bits |= 0111
+					}
+				case 's':
+					bits |= 06000
+				case 't':
+					bits |= 01000
+				}
+			}
+			bits &= o.who
+			switch o.kind {
+			case '+':
+				mode |= bits
+			case '-':
+				mode &^= bits
+			case '=':
+				clear := o.who
+				if isDir {
+					clear &^= 06000 // 与 chmod 一致，目录的 setuid/setgid 需要显式清除
+				}
+				mode = mode&^clear | bits
+			}
+		}
+		return mode
+	}, nil
+}
+
+// Chown 修改属主，owner 格式为 user、user:group 或 :group，支持名称和数字 ID
+// 使用 lchown，符号链接本身被修改而不影响其目标
+func Chown(ctx context.Context, path, owner string, opts PermissionOptions) (*PermissionResult, error) {
+	if !chownSupported {
+		return nil, errcode.New(errcode.Unimplemented, "当前平台不支持修改属主")
+	}
+	uid, gid, err := lookupOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	return walkPermissions(ctx, path, opts, func(p string, info fs.FileInfo) (*PermissionChange, error) {
+		oldUID, oldGID, ok := fileOwner(info)
+		if !ok {
+			return nil, fmt.Errorf("无法读取属主")
+		}
+		newUID, newGID := oldUID, oldGID
+		if uid >= 0 {
+			newUID = uid
+		}
+		if gid >= 0 {
+			newGID = gid
+		}
+		if newUID == oldUID && newGID == oldGID {
+			return nil, nil
+		}
+		change := &PermissionChange{
+			Path: p,
+			Old:  fmt.Sprintf("%d:%d", oldUID, oldGID),
+			New:  fmt.Sprintf("%d:%d", newUID, newGID),
+		}
+		if opts.DryRun {
+			return change, nil
+		}
+		return change, os.Lchown(p, newUID, newGID)
+	})
+}
+
+// lookupOwner 解析属主字符串，未指定的部分返回 -1
+func lookupOwner(owner string) (uid, gid int, err error) {
+	name, group, _ := strings.Cut(strings.TrimSpace(owner), ":")
+	if name == "" && group == "" {
+		return 0, 0, errcode.New(errcode.InvalidArgument, "属主不能为空")
+	}
+	uid, gid = -1, -1
+	if name != "" {
+		if uid, err = strconv.Atoi(name); err != nil {
+			u, lookupErr := user.Lookup(name)
+			if lookupErr != nil {
+				return 0, 0, errcode.New(errcode.InvalidArgument, "用户不存在: %s", name)
+			}
+			uid, _ = strconv.Atoi(u.Uid)
+		}
+	}
+	if group != "" {
+		if gid, err = strconv.Atoi(group); err != nil {
+			g, lookupErr := user.LookupGroup(group)
+			if lookupErr != nil {
+				return 0, 0, errcode.New(errcode.InvalidArgument, "用户组不存在: %s", group)
+			}
+			gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+	if uid < -1 || gid < -1 {
+		return 0, 0, errcode.New(errcode.InvalidArgument, "无效的属主: %s", owner)
+	}
+	return uid, gid, nil
+}
+
+// aclEntry 对应 setfacl 的条目语法，如 u:www-data:rwx、g:dev:r-x、d:u:deploy:rwX、m::rx
+// 删除时权限部分可以省略（u:www-data）
+var aclEntry = regexp.MustCompile(`^(d(efault)?:)?(u(ser)?|g(roup)?|m(ask)?|o(ther)?):[A-Za-z0-9._-]*(:[rwxX-]{0,3})?$`)
+
+// SetACL 通过 setfacl 修改 POSIX ACL，remove 为 true 时删除条目（setfacl -x）
+// 默认 ACL（d: 前缀）只应用到目录；DryRun 时 Old 为当前 ACL，New 为要应用的条目
+func SetACL(ctx context.Context, path string, entries []string, remove bool, opts PermissionOptions) (*PermissionResult, error) {
+	if len(entries) == 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "ACL 条目不能为空")
+	}
+	var access, defaults []string
+	for _, e := range entries {
+		if !aclEntry.MatchString(e) {
+			return nil, errcode.New(errcode.InvalidArgument, "无效的 ACL 条目: %s", e)
+		}
+		if strings.HasPrefix(e, "d") {
+			defaults = append(defaults, e)
+		} else {
+			access = append(access, e)
+		}
+	}
+	if _, err := exec.LookPath("setfacl"); err != nil {
+		return nil, errcode.New(errcode.Unimplemented, "未找到 setfacl，请安装 acl 软件包")
+	}
+
+	// 按条目组合分批，目录和文件可应用的条目不同
+	batches := map[string][]string{}
+	result, err := walkPermissions(ctx, path, opts, func(p string, info fs.FileInfo) (*PermissionChange, error) {
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return nil, nil
+		}
+		spec := access
+		if info.IsDir() {
+			spec = append(append([]string{}, access...), defaults...)
+		}
+		if len(spec) == 0 {
+			return nil, nil
+		}
+		joined := strings.Join(spec, ",")
+		change := &PermissionChange{Path: p, New: joined}
+		if remove {
+			change.New = "-" + joined
+		}
+		if opts.DryRun {
+			if out, err := exec.CommandContext(ctx, "getfacl", "--omit-header", "--absolute-names", "--", p).Output(); err == nil {
+				change.Old = strings.Join(strings.Fields(string(out)), ",")
+			}
+			return change, nil
+		}
+		batches[joined] = append(batches[joined], p)
+		return change, nil
+	})
+	if err != nil || opts.DryRun {
+		return result, err
+	}
+
+	flag := "-m"
+	if remove {
+		flag = "-x"
+	}
+	for spec, paths := range batches {
+		for start := 0; start < len(paths); start += setfaclBatchSize {
+			chunk := paths[start:min(start+setfaclBatchSize, len(paths))]
+			args := append([]string{flag, spec, "--"}, chunk...)
+			if out, err := exec.CommandContext(ctx, "setfacl", args...).CombinedOutput(); err != nil {
+				msg := strings.TrimSpace(string(out))
+				if !opts.Recursive {
+					return nil, fmt.Errorf("setfacl 失败: %s", msg)
+				}
+				result.addError(chunk[0], fmt.Errorf("%s", msg))
+			}
+		}
+	}
+	return result, nil
+}
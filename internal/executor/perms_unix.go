@@ -0,0 +1,19 @@
+//go:build !windows
+
+package executor
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+const chownSupported = true
+
+// fileOwner 返回文件的 uid 和 gid
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
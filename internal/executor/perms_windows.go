@@ -0,0 +1,12 @@
+//go:build windows
+
+package executor
+
+import "io/fs"
+
+// Windows 使用 ACL 管理所有权，不支持 uid/gid
+const chownSupported = false
+
+func fileOwner(fs.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,25 @@
+package executor
+
+import "time"
+
+// defaultKillGrace 超时或取消时从 SIGTERM 到 SIGKILL 的默认宽限期
+const defaultKillGrace = 5 * time.Second
+
+// killGrace 由 SetKillGrace 设置，0 表示直接 SIGKILL
+var killGrace = defaultKillGrace
+
+// SetKillGrace 设置命令超时或被取消时的宽限期，启动时调用
+// 命令在独立的进程组中运行，先向整个进程组发送 SIGTERM，宽限期后仍未退出的进程被 SIGKILL；
+// Windows 没有对应的信号，作业对象中的进程直接被结束
+func SetKillGrace(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	killGrace = d
+}
+
+// waitDelay 结束进程组后等待输出读取完成的时间
+// 脱离进程组的后台进程可能继续持有输出管道，超过后 Wait 不再等待
+func waitDelay() time.Duration {
+	return killGrace + time.Second
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+
+package executor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup 让命令成为新进程组的组长，ctx 结束时结束整个进程组，
+// 否则命令创建的子进程在命令被结束后继续运行
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	grace := killGrace
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		sig := syscall.SIGTERM
+		if grace <= 0 {
+			sig = syscall.SIGKILL
+		}
+		if err := syscall.Kill(pgid, sig); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				return os.ErrProcessDone
+			}
+			return err
+		}
+		if grace > 0 {
+			// 组长退出后组内仍可能有进程，不能以组长是否退出为准
+			time.AfterFunc(grace, func() { syscall.Kill(pgid, syscall.SIGKILL) })
+		}
+		return nil
+	}
+	cmd.WaitDelay = waitDelay()
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package executor
+
+import "os/exec"
+
+// setProcessGroup 命令及其子进程由 startCommand 放入作业对象，ctx 结束时结束整个作业
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.WaitDelay = waitDelay()
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// TerminalOptions 终端会话选项
+type TerminalOptions struct {
+	Shell      string // 为空时使用 DefaultShell
+	Rows       uint16
+	Cols       uint16
+	Env        map[string]string
+	WorkingDir string
+}
+
+// Terminal 运行在伪终端（Unix PTY / Windows ConPTY）中的交互式 shell
+type Terminal interface {
+	io.ReadWriteCloser
+	// Resize 调整终端窗口大小
+	Resize(rows, cols uint16) error
+	// Signal 向终端的前台进程组发送信号（Windows 只支持终止）
+	Signal(sig syscall.Signal) error
+	// Wait 等待 shell 退出并返回退出码，每个终端必须调用且只调用一次
+	Wait() (int, error)
+}
+
+// DefaultShells 各平台允许的 shell
+func DefaultShells() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"powershell.exe", "pwsh.exe", "cmd.exe"}
+	}
+	return []string{"/bin/bash", "/bin/sh", "/bin/zsh", "/usr/bin/bash", "/usr/bin/zsh", "/usr/bin/fish"}
+}
+
+// DefaultShell 返回 shells 中第一个存在的 shell
+func DefaultShell(shells []string) string {
+	for _, sh := range shells {
+		if _, err := exec.LookPath(sh); err == nil {
+			return sh
+		}
+	}
+	return ""
+}
+
+// StartTerminal 在伪终端中启动 shell，shell 必须在 allowed 列表中
+func StartTerminal(opts TerminalOptions, allowed []string) (Terminal, error) {
+	shell := opts.Shell
+	if shell == "" {
+		shell = DefaultShell(allowed)
+		if shell == "" {
+			return nil, errcode.New(errcode.CommandNotAllowed, "没有可用的 shell")
+		}
+	}
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		return nil, fmt.Errorf("找不到 shell: %w", err)
+	}
+	if !shellAllowed(path, allowed) {
+		return nil, errcode.New(errcode.CommandNotAllowed, "shell '%s' 不在允许列表中", shell)
+	}
+
+	if opts.WorkingDir != "" {
+		if err := pathValidator.ValidatePath(opts.WorkingDir); err != nil {
+			return nil, fmt.Errorf("工作目录安全检查失败: %w", err)
+		}
+	}
+	if opts.Rows == 0 || opts.Cols == 0 {
+		opts.Rows, opts.Cols = 24, 80
+	}
+
+	env := append(FilterEnvVars(os.Environ()), "TERM=xterm-256color")
+	for k, v := range opts.Env {
+		if IsValidEnvVar(k) && !isDangerousEnvVar(k) {
+			env = append(env, k+"="+v)
+		}
+	}
+	return startTerminal(path, env, opts.WorkingDir, opts.Rows, opts.Cols)
+}
+
+// shellAllowed 比较解析后的文件，同名但位于其他目录的程序不会被放行
+func shellAllowed(path string, allowed []string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		p, err := exec.LookPath(a)
+		if err != nil {
+			continue
+		}
+		if ai, err := os.Stat(p); err == nil && os.SameFile(info, ai) {
+			return true
+		}
+	}
+	return false
+}
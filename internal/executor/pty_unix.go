@@ -0,0 +1,60 @@
+//go:build !windows
+
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+)
+
+// unixTerminal 基于 PTY 的终端，shell 是新会话的首进程
+type unixTerminal struct {
+	*os.File // PTY 主设备
+	cmd      *exec.Cmd
+}
+
+func startTerminal(path string, env []string, dir string, rows, cols uint16) (Terminal, error) {
+	cmd := exec.Command(path)
+	cmd.Env = env
+	cmd.Dir = dir
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: rows, Cols: cols})
+	if err != nil {
+		return nil, err
+	}
+	return &unixTerminal{File: f, cmd: cmd}, nil
+}
+
+func (t *unixTerminal) Resize(rows, cols uint16) error {
+	return pty.Setsize(t.File, &pty.Winsize{Rows: rows, Cols: cols})
+}
+
+// Signal 发给前台进程组（例如正在运行的命令），取不到时发给 shell
+func (t *unixTerminal) Signal(sig syscall.Signal) error {
+	if pgrp, err := unix.IoctlGetInt(int(t.File.Fd()), unix.TIOCGPGRP); err == nil && pgrp > 0 {
+		return syscall.Kill(-pgrp, sig)
+	}
+	return t.cmd.Process.Signal(sig)
+}
+
+func (t *unixTerminal) Wait() (int, error) {
+	err := t.cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// Close 关闭 PTY 并结束整个会话
+func (t *unixTerminal) Close() error {
+	err := t.File.Close()
+	// shell 是会话首进程，进程组 ID 等于其 PID
+	syscall.Kill(-t.cmd.Process.Pid, syscall.SIGHUP)
+	return err
+}
@@ -0,0 +1,165 @@
+//go:build windows
+
+package executor
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// conPTY 基于 Windows 伪控制台（ConPTY，Windows 10 1809+）的终端
+type conPTY struct {
+	console windows.Handle
+	input   *os.File // 写入伪控制台的输入
+	output  *os.File // 伪控制台的输出
+	process windows.Handle
+
+	mu        sync.Mutex
+	exited    bool // Wait 已返回，process 句柄已关闭
+	closeOnce sync.Once
+}
+
+func startTerminal(path string, env []string, dir string, rows, cols uint16) (Terminal, error) {
+	var inRead, inWrite, outRead, outWrite windows.Handle
+	if err := windows.CreatePipe(&inRead, &inWrite, nil, 0); err != nil {
+		return nil, err
+	}
+	if err := windows.CreatePipe(&outRead, &outWrite, nil, 0); err != nil {
+		windows.CloseHandle(inRead)
+		windows.CloseHandle(inWrite)
+		return nil, err
+	}
+	var console windows.Handle
+	err := windows.CreatePseudoConsole(windows.Coord{X: int16(cols), Y: int16(rows)}, inRead, outWrite, 0, &console)
+	// 伪控制台持有管道另一端的副本，本进程不再需要
+	windows.CloseHandle(inRead)
+	windows.CloseHandle(outWrite)
+	if err != nil {
+		windows.CloseHandle(inWrite)
+		windows.CloseHandle(outRead)
+		return nil, err
+	}
+	t := &conPTY{
+		console: console,
+		input:   os.NewFile(uintptr(inWrite), "conpty-input"),
+		output:  os.NewFile(uintptr(outRead), "conpty-output"),
+	}
+
+	if t.process, err = createConsoleProcess(console, path, env, dir); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// createConsoleProcess 以伪控制台作为控制台启动进程
+func createConsoleProcess(console windows.Handle, path string, env []string, dir string) (windows.Handle, error) {
+	attrs, err := windows.NewProcThreadAttributeList(1)
+	if err != nil {
+		return 0, err
+	}
+	defer attrs.Delete()
+	// 该属性的值是 HPCON 本身而不是指向它的指针
+	if err := attrs.Update(windows.PROC_THREAD_ATTRIBUTE_PSEUDOCONSOLE, *(*unsafe.Pointer)(unsafe.Pointer(&console)), unsafe.Sizeof(console)); err != nil {
+		return 0, err
+	}
+
+	si := &windows.StartupInfoEx{ProcThreadAttributeList: attrs.List()}
+	si.Cb = uint32(unsafe.Sizeof(*si))
+	// 不继承 Agent 自身的标准输入输出
+	si.Flags = windows.STARTF_USESTDHANDLES
+
+	cmdline, err := windows.UTF16PtrFromString(windows.EscapeArg(path))
+	if err != nil {
+		return 0, err
+	}
+	var dirPtr *uint16
+	if dir != "" {
+		if dirPtr, err = windows.UTF16PtrFromString(dir); err != nil {
+			return 0, err
+		}
+	}
+	block := envBlock(env)
+
+	var pi windows.ProcessInformation
+	err = windows.CreateProcess(nil, cmdline, nil, nil, false,
+		windows.EXTENDED_STARTUPINFO_PRESENT|windows.CREATE_UNICODE_ENVIRONMENT,
+		&block[0], dirPtr, &si.StartupInfo, &pi)
+	if err != nil {
+		return 0, err
+	}
+	windows.CloseHandle(pi.Thread)
+	return pi.Process, nil
+}
+
+// envBlock 构造 CreateProcess 需要的环境块（以两个 NUL 结尾的 UTF-16 字符串序列）
+func envBlock(env []string) []uint16 {
+	var block []uint16
+	for _, kv := range env {
+		block = append(block, utf16.Encode([]rune(kv))...)
+		block = append(block, 0)
+	}
+	return append(block, 0)
+}
+
+func (t *conPTY) Read(p []byte) (int, error)  { return t.output.Read(p) }
+func (t *conPTY) Write(p []byte) (int, error) { return t.input.Write(p) }
+
+func (t *conPTY) Resize(rows, cols uint16) error {
+	return windows.ResizePseudoConsole(t.console, windows.Coord{X: int16(cols), Y: int16(rows)})
+}
+
+// Signal Windows 没有信号，SIGINT 转为 Ctrl+C 输入，SIGTERM / SIGKILL 终止 shell
+func (t *conPTY) Signal(sig syscall.Signal) error {
+	switch sig {
+	case syscall.SIGINT:
+		_, err := t.input.Write([]byte{0x03})
+		return err
+	case syscall.SIGTERM, syscall.SIGKILL:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.exited {
+			return nil
+		}
+		return windows.TerminateProcess(t.process, 1)
+	}
+	return syscall.EWINDOWS
+}
+
+// Wait 只能调用一次，返回后进程句柄被释放
+func (t *conPTY) Wait() (int, error) {
+	_, err := windows.WaitForSingleObject(t.process, windows.INFINITE)
+	var code uint32
+	if err == nil {
+		err = windows.GetExitCodeProcess(t.process, &code)
+	}
+
+	t.mu.Lock()
+	t.exited = true
+	windows.CloseHandle(t.process)
+	t.mu.Unlock()
+	if err != nil {
+		return -1, err
+	}
+	return int(code), nil
+}
+
+// Close 关闭伪控制台（控制台内的进程随之退出）并释放句柄
+func (t *conPTY) Close() error {
+	t.closeOnce.Do(func() {
+		windows.ClosePseudoConsole(t.console)
+		t.mu.Lock()
+		if t.process != 0 && !t.exited {
+			windows.TerminateProcess(t.process, 1)
+		}
+		t.mu.Unlock()
+		t.input.Close()
+		t.output.Close()
+	})
+	return nil
+}
@@ -0,0 +1,40 @@
+package executor
+
+// Sandbox 沙箱执行选项，仅 Linux 支持
+//
+// 命令在独立的用户 / mount / PID / IPC / UTS 命名空间中运行（默认还有独立的网络命名空间），
+// 在主机上以非特权用户的身份运行（Agent 以 root 运行时为 nobody，否则为 Agent 自身的用户），
+// 文件系统以只读方式挂载，/run、/var/run 和 Hidden 中的路径被空的 tmpfs 覆盖，
+// 设置 no_new_privs、丢弃全部 capabilities 并通过 seccomp 禁止 mount、ptrace、
+// 加载内核模块、创建新命名空间等系统调用。沙箱由 Agent 自身作为辅助进程完成隔离后再
+// exec 目标命令，因此 main 需要在启动时调用 RunSandboxHelper。
+type Sandbox struct {
+	// Network 保留主机网络，默认只有回环接口
+	Network bool
+	// Root chroot 根目录，需包含命令及其依赖的库；为空时使用主机文件系统
+	Root string
+	// Writable 保持可写的路径（设置 Root 时相对于 Root），其余挂载点均为只读。
+	// 沙箱进程在主机上不是 root，路径需要对该用户可写
+	Writable []string
+	// Hidden 在沙箱中隐藏的路径（设置 Root 时相对于 Root），如 Agent 的配置和数据目录。
+	// 目录以空的 tmpfs 覆盖，文件（包括 unix 套接字）以 /dev/null 覆盖
+	Hidden []string
+}
+
+// sandboxArg 辅助进程的第一个参数，其后依次为 JSON 配置、命令和参数
+const sandboxArg = "__runixo_sandbox"
+
+// sandboxExitCode 辅助进程初始化失败时的退出码
+const sandboxExitCode = 125
+
+// sandboxScriptDir 沙箱中挂载脚本所在目录的位置，位于覆盖 /run 的 tmpfs 中
+const sandboxScriptDir = "/run/runixo-script"
+
+// sandboxConfig 传给辅助进程的配置
+type sandboxConfig struct {
+	Sandbox
+	// Dir 设置 Root 时 chroot 之后的工作目录
+	Dir string `json:"dir,omitempty"`
+	// ScriptDir 脚本所在的主机目录，挂载到沙箱中的 sandboxScriptDir
+	ScriptDir string `json:"script_dir,omitempty"`
+}
@@ -0,0 +1,282 @@
+//go:build linux
+
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxSupported 没有 seccomp 过滤器的架构（arm、386 等）不支持沙箱，请求沙箱执行时直接拒绝
+const sandboxSupported = seccompSupported
+
+// sandboxUID Agent 以 root 运行时沙箱进程在主机上的 uid / gid（nobody / nogroup）。
+// 沙箱进程在用户命名空间中是 root，但对主机上的文件和 unix 套接字只有 nobody 的权限
+const sandboxUID = 65534
+
+// sandboxHiddenPaths 总是被空的 tmpfs 覆盖的目录，其中有 Docker、systemd、D-Bus 等服务的 unix 套接字
+var sandboxHiddenPaths = []string{"/run", "/var/run"}
+
+// sandboxOwner 沙箱进程在主机上对应的 uid / gid
+func sandboxOwner() (int, int) {
+	if uid := os.Getuid(); uid != 0 {
+		return uid, os.Getgid()
+	}
+	return sandboxUID, sandboxUID
+}
+
+// sandboxCommand 构建通过辅助进程在新命名空间中执行的命令
+func sandboxCommand(ctx context.Context, command string, args []string, opts Options) *exec.Cmd {
+	cfg := sandboxConfig{Sandbox: *opts.Sandbox, ScriptDir: opts.scriptDir}
+	if cfg.Root != "" {
+		cfg.Dir = opts.WorkingDir
+	}
+	data, _ := json.Marshal(cfg)
+	// 辅助进程在 exec 时已是非特权用户，通过 /proc/self/exe 执行不要求该用户能访问 Agent 所在的目录
+	cmd := exec.CommandContext(ctx, "/proc/self/exe", append([]string{sandboxArg, string(data), command}, args...)...)
+
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS)
+	if !cfg.Network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	// 辅助进程在用户命名空间中是 root，可以完成挂载等隔离操作，在主机上则是非特权用户
+	uid, gid := sandboxOwner()
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig:  syscall.SIGKILL,
+		Cloneflags: flags,
+		// 映射后切换为命名空间中的 root，否则仍是映射之外的主机 uid，exec 后没有任何 capability
+		Credential:  &syscall.Credential{Uid: 0, Gid: 0, NoSetGroups: true},
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: uid, Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: gid, Size: 1}},
+	}
+	return cmd
+}
+
+// RunSandboxHelper 在 main 开头调用。进程作为沙箱辅助进程启动时完成隔离后 exec 目标命令，
+// 不会返回；否则直接返回
+func RunSandboxHelper() {
+	if len(os.Args) < 4 || os.Args[1] != sandboxArg {
+		return
+	}
+	var cfg sandboxConfig
+	err := json.Unmarshal([]byte(os.Args[2]), &cfg)
+	if err == nil {
+		err = enterSandbox(cfg, os.Args[3], os.Args[4:])
+	}
+	fmt.Fprintf(os.Stderr, "沙箱初始化失败: %v\n", err)
+	os.Exit(sandboxExitCode)
+}
+
+// enterSandbox 在辅助进程中依次完成挂载隔离、隐藏路径、chroot、no_new_privs、丢弃 capabilities 和 seccomp，成功时不返回
+func enterSandbox(cfg sandboxConfig, command string, args []string) error {
+	// 挂载变更不能传播回主机
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("设置挂载传播失败: %w", err)
+	}
+	root := "/"
+	if cfg.Root != "" {
+		root = filepath.Clean(cfg.Root)
+	}
+	// 脚本目录可能位于随后被隐藏的路径下，先打开
+	scriptDir := -1
+	if cfg.ScriptDir != "" {
+		fd, err := unix.Open(cfg.ScriptDir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("打开脚本目录失败: %w", err)
+		}
+		scriptDir = fd
+	}
+	if err := hidePaths(root, append(append([]string{}, sandboxHiddenPaths...), cfg.Hidden...)); err != nil {
+		return err
+	}
+	if scriptDir >= 0 {
+		target := filepath.Join(root, sandboxScriptDir)
+		if err := os.Mkdir(target, 0755); err != nil {
+			return fmt.Errorf("创建脚本挂载点失败（沙箱根目录中需要 /run 目录）: %w", err)
+		}
+		if err := unix.Mount(fmt.Sprintf("/proc/self/fd/%d", scriptDir), target, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("挂载脚本目录失败: %w", err)
+		}
+		unix.Close(scriptDir)
+	}
+	if err := remountReadOnly(); err != nil {
+		return err
+	}
+	for _, w := range cfg.Writable {
+		p := filepath.Join(root, w)
+		if err := unix.Mount(p, p, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("挂载可写路径 %s 失败: %w", w, err)
+		}
+		if err := unix.Mount("", p, "", unix.MS_REMOUNT|unix.MS_BIND|mountFlags(p), ""); err != nil {
+			return fmt.Errorf("挂载可写路径 %s 失败: %w", w, err)
+		}
+	}
+	// 新的 PID 命名空间需要对应的 /proc，否则 ps 等命令看到的仍是主机进程
+	if _, err := os.Stat(filepath.Join(root, "proc")); err == nil {
+		if err := unix.Mount("proc", filepath.Join(root, "proc"), "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+			return fmt.Errorf("挂载 /proc 失败: %w", err)
+		}
+	}
+	if !cfg.Network {
+		// 新网络命名空间中的回环接口默认是关闭的，失败不影响隔离
+		setLoopbackUp()
+	}
+	unix.Sethostname([]byte("sandbox"))
+
+	if cfg.Root != "" {
+		if err := unix.Chroot(root); err != nil {
+			return fmt.Errorf("chroot 失败: %w", err)
+		}
+		dir := cfg.Dir
+		if dir == "" {
+			dir = "/"
+		}
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("切换工作目录失败: %w", err)
+		}
+	}
+
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return err
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("设置 no_new_privs 失败: %w", err)
+	}
+	if err := dropCapabilities(); err != nil {
+		return fmt.Errorf("丢弃 capabilities 失败: %w", err)
+	}
+	if err := installSeccomp(); err != nil {
+		return fmt.Errorf("安装 seccomp 过滤器失败: %w", err)
+	}
+	return syscall.Exec(path, append([]string{command}, args...), os.Environ())
+}
+
+// dropCapabilities 清空 bounding、ambient、effective、permitted 和 inheritable 集合。
+// 以 root 运行时沙箱进程否则仍持有 CAP_SYS_ADMIN 等权限，可以撤销只读挂载或逃出 chroot
+func dropCapabilities() error {
+	for c := 0; ; c++ {
+		err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0)
+		if err == unix.EINVAL {
+			// 超过内核支持的最大编号
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("从 bounding 集合中移除 %d 失败: %w", c, err)
+		}
+	}
+	// 旧内核不支持 ambient 集合，此时也没有可继承的 ambient capabilities
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil && err != unix.EINVAL {
+		return fmt.Errorf("清空 ambient 集合失败: %w", err)
+	}
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	return unix.Capset(&hdr, &data[0])
+}
+
+// hidePaths 用空的 tmpfs 覆盖目录，用 /dev/null 覆盖文件（包括 unix 套接字）。
+// 不存在的路径和符号链接跳过，符号链接可能指向根目录之外，其目标应单独隐藏
+func hidePaths(root string, paths []string) error {
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		target := filepath.Join(root, p)
+		info, err := os.Lstat(target)
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if info.IsDir() {
+			err = unix.Mount("tmpfs", target, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "mode=755,size=1m")
+		} else {
+			err = unix.Mount("/dev/null", target, "", unix.MS_BIND, "")
+		}
+		if err != nil {
+			return fmt.Errorf("隐藏 %s 失败: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// remountReadOnly 把命名空间中的全部挂载点改为只读
+// 优先使用 mount_setattr（5.12+）一次完成，旧内核上逐个重新挂载
+func remountReadOnly() error {
+	err := unix.MountSetattr(-1, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY})
+	if err == nil {
+		return nil
+	}
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return fmt.Errorf("读取挂载信息失败: %w", err)
+	}
+	defer f.Close()
+	var mounts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// 第 5 列为挂载点，空格等字符以 \040 形式转义
+		if fields := strings.Fields(scanner.Text()); len(fields) > 4 {
+			mounts = append(mounts, unescapeMountPath(fields[4]))
+		}
+	}
+	for _, mp := range mounts {
+		err := unix.Mount("", mp, "", unix.MS_REMOUNT|unix.MS_BIND|unix.MS_RDONLY|mountFlags(mp), "")
+		// 只有根挂载失败时放弃，/proc、/sys 下的部分伪文件系统不支持重新挂载
+		if err != nil && mp == "/" {
+			return fmt.Errorf("只读挂载失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// mountFlags 返回挂载点现有的 nosuid / nodev / noexec 等标志
+// 重新挂载时必须保留，用户命名空间中这些标志是锁定的，去掉会导致 EPERM
+func mountFlags(path string) uintptr {
+	var st unix.Statfs_t
+	if unix.Statfs(path, &st) != nil {
+		return 0
+	}
+	const keep = unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC | unix.MS_NOATIME | unix.MS_NODIRATIME | unix.MS_RELATIME
+	return uintptr(st.Flags) & keep
+}
+
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			var c byte
+			if _, err := fmt.Sscanf(s[i+1:i+4], "%03o", &c); err == nil {
+				b.WriteByte(c)
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func setLoopbackUp() {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return
+	}
+	defer unix.Close(fd)
+	ifr, err := unix.NewIfreq("lo")
+	if err != nil || unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifr) != nil {
+		return
+	}
+	ifr.SetUint16(ifr.Uint16() | unix.IFF_UP)
+	unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifr)
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package executor
+
+import (
+	"context"
+	"os/exec"
+)
+
+const sandboxSupported = false
+
+// sandboxCommand 不会被调用，checkOptions 已拒绝沙箱请求
+func sandboxCommand(ctx context.Context, command string, args []string, opts Options) *exec.Cmd {
+	return exec.CommandContext(ctx, command, args...)
+}
+
+// sandboxOwner 不会被调用，checkOptions 已拒绝沙箱请求
+func sandboxOwner() (int, int) {
+	return -1, -1
+}
+
+// RunSandboxHelper 该平台不支持沙箱
+func RunSandboxHelper() {}
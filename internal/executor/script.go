@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// maxScriptSize 脚本内容的最大长度
+const maxScriptSize = 1024 * 1024 // 1MB
+
+// interpreter 脚本解释器
+type interpreter struct {
+	binaries []string // 按顺序查找第一个存在的程序
+	args     []string // 位于脚本路径之前的参数
+	ext      string
+	crlf     bool // 保留 Windows 换行；其他解释器会把 \r\n 转为 \n
+	bom      bool // 写入 UTF-8 BOM，Windows PowerShell 5.1 否则按系统代码页解析
+}
+
+var interpreters = map[string]interpreter{
+	"bash":   {binaries: []string{"bash"}, ext: ".sh"},
+	"sh":     {binaries: []string{"sh"}, ext: ".sh"},
+	"python": {binaries: []string{"python3", "python"}, ext: ".py"},
+	"powershell": {
+		binaries: []string{"powershell.exe", "pwsh.exe", "pwsh"},
+		args:     []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File"},
+		ext:      ".ps1",
+		crlf:     true,
+		bom:      true,
+	},
+}
+
+// Interpreters 返回支持的解释器名称
+func Interpreters() []string {
+	return []string{"bash", "sh", "python", "powershell"}
+}
+
+// ExecuteScript 把脚本写入仅当前用户可访问的临时目录后用指定解释器执行，结束后删除
+// 脚本内容不经过命令白名单检查，是否允许执行由调用方决定；不支持 sudo
+func ExecuteScript(ctx context.Context, name, script string, args []string, opts Options) (*Result, error) {
+	interp, ok := interpreters[name]
+	if !ok {
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的解释器: %s", name)
+	}
+	if opts.Sudo {
+		return nil, errcode.New(errcode.CommandNotAllowed, "脚本执行不支持 sudo")
+	}
+	if len(script) > maxScriptSize {
+		return nil, errcode.New(errcode.InvalidArgument, "脚本超过 1MB 限制")
+	}
+	if denied := checkOptions(opts); denied != nil {
+		return denied, nil
+	}
+
+	var bin string
+	for _, b := range interp.binaries {
+		if p, err := exec.LookPath(b); err == nil {
+			bin = p
+			break
+		}
+	}
+	if bin == "" {
+		return nil, errcode.New(errcode.NotFound, "找不到解释器: %s", name)
+	}
+
+	// MkdirTemp 创建 0700 目录，其他用户无法读取或替换脚本
+	dir, err := os.MkdirTemp("", "runixo-script-")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if !interp.crlf {
+		script = strings.ReplaceAll(script, "\r\n", "\n")
+	}
+	if interp.bom && !strings.HasPrefix(script, "\uFEFF") {
+		script = "\uFEFF" + script
+	}
+	path := filepath.Join(dir, "script"+interp.ext)
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		return nil, fmt.Errorf("写入脚本失败: %w", err)
+	}
+
+	if opts.Sandbox != nil {
+		// 沙箱进程在主机上不是当前用户，chroot 后也看不到主机的临时目录，
+		// 脚本目录交给沙箱进程所在的用户并挂载到沙箱中的固定位置
+		if uid, gid := sandboxOwner(); uid != os.Getuid() {
+			for _, p := range []string{dir, path} {
+				if err := os.Chown(p, uid, gid); err != nil {
+					return nil, fmt.Errorf("设置脚本属主失败: %w", err)
+				}
+			}
+		}
+		opts.scriptDir = dir
+		path = sandboxScriptDir + "/script" + interp.ext
+	}
+
+	cmdArgs := append(append(append([]string{}, interp.args...), path), args...)
+	return run(ctx, bin, cmdArgs, opts)
+}
@@ -0,0 +1,193 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	defaultSearchResults = 1000
+	maxSearchResults     = 10000
+	defaultFileMatches   = 10
+	maxGrepFileSize      = 20 * 1024 * 1024 // 内容搜索跳过更大的文件
+	maxMatchLineLength   = 512              // 匹配行超过该长度时截断
+)
+
+// SearchOptions 文件搜索条件，零值字段表示不限制
+type SearchOptions struct {
+	Root string
+	// Pattern 文件名通配符（如 *.conf），只匹配文件名
+	Pattern        string
+	MinSize        int64
+	MaxSize        int64
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	// Content 内容正则，设置后只返回有匹配行的文件，并跳过二进制文件
+	Content string
+	// MaxMatches 每个文件最多返回的匹配行，默认 10
+	MaxMatches int
+	// MaxResults 最多返回的文件数，默认 1000，上限 10000
+	MaxResults int
+	// MaxDepth 相对 Root 的最大目录深度，0 表示不限制
+	MaxDepth   int
+	ShowHidden bool
+}
+
+// SearchMatch 内容匹配行
+type SearchMatch struct {
+	Line int
+	Text string
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	File    *FileInfo
+	Matches []SearchMatch
+}
+
+// SearchFiles 在 Root 下递归搜索文件，不跟随符号链接，跳过禁止访问的路径
+// 结果达到上限时 truncated 为 true
+func SearchFiles(ctx context.Context, opts SearchOptions) (results []*SearchResult, truncated bool, err error) {
+	root, err := resolveReadPath(opts.Root)
+	if err != nil {
+		return nil, false, err
+	}
+	if opts.Pattern != "" {
+		if _, err := filepath.Match(opts.Pattern, ""); err != nil {
+			return nil, false, errcode.New(errcode.InvalidArgument, "无效的文件名模式: %v", err)
+		}
+	}
+	var content *regexp.Regexp
+	if opts.Content != "" {
+		if content, err = regexp.Compile(opts.Content); err != nil {
+			return nil, false, errcode.New(errcode.InvalidArgument, "无效的内容正则: %v", err)
+		}
+	}
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = defaultSearchResults
+	}
+	opts.MaxResults = min(opts.MaxResults, maxSearchResults)
+	if opts.MaxMatches <= 0 {
+		opts.MaxMatches = defaultFileMatches
+	}
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return nil // 跳过无法访问的文件
+		}
+		if p != root {
+			if (!opts.ShowHidden && strings.HasPrefix(d.Name(), ".")) || pathValidator.ValidatePath(p) != nil {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() && opts.MaxDepth > 0 {
+				if rel, err := filepath.Rel(root, p); err == nil && strings.Count(rel, string(filepath.Separator))+1 >= opts.MaxDepth {
+					return filepath.SkipDir
+				}
+			}
+		}
+		// 只匹配普通文件，符号链接可能指向禁止访问的路径
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if opts.Pattern != "" {
+			if ok, _ := filepath.Match(opts.Pattern, d.Name()); !ok {
+				return nil
+			}
+		}
+		info, err := d.Info()
+		if err != nil || !matchesFilters(info, opts) {
+			return nil
+		}
+
+		result := &SearchResult{File: &FileInfo{
+			Name:    info.Name(),
+			Path:    p,
+			Size:    info.Size(),
+			Mode:    int64(info.Mode()),
+			ModTime: info.ModTime().Unix(),
+		}}
+		if content != nil {
+			if info.Size() > maxGrepFileSize {
+				return nil
+			}
+			if result.Matches = grepFile(p, content, opts.MaxMatches); len(result.Matches) == 0 {
+				return nil
+			}
+		}
+		if len(results) >= opts.MaxResults {
+			truncated = true
+			return filepath.SkipAll
+		}
+		results = append(results, result)
+		return nil
+	})
+	return results, truncated, err
+}
+
+func matchesFilters(info fs.FileInfo, opts SearchOptions) bool {
+	if opts.MinSize > 0 && info.Size() < opts.MinSize {
+		return false
+	}
+	if opts.MaxSize > 0 && info.Size() > opts.MaxSize {
+		return false
+	}
+	if !opts.ModifiedAfter.IsZero() && info.ModTime().Before(opts.ModifiedAfter) {
+		return false
+	}
+	if !opts.ModifiedBefore.IsZero() && info.ModTime().After(opts.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// grepFile 返回文件中匹配的行，文件开头包含 NUL 字节时视为二进制文件跳过
+func grepFile(path string, re *regexp.Regexp, maxMatches int) []SearchMatch {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	head, err := r.Peek(8000)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+
+	var matches []SearchMatch
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Bytes()
+		if !re.Match(text) {
+			continue
+		}
+		if len(text) > maxMatchLineLength {
+			text = text[:maxMatchLineLength]
+		}
+		matches = append(matches, SearchMatch{Line: line, Text: strings.ToValidUTF8(string(text), "")})
+		if len(matches) >= maxMatches {
+			break
+		}
+	}
+	return matches
+}
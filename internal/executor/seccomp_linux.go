@@ -0,0 +1,86 @@
+//go:build linux && (amd64 || arm64)
+
+package executor
+
+import (
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// seccompSupported 该架构内置了系统调用过滤器
+const seccompSupported = true
+
+const seccompRetKillProcess = 0x80000000
+
+// seccompDenied 沙箱中返回 EPERM 的系统调用：修改挂载与命名空间、调试其他进程、
+// 内核模块与 kexec、BPF 与性能事件、密钥环、修改系统时间等
+var seccompDenied = []uint32{
+	unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_CHROOT,
+	// 新挂载 API，可以清除只读标志或挂载主机块设备
+	unix.SYS_MOUNT_SETATTR, unix.SYS_FSOPEN, unix.SYS_FSCONFIG, unix.SYS_FSMOUNT,
+	unix.SYS_MOVE_MOUNT, unix.SYS_OPEN_TREE, unix.SYS_FSPICK,
+	unix.SYS_UNSHARE, unix.SYS_SETNS,
+	unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE, unix.SYS_DELETE_MODULE,
+	unix.SYS_KEXEC_LOAD, unix.SYS_KEXEC_FILE_LOAD, unix.SYS_REBOOT, unix.SYS_SWAPON, unix.SYS_SWAPOFF,
+	unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN, unix.SYS_USERFAULTFD,
+	unix.SYS_KEYCTL, unix.SYS_ADD_KEY, unix.SYS_REQUEST_KEY,
+	unix.SYS_OPEN_BY_HANDLE_AT, unix.SYS_ACCT,
+	unix.SYS_SETTIMEOFDAY, unix.SYS_CLOCK_SETTIME, unix.SYS_ADJTIMEX, unix.SYS_CLOCK_ADJTIME,
+}
+
+// seccompNamespaceFlags clone 时不允许使用的命名空间标志
+const seccompNamespaceFlags = unix.CLONE_NEWNS | unix.CLONE_NEWUSER | unix.CLONE_NEWPID |
+	unix.CLONE_NEWNET | unix.CLONE_NEWIPC | unix.CLONE_NEWUTS | unix.CLONE_NEWCGROUP
+
+// installSeccomp 安装拒绝列表过滤器，其他架构的系统调用直接终止进程
+func installSeccomp() error {
+	arch := uint32(unix.AUDIT_ARCH_X86_64)
+	if runtime.GOARCH == "arm64" {
+		arch = unix.AUDIT_ARCH_AARCH64
+	}
+	const (
+		ld  = unix.BPF_LD | unix.BPF_W | unix.BPF_ABS
+		jeq = unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K
+		ret = unix.BPF_RET | unix.BPF_K
+		// struct seccomp_data 中的偏移
+		offNr   = 0
+		offArch = 4
+		offArg0 = 16 // 小端序下 args[0] 的低 32 位
+	)
+	eperm := uint32(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+	filter := []unix.SockFilter{
+		{Code: ld, K: offArch},
+		{Code: jeq, Jt: 1, K: arch},
+		{Code: ret, K: seccompRetKillProcess},
+		{Code: ld, K: offNr},
+	}
+	if runtime.GOARCH == "amd64" {
+		// x32 ABI 的系统调用号带有 0x40000000 位，不拦截会绕过拒绝列表
+		filter = append(filter,
+			unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K, Jf: 1, K: 0x40000000},
+			unix.SockFilter{Code: ret, K: eperm},
+		)
+	}
+	for _, nr := range seccompDenied {
+		filter = append(filter,
+			unix.SockFilter{Code: jeq, Jf: 1, K: nr},
+			unix.SockFilter{Code: ret, K: eperm},
+		)
+	}
+	// clone3 的参数在用户内存中无法检查，返回 ENOSYS 让 libc 退回到 clone
+	filter = append(filter,
+		unix.SockFilter{Code: jeq, Jf: 1, K: unix.SYS_CLONE3},
+		unix.SockFilter{Code: ret, K: unix.SECCOMP_RET_ERRNO | uint32(unix.ENOSYS)},
+		unix.SockFilter{Code: jeq, Jf: 3, K: unix.SYS_CLONE},
+		unix.SockFilter{Code: ld, K: offArg0},
+		unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K, Jf: 1, K: seccompNamespaceFlags},
+		unix.SockFilter{Code: ret, K: eperm},
+		unix.SockFilter{Code: ret, K: unix.SECCOMP_RET_ALLOW},
+	)
+
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	return unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&prog)), 0, 0)
+}
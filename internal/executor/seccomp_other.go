@@ -0,0 +1,13 @@
+//go:build linux && !amd64 && !arm64
+
+package executor
+
+import "errors"
+
+// seccompSupported 该架构没有内置的系统调用过滤器，不支持沙箱执行
+const seccompSupported = false
+
+// installSeccomp 不会被调用，checkOptions 已拒绝沙箱请求；仍返回错误以免在没有过滤器时执行命令
+func installSeccomp() error {
+	return errors.New("该架构不支持 seccomp 过滤器")
+}
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// allowedSignals 允许通过 API 发送的信号（平台相关的信号在 signal_unix.go 中补充）
+var allowedSignals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+}
+
+// ParseSignal 解析信号名（支持 TERM / SIGTERM / 15 三种写法）
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return syscall.SIGTERM, nil
+	}
+
+	if num, err := strconv.Atoi(name); err == nil {
+		for _, sig := range allowedSignals {
+			if int(sig) == num {
+				return sig, nil
+			}
+		}
+		return 0, errcode.New(errcode.InvalidArgument, "不允许的信号: %d", num)
+	}
+
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := allowedSignals[name]
+	if !ok {
+		return 0, errcode.New(errcode.InvalidArgument, "不允许的信号: %s", name)
+	}
+	return sig, nil
+}
+
+// SignalProcess 向进程发送信号
+// PID 1 和 Agent 自身默认受保护，只有 force 为 true 时才允许
+func SignalProcess(pid int, sig syscall.Signal, force bool) error {
+	if pid <= 0 {
+		return errcode.New(errcode.InvalidArgument, "无效的 PID: %d", pid)
+	}
+	if !force {
+		if pid == 1 {
+			return errcode.New(errcode.ProcessProtected, "不允许向 PID 1 发送信号（需要 force）")
+		}
+		if pid == os.Getpid() {
+			return errcode.New(errcode.ProcessProtected, "不允许向 Agent 自身发送信号（需要 force）")
+		}
+	}
+
+	allowed := false
+	for _, s := range allowedSignals {
+		if s == sig {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errcode.New(errcode.InvalidArgument, "不允许的信号: %d", int(sig))
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(sig)
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package executor
+
+import "syscall"
+
+func init() {
+	allowedSignals["SIGUSR1"] = syscall.SIGUSR1
+	allowedSignals["SIGUSR2"] = syscall.SIGUSR2
+	allowedSignals["SIGSTOP"] = syscall.SIGSTOP
+	allowedSignals["SIGCONT"] = syscall.SIGCONT
+}
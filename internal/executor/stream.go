@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// OutputFunc 接收流式执行的一段输出，data 只在回调期间有效
+// 返回错误（通常是客户端已断开）时终止命令
+type OutputFunc func(stderr bool, data []byte) error
+
+// ExecuteStream 执行命令并在输出产生时回调，不缓存输出
+// 返回的 Result 中 Stdout / Stderr 为空，只有安全检查失败、超时或超出限制的说明会写入 Stderr
+func ExecuteStream(ctx context.Context, command string, args []string, opts Options, emit OutputFunc) (*Result, error) {
+	if denied := checkCommand(command, args, opts); denied != nil {
+		return denied, nil
+	}
+
+	start := time.Now()
+
+	// 回调失败或输出超过限制时通过 cancel 终止命令
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd := buildCommand(ctx, command, args, opts)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建 stdout 管道失败: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建 stderr 管道失败: %w", err)
+	}
+	budget := newOutputBudget(opts.Limits.MaxOutput, cancel)
+	lim, err := startCommand(cmd, opts.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("启动命令失败: %w", err)
+	}
+
+	// gRPC 流不能并发发送，两个管道的输出串行回调
+	var (
+		mu      sync.Mutex
+		emitErr error
+		wg      sync.WaitGroup
+	)
+	pump := func(r io.Reader, isStderr bool) {
+		defer wg.Done()
+		bufp := bufPool.Get().(*[]byte)
+		defer bufPool.Put(bufp)
+		buf := *bufp
+		for {
+			n, err := r.Read(buf)
+			n = budget.take(n)
+			if n > 0 {
+				mu.Lock()
+				if emitErr == nil {
+					if emitErr = emit(isStderr, buf[:n]); emitErr != nil {
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+	wg.Add(2)
+	go pump(stdout, false)
+	go pump(stderr, true)
+	// 必须读完管道再 Wait，Wait 会关闭管道
+	wg.Wait()
+	err = cmd.Wait()
+	oom := lim.release()
+
+	if emitErr != nil {
+		return nil, emitErr
+	}
+	result := &Result{DurationMs: time.Since(start).Milliseconds()}
+	result.Stderr = limitMessage(opts.Limits, cmd.ProcessState, oom, budget)
+	if err := exitStatus(ctx, err, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	maxTailLineLength = 64 * 1024 // 超过该长度的行被拆分输出，避免无换行的文件占满内存
+	tailPollInterval  = time.Second
+)
+
+// TailOptions 日志跟踪选项
+type TailOptions struct {
+	// Lines 先输出文件末尾的行数
+	Lines int
+	// Follow 持续输出新增内容，文件被轮转（重命名后新建）或截断时自动重新打开
+	Follow bool
+	// Filter 只输出匹配该正则的行，对末尾行同样生效（等价于 tail -n N | grep）
+	Filter string
+}
+
+// TailFile 读取文件末尾并可持续跟踪新增内容
+// 跟踪模式通过 inotify 监听文件所在目录，另有定时轮询兜底（NFS 等文件系统不产生事件）
+func TailFile(ctx context.Context, path string, opts TailOptions) (<-chan string, error) {
+	var filter *regexp.Regexp
+	if opts.Filter != "" {
+		re, err := regexp.Compile(opts.Filter)
+		if err != nil {
+			return nil, errcode.New(errcode.InvalidArgument, "无效的过滤正则: %v", err)
+		}
+		filter = re
+	}
+
+	f, info, cleanPath, err := openRegularFile(path)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := tailOffset(f, info.Size(), opts.Lines)
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	t := &tailer{
+		path:   path,
+		name:   cleanPath,
+		file:   f,
+		reader: bufio.NewReaderSize(f, 64*1024),
+		offset: offset,
+		filter: filter,
+		out:    make(chan string, 100),
+	}
+	go t.run(ctx, opts.Follow)
+	return t.out, nil
+}
+
+// tailOffset 从文件末尾向前查找最后 n 行的起始偏移，末尾的换行不算作一个空行
+func tailOffset(f *os.File, size int64, n int) (int64, error) {
+	if n <= 0 {
+		return size, nil
+	}
+	buf := make([]byte, 32*1024)
+	pos := size
+	newlines := 0
+	for pos > 0 {
+		chunk := min(int64(len(buf)), pos)
+		pos -= chunk
+		if _, err := f.ReadAt(buf[:chunk], pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := chunk - 1; i >= 0; i-- {
+			if buf[i] != '\n' || pos+i == size-1 {
+				continue
+			}
+			if newlines++; newlines == n {
+				return pos + i + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+type tailer struct {
+	path    string // 调用方传入的路径，轮转后按它重新校验
+	name    string // 校验后的实际路径
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64 // 已读取的字节数，用于检测截断
+	partial []byte
+	filter  *regexp.Regexp
+	out     chan string
+}
+
+func (t *tailer) run(ctx context.Context, follow bool) {
+	defer close(t.out)
+	defer func() { t.file.Close() }()
+
+	if !t.drain(ctx) {
+		return
+	}
+	if !follow {
+		t.flush(ctx)
+		return
+	}
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if watcher, err := fsnotify.NewWatcher(); err == nil {
+		defer watcher.Close()
+		// 监听目录而不是文件本身，才能收到轮转时新文件的创建事件
+		if watcher.Add(filepath.Dir(t.name)) == nil {
+			events, errs = watcher.Events, watcher.Errors
+		}
+	}
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(ev.Name) != t.name {
+				continue
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+			continue
+		case <-ticker.C:
+		}
+		if !t.check(ctx) {
+			return
+		}
+	}
+}
+
+// check 读取新增内容，并处理截断和轮转；返回 false 表示应结束跟踪
+func (t *tailer) check(ctx context.Context) bool {
+	if !t.drain(ctx) {
+		return false
+	}
+
+	cur, err := t.file.Stat()
+	if err != nil {
+		return true
+	}
+	// copytruncate 方式的轮转：文件变短后从头读取
+	if cur.Size() < t.offset {
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return true
+		}
+		t.reset()
+		return t.drain(ctx)
+	}
+
+	// 重命名后新建方式的轮转：路径指向了新文件。路径暂时不存在时继续读取旧文件等待新文件出现
+	next, err := os.Stat(t.name)
+	if err != nil || os.SameFile(cur, next) {
+		return true
+	}
+	f, _, cleanPath, err := openRegularFile(t.path)
+	if err != nil {
+		return true
+	}
+	if !t.flush(ctx) {
+		f.Close()
+		return false
+	}
+	t.file.Close()
+	t.file, t.name = f, cleanPath
+	t.reset()
+	return t.drain(ctx)
+}
+
+func (t *tailer) reset() {
+	t.reader.Reset(t.file)
+	t.offset = 0
+	t.partial = t.partial[:0]
+}
+
+// drain 读取到文件末尾，末尾不完整的行保留到下次读取
+func (t *tailer) drain(ctx context.Context) bool {
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		t.offset += int64(len(chunk))
+		t.partial = append(t.partial, chunk...)
+		switch {
+		case err == nil || (err == bufio.ErrBufferFull && len(t.partial) >= maxTailLineLength):
+			if !t.flush(ctx) {
+				return false
+			}
+		case err == bufio.ErrBufferFull:
+		default:
+			return true
+		}
+	}
+}
+
+// flush 输出缓冲中的行
+func (t *tailer) flush(ctx context.Context) bool {
+	if len(t.partial) == 0 {
+		return true
+	}
+	line := strings.TrimRight(string(t.partial), "\r\n")
+	t.partial = t.partial[:0]
+	if t.filter != nil && !t.filter.MatchString(line) {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case t.out <- line:
+		return true
+	}
+}
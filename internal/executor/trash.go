@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// Trash 回收站
+// 每个条目是 <dir>/<id>/ 目录，data 为被删除的文件或目录，info.json 记录原路径
+// 回收站与被删除路径不在同一文件系统时，删除会退化为复制后删除
+type Trash struct {
+	dir       string
+	retention time.Duration
+	mu        sync.Mutex
+}
+
+// TrashEntry 回收站条目
+type TrashEntry struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	IsDir        bool      `json:"is_dir"`
+}
+
+var trashIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}-[0-9a-f]{8}$`)
+
+// NewTrash 创建回收站，retention 为条目保留时长，0 表示不自动清理
+func NewTrash(dir string, retention time.Duration) (*Trash, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建回收站目录失败: %w", err)
+	}
+	return &Trash{dir: dir, retention: retention}, nil
+}
+
+func (t *Trash) put(ctx context.Context, path string, info fs.FileInfo) (string, error) {
+	t.Purge()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	entry := &TrashEntry{
+		ID:           time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix),
+		OriginalPath: path,
+		DeletedAt:    time.Now(),
+		IsDir:        info.IsDir(),
+	}
+	entryDir := filepath.Join(t.dir, entry.ID)
+	if err := os.Mkdir(entryDir, 0700); err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(entry)
+	if err := os.WriteFile(filepath.Join(entryDir, "info.json"), data, 0600); err != nil {
+		os.RemoveAll(entryDir)
+		return "", err
+	}
+	if err := moveAcrossDevices(ctx, path, filepath.Join(entryDir, "data"), info); err != nil {
+		os.RemoveAll(entryDir)
+		return "", fmt.Errorf("移入回收站失败: %w", err)
+	}
+	return entry.ID, nil
+}
+
+// List 按删除时间倒序列出条目
+func (t *Trash) List() ([]*TrashEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dirs, err := os.ReadDir(t.dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*TrashEntry, 0, len(dirs))
+	for _, d := range dirs {
+		if entry, err := t.read(d.Name()); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+func (t *Trash) read(id string) (*TrashEntry, error) {
+	if !trashIDPattern.MatchString(id) {
+		return nil, errcode.New(errcode.InvalidArgument, "无效的回收站条目 ID: %s", id)
+	}
+	data, err := os.ReadFile(filepath.Join(t.dir, id, "info.json"))
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.NotFound, "回收站条目不存在: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry TrashEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Restore 把条目恢复到原路径，原路径已存在时失败
+func (t *Trash) Restore(ctx context.Context, id string) (*FileOpResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, err := t.read(id)
+	if err != nil {
+		return nil, err
+	}
+	// 原路径可能在删除后被加入禁止列表，恢复前重新校验
+	if _, err := resolveWritePath(entry.OriginalPath); err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(entry.OriginalPath); err == nil {
+		return nil, errcode.New(errcode.AlreadyExists, "原路径已存在: %s", entry.OriginalPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.OriginalPath), 0755); err != nil {
+		return nil, err
+	}
+
+	data := filepath.Join(t.dir, id, "data")
+	info, err := os.Lstat(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := moveAcrossDevices(ctx, data, entry.OriginalPath, info); err != nil {
+		return nil, err
+	}
+	os.RemoveAll(filepath.Join(t.dir, id))
+	return &FileOpResult{Path: entry.OriginalPath, Files: 1}, nil
+}
+
+// Purge 清理超过保留时长的条目
+func (t *Trash) Purge() {
+	if t.retention <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dirs, err := os.ReadDir(t.dir)
+	if err != nil {
+		return
+	}
+	for _, d := range dirs {
+		entry, err := t.read(d.Name())
+		if err != nil || time.Since(entry.DeletedAt) < t.retention {
+			continue
+		}
+		os.RemoveAll(filepath.Join(t.dir, d.Name()))
+	}
+}
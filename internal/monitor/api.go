@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/plugin/httpapi"
+)
+
+// Handler 高级监控插件的 HTTP 接口，挂载在 prefix 下，响应格式与 Agent REST API 一致：
+//
+//	GET  /checks                       检查及当前状态
+//	GET  /checks/{name}                单个检查
+//	GET  /checks/{name}/results?limit= 最近的结果，从新到旧，默认全部
+//	POST /checks/{name}/run            立即运行一次
+func (m *Manager) Handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/checks", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, m.Checks())
+	})
+	mux.HandleFunc("GET "+prefix+"/checks/{name}", func(w http.ResponseWriter, r *http.Request) {
+		check, err := m.GetCheck(r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, check, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/checks/{name}/results", func(w http.ResponseWriter, r *http.Request) {
+		limit := maxHistorySize
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				httpapi.WriteError(w, errcode.New(errcode.InvalidArgument, "limit 应为正整数"))
+				return
+			}
+			limit = n
+		}
+		results, err := m.Results(r.PathValue("name"), limit)
+		httpapi.WriteResult(w, http.StatusOK, results, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/checks/{name}/run", func(w http.ResponseWriter, r *http.Request) {
+		result, err := m.RunCheck(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, result, err)
+	})
+	return mux
+}
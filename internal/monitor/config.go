@@ -0,0 +1,236 @@
+// Package monitor 高级监控：按配置定期运行进程、端口、HTTP 和脚本检查，
+// 保存最近的检查结果，连续失败达到阈值时发出告警，恢复后发出恢复通知
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 检查类型
+const (
+	TypeProcess = "process" // 进程存在
+	TypePort    = "port"    // TCP 端口可连接
+	TypeHTTP    = "http"    // HTTP 探测
+	TypeScript  = "script"  // 脚本退出码为 0
+)
+
+// 配置默认值
+const (
+	defaultInterval         = 60
+	defaultTimeout          = 10
+	defaultFailureThreshold = 3
+	defaultHistorySize      = 100
+	maxHistorySize          = 1000
+	minInterval             = 5
+)
+
+// namePattern 检查名
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,63}$`)
+
+// Config 高级监控插件配置
+type Config struct {
+	Checks      map[string]*Check `json:"checks"`
+	Interval    int               `json:"interval"`     // 秒，检查未设置 interval 时的默认间隔
+	HistorySize int               `json:"history_size"` // 每个检查保存的最近结果数
+}
+
+// Check 一项检查，按 Type 使用对应的字段
+type Check struct {
+	Type             string `json:"type"`
+	Interval         int    `json:"interval,omitempty"`          // 秒，默认为 Config.Interval
+	Timeout          int    `json:"timeout,omitempty"`           // 秒，默认 10
+	FailureThreshold int    `json:"failure_threshold,omitempty"` // 连续失败多少次后告警，默认 3
+	Disabled         bool   `json:"disabled,omitempty"`
+
+	// process：进程名等于 Process，或命令行匹配正则 Pattern 的进程数不少于 MinCount
+	Process  string `json:"process,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+	MinCount int    `json:"min_count,omitempty"` // 默认 1
+
+	// port：能在超时内建立 TCP 连接
+	Address string `json:"address,omitempty"` // host:port
+
+	// http：状态码在 ExpectStatus 中（默认 200-399），响应体包含 ExpectBody
+	URL          string            `json:"url,omitempty"`
+	Method       string            `json:"method,omitempty"` // 默认 GET
+	Headers      map[string]string `json:"headers,omitempty"`
+	ExpectStatus []int             `json:"expect_status,omitempty"`
+	ExpectBody   string            `json:"expect_body,omitempty"`
+
+	// script：命令退出码为 0，输出的第一行作为结果说明
+	Command string   `json:"command,omitempty"` // 绝对路径或 PATH 中的命令
+	Args    []string `json:"args,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseConfig 解析并校验插件配置，未设置的项使用默认值
+func ParseConfig(values map[string]any) (*Config, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	config := Config{Interval: defaultInterval, HistorySize: defaultHistorySize}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "监控配置格式无效")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// validate 逐项校验配置并编译正则，错误以字段路径返回
+func (c *Config) validate() error {
+	var fields []errcode.FieldViolation
+	add := func(field, format string, args ...any) {
+		fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Interval < minInterval {
+		add("interval", "至少 %d 秒", minInterval)
+	}
+	if c.HistorySize < 1 || c.HistorySize > maxHistorySize {
+		add("history_size", "应在 1 到 %d 之间", maxHistorySize)
+	}
+	for _, name := range sortedKeys(c.Checks) {
+		field := "checks." + name
+		if !namePattern.MatchString(name) {
+			add(field, "无效的检查名")
+		}
+		check := c.Checks[name]
+		if check == nil {
+			add(field, "不能为空")
+			continue
+		}
+		for _, v := range check.validate() {
+			add(field+"."+v.Field, "%s", v.Description)
+		}
+	}
+
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "监控配置无效", fields)
+	}
+	return nil
+}
+
+// validate 校验检查参数，字段路径相对于检查
+func (c *Check) validate() []errcode.FieldViolation {
+	var fields []errcode.FieldViolation
+	add := func(field, format string, args ...any) {
+		fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Interval != 0 && c.Interval < minInterval {
+		add("interval", "至少 %d 秒", minInterval)
+	}
+	if c.Timeout < 0 {
+		add("timeout", "不能为负数")
+	}
+	if c.FailureThreshold < 0 {
+		add("failure_threshold", "不能为负数")
+	}
+	switch c.Type {
+	case TypeProcess:
+		if c.Process == "" && c.Pattern == "" {
+			add("process", "process 和 pattern 至少设置一项")
+		}
+		if c.Pattern != "" {
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				add("pattern", "无效的正则表达式: %v", err)
+			}
+			c.pattern = re
+		}
+		if c.MinCount < 0 {
+			add("min_count", "不能为负数")
+		}
+	case TypePort:
+		if _, port, err := net.SplitHostPort(c.Address); err != nil || port == "" {
+			add("address", "应为 host:port")
+		}
+	case TypeHTTP:
+		if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("url", "应为 http 或 https 地址")
+		}
+		if c.Method != "" && !validMethods[strings.ToUpper(c.Method)] {
+			add("method", "不支持的方法 %s", c.Method)
+		}
+		for i, s := range c.ExpectStatus {
+			if s < 100 || s > 599 {
+				add(fmt.Sprintf("expect_status[%d]", i), "无效的状态码")
+			}
+		}
+	case TypeScript:
+		if c.Command == "" {
+			add("command", "不能为空")
+		}
+	default:
+		add("type", "应为 process、port、http、script 之一")
+	}
+	return fields
+}
+
+// validMethods HTTP 检查允许的方法
+var validMethods = map[string]bool{http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodOptions: true}
+
+// interval 检查间隔
+func (c *Check) interval(config *Config) time.Duration {
+	if c.Interval > 0 {
+		return time.Duration(c.Interval) * time.Second
+	}
+	return time.Duration(config.Interval) * time.Second
+}
+
+// timeout 单次检查的超时
+func (c *Check) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	return defaultTimeout * time.Second
+}
+
+// failureThreshold 触发告警的连续失败次数
+func (c *Check) failureThreshold() int {
+	if c.FailureThreshold > 0 {
+		return c.FailureThreshold
+	}
+	return defaultFailureThreshold
+}
+
+// Target 检查对象的简短描述，用于告警和展示
+func (c *Check) Target() string {
+	switch c.Type {
+	case TypeProcess:
+		if c.Process != "" {
+			return c.Process
+		}
+		return c.Pattern
+	case TypePort:
+		return c.Address
+	case TypeHTTP:
+		return c.URL
+	case TypeScript:
+		return c.Command
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
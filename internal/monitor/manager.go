@@ -0,0 +1,372 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// checkPrefix 存储中检查记录的键前缀，键为 checks/<检查名>
+const checkPrefix = "checks/"
+
+// tickInterval 调度循环检查到期任务的间隔
+const tickInterval = time.Second
+
+// 检查状态
+const (
+	StatePending = "pending" // 尚未运行
+	StateUp      = "up"      // 最近一次通过
+	StateFailing = "failing" // 失败，但连续失败次数未达到告警阈值
+	StateDown    = "down"    // 连续失败达到阈值，已告警
+)
+
+// 告警状态
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// Store 检查记录的持久化存储，插件的 KV 存储实现该接口
+type Store interface {
+	StorageGet(ctx context.Context, key string) ([]byte, bool, error)
+	StorageSet(ctx context.Context, key string, value []byte) error
+	StorageDelete(ctx context.Context, key string) error
+}
+
+// Env 访问进程、网络和命令的入口，由插件按清单声明的权限提供
+type Env struct {
+	Store           Store
+	HTTPClient      *http.Client
+	LookPath        func(name string) (string, error) // 解析命令路径并检查执行权限
+	CheckNetwork    func(hostport string) error
+	CheckSystemRead func() error
+	WorkDir         string // 脚本的工作目录
+	// OnResult 每次检查结束后调用
+	OnResult func(name string, c *Check, r *Result)
+	// OnAlert 检查进入 down 状态和从 down 恢复时调用
+	OnAlert func(a *Alert)
+}
+
+// Result 一次检查的结果
+type Result struct {
+	OK         bool      `json:"ok"`
+	Message    string    `json:"message"`
+	DurationMs int64     `json:"duration_ms"`
+	Time       time.Time `json:"time"`
+}
+
+// Alert 检查告警或恢复通知
+type Alert struct {
+	Status   string    `json:"status"` // firing 或 resolved
+	Check    string    `json:"check"`
+	Type     string    `json:"type"`
+	Target   string    `json:"target"`
+	Message  string    `json:"message"`
+	Failures int       `json:"failures"` // 触发告警时的连续失败次数
+	Since    time.Time `json:"since"`    // 开始失败的时间
+	Time     time.Time `json:"time"`
+}
+
+// CheckStatus 检查的定义和当前状态
+type CheckStatus struct {
+	Name string `json:"name"`
+	*Check
+	State    string     `json:"state"`
+	Failures int        `json:"consecutive_failures"`
+	Since    *time.Time `json:"since,omitempty"` // 进入当前状态的时间
+	Last     *Result    `json:"last,omitempty"`
+	Running  bool       `json:"running"`
+}
+
+// record 保存在存储中的检查记录，重启后恢复状态并保留最近的结果
+type record struct {
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	Since    time.Time `json:"since"`
+	Results  []*Result `json:"results"` // 从旧到新
+}
+
+// Manager 调度检查并维护状态
+type Manager struct {
+	env Env
+
+	mu      sync.Mutex
+	config  *Config
+	records map[string]*record
+	next    map[string]time.Time // 下一次运行的时间
+	running map[string]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建管理器，从存储恢复检查记录并开始调度
+func NewManager(env Env, config *Config) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		env:     env,
+		config:  config,
+		records: make(map[string]*record),
+		next:    make(map[string]time.Time),
+		running: make(map[string]bool),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for name := range config.Checks {
+		m.records[name] = m.loadRecord(name)
+	}
+	m.wg.Add(1)
+	go m.loop()
+	return m
+}
+
+// SetConfig 替换配置：新增的检查立即运行，修改过的检查按新间隔重新计时，删除的检查清除记录
+func (m *Manager) SetConfig(config *Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old := m.config
+	m.config = config
+	for name, c := range config.Checks {
+		if _, ok := m.records[name]; !ok {
+			m.records[name] = m.loadRecord(name)
+		}
+		if prev := old.Checks[name]; prev == nil || !sameCheck(prev, c) {
+			delete(m.next, name)
+		}
+	}
+	for name := range old.Checks {
+		if _, ok := config.Checks[name]; ok {
+			continue
+		}
+		delete(m.records, name)
+		delete(m.next, name)
+		if err := m.env.Store.StorageDelete(context.Background(), checkPrefix+name); err != nil {
+			log.Warn().Err(err).Str("check", name).Msg("删除检查记录失败")
+		}
+	}
+}
+
+// Config 当前配置
+func (m *Manager) Config() *Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.config
+}
+
+// Close 停止调度并等待进行中的检查结束
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Checks 全部检查的状态，按名称排序
+func (m *Manager) Checks() []*CheckStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	checks := make([]*CheckStatus, 0, len(m.config.Checks))
+	for _, name := range sortedKeys(m.config.Checks) {
+		checks = append(checks, m.status(name))
+	}
+	return checks
+}
+
+// GetCheck 单个检查的状态
+func (m *Manager) GetCheck(name string) (*CheckStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.config.Checks[name]; !ok {
+		return nil, errcode.New(errcode.NotFound, "检查 %s 不存在", name)
+	}
+	return m.status(name), nil
+}
+
+// Results 检查最近的结果，从新到旧，最多 limit 条
+func (m *Manager) Results(name string, limit int) ([]*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.config.Checks[name]; !ok {
+		return nil, errcode.New(errcode.NotFound, "检查 %s 不存在", name)
+	}
+	results := m.records[name].Results
+	out := make([]*Result, 0, min(limit, len(results)))
+	for i := len(results) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, results[i])
+	}
+	return out, nil
+}
+
+// RunCheck 立即运行一次检查，检查正在运行时返回 ALREADY_EXISTS
+func (m *Manager) RunCheck(ctx context.Context, name string) (*Result, error) {
+	m.mu.Lock()
+	c, ok := m.config.Checks[name]
+	if !ok {
+		m.mu.Unlock()
+		return nil, errcode.New(errcode.NotFound, "检查 %s 不存在", name)
+	}
+	if m.running[name] {
+		m.mu.Unlock()
+		return nil, errcode.New(errcode.AlreadyExists, "检查 %s 正在运行", name)
+	}
+	m.running[name] = true
+	m.next[name] = time.Now().Add(c.interval(m.config))
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(m.ctx, cancel)
+	defer stop()
+	return m.run(ctx, name, c), nil
+}
+
+// status 组装检查状态（需要持有锁）
+func (m *Manager) status(name string) *CheckStatus {
+	rec := m.records[name]
+	s := &CheckStatus{Name: name, Check: m.config.Checks[name], State: rec.State, Failures: rec.Failures, Running: m.running[name]}
+	if !rec.Since.IsZero() {
+		since := rec.Since
+		s.Since = &since
+	}
+	if n := len(rec.Results); n > 0 {
+		s.Last = rec.Results[n-1]
+	}
+	return s
+}
+
+// loop 每秒启动到期且未在运行的检查，直到管理器关闭
+func (m *Manager) loop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	m.runDue(time.Now())
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.runDue(now)
+		}
+	}
+}
+
+// runDue 在各自的 goroutine 中启动到期的检查
+func (m *Manager) runDue(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, c := range m.config.Checks {
+		if c.Disabled || m.running[name] {
+			continue
+		}
+		if next, ok := m.next[name]; ok && now.Before(next) {
+			continue
+		}
+		m.running[name] = true
+		m.next[name] = now.Add(c.interval(m.config))
+		m.wg.Add(1)
+		go func(name string, c *Check) {
+			defer m.wg.Done()
+			m.run(m.ctx, name, c)
+		}(name, c)
+	}
+}
+
+// run 执行一次检查，更新状态、保存记录并通知 OnResult 和 OnAlert
+func (m *Manager) run(ctx context.Context, name string, c *Check) *Result {
+	probeCtx, cancel := context.WithTimeout(ctx, c.timeout())
+	start := time.Now()
+	msg, err := m.probe(probeCtx, c)
+	cancel()
+	r := &Result{OK: err == nil, Message: msg, DurationMs: time.Since(start).Milliseconds(), Time: start.UTC()}
+	if err != nil {
+		r.Message = err.Error()
+	}
+	r.Message = truncate(r.Message)
+
+	m.mu.Lock()
+	delete(m.running, name)
+	if cur := m.config.Checks[name]; m.ctx.Err() != nil || cur == nil || !sameCheck(cur, c) {
+		// 管理器已关闭，或检查在运行期间被修改或删除，丢弃结果
+		m.mu.Unlock()
+		return r
+	}
+	rec := m.records[name]
+	alert := rec.apply(c, r, m.config.HistorySize)
+	data, _ := json.Marshal(rec)
+	m.mu.Unlock()
+
+	if alert != nil {
+		alert.Check, alert.Type, alert.Target = name, c.Type, c.Target()
+		log.Warn().Str("check", name).Str("status", alert.Status).Str("message", alert.Message).Msg("监控检查状态变化")
+	}
+	if err := m.env.Store.StorageSet(context.Background(), checkPrefix+name, data); err != nil {
+		log.Warn().Err(err).Str("check", name).Msg("保存检查记录失败")
+	}
+	if m.env.OnResult != nil {
+		m.env.OnResult(name, c, r)
+	}
+	if alert != nil && m.env.OnAlert != nil {
+		m.env.OnAlert(alert)
+	}
+	return r
+}
+
+// apply 按结果更新状态并追加到最近结果，进入 down 或从 down 恢复时返回告警。
+// Since 在 up 状态下为恢复的时间，在 failing 和 down 状态下为开始失败的时间
+func (rec *record) apply(c *Check, r *Result, historySize int) *Alert {
+	var alert *Alert
+	prev := rec.State
+	if r.OK {
+		if prev == StateDown {
+			alert = &Alert{Status: AlertResolved, Message: r.Message, Failures: rec.Failures, Since: rec.Since, Time: r.Time}
+		}
+		if prev != StateUp {
+			rec.Since = r.Time
+		}
+		rec.Failures = 0
+		rec.State = StateUp
+	} else {
+		rec.Failures++
+		if rec.Failures == 1 {
+			rec.Since = r.Time
+		}
+		if prev != StateDown {
+			rec.State = StateFailing
+			if rec.Failures >= c.failureThreshold() {
+				rec.State = StateDown
+				alert = &Alert{Status: AlertFiring, Message: r.Message, Failures: rec.Failures, Since: rec.Since, Time: r.Time}
+			}
+		}
+	}
+
+	rec.Results = append(rec.Results, r)
+	if len(rec.Results) > historySize {
+		rec.Results = append([]*Result(nil), rec.Results[len(rec.Results)-historySize:]...)
+	}
+	return alert
+}
+
+// loadRecord 从存储恢复检查记录，不存在或无法解析时为 pending
+func (m *Manager) loadRecord(name string) *record {
+	rec := &record{State: StatePending}
+	data, found, err := m.env.Store.StorageGet(context.Background(), checkPrefix+name)
+	if err != nil || !found {
+		return rec
+	}
+	if err := json.Unmarshal(data, rec); err != nil {
+		log.Warn().Err(err).Str("check", name).Msg("检查记录无法解析，已忽略")
+		return &record{State: StatePending}
+	}
+	return rec
+}
+
+// sameCheck 判断两个检查定义是否相同
+func sameCheck(a, b *Check) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
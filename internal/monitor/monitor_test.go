@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// memStore 内存中的 Store
+type memStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memStore) StorageGet(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok, nil
+}
+
+func (s *memStore) StorageSet(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *memStore) StorageDelete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// testManager 检查都设为 disabled，由测试通过 RunCheck 运行
+func testManager(t *testing.T, store *memStore, checks map[string]*Check, onAlert func(*Alert)) *Manager {
+	t.Helper()
+	for _, c := range checks {
+		c.Disabled = true
+	}
+	config := &Config{Checks: checks, Interval: 60, HistorySize: 3}
+	if err := config.validate(); err != nil {
+		t.Fatal(err)
+	}
+	allow := func(string) error { return nil }
+	m := NewManager(Env{
+		Store:           store,
+		HTTPClient:      http.DefaultClient,
+		LookPath:        func(name string) (string, error) { return name, nil },
+		CheckNetwork:    allow,
+		CheckSystemRead: func() error { return nil },
+		WorkDir:         t.TempDir(),
+		OnAlert:         onAlert,
+	}, config)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestProbes(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed.Close()
+	defer ln.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "status: healthy")
+	}))
+	defer srv.Close()
+	script := filepath.Join(t.TempDir(), "check.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\necho \"$1\"\nexit $2\n"), 0755)
+	self := filepath.Base(os.Args[0])
+
+	tests := []struct {
+		check   *Check
+		ok      bool
+		message string
+	}{
+		{&Check{Type: TypePort, Address: ln.Addr().String()}, true, "可连接"},
+		{&Check{Type: TypePort, Address: closed.Addr().String(), Timeout: 1}, false, "无法连接"},
+		{&Check{Type: TypeHTTP, URL: srv.URL, ExpectBody: "healthy"}, true, "HTTP 200"},
+		{&Check{Type: TypeHTTP, URL: srv.URL, ExpectBody: "ready"}, false, "不包含"},
+		{&Check{Type: TypeHTTP, URL: srv.URL + "/missing"}, false, "HTTP 404"},
+		{&Check{Type: TypeHTTP, URL: srv.URL + "/missing", ExpectStatus: []int{404}}, true, "HTTP 404"},
+		{&Check{Type: TypeScript, Command: script, Args: []string{"磁盘空间充足", "0"}}, true, "磁盘空间充足"},
+		{&Check{Type: TypeScript, Command: script, Args: []string{"磁盘空间不足", "2"}}, false, "退出码 2: 磁盘空间不足"},
+		{&Check{Type: TypeProcess, Process: self[:min(len(self), 15)]}, true, "有 1 个"},
+		{&Check{Type: TypeProcess, Pattern: `^/nonexistent/daemon\b`}, false, "不存在"},
+	}
+	checks := make(map[string]*Check)
+	for i, tt := range tests {
+		checks[fmt.Sprintf("c%d", i)] = tt.check
+	}
+	m := testManager(t, &memStore{data: make(map[string][]byte)}, checks, nil)
+	for i, tt := range tests {
+		r, err := m.RunCheck(context.Background(), fmt.Sprintf("c%d", i))
+		if err != nil {
+			t.Fatalf("RunCheck: %v", err)
+		}
+		if r.OK != tt.ok || !strings.Contains(r.Message, tt.message) {
+			t.Errorf("%s %s = %+v, want ok=%v 且包含 %q", tt.check.Type, tt.check.Target(), r, tt.ok, tt.message)
+		}
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "check.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\nif [ -e "+filepath.Join(dir, "fail")+" ]; then echo down; exit 1; fi\necho up\n"), 0755)
+	store := &memStore{data: make(map[string][]byte)}
+	var alerts []*Alert
+	onAlert := func(a *Alert) { alerts = append(alerts, a) }
+	checks := func() map[string]*Check {
+		return map[string]*Check{"app": {Type: TypeScript, Command: script, FailureThreshold: 2}}
+	}
+	m := testManager(t, store, checks(), onAlert)
+	ctx := context.Background()
+	run := func(m *Manager, want string) {
+		t.Helper()
+		if _, err := m.RunCheck(ctx, "app"); err != nil {
+			t.Fatal(err)
+		}
+		if s, _ := m.GetCheck("app"); s.State != want {
+			t.Fatalf("state = %s, want %s", s.State, want)
+		}
+	}
+
+	run(m, StateUp)
+	writeFail := func() { os.WriteFile(filepath.Join(dir, "fail"), nil, 0644) }
+	writeFail()
+	run(m, StateFailing)
+	if len(alerts) != 0 {
+		t.Fatalf("未达到阈值时不应告警: %+v", alerts)
+	}
+	run(m, StateDown)
+	run(m, StateDown)
+	if len(alerts) != 1 || alerts[0].Status != AlertFiring || alerts[0].Check != "app" || alerts[0].Failures != 2 || alerts[0].Message != "退出码 1: down" {
+		t.Fatalf("alerts = %+v, want 一次 firing", alerts)
+	}
+
+	// 记录保存在存储中，重启后从 down 恢复仍然发送恢复通知
+	m.Close()
+	m = testManager(t, store, checks(), onAlert)
+	if s, _ := m.GetCheck("app"); s.State != StateDown || s.Failures != 3 {
+		t.Fatalf("恢复的状态 = %+v", s)
+	}
+	os.Remove(filepath.Join(dir, "fail"))
+	run(m, StateUp)
+	if len(alerts) != 2 || alerts[1].Status != AlertResolved || !alerts[1].Since.Equal(alerts[0].Since) {
+		t.Fatalf("alerts = %+v, want firing 后 resolved", alerts)
+	}
+
+	results, err := m.Results("app", 10)
+	if err != nil || len(results) != 3 || !results[0].OK || results[1].OK {
+		t.Errorf("最近结果应保留 history_size 条，从新到旧: %+v, %v", results, err)
+	}
+	if _, err := m.Results("missing", 10); errcode.Of(err) != errcode.NotFound {
+		t.Errorf("不存在的检查 = %v, want NOT_FOUND", err)
+	}
+
+	m.SetConfig(&Config{Checks: map[string]*Check{}, Interval: 60, HistorySize: 3})
+	if len(store.data) != 0 {
+		t.Errorf("删除检查后应清除记录: %v", store.data)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(map[string]any{"checks": map[string]any{
+		"web": map[string]any{"type": "http", "url": "https://example.com/health"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Interval != defaultInterval || config.Checks["web"].failureThreshold() != defaultFailureThreshold {
+		t.Errorf("默认值 = %+v", config)
+	}
+
+	_, err = ParseConfig(map[string]any{"interval": 1, "checks": map[string]any{
+		"a": map[string]any{"type": "ping"},
+		"b": map[string]any{"type": "port", "address": "localhost"},
+		"c": map[string]any{"type": "process", "pattern": "("},
+		"d": map[string]any{"type": "http", "url": "ftp://example.com", "method": "DELETE"},
+	}})
+	if fields := errcode.FieldsOf(err); errcode.Of(err) != errcode.ValidationFailed || len(fields) != 6 {
+		t.Errorf("ParseConfig = %v, fields = %+v", err, fields)
+	}
+}
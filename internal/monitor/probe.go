@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	// maxBodySize HTTP 检查读取的响应体上限
+	maxBodySize = 1 << 20
+	// maxScriptOutput 脚本检查保留的输出上限
+	maxScriptOutput = 4 << 10
+	// maxMessageLength 结果说明的长度上限
+	maxMessageLength = 256
+)
+
+// probe 按类型执行一次检查，返回结果说明；检查不通过时返回错误
+func (m *Manager) probe(ctx context.Context, c *Check) (string, error) {
+	switch c.Type {
+	case TypeProcess:
+		return m.probeProcess(ctx, c)
+	case TypePort:
+		return m.probePort(ctx, c)
+	case TypeHTTP:
+		return m.probeHTTP(ctx, c)
+	case TypeScript:
+		return m.probeScript(ctx, c)
+	}
+	return "", fmt.Errorf("不支持的检查类型 %s", c.Type)
+}
+
+// probeProcess 统计进程名等于 Process 且命令行匹配 Pattern 的进程
+func (m *Manager) probeProcess(ctx context.Context, c *Check) (string, error) {
+	if err := m.env.CheckSystemRead(); err != nil {
+		return "", err
+	}
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("读取进程列表失败: %w", err)
+	}
+	count := 0
+	for _, p := range procs {
+		if c.Process != "" {
+			// 进程在遍历期间退出时读取失败，跳过
+			if name, err := p.NameWithContext(ctx); err != nil || name != c.Process {
+				continue
+			}
+		}
+		if c.pattern != nil {
+			if cmdline, err := p.CmdlineWithContext(ctx); err != nil || !c.pattern.MatchString(cmdline) {
+				continue
+			}
+		}
+		count++
+	}
+	minCount := max(c.MinCount, 1)
+	if count < minCount {
+		if count == 0 {
+			return "", fmt.Errorf("进程 %s 不存在", c.Target())
+		}
+		return "", fmt.Errorf("进程 %s 有 %d 个，少于 %d 个", c.Target(), count, minCount)
+	}
+	return fmt.Sprintf("进程 %s 有 %d 个", c.Target(), count), nil
+}
+
+// probePort 建立 TCP 连接后立即关闭
+func (m *Manager) probePort(ctx context.Context, c *Check) (string, error) {
+	if err := m.env.CheckNetwork(c.Address); err != nil {
+		return "", err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return "", fmt.Errorf("无法连接 %s: %w", c.Address, err)
+	}
+	conn.Close()
+	return fmt.Sprintf("%s 可连接", c.Address), nil
+}
+
+// probeHTTP 发送请求并检查状态码和响应体
+func (m *Manager) probeHTTP(ctx context.Context, c *Check) (string, error) {
+	method := strings.ToUpper(c.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := m.env.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if len(c.ExpectStatus) > 0 {
+		if !slices.Contains(c.ExpectStatus, resp.StatusCode) {
+			return "", fmt.Errorf("HTTP %d，期望 %v", resp.StatusCode, c.ExpectStatus)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if c.ExpectBody != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			return "", fmt.Errorf("读取响应失败: %w", err)
+		}
+		if !bytes.Contains(body, []byte(c.ExpectBody)) {
+			return "", fmt.Errorf("HTTP %d，响应中不包含 %q", resp.StatusCode, c.ExpectBody)
+		}
+	}
+	return fmt.Sprintf("HTTP %d", resp.StatusCode), nil
+}
+
+// probeScript 运行脚本，退出码为 0 时通过，输出的第一行作为说明
+func (m *Manager) probeScript(ctx context.Context, c *Check) (string, error) {
+	path, err := m.env.LookPath(c.Command)
+	if err != nil {
+		return "", err
+	}
+	var out limitedWriter
+	out.max = maxScriptOutput
+	cmd := exec.CommandContext(ctx, path, c.Args...)
+	cmd.Dir = m.env.WorkDir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	msg := firstLine(out.String())
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return "", fmt.Errorf("脚本执行超时")
+	case errors.As(err, &exitErr):
+		if msg == "" {
+			msg = "无输出"
+		}
+		return "", fmt.Errorf("退出码 %d: %s", exitErr.ExitCode(), msg)
+	case err != nil:
+		return "", fmt.Errorf("执行脚本失败: %w", err)
+	}
+	return msg, nil
+}
+
+// firstLine 第一个非空行
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// truncate 按字符截断结果说明
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxMessageLength {
+		return string(r[:maxMessageLength]) + "…"
+	}
+	return s
+}
+
+// limitedWriter 只保留前 max 字节
+type limitedWriter struct {
+	bytes.Buffer
+	max int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.max - w.Len(); room > 0 {
+		w.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
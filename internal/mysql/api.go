@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/plugin/httpapi"
+)
+
+// 慢查询接口返回的条数
+const (
+	defaultSlowQueryLimit = 20
+	maxSlowQueryLimit     = 500
+)
+
+// connectionRequest 添加连接的请求体，连接参数与 Connection 相同
+type connectionRequest struct {
+	Name string `json:"name"`
+	Connection
+}
+
+// Handler MySQL 插件的 HTTP 接口，挂载在 prefix 下，响应格式与 Agent REST API 一致：
+//
+//	GET    /connections                                连接及最近一次采集的状态
+//	POST   /connections                                测试并添加连接（请求体为 name 和 Connection）
+//	PUT    /connections/{name}                         测试并修改通过接口添加的连接
+//	DELETE /connections/{name}                         删除通过接口添加的连接
+//	POST   /connections/{name}/test                    测试连接
+//	GET    /connections/{name}/databases               数据库及大小
+//	GET    /connections/{name}/users                   账号
+//	GET    /connections/{name}/users/{user}/{host}/grants  账号的授权
+//	GET    /connections/{name}/status                  最近一次采集的状态
+//	GET    /connections/{name}/slow-queries?limit=     慢查询摘要
+//	POST   /connections/{name}/query                   执行语句（请求体为 QueryRequest）
+//	GET    /backups                                    备份任务
+//	GET    /backups/{job}                              任务的备份文件
+//	POST   /backups/{job}/run                          在后台运行备份任务
+//	DELETE /backups/{job}/{file}                       删除备份文件
+func (m *Manager) Handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/connections", func(w http.ResponseWriter, r *http.Request) {
+		conns, err := m.Connections(r.Context())
+		httpapi.WriteResult(w, http.StatusOK, conns, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/connections", func(w http.ResponseWriter, r *http.Request) {
+		var req connectionRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		info, err := m.AddConnection(r.Context(), req.Name, &req.Connection)
+		httpapi.WriteResult(w, http.StatusCreated, info, err)
+	})
+	mux.HandleFunc("PUT "+prefix+"/connections/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var c Connection
+		if err := httpapi.DecodeJSON(r, &c); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		info, err := m.UpdateConnection(r.Context(), r.PathValue("name"), &c)
+		httpapi.WriteResult(w, http.StatusOK, info, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/connections/{name}", func(w http.ResponseWriter, r *http.Request) {
+		err := m.DeleteConnection(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, map[string]string{"message": "连接已删除"}, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/connections/{name}/test", func(w http.ResponseWriter, r *http.Request) {
+		info, err := m.TestConnection(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, info, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/connections/{name}/databases", func(w http.ResponseWriter, r *http.Request) {
+		dbs, err := m.Databases(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, dbs, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/connections/{name}/users", func(w http.ResponseWriter, r *http.Request) {
+		users, err := m.Users(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, users, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/connections/{name}/users/{user}/{host}/grants", func(w http.ResponseWriter, r *http.Request) {
+		grants, err := m.Grants(r.Context(), r.PathValue("name"), r.PathValue("user"), r.PathValue("host"))
+		httpapi.WriteResult(w, http.StatusOK, grants, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/connections/{name}/status", func(w http.ResponseWriter, r *http.Request) {
+		status, err := m.Status(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/connections/{name}/slow-queries", func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultSlowQueryLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxSlowQueryLimit {
+				httpapi.WriteError(w, errcode.New(errcode.InvalidArgument, "limit 应为 1 到 %d 之间的整数", maxSlowQueryLimit))
+				return
+			}
+			limit = n
+		}
+		queries, err := m.SlowQueries(r.Context(), r.PathValue("name"), limit)
+		httpapi.WriteResult(w, http.StatusOK, queries, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/connections/{name}/query", func(w http.ResponseWriter, r *http.Request) {
+		var req QueryRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		result, err := m.Query(r.Context(), r.PathValue("name"), req)
+		httpapi.WriteResult(w, http.StatusOK, result, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/backups", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, m.BackupJobs(r.Context()))
+	})
+	mux.HandleFunc("GET "+prefix+"/backups/{job}", func(w http.ResponseWriter, r *http.Request) {
+		backups, err := m.Backups(r.Context(), r.PathValue("job"))
+		httpapi.WriteResult(w, http.StatusOK, backups, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/backups/{job}/run", func(w http.ResponseWriter, r *http.Request) {
+		err := m.StartBackup(r.PathValue("job"))
+		httpapi.WriteResult(w, http.StatusAccepted, map[string]string{"message": "备份已开始"}, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/backups/{job}/{file}", func(w http.ResponseWriter, r *http.Request) {
+		err := m.DeleteBackup(r.Context(), r.PathValue("job"), r.PathValue("file"))
+		httpapi.WriteResult(w, http.StatusOK, map[string]string{"message": "备份已删除"}, err)
+	})
+	return mux
+}
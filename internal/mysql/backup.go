@@ -0,0 +1,365 @@
+package mysql
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// jobStatePrefix 存储中备份任务状态的键前缀，键为 jobs/<任务名>
+	jobStatePrefix = "jobs/"
+	// backupTimeLayout 备份文件名中的时间
+	backupTimeLayout = "20060102-150405"
+	// backupTimeout 一次备份的超时
+	backupTimeout = 6 * time.Hour
+)
+
+// backupFilePattern 备份文件名：<任务名>-<时间>.sql.gz
+var backupFilePattern = regexp.MustCompile(`^(.+)-(\d{8}-\d{6})\.sql\.gz$`)
+
+// BackupFile 一个备份文件
+type BackupFile struct {
+	Job       string    `json:"job"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JobStatus 备份任务及最近一次运行的结果
+type JobStatus struct {
+	Name string `json:"name"`
+	*BackupJob
+	Running    bool        `json:"running"`
+	LastRun    *time.Time  `json:"last_run,omitempty"`
+	LastError  string      `json:"last_error,omitempty"`
+	LastBackup *BackupFile `json:"last_backup,omitempty"` // 最近一次成功的备份
+}
+
+// jobState 保存在存储中的任务状态
+type jobState struct {
+	LastRun    time.Time   `json:"last_run"`
+	LastError  string      `json:"last_error,omitempty"`
+	LastBackup *BackupFile `json:"last_backup,omitempty"`
+}
+
+// BackupJobs 全部备份任务的状态，按名称排序
+func (m *Manager) BackupJobs(ctx context.Context) []*JobStatus {
+	config := m.Config()
+	m.backupMu.Lock()
+	running := make(map[string]bool, len(m.running))
+	for k := range m.running {
+		running[k] = true
+	}
+	m.backupMu.Unlock()
+
+	jobs := make([]*JobStatus, 0, len(config.Backups))
+	for _, name := range sortedKeys(config.Backups) {
+		status := &JobStatus{Name: name, BackupJob: config.Backups[name], Running: running[name]}
+		if state, err := m.loadJobState(ctx, name); err == nil && state != nil {
+			status.LastRun = &state.LastRun
+			status.LastError = state.LastError
+			status.LastBackup = state.LastBackup
+		}
+		jobs = append(jobs, status)
+	}
+	return jobs
+}
+
+// Backups 任务的备份文件，按时间从新到旧排序
+func (m *Manager) Backups(ctx context.Context, job string) ([]*BackupFile, error) {
+	if _, err := m.backupJob(job); err != nil {
+		return nil, err
+	}
+	dir := m.backupDir(job)
+	if err := m.env.CheckRead(dir); err != nil {
+		return nil, err
+	}
+	return listBackups(dir, job)
+}
+
+// DeleteBackup 删除任务的一个备份文件
+func (m *Manager) DeleteBackup(ctx context.Context, job, name string) error {
+	if _, err := m.backupJob(job); err != nil {
+		return err
+	}
+	if match := backupFilePattern.FindStringSubmatch(name); match == nil || match[1] != job {
+		return errcode.New(errcode.InvalidArgument, "无效的备份文件名 %q", name)
+	}
+	path := filepath.Join(m.backupDir(job), name)
+	if err := m.env.CheckWrite(path); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errcode.New(errcode.NotFound, "备份 %s 不存在", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// StartBackup 在后台运行备份任务，任务正在运行时返回 ALREADY_EXISTS
+func (m *Manager) StartBackup(job string) error {
+	if _, err := m.backupJob(job); err != nil {
+		return err
+	}
+	if err := m.acquire(job); err != nil {
+		return err
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer m.release(job)
+		m.runBackup(m.ctx, job)
+	}()
+	return nil
+}
+
+// RunBackup 运行备份任务并清理超出 keep_last 的旧备份，供定时任务调用；Close 时取消
+func (m *Manager) RunBackup(ctx context.Context, job string) (*BackupFile, error) {
+	if m.ctx.Err() != nil {
+		return nil, errcode.New(errcode.Unavailable, "MySQL 管理器已关闭")
+	}
+	if _, err := m.backupJob(job); err != nil {
+		return nil, err
+	}
+	if err := m.acquire(job); err != nil {
+		return nil, err
+	}
+	defer m.release(job)
+	m.wg.Add(1)
+	defer m.wg.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(m.ctx, cancel)
+	defer stop()
+	return m.runBackup(ctx, job)
+}
+
+// runBackup 运行一次备份，记录任务状态并通知 OnBackup
+func (m *Manager) runBackup(ctx context.Context, job string) (*BackupFile, error) {
+	start := time.Now()
+	b, err := m.dump(ctx, job)
+	if err != nil {
+		log.Error().Err(err).Str("job", job).Msg("MySQL 备份失败")
+	} else {
+		log.Info().Str("job", job).Str("file", b.Path).Int64("size", b.Size).Dur("duration", time.Since(start)).Msg("MySQL 备份完成")
+	}
+	m.saveJobState(job, b, err)
+	if m.env.OnBackup != nil {
+		m.env.OnBackup(job, b, err)
+	}
+	return b, err
+}
+
+// dump 用 mysqldump 导出数据库，gzip 压缩后写入备份目录，最后清理旧备份
+func (m *Manager) dump(ctx context.Context, name string) (*BackupFile, error) {
+	job, err := m.backupJob(name)
+	if err != nil {
+		return nil, err
+	}
+	c, _, err := m.connection(ctx, job.Connection)
+	if err != nil {
+		return nil, err
+	}
+	dir := m.backupDir(name)
+	if err := m.env.CheckWrite(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, backupTimeout)
+	defer cancel()
+
+	args := []string{"--single-transaction", "--quick", "--routines", "--triggers", "--events", "--hex-blob"}
+	if len(job.Databases) > 0 {
+		args = append(append(args, "--databases"), job.Databases...)
+	} else {
+		args = append(args, "--all-databases")
+	}
+	cmd, stderr, err := m.command(ctx, c, "mysqldump", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	created := time.Now().UTC()
+	file := name + "-" + created.Format(backupTimeLayout) + ".sql.gz"
+	path := filepath.Join(dir, file)
+	if _, err := os.Stat(path); err == nil {
+		return nil, errcode.New(errcode.AlreadyExists, "备份 %s 已存在，请稍后重试", file)
+	}
+	tmp := path + ".part"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	cmd.Stdout = gz
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, errcode.Wrap(errcode.Unavailable, ctx.Err(), "备份被取消或超时")
+		}
+		return nil, clientError(err, stderr.String())
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := out.Sync(); err != nil {
+		return nil, err
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	if err := prune(dir, name, job.keepLast()); err != nil {
+		log.Warn().Err(err).Str("job", name).Msg("清理旧 MySQL 备份失败")
+	}
+	return &BackupFile{Job: name, Name: file, Path: path, Size: info.Size(), CreatedAt: created}, nil
+}
+
+// backupJob 按名称查找配置中的备份任务
+func (m *Manager) backupJob(name string) (*BackupJob, error) {
+	job, ok := m.Config().Backups[name]
+	if !ok || job == nil {
+		return nil, errcode.New(errcode.NotFound, "备份任务 %s 不存在", name)
+	}
+	return job, nil
+}
+
+// backupDir 任务的备份目录
+func (m *Manager) backupDir(job string) string {
+	dir := m.Config().BackupDir
+	if dir == "" {
+		dir = filepath.Join(m.env.DataDir, "backups")
+	}
+	return filepath.Join(dir, job)
+}
+
+// acquire 标记任务正在运行，同一任务不能同时运行多次
+func (m *Manager) acquire(job string) error {
+	m.backupMu.Lock()
+	defer m.backupMu.Unlock()
+	if m.running[job] {
+		return errcode.New(errcode.AlreadyExists, "备份任务 %s 正在运行", job)
+	}
+	m.running[job] = true
+	return nil
+}
+
+func (m *Manager) release(job string) {
+	m.backupMu.Lock()
+	defer m.backupMu.Unlock()
+	delete(m.running, job)
+}
+
+func (m *Manager) loadJobState(ctx context.Context, name string) (*jobState, error) {
+	data, found, err := m.env.Store.StorageGet(ctx, jobStatePrefix+name)
+	if err != nil || !found {
+		return nil, err
+	}
+	var state jobState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// saveJobState 记录任务最近一次运行的结果，失败时保留上次成功的备份
+func (m *Manager) saveJobState(name string, b *BackupFile, runErr error) {
+	ctx := context.Background()
+	state, _ := m.loadJobState(ctx, name)
+	if state == nil {
+		state = &jobState{}
+	}
+	state.LastRun = time.Now().UTC()
+	state.LastError = ""
+	if runErr != nil {
+		state.LastError = runErr.Error()
+	} else if b != nil {
+		state.LastBackup = b
+	}
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = m.env.Store.StorageSet(ctx, jobStatePrefix+name, data)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("job", name).Msg("保存 MySQL 备份任务状态失败")
+	}
+}
+
+// listBackups 目录中任务的备份文件，按时间从新到旧排序；目录不存在时为空
+func listBackups(dir, job string) ([]*BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []*BackupFile{}, nil
+		}
+		return nil, err
+	}
+	backups := []*BackupFile{}
+	for _, e := range entries {
+		match := backupFilePattern.FindStringSubmatch(e.Name())
+		if match == nil || match[1] != job || !e.Type().IsRegular() {
+			continue
+		}
+		created, err := time.Parse(backupTimeLayout, match[2])
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, &BackupFile{
+			Job:       job,
+			Name:      e.Name(),
+			Path:      filepath.Join(dir, e.Name()),
+			Size:      info.Size(),
+			CreatedAt: created,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// prune 只保留最近的 keep 个备份
+func prune(dir, job string, keep int) error {
+	backups, err := listBackups(dir, job)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, b := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(b.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("删除旧备份失败: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
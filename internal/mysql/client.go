@@ -0,0 +1,259 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// connectTimeout 客户端连接超时（秒）
+	connectTimeout = 10
+	// maxCommandStderr 保留的命令错误输出上限
+	maxCommandStderr = 4 << 10
+)
+
+// errorPattern mysql 客户端的错误输出，如 ERROR 1045 (28000): Access denied ...
+var errorPattern = regexp.MustCompile(`ERROR (\d+)(?: \(\w+\))?(?: at line \d+)?: (.*)`)
+
+// ResultSet 一条语句返回的结果
+type ResultSet struct {
+	Columns []string    `json:"columns"`
+	Rows    [][]*string `json:"rows"` // NULL 为 null
+
+	statement string // 产生结果的语句
+}
+
+// clientArgs 连接参数，不含密码
+func clientArgs(c *Connection) []string {
+	args := []string{"--connect-timeout=" + strconv.Itoa(connectTimeout), "--default-character-set=utf8mb4"}
+	if c.Socket != "" {
+		args = append(args, "--protocol=SOCKET", "--socket="+c.Socket)
+	} else {
+		host, port := c.Host, c.Port
+		if host == "" {
+			host = defaultHost
+		}
+		if port == 0 {
+			port = defaultPort
+		}
+		args = append(args, "--protocol=TCP", "--host="+host, "--port="+strconv.Itoa(port))
+	}
+	if c.User != "" {
+		args = append(args, "--user="+c.User)
+	}
+	return args
+}
+
+// checkAccess 按连接方式检查 network 权限或套接字文件的 file.read 权限
+func (m *Manager) checkAccess(c *Connection) error {
+	if c.Socket != "" {
+		return m.env.CheckRead(c.Socket)
+	}
+	return m.env.CheckNetwork(c.address())
+}
+
+// command 创建数据库客户端命令，密码通过环境变量传入
+func (m *Manager) command(ctx context.Context, c *Connection, name string, args ...string) (*exec.Cmd, *limitedWriter, error) {
+	if err := m.checkAccess(c); err != nil {
+		return nil, nil, err
+	}
+	bin, err := m.env.LookPath(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	stderr := &limitedWriter{max: maxCommandStderr}
+	cmd := exec.CommandContext(ctx, bin, append(clientArgs(c), args...)...)
+	cmd.Env = os.Environ()
+	if c.Password != "" {
+		cmd.Env = append(cmd.Env, "MYSQL_PWD="+c.Password)
+	}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	return cmd, stderr, nil
+}
+
+// query 用 mysql --xml 执行脚本并解析结果，最多读取 maxRows 行（所有结果合计），超出时停止执行并返回 truncated
+// --binary-mode 在非交互模式下禁用 system、source 等客户端命令
+func (m *Manager) query(ctx context.Context, c *Connection, database, script string, maxRows int) ([]*ResultSet, bool, error) {
+	args := []string{"--xml", "--batch", "--binary-mode"}
+	if database != "" {
+		args = append(args, "--database="+database)
+	}
+	cmd, stderr, err := m.command(ctx, c, "mysql", args...)
+	if err != nil {
+		return nil, false, err
+	}
+	cmd.Stdin = strings.NewReader(script)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, false, err
+	}
+
+	results, truncated, parseErr := parseResults(stdout, maxRows)
+	if truncated || parseErr != nil {
+		// 停止读取后结束客户端，避免其阻塞在写输出上；连接断开时服务端回滚未提交的事务
+		cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	switch {
+	case truncated:
+		return results, true, nil
+	case ctx.Err() != nil:
+		return nil, false, errcode.Wrap(errcode.Unavailable, ctx.Err(), "查询超时")
+	case waitErr != nil && (parseErr == nil || stderr.Len() > 0):
+		return nil, false, clientError(waitErr, stderr.String())
+	case parseErr != nil:
+		return nil, false, errcode.Wrap(errcode.Internal, parseErr, "无法解析 mysql 的输出")
+	}
+	return results, false, nil
+}
+
+// clientError 把客户端错误转为错误码：连接失败为 UNAVAILABLE，数据库拒绝访问为 PERMISSION_DENIED，
+// 数据库、表或账号不存在为 NOT_FOUND，其余为 INVALID_ARGUMENT
+func clientError(err error, stderr string) error {
+	stderr = strings.TrimSpace(stderr)
+	match := errorPattern.FindStringSubmatch(stderr)
+	if match == nil {
+		if stderr == "" {
+			return err
+		}
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	code, _ := strconv.Atoi(match[1])
+	msg := fmt.Sprintf("MySQL 错误 %d: %s", code, match[2])
+	switch {
+	case code >= 2000 && code < 3000:
+		return errcode.Wrap(errcode.Unavailable, err, msg)
+	case code == 1044 || code == 1045 || code == 1142 || code == 1143 || code == 1227:
+		return errcode.Wrap(errcode.PermissionDenied, err, msg)
+	case code == 1049 || code == 1141 || code == 1146:
+		return errcode.Wrap(errcode.NotFound, err, msg)
+	default:
+		return errcode.Wrap(errcode.InvalidArgument, err, msg)
+	}
+}
+
+// parseResults 解析 mysql --xml 的输出：每条返回结果的语句一个 <resultset>，
+// 每行一个 <row>，<field name="..."> 为列值，xsi:nil="true" 表示 NULL
+func parseResults(r io.Reader, maxRows int) ([]*ResultSet, bool, error) {
+	d := xml.NewDecoder(r)
+	results := []*ResultSet{}
+	var rs *ResultSet
+	var row []*string
+	var field *bytes.Buffer
+	var null bool
+	rows := 0
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return results, false, nil
+		}
+		if err != nil {
+			return results, false, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "resultset":
+				rs = &ResultSet{Columns: []string{}, Rows: [][]*string{}}
+				for _, a := range t.Attr {
+					if a.Name.Local == "statement" {
+						rs.statement = a.Value
+					}
+				}
+				results = append(results, rs)
+			case "row":
+				if rows >= maxRows {
+					return results, true, nil
+				}
+				row = []*string{}
+			case "field":
+				field, null = &bytes.Buffer{}, false
+				for _, a := range t.Attr {
+					if a.Name.Local == "nil" && a.Value == "true" {
+						null = true
+					}
+					if a.Name.Local == "name" && rs != nil && len(rs.Rows) == 0 {
+						rs.Columns = append(rs.Columns, a.Value)
+					}
+				}
+			}
+		case xml.CharData:
+			if field != nil {
+				field.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "field":
+				if null {
+					row = append(row, nil)
+				} else {
+					v := field.String()
+					row = append(row, &v)
+				}
+				field = nil
+			case "row":
+				if rs != nil {
+					rs.Rows = append(rs.Rows, row)
+					rows++
+				}
+			}
+		}
+	}
+}
+
+// value 按列名取第一行的值，NULL 或不存在时为空
+func (rs *ResultSet) value(column string) string {
+	if len(rs.Rows) == 0 {
+		return ""
+	}
+	for i, c := range rs.Columns {
+		if strings.EqualFold(c, column) && i < len(rs.Rows[0]) && rs.Rows[0][i] != nil {
+			return *rs.Rows[0][i]
+		}
+	}
+	return ""
+}
+
+// maps 把每行转为列名到值的映射，NULL 为空字符串
+func (rs *ResultSet) maps() []map[string]string {
+	out := make([]map[string]string, 0, len(rs.Rows))
+	for _, row := range rs.Rows {
+		m := make(map[string]string, len(rs.Columns))
+		for i, c := range rs.Columns {
+			if i < len(row) && row[i] != nil {
+				m[c] = *row[i]
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// limitedWriter 只保留前 max 字节
+type limitedWriter struct {
+	bytes.Buffer
+	max int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.max - w.Len(); room > 0 {
+		w.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,211 @@
+// Package mysql MySQL 管理：管理连接，列出数据库和用户，采集连接数和慢查询指标，
+// 定时用 mysqldump 逻辑备份，并提供带只读模式和语句检查的查询接口。
+// 通过系统的 mysql 和 mysqldump 客户端访问数据库，兼容 MySQL 5.7+ 和 MariaDB 10.3+
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 配置默认值
+const (
+	defaultPort            = 3306
+	defaultHost            = "127.0.0.1"
+	defaultMaxRows         = 1000
+	defaultQueryTimeout    = 30
+	defaultMetricsInterval = 15
+	defaultKeepLast        = 7
+)
+
+// namePattern 连接名和备份任务名，与插件定时任务名一致
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// Config MySQL 插件配置
+type Config struct {
+	Connections     map[string]*Connection `json:"connections"`
+	Backups         map[string]*BackupJob  `json:"backups"`
+	ReadOnly        bool                   `json:"read_only"`        // 查询接口只允许只读语句，对所有连接生效
+	MaxRows         int                    `json:"max_rows"`         // 查询返回的最大行数
+	QueryTimeout    int                    `json:"query_timeout"`    // 秒，查询接口的超时
+	MetricsInterval int                    `json:"metrics_interval"` // 秒，状态采集间隔
+	BackupDir       string                 `json:"backup_dir"`       // 备份目录，为空时为插件数据目录下的 backups
+}
+
+// Connection 数据库连接；Socket 为空时通过 TCP 连接 Host:Port
+type Connection struct {
+	Host     string `json:"host,omitempty"` // 默认 127.0.0.1
+	Port     int    `json:"port,omitempty"` // 默认 3306
+	Socket   string `json:"socket,omitempty"`
+	User     string `json:"user,omitempty"`      // 为空时使用 Agent 运行用户（配合 auth_socket 认证）
+	Password string `json:"password,omitempty"`  // 通过环境变量传给客户端，不出现在命令行中
+	Database string `json:"database,omitempty"`  // 查询的默认数据库
+	ReadOnly bool   `json:"read_only,omitempty"` // 查询接口只允许只读语句
+}
+
+// BackupJob 逻辑备份任务，每次把选定的数据库导出为一个 gzip 压缩的 SQL 文件
+type BackupJob struct {
+	Connection string   `json:"connection"`
+	Databases  []string `json:"databases,omitempty"` // 为空时备份全部数据库
+	Schedule   string   `json:"schedule,omitempty"`  // cron 表达式或 @daily 等，为空时只能手动运行
+	Timezone   string   `json:"timezone,omitempty"`
+	KeepLast   int      `json:"keep_last,omitempty"` // 保留最近的备份数，默认 7
+}
+
+// ParseConfig 解析并校验插件配置，未设置的项使用默认值
+func ParseConfig(values map[string]any) (*Config, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	config := Config{
+		MaxRows:         defaultMaxRows,
+		QueryTimeout:    defaultQueryTimeout,
+		MetricsInterval: defaultMetricsInterval,
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "MySQL 配置格式无效")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// validate 逐项校验配置，错误以字段路径返回
+func (c *Config) validate() error {
+	var fields []errcode.FieldViolation
+	add := func(field, format string, args ...any) {
+		fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range sortedKeys(c.Connections) {
+		field := "connections." + name
+		if !namePattern.MatchString(name) {
+			add(field, "无效的连接名")
+		}
+		conn := c.Connections[name]
+		if conn == nil {
+			add(field, "不能为空")
+			continue
+		}
+		for _, v := range conn.validate() {
+			add(field+"."+v.Field, "%s", v.Description)
+		}
+	}
+	for _, name := range sortedKeys(c.Backups) {
+		field := "backups." + name
+		if !namePattern.MatchString(name) {
+			add(field, "无效的任务名")
+		}
+		job := c.Backups[name]
+		if job == nil {
+			add(field, "不能为空")
+			continue
+		}
+		// 连接也可以通过接口添加，运行时再检查是否存在
+		if !namePattern.MatchString(job.Connection) {
+			add(field+".connection", "无效的连接名")
+		}
+		for i, db := range job.Databases {
+			if !identifierPattern.MatchString(db) {
+				add(fmt.Sprintf("%s.databases[%d]", field, i), "无效的数据库名")
+			}
+		}
+		if job.KeepLast < 0 {
+			add(field+".keep_last", "不能为负数")
+		}
+	}
+	if c.MaxRows < 1 {
+		add("max_rows", "至少为 1")
+	}
+	if c.QueryTimeout < 1 {
+		add("query_timeout", "至少 1 秒")
+	}
+	if c.MetricsInterval < 1 {
+		add("metrics_interval", "至少 1 秒")
+	}
+	if c.BackupDir != "" && !filepath.IsAbs(c.BackupDir) {
+		add("backup_dir", "必须是绝对路径")
+	}
+
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "MySQL 配置无效", fields)
+	}
+	return nil
+}
+
+// validate 校验连接参数，字段路径相对于连接
+func (c *Connection) validate() []errcode.FieldViolation {
+	var fields []errcode.FieldViolation
+	if c.Port < 0 || c.Port > 65535 {
+		fields = append(fields, errcode.FieldViolation{Field: "port", Description: "无效的端口"})
+	}
+	if c.Socket != "" && !filepath.IsAbs(c.Socket) {
+		fields = append(fields, errcode.FieldViolation{Field: "socket", Description: "必须是绝对路径"})
+	}
+	if c.Database != "" && !identifierPattern.MatchString(c.Database) {
+		fields = append(fields, errcode.FieldViolation{Field: "database", Description: "无效的数据库名"})
+	}
+	return fields
+}
+
+// address 连接地址，用于检查 network 权限和展示
+func (c *Connection) address() string {
+	if c.Socket != "" {
+		return c.Socket
+	}
+	host, port := c.Host, c.Port
+	if host == "" {
+		host = defaultHost
+	}
+	if port == 0 {
+		port = defaultPort
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+// redacted 去掉密码的副本，用于对外返回
+func (c *Connection) redacted() *Connection {
+	out := *c
+	if out.Password != "" {
+		out.Password = redactedValue
+	}
+	return &out
+}
+
+// keepLast 保留的备份数
+func (j *BackupJob) keepLast() int {
+	if j.KeepLast > 0 {
+		return j.KeepLast
+	}
+	return defaultKeepLast
+}
+
+// queryTimeout 查询接口的超时
+func (c *Config) queryTimeout() time.Duration {
+	return time.Duration(c.QueryTimeout) * time.Second
+}
+
+// interval 状态采集间隔
+func (c *Config) interval() time.Duration {
+	return time.Duration(c.MetricsInterval) * time.Second
+}
+
+// redactedValue 对外返回时替换凭据的占位值，更新连接时传回该值表示保留原密码
+const redactedValue = "********"
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,178 @@
+package mysql
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// identifierPattern 数据库名，比 MySQL 的规则严格，避免需要转义的名称
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z0-9_$-]{1,64}$`)
+
+// 查询接口允许的语句，按首个关键字区分
+var (
+	// readKeywords 只读语句；只读模式下在 START TRANSACTION READ ONLY 中执行，由服务端拒绝写入
+	readKeywords = map[string]bool{
+		"SELECT": true, "SHOW": true, "DESCRIBE": true, "DESC": true, "EXPLAIN": true,
+		"WITH": true, "TABLE": true, "VALUES": true, "HELP": true,
+	}
+	// writeKeywords 修改数据、结构或权限的语句，只读模式下拒绝
+	writeKeywords = map[string]bool{
+		"INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true,
+		"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true,
+		"GRANT": true, "REVOKE": true, "SET": true, "CALL": true, "DO": true, "KILL": true,
+		"OPTIMIZE": true, "ANALYZE": true, "CHECK": true, "CHECKSUM": true, "REPAIR": true, "FLUSH": true,
+	}
+	// deniedKeywords 出现在语句任何位置都拒绝的关键字：
+	// OUTFILE/DUMPFILE 在数据库服务器上写文件，DELIMITER 是客户端命令，可能把一条语句拆成多条
+	deniedKeywords = map[string]bool{"OUTFILE": true, "DUMPFILE": true, "DELIMITER": true}
+	// modifyKeywords WITH 语句中出现这些关键字时不是只读语句（MySQL 8 允许 WITH ... UPDATE/DELETE）
+	modifyKeywords = map[string]bool{"INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true}
+)
+
+// statement 检查通过的语句
+type statement struct {
+	body     string // 去掉结尾分号的语句
+	keyword  string // 首个关键字（大写）
+	readOnly bool
+}
+
+// parseStatement 检查查询接口提交的语句：只能是一条语句，首个关键字在允许的范围内，
+// 不含客户端命令（反斜杠命令、DELIMITER），不在服务器上写文件。
+// 字符串、引号标识符和注释中的内容不参与检查，/*! */ 可执行注释中的内容按语句检查
+func parseStatement(sql string) (*statement, error) {
+	var words []string
+	end := -1 // 结尾分号的位置
+	execComment := false
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		if end >= 0 && !isSpace(c) && c != '#' && !strings.HasPrefix(sql[i:], "--") && !strings.HasPrefix(sql[i:], "/*") {
+			return nil, errcode.New(errcode.InvalidArgument, "一次只能执行一条语句")
+		}
+		switch {
+		case isSpace(c):
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			j, ok := skipQuoted(sql, i)
+			if !ok {
+				return nil, errcode.New(errcode.InvalidArgument, "引号未闭合")
+			}
+			i = j
+		case c == '#' || (strings.HasPrefix(sql[i:], "--") && (i+2 == len(sql) || isSpace(sql[i+2]))):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*!"):
+			if execComment {
+				return nil, errcode.New(errcode.InvalidArgument, "不支持嵌套的可执行注释")
+			}
+			execComment = true
+			i += 3
+			for i < len(sql) && sql[i] >= '0' && sql[i] <= '9' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			j := strings.Index(sql[i+2:], "*/")
+			if j < 0 {
+				return nil, errcode.New(errcode.InvalidArgument, "注释未闭合")
+			}
+			i += j + 4
+		case execComment && strings.HasPrefix(sql[i:], "*/"):
+			execComment = false
+			i += 2
+		case c == ';':
+			if execComment {
+				return nil, errcode.New(errcode.InvalidArgument, "一次只能执行一条语句")
+			}
+			end = i
+			i++
+		case c == '\\':
+			return nil, errcode.New(errcode.InvalidArgument, "不允许客户端命令")
+		case isWordStart(c):
+			j := i + 1
+			for j < len(sql) && isWordChar(sql[j]) {
+				j++
+			}
+			words = append(words, strings.ToUpper(sql[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+	if execComment {
+		return nil, errcode.New(errcode.InvalidArgument, "注释未闭合")
+	}
+	if len(words) == 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "语句不能为空")
+	}
+
+	s := &statement{body: sql, keyword: words[0]}
+	if end >= 0 {
+		s.body = sql[:end]
+	}
+	for _, w := range words {
+		if deniedKeywords[w] {
+			return nil, errcode.New(errcode.PermissionDenied, "不允许在查询接口中使用 %s", w)
+		}
+	}
+	switch {
+	case readKeywords[s.keyword]:
+		s.readOnly = true
+		if s.keyword == "WITH" {
+			for _, w := range words {
+				if modifyKeywords[w] {
+					s.readOnly = false
+				}
+			}
+		}
+	case writeKeywords[s.keyword]:
+	default:
+		return nil, errcode.New(errcode.PermissionDenied, "不允许在查询接口中执行 %s 语句", s.keyword)
+	}
+	return s, nil
+}
+
+// skipQuoted 跳过从 i 开始的字符串或引号标识符，返回结束引号之后的位置
+func skipQuoted(sql string, i int) (int, bool) {
+	quote := sql[i]
+	for j := i + 1; j < len(sql); j++ {
+		switch sql[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			// 连续两个引号表示引号本身
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1, true
+		}
+	}
+	return len(sql), false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isWordChar(c byte) bool {
+	return isWordStart(c) || c == '$' || (c >= '0' && c <= '9')
+}
+
+// quoteString 转义为单引号字符串
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdent 转义为反引号标识符
+func quoteIdent(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,298 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// connectionPrefix 存储中通过接口添加的连接的键前缀，键为 connections/<连接名>
+const connectionPrefix = "connections/"
+
+// 连接来源
+const (
+	SourceConfig = "config" // 插件配置中的连接，只能通过修改配置变更
+	SourceAPI    = "api"    // 通过接口添加的连接，保存在插件存储中
+)
+
+// Store 连接和备份任务状态的持久化存储，插件的 KV 存储实现该接口
+type Store interface {
+	StorageGet(ctx context.Context, key string) ([]byte, bool, error)
+	StorageSet(ctx context.Context, key string, value []byte) error
+	StorageDelete(ctx context.Context, key string) error
+	StorageList(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Env 访问文件、命令和网络的入口，由插件按清单声明的权限提供
+type Env struct {
+	DataDir      string // 插件数据目录，默认的备份目录在其中
+	Store        Store
+	LookPath     func(name string) (string, error) // 解析命令路径并检查执行权限
+	CheckRead    func(path string) error
+	CheckWrite   func(path string) error
+	CheckNetwork func(hostport string) error
+	// OnStatus 每次采集连接状态后调用，失败时 cur 为 nil；prev 为上一次成功采集的状态
+	OnStatus func(connection string, prev, cur *Status, err error)
+	// OnBackup 每次备份结束后调用，err 为 nil 表示成功
+	OnBackup func(job string, b *BackupFile, err error)
+}
+
+// Manager 管理 MySQL 连接、查询、状态采集和备份
+type Manager struct {
+	env Env
+
+	cfgMu  sync.RWMutex
+	config *Config
+	connMu sync.Mutex // 串行化通过接口增删改连接
+
+	statusMu  sync.RWMutex
+	status    map[string]*Status
+	statusErr map[string]error
+
+	backupMu sync.Mutex
+	running  map[string]bool // 正在运行的备份任务
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建管理器并开始采集连接状态
+func NewManager(env Env, config *Config) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		env:       env,
+		config:    config,
+		status:    make(map[string]*Status),
+		statusErr: make(map[string]error),
+		running:   make(map[string]bool),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	m.wg.Add(1)
+	go m.collectStatus()
+	return m
+}
+
+// SetConfig 替换配置，下一次采集和操作起生效
+func (m *Manager) SetConfig(config *Config) {
+	m.cfgMu.Lock()
+	defer m.cfgMu.Unlock()
+	m.config = config
+}
+
+// Config 当前配置
+func (m *Manager) Config() *Config {
+	m.cfgMu.RLock()
+	defer m.cfgMu.RUnlock()
+	return m.config
+}
+
+// Close 停止采集，取消进行中的备份并等待退出
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// ConnectionInfo 连接及最近一次采集的状态，密码已隐藏
+type ConnectionInfo struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Address string `json:"address"`
+	*Connection
+	Status *Status `json:"status,omitempty"`
+	Error  string  `json:"error,omitempty"` // 最近一次采集失败的原因
+}
+
+// ServerInfo 连接测试返回的服务器信息
+type ServerInfo struct {
+	Version  string `json:"version"`
+	Comment  string `json:"comment,omitempty"` // 如 MySQL Community Server - GPL、mariadb.org binary distribution
+	Hostname string `json:"hostname,omitempty"`
+	ReadOnly bool   `json:"read_only"` // 服务器的 read_only 变量
+}
+
+// Connections 全部连接，按名称排序
+func (m *Manager) Connections(ctx context.Context) ([]*ConnectionInfo, error) {
+	conns, sources, err := m.connections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	infos := make([]*ConnectionInfo, 0, len(conns))
+	for _, name := range sortedKeys(conns) {
+		c := conns[name]
+		info := &ConnectionInfo{Name: name, Source: sources[name], Address: c.address(), Connection: c.redacted(), Status: m.status[name]}
+		if err := m.statusErr[name]; err != nil {
+			info.Error = err.Error()
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// AddConnection 测试连接成功后保存
+func (m *Manager) AddConnection(ctx context.Context, name string, c *Connection) (*ServerInfo, error) {
+	if err := checkConnection(name, c); err != nil {
+		return nil, err
+	}
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	if _, _, err := m.connection(ctx, name); err == nil {
+		return nil, errcode.New(errcode.AlreadyExists, "连接 %s 已存在", name)
+	} else if errcode.Of(err) != errcode.NotFound {
+		return nil, err
+	}
+	info, err := m.test(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return info, m.saveConnection(ctx, name, c)
+}
+
+// UpdateConnection 修改通过接口添加的连接，密码为隐藏占位值时保留原密码
+func (m *Manager) UpdateConnection(ctx context.Context, name string, c *Connection) (*ServerInfo, error) {
+	if err := checkConnection(name, c); err != nil {
+		return nil, err
+	}
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	old, source, err := m.connection(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if source != SourceAPI {
+		return nil, errcode.New(errcode.InvalidArgument, "连接 %s 在插件配置中定义，请修改插件配置", name)
+	}
+	if c.Password == redactedValue {
+		c.Password = old.Password
+	}
+	info, err := m.test(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return info, m.saveConnection(ctx, name, c)
+}
+
+// DeleteConnection 删除通过接口添加的连接
+func (m *Manager) DeleteConnection(ctx context.Context, name string) error {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	_, source, err := m.connection(ctx, name)
+	if err != nil {
+		return err
+	}
+	if source != SourceAPI {
+		return errcode.New(errcode.InvalidArgument, "连接 %s 在插件配置中定义，请修改插件配置", name)
+	}
+	if err := m.env.Store.StorageDelete(ctx, connectionPrefix+name); err != nil {
+		return err
+	}
+	m.statusMu.Lock()
+	delete(m.status, name)
+	delete(m.statusErr, name)
+	m.statusMu.Unlock()
+	return nil
+}
+
+// TestConnection 连接数据库并返回服务器信息
+func (m *Manager) TestConnection(ctx context.Context, name string) (*ServerInfo, error) {
+	c, _, err := m.connection(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return m.test(ctx, c)
+}
+
+func (m *Manager) test(ctx context.Context, c *Connection) (*ServerInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*connectTimeout*time.Second)
+	defer cancel()
+	results, _, err := m.query(ctx, c, "", "SELECT VERSION() AS version, @@version_comment AS comment, @@hostname AS hostname, @@read_only AS read_only;\n", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, errcode.New(errcode.Internal, "mysql 未返回结果")
+	}
+	rs := results[0]
+	return &ServerInfo{
+		Version:  rs.value("version"),
+		Comment:  rs.value("comment"),
+		Hostname: rs.value("hostname"),
+		ReadOnly: rs.value("read_only") == "1",
+	}, nil
+}
+
+// connection 按名称查找连接，配置中的连接优先
+func (m *Manager) connection(ctx context.Context, name string) (*Connection, string, error) {
+	if c, ok := m.Config().Connections[name]; ok && c != nil {
+		return c, SourceConfig, nil
+	}
+	data, found, err := m.env.Store.StorageGet(ctx, connectionPrefix+name)
+	if err != nil {
+		return nil, "", err
+	}
+	if !found {
+		return nil, "", errcode.New(errcode.NotFound, "连接 %s 不存在", name)
+	}
+	var c Connection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, "", err
+	}
+	return &c, SourceAPI, nil
+}
+
+// connections 配置中和通过接口添加的全部连接及其来源
+func (m *Manager) connections(ctx context.Context) (map[string]*Connection, map[string]string, error) {
+	conns := make(map[string]*Connection)
+	sources := make(map[string]string)
+	keys, err := m.env.Store.StorageList(ctx, connectionPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, connectionPrefix)
+		c, source, err := m.connection(ctx, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		conns[name], sources[name] = c, source
+	}
+	for name, c := range m.Config().Connections {
+		if c != nil {
+			conns[name], sources[name] = c, SourceConfig
+		}
+	}
+	return conns, sources, nil
+}
+
+func (m *Manager) saveConnection(ctx context.Context, name string, c *Connection) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return m.env.Store.StorageSet(ctx, connectionPrefix+name, data)
+}
+
+// checkConnection 校验接口提交的连接
+func checkConnection(name string, c *Connection) error {
+	var fields []errcode.FieldViolation
+	if !namePattern.MatchString(name) {
+		fields = append(fields, errcode.FieldViolation{Field: "name", Description: "无效的连接名"})
+	}
+	fields = append(fields, c.validate()...)
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "连接参数无效", fields)
+	}
+	return nil
+}
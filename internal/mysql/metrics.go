@@ -0,0 +1,190 @@
+package mysql
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// sampleTimeout 一次状态采集的超时
+const sampleTimeout = 10 * time.Second
+
+// statusQuery 采集状态的脚本：全局状态计数器和连接相关的变量
+const statusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN
+  ('Threads_connected', 'Threads_running', 'Max_used_connections', 'Slow_queries',
+   'Questions', 'Connections', 'Aborted_connects', 'Uptime');
+SELECT @@max_connections AS max_connections, @@long_query_time AS long_query_time, @@slow_query_log AS slow_query_log;
+`
+
+// Status 连接的一次状态采样，计数器为服务启动以来的累计值
+type Status struct {
+	ThreadsConnected   int64     `json:"threads_connected"`
+	ThreadsRunning     int64     `json:"threads_running"`
+	MaxConnections     int64     `json:"max_connections"`
+	MaxUsedConnections int64     `json:"max_used_connections"`
+	SlowQueries        int64     `json:"slow_queries"`
+	Questions          int64     `json:"questions"`
+	Connections        int64     `json:"connections"`
+	AbortedConnects    int64     `json:"aborted_connects"`
+	UptimeSeconds      int64     `json:"uptime_seconds"`
+	QueriesPerSecond   float64   `json:"queries_per_second"` // 与上一次采样之间的平均值
+	LongQueryTime      float64   `json:"long_query_time"`    // 秒，超过该时间的查询计入慢查询
+	SlowQueryLog       bool      `json:"slow_query_log"`
+	Time               time.Time `json:"time"`
+}
+
+// SlowQuery 按语句摘要汇总的慢查询
+type SlowQuery struct {
+	Schema       string  `json:"schema,omitempty"`
+	Digest       string  `json:"digest"` // 参数替换为 ? 的语句
+	Count        int64   `json:"count"`
+	TotalSeconds float64 `json:"total_seconds"`
+	AvgSeconds   float64 `json:"avg_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+	RowsExamined int64   `json:"rows_examined"`
+	RowsSent     int64   `json:"rows_sent"`
+	LastSeen     string  `json:"last_seen"`
+}
+
+// Status 连接最近一次采集的状态，采集失败时返回错误
+func (m *Manager) Status(ctx context.Context, conn string) (*Status, error) {
+	if _, _, err := m.connection(ctx, conn); err != nil {
+		return nil, err
+	}
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	if err := m.statusErr[conn]; err != nil {
+		return nil, err
+	}
+	if s := m.status[conn]; s != nil {
+		return s, nil
+	}
+	return nil, errcode.New(errcode.Unavailable, "尚未采集到连接 %s 的状态", conn)
+}
+
+// SlowQueries 平均耗时超过 long_query_time 的语句，按平均耗时降序，
+// 来自 performance_schema 的语句摘要，需要开启 performance_schema
+func (m *Manager) SlowQueries(ctx context.Context, conn string, limit int) ([]*SlowQuery, error) {
+	rs, err := m.adminQuery(ctx, conn, `SELECT SCHEMA_NAME AS schema_name, DIGEST_TEXT AS digest_text, COUNT_STAR AS exec_count,
+  SUM_TIMER_WAIT / 1e12 AS total_seconds, AVG_TIMER_WAIT / 1e12 AS avg_seconds, MAX_TIMER_WAIT / 1e12 AS max_seconds,
+  SUM_ROWS_EXAMINED AS rows_examined, SUM_ROWS_SENT AS rows_sent, LAST_SEEN AS last_seen
+FROM performance_schema.events_statements_summary_by_digest
+WHERE DIGEST_TEXT IS NOT NULL AND AVG_TIMER_WAIT >= @@long_query_time * 1e12
+ORDER BY AVG_TIMER_WAIT DESC
+LIMIT `+strconv.Itoa(limit)+";\n")
+	if err != nil {
+		return nil, err
+	}
+	queries := []*SlowQuery{}
+	for _, row := range rs.maps() {
+		q := &SlowQuery{Schema: row["schema_name"], Digest: row["digest_text"], LastSeen: row["last_seen"]}
+		q.Count, _ = strconv.ParseInt(row["exec_count"], 10, 64)
+		q.TotalSeconds, _ = strconv.ParseFloat(row["total_seconds"], 64)
+		q.AvgSeconds, _ = strconv.ParseFloat(row["avg_seconds"], 64)
+		q.MaxSeconds, _ = strconv.ParseFloat(row["max_seconds"], 64)
+		q.RowsExamined, _ = strconv.ParseInt(row["rows_examined"], 10, 64)
+		q.RowsSent, _ = strconv.ParseInt(row["rows_sent"], 10, 64)
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// collectStatus 按配置的间隔依次采集每个连接的状态，直到管理器关闭
+func (m *Manager) collectStatus() {
+	defer m.wg.Done()
+	for {
+		conns, _, err := m.connections(m.ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("读取 MySQL 连接失败")
+		}
+		for _, name := range sortedKeys(conns) {
+			if m.ctx.Err() != nil {
+				return
+			}
+			m.sampleStatus(name, conns[name])
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.Config().interval()):
+		}
+	}
+}
+
+// sampleStatus 采集一个连接的状态并通知 OnStatus
+func (m *Manager) sampleStatus(name string, c *Connection) {
+	ctx, cancel := context.WithTimeout(m.ctx, sampleTimeout)
+	cur, err := m.fetchStatus(ctx, c)
+	cancel()
+	if err != nil && m.ctx.Err() != nil {
+		// 插件停止时取消的采集不算失败
+		return
+	}
+
+	m.statusMu.Lock()
+	prev := m.status[name]
+	if err != nil {
+		m.statusErr[name] = err
+	} else {
+		if prev != nil && cur.UptimeSeconds >= prev.UptimeSeconds {
+			if elapsed := cur.Time.Sub(prev.Time).Seconds(); elapsed > 0 && cur.Questions >= prev.Questions {
+				cur.QueriesPerSecond = float64(cur.Questions-prev.Questions) / elapsed
+			}
+		}
+		m.status[name] = cur
+		delete(m.statusErr, name)
+	}
+	m.statusMu.Unlock()
+
+	if err != nil {
+		log.Debug().Err(err).Str("connection", name).Msg("采集 MySQL 状态失败")
+	}
+	if m.env.OnStatus != nil {
+		m.env.OnStatus(name, prev, cur, err)
+	}
+}
+
+// fetchStatus 查询并解析一次状态
+func (m *Manager) fetchStatus(ctx context.Context, c *Connection) (*Status, error) {
+	results, _, err := m.query(ctx, c, "", statusQuery, 100)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 2 {
+		return nil, errcode.New(errcode.Internal, "mysql 返回了 %d 个结果，应为 2 个", len(results))
+	}
+	s := parseGlobalStatus(results[0])
+	vars := results[1]
+	s.MaxConnections, _ = strconv.ParseInt(vars.value("max_connections"), 10, 64)
+	s.LongQueryTime, _ = strconv.ParseFloat(vars.value("long_query_time"), 64)
+	s.SlowQueryLog = vars.value("slow_query_log") == "1"
+	s.Time = time.Now().UTC()
+	return s, nil
+}
+
+// parseGlobalStatus 解析 SHOW GLOBAL STATUS 的 Variable_name/Value 两列
+func parseGlobalStatus(rs *ResultSet) *Status {
+	var s Status
+	fields := map[string]*int64{
+		"Threads_connected":    &s.ThreadsConnected,
+		"Threads_running":      &s.ThreadsRunning,
+		"Max_used_connections": &s.MaxUsedConnections,
+		"Slow_queries":         &s.SlowQueries,
+		"Questions":            &s.Questions,
+		"Connections":          &s.Connections,
+		"Aborted_connects":     &s.AbortedConnects,
+		"Uptime":               &s.UptimeSeconds,
+	}
+	for _, row := range rs.Rows {
+		if len(row) < 2 || row[0] == nil || row[1] == nil {
+			continue
+		}
+		if p, ok := fields[*row[0]]; ok {
+			*p, _ = strconv.ParseInt(*row[1], 10, 64)
+		}
+	}
+	return &s
+}
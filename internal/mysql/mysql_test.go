@@ -0,0 +1,362 @@
+package mysql
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// memStore 内存中的 Store
+type memStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memStore) StorageGet(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok, nil
+}
+
+func (s *memStore) StorageSet(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *memStore) StorageDelete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memStore) StorageList(_ context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+const statusXML = `<?xml version="1.0"?>
+
+<resultset statement="SHOW GLOBAL STATUS" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <row>
+	<field name="Variable_name">Threads_connected</field>
+	<field name="Value">5</field>
+  </row>
+  <row>
+	<field name="Variable_name">Questions</field>
+	<field name="Value">1000</field>
+  </row>
+  <row>
+	<field name="Variable_name">Slow_queries</field>
+	<field name="Value">3</field>
+  </row>
+  <row>
+	<field name="Variable_name">Uptime</field>
+	<field name="Value">60</field>
+  </row>
+</resultset>
+
+<resultset statement="SELECT @@max_connections" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <row>
+	<field name="max_connections">151</field>
+	<field name="long_query_time">10.000000</field>
+	<field name="slow_query_log">1</field>
+  </row>
+</resultset>
+`
+
+// testManager 使用假的 mysql 和 mysqldump 命令：mysql 对状态查询返回 status.xml，
+// 其他输入写入 stdin 文件后返回 out.xml，目录中存在 fail 文件时返回拒绝访问
+func testManager(t *testing.T, config *Config) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	mysql := "#!/bin/sh\n" +
+		"input=$(cat)\n" +
+		"case \"$input\" in *'SHOW GLOBAL STATUS'*) cat " + filepath.Join(dir, "status.xml") + "; exit 0;; esac\n" +
+		"printf '%s' \"$input\" > " + filepath.Join(dir, "stdin") + "\n" +
+		"echo \"$MYSQL_PWD|$*\" > " + filepath.Join(dir, "args") + "\n" +
+		"if [ -e " + filepath.Join(dir, "fail") + " ]; then echo 'ERROR 1045 (28000): Access denied for user' >&2; exit 1; fi\n" +
+		"cat " + filepath.Join(dir, "out.xml") + "\n"
+	writeTestFile(t, filepath.Join(dir, "mysql"), mysql)
+	writeTestFile(t, filepath.Join(dir, "mysqldump"), "#!/bin/sh\necho \"-- mysqldump $*\"\necho 'CREATE TABLE t (id int);'\n")
+	writeTestFile(t, filepath.Join(dir, "status.xml"), statusXML)
+	os.Chmod(filepath.Join(dir, "mysql"), 0755)
+	os.Chmod(filepath.Join(dir, "mysqldump"), 0755)
+
+	if config.Connections == nil {
+		config.Connections = map[string]*Connection{"main": {User: "root", Password: "secret"}}
+	}
+	if config.MaxRows == 0 {
+		config.MaxRows = defaultMaxRows
+	}
+	config.QueryTimeout, config.MetricsInterval = 10, 3600
+	allow := func(string) error { return nil }
+	m := NewManager(Env{
+		DataDir:      dir,
+		Store:        &memStore{data: make(map[string][]byte)},
+		LookPath:     func(name string) (string, error) { return filepath.Join(dir, name), nil },
+		CheckRead:    allow,
+		CheckWrite:   allow,
+		CheckNetwork: allow,
+	}, config)
+	t.Cleanup(m.Close)
+	return m, dir
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		sql      string
+		keyword  string // 为空表示应拒绝
+		readOnly bool
+	}{
+		{"SELECT 1", "SELECT", true},
+		{"  select ';' AS a; -- 注释", "SELECT", true},
+		{"SELECT `a;b` FROM t /* ; */", "SELECT", true},
+		{"SHOW DATABASES;", "SHOW", true},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", "WITH", true},
+		{"WITH x AS (SELECT 1) DELETE FROM t", "WITH", false},
+		{"INSERT INTO t VALUES ('a\\'; DROP TABLE t')", "INSERT", false},
+		{"SELECT 1; SELECT 2", "", false},
+		{"SELECT 1; DROP TABLE t", "", false},
+		{"SELECT * FROM t INTO OUTFILE '/tmp/x'", "", false},
+		{"LOAD DATA INFILE '/etc/passwd' INTO TABLE t", "", false},
+		{"\\! ls", "", false},
+		{"SELECT 1 /*!; DROP TABLE t */", "", false},
+		{"SELECT 'abc", "", false},
+		{"-- 只有注释", "", false},
+	}
+	for _, tt := range tests {
+		s, err := parseStatement(tt.sql)
+		if tt.keyword == "" {
+			if err == nil {
+				t.Errorf("parseStatement(%q) 应被拒绝", tt.sql)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseStatement(%q) = %v", tt.sql, err)
+			continue
+		}
+		if s.keyword != tt.keyword || s.readOnly != tt.readOnly || strings.HasSuffix(strings.TrimSpace(s.body), ";") {
+			t.Errorf("parseStatement(%q) = %+v", tt.sql, s)
+		}
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	out := `<?xml version="1.0"?>
+
+<resultset statement="SELECT id, name FROM t" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <row>
+	<field name="id">1</field>
+	<field name="name">a &amp; b</field>
+  </row>
+  <row>
+	<field name="id">2</field>
+	<field name="name" xsi:nil="true" />
+  </row>
+  <row>
+	<field name="id">3</field>
+	<field name="name"></field>
+  </row>
+</resultset>
+`
+	results, truncated, err := parseResults(strings.NewReader(out), 10)
+	if err != nil || truncated || len(results) != 1 {
+		t.Fatalf("parseResults = %v, %v, %v", results, truncated, err)
+	}
+	rs := results[0]
+	if strings.Join(rs.Columns, ",") != "id,name" || len(rs.Rows) != 3 || rs.statement != "SELECT id, name FROM t" {
+		t.Fatalf("结果 = %+v", rs)
+	}
+	if *rs.Rows[0][1] != "a & b" || rs.Rows[1][1] != nil || rs.Rows[2][1] == nil || *rs.Rows[2][1] != "" {
+		t.Errorf("NULL 和空字符串应区分: %v %v %v", rs.Rows[0][1], rs.Rows[1][1], rs.Rows[2][1])
+	}
+
+	results, truncated, err = parseResults(strings.NewReader(out), 2)
+	if err != nil || !truncated || len(results[0].Rows) != 2 {
+		t.Errorf("超过行数上限时应截断: %v, %v", truncated, err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	m, dir := testManager(t, &Config{})
+	ctx := context.Background()
+	rows := `<?xml version="1.0"?>
+<resultset statement="SELECT id FROM t" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <row><field name="id">1</field></row>
+  <row><field name="id">2</field></row>
+  <row><field name="id">3</field></row>
+</resultset>
+`
+	writeTestFile(t, filepath.Join(dir, "out.xml"), rows)
+
+	result, err := m.Query(ctx, "main", QueryRequest{SQL: "SELECT id FROM t -- 注释", Database: "app", ReadOnly: true, MaxRows: 2})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !result.ReadOnly || !result.Truncated || len(result.Results) != 1 || len(result.Results[0].Rows) != 2 {
+		t.Errorf("只读查询结果 = %+v", result)
+	}
+	stdin, _ := os.ReadFile(filepath.Join(dir, "stdin"))
+	// 假命令用 $(cat) 读取输入，去掉了结尾的换行
+	if string(stdin) != "START TRANSACTION READ ONLY;\nSELECT id FROM t -- 注释\n;\nROLLBACK;" {
+		t.Errorf("只读脚本 = %q", stdin)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if !strings.HasPrefix(string(args), "secret|") || strings.Count(string(args), "secret") != 1 || !strings.Contains(string(args), "--database=app") {
+		t.Errorf("密码应通过环境变量传入: %q", args)
+	}
+
+	if _, err := m.Query(ctx, "main", QueryRequest{SQL: "DELETE FROM t", ReadOnly: true}); errcode.Of(err) != errcode.PermissionDenied {
+		t.Errorf("只读模式下的写语句 = %v, want PERMISSION_DENIED", err)
+	}
+	m.SetConfig(&Config{Connections: m.Config().Connections, ReadOnly: true, MaxRows: 10, QueryTimeout: 10, MetricsInterval: 3600})
+	if _, err := m.Query(ctx, "main", QueryRequest{SQL: "DROP TABLE t"}); errcode.Of(err) != errcode.PermissionDenied {
+		t.Errorf("全局只读时的写语句 = %v, want PERMISSION_DENIED", err)
+	}
+	m.SetConfig(&Config{Connections: m.Config().Connections, MaxRows: 10, QueryTimeout: 10, MetricsInterval: 3600})
+
+	writeTestFile(t, filepath.Join(dir, "out.xml"), `<?xml version="1.0"?>
+<resultset statement="SELECT ROW_COUNT() AS `+"`affected_rows`"+`" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <row><field name="affected_rows">3</field></row>
+</resultset>
+`)
+	result, err = m.Query(ctx, "main", QueryRequest{SQL: "DELETE FROM t WHERE id < 4;"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if result.ReadOnly || result.AffectedRows == nil || *result.AffectedRows != 3 || len(result.Results) != 0 {
+		t.Errorf("写语句结果 = %+v", result)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "fail"), "")
+	if _, err := m.Query(ctx, "main", QueryRequest{SQL: "SELECT 1"}); errcode.Of(err) != errcode.PermissionDenied || !strings.Contains(err.Error(), "1045") {
+		t.Errorf("拒绝访问 = %v, want PERMISSION_DENIED", err)
+	}
+	if _, err := m.Query(ctx, "missing", QueryRequest{SQL: "SELECT 1"}); errcode.Of(err) != errcode.NotFound {
+		t.Errorf("不存在的连接 = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	m, dir := testManager(t, &Config{})
+	ctx := context.Background()
+	// 等待启动时的采集完成，之后的采样与其计算每秒查询数
+	for i := 0; ; i++ {
+		if _, err := m.Status(ctx, "main"); err == nil {
+			break
+		} else if i == 100 {
+			t.Fatalf("启动后未采集状态: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	writeTestFile(t, filepath.Join(dir, "status.xml"), strings.Replace(statusXML, "1000", "1100", 1))
+	m.sampleStatus("main", m.Config().Connections["main"])
+
+	s, err := m.Status(ctx, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ThreadsConnected != 5 || s.Questions != 1100 || s.SlowQueries != 3 || s.MaxConnections != 151 ||
+		!s.SlowQueryLog || s.LongQueryTime != 10 || s.QueriesPerSecond <= 0 {
+		t.Errorf("Status = %+v", s)
+	}
+}
+
+func TestBackupRetention(t *testing.T) {
+	m, dir := testManager(t, &Config{
+		Backups: map[string]*BackupJob{"daily": {Connection: "main", Databases: []string{"app"}, KeepLast: 2}},
+	})
+	ctx := context.Background()
+	backupDir := filepath.Join(dir, "backups", "daily")
+	writeTestFile(t, filepath.Join(backupDir, "daily-20200101-000000.sql.gz"), "old")
+	writeTestFile(t, filepath.Join(backupDir, "daily-20200102-000000.sql.gz"), "old")
+
+	b, err := m.RunBackup(ctx, "daily")
+	if err != nil {
+		t.Fatalf("RunBackup: %v", err)
+	}
+	f, err := os.Open(b.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, _ := io.ReadAll(gz)
+	if !strings.Contains(string(dump), "--single-transaction") || !strings.Contains(string(dump), "--databases app") {
+		t.Errorf("mysqldump 输出 = %q", dump)
+	}
+
+	backups, err := m.Backups(ctx, "daily")
+	if err != nil || len(backups) != 2 || backups[0].Name != b.Name || backups[1].Name != "daily-20200102-000000.sql.gz" {
+		t.Errorf("保留最近 2 个备份: %+v, %v", backups, err)
+	}
+	jobs := m.BackupJobs(ctx)
+	if len(jobs) != 1 || jobs[0].LastBackup == nil || jobs[0].LastBackup.Name != b.Name || jobs[0].LastError != "" {
+		t.Errorf("BackupJobs = %+v", jobs)
+	}
+
+	if err := m.DeleteBackup(ctx, "daily", "../../mysql"); errcode.Of(err) != errcode.InvalidArgument {
+		t.Errorf("DeleteBackup 路径穿越 = %v, want INVALID_ARGUMENT", err)
+	}
+	if err := m.DeleteBackup(ctx, "daily", b.Name); err != nil {
+		t.Errorf("DeleteBackup: %v", err)
+	}
+	if _, err := m.RunBackup(ctx, "weekly"); errcode.Of(err) != errcode.NotFound {
+		t.Errorf("不存在的任务 = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(map[string]any{"connections": map[string]any{"main": map[string]any{"user": "root"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxRows != defaultMaxRows || config.Connections["main"].address() != "127.0.0.1:3306" {
+		t.Errorf("默认值 = %+v", config)
+	}
+
+	_, err = ParseConfig(map[string]any{
+		"connections": map[string]any{"main": map[string]any{"port": 70000, "socket": "mysql.sock"}},
+		"backups":     map[string]any{"daily": map[string]any{"connection": "main", "databases": []string{"app`; --"}}},
+		"backup_dir":  "backups",
+	})
+	fields := errcode.FieldsOf(err)
+	if errcode.Of(err) != errcode.ValidationFailed || len(fields) != 4 {
+		t.Errorf("ParseConfig = %v, fields = %+v", err, fields)
+	}
+}
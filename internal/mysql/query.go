@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// rowCountQuery 写语句之后追加的查询，结果中按语句文本识别
+const rowCountQuery = "SELECT ROW_COUNT() AS `affected_rows`"
+
+// QueryRequest 查询接口的请求
+type QueryRequest struct {
+	SQL      string `json:"sql"`
+	Database string `json:"database,omitempty"`  // 默认为连接的 database
+	ReadOnly bool   `json:"read_only,omitempty"` // 只允许只读语句，配置或连接为只读时忽略
+	MaxRows  int    `json:"max_rows,omitempty"`  // 不超过配置的 max_rows
+}
+
+// QueryResult 查询接口的结果
+type QueryResult struct {
+	Statement    string       `json:"statement"` // 语句的首个关键字
+	ReadOnly     bool         `json:"read_only"` // 是否在只读事务中执行
+	Results      []*ResultSet `json:"results"`
+	AffectedRows *int64       `json:"affected_rows,omitempty"` // 写语句影响的行数
+	Truncated    bool         `json:"truncated"`               // 结果超过行数上限，已停止读取
+	DurationMs   int64        `json:"duration_ms"`
+}
+
+// Query 执行一条语句。只读模式（全局配置、连接或请求任一开启）下只允许只读语句，
+// 并在 START TRANSACTION READ ONLY 中执行后回滚，由服务端保证不产生写入
+func (m *Manager) Query(ctx context.Context, conn string, req QueryRequest) (*QueryResult, error) {
+	config := m.Config()
+	c, _, err := m.connection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseStatement(req.SQL)
+	if err != nil {
+		return nil, err
+	}
+	database := req.Database
+	if database == "" {
+		database = c.Database
+	}
+	if database != "" && !identifierPattern.MatchString(database) {
+		return nil, errcode.New(errcode.InvalidArgument, "无效的数据库名 %q", database)
+	}
+	maxRows := config.MaxRows
+	if req.MaxRows > 0 && req.MaxRows < maxRows {
+		maxRows = req.MaxRows
+	}
+
+	readOnly := config.ReadOnly || c.ReadOnly || req.ReadOnly
+	if readOnly && !s.readOnly {
+		return nil, errcode.New(errcode.PermissionDenied, "只读模式下不允许执行 %s 语句", s.keyword)
+	}
+	// 语句和追加的部分之间用换行分隔，避免语句末尾的单行注释吞掉后面的内容
+	var script string
+	switch {
+	case readOnly:
+		script = "START TRANSACTION READ ONLY;\n" + s.body + "\n;\nROLLBACK;\n"
+	case s.readOnly:
+		script = s.body + "\n;\n"
+	default:
+		script = s.body + "\n;\n" + rowCountQuery + ";\n"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.queryTimeout())
+	defer cancel()
+	start := time.Now()
+	// 多读一行，结果中可能有追加的影响行数
+	results, truncated, err := m.query(ctx, c, database, script, maxRows+1)
+	elapsed := time.Since(start)
+	log.Info().Str("connection", conn).Str("database", database).Str("statement", s.keyword).
+		Bool("read_only", readOnly).Dur("duration", elapsed).Err(err).Msg("执行 MySQL 查询")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Statement: s.keyword, ReadOnly: readOnly, DurationMs: elapsed.Milliseconds()}
+	if n := len(results); n > 0 && isRowCountQuery(results[n-1].statement) {
+		if v, err := strconv.ParseInt(results[n-1].value("affected_rows"), 10, 64); err == nil && v >= 0 {
+			result.AffectedRows = &v
+		}
+		results = results[:n-1]
+	}
+	result.Results, result.Truncated = limitRows(results, maxRows)
+	result.Truncated = result.Truncated || truncated
+	return result, nil
+}
+
+// limitRows 只保留前 maxRows 行（所有结果合计）
+func limitRows(results []*ResultSet, maxRows int) ([]*ResultSet, bool) {
+	truncated := false
+	for _, rs := range results {
+		if len(rs.Rows) > maxRows {
+			rs.Rows, truncated = rs.Rows[:maxRows], true
+		}
+		maxRows -= len(rs.Rows)
+	}
+	return results, truncated
+}
+
+// isRowCountQuery 判断结果是否来自追加的影响行数查询
+func isRowCountQuery(statement string) bool {
+	return strings.TrimSpace(statement) == rowCountQuery
+}
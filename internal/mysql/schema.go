@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// schemaTimeout 列出数据库、用户等管理查询的超时
+const schemaTimeout = 30 * time.Second
+
+// maxSchemaRows 管理查询读取的最大行数
+const maxSchemaRows = 10000
+
+// systemDatabases MySQL 和 MariaDB 自带的数据库
+var systemDatabases = map[string]bool{
+	"mysql": true, "information_schema": true, "performance_schema": true, "sys": true,
+}
+
+// Database 数据库及其大小
+type Database struct {
+	Name      string `json:"name"`
+	Charset   string `json:"charset"`
+	Collation string `json:"collation"`
+	Tables    int    `json:"tables"`
+	SizeBytes int64  `json:"size_bytes"` // 数据和索引大小，来自 information_schema 的统计值
+	System    bool   `json:"system"`
+}
+
+// User 数据库账号
+type User struct {
+	User   string `json:"user"`
+	Host   string `json:"host"`
+	Plugin string `json:"plugin,omitempty"` // 认证插件
+	Locked bool   `json:"locked"`
+}
+
+// Databases 列出连接上的数据库
+func (m *Manager) Databases(ctx context.Context, conn string) ([]*Database, error) {
+	rs, err := m.adminQuery(ctx, conn, `SELECT s.SCHEMA_NAME AS schema_name, s.DEFAULT_CHARACTER_SET_NAME AS charset_name,
+  s.DEFAULT_COLLATION_NAME AS collation_name, COUNT(t.TABLE_NAME) AS table_count,
+  COALESCE(SUM(t.DATA_LENGTH + t.INDEX_LENGTH), 0) AS size_bytes
+FROM information_schema.SCHEMATA s
+LEFT JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = s.SCHEMA_NAME
+GROUP BY s.SCHEMA_NAME, s.DEFAULT_CHARACTER_SET_NAME, s.DEFAULT_COLLATION_NAME
+ORDER BY s.SCHEMA_NAME;
+`)
+	if err != nil {
+		return nil, err
+	}
+	dbs := []*Database{}
+	for _, row := range rs.maps() {
+		tables, _ := strconv.Atoi(row["table_count"])
+		size, _ := strconv.ParseInt(row["size_bytes"], 10, 64)
+		dbs = append(dbs, &Database{
+			Name:      row["schema_name"],
+			Charset:   row["charset_name"],
+			Collation: row["collation_name"],
+			Tables:    tables,
+			SizeBytes: size,
+			System:    systemDatabases[row["schema_name"]],
+		})
+	}
+	return dbs, nil
+}
+
+// Users 列出数据库账号，需要 mysql.user 的读取权限
+func (m *Manager) Users(ctx context.Context, conn string) ([]*User, error) {
+	// account_locked 列只在 MySQL 5.7+ 中存在，单独查询
+	rs, err := m.adminQuery(ctx, conn, "SELECT User AS user, Host AS host, plugin FROM mysql.user ORDER BY User, Host;\n")
+	if err != nil {
+		return nil, err
+	}
+	users := []*User{}
+	for _, row := range rs.maps() {
+		users = append(users, &User{User: row["user"], Host: row["host"], Plugin: row["plugin"]})
+	}
+	if locked, err := m.lockedAccounts(ctx, conn); err == nil {
+		for _, u := range users {
+			u.Locked = locked[u.User+"@"+u.Host]
+		}
+	}
+	return users, nil
+}
+
+// lockedAccounts 已锁定的账号，MySQL 5.7+ 有 account_locked 列，其他版本返回错误
+func (m *Manager) lockedAccounts(ctx context.Context, conn string) (map[string]bool, error) {
+	rs, err := m.adminQuery(ctx, conn, "SELECT User AS user, Host AS host FROM mysql.user WHERE account_locked = 'Y';\n")
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[string]bool)
+	for _, row := range rs.maps() {
+		locked[row["user"]+"@"+row["host"]] = true
+	}
+	return locked, nil
+}
+
+// Grants 账号的授权语句
+func (m *Manager) Grants(ctx context.Context, conn, user, host string) ([]string, error) {
+	rs, err := m.adminQuery(ctx, conn, "SHOW GRANTS FOR "+quoteString(user)+"@"+quoteString(host)+";\n")
+	if err != nil {
+		return nil, err
+	}
+	grants := []string{}
+	for _, row := range rs.Rows {
+		if len(row) > 0 && row[0] != nil {
+			grants = append(grants, *row[0])
+		}
+	}
+	return grants, nil
+}
+
+// adminQuery 执行插件自身的只读管理查询，返回第一个结果
+func (m *Manager) adminQuery(ctx context.Context, conn, sql string) (*ResultSet, error) {
+	c, _, err := m.connection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, schemaTimeout)
+	defer cancel()
+	results, truncated, err := m.query(ctx, c, "", sql, maxSchemaRows)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		return nil, errcode.New(errcode.Internal, "结果超过 %d 行", maxSchemaRows)
+	}
+	if len(results) == 0 {
+		return &ResultSet{}, nil
+	}
+	return results[0], nil
+}
@@ -0,0 +1,116 @@
+package nginx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/runixo/agent/internal/plugin/httpapi"
+)
+
+// siteRequest 创建站点的请求体，站点参数与 Spec 相同
+type siteRequest struct {
+	Name string `json:"name"`
+	Spec
+}
+
+// Handler Nginx 插件的 HTTP 接口，挂载在 prefix 下，响应格式与 Agent REST API 一致。
+// 修改配置的接口在 nginx -t 通过后默认重载 Nginx，?reload=false 时只写入配置：
+//
+//	GET    /servers               配置中的 server 块
+//	GET    /templates             站点模板
+//	GET    /sites                 托管的站点
+//	POST   /sites                 按模板创建站点（请求体为 name 和 Spec）
+//	GET    /sites/{name}          站点及配置文件内容
+//	PUT    /sites/{name}          按新参数重新生成站点（请求体为 Spec）
+//	DELETE /sites/{name}          删除站点
+//	POST   /sites/{name}/enable   启用站点
+//	POST   /sites/{name}/disable  停用站点
+//	PUT    /sites/{name}/ssl      设置证书（请求体为 SSL，null 表示移除）
+//	GET    /certificates          配置中引用的证书及有效期
+//	POST   /test                  nginx -t
+//	POST   /reload                测试并重载
+//	GET    /status                最近一次采集的 stub_status
+func (m *Manager) Handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/servers", func(w http.ResponseWriter, r *http.Request) {
+		servers, err := m.Servers(r.Context())
+		httpapi.WriteResult(w, http.StatusOK, servers, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/templates", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, http.StatusOK, Templates)
+	})
+	mux.HandleFunc("GET "+prefix+"/sites", func(w http.ResponseWriter, r *http.Request) {
+		sites, err := m.Sites(r.Context())
+		httpapi.WriteResult(w, http.StatusOK, sites, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/sites", func(w http.ResponseWriter, r *http.Request) {
+		var req siteRequest
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		site, err := m.CreateSite(r.Context(), req.Name, req.Spec, reloadParam(r))
+		httpapi.WriteResult(w, http.StatusCreated, site, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/sites/{name}", func(w http.ResponseWriter, r *http.Request) {
+		site, err := m.GetSite(r.Context(), r.PathValue("name"))
+		httpapi.WriteResult(w, http.StatusOK, site, err)
+	})
+	mux.HandleFunc("PUT "+prefix+"/sites/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var spec Spec
+		if err := httpapi.DecodeJSON(r, &spec); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		site, err := m.UpdateSite(r.Context(), r.PathValue("name"), spec, reloadParam(r))
+		httpapi.WriteResult(w, http.StatusOK, site, err)
+	})
+	mux.HandleFunc("DELETE "+prefix+"/sites/{name}", func(w http.ResponseWriter, r *http.Request) {
+		err := m.DeleteSite(r.Context(), r.PathValue("name"), reloadParam(r))
+		httpapi.WriteResult(w, http.StatusOK, map[string]string{"message": "站点已删除"}, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/sites/{name}/enable", func(w http.ResponseWriter, r *http.Request) {
+		site, err := m.SetEnabled(r.Context(), r.PathValue("name"), true, reloadParam(r))
+		httpapi.WriteResult(w, http.StatusOK, site, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/sites/{name}/disable", func(w http.ResponseWriter, r *http.Request) {
+		site, err := m.SetEnabled(r.Context(), r.PathValue("name"), false, reloadParam(r))
+		httpapi.WriteResult(w, http.StatusOK, site, err)
+	})
+	mux.HandleFunc("PUT "+prefix+"/sites/{name}/ssl", func(w http.ResponseWriter, r *http.Request) {
+		var ssl *SSL
+		if err := httpapi.DecodeJSON(r, &ssl); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		site, err := m.SetSSL(r.Context(), r.PathValue("name"), ssl, reloadParam(r))
+		httpapi.WriteResult(w, http.StatusOK, site, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/certificates", func(w http.ResponseWriter, r *http.Request) {
+		certs, err := m.Certificates(r.Context())
+		httpapi.WriteResult(w, http.StatusOK, certs, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/test", func(w http.ResponseWriter, r *http.Request) {
+		out, err := m.Test(r.Context())
+		httpapi.WriteResult(w, http.StatusOK, map[string]string{"output": out}, err)
+	})
+	mux.HandleFunc("POST "+prefix+"/reload", func(w http.ResponseWriter, r *http.Request) {
+		out, err := m.Reload(r.Context())
+		httpapi.WriteResult(w, http.StatusOK, map[string]string{"output": out}, err)
+	})
+	mux.HandleFunc("GET "+prefix+"/status", func(w http.ResponseWriter, r *http.Request) {
+		status, err := m.Status()
+		httpapi.WriteResult(w, http.StatusOK, status, err)
+	})
+	return mux
+}
+
+// reloadParam 查询参数 reload，默认为 true
+func reloadParam(r *http.Request) bool {
+	v := r.URL.Query().Get("reload")
+	if v == "" {
+		return true
+	}
+	reload, err := strconv.ParseBool(v)
+	return err != nil || reload
+}
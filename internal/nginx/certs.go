@@ -0,0 +1,139 @@
+package nginx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// Certificate server 块引用的证书
+type Certificate struct {
+	Path      string    `json:"path"`
+	Key       string    `json:"key,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	NotAfter  time.Time `json:"not_after,omitempty"`
+	DaysLeft  int       `json:"days_left"`
+	Servers   []string  `json:"servers"`         // 使用该证书的 server 块的 server_name
+	Sites     []string  `json:"sites,omitempty"` // 使用该证书的托管站点
+	Error     string    `json:"error,omitempty"` // 无法读取、解析证书或与私钥不匹配
+}
+
+// Certificates 列出配置中引用的证书及其有效期，按到期时间排序
+func (m *Manager) Certificates(ctx context.Context) ([]*Certificate, error) {
+	result, err := m.Servers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 相对路径相对于 nginx 的 conf 前缀，即主配置文件所在目录
+	prefix := filepath.Dir(m.Config().ConfigFile)
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(prefix, path)
+	}
+	byPath := make(map[string]*Certificate)
+	certs := []*Certificate{}
+	for _, s := range result.Servers {
+		if s.Certificate == "" {
+			continue
+		}
+		path := abs(s.Certificate)
+		c := byPath[path]
+		if c == nil {
+			c = &Certificate{Path: path, Key: abs(s.CertificateKey), Servers: []string{}}
+			byPath[path] = c
+			certs = append(certs, c)
+		}
+		c.Servers = append(c.Servers, s.ServerNames...)
+		if s.Site != "" && !slices.Contains(c.Sites, s.Site) {
+			c.Sites = append(c.Sites, s.Site)
+		}
+	}
+
+	for _, c := range certs {
+		// 证书路径可能含变量（如 $ssl_server_name），只在 nginx 运行时确定
+		if !safeValue(c.Path) {
+			c.Error = "证书路径含变量，无法检查"
+			continue
+		}
+		var leaf *x509.Certificate
+		if c.Key != "" && safeValue(c.Key) {
+			leaf, err = m.loadCertificate(c.Path, c.Key)
+		} else {
+			leaf, err = m.readCertificate(c.Path)
+		}
+		if err != nil {
+			c.Error = err.Error()
+			continue
+		}
+		c.Subject = leaf.Subject.String()
+		c.Issuer = leaf.Issuer.String()
+		c.DNSNames = leaf.DNSNames
+		c.NotBefore = leaf.NotBefore
+		c.NotAfter = leaf.NotAfter
+		c.DaysLeft = int(time.Until(leaf.NotAfter).Hours() / 24)
+	}
+	sort.SliceStable(certs, func(i, j int) bool {
+		if (certs[i].Error == "") != (certs[j].Error == "") {
+			return certs[i].Error != ""
+		}
+		return certs[i].NotAfter.Before(certs[j].NotAfter)
+	})
+	return certs, nil
+}
+
+// loadCertificate 读取证书和私钥并检查是否匹配，返回证书链中的第一个证书
+func (m *Manager) loadCertificate(certFile, keyFile string) (*x509.Certificate, error) {
+	for _, path := range []string{certFile, keyFile} {
+		if err := m.env.CheckRead(path); err != nil {
+			return nil, err
+		}
+	}
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "证书或私钥无效")
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "无法解析证书")
+	}
+	return leaf, nil
+}
+
+// readCertificate 只读取证书，用于没有配置私钥的 server 块
+func (m *Manager) readCertificate(certFile string) (*x509.Certificate, error) {
+	if err := m.env.CheckRead(certFile); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errcode.New(errcode.InvalidArgument, "%s 中没有 PEM 格式的证书", certFile)
+		}
+		if block.Type == "CERTIFICATE" {
+			leaf, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, errcode.Wrap(errcode.InvalidArgument, err, "无法解析证书")
+			}
+			return leaf, nil
+		}
+	}
+}
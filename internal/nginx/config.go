@@ -0,0 +1,104 @@
+// Package nginx Nginx 管理：解析配置并列出 server 块，按模板创建和编辑站点（虚拟主机），
+// 修改后先 nginx -t 测试、失败时回滚再重载，管理站点的 SSL 证书，并采集 stub_status 状态
+package nginx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 配置默认值
+const (
+	defaultBinary          = "nginx"
+	defaultConfigFile      = "/etc/nginx/nginx.conf"
+	defaultSitesDir        = "/etc/nginx/conf.d"
+	defaultStatusURL       = "http://127.0.0.1/nginx_status"
+	defaultMetricsInterval = 15
+)
+
+// Config Nginx 插件配置
+type Config struct {
+	Binary     string `json:"binary"`      // nginx 可执行文件，默认 nginx
+	ConfigFile string `json:"config_file"` // 主配置文件，默认 /etc/nginx/nginx.conf
+	SitesDir   string `json:"sites_dir"`   // 托管站点的配置文件目录，默认 /etc/nginx/conf.d
+	// EnabledDir 非空时站点配置写入 SitesDir，启用的站点在该目录创建符号链接
+	// （Debian 的 sites-available/sites-enabled 布局）；为空时删除站点即停用
+	EnabledDir      string `json:"enabled_dir,omitempty"`
+	StatusURL       string `json:"status_url"`       // stub_status 地址，"-" 表示不采集
+	MetricsInterval int    `json:"metrics_interval"` // 秒，stub_status 采集间隔
+}
+
+// ParseConfig 解析并校验插件配置，未设置的项使用默认值
+func ParseConfig(values map[string]any) (*Config, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	config := Config{
+		Binary:          defaultBinary,
+		ConfigFile:      defaultConfigFile,
+		SitesDir:        defaultSitesDir,
+		StatusURL:       defaultStatusURL,
+		MetricsInterval: defaultMetricsInterval,
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "Nginx 配置格式无效")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// validate 逐项校验配置，错误以字段路径返回
+func (c *Config) validate() error {
+	var fields []errcode.FieldViolation
+	add := func(field, format string, args ...any) {
+		fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Binary == "" {
+		add("binary", "不能为空")
+	}
+	if !filepath.IsAbs(c.ConfigFile) {
+		add("config_file", "必须是绝对路径")
+	}
+	if !filepath.IsAbs(c.SitesDir) {
+		add("sites_dir", "必须是绝对路径")
+	}
+	if c.EnabledDir != "" {
+		if !filepath.IsAbs(c.EnabledDir) {
+			add("enabled_dir", "必须是绝对路径")
+		} else if filepath.Clean(c.EnabledDir) == filepath.Clean(c.SitesDir) {
+			add("enabled_dir", "不能与 sites_dir 相同")
+		}
+	}
+	if c.statusEnabled() {
+		if u, err := url.Parse(c.StatusURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("status_url", "必须是 http 或 https 地址")
+		}
+	}
+	if c.MetricsInterval < 1 {
+		add("metrics_interval", "至少 1 秒")
+	}
+
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "Nginx 配置无效", fields)
+	}
+	return nil
+}
+
+// statusEnabled 是否采集 stub_status
+func (c *Config) statusEnabled() bool {
+	return c.StatusURL != "" && c.StatusURL != "-"
+}
+
+// interval stub_status 采集间隔
+func (c *Config) interval() time.Duration {
+	return time.Duration(c.MetricsInterval) * time.Second
+}
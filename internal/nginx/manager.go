@@ -0,0 +1,313 @@
+package nginx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// commandTimeout nginx -t 和 nginx -s reload 的超时
+const commandTimeout = 30 * time.Second
+
+// Store 站点记录的持久化存储，插件的 KV 存储实现该接口
+type Store interface {
+	StorageGet(ctx context.Context, key string) ([]byte, bool, error)
+	StorageSet(ctx context.Context, key string, value []byte) error
+	StorageDelete(ctx context.Context, key string) error
+	StorageList(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Env 访问文件、命令和网络的入口，由插件按清单声明的权限提供
+type Env struct {
+	Store      Store
+	HTTPClient *http.Client                      // 采集 stub_status
+	LookPath   func(name string) (string, error) // 查找命令并检查 exec 权限
+	CheckRead  func(path string) error
+	CheckWrite func(path string) error
+	// OnStatus 每次采集 stub_status 后调用，失败时 cur 为 nil；prev 为上一次成功采集的状态
+	OnStatus func(prev, cur *Status, err error)
+}
+
+// Manager 管理 Nginx 配置和站点
+type Manager struct {
+	env Env
+
+	mu     sync.Mutex // 串行化配置修改、测试和重载
+	cfgMu  sync.RWMutex
+	config *Config
+
+	statusMu  sync.RWMutex
+	status    *Status
+	statusErr error
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager 创建管理器并开始采集 stub_status
+func NewManager(env Env, config *Config) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{env: env, config: config, cancel: cancel}
+	m.wg.Add(1)
+	go m.collectStatus(ctx)
+	return m
+}
+
+// SetConfig 替换配置，下一次采集起使用新的 stub_status 地址和间隔
+func (m *Manager) SetConfig(config *Config) {
+	m.cfgMu.Lock()
+	defer m.cfgMu.Unlock()
+	m.config = config
+}
+
+// Config 当前配置
+func (m *Manager) Config() *Config {
+	m.cfgMu.RLock()
+	defer m.cfgMu.RUnlock()
+	return m.config
+}
+
+// Close 停止采集并等待退出
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// ServersResult 配置中的 server 块及解析过的文件
+type ServersResult struct {
+	Servers []*ServerBlock `json:"servers"`
+	Files   []string       `json:"files"`
+	Errors  []ParseError   `json:"errors,omitempty"`
+}
+
+// Servers 解析配置并列出 server 块，标注由插件管理的站点
+func (m *Manager) Servers(ctx context.Context) (*ServersResult, error) {
+	config := m.Config()
+	result, err := Parse(config.ConfigFile, m.env.CheckRead)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Of(err), err, "读取 Nginx 配置失败")
+	}
+
+	sites, err := m.Sites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	for _, s := range sites {
+		files[m.sitePath(s.Name, s.Enabled)] = s.Name
+		if config.EnabledDir != "" {
+			files[m.linkPath(s.Name)] = s.Name
+		}
+	}
+	servers := Servers(result.Directives)
+	for _, s := range servers {
+		s.Site = files[filepath.Clean(s.File)]
+	}
+	if servers == nil {
+		servers = []*ServerBlock{}
+	}
+	return &ServersResult{Servers: servers, Files: result.Files, Errors: result.Errors}, nil
+}
+
+// Test 执行 nginx -t，配置有误时返回 VALIDATION_FAILED 和 nginx 的输出
+func (m *Manager) Test(ctx context.Context) (string, error) {
+	out, err := m.run(ctx, "-t")
+	if err != nil {
+		if errcode.Of(err) == errcode.Internal {
+			return out, errcode.Wrap(errcode.ValidationFailed, err, "Nginx 配置测试失败: "+out)
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+// Reload 测试通过后重载 Nginx
+func (m *Manager) Reload(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reload(ctx)
+}
+
+// reload 测试并重载（需要持有 mu）
+func (m *Manager) reload(ctx context.Context) (string, error) {
+	out, err := m.Test(ctx)
+	if err != nil {
+		return out, err
+	}
+	reloadOut, err := m.run(ctx, "-s", "reload")
+	out = strings.TrimSpace(out + "\n" + reloadOut)
+	if err != nil {
+		return out, errcode.Wrap(errcode.Unavailable, err, "重载 Nginx 失败: "+reloadOut)
+	}
+	log.Info().Msg("Nginx 已重载")
+	return out, nil
+}
+
+// run 以配置的主配置文件执行 nginx，返回合并的输出
+func (m *Manager) run(ctx context.Context, args ...string) (string, error) {
+	config := m.Config()
+	path, err := m.env.LookPath(config.Binary)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, append([]string{"-c", config.ConfigFile}, args...)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	output := strings.TrimSpace(out.String())
+	if ctx.Err() != nil {
+		return output, errcode.Wrap(errcode.Unavailable, ctx.Err(), "nginx 执行超时")
+	}
+	return output, err
+}
+
+// fileChange 一次配置修改中的一个文件：写入 content、创建指向 link 的符号链接，或在 remove 时删除
+type fileChange struct {
+	path    string
+	content []byte
+	link    string
+	remove  bool
+}
+
+// apply 修改配置文件后执行 nginx -t，失败时恢复全部文件并返回测试输出；通过后按需重载（需要持有 mu）
+func (m *Manager) apply(ctx context.Context, changes []fileChange, reload bool) error {
+	for _, c := range changes {
+		if err := m.env.CheckWrite(c.path); err != nil {
+			return err
+		}
+	}
+
+	var snapshots []*snapshot
+	rollback := func() {
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if err := snapshots[i].restore(); err != nil {
+				log.Error().Err(err).Str("path", snapshots[i].path).Msg("恢复 Nginx 配置文件失败")
+			}
+		}
+	}
+	for _, c := range changes {
+		s, err := takeSnapshot(c.path)
+		if err != nil {
+			rollback()
+			return err
+		}
+		snapshots = append(snapshots, s)
+		if err := c.perform(); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	if _, err := m.Test(ctx); err != nil {
+		rollback()
+		return err
+	}
+	if reload {
+		if _, err := m.reload(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fileChange) perform() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	switch {
+	case c.remove:
+		if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	case c.link != "":
+		os.Remove(c.path)
+		return os.Symlink(c.link, c.path)
+	default:
+		return writeFileAtomic(c.path, c.content, 0644)
+	}
+}
+
+// snapshot 文件修改前的状态
+type snapshot struct {
+	path   string
+	exists bool
+	link   string
+	data   []byte
+	mode   os.FileMode
+}
+
+func takeSnapshot(path string) (*snapshot, error) {
+	s := &snapshot{path: path}
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.exists = true
+	s.mode = info.Mode().Perm()
+	if info.Mode()&os.ModeSymlink != 0 {
+		s.link, err = os.Readlink(path)
+		return s, err
+	}
+	s.data, err = os.ReadFile(path)
+	return s, err
+}
+
+func (s *snapshot) restore() error {
+	switch {
+	case !s.exists:
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	case s.link != "":
+		os.Remove(s.path)
+		return os.Symlink(s.link, s.path)
+	default:
+		return writeFileAtomic(s.path, s.data, s.mode)
+	}
+}
+
+// writeFileAtomic 先写入同目录的临时文件再重命名，已存在的文件保留原有权限
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".runixo-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,303 @@
+package nginx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// memStore 内存中的 Store
+type memStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memStore) StorageGet(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok, nil
+}
+
+func (s *memStore) StorageSet(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *memStore) StorageDelete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memStore) StorageList(_ context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// testManager 使用假的 nginx 命令：目录中存在 fail 文件时 -t 失败，每次调用记录到 calls 文件
+func testManager(t *testing.T, enabledDir bool) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "nginx.conf"), "events {}\nhttp {\n    include conf.d/*.conf;\n    include sites-enabled/*;\n}\n")
+	script := "#!/bin/sh\n" +
+		"echo \"$3 $4\" >> " + filepath.Join(dir, "calls") + "\n" +
+		"if [ \"$3\" = \"-t\" ] && [ -e " + filepath.Join(dir, "fail") + " ]; then echo 'nginx: [emerg] unknown directive' >&2; exit 1; fi\n"
+	writeTestFile(t, filepath.Join(dir, "nginx"), script)
+	os.Chmod(filepath.Join(dir, "nginx"), 0755)
+
+	config := &Config{
+		Binary:          filepath.Join(dir, "nginx"),
+		ConfigFile:      filepath.Join(dir, "nginx.conf"),
+		SitesDir:        filepath.Join(dir, "conf.d"),
+		StatusURL:       "-",
+		MetricsInterval: 60,
+	}
+	if enabledDir {
+		config.SitesDir = filepath.Join(dir, "sites-available")
+		config.EnabledDir = filepath.Join(dir, "sites-enabled")
+	}
+	allow := func(string) error { return nil }
+	m := NewManager(Env{
+		Store:      &memStore{data: make(map[string][]byte)},
+		LookPath:   func(name string) (string, error) { return name, nil },
+		CheckRead:  allow,
+		CheckWrite: allow,
+	}, config)
+	t.Cleanup(m.Close)
+	return m, dir
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "nginx.conf"), `
+user www-data;
+events { worker_connections 768; }
+http {
+    # 注释中的 { 不影响解析
+    log_format main '$remote_addr "$request" ${status}';
+    include conf.d/*.conf;
+    upstream app { server 127.0.0.1:3000; }
+}
+`)
+	writeTestFile(t, filepath.Join(dir, "conf.d", "a.conf"), `server {
+    listen 443 ssl http2;
+    server_name example.com www.example.com;
+    ssl_certificate /etc/ssl/example.pem;
+    location / { proxy_pass http://app; }
+    location ~ \.php$ { root /var/www; }
+}
+`)
+	writeTestFile(t, filepath.Join(dir, "conf.d", "b.conf"), "server {\n    listen 80\n}\n")
+
+	result, err := Parse(filepath.Join(dir, "nginx.conf"), func(string) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 3 {
+		t.Errorf("Files = %v, want 3 个文件", result.Files)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 3 || !strings.HasSuffix(result.Errors[0].File, "b.conf") {
+		t.Errorf("Errors = %+v, want b.conf 第 3 行缺少 ;", result.Errors)
+	}
+	http := result.Directives[2]
+	if http.Name != "http" || http.Block[0].Args[1] != `$remote_addr "$request" ${status}` {
+		t.Errorf("log_format = %+v", http.Block[0])
+	}
+
+	servers := Servers(result.Directives)
+	if len(servers) != 2 {
+		t.Fatalf("Servers = %d, want 2（upstream 中的 server 不算）", len(servers))
+	}
+	s := servers[0]
+	if !s.SSL || strings.Join(s.ServerNames, ",") != "example.com,www.example.com" || s.Certificate != "/etc/ssl/example.pem" {
+		t.Errorf("server = %+v", s)
+	}
+	if len(s.Locations) != 2 || s.Locations[0].ProxyPass != "http://app" || s.Locations[1].Path != `~ \.php$` || s.Locations[1].Root != "/var/www" {
+		t.Errorf("locations = %+v", s.Locations)
+	}
+}
+
+func TestSiteRollback(t *testing.T) {
+	for _, enabledDir := range []bool{false, true} {
+		m, dir := testManager(t, enabledDir)
+		ctx := context.Background()
+		spec := Spec{Template: TemplateReverseProxy, ServerNames: []string{"app.example.com"}, Upstream: "http://127.0.0.1:3000"}
+
+		site, err := m.CreateSite(ctx, "app", spec, true)
+		if err != nil {
+			t.Fatalf("CreateSite: %v", err)
+		}
+		original, _ := os.ReadFile(site.File)
+		if !strings.Contains(string(original), "proxy_pass http://127.0.0.1:3000;") {
+			t.Errorf("生成的配置:\n%s", original)
+		}
+		if calls, _ := os.ReadFile(filepath.Join(dir, "calls")); string(calls) != "-t \n-t \n-s reload\n" {
+			t.Errorf("nginx 调用 = %q, want 测试、重载前再测试、重载", calls)
+		}
+		servers, err := m.Servers(ctx)
+		if err != nil || len(servers.Servers) != 1 || servers.Servers[0].Site != "app" {
+			t.Errorf("Servers = %+v, %v", servers, err)
+		}
+
+		// nginx -t 失败时恢复原配置
+		writeTestFile(t, filepath.Join(dir, "fail"), "")
+		spec.Upstream = "http://127.0.0.1:4000"
+		if _, err := m.UpdateSite(ctx, "app", spec, true); errcode.Of(err) != errcode.ValidationFailed || !strings.Contains(err.Error(), "unknown directive") {
+			t.Errorf("UpdateSite = %v, want VALIDATION_FAILED 及 nginx 输出", err)
+		}
+		if data, _ := os.ReadFile(site.File); string(data) != string(original) {
+			t.Errorf("测试失败后配置未恢复:\n%s", data)
+		}
+		if _, err := m.CreateSite(ctx, "other", spec, false); err == nil {
+			t.Errorf("测试失败时 CreateSite 应失败")
+		}
+		if _, err := os.Stat(m.sitePath("other", true)); !os.IsNotExist(err) {
+			t.Errorf("创建失败后遗留了配置文件")
+		}
+		os.Remove(filepath.Join(dir, "fail"))
+
+		if _, err := m.SetEnabled(ctx, "app", false, false); err != nil {
+			t.Fatalf("SetEnabled: %v", err)
+		}
+		if servers, _ := m.Servers(ctx); len(servers.Servers) != 0 {
+			t.Errorf("停用后仍被 include: %+v", servers.Servers)
+		}
+		if err := m.DeleteSite(ctx, "app", false); err != nil {
+			t.Fatalf("DeleteSite: %v", err)
+		}
+		if entries, _ := os.ReadDir(m.Config().SitesDir); len(entries) != 0 {
+			t.Errorf("删除后遗留文件: %v", entries)
+		}
+	}
+}
+
+func TestSiteSSL(t *testing.T) {
+	m, dir := testManager(t, false)
+	ctx := context.Background()
+	certFile, keyFile := writeTestCert(t, dir, "secure.example.com")
+
+	if _, err := m.CreateSite(ctx, "secure", Spec{Template: TemplateStatic, ServerNames: []string{"secure.example.com"}, Root: "/var/www/secure"}, false); err != nil {
+		t.Fatal(err)
+	}
+	site, err := m.SetSSL(ctx, "secure", &SSL{Certificate: certFile, CertificateKey: keyFile, RedirectHTTP: true}, false)
+	if err != nil {
+		t.Fatalf("SetSSL: %v", err)
+	}
+	data, _ := os.ReadFile(site.File)
+	for _, want := range []string{"listen 443 ssl;", "ssl_certificate " + certFile + ";", "return 301 https://$host$request_uri;"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("配置中缺少 %q:\n%s", want, data)
+		}
+	}
+
+	certs, err := m.Certificates(ctx)
+	if err != nil || len(certs) != 1 {
+		t.Fatalf("Certificates = %+v, %v", certs, err)
+	}
+	if c := certs[0]; c.Error != "" || c.DaysLeft < 29 || c.Sites[0] != "secure" || c.DNSNames[0] != "secure.example.com" {
+		t.Errorf("certificate = %+v", c)
+	}
+
+	// 私钥与证书不匹配
+	_, otherKey := writeTestCert(t, filepath.Join(dir, "other"), "other.example.com")
+	if _, err := m.SetSSL(ctx, "secure", &SSL{Certificate: certFile, CertificateKey: otherKey}, false); errcode.Of(err) != errcode.InvalidArgument {
+		t.Errorf("SetSSL 不匹配的私钥 = %v", err)
+	}
+}
+
+func TestSpecValidate(t *testing.T) {
+	spec := Spec{
+		Template:    TemplatePHP,
+		ServerNames: []string{"example.com", "bad;name"},
+		Root:        "/var/www; include /etc/passwd",
+		FastCGI:     "127.0.0.1:9000",
+		SSL:         &SSL{Certificate: "cert.pem", CertificateKey: "/etc/ssl/key.pem"},
+	}
+	want := []string{"server_names[1]", "root", "ssl.certificate"}
+	fields := errcode.FieldsOf(spec.validate())
+	if len(fields) != len(want) {
+		t.Fatalf("校验错误 = %+v, want %v", fields, want)
+	}
+	for i, f := range fields {
+		if f.Field != want[i] {
+			t.Errorf("fields[%d] = %s, want %s", i, f.Field, want[i])
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	s, err := parseStatus("Active connections: 291 \nserver accepts handled requests\n 16630948 16630947 31070465 \nReading: 6 Writing: 179 Waiting: 106 \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Active != 291 || s.Handled != 16630947 || s.Requests != 31070465 || s.Writing != 179 || s.Waiting != 106 {
+		t.Errorf("status = %+v", s)
+	}
+	if _, err := parseStatus("<html>404</html>"); err == nil {
+		t.Errorf("非 stub_status 内容应解析失败")
+	}
+}
+
+// writeTestCert 生成有效期 30 天的自签名证书
+func writeTestCert(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(30*24*time.Hour + time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+".key")
+	writeTestFile(t, certFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	writeTestFile(t, keyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return certFile, keyFile
+}
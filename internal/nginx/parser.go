@@ -0,0 +1,271 @@
+package nginx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth include 的最大嵌套层数，防止循环包含
+const maxIncludeDepth = 16
+
+// Directive 配置中的一条指令，块指令的子指令在 Block 中，include 已展开为被包含文件中的指令
+type Directive struct {
+	Name  string       `json:"name"`
+	Args  []string     `json:"args,omitempty"`
+	Block []*Directive `json:"block,omitempty"`
+	File  string       `json:"file"`
+	Line  int          `json:"line"`
+}
+
+// ParseError 配置文件中的语法错误或无法读取的文件，不影响其他文件的解析
+type ParseError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// ParseResult 从主配置文件开始解析的结果
+type ParseResult struct {
+	Directives []*Directive `json:"directives"`
+	Files      []string     `json:"files"` // 解析过的全部配置文件
+	Errors     []ParseError `json:"errors,omitempty"`
+}
+
+// Parse 解析主配置文件及其 include 的文件，相对路径的 include 相对于主配置文件所在目录（与 nginx 的 conf 前缀一致）
+// check 在读取每个文件前调用，返回错误的文件记录到 Errors 中并跳过
+func Parse(path string, check func(path string) error) (*ParseResult, error) {
+	p := &parser{prefix: filepath.Dir(path), check: check, result: &ParseResult{}, active: make(map[string]bool)}
+	if err := check(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p.result.Directives = p.parseData(path, data, 0)
+	return p.result, nil
+}
+
+type parser struct {
+	prefix string
+	check  func(path string) error
+	result *ParseResult
+	active map[string]bool // 正在解析的文件，用于发现循环包含
+}
+
+// parseData 解析一个文件的内容，出现语法错误时返回错误之前已解析的指令
+func (p *parser) parseData(file string, data []byte, depth int) []*Directive {
+	p.result.Files = append(p.result.Files, file)
+	p.active[file] = true
+	defer delete(p.active, file)
+
+	tokens, lerr := lex(data)
+	if lerr != nil {
+		p.result.Errors = append(p.result.Errors, ParseError{File: file, Line: lerr.line, Message: lerr.msg})
+		return nil
+	}
+	pos := 0
+	directives, perr := p.parseBlock(tokens, &pos, file, depth, false)
+	if perr != nil {
+		p.result.Errors = append(p.result.Errors, *perr)
+	}
+	return directives
+}
+
+// parseBlock 解析到块结束的 } 或文件末尾
+func (p *parser) parseBlock(tokens []token, pos *int, file string, depth int, inBlock bool) ([]*Directive, *ParseError) {
+	var directives []*Directive
+	var words []token
+	for *pos < len(tokens) {
+		t := tokens[*pos]
+		*pos++
+		if t.quoted || (t.text != ";" && t.text != "{" && t.text != "}") {
+			words = append(words, t)
+			continue
+		}
+		switch t.text {
+		case "}":
+			if len(words) > 0 {
+				return directives, &ParseError{File: file, Line: t.line, Message: `意外的 "}"，缺少 ";"`}
+			}
+			if !inBlock {
+				return directives, &ParseError{File: file, Line: t.line, Message: `意外的 "}"`}
+			}
+			return directives, nil
+		case ";", "{":
+			if len(words) == 0 {
+				return directives, &ParseError{File: file, Line: t.line, Message: fmt.Sprintf("意外的 %q", t.text)}
+			}
+			d := &Directive{Name: words[0].text, File: file, Line: words[0].line}
+			for _, w := range words[1:] {
+				d.Args = append(d.Args, w.text)
+			}
+			words = nil
+			if t.text == "{" {
+				block, err := p.parseBlock(tokens, pos, file, depth, true)
+				d.Block = block
+				if d.Block == nil {
+					d.Block = []*Directive{}
+				}
+				directives = append(directives, d)
+				if err != nil {
+					return directives, err
+				}
+				continue
+			}
+			if d.Name == "include" && len(d.Args) == 1 {
+				directives = append(directives, p.include(d, depth)...)
+				continue
+			}
+			directives = append(directives, d)
+		}
+	}
+	if len(words) > 0 {
+		return directives, &ParseError{File: file, Line: words[0].line, Message: `意外的文件结束，缺少 ";"`}
+	}
+	if inBlock {
+		return directives, &ParseError{File: file, Line: lastLine(tokens), Message: `意外的文件结束，缺少 "}"`}
+	}
+	return directives, nil
+}
+
+// include 展开 include 指令，通配符按文件名排序（与 nginx 一致），没有匹配的文件时为空
+func (p *parser) include(d *Directive, depth int) []*Directive {
+	pattern := d.Args[0]
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(p.prefix, pattern)
+	}
+	var files []string
+	if strings.ContainsAny(pattern, "*?[") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			p.result.Errors = append(p.result.Errors, ParseError{File: d.File, Line: d.Line, Message: "无效的 include 模式"})
+			return nil
+		}
+		files = matches
+	} else {
+		files = []string{pattern}
+	}
+
+	var directives []*Directive
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil && info.IsDir() {
+			continue
+		}
+		if depth+1 > maxIncludeDepth || p.active[f] {
+			p.result.Errors = append(p.result.Errors, ParseError{File: d.File, Line: d.Line, Message: "循环包含 " + f})
+			continue
+		}
+		if err := p.check(f); err != nil {
+			p.result.Errors = append(p.result.Errors, ParseError{File: f, Message: err.Error()})
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			p.result.Errors = append(p.result.Errors, ParseError{File: f, Message: err.Error()})
+			continue
+		}
+		directives = append(directives, p.parseData(f, data, depth+1)...)
+	}
+	return directives
+}
+
+// token 词法单元，quoted 为引号中的内容（不作为 ; { } 处理）
+type token struct {
+	text   string
+	line   int
+	quoted bool
+}
+
+// lexError 词法错误的位置和原因
+type lexError struct {
+	line int
+	msg  string
+}
+
+// lex 按 nginx 的规则切分词法单元：# 开始的注释到行尾，单双引号中的内容为一个单元，
+// 未加引号的单元中 ${var} 的花括号属于变量名
+func lex(data []byte) ([]token, *lexError) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case isSpace(c):
+			i++
+		case c == '#':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == ';' || c == '{' || c == '}':
+			tokens = append(tokens, token{text: string(c), line: line})
+			i++
+		case c == '"' || c == '\'':
+			start := line
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(data) {
+					return nil, &lexError{start, "引号未闭合"}
+				}
+				ch := data[i]
+				if ch == '\\' && i+1 < len(data) && (data[i+1] == c || data[i+1] == '\\') {
+					b.WriteByte(data[i+1])
+					i += 2
+					continue
+				}
+				i++
+				if ch == c {
+					break
+				}
+				if ch == '\n' {
+					line++
+				}
+				b.WriteByte(ch)
+			}
+			tokens = append(tokens, token{text: b.String(), line: start, quoted: true})
+		default:
+			start, vars := i, 0
+			for i < len(data) {
+				ch := data[i]
+				if ch == '\\' && i+1 < len(data) {
+					i += 2
+					continue
+				}
+				if ch == '{' && i > start && data[i-1] == '$' {
+					vars++
+				} else if ch == '}' && vars > 0 {
+					vars--
+				} else if isSpace(ch) || ch == '\n' || ch == ';' || ch == '{' || ch == '}' {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, token{text: string(data[start:i]), line: line})
+		}
+	}
+	return tokens, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+func lastLine(tokens []token) int {
+	if len(tokens) == 0 {
+		return 0
+	}
+	return tokens[len(tokens)-1].line
+}
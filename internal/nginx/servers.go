@@ -0,0 +1,89 @@
+package nginx
+
+import (
+	"strings"
+)
+
+// ServerBlock http 上下文中的一个 server 块
+type ServerBlock struct {
+	File           string     `json:"file"`
+	Line           int        `json:"line"`
+	ServerNames    []string   `json:"server_names"`
+	Listen         []string   `json:"listen"` // 每条 listen 指令的参数以空格连接，没有 listen 时 nginx 使用默认端口
+	SSL            bool       `json:"ssl"`
+	Root           string     `json:"root,omitempty"`
+	Certificate    string     `json:"certificate,omitempty"`
+	CertificateKey string     `json:"certificate_key,omitempty"`
+	Locations      []Location `json:"locations,omitempty"`
+	Site           string     `json:"site,omitempty"` // 由插件管理的站点名
+}
+
+// Location server 块中的 location
+type Location struct {
+	Path      string `json:"path"` // 包括修饰符，如 "~ \.php$"
+	Line      int    `json:"line"`
+	ProxyPass string `json:"proxy_pass,omitempty"`
+	Root      string `json:"root,omitempty"`
+}
+
+// Servers 列出 http 块中的 server 块
+func Servers(directives []*Directive) []*ServerBlock {
+	var servers []*ServerBlock
+	for _, d := range directives {
+		if d.Name != "http" {
+			continue
+		}
+		for _, s := range d.Block {
+			if s.Name == "server" && s.Block != nil {
+				servers = append(servers, serverBlock(s))
+			}
+		}
+	}
+	return servers
+}
+
+func serverBlock(d *Directive) *ServerBlock {
+	s := &ServerBlock{File: d.File, Line: d.Line, ServerNames: []string{}, Listen: []string{}}
+	for _, c := range d.Block {
+		switch c.Name {
+		case "server_name":
+			s.ServerNames = append(s.ServerNames, c.Args...)
+		case "listen":
+			s.Listen = append(s.Listen, strings.Join(c.Args, " "))
+			for _, a := range c.Args[min(1, len(c.Args)):] {
+				if a == "ssl" {
+					s.SSL = true
+				}
+			}
+		case "ssl":
+			if len(c.Args) == 1 && c.Args[0] == "on" {
+				s.SSL = true
+			}
+		case "root":
+			s.Root = firstArg(c)
+		case "ssl_certificate":
+			s.Certificate = firstArg(c)
+		case "ssl_certificate_key":
+			s.CertificateKey = firstArg(c)
+		case "location":
+			l := Location{Path: strings.Join(c.Args, " "), Line: c.Line}
+			for _, ld := range c.Block {
+				switch ld.Name {
+				case "proxy_pass":
+					l.ProxyPass = firstArg(ld)
+				case "root", "alias":
+					l.Root = firstArg(ld)
+				}
+			}
+			s.Locations = append(s.Locations, l)
+		}
+	}
+	return s
+}
+
+func firstArg(d *Directive) string {
+	if len(d.Args) == 0 {
+		return ""
+	}
+	return d.Args[0]
+}
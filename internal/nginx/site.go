@@ -0,0 +1,249 @@
+package nginx
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// sitePrefix 存储中站点记录的键前缀，键为 sites/<站点名>
+const sitePrefix = "sites/"
+
+// siteNamePattern 站点名，同时是配置文件名（<站点名>.conf），允许使用域名
+var siteNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// Site 由插件管理的站点，配置由 Spec 按模板生成
+type Site struct {
+	Name      string    `json:"name"`
+	Spec      Spec      `json:"spec"`
+	Enabled   bool      `json:"enabled"`
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SiteDetail 站点及其当前的配置文件内容
+type SiteDetail struct {
+	*Site
+	Content string `json:"content"`
+}
+
+// Sites 由插件管理的站点，按名称排序
+func (m *Manager) Sites(ctx context.Context) ([]*Site, error) {
+	keys, err := m.env.Store.StorageList(ctx, sitePrefix)
+	if err != nil {
+		return nil, err
+	}
+	sites := []*Site{}
+	for _, key := range keys {
+		s, err := m.loadSite(ctx, strings.TrimPrefix(key, sitePrefix))
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, s)
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Name < sites[j].Name })
+	return sites, nil
+}
+
+// GetSite 站点及其配置文件内容
+func (m *Manager) GetSite(ctx context.Context, name string) (*SiteDetail, error) {
+	s, err := m.loadSite(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.env.CheckRead(s.File); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.File)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &SiteDetail{Site: s, Content: string(data)}, nil
+}
+
+// CreateSite 按模板创建并启用站点，nginx -t 失败时不留下任何文件；不覆盖已存在的配置文件
+func (m *Manager) CreateSite(ctx context.Context, name string, spec Spec, reload bool) (*Site, error) {
+	if !siteNamePattern.MatchString(name) {
+		return nil, errcode.Invalid(errcode.ValidationFailed, "站点参数无效", []errcode.FieldViolation{{Field: "name", Description: "无效的站点名"}})
+	}
+	if err := m.checkSpec(&spec); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, found, err := m.env.Store.StorageGet(ctx, sitePrefix+name); err != nil {
+		return nil, err
+	} else if found {
+		return nil, errcode.New(errcode.AlreadyExists, "站点 %s 已存在", name)
+	}
+	for _, path := range []string{m.sitePath(name, true), m.sitePath(name, false), m.linkPath(name)} {
+		if _, err := os.Lstat(path); err == nil {
+			return nil, errcode.New(errcode.AlreadyExists, "配置文件 %s 已存在，不覆盖未由插件管理的配置", path)
+		}
+	}
+
+	now := time.Now().UTC()
+	s := &Site{Name: name, Spec: spec, Enabled: true, CreatedAt: now}
+	if err := m.writeSite(ctx, s, reload); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UpdateSite 按新参数重新生成站点配置，nginx -t 失败时恢复原配置
+func (m *Manager) UpdateSite(ctx context.Context, name string, spec Spec, reload bool) (*Site, error) {
+	if err := m.checkSpec(&spec); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.loadSite(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.Spec = spec
+	if err := m.writeSite(ctx, s, reload); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetSSL 设置或移除（ssl 为 nil）站点证书，证书和私钥须可读且相互匹配
+func (m *Manager) SetSSL(ctx context.Context, name string, ssl *SSL, reload bool) (*Site, error) {
+	m.mu.Lock()
+	s, err := m.loadSite(ctx, name)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	spec := s.Spec
+	spec.SSL = ssl
+	return m.UpdateSite(ctx, name, spec, reload)
+}
+
+// SetEnabled 启用或停用站点：配置了 enabled_dir 时创建或删除符号链接，
+// 否则把配置文件重命名为 <站点名>.conf 或 <站点名>.conf.disabled
+func (m *Manager) SetEnabled(ctx context.Context, name string, enabled, reload bool) (*Site, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.loadSite(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if s.Enabled == enabled {
+		return s, nil
+	}
+	s.Enabled = enabled
+	if err := m.writeSite(ctx, s, reload); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DeleteSite 删除站点的配置文件和记录
+func (m *Manager) DeleteSite(ctx context.Context, name string, reload bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.loadSite(ctx, name)
+	if err != nil {
+		return err
+	}
+	changes := []fileChange{{path: m.sitePath(name, true), remove: true}, {path: m.sitePath(name, false), remove: true}}
+	if m.Config().EnabledDir != "" {
+		changes = append(changes, fileChange{path: m.linkPath(name), remove: true})
+	}
+	if err := m.apply(ctx, changes, reload && s.Enabled); err != nil {
+		return err
+	}
+	return m.env.Store.StorageDelete(ctx, sitePrefix+name)
+}
+
+// checkSpec 校验参数，启用 SSL 时检查证书和私钥
+func (m *Manager) checkSpec(spec *Spec) error {
+	if err := spec.validate(); err != nil {
+		return err
+	}
+	if spec.SSL != nil {
+		if _, err := m.loadCertificate(spec.SSL.Certificate, spec.SSL.CertificateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSite 按站点当前的参数和启用状态写入配置文件，测试通过后保存记录（需要持有 mu）
+func (m *Manager) writeSite(ctx context.Context, s *Site, reload bool) error {
+	content, err := render(s.Name, &s.Spec)
+	if err != nil {
+		return err
+	}
+
+	path := m.sitePath(s.Name, s.Enabled)
+	var changes []fileChange
+	if m.Config().EnabledDir == "" {
+		changes = []fileChange{{path: path, content: content}, {path: m.sitePath(s.Name, !s.Enabled), remove: true}}
+	} else if s.Enabled {
+		changes = []fileChange{{path: path, content: content}, {path: m.linkPath(s.Name), link: path}}
+	} else {
+		changes = []fileChange{{path: path, content: content}, {path: m.linkPath(s.Name), remove: true}}
+	}
+	if err := m.apply(ctx, changes, reload); err != nil {
+		return err
+	}
+
+	s.File = path
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return m.env.Store.StorageSet(ctx, sitePrefix+s.Name, data)
+}
+
+func (m *Manager) loadSite(ctx context.Context, name string) (*Site, error) {
+	data, found, err := m.env.Store.StorageGet(ctx, sitePrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errcode.New(errcode.NotFound, "站点 %s 不存在", name)
+	}
+	var s Site
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// sitePath 站点配置文件的路径，未配置 enabled_dir 时停用的站点以 .disabled 结尾，不被 include *.conf 加载
+func (m *Manager) sitePath(name string, enabled bool) string {
+	config := m.Config()
+	path := filepath.Join(config.SitesDir, name+".conf")
+	if config.EnabledDir == "" && !enabled {
+		path += ".disabled"
+	}
+	return path
+}
+
+// linkPath enabled_dir 中指向站点配置的符号链接
+func (m *Manager) linkPath(name string) string {
+	config := m.Config()
+	if config.EnabledDir == "" {
+		return ""
+	}
+	return filepath.Join(config.EnabledDir, name+".conf")
+}
@@ -0,0 +1,143 @@
+package nginx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// maxStatusSize stub_status 响应的读取上限
+const maxStatusSize = 4096
+
+// Status stub_status 的一次采样
+type Status struct {
+	Active            int64     `json:"active"`
+	Accepts           int64     `json:"accepts"`
+	Handled           int64     `json:"handled"`
+	Requests          int64     `json:"requests"`
+	Reading           int64     `json:"reading"`
+	Writing           int64     `json:"writing"`
+	Waiting           int64     `json:"waiting"`
+	RequestsPerSecond float64   `json:"requests_per_second"` // 与上一次采样之间的平均值
+	Time              time.Time `json:"time"`
+}
+
+// Status 最近一次采集的 stub_status，采集失败时返回错误
+func (m *Manager) Status() (*Status, error) {
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	if m.statusErr != nil {
+		return nil, m.statusErr
+	}
+	if m.status == nil {
+		return nil, errcode.New(errcode.Unavailable, "尚未采集到 Nginx 状态")
+	}
+	return m.status, nil
+}
+
+// collectStatus 按配置的间隔采集 stub_status，直到 ctx 取消
+func (m *Manager) collectStatus(ctx context.Context) {
+	defer m.wg.Done()
+	for {
+		config := m.Config()
+		if config.statusEnabled() {
+			m.sampleStatus(ctx, config.StatusURL)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.interval()):
+		}
+	}
+}
+
+// sampleStatus 采集一次并通知 OnStatus
+func (m *Manager) sampleStatus(ctx context.Context, url string) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	cur, err := m.fetchStatus(reqCtx, url)
+	cancel()
+	if err != nil && ctx.Err() != nil {
+		// 插件停止时取消的请求不算采集失败
+		return
+	}
+
+	m.statusMu.Lock()
+	prev := m.status
+	if err != nil {
+		m.statusErr = err
+	} else {
+		if prev != nil {
+			if elapsed := cur.Time.Sub(prev.Time).Seconds(); elapsed > 0 && cur.Requests >= prev.Requests {
+				cur.RequestsPerSecond = float64(cur.Requests-prev.Requests) / elapsed
+			}
+		}
+		m.status, m.statusErr = cur, nil
+	}
+	m.statusMu.Unlock()
+
+	if err != nil {
+		log.Debug().Err(err).Str("url", url).Msg("采集 Nginx 状态失败")
+	}
+	if m.env.OnStatus != nil {
+		m.env.OnStatus(prev, cur, err)
+	}
+}
+
+// fetchStatus 请求并解析 stub_status
+func (m *Manager) fetchStatus(ctx context.Context, url string) (*Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.env.HTTPClient.Do(req)
+	if err != nil {
+		if code := errcode.Of(err); code != errcode.Internal {
+			return nil, errcode.Wrap(code, err, "请求 stub_status 失败")
+		}
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "请求 stub_status 失败")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStatusSize))
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "读取 stub_status 失败")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errcode.New(errcode.UpstreamUnavailable, "stub_status 返回 HTTP %d", resp.StatusCode)
+	}
+	s, err := parseStatus(string(body))
+	if err != nil {
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "无法解析 stub_status，请确认地址指向 stub_status")
+	}
+	s.Time = time.Now().UTC()
+	return s, nil
+}
+
+// parseStatus 解析 stub_status 的输出：
+//
+//	Active connections: 291
+//	server accepts handled requests
+//	 16630948 16630948 31070465
+//	Reading: 6 Writing: 179 Waiting: 106
+func parseStatus(body string) (*Status, error) {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 4 {
+		return nil, fmt.Errorf("应为 4 行，实际 %d 行", len(lines))
+	}
+	var s Status
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[0]), "Active connections: %d", &s.Active); err != nil {
+		return nil, fmt.Errorf("第 1 行: %w", err)
+	}
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[2]), "%d %d %d", &s.Accepts, &s.Handled, &s.Requests); err != nil {
+		return nil, fmt.Errorf("第 3 行: %w", err)
+	}
+	if _, err := fmt.Sscanf(strings.TrimSpace(lines[3]), "Reading: %d Writing: %d Waiting: %d", &s.Reading, &s.Writing, &s.Waiting); err != nil {
+		return nil, fmt.Errorf("第 4 行: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,273 @@
+package nginx
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 站点模板
+const (
+	TemplateStatic       = "static"
+	TemplateReverseProxy = "reverse-proxy"
+	TemplatePHP          = "php"
+	TemplateRedirect     = "redirect"
+)
+
+var (
+	// serverNamePattern 域名、*.example.com、.example.com 或 _，不支持正则形式的 server_name
+	serverNamePattern = regexp.MustCompile(`^(_|[*.]?[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(\.\*)?)$`)
+	// sizePattern client_max_body_size 的取值，如 10m
+	sizePattern = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+	// fastcgiPattern PHP-FPM 地址，unix:/path 或 host:port
+	fastcgiPattern = regexp.MustCompile(`^(unix:/[^\s;{}"'$]+|[a-zA-Z0-9.-]+:[0-9]{1,5}|\[[0-9a-fA-F:]+\]:[0-9]{1,5})$`)
+)
+
+// Spec 按模板生成站点配置的参数
+type Spec struct {
+	Template          string   `json:"template"`
+	ServerNames       []string `json:"server_names"`
+	Listen            int      `json:"listen,omitempty"`      // 默认 80，启用 SSL 时为 443
+	IPv6              bool     `json:"ipv6,omitempty"`        // 同时监听 IPv6
+	Root              string   `json:"root,omitempty"`        // static、php 的站点目录
+	Index             string   `json:"index,omitempty"`       // 默认 index.html index.htm，php 为 index.php index.html
+	Upstream          string   `json:"upstream,omitempty"`    // reverse-proxy 的后端地址，如 http://127.0.0.1:3000
+	WebSocket         bool     `json:"websocket,omitempty"`   // reverse-proxy 转发 WebSocket 升级请求
+	FastCGI           string   `json:"fastcgi,omitempty"`     // php 的 PHP-FPM 地址，如 unix:/run/php/php-fpm.sock
+	RedirectTo        string   `json:"redirect_to,omitempty"` // redirect 的目标地址，请求路径附加在其后
+	ClientMaxBodySize string   `json:"client_max_body_size,omitempty"`
+	SSL               *SSL     `json:"ssl,omitempty"`
+}
+
+// SSL 站点证书
+type SSL struct {
+	Certificate    string `json:"certificate"` // 证书链文件（PEM）
+	CertificateKey string `json:"certificate_key"`
+	HTTP2          bool   `json:"http2,omitempty"`
+	RedirectHTTP   bool   `json:"redirect_http,omitempty"` // 另加监听 80 端口的 server，把 HTTP 请求重定向到 HTTPS
+}
+
+// TemplateInfo 模板说明，供界面生成表单
+type TemplateInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    []string `json:"required"` // 除 server_names 外的必填参数
+}
+
+// Templates 可用的站点模板
+var Templates = []TemplateInfo{
+	{Name: TemplateStatic, Description: "静态网站", Required: []string{"root"}},
+	{Name: TemplateReverseProxy, Description: "反向代理到后端服务", Required: []string{"upstream"}},
+	{Name: TemplatePHP, Description: "PHP 网站（PHP-FPM）", Required: []string{"root", "fastcgi"}},
+	{Name: TemplateRedirect, Description: "重定向到其他地址", Required: []string{"redirect_to"}},
+}
+
+// validate 逐项校验参数；参数原样写入配置，含 ; { } 引号、空白或变量的值会改变配置结构，一律拒绝
+func (s *Spec) validate() error {
+	var fields []errcode.FieldViolation
+	add := func(field, format string, args ...any) {
+		fields = append(fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+	path := func(field, value string) {
+		if !filepath.IsAbs(value) || !safeValue(value) {
+			add(field, "必须是不含空白、引号、; { } 和 $ 的绝对路径")
+		}
+	}
+
+	if len(s.ServerNames) == 0 {
+		add("server_names", "至少需要一个域名")
+	}
+	for i, name := range s.ServerNames {
+		if !serverNamePattern.MatchString(name) {
+			add(fmt.Sprintf("server_names[%d]", i), "无效的域名 %q", name)
+		}
+	}
+	if s.Listen < 0 || s.Listen > 65535 {
+		add("listen", "无效的端口")
+	}
+	if s.Index != "" && !safeValue(strings.ReplaceAll(s.Index, " ", "")) {
+		add("index", "包含不允许的字符")
+	}
+	if s.ClientMaxBodySize != "" && !sizePattern.MatchString(s.ClientMaxBodySize) {
+		add("client_max_body_size", "应为数字加可选的 k、m、g 单位")
+	}
+
+	switch s.Template {
+	case TemplateStatic:
+		path("root", s.Root)
+	case TemplateReverseProxy:
+		if u, err := url.Parse(s.Upstream); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !safeValue(s.Upstream) {
+			add("upstream", "必须是 http 或 https 地址")
+		}
+	case TemplatePHP:
+		path("root", s.Root)
+		if !fastcgiPattern.MatchString(s.FastCGI) {
+			add("fastcgi", "应为 unix:/path/to/php-fpm.sock 或 host:port")
+		}
+	case TemplateRedirect:
+		if u, err := url.Parse(s.RedirectTo); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !safeValue(s.RedirectTo) {
+			add("redirect_to", "必须是 http 或 https 地址")
+		}
+	default:
+		add("template", "未知的模板 %q", s.Template)
+	}
+
+	if s.SSL != nil {
+		path("ssl.certificate", s.SSL.Certificate)
+		path("ssl.certificate_key", s.SSL.CertificateKey)
+		if s.SSL.RedirectHTTP && s.listen() == 80 {
+			add("ssl.redirect_http", "HTTPS 监听 80 端口时不能同时重定向 HTTP")
+		}
+	}
+
+	if len(fields) > 0 {
+		return errcode.Invalid(errcode.ValidationFailed, "站点参数无效", fields)
+	}
+	return nil
+}
+
+// listen 监听端口
+func (s *Spec) listen() int {
+	switch {
+	case s.Listen > 0:
+		return s.Listen
+	case s.SSL != nil:
+		return 443
+	default:
+		return 80
+	}
+}
+
+// safeValue 值可以不加引号写入配置
+func safeValue(v string) bool {
+	return v != "" && !strings.ContainsAny(v, " \t\r\n;{}\"'\\$#")
+}
+
+// siteTemplate 所有模板共用的 server 框架，各模板的 body 在其中展开
+const siteTemplate = `# 由 Runixo 管理的站点 {{.Name}}，请通过 Agent 修改，手动修改会在下次编辑时被覆盖
+{{- if and .SSL .SSL.RedirectHTTP}}
+server {
+    listen 80;
+{{- if .IPv6}}
+    listen [::]:80;
+{{- end}}
+    server_name {{.ServerNames}};
+    return 301 https://$host$request_uri;
+}
+{{- end}}
+
+server {
+    listen {{.Listen}}{{if .SSL}} ssl{{if .SSL.HTTP2}} http2{{end}}{{end}};
+{{- if .IPv6}}
+    listen [::]:{{.Listen}}{{if .SSL}} ssl{{if .SSL.HTTP2}} http2{{end}}{{end}};
+{{- end}}
+    server_name {{.ServerNames}};
+{{- if .SSL}}
+
+    ssl_certificate {{.SSL.Certificate}};
+    ssl_certificate_key {{.SSL.CertificateKey}};
+    ssl_protocols TLSv1.2 TLSv1.3;
+    ssl_session_cache shared:SSL:10m;
+    ssl_session_timeout 1d;
+{{- end}}
+{{- if .ClientMaxBodySize}}
+
+    client_max_body_size {{.ClientMaxBodySize}};
+{{- end}}
+{{template "body" .}}
+}
+`
+
+// templateBodies 各模板 server 块的主体
+var templateBodies = map[string]string{
+	TemplateStatic: `
+    root {{.Root}};
+    index {{.Index}};
+
+    location / {
+        try_files $uri $uri/ =404;
+    }`,
+	TemplateReverseProxy: `
+    location / {
+        proxy_pass {{.Upstream}};
+        proxy_http_version 1.1;
+        proxy_set_header Host $host;
+        proxy_set_header X-Real-IP $remote_addr;
+        proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+        proxy_set_header X-Forwarded-Proto $scheme;
+{{- if .WebSocket}}
+        proxy_set_header Upgrade $http_upgrade;
+        proxy_set_header Connection "upgrade";
+        proxy_read_timeout 3600s;
+{{- end}}
+    }`,
+	TemplatePHP: `
+    root {{.Root}};
+    index {{.Index}};
+
+    location / {
+        try_files $uri $uri/ /index.php?$query_string;
+    }
+
+    location ~ \.php$ {
+        try_files $uri =404;
+        include fastcgi_params;
+        fastcgi_param SCRIPT_FILENAME $document_root$fastcgi_script_name;
+        fastcgi_pass {{.FastCGI}};
+    }
+
+    location ~ /\.(?!well-known) {
+        deny all;
+    }`,
+	TemplateRedirect: `
+    return 301 {{.RedirectTo}}$request_uri;`,
+}
+
+// templateData 渲染模板的数据，ServerNames 已拼接
+type templateData struct {
+	Spec
+	Name        string
+	ServerNames string
+	Listen      int
+	Index       string
+}
+
+// render 按模板生成站点配置，参数须已校验
+func render(name string, spec *Spec) ([]byte, error) {
+	body, ok := templateBodies[spec.Template]
+	if !ok {
+		return nil, errcode.New(errcode.InvalidArgument, "未知的模板 %q", spec.Template)
+	}
+	t, err := template.New("site").Parse(siteTemplate)
+	if err == nil {
+		_, err = t.New("body").Parse(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := templateData{
+		Spec:        *spec,
+		Name:        name,
+		ServerNames: strings.Join(spec.ServerNames, " "),
+		Listen:      spec.listen(),
+		Index:       spec.Index,
+	}
+	if data.Index == "" {
+		data.Index = "index.html index.htm"
+		if spec.Template == TemplatePHP {
+			data.Index = "index.php index.html"
+		}
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
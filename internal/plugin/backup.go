@@ -0,0 +1,217 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/backup"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/scheduler"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+// 备份插件注册的指标，名称被加上 plugin_backup_manager_ 前缀
+var backupMetrics = []pluginsdk.Metric{
+	{Name: "last_success_timestamp", Help: "最近一次成功备份的时间（Unix 秒）"},
+	{Name: "last_size_bytes", Help: "最近一次成功备份的大小"},
+	{Name: "runs_total", Help: "备份次数", Type: pluginsdk.MetricCounter},
+	{Name: "failures_total", Help: "失败的备份次数", Type: pluginsdk.MetricCounter},
+}
+
+// BackupPlugin 自动备份插件，按配置的任务定时备份文件和数据库，备份逻辑在 internal/backup 中
+//
+// 插件通过 HTTP 接口（PluginHTTPPrefix 下，见 backup.Manager.Handler）提供备份列表、手动运行、校验和恢复。
+// 读取备份路径和本地存储目录需要 file.read 权限，写入本地存储目录和恢复文件需要 file.write 权限，
+// 执行 mysqldump、pg_dump、sftp 等命令需要 exec 权限，访问 S3 和 SFTP 需要 network 权限。
+type BackupPlugin struct {
+	pluginsDir string
+	pluginID   string
+	broker     *Broker // 由 Manager 注入
+	manager    *backup.Manager
+	handler    http.Handler
+	running    bool
+	mu         sync.RWMutex
+}
+
+// NewBackupPlugin 创建备份插件
+func NewBackupPlugin(pluginsDir, pluginID string) (*BackupPlugin, error) {
+	return &BackupPlugin{
+		pluginsDir: pluginsDir,
+		pluginID:   pluginID,
+	}, nil
+}
+
+// Start 启动备份插件，为配置了 schedule 的任务注册定时任务
+func (p *BackupPlugin) Start(ctx context.Context, config map[string]any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg, err := backup.ParseConfig(config)
+	if err != nil {
+		return err
+	}
+	for _, m := range backupMetrics {
+		if err := p.broker.RegisterMetric(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	p.manager = backup.NewManager(p.env(), cfg)
+	p.handler = p.manager.Handler(PluginHTTPPrefix(p.pluginID))
+	if err := p.scheduleJobs(cfg, nil); err != nil {
+		p.manager.Close()
+		p.manager = nil
+		return err
+	}
+	p.running = true
+
+	log.Info().Str("plugin", p.pluginID).Int("jobs", len(cfg.Jobs)).Msg("备份插件已启动")
+	return nil
+}
+
+// Stop 停止备份插件，取消进行中的备份操作；定时任务由 Manager 在停止插件前移除
+func (p *BackupPlugin) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager != nil {
+		p.manager.Close()
+	}
+	p.handler = nil
+	p.running = false
+	log.Info().Str("plugin", p.pluginID).Msg("备份插件已停止")
+	return nil
+}
+
+// GetStatus 获取状态
+func (p *BackupPlugin) GetStatus() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := map[string]string{
+		"running": fmt.Sprintf("%v", p.running),
+	}
+	if p.manager != nil {
+		config := p.manager.Config()
+		status["jobs"] = fmt.Sprintf("%d", len(config.Jobs))
+		status["targets"] = fmt.Sprintf("%d", len(config.Targets))
+	}
+	return status
+}
+
+// HealthCheck 健康检查，备份失败通过任务状态和指标反映，不影响插件健康
+func (p *BackupPlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.running {
+		return fmt.Errorf("插件未运行")
+	}
+	return nil
+}
+
+// UpdateConfig 应用新配置：更新任务和存储目标，按新的 schedule 重新注册定时任务
+func (p *BackupPlugin) UpdateConfig(ctx context.Context, change pluginsdk.ConfigChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager == nil {
+		return errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	cfg, err := backup.ParseConfig(change.New)
+	if err != nil {
+		return err
+	}
+	old := p.manager.Config()
+	p.manager.SetConfig(cfg)
+	return p.scheduleJobs(cfg, old)
+}
+
+// ServeHTTP 处理 REST API 转发的请求
+func (p *BackupPlugin) ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+
+	if handler == nil {
+		return nil, errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	return pluginsdk.ServeHTTPRequest(ctx, handler, req)
+}
+
+// scheduleJobs 为配置了 schedule 的任务注册定时任务，移除 old 中已删除或不再定时的任务（需要持有锁）
+func (p *BackupPlugin) scheduleJobs(cfg, old *backup.Config) error {
+	if old != nil {
+		for name := range old.Jobs {
+			if job, ok := cfg.Jobs[name]; !ok || job.Schedule == "" {
+				p.broker.Unschedule(context.Background(), name)
+			}
+		}
+	}
+
+	manager := p.manager
+	for name, job := range cfg.Jobs {
+		if job.Schedule == "" {
+			continue
+		}
+		spec := scheduler.Spec{
+			Cron:      job.Schedule,
+			Timezone:  job.Timezone,
+			MissedRun: scheduler.MissedRunOnce,
+		}
+		err := p.broker.ScheduleFunc(name, spec, func(ctx context.Context, _ time.Time) error {
+			_, err := manager.Run(ctx, name)
+			return err
+		})
+		if err != nil {
+			return errcode.Wrap(errcode.Of(err), err, fmt.Sprintf("注册备份任务 %s 失败", name))
+		}
+	}
+	return nil
+}
+
+// env 按插件权限访问文件、命令和网络的备份环境
+func (p *BackupPlugin) env() backup.Env {
+	b := p.broker
+	return backup.Env{
+		DataDir: b.dataDir,
+		// 备份可能持续很久，由操作的超时而不是客户端超时限制
+		HTTPClient:   &http.Client{Transport: b.HTTPClient().Transport},
+		Store:        b,
+		LookPath:     b.lookPath,
+		CheckRead:    func(path string) error { return p.broker.checkPath(PermFileRead, path) },
+		CheckWrite:   func(path string) error { return p.broker.checkPath(PermFileWrite, path) },
+		CheckNetwork: func(hostport string) error { return b.Check(PermNetwork, hostport) },
+		OnResult:     p.recordResult,
+	}
+}
+
+// recordResult 更新备份指标，声明了 events.publish 权限时发布 backup.<操作>.<succeeded|failed> 事件
+func (p *BackupPlugin) recordResult(op *backup.Operation, err error) {
+	ctx := context.Background()
+	if op.Type == backup.OpBackup {
+		labels := map[string]string{"job": op.Job}
+		samples := []pluginsdk.MetricSample{{Name: "runs_total", Labels: labels, Value: 1}}
+		if err != nil {
+			samples = append(samples, pluginsdk.MetricSample{Name: "failures_total", Labels: labels, Value: 1})
+		} else if b, ok := op.Result.(*backup.Backup); ok {
+			samples = append(samples,
+				pluginsdk.MetricSample{Name: "last_success_timestamp", Labels: labels, Value: float64(b.CreatedAt.Unix())},
+				pluginsdk.MetricSample{Name: "last_size_bytes", Labels: labels, Value: float64(b.Size)},
+			)
+		}
+		if err := p.broker.RecordMetrics(ctx, samples...); err != nil {
+			log.Debug().Err(err).Msg("记录备份指标失败")
+		}
+	}
+
+	if p.broker.granted(PermEventsPublish) {
+		if err := p.broker.Publish(ctx, "backup."+op.Type+"."+op.Status, op); err != nil {
+			log.Debug().Err(err).Msg("发布备份事件失败")
+		}
+	}
+}
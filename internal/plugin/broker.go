@@ -0,0 +1,613 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/scheduler"
+	"github.com/runixo/agent/internal/webhook"
+	"github.com/runixo/agent/pkg/pluginsdk"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+const (
+	// brokerMaxPayload 经 Broker 读写的文件、命令输出和 HTTP 响应的大小上限，低于 gRPC 默认的 4 MB 消息上限
+	brokerMaxPayload = 3 << 20
+	// brokerExecTimeout 插件执行命令的默认超时和最大超时
+	brokerExecTimeout    = 30 * time.Second
+	brokerMaxExecTimeout = 10 * time.Minute
+	// brokerFetchTimeout 插件 HTTP 请求的超时
+	brokerFetchTimeout = 60 * time.Second
+	// maxRecentDenials 插件状态中保留的最近拒绝记录数
+	maxRecentDenials = 20
+	// maxPluginTasks 每个插件最多注册的定时任务数
+	maxPluginTasks = 32
+	// brokerScheduleTimeout 插件定时任务单次运行的默认超时
+	brokerScheduleTimeout = 10 * time.Minute
+)
+
+// PermissionDenial 一次被拒绝的插件访问
+type PermissionDenial struct {
+	Permission string    `json:"permission"`
+	Resource   string    `json:"resource,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Broker 插件访问文件、网络、命令和 Agent 接口的入口，按清单声明的权限放行
+//
+// 外部插件通过 Host 服务调用 Broker，进程内插件直接调用 Check。插件数据目录内的文件、插件自己的 KV 存储和指标无需声明权限，
+// 插件目录的其余部分只读，其他插件的目录和 Agent 的受保护路径始终拒绝访问。
+// Broker 只约束经由 Agent 的访问，外部插件进程自身的文件访问由 IsolationConfig 约束。
+type Broker struct {
+	pluginID  string
+	dir       string
+	dataDir   string
+	protected []string            // 解析过符号链接的受保护路径
+	uid       int                 // 外部插件进程的 uid，非 0 时写入数据目录的文件归该 uid 所有
+	grants    map[string][]string // 权限 -> 范围，空范围表示不限
+	bus       *eventbus.Bus
+	storage   *pluginStorage
+	metrics   *pluginMetrics
+	scheduler *scheduler.Scheduler
+	trace     *pluginTrace // 记录插件发布的事件，用于诊断
+	onDeny    func(pluginID string, d PermissionDenial)
+
+	mu         sync.Mutex
+	denials    int
+	recent     []PermissionDenial
+	onSchedule func(ctx context.Context, name string, scheduled time.Time) error // 外部插件的 OnSchedule
+}
+
+// newBroker 按清单声明的权限创建 Broker，onDeny 在每次拒绝访问时调用
+func newBroker(pluginsDir string, protected []string, manifest *PluginManifest, bus *eventbus.Bus, onDeny func(string, PermissionDenial)) *Broker {
+	b := &Broker{
+		pluginID:  manifest.ID,
+		dir:       filepath.Join(pluginsDir, manifest.ID),
+		dataDir:   pluginDataDir(pluginsDir, manifest.ID),
+		protected: protected,
+		grants:    make(map[string][]string),
+		bus:       bus,
+		metrics:   newPluginMetrics(manifest.ID),
+		onDeny:    onDeny,
+	}
+	for _, p := range manifest.Permissions {
+		name, scope, _ := strings.Cut(p, ":")
+		b.grants[name] = append(b.grants[name], scope)
+	}
+	return b
+}
+
+// granted 清单是否声明了权限（任意范围），不记录拒绝
+func (b *Broker) granted(perm string) bool {
+	_, ok := b.grants[perm]
+	return ok
+}
+
+// Check 检查插件是否声明了访问 resource 所需的权限，未声明时记录并返回 PERMISSION_DENIED
+// resource 对文件权限是绝对路径，对 exec 是命令名或路径（应通过 lookPath 检查），对 network 是 host 或 host:port
+func (b *Broker) Check(perm, resource string) error {
+	for _, scope := range b.grants[perm] {
+		if scopeAllows(perm, scope, resource) {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("插件 %s 未声明权限 %s", b.pluginID, perm)
+	if resource != "" {
+		msg += fmt.Sprintf("（访问 %s）", resource)
+	}
+	return b.deny(perm, resource, msg)
+}
+
+// deny 记录一次拒绝访问并返回 PERMISSION_DENIED
+func (b *Broker) deny(perm, resource, msg string) error {
+	d := PermissionDenial{Permission: perm, Resource: resource, Time: time.Now()}
+	b.mu.Lock()
+	b.denials++
+	b.recent = append(b.recent, d)
+	if len(b.recent) > maxRecentDenials {
+		b.recent = b.recent[len(b.recent)-maxRecentDenials:]
+	}
+	b.mu.Unlock()
+	if b.onDeny != nil {
+		b.onDeny(b.pluginID, d)
+	}
+	return errcode.Wrap(errcode.PermissionDenied, pluginsdk.ErrPermissionDenied, msg)
+}
+
+// Denials 被拒绝的访问次数和最近的拒绝记录
+func (b *Broker) Denials() (int, []PermissionDenial) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.denials, append([]PermissionDenial(nil), b.recent...)
+}
+
+// scopeAllows 判断权限范围是否覆盖 resource
+func scopeAllows(perm, scope, resource string) bool {
+	if scope == "" {
+		return true
+	}
+	switch perm {
+	case PermFileRead, PermFileWrite:
+		return resource == scope || strings.HasPrefix(resource, strings.TrimSuffix(scope, "/")+"/")
+	case PermExec:
+		// 命令名范围只匹配按 PATH 查找的命令名，路径范围只匹配该路径，见 lookPath
+		return resource == scope
+	case PermNetwork:
+		// 范围带端口时只允许该端口
+		if _, _, err := net.SplitHostPort(scope); err == nil {
+			return strings.EqualFold(resource, scope)
+		}
+		hostname := resource
+		if h, _, err := net.SplitHostPort(resource); err == nil {
+			hostname = h
+		}
+		if suffix, ok := strings.CutPrefix(scope, "*."); ok {
+			return strings.HasSuffix(strings.ToLower(hostname), "."+strings.ToLower(suffix))
+		}
+		return strings.EqualFold(hostname, scope)
+	}
+	return false
+}
+
+// resolvePath 把插件传入的路径转为绝对路径并解析符号链接，防止借助链接绕过范围检查
+// 路径不存在时解析其所在目录
+func (b *Broker) resolvePath(path string) (string, error) {
+	if path == "" {
+		return "", errcode.New(errcode.InvalidArgument, "路径不能为空")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(b.dataDir, path)
+	}
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
+// checkFile 数据目录内的文件无需声明权限，插件目录的其余部分只读，
+// 受保护的路径（其他插件的目录、Agent 的配置和数据）即使声明了文件权限也拒绝访问
+func (b *Broker) checkFile(perm, path string) error {
+	if pathWithin(path, resolvedPath(b.dataDir)) {
+		return nil
+	}
+	if pathWithin(path, resolvedPath(b.dir)) {
+		if perm == PermFileRead {
+			return nil
+		}
+		return b.deny(perm, path, fmt.Sprintf("插件 %s 的插件目录只读，只能写入数据目录（访问 %s）", b.pluginID, path))
+	}
+	for _, p := range b.protected {
+		if pathWithin(path, p) {
+			return b.deny(perm, path, fmt.Sprintf("插件 %s 不能访问受保护的路径 %s", b.pluginID, path))
+		}
+	}
+	return b.Check(perm, path)
+}
+
+// checkPath 解析符号链接后检查文件权限，供进程内插件在访问文件前调用；
+// 路径尚不存在时按最近的已存在上级目录解析（如写入新目录下的文件）
+func (b *Broker) checkPath(perm, path string) error {
+	path = filepath.Clean(path)
+	var rest []string
+	for {
+		resolved, err := b.resolvePath(path)
+		if err == nil {
+			return b.checkFile(perm, filepath.Join(append([]string{resolved}, rest...)...))
+		}
+		parent := filepath.Dir(path)
+		if !os.IsNotExist(err) || parent == path {
+			return err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// Publish 向事件总线发布事件，主题被限定在 plugin.<插件 ID>. 之下，
+// 插件无法冒充核心模块的事件（如 alert、ip.blocked）
+func (b *Broker) Publish(_ context.Context, topic string, data any) error {
+	if topic == "" || strings.Contains(topic, "*") {
+		return errcode.New(errcode.InvalidArgument, "无效的事件主题: %q", topic)
+	}
+	if err := b.Check(PermEventsPublish, ""); err != nil {
+		return err
+	}
+	if b.bus == nil {
+		return nil
+	}
+	e := eventbus.Event{
+		Topic:  "plugin." + b.pluginID + "." + topic,
+		Source: "plugin:" + b.pluginID,
+		Data:   data,
+	}
+	if b.trace != nil {
+		b.trace.recordEvent(TracePublished, e)
+	}
+	b.bus.PublishEvent(e)
+	return nil
+}
+
+// Alert 发出核心告警事件（alert 主题），与 Agent 自身的告警一样经 webhook 等通知渠道送达。
+// data 中的 source 和 plugin_id 由 Broker 设置，插件不能冒充其他来源
+func (b *Broker) Alert(_ context.Context, data map[string]any) error {
+	if err := b.Check(PermAlertsPublish, ""); err != nil {
+		return err
+	}
+	if b.bus == nil {
+		return nil
+	}
+	payload := make(map[string]any, len(data)+2)
+	for k, v := range data {
+		payload[k] = v
+	}
+	payload["source"] = "plugin"
+	payload["plugin_id"] = b.pluginID
+	e := eventbus.Event{
+		Topic:  webhook.EventAlert,
+		Source: "plugin:" + b.pluginID,
+		Data:   payload,
+	}
+	if b.trace != nil {
+		b.trace.recordEvent(TracePublished, e)
+	}
+	b.bus.PublishEvent(e)
+	return nil
+}
+
+// ReadFile 读取文件
+func (b *Broker) ReadFile(_ context.Context, path string) ([]byte, error) {
+	path, err := b.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkFile(PermFileRead, path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, brokerMaxPayload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > brokerMaxPayload {
+		return nil, errcode.New(errcode.InvalidArgument, "文件超过 %d MB", brokerMaxPayload>>20)
+	}
+	return data, nil
+}
+
+// WriteFile 先写入临时文件再重命名，已存在的文件保留原有权限
+func (b *Broker) WriteFile(_ context.Context, path string, data []byte) error {
+	if len(data) > brokerMaxPayload {
+		return errcode.New(errcode.InvalidArgument, "写入内容超过 %d MB", brokerMaxPayload>>20)
+	}
+	path, err := b.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if err := b.checkFile(PermFileWrite, path); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := writeFileAtomic(path, data, mode); err != nil {
+		return err
+	}
+	if b.uid != 0 && pathWithin(path, resolvedPath(b.dataDir)) {
+		return os.Lchown(path, b.uid, b.uid)
+	}
+	return nil
+}
+
+// lookPath 查找插件要执行的命令并检查 exec 权限，name 为 PATH 中的命令名或绝对路径。
+// 命令名范围（如 exec:curl）只匹配按 PATH 查找的命令名，不匹配同名的其他路径；
+// 插件目录和数据目录中的文件由插件控制，即使声明了权限也不能经由 Agent 执行
+func (b *Broker) lookPath(name string) (string, error) {
+	if name == "" {
+		return "", errcode.New(errcode.InvalidArgument, "命令不能为空")
+	}
+	bare := filepath.Base(name) == name && !strings.Contains(name, "/")
+	if !bare && !filepath.IsAbs(name) {
+		return "", errcode.New(errcode.InvalidArgument, "命令必须是命令名或绝对路径: %s", name)
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", errcode.Wrap(errcode.NotFound, err, fmt.Sprintf("命令 %s 不存在", name))
+	}
+
+	resolved := resolvedPath(path)
+	for _, dir := range []string{b.dir, b.dataDir} {
+		if dir := resolvedPath(dir); pathWithin(path, dir) || pathWithin(resolved, dir) {
+			return "", b.deny(PermExec, path, fmt.Sprintf("插件 %s 不能执行插件目录中的文件 %s", b.pluginID, path))
+		}
+	}
+	for _, scope := range b.grants[PermExec] {
+		if scopeAllows(PermExec, scope, path) || (bare && scopeAllows(PermExec, scope, name)) {
+			return path, nil
+		}
+	}
+	return "", b.Check(PermExec, path)
+}
+
+// Exec 执行命令，输出超过上限的部分被截断。启用 uid 隔离时命令以插件进程的 uid 运行
+func (b *Broker) Exec(ctx context.Context, req pluginsdk.ExecRequest) (*pluginsdk.ExecResult, error) {
+	path, err := b.lookPath(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = brokerExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, min(timeout, brokerMaxExecTimeout))
+	defer cancel()
+
+	var stdout, stderr limitedBuffer
+	cmd := exec.CommandContext(ctx, path, req.Args...)
+	cmd.Dir = b.dataDir
+	setCommandUID(cmd, b.uid)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("执行命令失败: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("命令执行超时")
+	}
+	return &pluginsdk.ExecResult{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+	}, nil
+}
+
+// Fetch 发起 HTTP 请求，重定向到的主机同样需要权限
+func (b *Broker) Fetch(ctx context.Context, req pluginsdk.FetchRequest) (*pluginsdk.FetchResponse, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "无效的请求")
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := b.HTTPClient().Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, brokerMaxPayload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > brokerMaxPayload {
+		return nil, errcode.New(errcode.InvalidArgument, "响应超过 %d MB", brokerMaxPayload>>20)
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for k := range resp.Header {
+		headers[k] = resp.Header.Get(k)
+	}
+	return &pluginsdk.FetchResponse{StatusCode: resp.StatusCode, Headers: headers, Body: body}, nil
+}
+
+// HTTPClient 每个请求（包括重定向）都检查 network 权限的 HTTP 客户端，供进程内插件使用
+func (b *Broker) HTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   brokerFetchTimeout,
+		Transport: &brokerTransport{broker: b, next: http.DefaultTransport},
+	}
+}
+
+type brokerTransport struct {
+	broker *Broker
+	next   http.RoundTripper
+}
+
+func (t *brokerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的协议: %s", req.URL.Scheme)
+	}
+	host := req.URL.Host
+	if req.URL.Port() == "" {
+		port := "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(req.URL.Hostname(), port)
+	}
+	if err := t.broker.Check(PermNetwork, host); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// SystemInfo 读取主机信息和当前负载
+func (b *Broker) SystemInfo(ctx context.Context) (*pluginsdk.SystemInfo, error) {
+	if err := b.Check(PermSystemRead, ""); err != nil {
+		return nil, err
+	}
+	hi, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &pluginsdk.SystemInfo{
+		Hostname:        hi.Hostname,
+		OS:              hi.OS,
+		Platform:        hi.Platform,
+		PlatformVersion: hi.PlatformVersion,
+		KernelVersion:   hi.KernelVersion,
+		Uptime:          hi.Uptime,
+	}
+	if percents, err := cpu.PercentWithContext(ctx, 0, false); err == nil && len(percents) > 0 {
+		info.CPUPercent = percents[0]
+	}
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		info.MemoryTotal = vm.Total
+		info.MemoryUsed = vm.Used
+	}
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		info.Load1 = avg.Load1
+	}
+	return info, nil
+}
+
+// StorageGet 读取插件 KV 存储中的值
+func (b *Broker) StorageGet(_ context.Context, key string) ([]byte, bool, error) {
+	return b.storage.Get(key)
+}
+
+// StorageSet 写入插件 KV 存储
+func (b *Broker) StorageSet(_ context.Context, key string, value []byte) error {
+	return b.storage.Set(key, value)
+}
+
+// StorageDelete 删除插件 KV 存储中的键
+func (b *Broker) StorageDelete(_ context.Context, key string) error {
+	return b.storage.Delete(key)
+}
+
+// StorageList 列出插件 KV 存储中以 prefix 开头的键
+func (b *Broker) StorageList(_ context.Context, prefix string) ([]string, error) {
+	return b.storage.List(prefix)
+}
+
+// RegisterMetric 注册插件指标，指标进入 GetMetrics、指标历史和 Prometheus 接口
+func (b *Broker) RegisterMetric(_ context.Context, m pluginsdk.Metric) error {
+	return b.metrics.register(m)
+}
+
+// RecordMetrics 上报插件指标的样本
+func (b *Broker) RecordMetrics(_ context.Context, samples ...pluginsdk.MetricSample) error {
+	return b.metrics.record(samples)
+}
+
+// Schedule 注册定时任务，到期时调用外部插件的 OnSchedule
+func (b *Broker) Schedule(_ context.Context, spec pluginsdk.ScheduleSpec) error {
+	if spec.Timeout == 0 {
+		spec.Timeout = brokerScheduleTimeout
+	}
+	name := spec.Name
+	return b.ScheduleFunc(name, scheduler.Spec{
+		Cron:      spec.Cron,
+		Timezone:  spec.Timezone,
+		Jitter:    spec.Jitter,
+		MissedRun: spec.MissedRun,
+		Timeout:   spec.Timeout,
+	}, func(ctx context.Context, scheduled time.Time) error {
+		b.mu.Lock()
+		handler := b.onSchedule
+		b.mu.Unlock()
+		if handler == nil {
+			return fmt.Errorf("插件未运行")
+		}
+		return handler(ctx, name, scheduled)
+	})
+}
+
+// ScheduleFunc 注册定时任务，供进程内插件使用；任务 ID 为 <插件 ID>/<name>，插件停止时移除
+func (b *Broker) ScheduleFunc(name string, spec scheduler.Spec, fn scheduler.Func) error {
+	if b.scheduler == nil {
+		return errcode.New(errcode.Unavailable, "Agent 未启用定时任务调度")
+	}
+	if !pluginIDPattern.MatchString(name) {
+		return errcode.New(errcode.InvalidArgument, "无效的任务名: %q", name)
+	}
+	id := b.pluginID + "/" + name
+	tasks := b.scheduler.Tasks(b.pluginID)
+	if len(tasks) >= maxPluginTasks && !slices.ContainsFunc(tasks, func(t scheduler.TaskInfo) bool { return t.ID == id }) {
+		return errcode.New(errcode.InvalidArgument, "插件最多注册 %d 个定时任务", maxPluginTasks)
+	}
+	return b.scheduler.Add(id, b.pluginID, spec, fn)
+}
+
+// Unschedule 移除定时任务
+func (b *Broker) Unschedule(_ context.Context, name string) error {
+	if b.scheduler != nil {
+		b.scheduler.Remove(b.pluginID + "/" + name)
+	}
+	return nil
+}
+
+// setScheduleHandler 设置外部插件接收定时任务的函数
+func (b *Broker) setScheduleHandler(h func(ctx context.Context, name string, scheduled time.Time) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onSchedule = h
+}
+
+// release 插件停止后注销插件指标、移除插件的定时任务
+func (b *Broker) release() {
+	b.metrics.close()
+	if b.scheduler != nil {
+		b.scheduler.RemoveOwner(b.pluginID)
+	}
+}
+
+// limitedBuffer 超过 brokerMaxPayload 后丢弃写入的内容
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := brokerMaxPayload - b.Len(); room < len(p) {
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// recordDenial 记录插件越权访问：写日志、审计日志并发布事件
+func (m *Manager) recordDenial(pluginID string, d PermissionDenial) {
+	log.Warn().Str("plugin", pluginID).Str("permission", d.Permission).Str("resource", d.Resource).Msg("拒绝插件未声明权限的访问")
+	if logger := m.auditLogger(); logger != nil {
+		logger.LogPluginPermission(pluginID, d.Permission, d.Resource)
+	}
+	m.publish(eventbus.TopicPermissionDenied, map[string]any{
+		"plugin_id":  pluginID,
+		"permission": d.Permission,
+		"resource":   d.Resource,
+	})
+}
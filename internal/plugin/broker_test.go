@@ -0,0 +1,210 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+// newTestBroker 在临时插件目录中创建声明了 permissions 的 Broker
+func newTestBroker(t *testing.T, protected []string, permissions ...string) *Broker {
+	t.Helper()
+	pluginsDir := resolvedPath(t.TempDir())
+	manifest := &PluginManifest{ID: "test-plugin", Permissions: permissions}
+	b := newBroker(pluginsDir, protected, manifest, nil, nil)
+	if err := os.MkdirAll(b.dataDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func denied(err error) bool {
+	return errors.Is(err, pluginsdk.ErrPermissionDenied)
+}
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		perm, scope, resource string
+		want                  bool
+	}{
+		{PermFileRead, "", "/etc/passwd", true},
+		{PermFileRead, "/data", "/data", true},
+		{PermFileRead, "/data", "/data/app/log.txt", true},
+		{PermFileRead, "/data/", "/data/app", true},
+		{PermFileRead, "/data", "/database", false},
+		{PermFileRead, "/data", "/database/app", false},
+		{PermFileWrite, "/var/log/app", "/var/log", false},
+
+		{PermExec, "curl", "curl", true},
+		{PermExec, "curl", "/usr/bin/curl", false},
+		{PermExec, "curl", "/opt/plugins/x/data/curl", false},
+		{PermExec, "/usr/bin/curl", "/usr/bin/curl", true},
+		{PermExec, "/usr/bin/curl", "curl", false},
+		{PermExec, "/usr/bin/curl", "/usr/local/bin/curl", false},
+
+		{PermNetwork, "api.example.com", "api.example.com:443", true},
+		{PermNetwork, "api.example.com", "API.example.com:80", true},
+		{PermNetwork, "api.example.com", "evil.example.com:443", false},
+		{PermNetwork, "api.example.com:443", "api.example.com:443", true},
+		{PermNetwork, "api.example.com:443", "api.example.com:8443", false},
+		{PermNetwork, "*.example.com", "a.b.example.com:443", true},
+		{PermNetwork, "*.example.com", "example.com:443", false},
+		{PermNetwork, "*.example.com", "badexample.com:443", false},
+		{PermNetwork, "10.0.0.1", "10.0.0.1:5432", true},
+		{PermNetwork, "10.0.0.1", "10.0.0.10:5432", false},
+
+		{PermSystemRead, "anything", "anything", false},
+	}
+	for _, tt := range tests {
+		if got := scopeAllows(tt.perm, tt.scope, tt.resource); got != tt.want {
+			t.Errorf("scopeAllows(%q, %q, %q) = %v, want %v", tt.perm, tt.scope, tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestBrokerCheckFile(t *testing.T) {
+	protectedDir := resolvedPath(t.TempDir())
+	sharedDir := resolvedPath(t.TempDir())
+	b := newTestBroker(t, []string{protectedDir},
+		PermFileRead+":"+sharedDir, PermFileWrite+":"+sharedDir,
+		PermFileRead+":"+protectedDir, PermFileWrite+":"+protectedDir)
+
+	tests := []struct {
+		perm, path string
+		allowed    bool
+	}{
+		// 数据目录无需声明权限
+		{PermFileWrite, filepath.Join(b.dataDir, "state.json"), true},
+		// 插件目录的其余部分只读
+		{PermFileRead, filepath.Join(b.dir, "plugin.json"), true},
+		{PermFileWrite, filepath.Join(b.dir, "plugin.json"), false},
+		{PermFileWrite, filepath.Join(b.dir, "bin", "entry"), false},
+		// 声明了范围的路径
+		{PermFileRead, filepath.Join(sharedDir, "a.txt"), true},
+		{PermFileWrite, filepath.Join(sharedDir, "a.txt"), true},
+		{PermFileRead, sharedDir + "-other", false},
+		// 受保护的路径即使声明了权限也拒绝
+		{PermFileRead, filepath.Join(protectedDir, "config.yaml"), false},
+		{PermFileWrite, protectedDir, false},
+	}
+	for _, tt := range tests {
+		err := b.checkFile(tt.perm, tt.path)
+		if tt.allowed && err != nil {
+			t.Errorf("checkFile(%s, %s) error: %v", tt.perm, tt.path, err)
+		}
+		if !tt.allowed && !denied(err) {
+			t.Errorf("checkFile(%s, %s) = %v, want permission denied", tt.perm, tt.path, err)
+		}
+	}
+
+	// 借助数据目录中的符号链接访问受保护的路径
+	link := filepath.Join(b.dataDir, "link")
+	if err := os.Symlink(protectedDir, link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.ReadFile(context.Background(), filepath.Join("link", "config.yaml")); !denied(err) {
+		t.Errorf("ReadFile() through symlink = %v, want permission denied", err)
+	}
+
+	if count, recent := b.Denials(); count != 6 || len(recent) != 6 {
+		t.Errorf("Denials() = %d, %d records; want 6", count, len(recent))
+	}
+}
+
+func TestBrokerLookPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要可执行的 shell 脚本")
+	}
+	binDir := resolvedPath(t.TempDir())
+	tool := filepath.Join(binDir, "runixo-tool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\necho ok\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+
+	b := newTestBroker(t, nil, PermExec+":runixo-tool")
+	path, err := b.lookPath("runixo-tool")
+	if err != nil || path != tool {
+		t.Errorf("lookPath(runixo-tool) = %q, %v; want %q", path, err, tool)
+	}
+	// 命令名范围不匹配同名的路径
+	if _, err := b.lookPath(tool); !denied(err) {
+		t.Errorf("lookPath(%s) = %v, want permission denied", tool, err)
+	}
+
+	// 插件放在数据目录中的同名文件
+	planted := filepath.Join(b.dataDir, "runixo-tool")
+	if err := os.WriteFile(planted, []byte("#!/bin/sh\nid\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.lookPath("data/runixo-tool"); err == nil || denied(err) {
+		t.Errorf("lookPath(data/runixo-tool) = %v, want invalid argument", err)
+	}
+	if _, err := b.lookPath(planted); !denied(err) {
+		t.Errorf("lookPath(%s) = %v, want permission denied", planted, err)
+	}
+	// 即使声明了该路径，也不能执行插件目录和数据目录中的文件
+	b = newTestBroker(t, nil)
+	b.grants[PermExec] = []string{filepath.Join(b.dataDir, "runixo-tool"), filepath.Join(b.dir, "runixo-tool")}
+	for _, p := range []string{filepath.Join(b.dataDir, "runixo-tool"), filepath.Join(b.dir, "runixo-tool")} {
+		if err := os.WriteFile(p, []byte("#!/bin/sh\nid\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.lookPath(p); !denied(err) {
+			t.Errorf("lookPath(%s) = %v, want permission denied", p, err)
+		}
+	}
+	// PATH 中指向数据目录的符号链接
+	if err := os.Symlink(filepath.Join(b.dataDir, "runixo-tool"), filepath.Join(binDir, "linked-tool")); err != nil {
+		t.Fatal(err)
+	}
+	b.grants[PermExec] = []string{"linked-tool"}
+	if _, err := b.lookPath("linked-tool"); !denied(err) {
+		t.Errorf("lookPath(linked-tool) = %v, want permission denied", err)
+	}
+
+	// 路径范围同样匹配按 PATH 查找到的命令
+	b = newTestBroker(t, nil, PermExec+":"+tool)
+	res, err := b.Exec(context.Background(), pluginsdk.ExecRequest{Name: "runixo-tool"})
+	if err != nil || string(res.Stdout) != "ok\n" {
+		t.Errorf("Exec(runixo-tool) = %+v, %v", res, err)
+	}
+}
+
+func TestBrokerTransportDeniesRedirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer origin.Close()
+
+	u, _ := url.Parse(origin.URL)
+	b := newTestBroker(t, nil, PermNetwork+":"+u.Host)
+
+	_, err := b.Fetch(context.Background(), pluginsdk.FetchRequest{URL: origin.URL})
+	if !denied(err) {
+		t.Fatalf("Fetch() redirected to ungranted host = %v, want permission denied", err)
+	}
+	_, recent := b.Denials()
+	if target, _ := url.Parse(target.URL); len(recent) != 1 || recent[0].Resource != target.Host {
+		t.Errorf("recent denials = %+v, want %s", recent, target.Host)
+	}
+
+	// 重定向到的主机也声明了权限时正常跟随
+	b = newTestBroker(t, nil, PermNetwork+":"+u.Host, PermNetwork+":"+target.Listener.Addr().String())
+	resp, err := b.Fetch(context.Background(), pluginsdk.FetchRequest{URL: origin.URL})
+	if err != nil || string(resp.Body) != "secret" {
+		t.Errorf("Fetch() with both hosts granted = %+v, %v", resp, err)
+	}
+}
@@ -0,0 +1,335 @@
+package plugin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	// bundleFormat 插件配置导出包的格式标识
+	bundleFormat = "runixo-plugin-configs"
+	// bundleVersion 导出包格式版本，不兼容的变更时递增
+	bundleVersion = 1
+	// bundleKDFIterations 由密码派生密钥的 PBKDF2-SHA256 迭代次数
+	bundleKDFIterations = 600000
+	// maxBundleKDFIterations 导入时接受的最大迭代次数，避免恶意导出包消耗 CPU
+	maxBundleKDFIterations = 10000000
+)
+
+// 导入结果
+const (
+	ImportApplied   = "applied"   // 已导入配置
+	ImportInstalled = "installed" // 已从官方仓库安装并导入配置
+	ImportSkipped   = "skipped"   // 本机未安装，未导入
+	ImportFailed    = "failed"
+)
+
+// ConfigBundle 插件配置导出包：已安装的插件、启用状态和配置，用于迁移 Agent 或批量配置主机
+// 加密时 Plugins 以 AES-256-GCM 加密后存放在 Ciphertext 中
+type ConfigBundle struct {
+	Format       string            `json:"format"`
+	Version      int               `json:"version"`
+	ExportedAt   time.Time         `json:"exported_at"`
+	AgentVersion string            `json:"agent_version,omitempty"`
+	Encryption   *BundleEncryption `json:"encryption,omitempty"`
+	Ciphertext   []byte            `json:"ciphertext,omitempty"`
+	Plugins      []BundledPlugin   `json:"plugins,omitempty"`
+}
+
+// BundleEncryption 导出包的加密参数，密钥由密码经 PBKDF2-SHA256 派生
+type BundleEncryption struct {
+	KDF        string `json:"kdf"` // pbkdf2-sha256
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Cipher     string `json:"cipher"` // aes-256-gcm
+	Nonce      []byte `json:"nonce"`
+}
+
+// BundledPlugin 导出包中的一个插件
+type BundledPlugin struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name,omitempty"`
+	Version string         `json:"version"`
+	Enabled bool           `json:"enabled"`
+	Config  map[string]any `json:"config"`
+}
+
+// ImportOptions 导入插件配置的选项
+type ImportOptions struct {
+	Passphrase     string   // 加密导出包的密码
+	InstallMissing bool     // 从官方仓库安装本机缺少的插件，否则跳过
+	Plugins        []string // 只导入这些插件，为空时导入全部
+}
+
+// ImportResult 一个插件的导入结果
+type ImportResult struct {
+	PluginID string `json:"plugin_id"`
+	Status   string `json:"status"`            // ImportApplied、ImportInstalled、ImportSkipped 或 ImportFailed
+	Message  string `json:"message,omitempty"` // 失败原因，或版本不同等提示
+}
+
+// ExportConfigs 导出所有已安装插件的启用状态和配置
+// passphrase 不为空时加密导出包并包含敏感配置项；否则敏感配置项（含名称像凭据的配置项）替换为 SecretMask，导入时保留本机的原值
+func (m *Manager) ExportConfigs(passphrase string) ([]byte, error) {
+	m.mu.RLock()
+	bundle := ConfigBundle{
+		Format:       bundleFormat,
+		Version:      bundleVersion,
+		ExportedAt:   time.Now().UTC(),
+		AgentVersion: m.version,
+	}
+	plugins := make([]BundledPlugin, 0, len(m.plugins))
+	for id, p := range m.plugins {
+		config := p.Config
+		if passphrase == "" {
+			config = redactConfig(p.Manifest.ConfigSchema, config)
+		}
+		plugins = append(plugins, BundledPlugin{
+			ID:      id,
+			Name:    p.Manifest.Name,
+			Version: p.Manifest.Version,
+			Enabled: p.State == StateEnabled || p.State == StateError,
+			Config:  config,
+		})
+	}
+	m.mu.RUnlock()
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].ID < plugins[j].ID })
+
+	if passphrase == "" {
+		bundle.Plugins = plugins
+	} else if err := bundle.seal(plugins, passphrase); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(bundle, "", "  ")
+}
+
+// ImportConfigs 导入 ExportConfigs 生成的导出包：先安装缺少的插件（InstallMissing）并导入配置，再按导出包调整启用状态
+// 单个插件失败不影响其他插件，导出包无效或密码错误时返回错误
+func (m *Manager) ImportConfigs(data []byte, opts ImportOptions) ([]ImportResult, error) {
+	var bundle ConfigBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "导出包格式无效")
+	}
+	if bundle.Format != bundleFormat {
+		return nil, errcode.New(errcode.InvalidArgument, "不是插件配置导出包")
+	}
+	if bundle.Version != bundleVersion {
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的导出包版本 %d", bundle.Version)
+	}
+	plugins := bundle.Plugins
+	if bundle.Encryption != nil {
+		if opts.Passphrase == "" {
+			return nil, errcode.New(errcode.InvalidArgument, "导出包已加密，需要提供密码")
+		}
+		var err error
+		if plugins, err = bundle.open(opts.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	selected := make(map[string]bool, len(opts.Plugins))
+	for _, id := range opts.Plugins {
+		selected[id] = true
+	}
+
+	results := make([]ImportResult, 0, len(plugins))
+	applied := make([]BundledPlugin, 0, len(plugins))
+	for _, bp := range plugins {
+		if len(selected) > 0 && !selected[bp.ID] {
+			continue
+		}
+		result := m.importPlugin(bp, opts.InstallMissing)
+		if result.Status == ImportApplied || result.Status == ImportInstalled {
+			applied = append(applied, bp)
+		}
+		results = append(results, result)
+	}
+
+	// 依赖的插件随被依赖者一起启用，配置全部导入后再调整启用状态
+	for _, bp := range applied {
+		var err error
+		if bp.Enabled {
+			err = m.EnablePlugin(bp.ID)
+		} else {
+			err = m.DisablePlugin(bp.ID)
+		}
+		if err != nil {
+			for i := range results {
+				if results[i].PluginID == bp.ID {
+					results[i].Status = ImportFailed
+					results[i].Message = fmt.Sprintf("配置已导入，但调整启用状态失败: %v", err)
+				}
+			}
+		}
+	}
+
+	log.Info().Int("plugins", len(results)).Msg("插件配置已导入")
+	return results, nil
+}
+
+// importPlugin 导入一个插件的配置，本机未安装时按 installMissing 从官方仓库安装
+func (m *Manager) importPlugin(bp BundledPlugin, installMissing bool) ImportResult {
+	result := ImportResult{PluginID: bp.ID, Status: ImportApplied}
+	if !validPluginID.MatchString(bp.ID) {
+		result.Status, result.Message = ImportFailed, "插件 ID 无效"
+		return result
+	}
+
+	m.mu.RLock()
+	installed, exists := m.plugins[bp.ID]
+	var old map[string]any
+	var version string
+	if exists {
+		old, version = installed.Config, installed.Manifest.Version
+	}
+	m.mu.RUnlock()
+
+	if !exists {
+		if !installMissing {
+			result.Status, result.Message = ImportSkipped, "本机未安装该插件"
+			return result
+		}
+		if err := m.InstallPlugin(bp.ID, InstallRequest{Source: SourceOfficial}); err != nil {
+			result.Status, result.Message = ImportFailed, fmt.Sprintf("安装失败: %v", err)
+			return result
+		}
+		result.Status = ImportInstalled
+		if p := m.GetPlugin(bp.ID); p != nil {
+			version = p.Manifest.Version
+		}
+	}
+
+	if err := m.SetPluginConfig(bp.ID, unmaskConfig(bp.Config, old)); err != nil {
+		result.Status, result.Message = ImportFailed, fmt.Sprintf("导入配置失败: %v", err)
+		return result
+	}
+	if version != bp.Version {
+		result.Message = fmt.Sprintf("版本不同：导出时 %s，本机 %s", bp.Version, version)
+	}
+	return result
+}
+
+// unmaskConfig 把明文导出包中为 SecretMask 的值（含嵌套对象）恢复为本机的原值，本机没有时去掉该配置项
+func unmaskConfig(config, old map[string]any) map[string]any {
+	out := make(map[string]any, len(config))
+	for k, v := range config {
+		switch val := v.(type) {
+		case string:
+			if val != SecretMask {
+				out[k] = val
+			} else if ov, ok := old[k]; ok {
+				out[k] = ov
+			}
+		case map[string]any:
+			ov, _ := old[k].(map[string]any)
+			out[k] = unmaskConfig(val, ov)
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// seal 加密插件列表
+func (b *ConfigBundle) seal(plugins []BundledPlugin, passphrase string) error {
+	plaintext, err := json.Marshal(plugins)
+	if err != nil {
+		return err
+	}
+	enc := &BundleEncryption{
+		KDF:        "pbkdf2-sha256",
+		Iterations: bundleKDFIterations,
+		Salt:       make([]byte, 16),
+		Cipher:     "aes-256-gcm",
+	}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return err
+	}
+	aead, err := enc.aead(passphrase)
+	if err != nil {
+		return err
+	}
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return err
+	}
+	b.Encryption = enc
+	b.Ciphertext = aead.Seal(nil, enc.Nonce, plaintext, b.additionalData())
+	return nil
+}
+
+// open 解密插件列表
+func (b *ConfigBundle) open(passphrase string) ([]BundledPlugin, error) {
+	enc := b.Encryption
+	if enc.KDF != "pbkdf2-sha256" || enc.Cipher != "aes-256-gcm" {
+		return nil, errcode.New(errcode.InvalidArgument, "不支持的加密方式 %s/%s", enc.KDF, enc.Cipher)
+	}
+	if enc.Iterations <= 0 || enc.Iterations > maxBundleKDFIterations || len(enc.Salt) == 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "导出包的加密参数无效")
+	}
+	aead, err := enc.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != aead.NonceSize() {
+		return nil, errcode.New(errcode.InvalidArgument, "导出包的加密参数无效")
+	}
+	plaintext, err := aead.Open(nil, enc.Nonce, b.Ciphertext, b.additionalData())
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "密码错误或导出包已损坏")
+	}
+	var plugins []BundledPlugin
+	if err := json.Unmarshal(plaintext, &plugins); err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgument, err, "导出包内容无效")
+	}
+	return plugins, nil
+}
+
+// additionalData 导出时间和 Agent 版本参与认证，不能被单独篡改
+func (b *ConfigBundle) additionalData() []byte {
+	return []byte(fmt.Sprintf("%s/%d/%d/%s", b.Format, b.Version, b.ExportedAt.UnixNano(), b.AgentVersion))
+}
+
+func (e *BundleEncryption) aead(passphrase string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), e.Salt, e.Iterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 PBKDF2（RFC 8018）以 HMAC-SHA256 为伪随机函数
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen)
+	u := make([]byte, sha256.Size)
+	block := make([]byte, sha256.Size)
+	for i := uint32(1); len(key) < keyLen; i++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, i))
+		u = prf.Sum(u[:0])
+		copy(block, u)
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range block {
+				block[j] ^= u[j]
+			}
+		}
+		key = append(key, block...)
+	}
+	return key[:keyLen]
+}
@@ -0,0 +1,157 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// RFC 7914 第 11 节的 PBKDF2-HMAC-SHA256 测试向量
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2SHA256([]byte(tt.password), []byte(tt.salt), tt.iterations, 64))
+		if got != tt.want {
+			t.Errorf("pbkdf2SHA256(%q, %q, %d) = %s, want %s", tt.password, tt.salt, tt.iterations, got, tt.want)
+		}
+	}
+}
+
+// newConfigManager 安装一个声明了敏感配置项的插件
+func newConfigManager(t *testing.T) *Manager {
+	t.Helper()
+	trusted, key := newSigningKey(t)
+	m := newTestManager(t, trusted)
+	pkg := manifestPackage(t, `{"id":"hello","name":"Hello","version":"1.0.0","config_schema":[{"key":"token","type":"string","secret":true},{"key":"region","type":"string"}]}`)
+	if err := m.InstallPlugin("hello", InstallRequest{Source: SourceLocal, Data: pkg, Signature: signPackage(key, pkg)}); err != nil {
+		t.Fatalf("InstallPlugin() error: %v", err)
+	}
+	return m
+}
+
+func setConfig(t *testing.T, m *Manager, config map[string]any) {
+	t.Helper()
+	if err := m.SetPluginConfig("hello", config); err != nil {
+		t.Fatalf("SetPluginConfig() error: %v", err)
+	}
+}
+
+func importConfigs(t *testing.T, m *Manager, data []byte, passphrase string) {
+	t.Helper()
+	results, err := m.ImportConfigs(data, ImportOptions{Passphrase: passphrase})
+	if err != nil {
+		t.Fatalf("ImportConfigs() error: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != ImportApplied {
+		t.Fatalf("ImportConfigs() = %+v, want hello applied", results)
+	}
+}
+
+func TestConfigBundleEncrypted(t *testing.T) {
+	m := newConfigManager(t)
+	setConfig(t, m, map[string]any{"token": "s3cret", "region": "eu"})
+
+	data, err := m.ExportConfigs("correct horse")
+	if err != nil {
+		t.Fatalf("ExportConfigs() error: %v", err)
+	}
+	if bytes.Contains(data, []byte("s3cret")) || bytes.Contains(data, []byte(`"eu"`)) {
+		t.Error("encrypted bundle contains plaintext config values")
+	}
+
+	setConfig(t, m, map[string]any{"token": "local", "region": "us"})
+	importConfigs(t, m, data, "correct horse")
+	want := map[string]any{"token": "s3cret", "region": "eu"}
+	if got := m.plugins["hello"].Config; !reflect.DeepEqual(got, want) {
+		t.Errorf("imported config = %v, want %v", got, want)
+	}
+
+	// tamper 修改导出包后重新编码
+	tamper := func(f func(b *ConfigBundle)) []byte {
+		var b ConfigBundle
+		if err := json.Unmarshal(data, &b); err != nil {
+			t.Fatal(err)
+		}
+		f(&b)
+		out, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	rejected := map[string]struct {
+		data       []byte
+		passphrase string
+	}{
+		"wrong passphrase":    {data, "wrong horse"},
+		"missing passphrase":  {data, ""},
+		"tampered version":    {tamper(func(b *ConfigBundle) { b.AgentVersion = "evil" }), "correct horse"},
+		"tampered exported":   {tamper(func(b *ConfigBundle) { b.ExportedAt = b.ExportedAt.Add(1) }), "correct horse"},
+		"tampered ciphertext": {tamper(func(b *ConfigBundle) { b.Ciphertext[0] ^= 0xff }), "correct horse"},
+		"tampered iterations": {tamper(func(b *ConfigBundle) { b.Encryption.Iterations = 1 }), "correct horse"},
+	}
+	setConfig(t, m, map[string]any{"token": "local", "region": "us"})
+	for name, c := range rejected {
+		if _, err := m.ImportConfigs(c.data, ImportOptions{Passphrase: c.passphrase}); errcode.Of(err) != errcode.InvalidArgument {
+			t.Errorf("ImportConfigs(%s) = %v, want INVALID_ARGUMENT", name, err)
+		}
+	}
+	if got := m.plugins["hello"].Config["token"]; got != "local" {
+		t.Errorf("token after rejected imports = %v, want local", got)
+	}
+}
+
+func TestConfigBundlePlainKeepsLocalSecrets(t *testing.T) {
+	m := newConfigManager(t)
+	setConfig(t, m, map[string]any{"token": "s3cret", "region": "eu"})
+
+	data, err := m.ExportConfigs("")
+	if err != nil {
+		t.Fatalf("ExportConfigs() error: %v", err)
+	}
+	if bytes.Contains(data, []byte("s3cret")) || !bytes.Contains(data, []byte(SecretMask)) {
+		t.Errorf("plain bundle should mask secrets:\n%s", data)
+	}
+
+	setConfig(t, m, map[string]any{"token": "local", "region": "us"})
+	importConfigs(t, m, data, "")
+	want := map[string]any{"token": "local", "region": "eu"}
+	if got := m.plugins["hello"].Config; !reflect.DeepEqual(got, want) {
+		t.Errorf("imported config = %v, want %v", got, want)
+	}
+}
+
+func TestUnmaskConfig(t *testing.T) {
+	config := map[string]any{
+		"token":   SecretMask,
+		"region":  "eu",
+		"port":    float64(8080),
+		"missing": SecretMask,
+		"db":      map[string]any{"password": SecretMask, "host": "db.local"},
+	}
+	old := map[string]any{
+		"token":  "local",
+		"region": "us",
+		"db":     map[string]any{"password": "db-local", "host": "old.local"},
+	}
+	want := map[string]any{
+		"token":  "local",
+		"region": "eu",
+		"port":   float64(8080),
+		"db":     map[string]any{"password": "db-local", "host": "db.local"},
+	}
+	if got := unmaskConfig(config, old); !reflect.DeepEqual(got, want) {
+		t.Errorf("unmaskConfig() = %v, want %v", got, want)
+	}
+}
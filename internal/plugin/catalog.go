@@ -0,0 +1,300 @@
+package plugin
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+)
+
+// AvailablePlugin 插件市场中的可安装插件
+type AvailablePlugin struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Version     string     `json:"version"`
+	Description string     `json:"description"`
+	Author      string     `json:"author"`
+	Icon        string     `json:"icon"`
+	Type        PluginType `json:"type"`
+	Downloads   int64      `json:"downloads"`
+	Rating      float64    `json:"rating"`
+	RatingCount int32      `json:"rating_count"`
+	Tags        []string   `json:"tags"`
+	Category    string     `json:"category"`
+	Official    bool       `json:"official"`
+	DownloadURL string     `json:"download_url"`
+	UpdatedAt   string     `json:"updated_at"`
+	// Readme 和 Screenshots 只在插件详情中返回，搜索结果中为空
+	Readme      string   `json:"readme,omitempty"`      // Markdown
+	Screenshots []string `json:"screenshots,omitempty"` // https 地址
+}
+
+// SearchQuery 插件市场搜索条件
+type SearchQuery struct {
+	Query    string   // 匹配 ID、名称、描述、作者和标签，不区分大小写
+	Category string   // 为空表示全部分类
+	Tags     []string // 须包含全部标签，不区分大小写
+	Page     int      // 从 1 开始
+	PageSize int      // 默认 20，最大 100
+}
+
+// CategoryCount 分类及其中的插件数
+type CategoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SearchResult 插件市场搜索结果
+type SearchResult struct {
+	Plugins  []*AvailablePlugin `json:"plugins"`
+	Total    int                `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	// Categories 满足关键词和标签条件的插件按分类计数，不受 Category 条件影响，供界面切换分类
+	Categories []CategoryCount `json:"categories"`
+}
+
+// PluginDetails 插件市场中单个插件的详情
+type PluginDetails struct {
+	*AvailablePlugin
+	Installed        bool   `json:"installed"`
+	InstalledVersion string `json:"installed_version,omitempty"`
+	UpdateAvailable  bool   `json:"update_available"`
+}
+
+// officialCatalog 内置的官方插件列表
+// 未配置插件仓库，或仓库不可用且没有本地缓存时使用
+var officialCatalog = []*AvailablePlugin{
+	{
+		ID:          "cloudflare-security",
+		Name:        "Cloudflare 安全防护",
+		Version:     "1.0.0",
+		Description: "集成 Cloudflare 安全功能，自动封禁恶意 IP，防 DDoS 攻击。24/7 全天候运行在服务器上。",
+		Author:      "Runixo",
+		Icon:        "🛡️",
+		Type:        TypeAgent,
+		Downloads:   5200,
+		Rating:      4.7,
+		RatingCount: 128,
+		Tags:        []string{"安全", "Cloudflare", "防火墙", "DDoS"},
+		Category:    "security",
+		Official:    true,
+		DownloadURL: "https://plugins.runixo.dev/cloudflare-security",
+		UpdatedAt:   "2024-01-20",
+	},
+	{
+		ID:          "nginx-manager",
+		Name:        "Nginx 管理",
+		Version:     "1.0.0",
+		Description: "可视化管理 Nginx 配置、虚拟主机和 SSL 证书",
+		Author:      "Runixo",
+		Icon:        "🌐",
+		Type:        TypeHybrid,
+		Downloads:   6200,
+		Rating:      4.6,
+		RatingCount: 189,
+		Tags:        []string{"Web服务器", "Nginx", "反向代理"},
+		Category:    "web",
+		Official:    true,
+		DownloadURL: "https://plugins.runixo.dev/nginx-manager",
+		UpdatedAt:   "2024-01-15",
+	},
+	{
+		ID:          "mysql-manager",
+		Name:        "MySQL 管理",
+		Version:     "1.0.0",
+		Description: "数据库管理、备份恢复、性能监控",
+		Author:      "Runixo",
+		Icon:        "🗄️",
+		Type:        TypeHybrid,
+		Downloads:   5100,
+		Rating:      4.5,
+		RatingCount: 167,
+		Tags:        []string{"数据库", "MySQL", "SQL"},
+		Category:    "database",
+		Official:    true,
+		DownloadURL: "https://plugins.runixo.dev/mysql-manager",
+		UpdatedAt:   "2024-01-10",
+	},
+	{
+		ID:          "backup-manager",
+		Name:        "自动备份",
+		Version:     "1.0.0",
+		Description: "定时备份文件和数据库到本地或云存储。在服务器上 24/7 运行。",
+		Author:      "Runixo",
+		Icon:        "💾",
+		Type:        TypeAgent,
+		Downloads:   4200,
+		Rating:      4.3,
+		RatingCount: 98,
+		Tags:        []string{"备份", "定时任务", "云存储"},
+		Category:    "tools",
+		Official:    true,
+		DownloadURL: "https://plugins.runixo.dev/backup-manager",
+		UpdatedAt:   "2024-01-05",
+	},
+	{
+		ID:          "advanced-monitor",
+		Name:        "高级监控",
+		Version:     "1.0.0",
+		Description: "详细的性能监控、告警通知、历史数据。在服务器上持续收集数据。",
+		Author:      "Runixo",
+		Icon:        "📊",
+		Type:        TypeAgent,
+		Downloads:   5600,
+		Rating:      4.6,
+		RatingCount: 145,
+		Tags:        []string{"监控", "告警", "性能"},
+		Category:    "monitor",
+		Official:    true,
+		DownloadURL: "https://plugins.runixo.dev/advanced-monitor",
+		UpdatedAt:   "2024-01-03",
+	},
+}
+
+// AvailablePlugins 返回可安装的插件列表，配置了插件仓库时从仓库获取
+func (m *Manager) AvailablePlugins(ctx context.Context) []*AvailablePlugin {
+	m.mu.RLock()
+	registry := m.registry
+	m.mu.RUnlock()
+	if registry == nil {
+		return officialCatalog
+	}
+	return registry.Plugins(ctx)
+}
+
+// SearchPlugins 按关键词、分类和标签搜索插件市场并分页
+// 有关键词时按匹配程度排序，其余按官方优先、下载量从高到低排序
+func (m *Manager) SearchPlugins(ctx context.Context, q SearchQuery) *SearchResult {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 || q.PageSize > maxSearchPageSize {
+		q.PageSize = defaultSearchPageSize
+	}
+	query := strings.ToLower(strings.TrimSpace(q.Query))
+
+	type match struct {
+		plugin *AvailablePlugin
+		score  int
+	}
+	var matches []match
+	categories := make(map[string]int)
+	for _, p := range m.AvailablePlugins(ctx) {
+		score := matchScore(p, query)
+		if score == 0 || !hasAllTags(p, q.Tags) {
+			continue
+		}
+		categories[p.Category]++
+		if q.Category != "" && !strings.EqualFold(p.Category, q.Category) {
+			continue
+		}
+		matches = append(matches, match{p, score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.score != b.score:
+			return a.score > b.score
+		case a.plugin.Official != b.plugin.Official:
+			return a.plugin.Official
+		case a.plugin.Downloads != b.plugin.Downloads:
+			return a.plugin.Downloads > b.plugin.Downloads
+		}
+		return a.plugin.ID < b.plugin.ID
+	})
+
+	result := &SearchResult{
+		Plugins:    []*AvailablePlugin{},
+		Total:      len(matches),
+		Page:       q.Page,
+		PageSize:   q.PageSize,
+		Categories: make([]CategoryCount, 0, len(categories)),
+	}
+	for start := (q.Page - 1) * q.PageSize; start < len(matches) && len(result.Plugins) < q.PageSize; start++ {
+		// 列表不返回详情字段，减小响应
+		p := *matches[start].plugin
+		p.Readme = ""
+		p.Screenshots = nil
+		result.Plugins = append(result.Plugins, &p)
+	}
+	for name, count := range categories {
+		if name != "" {
+			result.Categories = append(result.Categories, CategoryCount{Name: name, Count: count})
+		}
+	}
+	sort.Slice(result.Categories, func(i, j int) bool {
+		return result.Categories[i].Name < result.Categories[j].Name
+	})
+	return result
+}
+
+// PluginDetails 返回插件市场中插件的详情及本机的安装情况
+func (m *Manager) PluginDetails(ctx context.Context, id string) (*PluginDetails, error) {
+	var found *AvailablePlugin
+	for _, p := range m.AvailablePlugins(ctx) {
+		if p.ID == id {
+			found = p
+			break
+		}
+	}
+	if found == nil {
+		return nil, errcode.New(errcode.PluginNotFound, "插件市场中没有插件 %s", id)
+	}
+
+	details := &PluginDetails{AvailablePlugin: found}
+	if installed := m.GetPlugin(id); installed != nil {
+		details.Installed = true
+		details.InstalledVersion = installed.Manifest.Version
+		if cmp, err := compareVersions(installed.Manifest.Version, found.Version); err == nil && cmp < 0 {
+			details.UpdateAvailable = true
+		}
+	}
+	return details, nil
+}
+
+// matchScore 关键词匹配程度，0 表示不匹配；关键词为空时全部匹配
+func matchScore(p *AvailablePlugin, query string) int {
+	if query == "" {
+		return 1
+	}
+	id, name := strings.ToLower(p.ID), strings.ToLower(p.Name)
+	switch {
+	case id == query || name == query:
+		return 4
+	case strings.Contains(id, query) || strings.Contains(name, query):
+		return 3
+	}
+	for _, tag := range p.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return 2
+		}
+	}
+	if strings.Contains(strings.ToLower(p.Description), query) || strings.Contains(strings.ToLower(p.Author), query) {
+		return 1
+	}
+	return 0
+}
+
+func hasAllTags(p *AvailablePlugin, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range p.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
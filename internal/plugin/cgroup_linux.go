@@ -0,0 +1,158 @@
+//go:build linux
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	pluginCgroupParent = "/sys/fs/cgroup/runixo-plugins"
+	// cpuPeriod cpu.max 的周期（微秒）
+	cpuPeriod = 100000
+)
+
+var (
+	pluginCgroupOnce sync.Once
+	pluginCgroupOK   bool
+)
+
+// initPluginCgroup 创建 runixo-plugins 父 cgroup 并为子 cgroup 启用 cpu / memory 控制器
+// 需要 root 和 cgroup v2 统一层级
+func initPluginCgroup() {
+	if _, err := os.Stat("/sys/fs/cgroup/cgroup.controllers"); err != nil {
+		return
+	}
+	if err := os.Mkdir(pluginCgroupParent, 0755); err != nil && !os.IsExist(err) {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(pluginCgroupParent, "cgroup.subtree_control"), []byte("+cpu +memory"), 0644); err != nil {
+		return
+	}
+	pluginCgroupOK = true
+}
+
+// pluginCgroup 外部插件进程的 cgroup
+type pluginCgroup struct {
+	dir string
+
+	lastSample    time.Time
+	lastUsage     uint64 // cpu.stat usage_usec
+	lastPeriods   uint64
+	lastThrottled uint64
+	lastMemMax    uint64 // memory.events max
+}
+
+// newPluginCgroup 为插件创建 cgroup 并写入限制，上次运行遗留的同名 cgroup 先被删除
+func newPluginCgroup(id string, l ResourceLimits) (*pluginCgroup, error) {
+	pluginCgroupOnce.Do(initPluginCgroup)
+	if !pluginCgroupOK {
+		return nil, fmt.Errorf("cgroup v2 不可用")
+	}
+	c := &pluginCgroup{dir: filepath.Join(pluginCgroupParent, id)}
+	c.remove()
+	if err := os.Mkdir(c.dir, 0755); err != nil {
+		return nil, err
+	}
+	write := func(name, value string) error {
+		return os.WriteFile(filepath.Join(c.dir, name), []byte(value), 0644)
+	}
+	var err error
+	if l.CPUPercent > 0 {
+		quota := int(l.CPUPercent / 100 * cpuPeriod)
+		err = write("cpu.max", fmt.Sprintf("%d %d", max(quota, 1000), cpuPeriod))
+	}
+	if err == nil && l.Memory > 0 {
+		err = write("memory.max", strconv.FormatUint(l.Memory, 10))
+		write("memory.swap.max", "0")
+	}
+	if err != nil {
+		c.remove()
+		return nil, err
+	}
+	return c, nil
+}
+
+// attach 让命令在 clone 时加入 cgroup，返回的函数在命令启动后关闭目录描述符
+func (c *pluginCgroup) attach(cmd *exec.Cmd) (func(), error) {
+	fd, err := unix.Open(c.dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+	return func() { unix.Close(fd) }, nil
+}
+
+// usage 读取 cgroup 的资源统计，CPU 使用率为距上次统计的平均值
+func (c *pluginCgroup) usage() (*ResourceUsage, error) {
+	cpu, err := readKeyValues(filepath.Join(c.dir, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	memData, err := os.ReadFile(filepath.Join(c.dir, "memory.current"))
+	if err != nil {
+		return nil, err
+	}
+	mem, _ := strconv.ParseUint(strings.TrimSpace(string(memData)), 10, 64)
+	events, _ := readKeyValues(filepath.Join(c.dir, "memory.events"))
+
+	now := time.Now()
+	u := &ResourceUsage{Accounting: "cgroup", Memory: mem}
+	if !c.lastSample.IsZero() {
+		if elapsed := now.Sub(c.lastSample); elapsed > 0 && cpu["usage_usec"] >= c.lastUsage {
+			u.CPUPercent = float64(cpu["usage_usec"]-c.lastUsage) / float64(elapsed.Microseconds()) * 100
+		}
+		// 超过一半的周期被限流，或触发了内存上限回收，视为该周期达到限制
+		if periods := cpu["nr_periods"] - c.lastPeriods; periods > 0 && (cpu["nr_throttled"]-c.lastThrottled)*2 > periods {
+			u.cpuThrottled = true
+		}
+		u.memoryLimited = events["max"] > c.lastMemMax
+	}
+	c.lastSample = now
+	c.lastUsage = cpu["usage_usec"]
+	c.lastPeriods = cpu["nr_periods"]
+	c.lastThrottled = cpu["nr_throttled"]
+	c.lastMemMax = events["max"]
+	return u, nil
+}
+
+// remove 结束 cgroup 中遗留的进程并删除 cgroup
+func (c *pluginCgroup) remove() {
+	// cgroup.kill 需要 5.14+
+	os.WriteFile(filepath.Join(c.dir, "cgroup.kill"), []byte("1"), 0644)
+	for i := 0; i < 20; i++ {
+		if err := os.Remove(c.dir); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// readKeyValues 读取 "key value" 形式的 cgroup 统计文件
+func readKeyValues(path string) (map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		if f := strings.Fields(line); len(f) == 2 {
+			values[f[0]], _ = strconv.ParseUint(f[1], 10, 64)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// pluginCgroup 非 Linux 平台不支持 cgroup，外部插件只做进程级统计
+type pluginCgroup struct{}
+
+func newPluginCgroup(id string, l ResourceLimits) (*pluginCgroup, error) {
+	return nil, fmt.Errorf("当前平台不支持 cgroup")
+}
+
+func (c *pluginCgroup) attach(cmd *exec.Cmd) (func(), error) {
+	return func() {}, nil
+}
+
+func (c *pluginCgroup) usage() (*ResourceUsage, error) {
+	return nil, fmt.Errorf("当前平台不支持 cgroup")
+}
+
+func (c *pluginCgroup) remove() {}
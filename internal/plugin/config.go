@@ -0,0 +1,283 @@
+package plugin
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 配置项类型
+const (
+	ConfigString  = "string"
+	ConfigNumber  = "number"
+	ConfigInteger = "integer"
+	ConfigBoolean = "boolean"
+	ConfigArray   = "array"
+	ConfigObject  = "object"
+)
+
+// SecretMask 对外返回的敏感配置项占位值，提交配置时原样传回表示保留原值
+const SecretMask = "********"
+
+// ConfigField 插件清单 config_schema 中的一个配置项，前端按声明顺序渲染配置表单
+type ConfigField struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Label       string   `json:"label,omitempty"`       // 表单标签，为空时使用 Key
+	Description string   `json:"description,omitempty"` // 表单中的说明文字
+	Default     any      `json:"default,omitempty"`     // 安装时和配置项未提交时使用的值
+	Required    bool     `json:"required,omitempty"`
+	Secret      bool     `json:"secret,omitempty"` // 密码、令牌等，对外返回时替换为 SecretMask
+	Enum        []any    `json:"enum,omitempty"`   // 可选值，数组类型时约束每个元素
+	Min         *float64 `json:"min,omitempty"`    // 数值的最小值，字符串和数组的最小长度
+	Max         *float64 `json:"max,omitempty"`    // 数值的最大值，字符串和数组的最大长度
+	Pattern     string   `json:"pattern,omitempty"`
+}
+
+// PluginConfig 插件配置及其 schema
+type PluginConfig struct {
+	Values map[string]any `json:"values"` // 敏感配置项已替换为 SecretMask
+	Schema []ConfigField  `json:"schema,omitempty"`
+}
+
+// check 校验配置值，不合法时返回错误说明
+func (f *ConfigField) check(value any) string {
+	switch f.Type {
+	case ConfigString:
+		s, ok := value.(string)
+		if !ok {
+			return "类型应为字符串"
+		}
+		if msg := f.checkRange(float64(len([]rune(s))), "长度"); msg != "" {
+			return msg
+		}
+		if f.Pattern != "" {
+			if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(s) {
+				return fmt.Sprintf("格式无效，应匹配 %s", f.Pattern)
+			}
+		}
+	case ConfigNumber, ConfigInteger:
+		n, ok := value.(float64)
+		if !ok {
+			return "类型应为数字"
+		}
+		if f.Type == ConfigInteger && n != math.Trunc(n) {
+			return "类型应为整数"
+		}
+		if msg := f.checkRange(n, ""); msg != "" {
+			return msg
+		}
+	case ConfigBoolean:
+		if _, ok := value.(bool); !ok {
+			return "类型应为布尔值"
+		}
+	case ConfigArray:
+		items, ok := value.([]any)
+		if !ok {
+			return "类型应为数组"
+		}
+		if msg := f.checkRange(float64(len(items)), "元素个数"); msg != "" {
+			return msg
+		}
+		if len(f.Enum) > 0 {
+			for _, item := range items {
+				if !configEnumContains(f.Enum, item) {
+					return fmt.Sprintf("元素取值应为 %s 之一", configEnumString(f.Enum))
+				}
+			}
+		}
+		return ""
+	case ConfigObject:
+		if _, ok := value.(map[string]any); !ok {
+			return "类型应为对象"
+		}
+	}
+	if len(f.Enum) > 0 && !configEnumContains(f.Enum, value) {
+		return fmt.Sprintf("取值应为 %s 之一", configEnumString(f.Enum))
+	}
+	return ""
+}
+
+// checkRange 检查 Min 和 Max，what 为空时表示数值本身
+func (f *ConfigField) checkRange(n float64, what string) string {
+	if f.Min != nil && n < *f.Min {
+		return fmt.Sprintf("%s不能小于 %v", what, *f.Min)
+	}
+	if f.Max != nil && n > *f.Max {
+		return fmt.Sprintf("%s不能大于 %v", what, *f.Max)
+	}
+	return ""
+}
+
+// checkConfigSchema 配置项不能重复，默认值和可选值必须符合声明的类型，清单 config 中的配置项必须已声明
+func (v *manifestValidator) checkConfigSchema(fields []ConfigField, defaults map[string]any) {
+	seen := make(map[string]bool, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		field := fmt.Sprintf("config_schema[%d]", i)
+		if seen[f.Key] {
+			v.addf(field+".key", "重复声明配置项 %q", f.Key)
+		}
+		seen[f.Key] = true
+		if f.Secret && f.Type != ConfigString {
+			v.addf(field+".secret", "只有字符串类型的配置项可以声明为敏感")
+		}
+		if f.Pattern != "" {
+			if f.Type != ConfigString {
+				v.addf(field+".pattern", "只有字符串类型的配置项可以声明格式")
+			} else if _, err := regexp.Compile(f.Pattern); err != nil {
+				v.addf(field+".pattern", "无效的正则表达式: %v", err)
+			}
+		}
+		if (f.Min != nil || f.Max != nil) && (f.Type == ConfigBoolean || f.Type == ConfigObject) {
+			v.addf(field, "%s 类型的配置项不能声明 min 或 max", f.Type)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			v.addf(field+".min", "不能大于 max")
+		}
+		if len(f.Enum) > 0 && (f.Type == ConfigBoolean || f.Type == ConfigObject) {
+			v.addf(field+".enum", "%s 类型的配置项不能声明可选值", f.Type)
+		} else if f.Type != ConfigArray {
+			item := *f
+			item.Enum, item.Min, item.Max, item.Pattern = nil, nil, nil, ""
+			for j, e := range f.Enum {
+				if msg := item.check(e); msg != "" {
+					v.addf(fmt.Sprintf("%s.enum[%d]", field, j), "%s", msg)
+				}
+			}
+		}
+		if f.Default != nil {
+			if msg := f.check(f.Default); msg != "" {
+				v.addf(field+".default", "%s", msg)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(defaults))
+	for k := range defaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !seen[k] {
+			v.addf("config."+k, "未在 config_schema 中声明")
+		}
+	}
+}
+
+// defaultConfig 插件的初始配置：清单 config 中的值，再补全 config_schema 中的默认值
+func defaultConfig(manifest *PluginManifest) map[string]any {
+	config := make(map[string]any, len(manifest.Config)+len(manifest.ConfigSchema))
+	for k, v := range manifest.Config {
+		config[k] = v
+	}
+	for _, f := range manifest.ConfigSchema {
+		if _, ok := config[f.Key]; !ok && f.Default != nil {
+			config[f.Key] = f.Default
+		}
+	}
+	return config
+}
+
+// applyConfigSchema 按 schema 校验提交的配置并补全默认值，返回新配置
+// 值为 null 的配置项视为未提交；敏感配置项提交 SecretMask 时保留 old 中的原值。未声明 schema 时原样返回
+func applyConfigSchema(schema []ConfigField, config, old map[string]any) (map[string]any, error) {
+	if len(schema) == 0 {
+		return config, nil
+	}
+
+	var violations []errcode.FieldViolation
+	result := make(map[string]any, len(schema))
+	declared := make(map[string]bool, len(schema))
+	for i := range schema {
+		f := &schema[i]
+		declared[f.Key] = true
+		value, ok := config[f.Key]
+		if f.Secret && value == SecretMask {
+			value, ok = old[f.Key]
+		}
+		if !ok || value == nil {
+			if f.Default != nil {
+				result[f.Key] = f.Default
+			} else if f.Required {
+				violations = append(violations, errcode.FieldViolation{Field: f.Key, Description: "缺少必填配置项"})
+			}
+			continue
+		}
+		if f.Required && value == "" {
+			violations = append(violations, errcode.FieldViolation{Field: f.Key, Description: "不能为空"})
+			continue
+		}
+		if msg := f.check(value); msg != "" {
+			violations = append(violations, errcode.FieldViolation{Field: f.Key, Description: msg})
+			continue
+		}
+		result[f.Key] = value
+	}
+
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		if !declared[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		violations = append(violations, errcode.FieldViolation{Field: k, Description: "未声明的配置项"})
+	}
+
+	if len(violations) > 0 {
+		problems := make([]string, 0, len(violations))
+		for _, f := range violations {
+			problems = append(problems, f.Field+": "+f.Description)
+		}
+		return nil, errcode.Invalid(errcode.ValidationFailed,
+			"插件配置校验失败: "+strings.Join(problems, "; "), violations)
+	}
+	return result, nil
+}
+
+// maskSecrets 返回敏感配置项替换为 SecretMask 的副本，未设置的敏感配置项保持为空
+func maskSecrets(schema []ConfigField, config map[string]any) map[string]any {
+	masked := make(map[string]any, len(config))
+	for k, v := range config {
+		masked[k] = v
+	}
+	for _, f := range schema {
+		if v, ok := masked[f.Key]; f.Secret && ok && v != nil && v != "" {
+			masked[f.Key] = SecretMask
+		}
+	}
+	return masked
+}
+
+// configEnumContains 可选值只比较字符串、数字和布尔值
+func configEnumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		switch e.(type) {
+		case string, float64, bool:
+			if e == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func configEnumString(enum []any) string {
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		if s, ok := e.(string); ok {
+			values = append(values, fmt.Sprintf("%q", s))
+		} else {
+			values = append(values, fmt.Sprint(e))
+		}
+	}
+	return strings.Join(values, "、")
+}
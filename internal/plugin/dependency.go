@@ -0,0 +1,324 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// Dependencies 插件清单声明的依赖
+//
+//	"dependencies": {
+//	  "agent": "0.3.0",
+//	  "plugins": {"nginx-manager": "1.0.0", "backup-core": ""},
+//	  "binaries": ["nginx", "mysqldump"]
+//	}
+//
+// 旧版清单的字符串数组写法（["nginx-manager"]）视为不限版本的插件依赖
+type Dependencies struct {
+	// Agent 要求的最低 Agent 版本
+	Agent string `json:"agent,omitempty"`
+	// Plugins 依赖的插件 ID 及最低版本，版本为空表示不限
+	Plugins map[string]string `json:"plugins,omitempty"`
+	// Binaries 要求 PATH 中存在的系统命令
+	Binaries []string `json:"binaries,omitempty"`
+}
+
+// UnmarshalJSON 同时接受对象和旧版的插件 ID 数组
+func (d *Dependencies) UnmarshalJSON(data []byte) error {
+	*d = Dependencies{}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err == nil {
+		if len(ids) > 0 {
+			d.Plugins = make(map[string]string, len(ids))
+			for _, id := range ids {
+				d.Plugins[id] = ""
+			}
+		}
+		return nil
+	}
+	type plain Dependencies
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+// pluginIDs 按 ID 排序的依赖插件，保证解析顺序和错误信息稳定
+func (d Dependencies) pluginIDs() []string {
+	ids := make([]string, 0, len(d.Plugins))
+	for id := range d.Plugins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// compareVersions 比较 major.minor.patch 形式的版本号（可带 v 前缀，忽略 -/+ 之后的部分）
+// 返回 -1、0、1；无法解析时返回错误
+func compareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(v string) ([3]int, error) {
+	var parts [3]int
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	fields := strings.Split(s, ".")
+	if s == "" || len(fields) > 3 {
+		return parts, fmt.Errorf("无效的版本号: %q", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("无效的版本号: %q", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// checkEnvironment 检查最低 Agent 版本和系统命令，返回所有不满足的项
+func (m *Manager) checkEnvironment(manifest *PluginManifest) []string {
+	var problems []string
+	deps := manifest.Dependencies
+
+	if deps.Agent != "" {
+		cmp, err := compareVersions(m.version, deps.Agent)
+		switch {
+		case m.version == "":
+		case err != nil:
+			log.Warn().Err(err).Str("plugin", manifest.ID).Msg("无法比较 Agent 版本，跳过检查")
+		case cmp < 0:
+			problems = append(problems, fmt.Sprintf("需要 Agent 版本 >= %s（当前 %s），请先升级 Agent", deps.Agent, m.version))
+		}
+	}
+
+	for _, bin := range deps.Binaries {
+		if _, err := exec.LookPath(bin); err != nil {
+			problems = append(problems, fmt.Sprintf("缺少系统命令 %s，请先在服务器上安装", bin))
+		}
+	}
+	return problems
+}
+
+// checkPluginVersion 检查已安装的依赖插件是否满足最低版本
+func checkPluginVersion(dep *InstalledPlugin, minVersion string) string {
+	if minVersion == "" {
+		return ""
+	}
+	cmp, err := compareVersions(dep.Manifest.Version, minVersion)
+	if err != nil {
+		return fmt.Sprintf("无法比较依赖插件 %s 的版本: %v", dep.Manifest.ID, err)
+	}
+	if cmp < 0 {
+		return fmt.Sprintf("依赖插件 %s 版本为 %s，需要 >= %s，请先更新该插件", dep.Manifest.ID, dep.Manifest.Version, minVersion)
+	}
+	return ""
+}
+
+// fetchedPackage 加锁前从官方仓库下载的依赖插件包，下载失败时 err 不为空
+type fetchedPackage struct {
+	pkg       *packageFile
+	signature []byte
+	err       error
+}
+
+// fetchedPackages 插件 ID -> 加锁前下载的插件包
+type fetchedPackages map[string]*fetchedPackage
+
+// close 删除下载的临时文件
+func (f fetchedPackages) close() {
+	for _, p := range f {
+		if p.pkg != nil {
+			p.pkg.Close()
+		}
+	}
+}
+
+// fetchDependencies 在加锁前递归下载插件包声明的、尚未安装的依赖插件，结果放入 fetched。
+// 清单此时尚未验证，只用于决定下载哪些依赖；解压后的清单校验、签名验证和版本检查在加锁安装时进行，
+// 清单无法读取或下载失败的依赖也留到那时报告
+func (m *Manager) fetchDependencies(pkg *packageFile, repoURL string, fetched fetchedPackages) {
+	deps, err := packageDependencies(pkg)
+	if err != nil {
+		return
+	}
+	for _, id := range deps.pluginIDs() {
+		if _, ok := fetched[id]; ok || !validPluginID.MatchString(id) {
+			continue
+		}
+		m.mu.RLock()
+		_, installed := m.plugins[id]
+		m.mu.RUnlock()
+		if installed {
+			continue
+		}
+
+		log.Info().Str("dependency", id).Msg("下载依赖插件")
+		dep, signature, err := m.fetchPackage(id, repoURL, InstallRequest{Source: SourceOfficial})
+		fetched[id] = &fetchedPackage{pkg: dep, signature: signature, err: err}
+		if err == nil {
+			m.fetchDependencies(dep, repoURL, fetched)
+		}
+	}
+}
+
+// resolveDependencies 安装时解析依赖（需要持有锁）：缺少的依赖插件从 fetched 中安装
+// resolving 为当前的安装链，出现重复即为循环依赖
+func (m *Manager) resolveDependencies(manifest *PluginManifest, resolving []string, fetched fetchedPackages) error {
+	problems := m.checkEnvironment(manifest)
+
+	for _, id := range manifest.Dependencies.pluginIDs() {
+		minVersion := manifest.Dependencies.Plugins[id]
+		if containsString(resolving, id) {
+			problems = append(problems, fmt.Sprintf("循环依赖: %s -> %s", strings.Join(resolving, " -> "), id))
+			continue
+		}
+		if !validPluginID.MatchString(id) {
+			problems = append(problems, fmt.Sprintf("无效的依赖插件 ID: %q", id))
+			continue
+		}
+		if dep, ok := m.plugins[id]; ok {
+			if p := checkPluginVersion(dep, minVersion); p != "" {
+				problems = append(problems, p)
+			}
+			continue
+		}
+
+		log.Info().Str("plugin", manifest.ID).Str("dependency", id).Msg("安装依赖插件")
+		if err := m.installFetchedLocked(id, resolving, fetched); err != nil {
+			problems = append(problems, fmt.Sprintf("依赖插件 %s 未安装，且从仓库安装失败: %v", id, err))
+			continue
+		}
+		if p := checkPluginVersion(m.plugins[id], minVersion); p != "" {
+			problems = append(problems, p)
+		}
+	}
+
+	return dependencyError(manifest.ID, problems)
+}
+
+// installFetchedLocked 安装加锁前下载的依赖插件（需要持有锁）
+func (m *Manager) installFetchedLocked(id string, resolving []string, fetched fetchedPackages) error {
+	f, ok := fetched[id]
+	if !ok {
+		// 下载期间依赖插件被卸载
+		return fmt.Errorf("插件包未下载")
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return m.installPackageLocked(id, f.pkg, f.signature, resolving, fetched)
+}
+
+// enableDependencies 启用前检查依赖（需要持有锁），未启用的依赖插件先被启用
+func (m *Manager) enableDependencies(manifest *PluginManifest, resolving []string) error {
+	problems := m.checkEnvironment(manifest)
+
+	for _, id := range manifest.Dependencies.pluginIDs() {
+		if containsString(resolving, id) {
+			problems = append(problems, fmt.Sprintf("循环依赖: %s -> %s", strings.Join(resolving, " -> "), id))
+			continue
+		}
+		dep, ok := m.plugins[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("依赖插件 %s 未安装，请先安装", id))
+			continue
+		}
+		if p := checkPluginVersion(dep, manifest.Dependencies.Plugins[id]); p != "" {
+			problems = append(problems, p)
+			continue
+		}
+		if dep.State == StateEnabled {
+			continue
+		}
+		if err := m.enableLocked(id, resolving); err != nil {
+			problems = append(problems, fmt.Sprintf("启用依赖插件 %s 失败: %v", id, err))
+		}
+	}
+
+	return dependencyError(manifest.ID, problems)
+}
+
+// dependentsLocked 返回依赖指定插件的其他插件（需要持有锁），enabledOnly 时只返回已启用的
+func (m *Manager) dependentsLocked(id string, enabledOnly bool) []string {
+	var dependents []string
+	for otherID, p := range m.plugins {
+		if enabledOnly && p.State != StateEnabled {
+			continue
+		}
+		if _, ok := p.Manifest.Dependencies.Plugins[id]; ok {
+			dependents = append(dependents, otherID)
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// startOrderLocked 返回已启用插件的启动顺序，依赖的插件排在前面（需要持有锁）
+func (m *Manager) startOrderLocked() []string {
+	ids := make([]string, 0, len(m.plugins))
+	for id, p := range m.plugins {
+		if p.State == StateEnabled {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var order []string
+	visited := make(map[string]bool, len(ids))
+	var visit func(id string)
+	visit = func(id string) {
+		p, ok := m.plugins[id]
+		if visited[id] || !ok || p.State != StateEnabled {
+			return
+		}
+		visited[id] = true
+		for _, dep := range p.Manifest.Dependencies.pluginIDs() {
+			visit(dep)
+		}
+		order = append(order, id)
+	}
+	for _, id := range ids {
+		visit(id)
+	}
+	return order
+}
+
+func dependencyError(id string, problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return errcode.New(errcode.PluginDepsUnmet, "插件 %s 的依赖不满足：%s", id, strings.Join(problems, "；"))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+)
+
+const (
+	// maxTraceLines 保留的插件进程输出行数
+	maxTraceLines = 200
+	// maxTraceLineBytes 单行输出的最大字节数，超出部分截断
+	maxTraceLineBytes = 2048
+	// maxTraceEvents 保留的插件相关事件数
+	maxTraceEvents = 50
+	// maxTraceEventData 事件数据（JSON）的最大字节数
+	maxTraceEventData = 1024
+	// maxGoroutineDump 进程内插件 goroutine 堆栈的最大字节数
+	maxGoroutineDump = 256 << 10
+	// diagnosticsFile 插件目录中保存最近一次诊断信息的文件
+	diagnosticsFile = "diagnostics.json"
+)
+
+// 诊断信息中的事件类型
+const (
+	TraceLifecycle = "lifecycle" // 插件启动、停止和失败
+	TracePublished = "published" // 插件发布到事件总线
+	TraceDelivered = "delivered" // 投递给插件
+)
+
+// Diagnostics 插件进入错误状态时采集的诊断信息，用于远程排查插件故障
+type Diagnostics struct {
+	PluginID     string         `json:"plugin_id"`
+	Version      string         `json:"version"`
+	AgentVersion string         `json:"agent_version"`
+	Platform     string         `json:"platform"`
+	CapturedAt   time.Time      `json:"captured_at"`
+	Reason       string         `json:"reason"`
+	Restarts     int            `json:"restarts"`
+	Config       map[string]any `json:"config,omitempty"` // 敏感配置项已替换为 SecretMask
+	// Logs 插件进程最近的输出（stderr 及插件写到 stdout / stderr 的内容），跨多次启动，以启动标记分隔
+	Logs []string `json:"logs,omitempty"`
+	// Panic 最近一次启动后输出中的 panic 或 fatal error 及其堆栈
+	Panic string `json:"panic,omitempty"`
+	// Goroutines 进程内插件在失败时的 goroutine 堆栈
+	Goroutines string             `json:"goroutines,omitempty"`
+	Events     []TraceEvent       `json:"events,omitempty"`
+	Denials    []PermissionDenial `json:"denials,omitempty"`
+	Usage      *ResourceUsage     `json:"usage,omitempty"`
+}
+
+// TraceEvent 诊断信息中与插件相关的事件
+type TraceEvent struct {
+	Time  time.Time `json:"time"`
+	Kind  string    `json:"kind"`
+	Topic string    `json:"topic"`
+	Data  string    `json:"data,omitempty"` // JSON，超过上限时截断
+}
+
+// pluginTrace 插件最近的输出和事件，插件多次启动共用同一个实例
+type pluginTrace struct {
+	mu      sync.Mutex
+	lines   []string
+	partial []byte // 尚未遇到换行的输出
+	events  []TraceEvent
+}
+
+// Write 实现 io.Writer，按行保存插件进程的输出
+func (t *pluginTrace) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		t.appendLineLocked(string(data[:i]))
+		data = data[i+1:]
+	}
+	if len(data) > maxTraceLineBytes {
+		t.appendLineLocked(string(data))
+		data = nil
+	}
+	t.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (t *pluginTrace) appendLineLocked(line string) {
+	if len(line) > maxTraceLineBytes {
+		line = line[:maxTraceLineBytes] + "…"
+	}
+	t.lines = append(t.lines, line)
+	if len(t.lines) > maxTraceLines {
+		t.lines = t.lines[len(t.lines)-maxTraceLines:]
+	}
+}
+
+// started 在输出中插入启动标记，Panic 只在最后一次启动之后的输出中查找
+func (t *pluginTrace) started() {
+	t.mu.Lock()
+	if len(t.partial) > 0 {
+		t.appendLineLocked(string(t.partial))
+		t.partial = nil
+	}
+	t.appendLineLocked(startMarker + time.Now().Format(time.RFC3339) + " ---")
+	t.mu.Unlock()
+	t.record(TraceLifecycle, "plugin.started", nil)
+}
+
+const startMarker = "--- 插件启动 "
+
+// record 记录一个事件
+func (t *pluginTrace) record(kind, topic string, data any) {
+	e := TraceEvent{Time: time.Now(), Kind: kind, Topic: topic}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			if len(raw) > maxTraceEventData {
+				e.Data = string(raw[:maxTraceEventData]) + "…"
+			} else {
+				e.Data = string(raw)
+			}
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, e)
+	if len(t.events) > maxTraceEvents {
+		t.events = t.events[len(t.events)-maxTraceEvents:]
+	}
+}
+
+// recordEvent 记录事件总线上的事件
+func (t *pluginTrace) recordEvent(kind string, e eventbus.Event) {
+	t.record(kind, e.Topic, e.Data)
+}
+
+// snapshot 返回输出、最后一次启动后的 panic 和事件的副本
+func (t *pluginTrace) snapshot() (lines []string, panicText string, events []TraceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines = append([]string(nil), t.lines...)
+	if len(t.partial) > 0 {
+		lines = append(lines, string(t.partial))
+	}
+	events = append([]TraceEvent(nil), t.events...)
+
+	// Go 运行时的崩溃输出以 "panic: " 或 "fatal error: " 开头，之后是 goroutine 堆栈
+	for i := len(lines) - 1; i >= 0 && !strings.HasPrefix(lines[i], startMarker); i-- {
+		if strings.HasPrefix(lines[i], "panic: ") || strings.HasPrefix(lines[i], "fatal error: ") {
+			panicText = strings.Join(lines[i:], "\n")
+		}
+	}
+	return lines, panicText, events
+}
+
+// traceLocked 返回插件的诊断记录（需要持有锁）
+func (m *Manager) traceLocked(id string) *pluginTrace {
+	t, ok := m.traces[id]
+	if !ok {
+		t = &pluginTrace{}
+		m.traces[id] = t
+	}
+	return t
+}
+
+// captureDiagnosticsLocked 采集插件的诊断信息并保存到插件目录，在插件进入错误状态、停止插件之前调用（需要持有锁）
+func (m *Manager) captureDiagnosticsLocked(id, reason string) {
+	plugin := m.plugins[id]
+	if plugin == nil {
+		return
+	}
+	trace := m.traceLocked(id)
+	trace.record(TraceLifecycle, "plugin.failed", map[string]string{"error": reason})
+
+	d := &Diagnostics{
+		PluginID:     id,
+		Version:      plugin.Manifest.Version,
+		AgentVersion: m.version,
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		CapturedAt:   time.Now(),
+		Reason:       reason,
+		Config:       redactConfig(plugin.Manifest.ConfigSchema, plugin.Config),
+	}
+	d.Logs, d.Panic, d.Events = trace.snapshot()
+	if st := m.restarts[id]; st != nil {
+		d.Restarts = st.count
+	}
+	if rt, ok := m.runtimes[id]; ok {
+		d.Usage = rt.usage
+		_, d.Denials = rt.broker.Denials()
+		if _, external := rt.instance.(*ExternalPlugin); !external {
+			d.Goroutines = pluginGoroutines(id)
+		}
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(m.pluginsDir, id, diagnosticsFile), data, 0600)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("保存插件诊断信息失败")
+	}
+}
+
+// GetPluginDiagnostics 返回插件最近一次进入错误状态时采集的诊断信息
+func (m *Manager) GetPluginDiagnostics(id string) (*Diagnostics, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.plugins[id]; !exists {
+		return nil, errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
+	}
+	data, err := os.ReadFile(filepath.Join(m.pluginsDir, id, diagnosticsFile))
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.NotFound, "插件 %s 没有诊断信息", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var d Diagnostics
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, errcode.Wrap(errcode.Internal, err, "插件诊断信息损坏")
+	}
+	return &d, nil
+}
+
+// sensitiveConfigKeys 配置项名包含这些片段时，即使未在 config_schema 中声明为敏感也隐藏其值
+var sensitiveConfigKeys = []string{"token", "secret", "password", "passwd", "key", "auth", "credential", "cookie"}
+
+// redactConfig 返回用于诊断的配置副本：schema 声明的敏感配置项和名称像凭据的字符串值（含嵌套对象）替换为 SecretMask
+func redactConfig(schema []ConfigField, config map[string]any) map[string]any {
+	return redactValues(maskSecrets(schema, config))
+}
+
+func redactValues(config map[string]any) map[string]any {
+	out := make(map[string]any, len(config))
+	for k, v := range config {
+		switch val := v.(type) {
+		case map[string]any:
+			out[k] = redactValues(val)
+		case string:
+			if val != "" && isSensitiveConfigKey(k) {
+				out[k] = SecretMask
+			} else {
+				out[k] = val
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveConfigKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginGoroutines 返回带有该插件标签的 goroutine 堆栈，格式与 pprof debug=1 相同
+func pluginGoroutines(id string) string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return ""
+	}
+	label := `"` + pluginLabel + `":"` + id + `"`
+	var out strings.Builder
+	// 每组堆栈以空行分隔，带标签的组含有 "# labels: {...}" 行
+	for _, group := range strings.Split(buf.String(), "\n\n") {
+		if strings.Contains(group, "# labels: ") && strings.Contains(group, label) {
+			if out.Len()+len(group) > maxGoroutineDump {
+				break
+			}
+			out.WriteString(group)
+			out.WriteString("\n\n")
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,424 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/collector"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/pkg/pluginsdk"
+	"github.com/shirou/gopsutil/v3/process"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// externalCallTimeout 调用外部插件 RPC 的超时
+	externalCallTimeout = 30 * time.Second
+	// externalWatchInterval 检查插件进程是否退出的间隔
+	externalWatchInterval = 2 * time.Second
+)
+
+// ExternalPlugin 外部进程插件，插件进程通过 go-plugin 握手后以 gRPC 通信
+// 插件进程崩溃只影响该插件，Agent 通过 onExit 得知进程退出
+type ExternalPlugin struct {
+	pluginsDir string
+	pluginID   string
+	entryPoint string
+	onExit     func(err error) // 进程正常退出时 err 为空
+	limits     ResourceLimits
+	isolation  processIsolation // 插件进程的 mount 命名空间和 uid 隔离
+	bus        *eventbus.Bus
+	broker     *Broker      // 插件通过 Host 访问 Agent 时的权限检查
+	subscribe  []string     // 投递给插件的事件主题
+	trace      *pluginTrace // 插件进程的输出和投递的事件，用于诊断
+
+	mu      sync.RWMutex
+	client  *goplugin.Client
+	impl    pluginsdk.Plugin
+	stopped chan struct{}
+	cgroup  *pluginCgroup
+	sub     *eventbus.Subscription
+	metrics *eventbus.Subscription // 向实现了 MetricsHandler 的插件投递指标采样
+
+	// 无 cgroup 时按进程统计 CPU 使用率
+	lastCPU    float64
+	lastSample time.Time
+}
+
+// NewExternalPlugin 创建外部进程插件，entryPoint 是插件目录内的可执行文件
+func NewExternalPlugin(pluginsDir, pluginID, entryPoint string) (*ExternalPlugin, error) {
+	pluginDir := filepath.Join(pluginsDir, pluginID)
+	path := filepath.Join(pluginDir, filepath.Clean("/"+entryPoint))
+	if entryPoint == "" || !strings.HasPrefix(path, pluginDir+string(os.PathSeparator)) {
+		return nil, fmt.Errorf("插件入口无效: %q", entryPoint)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("插件入口不存在: %w", err)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return nil, fmt.Errorf("插件入口不是可执行文件: %s", entryPoint)
+	}
+	return &ExternalPlugin{
+		pluginsDir: pluginsDir,
+		pluginID:   pluginID,
+		entryPoint: path,
+	}, nil
+}
+
+// Start 启动插件进程并调用插件的 Start
+func (p *ExternalPlugin) Start(ctx context.Context, config map[string]any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dataDir := pluginDataDir(p.pluginsDir, p.pluginID)
+	iso := p.isolation
+	iso.Entry = resolvedPath(p.entryPoint)
+	iso.PluginDir = resolvedPath(filepath.Join(p.pluginsDir, p.pluginID))
+	iso.DataDir = resolvedPath(dataDir)
+	cmd, err := iso.command()
+	if err != nil {
+		return fmt.Errorf("创建插件进程失败: %w", err)
+	}
+	cmd.Dir = dataDir
+
+	// cgroup 不可用时插件照常运行，只做进程级统计
+	cg, err := newPluginCgroup(p.pluginID, p.limits)
+	if err != nil {
+		log.Debug().Err(err).Str("plugin", p.pluginID).Msg("未使用 cgroup 限制插件资源")
+		cg = nil
+	}
+	closeFD := func() {}
+	if cg != nil {
+		if closeFD, err = cg.attach(cmd); err != nil {
+			cg.remove()
+			return fmt.Errorf("加入插件 cgroup 失败: %w", err)
+		}
+	}
+
+	plugins := pluginsdk.PluginSet(nil)
+	if p.broker != nil {
+		plugins = pluginsdk.HostPluginSet(p.broker)
+	}
+
+	logger := log.With().Str("plugin", p.pluginID).Logger()
+	clientConfig := &goplugin.ClientConfig{
+		HandshakeConfig:  pluginsdk.Handshake,
+		Plugins:          plugins,
+		Cmd:              cmd,
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin",
+			Output: logger,
+			Level:  hclog.Info,
+		}),
+	}
+	if p.trace != nil {
+		// 原始的 stderr（含 panic 堆栈）和插件写到 stdout / stderr 的内容留作诊断
+		clientConfig.Stderr = p.trace
+		clientConfig.SyncStdout = p.trace
+		clientConfig.SyncStderr = p.trace
+	}
+	client := goplugin.NewClient(clientConfig)
+
+	// 启动失败时结束进程并删除 cgroup
+	fail := func(format string, err error) error {
+		client.Kill()
+		if cg != nil {
+			cg.remove()
+		}
+		return fmt.Errorf(format, err)
+	}
+
+	rpcClient, err := client.Client()
+	closeFD()
+	if err != nil {
+		return fail("启动插件进程失败: %w", err)
+	}
+	raw, err := rpcClient.Dispense(pluginsdk.PluginName)
+	if err != nil {
+		return fail("连接插件失败: %w", err)
+	}
+	impl := raw.(pluginsdk.Plugin)
+	if p.broker != nil {
+		// 插件可能在 Start 中注册定时任务
+		p.broker.setScheduleHandler(impl.(pluginsdk.ScheduleHandler).HandleSchedule)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, externalCallTimeout)
+	defer cancel()
+	if err := impl.Start(callCtx, p.pluginID, config, dataDir); err != nil {
+		return fail("插件启动失败: %w", err)
+	}
+
+	p.client = client
+	p.impl = impl
+	p.cgroup = cg
+	p.lastSample = time.Time{}
+	p.stopped = make(chan struct{})
+	go p.watch(client, cmd, p.stopped)
+	if p.bus != nil && len(p.subscribe) > 0 {
+		p.sub = p.bus.Subscribe("plugin:"+p.pluginID, 0, p.subscribe...)
+		go p.deliver(impl.(pluginsdk.EventHandler), p.sub)
+	}
+	if p.bus != nil && p.broker != nil && p.broker.granted(PermSystemRead) {
+		p.metrics = p.bus.Subscribe("plugin:"+p.pluginID+":metrics", 0, eventbus.TopicMetricsSampled)
+		go p.deliverMetrics(impl.(pluginsdk.MetricsHandler), p.metrics)
+	}
+
+	log.Info().Str("plugin", p.pluginID).Str("entry_point", p.entryPoint).Msg("外部插件已启动")
+	return nil
+}
+
+// watch 等待插件进程退出，非 Stop 引起的退出通知 onExit
+func (p *ExternalPlugin) watch(client *goplugin.Client, cmd *exec.Cmd, stopped chan struct{}) {
+	ticker := time.NewTicker(externalWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopped:
+			return
+		case <-ticker.C:
+			if !client.Exited() {
+				continue
+			}
+			p.mu.Lock()
+			current := p.client == client
+			if current {
+				p.release()
+			}
+			p.mu.Unlock()
+			if !current {
+				return
+			}
+			// Exited 在 cmd.Wait 返回后才为真，此时 ProcessState 已可读
+			var err error
+			switch state := cmd.ProcessState; {
+			case state == nil:
+				err = fmt.Errorf("插件进程意外退出")
+			case !state.Success():
+				err = fmt.Errorf("插件进程意外退出: %s", state)
+			}
+			if err != nil {
+				log.Error().Err(err).Str("plugin", p.pluginID).Msg("外部插件进程意外退出")
+			} else {
+				log.Warn().Str("plugin", p.pluginID).Msg("外部插件进程已退出")
+			}
+			if p.onExit != nil {
+				p.onExit(err)
+			}
+			return
+		}
+	}
+}
+
+// release 清理已退出的插件进程的状态（需要持有锁）
+func (p *ExternalPlugin) release() {
+	if p.sub != nil {
+		p.sub.Close()
+		p.sub = nil
+	}
+	if p.metrics != nil {
+		p.metrics.Close()
+		p.metrics = nil
+	}
+	if p.cgroup != nil {
+		p.cgroup.remove()
+		p.cgroup = nil
+	}
+	p.client = nil
+	p.impl = nil
+}
+
+// deliver 把订阅的事件依次投递给插件，直到订阅被关闭
+func (p *ExternalPlugin) deliver(handler pluginsdk.EventHandler, sub *eventbus.Subscription) {
+	for e := range sub.C {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			log.Debug().Err(err).Str("plugin", p.pluginID).Str("topic", e.Topic).Msg("序列化事件失败")
+			continue
+		}
+		if p.trace != nil {
+			p.trace.recordEvent(TraceDelivered, e)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), externalCallTimeout)
+		err = handler.HandleEvent(ctx, pluginsdk.Event{Topic: e.Topic, Source: e.Source, Time: e.Time, Data: data})
+		cancel()
+		if status.Code(err) == codes.Unimplemented {
+			log.Warn().Str("plugin", p.pluginID).Msg("插件声明了事件订阅但未实现 EventHandler，停止投递")
+			sub.Close()
+			return
+		}
+		if err != nil {
+			log.Debug().Err(err).Str("plugin", p.pluginID).Str("topic", e.Topic).Msg("投递事件失败")
+		}
+	}
+}
+
+// deliverMetrics 把指标采样依次投递给插件，插件未实现 MetricsHandler 时停止投递
+func (p *ExternalPlugin) deliverMetrics(handler pluginsdk.MetricsHandler, sub *eventbus.Subscription) {
+	for e := range sub.C {
+		point, ok := e.Data.(collector.HistoryPoint)
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), externalCallTimeout)
+		err := handler.HandleMetrics(ctx, pluginsdk.SystemMetrics{
+			Time:          time.Unix(point.Timestamp, 0),
+			CPUPercent:    point.CpuUsage,
+			MemoryPercent: point.MemoryUsage,
+			Load1:         point.Load1,
+			NetSent:       point.NetSent,
+			NetRecv:       point.NetRecv,
+			DiskRead:      point.DiskRead,
+			DiskWrite:     point.DiskWrite,
+			Custom:        point.Custom,
+		})
+		cancel()
+		if status.Code(err) == codes.Unimplemented {
+			sub.Close()
+			return
+		}
+		if err != nil {
+			log.Debug().Err(err).Str("plugin", p.pluginID).Msg("投递指标采样失败")
+		}
+	}
+}
+
+// UpdateConfig 通知插件配置已更新，插件未实现 ConfigChangeHandler 或处理失败时返回错误，由 Manager 重启插件
+func (p *ExternalPlugin) UpdateConfig(ctx context.Context, change pluginsdk.ConfigChange) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.impl == nil {
+		return fmt.Errorf("插件未运行")
+	}
+	return p.impl.(pluginsdk.ConfigChangeHandler).HandleConfigChange(ctx, change)
+}
+
+// ServeHTTP 把 HTTP 请求转发给插件的 HTTPHandler
+func (p *ExternalPlugin) ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	p.mu.RLock()
+	impl := p.impl
+	p.mu.RUnlock()
+
+	if impl == nil {
+		return nil, errcode.New(errcode.Unavailable, "插件 %s 未运行", p.pluginID)
+	}
+	resp, err := impl.(httpProxy).ServeHTTP(ctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, errcode.New(errcode.NotFound, "插件 %s 未提供 HTTP 接口", p.pluginID)
+	}
+	return resp, err
+}
+
+// Stop 调用插件的 Stop 后结束插件进程
+func (p *ExternalPlugin) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		return nil
+	}
+	close(p.stopped)
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalCallTimeout)
+	defer cancel()
+	if err := p.impl.Stop(ctx); err != nil {
+		log.Warn().Err(err).Str("plugin", p.pluginID).Msg("外部插件停止失败，结束进程")
+	}
+	// Kill 先尝试优雅关闭，超时后强制结束
+	p.client.Kill()
+	p.release()
+
+	log.Info().Str("plugin", p.pluginID).Msg("外部插件已停止")
+	return nil
+}
+
+// GetStatus 获取插件状态，插件进程不可用时返回错误信息
+func (p *ExternalPlugin) GetStatus() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.client == nil {
+		return map[string]string{"running": "false"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalCallTimeout)
+	defer cancel()
+	stats, err := p.impl.GetStatus(ctx)
+	if err != nil {
+		return map[string]string{"running": "true", "error": err.Error()}
+	}
+	if stats == nil {
+		stats = make(map[string]string)
+	}
+	stats["running"] = "true"
+	if rc := p.client.ReattachConfig(); rc != nil {
+		stats["pid"] = fmt.Sprintf("%d", rc.Pid)
+	}
+	return stats
+}
+
+// HealthCheck 检查插件进程是否存活并调用插件的 HealthCheck
+func (p *ExternalPlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.client == nil || p.client.Exited() {
+		return fmt.Errorf("插件进程未运行")
+	}
+	return p.impl.HealthCheck(ctx)
+}
+
+// resourceUsage 统计插件进程的资源使用：有 cgroup 时读取 cgroup（含插件创建的子进程），否则只统计插件进程
+func (p *ExternalPlugin) resourceUsage() (*ResourceUsage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		return nil, fmt.Errorf("插件未运行")
+	}
+	if p.cgroup != nil {
+		return p.cgroup.usage()
+	}
+
+	rc := p.client.ReattachConfig()
+	if rc == nil {
+		return nil, fmt.Errorf("插件进程未启动")
+	}
+	proc, err := process.NewProcess(int32(rc.Pid))
+	if err != nil {
+		return nil, err
+	}
+	times, err := proc.Times()
+	if err != nil {
+		return nil, err
+	}
+	mem, err := proc.MemoryInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cpu := times.User + times.System
+	u := &ResourceUsage{Accounting: "process", Memory: mem.RSS}
+	if !p.lastSample.IsZero() && cpu >= p.lastCPU {
+		u.CPUPercent = (cpu - p.lastCPU) / now.Sub(p.lastSample).Seconds() * 100
+	}
+	p.lastCPU = cpu
+	p.lastSample = now
+	return u, nil
+}
@@ -0,0 +1,283 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/webhook"
+)
+
+// restartResetAfter 插件连续健康运行超过该时间后重启计数清零
+const restartResetAfter = 10 * time.Minute
+
+// RestartPolicy 插件失败后的重启策略
+type RestartPolicy string
+
+const (
+	// RestartNever 不自动重启
+	RestartNever RestartPolicy = "never"
+	// RestartOnFailure 健康检查连续失败、启动失败或进程异常退出时重启
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartAlways 在 on-failure 之外，插件进程正常退出后同样重启
+	RestartAlways RestartPolicy = "always"
+)
+
+// RestartConfig 插件清单中声明的重启策略，未声明的字段使用 Agent 配置
+type RestartConfig struct {
+	Policy      RestartPolicy `json:"policy,omitempty"`
+	MaxRestarts int           `json:"max_restarts,omitempty"`
+}
+
+// HealthConfig 插件健康检查与重启配置
+type HealthConfig struct {
+	// Interval 健康检查间隔，默认 30 秒
+	Interval time.Duration
+	// Timeout 单次健康检查超时，默认 10 秒
+	Timeout time.Duration
+	// FailureThreshold 连续失败达到该次数后视为插件失败，默认 3
+	FailureThreshold int
+	// Restart 默认重启策略，默认 on-failure，最多重启 5 次
+	Restart RestartConfig
+	// InitialBackoff 首次重启前的等待时间，之后每次翻倍，默认 1 秒
+	InitialBackoff time.Duration
+	// MaxBackoff 重启等待时间的上限，默认 5 分钟
+	MaxBackoff time.Duration
+}
+
+// restartState 插件的自动重启状态
+type restartState struct {
+	count int
+	next  time.Time
+	timer *time.Timer
+}
+
+// effective 合并清单声明的重启策略与配置
+func (c RestartConfig) effective(declared RestartConfig) RestartConfig {
+	switch declared.Policy {
+	case RestartNever, RestartOnFailure, RestartAlways:
+		c.Policy = declared.Policy
+	}
+	if declared.MaxRestarts > 0 {
+		c.MaxRestarts = declared.MaxRestarts
+	}
+	return c
+}
+
+// backoff 第 n 次（从 0 开始）重启前的等待时间
+func (c HealthConfig) backoff(n int) time.Duration {
+	d := c.InitialBackoff
+	for i := 0; i < n && d < c.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, c.MaxBackoff)
+}
+
+// SetHealth 设置插件健康检查与重启策略
+func (m *Manager) SetHealth(cfg HealthConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	switch cfg.Restart.Policy {
+	case RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		cfg.Restart.Policy = RestartOnFailure
+	}
+	if cfg.Restart.MaxRestarts <= 0 {
+		cfg.Restart.MaxRestarts = 5
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = max(5*time.Minute, cfg.InitialBackoff)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health = cfg
+}
+
+// watchHealth 定期对运行中的插件做健康检查
+func (m *Manager) watchHealth() {
+	timer := time.NewTimer(m.checkHealth())
+	defer timer.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(m.checkHealth())
+		}
+	}
+}
+
+// checkHealth 检查一次所有运行中的插件，返回到下次检查的间隔
+// 健康检查可能调用外部插件，检查期间不持有锁
+func (m *Manager) checkHealth() time.Duration {
+	m.mu.RLock()
+	cfg := m.health
+	instances := make(map[string]PluginInstance, len(m.runtimes))
+	for id, runtime := range m.runtimes {
+		if runtime.running && runtime.instance != nil {
+			instances[id] = runtime.instance
+		}
+	}
+	m.mu.RUnlock()
+
+	var (
+		wg      sync.WaitGroup
+		resMu   sync.Mutex
+		results = make(map[string]error, len(instances))
+	)
+	for id, instance := range instances {
+		wg.Add(1)
+		go func(id string, instance PluginInstance) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(m.ctx, cfg.Timeout)
+			defer cancel()
+			err := instance.HealthCheck(ctx)
+			if err == nil && ctx.Err() != nil {
+				err = fmt.Errorf("健康检查超时")
+			}
+			resMu.Lock()
+			results[id] = err
+			resMu.Unlock()
+		}(id, instance)
+	}
+	wg.Wait()
+	if m.ctx.Err() != nil {
+		return cfg.Interval
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, err := range results {
+		runtime, ok := m.runtimes[id]
+		if !ok || runtime.instance != instances[id] {
+			continue
+		}
+		runtime.lastHealthCheck = now
+		if err == nil {
+			runtime.healthFailures = 0
+			if st := m.restarts[id]; st != nil && st.timer == nil && now.Sub(runtime.startTime) >= restartResetAfter {
+				delete(m.restarts, id)
+			}
+			continue
+		}
+
+		runtime.healthFailures++
+		log.Warn().Err(err).Str("plugin", id).Int("failures", runtime.healthFailures).Msg("插件健康检查失败")
+		if runtime.healthFailures >= m.health.FailureThreshold {
+			m.failPluginLocked(id, fmt.Sprintf("连续 %d 次健康检查失败: %v", runtime.healthFailures, err), true)
+		}
+	}
+	return m.health.Interval
+}
+
+// failPluginLocked 停止失败的插件并按重启策略安排重启（需要持有锁）
+// failed 为 false 表示插件进程正常退出，只有 always 策略会重启
+func (m *Manager) failPluginLocked(id, reason string, failed bool) {
+	// 停止插件前采集，进程内插件的 goroutine 堆栈和 Broker 记录随运行时一起释放
+	m.captureDiagnosticsLocked(id, reason)
+	if runtime, ok := m.runtimes[id]; ok {
+		runtime.broker.release()
+		if err := runtime.instance.Stop(); err != nil {
+			log.Warn().Err(err).Str("id", id).Msg("停止插件失败")
+		}
+		close(runtime.stopChan)
+		delete(m.runtimes, id)
+	}
+
+	plugin := m.plugins[id]
+	if plugin == nil {
+		return
+	}
+	plugin.State = StateError
+	plugin.Error = reason
+	log.Error().Str("id", id).Msg(reason)
+
+	policy := m.health.Restart.effective(plugin.Manifest.Restart)
+	if policy.Policy == RestartAlways || (policy.Policy == RestartOnFailure && failed) {
+		m.scheduleRestartLocked(id, policy.MaxRestarts)
+	}
+
+	if err := m.savePlugins(); err != nil {
+		log.Warn().Err(err).Msg("保存插件列表失败")
+	}
+	m.publish(webhook.EventPluginCrashed, map[string]string{"plugin_id": id, "error": plugin.Error})
+}
+
+// scheduleRestartLocked 按指数退避安排重启，达到最大重启次数后插件保持错误状态（需要持有锁）
+func (m *Manager) scheduleRestartLocked(id string, maxRestarts int) {
+	plugin := m.plugins[id]
+	st := m.restarts[id]
+	if st == nil {
+		st = &restartState{}
+		m.restarts[id] = st
+	}
+	if st.timer != nil {
+		return
+	}
+
+	if st.count >= maxRestarts {
+		plugin.Error = fmt.Sprintf("%s；已自动重启 %d 次，达到上限，不再重启", plugin.Error, st.count)
+		m.publish(webhook.EventAlert, map[string]string{
+			"source":    "plugin",
+			"plugin_id": id,
+			"reason":    plugin.Error,
+		})
+		return
+	}
+
+	delay := m.health.backoff(st.count)
+	st.count++
+	st.next = time.Now().Add(delay)
+	plugin.Error = fmt.Sprintf("%s；%s 后第 %d 次重启", plugin.Error, delay, st.count)
+	st.timer = time.AfterFunc(delay, func() { m.restartPlugin(id, st) })
+}
+
+// restartPlugin 执行安排好的重启，期间插件被手动启用、禁用或卸载时放弃
+func (m *Manager) restartPlugin(id string, st *restartState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plugin := m.plugins[id]
+	if m.ctx.Err() != nil || m.restarts[id] != st || plugin == nil || plugin.State != StateError {
+		return
+	}
+	st.timer = nil
+	st.next = time.Time{}
+
+	log.Info().Str("id", id).Int("restarts", st.count).Msg("自动重启插件")
+	if err := m.startPluginLocked(id); err != nil {
+		m.failPluginLocked(id, fmt.Sprintf("插件重启失败: %v", err), true)
+		return
+	}
+
+	plugin.State = StateEnabled
+	plugin.Error = ""
+	if err := m.savePlugins(); err != nil {
+		log.Warn().Err(err).Msg("保存插件列表失败")
+	}
+	log.Info().Str("id", id).Msg("插件已重启")
+}
+
+// resetRestartsLocked 取消待执行的重启并清零重启计数（需要持有锁），用于手动启用、禁用和卸载
+func (m *Manager) resetRestartsLocked(id string) {
+	if st := m.restarts[id]; st != nil {
+		if st.timer != nil {
+			st.timer.Stop()
+		}
+		delete(m.restarts, id)
+	}
+}
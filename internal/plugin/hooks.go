@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/pkg/pluginsdk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// configUpdater 可以不重启而应用新配置的插件实例
+type configUpdater interface {
+	UpdateConfig(ctx context.Context, change pluginsdk.ConfigChange) error
+}
+
+// httpProxy 把 HTTP 请求转发给插件的实例（外部插件及 pluginsdk 的 gRPC 客户端）
+type httpProxy interface {
+	ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error)
+}
+
+// PluginHTTPPrefix 插件 HTTP 接口在 REST API 中的挂载路径
+func PluginHTTPPrefix(id string) string {
+	return "/api/plugins/" + id + "/http"
+}
+
+// ServePluginHTTP 把 REST API 中 PluginHTTPPrefix 下的请求转发给插件
+func (m *Manager) ServePluginHTTP(ctx context.Context, id string, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	m.mu.RLock()
+	_, installed := m.plugins[id]
+	runtime, running := m.runtimes[id]
+	running = running && runtime.running
+	m.mu.RUnlock()
+
+	if !installed {
+		return nil, errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
+	}
+	if !running {
+		return nil, errcode.New(errcode.Unavailable, "插件 %s 未运行", id)
+	}
+	proxy, ok := runtime.instance.(httpProxy)
+	if !ok {
+		return nil, errcode.New(errcode.NotFound, "插件 %s 未提供 HTTP 接口", id)
+	}
+
+	req.Prefix = PluginHTTPPrefix(id)
+	ctx, cancel := context.WithTimeout(ctx, externalCallTimeout)
+	defer cancel()
+	return proxy.ServeHTTP(ctx, req)
+}
+
+// updateConfigLocked 通知运行中的插件配置已更新，插件不支持或处理失败时返回 false，由调用方重启插件（需要持有锁）
+func (m *Manager) updateConfigLocked(id string, runtime *PluginRuntime, old, config map[string]any) bool {
+	updater, ok := runtime.instance.(configUpdater)
+	if !ok {
+		return false
+	}
+	change := configChange(old, config)
+	if len(change.Changed) == 0 {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, externalCallTimeout)
+	defer cancel()
+	if err := updater.UpdateConfig(ctx, change); err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			log.Warn().Err(err).Str("id", id).Msg("插件应用新配置失败，重启插件")
+		}
+		return false
+	}
+	m.traceLocked(id).record(TraceLifecycle, "plugin.config_changed", change.Changed)
+	log.Info().Str("id", id).Strs("changed", change.Changed).Msg("插件已应用新配置")
+	return true
+}
+
+// configChange 比较新旧配置，得到新增、修改和删除的配置项
+func configChange(old, config map[string]any) pluginsdk.ConfigChange {
+	change := pluginsdk.ConfigChange{Old: old, New: config}
+	for k, v := range config {
+		if ov, ok := old[k]; !ok || !reflect.DeepEqual(ov, v) {
+			change.Changed = append(change.Changed, k)
+		}
+	}
+	for k := range old {
+		if _, ok := config[k]; !ok {
+			change.Changed = append(change.Changed, k)
+		}
+	}
+	sort.Strings(change.Changed)
+	return change
+}
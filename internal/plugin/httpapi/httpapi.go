@@ -0,0 +1,55 @@
+// Package httpapi 内置插件 HTTP 接口共用的响应格式，与 Agent REST API 一致
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// response 与 Agent REST API 的 Response 相同
+type response struct {
+	Success bool                     `json:"success"`
+	Data    any                      `json:"data,omitempty"`
+	Error   string                   `json:"error,omitempty"`
+	Code    string                   `json:"code,omitempty"`
+	Details []errcode.FieldViolation `json:"details,omitempty"`
+}
+
+// WriteResult err 不为 nil 时返回错误，否则以 status 返回 data
+func WriteResult(w http.ResponseWriter, status int, data any, err error) {
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	WriteJSON(w, status, data)
+}
+
+// WriteJSON 返回成功响应
+func WriteJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response{Success: true, Data: data})
+}
+
+// WriteError 按错误码返回错误，未携带错误码的错误为 500
+func WriteError(w http.ResponseWriter, err error) {
+	code := errcode.Internal
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		code = e.Code
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errcode.HTTPStatus(code))
+	json.NewEncoder(w).Encode(response{Success: false, Error: err.Error(), Code: string(code), Details: errcode.FieldsOf(err)})
+}
+
+// DecodeJSON 解析请求体，失败时返回 INVALID_ARGUMENT
+func DecodeJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errcode.Wrap(errcode.InvalidArgument, err, "无效的请求体")
+	}
+	return nil
+}
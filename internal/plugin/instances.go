@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
@@ -12,6 +13,10 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/runixo/agent/internal/cloudflare"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/webhook"
+	"github.com/runixo/agent/pkg/pluginsdk"
 )
 
 // GenericPlugin 通用插件实现
@@ -63,16 +68,33 @@ func (p *GenericPlugin) GetStatus() map[string]string {
 	}
 }
 
+// HealthCheck 健康检查，通用插件只检查是否在运行
+func (p *GenericPlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.running {
+		return fmt.Errorf("插件未运行")
+	}
+	return nil
+}
+
 // CloudflarePlugin Cloudflare 安全插件
+//
+// 插件启动时列出 API Token 可访问的所有域名，威胁按来源日志对应的域名封禁（见 cloudflare.ZoneConfig）。
+// 插件通过 HTTP 接口（PluginHTTPPrefix 下，见 cloudflare.SecurityManager.Handler）提供域名、WAF 规则、速率限制规则和封禁管理。
 type CloudflarePlugin struct {
 	pluginsDir string
 	pluginID   string
 	manager    *cloudflare.SecurityManager
+	handler    http.Handler
 	config     *CloudflareConfig
 	running    bool
 	mu         sync.RWMutex
 	ctx        context.Context
 	cancel     context.CancelFunc
+	bus        *eventbus.Bus // 由 Manager 注入，安全事件发布到事件总线
+	broker     *Broker       // 由 Manager 注入，访问 Cloudflare API 和监控日志前检查权限
 }
 
 // CloudflareConfig Cloudflare 插件配置
@@ -84,8 +106,49 @@ type CloudflareConfig struct {
 	BlockDuration  int      `json:"block_duration"`
 	MonitorPaths   []string `json:"monitor_paths"`
 	Enabled        bool     `json:"enabled"`
+	// MonitorSources 指定日志格式（如 sshd、nginx_error、mail、custom）和自定义检测模式的监控路径
+	MonitorSources []cloudflare.LogSource `json:"monitor_sources,omitempty"`
+	// DisabledZones 停用自动封禁的域名（zone 名称或 ID）
+	DisabledZones []string `json:"disabled_zones,omitempty"`
+	// ZoneSources 日志文件到域名的映射，未映射的日志按文件名中的域名匹配
+	ZoneSources map[string]string `json:"zone_sources,omitempty"`
+	// AccountWide 无法确定域名时在账户级别封禁，否则在所有启用的域名上封禁
+	AccountWide bool `json:"account_wide,omitempty"`
+	// CountryRules 按国家/地区封禁或质询，键为域名、"*"（所有启用的域名）或 "account"
+	CountryRules map[string]*cloudflare.CountryRules `json:"country_rules,omitempty"`
+	// BruteForceAction Web 暴力破解的处理方式：ban（默认）封禁 IP，rate_limit 对被暴力破解的路径限速
+	BruteForceAction string `json:"brute_force_action,omitempty"`
+	// BruteForceRateLimit rate_limit 方式下的速率限制参数，为空时每分钟 10 次、超出后封禁 10 分钟
+	BruteForceRateLimit *cloudflare.RateLimitSettings `json:"brute_force_rate_limit,omitempty"`
+	// ActionBands 按威胁评分选择自动封禁的动作，如 [{"min_score":100,"mode":"managed_challenge"},{"min_score":200,"mode":"block"}]，
+	// 已质询的 IP 评分升到 block 分段时改为封禁
+	ActionBands []cloudflare.ActionBand `json:"action_bands,omitempty"`
+	// CollapseThreshold 同一 /24（IPv6 为 /64）网段中自动封禁的 IP 达到该数量时合并为一条网段规则，默认 10，负数表示不合并
+	CollapseThreshold int `json:"collapse_threshold,omitempty"`
+	// DryRun 演练模式：照常检测并在 /dry-run 报告将封禁的 IP，但不创建任何封禁规则
+	DryRun bool `json:"dry_run,omitempty"`
+	// LocalFallback Cloudflare API 不可用时在本机防火墙封禁，插件清单需要声明 nft、iptables 或 netsh 的 exec 权限
+	LocalFallback bool `json:"local_fallback,omitempty"`
+	// LocalSources 不经过 Cloudflare 代理的站点日志，来自这些日志的威胁直接在本机封禁
+	LocalSources []string `json:"local_sources,omitempty"`
+	// Analytics 从 Cloudflare 拉取边缘防火墙事件计入评分，API Token 需要有 Analytics 读取权限
+	Analytics *cloudflare.AnalyticsConfig `json:"analytics,omitempty"`
+	// Sync 与其他服务器上的 Agent 共享自动封禁的 IP，通过发布地址和对方的列表地址（插件清单需要声明 network 权限），
+	// 或由控制面通过 /sync/list 转发；列表以 secret 签名
+	Sync *cloudflare.SyncConfig `json:"sync,omitempty"`
+	// IntelFeeds 威胁情报源（abuseipdb、spamhaus_drop、url），插件清单需要声明对应地址的 network 权限
+	IntelFeeds []*cloudflare.FeedConfig `json:"intel_feeds,omitempty"`
 }
 
+const (
+	// cloudflareAPIHost Cloudflare 插件访问的 API 地址，插件清单需要声明 network 权限
+	cloudflareAPIHost = "api.cloudflare.com:443"
+	// cloudflareConfigKey 插件 KV 存储中保存配置的键
+	cloudflareConfigKey = "config"
+	// highRiskNotifyInterval 同一 IP 的高风险通知的最小间隔，检测器对达到阈值的 IP 的每条日志都会发出威胁
+	highRiskNotifyInterval = time.Hour
+)
+
 // NewCloudflarePlugin 创建 Cloudflare 插件
 func NewCloudflarePlugin(pluginsDir, pluginID string) (*CloudflarePlugin, error) {
 	return &CloudflarePlugin{
@@ -119,6 +182,38 @@ func (p *CloudflarePlugin) Start(ctx context.Context, config map[string]any) err
 	if cfConfig.APIToken == "" {
 		return fmt.Errorf("API Token 未配置")
 	}
+	if err := cloudflare.ValidateCountryRules(cfConfig.CountryRules); err != nil {
+		return err
+	}
+	if err := cloudflare.ValidateLogSources(cfConfig.MonitorSources); err != nil {
+		return err
+	}
+	if err := cloudflare.ValidateFeeds(cfConfig.IntelFeeds); err != nil {
+		return err
+	}
+	if err := cloudflare.ValidateActionBands(cfConfig.ActionBands); err != nil {
+		return err
+	}
+	if cfConfig.Analytics != nil {
+		if err := cfConfig.Analytics.Validate(); err != nil {
+			return err
+		}
+	}
+	if cfConfig.Sync != nil {
+		if err := cfConfig.Sync.Validate(); err != nil {
+			return err
+		}
+	}
+	switch cfConfig.BruteForceAction {
+	case "", cloudflare.BruteForceBan, cloudflare.BruteForceRateLimit:
+	default:
+		return errcode.New(errcode.InvalidArgument, "brute_force_action 必须为 ban 或 rate_limit")
+	}
+	if cfConfig.BruteForceRateLimit != nil {
+		if err := cfConfig.BruteForceRateLimit.Validate(); err != nil {
+			return err
+		}
+	}
 
 	// 创建安全管理器
 	secConfig := cloudflare.DefaultSecurityConfig()
@@ -131,6 +226,34 @@ func (p *CloudflarePlugin) Start(ctx context.Context, config map[string]any) err
 		secConfig.Blocker.DefaultBlockDuration = cfConfig.BlockDuration
 	}
 	secConfig.Blocker.AutoBlockEnabled = cfConfig.AutoBlock
+	secConfig.Blocker.DryRun = cfConfig.DryRun
+	if cfConfig.CollapseThreshold != 0 {
+		secConfig.Blocker.CollapseThreshold = max(cfConfig.CollapseThreshold, 0)
+	}
+	if cfConfig.BruteForceAction != "" {
+		secConfig.Blocker.BruteForceAction = cfConfig.BruteForceAction
+	}
+	secConfig.Blocker.BruteForceRateLimit = cfConfig.BruteForceRateLimit
+	secConfig.Blocker.ActionBands = cfConfig.ActionBands
+	secConfig.Blocker.LocalFallback = cfConfig.LocalFallback
+	secConfig.Blocker.LocalSources = cfConfig.LocalSources
+	secConfig.Analytics = cfConfig.Analytics
+	secConfig.Sync = cfConfig.Sync
+	if len(cfConfig.IntelFeeds) > 0 {
+		secConfig.Intel = &cloudflare.IntelConfig{Feeds: cfConfig.IntelFeeds}
+	}
+	// 配置中没有多域名设置时使用运行中启用或停用域名后保存的设置
+	secConfig.Zones = nil
+	if len(cfConfig.DisabledZones) > 0 || len(cfConfig.ZoneSources) > 0 || cfConfig.AccountWide {
+		secConfig.Zones = cloudflare.DefaultZoneConfig()
+		secConfig.Zones.Disabled = cfConfig.DisabledZones
+		secConfig.Zones.Sources = cfConfig.ZoneSources
+		secConfig.Zones.AccountWide = cfConfig.AccountWide
+	}
+	// 国家/地区规则只来自插件配置，配置中删除的规则在启动时从 Cloudflare 上删除
+	if cfConfig.CountryRules != nil {
+		secConfig.Countries = cfConfig.CountryRules
+	}
 
 	manager, err := cloudflare.NewSecurityManager(secConfig)
 	if err != nil {
@@ -138,6 +261,14 @@ func (p *CloudflarePlugin) Start(ctx context.Context, config map[string]any) err
 	}
 
 	// 配置 Cloudflare
+	if err := p.broker.Check(PermNetwork, cloudflareAPIHost); err != nil {
+		return err
+	}
+	// 威胁情报源和共享封禁列表的请求逐个检查 network 权限
+	manager.SetHTTPClient(p.broker.HTTPClient())
+	// 本机防火墙命令检查 exec 权限
+	b := p.broker
+	manager.SetLookPath(b.lookPath)
 	if err := manager.Configure(cfConfig.APIToken, cfConfig.AccountID); err != nil {
 		return fmt.Errorf("配置 Cloudflare 失败: %w", err)
 	}
@@ -147,14 +278,28 @@ func (p *CloudflarePlugin) Start(ctx context.Context, config map[string]any) err
 		return fmt.Errorf("启动安全管理器失败: %w", err)
 	}
 
-	// 添加监控路径
+	// 添加监控路径，未声明读取权限的路径被跳过
 	for _, path := range cfConfig.MonitorPaths {
+		if err := p.broker.Check(PermFileRead, filepath.Clean(path)); err != nil {
+			log.Warn().Err(err).Str("path", path).Msg("跳过监控路径")
+			continue
+		}
 		if err := manager.AddMonitorPath(path); err != nil {
 			log.Warn().Err(err).Str("path", path).Msg("添加监控路径失败")
 		}
 	}
+	for _, source := range cfConfig.MonitorSources {
+		if err := p.broker.Check(PermFileRead, filepath.Clean(source.Path)); err != nil {
+			log.Warn().Err(err).Str("path", source.Path).Msg("跳过监控路径")
+			continue
+		}
+		if err := manager.AddMonitorSource(source); err != nil {
+			log.Warn().Err(err).Str("path", source.Path).Msg("添加监控路径失败")
+		}
+	}
 
 	p.manager = manager
+	p.handler = manager.Handler(PluginHTTPPrefix(p.pluginID))
 	p.ctx, p.cancel = context.WithCancel(ctx)
 	p.running = true
 
@@ -178,6 +323,7 @@ func (p *CloudflarePlugin) Stop() error {
 		p.manager.Stop()
 	}
 
+	p.handler = nil
 	p.running = false
 	log.Info().Str("plugin", p.pluginID).Msg("Cloudflare 安全插件已停止")
 	return nil
@@ -204,6 +350,32 @@ func (p *CloudflarePlugin) GetStatus() map[string]string {
 	return status
 }
 
+// HealthCheck 健康检查，配置中未启用时插件空闲运行，视为健康
+func (p *CloudflarePlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.manager == nil {
+		return nil
+	}
+	if !p.running || !p.manager.GetStatus().Running {
+		return fmt.Errorf("安全管理器已停止")
+	}
+	return nil
+}
+
+// ServeHTTP 处理 REST API 转发的请求
+func (p *CloudflarePlugin) ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+
+	if handler == nil {
+		return nil, errcode.New(errcode.Unavailable, "插件未运行或未启用")
+	}
+	return pluginsdk.ServeHTTPRequest(ctx, handler, req)
+}
+
 // processEvents 处理安全事件
 func (p *CloudflarePlugin) processEvents() {
 	if p.manager == nil {
@@ -211,6 +383,7 @@ func (p *CloudflarePlugin) processEvents() {
 	}
 
 	events := p.manager.Events()
+	notified := make(map[string]time.Time)
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -223,10 +396,39 @@ func (p *CloudflarePlugin) processEvents() {
 				Str("type", event.Type).
 				Time("timestamp", event.Timestamp).
 				Msg("安全事件")
+
+			if p.bus == nil {
+				continue
+			}
+			// 封禁事件发布为 ip.blocked / ip.unblocked / ip.expired 等，威胁发布为 security.threat，
+			// 同一 IP 每小时首次的威胁另外发布为 security.high_risk 用于通知
+			switch data := event.Data.(type) {
+			case *cloudflare.BlockEvent:
+				p.bus.Publish("ip."+data.Type, data)
+			case *cloudflare.Threat:
+				p.bus.Publish(eventbus.TopicSecurityThreat, data)
+				if shouldNotify(notified, data.IP, event.Timestamp) {
+					p.bus.Publish(webhook.EventHighRisk, data)
+				}
+			}
 		}
 	}
 }
 
+// shouldNotify 距 IP 上次通知是否已超过 highRiskNotifyInterval，是则记录本次通知并清理过期的记录
+func shouldNotify(notified map[string]time.Time, ip string, now time.Time) bool {
+	if last, ok := notified[ip]; ok && now.Sub(last) < highRiskNotifyInterval {
+		return false
+	}
+	for key, last := range notified {
+		if now.Sub(last) >= highRiskNotifyInterval {
+			delete(notified, key)
+		}
+	}
+	notified[ip] = now
+	return true
+}
+
 // GetManager 获取安全管理器（供外部调用）
 func (p *CloudflarePlugin) GetManager() *cloudflare.SecurityManager {
 	p.mu.RLock()
@@ -245,7 +447,7 @@ func (p *CloudflarePlugin) UpdateConfig(config map[string]any) error {
 	return p.Start(context.Background(), config)
 }
 
-// SaveConfig 保存配置到文件
+// SaveConfig 保存配置到插件 KV 存储
 func (p *CloudflarePlugin) SaveConfig() error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -254,25 +456,33 @@ func (p *CloudflarePlugin) SaveConfig() error {
 		return nil
 	}
 
-	configFile := filepath.Join(p.pluginsDir, p.pluginID, "config.json")
-	data, err := json.MarshalIndent(p.config, "", "  ")
+	data, err := json.Marshal(p.config)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configFile, data, 0644)
+	return p.broker.StorageSet(context.Background(), cloudflareConfigKey, data)
 }
 
-// LoadConfig 从文件加载配置
+// LoadConfig 从插件 KV 存储加载配置，存储中没有时迁移旧版的 config.json
 func (p *CloudflarePlugin) LoadConfig() error {
-	configFile := filepath.Join(p.pluginsDir, p.pluginID, "config.json")
-	data, err := os.ReadFile(configFile)
+	data, found, err := p.broker.StorageGet(context.Background(), cloudflareConfigKey)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
 		return err
 	}
+	if !found {
+		legacyFile := filepath.Join(p.pluginsDir, p.pluginID, "config.json")
+		if data, err = os.ReadFile(legacyFile); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if err := p.broker.StorageSet(context.Background(), cloudflareConfigKey, data); err != nil {
+			return err
+		}
+		os.Remove(legacyFile)
+	}
 
 	var config CloudflareConfig
 	if err := json.Unmarshal(data, &config); err != nil {
@@ -298,8 +508,8 @@ func (p *CloudflarePlugin) GetBlockedIPs() []*cloudflare.BlockedIP {
 	return p.manager.GetBlockedIPs()
 }
 
-// BlockIP 手动封禁 IP
-func (p *CloudflarePlugin) BlockIP(ip, zoneID, reason string, duration int) error {
+// BlockIP 手动封禁 IP，zone 为空时按配置在账户级别或所有启用的域名上封禁
+func (p *CloudflarePlugin) BlockIP(ip, zone, reason string, duration int) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -307,12 +517,12 @@ func (p *CloudflarePlugin) BlockIP(ip, zoneID, reason string, duration int) erro
 		return fmt.Errorf("插件未运行")
 	}
 
-	_, err := p.manager.BlockIP(ip, zoneID, reason, duration)
+	_, err := p.manager.BlockIP(ip, zone, reason, duration)
 	return err
 }
 
-// UnblockIP 解封 IP
-func (p *CloudflarePlugin) UnblockIP(ip, zoneID string) error {
+// UnblockIP 解封 IP，zone 为空时解除该 IP 的所有封禁
+func (p *CloudflarePlugin) UnblockIP(ip, zone string) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -320,75 +530,5 @@ func (p *CloudflarePlugin) UnblockIP(ip, zoneID string) error {
 		return fmt.Errorf("插件未运行")
 	}
 
-	return p.manager.UnblockIP(ip, zoneID)
-}
-
-// ScheduledTask 定时任务插件基类
-type ScheduledTask struct {
-	interval time.Duration
-	task     func() error
-	running  bool
-	stopChan chan struct{}
-	mu       sync.RWMutex
-}
-
-// NewScheduledTask 创建定时任务
-func NewScheduledTask(interval time.Duration, task func() error) *ScheduledTask {
-	return &ScheduledTask{
-		interval: interval,
-		task:     task,
-		stopChan: make(chan struct{}),
-	}
-}
-
-// Start 启动定时任务
-func (t *ScheduledTask) Start() {
-	t.mu.Lock()
-	if t.running {
-		t.mu.Unlock()
-		return
-	}
-	t.running = true
-	t.mu.Unlock()
-
-	go func() {
-		ticker := time.NewTicker(t.interval)
-		defer ticker.Stop()
-
-		// 立即执行一次
-		if err := t.task(); err != nil {
-			log.Error().Err(err).Msg("定时任务执行失败")
-		}
-
-		for {
-			select {
-			case <-t.stopChan:
-				return
-			case <-ticker.C:
-				if err := t.task(); err != nil {
-					log.Error().Err(err).Msg("定时任务执行失败")
-				}
-			}
-		}
-	}()
-}
-
-// Stop 停止定时任务
-func (t *ScheduledTask) Stop() {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	if !t.running {
-		return
-	}
-
-	close(t.stopChan)
-	t.running = false
-}
-
-// IsRunning 检查是否运行中
-func (t *ScheduledTask) IsRunning() bool {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	return t.running
+	return p.manager.UnblockIP(ip, zone)
 }
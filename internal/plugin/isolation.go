@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// dataDirName 插件私有数据目录在插件目录中的名称
+const dataDirName = "data"
+
+// IsolationConfig 插件的文件系统隔离
+//
+// 每个插件都有私有的数据目录 <插件目录>/data（0700）。插件经 Broker 读写文件时相对路径以数据目录为根，
+// 插件目录的其余部分只读，其他插件的目录和 ProtectedPaths 即使声明了文件权限也拒绝访问。
+// MountNamespace 和 UIDBase 进一步约束外部进程插件自身的文件访问，需要 Linux 和 root。
+type IsolationConfig struct {
+	// ProtectedPaths 插件不能访问的路径（目录或文件），如 Agent 的配置文件和数据目录，插件目录总是受保护
+	ProtectedPaths []string
+	// MountNamespace 外部插件在独立的 mount 命名空间中运行：受保护的路径被空目录（文件为 /dev/null）覆盖，
+	// 插件只能看到自己的插件目录，且除数据目录外只读
+	MountNamespace bool
+	// UIDBase 非 0 时每个外部插件以 [UIDBase, UIDBase+UIDCount) 中独占的 uid / gid 运行，数据目录归该 uid 所有
+	UIDBase  int
+	UIDCount int
+}
+
+// defaultUIDCount 未配置 UIDCount 时可分配的 uid 数
+const defaultUIDCount = 1000
+
+// SetIsolation 设置插件的文件系统隔离，对之后启动的插件生效
+func (m *Manager) SetIsolation(cfg IsolationConfig) error {
+	if cfg.UIDBase < 0 || cfg.UIDCount < 0 {
+		return errcode.New(errcode.InvalidArgument, "插件 uid 范围无效")
+	}
+	if cfg.UIDBase > 0 && cfg.UIDCount == 0 {
+		cfg.UIDCount = defaultUIDCount
+	}
+	if cfg.MountNamespace || cfg.UIDBase > 0 {
+		if !isolationSupported {
+			return errcode.New(errcode.Unimplemented, "当前平台不支持插件进程隔离")
+		}
+		if os.Geteuid() != 0 {
+			return errcode.New(errcode.PermissionDenied, "插件进程隔离需要以 root 运行")
+		}
+	}
+
+	protected := []string{resolvedPath(m.pluginsDir)}
+	for _, p := range cfg.ProtectedPaths {
+		if !filepath.IsAbs(p) {
+			return errcode.New(errcode.InvalidArgument, "受保护的路径必须是绝对路径: %q", p)
+		}
+		protected = append(protected, resolvedPath(p))
+	}
+	cfg.ProtectedPaths = protected
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isolation = cfg
+	return nil
+}
+
+// pluginDataDir 插件的私有数据目录
+func pluginDataDir(pluginsDir, id string) string {
+	return filepath.Join(pluginsDir, id, dataDirName)
+}
+
+// allocateUIDLocked 为外部插件分配独占的 uid，已分配且仍在范围内时沿用（需要持有锁）
+// 未启用 uid 隔离时返回 0
+func (m *Manager) allocateUIDLocked(plugin *InstalledPlugin) (int, error) {
+	base, count := m.isolation.UIDBase, m.isolation.UIDCount
+	if base == 0 || plugin.Manifest.Protocol != ProtocolGRPC {
+		return 0, nil
+	}
+	if plugin.UID >= base && plugin.UID < base+count {
+		return plugin.UID, nil
+	}
+
+	used := make(map[int]bool, len(m.plugins))
+	for _, p := range m.plugins {
+		used[p.UID] = true
+	}
+	for uid := base; uid < base+count; uid++ {
+		if !used[uid] {
+			plugin.UID = uid
+			if err := m.savePlugins(); err != nil {
+				return 0, err
+			}
+			return uid, nil
+		}
+	}
+	return 0, errcode.New(errcode.Unavailable, "插件 uid 已分配完（%d-%d）", base, base+count-1)
+}
+
+// resolvedPath 解析符号链接后的绝对路径，路径不存在时只做清理
+func resolvedPath(path string) string {
+	path, _ = filepath.Abs(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// pathWithin path 是否为 dir 或其下的路径
+func pathWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(os.PathSeparator))+string(os.PathSeparator))
+}
@@ -0,0 +1,173 @@
+//go:build linux
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const isolationSupported = true
+
+// isolationArg 隔离辅助进程的第一个参数，其后为 JSON 配置
+const isolationArg = "__runixo_plugin_isolation"
+
+// isolationExitCode 辅助进程初始化失败时的退出码
+const isolationExitCode = 125
+
+// processIsolation 外部插件进程的隔离方式
+type processIsolation struct {
+	Entry     string   `json:"entry"`
+	PluginDir string   `json:"plugin_dir"`
+	DataDir   string   `json:"data_dir"`
+	Hidden    []string `json:"hidden,omitempty"` // 以空目录或 /dev/null 覆盖的路径
+	MountNS   bool     `json:"-"`
+	UID       int      `json:"uid,omitempty"`
+}
+
+// command 构建插件进程的命令：启用 mount 命名空间时由 Agent 作为辅助进程完成挂载和降权后再 exec 入口，
+// 否则直接以分配的 uid 启动入口
+func (iso *processIsolation) command() (*exec.Cmd, error) {
+	if !iso.MountNS {
+		cmd := exec.Command(iso.Entry)
+		setCommandUID(cmd, iso.UID)
+		return cmd, nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("找不到 Agent 可执行文件: %w", err)
+	}
+	data, err := json.Marshal(iso)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(self, isolationArg, string(data))
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNS}
+	return cmd, nil
+}
+
+// setCommandUID uid 不为 0 时以该 uid 和同名 gid 运行命令，不保留附加组
+func setCommandUID(cmd *exec.Cmd, uid int) {
+	if uid == 0 {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(uid), Groups: []uint32{}}
+}
+
+// RunIsolationHelper 在 main 开头调用。进程作为插件隔离辅助进程启动时完成挂载和降权后 exec 插件入口，
+// 不会返回；否则直接返回
+func RunIsolationHelper() {
+	if len(os.Args) != 3 || os.Args[1] != isolationArg {
+		return
+	}
+	var iso processIsolation
+	err := json.Unmarshal([]byte(os.Args[2]), &iso)
+	if err == nil {
+		err = iso.enter()
+	}
+	fmt.Fprintf(os.Stderr, "插件隔离初始化失败: %v\n", err)
+	os.Exit(isolationExitCode)
+}
+
+// enter 在辅助进程的 mount 命名空间中覆盖受保护的路径，重新挂载插件目录，降权后 exec 入口，成功时不返回
+func (iso *processIsolation) enter() error {
+	// 挂载变更不能传播回主机
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("设置挂载传播失败: %w", err)
+	}
+	// 插件目录通常位于受保护的路径之下，覆盖前保留其引用
+	fd, err := unix.Open(iso.PluginDir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("打开插件目录失败: %w", err)
+	}
+	defer unix.Close(fd)
+
+	for _, p := range iso.Hidden {
+		info, err := os.Stat(p)
+		if err != nil {
+			// 不存在或已被上层路径覆盖
+			continue
+		}
+		if info.IsDir() {
+			err = unix.Mount("tmpfs", p, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "mode=0755,size=64k")
+		} else {
+			err = unix.Mount("/dev/null", p, "", unix.MS_BIND, "")
+		}
+		if err != nil {
+			return fmt.Errorf("覆盖 %s 失败: %w", p, err)
+		}
+	}
+
+	if err := os.MkdirAll(iso.PluginDir, 0755); err != nil {
+		return err
+	}
+	if err := unix.Mount(fmt.Sprintf("/proc/self/fd/%d", fd), iso.PluginDir, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("挂载插件目录失败: %w", err)
+	}
+	// 数据目录单独挂载后保持可写，插件目录的其余部分只读
+	if err := unix.Mount(iso.DataDir, iso.DataDir, "", unix.MS_BIND, ""); err != nil {
+		return fmt.Errorf("挂载数据目录失败: %w", err)
+	}
+	if err := unix.Mount("", iso.PluginDir, "", unix.MS_REMOUNT|unix.MS_BIND|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("只读挂载插件目录失败: %w", err)
+	}
+	if err := os.Chdir(iso.DataDir); err != nil {
+		return fmt.Errorf("切换工作目录失败: %w", err)
+	}
+
+	if iso.UID != 0 {
+		// syscall 的 Setuid 等作用于进程的所有线程
+		if err := syscall.Setgroups(nil); err != nil {
+			return fmt.Errorf("清除附加组失败: %w", err)
+		}
+		if err := syscall.Setgid(iso.UID); err != nil {
+			return fmt.Errorf("切换 gid 失败: %w", err)
+		}
+		if err := syscall.Setuid(iso.UID); err != nil {
+			return fmt.Errorf("切换 uid 失败: %w", err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("设置 no_new_privs 失败: %w", err)
+	}
+	return syscall.Exec(iso.Entry, []string{iso.Entry}, os.Environ())
+}
+
+// prepareDataDir 创建插件的私有数据目录，所有者与插件进程的 uid 不一致时（如启用或关闭了 uid 隔离）递归修改
+// uid 为 0 时归 Agent 用户所有
+func prepareDataDir(dir string, uid int) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	owner, group := uid, uid
+	if uid == 0 {
+		owner, group = os.Getuid(), os.Getgid()
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) == owner && int(st.Gid) == group {
+		return nil
+	}
+	return filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, owner, group)
+	})
+}
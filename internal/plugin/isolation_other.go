@@ -0,0 +1,35 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"os"
+	"os/exec"
+)
+
+const isolationSupported = false
+
+// processIsolation 非 Linux 平台不支持进程隔离，SetIsolation 已拒绝相关选项
+type processIsolation struct {
+	Entry     string
+	PluginDir string
+	DataDir   string
+	Hidden    []string
+	MountNS   bool
+	UID       int
+}
+
+func (iso *processIsolation) command() (*exec.Cmd, error) {
+	return exec.Command(iso.Entry), nil
+}
+
+// setCommandUID 该平台不分配插件 uid，uid 始终为 0
+func setCommandUID(cmd *exec.Cmd, uid int) {}
+
+// RunIsolationHelper 该平台不支持插件进程隔离
+func RunIsolationHelper() {}
+
+// prepareDataDir 创建插件的私有数据目录
+func prepareDataDir(dir string, uid int) error {
+	return os.MkdirAll(dir, 0700)
+}
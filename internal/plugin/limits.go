@@ -0,0 +1,223 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/webhook"
+)
+
+// pluginLabel 进程内插件 goroutine 的 pprof 标签，插件在 Start 中创建的 goroutine 继承该标签
+const pluginLabel = "runixo_plugin"
+
+// ResourceLimits 插件资源限制，零值字段表示不限制
+//
+// 外部进程插件的 CPU 和内存优先由 cgroup v2 限制（需要 root），不可用时只做统计；
+// 进程内插件与 Agent 共享进程，无法单独统计 CPU 和内存，只统计 goroutine 数。
+type ResourceLimits struct {
+	CPUPercent float64 `json:"cpu_percent,omitempty"` // 占单个 CPU 的百分比
+	Memory     uint64  `json:"memory,omitempty"`      // 字节
+	Goroutines int     `json:"goroutines,omitempty"`  // 仅进程内插件
+}
+
+// LimitsConfig 插件资源限制配置
+type LimitsConfig struct {
+	// Default 插件的默认限制，同时是清单中声明的限制的上限
+	Default ResourceLimits
+	// Interval 统计间隔，默认 10 秒
+	Interval time.Duration
+	// MaxViolations 连续超限达到该次数后自动禁用插件，默认 3
+	MaxViolations int
+	// Storage 每个插件 KV 存储的总大小（字节），默认 8 MB
+	Storage int
+}
+
+// ResourceUsage 插件资源使用情况
+type ResourceUsage struct {
+	// Accounting 统计方式：cgroup、process（外部进程，无 cgroup）或 goroutine（进程内插件）
+	Accounting string         `json:"accounting"`
+	CPUPercent float64        `json:"cpu_percent"`
+	Memory     uint64         `json:"memory"`
+	Goroutines int            `json:"goroutines"`
+	Limits     ResourceLimits `json:"limits"`
+	// Violations 连续超限的统计周期数
+	Violations int       `json:"violations"`
+	SampledAt  time.Time `json:"sampled_at"`
+
+	// cgroup 限制下用量不会超过限制，以限流和内存上限回收判断是否达到限制
+	cpuThrottled  bool
+	memoryLimited bool
+}
+
+// resourceAccounter 能单独统计资源的插件实例（外部进程插件）
+type resourceAccounter interface {
+	resourceUsage() (*ResourceUsage, error)
+}
+
+// effective 合并清单声明的限制与配置：清单未声明或超过配置时使用配置值
+func (l ResourceLimits) effective(declared ResourceLimits) ResourceLimits {
+	pick := func(declared, limit float64) float64 {
+		if declared > 0 && (limit == 0 || declared < limit) {
+			return declared
+		}
+		return limit
+	}
+	return ResourceLimits{
+		CPUPercent: pick(declared.CPUPercent, l.CPUPercent),
+		Memory:     uint64(pick(float64(declared.Memory), float64(l.Memory))),
+		Goroutines: int(pick(float64(declared.Goroutines), float64(l.Goroutines))),
+	}
+}
+
+// exceeded 返回超出限制的说明，未超出时返回空
+func (u *ResourceUsage) exceeded() string {
+	var reasons []string
+	if u.Limits.CPUPercent > 0 && (u.CPUPercent > u.Limits.CPUPercent || u.cpuThrottled) {
+		reasons = append(reasons, fmt.Sprintf("CPU %.1f%%（限制 %.1f%%）", u.CPUPercent, u.Limits.CPUPercent))
+	}
+	if u.Limits.Memory > 0 && (u.Memory > u.Limits.Memory || u.memoryLimited) {
+		reasons = append(reasons, fmt.Sprintf("内存 %d MB（限制 %d MB）", u.Memory>>20, u.Limits.Memory>>20))
+	}
+	if u.Limits.Goroutines > 0 && u.Goroutines > u.Limits.Goroutines {
+		reasons = append(reasons, fmt.Sprintf("goroutine %d 个（限制 %d）", u.Goroutines, u.Limits.Goroutines))
+	}
+	return strings.Join(reasons, "，")
+}
+
+// startLabeled 在带插件标签的 goroutine 上下文中调用 Start，使插件创建的 goroutine 可以按插件统计
+func startLabeled(ctx context.Context, id string, instance PluginInstance, config map[string]any) error {
+	var err error
+	pprof.Do(ctx, pprof.Labels(pluginLabel, id), func(ctx context.Context) {
+		err = instance.Start(ctx, config)
+	})
+	return err
+}
+
+// countGoroutines 按插件标签统计 goroutine 数
+func countGoroutines() map[string]int {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil
+	}
+
+	// debug=1 格式：每组以 "<数量> @ <地址...>" 开头，带标签的组随后有一行 "# labels: {...}"
+	counts := make(map[string]int)
+	count := 0
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if n, _, ok := strings.Cut(line, " @ "); ok {
+			count, _ = strconv.Atoi(n)
+			continue
+		}
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok {
+			continue
+		}
+		var m map[string]string
+		if json.Unmarshal([]byte(labels), &m) == nil && m[pluginLabel] != "" {
+			counts[m[pluginLabel]] += count
+		}
+	}
+	return counts
+}
+
+// SetLimits 设置插件资源限制，对之后启动的插件生效
+func (m *Manager) SetLimits(cfg LimitsConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.MaxViolations <= 0 {
+		cfg.MaxViolations = 3
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits = cfg
+}
+
+// watchResources 定期统计运行中插件的资源使用，连续超限的插件被自动禁用
+func (m *Manager) watchResources() {
+	timer := time.NewTimer(m.sampleResources())
+	defer timer.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(m.sampleResources())
+		}
+	}
+}
+
+// sampleResources 统计一次资源使用，返回到下次统计的间隔
+func (m *Manager) sampleResources() time.Duration {
+	goroutines := countGoroutines()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, runtime := range m.runtimes {
+		if !runtime.running || runtime.instance == nil {
+			continue
+		}
+
+		usage := &ResourceUsage{Accounting: "goroutine", Goroutines: goroutines[id]}
+		if acc, ok := runtime.instance.(resourceAccounter); ok {
+			u, err := acc.resourceUsage()
+			if err != nil {
+				log.Debug().Err(err).Str("plugin", id).Msg("统计插件资源失败")
+				continue
+			}
+			usage = u
+		}
+		usage.Limits = runtime.limits
+		usage.SampledAt = time.Now()
+
+		reason := usage.exceeded()
+		if reason == "" {
+			runtime.usage = usage
+			continue
+		}
+		usage.Violations = 1
+		if runtime.usage != nil {
+			usage.Violations = runtime.usage.Violations + 1
+		}
+		runtime.usage = usage
+		log.Warn().Str("plugin", id).Int("violations", usage.Violations).Msg("插件超出资源限制: " + reason)
+
+		if usage.Violations >= m.limits.MaxViolations {
+			m.disableForLimits(id, fmt.Sprintf("连续 %d 次超出资源限制（%s），已自动禁用", usage.Violations, reason))
+		}
+	}
+	return m.limits.Interval
+}
+
+// disableForLimits 停止并禁用超出资源限制的插件（需要持有锁）
+func (m *Manager) disableForLimits(id, reason string) {
+	m.resetRestartsLocked(id)
+	if err := m.stopPluginLocked(id); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("停止插件失败")
+	}
+	if plugin := m.plugins[id]; plugin != nil {
+		plugin.State = StateDisabled
+		plugin.Error = reason
+		if err := m.savePlugins(); err != nil {
+			log.Warn().Err(err).Msg("保存插件列表失败")
+		}
+	}
+	log.Error().Str("id", id).Msg(reason)
+	m.publish(webhook.EventAlert, map[string]string{
+		"source":    "plugin",
+		"plugin_id": id,
+		"reason":    reason,
+	})
+}
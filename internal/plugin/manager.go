@@ -3,6 +3,7 @@ package plugin
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"crypto/sha256"
@@ -13,11 +14,17 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/scheduler"
+	"github.com/runixo/agent/internal/webhook"
 )
 
 // PluginState 插件状态
@@ -40,19 +47,29 @@ const (
 	TypeHybrid PluginType = "hybrid" // 混合
 )
 
+// ProtocolGRPC 外部进程插件协议（hashicorp/go-plugin + gRPC，见 pkg/pluginsdk）
+const ProtocolGRPC = "grpc"
+
 // PluginManifest 插件清单
 type PluginManifest struct {
-	ID           string         `json:"id"`
-	Name         string         `json:"name"`
-	Version      string         `json:"version"`
-	Description  string         `json:"description"`
-	Author       string         `json:"author"`
-	Icon         string         `json:"icon"`
-	Type         PluginType     `json:"type"`
-	Permissions  []string       `json:"permissions"`
-	EntryPoint   string         `json:"entry_point"` // 入口脚本或二进制
-	Config       map[string]any `json:"config"`      // 默认配置
-	Dependencies []string       `json:"dependencies"`
+	// ManifestVersion 清单格式版本，决定安装时使用的校验 schema，未声明时为 1
+	ManifestVersion int            `json:"manifest_version,omitempty"`
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	Version         string         `json:"version"`
+	Description     string         `json:"description"`
+	Author          string         `json:"author"`
+	Icon            string         `json:"icon"`
+	Type            PluginType     `json:"type"`
+	Permissions     []string       `json:"permissions"`
+	EntryPoint      string         `json:"entry_point"`   // 入口脚本或二进制
+	Protocol        string         `json:"protocol"`      // 为 grpc 时 entry_point 作为外部进程插件运行
+	Resources       ResourceLimits `json:"resources"`     // 插件申请的资源限制，不超过 Agent 配置的上限
+	Restart         RestartConfig  `json:"restart"`       // 重启策略，未声明时使用 Agent 配置
+	Subscribe       []string       `json:"subscribe"`     // 外部进程插件订阅的事件主题，如 "metrics.sampled"、"ip.*"
+	Config          map[string]any `json:"config"`        // 默认配置
+	ConfigSchema    []ConfigField  `json:"config_schema"` // 配置项的类型、默认值和表单提示，声明后 SetPluginConfig 按其校验
+	Dependencies    Dependencies   `json:"dependencies"`
 }
 
 // InstalledPlugin 已安装的插件
@@ -63,6 +80,19 @@ type InstalledPlugin struct {
 	UpdatedAt   time.Time       `json:"updated_at"`
 	Config      map[string]any  `json:"config"`
 	Error       string          `json:"error,omitempty"`
+	// Signer 签名公钥的指纹，未签名的插件为空
+	Signer string `json:"signer,omitempty"`
+	// SHA256 安装时插件包的摘要
+	SHA256 string `json:"sha256,omitempty"`
+	// UID 启用 uid 隔离时分配给外部插件进程的 uid
+	UID int `json:"uid,omitempty"`
+}
+
+// redacted 返回敏感配置项替换为 SecretMask 的副本，用于对外输出
+func (p *InstalledPlugin) redacted() *InstalledPlugin {
+	cp := *p
+	cp.Config = maskSecrets(p.Manifest.ConfigSchema, p.Config)
+	return &cp
 }
 
 // PluginStatus 插件运行状态
@@ -73,6 +103,16 @@ type PluginStatus struct {
 	Error    string            `json:"error,omitempty"`
 	Uptime   int64             `json:"uptime"`
 	Stats    map[string]string `json:"stats"`
+	Usage    *ResourceUsage    `json:"usage,omitempty"`
+	// Restarts 自动重启次数，插件连续健康运行一段时间后清零
+	Restarts int `json:"restarts"`
+	// NextRestart 下次自动重启的时间，没有待执行的重启时为空
+	NextRestart *time.Time `json:"next_restart,omitempty"`
+	// LastHealthCheck 最近一次健康检查的时间
+	LastHealthCheck *time.Time `json:"last_health_check,omitempty"`
+	// PermissionDenials 本次运行中因未声明权限被拒绝的访问次数，Denials 为最近的记录
+	PermissionDenials int                `json:"permission_denials"`
+	Denials           []PermissionDenial `json:"denials,omitempty"`
 }
 
 // Manager 插件管理器
@@ -84,6 +124,22 @@ type Manager struct {
 	ctx        context.Context
 	cancel     context.CancelFunc
 	repoURL    string
+	registry   *Registry
+	version    string
+	limits     LimitsConfig
+	health     HealthConfig
+	restarts   map[string]*restartState
+	storages   map[string]*pluginStorage
+	traces     map[string]*pluginTrace
+	verifier   *packageVerifier
+	client     *http.Client // 下载插件包
+	scheduler  *scheduler.Scheduler
+	isolation  IsolationConfig
+
+	// 事件总线和审计日志单独加锁，插件启动时会在持有 mu 的情况下发布事件
+	bus      *eventbus.Bus
+	audit    *audit.Logger
+	eventsMu sync.RWMutex
 }
 
 // PluginRuntime 插件运行时接口
@@ -93,6 +149,12 @@ type PluginRuntime struct {
 	startTime time.Time
 	stopChan  chan struct{}
 	instance  PluginInstance
+	limits    ResourceLimits
+	usage     *ResourceUsage // 最近一次资源统计
+	broker    *Broker        // 按清单权限放行插件的访问
+
+	healthFailures  int // 连续健康检查失败次数
+	lastHealthCheck time.Time
 }
 
 // PluginInstance 插件实例接口
@@ -100,6 +162,8 @@ type PluginInstance interface {
 	Start(ctx context.Context, config map[string]any) error
 	Stop() error
 	GetStatus() map[string]string
+	// HealthCheck 检查插件是否正常工作，返回错误时由 Manager 按重启策略处理
+	HealthCheck(ctx context.Context) error
 }
 
 // NewManager 创建插件管理器
@@ -114,15 +178,25 @@ func NewManager(pluginsDir string) (*Manager, error) {
 		pluginsDir: pluginsDir,
 		plugins:    make(map[string]*InstalledPlugin),
 		runtimes:   make(map[string]*PluginRuntime),
+		restarts:   make(map[string]*restartState),
+		storages:   make(map[string]*pluginStorage),
+		traces:     make(map[string]*pluginTrace),
 		ctx:        ctx,
 		cancel:     cancel,
 		repoURL:    "https://plugins.runixo.dev",
+		verifier:   &packageVerifier{},
+		client:     newPackageClient(),
+		limits:     LimitsConfig{Interval: 10 * time.Second, MaxViolations: 3},
+		isolation:  IsolationConfig{ProtectedPaths: []string{resolvedPath(pluginsDir)}},
 	}
+	m.SetHealth(HealthConfig{})
 
 	// 加载已安装的插件
 	if err := m.loadPlugins(); err != nil {
 		log.Warn().Err(err).Msg("加载插件列表失败")
 	}
+	go m.watchResources()
+	go m.watchHealth()
 
 	return m, nil
 }
@@ -152,14 +226,12 @@ func (m *Manager) loadPlugins() error {
 	return nil
 }
 
-// savePlugins 保存插件列表
+// savePlugins 保存插件列表（需要持有锁）
 func (m *Manager) savePlugins() error {
-	m.mu.RLock()
 	plugins := make([]*InstalledPlugin, 0, len(m.plugins))
 	for _, p := range m.plugins {
 		plugins = append(plugins, p)
 	}
-	m.mu.RUnlock()
 
 	data, err := json.MarshalIndent(plugins, "", "  ")
 	if err != nil {
@@ -167,63 +239,165 @@ func (m *Manager) savePlugins() error {
 	}
 
 	installedFile := filepath.Join(m.pluginsDir, "installed.json")
-	return os.WriteFile(installedFile, data, 0644)
+	return os.WriteFile(installedFile, data, 0600)
 }
 
-// ListPlugins 列出所有插件
+// ListPlugins 列出所有插件，敏感配置项替换为 SecretMask
 func (m *Manager) ListPlugins() []*InstalledPlugin {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	plugins := make([]*InstalledPlugin, 0, len(m.plugins))
 	for _, p := range m.plugins {
-		plugins = append(plugins, p)
+		plugins = append(plugins, p.redacted())
 	}
+	// 按 ID 排序，保证输出稳定（ETag 依赖顺序）
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Manifest.ID < plugins[j].Manifest.ID
+	})
 	return plugins
 }
 
-// GetPlugin 获取插件
+// GetPlugin 获取插件，敏感配置项替换为 SecretMask；未安装时返回 nil
 func (m *Manager) GetPlugin(id string) *InstalledPlugin {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.plugins[id]
+	if p, ok := m.plugins[id]; ok {
+		return p.redacted()
+	}
+	return nil
+}
+
+// 插件安装来源
+const (
+	SourceOfficial = "official" // 官方仓库，按插件 ID 下载
+	SourceURL      = "url"      // 任意 http(s) 地址
+	SourceLocal    = "local"    // 随请求上传的插件包
+)
+
+// InstallRequest 安装插件的请求
+type InstallRequest struct {
+	Source    string    // SourceOfficial、SourceURL 或 SourceLocal，为空时为 SourceOfficial
+	URL       string    // SourceURL 的下载地址
+	Data      []byte    // SourceLocal 的插件包
+	Package   io.Reader // SourceLocal 的插件包（流式上传），不为空时忽略 Data
+	Signature []byte    // 插件包签名（base64）；SourceURL 不提供时下载 <URL>.sig
+	SHA256    string    // 插件包的 SHA-256（十六进制），不为空时必须一致
 }
 
 // InstallPlugin 安装插件
-func (m *Manager) InstallPlugin(id, source, url string, data []byte) error {
+// 插件包的签名必须由受信任的发布者验证通过（见 SetSigning）；local 来源的签名随请求提交。
+// 插件包及其缺少的依赖插件在加锁前下载或接收完毕，下载或上传较慢时不阻塞其他插件操作。
+func (m *Manager) InstallPlugin(id string, req InstallRequest) error {
+	if !validPluginID.MatchString(id) {
+		return errcode.New(errcode.InvalidArgument, "插件 ID 格式无效: %s", id)
+	}
+
+	m.mu.RLock()
+	_, exists := m.plugins[id]
+	repoURL := m.repoURL
+	m.mu.RUnlock()
+	if exists {
+		return errcode.New(errcode.PluginExists, "插件 %s 已安装", id)
+	}
+
+	pkg, signature, err := m.fetchPackage(id, repoURL, req)
+	if err != nil {
+		return err
+	}
+	fetched := fetchedPackages{id: {pkg: pkg, signature: signature}}
+	defer fetched.close()
+	m.fetchDependencies(pkg, repoURL, fetched)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// 检查是否已安装
+	// 检查是否已安装（获取插件包期间可能已被安装）
 	if _, exists := m.plugins[id]; exists {
-		return fmt.Errorf("插件 %s 已安装", id)
+		return errcode.New(errcode.PluginExists, "插件 %s 已安装", id)
 	}
 
-	pluginDir := filepath.Join(m.pluginsDir, id)
+	return m.installPackageLocked(id, pkg, signature, nil, fetched)
+}
 
-	var err error
-	switch source {
-	case "official":
-		err = m.downloadFromRepo(id, pluginDir)
-	case "url":
-		err = m.downloadFromURL(url, pluginDir)
-	case "local":
-		err = m.extractFromData(data, pluginDir)
+// fetchPackage 按安装来源获取插件包和签名，并校验请求中的 SHA-256
+func (m *Manager) fetchPackage(id, repoURL string, req InstallRequest) (*packageFile, []byte, error) {
+	want, err := parseDigest(req.SHA256)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		pkg       *packageFile
+		signature []byte
+	)
+	switch req.Source {
+	case SourceOfficial, "":
+		pkg, signature, err = downloadPackage(m.client, m.pluginsDir, fmt.Sprintf("%s/plugins/%s/latest.tar.gz", repoURL, id))
+	case SourceURL:
+		if req.URL == "" {
+			return nil, nil, errcode.New(errcode.InvalidArgument, "插件包地址不能为空")
+		}
+		pkg, signature, err = downloadPackage(m.client, m.pluginsDir, req.URL)
+		if len(bytes.TrimSpace(req.Signature)) > 0 {
+			signature = req.Signature
+		}
+	case SourceLocal:
+		r := req.Package
+		if r == nil {
+			r = bytes.NewReader(req.Data)
+		}
+		pkg, err = spoolPackage(m.pluginsDir, r)
+		signature = req.Signature
 	default:
-		return fmt.Errorf("未知的安装来源: %s", source)
+		return nil, nil, errcode.New(errcode.InvalidArgument, "未知的安装来源: %s", req.Source)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取插件包失败: %w", err)
+	}
+	if got := hex.EncodeToString(pkg.digest); want != "" && got != want {
+		pkg.Close()
+		return nil, nil, errcode.New(errcode.PluginVerifyFailed, "插件包 SHA-256 不一致: 期望 %s，实际 %s", want, got)
 	}
+	return pkg, signature, nil
+}
 
+// installPackageLocked 验证签名后解压插件包并登记插件（需要持有锁）
+// 缺少的依赖插件从 fetched 中安装，fetched 为加锁前下载的依赖插件包
+func (m *Manager) installPackageLocked(id string, pkg *packageFile, signature []byte, resolving []string, fetched fetchedPackages) error {
+	signer, err := m.verifier.verify(pkg.digest, signature)
 	if err != nil {
+		return err
+	}
+	if signer == "" {
+		log.Warn().Str("id", id).Msg("安装未签名的插件包")
+	}
+
+	pluginDir := filepath.Join(m.pluginsDir, id)
+	if _, err := pkg.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := m.extractTarGz(pkg, pluginDir); err != nil {
 		os.RemoveAll(pluginDir)
 		return fmt.Errorf("安装插件失败: %w", err)
 	}
 
-	// 读取插件清单
+	// 读取并校验插件清单，清单无效时不安装，避免到启用时才失败
 	manifest, err := m.readManifest(pluginDir)
 	if err != nil {
 		os.RemoveAll(pluginDir)
 		return fmt.Errorf("读取插件清单失败: %w", err)
 	}
+	// 签名只证明包来自受信任的发布者，清单中的 ID 还必须与请求安装的一致
+	if manifest.ID != id {
+		os.RemoveAll(pluginDir)
+		return errcode.New(errcode.PluginVerifyFailed, "插件清单的 ID %q 与请求安装的 %q 不一致", manifest.ID, id)
+	}
+	// 缺少的依赖插件从加锁前下载的插件包安装，其余依赖不满足时放弃安装
+	if err := m.resolveDependencies(manifest, append(resolving, id), fetched); err != nil {
+		os.RemoveAll(pluginDir)
+		return err
+	}
 
 	// 创建插件记录
 	plugin := &InstalledPlugin{
@@ -231,7 +405,9 @@ func (m *Manager) InstallPlugin(id, source, url string, data []byte) error {
 		State:       StateInstalled,
 		InstalledAt: time.Now(),
 		UpdatedAt:   time.Now(),
-		Config:      manifest.Config,
+		Config:      defaultConfig(manifest),
+		Signer:      signer,
+		SHA256:      hex.EncodeToString(pkg.digest),
 	}
 
 	m.plugins[id] = plugin
@@ -240,7 +416,7 @@ func (m *Manager) InstallPlugin(id, source, url string, data []byte) error {
 		log.Warn().Err(err).Msg("保存插件列表失败")
 	}
 
-	log.Info().Str("id", id).Str("version", manifest.Version).Msg("插件安装成功")
+	log.Info().Str("id", id).Str("version", manifest.Version).Str("signer", signer).Msg("插件安装成功")
 	return nil
 }
 
@@ -251,10 +427,14 @@ func (m *Manager) UninstallPlugin(id string) error {
 
 	plugin, exists := m.plugins[id]
 	if !exists {
-		return fmt.Errorf("插件 %s 未安装", id)
+		return errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
+	}
+	if dependents := m.dependentsLocked(id, false); len(dependents) > 0 {
+		return errcode.New(errcode.PluginDepsUnmet, "插件 %s 被 %s 依赖，请先卸载这些插件", id, strings.Join(dependents, ", "))
 	}
 
 	// 停止运行中的插件
+	m.resetRestartsLocked(id)
 	if runtime, ok := m.runtimes[id]; ok && runtime.running {
 		if err := m.stopPluginLocked(id); err != nil {
 			log.Warn().Err(err).Str("id", id).Msg("停止插件失败")
@@ -269,6 +449,8 @@ func (m *Manager) UninstallPlugin(id string) error {
 
 	delete(m.plugins, id)
 	delete(m.runtimes, id)
+	delete(m.storages, id)
+	delete(m.traces, id)
 
 	if err := m.savePlugins(); err != nil {
 		log.Warn().Err(err).Msg("保存插件列表失败")
@@ -285,17 +467,33 @@ func (m *Manager) EnablePlugin(id string) error {
 
 	plugin, exists := m.plugins[id]
 	if !exists {
-		return fmt.Errorf("插件 %s 未安装", id)
+		return errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
 	}
 
 	if plugin.State == StateEnabled {
 		return nil
 	}
+	// 手动启用时取消待执行的自动重启
+	m.resetRestartsLocked(id)
+	return m.enableLocked(id, nil)
+}
+
+// enableLocked 启用插件（需要持有锁），依赖的插件先被启用
+func (m *Manager) enableLocked(id string, resolving []string) error {
+	plugin := m.plugins[id]
+	if err := m.enableDependencies(plugin.Manifest, append(resolving, id)); err != nil {
+		plugin.State = StateError
+		plugin.Error = err.Error()
+		m.captureDiagnosticsLocked(id, plugin.Error)
+		return err
+	}
 
 	// 启动插件
 	if err := m.startPluginLocked(id); err != nil {
 		plugin.State = StateError
 		plugin.Error = err.Error()
+		m.captureDiagnosticsLocked(id, plugin.Error)
+		m.publish(webhook.EventPluginCrashed, map[string]string{"plugin_id": id, "error": err.Error()})
 		return err
 	}
 
@@ -317,14 +515,18 @@ func (m *Manager) DisablePlugin(id string) error {
 
 	plugin, exists := m.plugins[id]
 	if !exists {
-		return fmt.Errorf("插件 %s 未安装", id)
+		return errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
 	}
 
 	if plugin.State == StateDisabled {
 		return nil
 	}
+	if dependents := m.dependentsLocked(id, true); len(dependents) > 0 {
+		return errcode.New(errcode.PluginDepsUnmet, "插件 %s 被已启用的 %s 依赖，请先禁用这些插件", id, strings.Join(dependents, ", "))
+	}
 
 	// 停止插件
+	m.resetRestartsLocked(id)
 	if err := m.stopPluginLocked(id); err != nil {
 		log.Warn().Err(err).Str("id", id).Msg("停止插件失败")
 	}
@@ -339,29 +541,38 @@ func (m *Manager) DisablePlugin(id string) error {
 	return nil
 }
 
-// GetPluginConfig 获取插件配置
-func (m *Manager) GetPluginConfig(id string) (map[string]any, error) {
+// GetPluginConfig 获取插件配置和清单声明的 schema，敏感配置项替换为 SecretMask
+func (m *Manager) GetPluginConfig(id string) (*PluginConfig, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	plugin, exists := m.plugins[id]
 	if !exists {
-		return nil, fmt.Errorf("插件 %s 未安装", id)
+		return nil, errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
 	}
 
-	return plugin.Config, nil
+	return &PluginConfig{
+		Values: maskSecrets(plugin.Manifest.ConfigSchema, plugin.Config),
+		Schema: plugin.Manifest.ConfigSchema,
+	}, nil
 }
 
 // SetPluginConfig 设置插件配置
+// 清单声明了 config_schema 时按其校验并补全默认值，敏感配置项提交 SecretMask 表示保留原值
 func (m *Manager) SetPluginConfig(id string, config map[string]any) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	plugin, exists := m.plugins[id]
 	if !exists {
-		return fmt.Errorf("插件 %s 未安装", id)
+		return errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
 	}
 
+	config, err := applyConfigSchema(plugin.Manifest.ConfigSchema, config, plugin.Config)
+	if err != nil {
+		return err
+	}
+	old := plugin.Config
 	plugin.Config = config
 	plugin.UpdatedAt = time.Now()
 
@@ -371,15 +582,16 @@ func (m *Manager) SetPluginConfig(id string, config map[string]any) error {
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(configFile, data, 0644); err != nil {
+	if err := os.WriteFile(configFile, data, 0600); err != nil {
 		return err
 	}
 
-	// 通知运行中的插件配置已更新
+	// 通知运行中的插件配置已更新，插件不支持在运行中应用新配置时重启插件
 	if runtime, ok := m.runtimes[id]; ok && runtime.running && runtime.instance != nil {
-		// 重启插件以应用新配置
-		m.stopPluginLocked(id)
-		m.startPluginLocked(id)
+		if !m.updateConfigLocked(id, runtime, old, config) {
+			m.stopPluginLocked(id)
+			m.startPluginLocked(id)
+		}
 	}
 
 	if err := m.savePlugins(); err != nil {
@@ -396,7 +608,7 @@ func (m *Manager) GetPluginStatus(id string) (*PluginStatus, error) {
 
 	plugin, exists := m.plugins[id]
 	if !exists {
-		return nil, fmt.Errorf("插件 %s 未安装", id)
+		return nil, errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
 	}
 
 	status := &PluginStatus{
@@ -413,6 +625,21 @@ func (m *Manager) GetPluginStatus(id string) (*PluginStatus, error) {
 			if runtime.instance != nil {
 				status.Stats = runtime.instance.GetStatus()
 			}
+			status.Usage = runtime.usage
+			if !runtime.lastHealthCheck.IsZero() {
+				checked := runtime.lastHealthCheck
+				status.LastHealthCheck = &checked
+			}
+			if runtime.broker != nil {
+				status.PermissionDenials, status.Denials = runtime.broker.Denials()
+			}
+		}
+	}
+	if st := m.restarts[id]; st != nil {
+		status.Restarts = st.count
+		if st.timer != nil {
+			next := st.next
+			status.NextRestart = &next
 		}
 	}
 
@@ -423,7 +650,7 @@ func (m *Manager) GetPluginStatus(id string) (*PluginStatus, error) {
 func (m *Manager) startPluginLocked(id string) error {
 	plugin := m.plugins[id]
 	if plugin == nil {
-		return fmt.Errorf("插件不存在")
+		return errcode.New(errcode.PluginNotFound, "插件不存在")
 	}
 
 	// 只有 Agent 类型或混合类型的插件需要在 Agent 端运行
@@ -431,21 +658,15 @@ func (m *Manager) startPluginLocked(id string) error {
 		return nil
 	}
 
-	runtime := &PluginRuntime{
-		plugin:   plugin,
-		stopChan: make(chan struct{}),
-	}
-
-	// 根据插件类型创建实例
-	instance, err := m.createPluginInstance(plugin)
+	runtime, err := m.newRuntimeLocked(plugin, m.eventBus())
 	if err != nil {
 		return err
 	}
 
-	runtime.instance = instance
-
-	// 启动插件
-	if err := instance.Start(m.ctx, plugin.Config); err != nil {
+	// 启动插件，进程内插件创建的 goroutine 带有插件标签，用于资源统计
+	runtime.broker.trace.started()
+	if err := startLabeled(m.ctx, id, runtime.instance, plugin.Config); err != nil {
+		runtime.broker.release()
 		return err
 	}
 
@@ -456,6 +677,45 @@ func (m *Manager) startPluginLocked(id string) error {
 	return nil
 }
 
+// newRuntimeLocked 创建插件实例及其 broker，设置资源限制和进程隔离，不启动插件（需要持有锁）
+// 插件发布的事件发送到 bus，bus 为空时丢弃
+func (m *Manager) newRuntimeLocked(plugin *InstalledPlugin, bus *eventbus.Bus) (*PluginRuntime, error) {
+	id := plugin.Manifest.ID
+	uid, err := m.allocateUIDLocked(plugin)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareDataDir(pluginDataDir(m.pluginsDir, id), uid); err != nil {
+		return nil, fmt.Errorf("创建插件数据目录失败: %w", err)
+	}
+
+	// 根据插件类型创建实例，插件对文件、网络、命令等的访问经由 broker 检查权限
+	broker := newBroker(m.pluginsDir, m.isolation.ProtectedPaths, plugin.Manifest, bus, m.recordDenial)
+	broker.storage = m.storageLocked(id)
+	broker.scheduler = m.scheduler
+	broker.uid = uid
+	trace := m.traceLocked(id)
+	broker.trace = trace
+	instance, err := m.createPluginInstance(plugin, broker)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := &PluginRuntime{
+		plugin:   plugin,
+		stopChan: make(chan struct{}),
+		instance: instance,
+		broker:   broker,
+		limits:   m.limits.Default.effective(plugin.Manifest.Resources),
+	}
+	if ext, ok := instance.(*ExternalPlugin); ok {
+		ext.limits = runtime.limits
+		ext.isolation = processIsolation{MountNS: m.isolation.MountNamespace, Hidden: m.isolation.ProtectedPaths, UID: uid}
+		ext.trace = trace
+	}
+	return runtime, nil
+}
+
 // stopPluginLocked 停止插件（需要持有锁）
 func (m *Manager) stopPluginLocked(id string) error {
 	runtime, exists := m.runtimes[id]
@@ -463,6 +723,8 @@ func (m *Manager) stopPluginLocked(id string) error {
 		return nil
 	}
 
+	// 先注销指标和定时任务，插件停止期间不再触发任务
+	runtime.broker.release()
 	if runtime.instance != nil {
 		if err := runtime.instance.Stop(); err != nil {
 			return err
@@ -472,58 +734,164 @@ func (m *Manager) stopPluginLocked(id string) error {
 	close(runtime.stopChan)
 	runtime.running = false
 	delete(m.runtimes, id)
+	m.traceLocked(id).record(TraceLifecycle, "plugin.stopped", nil)
 
 	return nil
 }
 
-// createPluginInstance 创建插件实例
-func (m *Manager) createPluginInstance(plugin *InstalledPlugin) (PluginInstance, error) {
-	// 根据插件 ID 创建对应的实例
-	switch plugin.Manifest.ID {
-	case "cloudflare-security":
-		return NewCloudflarePlugin(m.pluginsDir, plugin.Manifest.ID)
-	default:
-		return NewGenericPlugin(m.pluginsDir, plugin.Manifest.ID)
+// handleExit 处理外部插件进程退出：插件进入错误状态并按重启策略处理，不影响其他插件
+// err 为空表示进程正常退出
+func (m *Manager) handleExit(id string, instance PluginInstance, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	runtime, ok := m.runtimes[id]
+	if !ok || runtime.instance != instance {
+		return
 	}
+	if err != nil {
+		m.failPluginLocked(id, err.Error(), true)
+		return
+	}
+	m.failPluginLocked(id, "插件进程已退出", false)
 }
 
-// downloadFromRepo 从官方仓库下载
-func (m *Manager) downloadFromRepo(id, destDir string) error {
-	url := fmt.Sprintf("%s/plugins/%s/latest.tar.gz", m.repoURL, id)
-	return m.downloadFromURL(url, destDir)
+// SetAgentVersion 设置 Agent 版本，用于检查插件要求的最低 Agent 版本
+func (m *Manager) SetAgentVersion(version string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.version = version
 }
 
-// downloadFromURL 从 URL 下载
-func (m *Manager) downloadFromURL(url, destDir string) error {
-	resp, err := http.Get(url)
+// SetSigning 设置插件包签名验证，未设置时拒绝安装所有插件包
+func (m *Manager) SetSigning(cfg SigningConfig) error {
+	v, err := newPackageVerifier(cfg)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verifier = v
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载失败: %s", resp.Status)
+// SetRegistry 设置远程插件仓库，插件列表从仓库获取，官方插件同样从仓库地址下载
+func (m *Manager) SetRegistry(r *Registry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registry = r
+	m.repoURL = r.URL()
+}
+
+// SetScheduler 设置定时任务调度器，插件通过 Host 注册的定时任务由其调度，对之后启动的插件生效
+func (m *Manager) SetScheduler(s *scheduler.Scheduler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scheduler = s
+}
+
+// ScheduledTasks 返回调度器中的任务，pluginID 为空时返回全部任务；未设置调度器时为空
+func (m *Manager) ScheduledTasks(pluginID string) []scheduler.TaskInfo {
+	m.mu.RLock()
+	s := m.scheduler
+	m.mu.RUnlock()
+	if s == nil {
+		return nil
 	}
+	return s.Tasks(pluginID)
+}
 
-	return m.extractTarGz(resp.Body, destDir)
+// SetEventBus 设置事件总线：管理器发布插件事件（启动失败、超限等），
+// 插件通过总线订阅核心模块的事件和发布自己的事件，对之后启动的插件生效
+func (m *Manager) SetEventBus(b *eventbus.Bus) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	m.bus = b
 }
 
-// extractFromData 从数据解压
-func (m *Manager) extractFromData(data []byte, destDir string) error {
-	// 创建临时文件
-	tmpFile, err := os.CreateTemp("", "plugin-*.tar.gz")
-	if err != nil {
-		return err
+// SetAuditLogger 设置审计日志，插件越权访问被拒绝时记录
+func (m *Manager) SetAuditLogger(l *audit.Logger) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	m.audit = l
+}
+
+// auditLogger 返回审计日志，未设置时为 nil
+func (m *Manager) auditLogger() *audit.Logger {
+	m.eventsMu.RLock()
+	defer m.eventsMu.RUnlock()
+	return m.audit
+}
+
+// eventBus 返回事件总线，未设置时为 nil
+func (m *Manager) eventBus() *eventbus.Bus {
+	m.eventsMu.RLock()
+	defer m.eventsMu.RUnlock()
+	return m.bus
+}
+
+// publish 发布事件，未设置事件总线时忽略
+func (m *Manager) publish(eventType string, data any) {
+	if bus := m.eventBus(); bus != nil {
+		bus.Publish(eventType, data)
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+}
 
-	if _, err := tmpFile.Write(data); err != nil {
-		return err
+// createPluginInstance 创建插件实例
+func (m *Manager) createPluginInstance(plugin *InstalledPlugin, broker *Broker) (PluginInstance, error) {
+	if plugin.Manifest.Protocol == ProtocolGRPC {
+		instance, err := NewExternalPlugin(m.pluginsDir, plugin.Manifest.ID, plugin.Manifest.EntryPoint)
+		if err != nil {
+			return nil, err
+		}
+		instance.onExit = func(err error) { m.handleExit(plugin.Manifest.ID, instance, err) }
+		instance.bus = m.eventBus()
+		instance.broker = broker
+		instance.subscribe = plugin.Manifest.Subscribe
+		return instance, nil
 	}
-	tmpFile.Seek(0, 0)
 
-	return m.extractTarGz(tmpFile, destDir)
+	// 根据插件 ID 创建对应的实例
+	switch plugin.Manifest.ID {
+	case "cloudflare-security":
+		instance, err := NewCloudflarePlugin(m.pluginsDir, plugin.Manifest.ID)
+		if err != nil {
+			return nil, err
+		}
+		instance.bus = m.eventBus()
+		instance.broker = broker
+		return instance, nil
+	case "backup-manager":
+		instance, err := NewBackupPlugin(m.pluginsDir, plugin.Manifest.ID)
+		if err != nil {
+			return nil, err
+		}
+		instance.broker = broker
+		return instance, nil
+	case "nginx-manager":
+		instance, err := NewNginxPlugin(m.pluginsDir, plugin.Manifest.ID)
+		if err != nil {
+			return nil, err
+		}
+		instance.broker = broker
+		return instance, nil
+	case "mysql-manager":
+		instance, err := NewMySQLPlugin(m.pluginsDir, plugin.Manifest.ID)
+		if err != nil {
+			return nil, err
+		}
+		instance.broker = broker
+		return instance, nil
+	case "advanced-monitor":
+		instance, err := NewMonitorPlugin(m.pluginsDir, plugin.Manifest.ID)
+		if err != nil {
+			return nil, err
+		}
+		instance.broker = broker
+		return instance, nil
+	default:
+		return NewGenericPlugin(m.pluginsDir, plugin.Manifest.ID)
+	}
 }
 
 // extractTarGz 解压 tar.gz
@@ -549,6 +917,13 @@ func (m *Manager) extractTarGz(r io.Reader, destDir string) error {
 			return err
 		}
 
+		// 插件包中不允许绝对路径和链接，链接可能指向插件目录之外
+		if filepath.IsAbs(header.Name) || strings.HasPrefix(header.Name, "/") {
+			return fmt.Errorf("插件包中不允许绝对路径: %s", header.Name)
+		}
+		if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+			return fmt.Errorf("插件包中不允许链接: %s", header.Name)
+		}
 		target := filepath.Join(destDir, header.Name)
 
 		// 路径穿越检查：确保解压目标在 destDir 内
@@ -585,20 +960,22 @@ func (m *Manager) extractTarGz(r io.Reader, destDir string) error {
 	return nil
 }
 
-// readManifest 读取插件清单
+// readManifest 读取并校验插件清单 manifest.json，没有时读取旧版的 plugin.json
 func (m *Manager) readManifest(pluginDir string) (*PluginManifest, error) {
-	manifestFile := filepath.Join(pluginDir, "plugin.json")
-	data, err := os.ReadFile(manifestFile)
-	if err != nil {
-		return nil, err
+	name := "manifest.json"
+	data, err := os.ReadFile(filepath.Join(pluginDir, name))
+	if os.IsNotExist(err) {
+		name = "plugin.json"
+		data, err = os.ReadFile(filepath.Join(pluginDir, name))
 	}
-
-	var manifest PluginManifest
-	if err := json.Unmarshal(data, &manifest); err != nil {
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.PluginBadManifest, "插件包中没有 manifest.json")
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return &manifest, nil
+	return validateManifest(pluginDir, name, data)
 }
 
 // StartEnabledPlugins 启动所有已启用的插件
@@ -606,13 +983,10 @@ func (m *Manager) StartEnabledPlugins() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for id, plugin := range m.plugins {
-		if plugin.State == StateEnabled {
-			if err := m.startPluginLocked(id); err != nil {
-				log.Error().Err(err).Str("id", id).Msg("启动插件失败")
-				plugin.State = StateError
-				plugin.Error = err.Error()
-			}
+	// 依赖的插件先启动
+	for _, id := range m.startOrderLocked() {
+		if err := m.startPluginLocked(id); err != nil {
+			m.failPluginLocked(id, fmt.Sprintf("启动插件失败: %v", err), true)
 		}
 	}
 }
@@ -0,0 +1,434 @@
+package plugin
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// ManifestVersion Agent 支持的最新插件清单版本，清单未声明 manifest_version 时按版本 1 校验
+const ManifestVersion = 1
+
+// 插件清单中可以声明的权限，文件、命令和网络权限可以带 ":" 后缀限定范围，
+// 如 "file.read:/var/log"、"exec:nginx"、"network:api.cloudflare.com"
+const (
+	PermFileRead      = "file.read"      // 读取文件
+	PermFileWrite     = "file.write"     // 写入、移动和删除文件
+	PermExec          = "exec"           // 执行系统命令
+	PermNetwork       = "network"        // 访问网络
+	PermSystemRead    = "system.read"    // 读取系统信息和监控指标
+	PermEventsPublish = "events.publish" // 向事件总线发布事件
+	PermAlertsPublish = "alerts.publish" // 发出告警，经 webhook 等通知渠道送达
+)
+
+// permissionScopes 权限词汇表，值表示是否允许带范围
+var permissionScopes = map[string]bool{
+	PermFileRead:      true,
+	PermFileWrite:     true,
+	PermExec:          true,
+	PermNetwork:       true,
+	PermSystemRead:    false,
+	PermEventsPublish: false,
+	PermAlertsPublish: false,
+}
+
+//go:embed schema/manifest.v*.json
+var manifestSchemaFS embed.FS
+
+// manifestSchemas 按清单版本索引的 JSON Schema
+var manifestSchemas = func() map[int]*jsonSchema {
+	schemas := make(map[int]*jsonSchema)
+	for v := 1; v <= ManifestVersion; v++ {
+		data, err := manifestSchemaFS.ReadFile(fmt.Sprintf("schema/manifest.v%d.json", v))
+		if err != nil {
+			panic(err)
+		}
+		var s jsonSchema
+		if err = json.Unmarshal(data, &s); err == nil {
+			err = s.compile()
+		}
+		if err != nil {
+			panic(fmt.Sprintf("插件清单 schema v%d 无效: %v", v, err))
+		}
+		schemas[v] = &s
+	}
+	return schemas
+}()
+
+// validateManifest 按清单声明的版本校验插件清单，并检查权限、入口和依赖等 schema 无法表达的约束
+// 校验失败时返回带逐字段错误的 PLUGIN_MANIFEST_INVALID 错误
+func validateManifest(pluginDir, name string, data []byte) (*PluginManifest, error) {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errcode.Invalid(errcode.PluginBadManifest, fmt.Sprintf("插件清单 %s 不是有效的 JSON", name),
+			[]errcode.FieldViolation{{Description: err.Error()}})
+	}
+
+	version := 1
+	if obj, ok := doc.(map[string]any); ok {
+		if n, ok := obj["manifest_version"].(json.Number); ok {
+			if v, err := n.Int64(); err == nil && v > 0 && v <= ManifestVersion {
+				version = int(v)
+			} else {
+				return nil, manifestInvalid(name, []errcode.FieldViolation{{
+					Field:       "manifest_version",
+					Description: fmt.Sprintf("不支持的清单版本 %s，当前 Agent 最高支持版本 %d", n, ManifestVersion),
+				}})
+			}
+		}
+	}
+
+	var v manifestValidator
+	manifestSchemas[version].validate(&v, "", doc)
+
+	// 类型错误时无法解析为清单，只报告 schema 错误；否则一并报告其余约束
+	var manifest PluginManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		if len(v.fields) == 0 {
+			v.addf("", "%v", err)
+		}
+		return nil, manifestInvalid(name, v.fields)
+	}
+	v.checkPermissions(manifest.Permissions)
+	v.checkEntryPoint(pluginDir, &manifest)
+	v.checkSubscribe(manifest.Subscribe)
+	v.checkDependencies(manifest.Dependencies)
+	v.checkConfigSchema(manifest.ConfigSchema, manifest.Config)
+	if len(v.fields) > 0 {
+		return nil, manifestInvalid(name, v.fields)
+	}
+	return &manifest, nil
+}
+
+func manifestInvalid(name string, fields []errcode.FieldViolation) error {
+	problems := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f.Field == "" {
+			problems = append(problems, f.Description)
+		} else {
+			problems = append(problems, f.Field+": "+f.Description)
+		}
+	}
+	return errcode.Invalid(errcode.PluginBadManifest,
+		fmt.Sprintf("插件清单 %s 校验失败: %s", name, strings.Join(problems, "; ")), fields)
+}
+
+// manifestValidator 收集清单的逐字段错误
+type manifestValidator struct {
+	fields []errcode.FieldViolation
+}
+
+func (v *manifestValidator) addf(field, format string, args ...any) {
+	v.fields = append(v.fields, errcode.FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// checkPermissions 权限必须在词汇表中，范围只能用于文件、命令和网络权限
+func (v *manifestValidator) checkPermissions(perms []string) {
+	seen := make(map[string]bool, len(perms))
+	for i, p := range perms {
+		field := fmt.Sprintf("permissions[%d]", i)
+		name, scope, scoped := strings.Cut(p, ":")
+		allowScope, known := permissionScopes[name]
+		switch {
+		case !known:
+			v.addf(field, "未知权限 %q", p)
+		case scoped && !allowScope:
+			v.addf(field, "权限 %s 不支持限定范围", name)
+		case scoped && scope == "":
+			v.addf(field, "权限 %s 的范围不能为空", name)
+		case scoped && (name == PermFileRead || name == PermFileWrite) && (!path.IsAbs(scope) || path.Clean(scope) != scope):
+			v.addf(field, "文件权限的范围必须是规范的绝对路径")
+		case seen[p]:
+			v.addf(field, "重复声明权限 %q", p)
+		}
+		seen[p] = true
+	}
+}
+
+// checkEntryPoint 入口必须是插件目录内的文件，外部进程插件的入口必须可执行
+func (v *manifestValidator) checkEntryPoint(pluginDir string, manifest *PluginManifest) {
+	entry := manifest.EntryPoint
+	if entry == "" {
+		if manifest.Protocol == ProtocolGRPC {
+			v.addf("entry_point", "协议为 grpc 的插件必须声明入口")
+		}
+		return
+	}
+	if !filepath.IsLocal(entry) {
+		v.addf("entry_point", "入口必须是插件目录内的相对路径")
+		return
+	}
+	info, err := os.Stat(filepath.Join(pluginDir, entry))
+	switch {
+	case err != nil:
+		v.addf("entry_point", "插件包中没有入口文件 %s", entry)
+	case !info.Mode().IsRegular():
+		v.addf("entry_point", "入口 %s 不是普通文件", entry)
+	case manifest.Protocol == ProtocolGRPC && runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0:
+		v.addf("entry_point", "入口 %s 没有可执行权限", entry)
+	}
+}
+
+// checkSubscribe 订阅主题只能是完整主题、"*" 或以 ".*" 结尾的前缀
+func (v *manifestValidator) checkSubscribe(topics []string) {
+	for i, t := range topics {
+		if strings.Contains(strings.TrimSuffix(t, ".*"), "*") && t != "*" {
+			v.addf(fmt.Sprintf("subscribe[%d]", i), "无效的订阅主题 %q，通配符只能是 \"*\" 或以 \".*\" 结尾", t)
+		}
+	}
+}
+
+// checkDependencies 依赖的插件 ID 和版本号必须有效
+func (v *manifestValidator) checkDependencies(deps Dependencies) {
+	if deps.Agent != "" {
+		if _, err := parseVersion(deps.Agent); err != nil {
+			v.addf("dependencies.agent", "%v", err)
+		}
+	}
+	ids := make([]string, 0, len(deps.Plugins))
+	for id := range deps.Plugins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		field := "dependencies.plugins." + id
+		if !pluginIDPattern.MatchString(id) {
+			v.addf(field, "无效的插件 ID")
+			continue
+		}
+		if ver := deps.Plugins[id]; ver != "" {
+			if _, err := parseVersion(ver); err != nil {
+				v.addf(field, "%v", err)
+			}
+		}
+	}
+}
+
+// pluginIDPattern 插件 ID 格式，与 API 层的校验一致
+var pluginIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// jsonSchema 插件清单 schema 用到的 JSON Schema 子集
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []any                  `json:"enum"`
+	Pattern              string                 `json:"pattern"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Minimum              *float64               `json:"minimum"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes type 关键字，可以是单个类型或类型数组
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// additionalProperties 为 false 时禁止未声明的属性，为 schema 时约束其余属性的值
+type additionalProperties struct {
+	allowed bool
+	schema  *jsonSchema
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+	a.allowed = true
+	return json.Unmarshal(data, &a.schema)
+}
+
+// compile 预编译 schema 中的正则表达式
+func (s *jsonSchema) compile() error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	children := make([]*jsonSchema, 0, len(s.Properties)+2)
+	for _, prop := range s.Properties {
+		children = append(children, prop)
+	}
+	children = append(children, s.Items)
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.schema)
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 校验 value，错误记录到 v
+func (s *jsonSchema) validate(v *manifestValidator, field string, value any) {
+	if len(s.Type) > 0 && !s.Type.matches(value) {
+		v.addf(field, "类型应为 %s", strings.Join(s.Type, " 或 "))
+		return
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		v.addf(field, "取值应为 %s 之一", enumString(s.Enum))
+	}
+
+	switch val := value.(type) {
+	case string:
+		n := len([]rune(val))
+		if s.MinLength != nil && n < *s.MinLength {
+			if *s.MinLength == 1 {
+				v.addf(field, "不能为空")
+			} else {
+				v.addf(field, "长度不能小于 %d", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			v.addf(field, "长度不能超过 %d", *s.MaxLength)
+		}
+		if s.pattern != nil {
+			if !s.pattern.MatchString(val) {
+				v.addf(field, "格式无效: %q", val)
+			}
+		}
+	case json.Number:
+		if f, err := val.Float64(); err == nil && s.Minimum != nil && f < *s.Minimum {
+			v.addf(field, "不能小于 %v", *s.Minimum)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range val {
+				s.Items.validate(v, fmt.Sprintf("%s[%d]", field, i), item)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				v.addf(joinField(field, name), "缺少必填字段")
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(v, joinField(field, k), val[k])
+				continue
+			}
+			switch ap := s.AdditionalProperties; {
+			case ap == nil:
+			case !ap.allowed:
+				v.addf(joinField(field, k), "不允许的字段")
+			case ap.schema != nil:
+				ap.schema.validate(v, joinField(field, k), val[k])
+			}
+		}
+	}
+}
+
+func (t schemaTypes) matches(value any) bool {
+	for _, typ := range t {
+		switch val := value.(type) {
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case json.Number:
+			if typ == "number" {
+				return true
+			}
+			if typ == "integer" {
+				if f, err := val.Float64(); err == nil && f == math.Trunc(f) {
+					return true
+				}
+			}
+		case []any:
+			if typ == "array" {
+				return true
+			}
+		case map[string]any:
+			if typ == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		switch ev := e.(type) {
+		case float64:
+			if n, ok := value.(json.Number); ok {
+				if f, err := n.Float64(); err == nil && f == ev {
+					return true
+				}
+			}
+		default:
+			if e == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func enumString(enum []any) string {
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		switch ev := e.(type) {
+		case string:
+			if ev != "" {
+				values = append(values, strconv.Quote(ev))
+			}
+		default:
+			values = append(values, fmt.Sprint(ev))
+		}
+	}
+	return strings.Join(values, "、")
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
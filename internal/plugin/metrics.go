@@ -0,0 +1,203 @@
+package plugin
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/runixo/agent/internal/collector"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+const (
+	// maxPluginMetrics 每个插件最多注册的指标数
+	maxPluginMetrics = 100
+	// maxPluginSeries 每个插件所有指标的序列总数上限，与采集器单个数据源的样本上限一致
+	maxPluginSeries = 1000
+	// maxMetricLabels 每个样本最多的标签数
+	maxMetricLabels = 10
+	// maxMetricHelp 指标说明的最大字节数
+	maxMetricHelp = 256
+)
+
+// metricNamePattern 指标名和标签名规则，与 Prometheus 一致
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// pluginMetrics 插件上报的指标，作为采集器的自定义数据源输出
+//
+// 数据源名为 plugin_<插件 ID>（ID 中的 - 替换为 _），指标名加上同样的前缀以免与其他插件冲突。
+// 首次注册指标时向采集器注册数据源，插件停止时注销，插件重启后计数器从零开始。
+type pluginMetrics struct {
+	source string
+
+	mu         sync.Mutex
+	metrics    map[string]*pluginMetric // 插件提交的指标名（不含前缀）-> 指标
+	series     int
+	registered bool
+	closed     bool
+}
+
+type pluginMetric struct {
+	help   string
+	typ    string
+	series map[string]*collector.Sample // Sample.Key -> 序列
+}
+
+func newPluginMetrics(pluginID string) *pluginMetrics {
+	return &pluginMetrics{
+		source:  "plugin_" + strings.ReplaceAll(pluginID, "-", "_"),
+		metrics: make(map[string]*pluginMetric),
+	}
+}
+
+// Name 实现 collector.Source
+func (pm *pluginMetrics) Name() string {
+	return pm.source
+}
+
+// Collect 实现 collector.Source，返回所有序列的当前值
+func (pm *pluginMetrics) Collect(context.Context) ([]collector.Sample, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	samples := make([]collector.Sample, 0, pm.series)
+	for _, m := range pm.metrics {
+		for _, s := range m.series {
+			sample := *s
+			sample.Help = m.help
+			samples = append(samples, sample)
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Key() < samples[j].Key() })
+	return samples, nil
+}
+
+// register 注册指标，同名指标再次注册时更新说明，类型不同则报错
+func (pm *pluginMetrics) register(m pluginsdk.Metric) error {
+	typ := m.Type
+	if typ == "" {
+		typ = pluginsdk.MetricGauge
+	}
+	if typ != pluginsdk.MetricGauge && typ != pluginsdk.MetricCounter {
+		return errcode.New(errcode.InvalidArgument, "指标类型只能是 gauge 或 counter: %q", m.Type)
+	}
+	if !metricNamePattern.MatchString(m.Name) {
+		return errcode.New(errcode.InvalidArgument, "无效的指标名: %q", m.Name)
+	}
+	if len(m.Help) > maxMetricHelp {
+		return errcode.New(errcode.InvalidArgument, "指标说明超过 %d 字节", maxMetricHelp)
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.closed {
+		return errcode.New(errcode.Unavailable, "插件已停止")
+	}
+	if existing, ok := pm.metrics[m.Name]; ok {
+		if existing.typ != typ {
+			return errcode.New(errcode.InvalidArgument, "指标 %s 已注册为 %s", m.Name, existing.typ)
+		}
+		existing.help = m.Help
+		return nil
+	}
+	if len(pm.metrics) >= maxPluginMetrics {
+		return errcode.New(errcode.InvalidArgument, "插件最多注册 %d 个指标", maxPluginMetrics)
+	}
+	if !pm.registered {
+		if err := collector.RegisterSource(pm); err != nil {
+			return errcode.Wrap(errcode.Internal, err, "注册插件指标失败")
+		}
+		pm.registered = true
+	}
+	pm.metrics[m.Name] = &pluginMetric{help: m.Help, typ: typ, series: make(map[string]*collector.Sample)}
+	return nil
+}
+
+// record 更新样本，整批校验通过后才生效
+func (pm *pluginMetrics) record(samples []pluginsdk.MetricSample) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.closed {
+		return errcode.New(errcode.Unavailable, "插件已停止")
+	}
+
+	type update struct {
+		metric *pluginMetric
+		sample collector.Sample
+	}
+	updates := make([]update, 0, len(samples))
+	added := make(map[string]bool)
+	for _, s := range samples {
+		m, ok := pm.metrics[s.Name]
+		if !ok {
+			return errcode.New(errcode.InvalidArgument, "指标未注册: %s", s.Name)
+		}
+		if len(s.Labels) > maxMetricLabels {
+			return errcode.New(errcode.InvalidArgument, "指标 %s 的标签超过 %d 个", s.Name, maxMetricLabels)
+		}
+		for k := range s.Labels {
+			if !metricNamePattern.MatchString(k) || k == "source" {
+				return errcode.New(errcode.InvalidArgument, "指标 %s 的标签名无效: %q", s.Name, k)
+			}
+		}
+		if m.typ == pluginsdk.MetricCounter && (s.Value < 0 || math.IsNaN(s.Value) || math.IsInf(s.Value, 0)) {
+			return errcode.New(errcode.InvalidArgument, "计数器 %s 的增量必须是非负有限数", s.Name)
+		}
+
+		sample := collector.Sample{
+			Name:   pm.source + "_" + s.Name,
+			Labels: copyLabels(s.Labels),
+			Value:  s.Value,
+			Type:   m.typ,
+		}
+		if key := sample.Key(); m.series[key] == nil {
+			added[key] = true
+		}
+		updates = append(updates, update{m, sample})
+	}
+	if pm.series+len(added) > maxPluginSeries {
+		return errcode.New(errcode.InvalidArgument, "插件指标序列超过 %d 个", maxPluginSeries)
+	}
+
+	for _, u := range updates {
+		key := u.sample.Key()
+		cur, ok := u.metric.series[key]
+		if !ok {
+			s := u.sample
+			u.metric.series[key] = &s
+			pm.series++
+			continue
+		}
+		if u.metric.typ == pluginsdk.MetricCounter {
+			cur.Value += u.sample.Value
+		} else {
+			cur.Value = u.sample.Value
+		}
+	}
+	return nil
+}
+
+// close 注销数据源，之后的注册和上报返回错误
+func (pm *pluginMetrics) close() {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.registered {
+		collector.UnregisterSource(pm.source)
+		pm.registered = false
+	}
+	pm.closed = true
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/monitor"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+// 高级监控插件注册的指标，名称被加上 plugin_advanced_monitor_ 前缀，带 check 标签。
+// 指标进入采集器历史，可在监控历史中查看每个检查的可用性和耗时
+var monitorMetrics = []pluginsdk.Metric{
+	{Name: "check_up", Help: "检查是否通过（1 或 0）"},
+	{Name: "check_duration_seconds", Help: "检查耗时"},
+	{Name: "check_failures_total", Help: "检查失败次数", Type: pluginsdk.MetricCounter},
+}
+
+// MonitorPlugin 高级监控插件，检查调度、状态和告警判定在 internal/monitor 中
+//
+// 插件通过 HTTP 接口（PluginHTTPPrefix 下，见 monitor.Manager.Handler）提供检查状态、最近结果和手动运行。
+// 进程检查需要 system.read 权限，端口检查需要对应地址的 network 权限，HTTP 检查需要目标主机的 network 权限，
+// 脚本检查需要命令的 exec 权限；声明 alerts.publish 权限后，检查进入 down 状态和恢复时发出核心告警。
+type MonitorPlugin struct {
+	pluginsDir string
+	pluginID   string
+	broker     *Broker // 由 Manager 注入
+	manager    *monitor.Manager
+	handler    http.Handler
+	running    bool
+	mu         sync.RWMutex
+}
+
+// NewMonitorPlugin 创建高级监控插件
+func NewMonitorPlugin(pluginsDir, pluginID string) (*MonitorPlugin, error) {
+	return &MonitorPlugin{
+		pluginsDir: pluginsDir,
+		pluginID:   pluginID,
+	}, nil
+}
+
+// Start 启动高级监控插件，立即运行全部检查，之后按各自的间隔运行
+func (p *MonitorPlugin) Start(ctx context.Context, config map[string]any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg, err := monitor.ParseConfig(config)
+	if err != nil {
+		return err
+	}
+	for _, m := range monitorMetrics {
+		if err := p.broker.RegisterMetric(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	p.manager = monitor.NewManager(p.env(), cfg)
+	p.handler = p.manager.Handler(PluginHTTPPrefix(p.pluginID))
+	p.running = true
+
+	log.Info().Str("plugin", p.pluginID).Int("checks", len(cfg.Checks)).Msg("高级监控插件已启动")
+	return nil
+}
+
+// Stop 停止高级监控插件，等待进行中的检查结束
+func (p *MonitorPlugin) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager != nil {
+		p.manager.Close()
+	}
+	p.handler = nil
+	p.running = false
+	log.Info().Str("plugin", p.pluginID).Msg("高级监控插件已停止")
+	return nil
+}
+
+// GetStatus 获取状态，包括各状态的检查数
+func (p *MonitorPlugin) GetStatus() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := map[string]string{
+		"running": fmt.Sprintf("%v", p.running),
+	}
+	if p.manager != nil {
+		checks := p.manager.Checks()
+		down := 0
+		for _, c := range checks {
+			if c.State == monitor.StateDown {
+				down++
+			}
+		}
+		status["checks"] = fmt.Sprintf("%d", len(checks))
+		status["down"] = fmt.Sprintf("%d", down)
+	}
+	return status
+}
+
+// HealthCheck 健康检查，检查失败通过指标和告警反映，不影响插件健康
+func (p *MonitorPlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.running {
+		return fmt.Errorf("插件未运行")
+	}
+	return nil
+}
+
+// UpdateConfig 应用新配置：新增和修改过的检查立即运行，删除的检查清除记录
+func (p *MonitorPlugin) UpdateConfig(ctx context.Context, change pluginsdk.ConfigChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager == nil {
+		return errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	cfg, err := monitor.ParseConfig(change.New)
+	if err != nil {
+		return err
+	}
+	p.manager.SetConfig(cfg)
+	return nil
+}
+
+// ServeHTTP 处理 REST API 转发的请求
+func (p *MonitorPlugin) ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+
+	if handler == nil {
+		return nil, errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	return pluginsdk.ServeHTTPRequest(ctx, handler, req)
+}
+
+// env 按插件权限访问进程、网络和命令的监控环境
+func (p *MonitorPlugin) env() monitor.Env {
+	b := p.broker
+	return monitor.Env{
+		Store:           b,
+		HTTPClient:      b.HTTPClient(),
+		LookPath:        b.lookPath,
+		CheckNetwork:    func(hostport string) error { return b.Check(PermNetwork, hostport) },
+		CheckSystemRead: func() error { return b.Check(PermSystemRead, "") },
+		WorkDir:         b.dataDir,
+		OnResult:        p.recordResult,
+		OnAlert:         p.sendAlert,
+	}
+}
+
+// recordResult 把检查结果记录为插件指标
+func (p *MonitorPlugin) recordResult(name string, _ *monitor.Check, r *monitor.Result) {
+	labels := map[string]string{"check": name}
+	up, failures := 1.0, 0.0
+	if !r.OK {
+		up, failures = 0, 1
+	}
+	err := p.broker.RecordMetrics(context.Background(),
+		pluginsdk.MetricSample{Name: "check_up", Labels: labels, Value: up},
+		pluginsdk.MetricSample{Name: "check_duration_seconds", Labels: labels, Value: float64(r.DurationMs) / 1000},
+		pluginsdk.MetricSample{Name: "check_failures_total", Labels: labels, Value: failures},
+	)
+	if err != nil {
+		log.Debug().Err(err).Msg("记录监控指标失败")
+	}
+}
+
+// sendAlert 声明了 alerts.publish 权限时发出核心告警，声明了 events.publish 权限时
+// 另外发布 monitor.check.<firing|resolved> 事件
+func (p *MonitorPlugin) sendAlert(a *monitor.Alert) {
+	ctx := context.Background()
+	if p.broker.granted(PermAlertsPublish) {
+		err := p.broker.Alert(ctx, map[string]any{
+			"kind":     "check",
+			"status":   a.Status,
+			"check":    a.Check,
+			"type":     a.Type,
+			"target":   a.Target,
+			"reason":   a.Message,
+			"failures": a.Failures,
+			"since":    a.Since,
+			"time":     a.Time,
+		})
+		if err != nil {
+			log.Debug().Err(err).Msg("发出监控告警失败")
+		}
+	}
+	if p.broker.granted(PermEventsPublish) {
+		if err := p.broker.Publish(ctx, "monitor.check."+a.Status, a); err != nil {
+			log.Debug().Err(err).Msg("发布监控事件失败")
+		}
+	}
+}
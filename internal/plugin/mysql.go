@@ -0,0 +1,268 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/mysql"
+	"github.com/runixo/agent/internal/scheduler"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+// MySQL 插件注册的指标，名称被加上 plugin_mysql_manager_ 前缀，连接指标带 connection 标签，备份指标带 job 标签
+var mysqlMetrics = []pluginsdk.Metric{
+	{Name: "up", Help: "连接是否可用（1 或 0）"},
+	{Name: "threads_connected", Help: "当前连接数"},
+	{Name: "threads_running", Help: "正在执行语句的连接数"},
+	{Name: "max_connections", Help: "最大连接数"},
+	{Name: "max_used_connections", Help: "启动以来的最大并发连接数"},
+	{Name: "queries_per_second", Help: "每秒语句数"},
+	{Name: "uptime_seconds", Help: "服务运行时间"},
+	{Name: "slow_queries_total", Help: "慢查询数", Type: pluginsdk.MetricCounter},
+	{Name: "connections_total", Help: "连接尝试次数", Type: pluginsdk.MetricCounter},
+	{Name: "aborted_connects_total", Help: "失败的连接尝试次数", Type: pluginsdk.MetricCounter},
+	{Name: "backup_last_success_timestamp", Help: "最近一次成功备份的时间（Unix 秒）"},
+	{Name: "backup_last_size_bytes", Help: "最近一次成功备份的大小"},
+	{Name: "backup_failures_total", Help: "失败的备份次数", Type: pluginsdk.MetricCounter},
+}
+
+// MySQLPlugin MySQL 管理插件，连接管理、查询检查、状态采集和备份在 internal/mysql 中
+//
+// 插件通过 HTTP 接口（PluginHTTPPrefix 下，见 mysql.Manager.Handler）提供连接管理、数据库和账号列表、
+// 状态、慢查询、查询执行和备份。执行 mysql、mysqldump 需要 exec 权限，TCP 连接需要对应地址的 network 权限，
+// 通过套接字连接需要套接字文件的 file.read 权限，写入备份目录需要 file.write 权限。
+type MySQLPlugin struct {
+	pluginsDir string
+	pluginID   string
+	broker     *Broker // 由 Manager 注入
+	manager    *mysql.Manager
+	handler    http.Handler
+	running    bool
+	mu         sync.RWMutex
+}
+
+// NewMySQLPlugin 创建 MySQL 插件
+func NewMySQLPlugin(pluginsDir, pluginID string) (*MySQLPlugin, error) {
+	return &MySQLPlugin{
+		pluginsDir: pluginsDir,
+		pluginID:   pluginID,
+	}, nil
+}
+
+// Start 启动 MySQL 插件，开始采集连接状态，为配置了 schedule 的备份任务注册定时任务
+func (p *MySQLPlugin) Start(ctx context.Context, config map[string]any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg, err := mysql.ParseConfig(config)
+	if err != nil {
+		return err
+	}
+	for _, m := range mysqlMetrics {
+		if err := p.broker.RegisterMetric(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	p.manager = mysql.NewManager(p.env(), cfg)
+	p.handler = p.manager.Handler(PluginHTTPPrefix(p.pluginID))
+	if err := p.scheduleBackups(cfg, nil); err != nil {
+		p.manager.Close()
+		p.manager = nil
+		return err
+	}
+	p.running = true
+
+	log.Info().Str("plugin", p.pluginID).Int("connections", len(cfg.Connections)).Int("backups", len(cfg.Backups)).Msg("MySQL 插件已启动")
+	return nil
+}
+
+// Stop 停止 MySQL 插件，取消进行中的备份；定时任务由 Manager 在停止插件前移除
+func (p *MySQLPlugin) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager != nil {
+		p.manager.Close()
+	}
+	p.handler = nil
+	p.running = false
+	log.Info().Str("plugin", p.pluginID).Msg("MySQL 插件已停止")
+	return nil
+}
+
+// GetStatus 获取状态
+func (p *MySQLPlugin) GetStatus() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := map[string]string{
+		"running": fmt.Sprintf("%v", p.running),
+	}
+	if p.manager != nil {
+		config := p.manager.Config()
+		status["connections"] = fmt.Sprintf("%d", len(config.Connections))
+		status["backups"] = fmt.Sprintf("%d", len(config.Backups))
+		status["read_only"] = fmt.Sprintf("%v", config.ReadOnly)
+	}
+	return status
+}
+
+// HealthCheck 健康检查，连接不可用和备份失败通过指标反映，不影响插件健康
+func (p *MySQLPlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.running {
+		return fmt.Errorf("插件未运行")
+	}
+	return nil
+}
+
+// UpdateConfig 应用新配置：更新连接和备份任务，按新的 schedule 重新注册定时任务
+func (p *MySQLPlugin) UpdateConfig(ctx context.Context, change pluginsdk.ConfigChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager == nil {
+		return errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	cfg, err := mysql.ParseConfig(change.New)
+	if err != nil {
+		return err
+	}
+	old := p.manager.Config()
+	p.manager.SetConfig(cfg)
+	return p.scheduleBackups(cfg, old)
+}
+
+// ServeHTTP 处理 REST API 转发的请求
+func (p *MySQLPlugin) ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+
+	if handler == nil {
+		return nil, errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	return pluginsdk.ServeHTTPRequest(ctx, handler, req)
+}
+
+// scheduleBackups 为配置了 schedule 的备份任务注册定时任务，移除 old 中已删除或不再定时的任务（需要持有锁）
+func (p *MySQLPlugin) scheduleBackups(cfg, old *mysql.Config) error {
+	if old != nil {
+		for name := range old.Backups {
+			if job, ok := cfg.Backups[name]; !ok || job.Schedule == "" {
+				p.broker.Unschedule(context.Background(), name)
+			}
+		}
+	}
+
+	manager := p.manager
+	for name, job := range cfg.Backups {
+		if job.Schedule == "" {
+			continue
+		}
+		spec := scheduler.Spec{
+			Cron:      job.Schedule,
+			Timezone:  job.Timezone,
+			MissedRun: scheduler.MissedRunOnce,
+		}
+		err := p.broker.ScheduleFunc(name, spec, func(ctx context.Context, _ time.Time) error {
+			_, err := manager.RunBackup(ctx, name)
+			return err
+		})
+		if err != nil {
+			return errcode.Wrap(errcode.Of(err), err, fmt.Sprintf("注册备份任务 %s 失败", name))
+		}
+	}
+	return nil
+}
+
+// env 按插件权限访问文件、命令和网络的 MySQL 环境
+func (p *MySQLPlugin) env() mysql.Env {
+	b := p.broker
+	return mysql.Env{
+		DataDir:      b.dataDir,
+		Store:        b,
+		LookPath:     b.lookPath,
+		CheckRead:    func(path string) error { return b.checkPath(PermFileRead, path) },
+		CheckWrite:   func(path string) error { return b.checkPath(PermFileWrite, path) },
+		CheckNetwork: func(hostport string) error { return b.Check(PermNetwork, hostport) },
+		OnStatus:     p.recordStatus,
+		OnBackup:     p.recordBackup,
+	}
+}
+
+// recordStatus 把连接状态记录为插件指标；计数器记录与上一次采样的差值，MySQL 重启后计数归零时从头计
+func (p *MySQLPlugin) recordStatus(conn string, prev, cur *mysql.Status, err error) {
+	ctx := context.Background()
+	labels := map[string]string{"connection": conn}
+	if err != nil {
+		if err := p.broker.RecordMetrics(ctx, pluginsdk.MetricSample{Name: "up", Labels: labels, Value: 0}); err != nil {
+			log.Debug().Err(err).Msg("记录 MySQL 指标失败")
+		}
+		return
+	}
+
+	counter := func(value func(*mysql.Status) int64) float64 {
+		switch {
+		case prev == nil:
+			return 0
+		case value(cur) < value(prev):
+			return float64(value(cur))
+		default:
+			return float64(value(cur) - value(prev))
+		}
+	}
+	samples := []pluginsdk.MetricSample{
+		{Name: "up", Labels: labels, Value: 1},
+		{Name: "threads_connected", Labels: labels, Value: float64(cur.ThreadsConnected)},
+		{Name: "threads_running", Labels: labels, Value: float64(cur.ThreadsRunning)},
+		{Name: "max_connections", Labels: labels, Value: float64(cur.MaxConnections)},
+		{Name: "max_used_connections", Labels: labels, Value: float64(cur.MaxUsedConnections)},
+		{Name: "queries_per_second", Labels: labels, Value: cur.QueriesPerSecond},
+		{Name: "uptime_seconds", Labels: labels, Value: float64(cur.UptimeSeconds)},
+		{Name: "slow_queries_total", Labels: labels, Value: counter(func(s *mysql.Status) int64 { return s.SlowQueries })},
+		{Name: "connections_total", Labels: labels, Value: counter(func(s *mysql.Status) int64 { return s.Connections })},
+		{Name: "aborted_connects_total", Labels: labels, Value: counter(func(s *mysql.Status) int64 { return s.AbortedConnects })},
+	}
+	if err := p.broker.RecordMetrics(ctx, samples...); err != nil {
+		log.Debug().Err(err).Msg("记录 MySQL 指标失败")
+	}
+}
+
+// recordBackup 更新备份指标，声明了 events.publish 权限时发布 mysql.backup.<succeeded|failed> 事件
+func (p *MySQLPlugin) recordBackup(job string, b *mysql.BackupFile, err error) {
+	ctx := context.Background()
+	labels := map[string]string{"job": job}
+	var samples []pluginsdk.MetricSample
+	if err != nil {
+		samples = append(samples, pluginsdk.MetricSample{Name: "backup_failures_total", Labels: labels, Value: 1})
+	} else {
+		samples = append(samples,
+			pluginsdk.MetricSample{Name: "backup_last_success_timestamp", Labels: labels, Value: float64(b.CreatedAt.Unix())},
+			pluginsdk.MetricSample{Name: "backup_last_size_bytes", Labels: labels, Value: float64(b.Size)},
+		)
+	}
+	if err := p.broker.RecordMetrics(ctx, samples...); err != nil {
+		log.Debug().Err(err).Msg("记录 MySQL 备份指标失败")
+	}
+
+	if p.broker.granted(PermEventsPublish) {
+		event := map[string]any{"job": job, "backup": b}
+		status := "succeeded"
+		if err != nil {
+			event["error"] = err.Error()
+			status = "failed"
+		}
+		if err := p.broker.Publish(ctx, "mysql.backup."+status, event); err != nil {
+			log.Debug().Err(err).Msg("发布 MySQL 备份事件失败")
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/nginx"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+// Nginx 插件注册的指标，名称被加上 plugin_nginx_manager_ 前缀，随采集器进入历史数据和 Prometheus
+var nginxMetrics = []pluginsdk.Metric{
+	{Name: "up", Help: "stub_status 是否可访问（1 或 0）"},
+	{Name: "active_connections", Help: "活动连接数"},
+	{Name: "reading", Help: "正在读取请求头的连接数"},
+	{Name: "writing", Help: "正在写响应的连接数"},
+	{Name: "waiting", Help: "空闲的 keep-alive 连接数"},
+	{Name: "requests_per_second", Help: "每秒请求数"},
+	{Name: "accepts_total", Help: "接受的连接数", Type: pluginsdk.MetricCounter},
+	{Name: "handled_total", Help: "处理的连接数", Type: pluginsdk.MetricCounter},
+	{Name: "requests_total", Help: "请求数", Type: pluginsdk.MetricCounter},
+}
+
+// NginxPlugin Nginx 管理插件，配置解析、站点模板和 stub_status 采集在 internal/nginx 中
+//
+// 插件通过 HTTP 接口（PluginHTTPPrefix 下，见 nginx.Manager.Handler）提供 server 块列表、站点管理、证书和状态。
+// 读取 Nginx 配置和证书需要 file.read 权限，写入站点目录需要 file.write 权限，
+// 执行 nginx -t 和重载需要 exec 权限，采集 stub_status 需要对应地址的 network 权限。
+type NginxPlugin struct {
+	pluginsDir string
+	pluginID   string
+	broker     *Broker // 由 Manager 注入
+	manager    *nginx.Manager
+	handler    http.Handler
+	running    bool
+	mu         sync.RWMutex
+}
+
+// NewNginxPlugin 创建 Nginx 插件
+func NewNginxPlugin(pluginsDir, pluginID string) (*NginxPlugin, error) {
+	return &NginxPlugin{
+		pluginsDir: pluginsDir,
+		pluginID:   pluginID,
+	}, nil
+}
+
+// Start 启动 Nginx 插件，开始采集 stub_status
+func (p *NginxPlugin) Start(ctx context.Context, config map[string]any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cfg, err := nginx.ParseConfig(config)
+	if err != nil {
+		return err
+	}
+	for _, m := range nginxMetrics {
+		if err := p.broker.RegisterMetric(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	p.manager = nginx.NewManager(p.env(), cfg)
+	p.handler = p.manager.Handler(PluginHTTPPrefix(p.pluginID))
+	p.running = true
+
+	log.Info().Str("plugin", p.pluginID).Str("config", cfg.ConfigFile).Msg("Nginx 插件已启动")
+	return nil
+}
+
+// Stop 停止 Nginx 插件
+func (p *NginxPlugin) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager != nil {
+		p.manager.Close()
+	}
+	p.handler = nil
+	p.running = false
+	log.Info().Str("plugin", p.pluginID).Msg("Nginx 插件已停止")
+	return nil
+}
+
+// GetStatus 获取状态
+func (p *NginxPlugin) GetStatus() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := map[string]string{
+		"running": fmt.Sprintf("%v", p.running),
+	}
+	if p.manager != nil {
+		status["config_file"] = p.manager.Config().ConfigFile
+		if s, err := p.manager.Status(); err == nil {
+			status["active_connections"] = fmt.Sprintf("%d", s.Active)
+		}
+	}
+	return status
+}
+
+// HealthCheck 健康检查，stub_status 不可访问通过 up 指标反映，不影响插件健康
+func (p *NginxPlugin) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.running {
+		return fmt.Errorf("插件未运行")
+	}
+	return nil
+}
+
+// UpdateConfig 应用新配置，下一次采集和修改起生效
+func (p *NginxPlugin) UpdateConfig(ctx context.Context, change pluginsdk.ConfigChange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manager == nil {
+		return errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	cfg, err := nginx.ParseConfig(change.New)
+	if err != nil {
+		return err
+	}
+	p.manager.SetConfig(cfg)
+	return nil
+}
+
+// ServeHTTP 处理 REST API 转发的请求
+func (p *NginxPlugin) ServeHTTP(ctx context.Context, req pluginsdk.HTTPRequest) (*pluginsdk.HTTPResponse, error) {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+
+	if handler == nil {
+		return nil, errcode.New(errcode.Unavailable, "插件未运行")
+	}
+	return pluginsdk.ServeHTTPRequest(ctx, handler, req)
+}
+
+// env 按插件权限访问文件、命令和网络的 Nginx 环境
+func (p *NginxPlugin) env() nginx.Env {
+	b := p.broker
+	return nginx.Env{
+		Store:      b,
+		HTTPClient: b.HTTPClient(),
+		LookPath:   b.lookPath,
+		CheckRead:  func(path string) error { return b.checkPath(PermFileRead, path) },
+		CheckWrite: func(path string) error { return b.checkPath(PermFileWrite, path) },
+		OnStatus:   p.recordStatus,
+	}
+}
+
+// recordStatus 把 stub_status 记录为插件指标；计数器记录与上一次采样的差值，Nginx 重启后计数归零时从头计
+func (p *NginxPlugin) recordStatus(prev, cur *nginx.Status, err error) {
+	ctx := context.Background()
+	if err != nil {
+		if err := p.broker.RecordMetrics(ctx, pluginsdk.MetricSample{Name: "up", Value: 0}); err != nil {
+			log.Debug().Err(err).Msg("记录 Nginx 指标失败")
+		}
+		return
+	}
+
+	counter := func(value func(*nginx.Status) int64) float64 {
+		switch {
+		case prev == nil:
+			return 0
+		case value(cur) < value(prev):
+			return float64(value(cur))
+		default:
+			return float64(value(cur) - value(prev))
+		}
+	}
+	samples := []pluginsdk.MetricSample{
+		{Name: "up", Value: 1},
+		{Name: "active_connections", Value: float64(cur.Active)},
+		{Name: "reading", Value: float64(cur.Reading)},
+		{Name: "writing", Value: float64(cur.Writing)},
+		{Name: "waiting", Value: float64(cur.Waiting)},
+		{Name: "requests_per_second", Value: cur.RequestsPerSecond},
+		{Name: "accepts_total", Value: counter(func(s *nginx.Status) int64 { return s.Accepts })},
+		{Name: "handled_total", Value: counter(func(s *nginx.Status) int64 { return s.Handled })},
+		{Name: "requests_total", Value: counter(func(s *nginx.Status) int64 { return s.Requests })},
+	}
+	if err := p.broker.RecordMetrics(ctx, samples...); err != nil {
+		log.Debug().Err(err).Msg("记录 Nginx 指标失败")
+	}
+}
@@ -0,0 +1,242 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/security"
+)
+
+// 插件包格式
+//
+// 插件包是 tar.gz 压缩包，根目录包含 manifest.json（旧版的 plugin.json 同样识别）和插件文件。
+// 签名是发布者私钥对包文件 SHA-256 摘要（32 字节）的 Ed25519 签名，base64 编码后作为独立文件分发：
+// 官方仓库为 <repo>/plugins/<id>/latest.tar.gz.sig，URL 安装为 <url>.sig（请求中带有签名时使用请求中的），本地安装随请求提交。
+// 安装请求还可以带上包的 SHA-256，用于确认内网镜像或离线拷贝的插件包与发布的一致。
+
+const (
+	// maxPackageSize 插件包的大小上限
+	maxPackageSize = 100 << 20
+	// maxSignatureSize 签名文件的大小上限，base64 编码的签名只有 88 字节
+	maxSignatureSize = 4 << 10
+	packageTimeout   = 5 * time.Minute
+	// packageDialTimeout 连接插件仓库的超时
+	packageDialTimeout = 30 * time.Second
+)
+
+// SigningConfig 插件包签名验证配置
+type SigningConfig struct {
+	// TrustedKeys 受信任的发布者 Ed25519 公钥（base64）
+	TrustedKeys []string
+	// AllowUnsigned 允许安装没有签名的插件包；带有签名的包仍然必须验证通过
+	AllowUnsigned bool
+}
+
+// packageVerifier 插件包签名验证器，零值拒绝所有插件包
+type packageVerifier struct {
+	keys          []ed25519.PublicKey
+	allowUnsigned bool
+}
+
+func newPackageVerifier(cfg SigningConfig) (*packageVerifier, error) {
+	v := &packageVerifier{allowUnsigned: cfg.AllowUnsigned}
+	for _, k := range cfg.TrustedKeys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("无效的插件发布者公钥: %s", k)
+		}
+		v.keys = append(v.keys, ed25519.PublicKey(key))
+	}
+	return v, nil
+}
+
+// verify 验证包摘要的签名，返回签名公钥的指纹；允许未签名且 sig 为空时返回空指纹
+func (v *packageVerifier) verify(digest, sig []byte) (string, error) {
+	sig = bytes.TrimSpace(sig)
+	if len(sig) == 0 {
+		if v.allowUnsigned {
+			return "", nil
+		}
+		return "", errcode.New(errcode.PluginVerifyFailed, "插件包没有签名")
+	}
+	signature, err := base64.StdEncoding.DecodeString(string(sig))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return "", errcode.New(errcode.PluginVerifyFailed, "插件包签名格式无效")
+	}
+	for _, key := range v.keys {
+		if ed25519.Verify(key, digest, signature) {
+			return keyFingerprint(key), nil
+		}
+	}
+	return "", errcode.New(errcode.PluginVerifyFailed, "插件包签名验证失败：不是受信任的发布者签名，或插件包已被篡改")
+}
+
+// keyFingerprint 公钥 SHA-256 的前 16 个十六进制字符
+func keyFingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// packageFile 下载或上传到临时文件的插件包
+type packageFile struct {
+	*os.File
+	digest []byte
+}
+
+// Close 关闭并删除临时文件
+func (p *packageFile) Close() error {
+	p.File.Close()
+	return os.Remove(p.Name())
+}
+
+// spoolPackage 把插件包写入临时文件并计算 SHA-256，超过大小上限时返回错误
+func spoolPackage(dir string, r io.Reader) (*packageFile, error) {
+	f, err := os.CreateTemp(dir, ".package-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	pkg := &packageFile{File: f}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, maxPackageSize+1))
+	if err == nil && n > maxPackageSize {
+		err = errcode.New(errcode.InvalidArgument, "插件包超过 %d MB 限制", maxPackageSize>>20)
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		pkg.Close()
+		return nil, err
+	}
+	pkg.digest = h.Sum(nil)
+	return pkg, nil
+}
+
+// maxManifestSize 加锁前从插件包中读取的清单的大小上限
+const maxManifestSize = 1 << 20
+
+// packageDependencies 从插件包中读取清单声明的依赖，不解压插件包；读取后把插件包重置到开头
+func packageDependencies(pkg *packageFile) (Dependencies, error) {
+	defer pkg.Seek(0, io.SeekStart)
+	if _, err := pkg.Seek(0, io.SeekStart); err != nil {
+		return Dependencies{}, err
+	}
+	gzr, err := gzip.NewReader(pkg)
+	if err != nil {
+		return Dependencies{}, err
+	}
+	defer gzr.Close()
+
+	// manifest.json 优先，旧版插件包只有 plugin.json
+	var manifests [2][]byte
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Dependencies{}, err
+		}
+		i := -1
+		switch path.Clean(header.Name) {
+		case "manifest.json":
+			i = 0
+		case "plugin.json":
+			i = 1
+		}
+		if i < 0 || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if manifests[i], err = io.ReadAll(io.LimitReader(tr, maxManifestSize)); err != nil {
+			return Dependencies{}, err
+		}
+	}
+	for _, data := range manifests {
+		if data != nil {
+			var manifest struct {
+				Dependencies Dependencies `json:"dependencies"`
+			}
+			err := json.Unmarshal(data, &manifest)
+			return manifest.Dependencies, err
+		}
+	}
+	return Dependencies{}, errcode.New(errcode.PluginBadManifest, "插件包中没有 manifest.json")
+}
+
+// parseDigest 规范化请求中的 SHA-256（十六进制，可带 sha256: 前缀），为空时返回空串
+func parseDigest(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "sha256:")
+	if s == "" {
+		return "", nil
+	}
+	if _, err := hex.DecodeString(s); err != nil || len(s) != sha256.Size*2 {
+		return "", errcode.New(errcode.InvalidArgument, "SHA-256 格式无效: %q", s)
+	}
+	return s, nil
+}
+
+// newPackageClient 下载插件包的 HTTP 客户端
+// 插件包地址和重定向都可能指向内网或元数据地址，连接时检查实际 IP
+func newPackageClient() *http.Client {
+	return &http.Client{
+		Timeout:       packageTimeout,
+		Transport:     &http.Transport{DialContext: security.Dialer(packageDialTimeout).DialContext},
+		CheckRedirect: security.CheckRedirect,
+	}
+}
+
+// downloadPackage 下载插件包及其签名，签名文件不存在时返回空签名
+func downloadPackage(client *http.Client, dir, url string) (*packageFile, []byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("下载失败: %s", resp.Status)
+	}
+	pkg, err := spoolPackage(dir, resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sig, err := downloadSignature(client, url+".sig")
+	if err != nil {
+		pkg.Close()
+		return nil, nil, err
+	}
+	return pkg, sig, nil
+}
+
+func downloadSignature(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("下载签名失败: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+	case http.StatusNotFound:
+		log.Debug().Str("url", url).Msg("插件包没有签名文件")
+		return nil, nil
+	}
+	return nil, fmt.Errorf("下载签名失败: %s", resp.Status)
+}
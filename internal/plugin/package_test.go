@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// tarEntry 测试插件包中的一个条目，typeflag 为 0 时为普通文件
+type tarEntry struct {
+	name     string
+	body     string
+	typeflag byte
+	linkname string
+}
+
+// buildPackage 生成 tar.gz 插件包
+func buildPackage(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.body)), Typeflag: e.typeflag, Linkname: e.linkname}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		} else {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// manifestPackage 只包含清单的插件包
+func manifestPackage(t *testing.T, manifest string) []byte {
+	return buildPackage(t, tarEntry{name: "manifest.json", body: manifest})
+}
+
+// signPackage 发布者对插件包摘要的签名（base64）
+func signPackage(key ed25519.PrivateKey, pkg []byte) []byte {
+	digest := sha256.Sum256(pkg)
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest[:])))
+}
+
+func newSigningKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(pub), priv
+}
+
+func newTestManager(t *testing.T, trustedKeys ...string) *Manager {
+	t.Helper()
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	t.Cleanup(m.Close)
+	if err := m.SetSigning(SigningConfig{TrustedKeys: trustedKeys}); err != nil {
+		t.Fatalf("SetSigning() error: %v", err)
+	}
+	return m
+}
+
+func TestPackageVerifier(t *testing.T) {
+	trusted, key := newSigningKey(t)
+	_, otherKey := newSigningKey(t)
+	pkg := manifestPackage(t, `{"id":"hello","name":"Hello","version":"1.0.0"}`)
+	digest := sha256.Sum256(pkg)
+
+	v, err := newPackageVerifier(SigningConfig{TrustedKeys: []string{trusted}})
+	if err != nil {
+		t.Fatalf("newPackageVerifier() error: %v", err)
+	}
+	signer, err := v.verify(digest[:], signPackage(key, pkg))
+	if err != nil || signer == "" {
+		t.Errorf("verify() with valid signature = %q, %v", signer, err)
+	}
+
+	tampered := sha256.Sum256(append(pkg, 0))
+	rejected := map[string]struct {
+		digest, sig []byte
+	}{
+		"tampered digest": {tampered[:], signPackage(key, pkg)},
+		"wrong key":       {digest[:], signPackage(otherKey, pkg)},
+		"missing":         {digest[:], nil},
+		"malformed":       {digest[:], []byte("not-base64!")},
+	}
+	for name, c := range rejected {
+		if _, err := v.verify(c.digest, c.sig); errcode.Of(err) != errcode.PluginVerifyFailed {
+			t.Errorf("verify(%s) = %v, want PLUGIN_VERIFY_FAILED", name, err)
+		}
+	}
+
+	// 允许未签名时，带有签名的包仍然必须验证通过
+	v.allowUnsigned = true
+	if signer, err := v.verify(digest[:], nil); err != nil || signer != "" {
+		t.Errorf("verify(unsigned, allowUnsigned) = %q, %v", signer, err)
+	}
+	if _, err := v.verify(digest[:], signPackage(otherKey, pkg)); errcode.Of(err) != errcode.PluginVerifyFailed {
+		t.Errorf("verify(wrong key, allowUnsigned) = %v, want PLUGIN_VERIFY_FAILED", err)
+	}
+
+	if _, err := newPackageVerifier(SigningConfig{TrustedKeys: []string{"c2hvcnQ="}}); err == nil {
+		t.Error("expected error for invalid public key")
+	}
+}
+
+func TestInstallPluginVerifiesPackage(t *testing.T) {
+	trusted, key := newSigningKey(t)
+	_, otherKey := newSigningKey(t)
+	m := newTestManager(t, trusted)
+	pkg := manifestPackage(t, `{"id":"hello","name":"Hello","version":"1.0.0"}`)
+
+	tampered := append([]byte(nil), pkg...)
+	tampered[len(tampered)-1] ^= 0xff
+	rejected := map[string]InstallRequest{
+		"tampered package": {Source: SourceLocal, Data: tampered, Signature: signPackage(key, pkg)},
+		"wrong key":        {Source: SourceLocal, Data: pkg, Signature: signPackage(otherKey, pkg)},
+		"unsigned":         {Source: SourceLocal, Data: pkg},
+		"sha256 mismatch":  {Source: SourceLocal, Data: pkg, Signature: signPackage(key, pkg), SHA256: "sha256:" + string(bytes.Repeat([]byte("0"), 64))},
+	}
+	for name, req := range rejected {
+		if err := m.InstallPlugin("hello", req); errcode.Of(err) != errcode.PluginVerifyFailed {
+			t.Errorf("InstallPlugin(%s) = %v, want PLUGIN_VERIFY_FAILED", name, err)
+		}
+	}
+
+	// 签名有效，但清单中的 ID 与请求安装的不一致
+	if err := m.InstallPlugin("other", InstallRequest{Source: SourceLocal, Data: pkg, Signature: signPackage(key, pkg)}); errcode.Of(err) != errcode.PluginVerifyFailed {
+		t.Errorf("InstallPlugin() with mismatched manifest ID = %v, want PLUGIN_VERIFY_FAILED", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.pluginsDir, "other")); !os.IsNotExist(err) {
+		t.Error("plugin directory should be removed after a rejected install")
+	}
+
+	if err := m.InstallPlugin("hello", InstallRequest{Source: SourceLocal, Data: pkg, Signature: signPackage(key, pkg)}); err != nil {
+		t.Fatalf("InstallPlugin() error: %v", err)
+	}
+	if p := m.plugins["hello"]; p == nil || p.Signer == "" {
+		t.Errorf("installed plugin = %+v, want signer recorded", p)
+	}
+}
+
+func TestInstallPluginFetchesDependenciesWithoutLock(t *testing.T) {
+	trusted, key := newSigningKey(t)
+	m := newTestManager(t, trusted)
+
+	dep := manifestPackage(t, `{"id":"dep","name":"Dep","version":"1.2.0"}`)
+	app := manifestPackage(t, `{"id":"app","name":"App","version":"1.0.0","dependencies":{"plugins":{"dep":"1.0.0"}}}`)
+
+	var lockedDuringDownload bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 下载依赖期间其他插件操作不应被阻塞
+		if !m.mu.TryLock() {
+			lockedDuringDownload = true
+		} else {
+			m.mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/plugins/dep/latest.tar.gz":
+			w.Write(dep)
+		case "/plugins/dep/latest.tar.gz.sig":
+			w.Write(signPackage(key, dep))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	// 测试服务器监听在 127.0.0.1，不经过内网地址检查
+	m.repoURL = srv.URL
+	m.client = srv.Client()
+
+	if err := m.InstallPlugin("app", InstallRequest{Source: SourceLocal, Data: app, Signature: signPackage(key, app)}); err != nil {
+		t.Fatalf("InstallPlugin() error: %v", err)
+	}
+	if m.plugins["dep"] == nil || m.plugins["app"] == nil {
+		t.Errorf("installed plugins = %v, want app and dep", m.plugins)
+	}
+	if lockedDuringDownload {
+		t.Error("manager lock held while downloading dependencies")
+	}
+
+	// 依赖下载失败时不安装
+	broken := manifestPackage(t, `{"id":"broken","name":"Broken","version":"1.0.0","dependencies":{"plugins":{"missing":""}}}`)
+	if err := m.InstallPlugin("broken", InstallRequest{Source: SourceLocal, Data: broken, Signature: signPackage(key, broken)}); errcode.Of(err) != errcode.PluginDepsUnmet {
+		t.Errorf("InstallPlugin() with missing dependency = %v, want PLUGIN_DEPS_UNMET", err)
+	}
+	if m.plugins["broken"] != nil {
+		t.Error("plugin with unmet dependencies should not be installed")
+	}
+}
+
+func TestExtractTarGzRejectsUnsafeEntries(t *testing.T) {
+	m := &Manager{}
+	tests := map[string]tarEntry{
+		"parent traversal": {name: "../escape.txt", body: "x"},
+		"nested traversal": {name: "bin/../../escape.txt", body: "x"},
+		"absolute path":    {name: "/tmp/escape.txt", body: "x"},
+		"symlink":          {name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"},
+		"hard link":        {name: "passwd", typeflag: tar.TypeLink, linkname: "/etc/passwd"},
+	}
+	for name, entry := range tests {
+		root := t.TempDir()
+		dest := filepath.Join(root, "plugin")
+		pkg := buildPackage(t, tarEntry{name: "manifest.json", body: "{}"}, entry)
+		if err := m.extractTarGz(bytes.NewReader(pkg), dest); err == nil {
+			t.Errorf("extractTarGz(%s) succeeded, want error", name)
+		}
+		if _, err := os.Lstat(filepath.Join(root, "escape.txt")); !os.IsNotExist(err) {
+			t.Errorf("extractTarGz(%s) wrote outside the plugin directory", name)
+		}
+	}
+
+	dest := filepath.Join(t.TempDir(), "plugin")
+	pkg := buildPackage(t, tarEntry{name: "./manifest.json", body: "{}"}, tarEntry{name: "bin/entry", body: "#!/bin/sh\n"})
+	if err := m.extractTarGz(bytes.NewReader(pkg), dest); err != nil {
+		t.Fatalf("extractTarGz() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "bin", "entry")); err != nil {
+		t.Errorf("extracted file missing: %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// registryIndexVersion 支持的索引格式版本
+	registryIndexVersion = 1
+	// maxRegistryIndexSize 索引文件的大小上限
+	maxRegistryIndexSize = 8 << 20
+	// registryRetryInterval 拉取失败后重试的最短间隔，避免每次查询都等待超时
+	registryRetryInterval = time.Minute
+	registryTimeout       = 15 * time.Second
+	// maxScreenshots 单个插件的截图数上限
+	maxScreenshots = 10
+)
+
+// validPluginID 插件 ID 只能包含小写字母、数字和连字符，同时用作插件目录名
+var validPluginID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// RegistryConfig 远程插件仓库配置
+type RegistryConfig struct {
+	// URL 仓库地址，索引位于 <URL>/index.json，签名位于 <URL>/index.json.sig
+	URL string
+	// PublicKeys 受信任的 Ed25519 公钥（base64），索引签名须由其中之一验证通过
+	PublicKeys []string
+	// CacheDir 已验证索引的缓存目录，仓库不可用时使用缓存
+	CacheDir string
+	// Refresh 索引的刷新间隔，默认 1 小时
+	Refresh time.Duration
+}
+
+// registryIndex 仓库索引
+type registryIndex struct {
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// ExpiresAt 过期后不再接受从仓库拉取的该索引，防止重放旧索引
+	ExpiresAt time.Time          `json:"expires_at"`
+	Plugins   []*AvailablePlugin `json:"plugins"`
+}
+
+// Registry 远程插件仓库客户端
+// 拉取的索引经签名和内容校验后缓存到本地；仓库不可用时返回缓存（可能已过期），
+// 没有缓存时返回内置的官方插件列表
+type Registry struct {
+	url      string
+	keys     []ed25519.PublicKey
+	cacheDir string
+	refresh  time.Duration
+	client   *http.Client
+
+	mu          sync.Mutex
+	index       *registryIndex
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewRegistry 创建仓库客户端并加载本地缓存
+func NewRegistry(cfg RegistryConfig) (*Registry, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("无效的插件仓库地址: %s", cfg.URL)
+	}
+	if len(cfg.PublicKeys) == 0 {
+		return nil, fmt.Errorf("未配置插件仓库公钥")
+	}
+	r := &Registry{
+		url:      strings.TrimRight(cfg.URL, "/"),
+		cacheDir: cfg.CacheDir,
+		refresh:  cfg.Refresh,
+		client:   &http.Client{Timeout: registryTimeout},
+	}
+	if r.refresh <= 0 {
+		r.refresh = time.Hour
+	}
+	for _, k := range cfg.PublicKeys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("无效的插件仓库公钥: %s", k)
+		}
+		r.keys = append(r.keys, ed25519.PublicKey(key))
+	}
+
+	if err := r.loadCache(); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Msg("插件仓库缓存无效，已忽略")
+	}
+	return r, nil
+}
+
+// URL 返回仓库地址
+func (r *Registry) URL() string {
+	return r.url
+}
+
+// Plugins 返回仓库中的插件列表，索引超过刷新间隔时先尝试重新拉取
+func (r *Registry) Plugins(ctx context.Context) []*AvailablePlugin {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if (r.index == nil || time.Since(r.fetchedAt) >= r.refresh) && time.Since(r.lastAttempt) >= registryRetryInterval {
+		r.lastAttempt = time.Now()
+		if err := r.fetchLocked(ctx); err != nil {
+			log.Warn().Err(err).Str("url", r.url).Msg("拉取插件仓库索引失败，使用缓存")
+		}
+	}
+	if r.index == nil {
+		return officialCatalog
+	}
+	if !r.index.ExpiresAt.IsZero() && time.Now().After(r.index.ExpiresAt) {
+		log.Warn().Time("expires_at", r.index.ExpiresAt).Msg("插件仓库索引缓存已过期")
+	}
+	return r.index.Plugins
+}
+
+// Refresh 立即从仓库拉取索引
+func (r *Registry) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastAttempt = time.Now()
+	return r.fetchLocked(ctx)
+}
+
+// fetchLocked 拉取并验证索引，成功后替换内存中的索引并写入缓存
+func (r *Registry) fetchLocked(ctx context.Context) error {
+	data, err := r.get(ctx, "/index.json")
+	if err != nil {
+		return err
+	}
+	sig, err := r.get(ctx, "/index.json.sig")
+	if err != nil {
+		return err
+	}
+	index, err := r.verify(data, sig)
+	if err != nil {
+		return err
+	}
+	if !index.ExpiresAt.IsZero() && time.Now().After(index.ExpiresAt) {
+		return fmt.Errorf("索引已于 %s 过期", index.ExpiresAt.Format(time.RFC3339))
+	}
+	// 不接受比已有索引更旧的索引，防止回滚到存在漏洞的插件版本
+	if r.index != nil && index.GeneratedAt.Before(r.index.GeneratedAt) {
+		return fmt.Errorf("索引生成时间 %s 早于缓存", index.GeneratedAt.Format(time.RFC3339))
+	}
+
+	r.index = index
+	r.fetchedAt = time.Now()
+	if err := r.saveCache(data, sig); err != nil {
+		log.Warn().Err(err).Msg("保存插件仓库缓存失败")
+	}
+	log.Info().Int("count", len(index.Plugins)).Msg("已更新插件仓库索引")
+	return nil
+}
+
+func (r *Registry) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取 %s 失败: %s", path, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryIndexSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRegistryIndexSize {
+		return nil, fmt.Errorf("%s 超过 %d 字节限制", path, maxRegistryIndexSize)
+	}
+	return data, nil
+}
+
+// verify 验证签名（base64 编码的 Ed25519 签名）后解析并校验索引内容
+func (r *Registry) verify(data, sig []byte) (*registryIndex, error) {
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("索引签名格式无效")
+	}
+	trusted := false
+	for _, key := range r.keys {
+		if ed25519.Verify(key, data, signature) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return nil, fmt.Errorf("索引签名验证失败")
+	}
+
+	var index registryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("解析索引失败: %w", err)
+	}
+	if index.Version != registryIndexVersion {
+		return nil, fmt.Errorf("不支持的索引版本: %d", index.Version)
+	}
+	seen := make(map[string]bool, len(index.Plugins))
+	for i, p := range index.Plugins {
+		if err := validateAvailablePlugin(p); err != nil {
+			return nil, fmt.Errorf("索引第 %d 项: %w", i+1, err)
+		}
+		if seen[p.ID] {
+			return nil, fmt.Errorf("索引中插件 %s 重复", p.ID)
+		}
+		seen[p.ID] = true
+	}
+	return &index, nil
+}
+
+// validateAvailablePlugin 校验索引中的单个插件
+func validateAvailablePlugin(p *AvailablePlugin) error {
+	if p == nil {
+		return fmt.Errorf("插件为空")
+	}
+	if !validPluginID.MatchString(p.ID) {
+		return fmt.Errorf("无效的插件 ID: %q", p.ID)
+	}
+	if p.Name == "" || p.Version == "" {
+		return fmt.Errorf("插件 %s 缺少名称或版本", p.ID)
+	}
+	switch p.Type {
+	case TypeClient, TypeAgent, TypeHybrid:
+	default:
+		return fmt.Errorf("插件 %s 的类型无效: %q", p.ID, p.Type)
+	}
+	if p.DownloadURL != "" {
+		if u, err := url.Parse(p.DownloadURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("插件 %s 的下载地址必须是 https: %s", p.ID, p.DownloadURL)
+		}
+	}
+	if len(p.Screenshots) > maxScreenshots {
+		return fmt.Errorf("插件 %s 的截图超过 %d 张", p.ID, maxScreenshots)
+	}
+	// 截图由客户端直接加载，只允许 https 地址
+	for _, s := range p.Screenshots {
+		if u, err := url.Parse(s); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("插件 %s 的截图地址必须是 https: %s", p.ID, s)
+		}
+	}
+	return nil
+}
+
+// loadCache 加载并重新验证缓存的索引，缓存文件的修改时间作为拉取时间
+func (r *Registry) loadCache() error {
+	if r.cacheDir == "" {
+		return nil
+	}
+	path := filepath.Join(r.cacheDir, "index.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sig, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return err
+	}
+	index, err := r.verify(data, sig)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	r.index = index
+	r.fetchedAt = info.ModTime()
+	return nil
+}
+
+// saveCache 原样保存索引和签名，加载时重新验证
+func (r *Registry) saveCache(data, sig []byte) error {
+	if r.cacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(r.cacheDir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(r.cacheDir, "index.json")
+	// 先写签名：两次写入之间中断时，旧索引配新签名验证失败，缓存被忽略而不是被误用
+	for _, f := range []struct {
+		path string
+		data []byte
+	}{{path + ".sig", sig}, {path, data}} {
+		tmp := f.path + ".tmp"
+		if err := os.WriteFile(tmp, f.data, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, f.path); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	return nil
+}
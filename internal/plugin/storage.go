@@ -0,0 +1,213 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/pkg/pluginsdk"
+)
+
+const (
+	// storageFile 插件 KV 存储文件，位于插件目录，卸载插件时一并删除
+	storageFile = "storage.json"
+	// maxStorageKey 键的最大字节数
+	maxStorageKey = 256
+	// maxStorageValue 单个值的最大字节数
+	maxStorageValue = 1 << 20
+	// defaultStorageQuota 每个插件存储的默认总大小（键和值的字节数之和）
+	defaultStorageQuota = 8 << 20
+)
+
+// pluginStorage 插件私有的 KV 存储，整体保存为一个 JSON 文件，每次修改后原子替换
+//
+// 每个插件只能访问自己的存储，无需在清单中声明权限。
+type pluginStorage struct {
+	path  string
+	quota int
+
+	mu     sync.Mutex
+	data   map[string][]byte
+	size   int
+	loaded bool
+}
+
+func newPluginStorage(dir string, quota int) *pluginStorage {
+	if quota <= 0 {
+		quota = defaultStorageQuota
+	}
+	return &pluginStorage{path: filepath.Join(dir, storageFile), quota: quota}
+}
+
+// loadLocked 首次访问时读取存储文件（需要持有锁）
+func (s *pluginStorage) loadLocked() error {
+	if s.loaded {
+		return nil
+	}
+	data := make(map[string][]byte)
+	raw, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return fmt.Errorf("插件存储文件损坏: %w", err)
+		}
+	}
+	s.data = data
+	s.size = 0
+	for k, v := range data {
+		s.size += len(k) + len(v)
+	}
+	s.loaded = true
+	return nil
+}
+
+// saveLocked 把存储写回文件（需要持有锁）
+func (s *pluginStorage) saveLocked() error {
+	raw, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, raw, 0600)
+}
+
+func checkStorageKey(key string) error {
+	if key == "" || len(key) > maxStorageKey || !utf8.ValidString(key) {
+		return errcode.New(errcode.InvalidArgument, "存储键必须是不超过 %d 字节的 UTF-8 字符串", maxStorageKey)
+	}
+	return nil
+}
+
+// Get 读取键的值，键不存在时 found 为 false
+func (s *pluginStorage) Get(key string) (value []byte, found bool, err error) {
+	if err := checkStorageKey(key); err != nil {
+		return nil, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return nil, false, err
+	}
+	value, found = s.data[key]
+	return value, found, nil
+}
+
+// Set 写入键值，超过单值上限或存储配额时返回错误且不修改存储
+func (s *pluginStorage) Set(key string, value []byte) error {
+	if err := checkStorageKey(key); err != nil {
+		return err
+	}
+	if len(value) > maxStorageValue {
+		return errcode.New(errcode.InvalidArgument, "存储值超过 %d KB", maxStorageValue>>10)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+
+	old, exists := s.data[key]
+	size := s.size + len(key) + len(value)
+	if exists {
+		size -= len(key) + len(old)
+	}
+	if size > s.quota {
+		return fmt.Errorf("%w（%d KB）", pluginsdk.ErrQuotaExceeded, s.quota>>10)
+	}
+
+	s.data[key] = append([]byte(nil), value...)
+	if err := s.saveLocked(); err != nil {
+		if exists {
+			s.data[key] = old
+		} else {
+			delete(s.data, key)
+		}
+		return err
+	}
+	s.size = size
+	return nil
+}
+
+// Delete 删除键，键不存在时不报错
+func (s *pluginStorage) Delete(key string) error {
+	if err := checkStorageKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+
+	old, exists := s.data[key]
+	if !exists {
+		return nil
+	}
+	delete(s.data, key)
+	if err := s.saveLocked(); err != nil {
+		s.data[key] = old
+		return err
+	}
+	s.size -= len(key) + len(old)
+	return nil
+}
+
+// List 按字典序列出以 prefix 开头的键
+func (s *pluginStorage) List(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// storageLocked 返回插件的 KV 存储，插件多次启动共用同一个实例（需要持有锁）
+func (m *Manager) storageLocked(id string) *pluginStorage {
+	s, ok := m.storages[id]
+	if !ok {
+		s = newPluginStorage(filepath.Join(m.pluginsDir, id), m.limits.Storage)
+		m.storages[id] = s
+	}
+	return s
+}
+
+// writeFileAtomic 写入同目录的临时文件并 fsync 后重命名替换 path
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 试运行各步骤的结果
+const (
+	VerifyPassed  = "passed"
+	VerifyFailed  = "failed"
+	VerifySkipped = "skipped"
+)
+
+// VerifyResult 插件试运行的结果
+type VerifyResult struct {
+	PluginID string        `json:"plugin_id"`
+	OK       bool          `json:"ok"` // 没有失败的步骤
+	Steps    []*VerifyStep `json:"steps"`
+	// Denials 试运行期间因未声明权限被拒绝的访问，提示清单缺少的权限
+	Denials []PermissionDenial `json:"denials,omitempty"`
+}
+
+// VerifyStep 试运行的一个步骤：config、dependencies、start、health_check、stop
+type VerifyStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // passed、failed 或 skipped
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// VerifyPlugin 试运行插件而不启用：按清单校验已保存的配置、检查依赖，再用与正式运行相同的权限、
+// 资源限制和进程隔离启动插件、做一次健康检查并停止。插件状态不变，试运行期间插件发布的事件和告警被丢弃。
+// 插件正在运行时不再启动第二个实例，只做配置和依赖检查
+func (m *Manager) VerifyPlugin(id string) (*VerifyResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plugin, exists := m.plugins[id]
+	if !exists {
+		return nil, errcode.New(errcode.PluginNotFound, "插件 %s 未安装", id)
+	}
+
+	result := &VerifyResult{PluginID: id, OK: true}
+	start := time.Now()
+	config, err := applyConfigSchema(plugin.Manifest.ConfigSchema, plugin.Config, plugin.Config)
+	result.add("config", start, err)
+
+	start = time.Now()
+	notes, err := m.verifyDependenciesLocked(plugin.Manifest)
+	if step := result.add("dependencies", start, err); err == nil {
+		step.Message = strings.Join(notes, "；")
+	}
+
+	runSteps := []string{"start", "health_check", "stop"}
+	switch {
+	case !result.OK:
+		result.skip("配置或依赖检查未通过", runSteps...)
+	case plugin.Manifest.Type == TypeClient:
+		result.skip("客户端插件不在 Agent 端运行", runSteps...)
+	case m.runtimes[id] != nil && m.runtimes[id].running:
+		result.skip("插件正在运行，不再启动试运行实例", runSteps...)
+	default:
+		m.verifyRunLocked(plugin, config, result)
+	}
+
+	log.Info().Str("id", id).Bool("ok", result.OK).Msg("插件试运行完成")
+	return result, nil
+}
+
+// verifyRunLocked 启动试运行实例，做一次健康检查后停止（需要持有锁）
+func (m *Manager) verifyRunLocked(plugin *InstalledPlugin, config map[string]any, result *VerifyResult) {
+	id := plugin.Manifest.ID
+
+	start := time.Now()
+	runtime, err := m.newRuntimeLocked(plugin, nil)
+	if err == nil {
+		runtime.broker.trace.started()
+		err = recoverPanic(func() error { return startLabeled(m.ctx, id, runtime.instance, config) })
+	}
+	if err != nil {
+		if runtime != nil {
+			runtime.broker.release()
+			_, result.Denials = runtime.broker.Denials()
+		}
+		result.add("start", start, err)
+		result.skip("插件启动失败", "health_check", "stop")
+		return
+	}
+	result.add("start", start, nil)
+
+	start = time.Now()
+	ctx, cancel := context.WithTimeout(m.ctx, m.health.Timeout)
+	err = recoverPanic(func() error { return runtime.instance.HealthCheck(ctx) })
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("健康检查超时")
+	}
+	cancel()
+	result.add("health_check", start, err)
+
+	start = time.Now()
+	runtime.broker.release()
+	err = recoverPanic(runtime.instance.Stop)
+	m.traceLocked(id).record(TraceLifecycle, "plugin.stopped", nil)
+	result.add("stop", start, err)
+	_, result.Denials = runtime.broker.Denials()
+}
+
+// add 记录一个执行过的步骤，err 不为空时步骤失败，校验错误的字段附在说明中
+func (r *VerifyResult) add(name string, start time.Time, err error) *VerifyStep {
+	step := &VerifyStep{Name: name, Status: VerifyPassed, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		step.Status = VerifyFailed
+		step.Message = err.Error()
+		// 插件配置的校验错误说明中已列出字段，插件自身的校验错误（如 ParseConfig）只有概要
+		if fields := errcode.FieldsOf(err); len(fields) > 0 && !strings.Contains(step.Message, fields[0].Field+": ") {
+			problems := make([]string, 0, len(fields))
+			for _, f := range fields {
+				problems = append(problems, f.Field+": "+f.Description)
+			}
+			step.Message += ": " + strings.Join(problems, "; ")
+		}
+		r.OK = false
+	}
+	r.Steps = append(r.Steps, step)
+	return step
+}
+
+// skip 记录因 reason 跳过的步骤
+func (r *VerifyResult) skip(reason string, names ...string) {
+	for _, name := range names {
+		r.Steps = append(r.Steps, &VerifyStep{Name: name, Status: VerifySkipped, Message: reason})
+	}
+}
+
+// verifyDependenciesLocked 检查依赖是否满足但不启用依赖插件（需要持有锁），返回启用时需要注意的事项
+func (m *Manager) verifyDependenciesLocked(manifest *PluginManifest) ([]string, error) {
+	problems := m.checkEnvironment(manifest)
+	var notes []string
+	for _, id := range manifest.Dependencies.pluginIDs() {
+		dep, ok := m.plugins[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("依赖插件 %s 未安装，请先安装", id))
+			continue
+		}
+		if p := checkPluginVersion(dep, manifest.Dependencies.Plugins[id]); p != "" {
+			problems = append(problems, p)
+			continue
+		}
+		if dep.State != StateEnabled {
+			notes = append(notes, fmt.Sprintf("依赖插件 %s 未启用，启用时会一并启用", id))
+		}
+	}
+	return notes, dependencyError(manifest.ID, problems)
+}
+
+// recoverPanic 调用 fn，把进程内插件的 panic 转为错误，避免试运行使 Agent 退出
+func recoverPanic(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Str("stack", string(debug.Stack())).Msgf("插件 panic: %v", r)
+			err = fmt.Errorf("插件 panic: %v", r)
+		}
+	}()
+	return fn()
+}
@@ -7,10 +7,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/runixo/agent/internal/errcode"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
-	"google.golang.org/grpc/status"
 )
 
 // Config 速率限制配置
@@ -219,7 +218,7 @@ func (l *Limiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
 		}
 
 		if !allowed {
-			return nil, status.Error(codes.ResourceExhausted, "请求过于频繁，请稍后重试")
+			return nil, errcode.Status(errcode.RateLimited, "请求过于频繁，请稍后重试")
 		}
 
 		return handler(ctx, req)
@@ -239,7 +238,7 @@ func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
 		}
 
 		if !l.AllowRequest(ss.Context()) {
-			return status.Error(codes.ResourceExhausted, "请求过于频繁，请稍后重试")
+			return errcode.Status(errcode.RateLimited, "请求过于频繁，请稍后重试")
 		}
 
 		return handler(srv, ss)
@@ -250,6 +249,9 @@ func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
 func isCommandMethod(method string) bool {
 	commandMethods := []string{
 		"ExecuteCommand",
+		"ExecuteStream",
+		"ExecuteScript",
+		"ExecuteBatch",
 		"ExecuteShell",
 		"ServiceAction",
 		"KillProcess",
@@ -271,6 +273,18 @@ func isFileMethod(method string) bool {
 		"ListDirectory",
 		"UploadFile",
 		"DownloadFile",
+		"GetUploadOffset",
+		"HashFile",
+		"CompareFiles",
+		"SearchFiles",
+		"Chmod",
+		"Chown",
+		"SetACL",
+		"CopyPath",
+		"MovePath",
+		"DeletePath",
+		"RestoreTrash",
+		"GetDirectorySize",
 	}
 	for _, m := range fileMethods {
 		if contains(method, m) {
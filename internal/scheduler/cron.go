@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron 解析后的 cron 表达式
+//
+// 支持标准的 5 个字段（分 时 日 月 周），字段可使用 *、列表（1,3）、范围（1-5）、步长（*/15、10-40/10）
+// 以及月份和星期的英文缩写（jan、mon），星期的 0 和 7 都表示周日。
+// 日和周都不是 * 时满足其一即触发，与 Vixie cron 一致。
+// 时间按任务时区的挂钟时间计算：夏令时开始时不存在的时刻当天不触发，结束时重复的时刻只触发一次。
+// 另支持 @yearly、@monthly、@weekly、@daily、@hourly 和 @every <时长>。
+type Cron struct {
+	expr                     string
+	minute, hour, dom, month uint64
+	dow                      uint64
+	domStar, dowStar         bool
+	every                    time.Duration
+}
+
+// cronField 字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = [5]cronField{
+	{name: "分", min: 0, max: 59},
+	{name: "时", min: 0, max: 23},
+	{name: "日", min: 1, max: 31},
+	{name: "月", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "周", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// minEvery @every 的最小间隔
+const minEvery = time.Second
+
+// ParseCron 解析 cron 表达式
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < minEvery {
+			return nil, fmt.Errorf("无效的间隔 %q，至少为 %s", rest, minEvery)
+		}
+		return &Cron{expr: expr, every: d}, nil
+	}
+	spec := expr
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		spec = d
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron 表达式应有 %d 个字段: %q", len(cronFields), expr)
+	}
+	c := &Cron{expr: expr}
+	dst := [5]*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, part := range parts {
+		bits, star, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		*dst[i] = bits
+		switch i {
+		case 2:
+			c.domStar = star
+		case 4:
+			c.dowStar = star
+		}
+	}
+	// 周日可写作 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	if c.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron 表达式不会触发: %q", expr)
+	}
+	return c, nil
+}
+
+// parseCronField 解析一个字段，返回取值的位集合；star 表示字段以 * 或 ? 开头
+func parseCronField(s string, f cronField) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("%s字段的步长无效: %q", f.name, part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*" || rng == "?":
+			lo, hi = f.min, f.max
+			star = true
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			if lo, err = parseCronValue(a, f); err != nil {
+				return 0, false, err
+			}
+			if hi, err = parseCronValue(b, f); err != nil {
+				return 0, false, err
+			}
+		default:
+			if lo, err = parseCronValue(rng, f); err != nil {
+				return 0, false, err
+			}
+			hi = lo
+			if hasStep {
+				hi = f.max
+			}
+		}
+		if lo > hi {
+			return 0, false, fmt.Errorf("%s字段的范围无效: %q", f.name, part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s字段的值无效: %q（%d-%d）", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String 返回原始表达式
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next 返回 t 之后（不含 t）的下一个触发时间，时区取 t 的时区；5 年内不会触发时返回零值
+func (c *Cron) Next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Truncate(time.Second).Add(c.every)
+	}
+
+	loc := t.Location()
+	from := wallClock(t)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			// 夏令时切换当天按挂钟时间前进，不存在的时刻由 time.Date 规范化到之后的时刻
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			if !next.After(t) {
+				next = t.Add(time.Hour).Truncate(time.Hour)
+			}
+			t = next
+			continue
+		}
+		// 夏令时结束时重复的一小时内不重复触发
+		if c.minute&(1<<uint(t.Minute())) == 0 || wallClock(t) <= from {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// wallClock 把挂钟时间（精确到分）转为可比较的整数
+func wallClock(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC).Unix()
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,369 @@
+// Package scheduler Agent 共享的定时任务调度器
+//
+// 任务按 cron 表达式在指定时区触发，支持随机延迟、防止同一任务重叠运行，
+// 以及主机休眠或 Agent 停止期间错过运行时的补偿策略。插件通过 Host 注册任务，核心模块直接调用 Add。
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 错过运行的处理策略
+const (
+	// MissedSkip 跳过错过的运行，等待下一个触发时间（默认）
+	MissedSkip = "skip"
+	// MissedRunOnce 错过一次或多次运行后立即补跑一次
+	MissedRunOnce = "run_once"
+)
+
+const (
+	// missedGrace 实际触发时间晚于计划超过该值视为错过运行（主机休眠、Agent 停止等）
+	missedGrace = time.Minute
+	// maxJitter 随机延迟的上限
+	maxJitter = time.Hour
+	// maxMissedCount 统计错过次数时最多向前推算的触发次数
+	maxMissedCount = 10000
+)
+
+// Spec 任务的调度方式
+type Spec struct {
+	Cron      string        // cron 表达式，见 Cron
+	Timezone  string        // IANA 时区名，为空时使用 Agent 的本地时区
+	Jitter    time.Duration // 每次触发随机推迟 [0, Jitter)，用于错开多台主机的同一任务
+	MissedRun string        // MissedSkip 或 MissedRunOnce，为空时为 MissedSkip
+	Timeout   time.Duration // 单次运行的超时，0 表示不限
+}
+
+// Func 任务函数，scheduled 为计划触发时间（不含随机延迟）
+type Func func(ctx context.Context, scheduled time.Time) error
+
+// TaskInfo 任务的调度状态
+type TaskInfo struct {
+	ID           string        `json:"id"`
+	Owner        string        `json:"owner,omitempty"`
+	Cron         string        `json:"cron"`
+	Timezone     string        `json:"timezone"`
+	Jitter       time.Duration `json:"jitter,omitempty"`
+	MissedRun    string        `json:"missed_run"`
+	Running      bool          `json:"running"`
+	NextRun      *time.Time    `json:"next_run,omitempty"`
+	LastRun      *time.Time    `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	Overlaps     int           `json:"overlaps"` // 上次运行未结束而跳过的次数
+	Missed       int           `json:"missed"`   // 错过的触发次数（含补跑的那次）
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	statePath string
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	saveMu    sync.Mutex // 串行写入状态文件
+
+	mu       sync.Mutex
+	tasks    map[string]*task
+	lastRuns map[string]time.Time // 任务 ID -> 上次运行时间，保存在 statePath
+}
+
+type task struct {
+	id     string
+	owner  string
+	spec   Spec
+	cron   *Cron
+	loc    *time.Location
+	fn     Func
+	cancel context.CancelFunc
+
+	// 以下字段由 Scheduler.mu 保护
+	next         time.Time
+	running      bool
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastError    string
+	runs         int
+	failures     int
+	overlaps     int
+	missed       int
+}
+
+// New 创建调度器，statePath 保存各任务的上次运行时间，用于判断 Agent 停止期间错过的运行；为空时不保存
+func New(statePath string) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		statePath: statePath,
+		ctx:       ctx,
+		cancel:    cancel,
+		tasks:     make(map[string]*task),
+		lastRuns:  make(map[string]time.Time),
+	}
+	if statePath != "" {
+		if data, err := os.ReadFile(statePath); err == nil {
+			if err := json.Unmarshal(data, &s.lastRuns); err != nil {
+				log.Warn().Err(err).Str("path", statePath).Msg("定时任务状态文件损坏，已忽略")
+				s.lastRuns = make(map[string]time.Time)
+			}
+		}
+	}
+	return s
+}
+
+// Add 添加任务，同 ID 的任务已存在时替换（正在进行的运行会被取消）
+// owner 用于按所有者批量移除和查询，例如插件 ID
+func (s *Scheduler) Add(id, owner string, spec Spec, fn Func) error {
+	if id == "" {
+		return errcode.New(errcode.InvalidArgument, "任务 ID 不能为空")
+	}
+	cron, err := ParseCron(spec.Cron)
+	if err != nil {
+		return errcode.Wrap(errcode.InvalidArgument, err, "定时任务无效")
+	}
+	loc := time.Local
+	if spec.Timezone != "" {
+		if loc, err = time.LoadLocation(spec.Timezone); err != nil {
+			return errcode.New(errcode.InvalidArgument, "未知的时区: %q", spec.Timezone)
+		}
+	}
+	if spec.Jitter < 0 || spec.Jitter > maxJitter {
+		return errcode.New(errcode.InvalidArgument, "随机延迟必须在 0 到 %s 之间", maxJitter)
+	}
+	if spec.Timeout < 0 {
+		return errcode.New(errcode.InvalidArgument, "超时不能为负")
+	}
+	switch spec.MissedRun {
+	case "":
+		spec.MissedRun = MissedSkip
+	case MissedSkip, MissedRunOnce:
+	default:
+		return errcode.New(errcode.InvalidArgument, "错过运行策略只能是 %s 或 %s: %q", MissedSkip, MissedRunOnce, spec.MissedRun)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return errcode.New(errcode.Unavailable, "调度器已停止")
+	}
+	if old, ok := s.tasks[id]; ok {
+		old.cancel()
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	t := &task{id: id, owner: owner, spec: spec, cron: cron, loc: loc, fn: fn, cancel: cancel, lastRun: s.lastRuns[id]}
+	s.tasks[id] = t
+	s.wg.Add(1)
+	go s.loop(ctx, t, s.lastRuns[id])
+	return nil
+}
+
+// Remove 移除任务并取消正在进行的运行，任务不存在时忽略
+func (s *Scheduler) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tasks[id]; ok {
+		t.cancel()
+		delete(s.tasks, id)
+	}
+}
+
+// RemoveOwner 移除 owner 的所有任务
+func (s *Scheduler) RemoveOwner(owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, t := range s.tasks {
+		if t.owner == owner {
+			t.cancel()
+			delete(s.tasks, id)
+		}
+	}
+}
+
+// Tasks 按 ID 排序返回任务状态，owner 为空时返回全部任务
+func (s *Scheduler) Tasks(owner string) []TaskInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]TaskInfo, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		if owner != "" && t.owner != owner {
+			continue
+		}
+		info := TaskInfo{
+			ID:           t.id,
+			Owner:        t.owner,
+			Cron:         t.cron.String(),
+			Timezone:     t.loc.String(),
+			Jitter:       t.spec.Jitter,
+			MissedRun:    t.spec.MissedRun,
+			Running:      t.running,
+			LastDuration: t.lastDuration,
+			LastError:    t.lastError,
+			Runs:         t.runs,
+			Failures:     t.failures,
+			Overlaps:     t.overlaps,
+			Missed:       t.missed,
+		}
+		if !t.next.IsZero() {
+			next := t.next
+			info.NextRun = &next
+		}
+		if !t.lastRun.IsZero() {
+			last := t.lastRun
+			info.LastRun = &last
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Stop 停止调度器，取消所有正在进行的运行并等待其返回
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop 等待任务的每个触发时间，last 为上次运行时间（来自状态文件）
+func (s *Scheduler) loop(ctx context.Context, t *task, last time.Time) {
+	defer s.wg.Done()
+
+	planned := time.Time{}
+	if !last.IsZero() {
+		// Agent 停止期间错过的运行按同样的策略处理
+		if p := t.cron.Next(last.In(t.loc)); !p.IsZero() && p.Before(time.Now()) {
+			planned = p
+		}
+	}
+	if planned.IsZero() {
+		planned = t.cron.Next(time.Now().In(t.loc))
+	}
+
+	for !planned.IsZero() {
+		var jitter time.Duration
+		if t.spec.Jitter > 0 {
+			jitter = rand.N(t.spec.Jitter)
+		}
+		s.mu.Lock()
+		t.next = planned.Add(jitter)
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(planned.Add(jitter)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		if now.Sub(planned.Add(jitter)) > missedGrace {
+			missed := 0
+			for p := planned; !p.IsZero() && p.Before(now) && missed < maxMissedCount; p = t.cron.Next(p) {
+				missed++
+			}
+			s.mu.Lock()
+			t.missed += missed
+			s.mu.Unlock()
+			log.Warn().Str("task", t.id).Int("missed", missed).Str("policy", t.spec.MissedRun).Msg("定时任务错过运行")
+			if t.spec.MissedRun == MissedRunOnce {
+				s.run(ctx, t, planned)
+			}
+		} else {
+			s.run(ctx, t, planned)
+		}
+		planned = t.cron.Next(now.In(t.loc))
+	}
+}
+
+// run 在新的 goroutine 中运行任务，上次运行尚未结束时跳过本次
+func (s *Scheduler) run(ctx context.Context, t *task, scheduled time.Time) {
+	s.mu.Lock()
+	if t.running {
+		t.overlaps++
+		s.mu.Unlock()
+		log.Warn().Str("task", t.id).Msg("定时任务上次运行尚未结束，跳过本次运行")
+		return
+	}
+	t.running = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if t.spec.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.spec.Timeout)
+			defer cancel()
+		}
+
+		start := time.Now()
+		err := call(ctx, t.fn, scheduled)
+		// 任务被移除或调度器停止导致的失败不记日志
+		if err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Str("task", t.id).Msg("定时任务运行失败")
+		}
+
+		s.mu.Lock()
+		t.running = false
+		t.lastRun = start
+		t.lastDuration = time.Since(start)
+		t.runs++
+		t.lastError = ""
+		if err != nil {
+			t.failures++
+			t.lastError = err.Error()
+		}
+		s.lastRuns[t.id] = start
+		s.mu.Unlock()
+		s.saveState()
+	}()
+}
+
+// call 调用任务函数，panic 作为运行失败处理
+func call(ctx context.Context, fn Func, scheduled time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, scheduled)
+}
+
+// saveState 原子替换状态文件
+func (s *Scheduler) saveState() {
+	if s.statePath == "" {
+		return
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	data, err := json.Marshal(s.lastRuns)
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0700); err != nil {
+		log.Warn().Err(err).Msg("保存定时任务状态失败")
+		return
+	}
+	tmp := s.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Warn().Err(err).Msg("保存定时任务状态失败")
+		return
+	}
+	if err := os.Rename(tmp, s.statePath); err != nil {
+		log.Warn().Err(err).Msg("保存定时任务状态失败")
+	}
+}
@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("时区数据不可用")
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("时区数据不可用")
+	}
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 5, 1, 10, 7, 30, 0, time.UTC), time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * mon-fri", time.Date(2024, 5, 3, 3, 0, 0, 0, shanghai), time.Date(2024, 5, 6, 3, 0, 0, 0, shanghai)},
+		{"0 0 29 feb *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// 日和周都受限时满足其一即可
+		{"0 12 1 * 7", time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// 夏令时开始时 2:30 不存在，当天不触发
+		{"30 2 * * *", time.Date(2024, 3, 10, 0, 0, 0, 0, newYork), time.Date(2024, 3, 11, 2, 30, 0, 0, newYork)},
+		// 夏令时结束时重复的 1:30 只触发一次
+		{"30 1 * * *", time.Date(2024, 11, 3, 1, 30, 0, 0, newYork), time.Date(2024, 11, 4, 1, 30, 0, 0, newYork)},
+		{"@every 90s", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 0, 1, 30, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "0 0 30 2 *", "@every 10ms"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestMissedRunAfterRestart(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "scheduler.json")
+	data, _ := json.Marshal(map[string]time.Time{"backup": time.Now().Add(-150 * time.Minute)})
+	if err := os.WriteFile(state, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(state)
+	defer s.Stop()
+	ran := make(chan time.Time, 1)
+	err := s.Add("backup", "demo", Spec{Cron: "@hourly", MissedRun: MissedRunOnce}, func(_ context.Context, scheduled time.Time) error {
+		ran <- scheduled
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("missed run not caught up")
+	}
+	time.Sleep(50 * time.Millisecond)
+	info := s.Tasks("demo")
+	if len(info) != 1 || info[0].Runs != 1 || info[0].Missed < 2 || info[0].NextRun == nil {
+		t.Errorf("unexpected task state: %+v", info)
+	}
+
+	if err := s.Add("bad", "", Spec{Cron: "@hourly", Timezone: "Mars/Olympus"}, nil); err == nil {
+		t.Error("unknown timezone accepted")
+	}
+}
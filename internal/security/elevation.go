@@ -0,0 +1,106 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// ElevationRule 允许提权执行的操作，Command / Args 的写法与 PolicyRule 相同
+type ElevationRule struct {
+	Name    string `mapstructure:"name"`
+	Command string `mapstructure:"command"`
+	Args    string `mapstructure:"args"`
+
+	rule PolicyRule
+}
+
+// ElevationConfig 提权配置
+type ElevationConfig struct {
+	// Mode 为空或 none 时不提权（Agent 以 root 运行的旧模式）；sudo 时匹配规则的操作通过 sudo -n 执行
+	Mode string `mapstructure:"mode"`
+	// Sudo sudo 程序路径，默认 sudo
+	Sudo string `mapstructure:"sudo"`
+	// Helper 由 sudo 执行的 root 所有的辅助程序，设置后以 sudo -n helper 命令 参数 执行，
+	// 由辅助程序再次校验命令和参数，sudoers 只需放行辅助程序
+	Helper string `mapstructure:"helper"`
+	// Rules 允许提权的操作，为空时使用 DefaultElevationRules
+	Rules []ElevationRule `mapstructure:"rules"`
+}
+
+// DefaultElevationRules 默认允许提权的操作：服务管理和安装软件包。
+// 服务名和包名必须以字母或数字开头，避免被当作选项传给以 root 运行的程序
+func DefaultElevationRules() []ElevationRule {
+	return []ElevationRule{
+		{Name: "service", Command: "systemctl", Args: `re:^(start|stop|restart|reload|enable|disable) [A-Za-z0-9][A-Za-z0-9@._:-]*$`},
+		{Name: "package-install", Command: "re:^(apt-get|apt|dnf|yum)$", Args: `re:^install -y( [A-Za-z0-9][A-Za-z0-9.+_:=~-]*)+$`},
+	}
+}
+
+// Elevation 提权策略
+// Agent 以普通用户运行，只有匹配规则的命令通过 sudo 执行。sudoers 需要为 Agent 用户
+// 放行对应的程序（NOPASSWD）；sudo -n 在需要密码时直接失败而不是等待输入。
+// 规则只约束经过 Agent 的调用，sudoers 放行的程序不限制参数时，应配置 Helper 在 root 侧再次校验
+type Elevation struct {
+	sudo   string
+	helper string
+	rules  []ElevationRule
+}
+
+// NewElevation 校验并编译提权配置，Mode 为空或 none 时返回 nil
+func NewElevation(cfg ElevationConfig) (*Elevation, error) {
+	switch strings.ToLower(cfg.Mode) {
+	case "", "none":
+		return nil, nil
+	case "sudo":
+	default:
+		return nil, fmt.Errorf("无效的提权模式: %s", cfg.Mode)
+	}
+
+	e := &Elevation{sudo: cfg.Sudo, helper: cfg.Helper}
+	if e.sudo == "" {
+		e.sudo = "sudo"
+	}
+	rules := cfg.Rules
+	if len(rules) == 0 {
+		rules = DefaultElevationRules()
+	}
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("#%d", i+1)
+		}
+		if r.Command == "" {
+			return nil, fmt.Errorf("提权规则 %s: command 不能为空", r.Name)
+		}
+		var err error
+		if r.rule.command, err = compilePattern(r.Command); err != nil {
+			return nil, fmt.Errorf("提权规则 %s: command: %w", r.Name, err)
+		}
+		if r.rule.args, err = compilePattern(r.Args); err != nil {
+			return nil, fmt.Errorf("提权规则 %s: args: %w", r.Name, err)
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+// Check 检查命令是否允许提权执行，返回匹配的规则名
+func (e *Elevation) Check(command string, args []string) (string, error) {
+	joined := strings.Join(args, " ")
+	for _, r := range e.rules {
+		if r.rule.matches("", command, joined) {
+			return r.Name, nil
+		}
+	}
+	return "", errcode.New(errcode.CommandNotAllowed, "命令 '%s' 不在提权规则中", command)
+}
+
+// Command 返回提权执行 command 时实际运行的程序和参数
+func (e *Elevation) Command(command string, args []string) (string, []string) {
+	sudoArgs := []string{"-n", "--"}
+	if e.helper != "" {
+		sudoArgs = append(sudoArgs, e.helper)
+	}
+	return e.sudo, append(append(sudoArgs, command), args...)
+}
@@ -0,0 +1,61 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestElevation(t *testing.T) {
+	if e, err := NewElevation(ElevationConfig{}); e != nil || err != nil {
+		t.Fatalf("empty mode should disable elevation, got %v, %v", e, err)
+	}
+	if _, err := NewElevation(ElevationConfig{Mode: "su"}); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+
+	e, err := NewElevation(ElevationConfig{Mode: "sudo"})
+	if err != nil {
+		t.Fatalf("NewElevation() error: %v", err)
+	}
+	tests := []struct {
+		command string
+		args    []string
+		rule    string
+	}{
+		{"systemctl", []string{"restart", "nginx.service"}, "service"},
+		{"/usr/bin/systemctl", []string{"reload", "php8.2-fpm"}, "service"},
+		{"systemctl", []string{"restart", "nginx;reboot"}, ""},
+		{"systemctl", []string{"mask", "nginx"}, ""},
+		{"apt-get", []string{"install", "-y", "curl", "jq=1.6-2"}, "package-install"},
+		{"apt-get", []string{"remove", "-y", "curl"}, ""},
+		// 以 - 开头的参数是选项，不能作为包名或服务名
+		{"apt-get", []string{"install", "-y", "-oDPkg::Pre-Invoke::=reboot", "curl"}, ""},
+		{"apt", []string{"install", "-y", "curl", "-o", "APT::Update::Pre-Invoke::=sh"}, ""},
+		{"dnf", []string{"install", "-y", "--setopt=tsflags=noscripts", "curl"}, ""},
+		{"yum", []string{"install", "-y", "-c", "/tmp/yum.conf", "curl"}, ""},
+		{"apt-get", []string{"install", "-y", "curl -oX"}, ""},
+		{"systemctl", []string{"start", "--root=/tmp"}, ""},
+		{"systemctl", []string{"link", "/tmp/evil.service"}, ""},
+		{"rm", []string{"-rf", "/tmp/x"}, ""},
+	}
+	for _, tt := range tests {
+		rule, err := e.Check(tt.command, tt.args)
+		if rule != tt.rule || (err == nil) != (tt.rule != "") {
+			t.Errorf("Check(%q, %v) = %q, %v; want %q", tt.command, tt.args, rule, err, tt.rule)
+		}
+	}
+
+	name, args := e.Command("systemctl", []string{"restart", "nginx"})
+	if name != "sudo" || !reflect.DeepEqual(args, []string{"-n", "--", "systemctl", "restart", "nginx"}) {
+		t.Errorf("Command() = %q %v", name, args)
+	}
+
+	e, err = NewElevation(ElevationConfig{Mode: "sudo", Helper: "/usr/local/libexec/runixo-elevate"})
+	if err != nil {
+		t.Fatalf("NewElevation() error: %v", err)
+	}
+	name, args = e.Command("systemctl", []string{"restart", "nginx"})
+	if name != "sudo" || !reflect.DeepEqual(args, []string{"-n", "--", "/usr/local/libexec/runixo-elevate", "systemctl", "restart", "nginx"}) {
+		t.Errorf("Command() with helper = %q %v", name, args)
+	}
+}
@@ -0,0 +1,135 @@
+package security
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PolicyRule 执行策略规则
+//
+// Command 匹配命令本身或其文件名，Args 匹配以空格连接的全部参数。
+// 两者默认为通配符（* 匹配任意字符，包括 / 和空格；? 匹配单个字符），
+// 以 "re:" 开头时为正则表达式（需自行加 ^$ 锚定），为空时匹配任意值。
+type PolicyRule struct {
+	Name    string   `mapstructure:"name"`
+	Action  string   `mapstructure:"action"` // allow / deny / sandbox（允许，但必须在沙箱中执行）
+	Command string   `mapstructure:"command"`
+	Args    string   `mapstructure:"args"`
+	Tokens  []string `mapstructure:"tokens"` // 适用的令牌 ID，为空表示所有令牌
+
+	command *regexp.Regexp
+	args    *regexp.Regexp
+}
+
+// PolicyConfig 执行策略配置
+type PolicyConfig struct {
+	// 没有规则匹配时的决定：allow（默认）或 deny
+	Default string       `mapstructure:"default"`
+	Rules   []PolicyRule `mapstructure:"rules"`
+}
+
+// PolicyDecision 策略评估结果
+type PolicyDecision struct {
+	Allowed bool
+	Sandbox bool   // 匹配 sandbox 规则，命令必须在沙箱中执行
+	Rule    string // 决定结果的规则名，使用默认决定时为空
+}
+
+// Policy 命令执行策略
+// 规则按顺序匹配，第一条匹配的规则决定结果。策略在内置白名单之外进一步收紧，
+// allow 规则不会放行白名单之外或危险的命令。
+type Policy struct {
+	defaultAllow bool
+	rules        []PolicyRule
+}
+
+// NewPolicy 校验并编译策略
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	p := &Policy{}
+	switch strings.ToLower(cfg.Default) {
+	case "", "allow":
+		p.defaultAllow = true
+	case "deny":
+	default:
+		return nil, fmt.Errorf("无效的默认策略: %s", cfg.Default)
+	}
+
+	for i, r := range cfg.Rules {
+		r.Action = strings.ToLower(r.Action)
+		if r.Action != "allow" && r.Action != "deny" && r.Action != "sandbox" {
+			return nil, fmt.Errorf("规则 %d: 无效的动作 %q", i+1, r.Action)
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("#%d", i+1)
+		}
+		var err error
+		if r.command, err = compilePattern(r.Command); err != nil {
+			return nil, fmt.Errorf("规则 %s: command: %w", r.Name, err)
+		}
+		if r.args, err = compilePattern(r.Args); err != nil {
+			return nil, fmt.Errorf("规则 %s: args: %w", r.Name, err)
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// compilePattern 编译通配符或 "re:" 正则，空模式返回 nil（匹配任意值）
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if re, ok := strings.CutPrefix(pattern, "re:"); ok {
+		return regexp.Compile(re)
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Evaluate 评估一次命令执行，tokenID 为调用方令牌的 ID（见 auth.TokenID）
+// nil 策略放行所有命令
+func (p *Policy) Evaluate(tokenID, command string, args []string) PolicyDecision {
+	if p == nil {
+		return PolicyDecision{Allowed: true}
+	}
+	joined := strings.Join(args, " ")
+	for _, r := range p.rules {
+		if r.matches(tokenID, command, joined) {
+			return PolicyDecision{Allowed: r.Action != "deny", Sandbox: r.Action == "sandbox", Rule: r.Name}
+		}
+	}
+	return PolicyDecision{Allowed: p.defaultAllow}
+}
+
+func (r *PolicyRule) matches(tokenID, command, args string) bool {
+	if len(r.Tokens) > 0 {
+		found := false
+		for _, t := range r.Tokens {
+			if t == tokenID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.command != nil && !r.command.MatchString(command) && !r.command.MatchString(filepath.Base(command)) {
+		return false
+	}
+	return r.args == nil || r.args.MatchString(args)
+}
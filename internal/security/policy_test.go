@@ -0,0 +1,48 @@
+package security
+
+import "testing"
+
+func TestPolicyEvaluate(t *testing.T) {
+	p, err := NewPolicy(PolicyConfig{
+		Default: "deny",
+		Rules: []PolicyRule{
+			{Name: "no-remove", Action: "deny", Command: "apt*", Args: "*remove*"},
+			{Name: "apt", Action: "allow", Command: "apt-get"},
+			{Name: "nginx", Action: "allow", Command: "systemctl", Args: "re:^(status|restart) nginx$"},
+			{Name: "ops", Action: "allow", Command: "journalctl", Tokens: []string{"ops"}},
+			{Name: "diag", Action: "sandbox", Command: "python3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error: %v", err)
+	}
+
+	tests := []struct {
+		token, command string
+		args           []string
+		allowed        bool
+		sandbox        bool
+		rule           string
+	}{
+		{"", "apt-get", []string{"install", "-y", "curl"}, true, false, "apt"},
+		{"", "/usr/bin/apt-get", []string{"autoremove"}, false, false, "no-remove"},
+		{"", "systemctl", []string{"restart", "nginx"}, true, false, "nginx"},
+		{"", "systemctl", []string{"stop", "nginx"}, false, false, ""},
+		{"ops", "journalctl", []string{"-u", "nginx"}, true, false, "ops"},
+		{"dev", "journalctl", []string{"-u", "nginx"}, false, false, ""},
+		{"", "python3", []string{"check.py"}, true, true, "diag"},
+	}
+	for _, tt := range tests {
+		d := p.Evaluate(tt.token, tt.command, tt.args)
+		if d.Allowed != tt.allowed || d.Sandbox != tt.sandbox || d.Rule != tt.rule {
+			t.Errorf("Evaluate(%q, %q, %v) = %+v, want allowed=%v sandbox=%v rule=%q", tt.token, tt.command, tt.args, d, tt.allowed, tt.sandbox, tt.rule)
+		}
+	}
+
+	if _, err := NewPolicy(PolicyConfig{Rules: []PolicyRule{{Action: "maybe"}}}); err == nil {
+		t.Error("expected error for invalid action")
+	}
+	if _, err := NewPolicy(PolicyConfig{Rules: []PolicyRule{{Action: "deny", Args: "re:("}}}); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
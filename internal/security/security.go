@@ -1,10 +1,11 @@
 package security
 
 import (
-	"fmt"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/runixo/agent/internal/errcode"
 )
 
 // SecurityConfig 安全配置
@@ -99,17 +100,17 @@ func (v *CommandValidator) ValidateCommand(command string, args []string, sudo b
 	// 检查命令长度
 	fullCommand := command + " " + strings.Join(args, " ")
 	if len(fullCommand) > v.config.MaxCommandLength {
-		return fmt.Errorf("命令长度超过限制 (%d > %d)", len(fullCommand), v.config.MaxCommandLength)
+		return errcode.New(errcode.InvalidArgument, "命令长度超过限制 (%d > %d)", len(fullCommand), v.config.MaxCommandLength)
 	}
 
 	// 检查参数数量
 	if len(args) > v.config.MaxArguments {
-		return fmt.Errorf("参数数量超过限制 (%d > %d)", len(args), v.config.MaxArguments)
+		return errcode.New(errcode.InvalidArgument, "参数数量超过限制 (%d > %d)", len(args), v.config.MaxArguments)
 	}
 
 	// 检查 sudo 权限
 	if sudo && !v.config.AllowSudo {
-		return fmt.Errorf("sudo 执行已被禁用")
+		return errcode.New(errcode.CommandNotAllowed, "sudo 执行已被禁用")
 	}
 
 	// sudo 命令白名单检查
@@ -121,7 +122,7 @@ func (v *CommandValidator) ValidateCommand(command string, args []string, sudo b
 			"docker": true, "runixo": true,
 		}
 		if !sudoWhitelist[command] {
-			return fmt.Errorf("命令 '%s' 不允许使用 sudo", command)
+			return errcode.New(errcode.CommandNotAllowed, "命令 '%s' 不允许使用 sudo", command)
 		}
 	}
 
@@ -133,7 +134,7 @@ func (v *CommandValidator) ValidateCommand(command string, args []string, sudo b
 	// 如果启用白名单模式，检查命令是否在白名单中
 	if v.config.EnableCommandWhitelist {
 		if !v.isCommandAllowed(command) {
-			return fmt.Errorf("命令 '%s' 不在允许列表中", command)
+			return errcode.New(errcode.CommandNotAllowed, "命令 '%s' 不在允许列表中", command)
 		}
 	}
 
@@ -146,7 +147,7 @@ func (v *CommandValidator) checkDangerousCommand(fullCommand string) error {
 
 	for _, dangerous := range v.config.DangerousCommands {
 		if strings.Contains(lowerCmd, strings.ToLower(dangerous)) {
-			return fmt.Errorf("检测到危险命令: %s", dangerous)
+			return errcode.New(errcode.CommandNotAllowed, "检测到危险命令: %s", dangerous)
 		}
 	}
 
@@ -164,7 +165,7 @@ func (v *CommandValidator) checkDangerousCommand(fullCommand string) error {
 	for _, dp := range dangerousPatterns {
 		matched, _ := regexp.MatchString(dp.pattern, lowerCmd)
 		if matched {
-			return fmt.Errorf("安全检查失败: %s", dp.desc)
+			return errcode.New(errcode.CommandNotAllowed, "安全检查失败: %s", dp.desc)
 		}
 	}
 
@@ -205,13 +206,13 @@ func (v *PathValidator) ValidatePath(path string) error {
 	// 检查路径遍历攻击
 	if strings.Contains(path, "..") {
 		if strings.Contains(cleanPath, "..") {
-			return fmt.Errorf("检测到路径遍历攻击")
+			return errcode.New(errcode.PathNotAllowed, "检测到路径遍历攻击")
 		}
 	}
 
 	// 检查绝对路径
 	if !filepath.IsAbs(cleanPath) {
-		return fmt.Errorf("必须使用绝对路径")
+		return errcode.New(errcode.InvalidArgument, "必须使用绝对路径")
 	}
 
 	// 解析符号链接，防止通过 symlink 绕过路径检查
@@ -220,7 +221,7 @@ func (v *PathValidator) ValidatePath(path string) error {
 		// 对真实路径也执行禁止路径检查
 		for _, forbidden := range v.config.ForbiddenPaths {
 			if strings.HasPrefix(realPath, forbidden) {
-				return fmt.Errorf("符号链接目标路径被禁止: %s", forbidden)
+				return errcode.New(errcode.PathNotAllowed, "符号链接目标路径被禁止: %s", forbidden)
 			}
 		}
 		cleanPath = realPath
@@ -229,7 +230,7 @@ func (v *PathValidator) ValidatePath(path string) error {
 	// 检查禁止访问的路径
 	for _, forbidden := range v.config.ForbiddenPaths {
 		if strings.HasPrefix(cleanPath, forbidden) {
-			return fmt.Errorf("禁止访问路径: %s", forbidden)
+			return errcode.New(errcode.PathNotAllowed, "禁止访问路径: %s", forbidden)
 		}
 	}
 
@@ -251,7 +252,7 @@ func (v *PathValidator) ValidatePathForWrite(path string) error {
 	cleanPath := filepath.Clean(path)
 	for _, restricted := range writeRestrictedPaths {
 		if strings.HasPrefix(cleanPath, restricted) {
-			return fmt.Errorf("禁止写入系统关键路径: %s", restricted)
+			return errcode.New(errcode.PathNotAllowed, "禁止写入系统关键路径: %s", restricted)
 		}
 	}
 
@@ -263,11 +264,11 @@ func SanitizePath(path string) (string, error) {
 	cleanPath := filepath.Clean(path)
 
 	if !filepath.IsAbs(cleanPath) {
-		return "", fmt.Errorf("必须使用绝对路径")
+		return "", errcode.New(errcode.InvalidArgument, "必须使用绝对路径")
 	}
 
 	if strings.Contains(cleanPath, "..") {
-		return "", fmt.Errorf("路径包含非法字符")
+		return "", errcode.New(errcode.PathNotAllowed, "路径包含非法字符")
 	}
 
 	return cleanPath, nil
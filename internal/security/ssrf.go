@@ -0,0 +1,90 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// CheckURL 检查 URL 是否指向内网/元数据等禁止访问的地址
+func CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errcode.New(errcode.InvalidArgument, "无效的 URL: %v", err)
+	}
+
+	// 只允许 http/https
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errcode.New(errcode.PermissionDenied, "不允许的协议: %s", u.Scheme)
+	}
+
+	host := u.Hostname()
+
+	// 先检查是否直接是 IP 字面量
+	if ip := net.ParseIP(host); ip != nil {
+		return CheckIP(ip)
+	}
+
+	// 解析域名
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return errcode.New(errcode.InvalidArgument, "无法解析域名: %s", host)
+	}
+
+	for _, ip := range ips {
+		if err := CheckIP(ip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckIP 检查单个 IP 是否在禁止列表中
+func CheckIP(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return errcode.New(errcode.PermissionDenied, "禁止访问内网地址: %s", ip)
+	}
+	if ip.Equal(net.ParseIP("169.254.169.254")) {
+		return errcode.New(errcode.PermissionDenied, "禁止访问云元数据服务")
+	}
+	return nil
+}
+
+// maxRedirects 跟随重定向的最大次数，与 net/http 的默认值相同
+const maxRedirects = 10
+
+// Dialer 在建立连接时检查实际连接的 IP，防止 DNS 重绑定或重定向绕过 CheckURL
+func Dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("无效的地址: %s", host)
+			}
+			return CheckIP(ip)
+		},
+	}
+}
+
+// CheckRedirect 用作 http.Client.CheckRedirect：只允许重定向到 http/https，
+// 重定向目标的地址由 Dialer 在连接时检查
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("重定向次数超过 %d 次", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return errcode.New(errcode.PermissionDenied, "不允许重定向到协议: %s", req.URL.Scheme)
+	}
+	return nil
+}
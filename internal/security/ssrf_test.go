@@ -0,0 +1,40 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+func TestDialerRejectsPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport:     &http.Transport{DialContext: Dialer(time.Second).DialContext},
+		CheckRedirect: CheckRedirect,
+	}
+	if _, err := client.Get(srv.URL); errcode.Of(err) != errcode.PermissionDenied {
+		t.Errorf("Get(%s) = %v, want permission denied", srv.URL, err)
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	req := func(raw string) *http.Request {
+		u, _ := url.Parse(raw)
+		return &http.Request{URL: u}
+	}
+	if err := CheckRedirect(req("https://example.com/a"), make([]*http.Request, 1)); err != nil {
+		t.Errorf("https redirect rejected: %v", err)
+	}
+	if err := CheckRedirect(req("file:///etc/passwd"), nil); err == nil {
+		t.Error("expected error for file redirect")
+	}
+	if err := CheckRedirect(req("https://example.com/a"), make([]*http.Request, maxRedirects)); err == nil {
+		t.Error("expected error after too many redirects")
+	}
+}
@@ -0,0 +1,115 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 客户端证书校验模式
+const (
+	ClientAuthNone    = "none"    // 不要求客户端证书（默认）
+	ClientAuthRequest = "request" // 客户端提供证书时校验，未提供时仍允许连接
+	ClientAuthRequire = "require" // 必须提供由 client_ca 签发的证书
+)
+
+// TLSConfig 服务端 TLS 配置
+type TLSConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Cert    string `mapstructure:"cert"`
+	Key     string `mapstructure:"key"`
+	// ClientCA 签发客户端证书的 CA（PEM，可包含多个证书），ClientAuth 不为 none 时必填
+	ClientCA string `mapstructure:"client_ca"`
+	// ClientAuth 客户端证书校验模式：none、request 或 require
+	ClientAuth string `mapstructure:"client_auth"`
+}
+
+// CertReloader 持有服务端证书和客户端 CA，Reload 时重新读取文件。
+// 新的握手使用新证书，已建立的连接不受影响；读取失败时继续使用原来的证书
+type CertReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	clientAuth   tls.ClientAuthType
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewCertReloader 校验配置并加载证书
+func NewCertReloader(cfg TLSConfig) (*CertReloader, error) {
+	r := &CertReloader{certFile: cfg.Cert, keyFile: cfg.Key, clientCAFile: cfg.ClientCA}
+	switch strings.ToLower(cfg.ClientAuth) {
+	case "", ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("无效的客户端证书校验模式: %s", cfg.ClientAuth)
+	}
+	if r.clientAuth != tls.NoClientCert && r.clientCAFile == "" {
+		return nil, fmt.Errorf("client_auth 为 %s 时需要配置 client_ca", cfg.ClientAuth)
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新读取证书、私钥和客户端 CA
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载 TLS 证书失败: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		data, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端 CA 失败: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("客户端 CA 文件 %s 中没有有效的证书", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	return nil
+}
+
+// VerifiesClients 是否校验客户端证书
+func (r *CertReloader) VerifiesClients() bool {
+	return r.clientAuth != tls.NoClientCert
+}
+
+// ServerConfig 使用当前证书的服务端 TLS 配置，verifyClients 时按配置校验客户端证书。
+// 每次握手时读取当前证书和客户端 CA，Reload 后无需重建监听器
+func (r *CertReloader) ServerConfig(verifyClients bool, nextProtos ...string) *tls.Config {
+	base := &tls.Config{MinVersion: tls.VersionTLS12, NextProtos: nextProtos}
+
+	config := base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		c := base.Clone()
+		c.Certificates = []tls.Certificate{*r.cert}
+		if verifyClients {
+			c.ClientAuth = r.clientAuth
+			c.ClientCAs = r.clientCAs
+		}
+		return c, nil
+	}
+	return config
+}
@@ -0,0 +1,124 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert 生成自签名证书并写入 dir/name.pem 和 dir/name-key.pem
+func writeTestCert(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "server")
+	caFile, _ := writeTestCert(t, dir, "client")
+
+	if _, err := NewCertReloader(TLSConfig{Cert: certFile, Key: keyFile, ClientAuth: "optional"}); err == nil {
+		t.Error("expected error for invalid client_auth")
+	}
+	if _, err := NewCertReloader(TLSConfig{Cert: certFile, Key: keyFile, ClientAuth: ClientAuthRequire}); err == nil {
+		t.Error("expected error for client_auth without client_ca")
+	}
+	if _, err := NewCertReloader(TLSConfig{Cert: filepath.Join(dir, "missing.pem"), Key: keyFile}); err == nil {
+		t.Error("expected error for missing certificate")
+	}
+
+	r, err := NewCertReloader(TLSConfig{Cert: certFile, Key: keyFile, ClientCA: caFile, ClientAuth: ClientAuthRequire})
+	if err != nil {
+		t.Fatalf("NewCertReloader() error: %v", err)
+	}
+	if !r.VerifiesClients() {
+		t.Error("VerifiesClients() = false, want true")
+	}
+
+	commonName := func(verifyClients bool) (string, tls.ClientAuthType) {
+		t.Helper()
+		config, err := r.ServerConfig(verifyClients, "h2").GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatalf("GetConfigForClient() error: %v", err)
+		}
+		if len(config.NextProtos) != 1 || config.NextProtos[0] != "h2" {
+			t.Errorf("NextProtos = %v, want [h2]", config.NextProtos)
+		}
+		leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName, config.ClientAuth
+	}
+	if name, auth := commonName(true); name != "server" || auth != tls.RequireAndVerifyClientCert {
+		t.Errorf("got %q, %v; want server, RequireAndVerifyClientCert", name, auth)
+	}
+	if _, auth := commonName(false); auth != tls.NoClientCert {
+		t.Errorf("ClientAuth = %v without client verification, want NoClientCert", auth)
+	}
+
+	// 替换证书后重新加载
+	newCert, newKey := writeTestCert(t, dir, "renewed")
+	for _, f := range [][2]string{{newCert, certFile}, {newKey, keyFile}} {
+		if err := os.Rename(f[0], f[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if name, _ := commonName(true); name != "renewed" {
+		t.Errorf("certificate after reload = %q, want renewed", name)
+	}
+
+	// 加载失败时保留原来的证书
+	if err := os.WriteFile(certFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Error("expected error when reloading an invalid certificate")
+	}
+	if name, _ := commonName(true); name != "renewed" {
+		t.Errorf("certificate after failed reload = %q, want renewed", name)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"time"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+)
+
+const archiveProgressInterval = 500 * time.Millisecond
+
+// archiveProgress 把进度回调转换为流消息，最多每 500ms 发送一次
+// 发送失败说明客户端已断开，流的 ctx 随之取消，打包或解压会中止
+func archiveProgress(send func(*pb.ArchiveProgress) error) func(executor.ArchiveProgress) {
+	var last time.Time
+	return func(p executor.ArchiveProgress) {
+		if time.Since(last) < archiveProgressInterval {
+			return
+		}
+		last = time.Now()
+		send(&pb.ArchiveProgress{
+			Files:      p.Files,
+			Bytes:      p.Bytes,
+			TotalBytes: p.TotalBytes,
+			Current:    p.Current,
+		})
+	}
+}
+
+// CreateArchive 打包文件或目录
+func (s *AgentServer) CreateArchive(req *pb.CreateArchiveRequest, stream pb.AgentService_CreateArchiveServer) error {
+	result, err := executor.CreateArchive(stream.Context(), req.Paths, req.Dest, executor.ArchiveOptions{
+		Format:   req.Format,
+		Progress: archiveProgress(stream.Send),
+	})
+	if err != nil {
+		return errcode.Status(errcode.Of(err), "打包失败: %v", err)
+	}
+	return stream.Send(&pb.ArchiveProgress{
+		Files:      result.Files,
+		Bytes:      result.Bytes,
+		TotalBytes: result.Bytes,
+		Done:       true,
+		Path:       result.Path,
+	})
+}
+
+// ExtractArchive 解压压缩包
+func (s *AgentServer) ExtractArchive(req *pb.ExtractArchiveRequest, stream pb.AgentService_ExtractArchiveServer) error {
+	result, err := executor.ExtractArchive(stream.Context(), req.Archive, req.Dest, executor.ArchiveOptions{
+		Progress: archiveProgress(stream.Send),
+	})
+	if err != nil {
+		return errcode.Status(errcode.Of(err), "解压失败: %v", err)
+	}
+	return stream.Send(&pb.ArchiveProgress{
+		Files:      result.Files,
+		Bytes:      result.Bytes,
+		TotalBytes: result.Bytes,
+		Done:       true,
+		Path:       result.Path,
+	})
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+)
+
+// ExecuteBatch 批量执行命令
+// 执行前逐条评估执行策略，任一命令被拒绝时整个批次都不执行
+func (s *AgentServer) ExecuteBatch(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	if len(req.Commands) == 0 {
+		return nil, errcode.Status(errcode.InvalidArgument, "批量执行的命令为空")
+	}
+	if len(req.Commands) > executor.MaxBatchCommands {
+		return nil, errcode.Status(errcode.InvalidArgument, "批量执行最多 %d 条命令", executor.MaxBatchCommands)
+	}
+
+	cmds := make([]executor.BatchCommand, len(req.Commands))
+	for i, c := range req.Commands {
+		sandbox, err := s.checkPolicy(ctx, c.Command, c.Args)
+		if err != nil {
+			return nil, errcode.Status(errcode.Of(err), "第 %d 条命令: %v", i, err)
+		}
+		timeout := time.Duration(c.TimeoutSeconds) * time.Second
+		if timeout == 0 {
+			timeout = 60 * time.Second
+		}
+		cmds[i] = executor.BatchCommand{
+			Command: c.Command,
+			Args:    c.Args,
+			Options: executor.Options{
+				WorkingDir: c.WorkingDir,
+				Env:        c.Env,
+				Timeout:    timeout,
+				Sudo:       c.Sudo,
+				Stdin:      c.Stdin,
+				Limits:     s.limits,
+				Sandbox:    s.sandboxFor(c.Sandbox, sandbox),
+			},
+		}
+	}
+
+	start := time.Now()
+	results, err := executor.ExecuteBatch(ctx, cmds, executor.BatchOptions{
+		Concurrency: int(req.Concurrency),
+		FailFast:    req.FailFast,
+	})
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "批量执行失败: %v", err)
+	}
+
+	resp := &pb.BatchResponse{Success: true, DurationMs: time.Since(start).Milliseconds()}
+	for i, r := range results {
+		if r.Status != executor.BatchSkipped {
+			s.logExec(ctx, commandRecord(audit.ActionExecBatch, req.Commands[i], cmds[i].Options.Sandbox), r.Result, nil, r.Err)
+		}
+		item := &pb.BatchCommandResult{Index: int32(i), Status: r.Status}
+		if r.Result != nil {
+			item.ExitCode = int32(r.Result.ExitCode)
+			item.Stdout = r.Result.Stdout
+			item.Stderr = r.Result.Stderr
+			item.DurationMs = r.Result.DurationMs
+		}
+		if r.Err != nil {
+			item.Error = r.Err.Error()
+			item.ExitCode = -1
+		}
+		resp.Success = resp.Success && r.Status == executor.BatchSucceeded
+		resp.Results = append(resp.Results, item)
+	}
+	return resp, nil
+}
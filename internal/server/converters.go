@@ -3,6 +3,7 @@
 import (
 pb "github.com/runixo/agent/api/proto"
 "github.com/runixo/agent/internal/collector"
+"github.com/runixo/agent/internal/errcode"
 "github.com/runixo/agent/internal/executor"
 )
 
@@ -19,6 +20,47 @@ KernelVersion:   info.KernelVersion,
 Arch:            info.Arch,
 Uptime:          info.Uptime,
 BootTime:        info.BootTime,
+Units:           convertUnitSummary(info.Units),
+Logins:          convertLoginInfo(info.Logins),
+Labels:          info.Labels,
+}
+if info.Distro != nil {
+result.Distro = &pb.DistroInfo{
+Id:         info.Distro.ID,
+Name:       info.Distro.Name,
+Version:    info.Distro.Version,
+Codename:   info.Distro.Codename,
+PrettyName: info.Distro.PrettyName,
+}
+}
+if info.Packages != nil {
+result.Packages = &pb.PackageInfo{
+Manager:         info.Packages.Manager,
+Installed:       int32(info.Packages.Installed),
+Updates:         int32(info.Packages.Updates),
+SecurityUpdates: int32(info.Packages.SecurityUpdates),
+CheckedAt:       info.Packages.CheckedAt,
+}
+}
+if ip := info.PublicIP; ip != nil {
+result.PublicIp = &pb.PublicIP{
+Ipv4:      ip.IPv4,
+Ipv6:      ip.IPv6,
+Country:   ip.Country,
+Asn:       ip.ASN,
+Org:       ip.Org,
+Source:    ip.Source,
+CheckedAt: ip.CheckedAt,
+}
+}
+if info.Clock != nil {
+result.Clock = &pb.ClockSync{
+Synchronized: info.Clock.Synchronized,
+Source:       info.Clock.Source,
+Server:       info.Clock.Server,
+Stratum:      int32(info.Clock.Stratum),
+Offset:       info.Clock.Offset,
+}
 }
 if info.Cpu != nil {
 result.Cpu = &pb.CpuInfo{
@@ -41,13 +83,17 @@ SwapUsed:    info.Memory.SwapUsed,
 }
 for _, d := range info.Disks {
 result.Disks = append(result.Disks, &pb.DiskInfo{
-Device:      d.Device,
-Mountpoint:  d.Mountpoint,
-Fstype:      d.Fstype,
-Total:       d.Total,
-Used:        d.Used,
-Free:        d.Free,
-UsedPercent: d.UsedPercent,
+Device:            d.Device,
+Mountpoint:        d.Mountpoint,
+Fstype:            d.Fstype,
+Total:             d.Total,
+Used:              d.Used,
+Free:              d.Free,
+UsedPercent:       d.UsedPercent,
+InodesTotal:       d.InodesTotal,
+InodesUsed:        d.InodesUsed,
+InodesFree:        d.InodesFree,
+InodesUsedPercent: d.InodesUsedPercent,
 })
 }
 for _, n := range info.Networks {
@@ -62,16 +108,116 @@ BytesRecv: n.BytesRecv,
 return result
 }
 
+func convertLoginInfo(l *collector.LoginInfo) *pb.LoginInfo {
+if l == nil {
+return nil
+}
+result := &pb.LoginInfo{
+Recent: convertLoginRecords(l.Recent),
+Failed: convertLoginRecords(l.Failed),
+}
+for _, s := range l.Sessions {
+result.Sessions = append(result.Sessions, &pb.LoginSession{
+User:     s.User,
+Terminal: s.Terminal,
+Host:     s.Host,
+Started:  s.Started,
+})
+}
+return result
+}
+
+func convertLoginRecords(records []*collector.LoginRecord) []*pb.LoginRecord {
+var result []*pb.LoginRecord
+for _, r := range records {
+result = append(result, &pb.LoginRecord{
+User:     r.User,
+Terminal: r.Terminal,
+Host:     r.Host,
+Time:     r.Time,
+Success:  r.Success,
+})
+}
+return result
+}
+
+func convertUnitSummary(u *collector.UnitSummary) *pb.UnitSummary {
+if u == nil {
+return nil
+}
+return &pb.UnitSummary{
+Total:       int32(u.Total),
+Active:      int32(u.Active),
+Inactive:    int32(u.Inactive),
+Activating:  int32(u.Activating),
+Failed:      int32(u.Failed),
+FailedUnits: u.FailedUnits,
+}
+}
+
+func convertTopProcesses(processes []*collector.TopProcess) []*pb.TopProcess {
+result := make([]*pb.TopProcess, 0, len(processes))
+for _, p := range processes {
+result = append(result, &pb.TopProcess{Pid: p.Pid, Name: p.Name, User: p.User, Value: p.Value})
+}
+return result
+}
+
 func convertMetrics(m *collector.Metrics) *pb.Metrics {
 if m == nil {
 return nil
 }
 result := &pb.Metrics{
-CpuUsage:    m.CpuUsage,
-MemoryUsage: m.MemoryUsage,
-Load_1:      m.Load1,
-Load_5:      m.Load5,
-Load_15:     m.Load15,
+CpuUsage:     m.CpuUsage,
+MemoryUsage:  m.MemoryUsage,
+Load_1:       m.Load1,
+Load_5:       m.Load5,
+Load_15:      m.Load15,
+Units:        convertUnitSummary(m.Units),
+ProcsRunning: int32(m.ProcsRunning),
+ProcsBlocked: int32(m.ProcsBlocked),
+Uptime:       m.Uptime,
+BootTime:     m.BootTime,
+SwapTotal:    m.SwapTotal,
+SwapUsed:     m.SwapUsed,
+SwapFree:     m.SwapFree,
+SwapUsage:    m.SwapUsage,
+SwapInRate:   m.SwapInRate,
+SwapOutRate:  m.SwapOutRate,
+Zombies:        int32(m.Zombies),
+ConntrackCount: m.ConntrackCount,
+ConntrackMax:   m.ConntrackMax,
+FdAllocated:    m.FdAllocated,
+FdMax:          m.FdMax,
+Labels:         m.Labels,
+}
+if m.Power != nil {
+result.Power = &pb.PowerInfo{AcOnline: m.Power.ACOnline, OnBattery: m.Power.OnBattery}
+for _, b := range m.Power.Batteries {
+result.Power.Batteries = append(result.Power.Batteries, &pb.Battery{Name: b.Name, Percent: b.Percent, Status: b.Status, Remaining: b.Remaining})
+}
+}
+for _, s := range m.Custom {
+result.Custom = append(result.Custom, &pb.CustomSample{Source: s.Source, Name: s.Name, Help: s.Help, Labels: s.Labels, Value: s.Value, Type: s.Type})
+}
+for _, f := range m.FdNearLimit {
+result.FdNearLimit = append(result.FdNearLimit, &pb.FdUsage{Pid: f.Pid, Name: f.Name, Open: f.Open, Limit: f.Limit, Percent: f.Percent})
+}
+for _, p := range m.StuckProcesses {
+result.StuckProcesses = append(result.StuckProcesses, &pb.StuckProcess{
+Pid:      p.Pid,
+Name:     p.Name,
+User:     p.User,
+Cmdline:  p.Cmdline,
+Since:    p.Since,
+Duration: p.Duration,
+})
+}
+if m.Top != nil {
+result.Top = &pb.TopProcesses{
+ByCpu:    convertTopProcesses(m.Top.ByCpu),
+ByMemory: convertTopProcesses(m.Top.ByMemory),
+}
 }
 for _, d := range m.DiskMetrics {
 result.DiskMetrics = append(result.DiskMetrics, &pb.DiskMetric{
@@ -80,6 +226,9 @@ ReadBytes:  d.ReadBytes,
 WriteBytes: d.WriteBytes,
 ReadCount:  d.ReadCount,
 WriteCount: d.WriteCount,
+Await:      d.Await,
+Util:       d.Util,
+InFlight:   d.InFlight,
 })
 }
 for _, n := range m.NetworkMetrics {
@@ -89,6 +238,25 @@ BytesSent:   n.BytesSent,
 BytesRecv:   n.BytesRecv,
 PacketsSent: n.PacketsSent,
 PacketsRecv: n.PacketsRecv,
+ErrIn:       n.ErrIn,
+ErrOut:      n.ErrOut,
+DropIn:      n.DropIn,
+DropOut:     n.DropOut,
+})
+}
+for _, c := range m.Containers {
+result.Containers = append(result.Containers, &pb.ContainerMetric{
+Id:            c.ID,
+Name:          c.Name,
+Image:         c.Image,
+State:         c.State,
+CpuPercent:    c.CpuPercent,
+MemoryUsage:   c.MemoryUsage,
+MemoryLimit:   c.MemoryLimit,
+MemoryPercent: c.MemoryPercent,
+NetworkRx:     c.NetworkRx,
+NetworkTx:     c.NetworkTx,
+RestartCount:  int32(c.RestartCount),
 })
 }
 return result
@@ -133,10 +301,37 @@ Uptime:      s.Uptime,
 return result
 }
 
+func convertNetworkConnections(c *collector.NetworkConnections) *pb.NetworkConnections {
+result := &pb.NetworkConnections{
+StateCounts: make(map[string]int32, len(c.StateCounts)),
+Total:       int32(c.Total),
+}
+for state, n := range c.StateCounts {
+result.StateCounts[state] = int32(n)
+}
+for _, lp := range c.Listening {
+result.Listening = append(result.Listening, &pb.ListeningPort{
+Protocol:    lp.Protocol,
+Address:     lp.Address,
+Port:        lp.Port,
+Pid:         lp.Pid,
+Process:     lp.Process,
+Established: int32(lp.Established),
+})
+}
+return result
+}
+
 func convertProcessList(processes []*collector.ProcessInfo) []*pb.ProcessInfo {
 var result []*pb.ProcessInfo
 for _, p := range processes {
-result = append(result, &pb.ProcessInfo{
+result = append(result, convertProcessInfo(p))
+}
+return result
+}
+
+func convertProcessInfo(p *collector.ProcessInfo) *pb.ProcessInfo {
+return &pb.ProcessInfo{
 Pid:           p.Pid,
 Ppid:          p.Ppid,
 Name:          p.Name,
@@ -147,7 +342,45 @@ MemoryPercent: p.MemoryPercent,
 MemoryRss:     p.MemoryRss,
 CreateTime:    p.CreateTime,
 Cmdline:       p.Cmdline,
+NumThreads:    p.NumThreads,
+NumFds:        p.NumFds,
+FdLimit:       p.FdLimit,
+IoReadBytes:   p.IoReadBytes,
+IoWriteBytes:  p.IoWriteBytes,
+IoReadRate:    p.IoReadRate,
+IoWriteRate:   p.IoWriteRate,
+}
+}
+
+func convertProcessNodes(nodes []*collector.ProcessNode) []*pb.ProcessNode {
+var result []*pb.ProcessNode
+for _, n := range nodes {
+result = append(result, &pb.ProcessNode{
+Process:            convertProcessInfo(n.ProcessInfo),
+Children:           convertProcessNodes(n.Children),
+TotalCpuPercent:    n.TotalCpuPercent,
+TotalMemoryRss:     n.TotalMemoryRss,
+TotalMemoryPercent: n.TotalMemoryPercent,
+Descendants:        int32(n.Descendants),
 })
 }
 return result
 }
+
+// actionError 构造失败的 ActionResponse，错误码取自 err，prefix 非空时作为消息前缀
+func actionError(prefix string, err error) *pb.ActionResponse {
+msg := err.Error()
+if prefix != "" {
+msg = prefix + ": " + msg
+}
+resp := &pb.ActionResponse{Success: false, Error: msg, Code: string(errcode.Of(err))}
+for _, f := range errcode.FieldsOf(err) {
+resp.Violations = append(resp.Violations, &pb.FieldViolation{Field: f.Field, Description: f.Description})
+}
+return resp
+}
+
+// actionFailure 构造指定错误码的失败 ActionResponse
+func actionFailure(code errcode.Code, msg string) *pb.ActionResponse {
+return &pb.ActionResponse{Success: false, Error: msg, Code: string(code)}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/base64"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+)
+
+// encodeFileContent 按请求的编码填充 FileContent 的内容字段和文本检测结果
+func encodeFileContent(resp *pb.FileContent, content []byte, enc pb.ContentEncoding) {
+	resp.Length = int64(len(content))
+	switch enc {
+	case pb.ContentEncoding_CONTENT_BASE64:
+		resp.Text = base64.StdEncoding.EncodeToString(content)
+	case pb.ContentEncoding_CONTENT_UTF8:
+		// 分页读取可能切断多字节字符，切掉的部分留给相邻的页
+		if resp.Offset > 0 || resp.Truncated || resp.Offset+resp.Length < resp.Info.Size {
+			var skip int
+			content, skip = executor.TrimPartialRunes(content)
+			resp.Offset += int64(skip)
+			resp.Length = int64(len(content))
+		}
+		text, info := executor.DecodeText(content)
+		resp.Bom, resp.Newline, resp.Binary = info.BOM, info.Newline, info.Binary
+		if info.Binary {
+			resp.Content = content
+		} else {
+			resp.Text = text
+		}
+		return
+	default:
+		resp.Content = content
+	}
+	info := executor.DetectText(content)
+	resp.Bom, resp.Newline, resp.Binary = info.BOM, info.Newline, info.Binary
+}
+
+// decodeWriteContent 按请求的编码取得要写入的字节
+func decodeWriteContent(req *pb.WriteFileRequest) ([]byte, error) {
+	switch req.Encoding {
+	case pb.ContentEncoding_CONTENT_BASE64:
+		content, err := base64.StdEncoding.DecodeString(req.Text)
+		if err != nil {
+			return nil, errcode.New(errcode.InvalidArgument, "base64 内容无效: %v", err)
+		}
+		return content, nil
+	case pb.ContentEncoding_CONTENT_UTF8:
+		return executor.EncodeText(req.Path, req.Text, executor.TextWriteOptions{
+			Newline: req.Newline,
+			BOM:     req.Bom,
+		})
+	}
+	return req.Content, nil
+}
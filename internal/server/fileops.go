@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/eventbus"
+	"github.com/runixo/agent/internal/executor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetTrash 设置回收站，为 nil 时 DeletePath 不支持 trash 选项
+func (s *AgentServer) SetTrash(t *executor.Trash) {
+	s.trash = t
+}
+
+// CopyPath 复制文件或目录
+func (s *AgentServer) CopyPath(ctx context.Context, req *pb.CopyPathRequest) (*pb.FileOpResult, error) {
+	result, err := executor.CopyPath(ctx, req.Src, req.Dst, executor.CopyOptions{
+		Recursive: req.Recursive,
+		Overwrite: executor.OverwritePolicy(req.Overwrite),
+	})
+	return s.fileOpResponse(ctx, "copy", req.Src+" -> "+req.Dst, result, err)
+}
+
+// MovePath 移动文件或目录
+func (s *AgentServer) MovePath(ctx context.Context, req *pb.MovePathRequest) (*pb.FileOpResult, error) {
+	result, err := executor.MovePath(ctx, req.Src, req.Dst, executor.CopyOptions{
+		Overwrite: executor.OverwritePolicy(req.Overwrite),
+	})
+	return s.fileOpResponse(ctx, "move", req.Src+" -> "+req.Dst, result, err)
+}
+
+// DeletePath 删除文件或目录
+func (s *AgentServer) DeletePath(ctx context.Context, req *pb.DeletePathRequest) (*pb.FileOpResult, error) {
+	opts := executor.DeleteOptions{Recursive: req.Recursive}
+	if req.Trash {
+		if s.trash == nil {
+			return nil, status.Error(codes.FailedPrecondition, "回收站未启用")
+		}
+		opts.Trash = s.trash
+	}
+	result, err := executor.DeletePath(ctx, req.Path, opts)
+	return s.fileOpResponse(ctx, "delete", req.Path, result, err)
+}
+
+// ListTrash 列出回收站条目
+func (s *AgentServer) ListTrash(ctx context.Context, req *pb.Empty) (*pb.TrashList, error) {
+	if s.trash == nil {
+		return nil, status.Error(codes.FailedPrecondition, "回收站未启用")
+	}
+	entries, err := s.trash.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "读取回收站失败: %v", err)
+	}
+	resp := &pb.TrashList{}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &pb.TrashEntry{
+			Id:           e.ID,
+			OriginalPath: e.OriginalPath,
+			DeletedAt:    e.DeletedAt.Unix(),
+			IsDir:        e.IsDir,
+		})
+	}
+	return resp, nil
+}
+
+// RestoreTrash 把回收站条目恢复到原路径
+func (s *AgentServer) RestoreTrash(ctx context.Context, req *pb.RestoreTrashRequest) (*pb.FileOpResult, error) {
+	if s.trash == nil {
+		return nil, status.Error(codes.FailedPrecondition, "回收站未启用")
+	}
+	result, err := s.trash.Restore(ctx, req.Id)
+	return s.fileOpResponse(ctx, "restore", req.Id, result, err)
+}
+
+func (s *AgentServer) fileOpResponse(ctx context.Context, action, target string, result *executor.FileOpResult, err error) (*pb.FileOpResult, error) {
+	if s.audit != nil {
+		s.audit.LogFileOp(clientAddr(ctx), action, target, err == nil)
+	}
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "%s 失败: %v", action, err)
+	}
+	s.publishFileChanged(action, result.Path)
+	return &pb.FileOpResult{
+		Path:    result.Path,
+		Files:   result.Files,
+		Bytes:   result.Bytes,
+		Skipped: result.Skipped,
+		TrashId: result.TrashID,
+	}, nil
+}
+
+// publishFileChanged 发布 file.changed 事件，path 为写入、删除或复制 / 移动的目标路径
+func (s *AgentServer) publishFileChanged(action, path string) {
+	if s.events != nil {
+		s.events.Publish(eventbus.TopicFileChanged, map[string]string{"action": action, "path": path})
+	}
+}
@@ -1,9 +1,7 @@
 package server
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"crypto/subtle"
 	"encoding/json"
@@ -12,19 +10,20 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/auth"
 	"github.com/runixo/agent/internal/collector"
 	"github.com/runixo/agent/internal/emergency"
+	"github.com/runixo/agent/internal/errcode"
 	"github.com/runixo/agent/internal/executor"
 	"github.com/runixo/agent/internal/security"
+	"github.com/runixo/agent/internal/webhook"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -38,6 +37,15 @@ type AgentServer struct {
 	collector    *collector.Collector
 	token        string
 	emergencyMgr *emergency.Manager
+	shell        ShellConfig
+	audit        *audit.Logger
+	limits       executor.Limits
+	policy       *security.Policy
+	scripts      ScriptConfig
+	trash        *executor.Trash
+	maxReadSize  int64
+	sandbox      SandboxConfig
+	events       webhook.Publisher
 }
 
 // NewAgentServer 创建新的 AgentServer
@@ -50,6 +58,81 @@ func NewAgentServer(version string, token string) *AgentServer {
 	}
 }
 
+// SetCollector 替换采集器，便于与 REST API 共享缓存的快照
+func (s *AgentServer) SetCollector(c *collector.Collector) {
+	s.collector = c
+}
+
+// SetExecLimits 设置 ExecuteCommand / ExecuteStream 执行命令时的资源限制
+func (s *AgentServer) SetExecLimits(l executor.Limits) {
+	s.limits = l
+}
+
+// SetMaxReadSize 设置 ReadFile 单次返回的最大字节数，0 使用默认的 50MB
+func (s *AgentServer) SetMaxReadSize(n int64) {
+	s.maxReadSize = n
+}
+
+// SetExecPolicy 设置命令执行策略，nil 表示不启用
+func (s *AgentServer) SetExecPolicy(p *security.Policy) {
+	s.policy = p
+}
+
+// checkPolicy 评估执行策略并把决定写入审计日志，拒绝时返回错误
+// sandbox 为 true 表示匹配的规则要求命令在沙箱中执行
+func (s *AgentServer) checkPolicy(ctx context.Context, command string, args []string) (sandbox bool, err error) {
+	if s.policy == nil {
+		return false, nil
+	}
+	tokenID := auth.TokenIDFromContext(ctx)
+	d := s.policy.Evaluate(tokenID, command, args)
+	if s.audit != nil {
+		s.audit.LogPolicyDecision(clientAddr(ctx), tokenID, command, args, d.Allowed, d.Rule)
+	}
+	if d.Allowed {
+		return d.Sandbox, nil
+	}
+	if d.Rule == "" {
+		return false, errcode.New(errcode.CommandNotAllowed, "执行策略拒绝: 没有匹配的允许规则")
+	}
+	return false, errcode.New(errcode.CommandNotAllowed, "执行策略拒绝: 规则 %s", d.Rule)
+}
+
+// SandboxConfig 沙箱执行配置
+type SandboxConfig struct {
+	executor.Sandbox
+	// Enforce 所有命令和脚本都在沙箱中执行，不论请求是否设置 sandbox
+	Enforce bool
+}
+
+// SetSandboxConfig 设置请求或策略要求沙箱时使用的隔离选项
+func (s *AgentServer) SetSandboxConfig(c SandboxConfig) {
+	s.sandbox = c
+}
+
+// sandboxFor 请求、策略和配置任一要求沙箱时返回沙箱选项，否则返回 nil
+func (s *AgentServer) sandboxFor(requested, required bool) *executor.Sandbox {
+	if !requested && !required && !s.sandbox.Enforce {
+		return nil
+	}
+	sb := s.sandbox.Sandbox
+	return &sb
+}
+
+// clientAddr 返回 gRPC 客户端地址
+func clientAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+// SetEventPublisher 设置事件推送（紧急避险告警、文件变更）
+func (s *AgentServer) SetEventPublisher(p webhook.Publisher) {
+	s.events = p
+	s.emergencyMgr.SetEventPublisher(p)
+}
+
 // Authenticate 认证
 func (s *AgentServer) Authenticate(ctx context.Context, req *pb.AuthRequest) (*pb.AuthResponse, error) {
 	// 使用常量时间比较防止时序攻击
@@ -117,18 +200,27 @@ func (s *AgentServer) ExecuteCommand(ctx context.Context, req *pb.CommandRequest
 	if resp := s.handleEmergencyCommand(req.Command, req.Args); resp != nil {
 		return resp, nil
 	}
+	sandbox, err := s.checkPolicy(ctx, req.Command, req.Args)
+	if err != nil {
+		return &pb.CommandResponse{ExitCode: -1, Stderr: err.Error()}, nil
+	}
 
 	timeout := time.Duration(req.TimeoutSeconds) * time.Second
 	if timeout == 0 {
 		timeout = 60 * time.Second
 	}
 
+	sb := s.sandboxFor(req.Sandbox, sandbox)
 	result, err := executor.Execute(ctx, req.Command, req.Args, executor.Options{
 		WorkingDir: req.WorkingDir,
 		Env:        req.Env,
 		Timeout:    timeout,
 		Sudo:       req.Sudo,
+		Stdin:      req.Stdin,
+		Limits:     s.limits,
+		Sandbox:    sb,
 	})
+	s.logExec(ctx, commandRecord(audit.ActionExecCommand, req, sb), result, nil, err)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "执行命令失败: %v", err)
 	}
@@ -141,89 +233,269 @@ func (s *AgentServer) ExecuteCommand(ctx context.Context, req *pb.CommandRequest
 	}, nil
 }
 
-// ExecuteShell 交互式 Shell（已禁用）
-// 安全修复：交互式 Shell 允许执行任意命令，完全绕过命令白名单
-// 替代方案：使用 SSH 连接服务器，或使用 ExecuteCommand
-func (s *AgentServer) ExecuteShell(stream pb.AgentService_ExecuteShellServer) error {
-	return status.Error(codes.Unimplemented,
-		"交互式 Shell 已禁用。请使用 SSH 连接服务器，或使用命令执行功能。")
+// ExecuteStream 流式执行命令，stdout / stderr 分块发送，最后发送退出状态
+// 客户端断开或取消时命令被终止
+func (s *AgentServer) ExecuteStream(req *pb.CommandRequest, stream pb.AgentService_ExecuteStreamServer) error {
+	if resp := s.handleEmergencyCommand(req.Command, req.Args); resp != nil {
+		return sendCommandResponse(stream, resp)
+	}
+	sandbox, err := s.checkPolicy(stream.Context(), req.Command, req.Args)
+	if err != nil {
+		return sendCommandResponse(stream, &pb.CommandResponse{ExitCode: -1, Stderr: err.Error()})
+	}
+
+	sb := s.sandboxFor(req.Sandbox, sandbox)
+	output := newOutputDigest()
+	result, err := executor.ExecuteStream(stream.Context(), req.Command, req.Args, executor.Options{
+		WorkingDir: req.WorkingDir,
+		Env:        req.Env,
+		Timeout:    time.Duration(req.TimeoutSeconds) * time.Second,
+		Sudo:       req.Sudo,
+		Stdin:      req.Stdin,
+		Limits:     s.limits,
+		Sandbox:    sb,
+	}, func(stderr bool, data []byte) error {
+		output.Write(data)
+		if stderr {
+			return stream.Send(&pb.CommandOutput{Output: &pb.CommandOutput_Stderr{Stderr: data}})
+		}
+		return stream.Send(&pb.CommandOutput{Output: &pb.CommandOutput_Stdout{Stdout: data}})
+	})
+	s.logExec(stream.Context(), commandRecord(audit.ActionExecStream, req, sb), result, output, err)
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return status.Errorf(codes.Internal, "执行命令失败: %v", err)
+	}
+
+	return stream.Send(&pb.CommandOutput{Output: &pb.CommandOutput_Exit{Exit: &pb.CommandExit{
+		ExitCode:   int32(result.ExitCode),
+		DurationMs: result.DurationMs,
+		Error:      result.Stderr,
+	}}})
+}
+
+// sendCommandResponse 以流的形式发送一次性的执行结果
+func sendCommandResponse(stream pb.AgentService_ExecuteStreamServer, resp *pb.CommandResponse) error {
+	if resp.Stdout != "" {
+		if err := stream.Send(&pb.CommandOutput{Output: &pb.CommandOutput_Stdout{Stdout: []byte(resp.Stdout)}}); err != nil {
+			return err
+		}
+	}
+	if resp.Stderr != "" {
+		if err := stream.Send(&pb.CommandOutput{Output: &pb.CommandOutput_Stderr{Stderr: []byte(resp.Stderr)}}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&pb.CommandOutput{Output: &pb.CommandOutput_Exit{Exit: &pb.CommandExit{
+		ExitCode:   resp.ExitCode,
+		DurationMs: resp.DurationMs,
+	}}})
 }
 
 // ReadFile 读取文件
 func (s *AgentServer) ReadFile(ctx context.Context, req *pb.FileRequest) (*pb.FileContent, error) {
-	content, info, err := executor.ReadFile(req.Path)
+	content, result, err := executor.ReadFileRange(req.Path, executor.ReadOptions{
+		Offset:  req.Offset,
+		Length:  req.Length,
+		MaxSize: s.maxReadSize,
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "读取文件失败: %v", err)
+		return nil, errcode.Status(errcode.Of(err), "读取文件失败: %v", err)
 	}
 
-	// 限制 gRPC 消息大小
-	const maxGrpcMessageSize = 50 * 1024 * 1024 // 50MB
-	if len(content) > maxGrpcMessageSize {
-		return nil, status.Errorf(codes.ResourceExhausted, "文件过大，请使用流式下载")
+	resp := &pb.FileContent{
+		Info:      convertFileInfo(result.Info),
+		Offset:    result.Offset,
+		Truncated: result.Truncated,
 	}
-
-	return &pb.FileContent{
-		Content: content,
-		Info:    convertFileInfo(info),
-	}, nil
+	encodeFileContent(resp, content, req.Encoding)
+	return resp, nil
 }
 
 // WriteFile 写入文件
 func (s *AgentServer) WriteFile(ctx context.Context, req *pb.WriteFileRequest) (*pb.ActionResponse, error) {
-	if err := executor.WriteFile(req.Path, req.Content, req.Mode, req.CreateDirs); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+	content, err := decodeWriteContent(req)
+	if err != nil {
+		return actionError("", err), nil
 	}
+	if req.Atomic || req.Backup || len(req.Validate) > 0 {
+		return s.writeFileAtomic(ctx, req, content)
+	}
+	if err := executor.WriteFile(req.Path, content, req.Mode, req.CreateDirs); err != nil {
+		return actionError("", err), nil
+	}
+	s.publishFileChanged("write", req.Path)
 	return &pb.ActionResponse{Success: true, Message: "文件已保存"}, nil
 }
 
-// ListDirectory 列出目录
-func (s *AgentServer) ListDirectory(ctx context.Context, req *pb.DirRequest) (*pb.DirContent, error) {
-	files, err := executor.ListDirectory(req.Path, req.Recursive, req.ShowHidden)
+// writeFileAtomic 原子写入，校验失败时 Error 中带有校验命令的输出
+func (s *AgentServer) writeFileAtomic(ctx context.Context, req *pb.WriteFileRequest, content []byte) (*pb.ActionResponse, error) {
+	var sandbox *executor.Sandbox
+	if len(req.Validate) > 0 {
+		required, err := s.checkPolicy(ctx, req.Validate[0], req.Validate[1:])
+		if err != nil {
+			return actionError("", err), nil
+		}
+		sandbox = s.sandboxFor(false, required)
+	}
+	result, err := executor.WriteFileAtomic(ctx, req.Path, content, executor.AtomicWriteOptions{
+		Mode:       req.Mode,
+		CreateDirs: req.CreateDirs,
+		Backup:     req.Backup,
+		MaxBackups: int(req.MaxBackups),
+		Validate:   req.Validate,
+		Sandbox:    sandbox,
+	})
+	if s.audit != nil {
+		s.audit.LogFileOp(clientAddr(ctx), "write_atomic", req.Path, err == nil)
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "列出目录失败: %v", err)
+		return actionError("", err), nil
 	}
-	return &pb.DirContent{
-		Path:  req.Path,
-		Files: convertFileInfoList(files),
-	}, nil
+	s.publishFileChanged("write", req.Path)
+	msg := "文件已保存"
+	if result.BackupPath != "" {
+		msg += "，备份: " + result.BackupPath
+	}
+	return &pb.ActionResponse{Success: true, Message: msg}, nil
 }
 
-// DeleteFile 删除文件（带安全检查）
-func (s *AgentServer) DeleteFile(ctx context.Context, req *pb.FileRequest) (*pb.ActionResponse, error) {
-	cleanPath, err := security.SanitizePath(req.Path)
+// HashFile 计算文件校验和
+func (s *AgentServer) HashFile(ctx context.Context, req *pb.HashFileRequest) (*pb.FileHash, error) {
+	h, err := executor.HashFile(ctx, req.Path, req.Algorithm)
 	if err != nil {
-		return &pb.ActionResponse{Success: false, Error: "路径安全检查失败: " + err.Error()}, nil
+		return nil, errcode.Status(errcode.Of(err), "计算校验和失败: %v", err)
 	}
+	return &pb.FileHash{Path: h.Path, Algorithm: h.Algorithm, Hash: h.Hash, Size: h.Size}, nil
+}
 
-	if err := pathValidator.ValidatePathForWrite(cleanPath); err != nil {
-		return &pb.ActionResponse{Success: false, Error: "删除路径被拒绝: " + err.Error()}, nil
+// CompareFiles 比较两个文件的内容
+func (s *AgentServer) CompareFiles(ctx context.Context, req *pb.CompareFilesRequest) (*pb.FileComparison, error) {
+	c, err := executor.CompareFiles(ctx, req.PathA, req.PathB)
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "比较文件失败: %v", err)
 	}
+	return &pb.FileComparison{
+		Equal:           c.Equal,
+		SizeA:           c.SizeA,
+		SizeB:           c.SizeB,
+		FirstDifference: c.FirstDifference,
+	}, nil
+}
 
-	realPath, err := filepath.EvalSymlinks(cleanPath)
-	if err == nil && realPath != cleanPath {
-		if err := pathValidator.ValidatePathForWrite(realPath); err != nil {
-			return &pb.ActionResponse{Success: false, Error: "符号链接目标路径被拒绝: " + err.Error()}, nil
-		}
+// SearchFiles 递归搜索文件，超时时返回已找到的结果并标记为未完成
+func (s *AgentServer) SearchFiles(ctx context.Context, req *pb.SearchFilesRequest) (*pb.SearchFilesResponse, error) {
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	searchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts := executor.SearchOptions{
+		Root:       req.Root,
+		Pattern:    req.Pattern,
+		MinSize:    req.MinSize,
+		MaxSize:    req.MaxSize,
+		Content:    req.Content,
+		MaxMatches: int(req.MaxMatches),
+		MaxResults: int(req.MaxResults),
+		MaxDepth:   int(req.MaxDepth),
+		ShowHidden: req.ShowHidden,
+	}
+	if req.ModifiedAfter > 0 {
+		opts.ModifiedAfter = time.Unix(req.ModifiedAfter, 0)
+	}
+	if req.ModifiedBefore > 0 {
+		opts.ModifiedBefore = time.Unix(req.ModifiedBefore, 0)
 	}
 
-	forbiddenPaths := []string{"/", "/bin", "/sbin", "/usr", "/etc", "/var", "/boot", "/root", "/home"}
-	for _, forbidden := range forbiddenPaths {
-		if cleanPath == forbidden {
-			return &pb.ActionResponse{Success: false, Error: "禁止删除系统关键目录"}, nil
+	results, truncated, err := executor.SearchFiles(searchCtx, opts)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		truncated, err = true, nil
+	}
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "搜索文件失败: %v", err)
+	}
+
+	resp := &pb.SearchFilesResponse{Truncated: truncated}
+	for _, r := range results {
+		pr := &pb.SearchResult{File: convertFileInfo(r.File)}
+		for _, m := range r.Matches {
+			pr.Matches = append(pr.Matches, &pb.SearchMatch{Line: int32(m.Line), Text: m.Text})
 		}
+		resp.Results = append(resp.Results, pr)
+	}
+	return resp, nil
+}
+
+// GetDirectorySize 统计目录占用，超时返回已统计的部分结果
+func (s *AgentServer) GetDirectorySize(ctx context.Context, req *pb.DirectorySizeRequest) (*pb.DirectorySizeResponse, error) {
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	duCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	root, truncated, err := executor.GetDirectorySize(duCtx, req.Path, executor.DirSizeOptions{
+		Depth:       int(req.Depth),
+		Top:         int(req.Top),
+		MaxEntries:  req.MaxEntries,
+		CrossMounts: req.CrossMounts,
+	})
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "统计目录占用失败: %v", err)
+	}
+	return &pb.DirectorySizeResponse{Root: convertDirSize(root), Truncated: truncated}, nil
+}
+
+func convertDirSize(d *executor.DirSize) *pb.DirectorySize {
+	pd := &pb.DirectorySize{
+		Path:  d.Path,
+		Size:  d.Size,
+		Disk:  d.Disk,
+		Files: d.Files,
+		Dirs:  d.Dirs,
 	}
+	for _, c := range d.Children {
+		pd.Children = append(pd.Children, convertDirSize(c))
+	}
+	return pd
+}
 
-	if err := os.RemoveAll(cleanPath); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+// ListDirectory 列出目录
+func (s *AgentServer) ListDirectory(ctx context.Context, req *pb.DirRequest) (*pb.DirContent, error) {
+	files, err := executor.ListDirectory(req.Path, req.Recursive, req.ShowHidden)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "列出目录失败: %v", err)
 	}
+	return &pb.DirContent{
+		Path:  req.Path,
+		Files: convertFileInfoList(files),
+	}, nil
+}
+
+// DeleteFile 删除文件（带安全检查）
+func (s *AgentServer) DeleteFile(ctx context.Context, req *pb.FileRequest) (*pb.ActionResponse, error) {
+	if _, err := executor.DeletePath(ctx, req.Path, executor.DeleteOptions{Recursive: true}); err != nil {
+		return actionError("", err), nil
+	}
+	s.publishFileChanged("delete", req.Path)
 	return &pb.ActionResponse{Success: true, Message: "文件已删除"}, nil
 }
 
 // TailLog 日志流
 func (s *AgentServer) TailLog(req *pb.LogRequest, stream pb.AgentService_TailLogServer) error {
-	lineChan, err := executor.TailFile(stream.Context(), req.Path, int(req.Lines), req.Follow)
+	lineChan, err := executor.TailFile(stream.Context(), req.Path, executor.TailOptions{
+		Lines:  int(req.Lines),
+		Follow: req.Follow,
+		Filter: req.Filter,
+	})
 	if err != nil {
-		return status.Errorf(codes.Internal, "读取日志失败: %v", err)
+		return errcode.Status(errcode.Of(err), "读取日志失败: %v", err)
 	}
 
 	for line := range lineChan {
@@ -265,7 +537,7 @@ func (s *AgentServer) ServiceAction(ctx context.Context, req *pb.ServiceActionRe
 	}
 
 	if err := executor.ServiceAction(ctx, req.Name, action); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 	return &pb.ActionResponse{Success: true, Message: "操作成功"}, nil
 }
@@ -279,262 +551,30 @@ func (s *AgentServer) ListProcesses(ctx context.Context, req *pb.ProcessFilter)
 	return &pb.ProcessList{Processes: convertProcessList(processes)}, nil
 }
 
-// KillProcess 终止进程
-func (s *AgentServer) KillProcess(ctx context.Context, req *pb.KillProcessRequest) (*pb.ActionResponse, error) {
-	if err := executor.KillProcess(int(req.Pid), int(req.Signal)); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
-	}
-	return &pb.ActionResponse{Success: true, Message: "进程已终止"}, nil
-}
-
-// UploadFile 流式文件上传
-func (s *AgentServer) UploadFile(stream pb.AgentService_UploadFileServer) error {
-	const maxUploadSize int64 = 1024 * 1024 * 1024 // 1GB 上传大小限制
-
-	var (
-		file       *os.File
-		filePath   string
-		totalSize  int64
-		bytesRecv  int64
-		isTarGz    bool
-		extractTo  string
-		createDirs bool
-	)
-
-	for {
-		chunk, err := stream.Recv()
-		if err == io.EOF {
-			// 上传完成
-			if file != nil {
-				file.Close()
-
-				// 如果是 tar.gz，需要解压
-				if isTarGz && extractTo != "" {
-					log.Info().Str("file", filePath).Str("extract_to", extractTo).Msg("解压文件")
-
-					// 创建解压目录
-					if err := os.MkdirAll(extractTo, 0755); err != nil {
-						os.Remove(filePath)
-						return status.Errorf(codes.Internal, "创建解压目录失败: %v", err)
-					}
-
-					// Zip-slip 防护：解压前验证 tar.gz 内容
-					if err := validateTarGzBeforeExtract(filePath, extractTo); err != nil {
-						os.Remove(filePath)
-						return status.Errorf(codes.InvalidArgument, "解压安全检查失败: %v", err)
-					}
-
-					// 解压
-					cmd := exec.Command("tar", "--no-same-owner", "-xzf", filePath, "-C", extractTo)
-					if output, err := cmd.CombinedOutput(); err != nil {
-						os.Remove(filePath)
-						return status.Errorf(codes.Internal, "解压失败: %v, output: %s", err, string(output))
-					}
-
-					// 二次验证：解压后检查
-					if err := validateExtractedFiles(extractTo); err != nil {
-						os.RemoveAll(extractTo)
-						os.Remove(filePath)
-						return status.Errorf(codes.InvalidArgument, "解压安全检查失败: %v", err)
-					}
-
-					// 删除临时 tar.gz 文件
-					os.Remove(filePath)
-
-					return stream.SendAndClose(&pb.UploadResponse{
-						Success:      true,
-						Message:      "文件夹上传并解压成功",
-						BytesWritten: bytesRecv,
-						Path:         extractTo,
-					})
-				}
-
-				return stream.SendAndClose(&pb.UploadResponse{
-					Success:      true,
-					Message:      "文件上传成功",
-					BytesWritten: bytesRecv,
-					Path:         filePath,
-				})
-			}
-			return stream.SendAndClose(&pb.UploadResponse{
-				Success: false,
-				Error:   "未收到任何数据",
-			})
-		}
-		if err != nil {
-			if file != nil {
-				file.Close()
-				os.Remove(filePath)
-			}
-			return err
-		}
-
-		switch data := chunk.Data.(type) {
-		case *pb.FileChunk_Start:
-			// 开始上传
-			start := data.Start
-			filePath = start.Path
-			totalSize = start.TotalSize
-			isTarGz = start.IsTarGz
-			extractTo = start.ExtractTo
-			createDirs = start.CreateDirs
-
-			log.Info().
-				Str("path", filePath).
-				Int64("size", totalSize).
-				Bool("is_tar_gz", isTarGz).
-				Str("extract_to", extractTo).
-				Msg("开始接收文件")
-
-			// 大小限制检查
-			if totalSize > maxUploadSize {
-				return status.Errorf(codes.InvalidArgument, "文件过大，超过 1GB 限制 (size: %d)", totalSize)
-			}
-
-			// 安全检查
-			cleanPath, err := security.SanitizePath(filePath)
-			if err != nil {
-				return status.Errorf(codes.InvalidArgument, "路径安全检查失败: %v", err)
-			}
-			filePath = cleanPath
-
-			if err := pathValidator.ValidatePathForWrite(filePath); err != nil {
-				return status.Errorf(codes.PermissionDenied, "写入路径被拒绝: %v", err)
-			}
-
-			// 安全检查：验证 extractTo 路径
-			if isTarGz && extractTo != "" {
-				cleanExtractTo, err := security.SanitizePath(extractTo)
-				if err != nil {
-					return status.Errorf(codes.InvalidArgument, "解压路径安全检查失败: %v", err)
-				}
-				extractTo = cleanExtractTo
-
-				if err := pathValidator.ValidatePathForWrite(extractTo); err != nil {
-					return status.Errorf(codes.PermissionDenied, "解压路径被拒绝: %v", err)
-				}
-			}
-
-			// 创建父目录
-			if createDirs {
-				dir := filepath.Dir(filePath)
-				if err := os.MkdirAll(dir, 0755); err != nil {
-					return status.Errorf(codes.Internal, "创建目录失败: %v", err)
-				}
-			}
-
-			// 创建文件
-			file, err = os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(start.Mode))
-			if err != nil {
-				return status.Errorf(codes.Internal, "创建文件失败: %v", err)
-			}
-
-		case *pb.FileChunk_Chunk:
-			// 写入数据块
-			if file == nil {
-				return status.Error(codes.FailedPrecondition, "未收到开始消息")
-			}
-
-			n, err := file.Write(data.Chunk)
-			if err != nil {
-				file.Close()
-				os.Remove(filePath)
-				return status.Errorf(codes.Internal, "写入文件失败: %v", err)
-			}
-			bytesRecv += int64(n)
-
-			// 运行时大小检查（防止 totalSize 被伪造）
-			if bytesRecv > maxUploadSize {
-				file.Close()
-				os.Remove(filePath)
-				return status.Errorf(codes.ResourceExhausted, "上传数据超过 1GB 限制")
-			}
-
-			// 每 10MB 记录一次进度
-			if bytesRecv%(10*1024*1024) < int64(len(data.Chunk)) {
-				log.Debug().
-					Int64("received", bytesRecv).
-					Int64("total", totalSize).
-					Float64("percent", func() float64 { if totalSize > 0 { return float64(bytesRecv)/float64(totalSize)*100 }; return 0 }()).
-					Msg("上传进度")
-			}
-
-		case *pb.FileChunk_End:
-			// 上传结束（可选的校验）
-			log.Info().Int64("bytes", bytesRecv).Msg("文件接收完成")
-		}
-	}
-}
-
-// DownloadFile 流式文件下载
-func (s *AgentServer) DownloadFile(req *pb.FileRequest, stream pb.AgentService_DownloadFileServer) error {
-	// 安全检查
-	cleanPath, err := security.SanitizePath(req.Path)
+// GetProcessTree 获取进程树
+func (s *AgentServer) GetProcessTree(ctx context.Context, req *pb.ProcessTreeRequest) (*pb.ProcessTree, error) {
+	roots, err := s.collector.GetProcessTree(req.Aggregate)
 	if err != nil {
-		return status.Errorf(codes.InvalidArgument, "路径安全检查失败: %v", err)
+		return nil, status.Errorf(codes.Internal, "获取进程树失败: %v", err)
 	}
+	return &pb.ProcessTree{Roots: convertProcessNodes(roots)}, nil
+}
 
-	// 安全检查：验证路径访问权限
-	if err := pathValidator.ValidatePath(cleanPath); err != nil {
-		return status.Errorf(codes.PermissionDenied, "路径访问被拒绝: %v", err)
-	}
-
-	// 打开文件
-	file, err := os.Open(cleanPath)
-	if err != nil {
-		return status.Errorf(codes.NotFound, "打开文件失败: %v", err)
-	}
-	defer file.Close()
-
-	// 获取文件信息
-	info, err := file.Stat()
+// GetNetworkConnections 获取监听端口和连接状态
+func (s *AgentServer) GetNetworkConnections(ctx context.Context, req *pb.Empty) (*pb.NetworkConnections, error) {
+	conns, err := s.collector.GetNetworkConnections()
 	if err != nil {
-		return status.Errorf(codes.Internal, "获取文件信息失败: %v", err)
-	}
-
-	if info.IsDir() {
-		return status.Error(codes.InvalidArgument, "不能下载目录，请先打包")
+		return nil, status.Errorf(codes.Internal, "获取网络连接失败: %v", err)
 	}
+	return convertNetworkConnections(conns), nil
+}
 
-	// 发送开始消息
-	if err := stream.Send(&pb.FileChunk{
-		Data: &pb.FileChunk_Start{
-			Start: &pb.FileUploadStart{
-				Path:      cleanPath,
-				TotalSize: info.Size(),
-				Mode:      int64(info.Mode()),
-			},
-		},
-	}); err != nil {
-		return err
-	}
-
-	// 分块发送文件内容
-	buf := make([]byte, 64*1024) // 64KB chunks
-	for {
-		n, err := file.Read(buf)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return status.Errorf(codes.Internal, "读取文件失败: %v", err)
-		}
-
-		if err := stream.Send(&pb.FileChunk{
-			Data: &pb.FileChunk_Chunk{
-				Chunk: buf[:n],
-			},
-		}); err != nil {
-			return err
-		}
+// KillProcess 终止进程
+func (s *AgentServer) KillProcess(ctx context.Context, req *pb.KillProcessRequest) (*pb.ActionResponse, error) {
+	if err := executor.KillProcess(int(req.Pid), int(req.Signal)); err != nil {
+		return actionError("", err), nil
 	}
-
-	// 发送结束消息
-	return stream.Send(&pb.FileChunk{
-		Data: &pb.FileChunk_End{
-			End: &pb.FileUploadEnd{},
-		},
-	})
+	return &pb.ActionResponse{Success: true, Message: "进程已终止"}, nil
 }
 
 // SearchDockerHub 搜索 Docker Hub 镜像（服务端代理）
@@ -624,54 +664,6 @@ func (s *AgentServer) SearchDockerHub(ctx context.Context, req *pb.DockerSearchR
 	}, nil
 }
 
-// isBlockedURL 检查 URL 是否指向内网/元数据等禁止访问的地址
-func isBlockedURL(rawURL string) error {
-	u, err := url.Parse(rawURL)
-	if err != nil {
-		return fmt.Errorf("无效的 URL: %v", err)
-	}
-
-	// 只允许 http/https
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return fmt.Errorf("不允许的协议: %s", u.Scheme)
-	}
-
-	host := u.Hostname()
-
-	// 先检查是否直接是 IP 字面量
-	if ip := net.ParseIP(host); ip != nil {
-		if err := checkBlockedIP(ip); err != nil {
-			return err
-		}
-		return nil
-	}
-
-	// 解析域名
-	ips, err := net.LookupIP(host)
-	if err != nil {
-		return fmt.Errorf("无法解析域名: %s", host)
-	}
-
-	for _, ip := range ips {
-		if err := checkBlockedIP(ip); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// checkBlockedIP 检查单个 IP 是否在禁止列表中
-func checkBlockedIP(ip net.IP) error {
-	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
-		return fmt.Errorf("禁止访问内网地址: %s", ip)
-	}
-	if ip.Equal(net.ParseIP("169.254.169.254")) {
-		return fmt.Errorf("禁止访问云元数据服务")
-	}
-	return nil
-}
-
 // ProxyHttpRequest 代理 HTTP 请求
 func (s *AgentServer) ProxyHttpRequest(ctx context.Context, req *pb.HttpProxyRequest) (*pb.HttpProxyResponse, error) {
 	if req.Url == "" {
@@ -682,7 +674,7 @@ func (s *AgentServer) ProxyHttpRequest(ctx context.Context, req *pb.HttpProxyReq
 	}
 
 	// 安全检查：禁止访问内网/元数据地址
-	if err := isBlockedURL(req.Url); err != nil {
+	if err := security.CheckURL(req.Url); err != nil {
 		return &pb.HttpProxyResponse{
 			Success: false,
 			Error:   "URL 安全检查失败: " + err.Error(),
@@ -739,12 +731,12 @@ func (s *AgentServer) ProxyHttpRequest(ctx context.Context, req *pb.HttpProxyReq
 						return nil, fmt.Errorf("DNS 解析失败: %v", err)
 					}
 					for _, ip := range ips {
-						if err := checkBlockedIP(ip); err != nil {
+						if err := security.CheckIP(ip); err != nil {
 							return nil, fmt.Errorf("DNS 重绑定检测: %v", err)
 						}
 					}
 				} else {
-					if err := checkBlockedIP(ip); err != nil {
+					if err := security.CheckIP(ip); err != nil {
 						return nil, err
 					}
 				}
@@ -878,77 +870,3 @@ func (s *AgentServer) DownloadCertificate(ctx context.Context, req *pb.Empty) (*
 	}, nil
 }
 */
-
-// validateExtractedFiles 验证解压后的文件都在目标目录内（防止 zip-slip）
-func validateExtractedFiles(extractTo string) error {
-	absExtractTo, err := filepath.Abs(extractTo)
-	if err != nil {
-		return fmt.Errorf("无法解析目标目录: %v", err)
-	}
-	return filepath.Walk(absExtractTo, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		realPath, err := filepath.EvalSymlinks(path)
-		if err != nil {
-			return nil
-		}
-		if !strings.HasPrefix(realPath, absExtractTo) {
-			return fmt.Errorf("检测到路径遍历: %s 指向 %s", path, realPath)
-		}
-		return nil
-	})
-}
-
-// validateTarGzBeforeExtract 在解压前验证 tar.gz 文件内容（Zip-Slip 防护）
-func validateTarGzBeforeExtract(tarGzPath, extractTo string) error {
-	absExtractTo, err := filepath.Abs(extractTo)
-	if err != nil {
-		return fmt.Errorf("无法解析目标目录: %v", err)
-	}
-
-	file, err := os.Open(tarGzPath)
-	if err != nil {
-		return fmt.Errorf("打开文件失败: %v", err)
-	}
-	defer file.Close()
-
-	gzr, err := gzip.NewReader(file)
-	if err != nil {
-		return fmt.Errorf("gzip 解析失败: %v", err)
-	}
-	defer gzr.Close()
-
-	tr := tar.NewReader(gzr)
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("读取 tar 失败: %v", err)
-		}
-
-		cleanName := filepath.Clean(header.Name)
-		if strings.Contains(cleanName, "..") {
-			return fmt.Errorf("检测到路径遍历: %s", header.Name)
-		}
-
-		targetPath := filepath.Join(absExtractTo, cleanName)
-		if !strings.HasPrefix(targetPath, absExtractTo+string(filepath.Separator)) && targetPath != absExtractTo {
-			return fmt.Errorf("检测到路径遍历: %s -> %s", header.Name, targetPath)
-		}
-
-		if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
-			linkTarget := filepath.Clean(header.Linkname)
-			if filepath.IsAbs(linkTarget) {
-				return fmt.Errorf("检测到绝对路径符号链接: %s -> %s", header.Name, linkTarget)
-			}
-			resolvedLink := filepath.Join(filepath.Dir(targetPath), linkTarget)
-			if !strings.HasPrefix(resolvedLink, absExtractTo+string(filepath.Separator)) && resolvedLink != absExtractTo {
-				return fmt.Errorf("检测到符号链接路径遍历: %s -> %s", header.Name, linkTarget)
-			}
-		}
-	}
-	return nil
-}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"time"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/auth"
+	"github.com/runixo/agent/internal/executor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// outputDigest 累计命令输出的 SHA-256 和长度，流式执行时不必缓存输出
+type outputDigest struct {
+	h hash.Hash
+	n int64
+}
+
+func newOutputDigest() *outputDigest {
+	return &outputDigest{h: sha256.New()}
+}
+
+func (d *outputDigest) Write(p []byte) {
+	d.h.Write(p)
+	d.n += int64(len(p))
+}
+
+func (d *outputDigest) WriteString(p string) {
+	d.Write([]byte(p))
+}
+
+// logExec 补全客户端地址、令牌、退出状态和输出摘要后写入执行审计记录
+// output 为 nil 时按 result 中缓存的 stdout 和 stderr 计算摘要
+func (s *AgentServer) logExec(ctx context.Context, rec audit.ExecRecord, result *executor.Result, output *outputDigest, err error) {
+	if s.audit == nil {
+		return
+	}
+	rec.ClientIP = clientAddr(ctx)
+	rec.TokenID = auth.TokenIDFromContext(ctx)
+	if result != nil {
+		rec.ExitCode = result.ExitCode
+		rec.DurationMs = result.DurationMs
+		if output == nil {
+			output = newOutputDigest()
+			output.WriteString(result.Stdout)
+			output.WriteString(result.Stderr)
+		}
+	}
+	if output != nil {
+		rec.OutputSHA256 = hex.EncodeToString(output.h.Sum(nil))
+		rec.OutputSize = output.n
+	}
+	if err != nil {
+		rec.Error = err.Error()
+		if rec.ExitCode == 0 {
+			rec.ExitCode = -1
+		}
+	}
+	s.audit.LogExec(rec)
+}
+
+// commandRecord 由命令请求构造执行审计记录
+func commandRecord(action string, req *pb.CommandRequest, sandbox *executor.Sandbox) audit.ExecRecord {
+	return audit.ExecRecord{
+		Action:     action,
+		Command:    req.Command,
+		Args:       req.Args,
+		WorkingDir: req.WorkingDir,
+		Sandbox:    sandbox != nil,
+	}
+}
+
+// GetExecHistory 查询审计日志中的命令执行记录
+func (s *AgentServer) GetExecHistory(ctx context.Context, req *pb.ExecHistoryRequest) (*pb.ExecHistory, error) {
+	if s.audit == nil {
+		return nil, status.Error(codes.FailedPrecondition, "审计日志未启用")
+	}
+	q := audit.ExecQuery{
+		TokenID: req.TokenId,
+		Command: req.Command,
+		Limit:   int(req.Limit),
+	}
+	if req.Since > 0 {
+		q.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until > 0 {
+		q.Until = time.Unix(req.Until, 0)
+	}
+	records, truncated, err := s.audit.ExecHistory(q)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "读取审计日志失败: %v", err)
+	}
+
+	resp := &pb.ExecHistory{Truncated: truncated}
+	for _, r := range records {
+		resp.Records = append(resp.Records, &pb.ExecRecord{
+			Timestamp:    r.Timestamp.UnixMilli(),
+			ClientIp:     r.ClientIP,
+			TokenId:      r.TokenID,
+			Action:       r.Action,
+			Command:      r.Command,
+			Args:         r.Args,
+			WorkingDir:   r.WorkingDir,
+			ExitCode:     int32(r.ExitCode),
+			DurationMs:   r.DurationMs,
+			OutputSha256: r.OutputSHA256,
+			OutputSize:   r.OutputSize,
+			ScriptSha256: r.ScriptSHA256,
+			Sandbox:      r.Sandbox,
+			Error:        r.Error,
+		})
+	}
+	return resp, nil
+}
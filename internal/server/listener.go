@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ListenerConfig gRPC 监听地址
+type ListenerConfig struct {
+	// tcp 或 unix，默认 tcp
+	Network string `mapstructure:"network"`
+	// tcp 为 host:port，unix 为套接字文件路径
+	Address string `mapstructure:"address"`
+	// unix 套接字文件的权限（八进制），默认 0600
+	Mode string `mapstructure:"mode"`
+	// unix 套接字文件的属组（组名或 GID），为空时不修改
+	Group string `mapstructure:"group"`
+}
+
+// defaultSocketMode unix 套接字文件的默认权限，只允许 Agent 所属用户访问
+const defaultSocketMode = 0600
+
+// Listen 按配置创建监听器。unix 套接字文件已存在时会先删除（只删除套接字，不删除普通文件）
+func Listen(config ListenerConfig) (net.Listener, error) {
+	switch config.Network {
+	case "", "tcp":
+		if config.Mode != "" || config.Group != "" {
+			return nil, fmt.Errorf("tcp 监听地址 %s 不支持 mode 和 group", config.Address)
+		}
+		return net.Listen("tcp", config.Address)
+	case "unix":
+		return listenUnix(config)
+	default:
+		return nil, fmt.Errorf("不支持的监听类型 %q，可选 tcp、unix", config.Network)
+	}
+}
+
+// listenUnix 监听 unix 套接字并设置文件权限和属组。
+// 套接字设置好权限后才出现在配置的路径上，避免创建后短暂地可被其他用户连接
+func listenUnix(config ListenerConfig) (net.Listener, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("unix 监听需要指定套接字路径")
+	}
+	mode := os.FileMode(defaultSocketMode)
+	if config.Mode != "" {
+		m, err := strconv.ParseUint(config.Mode, 8, 32)
+		if err != nil || m > 0777 {
+			return nil, fmt.Errorf("无效的套接字权限 %q", config.Mode)
+		}
+		mode = os.FileMode(m)
+	}
+	gid := -1
+	if config.Group != "" {
+		id, err := lookupGroupID(config.Group)
+		if err != nil {
+			return nil, err
+		}
+		gid = id
+	}
+
+	// 清理上次未正常退出时留下的套接字文件
+	if info, err := os.Lstat(config.Address); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s 已存在且不是套接字文件", config.Address)
+		}
+		if err := os.Remove(config.Address); err != nil {
+			return nil, fmt.Errorf("删除旧的套接字文件失败: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(config.Address), 0755); err != nil {
+		return nil, fmt.Errorf("创建套接字目录失败: %w", err)
+	}
+
+	return listenUnixSocket(config.Address, mode, gid)
+}
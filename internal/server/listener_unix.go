@@ -0,0 +1,74 @@
+//go:build !windows
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// unixListener 移动到目标路径的套接字，关闭时删除目标路径的套接字文件
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l *unixListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: l.path, Net: "unix"}
+}
+
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// listenUnixSocket 在同目录下只有 Agent 所属用户可访问的临时目录中创建套接字，
+// 设置权限和属组后再移动到 path，其他用户在权限设置好之前无法连接。不修改进程的 umask
+func listenUnixSocket(path string, mode os.FileMode, gid int) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock-")
+	if err != nil {
+		return nil, fmt.Errorf("创建套接字临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// 套接字文件移动后由 unixListener 删除
+	listener.SetUnlinkOnClose(false)
+
+	if gid >= 0 {
+		if err := os.Chown(tmp, -1, gid); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("设置套接字属组失败: %w", err)
+		}
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("设置套接字权限失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("移动套接字文件失败: %w", err)
+	}
+	return &unixListener{UnixListener: listener, path: path}, nil
+}
+
+// lookupGroupID 解析组名或 GID
+func lookupGroupID(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("查找用户组 %s 失败: %w", group, err)
+	}
+	return strconv.Atoi(g.Gid)
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// listenUnixSocket Windows 不支持属组，套接字文件的访问由所在目录的 ACL 控制
+func listenUnixSocket(path string, mode os.FileMode, gid int) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("设置套接字权限失败: %w", err)
+	}
+	return listener, nil
+}
+
+// lookupGroupID Windows 不支持设置套接字文件的属组
+func lookupGroupID(group string) (int, error) {
+	return 0, fmt.Errorf("Windows 不支持设置套接字属组 %s", group)
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+)
+
+// Chmod 修改文件模式
+func (s *AgentServer) Chmod(ctx context.Context, req *pb.ChmodRequest) (*pb.PermissionResult, error) {
+	opts := executor.PermissionOptions{Recursive: req.Recursive, DryRun: req.DryRun}
+	result, err := executor.Chmod(ctx, req.Path, req.Mode, opts)
+	return s.permissionResponse(ctx, "chmod", req.Path, opts, result, err)
+}
+
+// Chown 修改属主
+func (s *AgentServer) Chown(ctx context.Context, req *pb.ChownRequest) (*pb.PermissionResult, error) {
+	opts := executor.PermissionOptions{Recursive: req.Recursive, DryRun: req.DryRun}
+	result, err := executor.Chown(ctx, req.Path, req.Owner, opts)
+	return s.permissionResponse(ctx, "chown", req.Path, opts, result, err)
+}
+
+// SetACL 修改 POSIX ACL
+func (s *AgentServer) SetACL(ctx context.Context, req *pb.SetACLRequest) (*pb.PermissionResult, error) {
+	opts := executor.PermissionOptions{Recursive: req.Recursive, DryRun: req.DryRun}
+	result, err := executor.SetACL(ctx, req.Path, req.Entries, req.Remove, opts)
+	return s.permissionResponse(ctx, "setfacl", req.Path, opts, result, err)
+}
+
+// permissionResponse 记录审计日志（dry_run 除外）并转换结果
+func (s *AgentServer) permissionResponse(ctx context.Context, action, path string, opts executor.PermissionOptions, result *executor.PermissionResult, err error) (*pb.PermissionResult, error) {
+	if s.audit != nil && !opts.DryRun {
+		s.audit.LogFileOp(clientAddr(ctx), action, path, err == nil && len(result.Errors) == 0)
+	}
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "%s 失败: %v", action, err)
+	}
+
+	resp := &pb.PermissionResult{
+		Changed: int32(result.Changed),
+		Errors:  result.Errors,
+		DryRun:  result.DryRun,
+	}
+	for _, c := range result.Changes {
+		resp.Changes = append(resp.Changes, &pb.PermissionChange{Path: c.Path, Old: c.Old, New: c.New})
+	}
+	return resp, nil
+}
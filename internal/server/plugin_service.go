@@ -3,10 +3,15 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"regexp"
+	"time"
 
 	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
 	"github.com/runixo/agent/internal/plugin"
+	"github.com/runixo/agent/internal/security"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -41,41 +46,99 @@ func (s *PluginServer) ListPlugins(ctx context.Context, req *pb.Empty) (*pb.Plug
 // InstallPlugin 安装插件
 func (s *PluginServer) InstallPlugin(ctx context.Context, req *pb.InstallPluginRequest) (*pb.ActionResponse, error) {
 	if req.PluginId == "" {
-		return &pb.ActionResponse{Success: false, Error: "插件 ID 不能为空"}, nil
+		return actionFailure(errcode.InvalidArgument, "插件 ID 不能为空"), nil
 	}
 
 	// 验证插件 ID 格式（只允许字母数字和连字符）
 	if !validPluginID.MatchString(req.PluginId) {
-		return &pb.ActionResponse{Success: false, Error: "插件 ID 格式无效，只允许字母、数字、下划线和连字符"}, nil
+		return actionFailure(errcode.InvalidArgument, "插件 ID 格式无效，只允许字母、数字、下划线和连字符"), nil
 	}
 
 	// SSRF 防护：验证 URL 不指向内网
 	if req.Url != "" {
-		if err := isBlockedURL(req.Url); err != nil {
-			return &pb.ActionResponse{Success: false, Error: "插件 URL 安全检查失败: " + err.Error()}, nil
+		if err := security.CheckURL(req.Url); err != nil {
+			return actionError("插件 URL 安全检查失败", err), nil
 		}
 	}
 
-	source := req.Source
-	if source == "" {
-		source = "official"
+	err := s.manager.InstallPlugin(req.PluginId, plugin.InstallRequest{
+		Source:    req.Source,
+		URL:       req.Url,
+		Data:      req.Data,
+		Signature: []byte(req.Signature),
+		SHA256:    req.Sha256,
+	})
+	if err != nil {
+		return actionError("", err), nil
 	}
 
-	if err := s.manager.InstallPlugin(req.PluginId, source, req.Url, req.Data); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+	return &pb.ActionResponse{Success: true, Message: "插件安装成功"}, nil
+}
+
+// UploadPlugin 分块接收插件包并安装，插件包边接收边写入临时文件
+func (s *PluginServer) UploadPlugin(stream pb.PluginService_UploadPluginServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.FailedPrecondition, "未收到开始消息")
+	}
+	if !validPluginID.MatchString(start.PluginId) {
+		return stream.SendAndClose(actionFailure(errcode.InvalidArgument, "插件 ID 格式无效，只允许字母、数字、下划线和连字符"))
 	}
 
-	return &pb.ActionResponse{Success: true, Message: "插件安装成功"}, nil
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := s.manager.InstallPlugin(start.PluginId, plugin.InstallRequest{
+			Source:    plugin.SourceLocal,
+			Package:   pr,
+			Signature: []byte(start.Signature),
+			SHA256:    start.Sha256,
+		})
+		// 安装提前失败时让后续写入返回错误
+		pr.CloseWithError(io.ErrClosedPipe)
+		done <- err
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			pw.Close()
+			break
+		}
+		if err != nil {
+			pw.CloseWithError(err)
+			<-done
+			return err
+		}
+		chunk, ok := msg.Data.(*pb.PluginUpload_Chunk)
+		if !ok {
+			pw.CloseWithError(errors.New("重复的开始消息"))
+			<-done
+			return status.Error(codes.FailedPrecondition, "重复的开始消息")
+		}
+		if _, err := pw.Write(chunk.Chunk); err != nil {
+			break
+		}
+	}
+
+	if err := <-done; err != nil {
+		return stream.SendAndClose(actionError("", err))
+	}
+	return stream.SendAndClose(&pb.ActionResponse{Success: true, Message: "插件安装成功"})
 }
 
 // UninstallPlugin 卸载插件
 func (s *PluginServer) UninstallPlugin(ctx context.Context, req *pb.PluginRequest) (*pb.ActionResponse, error) {
 	if req.PluginId == "" {
-		return &pb.ActionResponse{Success: false, Error: "插件 ID 不能为空"}, nil
+		return actionFailure(errcode.InvalidArgument, "插件 ID 不能为空"), nil
 	}
 
 	if err := s.manager.UninstallPlugin(req.PluginId); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 
 	return &pb.ActionResponse{Success: true, Message: "插件已卸载"}, nil
@@ -84,24 +147,54 @@ func (s *PluginServer) UninstallPlugin(ctx context.Context, req *pb.PluginReques
 // EnablePlugin 启用插件
 func (s *PluginServer) EnablePlugin(ctx context.Context, req *pb.PluginRequest) (*pb.ActionResponse, error) {
 	if req.PluginId == "" {
-		return &pb.ActionResponse{Success: false, Error: "插件 ID 不能为空"}, nil
+		return actionFailure(errcode.InvalidArgument, "插件 ID 不能为空"), nil
 	}
 
 	if err := s.manager.EnablePlugin(req.PluginId); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 
 	return &pb.ActionResponse{Success: true, Message: "插件已启用"}, nil
 }
 
+// VerifyPlugin 试运行插件而不启用
+func (s *PluginServer) VerifyPlugin(ctx context.Context, req *pb.PluginRequest) (*pb.PluginVerifyResult, error) {
+	if req.PluginId == "" {
+		return nil, status.Error(codes.InvalidArgument, "插件 ID 不能为空")
+	}
+
+	result, err := s.manager.VerifyPlugin(req.PluginId)
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "试运行插件失败: %v", err)
+	}
+
+	resp := &pb.PluginVerifyResult{PluginId: result.PluginID, Ok: result.OK}
+	for _, step := range result.Steps {
+		resp.Steps = append(resp.Steps, &pb.PluginVerifyStep{
+			Name:       step.Name,
+			Status:     step.Status,
+			Message:    step.Message,
+			DurationMs: step.DurationMs,
+		})
+	}
+	for _, d := range result.Denials {
+		resp.Denials = append(resp.Denials, &pb.PluginPermissionDenial{
+			Permission: d.Permission,
+			Resource:   d.Resource,
+			Time:       d.Time.Unix(),
+		})
+	}
+	return resp, nil
+}
+
 // DisablePlugin 禁用插件
 func (s *PluginServer) DisablePlugin(ctx context.Context, req *pb.PluginRequest) (*pb.ActionResponse, error) {
 	if req.PluginId == "" {
-		return &pb.ActionResponse{Success: false, Error: "插件 ID 不能为空"}, nil
+		return actionFailure(errcode.InvalidArgument, "插件 ID 不能为空"), nil
 	}
 
 	if err := s.manager.DisablePlugin(req.PluginId); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 
 	return &pb.ActionResponse{Success: true, Message: "插件已禁用"}, nil
@@ -118,30 +211,37 @@ func (s *PluginServer) GetPluginConfig(ctx context.Context, req *pb.PluginReques
 		return nil, status.Errorf(codes.NotFound, "获取配置失败: %v", err)
 	}
 
-	configJSON, err := json.Marshal(config)
+	configJSON, err := json.Marshal(config.Values)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "序列化配置失败: %v", err)
 	}
+	var schemaJSON []byte
+	if len(config.Schema) > 0 {
+		if schemaJSON, err = json.Marshal(config.Schema); err != nil {
+			return nil, status.Errorf(codes.Internal, "序列化配置 schema 失败: %v", err)
+		}
+	}
 
 	return &pb.PluginConfig{
 		PluginId:   req.PluginId,
 		ConfigJson: string(configJSON),
+		SchemaJson: string(schemaJSON),
 	}, nil
 }
 
 // SetPluginConfig 设置插件配置
 func (s *PluginServer) SetPluginConfig(ctx context.Context, req *pb.SetPluginConfigRequest) (*pb.ActionResponse, error) {
 	if req.PluginId == "" {
-		return &pb.ActionResponse{Success: false, Error: "插件 ID 不能为空"}, nil
+		return actionFailure(errcode.InvalidArgument, "插件 ID 不能为空"), nil
 	}
 
 	var config map[string]any
 	if err := json.Unmarshal([]byte(req.ConfigJson), &config); err != nil {
-		return &pb.ActionResponse{Success: false, Error: "解析配置失败: " + err.Error()}, nil
+		return actionError("解析配置失败", err), nil
 	}
 
 	if err := s.manager.SetPluginConfig(req.PluginId, config); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 
 	return &pb.ActionResponse{Success: true, Message: "配置已保存"}, nil
@@ -159,110 +259,163 @@ func (s *PluginServer) GetPluginStatus(ctx context.Context, req *pb.PluginReques
 	}
 
 	return &pb.PluginStatus{
-		PluginId: pluginStatus.PluginID,
-		State:    convertPluginState(pluginStatus.State),
-		Running:  pluginStatus.Running,
-		Error:    pluginStatus.Error,
-		Uptime:   pluginStatus.Uptime,
-		Stats:    pluginStatus.Stats,
+		PluginId:          pluginStatus.PluginID,
+		State:             convertPluginState(pluginStatus.State),
+		Running:           pluginStatus.Running,
+		Error:             pluginStatus.Error,
+		Uptime:            pluginStatus.Uptime,
+		Stats:             pluginStatus.Stats,
+		Usage:             convertResourceUsage(pluginStatus.Usage),
+		Restarts:          int32(pluginStatus.Restarts),
+		NextRestart:       unixOrZero(pluginStatus.NextRestart),
+		LastHealthCheck:   unixOrZero(pluginStatus.LastHealthCheck),
+		PermissionDenials: int32(pluginStatus.PermissionDenials),
+	}, nil
+}
+
+// GetPluginDiagnostics 获取插件最近一次进入错误状态时采集的诊断信息
+func (s *PluginServer) GetPluginDiagnostics(ctx context.Context, req *pb.PluginRequest) (*pb.PluginDiagnostics, error) {
+	if req.PluginId == "" {
+		return nil, status.Error(codes.InvalidArgument, "插件 ID 不能为空")
+	}
+
+	diag, err := s.manager.GetPluginDiagnostics(req.PluginId)
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "获取诊断信息失败: %v", err)
+	}
+	bundle, err := json.Marshal(diag)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "序列化诊断信息失败: %v", err)
+	}
+
+	return &pb.PluginDiagnostics{
+		PluginId:   diag.PluginID,
+		CapturedAt: diag.CapturedAt.Unix(),
+		Reason:     diag.Reason,
+		Panic:      diag.Panic,
+		BundleJson: string(bundle),
 	}, nil
 }
 
 // GetAvailablePlugins 获取可用插件列表
 func (s *PluginServer) GetAvailablePlugins(ctx context.Context, req *pb.Empty) (*pb.AvailablePluginList, error) {
-	// 返回预定义的可用插件列表
-	// 实际应用中应该从远程仓库获取
-	plugins := []*pb.AvailablePlugin{
-		{
-			Id:          "cloudflare-security",
-			Name:        "Cloudflare 安全防护",
-			Version:     "1.0.0",
-			Description: "集成 Cloudflare 安全功能，自动封禁恶意 IP，防 DDoS 攻击。24/7 全天候运行在服务器上。",
-			Author:      "Runixo",
-			Icon:        "🛡️",
-			Type:        pb.PluginType_PLUGIN_AGENT,
-			Downloads:   5200,
-			Rating:      4.7,
-			RatingCount: 128,
-			Tags:        []string{"安全", "Cloudflare", "防火墙", "DDoS"},
-			Category:    "security",
-			Official:    true,
-			DownloadUrl: "https://plugins.runixo.dev/cloudflare-security",
-			UpdatedAt:   "2024-01-20",
-		},
-		{
-			Id:          "nginx-manager",
-			Name:        "Nginx 管理",
-			Version:     "1.0.0",
-			Description: "可视化管理 Nginx 配置、虚拟主机和 SSL 证书",
-			Author:      "Runixo",
-			Icon:        "🌐",
-			Type:        pb.PluginType_PLUGIN_HYBRID,
-			Downloads:   6200,
-			Rating:      4.6,
-			RatingCount: 189,
-			Tags:        []string{"Web服务器", "Nginx", "反向代理"},
-			Category:    "web",
-			Official:    true,
-			DownloadUrl: "https://plugins.runixo.dev/nginx-manager",
-			UpdatedAt:   "2024-01-15",
-		},
-		{
-			Id:          "mysql-manager",
-			Name:        "MySQL 管理",
-			Version:     "1.0.0",
-			Description: "数据库管理、备份恢复、性能监控",
-			Author:      "Runixo",
-			Icon:        "🗄️",
-			Type:        pb.PluginType_PLUGIN_HYBRID,
-			Downloads:   5100,
-			Rating:      4.5,
-			RatingCount: 167,
-			Tags:        []string{"数据库", "MySQL", "SQL"},
-			Category:    "database",
-			Official:    true,
-			DownloadUrl: "https://plugins.runixo.dev/mysql-manager",
-			UpdatedAt:   "2024-01-10",
-		},
-		{
-			Id:          "backup-manager",
-			Name:        "自动备份",
-			Version:     "1.0.0",
-			Description: "定时备份文件和数据库到本地或云存储。在服务器上 24/7 运行。",
-			Author:      "Runixo",
-			Icon:        "💾",
-			Type:        pb.PluginType_PLUGIN_AGENT,
-			Downloads:   4200,
-			Rating:      4.3,
-			RatingCount: 98,
-			Tags:        []string{"备份", "定时任务", "云存储"},
-			Category:    "tools",
-			Official:    true,
-			DownloadUrl: "https://plugins.runixo.dev/backup-manager",
-			UpdatedAt:   "2024-01-05",
-		},
-		{
-			Id:          "advanced-monitor",
-			Name:        "高级监控",
-			Version:     "1.0.0",
-			Description: "详细的性能监控、告警通知、历史数据。在服务器上持续收集数据。",
-			Author:      "Runixo",
-			Icon:        "📊",
-			Type:        pb.PluginType_PLUGIN_AGENT,
-			Downloads:   5600,
-			Rating:      4.6,
-			RatingCount: 145,
-			Tags:        []string{"监控", "告警", "性能"},
-			Category:    "monitor",
-			Official:    true,
-			DownloadUrl: "https://plugins.runixo.dev/advanced-monitor",
-			UpdatedAt:   "2024-01-03",
-		},
+	available := s.manager.AvailablePlugins(ctx)
+
+	plugins := make([]*pb.AvailablePlugin, 0, len(available))
+	for _, p := range available {
+		plugins = append(plugins, convertAvailablePlugin(p))
 	}
 
 	return &pb.AvailablePluginList{Plugins: plugins}, nil
 }
 
+// SearchPlugins 搜索插件市场
+func (s *PluginServer) SearchPlugins(ctx context.Context, req *pb.SearchPluginsRequest) (*pb.SearchPluginsResponse, error) {
+	result := s.manager.SearchPlugins(ctx, plugin.SearchQuery{
+		Query:    req.Query,
+		Category: req.Category,
+		Tags:     req.Tags,
+		Page:     int(req.Page),
+		PageSize: int(req.PageSize),
+	})
+
+	resp := &pb.SearchPluginsResponse{
+		Plugins:  make([]*pb.AvailablePlugin, 0, len(result.Plugins)),
+		Total:    int32(result.Total),
+		Page:     int32(result.Page),
+		PageSize: int32(result.PageSize),
+	}
+	for _, p := range result.Plugins {
+		resp.Plugins = append(resp.Plugins, convertAvailablePlugin(p))
+	}
+	for _, c := range result.Categories {
+		resp.Categories = append(resp.Categories, &pb.PluginCategory{Name: c.Name, Count: int32(c.Count)})
+	}
+	return resp, nil
+}
+
+// GetPluginDetails 获取插件市场中插件的详情
+func (s *PluginServer) GetPluginDetails(ctx context.Context, req *pb.PluginRequest) (*pb.PluginDetails, error) {
+	if req.PluginId == "" {
+		return nil, status.Error(codes.InvalidArgument, "插件 ID 不能为空")
+	}
+
+	details, err := s.manager.PluginDetails(ctx, req.PluginId)
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "获取插件详情失败: %v", err)
+	}
+
+	return &pb.PluginDetails{
+		Plugin:           convertAvailablePlugin(details.AvailablePlugin),
+		Readme:           details.Readme,
+		Screenshots:      details.Screenshots,
+		Installed:        details.Installed,
+		InstalledVersion: details.InstalledVersion,
+		UpdateAvailable:  details.UpdateAvailable,
+	}, nil
+}
+
+// ListScheduledTasks 列出定时任务
+func (s *PluginServer) ListScheduledTasks(ctx context.Context, req *pb.ListScheduledTasksRequest) (*pb.ScheduledTaskList, error) {
+	if req.PluginId != "" && !validPluginID.MatchString(req.PluginId) {
+		return nil, status.Error(codes.InvalidArgument, "插件 ID 格式无效")
+	}
+
+	tasks := s.manager.ScheduledTasks(req.PluginId)
+	list := &pb.ScheduledTaskList{Tasks: make([]*pb.ScheduledTask, 0, len(tasks))}
+	for _, t := range tasks {
+		list.Tasks = append(list.Tasks, &pb.ScheduledTask{
+			Id:             t.ID,
+			Owner:          t.Owner,
+			Cron:           t.Cron,
+			Timezone:       t.Timezone,
+			Jitter:         int64(t.Jitter / time.Second),
+			MissedRun:      t.MissedRun,
+			Running:        t.Running,
+			NextRun:        unixOrZero(t.NextRun),
+			LastRun:        unixOrZero(t.LastRun),
+			LastDurationMs: t.LastDuration.Milliseconds(),
+			LastError:      t.LastError,
+			Runs:           int32(t.Runs),
+			Failures:       int32(t.Failures),
+			Overlaps:       int32(t.Overlaps),
+			Missed:         int32(t.Missed),
+		})
+	}
+	return list, nil
+}
+
+// ExportPluginConfigs 导出所有已安装插件的启用状态和配置
+func (s *PluginServer) ExportPluginConfigs(ctx context.Context, req *pb.ExportPluginConfigsRequest) (*pb.PluginConfigBundle, error) {
+	data, err := s.manager.ExportConfigs(req.Passphrase)
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "导出插件配置失败: %v", err)
+	}
+	return &pb.PluginConfigBundle{Data: data, Encrypted: req.Passphrase != ""}, nil
+}
+
+// ImportPluginConfigs 导入插件配置导出包
+func (s *PluginServer) ImportPluginConfigs(ctx context.Context, req *pb.ImportPluginConfigsRequest) (*pb.ImportPluginConfigsResponse, error) {
+	if len(req.Data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "导出包不能为空")
+	}
+
+	results, err := s.manager.ImportConfigs(req.Data, plugin.ImportOptions{
+		Passphrase:     req.Passphrase,
+		InstallMissing: req.InstallMissing,
+		Plugins:        req.PluginIds,
+	})
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "导入插件配置失败: %v", err)
+	}
+
+	resp := &pb.ImportPluginConfigsResponse{Results: make([]*pb.PluginImportResult, 0, len(results))}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &pb.PluginImportResult{PluginId: r.PluginID, Status: r.Status, Message: r.Message})
+	}
+	return resp, nil
+}
+
 // 转换函数
 func convertPluginInfo(p *plugin.InstalledPlugin) *pb.PluginInfo {
 	return &pb.PluginInfo{
@@ -280,6 +433,51 @@ func convertPluginInfo(p *plugin.InstalledPlugin) *pb.PluginInfo {
 	}
 }
 
+func convertResourceUsage(u *plugin.ResourceUsage) *pb.PluginResourceUsage {
+	if u == nil {
+		return nil
+	}
+	return &pb.PluginResourceUsage{
+		Accounting:     u.Accounting,
+		CpuPercent:     u.CPUPercent,
+		Memory:         u.Memory,
+		Goroutines:     int32(u.Goroutines),
+		CpuLimit:       u.Limits.CPUPercent,
+		MemoryLimit:    u.Limits.Memory,
+		GoroutineLimit: int32(u.Limits.Goroutines),
+		Violations:     int32(u.Violations),
+		SampledAt:      u.SampledAt.Unix(),
+	}
+}
+
+// unixOrZero 可选时间转换为 Unix 时间，为空时返回 0
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func convertAvailablePlugin(p *plugin.AvailablePlugin) *pb.AvailablePlugin {
+	return &pb.AvailablePlugin{
+		Id:          p.ID,
+		Name:        p.Name,
+		Version:     p.Version,
+		Description: p.Description,
+		Author:      p.Author,
+		Icon:        p.Icon,
+		Type:        convertPluginType(p.Type),
+		Downloads:   p.Downloads,
+		Rating:      p.Rating,
+		RatingCount: p.RatingCount,
+		Tags:        p.Tags,
+		Category:    p.Category,
+		Official:    p.Official,
+		DownloadUrl: p.DownloadURL,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
 func convertPluginState(state plugin.PluginState) pb.PluginState {
 	switch state {
 	case plugin.StateInstalled:
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ScriptConfig 脚本执行配置
+type ScriptConfig struct {
+	Enabled bool
+	// Interpreters 允许的解释器，为空时允许 executor.Interpreters() 中的全部
+	Interpreters []string
+}
+
+// SetScriptConfig 设置脚本执行配置
+func (s *AgentServer) SetScriptConfig(c ScriptConfig) {
+	if len(c.Interpreters) == 0 {
+		c.Interpreters = executor.Interpreters()
+	}
+	s.scripts = c
+}
+
+// ExecuteScript 用指定解释器执行脚本
+// 脚本内容不受命令白名单限制，默认禁用；执行策略按解释器名称评估（如 command: "python"）
+func (s *AgentServer) ExecuteScript(ctx context.Context, req *pb.ScriptRequest) (*pb.CommandResponse, error) {
+	if !s.scripts.Enabled {
+		return nil, status.Error(codes.FailedPrecondition, "脚本执行未启用，请在配置中设置 executor.scripts.enabled")
+	}
+	allowed := false
+	for _, name := range s.scripts.Interpreters {
+		if name == req.Interpreter {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, errcode.Status(errcode.CommandNotAllowed, "解释器 '%s' 不在允许列表中", req.Interpreter)
+	}
+	sandbox, err := s.checkPolicy(ctx, req.Interpreter, req.Args)
+	if err != nil {
+		return &pb.CommandResponse{ExitCode: -1, Stderr: err.Error()}, nil
+	}
+
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+
+	sb := s.sandboxFor(req.Sandbox, sandbox)
+	result, err := executor.ExecuteScript(ctx, req.Interpreter, req.Script, req.Args, executor.Options{
+		WorkingDir: req.WorkingDir,
+		Env:        req.Env,
+		Timeout:    timeout,
+		Stdin:      req.Stdin,
+		Limits:     s.limits,
+		Sandbox:    sb,
+	})
+	// 脚本内容可能包含敏感信息，只记录 SHA-256 和长度
+	sum := sha256.Sum256([]byte(req.Script))
+	s.logExec(ctx, audit.ExecRecord{
+		Action:       audit.ActionExecScript,
+		Command:      req.Interpreter,
+		Args:         req.Args,
+		WorkingDir:   req.WorkingDir,
+		ScriptSHA256: hex.EncodeToString(sum[:]),
+		ScriptSize:   len(req.Script),
+		Sandbox:      sb != nil,
+	}, result, nil, err)
+	if err != nil {
+		return nil, errcode.Status(errcode.Of(err), "执行脚本失败: %v", err)
+	}
+
+	return &pb.CommandResponse{
+		ExitCode:   int32(result.ExitCode),
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		DurationMs: result.DurationMs,
+	}, nil
+}
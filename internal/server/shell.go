@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/audit"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shellDrainTimeout shell 退出后继续读取剩余输出的最长时间
+// 后台进程可能仍持有终端，不能无限等待输出结束
+const shellDrainTimeout = time.Second
+
+// ShellConfig 交互式终端配置
+type ShellConfig struct {
+	Enabled bool
+	// Shells 允许的 shell，为空时使用 executor.DefaultShells()
+	Shells []string
+	// IdleTimeout 超过该时长没有输入时断开会话，0 表示不限制
+	IdleTimeout time.Duration
+	// RecordDir 会话录像目录，为空时不录像
+	RecordDir string
+}
+
+// SetShellConfig 设置交互式终端配置
+func (s *AgentServer) SetShellConfig(c ShellConfig) {
+	if len(c.Shells) == 0 {
+		c.Shells = executor.DefaultShells()
+	}
+	s.shell = c
+}
+
+// SetAuditLogger 设置审计日志，终端会话的开始和结束都会记录
+func (s *AgentServer) SetAuditLogger(l *audit.Logger) {
+	s.audit = l
+}
+
+// ExecuteShell 交互式终端
+// 交互式会话不受命令白名单限制，默认禁用；启用后 shell 需要通过执行策略，每个会话都写入审计日志并（可选）录像
+func (s *AgentServer) ExecuteShell(stream pb.AgentService_ExecuteShellServer) error {
+	if !s.shell.Enabled {
+		return status.Error(codes.FailedPrecondition, "交互式 Shell 未启用，请在配置中设置 shell.enabled")
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "第一条消息必须是 start")
+	}
+	rows, cols := clampTermSize(start.Rows), clampTermSize(start.Cols)
+	if rows == 0 || cols == 0 {
+		rows, cols = 24, 80
+	}
+	shell := start.Shell
+	if shell == "" {
+		shell = executor.DefaultShell(s.shell.Shells)
+	}
+	// 会话中输入的命令无法逐条评估，按 shell 本身评估执行策略：配置了策略时需要规则明确允许该 shell
+	// （或 default 为 allow）；策略或配置要求沙箱时拒绝，终端不支持沙箱执行
+	sandbox, err := s.checkPolicy(stream.Context(), shell, nil)
+	if err != nil {
+		return err
+	}
+	if s.sandboxFor(false, sandbox) != nil {
+		return errcode.New(errcode.CommandNotAllowed, "执行策略要求在沙箱中执行，交互式终端不支持沙箱")
+	}
+	term, err := executor.StartTerminal(executor.TerminalOptions{
+		Shell:      shell,
+		Rows:       rows,
+		Cols:       cols,
+		Env:        start.Env,
+		WorkingDir: start.WorkingDir,
+	}, s.shell.Shells)
+	if err != nil {
+		return errcode.Status(errcode.Of(err), "启动终端失败: %v", err)
+	}
+
+	session := &shellSession{
+		id:       newSessionID(),
+		clientIP: clientAddr(stream.Context()),
+		term:     term,
+		stream:   stream,
+		started:  time.Now(),
+	}
+	if s.shell.RecordDir != "" {
+		rec, err := audit.NewSessionRecorder(s.shell.RecordDir, session.id, int(cols), int(rows), shell)
+		if err != nil {
+			log.Warn().Err(err).Str("session", session.id).Msg("创建终端会话录像失败")
+		} else {
+			session.recorder = rec
+			defer rec.Close()
+		}
+	}
+
+	log.Info().Str("session", session.id).Str("client", session.clientIP).Str("shell", shell).Msg("终端会话已开始")
+	if s.audit != nil {
+		details := map[string]interface{}{"shell": shell, "working_dir": start.WorkingDir}
+		if session.recorder != nil {
+			details["recording"] = filepath.Join(s.shell.RecordDir, session.id+".cast")
+		}
+		s.audit.LogShellSession(session.clientIP, "shell_start", session.id, true, details)
+	}
+
+	code, reason := session.run(s.shell.IdleTimeout)
+
+	log.Info().Str("session", session.id).Int("exit_code", code).Str("reason", reason).Msg("终端会话已结束")
+	if s.audit != nil {
+		s.audit.LogShellSession(session.clientIP, "shell_end", session.id, code == 0, map[string]interface{}{
+			"exit_code":   code,
+			"reason":      reason,
+			"duration_ms": time.Since(session.started).Milliseconds(),
+			"bytes_in":    session.bytesIn.Load(),
+			"bytes_out":   session.bytesOut.Load(),
+		})
+	}
+	return nil
+}
+
+// shellSession 一个终端会话
+type shellSession struct {
+	id       string
+	clientIP string
+	term     executor.Terminal
+	stream   pb.AgentService_ExecuteShellServer
+	recorder *audit.SessionRecorder
+	started  time.Time
+
+	// 输入协程在 handler 返回前可能仍在运行，计数使用原子操作
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+}
+
+// run 转发输入输出直到 shell 退出、客户端断开或空闲超时，返回退出码和结束原因
+func (s *shellSession) run(idleTimeout time.Duration) (int, string) {
+	ctx, cancel := context.WithCancel(s.stream.Context())
+	defer cancel()
+
+	if err := s.stream.Send(&pb.ShellOutput{SessionId: s.id}); err != nil {
+		cancel()
+	}
+
+	activity := make(chan struct{}, 1)
+	inputDone := make(chan struct{})
+	go func() {
+		defer close(inputDone)
+		defer cancel()
+		s.readInput(activity)
+	}()
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		s.writeOutput(cancel)
+	}()
+
+	type exit struct {
+		code int
+		err  error
+	}
+	exited := make(chan exit, 1)
+	go func() {
+		code, err := s.term.Wait()
+		exited <- exit{code, err}
+	}()
+
+	var idle <-chan time.Time
+	var timer *time.Timer
+	if idleTimeout > 0 {
+		timer = time.NewTimer(idleTimeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+
+	reason := "exited"
+	var result exit
+wait:
+	for {
+		select {
+		case result = <-exited:
+			break wait
+		case <-activity:
+			if timer != nil {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(idleTimeout)
+			}
+		case <-idle:
+			reason = "idle_timeout"
+			s.term.Close()
+			result = <-exited
+			break wait
+		case <-ctx.Done():
+			reason = "client_closed"
+			s.term.Close()
+			result = <-exited
+			break wait
+		}
+	}
+
+	// shell 退出后把剩余输出发完再关闭终端
+	select {
+	case <-outputDone:
+	case <-time.After(shellDrainTimeout):
+	}
+	s.term.Close()
+	<-outputDone
+
+	code := result.code
+	if result.err != nil {
+		code = -1
+	}
+	if reason != "client_closed" {
+		s.stream.Send(&pb.ShellOutput{Exit: &pb.ShellExit{ExitCode: int32(code), Reason: reason}})
+	}
+	return code, reason
+}
+
+// readInput 处理客户端输入，直到流结束
+func (s *shellSession) readInput(activity chan<- struct{}) {
+	for {
+		in, err := s.stream.Recv()
+		if err != nil {
+			return
+		}
+		switch input := in.Input.(type) {
+		case *pb.ShellInput_Data:
+			if _, err := s.term.Write(input.Data); err != nil {
+				return
+			}
+			s.bytesIn.Add(int64(len(input.Data)))
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+		case *pb.ShellInput_Resize:
+			rows, cols := clampTermSize(input.Resize.Rows), clampTermSize(input.Resize.Cols)
+			if rows == 0 || cols == 0 {
+				continue
+			}
+			if err := s.term.Resize(rows, cols); err == nil && s.recorder != nil {
+				s.recorder.Resize(int(cols), int(rows))
+			}
+		case *pb.ShellInput_Signal:
+			sig, err := executor.ParseSignal(input.Signal)
+			if err == nil {
+				err = s.term.Signal(sig)
+			}
+			if err != nil {
+				log.Debug().Err(err).Str("session", s.id).Str("signal", input.Signal).Msg("终端信号发送失败")
+			}
+		}
+	}
+}
+
+// writeOutput 把终端输出发送给客户端，终端关闭时返回
+func (s *shellSession) writeOutput(cancel context.CancelFunc) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.term.Read(buf)
+		if n > 0 {
+			if s.recorder != nil {
+				s.recorder.Output(buf[:n])
+			}
+			s.bytesOut.Add(int64(n))
+			if sendErr := s.stream.Send(&pb.ShellOutput{Data: buf[:n]}); sendErr != nil {
+				cancel()
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// clampTermSize 终端行列数限制在 1~1000，无效值返回 0
+func clampTermSize(v int32) uint16 {
+	switch {
+	case v <= 0:
+		return 0
+	case v > 1000:
+		return 1000
+	}
+	return uint16(v)
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
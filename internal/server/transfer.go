@@ -0,0 +1,393 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/executor"
+	"github.com/runixo/agent/internal/security"
+	"github.com/shirou/gopsutil/v3/disk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxUploadSize     int64 = 64 << 30 // 64GB 上传大小限制
+	transferChunkSize       = 256 * 1024
+	// 上传中的数据写入 <path>.runixo-part，校验通过后才重命名为目标文件
+	partSuffix = ".runixo-part"
+)
+
+// upload 一次上传（可能是续传）的状态
+type upload struct {
+	start     *pb.FileUploadStart
+	path      string
+	extractTo string
+	file      *os.File
+	hash      hash.Hash // 覆盖整个文件，续传时包含已有部分
+	written   int64     // 当前写入位置
+	checksum  string    // 结束消息中的校验和，优先于开始消息
+}
+
+// GetUploadOffset 返回未完成上传已写入的字节数，客户端以此作为 FileUploadStart.offset 续传
+func (s *AgentServer) GetUploadOffset(ctx context.Context, req *pb.FileRequest) (*pb.UploadOffset, error) {
+	path, err := uploadPath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path + partSuffix)
+	if os.IsNotExist(err) {
+		return &pb.UploadOffset{}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "读取上传状态失败: %v", err)
+	}
+	return &pb.UploadOffset{Offset: info.Size()}, nil
+}
+
+// UploadFile 流式文件上传
+// 连接中断时保留临时文件，客户端可以通过 GetUploadOffset 查询偏移后续传
+func (s *AgentServer) UploadFile(stream pb.AgentService_UploadFileServer) error {
+	var u *upload
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			if u == nil {
+				return stream.SendAndClose(&pb.UploadResponse{
+					Success: false,
+					Error:   "未收到任何数据",
+				})
+			}
+			resp, err := u.finish(stream.Context())
+			if err != nil {
+				return err
+			}
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			if u != nil {
+				u.file.Close()
+			}
+			return err
+		}
+
+		switch data := chunk.Data.(type) {
+		case *pb.FileChunk_Start:
+			if u != nil {
+				u.file.Close()
+				return status.Error(codes.FailedPrecondition, "重复的开始消息")
+			}
+			if u, err = beginUpload(data.Start); err != nil {
+				return err
+			}
+
+		case *pb.FileChunk_Chunk:
+			if u == nil {
+				return status.Error(codes.FailedPrecondition, "未收到开始消息")
+			}
+			if err := u.write(chunk.Offset, data.Chunk); err != nil {
+				return err
+			}
+
+		case *pb.FileChunk_End:
+			if u == nil {
+				return status.Error(codes.FailedPrecondition, "未收到开始消息")
+			}
+			u.checksum = data.End.GetChecksum()
+			log.Info().Int64("bytes", u.written).Msg("文件接收完成")
+		}
+	}
+}
+
+// uploadPath 清理并校验上传目标路径
+func uploadPath(path string) (string, error) {
+	cleanPath, err := security.SanitizePath(path)
+	if err != nil {
+		return "", errcode.Status(errcode.Of(err), "路径安全检查失败: %v", err)
+	}
+	if err := pathValidator.ValidatePathForWrite(cleanPath); err != nil {
+		return "", errcode.Status(errcode.Of(err), "写入路径被拒绝: %v", err)
+	}
+	return cleanPath, nil
+}
+
+// beginUpload 校验开始消息并打开临时文件，offset > 0 时在已有的临时文件上续写
+func beginUpload(start *pb.FileUploadStart) (*upload, error) {
+	log.Info().
+		Str("path", start.Path).
+		Int64("size", start.TotalSize).
+		Int64("offset", start.Offset).
+		Bool("is_tar_gz", start.IsTarGz).
+		Str("extract_to", start.ExtractTo).
+		Msg("开始接收文件")
+
+	// 大小限制检查
+	if start.TotalSize > maxUploadSize {
+		return nil, status.Errorf(codes.InvalidArgument, "文件过大，超过 64GB 限制 (size: %d)", start.TotalSize)
+	}
+	if start.Offset < 0 || (start.TotalSize > 0 && start.Offset > start.TotalSize) {
+		return nil, status.Errorf(codes.InvalidArgument, "无效的续传偏移: %d", start.Offset)
+	}
+
+	path, err := uploadPath(start.Path)
+	if err != nil {
+		return nil, err
+	}
+	u := &upload{start: start, path: path, hash: sha256.New(), written: start.Offset}
+
+	// 安全检查：验证 extractTo 路径
+	if start.IsTarGz && start.ExtractTo != "" {
+		cleanExtractTo, err := security.SanitizePath(start.ExtractTo)
+		if err != nil {
+			return nil, errcode.Status(errcode.Of(err), "解压路径安全检查失败: %v", err)
+		}
+		if err := pathValidator.ValidatePathForWrite(cleanExtractTo); err != nil {
+			return nil, errcode.Status(errcode.Of(err), "解压路径被拒绝: %v", err)
+		}
+		u.extractTo = cleanExtractTo
+	}
+
+	// 创建父目录
+	if start.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, status.Errorf(codes.Internal, "创建目录失败: %v", err)
+		}
+	}
+
+	// 提前发现空间不足，避免传输几个 GB 后才失败
+	if remaining := start.TotalSize - start.Offset; remaining > 0 {
+		if usage, err := disk.Usage(filepath.Dir(path)); err == nil && uint64(remaining) > usage.Free {
+			return nil, status.Errorf(codes.ResourceExhausted, "磁盘空间不足: 需要 %d 字节，可用 %d 字节", remaining, usage.Free)
+		}
+	}
+
+	part := path + partSuffix
+	if start.Offset == 0 {
+		mode := os.FileMode(start.Mode).Perm()
+		if mode == 0 {
+			mode = 0644
+		}
+		if u.file, err = os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode); err != nil {
+			return nil, status.Errorf(codes.Internal, "创建文件失败: %v", err)
+		}
+		return u, nil
+	}
+
+	if u.file, err = os.OpenFile(part, os.O_RDWR, 0); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "没有可续传的上传: %v", err)
+	}
+	info, err := u.file.Stat()
+	if err == nil && info.Size() < start.Offset {
+		err = status.Errorf(codes.FailedPrecondition, "临时文件只有 %d 字节，无法从 %d 续传", info.Size(), start.Offset)
+	}
+	// 丢弃偏移之后可能不完整的数据，并把已有部分计入校验和
+	if err == nil {
+		err = u.file.Truncate(start.Offset)
+	}
+	if err == nil {
+		_, err = io.Copy(u.hash, io.NewSectionReader(u.file, 0, start.Offset))
+	}
+	if err == nil {
+		_, err = u.file.Seek(start.Offset, io.SeekStart)
+	}
+	if err != nil {
+		u.file.Close()
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "打开临时文件失败: %v", err)
+	}
+	return u, nil
+}
+
+// write 写入一个数据块，写入失败时保留临时文件以便续传
+func (u *upload) write(offset int64, data []byte) error {
+	if offset != 0 && offset != u.written {
+		u.file.Close()
+		return status.Errorf(codes.InvalidArgument, "数据块偏移 %d 与当前位置 %d 不一致", offset, u.written)
+	}
+	// 运行时大小检查（防止 totalSize 被伪造）
+	if u.written+int64(len(data)) > maxUploadSize {
+		u.file.Close()
+		os.Remove(u.path + partSuffix)
+		return status.Errorf(codes.ResourceExhausted, "上传数据超过 64GB 限制")
+	}
+
+	n, err := u.file.Write(data)
+	u.hash.Write(data[:n])
+	u.written += int64(n)
+	if err != nil {
+		u.file.Close()
+		return status.Errorf(codes.Internal, "写入文件失败: %v", err)
+	}
+
+	// 每 100MB 记录一次进度
+	if u.written%(100*1024*1024) < int64(n) {
+		ev := log.Debug().Int64("received", u.written).Int64("total", u.start.TotalSize)
+		if u.start.TotalSize > 0 {
+			ev = ev.Float64("percent", float64(u.written)/float64(u.start.TotalSize)*100)
+		}
+		ev.Msg("上传进度")
+	}
+	return nil
+}
+
+// finish 校验大小和 sha256 后把临时文件移动到目标位置（或解压）
+func (u *upload) finish(ctx context.Context) (*pb.UploadResponse, error) {
+	part := u.path + partSuffix
+	if err := u.file.Close(); err != nil {
+		return nil, status.Errorf(codes.Internal, "写入文件失败: %v", err)
+	}
+
+	// 客户端提前结束，保留临时文件
+	if u.start.TotalSize > 0 && u.written < u.start.TotalSize {
+		return &pb.UploadResponse{
+			Success:      false,
+			Error:        "上传未完成，可从当前偏移续传",
+			BytesWritten: u.written,
+			Path:         u.path,
+		}, nil
+	}
+
+	sum := hex.EncodeToString(u.hash.Sum(nil))
+	expected := u.checksum
+	if expected == "" {
+		expected = u.start.Checksum
+	}
+	if expected != "" && !strings.EqualFold(strings.TrimPrefix(expected, "sha256:"), sum) {
+		os.Remove(part)
+		return nil, status.Errorf(codes.DataLoss, "校验和不匹配: 期望 %s，实际 %s", expected, sum)
+	}
+
+	// 如果是 tar.gz，需要解压
+	if u.start.IsTarGz && u.extractTo != "" {
+		defer os.Remove(part)
+		if err := extractTarGz(ctx, part, u.extractTo); err != nil {
+			return nil, err
+		}
+		return &pb.UploadResponse{
+			Success:      true,
+			Message:      "文件夹上传并解压成功",
+			BytesWritten: u.written,
+			Path:         u.extractTo,
+			Checksum:     sum,
+		}, nil
+	}
+
+	if err := os.Rename(part, u.path); err != nil {
+		return nil, status.Errorf(codes.Internal, "移动文件失败: %v", err)
+	}
+	return &pb.UploadResponse{
+		Success:      true,
+		Message:      "文件上传成功",
+		BytesWritten: u.written,
+		Path:         u.path,
+		Checksum:     sum,
+	}, nil
+}
+
+// extractTarGz 把上传的 tar.gz 解压到 extractTo，路径遍历检查由 executor.ExtractArchive 完成
+func extractTarGz(ctx context.Context, archive, extractTo string) error {
+	log.Info().Str("file", archive).Str("extract_to", extractTo).Msg("解压文件")
+
+	if _, err := executor.ExtractArchive(ctx, archive, extractTo, executor.ArchiveOptions{}); err != nil {
+		return errcode.Status(errcode.Of(err), "解压失败: %v", err)
+	}
+	return nil
+}
+
+// DownloadFile 流式文件下载
+// 每个数据块携带偏移；结束消息携带整个文件的 sha256（从 offset 续传时同样覆盖整个文件）
+func (s *AgentServer) DownloadFile(req *pb.FileRequest, stream pb.AgentService_DownloadFileServer) error {
+	// 安全检查
+	cleanPath, err := security.SanitizePath(req.Path)
+	if err != nil {
+		return errcode.Status(errcode.Of(err), "路径安全检查失败: %v", err)
+	}
+
+	// 安全检查：验证路径访问权限
+	if err := pathValidator.ValidatePath(cleanPath); err != nil {
+		return errcode.Status(errcode.Of(err), "路径访问被拒绝: %v", err)
+	}
+
+	// 打开文件
+	file, err := os.Open(cleanPath)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	// 获取文件信息
+	info, err := file.Stat()
+	if err != nil {
+		return status.Errorf(codes.Internal, "获取文件信息失败: %v", err)
+	}
+
+	if info.IsDir() {
+		return status.Error(codes.InvalidArgument, "不能下载目录，请先打包")
+	}
+	if req.Offset < 0 || req.Offset > info.Size() {
+		return status.Errorf(codes.OutOfRange, "偏移 %d 超出文件大小 %d", req.Offset, info.Size())
+	}
+
+	h := sha256.New()
+	if req.Offset > 0 {
+		// 已下载的部分只计入校验和，读取后文件位置恰好位于 offset
+		if _, err := io.CopyN(h, file, req.Offset); err != nil {
+			return status.Errorf(codes.Internal, "读取文件失败: %v", err)
+		}
+	}
+
+	// 发送开始消息
+	if err := stream.Send(&pb.FileChunk{
+		Data: &pb.FileChunk_Start{
+			Start: &pb.FileUploadStart{
+				Path:      cleanPath,
+				TotalSize: info.Size(),
+				Mode:      int64(info.Mode()),
+				Offset:    req.Offset,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	// 分块发送文件内容
+	buf := make([]byte, transferChunkSize)
+	offset := req.Offset
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			if err := stream.Send(&pb.FileChunk{
+				Data:   &pb.FileChunk_Chunk{Chunk: buf[:n]},
+				Offset: offset,
+			}); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "读取文件失败: %v", err)
+		}
+	}
+
+	// 发送结束消息
+	return stream.Send(&pb.FileChunk{
+		Data: &pb.FileChunk_End{
+			End: &pb.FileUploadEnd{Checksum: hex.EncodeToString(h.Sum(nil))},
+		},
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	pb "github.com/runixo/agent/api/proto"
+	"github.com/runixo/agent/internal/errcode"
 	"github.com/runixo/agent/internal/updater"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -85,11 +86,11 @@ func (s *UpdateServer) DownloadUpdate(req *pb.UpdateRequest, stream pb.UpdateSer
 // ApplyUpdate 应用更新
 func (s *UpdateServer) ApplyUpdate(ctx context.Context, req *pb.UpdateRequest) (*pb.ActionResponse, error) {
 	if req.Version == "" {
-		return &pb.ActionResponse{Success: false, Error: "版本号不能为空"}, nil
+		return actionFailure(errcode.InvalidArgument, "版本号不能为空"), nil
 	}
 
 	if err := s.updater.ApplyUpdate(req.Version); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 
 	return &pb.ActionResponse{Success: true, Message: "更新已应用，服务即将重启"}, nil
@@ -119,7 +120,7 @@ func (s *UpdateServer) SetUpdateConfig(ctx context.Context, req *pb.UpdateConfig
 	}
 
 	if err := s.updater.SetConfig(config); err != nil {
-		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+		return actionError("", err), nil
 	}
 
 	return &pb.ActionResponse{Success: true, Message: "配置已保存"}, nil
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/webhook"
 )
 
 const (
@@ -91,6 +93,7 @@ type Updater struct {
 	history        []UpdateRecord
 	progressChan   chan *DownloadProgress
 	lastApply      time.Time // 防 DoS 冷却
+	events         webhook.Publisher
 }
 
 // NewUpdater 创建更新器
@@ -116,6 +119,13 @@ func NewUpdater(currentVersion, dataDir string) (*Updater, error) {
 	return u, nil
 }
 
+// SetEventPublisher 设置事件推送（更新安装成功后发送 update.applied）
+func (u *Updater) SetEventPublisher(p webhook.Publisher) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.events = p
+}
+
 // loadConfig 加载配置
 func (u *Updater) loadConfig() {
 	configFile := filepath.Join(u.dataDir, "update_config.json")
@@ -227,12 +237,12 @@ func (u *Updater) CheckUpdate() (*UpdateInfo, error) {
 	httpClient := &http.Client{Timeout: apiTimeout}
 	resp, err := httpClient.Get(releaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("请求 GitHub 失败: %w", err)
+		return nil, errcode.Wrap(errcode.UpstreamUnavailable, err, "请求 GitHub 失败")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub 返回错误: %s", resp.Status)
+		return nil, errcode.New(errcode.UpstreamUnavailable, "GitHub 返回错误: %s", resp.Status)
 	}
 
 	var release struct {
@@ -290,7 +300,7 @@ func (u *Updater) DownloadUpdate(version string, progressChan chan<- *DownloadPr
 		return "", err
 	}
 	if !info.Available || info.LatestVersion != version {
-		return "", fmt.Errorf("版本 %s 不可用", version)
+		return "", errcode.New(errcode.UpdateNotAvailable, "版本 %s 不可用", version)
 	}
 	return u.downloadAndExtract(info, progressChan)
 }
@@ -353,13 +363,13 @@ func (u *Updater) ApplyUpdate(version string) error {
 	u.mu.Lock()
 	if time.Since(u.lastApply) < applyCooldown {
 		u.mu.Unlock()
-		return fmt.Errorf("更新冷却中，请 %d 秒后重试", int(applyCooldown.Seconds()))
+		return errcode.New(errcode.UpdateCooldown, "更新冷却中，请 %d 秒后重试", int(applyCooldown.Seconds()))
 	}
 	u.lastApply = time.Now()
 	u.mu.Unlock()
 
 	if !versionRegex.MatchString(version) {
-		return fmt.Errorf("无效的版本号: %s", version)
+		return errcode.New(errcode.InvalidArgument, "无效的版本号: %s", version)
 	}
 
 	info, err := u.CheckUpdate()
@@ -367,7 +377,7 @@ func (u *Updater) ApplyUpdate(version string) error {
 		return fmt.Errorf("获取更新信息失败: %w", err)
 	}
 	if !info.Available {
-		return fmt.Errorf("没有可用更新")
+		return errcode.New(errcode.UpdateNotAvailable, "没有可用更新")
 	}
 
 	// 安全修复：使用 downloadAndExtract（包含 SHA256 校验），而不是直接 downloadFile
@@ -409,11 +419,11 @@ func (u *Updater) downloadAndExtract(info *UpdateInfo, progressChan chan<- *Down
 		}
 		if !valid {
 			os.Remove(tarPath)
-			return "", fmt.Errorf("校验和不匹配，文件可能被篡改")
+			return "", errcode.New(errcode.UpdateVerifyFailed, "校验和不匹配，文件可能被篡改")
 		}
 	} else {
 		os.Remove(tarPath)
-		return "", fmt.Errorf("缺少校验和信息，拒绝安装未验证的更新")
+		return "", errcode.New(errcode.UpdateVerifyFailed, "缺少校验和信息，拒绝安装未验证的更新")
 	}
 
 	binaryName := "runixo-agent"
@@ -474,6 +484,16 @@ func (u *Updater) applyBinary(binaryPath, version string) error {
 
 	u.recordUpdate(version, true, "")
 	log.Info().Str("version", version).Msg("更新已应用，即将重启服务")
+
+	u.mu.RLock()
+	events := u.events
+	u.mu.RUnlock()
+	if events != nil {
+		events.Publish(webhook.EventUpdateApplied, map[string]string{
+			"version":      version,
+			"from_version": u.currentVersion,
+		})
+	}
 	go u.restartService()
 	return nil
 }
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/runixo/agent/internal/errcode"
+)
+
+// 订阅的推送格式
+const (
+	FormatJSON     = "json"     // 带签名的 JSON 事件（默认）
+	FormatSlack    = "slack"    // Slack Incoming Webhook，URL 为 Slack 提供的地址
+	FormatTelegram = "telegram" // Telegram Bot sendMessage，Secret 为 Bot Token
+)
+
+// defaultTelegramURL 未指定 URL 时 Telegram Bot API 的地址
+const defaultTelegramURL = "https://api.telegram.org"
+
+// maxMessageData 聊天消息中附带的事件数据的最大长度
+const maxMessageData = 1000
+
+// Channel 订阅的推送渠道，Format 为空或 json 时推送带签名的 JSON 事件，其他格式推送可读的文本消息
+type Channel struct {
+	Format string `json:"format,omitempty"`
+	// Telegram 的 chat ID
+	ChatID string `json:"chat_id,omitempty"`
+}
+
+// Summarizer 事件数据实现该接口时，聊天渠道使用其返回的文本作为消息正文，否则附带事件数据的 JSON
+type Summarizer interface {
+	Summary() string
+}
+
+// validate 校验渠道设置，secret 为订阅的签名密钥或 Bot Token
+func (c *Channel) validate(secret string) error {
+	switch c.Format {
+	case "", FormatJSON, FormatSlack:
+	case FormatTelegram:
+		if c.ChatID == "" {
+			return errcode.New(errcode.InvalidArgument, "telegram 需要 chat_id")
+		}
+		if secret == "" {
+			return errcode.New(errcode.InvalidArgument, "telegram 需要在 secret 中提供 Bot Token")
+		}
+	default:
+		return errcode.New(errcode.InvalidArgument, "不支持的推送格式 %q，可选 json、slack、telegram", c.Format)
+	}
+	return nil
+}
+
+// chat 是否推送文本消息
+func (c *Channel) chat() bool {
+	return c.Format == FormatSlack || c.Format == FormatTelegram
+}
+
+// message 按渠道格式生成请求地址和请求体
+func (c *Channel) message(target, secret string, event *Event) (string, []byte, error) {
+	text := messageText(event)
+	var payload any
+	switch c.Format {
+	case FormatTelegram:
+		target = strings.TrimSuffix(target, "/") + "/bot" + secret + "/sendMessage"
+		payload = map[string]any{"chat_id": c.ChatID, "text": text, "disable_web_page_preview": true}
+	default:
+		payload = map[string]string{"text": text}
+	}
+	body, err := json.Marshal(payload)
+	return target, body, err
+}
+
+// messageText 聊天消息的文本：主机名、事件类型和事件摘要
+func messageText(event *Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", event.Hostname, event.Type)
+	for _, key := range sortedKeys(event.Labels) {
+		fmt.Fprintf(&b, " %s=%s", key, event.Labels[key])
+	}
+
+	switch data := event.Data.(type) {
+	case nil:
+	case Summarizer:
+		b.WriteString("\n")
+		b.WriteString(data.Summary())
+	default:
+		if raw, err := json.Marshal(data); err == nil {
+			b.WriteString("\n")
+			b.WriteString(truncate(string(raw), maxMessageData))
+		}
+	}
+	return b.String()
+}
+
+// truncate 截断到 n 个字节以内，不截断多字节字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+// sortedKeys 按字典序排列的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,594 @@
+// Package webhook 向外部系统推送 Agent 事件
+// 每个订阅包含目标 URL、签名密钥和事件过滤器，投递失败时按指数退避重试
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/runixo/agent/internal/errcode"
+	"github.com/runixo/agent/internal/security"
+)
+
+// 事件类型
+const (
+	EventAlert         = "alert"              // 告警（如紧急避险终止进程）
+	EventUpdateApplied = "update.applied"     // 更新已安装
+	EventPluginCrashed = "plugin.crashed"     // 插件启动失败或异常退出
+	EventIPBlocked     = "ip.blocked"         // IP 被封禁
+	EventIPUnblocked   = "ip.unblocked"       // IP 被解封
+	EventHighRisk      = "security.high_risk" // 检测到评分达到封禁阈值的高风险 IP
+	EventLogin         = "login"              // 出现新的交互式登录会话
+	EventNeighbor      = "neighbor"           // 邻居表出现新的或发生变化的 MAC-IP 对应关系
+	EventPing          = "ping"               // 测试投递
+)
+
+// KnownEvents 可订阅的事件类型，"*" 表示全部
+var KnownEvents = []string{EventAlert, EventUpdateApplied, EventPluginCrashed, EventIPBlocked, EventIPUnblocked, EventHighRisk, EventLogin, EventNeighbor}
+
+const (
+	maxSubscriptions = 32
+	queueSize        = 256
+	workerCount      = 4
+	maxAttempts      = 5
+	initialBackoff   = 2 * time.Second
+	maxBackoff       = 2 * time.Minute
+	deliveryTimeout  = 10 * time.Second
+)
+
+// Publisher 事件发布接口，供其他模块在不依赖具体实现的情况下推送事件
+type Publisher interface {
+	Publish(eventType string, data any)
+}
+
+// Subscription webhook 订阅
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	// 推送渠道，默认为带签名的 JSON 事件
+	Channel
+
+	// 最近一次投递结果
+	LastDeliveryAt time.Time `json:"last_delivery_at,omitempty"`
+	LastStatus     int       `json:"last_status,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	Failures       int       `json:"failures"` // 连续失败次数
+}
+
+// Event 推送给订阅方的事件
+type Event struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Timestamp time.Time         `json:"timestamp"`
+	Hostname  string            `json:"hostname"`
+	Labels    map[string]string `json:"labels,omitempty"` // 主机标签
+	Data      any               `json:"data,omitempty"`
+}
+
+type delivery struct {
+	sub   *Subscription
+	event *Event
+	body  []byte
+}
+
+// Dispatcher webhook 订阅管理与投递
+type Dispatcher struct {
+	dataDir      string
+	subs         map[string]*Subscription
+	queue        chan *delivery
+	client       *http.Client
+	allowPrivate bool
+	hostname     string
+	labels       map[string]string
+	mu           sync.RWMutex
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// NewDispatcher 创建投递器并加载已保存的订阅
+// allowPrivate 为 true 时允许推送到内网地址（例如同机房的告警服务）
+func NewDispatcher(dataDir string, allowPrivate bool) (*Dispatcher, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hostname, _ := os.Hostname()
+
+	d := &Dispatcher{
+		dataDir:      dataDir,
+		subs:         make(map[string]*Subscription),
+		queue:        make(chan *delivery, queueSize),
+		allowPrivate: allowPrivate,
+		hostname:     hostname,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	d.client = &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: &http.Transport{DialContext: d.dialer().DialContext},
+		// 不跟随重定向，避免绕过地址检查
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	d.load()
+
+	for i := 0; i < workerCount; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+	return d, nil
+}
+
+// dialer 在建立连接时检查实际 IP，防止 DNS 重绑定绕过注册时的检查
+func (d *Dispatcher) dialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if d.allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("无效的地址: %s", host)
+			}
+			return security.CheckIP(ip)
+		},
+	}
+}
+
+// Close 停止投递，队列中未处理的事件将被丢弃
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Add 注册推送带签名的 JSON 事件的订阅，secret 为空时自动生成
+func (d *Dispatcher) Add(rawURL, secret string, events []string) (*Subscription, error) {
+	return d.AddChannel(rawURL, secret, events, Channel{})
+}
+
+// AddChannel 注册推送到 channel 的订阅。Telegram 的 secret 为 Bot Token，rawURL 为空时使用官方 API 地址；
+// 其他格式的 secret 为空时自动生成
+func (d *Dispatcher) AddChannel(rawURL, secret string, events []string, channel Channel) (*Subscription, error) {
+	if rawURL == "" && channel.Format == FormatTelegram {
+		rawURL = defaultTelegramURL
+	}
+	if err := channel.validate(secret); err != nil {
+		return nil, err
+	}
+	if err := d.checkURL(rawURL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeEvents(events)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		ID:        id,
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		Enabled:   true,
+		CreatedAt: time.Now(),
+		Channel:   channel,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.subs) >= maxSubscriptions {
+		return nil, errcode.New(errcode.InvalidArgument, "订阅数量已达上限 (%d)", maxSubscriptions)
+	}
+	d.subs[id] = sub
+	d.saveLocked()
+
+	log.Info().Str("id", id).Str("url", rawURL).Strs("events", events).Msg("已添加 webhook 订阅")
+	copied := *sub
+	return &copied, nil
+}
+
+// Update 修改订阅的 URL、事件过滤器和启用状态，nil 表示不修改
+func (d *Dispatcher) Update(id string, rawURL *string, events []string, enabled *bool) (*Subscription, error) {
+	if rawURL != nil {
+		if err := d.checkURL(*rawURL); err != nil {
+			return nil, err
+		}
+	}
+	if events != nil {
+		var err error
+		if events, err = normalizeEvents(events); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sub, ok := d.subs[id]
+	if !ok {
+		return nil, errcode.New(errcode.NotFound, "webhook 订阅 %s 不存在", id)
+	}
+	if rawURL != nil {
+		sub.URL = *rawURL
+	}
+	if events != nil {
+		sub.Events = events
+	}
+	if enabled != nil {
+		sub.Enabled = *enabled
+		if sub.Enabled {
+			sub.Failures = 0
+		}
+	}
+	d.saveLocked()
+
+	copied := *sub
+	return &copied, nil
+}
+
+// UpdateChannel 修改订阅的推送渠道，改为 Telegram 时订阅的 secret 应为 Bot Token
+func (d *Dispatcher) UpdateChannel(id string, channel Channel) (*Subscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sub, ok := d.subs[id]
+	if !ok {
+		return nil, errcode.New(errcode.NotFound, "webhook 订阅 %s 不存在", id)
+	}
+	if err := channel.validate(sub.Secret); err != nil {
+		return nil, err
+	}
+	sub.Channel = channel
+	d.saveLocked()
+
+	copied := *sub
+	return &copied, nil
+}
+
+// Remove 删除订阅
+func (d *Dispatcher) Remove(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.subs[id]; !ok {
+		return errcode.New(errcode.NotFound, "webhook 订阅 %s 不存在", id)
+	}
+	delete(d.subs, id)
+	d.saveLocked()
+	log.Info().Str("id", id).Msg("已删除 webhook 订阅")
+	return nil
+}
+
+// Get 获取订阅副本
+func (d *Dispatcher) Get(id string) *Subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	sub, ok := d.subs[id]
+	if !ok {
+		return nil
+	}
+	copied := *sub
+	return &copied
+}
+
+// List 列出所有订阅（按创建时间排序）
+func (d *Dispatcher) List() []*Subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	result := make([]*Subscription, 0, len(d.subs))
+	for _, sub := range d.subs {
+		copied := *sub
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// SetLabels 设置附加到每个事件的主机标签，便于接收方按环境、角色等分组
+func (d *Dispatcher) SetLabels(labels map[string]string) {
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.labels = copied
+}
+
+// Publish 将事件投递给所有匹配的订阅，不阻塞调用方
+func (d *Dispatcher) Publish(eventType string, data any) {
+	event, body, err := d.newEvent(eventType, data)
+	if err != nil {
+		log.Warn().Err(err).Str("type", eventType).Msg("序列化 webhook 事件失败")
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, sub := range d.subs {
+		if sub.Enabled && sub.matches(eventType) {
+			d.enqueue(&delivery{sub: sub, event: event, body: body})
+		}
+	}
+}
+
+// Test 向指定订阅发送 ping 事件
+func (d *Dispatcher) Test(id string) error {
+	d.mu.RLock()
+	sub, ok := d.subs[id]
+	d.mu.RUnlock()
+	if !ok {
+		return errcode.New(errcode.NotFound, "webhook 订阅 %s 不存在", id)
+	}
+
+	event, body, err := d.newEvent(EventPing, map[string]string{"subscription_id": id})
+	if err != nil {
+		return err
+	}
+	d.enqueue(&delivery{sub: sub, event: event, body: body})
+	return nil
+}
+
+func (d *Dispatcher) newEvent(eventType string, data any) (*Event, []byte, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return nil, nil, err
+	}
+	d.mu.RLock()
+	labels := d.labels
+	d.mu.RUnlock()
+	event := &Event{
+		ID:        id,
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Hostname:  d.hostname,
+		Labels:    labels,
+		Data:      data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, body, nil
+}
+
+func (d *Dispatcher) enqueue(item *delivery) {
+	select {
+	case d.queue <- item:
+	default:
+		log.Warn().Str("id", item.sub.ID).Str("type", item.event.Type).Msg("webhook 队列已满，事件被丢弃")
+	}
+}
+
+// worker 顺序处理投递任务，失败时在当前 worker 内退避重试
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case item := <-d.queue:
+			d.deliver(item)
+		}
+	}
+}
+
+// deliver 投递单个事件，网络错误、5xx 和 429 会重试
+func (d *Dispatcher) deliver(item *delivery) {
+	backoff := initialBackoff
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		status, err := d.send(item)
+		if err == nil && status < 300 {
+			d.recordResult(item.sub.ID, status, nil)
+			return
+		}
+		retryable := err != nil || status == http.StatusTooManyRequests || status >= 500
+		if err == nil {
+			err = fmt.Errorf("订阅方返回 %d", status)
+		}
+
+		if !retryable || attempt == maxAttempts {
+			d.recordResult(item.sub.ID, status, err)
+			log.Warn().Err(err).Str("id", item.sub.ID).Str("type", item.event.Type).Int("attempts", attempt).Msg("webhook 投递失败")
+			return
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// send 发送一次请求
+// 签名为 HMAC-SHA256(secret, timestamp + "." + body)，订阅方应校验时间戳防止重放；聊天渠道的消息不签名
+func (d *Dispatcher) send(item *delivery) (int, error) {
+	d.mu.RLock()
+	target, secret, channel := item.sub.URL, item.sub.Secret, item.sub.Channel
+	d.mu.RUnlock()
+
+	body := item.body
+	if channel.chat() {
+		var err error
+		if target, body, err = channel.message(target, secret, item.event); err != nil {
+			return 0, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Runixo-Agent-Webhook")
+	if !channel.chat() {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Runixo-Event", item.event.Type)
+		req.Header.Set("X-Runixo-Delivery", item.event.ID)
+		req.Header.Set("X-Runixo-Timestamp", timestamp)
+		req.Header.Set("X-Runixo-Signature", "sha256="+Sign(secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if channel.Format == FormatTelegram {
+			// 错误信息包含请求地址，隐藏其中的 Bot Token
+			err = errors.New(strings.ReplaceAll(err.Error(), secret, "***"))
+		}
+		return 0, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Sign 计算 webhook 签名（十六进制）
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dispatcher) recordResult(id string, status int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sub, ok := d.subs[id]
+	if !ok {
+		return
+	}
+	sub.LastDeliveryAt = time.Now()
+	sub.LastStatus = status
+	if err != nil {
+		sub.LastError = err.Error()
+		sub.Failures++
+	} else {
+		sub.LastError = ""
+		sub.Failures = 0
+	}
+	d.saveLocked()
+}
+
+func (d *Dispatcher) checkURL(rawURL string) error {
+	if d.allowPrivate {
+		return nil
+	}
+	if err := security.CheckURL(rawURL); err != nil {
+		return errcode.Wrap(errcode.Of(err), err, "webhook URL 安全检查失败")
+	}
+	return nil
+}
+
+func (s *Subscription) matches(eventType string) bool {
+	for _, e := range s.Events {
+		if e == "*" || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEvents 校验事件过滤器，空列表表示订阅全部事件
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return []string{"*"}, nil
+	}
+	for _, e := range events {
+		if e == "*" {
+			continue
+		}
+		known := false
+		for _, k := range KnownEvents {
+			if e == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, errcode.New(errcode.InvalidArgument, "未知的事件类型: %s", e)
+		}
+	}
+	return events, nil
+}
+
+func (d *Dispatcher) load() {
+	data, err := os.ReadFile(filepath.Join(d.dataDir, "webhooks.json"))
+	if err != nil {
+		return
+	}
+	var subs []*Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		log.Warn().Err(err).Msg("解析 webhook 订阅失败")
+		return
+	}
+	for _, sub := range subs {
+		d.subs[sub.ID] = sub
+	}
+}
+
+// saveLocked 保存订阅（需要持有锁），文件包含签名密钥，权限为 0600
+func (d *Dispatcher) saveLocked() {
+	subs := make([]*Subscription, 0, len(d.subs))
+	for _, sub := range d.subs {
+		subs = append(subs, sub)
+	}
+	data, err := json.MarshalIndent(subs, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(d.dataDir, "webhooks.json"), data, 0600); err != nil {
+		log.Warn().Err(err).Msg("保存 webhook 订阅失败")
+	}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublishDeliversSignedEvent(t *testing.T) {
+	received := make(chan *http.Request, 4)
+	bodies := make(chan []byte, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	// 测试服务器监听在 127.0.0.1，需要允许内网地址
+	d, err := NewDispatcher(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error: %v", err)
+	}
+	defer d.Close()
+
+	sub, err := d.Add(srv.URL, "test-secret", []string{EventIPBlocked})
+	if err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+
+	d.SetLabels(map[string]string{"env": "prod"})
+
+	// 未订阅的事件不应投递
+	d.Publish(EventAlert, nil)
+	d.Publish(EventIPBlocked, map[string]string{"ip": "203.0.113.7"})
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		if got := r.Header.Get("X-Runixo-Event"); got != EventIPBlocked {
+			t.Errorf("X-Runixo-Event = %q, want %q", got, EventIPBlocked)
+		}
+		want := "sha256=" + Sign("test-secret", r.Header.Get("X-Runixo-Timestamp"), body)
+		if got := r.Header.Get("X-Runixo-Signature"); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil || event.Type != EventIPBlocked || event.Labels["env"] != "prod" {
+			t.Errorf("unexpected payload %s (err %v)", body, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+
+	select {
+	case r := <-received:
+		t.Errorf("unexpected extra delivery: %s", r.Header.Get("X-Runixo-Event"))
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if got := d.Get(sub.ID); got == nil || got.LastStatus != http.StatusOK {
+		t.Errorf("LastStatus not recorded: %+v", got)
+	}
+}
+
+func TestAddRejectsUnknownEventAndPrivateURL(t *testing.T) {
+	d, err := NewDispatcher(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error: %v", err)
+	}
+	defer d.Close()
+
+	if _, err := d.Add("https://example.com/hook", "", []string{"no.such.event"}); err == nil {
+		t.Error("Add() should reject unknown event types")
+	}
+	if _, err := d.Add("http://127.0.0.1:8080/hook", "", nil); err == nil {
+		t.Error("Add() should reject private addresses when allowPrivate is false")
+	}
+}
+
+// summary 实现 Summarizer 的事件数据
+type summary string
+
+func (s summary) Summary() string { return string(s) }
+
+func TestChatChannelsSendTextMessages(t *testing.T) {
+	type request struct {
+		path string
+		body map[string]any
+		sig  string
+	}
+	received := make(chan request, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		received <- request{path: r.URL.Path, body: body, sig: r.Header.Get("X-Runixo-Signature")}
+	}))
+	defer srv.Close()
+
+	d, err := NewDispatcher(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error: %v", err)
+	}
+	defer d.Close()
+
+	if _, err := d.AddChannel(srv.URL+"/slack", "", []string{EventIPBlocked}, Channel{Format: FormatSlack}); err != nil {
+		t.Fatalf("AddChannel(slack) error: %v", err)
+	}
+	if _, err := d.AddChannel(srv.URL, "123:token", []string{EventIPBlocked}, Channel{Format: FormatTelegram, ChatID: "42"}); err != nil {
+		t.Fatalf("AddChannel(telegram) error: %v", err)
+	}
+
+	d.Publish(EventIPBlocked, summary("IP 203.0.113.7 已封禁"))
+
+	got := make(map[string]request)
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-received:
+			got[r.path] = r
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of 2 messages were delivered", i)
+		}
+	}
+
+	slack, ok := got["/slack"]
+	if !ok {
+		t.Fatalf("slack message not delivered: %v", got)
+	}
+	if text, _ := slack.body["text"].(string); !strings.Contains(text, EventIPBlocked) || !strings.Contains(text, "IP 203.0.113.7 已封禁") {
+		t.Errorf("slack text = %q", text)
+	}
+	if slack.sig != "" {
+		t.Error("chat messages should not be signed")
+	}
+
+	telegram, ok := got["/bot123:token/sendMessage"]
+	if !ok {
+		t.Fatalf("telegram message not delivered: %v", got)
+	}
+	if telegram.body["chat_id"] != "42" || !strings.Contains(telegram.body["text"].(string), "已封禁") {
+		t.Errorf("telegram body = %v", telegram.body)
+	}
+}
+
+func TestAddChannelValidatesTelegram(t *testing.T) {
+	// 允许内网地址时不解析域名，测试不依赖 DNS
+	d, err := NewDispatcher(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error: %v", err)
+	}
+	defer d.Close()
+
+	if _, err := d.AddChannel("", "123:token", nil, Channel{Format: FormatTelegram}); err == nil {
+		t.Error("AddChannel() should require chat_id for telegram")
+	}
+	if _, err := d.AddChannel("", "", nil, Channel{Format: FormatTelegram, ChatID: "42"}); err == nil {
+		t.Error("AddChannel() should require a bot token for telegram")
+	}
+	if _, err := d.AddChannel("https://example.com/hook", "", nil, Channel{Format: "email"}); err == nil {
+		t.Error("AddChannel() should reject unknown formats")
+	}
+
+	sub, err := d.AddChannel("", "123:token", nil, Channel{Format: FormatTelegram, ChatID: "42"})
+	if err != nil {
+		t.Fatalf("AddChannel(telegram) error: %v", err)
+	}
+	if sub.URL != defaultTelegramURL {
+		t.Errorf("URL = %q, want %q", sub.URL, defaultTelegramURL)
+	}
+}
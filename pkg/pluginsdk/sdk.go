@@ -0,0 +1,878 @@
+// Package pluginsdk 外部进程插件开发包
+//
+// 外部插件是独立的可执行文件，由 Agent 按插件清单的 entry_point 启动，
+// 通过 hashicorp/go-plugin 握手后以 gRPC 通信。插件进程崩溃不会影响 Agent。
+//
+// 插件可选实现 EventHandler 接收清单 subscribe 中声明的事件，实现 MetricsHandler 接收 Agent 的每次指标采样，
+// 实现 ConfigChangeHandler 在配置更新时不重启而直接应用，实现 HTTPHandler 在 Agent REST API 中提供页面和接口。
+// 实现 HostAware 得到 Host，通过 Agent 发布事件、读写文件、执行命令、访问网络，
+// 以及把配置和状态保存在插件私有的 KV 存储中、向 Agent 指标采集上报自定义指标。
+// 实现 ScheduleHandler 后可以通过 Host.Schedule 注册按 cron 表达式触发的定时任务。
+// Host 按清单 permissions 中声明的权限放行，未声明的访问返回 ErrPermissionDenied。
+//
+//	func main() {
+//		pluginsdk.Serve(&myPlugin{})
+//	}
+package pluginsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/runixo/agent/api/proto/pluginpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PluginName go-plugin 插件集合中的插件名
+const PluginName = "plugin"
+
+// Handshake Agent 与插件进程的握手配置，协议不兼容时递增 ProtocolVersion
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "RUNIXO_PLUGIN",
+	MagicCookieValue: "b3f1c7e2-runixo-agent-plugin",
+}
+
+// Plugin 外部插件需要实现的接口
+type Plugin interface {
+	// Start 启动插件，dataDir 为插件的私有数据目录（<插件目录>/data），也是插件进程的工作目录
+	Start(ctx context.Context, id string, config map[string]any, dataDir string) error
+	// Stop 停止插件，返回后插件进程被结束
+	Stop(ctx context.Context) error
+	// GetStatus 返回插件状态
+	GetStatus(ctx context.Context) (map[string]string, error)
+	// HealthCheck 返回错误表示插件不健康，连续失败后 Agent 按重启策略重启插件
+	HealthCheck(ctx context.Context) error
+}
+
+// Event Agent 事件总线上的事件
+type Event struct {
+	Topic  string
+	Source string // agent 或 plugin:<插件 ID>
+	Time   time.Time
+	Data   []byte // JSON 编码
+}
+
+// EventHandler 可选接口：实现后插件接收清单 subscribe 中声明的主题的事件
+type EventHandler interface {
+	HandleEvent(ctx context.Context, e Event) error
+}
+
+// ScheduleHandler 可选接口：实现后插件接收通过 Host.Schedule 注册的定时任务，scheduled 为计划触发时间
+// 同一任务的上次调用返回前不会再次触发
+type ScheduleHandler interface {
+	HandleSchedule(ctx context.Context, name string, scheduled time.Time) error
+}
+
+// SystemMetrics Agent 的一次指标采样
+type SystemMetrics struct {
+	Time          time.Time
+	CPUPercent    float64
+	MemoryPercent float64
+	Load1         float64
+	NetSent       float64            // 所有网卡累计发送字节数
+	NetRecv       float64            // 所有网卡累计接收字节数
+	DiskRead      float64            // 所有磁盘累计读取字节数
+	DiskWrite     float64            // 所有磁盘累计写入字节数
+	Custom        map[string]float64 // 自定义数据源和插件指标的序列，键为 <数据源>:<序列>
+}
+
+// MetricsHandler 可选接口：实现后插件在 Agent 每次采样指标后收到样本，需要在清单中声明 system.read 权限
+type MetricsHandler interface {
+	HandleMetrics(ctx context.Context, m SystemMetrics) error
+}
+
+// ConfigChange 插件配置的变更
+type ConfigChange struct {
+	Old     map[string]any
+	New     map[string]any
+	Changed []string // 新增、修改和删除的配置项，按字典序
+}
+
+// ConfigChangeHandler 可选接口：实现后插件配置更新时 Agent 不再重启插件，而是调用 HandleConfigChange；
+// 返回错误时 Agent 重启插件，以新配置调用 Start
+type ConfigChangeHandler interface {
+	HandleConfigChange(ctx context.Context, c ConfigChange) error
+}
+
+// HTTPHandler 可选接口：实现后插件可以在 Agent REST API 的 prefix（/api/plugins/<插件 ID>/http）下提供页面和接口，
+// 请求经 Agent 认证后转发给插件。HandleHTTP 在第一个请求到达时调用一次，返回的 Handler 收到的是完整路径，
+// 可以用 http.StripPrefix(prefix, mux) 去掉前缀。转发的请求不含 Authorization 和 Cookie，
+// 请求体不超过 MaxHTTPRequestBody，响应体不超过 MaxHTTPResponseBody，不支持流式响应
+type HTTPHandler interface {
+	HandleHTTP(prefix string) http.Handler
+}
+
+// 插件 HTTP 请求和响应体的大小上限
+const (
+	MaxHTTPRequestBody  = 2 << 20
+	MaxHTTPResponseBody = 16 << 20
+)
+
+// HTTPRequest Agent 转发给插件的 HTTP 请求
+type HTTPRequest struct {
+	Method     string
+	Path       string // 完整路径，以 Prefix 开头
+	Query      string // 未解码的查询字符串
+	Headers    map[string]string
+	Body       []byte
+	RemoteAddr string
+	Prefix     string
+}
+
+// HTTPResponse 插件返回的 HTTP 响应
+type HTTPResponse struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// ErrPermissionDenied 插件清单未声明访问所需的权限
+var ErrPermissionDenied = errors.New("插件未声明所需权限")
+
+// ErrQuotaExceeded 插件 KV 存储超过 Agent 配置的配额
+var ErrQuotaExceeded = errors.New("插件存储超过配额")
+
+// IsPermissionDenied 判断 Host 调用是否因权限不足被拒绝
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied) || status.Code(err) == codes.PermissionDenied
+}
+
+// Host 插件可调用的 Agent 功能，括号中为所需的清单权限；数据目录内的文件无需声明权限，插件目录的其余部分只读
+type Host interface {
+	// Publish 向 Agent 事件总线发布事件，主题被加上 "plugin.<插件 ID>." 前缀，data 按 JSON 编码（events.publish）
+	Publish(ctx context.Context, topic string, data any) error
+	// ReadFile 读取文件，相对路径相对于数据目录（file.read）
+	ReadFile(ctx context.Context, path string) ([]byte, error)
+	// WriteFile 原子写入文件，相对路径相对于数据目录（file.write）
+	WriteFile(ctx context.Context, path string, data []byte) error
+	// Exec 执行系统命令，命令以非零状态退出不视为错误（exec）
+	Exec(ctx context.Context, req ExecRequest) (*ExecResult, error)
+	// Fetch 发起 HTTP 请求（network）
+	Fetch(ctx context.Context, req FetchRequest) (*FetchResponse, error)
+	// SystemInfo 读取系统信息（system.read）
+	SystemInfo(ctx context.Context) (*SystemInfo, error)
+
+	// StorageGet 读取插件 KV 存储中的值，键不存在时 found 为 false
+	StorageGet(ctx context.Context, key string) (value []byte, found bool, err error)
+	// StorageSet 写入插件 KV 存储，超过配额时返回 ErrQuotaExceeded
+	StorageSet(ctx context.Context, key string, value []byte) error
+	// StorageDelete 删除插件 KV 存储中的键，键不存在时不报错
+	StorageDelete(ctx context.Context, key string) error
+	// StorageList 按字典序列出以 prefix 开头的键
+	StorageList(ctx context.Context, prefix string) ([]string, error)
+
+	// RegisterMetric 注册指标，指标名被加上 "plugin_<插件 ID>_" 前缀；重复注册同名同类型的指标不报错
+	RegisterMetric(ctx context.Context, m Metric) error
+	// RecordMetrics 上报已注册指标的样本：gauge 设置为 Value，counter 累加 Value（不能为负）
+	RecordMetrics(ctx context.Context, samples ...MetricSample) error
+
+	// Schedule 注册定时任务，同名任务已存在时替换；插件停止时任务被移除
+	Schedule(ctx context.Context, spec ScheduleSpec) error
+	// Unschedule 移除定时任务，任务不存在时不报错
+	Unschedule(ctx context.Context, name string) error
+}
+
+// 错过运行（主机休眠、Agent 停止等）的处理策略
+const (
+	MissedSkip    = "skip"     // 跳过错过的运行
+	MissedRunOnce = "run_once" // 立即补跑一次
+)
+
+// ScheduleSpec 定时任务
+type ScheduleSpec struct {
+	Name      string        // 任务名，规则同插件 ID
+	Cron      string        // 5 字段 cron 表达式（分 时 日 月 周），或 @daily、@hourly、@every 1h 等
+	Timezone  string        // IANA 时区名，为空时使用 Agent 的本地时区
+	Jitter    time.Duration // 每次触发随机推迟 [0, Jitter)，精确到秒
+	MissedRun string        // MissedSkip（默认）或 MissedRunOnce
+	Timeout   time.Duration // 单次运行的超时，为 0 时使用 Agent 的默认超时
+}
+
+// 指标类型
+const (
+	MetricGauge   = "gauge"
+	MetricCounter = "counter"
+)
+
+// Metric 插件指标定义，Name 规则同 Prometheus（字母、数字、下划线，不能以数字开头）
+type Metric struct {
+	Name string
+	Help string
+	Type string // MetricGauge 或 MetricCounter，为空时为 gauge
+}
+
+// MetricSample 指标的一个样本，Labels 区分同一指标的不同序列
+type MetricSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// ExecRequest 命令执行请求
+type ExecRequest struct {
+	Name    string // PATH 中的命令名或绝对路径，不能执行插件目录和数据目录中的文件
+	Args    []string
+	Timeout time.Duration // 为 0 时使用 Agent 的默认超时
+}
+
+// ExecResult 命令执行结果
+type ExecResult struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// FetchRequest HTTP 请求
+type FetchRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// FetchResponse HTTP 响应
+type FetchResponse struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// SystemInfo 系统信息
+type SystemInfo struct {
+	Hostname        string
+	OS              string
+	Platform        string
+	PlatformVersion string
+	KernelVersion   string
+	Uptime          uint64 // 秒
+	CPUPercent      float64
+	MemoryTotal     uint64 // 字节
+	MemoryUsed      uint64
+	Load1           float64
+}
+
+// HostAware 可选接口：实现后插件在 Start 之前得到 Host
+type HostAware interface {
+	SetHost(h Host)
+}
+
+// PluginSet 插件进程传给 go-plugin 的插件集合
+func PluginSet(impl Plugin) goplugin.PluginSet {
+	return goplugin.PluginSet{PluginName: &GRPCPlugin{Impl: impl}}
+}
+
+// HostPluginSet Agent 传给 go-plugin 的插件集合，host 为空时插件无法调用 Host
+func HostPluginSet(host Host) goplugin.PluginSet {
+	return goplugin.PluginSet{PluginName: &GRPCPlugin{Host: host}}
+}
+
+// Serve 在插件进程中提供插件服务，直到 Agent 结束插件进程
+func Serve(impl Plugin) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         PluginSet(impl),
+		GRPCServer:      goplugin.DefaultGRPCServer,
+	})
+}
+
+// GRPCPlugin go-plugin 的 gRPC 插件实现，插件端使用 Impl，Agent 端通过 broker 向插件提供 Host
+type GRPCPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+	Impl Plugin
+	Host Host
+}
+
+// GRPCServer 在插件进程中注册服务
+func (p *GRPCPlugin) GRPCServer(broker *goplugin.GRPCBroker, s *grpc.Server) error {
+	pluginpb.RegisterPluginServer(s, &grpcServer{impl: p.Impl, broker: broker})
+	return nil
+}
+
+// GRPCClient 在 Agent 中创建客户端
+func (p *GRPCPlugin) GRPCClient(_ context.Context, broker *goplugin.GRPCBroker, c *grpc.ClientConn) (any, error) {
+	return &grpcClient{client: pluginpb.NewPluginClient(c), broker: broker, host: p.Host}, nil
+}
+
+type grpcServer struct {
+	pluginpb.UnimplementedPluginServer
+	impl   Plugin
+	broker *goplugin.GRPCBroker
+
+	httpOnce    sync.Once
+	httpHandler http.Handler
+}
+
+func (s *grpcServer) Start(ctx context.Context, req *pluginpb.StartRequest) (*pluginpb.Empty, error) {
+	var config map[string]any
+	if len(req.Config) > 0 {
+		if err := json.Unmarshal(req.Config, &config); err != nil {
+			return nil, err
+		}
+	}
+	if ha, ok := s.impl.(HostAware); ok && req.HostBrokerId != 0 {
+		conn, err := s.broker.Dial(req.HostBrokerId)
+		if err != nil {
+			return nil, fmt.Errorf("连接 Agent 失败: %w", err)
+		}
+		ha.SetHost(&hostClient{client: pluginpb.NewHostClient(conn)})
+	}
+	return &pluginpb.Empty{}, s.impl.Start(ctx, req.PluginId, config, req.DataDir)
+}
+
+func (s *grpcServer) Stop(ctx context.Context, _ *pluginpb.Empty) (*pluginpb.Empty, error) {
+	return &pluginpb.Empty{}, s.impl.Stop(ctx)
+}
+
+func (s *grpcServer) GetStatus(ctx context.Context, _ *pluginpb.Empty) (*pluginpb.StatusResponse, error) {
+	stats, err := s.impl.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.StatusResponse{Stats: stats}, nil
+}
+
+func (s *grpcServer) HealthCheck(ctx context.Context, _ *pluginpb.Empty) (*pluginpb.Empty, error) {
+	return &pluginpb.Empty{}, s.impl.HealthCheck(ctx)
+}
+
+func (s *grpcServer) OnEvent(ctx context.Context, req *pluginpb.Event) (*pluginpb.Empty, error) {
+	h, ok := s.impl.(EventHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "插件未实现 EventHandler")
+	}
+	return &pluginpb.Empty{}, h.HandleEvent(ctx, Event{
+		Topic:  req.Topic,
+		Source: req.Source,
+		Time:   time.UnixMilli(req.Timestamp),
+		Data:   req.Data,
+	})
+}
+
+func (s *grpcServer) OnSchedule(ctx context.Context, req *pluginpb.ScheduleTick) (*pluginpb.Empty, error) {
+	h, ok := s.impl.(ScheduleHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "插件未实现 ScheduleHandler")
+	}
+	return &pluginpb.Empty{}, h.HandleSchedule(ctx, req.Name, time.UnixMilli(req.Scheduled))
+}
+
+func (s *grpcServer) OnMetrics(ctx context.Context, req *pluginpb.MetricsSample) (*pluginpb.Empty, error) {
+	h, ok := s.impl.(MetricsHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "插件未实现 MetricsHandler")
+	}
+	return &pluginpb.Empty{}, h.HandleMetrics(ctx, SystemMetrics{
+		Time:          time.UnixMilli(req.Timestamp),
+		CPUPercent:    req.CpuPercent,
+		MemoryPercent: req.MemoryPercent,
+		Load1:         req.Load1,
+		NetSent:       req.NetSent,
+		NetRecv:       req.NetRecv,
+		DiskRead:      req.DiskRead,
+		DiskWrite:     req.DiskWrite,
+		Custom:        req.Custom,
+	})
+}
+
+func (s *grpcServer) OnConfigChange(ctx context.Context, req *pluginpb.ConfigChange) (*pluginpb.Empty, error) {
+	h, ok := s.impl.(ConfigChangeHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "插件未实现 ConfigChangeHandler")
+	}
+	change := ConfigChange{Changed: req.Changed}
+	if err := json.Unmarshal(req.OldConfig, &change.Old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(req.NewConfig, &change.New); err != nil {
+		return nil, err
+	}
+	return &pluginpb.Empty{}, h.HandleConfigChange(ctx, change)
+}
+
+func (s *grpcServer) OnHTTP(ctx context.Context, req *pluginpb.HTTPRequest) (*pluginpb.HTTPResponse, error) {
+	h, ok := s.impl.(HTTPHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "插件未实现 HTTPHandler")
+	}
+	s.httpOnce.Do(func() { s.httpHandler = h.HandleHTTP(req.Prefix) })
+	if s.httpHandler == nil {
+		return nil, status.Error(codes.Unimplemented, "插件未提供 HTTP Handler")
+	}
+
+	resp, err := ServeHTTPRequest(ctx, s.httpHandler, HTTPRequest{
+		Method:     req.Method,
+		Path:       req.Path,
+		Query:      req.Query,
+		Headers:    req.Headers,
+		Body:       req.Body,
+		RemoteAddr: req.RemoteAddr,
+		Prefix:     req.Prefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.HTTPResponse{StatusCode: int32(resp.StatusCode), Headers: resp.Headers, Body: resp.Body}, nil
+}
+
+// ServeHTTPRequest 用 h 处理 Agent 转发的请求，整体返回响应；进程内插件也用它实现 HTTP 接口
+func ServeHTTPRequest(ctx context.Context, h http.Handler, req HTTPRequest) (*HTTPResponse, error) {
+	target := req.Path
+	if req.Query != "" {
+		target += "?" + req.Query
+	}
+	r, err := http.NewRequestWithContext(ctx, req.Method, target, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+	r.Host = r.Header.Get("Host")
+	r.Header.Del("Host")
+	r.RemoteAddr = req.RemoteAddr
+
+	w := &responseRecorder{header: make(http.Header)}
+	h.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.body.Len() > MaxHTTPResponseBody {
+		return nil, status.Errorf(codes.ResourceExhausted, "响应体超过 %d 字节", MaxHTTPResponseBody)
+	}
+	return &HTTPResponse{StatusCode: w.status, Headers: joinHeader(w.header), Body: w.body.Bytes()}, nil
+}
+
+// responseRecorder 记录插件 Handler 的响应，整体返回给 Agent
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header { return w.header }
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// joinHeader 多个值以 ", " 连接，与 gRPC 消息中的 map 对应
+func joinHeader(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+type grpcClient struct {
+	client pluginpb.PluginClient
+	broker *goplugin.GRPCBroker
+	host   Host
+}
+
+func (c *grpcClient) Start(ctx context.Context, id string, config map[string]any, dataDir string) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	req := &pluginpb.StartRequest{PluginId: id, Config: data, DataDir: dataDir}
+	if c.host != nil {
+		req.HostBrokerId = c.broker.NextId()
+		go c.broker.AcceptAndServe(req.HostBrokerId, func(opts []grpc.ServerOption) *grpc.Server {
+			s := grpc.NewServer(opts...)
+			pluginpb.RegisterHostServer(s, &hostServer{host: c.host})
+			return s
+		})
+	}
+	_, err = c.client.Start(ctx, req)
+	return err
+}
+
+func (c *grpcClient) Stop(ctx context.Context) error {
+	_, err := c.client.Stop(ctx, &pluginpb.Empty{})
+	return err
+}
+
+func (c *grpcClient) GetStatus(ctx context.Context) (map[string]string, error) {
+	resp, err := c.client.GetStatus(ctx, &pluginpb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Stats, nil
+}
+
+// HealthCheck 旧版 SDK 构建的插件没有 HealthCheck，以 GetStatus 能否响应判断
+func (c *grpcClient) HealthCheck(ctx context.Context) error {
+	_, err := c.client.HealthCheck(ctx, &pluginpb.Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		_, err = c.client.GetStatus(ctx, &pluginpb.Empty{})
+	}
+	return err
+}
+
+// HandleEvent 向插件投递事件，插件未实现 EventHandler 时返回 Unimplemented
+func (c *grpcClient) HandleEvent(ctx context.Context, e Event) error {
+	_, err := c.client.OnEvent(ctx, &pluginpb.Event{
+		Topic:     e.Topic,
+		Source:    e.Source,
+		Timestamp: e.Time.UnixMilli(),
+		Data:      e.Data,
+	})
+	return err
+}
+
+// HandleSchedule 触发插件的定时任务，插件未实现 ScheduleHandler 时返回 Unimplemented
+func (c *grpcClient) HandleSchedule(ctx context.Context, name string, scheduled time.Time) error {
+	_, err := c.client.OnSchedule(ctx, &pluginpb.ScheduleTick{Name: name, Scheduled: scheduled.UnixMilli()})
+	return err
+}
+
+// HandleMetrics 向插件投递指标采样，插件未实现 MetricsHandler 时返回 Unimplemented
+func (c *grpcClient) HandleMetrics(ctx context.Context, m SystemMetrics) error {
+	_, err := c.client.OnMetrics(ctx, &pluginpb.MetricsSample{
+		Timestamp:     m.Time.UnixMilli(),
+		CpuPercent:    m.CPUPercent,
+		MemoryPercent: m.MemoryPercent,
+		Load1:         m.Load1,
+		NetSent:       m.NetSent,
+		NetRecv:       m.NetRecv,
+		DiskRead:      m.DiskRead,
+		DiskWrite:     m.DiskWrite,
+		Custom:        m.Custom,
+	})
+	return err
+}
+
+// HandleConfigChange 通知插件配置已更新，插件未实现 ConfigChangeHandler 时返回 Unimplemented
+func (c *grpcClient) HandleConfigChange(ctx context.Context, change ConfigChange) error {
+	oldConfig, err := json.Marshal(change.Old)
+	if err != nil {
+		return err
+	}
+	newConfig, err := json.Marshal(change.New)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.OnConfigChange(ctx, &pluginpb.ConfigChange{OldConfig: oldConfig, NewConfig: newConfig, Changed: change.Changed})
+	return err
+}
+
+// ServeHTTP 把请求转发给插件的 HTTPHandler，插件未实现时返回 Unimplemented
+func (c *grpcClient) ServeHTTP(ctx context.Context, req HTTPRequest) (*HTTPResponse, error) {
+	resp, err := c.client.OnHTTP(ctx, &pluginpb.HTTPRequest{
+		Method:     req.Method,
+		Path:       req.Path,
+		Query:      req.Query,
+		Headers:    req.Headers,
+		Body:       req.Body,
+		RemoteAddr: req.RemoteAddr,
+		Prefix:     req.Prefix,
+	}, grpc.MaxCallRecvMsgSize(MaxHTTPResponseBody+64<<10))
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPResponse{StatusCode: int(resp.StatusCode), Headers: resp.Headers, Body: resp.Body}, nil
+}
+
+// hostServer 在 Agent 中提供 Host 服务，data 以 json.RawMessage 交给 Host 实现
+type hostServer struct {
+	pluginpb.UnimplementedHostServer
+	host Host
+}
+
+// hostError 把 Host 实现返回的错误转为 gRPC 状态，权限不足对应 PermissionDenied
+func hostError(err error, fallback codes.Code) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, ErrPermissionDenied) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, ErrQuotaExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(fallback, err.Error())
+}
+
+func (s *hostServer) Publish(ctx context.Context, req *pluginpb.Event) (*pluginpb.Empty, error) {
+	var data any
+	if len(req.Data) > 0 {
+		data = json.RawMessage(req.Data)
+	}
+	if err := s.host.Publish(ctx, req.Topic, data); err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) ReadFile(ctx context.Context, req *pluginpb.FileRequest) (*pluginpb.FileResponse, error) {
+	data, err := s.host.ReadFile(ctx, req.Path)
+	if err != nil {
+		return nil, hostError(err, codes.Unknown)
+	}
+	return &pluginpb.FileResponse{Data: data}, nil
+}
+
+func (s *hostServer) WriteFile(ctx context.Context, req *pluginpb.FileRequest) (*pluginpb.Empty, error) {
+	if err := s.host.WriteFile(ctx, req.Path, req.Data); err != nil {
+		return nil, hostError(err, codes.Unknown)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) Exec(ctx context.Context, req *pluginpb.ExecRequest) (*pluginpb.ExecResponse, error) {
+	res, err := s.host.Exec(ctx, ExecRequest{
+		Name:    req.Name,
+		Args:    req.Args,
+		Timeout: time.Duration(req.Timeout) * time.Second,
+	})
+	if err != nil {
+		return nil, hostError(err, codes.Unknown)
+	}
+	return &pluginpb.ExecResponse{Stdout: res.Stdout, Stderr: res.Stderr, ExitCode: int32(res.ExitCode)}, nil
+}
+
+func (s *hostServer) Fetch(ctx context.Context, req *pluginpb.FetchRequest) (*pluginpb.FetchResponse, error) {
+	resp, err := s.host.Fetch(ctx, FetchRequest{Method: req.Method, URL: req.Url, Headers: req.Headers, Body: req.Body})
+	if err != nil {
+		return nil, hostError(err, codes.Unavailable)
+	}
+	return &pluginpb.FetchResponse{StatusCode: int32(resp.StatusCode), Headers: resp.Headers, Body: resp.Body}, nil
+}
+
+func (s *hostServer) GetSystemInfo(ctx context.Context, _ *pluginpb.Empty) (*pluginpb.SystemInfo, error) {
+	info, err := s.host.SystemInfo(ctx)
+	if err != nil {
+		return nil, hostError(err, codes.Unknown)
+	}
+	return &pluginpb.SystemInfo{
+		Hostname:        info.Hostname,
+		Os:              info.OS,
+		Platform:        info.Platform,
+		PlatformVersion: info.PlatformVersion,
+		KernelVersion:   info.KernelVersion,
+		Uptime:          info.Uptime,
+		CpuPercent:      info.CPUPercent,
+		MemoryTotal:     info.MemoryTotal,
+		MemoryUsed:      info.MemoryUsed,
+		Load1:           info.Load1,
+	}, nil
+}
+
+func (s *hostServer) StorageGet(ctx context.Context, req *pluginpb.StorageRequest) (*pluginpb.StorageValue, error) {
+	value, found, err := s.host.StorageGet(ctx, req.Key)
+	if err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.StorageValue{Value: value, Found: found}, nil
+}
+
+func (s *hostServer) StorageSet(ctx context.Context, req *pluginpb.StorageRequest) (*pluginpb.Empty, error) {
+	if err := s.host.StorageSet(ctx, req.Key, req.Value); err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) StorageDelete(ctx context.Context, req *pluginpb.StorageRequest) (*pluginpb.Empty, error) {
+	if err := s.host.StorageDelete(ctx, req.Key); err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) StorageList(ctx context.Context, req *pluginpb.StorageRequest) (*pluginpb.StorageKeys, error) {
+	keys, err := s.host.StorageList(ctx, req.Prefix)
+	if err != nil {
+		return nil, hostError(err, codes.Unknown)
+	}
+	return &pluginpb.StorageKeys{Keys: keys}, nil
+}
+
+func (s *hostServer) RegisterMetric(ctx context.Context, req *pluginpb.MetricDesc) (*pluginpb.Empty, error) {
+	if err := s.host.RegisterMetric(ctx, Metric{Name: req.Name, Help: req.Help, Type: req.Type}); err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) RecordMetrics(ctx context.Context, req *pluginpb.MetricBatch) (*pluginpb.Empty, error) {
+	samples := make([]MetricSample, 0, len(req.Samples))
+	for _, m := range req.Samples {
+		samples = append(samples, MetricSample{Name: m.Name, Labels: m.Labels, Value: m.Value})
+	}
+	if err := s.host.RecordMetrics(ctx, samples...); err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) Schedule(ctx context.Context, req *pluginpb.ScheduleSpec) (*pluginpb.Empty, error) {
+	err := s.host.Schedule(ctx, ScheduleSpec{
+		Name:      req.Name,
+		Cron:      req.Cron,
+		Timezone:  req.Timezone,
+		Jitter:    time.Duration(req.Jitter) * time.Second,
+		MissedRun: req.MissedRun,
+		Timeout:   time.Duration(req.Timeout) * time.Second,
+	})
+	if err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *hostServer) Unschedule(ctx context.Context, req *pluginpb.ScheduleSpec) (*pluginpb.Empty, error) {
+	if err := s.host.Unschedule(ctx, req.Name); err != nil {
+		return nil, hostError(err, codes.InvalidArgument)
+	}
+	return &pluginpb.Empty{}, nil
+}
+
+// hostClient 插件进程中的 Host 客户端
+type hostClient struct {
+	client pluginpb.HostClient
+}
+
+func (c *hostClient) Publish(ctx context.Context, topic string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Publish(ctx, &pluginpb.Event{
+		Topic:     topic,
+		Timestamp: time.Now().UnixMilli(),
+		Data:      payload,
+	})
+	return err
+}
+
+func (c *hostClient) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	resp, err := c.client.ReadFile(ctx, &pluginpb.FileRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (c *hostClient) WriteFile(ctx context.Context, path string, data []byte) error {
+	_, err := c.client.WriteFile(ctx, &pluginpb.FileRequest{Path: path, Data: data})
+	return err
+}
+
+func (c *hostClient) Exec(ctx context.Context, req ExecRequest) (*ExecResult, error) {
+	resp, err := c.client.Exec(ctx, &pluginpb.ExecRequest{
+		Name:    req.Name,
+		Args:    req.Args,
+		Timeout: int32(req.Timeout / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ExecResult{Stdout: resp.Stdout, Stderr: resp.Stderr, ExitCode: int(resp.ExitCode)}, nil
+}
+
+func (c *hostClient) Fetch(ctx context.Context, req FetchRequest) (*FetchResponse, error) {
+	resp, err := c.client.Fetch(ctx, &pluginpb.FetchRequest{Method: req.Method, Url: req.URL, Headers: req.Headers, Body: req.Body})
+	if err != nil {
+		return nil, err
+	}
+	return &FetchResponse{StatusCode: int(resp.StatusCode), Headers: resp.Headers, Body: resp.Body}, nil
+}
+
+func (c *hostClient) SystemInfo(ctx context.Context) (*SystemInfo, error) {
+	resp, err := c.client.GetSystemInfo(ctx, &pluginpb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return &SystemInfo{
+		Hostname:        resp.Hostname,
+		OS:              resp.Os,
+		Platform:        resp.Platform,
+		PlatformVersion: resp.PlatformVersion,
+		KernelVersion:   resp.KernelVersion,
+		Uptime:          resp.Uptime,
+		CPUPercent:      resp.CpuPercent,
+		MemoryTotal:     resp.MemoryTotal,
+		MemoryUsed:      resp.MemoryUsed,
+		Load1:           resp.Load1,
+	}, nil
+}
+
+func (c *hostClient) StorageGet(ctx context.Context, key string) ([]byte, bool, error) {
+	resp, err := c.client.StorageGet(ctx, &pluginpb.StorageRequest{Key: key})
+	if err != nil {
+		return nil, false, err
+	}
+	return resp.Value, resp.Found, nil
+}
+
+func (c *hostClient) StorageSet(ctx context.Context, key string, value []byte) error {
+	_, err := c.client.StorageSet(ctx, &pluginpb.StorageRequest{Key: key, Value: value})
+	if status.Code(err) == codes.ResourceExhausted {
+		return ErrQuotaExceeded
+	}
+	return err
+}
+
+func (c *hostClient) StorageDelete(ctx context.Context, key string) error {
+	_, err := c.client.StorageDelete(ctx, &pluginpb.StorageRequest{Key: key})
+	return err
+}
+
+func (c *hostClient) StorageList(ctx context.Context, prefix string) ([]string, error) {
+	resp, err := c.client.StorageList(ctx, &pluginpb.StorageRequest{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Keys, nil
+}
+
+func (c *hostClient) RegisterMetric(ctx context.Context, m Metric) error {
+	_, err := c.client.RegisterMetric(ctx, &pluginpb.MetricDesc{Name: m.Name, Help: m.Help, Type: m.Type})
+	return err
+}
+
+func (c *hostClient) RecordMetrics(ctx context.Context, samples ...MetricSample) error {
+	batch := &pluginpb.MetricBatch{Samples: make([]*pluginpb.MetricSample, 0, len(samples))}
+	for _, m := range samples {
+		batch.Samples = append(batch.Samples, &pluginpb.MetricSample{Name: m.Name, Labels: m.Labels, Value: m.Value})
+	}
+	_, err := c.client.RecordMetrics(ctx, batch)
+	return err
+}
+
+func (c *hostClient) Schedule(ctx context.Context, spec ScheduleSpec) error {
+	_, err := c.client.Schedule(ctx, &pluginpb.ScheduleSpec{
+		Name:      spec.Name,
+		Cron:      spec.Cron,
+		Timezone:  spec.Timezone,
+		Jitter:    int32(spec.Jitter / time.Second),
+		MissedRun: spec.MissedRun,
+		Timeout:   int32(spec.Timeout / time.Second),
+	})
+	return err
+}
+
+func (c *hostClient) Unschedule(ctx context.Context, name string) error {
+	_, err := c.client.Unschedule(ctx, &pluginpb.ScheduleSpec{Name: name})
+	return err
+}
@@ -8,6 +8,7 @@
 # 环境变量:
 #   RUNIXO_TOKEN    - 预设认证令牌
 #   RUNIXO_PORT     - 监听端口 (默认: 9527)
+#   RUNIXO_USER     - 运行 Agent 的用户 (默认: runixo，设为 root 保持以 root 运行)
 #
 
 set -e
@@ -29,6 +30,9 @@ CONFIG_FILE="${CONFIG_DIR}/agent.yaml"
 DATA_DIR="/var/lib/runixo"
 SERVICE_FILE="/etc/systemd/system/runixo-agent.service"
 INSTALL_DIR="/usr/local/bin"
+SUDOERS_FILE="/etc/sudoers.d/runixo-agent"
+ELEVATE_HELPER="/usr/local/libexec/runixo-elevate"
+AGENT_USER="${RUNIXO_USER:-runixo}"
 PORT="${RUNIXO_PORT:-9527}"
 API_PORT=$((PORT + 1))
 
@@ -197,9 +201,101 @@ download_binary() {
     log_success "Agent 已安装: ${ver}"
 }
 
+# 创建运行 Agent 的系统用户，加入读取日志所需的组
+# 不加入 docker 组：能访问 Docker 套接字即等同 root，需要管理 Docker 时请自行评估后手动加入
+create_user() {
+    [ "$AGENT_USER" = "root" ] && return
+    if ! id "$AGENT_USER" >/dev/null 2>&1; then
+        useradd --system --no-create-home --home-dir "${DATA_DIR}" --shell /usr/sbin/nologin "$AGENT_USER"
+        log_success "已创建用户 ${AGENT_USER}"
+    fi
+    for group in systemd-journal adm; do
+        getent group "$group" >/dev/null && usermod -aG "$group" "$AGENT_USER"
+    done
+    return 0
+}
+
+# 提权辅助程序：以 root 运行，重新校验命令和参数后执行。
+# sudo 只放行这个程序，Agent 用户即使绕过 Agent 直接调用 sudo 也只能执行默认提权规则允许的操作
+create_elevate_helper() {
+    mkdir -p "$(dirname "${ELEVATE_HELPER}")"
+    cat > "${ELEVATE_HELPER}.tmp" << 'EOFHELPER'
+#!/bin/sh
+# runixo-elevate <命令> <参数...>
+# 只允许 systemctl start|stop|restart|reload|enable|disable <服务> 和
+# apt-get|apt|dnf|yum install -y <包...>，服务名和包名必须以字母或数字开头（不能是选项）
+set -eu
+PATH=/usr/sbin:/usr/bin:/sbin:/bin
+export PATH
+
+deny() {
+    echo "runixo-elevate: 不允许的操作: $*" >&2
+    exit 126
+}
+
+# check_name <名称> <允许的字符>
+check_name() {
+    case "$1" in
+        [A-Za-z0-9]*) ;;
+        *) deny "$1" ;;
+    esac
+    case "$1" in
+        *[!A-Za-z0-9$2]*) deny "$1" ;;
+    esac
+}
+
+[ $# -ge 1 ] || deny "缺少命令"
+cmd=$(basename -- "$1")
+shift
+case "$cmd" in
+    systemctl)
+        [ $# -eq 2 ] || deny "$cmd $*"
+        case "$1" in
+            start|stop|restart|reload|enable|disable) ;;
+            *) deny "$cmd $1" ;;
+        esac
+        check_name "$2" '@._:-'
+        ;;
+    apt-get|apt|dnf|yum)
+        [ $# -ge 3 ] && [ "$1" = "install" ] && [ "$2" = "-y" ] || deny "$cmd $*"
+        i=0
+        for pkg in "$@"; do
+            i=$((i + 1))
+            [ "$i" -le 2 ] && continue
+            check_name "$pkg" '.+_:=~-'
+        done
+        ;;
+    *)
+        deny "$cmd"
+        ;;
+esac
+exec "$cmd" "$@"
+EOFHELPER
+    chown root:root "${ELEVATE_HELPER}.tmp"
+    chmod 755 "${ELEVATE_HELPER}.tmp"
+    mv "${ELEVATE_HELPER}.tmp" "${ELEVATE_HELPER}"
+}
+
+# 为 Agent 用户放行提权辅助程序，参数由辅助程序校验，而不是依赖 Agent 自身的提权规则
+create_sudoers() {
+    [ "$AGENT_USER" = "root" ] && return
+    create_elevate_helper
+    echo "${AGENT_USER} ALL=(root) NOPASSWD: ${ELEVATE_HELPER}" > "${SUDOERS_FILE}.tmp"
+    chmod 440 "${SUDOERS_FILE}.tmp"
+    if visudo -cf "${SUDOERS_FILE}.tmp" >/dev/null 2>&1; then
+        mv "${SUDOERS_FILE}.tmp" "${SUDOERS_FILE}"
+        log_success "sudo 规则已写入 ${SUDOERS_FILE}"
+    else
+        rm -f "${SUDOERS_FILE}.tmp"
+        log_warn "sudo 规则校验失败，服务管理等特权操作将不可用"
+    fi
+}
+
 create_config() {
     local token=$1
     mkdir -p "${CONFIG_DIR}" "${DATA_DIR}"
+    local elevation_mode="sudo"
+    [ "$AGENT_USER" = "root" ] && elevation_mode="none"
     cat > "${CONFIG_FILE}" << EOF
 server:
   host: "0.0.0.0"
@@ -227,8 +323,18 @@ update:
   auto: false
   channel: "stable"
   interval: 3600
+
+executor:
+  elevation:
+    mode: "${elevation_mode}"
+    helper: "${ELEVATE_HELPER}"
 EOF
     chmod 600 "${CONFIG_FILE}"
+    if [ "$AGENT_USER" != "root" ]; then
+        chown -R "${AGENT_USER}:" "${CONFIG_DIR}" "${DATA_DIR}"
+        # 自动更新需要替换二进制文件
+        chown "${AGENT_USER}:" "${INSTALL_DIR}/${BINARY_NAME}"
+    fi
     log_success "配置文件已创建"
 }
 
@@ -240,6 +346,7 @@ After=network.target
 
 [Service]
 Type=simple
+User=${AGENT_USER}
 ExecStart=${INSTALL_DIR}/${BINARY_NAME} --config ${CONFIG_FILE}
 Restart=always
 RestartSec=5
@@ -290,6 +397,7 @@ case "${1:-info}" in
     [[ $REPLY =~ ^[Yy]$ ]] || exit 0
     systemctl stop $SVC 2>/dev/null; systemctl disable $SVC 2>/dev/null
     rm -f /usr/local/bin/runixo-agent /usr/local/bin/runixo /etc/systemd/system/runixo-agent.service
+    rm -f /etc/sudoers.d/runixo-agent /usr/local/libexec/runixo-elevate
     rm -rf /etc/runixo; systemctl daemon-reload
     echo -e "${GREEN}已卸载${NC}" ;;
   help|*)
@@ -388,6 +496,8 @@ main() {
         token=$(generate_token)
     fi
 
+    create_user
+    create_sudoers
     create_config "$token"
     create_service
     create_cli
@@ -33,6 +33,7 @@ echo "删除文件..."
 rm -f /usr/local/bin/runixo-agent
 rm -f /usr/local/bin/runixo
 rm -f /etc/systemd/system/runixo-agent.service
+rm -f /etc/sudoers.d/runixo-agent
 rm -rf /etc/runixo
 
 systemctl daemon-reload